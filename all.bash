@@ -60,6 +60,7 @@ testonly       tests/seepage
 testonly       tests/diffusion
 testonly       tests/thermomech
 testonly       tests/porous
+testonly       tests/bench
 testandinstall out
 
 echo
@@ -0,0 +1,110 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// CavityExp implements the small-strain solution of the expansion of a cylindrical or spherical
+// cavity with radius a in an infinite undrained (Tresca) elastic-perfectly-plastic medium with
+// shear modulus G, undrained shear strength su and initial isotropic pressure p0. With c being the
+// radius of the plastic zone, the cavity pressure p and cavity displacement ua are (Yu 2000):
+//
+//  cylinder:  p = p0 + su [1 + 2 ln(c/a)]        ua/a = su c² / (2G a²)    (p ≥ p0 + su)
+//  sphere:    p = p0 + 4su/3 [1 + 3 ln(c/a)]     ua/a = su c³ / (3G a³)    (p ≥ p0 + 4su/3)
+//
+//  and ua/a = (p - p0) / (2G) (cylinder) or ua/a = (p - p0) / (4G) (sphere) before yielding
+//  Parameters: "G", "su", "p0", "a" and "sph" (1 => spherical cavity; default = 0 => cylinder)
+//  Note: the stresses are given with the sign convention of gofem (tension positive)
+//  Reference: Yu HS (2000) Cavity expansion methods in geomechanics, Kluwer Academic Publishers
+type CavityExp struct {
+	G, Su, P0, A float64 // input
+	Sph          bool    // spherical cavity
+}
+
+// Init initialises this structure
+func (o *CavityExp) Init(prms fun.Prms) (err error) {
+	o.G, o.Su, o.A = 1000, 10, 1
+	for _, p := range prms {
+		switch p.N {
+		case "G":
+			o.G = p.V
+		case "su":
+			o.Su = p.V
+		case "p0":
+			o.P0 = p.V
+		case "a":
+			o.A = p.V
+		case "sph":
+			o.Sph = p.V > 0
+		default:
+			return chk.Err("CavityExp: parameter named %q is incorrect", p.N)
+		}
+	}
+	if o.G <= 0 || o.Su <= 0 || o.A <= 0 {
+		return chk.Err("CavityExp: G=%g, su=%g and a=%g must be positive", o.G, o.Su, o.A)
+	}
+	return
+}
+
+// Yield returns the cavity pressure at the onset of yielding
+func (o CavityExp) Yield() float64 {
+	if o.Sph {
+		return o.P0 + 4.0*o.Su/3.0
+	}
+	return o.P0 + o.Su
+}
+
+// Limit returns the limit pressure given by the large-strain theory
+//  cylinder: p0 + su [1 + ln(G/su)]   sphere: p0 + 4su/3 [1 + ln(G/su)]
+func (o CavityExp) Limit() float64 {
+	return o.Yield() + (o.Yield()-o.P0)*math.Log(o.G/o.Su)
+}
+
+// Expansion computes the cavity displacement ua and the radius of the plastic zone c (c = a if the
+// medium is still elastic) corresponding to the cavity pressure p
+func (o CavityExp) Expansion(p float64) (ua, c float64) {
+	py := o.Yield()
+	if p <= py {
+		if o.Sph {
+			return o.A * (p - o.P0) / (4.0 * o.G), o.A
+		}
+		return o.A * (p - o.P0) / (2.0 * o.G), o.A
+	}
+	if o.Sph {
+		c = o.A * math.Exp((p-py)/(4.0*o.Su))
+		return o.Su * c * c * c / (3.0 * o.G * o.A * o.A), c
+	}
+	c = o.A * math.Exp((p-py)/(2.0*o.Su))
+	return o.Su * c * c / (2.0 * o.G * o.A), c
+}
+
+// Stress computes the radial and circumferential stresses at radius r ≥ a corresponding to the
+// cavity pressure p
+func (o CavityExp) Stress(p, r float64) (σr, σθ float64) {
+	_, c := o.Expansion(p)
+	if r < c { // plastic zone
+		if o.Sph {
+			σr = -(o.P0 + 4.0*o.Su/3.0 + 4.0*o.Su*math.Log(c/r))
+		} else {
+			σr = -(o.P0 + o.Su + 2.0*o.Su*math.Log(c/r))
+		}
+		return σr, σr + 2.0*o.Su
+	}
+	py := p // elastic zone: the pressure at r = c is min(p, py)
+	if p > o.Yield() {
+		py = o.Yield()
+	}
+	if o.Sph {
+		f := math.Pow(c/r, 3)
+		return -(o.P0 + (py-o.P0)*f), -(o.P0 - (py-o.P0)*f/2.0)
+	}
+	f := math.Pow(c/r, 2)
+	return -(o.P0 + (py-o.P0)*f), -(o.P0 - (py-o.P0)*f)
+}
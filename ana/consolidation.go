@@ -0,0 +1,317 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// Terzaghi implements the solution of Terzaghi's one-dimensional consolidation of a layer drained
+// at the top and impermeable at the bottom, with uniform initial excess pore-pressure u0
+//
+//      u = 0 (drained)      ztop
+//     ===========  ---
+//     |         |   ↑
+//     |  u0     |   H     u(z,t) = Σ 2 u0 / M sin(M Z / H) exp(-M² Tv)
+//     |         |   ↓     M = (2m+1) π / 2,  Z = ztop - z,  Tv = cv t / H²
+//     -----------  ---
+//     impermeable
+//
+//  Parameters: "cv" (coefficient of consolidation), "H" (drainage length), "u0" (initial excess
+//              pore-pressure), "ztop" (elevation of drained boundary; default = H), "mv" (coefficient
+//              of volume compressibility; for settlements) and "nt" (number of terms; default = 200)
+//  Note: for layers drained at the top and bottom, H is half the thickness
+type Terzaghi struct {
+	Cv   float64 // coefficient of consolidation
+	H    float64 // drainage length
+	U0   float64 // initial excess pore-pressure
+	Ztop float64 // elevation of drained boundary
+	Mv   float64 // coefficient of volume compressibility
+	Nt   int     // number of terms of series
+}
+
+// Init initialises this structure
+func (o *Terzaghi) Init(prms fun.Prms) (err error) {
+	o.Cv, o.H, o.U0, o.Ztop, o.Nt = 1, 1, 1, math.NaN(), 200
+	for _, p := range prms {
+		switch p.N {
+		case "cv":
+			o.Cv = p.V
+		case "H":
+			o.H = p.V
+		case "u0":
+			o.U0 = p.V
+		case "ztop":
+			o.Ztop = p.V
+		case "mv":
+			o.Mv = p.V
+		case "nt":
+			o.Nt = int(p.V)
+		default:
+			return chk.Err("Terzaghi: parameter named %q is incorrect", p.N)
+		}
+	}
+	if math.IsNaN(o.Ztop) {
+		o.Ztop = o.H
+	}
+	if o.Cv <= 0 || o.H <= 0 || o.Nt < 1 {
+		return chk.Err("Terzaghi: cv=%g and H=%g must be positive and nt=%d must be at least 1", o.Cv, o.H, o.Nt)
+	}
+	return
+}
+
+// Pressure computes the excess pore-pressure at time t and point x (the last coordinate is the
+// elevation)
+func (o Terzaghi) Pressure(t float64, x []float64) (u float64) {
+	Z := o.Ztop - x[len(x)-1]
+	Tv := o.Cv * t / (o.H * o.H)
+	for m := 0; m < o.Nt; m++ {
+		M := float64(2*m+1) * math.Pi / 2.0
+		u += 2.0 * o.U0 / M * math.Sin(M*Z/o.H) * math.Exp(-M*M*Tv)
+	}
+	return
+}
+
+// Degree computes the average degree of consolidation U(t) = 1 - Σ 2 / M² exp(-M² Tv)
+func (o Terzaghi) Degree(t float64) (U float64) {
+	Tv := o.Cv * t / (o.H * o.H)
+	U = 1
+	for m := 0; m < o.Nt; m++ {
+		M := float64(2*m+1) * math.Pi / 2.0
+		U -= 2.0 / (M * M) * math.Exp(-M*M*Tv)
+	}
+	return
+}
+
+// Settlement computes the settlement of the layer; i.e. mv u0 H U(t)
+func (o Terzaghi) Settlement(t float64) float64 {
+	return o.Mv * o.U0 * o.H * o.Degree(t)
+}
+
+// Mandel implements the solution of Mandel's problem; i.e. a poroelastic specimen (plane-strain)
+// with width 2a squeezed between rigid and frictionless impermeable plates by the force 2F (per
+// unit length); the lateral faces are drained and free of tractions
+//
+//          2F ↓
+//     ================  rigid plates
+//     |      ↑y      |
+//     |      o→x     |  pl = 0 @ x = ±a
+//     |              |
+//     ================
+//     ←----- 2a ----→
+//
+//  The solution is (Abousleiman et al. 1996; tension positive):
+//    p   = 2 F B (1+νu) / (3a) Σ sin αi / (αi - sin αi cos αi) (cos(αi x/a) - cos αi) ei
+//    ux  = [F ν / (2Ga) - F νu / (Ga) Σ ci ei] x + F/G Σ cos αi / (αi - sin αi cos αi) sin(αi x/a) ei
+//    uy  = [-F (1-ν) / (2Ga) + F (1-νu) / (Ga) Σ ci ei] y
+//    σyy = -F/a - 2F (νu-ν) / (a (1-ν)) Σ sin αi / (αi - sin αi cos αi) cos(αi x/a) ei + 2F/a Σ ci ei
+//  where ci = sin αi cos αi / (αi - sin αi cos αi), ei = exp(-αi² c t / a²) and the αi are the
+//  positive roots of tan α = (1-ν) / (νu-ν) α. The coefficient of consolidation is
+//    c = 2 κ B² G (1-ν) (1+νu)² / (9 (1-νu) (νu-ν))
+//  Parameters: "E", "nu" (drained), "nuu" (undrained Poisson's coefficient; default = 0.5), "B"
+//              (Skempton's coefficient; default = 1), "k" (κ: permeability coefficient; i.e. the
+//              hydraulic conductivity divided by the unit weight of the liquid), "a", "F" and "nr"
+//              (number of roots; default = 200)
+//  Note: the default values of νu and B correspond to incompressible grains and liquid
+//  Reference: Abousleiman Y, Cheng AHD, Cui L, Detournay E and Roegiers JC (1996) Mandel's problem
+//             revisited, Géotechnique, 46(2):187-195
+type Mandel struct {
+	E, Nu, Nuu, B, K, A, F float64   // input
+	G, C                   float64   // derived: shear modulus and coefficient of consolidation
+	Alp                    []float64 // roots αi
+}
+
+// Init initialises this structure
+func (o *Mandel) Init(prms fun.Prms) (err error) {
+	o.E, o.Nu, o.Nuu, o.B, o.K, o.A, o.F = 1000, 0.25, 0.5, 1, 1, 1, 1
+	nr := 200
+	for _, p := range prms {
+		switch p.N {
+		case "E":
+			o.E = p.V
+		case "nu":
+			o.Nu = p.V
+		case "nuu":
+			o.Nuu = p.V
+		case "B":
+			o.B = p.V
+		case "k":
+			o.K = p.V
+		case "a":
+			o.A = p.V
+		case "F":
+			o.F = p.V
+		case "nr":
+			nr = int(p.V)
+		default:
+			return chk.Err("Mandel: parameter named %q is incorrect", p.N)
+		}
+	}
+	if o.E <= 0 || o.K <= 0 || o.A <= 0 || o.B <= 0 || o.B > 1 || nr < 1 {
+		return chk.Err("Mandel: E=%g, k=%g and a=%g must be positive, B=%g must be in (0, 1] and nr=%d must be at least 1", o.E, o.K, o.A, o.B, nr)
+	}
+	if o.Nu < 0 || o.Nuu <= o.Nu || o.Nuu > 0.5 {
+		return chk.Err("Mandel: Poisson's coefficients must satisfy 0 ≤ ν < νu ≤ 0.5. ν=%g and νu=%g are invalid", o.Nu, o.Nuu)
+	}
+	ν, νu := o.Nu, o.Nuu
+	o.G = o.E / (2.0 * (1.0 + ν))
+	o.C = 2.0 * o.K * o.B * o.B * o.G * (1.0 - ν) * (1.0 + νu) * (1.0 + νu) / (9.0 * (1.0 - νu) * (νu - ν))
+	κ := (1.0 - ν) / (νu - ν)
+	o.Alp = ana_roots(func(α float64) float64 { return math.Sin(α) - κ*α*math.Cos(α) }, nr)
+	return
+}
+
+// Pressure computes the pore-pressure at time t and point x (origin at the centre of the specimen)
+func (o Mandel) Pressure(t float64, x []float64) (p float64) {
+	for _, α := range o.Alp {
+		s, c := math.Sin(α), math.Cos(α)
+		p += s / (α - s*c) * (math.Cos(α*x[0]/o.A) - c) * o.decay(α, t)
+	}
+	return 2.0 * o.F * o.B * (1.0 + o.Nuu) / (3.0 * o.A) * p
+}
+
+// Displ computes the displacements {ux, uy} at time t and point x
+func (o Mandel) Displ(t float64, x []float64) (u []float64) {
+	var s1, s2 float64
+	for _, α := range o.Alp {
+		s, c := math.Sin(α), math.Cos(α)
+		e := o.decay(α, t)
+		s1 += s * c / (α - s*c) * e
+		s2 += c / (α - s*c) * math.Sin(α*x[0]/o.A) * e
+	}
+	F, G, a := o.F, o.G, o.A
+	u = make([]float64, 2)
+	u[0] = (F*o.Nu/(2.0*G*a)-F*o.Nuu/(G*a)*s1)*x[0] + F/G*s2
+	u[1] = (-F*(1.0-o.Nu)/(2.0*G*a) + F*(1.0-o.Nuu)/(G*a)*s1) * x[1]
+	return
+}
+
+// Syy computes the vertical total stress at time t and point x
+func (o Mandel) Syy(t float64, x []float64) float64 {
+	var s1, s2 float64
+	for _, α := range o.Alp {
+		s, c := math.Sin(α), math.Cos(α)
+		e := o.decay(α, t)
+		s1 += s / (α - s*c) * math.Cos(α*x[0]/o.A) * e
+		s2 += s * c / (α - s*c) * e
+	}
+	F, a := o.F, o.A
+	return -F/a - 2.0*F*(o.Nuu-o.Nu)/(a*(1.0-o.Nu))*s1 + 2.0*F/a*s2
+}
+
+// decay returns exp(-α² c t / a²)
+func (o Mandel) decay(α, t float64) float64 {
+	return math.Exp(-α * α * o.C * t / (o.A * o.A))
+}
+
+// Cryer implements the solution of Cryer's problem; i.e. a poroelastic sphere with radius a drained
+// at the surface and loaded by the sudden pressure q. The pore-pressure at the centre first rises
+// above q (Mandel-Cryer effect) and then dissipates
+//  The solution for incompressible grains and liquid is (see Laplace transform of the problem):
+//    p(r,t) = -2q Σ (sinc(ξi r/a) - sinc(ξi)) / (ξi d'(ξi)) exp(-ξi² c t / a²)
+//  where sinc(x) = sin(x)/x and the ξi are the positive roots of
+//    d(ξ) = sin ξ / ξ + β cos ξ / ξ² - β sin ξ / ξ³ = 0   with   β = 4G / M
+//  i.e. tan ξ = ξ / (1 - η ξ² / 2) with η = (1-ν) / (1-2ν). M is the oedometric modulus and
+//  c = κ M the coefficient of consolidation
+//  Parameters: "E", "nu" (drained), "k" (κ: permeability coefficient), "a", "q" and "nr" (number of
+//              roots; default = 200)
+//  Reference: Cryer CW (1963) A comparison of the three-dimensional consolidation theories of Biot
+//             and Terzaghi, Quarterly Journal of Mechanics and Applied Mathematics, 16(4):401-412
+type Cryer struct {
+	E, Nu, K, A, Q float64   // input
+	M, C, Beta     float64   // derived: oedometric modulus, coefficient of consolidation and 4G/M
+	Xi             []float64 // roots ξi
+}
+
+// Init initialises this structure
+func (o *Cryer) Init(prms fun.Prms) (err error) {
+	o.E, o.Nu, o.K, o.A, o.Q = 1000, 0.25, 1, 1, 1
+	nr := 200
+	for _, p := range prms {
+		switch p.N {
+		case "E":
+			o.E = p.V
+		case "nu":
+			o.Nu = p.V
+		case "k":
+			o.K = p.V
+		case "a":
+			o.A = p.V
+		case "q":
+			o.Q = p.V
+		case "nr":
+			nr = int(p.V)
+		default:
+			return chk.Err("Cryer: parameter named %q is incorrect", p.N)
+		}
+	}
+	if o.E <= 0 || o.K <= 0 || o.A <= 0 || o.Nu < 0 || o.Nu >= 0.5 || nr < 1 {
+		return chk.Err("Cryer: E=%g, k=%g and a=%g must be positive, ν=%g must be in [0, 0.5) and nr=%d must be at least 1", o.E, o.K, o.A, o.Nu, nr)
+	}
+	ν := o.Nu
+	G := o.E / (2.0 * (1.0 + ν))
+	o.M = o.E * (1.0 - ν) / ((1.0 + ν) * (1.0 - 2.0*ν))
+	o.C = o.K * o.M
+	o.Beta = 4.0 * G / o.M
+	o.Xi = ana_roots(func(ξ float64) float64 { return (ξ*ξ-o.Beta)*math.Sin(ξ) + o.Beta*ξ*math.Cos(ξ) }, nr)
+	return
+}
+
+// Pressure computes the pore-pressure at time t and point x (origin at the centre of the sphere)
+func (o Cryer) Pressure(t float64, x []float64) (p float64) {
+	var r float64
+	for _, v := range x {
+		r += v * v
+	}
+	r = math.Sqrt(r)
+	β := o.Beta
+	for _, ξ := range o.Xi {
+		s, c := math.Sin(ξ), math.Cos(ξ)
+		ξ2, ξ3, ξ4 := ξ*ξ, ξ*ξ*ξ, ξ*ξ*ξ*ξ
+		dd := c/ξ - s/ξ2 - β*s/ξ2 - 3.0*β*c/ξ3 + 3.0*β*s/ξ4
+		p += (ana_sinc(ξ*r/o.A) - s/ξ) / (ξ * dd) * math.Exp(-ξ2*o.C*t/(o.A*o.A))
+	}
+	return -2.0 * o.Q * p
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// ana_roots finds the first n positive roots of f by scanning and bisection
+//  Note: the roots must be separated by more than π/40
+func ana_roots(f func(x float64) float64, n int) (roots []float64) {
+	Δx := math.Pi / 40.0
+	a := 1e-3
+	fa := f(a)
+	for len(roots) < n {
+		b := a + Δx
+		fb := f(b)
+		if fa*fb < 0 {
+			lo, hi, flo := a, b, fa
+			for it := 0; it < 100 && hi-lo > 1e-15*hi; it++ {
+				x := (lo + hi) / 2.0
+				fx := f(x)
+				if fx*flo > 0 {
+					lo, flo = x, fx
+				} else {
+					hi = x
+				}
+			}
+			roots = append(roots, (lo+hi)/2.0)
+		}
+		a, fa = b, fb
+	}
+	return
+}
+
+// ana_sinc returns sin(x)/x
+func ana_sinc(x float64) float64 {
+	if math.Abs(x) < 1e-8 {
+		return 1
+	}
+	return math.Sin(x) / x
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// Boussinesq implements the solution of a point load P acting on the surface of a linear elastic
+// half-space (3D). With R² = r² + d² where r is the horizontal distance to the load and d the depth
+// (compression positive):
+//  σz  = 3P d³ / (2π R⁵)
+//  σr  = P / (2π) [3 r² d / R⁵ - (1-2ν) / (R (R+d))]
+//  σθ  = (1-2ν) P / (2π) [1 / (R (R+d)) - d / R³]
+//  τrd = 3P r d² / (2π R⁵)
+//  w   = P (1+ν) / (2π E R) [2 (1-ν) + d² / R²]          (downwards)
+//  ur  = P (1+ν) / (2π E R) [r d / R² - (1-2ν) r / (R+d)]  (outwards)
+//  Parameters: "P", "E", "nu", "x0", "y0" (position of load) and "zs" (elevation of surface)
+//  Note: the results are given with the sign convention of gofem (tension positive and z pointing
+//        upwards). The time t is not used
+type Boussinesq struct {
+	P, E, Nu   float64 // load and elastic constants
+	X0, Y0, Zs float64 // position of load
+}
+
+// Init initialises this structure
+func (o *Boussinesq) Init(prms fun.Prms) (err error) {
+	o.P, o.E, o.Nu = 1, 1000, 0.25
+	for _, p := range prms {
+		switch p.N {
+		case "P":
+			o.P = p.V
+		case "E":
+			o.E = p.V
+		case "nu":
+			o.Nu = p.V
+		case "x0":
+			o.X0 = p.V
+		case "y0":
+			o.Y0 = p.V
+		case "zs":
+			o.Zs = p.V
+		default:
+			return chk.Err("Boussinesq: parameter named %q is incorrect", p.N)
+		}
+	}
+	if o.E <= 0 || o.Nu < 0 || o.Nu > 0.5 {
+		return chk.Err("Boussinesq: E=%g must be positive and ν=%g must be in [0, 0.5]", o.E, o.Nu)
+	}
+	return
+}
+
+// Displ computes the displacements {ux, uy, uz} at point x = {x, y, z} below the surface
+func (o Boussinesq) Displ(t float64, x []float64) (u []float64) {
+	cs, sn, r, d, R := o.polar(x)
+	ν := o.Nu
+	coef := o.P * (1.0 + ν) / (2.0 * math.Pi * o.E * R)
+	w := coef * (2.0*(1.0-ν) + d*d/(R*R))
+	ur := coef * (r*d/(R*R) - (1.0-2.0*ν)*r/(R+d))
+	return []float64{ur * cs, ur * sn, -w}
+}
+
+// Stress computes the stresses {σx, σy, σz, σxy, σyz, σzx} (Mandel's basis; i.e. the shear
+// components are multiplied by √2) at point x = {x, y, z} below the surface
+func (o Boussinesq) Stress(t float64, x []float64) (σ []float64) {
+	cs, sn, r, d, R := o.polar(x)
+	ν, P := o.Nu, o.P
+	R3, R5 := R*R*R, R*R*R*R*R
+	σz := 3.0 * P * d * d * d / (2.0 * math.Pi * R5)
+	σr := P / (2.0 * math.Pi) * (3.0*r*r*d/R5 - (1.0-2.0*ν)/(R*(R+d)))
+	σθ := (1.0 - 2.0*ν) * P / (2.0 * math.Pi) * (1.0/(R*(R+d)) - d/R3)
+	τ := 3.0 * P * r * d * d / (2.0 * math.Pi * R5)
+
+	// tension positive and z upwards: τrz = τrd
+	σ = make([]float64, 6)
+	σ[0] = -(σr*cs*cs + σθ*sn*sn)
+	σ[1] = -(σr*sn*sn + σθ*cs*cs)
+	σ[2] = -σz
+	σ[3] = -(σr - σθ) * sn * cs * math.Sqrt2
+	σ[4] = τ * sn * math.Sqrt2
+	σ[5] = τ * cs * math.Sqrt2
+	return
+}
+
+// polar returns the direction cosines, horizontal distance, depth and distance to the load
+func (o Boussinesq) polar(x []float64) (cs, sn, r, d, R float64) {
+	dx, dy := x[0]-o.X0, x[1]-o.Y0
+	r = math.Sqrt(dx*dx + dy*dy)
+	d = o.Zs - x[2]
+	R = math.Sqrt(r*r + d*d)
+	cs = 1
+	if r > 0 {
+		cs, sn = dx/r, dy/r
+	}
+	return
+}
+
+// GibsonSoil implements the solution of a uniform load q on a strip (plane-strain) of width 2b over
+// an incompressible (ν = 0.5) elastic half-space whose shear modulus increases linearly with depth
+// from zero at the surface (Gibson soil); i.e. G = m d. The surface settlement is (Gibson 1967)
+//  w = q / (2m)  under the load  and  w = 0  outside
+//  i.e. the half-space behaves exactly as a Winkler foundation with modulus 2m
+//  Parameters: "q", "m", "b", "xc" (centre of strip) and "zs" (elevation of surface)
+//  Reference: Gibson RE (1967) Some results concerning displacements and stresses in a non-homogeneous
+//             elastic half-space, Géotechnique, 17(1):58-67
+type GibsonSoil struct {
+	Q, M, B, Xc, Zs float64
+}
+
+// Init initialises this structure
+func (o *GibsonSoil) Init(prms fun.Prms) (err error) {
+	o.Q, o.M, o.B = 1, 1000, 1
+	for _, p := range prms {
+		switch p.N {
+		case "q":
+			o.Q = p.V
+		case "m":
+			o.M = p.V
+		case "b":
+			o.B = p.V
+		case "xc":
+			o.Xc = p.V
+		case "zs":
+			o.Zs = p.V
+		default:
+			return chk.Err("GibsonSoil: parameter named %q is incorrect", p.N)
+		}
+	}
+	if o.M <= 0 || o.B <= 0 {
+		return chk.Err("GibsonSoil: m=%g and b=%g must be positive", o.M, o.B)
+	}
+	return
+}
+
+// Settlement computes the (downwards) settlement of the surface at point x; i.e. the vertical
+// displacement is -w
+func (o GibsonSoil) Settlement(t float64, x []float64) float64 {
+	if math.Abs(x[0]-o.Xc) < o.B {
+		return o.Q / (2.0 * o.M)
+	}
+	if math.Abs(x[0]-o.Xc) == o.B {
+		return o.Q / (4.0 * o.M)
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// PullOut implements the solution of the pull-out test of an elastic rod (axial stiffness EA) with
+// embedded length L in a rigid medium (shear-lag model). The bond stress τ = ks ω (ω = slip) acts
+// on the perimeter h and is limited by τy (elastic-perfectly plastic bond). With λ² = ks h / EA,
+// the pull-out force P and the displacement u of the loaded end are:
+//
+//  elastic:  u = P / (EA λ tanh(λ L))                                 (P ≤ Py)
+//  plastic:  P = Nb + τy h a     u = τy/ks + (Nb a + τy h a²/2) / EA  (Py ≤ P < Pu)
+//
+//  where a is the length of the debonded (plastic) zone at the loaded end, Nb = EA λ tanh(λ(L-a)) τy/ks
+//  is the axial force at the elastic-plastic boundary, Py = Nb(a=0) and Pu = τy h L
+//  Parameters: "EA", "ks", "h", "tauy" and "L"
+//  Note: the far end of the rod is free; this solution corresponds to the rjoint-m1 model with
+//        kh = 0 and μ ≈ 0 and to a very stiff medium
+type PullOut struct {
+	EA, Ks, H, Tauy, L float64 // input
+	λ                  float64 // derived: λ = sqrt(ks h / EA)
+}
+
+// Init initialises this structure
+func (o *PullOut) Init(prms fun.Prms) (err error) {
+	o.EA, o.Ks, o.H, o.Tauy, o.L = 1e4, 1e3, 0.1, 10, 1
+	for _, p := range prms {
+		switch p.N {
+		case "EA":
+			o.EA = p.V
+		case "ks":
+			o.Ks = p.V
+		case "h":
+			o.H = p.V
+		case "tauy":
+			o.Tauy = p.V
+		case "L":
+			o.L = p.V
+		default:
+			return chk.Err("PullOut: parameter named %q is incorrect", p.N)
+		}
+	}
+	if o.EA <= 0 || o.Ks <= 0 || o.H <= 0 || o.Tauy <= 0 || o.L <= 0 {
+		return chk.Err("PullOut: EA=%g, ks=%g, h=%g, tauy=%g and L=%g must be positive", o.EA, o.Ks, o.H, o.Tauy, o.L)
+	}
+	o.λ = math.Sqrt(o.Ks * o.H / o.EA)
+	return
+}
+
+// Yield returns the pull-out force at the onset of debonding (at the loaded end)
+func (o PullOut) Yield() float64 {
+	return o.force(0)
+}
+
+// Limit returns the ultimate pull-out force Pu = τy h L
+func (o PullOut) Limit() float64 {
+	return o.Tauy * o.H * o.L
+}
+
+// Displ computes the displacement u of the loaded end and the length a of the debonded zone
+// corresponding to the pull-out force P
+func (o PullOut) Displ(P float64) (u, a float64, err error) {
+	if P <= o.Yield() {
+		return P / (o.EA * o.λ * math.Tanh(o.λ*o.L)), 0, nil
+	}
+	if P >= o.Limit() {
+		return 0, 0, chk.Err("PullOut: force P=%g must be smaller than the ultimate force Pu=%g", P, o.Limit())
+	}
+	lo, hi := 0.0, o.L
+	for it := 0; it < 200; it++ {
+		a = (lo + hi) / 2.0
+		if o.force(a) < P {
+			lo = a
+		} else {
+			hi = a
+		}
+		if hi-lo < 1e-14*o.L {
+			break
+		}
+	}
+	a = (lo + hi) / 2.0
+	Nb := o.force(a) - o.Tauy*o.H*a
+	u = o.Tauy/o.Ks + (Nb*a+o.Tauy*o.H*a*a/2.0)/o.EA
+	return
+}
+
+// Curve computes np points of the force-displacement curve up to P = frac Pu (0 < frac < 1)
+func (o PullOut) Curve(np int, frac float64) (U, P []float64, err error) {
+	U, P = make([]float64, np), make([]float64, np)
+	for i := 0; i < np; i++ {
+		P[i] = frac * o.Limit() * float64(i) / float64(np-1)
+		U[i], _, err = o.Displ(P[i])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// force returns the pull-out force P corresponding to a debonded zone with length a
+func (o PullOut) force(a float64) float64 {
+	Nb := o.EA * o.λ * math.Tanh(o.λ*(o.L-a)) * o.Tauy / o.Ks
+	return Nb + o.Tauy*o.H*a
+}
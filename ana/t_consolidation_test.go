@@ -0,0 +1,147 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_terzaghi01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("terzaghi01. pore-pressure and degree of consolidation")
+
+	var sol Terzaghi
+	err := sol.Init([]*fun.Prm{
+		&fun.Prm{N: "cv", V: 2},
+		&fun.Prm{N: "H", V: 10},
+		&fun.Prm{N: "u0", V: 3},
+		&fun.Prm{N: "mv", V: 1e-3},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+
+	// boundary conditions
+	chk.Scalar(tst, "u(top)", 1e-15, sol.Pressure(1, []float64{0, 10}), 0)
+
+	// degree of consolidation = 1 - average of u / u0
+	n := 2000
+	for _, t := range []float64{0.5, 5, 20, 80} {
+		var avg float64
+		for i := 0; i < n; i++ {
+			z := (float64(i) + 0.5) / float64(n) * 10
+			avg += sol.Pressure(t, []float64{0, z}) / float64(n)
+		}
+		U := sol.Degree(t)
+		io.Pforan("t = %4g  U = %v\n", t, U)
+		chk.Scalar(tst, "U", 1e-7, U, 1-avg/3)
+		chk.Scalar(tst, "settlement", 1e-15, sol.Settlement(t), 1e-3*3*10*U)
+	}
+
+	// small times: U = sqrt(4 Tv / π)
+	Tv := 2 * 0.5 / 100.0
+	chk.Scalar(tst, "U(Tv=0.01)", 1e-12, sol.Degree(0.5), math.Sqrt(4*Tv/math.Pi))
+}
+
+func Test_mandel01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mandel01. undrained and drained limits and equilibrium")
+
+	var sol Mandel
+	err := sol.Init([]*fun.Prm{
+		&fun.Prm{N: "E", V: 1e4},
+		&fun.Prm{N: "nu", V: 0.2},
+		&fun.Prm{N: "nuu", V: 0.45},
+		&fun.Prm{N: "B", V: 0.8},
+		&fun.Prm{N: "k", V: 1e-3},
+		&fun.Prm{N: "a", V: 2},
+		&fun.Prm{N: "F", V: 10},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	io.Pforan("c = %v  α = %v\n", sol.C, sol.Alp[:3])
+
+	// roots: Σ sin α cos α / (α - sin α cos α) = (νu - ν) / (2 (1 - νu))
+	var sum float64
+	for _, α := range sol.Alp {
+		s, c := math.Sin(α), math.Cos(α)
+		sum += s * c / (α - s*c)
+	}
+	chk.Scalar(tst, "Σci", 2e-4, sum, (0.45-0.2)/(2*(1-0.45)))
+
+	// undrained response: p = B (1+νu) F / (3a)
+	p0 := 0.8 * 1.45 * 10 / (3 * 2)
+	chk.Scalar(tst, "p(t=0+)", 1e-2, sol.Pressure(1e-9, []float64{0.5, 0}), p0)
+	chk.Scalar(tst, "uy(t=0+)", 1e-6, sol.Displ(1e-12, []float64{1, 1})[1], -10*(1-0.45)/(2*sol.G*2))
+
+	// drained response
+	chk.Scalar(tst, "p(t=∞)", 1e-15, sol.Pressure(1e12, []float64{0.5, 0}), 0)
+	chk.Vector(tst, "u(t=∞)", 1e-15, sol.Displ(1e12, []float64{2, 1}), []float64{10 * 0.2 / (2 * sol.G), -10 * (1 - 0.2) / (2 * sol.G * 2)})
+
+	// equilibrium: average σyy = -F/a; Mandel-Cryer effect: p(0,t) > p0 at early times
+	n := 4000
+	for _, T := range []float64{1e-9, 0.01, 0.1, 1} {
+		t := T * 4 / sol.C
+		var avg float64
+		for i := 0; i < n; i++ {
+			x := (float64(i) + 0.5) / float64(n) * 2
+			avg += sol.Syy(t, []float64{x, 0}) / float64(n)
+		}
+		pc := sol.Pressure(t, []float64{0, 0})
+		io.Pforan("T = %5g  avg(σyy) = %v  p(0) = %v\n", T, avg, pc)
+		chk.Scalar(tst, "avg(σyy)", 1e-5, avg, -5)
+		if T > 0.005 && T < 0.5 && pc < p0 {
+			tst.Errorf("Mandel-Cryer effect is missing: p(0) = %g < %g", pc, p0)
+		}
+	}
+}
+
+func Test_cryer01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("cryer01. pore-pressure at the centre of sphere")
+
+	for _, ν := range []float64{0, 0.25} {
+		var sol Cryer
+		err := sol.Init([]*fun.Prm{
+			&fun.Prm{N: "E", V: 1e3},
+			&fun.Prm{N: "nu", V: ν},
+			&fun.Prm{N: "k", V: 1e-2},
+			&fun.Prm{N: "a", V: 1},
+			&fun.Prm{N: "q", V: 1},
+		})
+		if err != nil {
+			tst.Errorf("Init failed:\n%v", err)
+			return
+		}
+
+		// initial pore-pressure is equal to q
+		chk.Scalar(tst, "p(0,0+)", 0.02, sol.Pressure(1e-5/sol.C, []float64{0, 0, 0}), 1)
+		chk.Scalar(tst, "p(a/2,0+)", 0.02, sol.Pressure(1e-5/sol.C, []float64{0.5, 0, 0}), 1)
+		chk.Scalar(tst, "p(a,t)", 1e-14, sol.Pressure(1e-2/sol.C, []float64{0, 0, 1}), 0)
+
+		// Mandel-Cryer effect and dissipation
+		var pmax float64
+		for i := 0; i < 1000; i++ {
+			T := 1e-4 * math.Pow(10, float64(i)/250)
+			pmax = math.Max(pmax, sol.Pressure(T/sol.C, []float64{0, 0, 0}))
+		}
+		io.Pforan("ν = %v  ξ = %v  pmax = %v\n", ν, sol.Xi[:3], pmax)
+		if pmax < 1.1 {
+			tst.Errorf("Mandel-Cryer effect is missing: pmax = %g", pmax)
+		}
+		chk.Scalar(tst, "p(0,∞)", 1e-10, sol.Pressure(10/sol.C, []float64{0, 0, 0}), 0)
+	}
+}
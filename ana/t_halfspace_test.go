@@ -0,0 +1,140 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_boussinesq01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("boussinesq01. point load on half-space")
+
+	var sol Boussinesq
+	err := sol.Init([]*fun.Prm{
+		&fun.Prm{N: "P", V: 100},
+		&fun.Prm{N: "E", V: 1e3},
+		&fun.Prm{N: "nu", V: 0.3},
+		&fun.Prm{N: "x0", V: 1},
+		&fun.Prm{N: "y0", V: 2},
+		&fun.Prm{N: "zs", V: 5},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+
+	// stresses from displacements (Hooke's law)
+	E, ν := 1e3, 0.3
+	G, l := E/(2*(1+ν)), E*ν/((1+ν)*(1-2*ν))
+	h := 1e-6
+	for _, x := range [][]float64{{1.7, 1.4, 3.9}, {-2, 5, 1}, {1, 2, 4}} {
+		var g [3][3]float64
+		for j := 0; j < 3; j++ {
+			xa, xb := []float64{x[0], x[1], x[2]}, []float64{x[0], x[1], x[2]}
+			xa[j] += h
+			xb[j] -= h
+			ua, ub := sol.Displ(0, xa), sol.Displ(0, xb)
+			for i := 0; i < 3; i++ {
+				g[i][j] = (ua[i] - ub[i]) / (2 * h)
+			}
+		}
+		tr := g[0][0] + g[1][1] + g[2][2]
+		σ := sol.Stress(0, x)
+		io.Pforan("x = %v  σ = %v\n", x, σ)
+		chk.Vector(tst, "σ", 1e-6, σ, []float64{
+			l*tr + 2*G*g[0][0],
+			l*tr + 2*G*g[1][1],
+			l*tr + 2*G*g[2][2],
+			G * (g[0][1] + g[1][0]) * math.Sqrt2,
+			G * (g[1][2] + g[2][1]) * math.Sqrt2,
+			G * (g[2][0] + g[0][2]) * math.Sqrt2,
+		})
+	}
+
+	// equilibrium: ∫ σz dA = -P on horizontal planes
+	n, L := 800, 400.0
+	var Fz float64
+	for i := 0; i < n; i++ {
+		s := (float64(i) + 0.5) / float64(n)
+		r, dr := s*s*L, 2*s*L/float64(n)
+		Fz += sol.Stress(0, []float64{1 + r, 2, 3})[2] * 2 * math.Pi * r * dr
+	}
+	chk.Scalar(tst, "Fz", 1e-4, Fz, -100)
+
+	// surface settlement: P (1 - ν²) / (π E r)
+	chk.Scalar(tst, "w(r=2)", 1e-15, -sol.Displ(0, []float64{3, 2, 5})[2], 100*(1-ν*ν)/(math.Pi*E*2))
+}
+
+func Test_gibson01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("gibson01. strip load on Gibson soil")
+
+	var sol GibsonSoil
+	err := sol.Init([]*fun.Prm{
+		&fun.Prm{N: "q", V: 50},
+		&fun.Prm{N: "m", V: 500},
+		&fun.Prm{N: "b", V: 2},
+		&fun.Prm{N: "xc", V: 1},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	chk.Scalar(tst, "w(centre)", 1e-15, sol.Settlement(0, []float64{1, 0}), 0.05)
+	chk.Scalar(tst, "w(inside)", 1e-15, sol.Settlement(0, []float64{-0.9, 0}), 0.05)
+	chk.Scalar(tst, "w(edge)", 1e-15, sol.Settlement(0, []float64{3, 0}), 0.025)
+	chk.Scalar(tst, "w(outside)", 1e-15, sol.Settlement(0, []float64{3.1, 0}), 0)
+}
+
+func Test_cavity01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("cavity01. cavity expansion in Tresca medium")
+
+	for _, sph := range []float64{0, 1} {
+		var sol CavityExp
+		err := sol.Init([]*fun.Prm{
+			&fun.Prm{N: "G", V: 1000},
+			&fun.Prm{N: "su", V: 10},
+			&fun.Prm{N: "p0", V: 50},
+			&fun.Prm{N: "a", V: 0.5},
+			&fun.Prm{N: "sph", V: sph},
+		})
+		if err != nil {
+			tst.Errorf("Init failed:\n%v", err)
+			return
+		}
+		py := 50 + 10*[]float64{1, 4.0 / 3.0}[int(sph)]
+		chk.Scalar(tst, "py", 1e-13, sol.Yield(), py)
+		chk.Scalar(tst, "plim", 1e-13, sol.Limit(), py+(py-50)*math.Log(100))
+		for _, p := range []float64{55, py, 70, 90} {
+			ua, c := sol.Expansion(p)
+			σa, _ := sol.Stress(p, 0.5)
+			σc1, θc1 := sol.Stress(p, c*(1-1e-10))
+			σc2, θc2 := sol.Stress(p, c*(1+1e-10))
+			io.Pforan("sph = %v  p = %6.3f  ua = %.6f  c = %.6f\n", sph, p, ua, c)
+			chk.Scalar(tst, "σr(a)", 1e-12, σa, -p)
+			if p > py {
+				chk.Scalar(tst, "σr(c-)", 1e-7, σc1, -py)
+				chk.Scalar(tst, "σr(c+)", 1e-7, σc2, -py)
+				chk.Scalar(tst, "σθ(c-)", 1e-7, θc1, θc2)
+				chk.Scalar(tst, "σθ-σr", 1e-12, θc1-σc1, 2*10)
+			}
+		}
+
+		// continuity of displacement at yield
+		ua1, _ := sol.Expansion(py * (1 - 1e-12))
+		ua2, _ := sol.Expansion(py * (1 + 1e-12))
+		chk.Scalar(tst, "ua(py)", 1e-10, ua1, ua2)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_pullout01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("pullout01. pull-out of rod with elastic-perfectly plastic bond")
+
+	EA, ks, h, τy, L := 2000.0, 3000.0, 0.4, 2.0, 3.0
+	var sol PullOut
+	err := sol.Init([]*fun.Prm{
+		&fun.Prm{N: "EA", V: EA},
+		&fun.Prm{N: "ks", V: ks},
+		&fun.Prm{N: "h", V: h},
+		&fun.Prm{N: "tauy", V: τy},
+		&fun.Prm{N: "L", V: L},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	io.Pforan("Py = %v  Pu = %v\n", sol.Yield(), sol.Limit())
+
+	// continuity at the onset of debonding
+	u, a, _ := sol.Displ(sol.Yield() * (1 + 1e-12))
+	chk.Scalar(tst, "u(Py)", 1e-12, u, τy/ks)
+	chk.Scalar(tst, "a(Py)", 1e-9, a, 0)
+
+	// shooting method: N' = h τ(u), u' = N / EA from the free end with u(0) = u0 and N(0) = 0
+	shoot := func(u0 float64) (u, N float64) {
+		n := 4000
+		dx := L / float64(n)
+		f := func(u, N float64) (float64, float64) { return N / EA, h * math.Min(ks*u, τy) }
+		u, N = u0, 0
+		for i := 0; i < n; i++ {
+			k1u, k1N := f(u, N)
+			k2u, k2N := f(u+dx*k1u/2, N+dx*k1N/2)
+			k3u, k3N := f(u+dx*k2u/2, N+dx*k2N/2)
+			k4u, k4N := f(u+dx*k3u, N+dx*k3N)
+			u += dx * (k1u + 2*k2u + 2*k3u + k4u) / 6
+			N += dx * (k1N + 2*k2N + 2*k3N + k4N) / 6
+		}
+		return
+	}
+	for _, frac := range []float64{0.1, 0.5, 0.8, 0.95} {
+		P := frac * sol.Limit()
+		lo, hi := 0.0, 1.0
+		for it := 0; it < 100; it++ {
+			u0 := (lo + hi) / 2
+			if _, N := shoot(u0); N < P {
+				lo = u0
+			} else {
+				hi = u0
+			}
+		}
+		unum, _ := shoot((lo + hi) / 2)
+		u, a, err = sol.Displ(P)
+		if err != nil {
+			tst.Errorf("Displ failed:\n%v", err)
+			return
+		}
+		io.Pforan("P = %8.5f  u = %v  unum = %v  a = %v\n", P, u, unum, a)
+		chk.Scalar(tst, "u", 1e-8, u, unum)
+	}
+
+	// force-displacement curve
+	U, P, err := sol.Curve(11, 0.99)
+	if err != nil {
+		tst.Errorf("Curve failed:\n%v", err)
+		return
+	}
+	for i := 1; i < len(U); i++ {
+		if U[i] <= U[i-1] || P[i] <= P[i-1] {
+			tst.Errorf("force-displacement curve must be increasing")
+			return
+		}
+	}
+	_, _, err = sol.Displ(sol.Limit())
+	if err == nil {
+		tst.Errorf("Displ must fail with P = Pu")
+	}
+}
@@ -6,6 +6,9 @@ package ele
 
 import (
 	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/la"
 )
 
@@ -19,3 +22,30 @@ func BuildCoordsMatrix(cell *inp.Cell, msh *inp.Mesh) (x [][]float64) {
 	}
 	return
 }
+
+// NatBcValue evaluates the function of a natural boundary condition at the current integration
+// point of face iface; i.e. after shape.CalcAtFaceIp. The real coordinates of the point are
+// computed only if the function depends on them; e.g. inp.ExprFunc
+func NatBcValue(fcn fun.Func, t float64, shape *shp.Shape, x [][]float64, iface int) float64 {
+	if !inp.FuncDependsOnX(fcn) {
+		return fcn.F(t, nil)
+	}
+	y := make([]float64, len(x))
+	for i := 0; i < len(x); i++ {
+		for k, m := range shape.FaceLocalVerts[iface] {
+			y[i] += shape.Sf[k] * x[i][m]
+		}
+	}
+	return fcn.F(t, y)
+}
+
+// CheckIvs checks that the arrays of initial internal values (e.g. stresses) have one value per
+// integration point; e.g. after changing the integration rule (see inp.IpsData)
+func CheckIvs(ivs map[string][]float64, nip int) (err error) {
+	for key, vals := range ivs {
+		if len(vals) != nip {
+			return chk.Err("number of initial values of %q (%d) is different than the number of integration points (%d)", key, len(vals), nip)
+		}
+	}
+	return
+}
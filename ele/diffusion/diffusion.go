@@ -208,7 +208,7 @@ func (o *Diffusion) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 
 	// contribution from natural boundary conditions
 	if len(o.NatBcs) > 0 {
-		return o.add_natbcs_to_rhs(fb, sol)
+		return o.AddNatBcsToRhs(fb, sol)
 	}
 	return
 }
@@ -312,11 +312,31 @@ func (o *Diffusion) OutIpCoords() (C [][]float64) {
 
 // OutIpKeys returns the integration points' keys
 func (o *Diffusion) OutIpKeys() []string {
-	return nil
+	if o.Ndim == 3 {
+		return []string{"u", "wx", "wy", "wz"}
+	}
+	return []string{"u", "wx", "wy"}
 }
 
 // OutIpVals returns the integration points' values corresponding to keys
 func (o *Diffusion) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
+	keys := o.OutIpKeys()
+	nip := len(o.IpsElem)
+	for idx, _ := range o.IpsElem {
+		err := o.ipvars(idx, sol)
+		if err != nil {
+			return
+		}
+		kval := o.Mdl.Kval(o.Uval)
+		M.Set("u", idx, nip, o.Uval)
+		for i := 0; i < o.Ndim; i++ {
+			var w_i float64
+			for j := 0; j < o.Ndim; j++ {
+				w_i -= kval * o.Mdl.Kcte[i][j] * o.Gradu[j]
+			}
+			M.Set(keys[1+i], idx, nip, w_i)
+		}
+	}
 }
 
 // auxiliary ////////////////////////////////////////////////////////////////////////////////////////
@@ -349,16 +369,13 @@ func (o *Diffusion) ipvars(idx int, sol *ele.Solution) (err error) {
 	return
 }
 
-// add_natbcs_to_rhs adds natural boundary conditions to rhs
-func (o *Diffusion) add_natbcs_to_rhs(fb []float64, sol *ele.Solution) (err error) {
+// AddNatBcsToRhs adds natural boundary conditions to rhs
+func (o *Diffusion) AddNatBcsToRhs(fb []float64, sol *ele.Solution) (err error) {
 
 	// compute surface integral
 	var qb float64
 	for _, nbc := range o.NatBcs {
 
-		// specified flux
-		qb = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for _, ipf := range o.IpsFace {
 
@@ -368,6 +385,10 @@ func (o *Diffusion) add_natbcs_to_rhs(fb []float64, sol *ele.Solution) (err erro
 			if err != nil {
 				return
 			}
+
+			// specified flux @ face ip
+			qb = ele.NatBcValue(nbc.Fcn, sol.T, o.Cell.Shp, o.X, iface)
+
 			Sf := o.Cell.Shp.Sf
 			Jf := la.VecNorm(o.Cell.Shp.Fnvec)
 			coef := ipf[3] * Jf
@@ -63,7 +63,84 @@ type CanOutputIps interface {
 	OutIpVals(M *IpsMap, sol *Solution) // integration points' values corresponding to keys
 }
 
+// WithCycleJump defines elements whose internal variables can be extrapolated over skipped
+// loading cycles (cycle jumping)
+type WithCycleJump interface {
+	GetIvsVec() []float64        // returns a copy of the internal variables (including stresses) at all integration points
+	SetIvsVec(v []float64) error // sets the internal variables at all integration points (and their backups)
+}
+
+// WithDynCtrl defines elements whose inertia can be scaled (mass scaling) or ignored (quasi-static
+// regions in transient analyses)
+type WithDynCtrl interface {
+	SetDynCtrl(mscale float64, qsta bool) // sets mass scaling factor and quasi-static flag
+}
+
+// WithLumpedMass defines elements that can compute a diagonal (lumped) mass matrix; e.g. for
+// explicit dynamics
+type WithLumpedMass interface {
+	AddToLumpedMass(m []float64, sol *Solution) (err error) // adds the diagonal of the lumped mass matrix to m [ny]
+}
+
+// WithPrestress defines elements whose axial force can be prescribed (stressing of anchors and
+// struts) until the anchorage is locked
+type WithPrestress interface {
+	SetPrestress(P float64, f fun.Func, tlock float64) // sets the prestress force P multiplied by f(t) if f != nil
+}
+
+// WithNodalTemp defines elements whose temperatures @ nodes can be taken from the "temp" dofs of
+// other elements sharing the same vertices; e.g. solids with thermal strains and thermomech elements
+type WithNodalTemp interface {
+	SetTempEqs(eqs []int) // sets the equations of "temp" dofs @ nodes [nverts]; nil => not available
+}
+
+// WithMemberForces defines structural members (beams, rods, ...) that can compute their internal
+// forces; e.g. for envelopes over steps and stages
+type WithMemberForces interface {
+	MemberForces(sol *Solution) map[string][]float64 // internal forces at stations or integration points; e.g. "N", "V1", "M22"
+}
+
+// WithHourglass defines elements that can measure their zero-energy (hourglass) deformation
+type WithHourglass interface {
+	HourglassRatio(sol *Solution) (r float64, ok bool) // ratio between hourglass and total deformations; ok == false if not applicable
+}
+
+// WithNatBcs defines elements that can add the contributions of natural boundary conditions (e.g.
+// prescribed fluxes and surface loads) to fb separately; e.g. to recover boundary fluxes from the
+// residual of the internal terms
+type WithNatBcs interface {
+	AddNatBcsToRhs(fb []float64, sol *Solution) (err error) // adds the natural boundary conditions to fb; to be called after AddToRhs
+}
+
+// WithLiqStorage defines elements that store liquid; e.g. for water balance checks
+type WithLiqStorage interface {
+	LiqMass(sol *Solution) (mass float64, err error) // returns the mass of liquid in element (∫ρl dV) computed with the current states
+}
+
 // WithFixedKM defines elements with fixed K,M matrices; to be recomputed if prms are changed
 type WithFixedKM interface {
 	Recompute(withM bool) // recompute K and M
 }
+
+// WithQuadFormK defines elements that can compute aᵀ K b with the element matrix K computed by the
+// last call to AddToKb; i.e. without assembling K; e.g. to compute sensitivities
+type WithQuadFormK interface {
+	QuadFormK(a, b []float64) (res float64, ok bool) // a and b are global vectors [ny]. ok == false if not available
+}
+
+// stages of the staggered solution of coupled problems; see WithSplit
+const (
+	SplitNone = iota // monolithic solution
+	SplitFlow        // flow stage: displacements are frozen
+	SplitMech        // mechanics stage: pressures are frozen
+)
+
+// WithSplit defines coupled (u-p) elements that can be solved by the staggered fixed-stress split.
+// During the flow and mechanics stages, the coupling blocks are not added to Kb and the flow
+// equations receive the fixed-stress term β・sl/K_dr・(pl - pl_k), where pl_k is the pressure
+// recorded at the end of the flow stage of the previous outer iteration and K_dr is the drained
+// bulk modulus. Different slots of recorded pressures are used by the substeps of subcycled stages
+type WithSplit interface {
+	SetSplit(stage, slot int, β float64, sol *Solution) (err error) // sets stage (see SplitNone, SplitFlow and SplitMech) and slot of pl_k. pl_k is recorded now if slot is empty. SplitNone clears all slots
+	RecordSplit(slot int, sol *Solution) (err error)                // records pl_k @ ips in slot; i.e. at the end of the flow stage (or substep)
+}
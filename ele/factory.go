@@ -17,9 +17,8 @@ type AllocatorType func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData,
 
 // GetInfo returns information about elements from factory
 func GetInfo(cell *inp.Cell, reg *inp.Region, sim *inp.Simulation) (info *Info, inactive bool, err error) {
-	edat := reg.Etag2data(cell.Tag)
-	if edat == nil {
-		err = chk.Err("cannot get data for element {tag=%d, id=%d}", cell.Tag, cell.Id)
+	edat, err := reg.Cell2data(cell)
+	if err != nil {
 		return
 	}
 	inactive = edat.Inact
@@ -37,9 +36,8 @@ func GetInfo(cell *inp.Cell, reg *inp.Region, sim *inp.Simulation) (info *Info,
 
 // New returns a new element from from factory
 func New(cell *inp.Cell, reg *inp.Region, sim *inp.Simulation) (ele Element, err error) {
-	edat := reg.Etag2data(cell.Tag)
-	if edat == nil {
-		err = chk.Err("cannot get data for element {tag=%d, id=%d}", cell.Tag, cell.Id)
+	edat, err := reg.Cell2data(cell)
+	if err != nil {
 		return
 	}
 	fcn, ok := allocators[edat.Type]
@@ -0,0 +1,442 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package fracture implements elements for the phase-field modelling of fracture
+package fracture
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/inp"
+	mdlsolid "github.com/cpmech/gofem/mdl/solid"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// SolidPhaseField implements an element for phase-field fracture with the displacements u and the
+// damage (phase field) d as primary variables ("pf"); see mdl/solid.PhaseField
+//  The damage is governed by
+//     η・ḋ + g'(d)・H + Gc/(cw・l)・w'(d) - Dc・∇²d = 0     with Dc = 2・Gc・l/cw
+//  where H is the history of the positive strain energy; thus, cracks nucleate and propagate
+//  without tracking their paths. The residual of the damage equations is
+//     Rd = ∫ N・(η・ḋ + f(d, H)) + Dc・∇N・∇d dV      with f = g'(d)・H + Gc/(cw・l)・w'(d)
+//  Note: (1) the damage @ ips is set in the states of the u-element before updating the stresses;
+//            thus, σ = g(d)・σ⁺ + σ⁻
+//        (2) with the staggered solution (solver.split = true; see fem.Splitting), the damage
+//            (flow stage) and the displacements (mechanics stage) are solved alternately until
+//            convergence. The monolithic solution uses the full Jacobian with the coupling blocks
+//        (3) η is the viscosity of the (optional) viscous regularisation; set with extra =
+//            "!eta:value" (transient simulations only)
+//        (4) the irreversibility of cracks is enforced by the history variable H; initial cracks
+//            can be given by prescribing pf = 1 at nodes
+type SolidPhaseField struct {
+
+	// auxiliary
+	Sim  *inp.Simulation // simulation
+	Cell *inp.Cell       // cell
+	Edat *inp.ElemData   // element data; stored in allocator to be used in SetEqs
+	Ndim int             // space dimension
+
+	// underlying element and model
+	U   *solid.Solid             // u-element
+	Mdl mdlsolid.PhaseFieldModel // phase-field model of u-element
+	Eta float64                  // viscosity η
+
+	// damage variables
+	Nd   int       // number of damage variables == number of vertices
+	Dmap []int     // [nd] assembly map of damage equations
+	Psi  []float64 // [nip] ψ* = β1・d + β2・dddt @ ips
+
+	// scratchpad. computed @ each ip
+	dval  float64     // d @ ip
+	gradd []float64   // [ndim] ∇d @ ip
+	σp    []float64   // [nsig] positive stresses σ⁺
+	B     [][]float64 // [nsig][nu] B matrix
+	Kdd   [][]float64 // [nd][nd] Kdd := dRd/dd consistent tangent matrix
+	Kud   [][]float64 // [nu][nd] Kud := dRu/dd consistent tangent matrix
+	Kdu   [][]float64 // [nd][nu] Kdu := dRd/du consistent tangent matrix
+
+	// staggered solution
+	split int // stage of staggered solution; see ele.SplitFlow and ele.SplitMech
+}
+
+// initialisation ///////////////////////////////////////////////////////////////////////////////////
+
+// register element
+func init() {
+
+	// information allocator
+	ele.SetInfoFunc("solid-phasefield", func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData) *ele.Info {
+
+		// u-element info
+		u_info := ele.GetInfoFunc("solid")(sim, cell, edat)
+		if u_info == nil {
+			return nil // fail
+		}
+
+		// solution variables
+		var info ele.Info
+		info.Dofs = make([][]string, len(u_info.Dofs))
+		for m, dofs := range u_info.Dofs {
+			info.Dofs[m] = append(append(info.Dofs[m], dofs...), "pf")
+		}
+
+		// maps
+		info.Y2F = u_info.Y2F
+		info.Y2F["pf"] = "qpf"
+
+		// t1 and t2 variables
+		info.T1vars = []string{"pf"}
+		info.T2vars = u_info.T2vars
+		info.Nextrap = u_info.Nextrap
+		return &info
+	})
+
+	// element allocator
+	ele.SetAllocator("solid-phasefield", func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData, x [][]float64) ele.Element {
+
+		// basic data
+		var o SolidPhaseField
+		o.Sim = sim
+		o.Cell = cell
+		o.Edat = edat
+		o.Ndim = sim.Ndim
+
+		// allocate u element
+		u_elem := ele.GetAllocator("solid")(sim, cell, edat, x)
+		if u_elem == nil {
+			chk.Panic("cannot allocate underlying u-element")
+		}
+		o.U = u_elem.(*solid.Solid)
+		if o.U.HasContact || o.U.Xfem || o.U.Tdep != nil {
+			chk.Panic("solid-phasefield element {tag=%d, id=%d} cannot be combined with contact, xfem or temperature dependent parameters", cell.Tag, cell.Id)
+		}
+
+		// model
+		var ok bool
+		o.Mdl, ok = o.U.Mdl.(mdlsolid.PhaseFieldModel)
+		if !ok {
+			chk.Panic("solid-phasefield element {tag=%d, id=%d} requires a phase-field model; e.g. \"phase-field\". %q is invalid", cell.Tag, cell.Id, o.U.MdlName)
+		}
+
+		// viscosity
+		if s_eta, found := io.Keycode(edat.Extra, "eta"); found {
+			o.Eta = io.Atof(s_eta)
+		}
+
+		// damage variables
+		nip := len(o.U.IpsElem)
+		o.Nd = cell.Shp.Nverts
+		o.Psi = make([]float64, nip)
+
+		// scratchpad. computed @ each ip
+		nsig := 2 * o.Ndim
+		o.gradd = make([]float64, o.Ndim)
+		o.σp = make([]float64, nsig)
+		o.B = la.MatAlloc(nsig, o.U.Nu)
+		o.Kdd = la.MatAlloc(o.Nd, o.Nd)
+		o.Kud = la.MatAlloc(o.U.Nu, o.Nd)
+		o.Kdu = la.MatAlloc(o.Nd, o.U.Nu)
+
+		// return new element
+		return &o
+	})
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o *SolidPhaseField) Id() int { return o.Cell.Id }
+
+// SetEqs set equations
+func (o *SolidPhaseField) SetEqs(eqs [][]int, mixedform_eqs []int) (err error) {
+	u_eqs := make([][]int, len(eqs))
+	o.Dmap = make([]int, o.Nd)
+	for m, e := range eqs {
+		n := len(e) - 1
+		u_eqs[m] = e[:n]
+		o.Dmap[m] = e[n]
+	}
+	return o.U.SetEqs(u_eqs, mixedform_eqs)
+}
+
+// SetEleConds set element conditions
+func (o *SolidPhaseField) SetEleConds(key string, f fun.Func, extra string) (err error) {
+	return o.U.SetEleConds(key, f, extra)
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *SolidPhaseField) InterpStarVars(sol *ele.Solution) (err error) {
+	err = o.U.InterpStarVars(sol)
+	if err != nil {
+		return
+	}
+	for idx, ip := range o.U.IpsElem {
+		err = o.Cell.Shp.CalcAtIp(o.U.X, ip, false)
+		if err != nil {
+			return
+		}
+		o.Psi[idx] = 0
+		for m := 0; m < o.Nd; m++ {
+			o.Psi[idx] += o.Cell.Shp.S[m] * sol.Psi[o.Dmap[m]]
+		}
+	}
+	return
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o *SolidPhaseField) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
+
+	// u-element
+	err = o.U.AddToRhs(fb, sol)
+	if err != nil {
+		return
+	}
+
+	// for each integration point
+	var coef float64
+	Dc := o.Mdl.Diffusivity()
+	β1 := sol.DynCfs.GetBet1()
+	for idx, ip := range o.U.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		coef, err = o.ipvars(idx, ip, sol)
+		if err != nil {
+			return
+		}
+		S := o.Cell.Shp.S
+		G := o.Cell.Shp.G
+
+		// local term
+		f, _ := o.Mdl.Driving(o.dval, o.U.States[idx].Alp[1])
+		if !sol.Steady && o.Eta > 0 {
+			f += o.Eta * (β1*o.dval - o.Psi[idx])
+		}
+
+		// add negative of residual term to fb
+		for m := 0; m < o.Nd; m++ {
+			r := o.Dmap[m]
+			fb[r] -= coef * S[m] * f
+			for i := 0; i < o.Ndim; i++ {
+				fb[r] -= coef * Dc * G[m][i] * o.gradd[i]
+			}
+		}
+	}
+	return
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o *SolidPhaseField) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+
+	// u-element: Kuu
+	err = o.U.AddToKb(Kb, sol, firstIt)
+	if err != nil {
+		return
+	}
+
+	// clear matrices
+	la.MatFill(o.Kdd, 0)
+	la.MatFill(o.Kud, 0)
+	la.MatFill(o.Kdu, 0)
+
+	// for each integration point
+	var coef float64
+	Dc := o.Mdl.Diffusivity()
+	β1 := sol.DynCfs.GetBet1()
+	nverts := o.Cell.Shp.Nverts
+	for idx, ip := range o.U.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		coef, err = o.ipvars(idx, ip, sol)
+		if err != nil {
+			return
+		}
+		S := o.Cell.Shp.S
+		G := o.Cell.Shp.G
+
+		// Kdd := dRd/dd
+		_, dfdd := o.Mdl.Driving(o.dval, o.U.States[idx].Alp[1])
+		if !sol.Steady && o.Eta > 0 {
+			dfdd += o.Eta * β1
+		}
+		for m := 0; m < o.Nd; m++ {
+			for n := 0; n < o.Nd; n++ {
+				o.Kdd[m][n] += coef * S[m] * S[n] * dfdd
+				for i := 0; i < o.Ndim; i++ {
+					o.Kdd[m][n] += coef * Dc * G[m][i] * G[n][i]
+				}
+			}
+		}
+
+		// coupling: Kud = ∫ tr(B)・g'(d)・σ⁺・N dV and Kdu = ∫ N・g'(d)・σ⁺・B dV if H is increasing
+		if o.split != ele.SplitNone {
+			continue
+		}
+		radius := 1.0
+		if sol.Axisym {
+			radius = o.Cell.Shp.AxisymGetRadius(o.U.X)
+		}
+		solid.IpBmatrix(o.B, o.Ndim, nverts, G, radius, S, sol.Axisym)
+		loading := o.Mdl.PosStress(o.σp, o.U.States[idx])
+		_, dgdd := o.Mdl.Degrad(o.dval)
+		for i := 0; i < o.U.Nu; i++ {
+			var bσ float64
+			for k, σ := range o.σp {
+				bσ += o.B[k][i] * σ
+			}
+			for m := 0; m < o.Nd; m++ {
+				o.Kud[i][m] += coef * bσ * dgdd * S[m]
+				if loading {
+					o.Kdu[m][i] += coef * S[m] * dgdd * bσ
+				}
+			}
+		}
+	}
+
+	// add K to sparse matrix Kb
+	//    _         _
+	//   |  Kuu Kud  |
+	//   |_ Kdu Kdd _|
+	//
+	//  Note: Kud and Kdu are replaced by zeros in the stages of the staggered solution
+	//
+	for i, I := range o.Dmap {
+		for j, J := range o.Dmap {
+			Kb.Put(I, J, o.Kdd[i][j])
+		}
+		for j, J := range o.U.Umap {
+			Kb.Put(I, J, o.Kdu[i][j])
+			Kb.Put(J, I, o.Kud[j][i])
+		}
+	}
+	return
+}
+
+// Update perform (tangent) update
+func (o *SolidPhaseField) Update(sol *ele.Solution) (err error) {
+	err = o.set_damage(sol)
+	if err != nil {
+		return
+	}
+	return o.U.Update(sol)
+}
+
+// SetSplit sets the stage of the staggered solution
+//  Note: the damage (flow stage) and the displacements (mechanics stage) are solved alternately;
+//        thus, β and slot are not used
+func (o *SolidPhaseField) SetSplit(stage, slot int, β float64, sol *ele.Solution) (err error) {
+	o.split = stage
+	return
+}
+
+// RecordSplit does nothing because there are no fixed-stress terms
+func (o *SolidPhaseField) RecordSplit(slot int, sol *ele.Solution) (err error) {
+	return
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *SolidPhaseField) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
+	err = o.U.SetIniIvs(sol, ivs)
+	if err != nil {
+		return
+	}
+	return o.set_damage(sol)
+}
+
+// BackupIvs create copy of internal variables
+func (o *SolidPhaseField) BackupIvs(aux bool) (err error) {
+	return o.U.BackupIvs(aux)
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *SolidPhaseField) RestoreIvs(aux bool) (err error) {
+	return o.U.RestoreIvs(aux)
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *SolidPhaseField) Ureset(sol *ele.Solution) (err error) {
+	return o.U.Ureset(sol)
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o *SolidPhaseField) Encode(enc utl.Encoder) (err error) {
+	return o.U.Encode(enc)
+}
+
+// Decode decodes internal variables
+func (o *SolidPhaseField) Decode(dec utl.Decoder) (err error) {
+	return o.U.Decode(dec)
+}
+
+// OutIpCoords returns the coordinates of integration points
+func (o *SolidPhaseField) OutIpCoords() (C [][]float64) {
+	return o.U.OutIpCoords()
+}
+
+// OutIpKeys returns the integration points' keys
+//  Note: the damage "d" and the history "H" are internal variables of the model
+func (o *SolidPhaseField) OutIpKeys() []string {
+	return o.U.OutIpKeys()
+}
+
+// OutIpVals returns the integration points' values corresponding to keys
+func (o *SolidPhaseField) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
+	o.U.OutIpVals(M, sol)
+}
+
+// AddToExt extrapolates stresses at integration points to nodes
+func (o *SolidPhaseField) AddToExt(sol *ele.Solution) (err error) {
+	return o.U.AddToExt(sol)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// ipvars computes the damage and its gradient @ integration point idx and returns the coefficient
+// of integration
+func (o *SolidPhaseField) ipvars(idx int, ip []float64, sol *ele.Solution) (coef float64, err error) {
+	err = o.Cell.Shp.CalcAtIp(o.U.X, ip, true)
+	if err != nil {
+		return
+	}
+	coef = o.Cell.Shp.J * ip[3] * o.U.Thickness
+	if sol.Axisym {
+		coef *= o.Cell.Shp.AxisymGetRadius(o.U.X)
+	}
+	o.dval = 0
+	for i := 0; i < o.Ndim; i++ {
+		o.gradd[i] = 0
+	}
+	for m := 0; m < o.Nd; m++ {
+		d := sol.Y[o.Dmap[m]]
+		o.dval += o.Cell.Shp.S[m] * d
+		for i := 0; i < o.Ndim; i++ {
+			o.gradd[i] += o.Cell.Shp.G[m][i] * d
+		}
+	}
+	return
+}
+
+// set_damage sets the damage @ ips in the states of the u-element
+func (o *SolidPhaseField) set_damage(sol *ele.Solution) (err error) {
+	for idx, ip := range o.U.IpsElem {
+		err = o.Cell.Shp.CalcAtIp(o.U.X, ip, false)
+		if err != nil {
+			return
+		}
+		var d float64
+		for m := 0; m < o.Nd; m++ {
+			d += o.Cell.Shp.S[m] * sol.Y[o.Dmap[m]]
+		}
+		o.Mdl.SetDamage(o.U.States[idx], d)
+	}
+	return
+}
@@ -0,0 +1,105 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package porous
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// LiqKeys returns the keys of integration points' values related to liquefaction analyses
+//  Dpl  -- excess pore-liquid pressure: Δpl = pl - pl0
+//  ru   -- excess pore-liquid pressure ratio: ru = Δpl / σ'v0
+//  gamc -- cumulative (deviatoric) shear strain: Σ √(2/3) |dev(Δε)|
+func LiqKeys() []string {
+	return []string{"Dpl", "ru", "gamc"}
+}
+
+// Liquefaction holds data @ integration points to compute excess pore-liquid pressures and
+// cumulative shear strains; e.g. for dynamic coupled analyses
+//  Note: allocated by solid-liquid elements with the "!liq:1" flag only
+type Liquefaction struct {
+	Pl0  []float64 // [nip] initial liquid pressure
+	Sv0  []float64 // [nip] initial vertical effective stress (compression positive)
+	Gamc []float64 // [nip] cumulative shear strain
+
+	gamcBkp []float64 // backup copy of Gamc
+	gamcAux []float64 // auxiliary copy of Gamc
+}
+
+// Init initialises data structure
+func (o *Liquefaction) Init(nip int) {
+	o.Pl0 = make([]float64, nip)
+	o.Sv0 = make([]float64, nip)
+	o.Gamc = make([]float64, nip)
+	o.gamcBkp = make([]float64, nip)
+	o.gamcAux = make([]float64, nip)
+}
+
+// SetIni sets initial values @ integration points
+//  pl -- [nip] liquid pressures
+func (o *Liquefaction) SetIni(u *solid.Solid, pl []float64) {
+	iv := u.Ndim - 1 // index of vertical stress component
+	for idx, s := range u.States {
+		o.Pl0[idx] = pl[idx]
+		o.Sv0[idx] = -s.Sig[iv]
+		o.Gamc[idx] = 0
+		o.gamcBkp[idx] = 0
+	}
+}
+
+// Update updates the cumulative shear strains
+//  Note: sol.ΔY corresponds to the increment since the last converged state; thus, the backup
+//        copy is used as starting value
+func (o *Liquefaction) Update(u *solid.Solid, sol *ele.Solution) (err error) {
+	for idx, _ := range u.IpsElem {
+		err = u.IpStrains(idx, sol)
+		if err != nil {
+			return
+		}
+		εv := u.DelEps[0] + u.DelEps[1] + u.DelEps[2]
+		var sum float64
+		for i, v := range u.DelEps {
+			d := v - εv*tsr.Im[i]/3.0
+			sum += d * d
+		}
+		o.Gamc[idx] = o.gamcBkp[idx] + math.Sqrt(2.0*sum/3.0)
+	}
+	return
+}
+
+// Backup creates copy of cumulative shear strains
+func (o *Liquefaction) Backup(aux bool) {
+	if aux {
+		copy(o.gamcAux, o.Gamc)
+		return
+	}
+	copy(o.gamcBkp, o.Gamc)
+}
+
+// Restore restores cumulative shear strains from copies
+func (o *Liquefaction) Restore(aux bool) {
+	if aux {
+		copy(o.Gamc, o.gamcAux)
+		return
+	}
+	copy(o.Gamc, o.gamcBkp)
+}
+
+// OutIpVals sets the integration points' values corresponding to LiqKeys
+//  pl -- current liquid pressure @ ip
+func (o *Liquefaction) OutIpVals(M *ele.IpsMap, idx, nip int, pl float64) {
+	Δpl := pl - o.Pl0[idx]
+	ru := 0.0
+	if o.Sv0[idx] > 0 {
+		ru = Δpl / o.Sv0[idx]
+	}
+	M.Set("Dpl", idx, nip, Δpl)
+	M.Set("ru", idx, nip, ru)
+	M.Set("gamc", idx, nip, o.Gamc[idx])
+}
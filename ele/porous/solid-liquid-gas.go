@@ -312,15 +312,18 @@ func (o *SolidLiquidGas) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 		}
 	}
 
-	// external forces
+	// external forces and natural boundary conditions
+	return o.AddNatBcsToRhs(fb, sol)
+}
+
+// AddNatBcsToRhs adds surface loads and natural boundary conditions to rhs
+func (o *SolidLiquidGas) AddNatBcsToRhs(fb []float64, sol *ele.Solution) (err error) {
 	if len(o.U.NatBcs) > 0 {
 		err = o.U.AddSurfLoadsToRhs(fb, sol)
 		if err != nil {
 			return
 		}
 	}
-
-	// contribution from natural boundary conditions
 	if len(o.P.NatBcs) > 0 {
 		return o.P.AddNatBcsToRhs(fb, sol)
 	}
@@ -791,9 +794,6 @@ func (o *SolidLiquidGas) AddNatBcsToJac(sol *ele.Solution) (err error) {
 	var pl, fl, plmax, g, rmp float64
 	for idx, nbc := range o.P.NatBcs {
 
-		// plmax shift
-		shift = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for jdx, ipf := range o.P.IpsFace {
 
@@ -803,6 +803,10 @@ func (o *SolidLiquidGas) AddNatBcsToJac(sol *ele.Solution) (err error) {
 			if err != nil {
 				return
 			}
+
+			// plmax shift @ face ip
+			shift = ele.NatBcValue(nbc.Fcn, sol.T, o.P.Cell.Shp, o.P.X, iface)
+
 			Sf := o.P.Cell.Shp.Sf
 			Jf := la.VecNorm(o.P.Cell.Shp.Fnvec)
 			coef := ipf[3] * Jf
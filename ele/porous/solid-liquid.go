@@ -10,9 +10,11 @@ import (
 	"github.com/cpmech/gofem/ele/seepage"
 	"github.com/cpmech/gofem/ele/solid"
 	"github.com/cpmech/gofem/inp"
+	mdlsolid "github.com/cpmech/gofem/mdl/solid"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/tsr"
 	"github.com/cpmech/gosl/utl"
@@ -39,6 +41,13 @@ type SolidLiquid struct {
 	U *solid.Solid    // u-element
 	P *seepage.Liquid // p-element
 
+	// swelling
+	Swell *mdlsolid.Swelling // swelling/shrinkage model; nil if solid has no swelling parameters
+	dσsw  []float64          // [nsig] ∂σe/∂εsw・∂εsw/∂pl: derivative of effective stress w.r.t pl due to swelling
+
+	// excess pore-liquid pressures and cumulative shear strains
+	Liq *Liquefaction // liquefaction data; nil if element has no "!liq:1" flag
+
 	// scratchpad. computed @ each ip
 	divus float64     // divus
 	bs    []float64   // bs = as - g = α1・u - ζs - g; (Eqs 35b and A.1 [1]) with 'as' being the acceleration of solids and g, gravity
@@ -48,6 +57,13 @@ type SolidLiquid struct {
 
 	// for seepage face derivatives
 	dρldus_ex [][]float64 // [nverts][nverts*ndim] ∂ρl/∂us extrapolted to nodes => if has qb (flux)
+
+	// staggered solution (fixed-stress split)
+	split  int         // stage of staggered solution; see ele.SplitFlow and ele.SplitMech
+	fsβ    float64     // fixed-stress coefficient β
+	fsSlot int         // current slot of recorded pressures
+	fsL    [][]float64 // [nslots][nip] fixed-stress coefficients L = β・sl/K_dr; nil => empty slot
+	fsPl   [][]float64 // [nslots][nip] recorded pressures pl_k @ ips
 }
 
 // initialisation ///////////////////////////////////////////////////////////////////////////////////
@@ -124,6 +140,29 @@ func init() {
 		}
 		o.P = p_elem.(*seepage.Liquid)
 
+		// swelling
+		mat := sim.MatModels.GetCell(edat.Mat, cell)
+		if mat == nil {
+			chk.Panic("cannot find material %q for solid-liquid element {tag=%d, id=%d}\n", edat.Mat, cell.Tag, cell.Id)
+		}
+		var err error
+		o.Swell, err = mdlsolid.NewSwelling(mat.SldPrms)
+		if err != nil {
+			chk.Panic("cannot allocate swelling model for solid-liquid element {tag=%d, id=%d}:\n%v", cell.Tag, cell.Id, err)
+		}
+		if o.Swell != nil {
+			nip := len(o.U.IpsElem)
+			o.U.EigV = make([]float64, nip)
+			o.U.ΔEigV = make([]float64, nip)
+			o.dσsw = make([]float64, 2*o.Ndim)
+		}
+
+		// excess pore-liquid pressures and cumulative shear strains
+		if s_liq, found := io.Keycode(edat.Extra, "liq"); found && io.Atob(s_liq) {
+			o.Liq = new(Liquefaction)
+			o.Liq.Init(len(o.U.IpsElem))
+		}
+
 		// scratchpad. computed @ each ip
 		o.bs = make([]float64, o.Ndim)
 		o.hl = make([]float64, o.Ndim)
@@ -267,6 +306,9 @@ func (o *SolidLiquid) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 		for m := 0; m < p_nverts; m++ {
 			r = o.P.Pmap[m]
 			fb[r] -= coef * Sb[m] * (O.Cpl*plt + O.Cvs*divvs)
+			if o.split == ele.SplitFlow { // fixed-stress term
+				fb[r] -= coef * Sb[m] * O.Cvs * α4 * o.fsL[o.fsSlot][idx] * (o.P.Pl - o.fsPl[o.fsSlot][idx])
+			}
 			for i := 0; i < o.Ndim; i++ {
 				fb[r] += coef * Gb[m][i] * o.P.Rhowl[i] // += coef * div(ρl*wl)
 			}
@@ -307,15 +349,18 @@ func (o *SolidLiquid) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 		}
 	}
 
-	// external forces
+	// external forces and natural boundary conditions
+	return o.AddNatBcsToRhs(fb, sol)
+}
+
+// AddNatBcsToRhs adds surface loads and natural boundary conditions to rhs
+func (o *SolidLiquid) AddNatBcsToRhs(fb []float64, sol *ele.Solution) (err error) {
 	if len(o.U.NatBcs) > 0 {
 		err = o.U.AddSurfLoadsToRhs(fb, sol)
 		if err != nil {
 			return
 		}
 	}
-
-	// contribution from natural boundary conditions
 	if len(o.P.NatBcs) > 0 {
 		return o.P.AddNatBcsToRhs(fb, sol)
 	}
@@ -427,6 +472,9 @@ func (o *SolidLiquid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (
 
 				// add ∂rlb/dpl^n: Eq (A.5) of [1]
 				o.P.Kpp[m][n] += coef * Sb[m] * Sb[n] * (O.DCpldpl*plt + O.DCvsdpl*divvs + β1*O.Cpl)
+				if o.split == ele.SplitFlow { // fixed-stress term
+					o.P.Kpp[m][n] += coef * Sb[m] * Sb[n] * O.Cvs * α4 * o.fsL[o.fsSlot][idx]
+				}
 
 				// add ∂(ρl.wl)/∂us^m: Eq (A.7) of [1]
 				for i := 0; i < o.Ndim; i++ {
@@ -473,6 +521,32 @@ func (o *SolidLiquid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (
 		} else {
 			solid.IpAddToKt(o.U.K, u_nverts, o.Ndim, coef, G, o.U.D)
 		}
+
+		// Kup: add swelling term ∂(σe・G^m)/∂pl^n with ∂σe/∂pl = -D・(I/3)・∂εsw/∂pl
+		if o.Swell != nil {
+			err = o.swelling_dσdpl(idx)
+			if err != nil {
+				return
+			}
+			for m := 0; m < u_nverts; m++ {
+				for i := 0; i < o.Ndim; i++ {
+					r = i + m*o.Ndim
+					var v float64
+					if o.U.UseB {
+						for k := 0; k < len(o.dσsw); k++ {
+							v += o.U.B[k][r] * o.dσsw[k]
+						}
+					} else {
+						for j := 0; j < o.Ndim; j++ {
+							v += tsr.M2T(o.dσsw, i, j) * G[m][j]
+						}
+					}
+					for n := 0; n < p_nverts; n++ {
+						o.Kup[r][n] += coef * v * Sb[n]
+					}
+				}
+			}
+		}
 	}
 
 	// contribution from natural boundary conditions
@@ -493,6 +567,8 @@ func (o *SolidLiquid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (
 	//   |  Kpu Kpp Kpf  |
 	//   |_ Kfu Kfp Kff _|
 	//
+	//  Note: Kup and Kpu are replaced by zeros in the stages of the staggered solution
+	//
 	for i, I := range o.P.Pmap {
 		for j, J := range o.P.Pmap {
 			Kb.Put(I, J, o.P.Kpp[i][j])
@@ -502,6 +578,11 @@ func (o *SolidLiquid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (
 			Kb.Put(J, I, o.P.Kfp[j][i])
 		}
 		for j, J := range o.U.Umap {
+			if o.split != ele.SplitNone {
+				Kb.Put(I, J, 0) // keep sparsity pattern
+				Kb.Put(J, I, 0)
+				continue
+			}
 			Kb.Put(I, J, o.Kpu[i][j])
 			Kb.Put(J, I, o.Kup[j][i])
 		}
@@ -521,11 +602,77 @@ func (o *SolidLiquid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (
 
 // Update perform (tangent) update
 func (o *SolidLiquid) Update(sol *ele.Solution) (err error) {
-	err = o.U.Update(sol)
+
+	// p-element first because swelling strains depend on the liquid retention state
+	err = o.P.Update(sol)
 	if err != nil {
 		return
 	}
-	return o.P.Update(sol)
+
+	// swelling strains
+	if o.Swell != nil {
+		err = o.swelling_strains(sol)
+		if err != nil {
+			return
+		}
+	}
+	err = o.U.Update(sol)
+	if err != nil || o.Liq == nil {
+		return
+	}
+	return o.Liq.Update(o.U, sol)
+}
+
+// SetSplit sets the stage of the staggered solution (fixed-stress split) and the slot of recorded
+// pressures pl_k used by the flow stage
+func (o *SolidLiquid) SetSplit(stage, slot int, β float64, sol *ele.Solution) (err error) {
+	o.split = stage
+	o.fsβ = β
+	if stage == ele.SplitNone {
+		o.fsL, o.fsPl = nil, nil
+		return
+	}
+	o.fsSlot = slot
+	if stage == ele.SplitFlow && (slot >= len(o.fsL) || o.fsL[slot] == nil) {
+		return o.RecordSplit(slot, sol)
+	}
+	return
+}
+
+// RecordSplit records the pressures pl_k and the fixed-stress coefficients L = β・sl/K_dr @ ips
+//  Note: K_dr is the bulk modulus computed from the first tangent (elastic) modulus D
+func (o *SolidLiquid) RecordSplit(slot int, sol *ele.Solution) (err error) {
+	nip := len(o.U.IpsElem)
+	for len(o.fsL) <= slot {
+		o.fsL = append(o.fsL, nil)
+		o.fsPl = append(o.fsPl, nil)
+	}
+	if o.fsL[slot] == nil {
+		o.fsL[slot] = make([]float64, nip)
+		o.fsPl[slot] = make([]float64, nip)
+	}
+	for idx, _ := range o.U.IpsElem {
+		err = o.ipvars(idx, sol)
+		if err != nil {
+			return
+		}
+		err = o.U.MdlSmall.CalcD(o.U.D, o.U.States[idx], true)
+		if err != nil {
+			return
+		}
+		var Kdr float64
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				Kdr += o.U.D[i][j] / 9.0
+			}
+		}
+		if Kdr <= 0 {
+			return chk.Err("SolidLiquid: eid=%d: drained bulk modulus must be positive for the fixed-stress split. K_dr = %g", o.Id(), Kdr)
+		}
+		o.fsL[slot][idx] = o.fsβ * o.P.States[idx].A_sl / Kdr
+		o.fsPl[slot][idx] = o.P.Pl
+	}
+	return
 }
 
 // internal variables ///////////////////////////////////////////////////////////////////////////////
@@ -579,7 +726,27 @@ func (o *SolidLiquid) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (er
 	}
 
 	// set u-element
-	return o.U.SetIniIvs(sol, ivs)
+	err = o.U.SetIniIvs(sol, ivs)
+	if err != nil {
+		return
+	}
+
+	// initial values for liquefaction analyses
+	if o.Liq == nil {
+		return
+	}
+	pl := make([]float64, len(o.U.IpsElem))
+	for idx, ip := range o.U.IpsElem {
+		err = o.P.Cell.Shp.CalcAtIp(o.P.X, ip, false)
+		if err != nil {
+			return
+		}
+		for m := 0; m < o.P.Cell.Shp.Nverts; m++ {
+			pl[idx] += o.P.Cell.Shp.S[m] * sol.Y[o.P.Pmap[m]]
+		}
+	}
+	o.Liq.SetIni(o.U, pl)
+	return
 }
 
 // BackupIvs create copy of internal variables
@@ -588,6 +755,9 @@ func (o *SolidLiquid) BackupIvs(aux bool) (err error) {
 	if err != nil {
 		return
 	}
+	if o.Liq != nil {
+		o.Liq.Backup(aux)
+	}
 	return o.P.BackupIvs(aux)
 }
 
@@ -597,6 +767,9 @@ func (o *SolidLiquid) RestoreIvs(aux bool) (err error) {
 	if err != nil {
 		return
 	}
+	if o.Liq != nil {
+		o.Liq.Restore(aux)
+	}
 	return o.P.RestoreIvs(aux)
 }
 
@@ -629,12 +802,18 @@ func (o *SolidLiquid) Ureset(sol *ele.Solution) (err error) {
 // writer ///////////////////////////////////////////////////////////////////////////////////////////
 
 // Encode encodes internal variables
+//  Note: the liquefaction data is encoded after the states of the p-element if "!liq:1";
+//        thus, files saved without this flag keep the same format of u-p elements
 func (o *SolidLiquid) Encode(enc utl.Encoder) (err error) {
 	err = o.U.Encode(enc)
 	if err != nil {
 		return
 	}
-	return o.P.Encode(enc)
+	err = o.P.Encode(enc)
+	if err != nil || o.Liq == nil {
+		return
+	}
+	return enc.Encode(o.Liq)
 }
 
 // Decode decodes internal variables
@@ -643,7 +822,16 @@ func (o *SolidLiquid) Decode(dec utl.Decoder) (err error) {
 	if err != nil {
 		return
 	}
-	return o.P.Decode(dec)
+	err = o.P.Decode(dec)
+	if err != nil || o.Liq == nil {
+		return
+	}
+	err = dec.Decode(o.Liq)
+	if err != nil {
+		return chk.Err("cannot decode liquefaction data of solid-liquid element {tag=%d, id=%d}. results must be saved with the \"!liq:1\" flag:\n%v", o.Cell.Tag, o.Cell.Id, err)
+	}
+	o.Liq.Backup(false)
+	return
 }
 
 // OutIpCoords returns the coordinates of integration points
@@ -654,6 +842,9 @@ func (o *SolidLiquid) OutIpCoords() (C [][]float64) {
 // OutIpKeys returns the integration points' keys
 func (o *SolidLiquid) OutIpKeys() []string {
 	keys := append(o.U.OutIpKeys(), "nf", "pl", "sl", "pc", "RhoL")
+	if o.Liq != nil {
+		keys = append(keys, LiqKeys()...)
+	}
 	return append(keys, seepage.LiqFlowKeys(o.Ndim)...)
 }
 
@@ -676,6 +867,9 @@ func (o *SolidLiquid) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
 		M.Set("sl", idx, nip, sl)
 		M.Set("pc", idx, nip, -o.P.Pl)
 		M.Set("RhoL", idx, nip, ρL)
+		if o.Liq != nil {
+			o.Liq.OutIpVals(M, idx, nip, o.P.Pl)
+		}
 		for i := 0; i < o.Ndim; i++ {
 			var nwl_i float64
 			for j := 0; j < o.Ndim; j++ {
@@ -687,8 +881,72 @@ func (o *SolidLiquid) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
 	return
 }
 
+// LiqMass returns the mass of liquid in element (∫ρl dV) computed with the current states
+func (o *SolidLiquid) LiqMass(sol *ele.Solution) (mass float64, err error) {
+	O := o.P.LsVars
+	for idx, ip := range o.U.IpsElem {
+		err = o.ipvars(idx, sol)
+		if err != nil {
+			return
+		}
+		err = o.P.Mdl.CalcLs(O, o.P.States[idx], o.P.Pl, o.divus, false)
+		if err != nil {
+			return
+		}
+		coef := o.U.Cell.Shp.J * ip[3]
+		if sol.Axisym {
+			coef *= o.U.Cell.Shp.AxisymGetRadius(o.U.X)
+		}
+		mass += coef * O.A_ρl
+	}
+	return
+}
+
 // auxiliary ////////////////////////////////////////////////////////////////////////////////////////
 
+// swelling_strains computes the volumetric swelling strains @ ips of u-element
+//  Note: the corresponding term of Kup is computed with swelling_dσdpl
+func (o *SolidLiquid) swelling_strains(sol *ele.Solution) (err error) {
+	var pl, plOld, εv, εvOld float64
+	for idx, ip := range o.U.IpsElem {
+
+		// pl @ ip at the end and beginning of increment
+		err = o.P.Cell.Shp.CalcAtIp(o.P.X, ip, false)
+		if err != nil {
+			return
+		}
+		pl, plOld = 0, 0
+		for m := 0; m < o.P.Cell.Shp.Nverts; m++ {
+			r := o.P.Pmap[m]
+			pl += o.P.Cell.Shp.S[m] * sol.Y[r]
+			plOld += o.P.Cell.Shp.S[m] * (sol.Y[r] - sol.ΔY[r])
+		}
+
+		// volumetric strains; pc = -pl
+		εv = o.Swell.VolStrain(o.P.States[idx].A_sl, -pl)
+		εvOld = o.Swell.VolStrain(o.P.StatesBkp[idx].A_sl, -plOld)
+		o.U.EigV[idx] = εv
+		o.U.ΔEigV[idx] = εv - εvOld
+	}
+	return
+}
+
+// swelling_dσdpl computes the derivative of the effective stress w.r.t pl due to swelling @ ip
+//  Note: o.U.D and o.P.Pl must have been computed @ ip already. εsw depends on pl directly (suction)
+//        and via sl; thus dsl/dpc consistent with the update of the liquid retention model is used
+func (o *SolidLiquid) swelling_dσdpl(idx int) (err error) {
+	pc := -o.P.Pl
+	Cc, err := o.P.Mdl.Ccb(o.P.States[idx], pc)
+	if err != nil {
+		return
+	}
+	dεdpl := -o.Swell.DVolStrainDpc(Cc, pc)
+	for k := 0; k < len(o.dσsw); k++ {
+		o.dσsw[k] = -(o.U.D[k][0] + o.U.D[k][1] + o.U.D[k][2]) * dεdpl / 3.0
+	}
+	return
+}
+
 // ipvars computes current values @ integration points. idx == index of integration point
 func (o *SolidLiquid) ipvars(idx int, sol *ele.Solution) (err error) {
 
@@ -746,9 +1004,6 @@ func (o *SolidLiquid) AddNatBcsToJac(sol *ele.Solution) (err error) {
 	var pl, fl, plmax, g, rmp float64
 	for idx, nbc := range o.P.NatBcs {
 
-		// plmax shift
-		shift = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for jdx, ipf := range o.P.IpsFace {
 
@@ -758,6 +1013,10 @@ func (o *SolidLiquid) AddNatBcsToJac(sol *ele.Solution) (err error) {
 			if err != nil {
 				return
 			}
+
+			// plmax shift @ face ip
+			shift = ele.NatBcValue(nbc.Fcn, sol.T, o.P.Cell.Shp, o.P.X, iface)
+
 			Sf := o.P.Cell.Shp.Sf
 			Jf := la.VecNorm(o.P.Cell.Shp.Fnvec)
 			coef := ipf[3] * Jf
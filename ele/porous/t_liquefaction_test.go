@@ -0,0 +1,176 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package porous
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// liq_element allocates a solid-liquid element with the "!liq:1" flag. It returns also the
+// number of equations
+func liq_element(tst *testing.T) (e *SolidLiquid, neq int) {
+
+	// load sim => mesh => edat => cell
+	sim := inp.ReadSim("data/solid-liquid.sim", "", true, 0)
+	msh := sim.Regions[0].Msh
+	edat := sim.Regions[0].ElemsData[0]
+	cell := msh.Cells[0]
+
+	// allocate element
+	allocator := ele.GetAllocator("solid-liquid")
+	e = allocator(sim, cell, edat, ele.BuildCoordsMatrix(cell, msh)).(*SolidLiquid)
+	if e.Liq == nil {
+		tst.Errorf("liquefaction data must be allocated with the \"!liq:1\" flag\n")
+		return
+	}
+	e.SetEqs([][]int{
+		{0, 1, 2},
+		{3, 4, 5},
+		{6, 7, 8},
+		{9, 10, 11},
+		{12, 13},
+		{14, 15},
+		{16, 17},
+		{18, 19},
+		{20, 21},
+	}, nil)
+	neq = 22
+
+	// states with geostatic stresses: σv = -γ・depth, σh = K0・σv
+	nip := len(e.U.IpsElem)
+	e.U.States = make([]*solid.State, nip)
+	for idx, _ := range e.U.IpsElem {
+		e.U.States[idx] = solid.NewState(4, 0, false, false)
+		σv := -20.0 * float64(idx+1)
+		e.U.States[idx].Sig[0] = 0.5 * σv
+		e.U.States[idx].Sig[1] = σv
+		e.U.States[idx].Sig[2] = 0.5 * σv
+	}
+	return
+}
+
+func Test_liq01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("liq01. excess pore-liquid pressure and ru")
+
+	// element and initial values
+	e, _ := liq_element(tst)
+	if tst.Failed() {
+		return
+	}
+	nip := len(e.U.IpsElem)
+	pl0 := make([]float64, nip)
+	for idx, _ := range pl0 {
+		pl0[idx] = 10.0 * float64(idx+1)
+	}
+	e.Liq.SetIni(e.U, pl0)
+	for idx, _ := range e.U.IpsElem {
+		chk.Scalar(tst, io.Sf("Pl0[%d]", idx), 1e-15, e.Liq.Pl0[idx], pl0[idx])
+		chk.Scalar(tst, io.Sf("Sv0[%d]", idx), 1e-15, e.Liq.Sv0[idx], 20.0*float64(idx+1))
+		chk.Scalar(tst, io.Sf("Gamc[%d]", idx), 1e-15, e.Liq.Gamc[idx], 0)
+	}
+
+	// build-up of pore-liquid pressures until full liquefaction (Δpl = σ'v0)
+	M := ele.NewIpsMap()
+	for _, frac := range []float64{0, 0.25, 0.5, 1} {
+		for idx, _ := range e.U.IpsElem {
+			sv0 := 20.0 * float64(idx+1)
+			e.Liq.OutIpVals(M, idx, nip, pl0[idx]+frac*sv0)
+			chk.Scalar(tst, io.Sf("Dpl[%d]", idx), 1e-13, M.Get("Dpl", idx), frac*sv0)
+			chk.Scalar(tst, io.Sf("ru[%d]", idx), 1e-15, M.Get("ru", idx), frac)
+		}
+	}
+
+	// dissipation: pl smaller than initial value gives negative ratio
+	e.Liq.OutIpVals(M, 0, nip, pl0[0]-5)
+	chk.Scalar(tst, "Dpl (dissipation)", 1e-15, M.Get("Dpl", 0), -5)
+	chk.Scalar(tst, "ru  (dissipation)", 1e-15, M.Get("ru", 0), -5.0/20.0)
+
+	// zero initial vertical effective stress => ru is not defined and must be zero
+	e.Liq.Sv0[0] = 0
+	e.Liq.OutIpVals(M, 0, nip, pl0[0]+5)
+	chk.Scalar(tst, "ru (σ'v0=0)", 1e-15, M.Get("ru", 0), 0)
+}
+
+func Test_liq02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("liq02. cumulative shear strain")
+
+	// element and initial values
+	e, neq := liq_element(tst)
+	if tst.Failed() {
+		return
+	}
+	nip := len(e.U.IpsElem)
+	e.Liq.SetIni(e.U, make([]float64, nip))
+
+	// simple shear increment, Δux = γ・y, plus in-plane compression Δεxx = Δεyy = a
+	γ, a := 0.002, 0.001
+	sol := &ele.Solution{Y: make([]float64, neq), ΔY: make([]float64, neq)}
+	X := e.U.X
+	for m := 0; m < e.U.Cell.Shp.Nverts; m++ {
+		ux, uy := e.U.Umap[m*2], e.U.Umap[m*2+1]
+		sol.ΔY[ux] = γ*X[1][m] + a*X[0][m]
+		sol.ΔY[uy] = a * X[1][m]
+		sol.Y[ux] = sol.ΔY[ux]
+		sol.Y[uy] = sol.ΔY[uy]
+	}
+
+	// first step: dev(Δε) = {a/3, a/3, -2a/3, γ/√2} (Mandel) in plane-strain
+	//  => γc = √(2/3)・|dev(Δε)| = √(2/3・(2a²/3 + γ²/2))
+	err := e.Liq.Update(e.U, sol)
+	if err != nil {
+		tst.Errorf("update failed: %v\n", err)
+		return
+	}
+	γc := math.Sqrt(2.0 * (2.0*a*a/3.0 + γ*γ/2.0) / 3.0)
+	for idx, _ := range e.U.IpsElem {
+		chk.Scalar(tst, io.Sf("Gamc[%d] (1)", idx), 1e-15, e.Liq.Gamc[idx], γc)
+	}
+
+	// iterations within the same step do not accumulate
+	err = e.Liq.Update(e.U, sol)
+	if err != nil {
+		tst.Errorf("update failed: %v\n", err)
+		return
+	}
+	for idx, _ := range e.U.IpsElem {
+		chk.Scalar(tst, io.Sf("Gamc[%d] (1b)", idx), 1e-15, e.Liq.Gamc[idx], γc)
+	}
+
+	// converged step => reversed shear increment accumulates
+	e.Liq.Backup(false)
+	for i, _ := range sol.ΔY {
+		sol.ΔY[i] = -sol.ΔY[i]
+	}
+	err = e.Liq.Update(e.U, sol)
+	if err != nil {
+		tst.Errorf("update failed: %v\n", err)
+		return
+	}
+	for idx, _ := range e.U.IpsElem {
+		chk.Scalar(tst, io.Sf("Gamc[%d] (2)", idx), 1e-15, e.Liq.Gamc[idx], 2*γc)
+	}
+
+	// restore
+	e.Liq.Restore(false)
+	for idx, _ := range e.U.IpsElem {
+		chk.Scalar(tst, io.Sf("Gamc[%d] (restored)", idx), 1e-15, e.Liq.Gamc[idx], γc)
+	}
+
+	// output
+	M := ele.NewIpsMap()
+	e.Liq.OutIpVals(M, 0, nip, 0)
+	chk.Scalar(tst, "gamc", 1e-15, M.Get("gamc", 0), γc)
+}
@@ -43,9 +43,11 @@ type LiquidGas struct {
 	States    []*porous.State
 	StatesBkp []*porous.State
 	StatesAux []*porous.State
+	Soa       bool // states are stored in contiguous arrays; see porous.PackStates
 
 	// gravity
-	Gfcn fun.Func // gravity function
+	Gfcn fun.Func   // gravity function
+	Afcn []fun.Func // [ndim] body accelerations a(t,x) added to gravity; evaluated at the centre of element
 
 	// natural boundary conditions
 	NatBcs []*ele.NaturalBc // natural boundary conditions
@@ -148,6 +150,7 @@ func init() {
 		o.X = x
 		o.Np = o.Cell.Shp.Nverts
 		o.Ndim = sim.Ndim
+		o.Soa = sim.Data.SoA
 
 		// integration points
 		var err error
@@ -158,7 +161,7 @@ func init() {
 		nip := len(o.IpsElem)
 
 		// model
-		mat := sim.MatModels.Get(edat.Mat)
+		mat := sim.MatModels.GetCell(edat.Mat, cell)
 		if mat == nil {
 			chk.Panic("cannot get model for p-element {tag=%d id=%d material=%q}:\n%v", cell.Tag, cell.Id, edat.Mat, err)
 		}
@@ -278,6 +281,16 @@ func (o *LiquidGas) SetEleConds(key string, f fun.Func, extra string) (err error
 	if key == "g" { // gravity
 		o.Gfcn = f
 	}
+	if key == "ax" || key == "ay" || key == "az" { // body accelerations; e.g. ramped gravity
+		i := int(key[1] - 'x')
+		if i >= o.Ndim {
+			return chk.Err("body acceleration %q cannot be set in %dD", key, o.Ndim)
+		}
+		if o.Afcn == nil {
+			o.Afcn = make([]fun.Func, o.Ndim)
+		}
+		o.Afcn[i] = f
+	}
 	return
 }
 
@@ -623,6 +636,13 @@ func (o *LiquidGas) SetIniIvs(sol *ele.Solution, ignored map[string][]float64) (
 		o.StatesAux[idx] = o.States[idx].GetCopy()
 	}
 
+	// structure-of-arrays
+	if o.Soa {
+		o.States = porous.PackStates(o.States)
+		o.StatesBkp = porous.PackStates(o.StatesBkp)
+		o.StatesAux = porous.PackStates(o.StatesAux)
+	}
+
 	// seepage face structures
 	if o.HasSeep {
 		o.Plmax = la.MatAlloc(len(o.NatBcs), len(o.IpsFace))
@@ -694,6 +714,9 @@ func (o *LiquidGas) Decode(dec utl.Decoder) (err error) {
 	if err != nil {
 		return
 	}
+	if o.Soa {
+		o.States = porous.PackStates(o.States)
+	}
 	return o.BackupIvs(false)
 }
 
@@ -797,9 +820,6 @@ func (o *LiquidGas) AddNatBcsToRhs(fb []float64, sol *ele.Solution) (err error)
 	var ρl, ρg, pl, fl, plmax, g, rmp, rx, rf float64
 	for idx, nbc := range o.NatBcs {
 
-		// tmp := plmax shift or qlb
-		tmp = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for jdx, ipf := range o.IpsFace {
 
@@ -809,6 +829,10 @@ func (o *LiquidGas) AddNatBcsToRhs(fb []float64, sol *ele.Solution) (err error)
 			if err != nil {
 				return
 			}
+
+			// tmp := plmax shift or qlb @ face ip
+			tmp = ele.NatBcValue(nbc.Fcn, sol.T, o.Cell.Shp, o.X, iface)
+
 			Sf := o.Cell.Shp.Sf
 			Jf := la.VecNorm(o.Cell.Shp.Fnvec)
 			coef := ipf[3] * Jf
@@ -883,9 +907,6 @@ func (o *LiquidGas) AddNatBcsToJac(sol *ele.Solution) (err error) {
 	var drxdpl, drxdfl, drfdpl, drfdfl float64
 	for idx, nbc := range o.NatBcs {
 
-		// plmax shift
-		shift = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for jdx, ipf := range o.IpsFace {
 
@@ -895,6 +916,10 @@ func (o *LiquidGas) AddNatBcsToJac(sol *ele.Solution) (err error) {
 			if err != nil {
 				return
 			}
+
+			// plmax shift @ face ip
+			shift = ele.NatBcValue(nbc.Fcn, sol.T, o.Cell.Shp, o.X, iface)
+
 			Sf := o.Cell.Shp.Sf
 			Jf := la.VecNorm(o.Cell.Shp.Fnvec)
 			coef := ipf[3] * Jf
@@ -955,10 +980,26 @@ func (o *LiquidGas) RampDeriv(x float64) float64 {
 	return fun.SrampD1(x, o.BetRmp)
 }
 
-// ComputeGrav computes gravity vector @ time t
+// ComputeGrav computes gravity vector @ time t; including body accelerations
 func (o *LiquidGas) ComputeGrav(t float64) {
-	o.Grav[o.Ndim-1] = 0
+	for i := 0; i < o.Ndim; i++ {
+		o.Grav[i] = 0
+	}
 	if o.Gfcn != nil {
 		o.Grav[o.Ndim-1] = -o.Gfcn.F(t, nil)
 	}
+	if o.Afcn != nil {
+		xc := make([]float64, o.Ndim)
+		nverts := o.Cell.Shp.Nverts
+		for i := 0; i < o.Ndim; i++ {
+			for m := 0; m < nverts; m++ {
+				xc[i] += o.X[i][m] / float64(nverts)
+			}
+		}
+		for i, f := range o.Afcn {
+			if f != nil {
+				o.Grav[i] += f.F(t, xc)
+			}
+		}
+	}
 }
@@ -47,9 +47,11 @@ type Liquid struct {
 	States    []*porous.State
 	StatesBkp []*porous.State
 	StatesAux []*porous.State
+	Soa       bool // states are stored in contiguous arrays; see porous.PackStates
 
 	// gravity
-	Gfcn fun.Func // gravity function
+	Gfcn fun.Func   // gravity function
+	Afcn []fun.Func // [ndim] body accelerations a(t,x) added to gravity; evaluated at the centre of element
 
 	// natural boundary conditions
 	NatBcs []*ele.NaturalBc // natural boundary conditions
@@ -140,6 +142,7 @@ func init() {
 		o.X = x
 		o.Np = o.Cell.Shp.Nverts
 		o.Ndim = sim.Ndim
+		o.Soa = sim.Data.SoA
 
 		// integration points
 		var err error
@@ -150,7 +153,7 @@ func init() {
 		nip := len(o.IpsElem)
 
 		// model
-		mat := sim.MatModels.Get(edat.Mat)
+		mat := sim.MatModels.GetCell(edat.Mat, cell)
 		if mat == nil {
 			chk.Panic("cannot get model for p-element {tag=%d id=%d material=%q}:\n%v", cell.Tag, cell.Id, edat.Mat, err)
 		}
@@ -257,6 +260,16 @@ func (o *Liquid) SetEleConds(key string, f fun.Func, extra string) (err error) {
 	if key == "g" { // gravity
 		o.Gfcn = f
 	}
+	if key == "ax" || key == "ay" || key == "az" { // body accelerations; e.g. ramped gravity
+		i := int(key[1] - 'x')
+		if i >= o.Ndim {
+			return chk.Err("body acceleration %q cannot be set in %dD", key, o.Ndim)
+		}
+		if o.Afcn == nil {
+			o.Afcn = make([]fun.Func, o.Ndim)
+		}
+		o.Afcn[i] = f
+	}
 	return
 }
 
@@ -532,6 +545,13 @@ func (o *Liquid) SetIniIvs(sol *ele.Solution, ignored map[string][]float64) (err
 		o.StatesAux[idx] = o.States[idx].GetCopy()
 	}
 
+	// structure-of-arrays
+	if o.Soa {
+		o.States = porous.PackStates(o.States)
+		o.StatesBkp = porous.PackStates(o.StatesBkp)
+		o.StatesAux = porous.PackStates(o.StatesAux)
+	}
+
 	// seepage face structures
 	if o.HasSeep {
 		o.Plmax = la.MatAlloc(len(o.NatBcs), len(o.IpsFace))
@@ -603,6 +623,9 @@ func (o *Liquid) Decode(dec utl.Decoder) (err error) {
 	if err != nil {
 		return
 	}
+	if o.Soa {
+		o.States = porous.PackStates(o.States)
+	}
 	return o.BackupIvs(false)
 }
 
@@ -644,6 +667,23 @@ func (o *Liquid) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
 	}
 }
 
+// LiqMass returns the mass of liquid in element (∫ρl dV) computed with the current states
+func (o *Liquid) LiqMass(sol *ele.Solution) (mass float64, err error) {
+	O := o.LsVars
+	for idx, ip := range o.IpsElem {
+		err = o.CalcIpVars(idx, sol)
+		if err != nil {
+			return
+		}
+		err = o.Mdl.CalcLs(O, o.States[idx], o.Pl, 0, false)
+		if err != nil {
+			return
+		}
+		mass += o.Cell.Shp.J * ip[3] * O.A_ρl
+	}
+	return
+}
+
 // auxiliary ////////////////////////////////////////////////////////////////////////////////////////
 
 // CalcIpVars computes current values @ integration points. idx == index of integration point
@@ -695,9 +735,6 @@ func (o *Liquid) AddNatBcsToRhs(fb []float64, sol *ele.Solution) (err error) {
 	var ρl, pl, fl, plmax, g, rmp, rx, rf float64
 	for idx, nbc := range o.NatBcs {
 
-		// tmp := plmax shift or qlb
-		tmp = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for jdx, ipf := range o.IpsFace {
 
@@ -707,6 +744,10 @@ func (o *Liquid) AddNatBcsToRhs(fb []float64, sol *ele.Solution) (err error) {
 			if err != nil {
 				return
 			}
+
+			// tmp := plmax shift or qlb @ face ip
+			tmp = ele.NatBcValue(nbc.Fcn, sol.T, o.Cell.Shp, o.X, iface)
+
 			Sf := o.Cell.Shp.Sf
 			Jf := la.VecNorm(o.Cell.Shp.Fnvec)
 			coef := ipf[3] * Jf
@@ -771,9 +812,6 @@ func (o *Liquid) AddNatBcsToJac(sol *ele.Solution) (err error) {
 	var drxdpl, drxdfl, drfdpl, drfdfl float64
 	for idx, nbc := range o.NatBcs {
 
-		// plmax shift
-		shift = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for jdx, ipf := range o.IpsFace {
 
@@ -783,6 +821,10 @@ func (o *Liquid) AddNatBcsToJac(sol *ele.Solution) (err error) {
 			if err != nil {
 				return
 			}
+
+			// plmax shift @ face ip
+			shift = ele.NatBcValue(nbc.Fcn, sol.T, o.Cell.Shp, o.X, iface)
+
 			Sf := o.Cell.Shp.Sf
 			Jf := la.VecNorm(o.Cell.Shp.Fnvec)
 			coef := ipf[3] * Jf
@@ -843,10 +885,26 @@ func (o *Liquid) RampDeriv(x float64) float64 {
 	return fun.SrampD1(x, o.BetRmp)
 }
 
-// ComputeGrav computes gravity vector @ time t
+// ComputeGrav computes gravity vector @ time t; including body accelerations
 func (o *Liquid) ComputeGrav(t float64) {
-	o.Grav[o.Ndim-1] = 0
+	for i := 0; i < o.Ndim; i++ {
+		o.Grav[i] = 0
+	}
 	if o.Gfcn != nil {
 		o.Grav[o.Ndim-1] = -o.Gfcn.F(t, nil)
 	}
+	if o.Afcn != nil {
+		xc := make([]float64, o.Ndim)
+		nverts := o.Cell.Shp.Nverts
+		for i := 0; i < o.Ndim; i++ {
+			for m := 0; m < nverts; m++ {
+				xc[i] += o.X[i][m] / float64(nverts)
+			}
+		}
+		for i, f := range o.Afcn {
+			if f != nil {
+				o.Grav[i] += f.F(t, xc)
+			}
+		}
+	}
 }
@@ -189,3 +189,16 @@ func IpBmatrix_sparse(B *la.Triplet, ndim, nne int, G [][]float64, radius float6
 		B.Put(3, 1+i*2, G[i][0]/SQ2)
 	}
 }
+
+// kmat_quadform returns aᵀ K b where the rows and columns of K correspond to the global equations
+// in rmap and cmap, respectively
+func kmat_quadform(K [][]float64, rmap, cmap []int, a, b []float64) (res float64) {
+	for i, I := range rmap {
+		var Kb float64
+		for j, J := range cmap {
+			Kb += K[i][j] * b[J]
+		}
+		res += a[I] * Kb
+	}
+	return
+}
@@ -0,0 +1,298 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"sort"
+
+	"github.com/cpmech/gofem/ele"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// Device evaluates the kernels of batches of identical solid elements; e.g. on a GPU
+type Device interface {
+	Init(b *Batch) error      // allocates memory on device and uploads the constant data of batch
+	Stiffness(b *Batch) error // computes b.K and b.Jmin from b.D
+	Forces(b *Batch) error    // computes b.F from b.Sig
+	Free()                    // frees memory on device
+}
+
+// devallocators holds all available devices
+var devallocators = make(map[string]func() Device)
+
+// NewDevice returns a new device
+//  name -- "cpu" (reference implementation with goroutines) or "opencl" (requires the "opencl" build tag)
+func NewDevice(name string) (Device, error) {
+	if allocator, ok := devallocators[name]; ok {
+		return allocator(), nil
+	}
+	if name == "opencl" {
+		return nil, chk.Err("device %q is not available: gofem must be compiled with the \"opencl\" build tag; e.g. go install -tags opencl", name)
+	}
+	return nil, chk.Err("cannot find device named %q", name)
+}
+
+// Batch holds identical solid elements (same shape, integration points and thickness) whose
+// stiffness matrices and internal forces are evaluated together by a Device. Arrays are flat
+// (row-major) to be copied to/from the device
+//  Note: the consistent tangent matrices D and the stresses σ are computed by the material models
+//        on the CPU; the device computes Jacobians, gradients, K = Σ Bᵀ・D・B・J・w and F = Σ Bᵀ・σ・J・w
+type Batch struct {
+	Elems  []*Solid  // elements in batch
+	Ndim   int       // space dimension
+	Nverts int       // number of vertices of each element
+	Nip    int       // number of integration points of each element
+	Nsig   int       // number of stress components
+	Nu     int       // number of displacement dofs of each element
+	DSdR   []float64 // [nip][nverts][ndim] derivatives of shape functions w.r.t natural coordinates @ ips
+	W      []float64 // [nip] weights of ips multiplied by thickness
+	X      []float64 // [nelem][nverts][ndim] coordinates of vertices
+	D      []float64 // [nelem][nip][nsig][nsig] consistent tangent matrices
+	Sig    []float64 // [nelem][nip][nsig] stresses
+	K      []float64 // [nelem][nu][nu] stiffness matrices
+	F      []float64 // [nelem][nu] internal forces
+	Jmin   []float64 // [nelem] minimum determinant of Jacobian at ips
+	Dev    Device    // device
+	Rhs    []bool    // [nelem] element internal forces are the only terms in its right-hand side
+}
+
+// Batches holds all batches of a domain
+type Batches struct {
+	List    []*Batch        // batches
+	batched map[*Solid]int  // element => index in List
+	rhs     map[*Solid]bool // elements whose right-hand side is computed by batches
+}
+
+// NewBatches groups the solid elements that can be evaluated by a device
+//  elems  -- all elements of domain
+//  device -- name of device; see NewDevice
+//  steady -- steady analysis
+//  Note: elements with B-matrix (axisymmetric), contact, XFEM, hourglass control, NURBS or large
+//        deformation models are not batched. In transient analyses, only quasi-static elements are
+//        batched. The element's AddToRhs is replaced only if there are no body forces, body
+//        accelerations, gravity or surface loads
+func NewBatches(elems []ele.Element, device string, steady bool) (o *Batches, err error) {
+	o = new(Batches)
+	o.batched = make(map[*Solid]int)
+	o.rhs = make(map[*Solid]bool)
+	groups := make(map[string]*Batch)
+	var keys []string
+	for _, e := range elems {
+		s, ok := e.(*Solid)
+		if !ok || !s.batchable(steady) {
+			continue
+		}
+		key := io.Sf("%s_%d_%g", s.Cell.Shp.Type, len(s.IpsElem), s.Thickness)
+		b, found := groups[key]
+		if !found {
+			b = new(Batch)
+			groups[key] = b
+			keys = append(keys, key)
+		}
+		b.Elems = append(b.Elems, s)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b := groups[key]
+		err = b.init(device)
+		if err != nil {
+			o.Free()
+			return nil, err
+		}
+		for k, s := range b.Elems {
+			o.batched[s] = len(o.List)
+			if b.Rhs[k] {
+				o.rhs[s] = true
+			}
+		}
+		o.List = append(o.List, b)
+	}
+	return
+}
+
+// Has tells whether the stiffness matrix of element e is computed by a batch
+func (o *Batches) Has(e ele.Element) bool {
+	if o == nil {
+		return false
+	}
+	s, ok := e.(*Solid)
+	if !ok {
+		return false
+	}
+	_, ok = o.batched[s]
+	return ok
+}
+
+// HasRhs tells whether the contribution of element e to the right-hand side is computed by a batch
+func (o *Batches) HasRhs(e ele.Element) bool {
+	if o == nil {
+		return false
+	}
+	s, ok := e.(*Solid)
+	return ok && o.rhs[s]
+}
+
+// AddToKb computes the stiffness matrices of all batches and adds them to Kb
+func (o *Batches) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+	if o == nil {
+		return
+	}
+	for _, b := range o.List {
+		err = b.add_to_kb(Kb, firstIt)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// AddToRhs computes the internal forces of batched elements (see HasRhs) and adds them to fb
+func (o *Batches) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
+	if o == nil {
+		return
+	}
+	for _, b := range o.List {
+		err = b.add_to_rhs(fb)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Free frees memory on devices
+func (o *Batches) Free() {
+	if o == nil {
+		return
+	}
+	for _, b := range o.List {
+		if b.Dev != nil {
+			b.Dev.Free()
+		}
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// batchable tells whether this element can be evaluated by a device
+func (o *Solid) batchable(steady bool) bool {
+	if o.UseB || o.HasContact || o.Xfem || o.HgCoef > 0 || o.MdlSmall == nil || o.Tdep != nil {
+		return false
+	}
+	if o.Cell.Shp.Nurbs != nil || o.Cell.Shp.Func == nil || o.Cell.Shp.Gndim != o.Ndim {
+		return false
+	}
+	return steady || o.Qsta
+}
+
+// init sets constant data and initialises device
+func (o *Batch) init(device string) (err error) {
+	s := o.Elems[0]
+	shape := s.Cell.Shp
+	o.Ndim = s.Ndim
+	o.Nverts = shape.Nverts
+	o.Nip = len(s.IpsElem)
+	o.Nsig = 2 * o.Ndim
+	o.Nu = o.Ndim * o.Nverts
+	ne := len(o.Elems)
+
+	// shape functions derivatives and weights
+	S := make([]float64, o.Nverts)
+	dSdR := la.MatAlloc(o.Nverts, o.Ndim)
+	o.DSdR = make([]float64, o.Nip*o.Nverts*o.Ndim)
+	o.W = make([]float64, o.Nip)
+	for p, ip := range s.IpsElem {
+		shape.Func(S, dSdR, ip, true, -1)
+		for m := 0; m < o.Nverts; m++ {
+			for k := 0; k < o.Ndim; k++ {
+				o.DSdR[(p*o.Nverts+m)*o.Ndim+k] = dSdR[m][k]
+			}
+		}
+		o.W[p] = ip[3] * s.Thickness
+	}
+
+	// coordinates and flags
+	o.X = make([]float64, ne*o.Nverts*o.Ndim)
+	o.Rhs = make([]bool, ne)
+	for e, el := range o.Elems {
+		for m := 0; m < o.Nverts; m++ {
+			for i := 0; i < o.Ndim; i++ {
+				o.X[(e*o.Nverts+m)*o.Ndim+i] = el.X[i][m]
+			}
+		}
+		o.Rhs[e] = el.Gfcn == nil && el.Bfcn == nil && el.Afcn == nil && len(el.NatBcs) == 0
+	}
+
+	// workspace
+	o.D = make([]float64, ne*o.Nip*o.Nsig*o.Nsig)
+	o.Sig = make([]float64, ne*o.Nip*o.Nsig)
+	o.K = make([]float64, ne*o.Nu*o.Nu)
+	o.F = make([]float64, ne*o.Nu)
+	o.Jmin = make([]float64, ne)
+
+	// device
+	o.Dev, err = NewDevice(device)
+	if err != nil {
+		return
+	}
+	return o.Dev.Init(o)
+}
+
+// add_to_kb computes the stiffness matrices on device and adds them to Kb
+func (o *Batch) add_to_kb(Kb *la.Triplet, firstIt bool) (err error) {
+	nn := o.Nsig * o.Nsig
+	for e, el := range o.Elems {
+		for p := 0; p < o.Nip; p++ {
+			err = el.MdlSmall.CalcD(el.D, el.States[p], firstIt)
+			if err != nil {
+				return
+			}
+			for i := 0; i < o.Nsig; i++ {
+				copy(o.D[(e*o.Nip+p)*nn+i*o.Nsig:], el.D[i])
+			}
+		}
+	}
+	err = o.Dev.Stiffness(o)
+	if err != nil {
+		return
+	}
+	nuu := o.Nu * o.Nu
+	for e, el := range o.Elems {
+		if o.Jmin[e] < 0 {
+			return chk.Err("Solid: eid=%d: Jacobian is negative = %g\n", el.Id(), o.Jmin[e])
+		}
+		for i, I := range el.Umap {
+			for j, J := range el.Umap {
+				Kb.Put(I, J, o.K[e*nuu+i*o.Nu+j])
+			}
+		}
+	}
+	return
+}
+
+// add_to_rhs computes the internal forces on device and adds them to fb
+func (o *Batch) add_to_rhs(fb []float64) (err error) {
+	for e, el := range o.Elems {
+		for p := 0; p < o.Nip; p++ {
+			copy(o.Sig[(e*o.Nip+p)*o.Nsig:], el.States[p].Sig)
+		}
+	}
+	err = o.Dev.Forces(o)
+	if err != nil {
+		return
+	}
+	for e, el := range o.Elems {
+		if !o.Rhs[e] {
+			continue
+		}
+		for i, I := range el.Umap {
+			fb[I] -= o.F[e*o.Nu+i]
+		}
+	}
+	return
+}
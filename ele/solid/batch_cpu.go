@@ -0,0 +1,213 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// DevCpu implements the reference device: the kernels are evaluated by goroutines on the CPU.
+// Results must be the same as the ones computed by the elements (see Solid.AddToKb)
+type DevCpu struct {
+	Nworkers int // number of goroutines; 0 => runtime.NumCPU()
+}
+
+// add device to factory
+func init() {
+	devallocators["cpu"] = func() Device { return new(DevCpu) }
+}
+
+// Init initialises device
+func (o *DevCpu) Init(b *Batch) (err error) {
+	if b.Ndim != 2 && b.Ndim != 3 {
+		return chk.Err("device can only handle 2D or 3D elements. ndim = %d is invalid", b.Ndim)
+	}
+	return
+}
+
+// Stiffness computes the stiffness matrices
+func (o *DevCpu) Stiffness(b *Batch) (err error) {
+	o.run(b, true)
+	return
+}
+
+// Forces computes the internal forces
+func (o *DevCpu) Forces(b *Batch) (err error) {
+	o.run(b, false)
+	return
+}
+
+// Free does nothing
+func (o *DevCpu) Free() {}
+
+// run evaluates the kernels of all elements in batch
+func (o *DevCpu) run(b *Batch, stiff bool) {
+	nw := o.Nworkers
+	if nw < 1 {
+		nw = runtime.NumCPU()
+	}
+	var wg sync.WaitGroup
+	ne := len(b.Elems)
+	for w := 0; w < nw; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			ker := new_batch_kernel(b)
+			for e := w; e < ne; e += nw {
+				ker.eval(b, e, stiff)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// batch_kernel holds the scratchpad of the element kernel
+type batch_kernel struct {
+	dxdR, dRdx [][]float64 // [ndim][ndim] Jacobian matrix and its inverse
+	G          [][]float64 // [nverts][ndim] derivatives of shape functions w.r.t real coordinates
+	B          [][]float64 // [nsig][nu] B matrix (Mandel's representation)
+	DB         []float64   // [nsig] auxiliary: row of D・B
+}
+
+// new_batch_kernel allocates a new kernel scratchpad
+func new_batch_kernel(b *Batch) (o *batch_kernel) {
+	alloc := func(m, n int) (a [][]float64) {
+		a = make([][]float64, m)
+		for i := 0; i < m; i++ {
+			a[i] = make([]float64, n)
+		}
+		return
+	}
+	return &batch_kernel{alloc(b.Ndim, b.Ndim), alloc(b.Ndim, b.Ndim), alloc(b.Nverts, b.Ndim), alloc(b.Nsig, b.Nu), make([]float64, b.Nsig)}
+}
+
+// eval computes the stiffness matrix (stiff == true) or the internal forces of element e
+func (o *batch_kernel) eval(b *Batch, e int, stiff bool) {
+	nd, nv, ns, nu := b.Ndim, b.Nverts, b.Nsig, b.Nu
+	X := b.X[e*nv*nd:]
+	if stiff {
+		K := b.K[e*nu*nu : (e+1)*nu*nu]
+		for i := range K {
+			K[i] = 0
+		}
+		b.Jmin[e] = math.Inf(1)
+	} else {
+		F := b.F[e*nu : (e+1)*nu]
+		for i := range F {
+			F[i] = 0
+		}
+	}
+	for p := 0; p < b.Nip; p++ {
+
+		// Jacobian
+		dSdR := b.DSdR[p*nv*nd:]
+		for i := 0; i < nd; i++ {
+			for k := 0; k < nd; k++ {
+				o.dxdR[i][k] = 0
+				for m := 0; m < nv; m++ {
+					o.dxdR[i][k] += X[m*nd+i] * dSdR[m*nd+k]
+				}
+			}
+		}
+		J := batch_inv(o.dRdx, o.dxdR, nd)
+		if stiff && J < b.Jmin[e] {
+			b.Jmin[e] = J
+		}
+
+		// G and B matrices
+		for m := 0; m < nv; m++ {
+			for i := 0; i < nd; i++ {
+				o.G[m][i] = 0
+				for k := 0; k < nd; k++ {
+					o.G[m][i] += dSdR[m*nd+k] * o.dRdx[k][i]
+				}
+			}
+		}
+		batch_bmatrix(o.B, o.G, nd, nv)
+		coef := J * b.W[p]
+
+		// K += coef・Bᵀ・D・B
+		if stiff {
+			D := b.D[(e*b.Nip+p)*ns*ns:]
+			K := b.K[e*nu*nu:]
+			for c := 0; c < nu; c++ {
+				for s := 0; s < ns; s++ {
+					o.DB[s] = 0
+					for t := 0; t < ns; t++ {
+						o.DB[s] += D[s*ns+t] * o.B[t][c]
+					}
+				}
+				for r := 0; r < nu; r++ {
+					var sum float64
+					for s := 0; s < ns; s++ {
+						sum += o.B[s][r] * o.DB[s]
+					}
+					K[r*nu+c] += coef * sum
+				}
+			}
+			continue
+		}
+
+		// F += coef・Bᵀ・σ
+		σ := b.Sig[(e*b.Nip+p)*ns:]
+		F := b.F[e*nu:]
+		for r := 0; r < nu; r++ {
+			var sum float64
+			for s := 0; s < ns; s++ {
+				sum += o.B[s][r] * σ[s]
+			}
+			F[r] += coef * sum
+		}
+	}
+}
+
+// batch_inv computes the inverse of a 2x2 or 3x3 matrix and returns its determinant
+func batch_inv(ai, a [][]float64, nd int) (det float64) {
+	if nd == 2 {
+		det = a[0][0]*a[1][1] - a[0][1]*a[1][0]
+		ai[0][0], ai[0][1] = a[1][1]/det, -a[0][1]/det
+		ai[1][0], ai[1][1] = -a[1][0]/det, a[0][0]/det
+		return
+	}
+	det = a[0][0]*(a[1][1]*a[2][2]-a[1][2]*a[2][1]) - a[0][1]*(a[1][0]*a[2][2]-a[1][2]*a[2][0]) + a[0][2]*(a[1][0]*a[2][1]-a[1][1]*a[2][0])
+	ai[0][0] = (a[1][1]*a[2][2] - a[1][2]*a[2][1]) / det
+	ai[0][1] = (a[0][2]*a[2][1] - a[0][1]*a[2][2]) / det
+	ai[0][2] = (a[0][1]*a[1][2] - a[0][2]*a[1][1]) / det
+	ai[1][0] = (a[1][2]*a[2][0] - a[1][0]*a[2][2]) / det
+	ai[1][1] = (a[0][0]*a[2][2] - a[0][2]*a[2][0]) / det
+	ai[1][2] = (a[0][2]*a[1][0] - a[0][0]*a[1][2]) / det
+	ai[2][0] = (a[1][0]*a[2][1] - a[1][1]*a[2][0]) / det
+	ai[2][1] = (a[0][1]*a[2][0] - a[0][0]*a[2][1]) / det
+	ai[2][2] = (a[0][0]*a[1][1] - a[0][1]*a[1][0]) / det
+	return
+}
+
+// batch_bmatrix computes the B matrix in Mandel's representation: ε = B・u with the order of
+// components {xx, yy, zz, xy, yz, zx}; see IpAddToKt and IpStrains
+func batch_bmatrix(B, G [][]float64, nd, nv int) {
+	for s := range B {
+		for c := range B[s] {
+			B[s][c] = 0
+		}
+	}
+	for m := 0; m < nv; m++ {
+		c := m * nd
+		B[0][c+0] = G[m][0]
+		B[1][c+1] = G[m][1]
+		B[3][c+0] = G[m][1] / SQ2
+		B[3][c+1] = G[m][0] / SQ2
+		if nd == 3 {
+			B[2][c+2] = G[m][2]
+			B[4][c+1] = G[m][2] / SQ2
+			B[4][c+2] = G[m][1] / SQ2
+			B[5][c+0] = G[m][2] / SQ2
+			B[5][c+2] = G[m][0] / SQ2
+		}
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build opencl
+
+package solid
+
+/*
+#cgo linux LDFLAGS: -lOpenCL
+#cgo darwin LDFLAGS: -framework OpenCL
+#define CL_TARGET_OPENCL_VERSION 120
+#define CL_USE_DEPRECATED_OPENCL_1_2_APIS
+#ifdef __APPLE__
+#include <OpenCL/opencl.h>
+#else
+#include <CL/cl.h>
+#endif
+#include <stdlib.h>
+
+typedef struct {
+	cl_context       ctx;
+	cl_command_queue queue;
+	cl_program       prog;
+	cl_kernel        kstiff, kforce;
+	cl_mem           dsdr, w, x, d, sig, k, f, jmin;
+	int              ne;
+	size_t           nd, nsig, nk, nf;
+} gofem_ocl;
+
+static int ocl_init(gofem_ocl *o, const char *src, const char *opts, int ne,
+		double *dsdr, size_t ndsdr, double *w, size_t nw, double *x, size_t nx,
+		size_t nd, size_t nsig, size_t nk, size_t nf, char *log, size_t nlog) {
+	cl_int err;
+	cl_platform_id platform;
+	cl_device_id device;
+	o->ne = ne;
+	o->nd = nd; o->nsig = nsig; o->nk = nk; o->nf = nf;
+	err = clGetPlatformIDs(1, &platform, NULL);
+	if (err != CL_SUCCESS) return err;
+	err = clGetDeviceIDs(platform, CL_DEVICE_TYPE_GPU, 1, &device, NULL);
+	if (err != CL_SUCCESS) {
+		err = clGetDeviceIDs(platform, CL_DEVICE_TYPE_DEFAULT, 1, &device, NULL);
+		if (err != CL_SUCCESS) return err;
+	}
+	o->ctx = clCreateContext(NULL, 1, &device, NULL, NULL, &err);
+	if (err != CL_SUCCESS) return err;
+	o->queue = clCreateCommandQueue(o->ctx, device, 0, &err);
+	if (err != CL_SUCCESS) return err;
+	o->prog = clCreateProgramWithSource(o->ctx, 1, &src, NULL, &err);
+	if (err != CL_SUCCESS) return err;
+	err = clBuildProgram(o->prog, 1, &device, opts, NULL, NULL);
+	if (err != CL_SUCCESS) {
+		clGetProgramBuildInfo(o->prog, device, CL_PROGRAM_BUILD_LOG, nlog, log, NULL);
+		return err;
+	}
+	o->kstiff = clCreateKernel(o->prog, "stiffness", &err);
+	if (err != CL_SUCCESS) return err;
+	o->kforce = clCreateKernel(o->prog, "forces", &err);
+	if (err != CL_SUCCESS) return err;
+	o->dsdr = clCreateBuffer(o->ctx, CL_MEM_READ_ONLY | CL_MEM_COPY_HOST_PTR, ndsdr*sizeof(double), dsdr, &err);
+	if (err != CL_SUCCESS) return err;
+	o->w = clCreateBuffer(o->ctx, CL_MEM_READ_ONLY | CL_MEM_COPY_HOST_PTR, nw*sizeof(double), w, &err);
+	if (err != CL_SUCCESS) return err;
+	o->x = clCreateBuffer(o->ctx, CL_MEM_READ_ONLY | CL_MEM_COPY_HOST_PTR, nx*sizeof(double), x, &err);
+	if (err != CL_SUCCESS) return err;
+	o->d = clCreateBuffer(o->ctx, CL_MEM_READ_ONLY, nd*sizeof(double), NULL, &err);
+	if (err != CL_SUCCESS) return err;
+	o->sig = clCreateBuffer(o->ctx, CL_MEM_READ_ONLY, nsig*sizeof(double), NULL, &err);
+	if (err != CL_SUCCESS) return err;
+	o->k = clCreateBuffer(o->ctx, CL_MEM_WRITE_ONLY, nk*sizeof(double), NULL, &err);
+	if (err != CL_SUCCESS) return err;
+	o->f = clCreateBuffer(o->ctx, CL_MEM_WRITE_ONLY, nf*sizeof(double), NULL, &err);
+	if (err != CL_SUCCESS) return err;
+	o->jmin = clCreateBuffer(o->ctx, CL_MEM_WRITE_ONLY, ne*sizeof(double), NULL, &err);
+	if (err != CL_SUCCESS) return err;
+	err  = clSetKernelArg(o->kstiff, 0, sizeof(cl_mem), &o->dsdr);
+	err |= clSetKernelArg(o->kstiff, 1, sizeof(cl_mem), &o->w);
+	err |= clSetKernelArg(o->kstiff, 2, sizeof(cl_mem), &o->x);
+	err |= clSetKernelArg(o->kstiff, 3, sizeof(cl_mem), &o->d);
+	err |= clSetKernelArg(o->kstiff, 4, sizeof(cl_mem), &o->k);
+	err |= clSetKernelArg(o->kstiff, 5, sizeof(cl_mem), &o->jmin);
+	err |= clSetKernelArg(o->kstiff, 6, sizeof(int), &o->ne);
+	err |= clSetKernelArg(o->kforce, 0, sizeof(cl_mem), &o->dsdr);
+	err |= clSetKernelArg(o->kforce, 1, sizeof(cl_mem), &o->w);
+	err |= clSetKernelArg(o->kforce, 2, sizeof(cl_mem), &o->x);
+	err |= clSetKernelArg(o->kforce, 3, sizeof(cl_mem), &o->sig);
+	err |= clSetKernelArg(o->kforce, 4, sizeof(cl_mem), &o->f);
+	err |= clSetKernelArg(o->kforce, 5, sizeof(int), &o->ne);
+	return err;
+}
+
+static int ocl_stiffness(gofem_ocl *o, double *d, double *k, double *jmin) {
+	size_t global = (size_t)o->ne;
+	cl_int err = clEnqueueWriteBuffer(o->queue, o->d, CL_FALSE, 0, o->nd*sizeof(double), d, 0, NULL, NULL);
+	if (err != CL_SUCCESS) return err;
+	err = clEnqueueNDRangeKernel(o->queue, o->kstiff, 1, NULL, &global, NULL, 0, NULL, NULL);
+	if (err != CL_SUCCESS) return err;
+	err = clEnqueueReadBuffer(o->queue, o->k, CL_TRUE, 0, o->nk*sizeof(double), k, 0, NULL, NULL);
+	if (err != CL_SUCCESS) return err;
+	return clEnqueueReadBuffer(o->queue, o->jmin, CL_TRUE, 0, o->ne*sizeof(double), jmin, 0, NULL, NULL);
+}
+
+static int ocl_forces(gofem_ocl *o, double *sig, double *f) {
+	size_t global = (size_t)o->ne;
+	cl_int err = clEnqueueWriteBuffer(o->queue, o->sig, CL_FALSE, 0, o->nsig*sizeof(double), sig, 0, NULL, NULL);
+	if (err != CL_SUCCESS) return err;
+	err = clEnqueueNDRangeKernel(o->queue, o->kforce, 1, NULL, &global, NULL, 0, NULL, NULL);
+	if (err != CL_SUCCESS) return err;
+	return clEnqueueReadBuffer(o->queue, o->f, CL_TRUE, 0, o->nf*sizeof(double), f, 0, NULL, NULL);
+}
+
+static void ocl_free(gofem_ocl *o) {
+	if (o->dsdr)   clReleaseMemObject(o->dsdr);
+	if (o->w)      clReleaseMemObject(o->w);
+	if (o->x)      clReleaseMemObject(o->x);
+	if (o->d)      clReleaseMemObject(o->d);
+	if (o->sig)    clReleaseMemObject(o->sig);
+	if (o->k)      clReleaseMemObject(o->k);
+	if (o->f)      clReleaseMemObject(o->f);
+	if (o->jmin)   clReleaseMemObject(o->jmin);
+	if (o->kstiff) clReleaseKernel(o->kstiff);
+	if (o->kforce) clReleaseKernel(o->kforce);
+	if (o->prog)   clReleaseProgram(o->prog);
+	if (o->queue)  clReleaseCommandQueue(o->queue);
+	if (o->ctx)    clReleaseContext(o->ctx);
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// DevOpenCL implements a device using OpenCL (double precision); e.g. on GPUs. One work-item
+// evaluates one element. The sizes of the element are compiled into the kernels
+//  Note: (1) requires the "opencl" build tag, an OpenCL 1.2 runtime and devices with cl_khr_fp64
+//        (2) the first GPU of the first platform is selected; otherwise the default device
+type DevOpenCL struct {
+	ocl C.gofem_ocl // OpenCL data
+}
+
+// add device to factory
+func init() {
+	devallocators["opencl"] = func() Device { return new(DevOpenCL) }
+}
+
+// Init builds the kernels and uploads the constant data of batch
+func (o *DevOpenCL) Init(b *Batch) (err error) {
+	if b.Ndim != 2 && b.Ndim != 3 {
+		return chk.Err("device can only handle 2D or 3D elements. ndim = %d is invalid", b.Ndim)
+	}
+	ne := len(b.Elems)
+	src := C.CString(batch_opencl_src)
+	defer C.free(unsafe.Pointer(src))
+	opts := C.CString(io.Sf("-D NDIM=%d -D NVERTS=%d -D NIP=%d -D NSIG=%d -D NU=%d", b.Ndim, b.Nverts, b.Nip, b.Nsig, b.Nu))
+	defer C.free(unsafe.Pointer(opts))
+	nlog := 4096
+	log := (*C.char)(C.calloc(C.size_t(nlog), 1))
+	defer C.free(unsafe.Pointer(log))
+	status := C.ocl_init(&o.ocl, src, opts, C.int(ne),
+		(*C.double)(unsafe.Pointer(&b.DSdR[0])), C.size_t(len(b.DSdR)),
+		(*C.double)(unsafe.Pointer(&b.W[0])), C.size_t(len(b.W)),
+		(*C.double)(unsafe.Pointer(&b.X[0])), C.size_t(len(b.X)),
+		C.size_t(len(b.D)), C.size_t(len(b.Sig)), C.size_t(len(b.K)), C.size_t(len(b.F)),
+		log, C.size_t(nlog))
+	if status != 0 {
+		o.Free()
+		return chk.Err("cannot initialise OpenCL device. error code = %d\n%s", int(status), C.GoString(log))
+	}
+	return
+}
+
+// Stiffness computes the stiffness matrices
+func (o *DevOpenCL) Stiffness(b *Batch) (err error) {
+	status := C.ocl_stiffness(&o.ocl, (*C.double)(unsafe.Pointer(&b.D[0])), (*C.double)(unsafe.Pointer(&b.K[0])), (*C.double)(unsafe.Pointer(&b.Jmin[0])))
+	if status != 0 {
+		return chk.Err("OpenCL stiffness kernel failed. error code = %d", int(status))
+	}
+	return
+}
+
+// Forces computes the internal forces
+func (o *DevOpenCL) Forces(b *Batch) (err error) {
+	status := C.ocl_forces(&o.ocl, (*C.double)(unsafe.Pointer(&b.Sig[0])), (*C.double)(unsafe.Pointer(&b.F[0])))
+	if status != 0 {
+		return chk.Err("OpenCL forces kernel failed. error code = %d", int(status))
+	}
+	return
+}
+
+// Free frees memory on device
+func (o *DevOpenCL) Free() {
+	C.ocl_free(&o.ocl)
+	o.ocl = C.gofem_ocl{}
+}
+
+// batch_opencl_src holds the kernels; see batch_kernel.eval
+const batch_opencl_src = `
+#pragma OPENCL EXTENSION cl_khr_fp64 : enable
+#define SQ2 1.4142135623730951
+
+// gradients computes G and returns the determinant of the Jacobian
+double gradients(__global const double *dsdr, __global const double *X, double G[NVERTS][NDIM]) {
+	double a[NDIM][NDIM], ai[NDIM][NDIM], det;
+	for (int i = 0; i < NDIM; i++) {
+		for (int k = 0; k < NDIM; k++) {
+			a[i][k] = 0.0;
+			for (int m = 0; m < NVERTS; m++) a[i][k] += X[m*NDIM+i] * dsdr[m*NDIM+k];
+		}
+	}
+#if NDIM == 2
+	det = a[0][0]*a[1][1] - a[0][1]*a[1][0];
+	ai[0][0] =  a[1][1]/det;  ai[0][1] = -a[0][1]/det;
+	ai[1][0] = -a[1][0]/det;  ai[1][1] =  a[0][0]/det;
+#else
+	det = a[0][0]*(a[1][1]*a[2][2]-a[1][2]*a[2][1]) - a[0][1]*(a[1][0]*a[2][2]-a[1][2]*a[2][0]) + a[0][2]*(a[1][0]*a[2][1]-a[1][1]*a[2][0]);
+	ai[0][0] = (a[1][1]*a[2][2] - a[1][2]*a[2][1]) / det;
+	ai[0][1] = (a[0][2]*a[2][1] - a[0][1]*a[2][2]) / det;
+	ai[0][2] = (a[0][1]*a[1][2] - a[0][2]*a[1][1]) / det;
+	ai[1][0] = (a[1][2]*a[2][0] - a[1][0]*a[2][2]) / det;
+	ai[1][1] = (a[0][0]*a[2][2] - a[0][2]*a[2][0]) / det;
+	ai[1][2] = (a[0][2]*a[1][0] - a[0][0]*a[1][2]) / det;
+	ai[2][0] = (a[1][0]*a[2][1] - a[1][1]*a[2][0]) / det;
+	ai[2][1] = (a[0][1]*a[2][0] - a[0][0]*a[2][1]) / det;
+	ai[2][2] = (a[0][0]*a[1][1] - a[0][1]*a[1][0]) / det;
+#endif
+	for (int m = 0; m < NVERTS; m++) {
+		for (int i = 0; i < NDIM; i++) {
+			G[m][i] = 0.0;
+			for (int k = 0; k < NDIM; k++) G[m][i] += dsdr[m*NDIM+k] * ai[k][i];
+		}
+	}
+	return det;
+}
+
+// bmatrix computes the B matrix (Mandel's representation)
+void bmatrix(double B[NSIG][NU], double G[NVERTS][NDIM]) {
+	for (int s = 0; s < NSIG; s++) for (int c = 0; c < NU; c++) B[s][c] = 0.0;
+	for (int m = 0; m < NVERTS; m++) {
+		int c = m * NDIM;
+		B[0][c+0] = G[m][0];
+		B[1][c+1] = G[m][1];
+		B[3][c+0] = G[m][1] / SQ2;
+		B[3][c+1] = G[m][0] / SQ2;
+#if NDIM == 3
+		B[2][c+2] = G[m][2];
+		B[4][c+1] = G[m][2] / SQ2;
+		B[4][c+2] = G[m][1] / SQ2;
+		B[5][c+0] = G[m][2] / SQ2;
+		B[5][c+2] = G[m][0] / SQ2;
+#endif
+	}
+}
+
+__kernel void stiffness(__global const double *dsdr, __global const double *w, __global const double *x,
+		__global const double *D, __global double *K, __global double *jmin, const int ne) {
+	int e = get_global_id(0);
+	if (e >= ne) return;
+	__global const double *X = x + (size_t)e*NVERTS*NDIM;
+	__global double *Ke = K + (size_t)e*NU*NU;
+	double G[NVERTS][NDIM], B[NSIG][NU], DB[NSIG];
+	double jm = INFINITY;
+	for (int i = 0; i < NU*NU; i++) Ke[i] = 0.0;
+	for (int p = 0; p < NIP; p++) {
+		double J = gradients(dsdr + p*NVERTS*NDIM, X, G);
+		if (J < jm) jm = J;
+		bmatrix(B, G);
+		double coef = J * w[p];
+		__global const double *De = D + ((size_t)e*NIP+p)*NSIG*NSIG;
+		for (int c = 0; c < NU; c++) {
+			for (int s = 0; s < NSIG; s++) {
+				DB[s] = 0.0;
+				for (int t = 0; t < NSIG; t++) DB[s] += De[s*NSIG+t] * B[t][c];
+			}
+			for (int r = 0; r < NU; r++) {
+				double sum = 0.0;
+				for (int s = 0; s < NSIG; s++) sum += B[s][r] * DB[s];
+				Ke[r*NU+c] += coef * sum;
+			}
+		}
+	}
+	jmin[e] = jm;
+}
+
+__kernel void forces(__global const double *dsdr, __global const double *w, __global const double *x,
+		__global const double *sig, __global double *F, const int ne) {
+	int e = get_global_id(0);
+	if (e >= ne) return;
+	__global const double *X = x + (size_t)e*NVERTS*NDIM;
+	__global double *Fe = F + (size_t)e*NU;
+	double G[NVERTS][NDIM], B[NSIG][NU];
+	for (int i = 0; i < NU; i++) Fe[i] = 0.0;
+	for (int p = 0; p < NIP; p++) {
+		double J = gradients(dsdr + p*NVERTS*NDIM, X, G);
+		bmatrix(B, G);
+		double coef = J * w[p];
+		__global const double *s = sig + ((size_t)e*NIP+p)*NSIG;
+		for (int r = 0; r < NU; r++) {
+			double sum = 0.0;
+			for (int k = 0; k < NSIG; k++) sum += B[k][r] * s[k];
+			Fe[r] += coef * sum;
+		}
+	}
+}
+`
@@ -19,7 +19,8 @@ import (
 	"github.com/cpmech/gosl/utl"
 )
 
-// Beam represents a structural beam element (Euler-Bernoulli, linear elastic)
+// Beam represents a structural beam element (Euler-Bernoulli, linear elastic). 2D beams may have
+// concentrated plastic hinges at their ends (see init_hinges)
 //
 //  2D    y1     y2 is out-of-plane
 //         ^
@@ -46,6 +47,10 @@ import (
 //         | ,      | ,'
 //        (0)-------o' --------> y2
 //
+//  Note: (1) plastic hinges are rotational springs in series with the elastic beam; their moments
+//            are computed with LinkLaw (backbone curve and unloading rule)
+//        (2) distributed loads are converted to nodal loads; thus, the moments at hinges do not
+//            include fixed-end moments
 type Beam struct {
 
 	// basic data
@@ -94,6 +99,14 @@ type Beam struct {
 	ua   []float64 // [6] u aligned with beam system
 	ζe   []float64 // local ζ* vector
 	fxl  []float64 // local external force vector
+
+	// plastic hinges (2D)
+	Hinges []*LinkLaw      // [2] moment-rotation laws of hinges @ nodes 0 and 1 (nil entry => no hinge); nil => no hinges
+	Hst    *BeamHingeState // state of hinges
+	HstBkp *BeamHingeState // backup state of hinges
+	HstAux *BeamHingeState // auxiliary backup state of hinges
+	Kt     [][]float64     // [nu][nu] global tangent K matrix (with hinges)
+	Vofs   []float64       // [3] offsets of basic deformations due to reset of displacements
 }
 
 // register element
@@ -154,6 +167,12 @@ func init() {
 			}
 		}
 
+		// plastic hinges
+		err := o.init_hinges(sim, edat.Extra)
+		if err != nil {
+			chk.Panic("cannot initialise plastic hinges of beam {tag=%d, id=%d}:\n%v", cell.Tag, cell.Id, err)
+		}
+
 		// for output
 		o.Nstations = 11
 		if s_nsta, found := io.Keycode(edat.Extra, "nsta"); found {
@@ -252,15 +271,19 @@ func (o *Beam) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 		o.ue[i] = sol.Y[I]
 	}
 
-	// steady/dynamics
-	if sol.Steady {
+	// internal forces
+	if o.Hinges == nil {
 		la.MatVecMul(o.fi, 1, o.K, o.ue)
 	} else {
+		la.MatTrVecMul(o.fi, 1, o.hinge_B(), o.Hst.Q) // fi = trans(B) * q
+	}
+
+	// dynamics
+	if !sol.Steady {
 		α1 := sol.DynCfs.GetAlp1()
 		for i := 0; i < o.Nu; i++ {
-			o.fi[i] = 0
 			for j := 0; j < o.Nu; j++ {
-				o.fi[i] += o.M[i][j]*(α1*o.ue[j]-o.ζe[j]) + o.K[i][j]*o.ue[j]
+				o.fi[i] += o.M[i][j] * (α1*o.ue[j] - o.ζe[j])
 			}
 		}
 	}
@@ -299,10 +322,14 @@ func (o *Beam) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 
 // adds element K to global Jacobian matrix Kb
 func (o *Beam) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+	K := o.K
+	if o.Hinges != nil {
+		K = o.Kt
+	}
 	if sol.Steady {
 		for i, I := range o.Umap {
 			for j, J := range o.Umap {
-				Kb.Put(I, J, o.K[i][j])
+				Kb.Put(I, J, K[i][j])
 			}
 		}
 		return
@@ -310,20 +337,112 @@ func (o *Beam) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err err
 	α1 := sol.DynCfs.GetAlp1()
 	for i, I := range o.Umap {
 		for j, J := range o.Umap {
-			Kb.Put(I, J, o.M[i][j]*α1+o.K[i][j])
+			Kb.Put(I, J, o.M[i][j]*α1+K[i][j])
+		}
+	}
+	return
+}
+
+// QuadFormK computes aᵀ K b with the stiffness matrix (tangent if with hinges)
+//  Note: the contribution of the mass matrix in dynamic analyses is not included
+func (o *Beam) QuadFormK(a, b []float64) (res float64, ok bool) {
+	K := o.K
+	if o.Hinges != nil {
+		K = o.Kt
+	}
+	return kmat_quadform(K, o.Umap, o.Umap, a, b), true
+}
+
+// Update perform (tangent) update
+func (o *Beam) Update(sol *ele.Solution) (err error) {
+	if o.Hinges == nil {
+		return
+	}
+	return o.hinge_update(o.hinge_deformations(sol))
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *Beam) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
+	if o.Hinges == nil {
+		return
+	}
+	o.Hst = new_beam_hinge_state()
+	o.HstBkp = new_beam_hinge_state()
+	o.HstAux = new_beam_hinge_state()
+	for i, law := range o.Hinges {
+		if law != nil {
+			o.Hst.Hs[i] = *law.NewState()
 		}
 	}
+	err = o.hinge_update(o.hinge_deformations(sol))
+	if err != nil {
+		return
+	}
+	o.HstBkp.Set(o.Hst)
 	return
 }
 
+// BackupIvs create copy of internal variables
+func (o *Beam) BackupIvs(aux bool) (err error) {
+	if o.Hinges == nil {
+		return
+	}
+	if aux {
+		o.HstAux.Set(o.Hst)
+		return
+	}
+	o.HstBkp.Set(o.Hst)
+	return
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *Beam) RestoreIvs(aux bool) (err error) {
+	if o.Hinges == nil {
+		return
+	}
+	if aux {
+		o.Hst.Set(o.HstAux)
+		return
+	}
+	o.Hst.Set(o.HstBkp)
+	return
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *Beam) Ureset(sol *ele.Solution) (err error) {
+	if o.Hinges == nil {
+		return
+	}
+	copy(o.Vofs, o.hinge_deformations(sol)) // deformations before displacements are zeroed
+	return
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
 // Encode encodes internal variables
 func (o *Beam) Encode(enc utl.Encoder) (err error) {
-	return
+	if o.Hinges == nil {
+		return
+	}
+	return enc.Encode(o.Hst)
 }
 
 // Decode decodes internal variables
 func (o *Beam) Decode(dec utl.Decoder) (err error) {
-	return
+	if o.Hinges == nil {
+		return
+	}
+	if o.Hst == nil {
+		o.Hst, o.HstBkp, o.HstAux = new_beam_hinge_state(), new_beam_hinge_state(), new_beam_hinge_state()
+	}
+	err = dec.Decode(o.Hst)
+	if err != nil {
+		return
+	}
+	o.hinge_tangent()
+	return o.BackupIvs(false)
 }
 
 // OutIpCoords returns the coordinates of integration points
@@ -366,6 +485,26 @@ func (o *Beam) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
 	}
 }
 
+// MemberForces returns the internal forces at stations along the beam: N (axial force; tension
+// positive), V1 and M22 (2D) or N, M22, M11 and T00 (3D)
+//  Note: N is computed with the elongation of the beam; i.e. tangential distributed loads are not
+//        considered
+func (o *Beam) MemberForces(sol *ele.Solution) (F map[string][]float64) {
+	F = make(map[string][]float64)
+	if o.Ndim == 3 {
+		F["M22"], F["M11"], F["T00"] = o.CalcMoment3d(sol, 0, o.Nstations)
+	} else {
+		F["V1"] = o.CalcShearForce2d(sol, 0, o.Nstations)
+		F["M22"] = o.CalcMoment2d(sol, 0, o.Nstations)
+	}
+	N := o.Mdl.E * o.Mdl.A * (o.ua[o.Nu/2] - o.ua[0]) / o.L
+	F["N"] = make([]float64, o.Nstations)
+	for i := 0; i < o.Nstations; i++ {
+		F["N"][i] = N
+	}
+	return
+}
+
 // auxiliary ////////////////////////////////////////////////////////////////////////////////////////
 
 // Recompute re-compute matrices after dimensions or parameters are externally changed
@@ -498,9 +637,33 @@ func (o *Beam) Recompute(withM bool) {
 		// stiffness matrix in global system
 		la.MatTrMul3(o.K, 1, o.T, o.Kl, o.T) // K := 1 * trans(T) * Kl * T
 
-		// mass matrix
+		// mass matrix: consistent translational and torsional (polar moment I11 + I22) inertia
 		if withM {
-			chk.Panic("mass matrix is not available for 3D beams yet")
+			m := o.Mdl.GetRho() * o.Mdl.A * l / 420.0
+			mt := o.Mdl.GetRho() * (o.Mdl.I11 + o.Mdl.I22) * l / 6.0
+			la.MatFill(o.Ml, 0)
+			o.Ml[0][0], o.Ml[0][6], o.Ml[6][6] = 140.0*m, 70.0*m, 140.0*m
+			o.Ml[3][3], o.Ml[3][9], o.Ml[9][9] = 2.0*mt, mt, 2.0*mt
+
+			// bending about local r-axis (uy, rz)
+			o.Ml[1][1], o.Ml[1][5], o.Ml[1][7], o.Ml[1][11] = 156.0*m, 22.0*l*m, 54.0*m, -13.0*l*m
+			o.Ml[5][5], o.Ml[5][7], o.Ml[5][11] = 4.0*ll*m, 13.0*l*m, -3.0*ll*m
+			o.Ml[7][7], o.Ml[7][11] = 156.0*m, -22.0*l*m
+			o.Ml[11][11] = 4.0 * ll * m
+
+			// bending about local s-axis (uz, ry)
+			o.Ml[2][2], o.Ml[2][4], o.Ml[2][8], o.Ml[2][10] = 156.0*m, -22.0*l*m, 54.0*m, 13.0*l*m
+			o.Ml[4][4], o.Ml[4][8], o.Ml[4][10] = 4.0*ll*m, -13.0*l*m, -3.0*ll*m
+			o.Ml[8][8], o.Ml[8][10] = 156.0*m, 22.0*l*m
+			o.Ml[10][10] = 4.0 * ll * m
+
+			// symmetry
+			for i := 0; i < 12; i++ {
+				for j := i + 1; j < 12; j++ {
+					o.Ml[j][i] = o.Ml[i][j]
+				}
+			}
+			la.MatTrMul3(o.M, 1, o.T, o.Ml, o.T) // M := 1 * trans(T) * Ml * T
 		}
 		return
 	}
@@ -729,7 +892,11 @@ func (o *Beam) calc_moment2d_after_ua(time, ξ float64) (M22 float64) {
 	lll := ll * l
 
 	// bending moment
-	M22 = o.Mdl.E * o.Mdl.I22 * (o.ua[1]*((12.0*τ)/lll-6.0/ll) + o.ua[2]*((6.0*τ)/ll-4.0/l) + o.ua[4]*(6.0/ll-(12.0*τ)/lll) + o.ua[5]*((6.0*τ)/ll-2.0/l))
+	if o.Hinges == nil {
+		M22 = o.Mdl.E * o.Mdl.I22 * (o.ua[1]*((12.0*τ)/lll-6.0/ll) + o.ua[2]*((6.0*τ)/ll-4.0/l) + o.ua[4]*(6.0/ll-(12.0*τ)/lll) + o.ua[5]*((6.0*τ)/ll-2.0/l))
+	} else {
+		M22 = -o.Hst.Q[1]*(1.0-ξ) + o.Hst.Q[2]*ξ
+	}
 
 	// corrections due to applied loads
 	if o.Hasq {
@@ -754,7 +921,11 @@ func (o *Beam) calc_shearforce2d_after_ua(time, ξ float64) (V1 float64) {
 	lll := ll * l
 
 	// shear force
-	V1 = o.Mdl.E * o.Mdl.I22 * ((12.0*o.ua[1])/lll + (6.0*o.ua[2])/ll - (12.0*o.ua[4])/lll + (6.0*o.ua[5])/ll)
+	if o.Hinges == nil {
+		V1 = o.Mdl.E * o.Mdl.I22 * ((12.0*o.ua[1])/lll + (6.0*o.ua[2])/ll - (12.0*o.ua[4])/lll + (6.0*o.ua[5])/ll)
+	} else {
+		V1 = (o.Hst.Q[1] + o.Hst.Q[2]) / l
+	}
 
 	// corrections due to applied loads
 	if o.Hasq {
@@ -0,0 +1,245 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// BeamHingeState holds the state of a 2D beam with concentrated plastic hinges at its ends
+type BeamHingeState struct {
+	Hs []LinkState // [2] states of hinges @ nodes 0 and 1: rotation (D) and moment (F)
+	Q  []float64   // [3] basic forces: axial force and moments @ nodes 0 and 1
+	Kb [][]float64 // [3][3] tangent basic stiffness
+}
+
+// Set copies state
+func (o *BeamHingeState) Set(another *BeamHingeState) {
+	copy(o.Hs, another.Hs)
+	copy(o.Q, another.Q)
+	for i := 0; i < 3; i++ {
+		copy(o.Kb[i], another.Kb[i])
+	}
+}
+
+// new_beam_hinge_state allocates a new BeamHingeState
+func new_beam_hinge_state() *BeamHingeState {
+	return &BeamHingeState{
+		Hs: make([]LinkState, 2),
+		Q:  make([]float64, 3),
+		Kb: la.MatAlloc(3, 3),
+	}
+}
+
+// init_hinges initialises the plastic hinges of 2D beams
+//  Flags (extra):
+//   !hinges:both|left|right         -- position of hinges; left => node 0 and right => node 1
+//   !rule:elastic|kinematic|takeda  -- unloading rule (see LinkLaw). default = elastic
+//   !alp:val                        -- exponent of degradation of unloading stiffness (Takeda). default = 0.4
+//   !fm:name                        -- name of function with moment-rotation backbone M(θ)
+//   !km:val !fym:val !rm:val        -- or bilinear backbone: rotational stiffness, yield moment and hardening ratio
+//   !dym:val                        -- yield rotation (Takeda rule with function backbone)
+//   !fc:val ... !lp:val             -- or moment-curvature of fiber section (see NewFiberSection) with
+//                                      plastic hinge length lp; i.e. θ = κ・lp
+//   !npts:val                       -- number of points of moment-curvature curve on each side. default = 50
+func (o *Beam) init_hinges(sim *inp.Simulation, extra string) (err error) {
+
+	// position of hinges
+	pos, found := io.Keycode(extra, "hinges")
+	if !found {
+		return
+	}
+	if o.Ndim != 2 {
+		return chk.Err("plastic hinges are only available for 2D beams")
+	}
+
+	// law
+	rule := "elastic"
+	if val, found := io.Keycode(extra, "rule"); found {
+		rule = val
+	}
+	alp := 0.4
+	if val, found := io.Keycode(extra, "alp"); found {
+		alp = io.Atof(val)
+	}
+	var law *LinkLaw
+	if _, found := io.Keycode(extra, "fc"); found {
+		var sec *FiberSection
+		sec, err = NewFiberSection(extra, o.Mdl)
+		if err != nil {
+			return
+		}
+		var lp float64
+		if val, found := io.Keycode(extra, "lp"); found {
+			lp = io.Atof(val)
+		}
+		if lp <= 0 {
+			return chk.Err("length of plastic hinge must be given and positive (\"!lp\"). %g is invalid", lp)
+		}
+		npts := 50
+		if val, found := io.Keycode(extra, "npts"); found {
+			npts = io.Atoi(val)
+		}
+		D, F, dy := sec.Backbone(lp, npts)
+		law, err = NewLinkLawTable(D, F, dy, rule, alp)
+	} else {
+		law, err = NewLinkLaw(sim, extra, "m", rule, alp)
+		if err == nil && law == nil {
+			err = chk.Err("moment-rotation law of hinges must be given with \"!fm\", \"!km\" or \"!fc\" (fiber section)")
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	// hinges
+	o.Hinges = make([]*LinkLaw, 2)
+	switch pos {
+	case "both":
+		o.Hinges[0], o.Hinges[1] = law, law
+	case "left":
+		o.Hinges[0] = law
+	case "right":
+		o.Hinges[1] = law
+	default:
+		return chk.Err("position of hinges %q is invalid; options are \"both\", \"left\" and \"right\"", pos)
+	}
+	o.Kt = la.MatAlloc(o.Nu, o.Nu)
+	o.Vofs = make([]float64, 3)
+	return
+}
+
+// hinge_B computes the matrix B = Γ・T converting global displacements into basic deformations
+// (axial elongation and rotations relative to the chord at nodes 0 and 1)
+func (o *Beam) hinge_B() (B [][]float64) {
+	l := o.L
+	Γ := [][]float64{
+		{-1, 0, 0, 1, 0, 0},
+		{0, 1.0 / l, 1, 0, -1.0 / l, 0},
+		{0, 1.0 / l, 0, 0, -1.0 / l, 1},
+	}
+	B = la.MatAlloc(3, o.Nu)
+	la.MatMul(B, 1, Γ, o.T)
+	return
+}
+
+// hinge_deformations computes the basic deformations
+func (o *Beam) hinge_deformations(sol *ele.Solution) (v []float64) {
+	B := o.hinge_B()
+	v = make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		v[i] = o.Vofs[i]
+		for j, J := range o.Umap {
+			v[i] += B[i][j] * sol.Y[J]
+		}
+	}
+	return
+}
+
+// hinge_tangent computes the global tangent stiffness Kt = trans(B)・Kb・B
+func (o *Beam) hinge_tangent() {
+	B := o.hinge_B()
+	la.MatTrMul3(o.Kt, 1, B, o.Hst.Kb, B)
+}
+
+// hinge_update updates the state of hinges for given basic deformations v by solving the local
+// equilibrium between the moments of the elastic part of the beam and the moments of hinges
+//  Note: the states of hinges in Hst must correspond to the last converged state
+func (o *Beam) hinge_update(v []float64) (err error) {
+
+	// elastic basic stiffness
+	EI, l := o.Mdl.E*o.Mdl.I22, o.L
+	ke := [][]float64{{4.0 * EI / l, 2.0 * EI / l}, {2.0 * EI / l, 4.0 * EI / l}}
+	o.Hst.Q[0] = o.Mdl.E * o.Mdl.A * v[0] / l
+
+	// hinged ends
+	var hin []int
+	for i, law := range o.Hinges {
+		if law != nil {
+			hin = append(hin, i)
+		}
+	}
+
+	// local Newton-Raphson iterations
+	conv := []LinkState{o.Hst.Hs[0], o.Hst.Hs[1]}
+	θ := []float64{conv[0].D, conv[1].D}
+	q := make([]float64, 2)
+	A := la.MatAlloc(2, 2)
+	r := make([]float64, 2)
+	maxit := 50
+	for it := 0; ; it++ {
+
+		// moments
+		for _, i := range hin {
+			o.Hst.Hs[i] = conv[i]
+			o.Hinges[i].Update(&o.Hst.Hs[i], θ[i])
+		}
+		for i := 0; i < 2; i++ {
+			q[i] = ke[i][0]*(v[1]-θ[0]) + ke[i][1]*(v[2]-θ[1])
+		}
+
+		// residuals and Jacobian: A = -dr/dθ = ke + diag(kh)
+		converged := true
+		for _, i := range hin {
+			r[i] = q[i] - o.Hst.Hs[i].F
+			if math.Abs(r[i]) > 1e-10*(1.0+math.Abs(q[i])) {
+				converged = false
+			}
+			for _, j := range hin {
+				A[i][j] = ke[i][j]
+			}
+			A[i][i] += o.Hst.Hs[i].Kt
+		}
+		if converged {
+			break
+		}
+		if it == maxit {
+			return chk.Err("local iterations of plastic hinges of beam # %d did not converge after %d iterations", o.Cell.Id, maxit)
+		}
+
+		// update rotations of hinges
+		if len(hin) == 1 {
+			i := hin[0]
+			θ[i] += r[i] / A[i][i]
+		} else {
+			det := A[0][0]*A[1][1] - A[0][1]*A[1][0]
+			θ[0] += (A[1][1]*r[0] - A[0][1]*r[1]) / det
+			θ[1] += (A[0][0]*r[1] - A[1][0]*r[0]) / det
+		}
+	}
+	o.Hst.Q[1], o.Hst.Q[2] = q[0], q[1]
+
+	// condensed tangent: kc = ke - ke[:,h]・inv(A[h,h])・ke[h,:]
+	Ai := la.MatAlloc(2, 2)
+	if len(hin) == 1 {
+		i := hin[0]
+		Ai[i][i] = 1.0 / A[i][i]
+	} else {
+		det := A[0][0]*A[1][1] - A[0][1]*A[1][0]
+		Ai[0][0], Ai[0][1] = A[1][1]/det, -A[0][1]/det
+		Ai[1][0], Ai[1][1] = -A[1][0]/det, A[0][0]/det
+	}
+	la.MatFill(o.Hst.Kb, 0)
+	o.Hst.Kb[0][0] = o.Mdl.E * o.Mdl.A / l
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			o.Hst.Kb[1+i][1+j] = ke[i][j]
+			for _, m := range hin {
+				for _, n := range hin {
+					o.Hst.Kb[1+i][1+j] -= ke[i][m] * Ai[m][n] * ke[n][j]
+				}
+			}
+		}
+	}
+	o.hinge_tangent()
+	return
+}
@@ -18,7 +18,10 @@ import (
 )
 
 // BjointComp implements a beam-joint (interface/link) element for embedded beams with nodes
-// compatible with the nodes of the surrounding solid elements
+// compatible with the nodes of the surrounding solid elements. The line element may also be a
+// "lin2" rod; e.g. a geogrid with tension-only model. In this case, the solid elements on each face
+// of the geogrid may have their own nodes (coincident with the nodes of the rod) and be connected
+// to the rod by one joint each; i.e. with interfaces on both faces
 //  Note: beamNu corresponds to the number of displacemetns DOFs of beam; i.e. without rotations
 type BjointComp struct {
 
@@ -31,10 +34,17 @@ type BjointComp struct {
 	TolNod float64         // tolerance to find beam/solid compatible nodes
 
 	// essential
-	Lin *Beam           // beam (line) element
+	Lin *Beam           // beam (line) element; nil if rod is given
+	Rod *Rod            // rod (line) element; nil if beam is given
 	Sld *Solid          // solid element
 	Mdl *solid.RjointM1 // material model
 
+	// line data
+	LinX [][]float64 // [ndim][2] coordinates of nodes of line element
+	e0   []float64   // [3] unit vector aligned with line element
+	e1   []float64   // [3] unit vector perpendicular to line element
+	e2   []float64   // [3] unit vector perpendicular to line element
+
 	// asembly maps
 	LinUmap []int // beam umap with displacement DOFs equations only
 	SldUmap []int // solid umap with displacement DOFs at nodes connected to beam
@@ -102,13 +112,14 @@ func (o *BjointComp) Id() int { return o.Cell.Id }
 // Connect connects rod/solid elements in this BjointComp
 func (o *BjointComp) Connect(cid2elem []ele.Element, cell *inp.Cell) (nnzK int, err error) {
 
-	// get beam and solid elements
+	// get beam (or rod) and solid elements
 	linId := cell.JlinId
 	sldId := cell.JsldId
-	o.Lin = cid2elem[linId].(*Beam)
-	o.Sld = cid2elem[sldId].(*Solid)
-	if o.Lin == nil {
-		err = chk.Err("cannot find joint's beam cell with id == %d", linId)
+	o.Lin, _ = cid2elem[linId].(*Beam)
+	o.Rod, _ = cid2elem[linId].(*Rod)
+	o.Sld, _ = cid2elem[sldId].(*Solid)
+	if o.Lin == nil && o.Rod == nil {
+		err = chk.Err("cannot find joint's beam or rod cell with id == %d", linId)
 		return
 	}
 	if o.Sld == nil {
@@ -120,6 +131,21 @@ func (o *BjointComp) Connect(cid2elem []ele.Element, cell *inp.Cell) (nnzK int,
 	linNn := 2                  // number of nodes of beam
 	linNu := linNn * o.Ndim     // all displacement DOFs only
 	nodNdof := 3 * (o.Ndim - 1) // number of DOFs per node, including rotational ones
+	var linUmap []int
+	if o.Lin != nil {
+		linUmap = o.Lin.Umap
+		o.LinX = o.Lin.X
+		o.e0, o.e1, o.e2 = o.Lin.e0, o.Lin.e1, o.Lin.e2
+	} else {
+		if o.Rod.Cell.Shp.Nverts != 2 {
+			err = chk.Err("rods connected by beam-joints must be \"lin2\". %q is invalid", o.Rod.Cell.Type)
+			return
+		}
+		nodNdof = o.Ndim
+		linUmap = o.Rod.Umap
+		o.LinX = o.Rod.X
+		o.e0, o.e1, o.e2 = bjoint_rod_directions(o.Rod.X, o.Ndim)
+	}
 
 	// total number of DOFs
 	o.Ny = linNu + o.Sld.Nu
@@ -142,7 +168,7 @@ func (o *BjointComp) Connect(cid2elem []ele.Element, cell *inp.Cell) (nnzK int,
 		for i := 0; i < o.Ndim; i++ {
 			r := i + m*o.Ndim
 			s := i + m*nodNdof
-			o.LinUmap[r] = o.Lin.Umap[s]
+			o.LinUmap[r] = linUmap[s]
 		}
 	}
 
@@ -211,14 +237,14 @@ func (o *BjointComp) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 	// auxiliary
 	linNn := 2
 	h := o.Mdl.A_h
-	e0, e1, e2 := o.Lin.e0, o.Lin.e1, o.Lin.e2
+	e0, e1, e2 := o.e0, o.e1, o.e2
 
 	// loop over integration points along line
 	var coef, τ, q1, q2 float64
 	for idx, ip := range o.LinIps {
 
 		// interpolation functions and gradients
-		err = o.LinShp.CalcAtIp(o.Lin.X, ip, true)
+		err = o.LinShp.CalcAtIp(o.LinX, ip, true)
 		if err != nil {
 			return
 		}
@@ -258,7 +284,7 @@ func (o *BjointComp) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (e
 	linNu := linNn * o.Ndim
 	h := o.Mdl.A_h
 	kl := o.Mdl.A_kl
-	e0, e1, e2 := o.Lin.e0, o.Lin.e1, o.Lin.e2
+	e0, e1, e2 := o.e0, o.e1, o.e2
 
 	// zero K matrices
 	for i := 0; i < linNu; i++ {
@@ -282,7 +308,7 @@ func (o *BjointComp) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (e
 	for idx, ip := range o.LinIps {
 
 		// interpolation functions and gradients
-		err = o.LinShp.CalcAtIp(o.Lin.X, ip, true)
+		err = o.LinShp.CalcAtIp(o.LinX, ip, true)
 		if err != nil {
 			return
 		}
@@ -359,14 +385,14 @@ func (o *BjointComp) Update(sol *ele.Solution) (err error) {
 	// auxiliary
 	linNn := 2
 	kl := o.Mdl.A_kl
-	e0, e1, e2 := o.Lin.e0, o.Lin.e1, o.Lin.e2
+	e0, e1, e2 := o.e0, o.e1, o.e2
 
 	// for each integration point
 	var Δwb0, Δwb1, Δwb2, σcb float64
 	for idx, ip := range o.LinIps {
 
 		// interpolation functions and gradients
-		err = o.LinShp.CalcAtIp(o.Lin.X, ip, true)
+		err = o.LinShp.CalcAtIp(o.LinX, ip, true)
 		if err != nil {
 			return
 		}
@@ -421,6 +447,21 @@ func (o *BjointComp) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err
 	return
 }
 
+// bjoint_rod_directions computes the unit vectors aligned with rod (e0) and perpendicular to rod
+// (e1 and e2) with the same convention as Beam in 2D; i.e. e2 is the out-of-plane direction
+func bjoint_rod_directions(x [][]float64, ndim int) (e0, e1, e2 []float64) {
+	e0 = make([]float64, 3)
+	for i := 0; i < ndim; i++ {
+		e0[i] = x[i][1] - x[i][0]
+	}
+	la.VecScale(e0, 0, 1.0/la.VecNorm(e0), e0)
+	if ndim == 2 {
+		return e0, []float64{-e0[1], e0[0], 0}, []float64{0, 0, 1}
+	}
+	e1, e2 = link_perpendicular(e0)
+	return
+}
+
 // confining_pressure_ip computes stresses, tractions and confining pressure @ current ip
 // after the shape functions and stresses @ nodes are calculated
 func (o *BjointComp) confining_pressure_ip(sol *ele.Solution) (σcb, p1, p2 float64) {
@@ -441,15 +482,15 @@ func (o *BjointComp) confining_pressure_ip(sol *ele.Solution) (σcb, p1, p2 floa
 	for i := 0; i < 3; i++ {
 		o.t1[i], o.t2[i] = 0, 0
 		for j := 0; j < 3; j++ {
-			o.t1[i] += tsr.M2T(o.σ, i, j) * o.Lin.e1[j]
-			o.t2[i] += tsr.M2T(o.σ, i, j) * o.Lin.e2[j]
+			o.t1[i] += tsr.M2T(o.σ, i, j) * o.e1[j]
+			o.t2[i] += tsr.M2T(o.σ, i, j) * o.e2[j]
 		}
 	}
 
 	// calculate p1 and p2
 	for i := 0; i < 3; i++ {
-		p1 += o.t1[i] * o.Lin.e1[i]
-		p2 += o.t2[i] * o.Lin.e2[i]
+		p1 += o.t1[i] * o.e1[i]
+		p2 += o.t2[i] * o.e2[i]
 	}
 
 	// confining pressure: compressive is positive
@@ -510,7 +551,7 @@ func (o *BjointComp) Decode(dec utl.Decoder) (err error) {
 func (o *BjointComp) OutIpCoords() (C [][]float64) {
 	C = make([][]float64, len(o.LinIps))
 	for idx, ip := range o.LinIps {
-		C[idx] = o.LinShp.IpRealCoords(o.Lin.X, ip)
+		C[idx] = o.LinShp.IpRealCoords(o.LinX, ip)
 	}
 	return
 }
@@ -0,0 +1,401 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Corotational kinematics of two-node beam elements (large displacements and rotations, small
+// strains). The motion of the element is split into a rigid body motion of the element frame and
+// local deformations measured in this frame; thus any local (small-strain) formulation can be
+// used with
+//
+//      f = Bᵀ・fl    and    K = Bᵀ・Kl・B + Kg(fl)
+//
+//  where ul are the local deformations, fl = fl(ul) are the local forces, Kl = ∂fl/∂ul and
+//  B = ∂ul/∂u. The rotations of nodes (3D) are stored with quaternions and updated with the
+//  increments of rotational dofs (spatial spins); see CorotRot
+//  References:
+//   [1] Crisfield MA (1991) Non-linear finite element analysis of solids and structures. Vol 1.
+//       Wiley. Chapter 7
+//   [2] Battini JM and Pacoste C (2002) Co-rotational beam elements with warping effects in
+//       instability problems. Comput Methods Appl Mech Engrg, 191, 1755-1789
+
+// Quat implements quaternions (w, x, y, z) to represent rotations
+type Quat [4]float64
+
+// QuatIdentity returns the quaternion corresponding to no rotation
+func QuatIdentity() Quat {
+	return Quat{1, 0, 0, 0}
+}
+
+// QuatFromRotVec returns the quaternion of the rotation vector θ (exponential map); i.e. the
+// rotation by |θ| about the axis θ/|θ|
+func QuatFromRotVec(θ []float64) Quat {
+	a := math.Sqrt(θ[0]*θ[0] + θ[1]*θ[1] + θ[2]*θ[2])
+	if a < 1e-12 {
+		return Quat{1, θ[0] / 2.0, θ[1] / 2.0, θ[2] / 2.0}.Normalize()
+	}
+	s := math.Sin(a/2.0) / a
+	return Quat{math.Cos(a / 2.0), s * θ[0], s * θ[1], s * θ[2]}
+}
+
+// QuatFromMatrix returns the quaternion of the rotation matrix R (Spurrier's algorithm)
+func QuatFromMatrix(R [][]float64) (q Quat) {
+	tr := R[0][0] + R[1][1] + R[2][2]
+	switch {
+	case tr >= R[0][0] && tr >= R[1][1] && tr >= R[2][2]:
+		q[0] = 0.5 * math.Sqrt(1.0+tr)
+		f := 0.25 / q[0]
+		q[1], q[2], q[3] = f*(R[2][1]-R[1][2]), f*(R[0][2]-R[2][0]), f*(R[1][0]-R[0][1])
+	case R[0][0] >= R[1][1] && R[0][0] >= R[2][2]:
+		q[1] = 0.5 * math.Sqrt(1.0+R[0][0]-R[1][1]-R[2][2])
+		f := 0.25 / q[1]
+		q[0], q[2], q[3] = f*(R[2][1]-R[1][2]), f*(R[0][1]+R[1][0]), f*(R[0][2]+R[2][0])
+	case R[1][1] >= R[2][2]:
+		q[2] = 0.5 * math.Sqrt(1.0-R[0][0]+R[1][1]-R[2][2])
+		f := 0.25 / q[2]
+		q[0], q[1], q[3] = f*(R[0][2]-R[2][0]), f*(R[0][1]+R[1][0]), f*(R[1][2]+R[2][1])
+	default:
+		q[3] = 0.5 * math.Sqrt(1.0-R[0][0]-R[1][1]+R[2][2])
+		f := 0.25 / q[3]
+		q[0], q[1], q[2] = f*(R[1][0]-R[0][1]), f*(R[0][2]+R[2][0]), f*(R[1][2]+R[2][1])
+	}
+	return q.Normalize()
+}
+
+// Mul returns the composition q ⊗ p; i.e. the rotation p followed by q
+func (q Quat) Mul(p Quat) Quat {
+	return Quat{
+		q[0]*p[0] - q[1]*p[1] - q[2]*p[2] - q[3]*p[3],
+		q[0]*p[1] + p[0]*q[1] + q[2]*p[3] - q[3]*p[2],
+		q[0]*p[2] + p[0]*q[2] + q[3]*p[1] - q[1]*p[3],
+		q[0]*p[3] + p[0]*q[3] + q[1]*p[2] - q[2]*p[1],
+	}
+}
+
+// Normalize returns the unit quaternion q/|q|
+func (q Quat) Normalize() Quat {
+	n := math.Sqrt(q[0]*q[0] + q[1]*q[1] + q[2]*q[2] + q[3]*q[3])
+	return Quat{q[0] / n, q[1] / n, q[2] / n, q[3] / n}
+}
+
+// Matrix computes the rotation matrix R [3][3] of q
+func (q Quat) Matrix(R [][]float64) {
+	w, x, y, z := q[0], q[1], q[2], q[3]
+	R[0][0], R[0][1], R[0][2] = 1.0-2.0*(y*y+z*z), 2.0*(x*y-w*z), 2.0*(x*z+w*y)
+	R[1][0], R[1][1], R[1][2] = 2.0*(x*y+w*z), 1.0-2.0*(x*x+z*z), 2.0*(y*z-w*x)
+	R[2][0], R[2][1], R[2][2] = 2.0*(x*z-w*y), 2.0*(y*z+w*x), 1.0-2.0*(x*x+y*y)
+}
+
+// RotVec computes the rotation vector θ of q (logarithmic map) with |θ| ≤ π
+func (q Quat) RotVec(θ []float64) {
+	if q[0] < 0 {
+		q = Quat{-q[0], -q[1], -q[2], -q[3]}
+	}
+	v := math.Sqrt(q[1]*q[1] + q[2]*q[2] + q[3]*q[3])
+	f := 2.0
+	if v > 1e-15 {
+		f = 2.0 * math.Atan2(v, q[0]) / v
+	}
+	θ[0], θ[1], θ[2] = f*q[1], f*q[2], f*q[3]
+}
+
+// CorotRot holds the rotation of a node given by the (additive) values of rotational dofs; i.e.
+// the increments of the rotational dofs are interpreted as spatial spins and composed with the
+// current rotation: Q ← exp(Δθ) ⊗ Q
+type CorotRot struct {
+	Q    Quat      // current rotation
+	Th   []float64 // [3] values of rotational dofs corresponding to Q
+	bkpQ Quat      // backup rotation
+	bkpT []float64 // backup values of dofs
+}
+
+// NewCorotRot returns a new structure with no rotation
+func NewCorotRot() *CorotRot {
+	return &CorotRot{Q: QuatIdentity(), Th: make([]float64, 3), bkpQ: QuatIdentity(), bkpT: make([]float64, 3)}
+}
+
+// Update updates the rotation with the current values θ [3] of the rotational dofs
+func (o *CorotRot) Update(θ []float64) {
+	Δ := []float64{θ[0] - o.Th[0], θ[1] - o.Th[1], θ[2] - o.Th[2]}
+	o.Q = QuatFromRotVec(Δ).Mul(o.Q).Normalize()
+	copy(o.Th, θ)
+}
+
+// Backup saves the current rotation; e.g. at the beginning of increments
+func (o *CorotRot) Backup() {
+	o.bkpQ = o.Q
+	copy(o.bkpT, o.Th)
+}
+
+// Restore restores the rotation saved with Backup
+func (o *CorotRot) Restore() {
+	o.Q = o.bkpQ
+	copy(o.Th, o.bkpT)
+}
+
+// Corot2d implements the corotational kinematics of 2D beams with dofs u = (ux, uy, rz) @ nodes 0
+// and 1 and local deformations ul = (ū, θ̄0, θ̄1); i.e. the elongation and the rotations of the
+// nodes relative to the chord
+//  Note: the geometric stiffness is (consistent; see [1])
+//        Kg = z・zᵀ N/Ln + (r・zᵀ + z・rᵀ) (M0 + M1)/Ln²
+//        with r = (-c, -s, 0, c, s, 0) and z = (s, -c, 0, -s, c, 0)
+type Corot2d struct {
+	L0, C0, S0 float64     // initial length and direction cosines
+	Ln, C, S   float64     // current length and direction cosines
+	Ul         []float64   // [3] local deformations
+	B          [][]float64 // [3][6] B = ∂ul/∂u
+	r, z       []float64   // [6] auxiliary vectors
+	x0         [][]float64 // [2][2] initial coordinates
+}
+
+// NewCorot2d returns a new structure for the element with initial coordinates X [2][2]
+func NewCorot2d(X [][]float64) (o *Corot2d) {
+	o = new(Corot2d)
+	o.x0 = la.MatAlloc(2, 2)
+	la.MatCopy(o.x0, 1, X)
+	dx, dy := X[0][1]-X[0][0], X[1][1]-X[1][0]
+	o.L0 = math.Sqrt(dx*dx + dy*dy)
+	o.C0, o.S0 = dx/o.L0, dy/o.L0
+	o.Ul = make([]float64, 3)
+	o.B = la.MatAlloc(3, 6)
+	o.r = make([]float64, 6)
+	o.z = make([]float64, 6)
+	o.Update(make([]float64, 6))
+	return
+}
+
+// Update computes the local deformations (Ul) and B for the global displacements u [6]
+func (o *Corot2d) Update(u []float64) {
+
+	// chord
+	dx := o.x0[0][1] - o.x0[0][0] + u[3] - u[0]
+	dy := o.x0[1][1] - o.x0[1][0] + u[4] - u[1]
+	o.Ln = math.Sqrt(dx*dx + dy*dy)
+	o.C, o.S = dx/o.Ln, dy/o.Ln
+
+	// rigid rotation α and local deformations
+	α := math.Atan2(o.C0*o.S-o.S0*o.C, o.C0*o.C+o.S0*o.S)
+	o.Ul[0] = o.Ln - o.L0
+	o.Ul[1] = math.Atan2(math.Sin(u[2]-α), math.Cos(u[2]-α))
+	o.Ul[2] = math.Atan2(math.Sin(u[5]-α), math.Cos(u[5]-α))
+
+	// B matrix
+	c, s, l := o.C, o.S, o.Ln
+	copy(o.r, []float64{-c, -s, 0, c, s, 0})
+	copy(o.z, []float64{s, -c, 0, -s, c, 0})
+	for j := 0; j < 6; j++ {
+		o.B[0][j] = o.r[j]
+		o.B[1][j] = -o.z[j] / l
+		o.B[2][j] = -o.z[j] / l
+	}
+	o.B[1][2] += 1
+	o.B[2][5] += 1
+}
+
+// Forces computes the global forces f [6] from the local forces fl = (N, M0, M1); i.e. f = Bᵀ・fl
+func (o *Corot2d) Forces(f, fl []float64) {
+	la.MatTrVecMul(f, 1, o.B, fl)
+}
+
+// Tangent computes the global tangent K [6][6] from the local tangent Kl [3][3] and local forces fl
+func (o *Corot2d) Tangent(K, Kl [][]float64, fl []float64) {
+	la.MatTrMul3(K, 1, o.B, Kl, o.B) // K := Bᵀ・Kl・B
+	a := fl[0] / o.Ln
+	b := (fl[1] + fl[2]) / (o.Ln * o.Ln)
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			K[i][j] += a*o.z[i]*o.z[j] + b*(o.r[i]*o.z[j]+o.z[i]*o.r[j])
+		}
+	}
+}
+
+// Corot3d implements the corotational kinematics of 3D beams with dofs u = (ux, uy, uz, rx, ry, rz)
+// @ nodes 0 and 1 and local deformations ul = (ū, θ̄0, θ̄1); i.e. the elongation and the rotation
+// vectors of the nodal triads relative to the element frame
+//  The element frame Rr = [r1 r2 r3] is given by the chord r1 and the mean of the second axes p of
+//  the nodal triads: r3 = r1 × p / |r1 × p| and r2 = r3 × r1 (see [2])
+//  Note: B and Kg are computed by central differences of ul w.r.t the translations and spins of
+//        nodes; i.e. the linearisation is consistent with the kinematics up to the truncation
+//        error of the differences
+type Corot3d struct {
+	L0  float64     // initial length
+	E0  [][]float64 // [3][3] initial frame; columns are the unit vectors e0 (axis), e1 and e2
+	Ln  float64     // current length
+	Rr  [][]float64 // [3][3] current element frame; columns are r1, r2 and r3
+	Ul  []float64   // [7] local deformations
+	B   [][]float64 // [7][12] B = ∂ul/∂u (u: translations and spins)
+	Hb  float64     // step of differences for B
+	Hk  float64     // step of differences for Kg
+	U   []float64   // [6] translations of nodes (ux0, uy0, uz0, ux1, uy1, uz1)
+	Q   []Quat      // [2] rotations of nodes
+	x0  [][]float64 // [3][2] initial coordinates
+	wrk [][]float64 // [3][3] workspace
+	ula []float64   // [7] workspace
+	ulb []float64   // [7] workspace
+	d   []float64   // [12] workspace: perturbation
+}
+
+// NewCorot3d returns a new structure for the element with initial coordinates X [3][2] and initial
+// frame E0 [3][3] (e.g. from the beam element: columns = axis and local 1 and 2 directions)
+func NewCorot3d(X, E0 [][]float64) (o *Corot3d) {
+	o = new(Corot3d)
+	o.x0 = la.MatAlloc(3, 2)
+	la.MatCopy(o.x0, 1, X)
+	o.E0 = la.MatAlloc(3, 3)
+	la.MatCopy(o.E0, 1, E0)
+	for i := 0; i < 3; i++ {
+		o.L0 += (X[i][1] - X[i][0]) * (X[i][1] - X[i][0])
+	}
+	o.L0 = math.Sqrt(o.L0)
+	o.Rr = la.MatAlloc(3, 3)
+	o.Ul = make([]float64, 7)
+	o.B = la.MatAlloc(7, 12)
+	o.Hb, o.Hk = 1e-7, 1e-4
+	o.U = make([]float64, 6)
+	o.Q = []Quat{QuatIdentity(), QuatIdentity()}
+	o.wrk = la.MatAlloc(3, 3)
+	o.ula = make([]float64, 7)
+	o.ulb = make([]float64, 7)
+	o.d = make([]float64, 12)
+	o.Update(o.U, o.Q[0], o.Q[1])
+	return
+}
+
+// Update computes the local deformations (Ul) and B for the translations u [6] and rotations q0
+// and q1 of nodes
+func (o *Corot3d) Update(u []float64, q0, q1 Quat) {
+	copy(o.U, u)
+	o.Q[0], o.Q[1] = q0, q1
+	la.VecFill(o.d, 0)
+	o.Ln = o.local(o.Ul, o.Rr, o.d)
+	for j := 0; j < 12; j++ {
+		o.d[j] = o.Hb
+		o.local(o.ula, o.wrk, o.d)
+		o.d[j] = -o.Hb
+		o.local(o.ulb, o.wrk, o.d)
+		o.d[j] = 0
+		for i := 0; i < 7; i++ {
+			o.B[i][j] = (o.ula[i] - o.ulb[i]) / (2.0 * o.Hb)
+		}
+	}
+}
+
+// Forces computes the global forces f [12] from the local forces fl [7]; i.e. f = Bᵀ・fl
+func (o *Corot3d) Forces(f, fl []float64) {
+	la.MatTrVecMul(f, 1, o.B, fl)
+}
+
+// Tangent computes the global tangent K [12][12] from the local tangent Kl [7][7] and local forces
+// fl [7]; i.e. K = Bᵀ・Kl・B + Σ fl_k ∂²ul_k/∂u∂u
+func (o *Corot3d) Tangent(K, Kl [][]float64, fl []float64) {
+	la.MatTrMul3(K, 1, o.B, Kl, o.B) // K := Bᵀ・Kl・B
+	h := o.Hk
+	g := func(i, j int, si, sj float64) (res float64) {
+		o.d[i] += si * h
+		o.d[j] += sj * h
+		o.local(o.ula, o.wrk, o.d)
+		o.d[i], o.d[j] = 0, 0
+		for k := 0; k < 7; k++ {
+			res += fl[k] * o.ula[k]
+		}
+		return
+	}
+	var f0 float64
+	for k := 0; k < 7; k++ {
+		f0 += fl[k] * o.Ul[k]
+	}
+	for i := 0; i < 12; i++ {
+		K[i][i] += (g(i, i, 0.5, 0.5) - 2.0*f0 + g(i, i, -0.5, -0.5)) / (h * h)
+		for j := i + 1; j < 12; j++ {
+			v := (g(i, j, 1, 1) - g(i, j, 1, -1) - g(i, j, -1, 1) + g(i, j, -1, -1)) / (4.0 * h * h)
+			K[i][j] += v
+			K[j][i] += v
+		}
+	}
+}
+
+// local computes the local deformations ul and element frame Rr for the state of nodes perturbed by
+// d [12] = (translations and spins of node 0, translations and spins of node 1). Returns the length
+func (o *Corot3d) local(ul []float64, Rr [][]float64, d []float64) (ln float64) {
+
+	// chord
+	r1 := make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		r1[i] = o.x0[i][1] + o.U[3+i] + d[6+i] - o.x0[i][0] - o.U[i] - d[i]
+		ln += r1[i] * r1[i]
+	}
+	ln = math.Sqrt(ln)
+	for i := 0; i < 3; i++ {
+		r1[i] /= ln
+	}
+
+	// nodal triads: Rn = R(q)・E0
+	Rn := [][][]float64{la.MatAlloc(3, 3), la.MatAlloc(3, 3)}
+	for n := 0; n < 2; n++ {
+		q := QuatFromRotVec(d[6*n+3 : 6*n+6]).Mul(o.Q[n])
+		q.Matrix(o.wrk)
+		la.MatMul(Rn[n], 1, o.wrk, o.E0)
+	}
+
+	// element frame
+	p := make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		p[i] = (Rn[0][i][1] + Rn[1][i][1]) / 2.0
+	}
+	r2, r3 := make([]float64, 3), make([]float64, 3)
+	utl.Cross3d(r3, r1, p)
+	nrm := la.VecNorm(r3)
+	for i := 0; i < 3; i++ {
+		r3[i] /= nrm
+	}
+	utl.Cross3d(r2, r3, r1)
+	for i := 0; i < 3; i++ {
+		Rr[i][0], Rr[i][1], Rr[i][2] = r1[i], r2[i], r3[i]
+	}
+
+	// local rotations: R̄ = Rrᵀ・Rn
+	ul[0] = ln - o.L0
+	R := la.MatAlloc(3, 3)
+	for n := 0; n < 2; n++ {
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				R[i][j] = 0
+				for k := 0; k < 3; k++ {
+					R[i][j] += Rr[k][i] * Rn[n][k][j]
+				}
+			}
+		}
+		QuatFromMatrix(R).RotVec(ul[1+3*n : 4+3*n])
+	}
+	return
+}
+
+// CorotKl2d computes the local tangent Kl [3][3] of 2D Euler-Bernoulli beams
+func CorotKl2d(Kl [][]float64, EA, EI, L float64) {
+	la.MatFill(Kl, 0)
+	Kl[0][0] = EA / L
+	Kl[1][1], Kl[1][2] = 4.0*EI/L, 2.0*EI/L
+	Kl[2][1], Kl[2][2] = 2.0*EI/L, 4.0*EI/L
+}
+
+// CorotKl3d computes the local tangent Kl [7][7] of 3D Euler-Bernoulli beams; EI1 and EI2 are the
+// bending stiffnesses about the local 1 and 2 axes (second and third columns of E0)
+func CorotKl3d(Kl [][]float64, EA, GJ, EI1, EI2, L float64) {
+	la.MatFill(Kl, 0)
+	Kl[0][0] = EA / L
+	Kl[1][1], Kl[1][4] = GJ/L, -GJ/L
+	Kl[4][1], Kl[4][4] = -GJ/L, GJ/L
+	for a, EI := range []float64{EI1, EI2} {
+		i, j := 2+a, 5+a
+		Kl[i][i], Kl[i][j] = 4.0*EI/L, 2.0*EI/L
+		Kl[j][i], Kl[j][j] = 2.0*EI/L, 4.0*EI/L
+	}
+}
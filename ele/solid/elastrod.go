@@ -192,6 +192,11 @@ func (o *ElastRod) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err
 	return
 }
 
+// QuadFormK computes aᵀ K b with the (constant) K matrix
+func (o *ElastRod) QuadFormK(a, b []float64) (res float64, ok bool) {
+	return kmat_quadform(o.K, o.Umap, o.Umap, a, b), true
+}
+
 // writer ///////////////////////////////////////////////////////////////////////////////////////////
 
 // Encode encodes internal variables
@@ -237,6 +242,11 @@ func (o *ElastRod) CalcSig(sol *ele.Solution) float64 {
 	return o.Mdl.E * εa             // axial stress
 }
 
+// MemberForces returns the axial force N (tension positive)
+func (o *ElastRod) MemberForces(sol *ele.Solution) (F map[string][]float64) {
+	return map[string][]float64{"N": {o.Mdl.A * o.CalcSig(sol)}}
+}
+
 // auxiliary ////////////////////////////////////////////////////////////////////////////////////////
 
 // Recompute re-compute matrices after dimensions or parameters are externally changed
@@ -0,0 +1,203 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/mdl/solid"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// FiberSection implements a rectangular reinforced concrete cross-section discretised into layers
+// of concrete fibers and reinforcement layers. It is used to compute moment-curvature curves with
+// zero axial force
+//  Concrete: Hognestad parabola up to Ec0 followed by linear softening down to 0.85・Fc at Ecu;
+//            the concrete is crushed beyond Ecu and has no tensile strength
+//  Steel:    elastic-perfectly plastic
+//  Note: (1) y is the coordinate measured from the centroid of the section (positive upwards)
+//        (2) positive curvatures compress the top fibers; i.e. ε(y) = ε0 - κ・y
+//        (3) stresses and strains are positive in tension
+type FiberSection struct {
+	B    float64   // width
+	H    float64   // height
+	Fc   float64   // compressive strength of concrete (positive)
+	Ec0  float64   // strain at peak compressive stress (positive)
+	Ecu  float64   // ultimate (crushing) strain (positive)
+	Es   float64   // Young's modulus of steel
+	Fy   float64   // yield stress of steel
+	As   []float64 // areas of reinforcement layers
+	Ys   []float64 // y-coordinates of reinforcement layers
+	Nfib int       // number of concrete fibers (layers)
+}
+
+// NewFiberSection allocates a new rectangular section with width and height computed from the
+// area (A) and moment of inertia (I22) of the beam model: H = sqrt(12・I22/A) and B = A/H
+//  Flags (extra):
+//   !fc:val        -- compressive strength of concrete
+//   !ec0:val       -- strain at peak stress. default = 0.002
+//   !ecu:val       -- ultimate strain. default = 0.0035
+//   !es:val        -- Young's modulus of steel
+//   !fy:val        -- yield stress of steel
+//   !as:val        -- area of top and bottom reinforcement layers
+//   !ast, !asb     -- or areas of top and bottom layers
+//   !cov:val       -- distance from faces to centres of reinforcement layers. default = 0.1・H
+//   !nfib:val      -- number of concrete fibers. default = 40
+func NewFiberSection(extra string, mdl *solid.OnedLinElast) (o *FiberSection, err error) {
+
+	// geometry
+	o = &FiberSection{Ec0: 0.002, Ecu: 0.0035, Nfib: 40}
+	if mdl.A <= 0 || mdl.I22 <= 0 {
+		return nil, chk.Err("A and I22 must be positive to compute the dimensions of fiber section")
+	}
+	o.H = math.Sqrt(12.0 * mdl.I22 / mdl.A)
+	o.B = mdl.A / o.H
+
+	// parameters
+	get := func(key string, val *float64) (found bool) {
+		var str string
+		if str, found = io.Keycode(extra, key); found {
+			*val = io.Atof(str)
+		}
+		return
+	}
+	get("fc", &o.Fc)
+	get("ec0", &o.Ec0)
+	get("ecu", &o.Ecu)
+	get("es", &o.Es)
+	get("fy", &o.Fy)
+	if val, found := io.Keycode(extra, "nfib"); found {
+		o.Nfib = io.Atoi(val)
+	}
+
+	// reinforcement
+	var as, ast, asb float64
+	cov := 0.1 * o.H
+	get("cov", &cov)
+	if get("as", &as) {
+		ast, asb = as, as
+	}
+	get("ast", &ast)
+	get("asb", &asb)
+	o.As = []float64{ast, asb}
+	o.Ys = []float64{o.H/2.0 - cov, cov - o.H/2.0}
+
+	// check
+	if o.Fc < 0 || o.Ec0 <= 0 || o.Ecu < o.Ec0 || o.Nfib < 1 {
+		return nil, chk.Err("concrete parameters of fiber section are invalid: fc=%g, ec0=%g, ecu=%g, nfib=%d", o.Fc, o.Ec0, o.Ecu, o.Nfib)
+	}
+	if o.Es <= 0 || o.Fy <= 0 || ast < 0 || asb < 0 || ast+asb <= 0 {
+		return nil, chk.Err("steel parameters of fiber section are invalid: es=%g, fy=%g, ast=%g, asb=%g", o.Es, o.Fy, ast, asb)
+	}
+	if cov <= 0 || cov >= o.H/2.0 {
+		return nil, chk.Err("cover of fiber section must be in ]0, H/2[. %g is invalid (H = %g)", cov, o.H)
+	}
+	return
+}
+
+// Forces computes the axial force and the bending moment for given centroidal strain and curvature
+func (o *FiberSection) Forces(ε0, κ float64) (N, M float64) {
+	dy := o.H / float64(o.Nfib)
+	for i := 0; i < o.Nfib; i++ {
+		y := -o.H/2.0 + (float64(i)+0.5)*dy
+		σ := o.concrete(ε0 - κ*y)
+		N += σ * o.B * dy
+		M -= σ * o.B * dy * y
+	}
+	for i, y := range o.Ys {
+		σ := o.steel(ε0 - κ*y)
+		N += σ * o.As[i]
+		M -= σ * o.As[i] * y
+	}
+	return
+}
+
+// Moment computes the bending moment for given curvature with zero axial force
+//  Output:
+//   M  -- bending moment
+//   ε0 -- centroidal strain satisfying N(ε0, κ) = 0
+func (o *FiberSection) Moment(κ float64) (M, ε0 float64) {
+	lo := -o.Ecu - math.Abs(κ)*o.H    // all fibers compressed
+	hi := o.Fy/o.Es + math.Abs(κ)*o.H // all fibers in tension
+	for it := 0; it < 100; it++ {
+		ε0 = (lo + hi) / 2.0
+		N, _ := o.Forces(ε0, κ)
+		if math.Abs(N) < 1e-12*o.Fy*(o.As[0]+o.As[1]) {
+			break
+		}
+		if N > 0 {
+			hi = ε0
+		} else {
+			lo = ε0
+		}
+	}
+	_, M = o.Forces(ε0, κ)
+	return
+}
+
+// Backbone computes the moment-rotation backbone curve of a plastic hinge with length lp; i.e.
+// θ = κ・lp, by increasing the curvature (in both directions) until the concrete is crushed
+//  Input:
+//   lp   -- length of plastic hinge
+//   npts -- number of points on each side
+//  Output:
+//   D  -- rotations (sorted; including zero)
+//   F  -- bending moments
+//   dy -- yield rotation: first yield of reinforcement under positive curvature
+func (o *FiberSection) Backbone(lp float64, npts int) (D, F []float64, dy float64) {
+	dκ := 20.0 * o.Ecu / (o.H * float64(npts)) // the neutral axis depth is assumed to be > H/20
+	εy := o.Fy / o.Es
+	var Dp, Fp, Dn, Fn []float64
+	for _, sgn := range []float64{1, -1} {
+		for i := 1; i <= npts; i++ {
+			κ := sgn * float64(i) * dκ
+			M, ε0 := o.Moment(κ)
+			if sgn > 0 {
+				Dp, Fp = append(Dp, κ*lp), append(Fp, M)
+			} else {
+				Dn, Fn = append(Dn, κ*lp), append(Fn, M)
+			}
+			if sgn > 0 && dy == 0 {
+				for _, y := range o.Ys {
+					if math.Abs(ε0-κ*y) >= εy {
+						dy = κ * lp
+					}
+				}
+			}
+			if math.Max(-(ε0-κ*o.H/2.0), -(ε0+κ*o.H/2.0)) >= o.Ecu { // crushing of concrete
+				break
+			}
+		}
+	}
+	for i := len(Dn) - 1; i >= 0; i-- {
+		D, F = append(D, Dn[i]), append(F, Fn[i])
+	}
+	D, F = append(D, 0), append(F, 0)
+	D, F = append(D, Dp...), append(F, Fp...)
+	if dy == 0 {
+		dy = Dp[len(Dp)-1]
+	}
+	return
+}
+
+// concrete computes the stress in concrete
+func (o *FiberSection) concrete(ε float64) float64 {
+	εc := -ε
+	switch {
+	case εc <= 0 || εc > o.Ecu: // tension or crushed
+		return 0
+	case εc <= o.Ec0:
+		r := εc / o.Ec0
+		return -o.Fc * (2.0*r - r*r)
+	}
+	return -o.Fc * (1.0 - 0.15*(εc-o.Ec0)/(o.Ecu-o.Ec0))
+}
+
+// steel computes the stress in reinforcement
+func (o *FiberSection) steel(ε float64) float64 {
+	return math.Max(-o.Fy, math.Min(o.Fy, o.Es*ε))
+}
@@ -0,0 +1,528 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Interface implements a zero-thickness (Goodman) or thin-layer interface element for soil-structure
+// contact; e.g. between retaining walls, piles or culverts and the surrounding soil. With the "fault"
+// model, the element represents (hydro-mechanical) faults and discontinuities; e.g. for induced
+// seismicity and reservoir geomechanics
+//  Cells: "qua4" in 2D with vertices {0,1} on the bottom face and {3,2} on the top face; or "hex8"
+//         in 3D with vertices {0,1,2,3} on the bottom face and {4,5,6,7} on the top face. The
+//         geometry is given by the mid-surface; thus the thickness may be zero or small
+//  Flags (extra):
+//   !coupled -- discontinuous pore-liquid pressures (pl) on both faces. The total normal traction
+//               is tn - pm, where pm is the mean pore-pressure; and the leakage across the interface
+//               is kl・(pl_bot - pl_top) (see InterfaceMC and Fault). Since the model tractions are
+//               effective, the strength of faults depends on the pore-pressure
+//  Note: (1) the material model must be "interface-mc" or "fault"
+//        (2) the local system {n, t1, [t2]} is computed @ each integration point with the normal
+//            n pointing from the bottom face to the top face
+//        (3) the relative displacements are kept in the states since Update is incremental
+type Interface struct {
+
+	// basic data
+	Cell    *inp.Cell   // the cell structure
+	X       [][]float64 // matrix of nodal coordinates [ndim][nnode]
+	Ndim    int         // space dimension
+	Nu      int         // number of displacement dofs == ndim * nverts
+	Mdl     solid.Joint // material model
+	Coupled bool        // with pore-liquid pressures
+
+	// geometry
+	Bot     []int         // local indices of vertices on bottom face
+	Top     []int         // local indices of vertices on top face
+	Fshp    *shp.Shape    // shape of faces
+	IpsElem []shp.Ipoint  // integration points
+	S       [][]float64   // [nip][nf] shape functions of faces @ ips
+	B       [][][]float64 // [nip][ndim][nu] relative displacements-displacements matrices
+	Coef    []float64     // [nip] weights times the area (length) of mid-surface
+	Rot     [][][]float64 // [nip][ndim][ndim] local system @ ips: rows = {n, t1, [t2]}
+
+	// problem variables
+	Umap []int // assembly map of displacements
+	Pmap []int // assembly map of pore-liquid pressures
+
+	// matrices
+	Kuu [][]float64 // [nu][nu]
+	Kup [][]float64 // [nu][np]
+	Kpp [][]float64 // [np][np]
+
+	// internal variables
+	States    []*solid.State
+	StatesBkp []*solid.State
+	StatesAux []*solid.State
+
+	// scratchpad
+	D [][]float64 // [ndim][ndim] tangent modulus
+}
+
+// register element
+func init() {
+
+	// information allocator
+	ele.SetInfoFunc("interface", func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData) *ele.Info {
+
+		// new info
+		var info ele.Info
+
+		// solution variables
+		ykeys := []string{"ux", "uy"}
+		if sim.Ndim == 3 {
+			ykeys = []string{"ux", "uy", "uz"}
+		}
+		info.Y2F = map[string]string{"ux": "fx", "uy": "fy", "uz": "fz"}
+		if _, coupled := io.Keycode(edat.Extra, "coupled"); coupled {
+			ykeys = append(ykeys, "pl")
+			info.Y2F["pl"] = "ql"
+		}
+		info.Dofs = make([][]string, cell.Shp.Nverts)
+		for m := 0; m < cell.Shp.Nverts; m++ {
+			info.Dofs[m] = ykeys
+		}
+		return &info
+	})
+
+	// element allocator
+	ele.SetAllocator("interface", func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData, x [][]float64) ele.Element {
+
+		// basic data
+		var o Interface
+		o.Cell = cell
+		o.X = x
+		o.Ndim = sim.Ndim
+		o.Nu = o.Ndim * cell.Shp.Nverts
+		_, o.Coupled = io.Keycode(edat.Extra, "coupled")
+
+		// faces
+		var ftype string
+		switch {
+		case cell.Type == "qua4" && o.Ndim == 2:
+			o.Bot, o.Top, ftype = []int{0, 1}, []int{3, 2}, "lin2"
+		case cell.Type == "hex8" && o.Ndim == 3:
+			o.Bot, o.Top, ftype = []int{0, 1, 2, 3}, []int{4, 5, 6, 7}, "qua4"
+		default:
+			chk.Panic("interface elements require \"qua4\" cells in 2D or \"hex8\" cells in 3D. Interface {tag=%d, id=%d} with %q is invalid\n", cell.Tag, cell.Id, cell.Type)
+		}
+		o.Fshp = shp.Get(ftype, cell.GoroutineId)
+
+		// model
+		mat := sim.MatModels.Get(edat.Mat)
+		if mat == nil {
+			chk.Panic("cannot find material %q for Interface {tag=%d, id=%d}\n", edat.Mat, cell.Tag, cell.Id)
+		}
+		var ok bool
+		o.Mdl, ok = mat.Sld.(solid.Joint)
+		if !ok {
+			chk.Panic("material model of Interface {tag=%d, id=%d} must be \"interface-mc\" or \"fault\"\n", cell.Tag, cell.Id)
+		}
+
+		// integration points
+		var err error
+		o.IpsElem, _, err = o.Fshp.GetIps(edat.Nip, 0)
+		if err != nil {
+			chk.Panic("cannot get integration points for interface element {tag=%d id=%d material=%q} with nip=%d", cell.Tag, cell.Id, edat.Mat, edat.Nip)
+		}
+
+		// geometry
+		err = o.init_geometry()
+		if err != nil {
+			chk.Panic("cannot initialise interface element {tag=%d id=%d}:\n%v", cell.Tag, cell.Id, err)
+		}
+
+		// matrices
+		nverts := cell.Shp.Nverts
+		o.Kuu = la.MatAlloc(o.Nu, o.Nu)
+		if o.Coupled {
+			o.Kup = la.MatAlloc(o.Nu, nverts)
+			o.Kpp = la.MatAlloc(nverts, nverts)
+		}
+		o.D = la.MatAlloc(o.Ndim, o.Ndim)
+
+		// return new element
+		return &o
+	})
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o *Interface) Id() int { return o.Cell.Id }
+
+// SetEqs set equations
+func (o *Interface) SetEqs(eqs [][]int, mixedform_eqs []int) (err error) {
+	nverts := o.Cell.Shp.Nverts
+	o.Umap = make([]int, o.Nu)
+	for m := 0; m < nverts; m++ {
+		for i := 0; i < o.Ndim; i++ {
+			o.Umap[i+m*o.Ndim] = eqs[m][i]
+		}
+	}
+	if o.Coupled {
+		o.Pmap = make([]int, nverts)
+		for m := 0; m < nverts; m++ {
+			o.Pmap[m] = eqs[m][o.Ndim]
+		}
+	}
+	return
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *Interface) InterpStarVars(sol *ele.Solution) (err error) {
+	return
+}
+
+// SetEleConds set element conditions
+func (o *Interface) SetEleConds(key string, f fun.Func, extra string) (err error) {
+	return
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o *Interface) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
+	for idx, _ := range o.IpsElem {
+		coef := o.Coef[idx]
+		t := o.States[idx].Sig
+
+		// pore-liquid pressures
+		var pm, ql float64
+		if o.Coupled {
+			pb, pt := o.face_pressures(idx, sol)
+			pm = (pb + pt) / 2.0
+			ql = o.Mdl.Leakance(o.States[idx]) * (pb - pt)
+			for k, S := range o.S[idx] {
+				fb[o.Pmap[o.Bot[k]]] -= coef * S * ql
+				fb[o.Pmap[o.Top[k]]] += coef * S * ql
+			}
+		}
+
+		// tractions
+		for r, I := range o.Umap {
+			fb[I] -= coef * o.B[idx][0][r] * (t[0] - pm)
+			for i := 1; i < o.Ndim; i++ {
+				fb[I] -= coef * o.B[idx][i][r] * t[i]
+			}
+		}
+	}
+	return
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o *Interface) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+
+	// zero matrices
+	la.MatFill(o.Kuu, 0)
+	if o.Coupled {
+		la.MatFill(o.Kup, 0)
+		la.MatFill(o.Kpp, 0)
+	}
+
+	// for each integration point
+	for idx, _ := range o.IpsElem {
+		coef := o.Coef[idx]
+		B := o.B[idx]
+		err = o.Mdl.CalcD(o.D, o.States[idx])
+		if err != nil {
+			return
+		}
+		for r := 0; r < o.Nu; r++ {
+			for c := 0; c < o.Nu; c++ {
+				for i := 0; i < o.Ndim; i++ {
+					for j := 0; j < o.Ndim; j++ {
+						o.Kuu[r][c] += coef * B[i][r] * o.D[i][j] * B[j][c]
+					}
+				}
+			}
+		}
+		if !o.Coupled {
+			continue
+		}
+
+		// coupling: Kup = -dfb_u/dp and Kpp = -dfb_p/dp
+		kl := o.Mdl.Leakance(o.States[idx])
+		for k, Sk := range o.S[idx] {
+			for r := 0; r < o.Nu; r++ {
+				o.Kup[r][o.Bot[k]] -= coef * B[0][r] * Sk / 2.0
+				o.Kup[r][o.Top[k]] -= coef * B[0][r] * Sk / 2.0
+			}
+			for l, Sl := range o.S[idx] {
+				o.Kpp[o.Bot[k]][o.Bot[l]] += coef * kl * Sk * Sl
+				o.Kpp[o.Bot[k]][o.Top[l]] -= coef * kl * Sk * Sl
+				o.Kpp[o.Top[k]][o.Bot[l]] -= coef * kl * Sk * Sl
+				o.Kpp[o.Top[k]][o.Top[l]] += coef * kl * Sk * Sl
+			}
+		}
+	}
+
+	// add to sparse matrix Kb
+	for i, I := range o.Umap {
+		for j, J := range o.Umap {
+			Kb.Put(I, J, o.Kuu[i][j])
+		}
+	}
+	if o.Coupled {
+		for i, I := range o.Umap {
+			for n, N := range o.Pmap {
+				Kb.Put(I, N, o.Kup[i][n])
+			}
+		}
+		for m, M := range o.Pmap {
+			for n, N := range o.Pmap {
+				Kb.Put(M, N, o.Kpp[m][n])
+			}
+		}
+	}
+	return
+}
+
+// Update perform (tangent) update
+func (o *Interface) Update(sol *ele.Solution) (err error) {
+	Δw := make([]float64, o.Ndim)
+	for idx, _ := range o.IpsElem {
+		for i := 0; i < o.Ndim; i++ {
+			Δw[i] = 0
+			for r, I := range o.Umap {
+				Δw[i] += o.B[idx][i][r] * sol.ΔY[I]
+			}
+		}
+		err = o.Mdl.Update(o.States[idx], Δw, sol.T)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+//  Note: initial tractions may be given with the output keys; e.g. "tn" and "ts"
+func (o *Interface) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
+	nip := len(o.IpsElem)
+	keys := o.traction_keys()
+	o.States = make([]*solid.State, nip)
+	o.StatesBkp = make([]*solid.State, nip)
+	o.StatesAux = make([]*solid.State, nip)
+	t := make([]float64, o.Ndim)
+	for idx := 0; idx < nip; idx++ {
+		for i, key := range keys {
+			t[i] = 0
+			if vals, ok := ivs[key]; ok {
+				t[i] = vals[idx]
+			}
+		}
+		o.States[idx], err = o.Mdl.InitIntVars(t)
+		if err != nil {
+			return
+		}
+		o.StatesBkp[idx] = o.States[idx].GetCopy()
+		o.StatesAux[idx] = o.States[idx].GetCopy()
+	}
+	return
+}
+
+// SetIvs set secondary variables; e.g. during initialisation via files
+func (o *Interface) SetIvs(zvars map[string][]float64) (err error) {
+	return
+}
+
+// BackupIvs create copy of internal variables
+func (o *Interface) BackupIvs(aux bool) (err error) {
+	if aux {
+		for i, s := range o.StatesAux {
+			s.Set(o.States[i])
+		}
+		return
+	}
+	for i, s := range o.StatesBkp {
+		s.Set(o.States[i])
+	}
+	return
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *Interface) RestoreIvs(aux bool) (err error) {
+	if aux {
+		for i, s := range o.States {
+			s.Set(o.StatesAux[i])
+		}
+		return
+	}
+	for i, s := range o.States {
+		s.Set(o.StatesBkp[i])
+	}
+	return
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *Interface) Ureset(sol *ele.Solution) (err error) {
+	return
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o *Interface) Encode(enc utl.Encoder) (err error) {
+	return enc.Encode(o.States)
+}
+
+// Decode decodes internal variables
+func (o *Interface) Decode(dec utl.Decoder) (err error) {
+	err = dec.Decode(&o.States)
+	if err != nil {
+		return
+	}
+	return o.BackupIvs(false)
+}
+
+// OutIpCoords returns the coordinates of integration points
+func (o *Interface) OutIpCoords() (C [][]float64) {
+	C = make([][]float64, len(o.IpsElem))
+	xm := o.mid_coords()
+	for idx, ip := range o.IpsElem {
+		C[idx] = o.Fshp.IpRealCoords(xm, ip)
+	}
+	return
+}
+
+// OutIpKeys returns the integration points' keys
+//  Note: wn is the normal relative displacement (positive means opening) and open indicates gaps.
+//        With faults: slip is the accumulated slip, vs the slip rate, mu the friction coefficient
+//        and theta the state variable of rate-and-state friction
+func (o *Interface) OutIpKeys() []string {
+	keys := append(o.traction_keys(), "wn", "open")
+	if _, ok := o.Mdl.(*solid.Fault); ok {
+		keys = append(keys, "slip", "vs", "mu", "theta")
+	}
+	return keys
+}
+
+// OutIpVals returns the integration points' values corresponding to keys
+func (o *Interface) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
+	nip := len(o.IpsElem)
+	keys := o.traction_keys()
+	for idx, _ := range o.IpsElem {
+		s := o.States[idx]
+		for i, key := range keys {
+			M.Set(key, idx, nip, s.Sig[i])
+		}
+		M.Set("wn", idx, nip, s.EpsE[0])
+		open := 0.0
+		if o.Mdl.IsOpen(s) {
+			open = 1
+		}
+		M.Set("open", idx, nip, open)
+		if fault, ok := o.Mdl.(*solid.Fault); ok {
+			δ, V, μ, θ := fault.SlipVars(s)
+			M.Set("slip", idx, nip, δ)
+			M.Set("vs", idx, nip, V)
+			M.Set("mu", idx, nip, μ)
+			M.Set("theta", idx, nip, θ)
+		}
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// traction_keys returns the keys of tractions
+func (o *Interface) traction_keys() []string {
+	if o.Ndim == 3 {
+		return []string{"tn", "ts1", "ts2"}
+	}
+	return []string{"tn", "ts"}
+}
+
+// mid_coords returns the coordinates of the mid-surface [ndim][nf]
+func (o *Interface) mid_coords() (xm [][]float64) {
+	xm = la.MatAlloc(o.Ndim, len(o.Bot))
+	for i := 0; i < o.Ndim; i++ {
+		for k := range o.Bot {
+			xm[i][k] = (o.X[i][o.Bot[k]] + o.X[i][o.Top[k]]) / 2.0
+		}
+	}
+	return
+}
+
+// init_geometry computes the local systems and the B matrices @ integration points
+func (o *Interface) init_geometry() (err error) {
+	nip := len(o.IpsElem)
+	nf := len(o.Bot)
+	xm := o.mid_coords()
+	o.S = la.MatAlloc(nip, nf)
+	o.B = make([][][]float64, nip)
+	o.Coef = make([]float64, nip)
+	o.Rot = make([][][]float64, nip)
+	dSdR := la.MatAlloc(nf, o.Ndim-1)
+	for idx, ip := range o.IpsElem {
+
+		// tangent vectors of mid-surface
+		o.Fshp.Func(o.S[idx], dSdR, ip, true, -1)
+		g := la.MatAlloc(o.Ndim-1, 3)
+		for j := 0; j < o.Ndim-1; j++ {
+			for i := 0; i < o.Ndim; i++ {
+				for k := 0; k < nf; k++ {
+					g[j][i] += xm[i][k] * dSdR[k][j]
+				}
+			}
+		}
+
+		// local system
+		R := la.MatAlloc(o.Ndim, o.Ndim)
+		var dA float64
+		if o.Ndim == 2 {
+			dA = la.VecNorm(g[0])
+			R[1][0], R[1][1] = g[0][0]/dA, g[0][1]/dA
+			R[0][0], R[0][1] = -R[1][1], R[1][0]
+		} else {
+			n, t2 := make([]float64, 3), make([]float64, 3)
+			utl.Cross3d(n, g[0], g[1])
+			dA = la.VecNorm(n)
+			la.VecScale(n, 0, 1.0/dA, n)
+			la.VecScale(g[0], 0, 1.0/la.VecNorm(g[0]), g[0])
+			utl.Cross3d(t2, n, g[0])
+			copy(R[0], n)
+			copy(R[1], g[0])
+			copy(R[2], t2)
+		}
+		if dA < 1e-14 {
+			return chk.Err("mid-surface of interface is degenerated @ integration point %d", idx)
+		}
+
+		// B matrix: w = R・(u_top - u_bot)
+		B := la.MatAlloc(o.Ndim, o.Nu)
+		for k, S := range o.S[idx] {
+			for a := 0; a < o.Ndim; a++ {
+				for i := 0; i < o.Ndim; i++ {
+					B[a][i+o.Top[k]*o.Ndim] += S * R[a][i]
+					B[a][i+o.Bot[k]*o.Ndim] -= S * R[a][i]
+				}
+			}
+		}
+		o.B[idx] = B
+		o.Rot[idx] = R
+		o.Coef[idx] = ip[3] * dA
+	}
+	return
+}
+
+// face_pressures computes the pore-liquid pressures @ bottom and top faces
+func (o *Interface) face_pressures(idx int, sol *ele.Solution) (pb, pt float64) {
+	for k, S := range o.S[idx] {
+		pb += S * sol.Y[o.Pmap[o.Bot[k]]]
+		pt += S * sol.Y[o.Pmap[o.Top[k]]]
+	}
+	return
+}
@@ -0,0 +1,545 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Link represents a nonlinear spring (link) between two nodes whose axial force (and, optionally,
+// shear forces) is given by a force-displacement law with backbone curve and unloading rules;
+// e.g. for simplified anchors, struts and connections
+//  Flags (extra):
+//   !rule:elastic|kinematic|takeda -- unloading rule (see LinkLaw). default = elastic
+//   !fa:name                       -- name of function with axial backbone curve F(δ); e.g. a table ("pts")
+//   !ka:val !fya:val !ra:val       -- or bilinear axial backbone: stiffness, yield force and hardening ratio
+//   !dya:val                       -- axial yield displacement (Takeda rule with function backbone)
+//   !fs:name, !ks !fys !rs !dys    -- shear backbone (optional; same as axial)
+//   !alp:val                       -- exponent of degradation of unloading stiffness (Takeda). default = 0.4
+//  Note: (1) small displacements are assumed; i.e. the directions of the link are fixed
+//        (2) in 3D, the shear law is applied along two directions perpendicular to the axis
+//        (3) the link has no mass
+type Link struct {
+
+	// basic data
+	Cell *inp.Cell   // the cell structure
+	X    [][]float64 // matrix of nodal coordinates [ndim][nnode]
+	Nu   int         // total number of unknowns == 2 * ndim
+	Ndim int         // space dimension
+	L    float64     // length of link
+
+	// laws and directions
+	Laws []*LinkLaw  // [ndir] laws: axial and shear (if any)
+	Dirs [][]float64 // [ndir][ndim] unit vectors: axial and shear (if any)
+
+	// internal variables
+	States    []*LinkState // [ndir] states
+	StatesBkp []*LinkState // [ndir] backup states
+	StatesAux []*LinkState // [ndir] auxiliary backup states
+
+	// problem variables
+	Umap []int       // assembly map (location array/element equations)
+	K    [][]float64 // [nu][nu] element K matrix
+}
+
+// register element
+func init() {
+
+	// information allocator
+	ele.SetInfoFunc("link", func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData) *ele.Info {
+
+		// new info
+		var info ele.Info
+
+		// solution variables
+		ykeys := []string{"ux", "uy"}
+		if sim.Ndim == 3 {
+			ykeys = []string{"ux", "uy", "uz"}
+		}
+		info.Dofs = make([][]string, 2)
+		for m := 0; m < 2; m++ {
+			info.Dofs[m] = ykeys
+		}
+
+		// maps
+		info.Y2F = map[string]string{"ux": "fx", "uy": "fy", "uz": "fz"}
+		return &info
+	})
+
+	// element allocator
+	ele.SetAllocator("link", func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData, x [][]float64) ele.Element {
+
+		// basic data
+		var o Link
+		o.Cell = cell
+		o.X = x
+		o.Ndim = sim.Ndim
+		o.Nu = o.Ndim * 2
+
+		// axis
+		axis := make([]float64, o.Ndim)
+		for i := 0; i < o.Ndim; i++ {
+			axis[i] = o.X[i][1] - o.X[i][0]
+		}
+		o.L = la.VecNorm(axis)
+		if o.L < 1e-14 {
+			chk.Panic("link element {tag=%d id=%d} must have two distinct nodes", cell.Tag, cell.Id)
+		}
+		la.VecScale(axis, 0, 1.0/o.L, axis)
+
+		// laws
+		rule := "elastic"
+		if val, found := io.Keycode(edat.Extra, "rule"); found {
+			rule = val
+		}
+		alp := 0.4
+		if val, found := io.Keycode(edat.Extra, "alp"); found {
+			alp = io.Atof(val)
+		}
+		law, err := NewLinkLaw(sim, edat.Extra, "a", rule, alp)
+		if err != nil {
+			chk.Panic("cannot allocate axial law of link element {tag=%d id=%d}:\n%v", cell.Tag, cell.Id, err)
+		}
+		if law == nil {
+			chk.Panic("axial law of link element {tag=%d id=%d} must be given with \"!fa\" or \"!ka\"", cell.Tag, cell.Id)
+		}
+		o.Laws = []*LinkLaw{law}
+		o.Dirs = [][]float64{axis}
+		law, err = NewLinkLaw(sim, edat.Extra, "s", rule, alp)
+		if err != nil {
+			chk.Panic("cannot allocate shear law of link element {tag=%d id=%d}:\n%v", cell.Tag, cell.Id, err)
+		}
+		if law != nil {
+			if o.Ndim == 2 {
+				o.Laws = append(o.Laws, law)
+				o.Dirs = append(o.Dirs, []float64{-axis[1], axis[0]})
+			} else {
+				e2, e3 := link_perpendicular(axis)
+				o.Laws = append(o.Laws, law, law)
+				o.Dirs = append(o.Dirs, e2, e3)
+			}
+		}
+
+		// matrices
+		o.K = la.MatAlloc(o.Nu, o.Nu)
+
+		// return new element
+		return &o
+	})
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o *Link) Id() int { return o.Cell.Id }
+
+// SetEqs set equations
+func (o *Link) SetEqs(eqs [][]int, mixedform_eqs []int) (err error) {
+	o.Umap = make([]int, o.Nu)
+	for m := 0; m < 2; m++ {
+		for i := 0; i < o.Ndim; i++ {
+			o.Umap[i+m*o.Ndim] = eqs[m][i]
+		}
+	}
+	return
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *Link) InterpStarVars(sol *ele.Solution) (err error) {
+	return // link has no mass
+}
+
+// SetEleConds set element conditions
+func (o *Link) SetEleConds(key string, f fun.Func, extra string) (err error) {
+	return
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o *Link) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
+	for k, e := range o.Dirs {
+		F := o.States[k].F
+		for i := 0; i < o.Ndim; i++ {
+			fb[o.Umap[i]] += F * e[i]        // -fi @ node 0
+			fb[o.Umap[i+o.Ndim]] -= F * e[i] // -fi @ node 1
+		}
+	}
+	return
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o *Link) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+	la.MatFill(o.K, 0)
+	for k, e := range o.Dirs {
+		kt := o.States[k].Kt
+		for i := 0; i < o.Ndim; i++ {
+			for j := 0; j < o.Ndim; j++ {
+				v := kt * e[i] * e[j]
+				o.K[i][j] += v
+				o.K[i][j+o.Ndim] -= v
+				o.K[i+o.Ndim][j] -= v
+				o.K[i+o.Ndim][j+o.Ndim] += v
+			}
+		}
+	}
+	for i, I := range o.Umap {
+		for j, J := range o.Umap {
+			Kb.Put(I, J, o.K[i][j])
+		}
+	}
+	return
+}
+
+// Update perform (tangent) update
+func (o *Link) Update(sol *ele.Solution) (err error) {
+	for k, e := range o.Dirs {
+		Δδ := 0.0
+		for i := 0; i < o.Ndim; i++ {
+			Δδ += e[i] * (sol.ΔY[o.Umap[i+o.Ndim]] - sol.ΔY[o.Umap[i]])
+		}
+		o.Laws[k].Update(o.States[k], o.States[k].D+Δδ)
+	}
+	return
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *Link) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
+	n := len(o.Laws)
+	o.States = make([]*LinkState, n)
+	o.StatesBkp = make([]*LinkState, n)
+	o.StatesAux = make([]*LinkState, n)
+	for k, law := range o.Laws {
+		o.States[k] = law.NewState()
+		o.StatesBkp[k] = law.NewState()
+		o.StatesAux[k] = law.NewState()
+	}
+	return
+}
+
+// BackupIvs create copy of internal variables
+func (o *Link) BackupIvs(aux bool) (err error) {
+	if aux {
+		for k, s := range o.StatesAux {
+			*s = *o.States[k]
+		}
+		return
+	}
+	for k, s := range o.StatesBkp {
+		*s = *o.States[k]
+	}
+	return
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *Link) RestoreIvs(aux bool) (err error) {
+	if aux {
+		for k, s := range o.States {
+			*s = *o.StatesAux[k]
+		}
+		return
+	}
+	for k, s := range o.States {
+		*s = *o.StatesBkp[k]
+	}
+	return
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *Link) Ureset(sol *ele.Solution) (err error) {
+	return // deformations are kept in states since Update is incremental
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o *Link) Encode(enc utl.Encoder) (err error) {
+	return enc.Encode(o.States)
+}
+
+// Decode decodes internal variables
+func (o *Link) Decode(dec utl.Decoder) (err error) {
+	err = dec.Decode(&o.States)
+	if err != nil {
+		return
+	}
+	return o.BackupIvs(false)
+}
+
+// OutIpCoords returns the coordinates of integration points
+func (o *Link) OutIpCoords() (C [][]float64) {
+	C = utl.DblsAlloc(1, o.Ndim) // centroid only
+	for i := 0; i < o.Ndim; i++ {
+		C[0][i] = (o.X[i][0] + o.X[i][1]) / 2.0
+	}
+	return
+}
+
+// OutIpKeys returns the integration points' keys
+func (o *Link) OutIpKeys() []string {
+	keys := []string{"Fa", "Da"}
+	if len(o.Laws) == 2 {
+		keys = append(keys, "Fs", "Ds")
+	}
+	if len(o.Laws) == 3 {
+		keys = append(keys, "Fs", "Ds", "Ft", "Dt")
+	}
+	return keys
+}
+
+// OutIpVals returns the integration points' values corresponding to keys
+func (o *Link) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
+	keys := o.OutIpKeys()
+	for k, s := range o.States {
+		M.Set(keys[2*k], 0, 1, s.F)
+		M.Set(keys[2*k+1], 0, 1, s.D)
+	}
+}
+
+// law //////////////////////////////////////////////////////////////////////////////////////////////
+
+// LinkLaw implements a force-displacement law with backbone curve and unloading rules
+//  Rules:
+//   "elastic"   -- nonlinear elastic: loading and unloading follow the backbone curve
+//   "kinematic" -- Masing rule: after a reversal at (Dr,Fr), F = Fr + 2・B((δ-Dr)/2) until the
+//                  backbone is reached again on the loading side; i.e. kinematic hardening for
+//                  bilinear backbones
+//   "takeda"    -- Takeda rule: unloading with stiffness K0・(Dy/Dm)^α, where Dm is the extreme
+//                  displacement reached on the unloading side, followed by reloading towards the
+//                  extreme point of the opposite side; then, the backbone is followed
+type LinkLaw struct {
+	Fcn  fun.Func  // backbone curve F(δ); nil => table or bilinear
+	Dtab []float64 // displacements of tabulated backbone (sorted); nil => function or bilinear
+	Ftab []float64 // forces of tabulated backbone
+	K0   float64   // initial stiffness
+	Fy   float64   // yield force (bilinear backbone)
+	R    float64   // ratio of post-yield and initial stiffnesses (bilinear backbone)
+	Dy   float64   // yield displacement (Takeda rule)
+	Rule string    // unloading rule
+	Alp  float64   // exponent of degradation of unloading stiffness (Takeda rule)
+}
+
+// LinkState holds the state of a LinkLaw
+type LinkState struct {
+	D, F       float64 // displacement and force
+	Kt         float64 // tangent stiffness
+	Dir        int     // direction of last loading: +1, -1 or 0 (initial)
+	Rev        bool    // on a reversal branch (kinematic rule)
+	Dr, Fr     float64 // reversal point (kinematic rule)
+	Dmax, Fmax float64 // extreme point on positive side (Takeda rule)
+	Dmin, Fmin float64 // extreme point on negative side (Takeda rule)
+}
+
+// NewLinkLaw allocates a new LinkLaw with flags in extra corresponding to suffix (e.g. "a" for
+// axial and "s" for shear). Returns nil if the law is not given
+func NewLinkLaw(sim *inp.Simulation, extra, suffix, rule string, alp float64) (o *LinkLaw, err error) {
+
+	// backbone
+	o = &LinkLaw{Rule: rule, Alp: alp}
+	if name, found := io.Keycode(extra, "f"+suffix); found {
+		o.Fcn, err = sim.Functions.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		o.K0 = o.Fcn.G(0, nil)
+		if val, found := io.Keycode(extra, "dy"+suffix); found {
+			o.Dy = io.Atof(val)
+		}
+	} else if val, found := io.Keycode(extra, "k"+suffix); found {
+		o.K0 = io.Atof(val)
+		o.Fy = math.Inf(1)
+		if val, found := io.Keycode(extra, "fy"+suffix); found {
+			o.Fy = io.Atof(val)
+		}
+		if val, found := io.Keycode(extra, "r"+suffix); found {
+			o.R = io.Atof(val)
+		}
+		o.Dy = o.Fy / o.K0
+	} else {
+		return nil, nil
+	}
+	err = o.check()
+	if err != nil {
+		return nil, chk.Err("%v. flags = \"!f%s\", \"!k%s\", \"!fy%s\", \"!dy%s\"", err, suffix, suffix, suffix, suffix)
+	}
+	return
+}
+
+// NewLinkLawTable allocates a new LinkLaw with tabulated (piecewise linear) backbone curve
+//  Input:
+//   D, F -- displacements (sorted and including zero) and forces of backbone. The last values are
+//           kept constant beyond the first and last points
+//   dy   -- yield displacement (Takeda rule)
+func NewLinkLawTable(D, F []float64, dy float64, rule string, alp float64) (o *LinkLaw, err error) {
+	if len(D) < 2 || len(D) != len(F) {
+		return nil, chk.Err("tabulated backbone requires at least two points and len(D) == len(F). %d, %d are invalid", len(D), len(F))
+	}
+	o = &LinkLaw{Dtab: D, Ftab: F, Dy: dy, Rule: rule, Alp: alp}
+	for i := 1; i < len(D); i++ {
+		if D[i] <= D[i-1] {
+			return nil, chk.Err("displacements of tabulated backbone must be sorted in ascending order")
+		}
+	}
+	_, o.K0 = o.Backbone(0)
+	err = o.check()
+	return
+}
+
+// check checks initial stiffness, rule and yield displacement
+func (o *LinkLaw) check() (err error) {
+	if o.K0 <= 0 {
+		return chk.Err("initial stiffness of link law must be positive. %g is invalid", o.K0)
+	}
+	switch o.Rule {
+	case "elastic", "kinematic":
+	case "takeda":
+		if o.Dy <= 0 || math.IsInf(o.Dy, 0) {
+			return chk.Err("Takeda rule requires a positive yield displacement")
+		}
+	default:
+		return chk.Err("rule %q of link law is invalid; options are \"elastic\", \"kinematic\" and \"takeda\"", o.Rule)
+	}
+	return
+}
+
+// NewState returns a new (initial) state
+func (o *LinkLaw) NewState() (s *LinkState) {
+	s = &LinkState{Kt: o.K0}
+	if o.Rule == "takeda" {
+		s.Dmax, s.Dmin = o.Dy, -o.Dy
+		s.Fmax, _ = o.Backbone(o.Dy)
+		s.Fmin, _ = o.Backbone(-o.Dy)
+	}
+	return
+}
+
+// Backbone returns the force and the tangent stiffness on the backbone curve
+func (o *LinkLaw) Backbone(δ float64) (F, dFdδ float64) {
+	if o.Fcn != nil {
+		return o.Fcn.F(δ, nil), o.Fcn.G(δ, nil)
+	}
+	if o.Dtab != nil {
+		n := len(o.Dtab)
+		if δ <= o.Dtab[0] {
+			return o.Ftab[0], 0
+		}
+		if δ >= o.Dtab[n-1] {
+			return o.Ftab[n-1], 0
+		}
+		i := 1
+		for δ > o.Dtab[i] {
+			i++
+		}
+		dFdδ = (o.Ftab[i] - o.Ftab[i-1]) / (o.Dtab[i] - o.Dtab[i-1])
+		return o.Ftab[i-1] + dFdδ*(δ-o.Dtab[i-1]), dFdδ
+	}
+	if math.Abs(δ) <= o.Dy {
+		return o.K0 * δ, o.K0
+	}
+	sgn := math.Copysign(1, δ)
+	return sgn * (o.Fy + o.R*o.K0*(math.Abs(δ)-o.Dy)), o.R * o.K0
+}
+
+// Update updates state s (last converged) for the new displacement δ
+func (o *LinkLaw) Update(s *LinkState, δ float64) {
+
+	// direction of loading
+	Δ := δ - s.D
+	if math.Abs(Δ) < 1e-15 {
+		return
+	}
+	dir := 1
+	if Δ < 0 {
+		dir = -1
+	}
+
+	// rules
+	switch o.Rule {
+	case "elastic":
+		s.F, s.Kt = o.Backbone(δ)
+
+	case "kinematic":
+		if s.Dir != 0 && dir != s.Dir {
+			s.Rev, s.Dr, s.Fr = true, s.D, s.F
+		}
+		if s.Rev {
+			b, db := o.Backbone((δ - s.Dr) / 2.0)
+			s.F, s.Kt = s.Fr+2.0*b, db
+			fb, dfb := o.Backbone(δ)
+			if float64(dir)*δ > 0 && float64(dir)*(s.F-fb) >= 0 { // backbone reached
+				s.Rev, s.F, s.Kt = false, fb, dfb
+			}
+		} else {
+			s.F, s.Kt = o.Backbone(δ)
+		}
+
+	case "takeda":
+		o.takeda(s, δ, dir)
+	}
+	s.D, s.Dir = δ, dir
+}
+
+// takeda implements the Takeda rule
+func (o *LinkLaw) takeda(s *LinkState, δ float64, dir int) {
+
+	// unloading
+	Ds, Fs := s.D, s.F
+	if float64(dir)*Fs < 0 {
+		Dm := s.Dmax
+		if Fs < 0 {
+			Dm = s.Dmin
+		}
+		Ku := o.K0 * math.Min(1, math.Pow(o.Dy/math.Abs(Dm), o.Alp))
+		δ0 := Ds - Fs/Ku
+		if float64(dir)*(δ-δ0) <= 0 {
+			s.F, s.Kt = Fs+Ku*(δ-Ds), Ku
+			return
+		}
+		Ds, Fs = δ0, 0
+	}
+
+	// reloading towards extreme point or backbone
+	De, Fe := s.Dmax, s.Fmax
+	if dir < 0 {
+		De, Fe = s.Dmin, s.Fmin
+	}
+	if float64(dir)*(δ-De) >= 0 || float64(dir)*(Ds-De) >= 0 {
+		s.F, s.Kt = o.Backbone(δ)
+		if dir > 0 && δ > s.Dmax {
+			s.Dmax, s.Fmax = δ, s.F
+		}
+		if dir < 0 && δ < s.Dmin {
+			s.Dmin, s.Fmin = δ, s.F
+		}
+		return
+	}
+	k := (Fe - Fs) / (De - Ds)
+	s.F, s.Kt = Fs+k*(δ-Ds), k
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// link_perpendicular returns two unit vectors perpendicular to the unit vector e1 (3D)
+func link_perpendicular(e1 []float64) (e2, e3 []float64) {
+	a := []float64{1, 0, 0}
+	if math.Abs(e1[0]) > 0.9 {
+		a = []float64{0, 1, 0}
+	}
+	e2 = make([]float64, 3)
+	e3 = make([]float64, 3)
+	utl.Cross3d(e2, e1, a)
+	la.VecScale(e2, 0, 1.0/la.VecNorm(e2), e2)
+	utl.Cross3d(e3, e1, e2)
+	return
+}
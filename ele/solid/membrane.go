@@ -0,0 +1,458 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// membrane modes
+const (
+	MembraneTaut     = 0 // both principal stresses are non-negative
+	MembraneWrinkled = 1 // uniaxial tension along the major principal direction
+	MembraneSlack    = 2 // no tension in any direction
+)
+
+// MembraneState holds the state @ integration points of membranes
+type MembraneState struct {
+	Eps  []float64 // [3] in-plane strains {ε11, ε22, γ12} in the local system
+	Sig  []float64 // [3] in-plane stresses {σ11, σ22, σ12} in the local system
+	Mode int       // taut, wrinkled or slack
+}
+
+// Set copies state
+func (o *MembraneState) Set(another *MembraneState) {
+	copy(o.Eps, another.Eps)
+	copy(o.Sig, another.Sig)
+	o.Mode = another.Mode
+}
+
+// Membrane implements a tension-only membrane element (e.g. geotextiles and geomembranes) in 3D
+// using surface cells; e.g. "tri3", "qua4" or "qua8". The tension-field theory is employed: the
+// membrane is taut if both principal stresses are non-negative, wrinkled (uniaxial tension) if only
+// the major principal strain is positive, or slack otherwise
+//  Note: (1) the material model must be "oned-tension"; with A corresponding to the thickness
+//        (2) strains and stresses are computed in a local system {a1, a2} tangent to the surface @
+//            each integration point, with a1 aligned with the first natural direction
+//        (3) membranes are connected to the surrounding solids by sharing nodes. Interfaces on both
+//            faces of geogrids are available in 2D by means of rods and "bjointcomp" elements
+type Membrane struct {
+
+	// basic data
+	Cell *inp.Cell          // the cell structure
+	X    [][]float64        // matrix of nodal coordinates [ndim][nnode]
+	Nu   int                // total number of unknowns == 3 * nverts
+	Mdl  *solid.OnedTension // material model
+
+	// integration points
+	IpsElem []shp.Ipoint // integration points of element
+
+	// geometry @ integration points
+	B    [][][]float64 // [nip][3][nu] strain-displacement matrices in the local systems
+	Coef []float64     // [nip] weights times the area of the surface
+	A1   [][]float64   // [nip][3] first local direction
+	A2   [][]float64   // [nip][3] second local direction
+
+	// vectors and matrices
+	K [][]float64 // element K matrix
+
+	// problem variables
+	Umap []int // assembly map (location array/element equations)
+
+	// internal variables
+	States    []*MembraneState
+	StatesBkp []*MembraneState
+	StatesAux []*MembraneState
+
+	// scratchpad
+	D [][]float64 // [3][3] tangent modulus
+}
+
+// register element
+func init() {
+
+	// information allocator
+	ele.SetInfoFunc("membrane", func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData) *ele.Info {
+
+		// new info
+		var info ele.Info
+
+		// solution variables
+		ykeys := []string{"ux", "uy", "uz"}
+		info.Dofs = make([][]string, cell.Shp.Nverts)
+		for m := 0; m < cell.Shp.Nverts; m++ {
+			info.Dofs[m] = ykeys
+		}
+
+		// maps
+		info.Y2F = map[string]string{"ux": "fx", "uy": "fy", "uz": "fz"}
+		return &info
+	})
+
+	// element allocator
+	ele.SetAllocator("membrane", func(sim *inp.Simulation, cell *inp.Cell, edat *inp.ElemData, x [][]float64) ele.Element {
+
+		// check
+		if sim.Ndim != 3 || cell.Shp.Gndim != 2 {
+			chk.Panic("membrane elements require surface cells (e.g. \"qua4\") in 3D analyses. Membrane {tag=%d, id=%d} is invalid\n", cell.Tag, cell.Id)
+		}
+
+		// basic data
+		var o Membrane
+		o.Cell = cell
+		o.X = x
+		o.Nu = 3 * cell.Shp.Nverts
+
+		// model
+		mat := sim.MatModels.Get(edat.Mat)
+		if mat == nil {
+			chk.Panic("cannot find material %q for Membrane {tag=%d, id=%d}\n", edat.Mat, cell.Tag, cell.Id)
+		}
+		var ok bool
+		o.Mdl, ok = mat.Sld.(*solid.OnedTension)
+		if !ok {
+			chk.Panic("material model of Membrane {tag=%d, id=%d} must be \"oned-tension\"\n", cell.Tag, cell.Id)
+		}
+
+		// integration points
+		var err error
+		o.IpsElem, _, err = o.Cell.Shp.GetIps(edat.Nip, 0)
+		if err != nil {
+			chk.Panic("cannot get integration points for membrane element {tag=%d id=%d material=%q} with nip=%d", cell.Tag, cell.Id, edat.Mat, edat.Nip)
+		}
+
+		// geometry
+		err = o.init_geometry()
+		if err != nil {
+			chk.Panic("cannot initialise membrane element {tag=%d id=%d}:\n%v", cell.Tag, cell.Id, err)
+		}
+
+		// scratchpad
+		o.K = la.MatAlloc(o.Nu, o.Nu)
+		o.D = la.MatAlloc(3, 3)
+
+		// return new element
+		return &o
+	})
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o *Membrane) Id() int { return o.Cell.Id }
+
+// SetEqs set equations
+func (o *Membrane) SetEqs(eqs [][]int, mixedform_eqs []int) (err error) {
+	o.Umap = make([]int, o.Nu)
+	for m := 0; m < o.Cell.Shp.Nverts; m++ {
+		for i := 0; i < 3; i++ {
+			o.Umap[i+m*3] = eqs[m][i]
+		}
+	}
+	return
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *Membrane) InterpStarVars(sol *ele.Solution) (err error) {
+	return
+}
+
+// SetEleConds set element conditions
+func (o *Membrane) SetEleConds(key string, f fun.Func, extra string) (err error) {
+	return
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o *Membrane) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
+	t := o.Mdl.A
+	for idx, _ := range o.IpsElem {
+		σ := o.States[idx].Sig
+		for r, I := range o.Umap {
+			for i := 0; i < 3; i++ {
+				fb[I] -= o.Coef[idx] * t * o.B[idx][i][r] * σ[i] // -fi
+			}
+		}
+	}
+	return
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o *Membrane) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+	la.MatFill(o.K, 0)
+	t := o.Mdl.A
+	σ := make([]float64, 3)
+	for idx, _ := range o.IpsElem {
+		o.stress(σ, o.D, o.States[idx].Eps)
+		B := o.B[idx]
+		for r := 0; r < o.Nu; r++ {
+			for c := 0; c < o.Nu; c++ {
+				for i := 0; i < 3; i++ {
+					for j := 0; j < 3; j++ {
+						o.K[r][c] += o.Coef[idx] * t * B[i][r] * o.D[i][j] * B[j][c]
+					}
+				}
+			}
+		}
+	}
+	for i, I := range o.Umap {
+		for j, J := range o.Umap {
+			Kb.Put(I, J, o.K[i][j])
+		}
+	}
+	return
+}
+
+// Update perform (tangent) update
+func (o *Membrane) Update(sol *ele.Solution) (err error) {
+	for idx, _ := range o.IpsElem {
+		s := o.States[idx]
+		for i := 0; i < 3; i++ {
+			for r, I := range o.Umap {
+				s.Eps[i] += o.B[idx][i][r] * sol.ΔY[I]
+			}
+		}
+		s.Mode = o.stress(s.Sig, o.D, s.Eps)
+	}
+	return
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *Membrane) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
+	nip := len(o.IpsElem)
+	o.States = make([]*MembraneState, nip)
+	o.StatesBkp = make([]*MembraneState, nip)
+	o.StatesAux = make([]*MembraneState, nip)
+	for i := 0; i < nip; i++ {
+		o.States[i] = &MembraneState{Eps: make([]float64, 3), Sig: make([]float64, 3)}
+		o.StatesBkp[i] = &MembraneState{Eps: make([]float64, 3), Sig: make([]float64, 3)}
+		o.StatesAux[i] = &MembraneState{Eps: make([]float64, 3), Sig: make([]float64, 3)}
+	}
+	return
+}
+
+// SetIvs set secondary variables; e.g. during initialisation via files
+func (o *Membrane) SetIvs(zvars map[string][]float64) (err error) {
+	return
+}
+
+// BackupIvs create copy of internal variables
+func (o *Membrane) BackupIvs(aux bool) (err error) {
+	if aux {
+		for i, s := range o.StatesAux {
+			s.Set(o.States[i])
+		}
+		return
+	}
+	for i, s := range o.StatesBkp {
+		s.Set(o.States[i])
+	}
+	return
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *Membrane) RestoreIvs(aux bool) (err error) {
+	if aux {
+		for i, s := range o.States {
+			s.Set(o.StatesAux[i])
+		}
+		return
+	}
+	for i, s := range o.States {
+		s.Set(o.StatesBkp[i])
+	}
+	return
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *Membrane) Ureset(sol *ele.Solution) (err error) {
+	return
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o *Membrane) Encode(enc utl.Encoder) (err error) {
+	return enc.Encode(o.States)
+}
+
+// Decode decodes internal variables
+func (o *Membrane) Decode(dec utl.Decoder) (err error) {
+	err = dec.Decode(&o.States)
+	if err != nil {
+		return
+	}
+	return o.BackupIvs(false)
+}
+
+// OutIpCoords returns the coordinates of integration points
+func (o *Membrane) OutIpCoords() (C [][]float64) {
+	C = make([][]float64, len(o.IpsElem))
+	for idx, ip := range o.IpsElem {
+		C[idx] = o.Cell.Shp.IpRealCoords(o.X, ip)
+	}
+	return
+}
+
+// OutIpKeys returns the integration points' keys
+//  Note: s11, s22 and s12 are the stresses in the local system and mode indicates the
+//        taut (0), wrinkled (1) or slack (2) state
+func (o *Membrane) OutIpKeys() []string {
+	return []string{"s11", "s22", "s12", "mode"}
+}
+
+// OutIpVals returns the integration points' values corresponding to keys
+func (o *Membrane) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
+	nip := len(o.IpsElem)
+	for idx, _ := range o.IpsElem {
+		s := o.States[idx]
+		M.Set("s11", idx, nip, s.Sig[0])
+		M.Set("s22", idx, nip, s.Sig[1])
+		M.Set("s12", idx, nip, s.Sig[2])
+		M.Set("mode", idx, nip, float64(s.Mode))
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// init_geometry computes the local systems and the strain-displacement matrices @ integration points
+func (o *Membrane) init_geometry() (err error) {
+	nip := len(o.IpsElem)
+	nverts := o.Cell.Shp.Nverts
+	o.B = make([][][]float64, nip)
+	o.Coef = make([]float64, nip)
+	o.A1 = la.MatAlloc(nip, 3)
+	o.A2 = la.MatAlloc(nip, 3)
+	S := make([]float64, nverts)
+	dSdR := la.MatAlloc(nverts, 2)
+	g1, g2, n := make([]float64, 3), make([]float64, 3), make([]float64, 3)
+	for idx, ip := range o.IpsElem {
+
+		// tangent vectors
+		o.Cell.Shp.Func(S, dSdR, ip, true, -1)
+		for i := 0; i < 3; i++ {
+			g1[i], g2[i] = 0, 0
+			for m := 0; m < nverts; m++ {
+				g1[i] += o.X[i][m] * dSdR[m][0]
+				g2[i] += o.X[i][m] * dSdR[m][1]
+			}
+		}
+		utl.Cross3d(n, g1, g2)
+		dA := la.VecNorm(n)
+		if dA < 1e-14 {
+			return chk.Err("surface of membrane is degenerated @ integration point %d", idx)
+		}
+
+		// local system: a1 ∥ g1, a2 = n × a1
+		a1, a2 := o.A1[idx], o.A2[idx]
+		la.VecScale(a1, 0, 1.0/la.VecNorm(g1), g1)
+		la.VecScale(n, 0, 1.0/dA, n)
+		utl.Cross3d(a2, n, a1)
+
+		// gradients w.r.t local coordinates: G = dSdR・inv(M) with M[α][j] = aα・gj
+		M := [][]float64{
+			{la.VecDot(a1, g1), la.VecDot(a1, g2)},
+			{la.VecDot(a2, g1), la.VecDot(a2, g2)},
+		}
+		det := M[0][0]*M[1][1] - M[0][1]*M[1][0]
+		Mi := [][]float64{{M[1][1] / det, -M[0][1] / det}, {-M[1][0] / det, M[0][0] / det}}
+
+		// strain-displacement matrix
+		B := la.MatAlloc(3, o.Nu)
+		for m := 0; m < nverts; m++ {
+			G0 := dSdR[m][0]*Mi[0][0] + dSdR[m][1]*Mi[1][0]
+			G1 := dSdR[m][0]*Mi[0][1] + dSdR[m][1]*Mi[1][1]
+			for i := 0; i < 3; i++ {
+				B[0][i+m*3] = G0 * a1[i]
+				B[1][i+m*3] = G1 * a2[i]
+				B[2][i+m*3] = G1*a1[i] + G0*a2[i]
+			}
+		}
+		o.B[idx] = B
+		o.Coef[idx] = ip[3] * dA
+	}
+	return
+}
+
+// stress computes stresses and tangent modulus for given strains according to the tension-field
+// theory and returns the corresponding mode
+//  Note: (1) in the wrinkled state, σ = E・ε1・v + kc・De・ε where ε1 is the major principal strain
+//            and v = {c², s², c・s} with c = cos(θ) and s = sin(θ); θ being the principal direction
+//        (2) D = E・v・trans(v) + E・ε1/(4 R)・w・trans(w) + kc・De where w = dv/dθ and R is the radius
+//            of Mohr's circle of strains
+func (o *Membrane) stress(σ []float64, D [][]float64, ε []float64) (mode int) {
+
+	// elastic modulus (plane-stress)
+	E, ν, kc := o.Mdl.E, o.Mdl.Nu, o.Mdl.Kc
+	c := E / (1.0 - ν*ν)
+	De := [][]float64{{c, c * ν, 0}, {c * ν, c, 0}, {0, 0, c * (1.0 - ν) / 2.0}}
+
+	// elastic trial: minor principal stress
+	var σe [3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			σe[i] += De[i][j] * ε[j]
+		}
+	}
+	σ2 := (σe[0]+σe[1])/2.0 - math.Sqrt(math.Pow((σe[0]-σe[1])/2.0, 2)+σe[2]*σe[2])
+
+	// major principal strain
+	d := ε[0] - ε[1]
+	R := math.Sqrt(d*d/4.0 + ε[2]*ε[2]/4.0)
+	ε1 := (ε[0]+ε[1])/2.0 + R
+
+	// mode
+	mode = MembraneSlack
+	switch {
+	case σ2 >= 0:
+		mode = MembraneTaut
+	case ε1 > 0:
+		mode = MembraneWrinkled
+	}
+
+	// taut
+	if mode == MembraneTaut {
+		for i := 0; i < 3; i++ {
+			σ[i] = σe[i]
+			copy(D[i], De[i])
+		}
+		return
+	}
+
+	// slack (or residual stiffness of wrinkled state)
+	for i := 0; i < 3; i++ {
+		σ[i] = kc * σe[i]
+		for j := 0; j < 3; j++ {
+			D[i][j] = kc * De[i][j]
+		}
+	}
+	if mode == MembraneSlack {
+		return
+	}
+
+	// wrinkled
+	θ := math.Atan2(ε[2], d) / 2.0
+	cs, sn := math.Cos(θ), math.Sin(θ)
+	v := []float64{cs * cs, sn * sn, cs * sn}
+	w := []float64{-2.0 * cs * sn, 2.0 * cs * sn, cs*cs - sn*sn}
+	for i := 0; i < 3; i++ {
+		σ[i] += E * ε1 * v[i]
+		for j := 0; j < 3; j++ {
+			D[i][j] += E*v[i]*v[j] + E*ε1*w[i]*w[j]/(4.0*R)
+		}
+	}
+	return
+}
@@ -0,0 +1,161 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// RjointSeg holds data of a segment of rod embedded into one solid element
+//  Note: the segment is defined by [Sa, Sb] in the natural coordinates of the rod (-1 ≤ s ≤ 1).
+//        The segment has its own integration points, obtained by mapping the integration points of
+//        the rod onto [Sa, Sb], and its own Nmat computed at the "segment nodes"; i.e. points along
+//        the rod with natural coordinates mapped from the rod's nodes onto [Sa, Sb]
+type RjointSeg struct {
+
+	// input
+	Sld *Solid  // solid element containing segment
+	Sa  float64 // natural coordinate (w.r.t rod) of start point
+	Sb  float64 // natural coordinate (w.r.t rod) of end point
+
+	// integration points
+	Ips []shp.Ipoint // [segNp] integration points w.r.t rod's natural coordinates (with scaled weights)
+	Zps [][]float64  // [segNp][3] natural coordinates of integration points w.r.t the segment
+	Ip0 int          // index of first integration point of segment in list of all joint's ips
+
+	// shape functions of solid
+	Nmat [][]float64 // [sldNn][rodNn] solid shape functions @ segment nodes
+	Pmat [][]float64 // [sldNn][segNp] solid shape functions @ segment ips
+	Emat [][]float64 // [sldNn][sldNp] solid extrapolation matrix
+
+	// variables for Coulomb model
+	σNo    [][]float64     // [sldNn][nsig] σ at nodes of solid
+	DσNoDu [][][][]float64 // [sldNn][nsig][sldNn][ndim] ∂σSolidNodes/∂uSolidNodes
+
+	// temporary Jacobian matrices. see Eq. (57)
+	Krs [][]float64 // [rodNu][sldNu] Eq. (59)
+	Ksr [][]float64 // [sldNu][rodNu] Eq. (60)
+	Kss [][]float64 // [sldNu][sldNu] Eq. (61)
+}
+
+// Init initialises segment; e.g. computes integration points, Nmat and Pmat
+//  ip0 -- index of first integration point of this segment
+func (o *RjointSeg) Init(rod *Rod, ip0 int, coulomb, ncns bool) (err error) {
+
+	// auxiliary
+	ndim := rod.Ndim
+	nsig := 2 * ndim
+	rodH := rod.Cell.Shp
+	rodNn := rodH.Nverts
+	sldH := o.Sld.Cell.Shp
+	sldNn := sldH.Nverts
+	sldNp := len(o.Sld.IpsElem)
+
+	// integration points
+	segNp := len(rod.IpsElem)
+	o.Ip0 = ip0
+	o.Ips = make([]shp.Ipoint, segNp)
+	o.Zps = la.MatAlloc(segNp, 3)
+	L := (o.Sb - o.Sa) / 2.0
+	for k, ip := range rod.IpsElem {
+		o.Zps[k][0] = ip[0]
+		o.Ips[k] = shp.Ipoint{o.Sa + (ip[0]+1.0)*L, 0, 0, ip[3] * L}
+	}
+
+	// shape functions of solid @ nodes of segment
+	o.Nmat = la.MatAlloc(sldNn, rodNn)
+	sldR := make([]float64, 3)
+	for m := 0; m < rodNn; m++ {
+		z := o.Sa + (rodH.NatCoords[0][m]+1.0)*L
+		y := rodH.IpRealCoords(rod.X, shp.Ipoint{z, 0, 0, 0})
+		err = sldH.InvMap(sldR, y, o.Sld.X)
+		if err != nil {
+			return
+		}
+		err = sldH.CalcAtR(o.Sld.X, sldR, false)
+		if err != nil {
+			return
+		}
+		for n := 0; n < sldNn; n++ {
+			o.Nmat[n][m] = sldH.S[n]
+		}
+	}
+
+	// coulomb model => σc depends on p values of solid
+	if coulomb {
+
+		// allocate variables
+		o.Pmat = la.MatAlloc(sldNn, segNp)
+		o.Emat = la.MatAlloc(sldNn, sldNp)
+		o.σNo = la.MatAlloc(sldNn, nsig)
+		if !ncns {
+			o.DσNoDu = utl.Deep4alloc(sldNn, nsig, sldNn, ndim)
+		}
+
+		// extrapolator matrix
+		err = sldH.Extrapolator(o.Emat, o.Sld.IpsElem)
+		if err != nil {
+			return
+		}
+
+		// shape function of solid @ ips of segment
+		for k, ip := range o.Ips {
+			y := rodH.IpRealCoords(rod.X, ip)
+			err = sldH.InvMap(sldR, y, o.Sld.X)
+			if err != nil {
+				return
+			}
+			err = sldH.CalcAtR(o.Sld.X, sldR, false)
+			if err != nil {
+				return
+			}
+			for n := 0; n < sldNn; n++ {
+				o.Pmat[n][k] = sldH.S[n]
+			}
+		}
+	}
+
+	// temporary Jacobian matrices
+	o.Krs = la.MatAlloc(rod.Nu, o.Sld.Nu)
+	o.Ksr = la.MatAlloc(o.Sld.Nu, rod.Nu)
+	o.Kss = la.MatAlloc(o.Sld.Nu, o.Sld.Nu)
+	return
+}
+
+// RjointSplitRod splits rod into segments; one for each solid crossed by the rod
+//  Note: (1) the rod is split with inp.RodSplit; i.e. by sampling and bisection; thus solids
+//            crossed over a very small length may be missed.
+//        (2) all points along the rod must be inside one of the given solids
+func RjointSplitRod(rod *Rod, slds []*Solid) (segs []*RjointSeg, err error) {
+
+	// shapes and coordinates of solids
+	shps := make([]*shp.Shape, len(slds))
+	xsld := make([][][]float64, len(slds))
+	for i, sld := range slds {
+		shps[i], xsld[i] = sld.Cell.Shp, sld.X
+	}
+
+	// split rod
+	point := func(z float64) []float64 {
+		return rod.Cell.Shp.IpRealCoords(rod.X, shp.Ipoint{z, 0, 0, 0})
+	}
+	ss, owners := inp.RodSplit(-1, 1, func(z float64) int {
+		return inp.RodOwner(shps, xsld, point(z))
+	})
+
+	// segments
+	for i, idx := range owners {
+		if idx < 0 {
+			return nil, chk.Err("rjoint: point %v of rod (cell id = %d) is not inside any of the given solids", point((ss[i]+ss[i+1])/2.0), rod.Id())
+		}
+		segs = append(segs, &RjointSeg{Sld: slds[idx], Sa: ss[i], Sb: ss[i+1]})
+	}
+	return
+}
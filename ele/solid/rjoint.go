@@ -5,9 +5,12 @@
 package solid
 
 import (
+	"math"
+
 	"github.com/cpmech/gofem/ele"
 	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gofem/shp"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
@@ -30,6 +33,8 @@ import (
 //   rodNn    -- rod number of nodes
 //   rodNp    -- rod number of integration points
 //   rodS     -- rod shape functions
+//   seg      -- means segment of rod embedded in one solid element
+//   segS     -- rod shape functions evaluated with the natural coordinates of the segment
 //   sld      -- means solid element
 //   sldH     -- rod shape structure
 //   sldNn    -- solid number of nodes
@@ -42,8 +47,10 @@ import (
 //   s or S   -- parametric coordinate along rod
 //   rodRn    -- natural coordinates or rod's nodes w.r.t solid's system
 //   rodRp    -- natural coordinates of rod's integration point w.r.t to solid's system
-//   Nmat     -- solid shape functions evaluated at rod nodes
-//   Pmat     -- solid shape functions evaluated at rod integration points
+//   Nmat     -- solid shape functions evaluated at (segment) rod nodes
+//   Pmat     -- solid shape functions evaluated at (segment) rod integration points
+//  Note: a rod crossing several solids is automatically split into segments; one per solid.
+//        Each segment has its own integration points and Nmat matrix. See rjoint-segs.go
 //  References:
 //   [1] Durand R, Farias MM, Pedroso DM. Modelling the strengthening of solids with
 //       incompatible line finite elements. Submitted.
@@ -59,43 +66,36 @@ type Rjoint struct {
 	Sim  *inp.Simulation // simulation
 	Edat *inp.ElemData   // element data; stored in allocator to be used in Connect
 	Cell *inp.Cell       // the cell structure
-	Ny   int             // total number of dofs == rod.Nu + solid.Nu
+	Ny   int             // total number of dofs == rod.Nu + sum(solid.Nu)
 	Ndim int             // space dimension
+	Nip  int             // total number of integration points == sum(len(segment.Ips))
 
 	// essential
-	Rod *Rod            // rod element
-	Sld *Solid          // solid element
-	Mdl *solid.RjointM1 // material model
-
-	// shape functions evaluations and extrapolator matrices
-	Nmat [][]float64 // [sldNn][rodNn] shape functions of solids @ [N]odes of rod element
-	Pmat [][]float64 // [sldNn][rodNp] shape functions of solids @ integration [P]oints of rod element (for Coulomb model)
-	Emat [][]float64 // [sldNn][sldNp] solid's extrapolation matrix (for Coulomb model)
+	Rod  *Rod            // rod element
+	Segs []*RjointSeg    // segments of rod; one per solid crossed by rod
+	Mdl  *solid.RjointM1 // material model
 
 	// variables for Coulomb model
-	Coulomb bool        // use Coulomb model
-	rodRp   [][]float64 // [rodNp][ndim] natural coordinates of ips of rod w.r.t. solid's system
-	σNo     [][]float64 // [nneSld][nsig] σ at nodes of solid
-	σIp     []float64   // [nsig] σ at ips of rod
-	t1      []float64   // [ndim] traction vectors for σc
-	t2      []float64   // [ndim] traction vectors for σc
+	Coulomb bool      // use Coulomb model
+	σIp     []float64 // [nsig] σ at ips of rod
+	t1      []float64 // [ndim] traction vectors for σc
+	t2      []float64 // [ndim] traction vectors for σc
 
 	// corotational system aligned with rod element
-	e0 [][]float64 // [rodNp][ndim] local directions at each integration point of rod
-	e1 [][]float64 // [rodNp][ndim] local directions at each integration point of rod
-	e2 [][]float64 // [rodNp][ndim] local directions at each integration point of rod
+	e0 [][]float64 // [nip][ndim] local directions at each integration point of joint
+	e1 [][]float64 // [nip][ndim] local directions at each integration point of joint
+	e2 [][]float64 // [nip][ndim] local directions at each integration point of joint
 
 	// auxiliary variables
-	ΔuC [][]float64 // [rodNn][ndim] relative displ. increment of solid @ nodes of rod; Eq (30)
-	Δw  []float64   // [ndim] relative velocity; Eq (32)
-	qb  []float64   // [ndim] resultant traction vector 'holding' the rod @ ip; Eq (34)
-	fC  []float64   // [rodNu] internal/contact forces vector; Eq (34)
+	ΔuC  [][]float64 // [rodNn][ndim] relative displ. increment of solid @ nodes of segment; Eq (30)
+	Δw   []float64   // [ndim] relative velocity; Eq (32)
+	qb   []float64   // [ndim] resultant traction vector 'holding' the rod @ ip; Eq (34)
+	fC   []float64   // [rodNu] internal/contact forces vector; Eq (34)
+	fCs  []float64   // [rodNu] internal/contact forces vector w.r.t nodes of segment
+	segS []float64   // [rodNn] rod shape functions evaluated with the natural coordinates of segment
 
 	// temporary Jacobian matrices. see Eq. (57)
 	Krr [][]float64 // [rodNu][rodNu] Eq. (58)
-	Krs [][]float64 // [rodNu][sldNu] Eq. (59)
-	Ksr [][]float64 // [sldNu][rodNu] Eq. (60)
-	Kss [][]float64 // [sldNu][sldNu] Eq. (61)
 
 	// internal values
 	States    []*solid.OnedState // [nip] internal states
@@ -103,11 +103,10 @@ type Rjoint struct {
 	StatesAux []*solid.OnedState // [nip] backup internal states
 
 	// extra variables for consistent tangent operator
-	Ncns   bool            // use non-consistent model
-	T1     [][]float64     // [rodNp][nsig] tensor (e1 dy e1)
-	T2     [][]float64     // [rodNp][nsig] tensor (e2 dy e2)
-	DσNoDu [][][][]float64 // [sldNn][nsig][sldNn][ndim] ∂σSldNod/∂uSldNod : derivatives of σ @ nodes of solid w.r.t displacements of solid
-	DσDun  [][]float64     // [nsig][ndim] ∂σIp/∂us : derivatives of σ @ ip of solid w.r.t displacements of solid
+	Ncns  bool        // use non-consistent model
+	T1    [][]float64 // [nip][nsig] tensor (e1 dy e1)
+	T2    [][]float64 // [nip][nsig] tensor (e2 dy e2)
+	DσDun [][]float64 // [nsig][ndim] ∂σIp/∂us : derivatives of σ @ ip of solid w.r.t displacements of solid
 }
 
 // initialisation ///////////////////////////////////////////////////////////////////////////////////
@@ -140,22 +139,31 @@ func (o *Rjoint) Id() int { return o.Cell.Id }
 // Connect connects rod/solid elements in this Rjoint
 func (o *Rjoint) Connect(cid2elem []ele.Element, c *inp.Cell) (nnzK int, err error) {
 
-	// get rod and solid elements
+	// get rod element
 	rodId := c.JlinId
-	sldId := c.JsldId
-	o.Rod = cid2elem[rodId].(*Rod)
-	o.Sld = cid2elem[sldId].(*Solid)
+	if rod, ok := cid2elem[rodId].(*Rod); ok {
+		o.Rod = rod
+	}
 	if o.Rod == nil {
 		err = chk.Err("cannot find joint's rod cell with id == %d", rodId)
 		return
 	}
-	if o.Sld == nil {
-		err = chk.Err("cannot find joint's solid cell with id == %d", sldId)
-		return
-	}
 
-	// total number of dofs
-	o.Ny = o.Rod.Nu + o.Sld.Nu
+	// get solid elements
+	sldIds := c.JsldIds
+	if len(sldIds) == 0 {
+		sldIds = []int{c.JsldId}
+	}
+	slds := make([]*Solid, len(sldIds))
+	for i, sldId := range sldIds {
+		if sld, ok := cid2elem[sldId].(*Solid); ok {
+			slds[i] = sld
+		}
+		if slds[i] == nil {
+			err = chk.Err("cannot find joint's solid cell with id == %d", sldId)
+			return
+		}
+	}
 
 	// model
 	mat := o.Sim.MatModels.Get(o.Edat.Mat)
@@ -168,138 +176,104 @@ func (o *Rjoint) Connect(cid2elem []ele.Element, c *inp.Cell) (nnzK int, err err
 	// flag
 	o.Coulomb = o.Mdl.A_μ > 0
 
+	// split rod into segments
+	if len(slds) == 1 {
+		o.Segs = []*RjointSeg{&RjointSeg{Sld: slds[0], Sa: -1, Sb: 1}}
+	} else {
+		o.Segs, err = RjointSplitRod(o.Rod, slds)
+		if err != nil {
+			return
+		}
+	}
+
 	// auxiliary
 	nsig := 2 * o.Ndim
 
 	// rod data
 	rodH := o.Rod.Cell.Shp
-	rodNp := len(o.Rod.IpsElem)
 	rodNn := rodH.Nverts
 	rodNu := o.Rod.Nu
 
-	// solid data
-	sldH := o.Sld.Cell.Shp
-	sldS := sldH.S
-	sldNp := len(o.Sld.IpsElem)
-	sldNn := sldH.Nverts
-	sldNu := o.Sld.Nu
-
-	// shape functions of solid @ nodes of rod
-	o.Nmat = la.MatAlloc(sldNn, rodNn)
-	rodYn := make([]float64, o.Ndim)
-	rodRn := make([]float64, 3)
-	for m := 0; m < rodNn; m++ {
-		for i := 0; i < o.Ndim; i++ {
-			rodYn[i] = o.Rod.X[i][m]
-		}
-		err = sldH.InvMap(rodRn, rodYn, o.Sld.X)
+	// segments data
+	o.Ny = o.Rod.Nu
+	o.Nip = 0
+	for _, seg := range o.Segs {
+		err = seg.Init(o.Rod, o.Nip, o.Coulomb, o.Ncns)
 		if err != nil {
 			return
 		}
-		err = sldH.CalcAtR(o.Sld.X, rodRn, false)
-		if err != nil {
-			return
-		}
-		for n := 0; n < sldNn; n++ {
-			o.Nmat[n][m] = sldH.S[n]
-		}
+		o.Ny += seg.Sld.Nu
+		o.Nip += len(seg.Ips)
+		nnzK += (rodNu + seg.Sld.Nu) * (rodNu + seg.Sld.Nu)
 	}
 
 	// coulomb model => σc depends on p values of solid
 	if o.Coulomb {
-
-		// allocate variables
-		o.Pmat = la.MatAlloc(sldNn, rodNp)
-		o.Emat = la.MatAlloc(sldNn, sldNp)
-		o.rodRp = la.MatAlloc(rodNp, 3)
-		o.σNo = la.MatAlloc(sldNn, nsig)
 		o.σIp = make([]float64, nsig)
 		o.t1 = make([]float64, o.Ndim)
 		o.t2 = make([]float64, o.Ndim)
-
-		// fully consistent model
 		if !o.Ncns {
-			o.T1 = la.MatAlloc(rodNp, nsig)
-			o.T2 = la.MatAlloc(rodNp, nsig)
-			o.DσNoDu = utl.Deep4alloc(sldNn, nsig, sldNn, o.Ndim)
+			o.T1 = la.MatAlloc(o.Nip, nsig)
+			o.T2 = la.MatAlloc(o.Nip, nsig)
 			o.DσDun = la.MatAlloc(nsig, o.Ndim)
 		}
-
-		// extrapolator matrix
-		err = sldH.Extrapolator(o.Emat, o.Sld.IpsElem)
-		if err != nil {
-			return
-		}
-
-		// shape function of solid @ ips of rod
-		for idx, ip := range o.Rod.IpsElem {
-			rodYp := rodH.IpRealCoords(o.Rod.X, ip)
-			err = sldH.InvMap(o.rodRp[idx], rodYp, o.Sld.X)
-			if err != nil {
-				return
-			}
-			err = sldH.CalcAtR(o.Sld.X, o.rodRp[idx], false)
-			if err != nil {
-				return
-			}
-			for n := 0; n < sldNn; n++ {
-				o.Pmat[n][idx] = sldS[n]
-			}
-		}
 	}
 
 	// joint direction @ ip[idx]; corotational system aligned with rod element
-	o.e0 = la.MatAlloc(rodNp, o.Ndim)
-	o.e1 = la.MatAlloc(rodNp, o.Ndim)
-	o.e2 = la.MatAlloc(rodNp, o.Ndim)
+	o.e0 = la.MatAlloc(o.Nip, o.Ndim)
+	o.e1 = la.MatAlloc(o.Nip, o.Ndim)
+	o.e2 = la.MatAlloc(o.Nip, o.Ndim)
 	π := make([]float64, o.Ndim) // Eq. (27)
 	Q := la.MatAlloc(o.Ndim, o.Ndim)
 	α := 666.0
 	Jvec := rodH.Jvec3d[:o.Ndim]
-	for idx, ip := range o.Rod.IpsElem {
+	for _, seg := range o.Segs {
+		for k, ip := range seg.Ips {
 
-		// auxiliary
-		e0, e1, e2 := o.e0[idx], o.e1[idx], o.e2[idx]
+			// auxiliary
+			idx := seg.Ip0 + k
+			e0, e1, e2 := o.e0[idx], o.e1[idx], o.e2[idx]
 
-		// interpolation functions and gradients
-		err = rodH.CalcAtIp(o.Rod.X, ip, true)
-		if err != nil {
-			return
-		}
+			// interpolation functions and gradients
+			err = rodH.CalcAtIp(o.Rod.X, ip, true)
+			if err != nil {
+				return
+			}
 
-		// compute basis vectors
-		J := rodH.J
-		π[0] = Jvec[0] + α
-		π[1] = Jvec[1]
-		e0[0] = Jvec[0] / J
-		e0[1] = Jvec[1] / J
-		if o.Ndim == 3 {
-			π[2] = Jvec[2]
-			e0[2] = Jvec[2] / J
-		}
-		la.MatSetDiag(Q, 1)
-		la.VecOuterAdd(Q, -1, e0, e0) // Q := I - e0 dyad e0
-		la.MatVecMul(e1, 1, Q, π)     // Eq. (29) * norm(E1)
-		la.VecScale(e1, 0, 1.0/la.VecNorm(e1), e1)
-		if o.Ndim == 3 {
-			e2[0] = e0[1]*e1[2] - e0[2]*e1[1]
-			e2[1] = e0[2]*e1[0] - e0[0]*e1[2]
-			e2[2] = e0[0]*e1[1] - e0[1]*e1[0]
-		}
+			// compute basis vectors
+			J := rodH.J
+			π[0] = Jvec[0] + α
+			π[1] = Jvec[1]
+			e0[0] = Jvec[0] / J
+			e0[1] = Jvec[1] / J
+			if o.Ndim == 3 {
+				π[2] = Jvec[2]
+				e0[2] = Jvec[2] / J
+			}
+			la.MatSetDiag(Q, 1)
+			la.VecOuterAdd(Q, -1, e0, e0) // Q := I - e0 dyad e0
+			la.MatVecMul(e1, 1, Q, π)     // Eq. (29) * norm(E1)
+			la.VecScale(e1, 0, 1.0/la.VecNorm(e1), e1)
+			if o.Ndim == 3 {
+				e2[0] = e0[1]*e1[2] - e0[2]*e1[1]
+				e2[1] = e0[2]*e1[0] - e0[0]*e1[2]
+				e2[2] = e0[0]*e1[1] - e0[1]*e1[0]
+			}
 
-		// compute auxiliary tensors
-		if o.Coulomb {
-			e1_dy_e1 := tsr.Alloc2()
-			e2_dy_e2 := tsr.Alloc2()
-			for i := 0; i < o.Ndim; i++ {
-				for j := 0; j < o.Ndim; j++ {
-					e1_dy_e1[i][j] = e1[i] * e1[j]
-					e2_dy_e2[i][j] = e2[i] * e2[j]
+			// compute auxiliary tensors
+			if o.Coulomb {
+				e1_dy_e1 := tsr.Alloc2()
+				e2_dy_e2 := tsr.Alloc2()
+				for i := 0; i < o.Ndim; i++ {
+					for j := 0; j < o.Ndim; j++ {
+						e1_dy_e1[i][j] = e1[i] * e1[j]
+						e2_dy_e2[i][j] = e2[i] * e2[j]
+					}
+				}
+				if !o.Ncns {
+					tsr.Ten2Man(o.T1[idx], e1_dy_e1)
+					tsr.Ten2Man(o.T2[idx], e2_dy_e2)
 				}
-			}
-			if !o.Ncns {
-				tsr.Ten2Man(o.T1[idx], e1_dy_e1)
-				tsr.Ten2Man(o.T2[idx], e2_dy_e2)
 			}
 		}
 	}
@@ -309,12 +283,11 @@ func (o *Rjoint) Connect(cid2elem []ele.Element, c *inp.Cell) (nnzK int, err err
 	o.Δw = make([]float64, o.Ndim)
 	o.qb = make([]float64, o.Ndim)
 	o.fC = make([]float64, rodNu)
+	o.fCs = make([]float64, rodNu)
+	o.segS = make([]float64, rodNn)
 
 	// temporary Jacobian matrices. see Eq. (57)
 	o.Krr = la.MatAlloc(rodNu, rodNu)
-	o.Krs = la.MatAlloc(rodNu, sldNu)
-	o.Ksr = la.MatAlloc(sldNu, rodNu)
-	o.Kss = la.MatAlloc(sldNu, sldNu)
 
 	// debugging
 	//if true {
@@ -323,7 +296,7 @@ func (o *Rjoint) Connect(cid2elem []ele.Element, c *inp.Cell) (nnzK int, err err
 	}
 
 	// success
-	return o.Ny * o.Ny, nil
+	return
 }
 
 // implementation ///////////////////////////////////////////////////////////////////////////////////
@@ -350,53 +323,68 @@ func (o *Rjoint) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 	rodH := o.Rod.Cell.Shp
 	rodS := rodH.S
 	rodNn := rodH.Nverts
-	sldH := o.Sld.Cell.Shp
-	sldNn := sldH.Nverts
 	h := o.Mdl.A_h
 
 	// internal forces vector
 	la.VecFill(o.fC, 0)
 
-	// loop over rod's integration points
+	// loop over segments
 	var coef, τ, qn1, qn2 float64
-	for idx, ip := range o.Rod.IpsElem {
+	for _, seg := range o.Segs {
 
 		// auxiliary
-		e0, e1, e2 := o.e0[idx], o.e1[idx], o.e2[idx]
+		sldNn := seg.Sld.Cell.Shp.Nverts
+		la.VecFill(o.fCs, 0)
 
-		// interpolation functions and gradients
-		err = rodH.CalcAtIp(o.Rod.X, ip, true)
-		if err != nil {
-			return
-		}
-		coef = ip[3] * rodH.J
+		// loop over segment's integration points
+		for k, ip := range seg.Ips {
+
+			// auxiliary
+			idx := seg.Ip0 + k
+			e0, e1, e2 := o.e0[idx], o.e1[idx], o.e2[idx]
+
+			// interpolation functions and gradients
+			err = o.segment_shape(seg, k)
+			if err != nil {
+				return
+			}
+			coef = ip[3] * rodH.J
 
-		// state variables
-		τ = o.States[idx].Sig
-		qn1 = o.States[idx].Phi[0]
-		qn2 = o.States[idx].Phi[1]
+			// state variables
+			τ = o.States[idx].Sig
+			qn1 = o.States[idx].Phi[0]
+			qn2 = o.States[idx].Phi[1]
 
-		// fC vector. Eq. (34)
+			// fC vector. Eq. (34)
+			for i := 0; i < o.Ndim; i++ {
+				o.qb[i] = τ*h*e0[i] + qn1*e1[i] + qn2*e2[i]
+				for m := 0; m < rodNn; m++ {
+					r := i + m*o.Ndim
+					o.fC[r] += coef * rodS[m] * o.qb[i]
+					o.fCs[r] += coef * o.segS[m] * o.qb[i]
+				}
+			}
+		}
+
+		// fS = Nmat*fC  =>  fb := -Nmat*fC
 		for i := 0; i < o.Ndim; i++ {
-			o.qb[i] = τ*h*e0[i] + qn1*e1[i] + qn2*e2[i]
 			for m := 0; m < rodNn; m++ {
 				r := i + m*o.Ndim
-				o.fC[r] += coef * rodS[m] * o.qb[i]
+				for n := 0; n < sldNn; n++ {
+					s := i + n*o.Ndim
+					J := seg.Sld.Umap[s]
+					fb[J] -= seg.Nmat[n][m] * o.fCs[r] // fb := - (fS Eq (36))
+				}
 			}
 		}
 	}
 
-	// fb = -Resid;  fR = -fC  and  fS = Nmat*fC  =>  fb := {fC, -Nmat*fC}
+	// fb = -Resid;  fR = -fC  =>  fb := fC
 	for i := 0; i < o.Ndim; i++ {
 		for m := 0; m < rodNn; m++ {
 			r := i + m*o.Ndim
 			I := o.Rod.Umap[r]
 			fb[I] += o.fC[r] // fb := - (fR == -fC Eq (35))
-			for n := 0; n < sldNn; n++ {
-				s := i + n*o.Ndim
-				J := o.Sld.Umap[s]
-				fb[J] -= o.Nmat[n][m] * o.fC[r] // fb := - (fS Eq (36))
-			}
 		}
 	}
 	return
@@ -409,58 +397,12 @@ func (o *Rjoint) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err e
 	rodH := o.Rod.Cell.Shp
 	rodS := rodH.S
 	rodNn := rodH.Nverts
-	sldH := o.Sld.Cell.Shp
-	sldNn := sldH.Nverts
 	h := o.Mdl.A_h
 	kl := o.Mdl.A_kl
-
-	// compute DσNoDu
 	nsig := 2 * o.Ndim
-	if o.Coulomb && !o.Ncns {
-
-		// clear deep4 structure
-		utl.Deep4set(o.DσNoDu, 0)
-
-		// loop over solid's integration points
-		for idx, ip := range o.Sld.IpsElem {
-
-			// interpolation functions, gradients and variables @ ip
-			err = sldH.CalcAtIp(o.Sld.X, ip, true)
-			if err != nil {
-				return
-			}
 
-			// consistent tangent model matrix
-			err = o.Sld.MdlSmall.CalcD(o.Sld.D, o.Sld.States[idx], firstIt)
-			if err != nil {
-				return
-			}
-
-			// extrapolate derivatives
-			for n := 0; n < sldNn; n++ {
-				DerivSig(o.DσDun, n, o.Ndim, sldH.G, o.Sld.D)
-				for m := 0; m < sldNn; m++ {
-					for i := 0; i < nsig; i++ {
-						for j := 0; j < o.Ndim; j++ {
-							o.DσNoDu[m][i][n][j] += o.Emat[m][idx] * o.DσDun[i][j]
-						}
-					}
-				}
-			}
-		}
-	}
-
-	// zero K matrices
-	for i, _ := range o.Rod.Umap {
-		for j, _ := range o.Rod.Umap {
-			o.Krr[i][j] = 0
-		}
-		for j, _ := range o.Sld.Umap {
-			o.Krs[i][j] = 0
-			o.Ksr[j][i] = 0
-		}
-	}
-	la.MatFill(o.Kss, 0)
+	// zero Krr matrix
+	la.MatFill(o.Krr, 0)
 
 	// auxiliary
 	var coef float64
@@ -474,140 +416,205 @@ func (o *Rjoint) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err e
 	var DτDσc, DσcDu_nj float64
 	var Dp1Du_nj, Dp2Du_nj float64
 
-	// loop over rod's integration points
-	for idx, ip := range o.Rod.IpsElem {
-
-		// auxiliary
-		e0, e1, e2 := o.e0[idx], o.e1[idx], o.e2[idx]
-
-		// interpolation functions and gradients
-		err = rodH.CalcAtIp(o.Rod.X, ip, true)
-		if err != nil {
-			return
-		}
-		coef = ip[3] * rodH.J
-
-		// model derivatives
-		DτDω, DτDσc, err = o.Mdl.CalcD(o.States[idx], firstIt)
-		if err != nil {
-			return
-		}
+	// loop over segments
+	for _, seg := range o.Segs {
 
-		// compute derivatives
-		for j := 0; j < o.Ndim; j++ {
+		// solid data
+		sld := seg.Sld
+		sldH := sld.Cell.Shp
+		sldNn := sldH.Nverts
 
-			// Krr and Ksr; derivatives with respect to ur_nj
-			for n := 0; n < rodNn; n++ {
+		// compute DσNoDu
+		if o.Coulomb && !o.Ncns {
 
-				// ∂wb/∂ur Eq (A.4)
-				Dwb0Dur_nj = -rodS[n] * e0[j]
-				Dwb1Dur_nj = -rodS[n] * e1[j]
-				Dwb2Dur_nj = -rodS[n] * e2[j]
+			// clear deep4 structure
+			utl.Deep4set(seg.DσNoDu, 0)
 
-				// compute ∂■/∂ur derivatives
-				c := j + n*o.Ndim
-				for i := 0; i < o.Ndim; i++ {
+			// loop over solid's integration points
+			for idx, ip := range sld.IpsElem {
 
-					// ∂qb/∂ur Eq (A.2)
-					DqbDur_nij = h*e0[i]*(DτDω*Dwb0Dur_nj) + kl*e1[i]*Dwb1Dur_nj + kl*e2[i]*Dwb2Dur_nj
+				// interpolation functions, gradients and variables @ ip
+				err = sldH.CalcAtIp(sld.X, ip, true)
+				if err != nil {
+					return
+				}
 
-					// Krr := ∂fr/∂ur Eq (58)
-					for m := 0; m < rodNn; m++ {
-						r := i + m*o.Ndim
-						o.Krr[r][c] -= coef * rodS[m] * DqbDur_nij
-					}
+				// consistent tangent model matrix
+				err = sld.small(idx).CalcD(sld.D, sld.States[idx], firstIt)
+				if err != nil {
+					return
+				}
 
-					//  Ksr := ∂fs/∂ur Eq (60)
+				// extrapolate derivatives
+				for n := 0; n < sldNn; n++ {
+					DerivSig(o.DσDun, n, o.Ndim, sldH.G, sld.D)
 					for m := 0; m < sldNn; m++ {
-						r := i + m*o.Ndim
-						for p := 0; p < rodNn; p++ {
-							o.Ksr[r][c] += coef * o.Nmat[m][p] * rodS[p] * DqbDur_nij
+						for i := 0; i < nsig; i++ {
+							for j := 0; j < o.Ndim; j++ {
+								seg.DσNoDu[m][i][n][j] += seg.Emat[m][idx] * o.DσDun[i][j]
+							}
 						}
 					}
 				}
 			}
+		}
 
-			// Krs and Kss
-			for n := 0; n < sldNn; n++ {
+		// zero K matrices
+		la.MatFill(seg.Krs, 0)
+		la.MatFill(seg.Ksr, 0)
+		la.MatFill(seg.Kss, 0)
 
-				// ∂σc/∂us_nj
-				DσcDu_nj = 0
-				if o.Coulomb && !o.Ncns {
+		// loop over segment's integration points
+		for k, ip := range seg.Ips {
 
-					// Eqs (A.10) (A.11) and (A.12)
-					Dp1Du_nj, Dp2Du_nj = 0, 0
-					for m := 0; m < sldNn; m++ {
-						for i := 0; i < nsig; i++ {
-							Dp1Du_nj += o.Pmat[m][idx] * o.T1[idx][i] * o.DσNoDu[m][i][n][j]
-							Dp2Du_nj += o.Pmat[m][idx] * o.T2[idx][i] * o.DσNoDu[m][i][n][j]
-						}
-					}
-					DσcDu_nj = (Dp1Du_nj + Dp2Du_nj) / 2.0
-				}
+			// auxiliary
+			idx := seg.Ip0 + k
+			e0, e1, e2 := o.e0[idx], o.e1[idx], o.e2[idx]
 
-				// ∂wb/∂us Eq (A.5)
-				Dwb0Du_nj, Dwb1Du_nj, Dwb2Du_nj = 0, 0, 0
-				for m := 0; m < rodNn; m++ {
-					Dwb0Du_nj += rodS[m] * o.Nmat[n][m] * e0[j]
-					Dwb1Du_nj += rodS[m] * o.Nmat[n][m] * e1[j]
-					Dwb2Du_nj += rodS[m] * o.Nmat[n][m] * e2[j]
-				}
+			// interpolation functions and gradients
+			err = o.segment_shape(seg, k)
+			if err != nil {
+				return
+			}
+			coef = ip[3] * rodH.J
 
-				// ∂τ/∂us_nj highlighted term in Eq (A.3)
-				DτDu_nj = DτDω * Dwb0Du_nj
-				if !o.Ncns {
-					DτDu_nj += DτDσc * DσcDu_nj
+			// model derivatives
+			DτDω, DτDσc, err = o.Mdl.CalcD(o.States[idx], firstIt)
+			if err != nil {
+				return
+			}
+
+			// compute derivatives
+			for j := 0; j < o.Ndim; j++ {
+
+				// Krr and Ksr; derivatives with respect to ur_nj
+				for n := 0; n < rodNn; n++ {
+
+					// ∂wb/∂ur Eq (A.4)
+					Dwb0Dur_nj = -rodS[n] * e0[j]
+					Dwb1Dur_nj = -rodS[n] * e1[j]
+					Dwb2Dur_nj = -rodS[n] * e2[j]
+
+					// compute ∂■/∂ur derivatives
+					c := j + n*o.Ndim
+					for i := 0; i < o.Ndim; i++ {
+
+						// ∂qb/∂ur Eq (A.2)
+						DqbDur_nij = h*e0[i]*(DτDω*Dwb0Dur_nj) + kl*e1[i]*Dwb1Dur_nj + kl*e2[i]*Dwb2Dur_nj
+
+						// Krr := ∂fr/∂ur Eq (58)
+						for m := 0; m < rodNn; m++ {
+							r := i + m*o.Ndim
+							o.Krr[r][c] -= coef * rodS[m] * DqbDur_nij
+						}
+
+						//  Ksr := ∂fs/∂ur Eq (60)
+						for m := 0; m < sldNn; m++ {
+							r := i + m*o.Ndim
+							for p := 0; p < rodNn; p++ {
+								seg.Ksr[r][c] += coef * seg.Nmat[m][p] * o.segS[p] * DqbDur_nij
+							}
+						}
+					}
 				}
 
-				// compute ∂■/∂us derivatives
-				c := j + n*o.Ndim
-				for i := 0; i < o.Ndim; i++ {
+				// Krs and Kss
+				for n := 0; n < sldNn; n++ {
 
-					// ∂qb/∂us Eq (A.3)
-					DqbDu_nij = h*e0[i]*DτDu_nj + kl*e1[i]*Dwb1Du_nj + kl*e2[i]*Dwb2Du_nj
+					// ∂σc/∂us_nj
+					DσcDu_nj = 0
+					if o.Coulomb && !o.Ncns {
+
+						// Eqs (A.10) (A.11) and (A.12)
+						Dp1Du_nj, Dp2Du_nj = 0, 0
+						for m := 0; m < sldNn; m++ {
+							for i := 0; i < nsig; i++ {
+								Dp1Du_nj += seg.Pmat[m][k] * o.T1[idx][i] * seg.DσNoDu[m][i][n][j]
+								Dp2Du_nj += seg.Pmat[m][k] * o.T2[idx][i] * seg.DσNoDu[m][i][n][j]
+							}
+						}
+						DσcDu_nj = (Dp1Du_nj + Dp2Du_nj) / 2.0
+					}
 
-					// Krs := ∂fr/∂us Eq (59)
+					// ∂wb/∂us Eq (A.5)
+					Dwb0Du_nj, Dwb1Du_nj, Dwb2Du_nj = 0, 0, 0
 					for m := 0; m < rodNn; m++ {
-						r := i + m*o.Ndim
-						o.Krs[r][c] -= coef * rodS[m] * DqbDu_nij
+						Dwb0Du_nj += o.segS[m] * seg.Nmat[n][m] * e0[j]
+						Dwb1Du_nj += o.segS[m] * seg.Nmat[n][m] * e1[j]
+						Dwb2Du_nj += o.segS[m] * seg.Nmat[n][m] * e2[j]
 					}
 
-					// Kss := ∂fs/∂us Eq (61)
-					for m := 0; m < sldNn; m++ {
-						r := i + m*o.Ndim
-						for p := 0; p < rodNn; p++ {
-							o.Kss[r][c] += coef * o.Nmat[m][p] * rodS[p] * DqbDu_nij
+					// ∂τ/∂us_nj highlighted term in Eq (A.3)
+					DτDu_nj = DτDω * Dwb0Du_nj
+					if !o.Ncns {
+						DτDu_nj += DτDσc * DσcDu_nj
+					}
+
+					// compute ∂■/∂us derivatives
+					c := j + n*o.Ndim
+					for i := 0; i < o.Ndim; i++ {
+
+						// ∂qb/∂us Eq (A.3)
+						DqbDu_nij = h*e0[i]*DτDu_nj + kl*e1[i]*Dwb1Du_nj + kl*e2[i]*Dwb2Du_nj
+
+						// Krs := ∂fr/∂us Eq (59)
+						for m := 0; m < rodNn; m++ {
+							r := i + m*o.Ndim
+							seg.Krs[r][c] -= coef * rodS[m] * DqbDu_nij
+						}
+
+						// Kss := ∂fs/∂us Eq (61)
+						for m := 0; m < sldNn; m++ {
+							r := i + m*o.Ndim
+							for p := 0; p < rodNn; p++ {
+								seg.Kss[r][c] += coef * seg.Nmat[m][p] * o.segS[p] * DqbDu_nij
+							}
 						}
 					}
 				}
 			}
 		}
-	}
 
-	// debug
-	//if true {
-	if false {
-		o.debug_print_K()
+		// debug
+		//if true {
+		if false {
+			o.debug_print_K(seg)
+		}
+
+		// add segment's K to sparse matrix Kb
+		for i, I := range o.Rod.Umap {
+			for j, J := range sld.Umap {
+				Kb.Put(I, J, seg.Krs[i][j])
+				Kb.Put(J, I, seg.Ksr[j][i])
+			}
+		}
+		for i, I := range sld.Umap {
+			for j, J := range sld.Umap {
+				Kb.Put(I, J, seg.Kss[i][j])
+			}
+		}
 	}
 
-	// add K to sparse matrix Kb
+	// add Krr to sparse matrix Kb
 	for i, I := range o.Rod.Umap {
 		for j, J := range o.Rod.Umap {
 			Kb.Put(I, J, o.Krr[i][j])
 		}
-		for j, J := range o.Sld.Umap {
-			Kb.Put(I, J, o.Krs[i][j])
-			Kb.Put(J, I, o.Ksr[j][i])
-		}
-	}
-	for i, I := range o.Sld.Umap {
-		for j, J := range o.Sld.Umap {
-			Kb.Put(I, J, o.Kss[i][j])
-		}
 	}
 	return
 }
 
+// QuadFormK computes aᵀ K b with the Krr, Krs, Ksr and Kss matrices computed by the last call to AddToKb
+func (o *Rjoint) QuadFormK(a, b []float64) (res float64, ok bool) {
+	res = kmat_quadform(o.Krr, o.Rod.Umap, o.Rod.Umap, a, b)
+	for _, seg := range o.Segs {
+		res += kmat_quadform(seg.Krs, o.Rod.Umap, seg.Sld.Umap, a, b)
+		res += kmat_quadform(seg.Ksr, seg.Sld.Umap, o.Rod.Umap, a, b)
+		res += kmat_quadform(seg.Kss, seg.Sld.Umap, seg.Sld.Umap, a, b)
+	}
+	return res, true
+}
+
 // Update perform (tangent) update
 func (o *Rjoint) Update(sol *ele.Solution) (err error) {
 
@@ -616,111 +623,118 @@ func (o *Rjoint) Update(sol *ele.Solution) (err error) {
 	rodH := o.Rod.Cell.Shp
 	rodS := rodH.S
 	rodNn := rodH.Nverts
-	sldH := o.Sld.Cell.Shp
-	sldNn := sldH.Nverts
 	kl := o.Mdl.A_kl
 
-	// extrapolate stresses at integration points of solid element to its nodes
-	if o.Coulomb {
-		la.MatFill(o.σNo, 0)
-		for idx, _ := range o.Sld.IpsElem {
-			σ := o.Sld.States[idx].Sig
-			for i := 0; i < nsig; i++ {
-				for m := 0; m < sldNn; m++ {
-					o.σNo[m][i] += o.Emat[m][idx] * σ[i]
-				}
-			}
-		}
-	}
-
-	// interpolate Δu of solid to find ΔuC @ rod node; Eq (30)
+	// loop over segments
 	var r, I int
-	for m := 0; m < rodNn; m++ {
-		for i := 0; i < o.Ndim; i++ {
-			o.ΔuC[m][i] = 0
-			for n := 0; n < sldNn; n++ {
-				r = i + n*o.Ndim
-				I = o.Sld.Umap[r]
-				o.ΔuC[m][i] += o.Nmat[n][m] * sol.ΔY[I] // Eq (30)
-			}
-		}
-	}
-
-	// loop over ips of rod
 	var Δwb0, Δwb1, Δwb2, σc float64
-	for idx, ip := range o.Rod.IpsElem {
+	for _, seg := range o.Segs {
 
-		// auxiliary
-		e0, e1, e2 := o.e0[idx], o.e1[idx], o.e2[idx]
+		// solid data
+		sld := seg.Sld
+		sldNn := sld.Cell.Shp.Nverts
 
-		// interpolation functions and gradients
-		err = rodH.CalcAtIp(o.Rod.X, ip, true)
-		if err != nil {
-			return
+		// extrapolate stresses at integration points of solid element to its nodes
+		if o.Coulomb {
+			la.MatFill(seg.σNo, 0)
+			for idx, _ := range sld.IpsElem {
+				σ := sld.States[idx].Sig
+				for i := 0; i < nsig; i++ {
+					for m := 0; m < sldNn; m++ {
+						seg.σNo[m][i] += seg.Emat[m][idx] * σ[i]
+					}
+				}
+			}
 		}
 
-		// interpolated relative displacements @ ip of join; Eqs (31) and (32)
-		for i := 0; i < o.Ndim; i++ {
-			o.Δw[i] = 0
-			for m := 0; m < rodNn; m++ {
-				r = i + m*o.Ndim
-				I = o.Rod.Umap[r]
-				o.Δw[i] += rodS[m] * (o.ΔuC[m][i] - sol.ΔY[I]) // Eq (31) and (32)
+		// interpolate Δu of solid to find ΔuC @ (segment) rod node; Eq (30)
+		for m := 0; m < rodNn; m++ {
+			for i := 0; i < o.Ndim; i++ {
+				o.ΔuC[m][i] = 0
+				for n := 0; n < sldNn; n++ {
+					r = i + n*o.Ndim
+					I = sld.Umap[r]
+					o.ΔuC[m][i] += seg.Nmat[n][m] * sol.ΔY[I] // Eq (30)
+				}
 			}
 		}
 
-		// relative displacements in the corotational system
-		Δwb0, Δwb1, Δwb2 = 0, 0, 0
-		for i := 0; i < o.Ndim; i++ {
-			Δwb0 += e0[i] * o.Δw[i]
-			Δwb1 += e1[i] * o.Δw[i]
-			Δwb2 += e2[i] * o.Δw[i]
-		}
+		// loop over ips of segment
+		for k, _ := range seg.Ips {
 
-		// new confining stress
-		σc = 0.0
-		if o.Coulomb {
+			// auxiliary
+			idx := seg.Ip0 + k
+			e0, e1, e2 := o.e0[idx], o.e1[idx], o.e2[idx]
 
-			// calculate σIp
-			for j := 0; j < nsig; j++ {
-				o.σIp[j] = 0
-				for n := 0; n < sldNn; n++ {
-					o.σIp[j] += o.Pmat[n][idx] * o.σNo[n][j]
-				}
+			// interpolation functions and gradients
+			err = o.segment_shape(seg, k)
+			if err != nil {
+				return
 			}
 
-			// calculate t1 and t2
+			// interpolated relative displacements @ ip of join; Eqs (31) and (32)
 			for i := 0; i < o.Ndim; i++ {
-				o.t1[i], o.t2[i] = 0, 0
-				for j := 0; j < o.Ndim; j++ {
-					o.t1[i] += tsr.M2T(o.σIp, i, j) * e1[j]
-					o.t2[i] += tsr.M2T(o.σIp, i, j) * e2[j]
+				o.Δw[i] = 0
+				for m := 0; m < rodNn; m++ {
+					r = i + m*o.Ndim
+					I = o.Rod.Umap[r]
+					o.Δw[i] += o.segS[m]*o.ΔuC[m][i] - rodS[m]*sol.ΔY[I] // Eq (31) and (32)
 				}
 			}
 
-			// calculate p1, p2 and σcNew
-			p1, p2 := 0.0, 0.0
+			// relative displacements in the corotational system
+			Δwb0, Δwb1, Δwb2 = 0, 0, 0
 			for i := 0; i < o.Ndim; i++ {
-				p1 += o.t1[i] * e1[i]
-				p2 += o.t2[i] * e2[i]
+				Δwb0 += e0[i] * o.Δw[i]
+				Δwb1 += e1[i] * o.Δw[i]
+				Δwb2 += e2[i] * o.Δw[i]
 			}
 
-			// σcNew
-			σc = -(p1 + p2) / 2.0
-		}
+			// new confining stress
+			σc = 0.0
+			if o.Coulomb {
 
-		// update model
-		err = o.Mdl.Update(o.States[idx], σc, Δwb0)
-		if err != nil {
-			return
-		}
-		o.States[idx].Phi[0] += kl * Δwb1 // qn1
-		o.States[idx].Phi[1] += kl * Δwb2 // qn2
+				// calculate σIp
+				for j := 0; j < nsig; j++ {
+					o.σIp[j] = 0
+					for n := 0; n < sldNn; n++ {
+						o.σIp[j] += seg.Pmat[n][k] * seg.σNo[n][j]
+					}
+				}
 
-		// debugging
-		//if true {
-		if false {
-			o.debug_update(idx, Δwb0, Δwb1, Δwb2, σc)
+				// calculate t1 and t2
+				for i := 0; i < o.Ndim; i++ {
+					o.t1[i], o.t2[i] = 0, 0
+					for j := 0; j < o.Ndim; j++ {
+						o.t1[i] += tsr.M2T(o.σIp, i, j) * e1[j]
+						o.t2[i] += tsr.M2T(o.σIp, i, j) * e2[j]
+					}
+				}
+
+				// calculate p1, p2 and σcNew
+				p1, p2 := 0.0, 0.0
+				for i := 0; i < o.Ndim; i++ {
+					p1 += o.t1[i] * e1[i]
+					p2 += o.t2[i] * e2[i]
+				}
+
+				// σcNew
+				σc = -(p1 + p2) / 2.0
+			}
+
+			// update model
+			err = o.Mdl.Update(o.States[idx], σc, Δwb0)
+			if err != nil {
+				return
+			}
+			o.States[idx].Phi[0] += kl * Δwb1 // qn1
+			o.States[idx].Phi[1] += kl * Δwb2 // qn2
+
+			// debugging
+			//if true {
+			if false {
+				o.debug_update(idx, Δwb0, Δwb1, Δwb2, σc)
+			}
 		}
 	}
 	return
@@ -730,11 +744,10 @@ func (o *Rjoint) Update(sol *ele.Solution) (err error) {
 
 // SetIniIvs sets initial ivs for given values in sol and ivs map
 func (o *Rjoint) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
-	nip := len(o.Rod.IpsElem)
-	o.States = make([]*solid.OnedState, nip)
-	o.StatesBkp = make([]*solid.OnedState, nip)
-	o.StatesAux = make([]*solid.OnedState, nip)
-	for i := 0; i < nip; i++ {
+	o.States = make([]*solid.OnedState, o.Nip)
+	o.StatesBkp = make([]*solid.OnedState, o.Nip)
+	o.StatesAux = make([]*solid.OnedState, o.Nip)
+	for i := 0; i < o.Nip; i++ {
 		o.States[i], _ = o.Mdl.InitIntVars1D()
 		o.StatesBkp[i] = o.States[i].GetCopy()
 		o.StatesAux[i] = o.States[i].GetCopy()
@@ -793,44 +806,178 @@ func (o *Rjoint) Decode(dec utl.Decoder) (err error) {
 
 // OutIpCoords returns the coordinates of integration points
 func (o *Rjoint) OutIpCoords() (C [][]float64) {
-	return o.Rod.OutIpCoords()
+	C = make([][]float64, o.Nip)
+	for _, seg := range o.Segs {
+		for k, ip := range seg.Ips {
+			C[seg.Ip0+k] = o.Rod.Cell.Shp.IpRealCoords(o.Rod.X, ip)
+		}
+	}
+	return
 }
 
 // OutIpKeys returns the integration points' keys
+//  tau  -- bond stress τ
+//  ompb -- accumulated plastic slip
+//  slip -- slip ω = e0 · (u_sld - u_rod); i.e. relative displacement along the rod
+//  N    -- axial force of rod (interpolated from the integration points of the rod)
 func (o *Rjoint) OutIpKeys() []string {
-	return []string{"tau", "ompb"}
+	return []string{"tau", "ompb", "slip", "N"}
 }
 
 // OutIpVals returns the integration points' values corresponding to keys
 func (o *Rjoint) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
-	nip := len(o.Rod.IpsElem)
-	for idx, _ := range o.Rod.IpsElem {
-		M.Set("tau", idx, nip, o.States[idx].Sig)
-		M.Set("ompb", idx, nip, o.States[idx].Alp[0])
+	for idx := 0; idx < o.Nip; idx++ {
+		M.Set("tau", idx, o.Nip, o.States[idx].Sig)
+		M.Set("ompb", idx, o.Nip, o.States[idx].Alp[0])
+	}
+	ω, err := o.out_slip(sol)
+	if err != nil {
+		return
+	}
+	A := o.Rod.Mdl.GetA()
+	for _, seg := range o.Segs {
+		for k, ip := range seg.Ips {
+			idx := seg.Ip0 + k
+			M.Set("slip", idx, o.Nip, ω[idx])
+			M.Set("N", idx, o.Nip, A*o.out_rodσ(ip[0]))
+		}
 	}
 }
 
+// OutIpArcLengths returns the parametric coordinate s (arc length from the first node of the rod)
+// of each integration point and the length L of the rod; e.g. to plot profiles along the rod
+func (o *Rjoint) OutIpArcLengths() (s []float64, L float64, err error) {
+	s = make([]float64, o.Nip)
+	for _, seg := range o.Segs {
+		for k, ip := range seg.Ips {
+			s[seg.Ip0+k], err = o.rod_arclength(ip[0])
+			if err != nil {
+				return
+			}
+		}
+	}
+	L, err = o.rod_arclength(1)
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// segment_shape computes the rod shape functions (S, J, ...) @ integration point k of segment and
+// the rod shape functions evaluated with the natural coordinates of the segment (segS)
+func (o *Rjoint) segment_shape(seg *RjointSeg, k int) (err error) {
+	rodH := o.Rod.Cell.Shp
+	rodH.Func(o.segS, rodH.DSdR, seg.Zps[k], false, -1)
+	return rodH.CalcAtIp(o.Rod.X, seg.Ips[k], true)
+}
+
+// out_slip computes the slip ω = e0 · (u_sld - u_rod) @ integration points with the total
+// displacements; see Update
+func (o *Rjoint) out_slip(sol *ele.Solution) (ω []float64, err error) {
+	rodH := o.Rod.Cell.Shp
+	rodS := rodH.S
+	rodNn := rodH.Nverts
+	uC := la.MatAlloc(rodNn, o.Ndim)
+	ω = make([]float64, o.Nip)
+	for _, seg := range o.Segs {
+
+		// interpolate u of solid @ (segment) rod nodes
+		sld := seg.Sld
+		sldNn := sld.Cell.Shp.Nverts
+		for m := 0; m < rodNn; m++ {
+			for i := 0; i < o.Ndim; i++ {
+				for n := 0; n < sldNn; n++ {
+					uC[m][i] += seg.Nmat[n][m] * sol.Y[sld.Umap[i+n*o.Ndim]]
+				}
+			}
+		}
+
+		// relative displacements along the rod @ ips of segment
+		for k, _ := range seg.Ips {
+			idx := seg.Ip0 + k
+			err = o.segment_shape(seg, k)
+			if err != nil {
+				return
+			}
+			for i := 0; i < o.Ndim; i++ {
+				w := 0.0
+				for m := 0; m < rodNn; m++ {
+					w += o.segS[m]*uC[m][i] - rodS[m]*sol.Y[o.Rod.Umap[i+m*o.Ndim]]
+				}
+				ω[idx] += o.e0[idx][i] * w
+			}
+		}
+		la.MatFill(uC, 0)
+	}
+	return
+}
+
+// out_rodσ returns the stress of the rod @ natural coordinate r (w.r.t rod) by linear interpolation
+// of the stresses at the integration points of the rod; constant beyond the first/last point
+func (o *Rjoint) out_rodσ(r float64) float64 {
+	ia, ib := -1, -1
+	for i, ip := range o.Rod.IpsElem {
+		if ip[0] <= r && (ia < 0 || ip[0] > o.Rod.IpsElem[ia][0]) {
+			ia = i
+		}
+		if ip[0] >= r && (ib < 0 || ip[0] < o.Rod.IpsElem[ib][0]) {
+			ib = i
+		}
+	}
+	if ia < 0 {
+		return o.Rod.States[ib].Sig
+	}
+	if ib < 0 || ia == ib {
+		return o.Rod.States[ia].Sig
+	}
+	ra, rb := o.Rod.IpsElem[ia][0], o.Rod.IpsElem[ib][0]
+	σa, σb := o.Rod.States[ia].Sig, o.Rod.States[ib].Sig
+	return σa + (σb-σa)*(r-ra)/(rb-ra)
+}
+
+// rod_arclength computes the arc length along the rod from its first node (r = -1) to the point
+// with natural coordinate r (w.r.t rod) using a 3-point Gauss-Legendre rule
+func (o *Rjoint) rod_arclength(r float64) (s float64, err error) {
+	rodH := o.Rod.Cell.Shp
+	ip := make(shp.Ipoint, 4)
+	h := (r + 1.0) / 2.0
+	ξ := []float64{-math.Sqrt(0.6), 0, math.Sqrt(0.6)}
+	w := []float64{5.0 / 9.0, 8.0 / 9.0, 5.0 / 9.0}
+	for g := 0; g < 3; g++ {
+		ip[0] = -1.0 + h*(1.0+ξ[g])
+		err = rodH.CalcAtIp(o.Rod.X, ip, true)
+		if err != nil {
+			return
+		}
+		s += w[g] * rodH.J * h
+	}
+	return
+}
+
 // debugging ////////////////////////////////////////////////////////////////////////////////////////
 
 func (o *Rjoint) debug_print_init() {
-	sldNn := o.Sld.Cell.Shp.Nverts
 	rodNn := o.Rod.Cell.Shp.Nverts
-	rodNp := len(o.Rod.IpsElem)
-	io.Pf("Nmat =\n")
-	for i := 0; i < sldNn; i++ {
-		for j := 0; j < rodNn; j++ {
-			io.Pf("%g ", o.Nmat[i][j])
+	for iseg, seg := range o.Segs {
+		sldNn := seg.Sld.Cell.Shp.Nverts
+		io.Pf("segment %d: solid=%d  s=[%g, %g]\n", iseg, seg.Sld.Id(), seg.Sa, seg.Sb)
+		io.Pf("Nmat =\n")
+		for i := 0; i < sldNn; i++ {
+			for j := 0; j < rodNn; j++ {
+				io.Pf("%g ", seg.Nmat[i][j])
+			}
+			io.Pf("\n")
 		}
-		io.Pf("\n")
-	}
-	io.Pf("\nPmat =\n")
-	for i := 0; i < sldNn; i++ {
-		for j := 0; j < rodNp; j++ {
-			io.Pf("%g ", o.Pmat[i][j])
+		if o.Coulomb {
+			io.Pf("\nPmat =\n")
+			for i := 0; i < sldNn; i++ {
+				for j := 0; j < len(seg.Ips); j++ {
+					io.Pf("%g ", seg.Pmat[i][j])
+				}
+				io.Pf("\n")
+			}
 		}
 		io.Pf("\n")
 	}
-	io.Pf("\n")
 	la.PrintMat("e0", o.e0, "%20.13f", false)
 	io.Pf("\n")
 	la.PrintMat("e1", o.e1, "%20.13f", false)
@@ -838,23 +985,24 @@ func (o *Rjoint) debug_print_init() {
 	la.PrintMat("e2", o.e2, "%20.13f", false)
 }
 
-func (o *Rjoint) debug_print_K() {
-	sldNn := o.Sld.Cell.Shp.Nverts
+func (o *Rjoint) debug_print_K(seg *RjointSeg) {
+	sldNn := seg.Sld.Cell.Shp.Nverts
 	rodNn := o.Rod.Cell.Shp.Nverts
-	K := la.MatAlloc(o.Ny, o.Ny)
-	start := o.Sld.Nu
+	ny := o.Rod.Nu + seg.Sld.Nu
+	K := la.MatAlloc(ny, ny)
+	start := seg.Sld.Nu
 	for i := 0; i < o.Ndim; i++ {
 		for m := 0; m < sldNn; m++ {
 			r := i + m*o.Ndim
 			for j := 0; j < o.Ndim; j++ {
 				for n := 0; n < sldNn; n++ {
 					c := j + n*o.Ndim
-					K[r][c] = o.Kss[r][c]
+					K[r][c] = seg.Kss[r][c]
 				}
 				for n := 0; n < rodNn; n++ {
 					c := j + n*o.Ndim
-					K[r][start+c] = o.Ksr[r][c]
-					K[start+c][r] = o.Krs[c][r]
+					K[r][start+c] = seg.Ksr[r][c]
+					K[start+c][r] = seg.Krs[c][r]
 				}
 			}
 		}
@@ -879,7 +1027,9 @@ func (o *Rjoint) debug_update(idx int, Δwb0, Δwb1, Δwb2, σc float64) {
 	qn2 := o.States[idx].Phi[1]
 	la.PrintVec("Δw", o.Δw, "%13.10f", false)
 	io.Pf("Δwb0=%13.10f Δwb1=%13.10f Δwb2=%13.10f\n", Δwb0, Δwb1, Δwb2)
-	la.PrintVec("σIp", o.σIp, "%13.10f", false)
-	io.Pf("σc=%13.10f t1=%13.10f t2=%13.10f\n", σc, o.t1, o.t2)
+	if o.Coulomb {
+		la.PrintVec("σIp", o.σIp, "%13.10f", false)
+		io.Pf("σc=%13.10f t1=%13.10f t2=%13.10f\n", σc, o.t1, o.t2)
+	}
 	io.Pf("τ=%13.10f qn1=%13.10f qn2=%13.10f\n", τ, qn1, qn2)
 }
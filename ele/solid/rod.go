@@ -5,6 +5,8 @@
 package solid
 
 import (
+	"math"
+
 	"github.com/cpmech/gofem/ele"
 	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/mdl/solid"
@@ -44,11 +46,24 @@ type Rod struct {
 	StatesBkp []*solid.OnedState
 	StatesAux []*solid.OnedState
 
+	// prestress
+	Pre   bool     // with prestress: the axial force is prescribed until Tlock
+	Ppre  float64  // prestress force
+	Pfcn  fun.Func // [optional] function multiplying the prestress force
+	Tlock float64  // time of locking of anchorage; prescribed force during the whole stage if <= 0
+
+	// imposed strains: εp = ε0 + α (T - T0)
+	Therm *solid.ThermalStrain // thermal strains data; nil if material has no "alpT" parameter
+	Tfcn  fun.Func             // temperature function T(t,x) evaluated at ips; set via "temp" element condition
+	E0fcn fun.Func             // prestrain (lack-of-fit) function ε0(t,x) evaluated at ips; set via "eps0" element condition
+	Timp  float64              // time corresponding to the imposed strains already included in States
+
 	// scratchpad. computed @ each ip
-	grav []float64 // [ndim] gravity vector
-	us   []float64 // [ndim] displacements @ ip
-	fi   []float64 // [nu] internal forces
-	ue   []float64 // local u vector
+	grav []float64   // [ndim] gravity vector
+	us   []float64   // [ndim] displacements @ ip
+	fi   []float64   // [nu] internal forces
+	ue   []float64   // local u vector
+	xip  [][]float64 // [nip][ndim] coordinates of ips
 }
 
 // register element
@@ -97,6 +112,7 @@ func init() {
 			chk.Panic("cannot find material %q for Rod {tag=%d, id=%d}\n", edat.Mat, cell.Tag, cell.Id)
 		}
 		o.Mdl = mat.Sld.(solid.OneD)
+		o.Therm = solid.NewThermalStrain(mat.SldPrms)
 
 		// integration points
 		var err error
@@ -114,6 +130,7 @@ func init() {
 		o.grav = make([]float64, o.Ndim)
 		o.us = make([]float64, o.Ndim)
 		o.fi = make([]float64, o.Nu)
+		o.xip = o.OutIpCoords()
 
 		// return new element
 		return &o
@@ -144,8 +161,16 @@ func (o *Rod) InterpStarVars(sol *ele.Solution) (err error) {
 
 // SetEleConds set element conditions
 func (o *Rod) SetEleConds(key string, f fun.Func, extra string) (err error) {
-	if key == "g" {
+	switch key {
+	case "g":
 		o.Gfcn = f
+	case "temp":
+		if o.Therm == nil {
+			return chk.Err("temperature of rod # %d requires the thermal expansion coefficient \"alpT\" of its material", o.Id())
+		}
+		o.Tfcn = f
+	case "eps0":
+		o.E0fcn = f
 	}
 	return
 }
@@ -156,6 +181,8 @@ func (o *Rod) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 	// for each integration point
 	A := o.Mdl.GetA()
 	nverts := o.Cell.Shp.Nverts
+	pending := o.imposing() && sol.T != o.Timp
+	var E float64
 	for idx, ip := range o.IpsElem {
 
 		// interpolation functions, gradients and variables @ ip
@@ -170,6 +197,15 @@ func (o *Rod) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 		G := o.Cell.Shp.Gvec
 		σ := o.States[idx].Sig
 
+		// fixed-end forces due to the increment of imposed strains not yet included in States
+		if pending {
+			E, _, err = o.Mdl.CalcD(o.States[idx], false)
+			if err != nil {
+				return
+			}
+			σ -= E * o.imposed_incr(idx, sol)
+		}
+
 		// update fb with internal forces
 		for m := 0; m < nverts; m++ {
 			for i := 0; i < o.Ndim; i++ {
@@ -233,13 +269,28 @@ func (o *Rod) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err erro
 	return
 }
 
+// QuadFormK computes aᵀ K b with the K matrix computed by the last call to AddToKb
+func (o *Rod) QuadFormK(a, b []float64) (res float64, ok bool) {
+	return kmat_quadform(o.K, o.Umap, o.Umap, a, b), true
+}
+
 // Update perform (tangent) update
 func (o *Rod) Update(sol *ele.Solution) (err error) {
 
 	// for each integration point
 	nverts := o.Cell.Shp.Nverts
+	imposing := o.imposing()
 	for idx, _ := range o.IpsElem {
 
+		// stressing
+		if o.Pre && (o.Tlock <= 0 || sol.T <= o.Tlock+1e-10) {
+			err = o.prestress_update(idx, sol.T)
+			if err != nil {
+				return
+			}
+			continue
+		}
+
 		// interpolation functions, gradients and variables @ ip
 		err = o.ipvars(idx, sol)
 		if err != nil {
@@ -260,15 +311,27 @@ func (o *Rod) Update(sol *ele.Solution) (err error) {
 			}
 		}
 
+		// subtract imposed strains
+		if imposing {
+			Δε -= o.imposed_incr(idx, sol)
+		}
+
 		// call model update => update stresses
 		err = o.Mdl.Update(o.States[idx], 0.0, Δε, 0)
 		if err != nil {
 			return
 		}
 	}
+	o.Timp = sol.T
 	return
 }
 
+// SetPrestress sets the prestress force (tension positive) to be prescribed until tlock
+//  Note: the tangent stiffness is kept during stressing in order to avoid singular matrices
+func (o *Rod) SetPrestress(P float64, f fun.Func, tlock float64) {
+	o.Pre, o.Ppre, o.Pfcn, o.Tlock = true, P, f, tlock
+}
+
 // internal variables ///////////////////////////////////////////////////////////////////////////////
 
 // SetIniIvs sets initial ivs for given values in sol and ivs map
@@ -348,6 +411,9 @@ func (o *Rod) Decode(dec utl.Decoder) (err error) {
 	if err != nil {
 		return
 	}
+	if len(o.States) != len(o.IpsElem) {
+		return chk.Err("number of states (%d) of rod element {tag=%d, id=%d} is different than the number of integration points (%d)", len(o.States), o.Cell.Tag, o.Cell.Id, len(o.IpsElem))
+	}
 	return o.BackupIvs(false)
 }
 
@@ -373,6 +439,16 @@ func (o *Rod) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
 	}
 }
 
+// MemberForces returns the axial forces N (tension positive) at integration points
+func (o *Rod) MemberForces(sol *ele.Solution) (F map[string][]float64) {
+	A := o.Mdl.GetA()
+	N := make([]float64, len(o.IpsElem))
+	for idx, _ := range o.IpsElem {
+		N[idx] = A * o.States[idx].Sig
+	}
+	return map[string][]float64{"N": N}
+}
+
 // auxiliary ////////////////////////////////////////////////////////////////////////////////////////
 
 // ipvars computes current values @ integration points. idx == index of integration point
@@ -403,3 +479,55 @@ func (o *Rod) ipvars(idx int, sol *ele.Solution) (err error) {
 	}
 	return
 }
+
+// imposing returns whether this rod has imposed strains; i.e. prestrain or temperature loading
+func (o *Rod) imposing() bool {
+	return o.E0fcn != nil || o.Tfcn != nil
+}
+
+// imposed_incr computes the increment of imposed strains εp = ε0 + α (T - T0) @ integration
+// point idx from the beginning (t-Δt) to the end (t) of the current increment
+func (o *Rod) imposed_incr(idx int, sol *ele.Solution) (Δεp float64) {
+	x, t, told := o.xip[idx], sol.T, sol.T-sol.Dt
+	if o.E0fcn != nil {
+		Δεp += o.E0fcn.F(t, x) - o.E0fcn.F(told, x)
+	}
+	if o.Tfcn != nil {
+		Δεp += o.Therm.Strain(o.Tfcn.F(t, x)) - o.Therm.Strain(o.Tfcn.F(told, x))
+	}
+	return
+}
+
+// prestress_update updates the state @ integration point idx such that the axial force is equal to
+// the prestress force. The strain increment is found by means of Newton's method using the model
+//  Note: the state must correspond to the last converged state
+func (o *Rod) prestress_update(idx int, t float64) (err error) {
+	P := o.Ppre
+	if o.Pfcn != nil {
+		P *= o.Pfcn.F(t, nil)
+	}
+	σp := P / o.Mdl.GetA()
+	conv := o.States[idx].GetCopy()
+	var Δε, E float64
+	maxit := 20
+	for it := 0; it < maxit; it++ {
+		o.States[idx].Set(conv)
+		err = o.Mdl.Update(o.States[idx], 0.0, Δε, 0)
+		if err != nil {
+			return
+		}
+		r := σp - o.States[idx].Sig
+		if math.Abs(r) <= 1e-10*(1.0+math.Abs(σp)) {
+			return
+		}
+		E, _, err = o.Mdl.CalcD(o.States[idx], false)
+		if err != nil {
+			return
+		}
+		if E <= 0 {
+			return chk.Err("cannot prestress rod # %d because its tangent modulus is not positive (E = %g)", o.Cell.Id, E)
+		}
+		Δε += r / E
+	}
+	return chk.Err("prestress of rod # %d did not converge after %d iterations", o.Cell.Id, maxit)
+}
@@ -0,0 +1,196 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// hourglass base vectors h_α evaluated at the vertices of qua4 and hex8 cells: ξη, ηζ, ζξ and ξηζ
+var (
+	hg_base_qua4 = [][]float64{
+		{1, -1, 1, -1},
+	}
+	hg_base_hex8 = [][]float64{
+		{1, 1, -1, -1, -1, -1, 1, 1},
+		{1, -1, -1, 1, -1, 1, 1, -1},
+		{1, -1, 1, -1, 1, -1, 1, -1},
+		{-1, 1, -1, 1, 1, -1, 1, -1},
+	}
+)
+
+// hourglass_init initialises the hourglass control of qua4 and hex8 cells (Flanagan and Belytschko 1981)
+//  The hourglass shape vectors are
+//     γ_α = (h_α - (h_α・x_i) b_i) / nverts
+//  where b_i = ∂N/∂x_i at the centre of the cell and x_i are the vertices coordinates. The hourglass
+//  modes q_αi = γ_α・u_i are resisted by the (stiffness-type) forces
+//     f_i = c Σ_α q_αi γ_α   with   c = κ・D_00・V・(b・b) / ndim
+//  where D_00 is the elastic modulus λ+2G (for linear elasticity) and V is the volume of the cell
+//  Note: (1) the stabilisation is switched on for one-point qua4 and hex8 cells with κ = 0.05 by
+//            default; the coefficient can be given with the "!hg:κ" extra flag ("!hg:0" => off)
+//        (2) the shape vectors are computed for all one-point qua4 and hex8 cells (even without
+//            stabilisation), such that the hourglass ratio can be checked; see HourglassRatio
+func (o *Solid) hourglass_init(edat *inp.ElemData, axisym bool) {
+
+	// check cell type
+	var base [][]float64
+	switch o.Cell.Shp.Type {
+	case "qua4":
+		base = hg_base_qua4
+	case "hex8":
+		base = hg_base_hex8
+	}
+	onept := base != nil && len(o.IpsElem) == 1
+	κ := 0.0
+	if onept {
+		κ = 0.05
+	}
+	if val, found := io.Keycode(edat.Extra, "hg"); found {
+		κ = io.Atof(val)
+	}
+	if κ > 0 && !onept {
+		chk.Panic("hourglass stabilisation is only available for one-point qua4 and hex8 cells. cell %d is %q with %d integration points", o.Id(), o.Cell.Shp.Type, len(o.IpsElem))
+	}
+	if κ > 0 && axisym {
+		chk.Panic("hourglass stabilisation is not available for axisymmetric problems")
+	}
+	if !onept {
+		return
+	}
+	o.HgCoef = κ
+
+	// derivatives of shape functions and volume at the centre of cell
+	err := o.Cell.Shp.CalcAtIp(o.X, shp.Ipoint{0, 0, 0, 0}, true)
+	if err != nil {
+		chk.Panic("cannot compute hourglass shape vectors of cell %d:\n%v", o.Id(), err)
+	}
+	nverts := o.Cell.Shp.Nverts
+	o.HgG = la.MatAlloc(nverts, o.Ndim)
+	la.MatCopy(o.HgG, 1, o.Cell.Shp.G)
+	o.HgVol = o.Cell.Shp.J * math.Pow(2, float64(o.Ndim)) * o.Thickness
+	o.HgGG = 0
+	for m := 0; m < nverts; m++ {
+		for i := 0; i < o.Ndim; i++ {
+			o.HgGG += o.HgG[m][i] * o.HgG[m][i]
+		}
+	}
+
+	// shape vectors
+	o.HgGam = la.MatAlloc(len(base), nverts)
+	for α, h := range base {
+		for m := 0; m < nverts; m++ {
+			o.HgGam[α][m] = h[m]
+			for i := 0; i < o.Ndim; i++ {
+				var hx float64 // h_α・x_i
+				for n := 0; n < nverts; n++ {
+					hx += h[n] * o.X[i][n]
+				}
+				o.HgGam[α][m] -= hx * o.HgG[m][i]
+			}
+			o.HgGam[α][m] /= float64(nverts)
+		}
+	}
+}
+
+// hourglass_coef computes the coefficient c of the hourglass stiffness
+func (o *Solid) hourglass_coef() (c float64, err error) {
+	err = o.small(0).CalcD(o.D, o.States[0], true)
+	if err != nil {
+		return
+	}
+	c = o.HgCoef * o.D[0][0] * o.HgVol * o.HgGG / float64(o.Ndim)
+	return
+}
+
+// hourglass_add_to_rhs adds the hourglass resisting forces to fb
+func (o *Solid) hourglass_add_to_rhs(fb []float64, sol *ele.Solution) (err error) {
+	if o.HgCoef <= 0 {
+		return
+	}
+	c, err := o.hourglass_coef()
+	if err != nil {
+		return
+	}
+	nverts := o.Cell.Shp.Nverts
+	for _, γ := range o.HgGam {
+		for i := 0; i < o.Ndim; i++ {
+			var q float64 // hourglass mode q_αi
+			for m := 0; m < nverts; m++ {
+				q += γ[m] * sol.Y[o.Umap[i+m*o.Ndim]]
+			}
+			for m := 0; m < nverts; m++ {
+				fb[o.Umap[i+m*o.Ndim]] -= c * q * γ[m]
+			}
+		}
+	}
+	return
+}
+
+// hourglass_add_to_kt adds the hourglass stiffness to K
+func (o *Solid) hourglass_add_to_kt() (err error) {
+	if o.HgCoef <= 0 {
+		return
+	}
+	c, err := o.hourglass_coef()
+	if err != nil {
+		return
+	}
+	nverts := o.Cell.Shp.Nverts
+	for _, γ := range o.HgGam {
+		for m := 0; m < nverts; m++ {
+			for n := 0; n < nverts; n++ {
+				for i := 0; i < o.Ndim; i++ {
+					o.K[i+m*o.Ndim][i+n*o.Ndim] += c * γ[m] * γ[n]
+				}
+			}
+		}
+	}
+	return
+}
+
+// HourglassRatio returns the ratio between the hourglass deformation sqrt(b・b)・|q| and the
+// strains |ε| at the centre of one-point qua4 and hex8 cells. Large values indicate dominant
+// zero-energy deformation patterns. ok == false if not applicable (other cells)
+func (o *Solid) HourglassRatio(sol *ele.Solution) (r float64, ok bool) {
+	if o.HgGam == nil {
+		return
+	}
+	nverts := o.Cell.Shp.Nverts
+	var qq, εε float64
+	for _, γ := range o.HgGam {
+		for i := 0; i < o.Ndim; i++ {
+			var q float64
+			for m := 0; m < nverts; m++ {
+				q += γ[m] * sol.Y[o.Umap[i+m*o.Ndim]]
+			}
+			qq += q * q
+		}
+	}
+	for i := 0; i < o.Ndim; i++ {
+		for j := 0; j < o.Ndim; j++ {
+			var εij float64
+			for m := 0; m < nverts; m++ {
+				εij += (sol.Y[o.Umap[i+m*o.Ndim]]*o.HgG[m][j] + sol.Y[o.Umap[j+m*o.Ndim]]*o.HgG[m][i]) / 2.0
+			}
+			εε += εij * εij
+		}
+	}
+	hg := math.Sqrt(o.HgGG * qq)
+	if hg <= 1e-14*math.Sqrt(o.HgGG)*la.VecLargest(sol.Y, 1) {
+		return 0, true // round-off of rigid body motions
+	}
+	if εε == 0 {
+		return math.Inf(1), true
+	}
+	return hg / math.Sqrt(εε), true
+}
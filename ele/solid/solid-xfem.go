@@ -5,30 +5,59 @@
 package solid
 
 import (
+	"math"
+
 	"github.com/cpmech/gofem/ele"
 	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mdl/solid"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
 )
 
+// constants for embedded cracks
+const (
+	XCRK_NITMAX = 20    // max number of local iterations to compute the relative displacements
+	XCRK_TOL    = 1e-10 // tolerance of local iterations (relative to tractions)
+)
+
+// Embedded strong discontinuity (crack) in 2D; set with extra = "!xcrk:1"
+//  The crack is a straight line through the element with normal n and (constant) relative
+//  displacements w = {wn, ws} in the local system {n, t}. Following the enhanced assumed strain
+//  method (E-FEM; Oliver 1996 and Jirásek 2000), the displacements are u = ū + (H - φ)・w, where H
+//  is the Heaviside function of the crack and φ = Σ N_m (m ∈ Ω+) is the ramp function made of the
+//  shape functions of the vertices on the positive side; thus the strains are
+//     ε = B・u - sym(∇φ ⊗ w)
+//  and w is computed in Update such that the mean traction in the element equals the traction of
+//  the cohesive law:
+//     (1/A)∫ σ・n dA - t(w) = 0
+//  Since w is an element variable, it is eliminated by static condensation and no extra DOFs are
+//  required. The crack is initiated by calling XcrkInit; e.g. by fem.Tracking
+//  Note: (1) the cohesive law (solid.Joint; e.g. "cohesive") is the first "sld" material listed in
+//            the dependencies ("deps") of the material of the element
+//        (2) the condensed stiffness matrix is not symmetric
+//        (3) before initiation, the element behaves as a standard solid element
+
+// xcrk_data holds the data of embedded cracks to be encoded
+type xcrk_data struct {
+	On bool         // crack has been initiated
+	X0 []float64    // point on crack line
+	N  []float64    // normal of crack
+	St *solid.State // state of cohesive law
+}
+
 // xfem_set_info sets extra information for XFEM elements
+//  Note: embedded cracks ("!xcrk:1") do not require extra DOFs
 func xfem_set_info(info *ele.Info, cell *inp.Cell, edat *inp.ElemData) (ykeys []string) {
 
 	// flags
-	xmat, xcrk, xfem := false, false, false
+	xmat := false
 	if s_xmat, found := io.Keycode(edat.Extra, "xmat"); found {
 		xmat = io.Atob(s_xmat)
-		xfem = true
-	}
-	if s_xcrk, found := io.Keycode(edat.Extra, "xcrk"); found {
-		xcrk = io.Atob(s_xcrk)
-		xfem = true
 	}
-	_ = xcrk // TODO
 
 	// skip if not XFEM
-	if !xfem {
+	if !xmat {
 		return
 	}
 
@@ -45,7 +74,7 @@ func xfem_set_info(info *ele.Info, cell *inp.Cell, edat *inp.ElemData) (ykeys []
 }
 
 // xfem_init initialises variables need by xfem model
-func (o *Solid) xfem_init(edat *inp.ElemData) {
+func (o *Solid) xfem_init(sim *inp.Simulation, edat *inp.ElemData, mat *inp.Material) {
 
 	// flags
 	o.Xmat, o.Xcrk, o.Xfem = false, false, false
@@ -63,17 +92,15 @@ func (o *Solid) xfem_init(edat *inp.ElemData) {
 		return
 	}
 
-	// auxiliary variables
-	switch {
-	case o.Xmat:
-		o.Na = 1
-	case o.Xcrk:
-		o.Na = 4 // TODO: this is 2D only
-		if o.Ndim != 2 {
-			chk.Panic("xfem with crack works in 2D only for now")
-		}
+	// embedded crack
+	if o.Xcrk {
+		o.xcrk_init(sim, mat)
+		return
 	}
 
+	// auxiliary variables
+	o.Na = 1
+
 	// allocate extrem XFEM coupling matrices
 	nverts := o.Cell.Shp.Nverts
 	o.Amap = make([]int, o.Na*nverts)
@@ -83,11 +110,347 @@ func (o *Solid) xfem_init(edat *inp.ElemData) {
 }
 
 // xfem_add_to_rhs adds contribution to rhs due to xfem model
+//  Note: the local equation of embedded cracks is satisfied in Update; thus, there is no additional
+//        term to be condensed
 func (o *Solid) xfem_add_to_rhs(fb []float64, sol *ele.Solution) (err error) {
 	return
 }
 
 // contact_add_to_jac adds coupled equations due to xfem to Jacobian
-func (o *Solid) xfem_add_to_jac(Kb *la.Triplet, sol *ele.Solution) (err error) {
+func (o *Solid) xfem_add_to_jac(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+	if !o.Xcrk {
+		return
+	}
+
+	// condensed stiffness: K = Kuu - Kuw・Kww⁻¹・Kwu
+	if o.XcOn {
+		err = o.xcrk_matrices(true, firstIt)
+		if err != nil {
+			return
+		}
+		Kwwi, err := xcrk_inv(o.XcKww)
+		if err != nil {
+			return chk.Err("Solid: eid=%d: cannot condense the relative displacements of crack:\n%v", o.Id(), err)
+		}
+		for i := 0; i < o.Nu; i++ {
+			for j := 0; j < o.Nu; j++ {
+				for k := 0; k < 2; k++ {
+					for l := 0; l < 2; l++ {
+						o.K[i][j] -= o.XcKuw[i][k] * Kwwi[k][l] * o.XcKwu[l][j]
+					}
+				}
+			}
+		}
+	}
+	for i, I := range o.Umap {
+		for j, J := range o.Umap {
+			Kb.Put(I, J, o.K[i][j])
+		}
+	}
+	return
+}
+
+// embedded cracks //////////////////////////////////////////////////////////////////////////////////
+
+// XcrkInit initiates the embedded crack along the line through x0 with normal n
+//  Note: the vertices of the element must be on both sides of the line
+func (o *Solid) XcrkInit(x0, n []float64) (err error) {
+	if !o.Xcrk {
+		return chk.Err("Solid: eid=%d: embedded crack requires extra = \"!xcrk:1\"", o.Id())
+	}
+	if o.XcOn {
+		return chk.Err("Solid: eid=%d: embedded crack has already been initiated", o.Id())
+	}
+	nn := math.Sqrt(n[0]*n[0] + n[1]*n[1])
+	if nn < 1e-14 {
+		return chk.Err("Solid: eid=%d: normal of crack must not be zero", o.Id())
+	}
+	copy(o.XcX0, x0)
+	o.XcRot[0][0], o.XcRot[0][1] = n[0]/nn, n[1]/nn
+	o.XcRot[1][0], o.XcRot[1][1] = -n[1]/nn, n[0]/nn
+	err = o.xcrk_geometry()
+	if err != nil {
+		return
+	}
+	o.XcOn = true
+	o.XcSt, err = o.XcMdl.InitIntVars(make([]float64, 2))
+	if err != nil {
+		return
+	}
+	o.XcStBkp = o.XcSt.GetCopy()
+	o.XcStAux = o.XcSt.GetCopy()
+	o.XcTmp = o.XcSt.GetCopy()
+	return
+}
+
+// XcrkOn returns whether the embedded crack has been initiated or not
+func (o *Solid) XcrkOn() bool {
+	return o.XcOn
+}
+
+// XcrkStrength returns the tensile strength of the cohesive law; or zero if not available
+func (o *Solid) XcrkStrength() float64 {
+	if m, ok := o.XcMdl.(solid.JointStrength); ok {
+		return m.TensileStrength()
+	}
+	return 0
+}
+
+// XcrkSegment returns the points where the line of the crack crosses the edges of the element and
+// the local indices of these edges
+//  Note: only the corner vertices of edges are considered; i.e. edges are straight
+func (o *Solid) XcrkSegment() (pa, pb []float64, ea, eb int) {
+	ea, eb = -1, -1
+	n := o.XcRot[0]
+	for e, l := range o.Cell.Shp.FaceLocalVerts {
+		p, q := l[0], l[1]
+		dp, dq := 0.0, 0.0
+		for i := 0; i < 2; i++ {
+			dp += (o.X[i][p] - o.XcX0[i]) * n[i]
+			dq += (o.X[i][q] - o.XcX0[i]) * n[i]
+		}
+		if (dp > 0) == (dq > 0) {
+			continue
+		}
+		s := dp / (dp - dq)
+		x := []float64{o.X[0][p] + s*(o.X[0][q]-o.X[0][p]), o.X[1][p] + s*(o.X[1][q]-o.X[1][p])}
+		if ea < 0 {
+			pa, ea = x, e
+		} else {
+			pb, eb = x, e
+		}
+	}
+	return
+}
+
+// xcrk_init allocates the variables of embedded cracks
+func (o *Solid) xcrk_init(sim *inp.Simulation, mat *inp.Material) {
+	if o.Ndim != 2 || sim.Data.Axisym {
+		chk.Panic("Solid: eid=%d: embedded cracks are only available in 2D (plane-strain or plane-stress)", o.Id())
+	}
+	for _, name := range mat.Deps {
+		if m := sim.MatModels.Get(name); m != nil {
+			if jmdl, ok := m.Sld.(solid.Joint); ok {
+				o.XcMdl = jmdl
+				break
+			}
+		}
+	}
+	if o.XcMdl == nil {
+		chk.Panic("Solid: eid=%d: embedded crack requires a cohesive law (e.g. \"cohesive\") listed in the dependencies (\"deps\") of material %q", o.Id(), mat.Name)
+	}
+	nip, nsig := len(o.IpsElem), 2*o.Ndim
+	o.XcX0 = make([]float64, 2)
+	o.XcRot = la.MatAlloc(2, 2)
+	o.XcPos = make([]bool, o.Cell.Shp.Nverts)
+	o.XcGphi = la.MatAlloc(nip, 2)
+	o.XcCoef = make([]float64, nip)
+	o.XcSt0 = make([]*solid.State, nip)
+	o.XcB = la.MatAlloc(nsig, o.Nu)
+	o.XcGw = la.MatAlloc(nsig, 2)
+	o.XcNw = la.MatAlloc(2, nsig)
+	o.XcKuw = la.MatAlloc(o.Nu, 2)
+	o.XcKwu = la.MatAlloc(2, o.Nu)
+	o.XcKww = la.MatAlloc(2, 2)
+	o.XcRw = make([]float64, 2)
+}
+
+// xcrk_geometry computes the vertices on the positive side of the crack, the gradients of the
+// ramp function and the coefficients of integration @ ips
+func (o *Solid) xcrk_geometry() (err error) {
+	npos := 0
+	n := o.XcRot[0]
+	for m := 0; m < o.Cell.Shp.Nverts; m++ {
+		d := (o.X[0][m]-o.XcX0[0])*n[0] + (o.X[1][m]-o.XcX0[1])*n[1]
+		o.XcPos[m] = d > 0
+		if o.XcPos[m] {
+			npos++
+		}
+	}
+	if npos == 0 || npos == o.Cell.Shp.Nverts {
+		return chk.Err("Solid: eid=%d: line of crack through %v with normal %v does not cross the element", o.Id(), o.XcX0, n)
+	}
+	o.XcArea = 0
+	for idx, ip := range o.IpsElem {
+		err = o.Cell.Shp.CalcAtIp(o.X, ip, true)
+		if err != nil {
+			return
+		}
+		o.XcGphi[idx][0], o.XcGphi[idx][1] = 0, 0
+		for m := 0; m < o.Cell.Shp.Nverts; m++ {
+			if o.XcPos[m] {
+				o.XcGphi[idx][0] += o.Cell.Shp.G[m][0]
+				o.XcGphi[idx][1] += o.Cell.Shp.G[m][1]
+			}
+		}
+		o.XcCoef[idx] = o.Cell.Shp.J * ip[3] * o.Thickness
+		o.XcArea += o.XcCoef[idx]
+	}
+
+	// normal tractions: Nw = R・N with (σ・n)_i = N_ij σ_j (Mandel)
+	o.XcNw[0][0], o.XcNw[0][1], o.XcNw[0][3] = n[0]*n[0], n[1]*n[1], 2*n[0]*n[1]/SQ2
+	o.XcNw[1][0], o.XcNw[1][1], o.XcNw[1][3] = -n[1]*n[0], n[0]*n[1], (n[0]*n[0]-n[1]*n[1])/SQ2
+	return
+}
+
+// xcrk_subtract subtracts the strains due to the relative displacements w and Δw (global system)
+// from the strains at integration point idx
+func (o *Solid) xcrk_subtract(idx int, w, Δw []float64) {
+	g := o.XcGphi[idx]
+	o.Eps[0] -= g[0] * w[0]
+	o.Eps[1] -= g[1] * w[1]
+	o.Eps[3] -= (g[0]*w[1] + g[1]*w[0]) / SQ2
+	o.DelEps[0] -= g[0] * Δw[0]
+	o.DelEps[1] -= g[1] * Δw[1]
+	o.DelEps[3] -= (g[0]*Δw[1] + g[1]*Δw[0]) / SQ2
+}
+
+// xcrk_update updates the states @ ips and the relative displacements of the crack such that the
+// mean traction equals the traction of the cohesive law (local Newton iterations)
+func (o *Solid) xcrk_update(sol *ele.Solution, thermal bool) (err error) {
+
+	// states at the beginning of the update
+	for idx, s := range o.States {
+		if o.XcSt0[idx] == nil {
+			o.XcSt0[idx] = s.GetCopy()
+		} else {
+			o.XcSt0[idx].Set(s)
+		}
+	}
+
+	// local iterations
+	Δwl := make([]float64, 2)
+	w, Δw := make([]float64, 2), make([]float64, 2)
+	R := o.XcRot
+	for it := 0; it < XCRK_NITMAX; it++ {
+
+		// cohesive law
+		o.XcTmp.Set(o.XcSt)
+		err = o.XcMdl.Update(o.XcTmp, Δwl, sol.T)
+		if err != nil {
+			return chk.Err("Solid: eid=%d: update of cohesive law failed:\n%v", o.Id(), err)
+		}
+
+		// states @ ips with relative displacements in global system
+		for i := 0; i < 2; i++ {
+			w[i] = R[0][i]*o.XcTmp.EpsE[0] + R[1][i]*o.XcTmp.EpsE[1]
+			Δw[i] = R[0][i]*Δwl[0] + R[1][i]*Δwl[1]
+		}
+		if it > 0 {
+			for idx, s := range o.States {
+				s.Set(o.XcSt0[idx])
+			}
+		}
+		err = o.update_ips(sol, thermal, w, Δw)
+		if err != nil {
+			return
+		}
+
+		// residual and Jacobian
+		err = o.xcrk_matrices(false, false)
+		if err != nil {
+			return
+		}
+		tnorm := math.Sqrt(o.XcTmp.Sig[0]*o.XcTmp.Sig[0] + o.XcTmp.Sig[1]*o.XcTmp.Sig[1])
+		if la.VecNorm(o.XcRw) <= XCRK_TOL*math.Max(1, tnorm) {
+			o.XcSt.Set(o.XcTmp)
+			return
+		}
+		Kwwi, err := xcrk_inv(o.XcKww)
+		if err != nil {
+			return chk.Err("Solid: eid=%d: local iterations of crack failed:\n%v", o.Id(), err)
+		}
+		for i := 0; i < 2; i++ {
+			Δwl[i] -= Kwwi[i][0]*o.XcRw[0] + Kwwi[i][1]*o.XcRw[1]
+		}
+	}
+	return chk.Err("Solid: eid=%d: local iterations of crack did not converge after %d iterations", o.Id(), XCRK_NITMAX)
+}
+
+// xcrk_matrices computes the residual Rw = (1/A)∫ Nw・σ dA - t(w), Kww = ∂Rw/∂w and, if coupled,
+// Kuw = ∫ tr(B)・D・Gw dA and Kwu = (1/A)∫ Nw・D・B dA; where Gw = ∂ε/∂w
+//  Note: the state of the cohesive law must be in XcTmp if !coupled and in XcSt otherwise
+func (o *Solid) xcrk_matrices(coupled, firstIt bool) (err error) {
+
+	// cohesive law
+	st := o.XcTmp
+	if coupled {
+		st = o.XcSt
+		la.MatFill(o.XcKuw, 0)
+		la.MatFill(o.XcKwu, 0)
+	}
+	err = o.XcMdl.CalcD(o.XcKww, st)
+	if err != nil {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		o.XcRw[i] = -st.Sig[i]
+		for j := 0; j < 2; j++ {
+			o.XcKww[i][j] = -o.XcKww[i][j]
+		}
+	}
+
+	// for each integration point
+	R := o.XcRot
+	nsig := 2 * o.Ndim
+	nverts := o.Cell.Shp.Nverts
+	for idx, ip := range o.IpsElem {
+
+		// Gw = -∂sym(∇φ ⊗ w)/∂w・tr(R)
+		g := o.XcGphi[idx]
+		for k := 0; k < 2; k++ {
+			o.XcGw[0][k] = -g[0] * R[k][0]
+			o.XcGw[1][k] = -g[1] * R[k][1]
+			o.XcGw[2][k] = 0
+			o.XcGw[3][k] = -(g[0]*R[k][1] + g[1]*R[k][0]) / SQ2
+		}
+
+		// residual and Kww
+		err = o.small(idx).CalcD(o.D, o.States[idx], firstIt)
+		if err != nil {
+			return
+		}
+		c := o.XcCoef[idx] / o.XcArea
+		for i := 0; i < 2; i++ {
+			for a := 0; a < nsig; a++ {
+				o.XcRw[i] += c * o.XcNw[i][a] * o.States[idx].Sig[a]
+				for b := 0; b < nsig; b++ {
+					for k := 0; k < 2; k++ {
+						o.XcKww[i][k] += c * o.XcNw[i][a] * o.D[a][b] * o.XcGw[b][k]
+					}
+				}
+			}
+		}
+		if !coupled {
+			continue
+		}
+
+		// Kuw and Kwu
+		err = o.Cell.Shp.CalcAtIp(o.X, ip, true)
+		if err != nil {
+			return
+		}
+		IpBmatrix(o.XcB, o.Ndim, nverts, o.Cell.Shp.G, 1, o.Cell.Shp.S, false)
+		for r := 0; r < o.Nu; r++ {
+			for a := 0; a < nsig; a++ {
+				for b := 0; b < nsig; b++ {
+					for k := 0; k < 2; k++ {
+						o.XcKuw[r][k] += o.XcCoef[idx] * o.XcB[a][r] * o.D[a][b] * o.XcGw[b][k]
+						o.XcKwu[k][r] += c * o.XcNw[k][a] * o.D[a][b] * o.XcB[b][r]
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// xcrk_inv returns the inverse of a 2x2 matrix
+func xcrk_inv(a [][]float64) (ai [][]float64, err error) {
+	det := a[0][0]*a[1][1] - a[0][1]*a[1][0]
+	if math.Abs(det) < 1e-14*math.Max(1, math.Abs(a[0][0]*a[1][1])) {
+		return nil, chk.Err("matrix is singular; det = %g", det)
+	}
+	ai = [][]float64{{a[1][1] / det, -a[0][1] / det}, {-a[1][0] / det, a[0][0] / det}}
 	return
 }
@@ -6,6 +6,8 @@
 package solid
 
 import (
+	"math"
+
 	"github.com/cpmech/gofem/ele"
 	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/mdl/solid"
@@ -29,20 +31,38 @@ type Solid struct {
 	Ndim int         // space dimension
 
 	// variables for dynamics
-	Cdam float64  // coefficient for damping // TODO: read this value
-	Gfcn fun.Func // gravity function
+	Cdam   float64    // coefficient for damping // TODO: read this value
+	Gfcn   fun.Func   // gravity function
+	Bfcn   []fun.Func // [ndim] body forces b(t,x) per unit volume; set via "bx", "by" and "bz" element conditions
+	Afcn   []fun.Func // [ndim] body accelerations a(t,x) (forces per unit mass); set via "ax", "ay" and "az" element conditions
+	Aupd   bool       // body accelerations are evaluated at the current coordinates (X + u) of ips; set with extra = "!updated:1"
+	Mscale float64    // mass scaling factor; i.e. the inertia term uses Mscale・ρ (gravity uses ρ)
+	Qsta   bool       // quasi-static element: inertia and damping terms are ignored in transient analyses
 
 	// optional data
 	UseB      bool    // use B matrix
 	Thickness float64 // thickness
 	Debug     bool    // debugging flag
 
+	// thermal strains
+	Therm *solid.ThermalStrain // thermal strains data; nil if material has no "alpT" parameter
+	Tfcn  fun.Func             // temperature function T(t,x) evaluated at nodes; set via "temp" element condition
+	Teqs  []int                // [nverts] equations of "temp" dofs @ nodes; nil if not available. Has priority over Tfcn
+	Tnod  []float64            // [nverts] temperature @ nodes
+	Tonod []float64            // [nverts] temperature @ nodes at the beginning of the increment
+	Tdep  *solid.TempDep       // temperature dependent parameters; nil if material has no "tdep" data
+
+	// volumetric eigen-strains; e.g. swelling. set by coupled elements (nil if not used)
+	EigV  []float64 // [nip] volumetric eigen-strains @ ips
+	ΔEigV []float64 // [nip] increments of volumetric eigen-strains @ ips
+
 	// integration points
 	IpsElem []shp.Ipoint // integration points of element
 	IpsFace []shp.Ipoint // integration points corresponding to faces
 
 	// material model and internal variables
 	Mdl      solid.Model // material model
+	MdlName  string      // name of material model; e.g. for the names of internal variables
 	MdlSmall solid.Small // model specialisation for small strains
 	MdlLarge solid.Large // model specialisation for large deformations
 
@@ -50,6 +70,7 @@ type Solid struct {
 	States    []*solid.State // [nip] states
 	StatesBkp []*solid.State // [nip] backup states
 	StatesAux []*solid.State // [nip] auxiliary backup states
+	Soa       bool           // states are stored in contiguous arrays; see solid.PackStates
 
 	// additional variables
 	Umap   []int            // assembly map (location array/element equations)
@@ -64,6 +85,7 @@ type Solid struct {
 	// scratchpad. computed @ each ip
 	Grav []float64   // [ndim] gravity vector
 	Us   []float64   // [ndim] displacements @ ip
+	Xip  []float64   // [ndim] coordinates of ip; to compute body forces
 	Fi   []float64   // [nu] internal forces
 	K    [][]float64 // [nu][nu] consistent tangent (stiffness) matrix
 	B    [][]float64 // [nsig][nu] B matrix for axisymetric case
@@ -78,6 +100,13 @@ type Solid struct {
 	Fey []float64 // y-components of external syrface forces
 	Fez []float64 // z-components of external syrface forces
 
+	// hourglass control of one-point qua4 and hex8 cells (see solid-hourglass.go)
+	HgCoef float64     // coefficient κ of hourglass stabilisation (Flanagan-Belytschko); 0 => no stabilisation
+	HgGam  [][]float64 // [nmodes][nverts] hourglass shape vectors γ
+	HgG    [][]float64 // [nverts][ndim] derivatives of shape functions at the centre of cell
+	HgGG   float64     // Σ G・G at the centre of cell
+	HgVol  float64     // volume (or area times thickness) of cell
+
 	// contact (see e_u_contact.go)
 	Nq            int         // number of qb variables
 	HasContact    bool        // indicates if this element has contact faces
@@ -102,6 +131,28 @@ type Solid struct {
 	Kaa  [][]float64 // TODO: [na][na] Kaa := dRa/da consistent tangent matrix
 	//ProxyMesh *inp.Mesh      // TODO: auxiliary mesh
 	//EnrichShp *shp.EnrichShp // TODO: enriched shape functions
+
+	// embedded crack (see solid-xfem.go)
+	XcMdl   solid.Joint    // cohesive law
+	XcOn    bool           // crack has been initiated
+	XcX0    []float64      // [ndim] point on crack line
+	XcRot   [][]float64    // [ndim][ndim] local system of crack: rows = {n, t}
+	XcPos   []bool         // [nverts] vertices on the positive side of crack
+	XcGphi  [][]float64    // [nip][ndim] gradient of ramp function φ @ ips
+	XcCoef  []float64      // [nip] coefficients of integration @ ips
+	XcArea  float64        // area of element (times thickness)
+	XcSt    *solid.State   // state of cohesive law
+	XcStBkp *solid.State   // backup state of cohesive law
+	XcStAux *solid.State   // auxiliary backup state of cohesive law
+	XcTmp   *solid.State   // scratchpad: trial state of cohesive law
+	XcSt0   []*solid.State // [nip] scratchpad: states @ ips at the beginning of Update
+	XcB     [][]float64    // [nsig][nu] scratchpad: B matrix
+	XcGw    [][]float64    // [nsig][ndim] scratchpad: Gw = ∂ε/∂w
+	XcNw    [][]float64    // [ndim][nsig] local tractions from stresses: t = Nw・σ
+	XcKuw   [][]float64    // [nu][ndim] Kuw := dRu/dw
+	XcKwu   [][]float64    // [ndim][nu] Kwu := dRw/du
+	XcKww   [][]float64    // [ndim][ndim] Kww := dRw/dw
+	XcRw    []float64      // [ndim] residual of local equation
 }
 
 // initialisation ///////////////////////////////////////////////////////////////////////////////////
@@ -155,9 +206,11 @@ func init() {
 		o.X = x
 		o.Ndim = len(x)
 		o.Nu = o.Ndim * o.Cell.Shp.Nverts
+		o.Mscale = 1
 
 		// parse flags
 		o.UseB, o.Debug, o.Thickness = GetSolidFlags(sim.Data.Axisym, sim.Data.Pstress, edat.Extra)
+		o.Soa = sim.Data.SoA
 
 		// integration points
 		var err error
@@ -168,11 +221,25 @@ func init() {
 		nip := len(o.IpsElem)
 
 		// model
-		mat := sim.MatModels.Get(edat.Mat)
+		mat := sim.MatModels.GetCell(edat.Mat, cell)
 		if mat == nil {
 			chk.Panic("cannot find material %q for solid element {tag=%d, id=%d}\n", edat.Mat, cell.Tag, cell.Id)
 		}
 		o.Mdl = mat.Sld
+		o.MdlName = mat.SldName
+
+		// thermal strains
+		o.Therm = solid.NewThermalStrain(mat.SldPrms)
+		if len(mat.SldTdep) > 0 {
+			o.Tdep, err = solid.NewTempDep(mat.SldName, o.Ndim, sim.Data.Pstress, mat.SldPrms, mat.SldTdep, nip)
+			if err != nil {
+				chk.Panic("cannot allocate temperature dependent model of solid element {tag=%d, id=%d}:\n%v", cell.Tag, cell.Id, err)
+			}
+		}
+		if o.Therm != nil || o.Tdep != nil {
+			o.Tnod = make([]float64, o.Cell.Shp.Nverts)
+			o.Tonod = make([]float64, o.Cell.Shp.Nverts)
+		}
 
 		// model specialisations
 		switch m := o.Mdl.(type) {
@@ -218,11 +285,14 @@ func init() {
 			o.NatBcs = append(o.NatBcs, &ele.NaturalBc{fc.Cond, fc.FaceId, fc.Func, fc.Extra})
 		}
 
+		// hourglass control
+		o.hourglass_init(edat, sim.Data.Axisym)
+
 		// contact: init
 		o.contact_init(edat)
 
 		// xfem: init
-		o.xfem_init(edat)
+		o.xfem_init(sim, edat, mat)
 
 		// return new element
 		return &o
@@ -267,11 +337,47 @@ func (o *Solid) SetEqs(eqs [][]int, mixedform_eqs []int) (err error) {
 	return
 }
 
+// SetTempEqs sets the equations of "temp" dofs @ nodes; e.g. from thermomech elements sharing the
+// vertices of this element
+//  Note: the temperatures are taken from the current solution; however, the coupling terms
+//        ∂fu/∂T are not added to Kb (i.e. one-way coupling: the temperatures drive the strains)
+func (o *Solid) SetTempEqs(eqs []int) {
+	o.Teqs = eqs
+}
+
 // SetEleConds set element conditions
 func (o *Solid) SetEleConds(key string, f fun.Func, extra string) (err error) {
 	if key == "g" { // gravity
 		o.Gfcn = f
 	}
+	if key == "temp" { // temperature
+		o.Tfcn = f
+	}
+	if key == "bx" || key == "by" || key == "bz" { // body forces
+		i := int(key[1] - 'x')
+		if i >= o.Ndim {
+			return chk.Err("body force %q cannot be set in %dD", key, o.Ndim)
+		}
+		if o.Bfcn == nil {
+			o.Bfcn = make([]fun.Func, o.Ndim)
+			o.Xip = make([]float64, o.Ndim)
+		}
+		o.Bfcn[i] = f
+	}
+	if key == "ax" || key == "ay" || key == "az" { // body accelerations; e.g. ramped gravity
+		i := int(key[1] - 'x')
+		if i >= o.Ndim {
+			return chk.Err("body acceleration %q cannot be set in %dD", key, o.Ndim)
+		}
+		if o.Afcn == nil {
+			o.Afcn = make([]fun.Func, o.Ndim)
+			o.Xip = make([]float64, o.Ndim)
+		}
+		o.Afcn[i] = f
+		if _, found := io.Keycode(extra, "updated"); found {
+			o.Aupd = true
+		}
+	}
 	return
 }
 
@@ -355,7 +461,7 @@ func (o *Solid) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 		}
 
 		// dynamic term
-		if sol.Steady {
+		if sol.Steady || o.Qsta {
 			if o.Gfcn != nil {
 				for m := 0; m < nverts; m++ {
 					i := o.Ndim - 1
@@ -366,10 +472,30 @@ func (o *Solid) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 		} else {
 			α1 := sol.DynCfs.GetAlp1()
 			α4 := sol.DynCfs.GetAlp4()
+			ρm := o.Mscale * ρ
 			for m := 0; m < nverts; m++ {
 				for i := 0; i < o.Ndim; i++ {
 					r := o.Umap[i+m*o.Ndim]
-					fb[r] -= coef * S[m] * (ρ*(α1*o.Us[i]-o.Zet[idx][i]-o.Grav[i]) + o.Cdam*(α4*o.Us[i]-o.Chi[idx][i])) // -RuBar
+					fb[r] -= coef * S[m] * (ρm*(α1*o.Us[i]-o.Zet[idx][i]) - ρ*o.Grav[i] + o.Cdam*(α4*o.Us[i]-o.Chi[idx][i])) // -RuBar
+				}
+			}
+		}
+
+		// body forces and accelerations. the latter are multiplied by the actual density ρ (not
+		// the scaled density used by the inertia term) as gravity
+		if o.Bfcn != nil || o.Afcn != nil {
+			o.body_xip(S, sol)
+			for i := 0; i < o.Ndim; i++ {
+				var b float64
+				if o.Bfcn != nil && o.Bfcn[i] != nil {
+					b += o.Bfcn[i].F(sol.T, o.Xip)
+				}
+				if o.Afcn != nil && o.Afcn[i] != nil {
+					b += ρ * o.Afcn[i].F(sol.T, o.Xip)
+				}
+				for m := 0; m < nverts; m++ {
+					r := o.Umap[i+m*o.Ndim]
+					fb[r] += coef * S[m] * b // +fx
 				}
 			}
 		}
@@ -382,6 +508,12 @@ func (o *Solid) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 		}
 	}
 
+	// hourglass resisting forces
+	err = o.hourglass_add_to_rhs(fb, sol)
+	if err != nil {
+		return
+	}
+
 	// external forces
 	err = o.AddSurfLoadsToRhs(fb, sol)
 	if err != nil {
@@ -424,7 +556,7 @@ func (o *Solid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err er
 		G := o.Cell.Shp.G
 
 		// consistent tangent model matrix
-		err = o.MdlSmall.CalcD(o.D, o.States[idx], firstIt)
+		err = o.small(idx).CalcD(o.D, o.States[idx], firstIt)
 		if err != nil {
 			return
 		}
@@ -442,22 +574,50 @@ func (o *Solid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err er
 			IpAddToKt(o.K, nverts, o.Ndim, coef, G, o.D)
 		}
 
+		// body accelerations depending on current coordinates: -ρ・∂a/∂x
+		if o.Aupd && o.Afcn != nil {
+			o.body_xip(S, sol)
+			dadx := make([]float64, o.Ndim)
+			for i, f := range o.Afcn {
+				if f == nil {
+					continue
+				}
+				f.Grad(dadx, sol.T, o.Xip)
+				for m := 0; m < nverts; m++ {
+					r := i + m*o.Ndim
+					for n := 0; n < nverts; n++ {
+						for j := 0; j < o.Ndim; j++ {
+							c := j + n*o.Ndim
+							o.K[r][c] -= coef * S[m] * S[n] * ρ * dadx[j]
+						}
+					}
+				}
+			}
+		}
+
 		// dynamic term
-		if !sol.Steady {
+		if !sol.Steady && !o.Qsta {
 			α1 := sol.DynCfs.GetAlp1()
 			α4 := sol.DynCfs.GetAlp4()
+			ρm := o.Mscale * ρ
 			for m := 0; m < nverts; m++ {
 				for i := 0; i < o.Ndim; i++ {
 					r := i + m*o.Ndim
 					for n := 0; n < nverts; n++ {
 						c := i + n*o.Ndim
-						o.K[r][c] += coef * S[m] * S[n] * (ρ*α1 + o.Cdam*α4)
+						o.K[r][c] += coef * S[m] * S[n] * (ρm*α1 + o.Cdam*α4)
 					}
 				}
 			}
 		}
 	}
 
+	// hourglass stiffness
+	err = o.hourglass_add_to_kt()
+	if err != nil {
+		return
+	}
+
 	// add Ks to sparse matrix Kb
 	switch {
 
@@ -465,7 +625,7 @@ func (o *Solid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err er
 		err = o.contact_add_to_jac(Kb, sol)
 
 	case o.Xfem:
-		err = o.xfem_add_to_jac(Kb, sol)
+		err = o.xfem_add_to_jac(Kb, sol, firstIt)
 
 	default:
 		for i, I := range o.Umap {
@@ -477,35 +637,85 @@ func (o *Solid) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err er
 	return
 }
 
+// QuadFormK computes aᵀ K b with the K matrix computed by the last call to AddToKb
+//  Note: not available with contact or XFEM because K is assembled with extra dofs
+func (o *Solid) QuadFormK(a, b []float64) (res float64, ok bool) {
+	if o.HasContact || o.Xfem {
+		return 0, false
+	}
+	return kmat_quadform(o.K, o.Umap, o.Umap, a, b), true
+}
+
 // Update perform (tangent) update
 func (o *Solid) Update(sol *ele.Solution) (err error) {
 
+	// temperatures @ nodes
+	hastemp := o.Tfcn != nil || o.Teqs != nil
+	thermal := o.Therm != nil && hastemp
+	if o.Tdep != nil && !hastemp {
+		return chk.Err("temperature dependent parameters of element # %d require the \"temp\" element condition or \"temp\" dofs at its nodes", o.Id())
+	}
+	if thermal || o.Tdep != nil {
+		o.nodal_temperatures(sol)
+	}
+
+	// embedded crack
+	if o.XcOn {
+		return o.xcrk_update(sol, thermal)
+	}
+	return o.update_ips(sol, thermal, nil, nil)
+}
+
+// update_ips updates the states @ all integration points. The strains due to the relative
+// displacements w and Δw of the embedded crack (global system) are subtracted if w != nil
+func (o *Solid) update_ips(sol *ele.Solution, thermal bool, w, Δw []float64) (err error) {
+
 	// for each integration point
 	nverts := o.Cell.Shp.Nverts
-	for idx, ip := range o.IpsElem {
+	for idx, _ := range o.IpsElem {
 
-		// interpolation functions and gradients
-		err = o.Cell.Shp.CalcAtIp(o.X, ip, true)
+		// compute strains
+		err = o.IpStrains(idx, sol)
 		if err != nil {
 			return
 		}
 		S := o.Cell.Shp.S
-		G := o.Cell.Shp.G
 
-		// compute strains
-		if o.UseB {
-			radius := 1.0
-			if sol.Axisym {
-				radius = o.Cell.Shp.AxisymGetRadius(o.X)
+		// temperatures @ ip
+		var T, Told float64
+		if thermal || o.Tdep != nil {
+			for m := 0; m < nverts; m++ {
+				T += S[m] * o.Tnod[m]
+				Told += S[m] * o.Tonod[m]
+			}
+		}
+
+		// subtract thermal strains
+		if thermal {
+			o.Therm.Subtract(o.Eps, o.DelEps, T, Told)
+		}
+
+		// subtract volumetric eigen-strains
+		if o.EigV != nil {
+			solid.SubtractVolStrain(o.Eps, o.DelEps, o.EigV[idx], o.ΔEigV[idx])
+		}
+
+		// subtract strains of embedded crack
+		if w != nil {
+			o.xcrk_subtract(idx, w, Δw)
+		}
+
+		// model with parameters at current temperature
+		mdl := o.MdlSmall
+		if o.Tdep != nil {
+			mdl, err = o.Tdep.Model(idx, T)
+			if err != nil {
+				return chk.Err("eid=%d, ip=%d:\n%v", o.Id(), idx, err)
 			}
-			IpBmatrix(o.B, o.Ndim, nverts, G, radius, S, sol.Axisym)
-			IpStrainsAndIncB(o.Eps, o.DelEps, 2*o.Ndim, o.Nu, o.B, sol.Y, sol.ΔY, o.Umap)
-		} else {
-			IpStrainsAndInc(o.Eps, o.DelEps, nverts, o.Ndim, sol.Y, sol.ΔY, o.Umap, G)
 		}
 
 		// call model update => update stresses
-		err = o.MdlSmall.Update(o.States[idx], o.Eps, o.DelEps, o.Id(), idx, sol.T)
+		err = mdl.Update(o.States[idx], o.Eps, o.DelEps, o.Id(), idx, sol.T)
 		if err != nil {
 			return chk.Err("Update failed (eid=%d, ip=%d)\nΔε=%v\n%v", o.Id(), idx, o.DelEps, err)
 		}
@@ -518,8 +728,14 @@ func (o *Solid) Update(sol *ele.Solution) (err error) {
 // SetIniIvs sets initial ivs for given values in sol and ivs map
 func (o *Solid) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
 
-	// allocate slices of states
+	// check initial values
 	nip := len(o.IpsElem)
+	err = ele.CheckIvs(ivs, nip)
+	if err != nil {
+		return chk.Err("cannot set initial values of solid element {tag=%d, id=%d}:\n%v", o.Cell.Tag, o.Cell.Id, err)
+	}
+
+	// allocate slices of states
 	o.States = make([]*solid.State, nip)
 	o.StatesBkp = make([]*solid.State, nip)
 	o.StatesAux = make([]*solid.State, nip)
@@ -537,9 +753,39 @@ func (o *Solid) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err erro
 		if err != nil {
 			return
 		}
+	}
+
+	// characteristic length
+	if m, ok := o.Mdl.(solid.CharLengthSetter); ok {
+		var vol float64
+		for _, ip := range o.IpsElem {
+			err = o.Cell.Shp.CalcAtIp(o.X, ip, false)
+			if err != nil {
+				return
+			}
+			vol += o.Cell.Shp.J * ip[3]
+		}
+		h := math.Pow(vol, 1.0/float64(o.Ndim))
+		for i := 0; i < nip; i++ {
+			m.SetCharLength(o.States[i], h)
+		}
+	}
+
+	// backup and auxiliary states
+	for i := 0; i < nip; i++ {
 		o.StatesBkp[i] = o.States[i].GetCopy()
 		o.StatesAux[i] = o.States[i].GetCopy()
 	}
+
+	// structure-of-arrays
+	if o.Soa {
+		o.States = solid.PackStates(o.States)
+		o.StatesBkp = solid.PackStates(o.StatesBkp)
+		o.StatesAux = solid.PackStates(o.StatesAux)
+	}
+
+	// embedded crack
+	o.XcOn = false
 	return
 }
 
@@ -549,11 +795,17 @@ func (o *Solid) BackupIvs(aux bool) (err error) {
 		for i, s := range o.StatesAux {
 			s.Set(o.States[i])
 		}
+		if o.XcOn {
+			o.XcStAux.Set(o.XcSt)
+		}
 		return
 	}
 	for i, s := range o.StatesBkp {
 		s.Set(o.States[i])
 	}
+	if o.XcOn {
+		o.XcStBkp.Set(o.XcSt)
+	}
 	return
 }
 
@@ -563,11 +815,17 @@ func (o *Solid) RestoreIvs(aux bool) (err error) {
 		for i, s := range o.States {
 			s.Set(o.StatesAux[i])
 		}
+		if o.XcOn {
+			o.XcSt.Set(o.XcStAux)
+		}
 		return
 	}
 	for i, s := range o.States {
 		s.Set(o.StatesBkp[i])
 	}
+	if o.XcOn {
+		o.XcSt.Set(o.XcStBkp)
+	}
 	return
 }
 
@@ -582,11 +840,91 @@ func (o *Solid) Ureset(sol *ele.Solution) (err error) {
 	return
 }
 
+// SetDynCtrl sets the mass scaling factor and the quasi-static flag
+func (o *Solid) SetDynCtrl(mscale float64, qsta bool) {
+	o.Mscale, o.Qsta = mscale, qsta
+}
+
+// AddToLumpedMass adds the diagonal of the lumped mass matrix to m. The diagonal terms of the
+// consistent mass matrix are scaled to preserve the total mass (HRZ lumping)
+//  Note: the consistent mass matrix of spectral elements with the default integration points
+//        (see shp.SemType) is already diagonal; i.e. it is recovered exactly
+func (o *Solid) AddToLumpedMass(m []float64, sol *ele.Solution) (err error) {
+	ρm := o.Mscale * o.Mdl.GetRho()
+	nverts := o.Cell.Shp.Nverts
+	diag := make([]float64, nverts)
+	var total, sumdiag float64
+	for _, ip := range o.IpsElem {
+		err = o.Cell.Shp.CalcAtIp(o.X, ip, false)
+		if err != nil {
+			return
+		}
+		coef := o.Cell.Shp.J * ip[3] * o.Thickness
+		if sol.Axisym {
+			coef *= o.Cell.Shp.AxisymGetRadius(o.X)
+		}
+		S := o.Cell.Shp.S
+		total += coef * ρm
+		for n := 0; n < nverts; n++ {
+			diag[n] += coef * ρm * S[n] * S[n]
+			sumdiag += coef * ρm * S[n] * S[n]
+		}
+	}
+	if sumdiag <= 0 {
+		return
+	}
+	for n := 0; n < nverts; n++ {
+		for i := 0; i < o.Ndim; i++ {
+			m[o.Umap[i+n*o.Ndim]] += diag[n] * total / sumdiag
+		}
+	}
+	return
+}
+
+// GetIvsVec returns a copy of the stresses, elastic strains and internal variables at all
+// integration points
+func (o *Solid) GetIvsVec() (v []float64) {
+	for _, s := range o.States {
+		v = append(v, s.Sig...)
+		v = append(v, s.EpsE...)
+		v = append(v, s.Alp...)
+	}
+	return
+}
+
+// SetIvsVec sets the stresses, elastic strains and internal variables at all integration points
+// (and their backups) with values from v; e.g. extrapolated over skipped loading cycles
+func (o *Solid) SetIvsVec(v []float64) (err error) {
+	k := 0
+	for _, s := range o.States {
+		for _, a := range [][]float64{s.Sig, s.EpsE, s.Alp} {
+			if k+len(a) > len(v) {
+				return chk.Err("length of vector with internal variables is incorrect")
+			}
+			copy(a, v[k:k+len(a)])
+			k += len(a)
+		}
+	}
+	if k != len(v) {
+		return chk.Err("length of vector with internal variables is incorrect. %d != %d", len(v), k)
+	}
+	return o.BackupIvs(false)
+}
+
 // writer ///////////////////////////////////////////////////////////////////////////////////////////
 
 // Encode encodes internal variables
+//  Note: the data of the embedded crack is encoded after the states if "!xcrk:1"
 func (o *Solid) Encode(enc utl.Encoder) (err error) {
-	return enc.Encode(o.States)
+	err = enc.Encode(o.States)
+	if err != nil || !o.Xcrk {
+		return
+	}
+	dat := xcrk_data{On: o.XcOn}
+	if o.XcOn {
+		dat.X0, dat.N, dat.St = o.XcX0, o.XcRot[0], o.XcSt
+	}
+	return enc.Encode(dat)
 }
 
 // Decode decodes internal variables
@@ -595,6 +933,27 @@ func (o *Solid) Decode(dec utl.Decoder) (err error) {
 	if err != nil {
 		return
 	}
+	if len(o.States) != len(o.IpsElem) {
+		return chk.Err("number of states (%d) of solid element {tag=%d, id=%d} is different than the number of integration points (%d)", len(o.States), o.Cell.Tag, o.Cell.Id, len(o.IpsElem))
+	}
+	if o.Soa {
+		o.States = solid.PackStates(o.States)
+	}
+	if o.Xcrk {
+		var dat xcrk_data
+		err = dec.Decode(&dat)
+		if err != nil {
+			return
+		}
+		o.XcOn = false
+		if dat.On {
+			err = o.XcrkInit(dat.X0, dat.N)
+			if err != nil {
+				return
+			}
+			o.XcSt.Set(dat.St)
+		}
+	}
 	return o.BackupIvs(false)
 }
 
@@ -608,10 +967,11 @@ func (o *Solid) OutIpCoords() (C [][]float64) {
 }
 
 // OutIpKeys returns the integration points' keys
+//  Note: the relative displacements {wn, ws} of the embedded crack are included if "!xcrk:1"
 func (o *Solid) OutIpKeys() []string {
-	keys := StressKeys(o.Ndim)
-	for i := 0; i < len(o.States[0].Alp); i++ {
-		keys = append(keys, io.Sf("alp%d", i))
+	keys := append(StressKeys(o.Ndim), solid.IntVarNames(o.MdlName, o.Mdl, len(o.States[0].Alp))...)
+	if o.Xcrk {
+		keys = append(keys, "wn", "ws")
 	}
 	return keys
 }
@@ -624,12 +984,22 @@ func (o *Solid) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
 			M.Set(key, idx, nip, o.States[idx].Sig[i])
 		}
 	}
-	for i := 0; i < len(o.States[0].Alp); i++ {
-		key := io.Sf("alp%d", i)
+	for i, key := range solid.IntVarNames(o.MdlName, o.Mdl, len(o.States[0].Alp)) {
 		for idx, _ := range o.IpsElem {
 			M.Set(key, idx, nip, o.States[idx].Alp[i])
 		}
 	}
+	if o.Xcrk {
+		for i, key := range []string{"wn", "ws"} {
+			for idx, _ := range o.IpsElem {
+				var w float64
+				if o.XcOn {
+					w = o.XcSt.EpsE[i]
+				}
+				M.Set(key, idx, nip, w)
+			}
+		}
+	}
 }
 
 // extra ////////////////////////////////////////////////////////////////////////////////////////////
@@ -664,6 +1034,58 @@ func (o *Solid) AddToExt(sol *ele.Solution) (err error) {
 
 // auxiliary ////////////////////////////////////////////////////////////////////////////////////////
 
+// small returns the small-strain model of integration point idx; i.e. the model initialised with
+// the parameters at the temperature of ip if the material has temperature dependent parameters
+func (o *Solid) small(idx int) solid.Small {
+	if o.Tdep != nil {
+		return o.Tdep.Mdls[idx]
+	}
+	return o.MdlSmall
+}
+
+// nodal_temperatures computes the temperatures @ nodes at the end and beginning of the increment
+//  Note: with "temp" dofs, the temperatures at the beginning of the increment are Y - ΔY
+func (o *Solid) nodal_temperatures(sol *ele.Solution) {
+	if o.Teqs != nil {
+		for m, eq := range o.Teqs {
+			o.Tnod[m] = sol.Y[eq]
+			o.Tonod[m] = sol.Y[eq] - sol.ΔY[eq]
+		}
+		return
+	}
+	x := make([]float64, o.Ndim)
+	for m := 0; m < o.Cell.Shp.Nverts; m++ {
+		for i := 0; i < o.Ndim; i++ {
+			x[i] = o.X[i][m]
+		}
+		o.Tnod[m] = o.Tfcn.F(sol.T, x)
+		o.Tonod[m] = o.Tfcn.F(sol.T-sol.Dt, x)
+	}
+}
+
+// IpStrains computes the strains (Eps) and strain increments (DelEps) @ integration point idx
+//  Note: the interpolation functions and gradients are computed as well
+func (o *Solid) IpStrains(idx int, sol *ele.Solution) (err error) {
+	err = o.Cell.Shp.CalcAtIp(o.X, o.IpsElem[idx], true)
+	if err != nil {
+		return
+	}
+	nverts := o.Cell.Shp.Nverts
+	S := o.Cell.Shp.S
+	G := o.Cell.Shp.G
+	if o.UseB {
+		radius := 1.0
+		if sol.Axisym {
+			radius = o.Cell.Shp.AxisymGetRadius(o.X)
+		}
+		IpBmatrix(o.B, o.Ndim, nverts, G, radius, S, sol.Axisym)
+		IpStrainsAndIncB(o.Eps, o.DelEps, 2*o.Ndim, o.Nu, o.B, sol.Y, sol.ΔY, o.Umap)
+		return
+	}
+	IpStrainsAndInc(o.Eps, o.DelEps, nverts, o.Ndim, sol.Y, sol.ΔY, o.Umap, G)
+	return
+}
+
 // ipvars computes current values @ integration points. idx == index of integration point
 func (o *Solid) ipvars(idx int, sol *ele.Solution) (err error) {
 
@@ -693,6 +1115,22 @@ func (o *Solid) ipvars(idx int, sol *ele.Solution) (err error) {
 	return
 }
 
+// body_xip computes the coordinates of ip (Xip) to evaluate body forces and accelerations; the
+// displacements are added if the accelerations depend on the current coordinates (Aupd)
+//  Note: S must have been computed at ip
+func (o *Solid) body_xip(S []float64, sol *ele.Solution) {
+	nverts := o.Cell.Shp.Nverts
+	for i := 0; i < o.Ndim; i++ {
+		o.Xip[i] = 0
+		for m := 0; m < nverts; m++ {
+			o.Xip[i] += S[m] * o.X[i][m]
+			if o.Aupd {
+				o.Xip[i] += S[m] * sol.Y[o.Umap[i+m*o.Ndim]]
+			}
+		}
+	}
+}
+
 // surfloads_keys returns the keys that can be used to specify surface loads
 func (o *Solid) surfloads_keys() map[string]bool {
 	return map[string]bool{"qn": true, "qn0": true, "aqn": true}
@@ -714,9 +1152,6 @@ func (o *Solid) AddSurfLoadsToRhs(fb []float64, sol *ele.Solution) (err error) {
 	var res float64
 	for _, nbc := range o.NatBcs {
 
-		// function evaluation
-		res = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for _, ipf := range o.IpsFace {
 
@@ -726,6 +1161,10 @@ func (o *Solid) AddSurfLoadsToRhs(fb []float64, sol *ele.Solution) (err error) {
 			if err != nil {
 				return
 			}
+
+			// function evaluation @ face ip
+			res = ele.NatBcValue(nbc.Fcn, sol.T, o.Cell.Shp, o.X, iface)
+
 			Sf := o.Cell.Shp.Sf
 			nvec := o.Cell.Shp.Fnvec
 
@@ -0,0 +1,47 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_beam01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beam01. member forces of 2D beams")
+
+	// inclined beam: L = 5
+	mdl := &solid.OnedLinElast{E: 2, A: 3, I22: 0.5}
+	o := beamhinge_alloc(tst, mdl, 3, 4, "")
+	if o == nil {
+		return
+	}
+	o.Umap = []int{0, 1, 2, 3, 4, 5}
+	o.ua = make([]float64, o.Nu)
+	o.Nstations = 3
+	sol := &ele.Solution{Y: make([]float64, 6)}
+
+	// stretching along the axis
+	δ := 0.01
+	sol.Y[3], sol.Y[4] = δ*3.0/5.0, δ*4.0/5.0
+	F := o.MemberForces(sol)
+	N := mdl.E * mdl.A * δ / 5.0
+	chk.Vector(tst, "N", 1e-15, F["N"], []float64{N, N, N})
+	chk.Vector(tst, "V1", 1e-15, F["V1"], []float64{0, 0, 0})
+	chk.Vector(tst, "M22", 1e-15, F["M22"], []float64{0, 0, 0})
+
+	// rigid rotation about node 0
+	θ := 1e-3
+	sol.Y[2], sol.Y[3], sol.Y[4], sol.Y[5] = θ, -θ*4.0, θ*3.0, θ
+	F = o.MemberForces(sol)
+	chk.Vector(tst, "N", 1e-15, F["N"], []float64{0, 0, 0})
+	chk.Vector(tst, "V1", 1e-15, F["V1"], []float64{0, 0, 0})
+	chk.Vector(tst, "M22", 1e-15, F["M22"], []float64{0, 0, 0})
+}
@@ -0,0 +1,149 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// beamhinge_alloc allocates a 2D beam with hinges from (0,0) to (xb,yb)
+func beamhinge_alloc(tst *testing.T, mdl *solid.OnedLinElast, xb, yb float64, extra string) *Beam {
+	o := &Beam{Cell: &inp.Cell{}, Mdl: mdl, Ndim: 2, Nu: 6}
+	o.X = [][]float64{{0, xb}, {0, yb}}
+	o.P02 = []float64{0, 0, 1}
+	o.e0, o.e1, o.e2 = make([]float64, 3), make([]float64, 3), make([]float64, 3)
+	o.T = la.MatAlloc(o.Nu, o.Nu)
+	o.Kl = la.MatAlloc(o.Nu, o.Nu)
+	o.K = la.MatAlloc(o.Nu, o.Nu)
+	o.Recompute(false)
+	err := o.init_hinges(nil, extra)
+	if err != nil {
+		tst.Errorf("init_hinges failed:\n%v", err)
+		return nil
+	}
+	o.Hst = new_beam_hinge_state()
+	for i, law := range o.Hinges {
+		if law != nil {
+			o.Hst.Hs[i] = *law.NewState()
+		}
+	}
+	return o
+}
+
+func Test_beamhinge01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beamhinge01. basic forces and tangent of beams with hinges")
+
+	// hinges at both ends yielding: q = My and zero rotational tangent
+	mdl := &solid.OnedLinElast{E: 1, A: 1, I22: 1}
+	o := beamhinge_alloc(tst, mdl, 1, 0, "!hinges:both !km:1000 !fym:1 !rm:0")
+	if o == nil {
+		return
+	}
+	err := o.hinge_update([]float64{0, 0.5, 0.5})
+	if err != nil {
+		tst.Errorf("hinge_update failed:\n%v", err)
+		return
+	}
+	chk.Vector(tst, "Q", 1e-12, o.Hst.Q, []float64{0, 1, 1})
+	chk.Scalar(tst, "θ0", 1e-12, o.Hst.Hs[0].D, 0.5-1.0/6.0)
+	chk.Scalar(tst, "θ1", 1e-12, o.Hst.Hs[1].D, 0.5-1.0/6.0)
+	chk.Matrix(tst, "Kb", 1e-12, o.Hst.Kb, [][]float64{{1, 0, 0}, {0, 0, 0}, {0, 0, 0}})
+
+	// elastic hinge at left end: springs in series
+	o = beamhinge_alloc(tst, mdl, 1, 0, "!hinges:left !km:10")
+	if o == nil {
+		return
+	}
+	v := []float64{0.002, 1e-4, 0}
+	err = o.hinge_update(v)
+	if err != nil {
+		tst.Errorf("hinge_update failed:\n%v", err)
+		return
+	}
+	kc := [][]float64{
+		{1, 0, 0},
+		{0, 4.0 - 16.0/14.0, 2.0 - 8.0/14.0},
+		{0, 2.0 - 8.0/14.0, 4.0 - 4.0/14.0},
+	}
+	chk.Matrix(tst, "Kb", 1e-14, o.Hst.Kb, kc)
+	chk.Vector(tst, "Q", 1e-14, o.Hst.Q, []float64{0.002, kc[1][1] * 1e-4, kc[2][1] * 1e-4})
+
+	// stiff hinges on inclined beam: tangent equals elastic stiffness
+	mdl = &solid.OnedLinElast{E: 2, A: 3, I22: 0.5}
+	o = beamhinge_alloc(tst, mdl, 3, 4, "!hinges:both !km:1e12")
+	if o == nil {
+		return
+	}
+	err = o.hinge_update([]float64{1e-3, 2e-3, -1e-3})
+	if err != nil {
+		tst.Errorf("hinge_update failed:\n%v", err)
+		return
+	}
+	chk.Matrix(tst, "Kt", 1e-10, o.Kt, o.K)
+}
+
+func Test_fibersec01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("fibersec01. moment-curvature of fiber sections")
+
+	// section with reinforcement only: plastic moment = 2・As・fy・d
+	mdl := &solid.OnedLinElast{E: 3e7, A: 0.15, I22: 0.003125} // B = 0.3, H = 0.5
+	sec, err := NewFiberSection("!fc:0 !es:2e8 !fy:5e5 !as:0.001 !cov:0.05", mdl)
+	if err != nil {
+		tst.Errorf("NewFiberSection failed:\n%v", err)
+		return
+	}
+	chk.Scalar(tst, "B", 1e-15, sec.B, 0.3)
+	chk.Scalar(tst, "H", 1e-15, sec.H, 0.5)
+	lp := 0.25
+	D, F, dy := sec.Backbone(lp, 100)
+	n := len(D)
+	chk.Scalar(tst, "Mp", 1e-10, F[n-1], 200)
+	chk.Scalar(tst, "-Mp", 1e-10, F[0], -200)
+	chk.Scalar(tst, "D[mid]", 1e-15, D[n/2], 0)
+	κy := 5e5 / 2e8 / 0.2
+	dκ := 20.0 * 0.0035 / (0.5 * 100)
+	chk.Scalar(tst, "dy", 1e-15, dy, math.Ceil(κy/dκ)*dκ*lp)
+
+	// reinforced concrete: equilibrium of axial forces and increasing moments before yielding
+	sec, err = NewFiberSection("!fc:3e4 !es:2e8 !fy:5e5 !as:0.001", mdl)
+	if err != nil {
+		tst.Errorf("NewFiberSection failed:\n%v", err)
+		return
+	}
+	Mold := 0.0
+	for _, κ := range []float64{1e-4, 1e-3, 5e-3} {
+		M, ε0 := sec.Moment(κ)
+		N, _ := sec.Forces(ε0, κ)
+		chk.Scalar(tst, "N", 1e-6, N, 0)
+		if M <= Mold {
+			tst.Errorf("moment must increase with curvature before yielding: %g <= %g", M, Mold)
+			return
+		}
+		Mold = M
+	}
+
+	// hinge law from fiber section
+	o := beamhinge_alloc(tst, mdl, 1, 0, "!hinges:right !fc:3e4 !es:2e8 !fy:5e5 !as:0.001 !lp:0.25 !rule:takeda")
+	if o == nil {
+		return
+	}
+	if o.Hinges[0] != nil || o.Hinges[1] == nil {
+		tst.Errorf("only the hinge at node 1 must be allocated")
+		return
+	}
+	if o.Hinges[1].Dtab == nil || o.Hinges[1].K0 <= 0 || o.Hinges[1].Dy <= 0 {
+		tst.Errorf("tabulated law is invalid")
+	}
+}
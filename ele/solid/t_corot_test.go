@@ -0,0 +1,256 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_corot01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("corot01. quaternions")
+
+	// 90° about z: e0 => e1
+	R := la.MatAlloc(3, 3)
+	q := QuatFromRotVec([]float64{0, 0, math.Pi / 2.0})
+	q.Matrix(R)
+	v := make([]float64, 3)
+	la.MatVecMul(v, 1, R, []float64{1, 0, 0})
+	chk.Vector(tst, "R・e0", 1e-15, v, []float64{0, 1, 0})
+
+	// composition: 90° about z followed by 90° about x => e0 => e2
+	p := QuatFromRotVec([]float64{math.Pi / 2.0, 0, 0}).Mul(q)
+	p.Matrix(R)
+	la.MatVecMul(v, 1, R, []float64{1, 0, 0})
+	chk.Vector(tst, "R・e0", 1e-15, v, []float64{0, 0, 1})
+
+	// round trips
+	θ := make([]float64, 3)
+	for _, θref := range [][]float64{{0, 0, 0}, {1e-9, -2e-9, 3e-9}, {0.3, -0.2, 0.5}, {2.0, 1.5, -1.0}, {-3.1, 0, 0}} {
+		q = QuatFromRotVec(θref)
+		q.RotVec(θ)
+		chk.Vector(tst, "log(exp(θ))", 1e-14, θ, θref)
+		q.Matrix(R)
+		QuatFromMatrix(R).RotVec(θ)
+		chk.Vector(tst, "log(R(θ))", 1e-13, θ, θref)
+	}
+
+	// rotation updates
+	rot := NewCorotRot()
+	rot.Update([]float64{0, 0, 0.5})
+	rot.Backup()
+	rot.Update([]float64{0, 0, 1.2})
+	rot.Q.RotVec(θ)
+	chk.Vector(tst, "θ", 1e-15, θ, []float64{0, 0, 1.2})
+	rot.Restore()
+	rot.Q.RotVec(θ)
+	chk.Vector(tst, "θ(restored)", 1e-15, θ, []float64{0, 0, 0.5})
+}
+
+func Test_corot02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("corot02. 2D kinematics and tangent")
+
+	// rigid rotation about node 0
+	X := [][]float64{{1, 3}, {0.5, 1.5}}
+	o := NewCorot2d(X)
+	φ := 2.5
+	c, s := math.Cos(φ), math.Sin(φ)
+	dx, dy := X[0][1]-X[0][0], X[1][1]-X[1][0]
+	u := []float64{0, 0, φ, c*dx - s*dy - dx, s*dx + c*dy - dy, φ}
+	o.Update(u)
+	chk.Vector(tst, "ul(rigid)", 1e-14, o.Ul, []float64{0, 0, 0})
+
+	// deformed state
+	EA, EI := 100.0, 3.0
+	Kl := la.MatAlloc(3, 3)
+	CorotKl2d(Kl, EA, EI, o.L0)
+	u = []float64{0.1, -0.2, 0.3, 0.4, 0.25, -0.6}
+	forces := func(f, u []float64) {
+		o.Update(u)
+		fl := make([]float64, 3)
+		la.MatVecMul(fl, 1, Kl, o.Ul)
+		o.Forces(f, fl)
+	}
+
+	// B and K versus numerical derivatives
+	o.Update(u)
+	fl := make([]float64, 3)
+	la.MatVecMul(fl, 1, Kl, o.Ul)
+	K := la.MatAlloc(6, 6)
+	o.Tangent(K, Kl, fl)
+	B := la.MatAlloc(3, 6)
+	la.MatCopy(B, 1, o.B)
+	Bnum := la.MatAlloc(3, 6)
+	Knum := la.MatAlloc(6, 6)
+	h := 1e-6
+	fa, fb := make([]float64, 6), make([]float64, 6)
+	for j := 0; j < 6; j++ {
+		tmp := u[j]
+		u[j] = tmp + h
+		forces(fa, u)
+		ula := []float64{o.Ul[0], o.Ul[1], o.Ul[2]}
+		u[j] = tmp - h
+		forces(fb, u)
+		u[j] = tmp
+		for i := 0; i < 3; i++ {
+			Bnum[i][j] = (ula[i] - o.Ul[i]) / (2.0 * h)
+		}
+		for i := 0; i < 6; i++ {
+			Knum[i][j] = (fa[i] - fb[i]) / (2.0 * h)
+		}
+	}
+	chk.Matrix(tst, "B", 1e-9, B, Bnum)
+	chk.Matrix(tst, "K", 1e-7, K, Knum)
+}
+
+func Test_corot03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("corot03. cantilever under end moment")
+
+	// cantilever along x; the exact tip rotation is M L / EI
+	L, EA, EI := 10.0, 1e4, 2.0
+	nel := 8
+	M := math.Pi * EI / L // half circle
+	nu := 3 * (nel + 1)
+	elems := make([]*Corot2d, nel)
+	Kl := la.MatAlloc(3, 3)
+	for e := 0; e < nel; e++ {
+		xa, xb := float64(e)*L/float64(nel), float64(e+1)*L/float64(nel)
+		elems[e] = NewCorot2d([][]float64{{xa, xb}, {0, 0}})
+	}
+	CorotKl2d(Kl, EA, EI, L/float64(nel))
+
+	// Newton's method with load increments
+	u := make([]float64, nu)
+	r := make([]float64, nu)
+	du := make([]float64, nu)
+	K := la.MatAlloc(nu, nu)
+	Ki := la.MatAlloc(nu, nu)
+	Ke := la.MatAlloc(6, 6)
+	fe, fl, ue := make([]float64, 6), make([]float64, 3), make([]float64, 6)
+	nincs := 10
+	for inc := 1; inc <= nincs; inc++ {
+		λ := float64(inc) / float64(nincs)
+		var it int
+		for it = 0; it < 20; it++ {
+			la.VecFill(r, 0)
+			la.MatFill(K, 0)
+			r[nu-1] = -λ * M
+			for e, o := range elems {
+				copy(ue, u[3*e:3*e+6])
+				o.Update(ue)
+				la.MatVecMul(fl, 1, Kl, o.Ul)
+				o.Forces(fe, fl)
+				o.Tangent(Ke, Kl, fl)
+				for i := 0; i < 6; i++ {
+					r[3*e+i] += fe[i]
+					for j := 0; j < 6; j++ {
+						K[3*e+i][3*e+j] += Ke[i][j]
+					}
+				}
+			}
+			for i := 0; i < 3; i++ { // clamped
+				r[i] = 0
+				for j := 0; j < nu; j++ {
+					K[i][j], K[j][i] = 0, 0
+				}
+				K[i][i] = 1
+			}
+			if la.VecNorm(r) < 1e-10 {
+				break
+			}
+			_, err := la.MatInv(Ki, K, 1e-14)
+			if err != nil {
+				tst.Errorf("MatInv failed:\n%v", err)
+				return
+			}
+			la.MatVecMul(du, -1, Ki, r)
+			for i := 0; i < nu; i++ {
+				u[i] += du[i]
+			}
+		}
+		if it == 20 {
+			tst.Errorf("Newton's method did not converge @ increment %d\n", inc)
+			return
+		}
+		io.Pforan("λ = %.1f: it = %d  θtip = %v\n", λ, it, u[nu-1])
+	}
+	chk.Scalar(tst, "θtip", 1e-8, u[nu-1], M*L/EI)
+
+	// the chords keep their lengths; i.e. the nodes are on a circle with radius R
+	R := L / float64(nel) / (2.0 * math.Sin(math.Pi/float64(2*nel)))
+	chk.Scalar(tst, "ux(tip)", 1e-8, u[nu-3], -L)
+	chk.Scalar(tst, "uy(tip)", 1e-8, u[nu-2], 2.0*R)
+}
+
+func Test_corot04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("corot04. 3D kinematics")
+
+	// beam in the xy-plane
+	X := [][]float64{{1, 3}, {0.5, 1.5}, {0, 0}}
+	dx, dy := X[0][1]-X[0][0], X[1][1]-X[1][0]
+	L := math.Sqrt(dx*dx + dy*dy)
+	E0 := [][]float64{
+		{dx / L, -dy / L, 0},
+		{dy / L, dx / L, 0},
+		{0, 0, 1},
+	}
+	o := NewCorot3d(X, E0)
+	chk.Vector(tst, "ul(0)", 1e-15, o.Ul, make([]float64, 7))
+
+	// rigid rotation about node 0
+	θ := []float64{0.4, -1.1, 0.7}
+	q := QuatFromRotVec(θ)
+	R := la.MatAlloc(3, 3)
+	q.Matrix(R)
+	u := make([]float64, 6)
+	for i := 0; i < 3; i++ {
+		u[3+i] = R[i][0]*(X[0][1]-X[0][0]) + R[i][1]*(X[1][1]-X[1][0]) + R[i][2]*(X[2][1]-X[2][0]) - (X[i][1] - X[i][0])
+	}
+	o.Update(u, q, q)
+	chk.Vector(tst, "ul(rigid)", 1e-14, o.Ul, make([]float64, 7))
+
+	// planar deformation: compare with 2D
+	u2 := []float64{0.1, -0.2, 0.3, 0.4, 0.25, -0.6}
+	o2 := NewCorot2d([][]float64{X[0], X[1]})
+	o2.Update(u2)
+	u = []float64{u2[0], u2[1], 0, u2[3], u2[4], 0}
+	o.Update(u, QuatFromRotVec([]float64{0, 0, u2[2]}), QuatFromRotVec([]float64{0, 0, u2[5]}))
+	chk.Vector(tst, "ul", 1e-14, []float64{o.Ul[0], o.Ul[3], o.Ul[6]}, o2.Ul)
+	chk.Vector(tst, "ul(out-of-plane)", 1e-14, []float64{o.Ul[1], o.Ul[2], o.Ul[4], o.Ul[5]}, make([]float64, 4))
+
+	// planar tangent
+	EA, GJ, EI := 100.0, 5.0, 3.0
+	Kl2, Kl3 := la.MatAlloc(3, 3), la.MatAlloc(7, 7)
+	CorotKl2d(Kl2, EA, EI, o2.L0)
+	CorotKl3d(Kl3, EA, GJ, 2.0*EI, EI, o.L0)
+	fl2, fl3 := make([]float64, 3), make([]float64, 7)
+	la.MatVecMul(fl2, 1, Kl2, o2.Ul)
+	la.MatVecMul(fl3, 1, Kl3, o.Ul)
+	K2, K3 := la.MatAlloc(6, 6), la.MatAlloc(12, 12)
+	o2.Tangent(K2, Kl2, fl2)
+	o.Tangent(K3, Kl3, fl3)
+	f2, f3 := make([]float64, 6), make([]float64, 12)
+	o2.Forces(f2, fl2)
+	o.Forces(f3, fl3)
+	dofs := []int{0, 1, 5, 6, 7, 11}
+	for i, I := range dofs {
+		chk.Scalar(tst, io.Sf("f%d", I), 1e-6, f3[I], f2[i])
+		for j, J := range dofs {
+			chk.Scalar(tst, io.Sf("K%d%d", I, J), 1e-5, K3[I][J], K2[i][j])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gofem/shp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_interface01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interface01. zero-thickness interface in 2D with coupled pore-pressures")
+
+	// model
+	mdl := new(solid.InterfaceMC)
+	err := mdl.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1000},
+		&fun.Prm{N: "ks", V: 100},
+		&fun.Prm{N: "phi", V: 30},
+		&fun.Prm{N: "kl", V: 0.5},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+
+	// inclined interface with length 2
+	o := &Interface{Cell: &inp.Cell{Shp: shp.Get("qua4", 0)}, Ndim: 2, Nu: 8, Mdl: mdl, Coupled: true}
+	c, s := 0.6, 0.8
+	o.X = [][]float64{{0, 2 * c, 2 * c, 0}, {0, 2 * s, 2 * s, 0}}
+	o.Bot, o.Top = []int{0, 1}, []int{3, 2}
+	o.Fshp = shp.Get("lin2", 0)
+	o.IpsElem, _, err = o.Fshp.GetIps(2, 0)
+	if err != nil {
+		tst.Errorf("GetIps failed:\n%v", err)
+		return
+	}
+	err = o.init_geometry()
+	if err != nil {
+		tst.Errorf("init_geometry failed:\n%v", err)
+		return
+	}
+	o.SetIniIvs(nil, nil)
+	o.Umap = []int{0, 1, 2, 3, 4, 5, 6, 7}
+	o.Pmap = []int{8, 9, 10, 11}
+	o.Kuu = la.MatAlloc(8, 8)
+	o.Kup = la.MatAlloc(8, 4)
+	o.Kpp = la.MatAlloc(4, 4)
+	o.D = la.MatAlloc(2, 2)
+	chk.Matrix(tst, "R", 1e-15, o.Rot[0], [][]float64{{-s, c}, {c, s}})
+
+	// top face moves towards bottom face
+	sol := &ele.Solution{Y: make([]float64, 12), ΔY: make([]float64, 12)}
+	for _, m := range o.Top {
+		sol.ΔY[0+m*2] = 0.001 * s
+		sol.ΔY[1+m*2] = -0.001 * c
+	}
+	copy(sol.Y, sol.ΔY)
+	sol.Y[8], sol.Y[9] = 10, 10 // pressures @ bottom
+	err = o.Update(sol)
+	if err != nil {
+		tst.Errorf("Update failed:\n%v", err)
+		return
+	}
+	for _, st := range o.States {
+		chk.Vector(tst, "t", 1e-12, st.Sig, []float64{-1, 0})
+	}
+
+	// forces on top face: total normal traction = tn - pm = -6; and leakage = kl・10・L
+	fb := make([]float64, 12)
+	o.AddToRhs(fb, sol)
+	chk.Scalar(tst, "Fx(top)", 1e-12, fb[4]+fb[6], 6*2*(-s))
+	chk.Scalar(tst, "Fy(top)", 1e-12, fb[5]+fb[7], 6*2*c)
+	chk.Scalar(tst, "ql(bot)", 1e-12, fb[8]+fb[9], -10)
+	chk.Scalar(tst, "ql(top)", 1e-12, fb[10]+fb[11], 10)
+}
+
+func Test_interface02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interface02. fault with slip-weakening friction and coupled pore-pressures")
+
+	// model
+	mdl := new(solid.Fault)
+	err := mdl.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e4},
+		&fun.Prm{N: "ks", V: 1e3},
+		&fun.Prm{N: "mus", V: 0.6},
+		&fun.Prm{N: "mud", V: 0.4},
+		&fun.Prm{N: "dc", V: 0.01},
+		&fun.Prm{N: "kl", V: 1},
+		&fun.Prm{N: "kls", V: 3},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+
+	// horizontal fault with length 2 and initial tractions
+	o := &Interface{Cell: &inp.Cell{Shp: shp.Get("qua4", 0)}, Ndim: 2, Nu: 8, Mdl: mdl, Coupled: true}
+	o.X = [][]float64{{0, 2, 2, 0}, {0, 0, 0, 0}}
+	o.Bot, o.Top = []int{0, 1}, []int{3, 2}
+	o.Fshp = shp.Get("lin2", 0)
+	o.IpsElem, _, err = o.Fshp.GetIps(2, 0)
+	if err != nil {
+		tst.Errorf("GetIps failed:\n%v", err)
+		return
+	}
+	err = o.init_geometry()
+	if err != nil {
+		tst.Errorf("init_geometry failed:\n%v", err)
+		return
+	}
+	err = o.SetIniIvs(nil, map[string][]float64{"tn": {-10, -10}, "ts": {0, 0}})
+	if err != nil {
+		tst.Errorf("SetIniIvs failed:\n%v", err)
+		return
+	}
+	o.Umap = []int{0, 1, 2, 3, 4, 5, 6, 7}
+	o.Pmap = []int{8, 9, 10, 11}
+
+	// top face slides by 0.03 => slip = 0.03 - μd・σn / ks
+	sol := &ele.Solution{Y: make([]float64, 12), ΔY: make([]float64, 12)}
+	for _, m := range o.Top {
+		sol.ΔY[0+m*2] = 0.03
+	}
+	copy(sol.Y, sol.ΔY)
+	sol.Y[8], sol.Y[9] = 10, 10 // pressures @ bottom
+	err = o.Update(sol)
+	if err != nil {
+		tst.Errorf("Update failed:\n%v", err)
+		return
+	}
+	M := ele.NewIpsMap()
+	o.OutIpVals(M, sol)
+	chk.Strings(tst, "keys", o.OutIpKeys(), []string{"tn", "ts", "wn", "open", "slip", "vs", "mu", "theta"})
+	chk.Vector(tst, "tn", 1e-12, (*M)["tn"], []float64{-10, -10})
+	chk.Vector(tst, "ts", 1e-12, (*M)["ts"], []float64{4, 4})
+	chk.Vector(tst, "slip", 1e-12, (*M)["slip"], []float64{0.026, 0.026})
+	chk.Vector(tst, "mu", 1e-15, (*M)["mu"], []float64{0.4, 0.4})
+	chk.Vector(tst, "open", 1e-15, (*M)["open"], []float64{0, 0})
+
+	// forces on top face: total normal traction = tn - pm = -15; and leakage = kls・10・L
+	fb := make([]float64, 12)
+	o.AddToRhs(fb, sol)
+	chk.Scalar(tst, "Fx(top)", 1e-12, fb[4]+fb[6], -4*2)
+	chk.Scalar(tst, "Fy(top)", 1e-12, fb[5]+fb[7], 15*2)
+	chk.Scalar(tst, "ql(bot)", 1e-12, fb[8]+fb[9], -3*10*2)
+	chk.Scalar(tst, "ql(top)", 1e-12, fb[10]+fb[11], 3*10*2)
+}
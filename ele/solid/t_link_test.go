@@ -0,0 +1,83 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func link_run(tst *testing.T, law *LinkLaw, path, Fcor, Kcor []float64) {
+	s := law.NewState()
+	for i, δ := range path {
+		law.Update(s, δ)
+		chk.Scalar(tst, "F", 1e-12, s.F, Fcor[i])
+		chk.Scalar(tst, "Kt", 1e-12, s.Kt, Kcor[i])
+	}
+}
+
+func Test_link01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("link01. bilinear backbone: elastic and kinematic rules")
+
+	// elastic
+	law, err := NewLinkLaw(nil, "!ka:100 !fya:10 !ra:0.1", "a", "elastic", 0.4)
+	if err != nil {
+		tst.Errorf("NewLinkLaw failed:\n%v", err)
+		return
+	}
+	link_run(tst, law,
+		[]float64{0.05, 0.2, 0.0, -0.2},
+		[]float64{5, 11, 0, -11},
+		[]float64{100, 10, 100, 10})
+
+	// kinematic
+	law, err = NewLinkLaw(nil, "!ka:100 !fya:10 !ra:0.1", "a", "kinematic", 0.4)
+	if err != nil {
+		tst.Errorf("NewLinkLaw failed:\n%v", err)
+		return
+	}
+	link_run(tst, law,
+		[]float64{0.2, 0.0, -0.2, 0.0},
+		[]float64{11, -9, -11, 9},
+		[]float64{10, 100, 10, 100})
+
+	// shear law not given
+	law, err = NewLinkLaw(nil, "!ka:100", "s", "elastic", 0.4)
+	if err != nil || law != nil {
+		tst.Errorf("shear law should not be allocated")
+		return
+	}
+
+	// errors
+	_, err = NewLinkLaw(nil, "!ka:100", "a", "takeda", 0.4)
+	if err == nil {
+		tst.Errorf("Takeda rule without yield displacement should fail")
+	}
+	_, err = NewLinkLaw(nil, "!ka:100 !fya:10", "a", "pinching", 0.4)
+	if err == nil {
+		tst.Errorf("invalid rule should fail")
+	}
+}
+
+func Test_link02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("link02. bilinear backbone: Takeda rule")
+
+	law, err := NewLinkLaw(nil, "!ka:100 !fya:10 !ra:0.1", "a", "takeda", 0.5)
+	if err != nil {
+		tst.Errorf("NewLinkLaw failed:\n%v", err)
+		return
+	}
+	Ku := 70.71067811865476 // 100・sqrt(0.1/0.2)
+	Kr := 69.23457323116116 // reloading from (δ0,0) to (-0.1,-10)
+	link_run(tst, law,
+		[]float64{0.05, 0.2, 0.1, -0.05, -0.2},
+		[]float64{5, 11, 3.9289321881345245, -6.538271338441944, -11},
+		[]float64{100, 10, Ku, Kr, 10})
+}
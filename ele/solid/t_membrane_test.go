@@ -0,0 +1,138 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gofem/shp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_membrane01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("membrane01. tension-field law of membranes")
+
+	mdl := &solid.OnedTension{E: 1000, Nu: 0.25, A: 0.01, Kc: 1e-6}
+	o := &Membrane{Mdl: mdl}
+	σ := make([]float64, 3)
+	D := la.MatAlloc(3, 3)
+	c := 1000.0 / (1.0 - 0.25*0.25)
+
+	// taut
+	mode := o.stress(σ, D, []float64{0.01, 0, 0})
+	if mode != MembraneTaut {
+		tst.Errorf("membrane must be taut. mode = %d", mode)
+		return
+	}
+	chk.Vector(tst, "σ(taut)", 1e-12, σ, []float64{c * 0.01, c * 0.25 * 0.01, 0})
+
+	// wrinkled: uniaxial tension
+	mode = o.stress(σ, D, []float64{0.01, -0.01, 0})
+	if mode != MembraneWrinkled {
+		tst.Errorf("membrane must be wrinkled. mode = %d", mode)
+		return
+	}
+	chk.Vector(tst, "σ(wrinkled)", 1e-6, σ, []float64{10, 0, 0})
+
+	// slack
+	mode = o.stress(σ, D, []float64{-0.01, -0.02, 0.001})
+	if mode != MembraneSlack {
+		tst.Errorf("membrane must be slack. mode = %d", mode)
+		return
+	}
+	chk.Vector(tst, "σ(slack)", 1e-6, σ, []float64{0, 0, 0})
+
+	// tangent of wrinkled state with shear
+	ε := []float64{0.01, -0.008, 0.006}
+	o.stress(σ, D, ε)
+	σtmp := make([]float64, 3)
+	Dtmp := la.MatAlloc(3, 3)
+	h := 1e-7
+	Dnum := la.MatAlloc(3, 3)
+	for j := 0; j < 3; j++ {
+		εtmp := []float64{ε[0], ε[1], ε[2]}
+		εtmp[j] += h
+		if o.stress(σtmp, Dtmp, εtmp) != MembraneWrinkled {
+			tst.Errorf("membrane must be wrinkled")
+			return
+		}
+		for i := 0; i < 3; i++ {
+			Dnum[i][j] = (σtmp[i] - σ[i]) / h
+		}
+	}
+	chk.Matrix(tst, "D(wrinkled)", 1e-3, D, Dnum)
+}
+
+func Test_membrane02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("membrane02. uniaxial stretching of inclined membrane")
+
+	// membrane on the plane x = z
+	mdl := &solid.OnedTension{E: 1000, Nu: 0, A: 0.01, Kc: 1e-6}
+	o := &Membrane{Cell: &inp.Cell{Shp: shp.Get("qua4", 0)}, Mdl: mdl, Nu: 12}
+	o.X = [][]float64{
+		{0, 1, 1, 0},
+		{0, 0, 2, 2},
+		{0, 1, 1, 0},
+	}
+	var err error
+	o.IpsElem, _, err = o.Cell.Shp.GetIps(4, 0)
+	if err != nil {
+		tst.Errorf("GetIps failed:\n%v", err)
+		return
+	}
+	err = o.init_geometry()
+	if err != nil {
+		tst.Errorf("init_geometry failed:\n%v", err)
+		return
+	}
+	o.SetIniIvs(nil, nil)
+	o.Umap = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+
+	// stretching along y
+	e := 0.01
+	ΔY := make([]float64, 12)
+	for m := 0; m < 4; m++ {
+		ΔY[1+m*3] = e * o.X[1][m]
+	}
+	err = o.Update(&ele.Solution{ΔY: ΔY})
+	if err != nil {
+		tst.Errorf("Update failed:\n%v", err)
+		return
+	}
+	for idx, s := range o.States {
+		chk.Scalar(tst, "|ε|²", 1e-15, la.VecDot(s.Eps, s.Eps), e*e)
+		chk.Scalar(tst, "σ11+σ22", 1e-12, s.Sig[0]+s.Sig[1], 1000*e)
+		chk.Scalar(tst, "σ12", 1e-12, s.Sig[2], 0)
+		chk.Scalar(tst, "σ22", 1e-12, s.Sig[1], 1000*e)
+		chk.Scalar(tst, "a2・y", 1e-15, math.Abs(o.A2[idx][1]), 1)
+	}
+
+	// reactions @ top nodes: σ・t・width
+	fb := make([]float64, 12)
+	o.AddToRhs(fb, nil)
+	chk.Scalar(tst, "Fy(top)", 1e-12, -(fb[7] + fb[10]), 1000*e*0.01*math.Sqrt2)
+	chk.Scalar(tst, "Fy(bot)", 1e-12, -(fb[1] + fb[4]), -1000*e*0.01*math.Sqrt2)
+
+	// compression: slack
+	for i := 0; i < 12; i++ {
+		ΔY[i] = -2.0 * ΔY[i]
+	}
+	o.Update(&ele.Solution{ΔY: ΔY})
+	for _, s := range o.States {
+		if s.Mode != MembraneSlack {
+			tst.Errorf("membrane must be slack. mode = %d", s.Mode)
+			return
+		}
+	}
+}
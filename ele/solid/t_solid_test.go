@@ -5,11 +5,14 @@
 package solid
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gofem/ele"
 	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/utl"
 )
 
@@ -41,3 +44,117 @@ func Test_solid01(tst *testing.T) {
 	}, nil)
 	chk.Ints(tst, "Umap", e.Umap, utl.IntRange(18))
 }
+
+func Test_solid02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solid02. hourglass control of one-point qua4")
+
+	// load sim => mesh => edat => cell
+	sim := inp.ReadSim("data/hourglass.sim", "", true, 0)
+	msh := sim.Regions[0].Msh
+	edat := sim.Regions[0].ElemsData[0]
+	cell := msh.Cells[0]
+
+	// element
+	allocator := ele.GetAllocator("solid")
+	e := allocator(sim, cell, edat, ele.BuildCoordsMatrix(cell, msh)).(*Solid)
+	e.SetEqs([][]int{{0, 1}, {2, 3}, {4, 5}, {6, 7}}, nil)
+	sol := &ele.Solution{Steady: true, Y: make([]float64, 8)}
+	err := e.SetIniIvs(sol, nil)
+	if err != nil {
+		tst.Errorf("SetIniIvs failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(len(e.IpsElem), 1)
+	chk.Scalar(tst, "κ", 1e-15, e.HgCoef, 0.05)
+
+	// stiffness
+	var Kb la.Triplet
+	Kb.Init(8, 8, 64)
+	err = e.AddToKb(&Kb, sol, true)
+	if err != nil {
+		tst.Errorf("AddToKb failed:\n%v", err)
+		return
+	}
+
+	// energy of hourglass mode: ux = h = {1,-1,1,-1} => q = 1 and uᵀ・K・u = c
+	E, ν := 10000.0, 0.2
+	V, GG := 2.0, 1.25
+	c := 0.05 * E * (1.0 - ν) / ((1.0 + ν) * (1.0 - 2.0*ν)) * V * GG / 2.0
+	uhg := []float64{1, 0, -1, 0, 1, 0, -1, 0}
+	Ku := make([]float64, 8)
+	la.MatVecMul(Ku, 1, e.K, uhg)
+	io.Pforan("c = %v\n", c)
+	chk.Scalar(tst, "uᵀ・K・u", 1e-10, la.VecDot(uhg, Ku), c)
+
+	// rigid body translation and linear field do not activate hourglass forces
+	copy(sol.Y, []float64{0.1, 0.2, 0.1, 0.2, 0.1, 0.2, 0.1, 0.2})
+	fb := make([]float64, 8)
+	err = e.hourglass_add_to_rhs(fb, sol)
+	if err != nil {
+		tst.Errorf("hourglass_add_to_rhs failed:\n%v", err)
+		return
+	}
+	chk.Vector(tst, "fb(rigid)", 1e-15, fb, make([]float64, 8))
+	for m := 0; m < 4; m++ {
+		x, y := e.X[0][m], e.X[1][m]
+		sol.Y[m*2], sol.Y[1+m*2] = 0.01*x+0.02*y, -0.03*x+0.01*y
+	}
+	r, ok := e.HourglassRatio(sol)
+	if !ok {
+		tst.Errorf("HourglassRatio must be available for one-point qua4\n")
+		return
+	}
+	chk.Scalar(tst, "r(linear)", 1e-15, r, 0)
+
+	// pure hourglass mode
+	copy(sol.Y, uhg)
+	r, _ = e.HourglassRatio(sol)
+	if !math.IsInf(r, 1) {
+		tst.Errorf("hourglass ratio of pure hourglass mode must be +Inf. %g is incorrect\n", r)
+	}
+}
+
+func Test_solid03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solid03. thermal strains with temperatures from \"temp\" dofs")
+
+	// load sim => mesh => edat => cell
+	sim := inp.ReadSim("data/thermal.sim", "", true, 0)
+	msh := sim.Regions[0].Msh
+	edat := sim.Regions[0].ElemsData[0]
+	cell := msh.Cells[0]
+
+	// element with "temp" dofs @ nodes after displacements
+	allocator := ele.GetAllocator("solid")
+	e := allocator(sim, cell, edat, ele.BuildCoordsMatrix(cell, msh)).(*Solid)
+	e.SetEqs([][]int{{0, 1}, {2, 3}, {4, 5}, {6, 7}}, nil)
+	e.SetTempEqs([]int{8, 9, 10, 11})
+	sol := &ele.Solution{Steady: true, Y: make([]float64, 12), ΔY: make([]float64, 12)}
+	err := e.SetIniIvs(sol, nil)
+	if err != nil {
+		tst.Errorf("SetIniIvs failed:\n%v", err)
+		return
+	}
+
+	// heating from T = 5 to T = 25 with fixed displacements
+	for eq := 8; eq < 12; eq++ {
+		sol.Y[eq], sol.ΔY[eq] = 25, 20
+	}
+	err = e.Update(sol)
+	if err != nil {
+		tst.Errorf("Update failed:\n%v", err)
+		return
+	}
+	chk.Vector(tst, "Tnod", 1e-15, e.Tnod, []float64{25, 25, 25, 25})
+	chk.Vector(tst, "Tonod", 1e-15, e.Tonod, []float64{5, 5, 5, 5})
+
+	// plane-strain with incremental linear elasticity: Δσ = -3K・α・ΔT along all normal directions
+	E, ν, α := 10000.0, 0.2, 1e-5
+	σ := -E / (1.0 - 2.0*ν) * α * 20
+	for idx, s := range e.States {
+		chk.Vector(tst, io.Sf("σ%d", idx), 1e-12, s.Sig, []float64{σ, σ, σ, 0})
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/tsr"
-	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gofem/mdl/thermomech"
 	elesolid "github.com/cpmech/gofem/ele/solid"
 	mdlsolid "github.com/cpmech/gofem/mdl/solid"
@@ -49,9 +48,12 @@ type SolidThermal struct {
 
 	// material models and internal variables (sld model)
 	SldMdl       mdlsolid.Model      // material model
+	SldName      string              // name of material model; e.g. for the names of internal variables
 	SldMdlSmall  mdlsolid.Small      // model specialisation for small strains
 	SldMdlLarge  mdlsolid.Large      // model specialisation for large deformations
 	TrmMdl       *thermomech.Thermomech      // thermal material model
+	Tdep         *mdlsolid.TempDep           // temperature dependent parameters of sld model; nil if not given
+	DσdT         [][]float64                 // [nip][nsig] ∂σ/∂T @ ips; if Tdep != nil
 
 	// internal variables
 	States    []*mdlsolid.State   // [nip] states
@@ -105,6 +107,9 @@ type SolidThermal struct {
 	Ktc        [][]float64   // [nt][nc]
 	Kct        [][]float64   // [nc][nt]
 	Kcc        [][]float64   // [nc][nc]
+
+	// staggered solution
+	split      int           // stage of staggered solution; see ele.SplitFlow and ele.SplitMech
 }
 
 // initialisation ///////////////////////////////////////////////////////////////////////////////////
@@ -206,11 +211,12 @@ func init() {
 		nip := len(o.IpsElem)
 
 		// model
-		mat := sim.MatModels.Get(edat.Mat)
+		mat := sim.MatModels.GetCell(edat.Mat, cell)
 		if mat == nil {
 			chk.Panic("cannot find material %q for solid-thermal element {tag=%d, id=%d}\n", edat.Mat, cell.Tag, cell.Id)
 		}
 		o.SldMdl = mat.Sld
+		o.SldName = mat.SldName
 		o.TrmMdl = mat.Trm.(*thermomech.Thermomech)
 
 		// model specialisations
@@ -223,6 +229,15 @@ func init() {
 			chk.Panic("__internal_error__: 'u' element cannot determine the type of the material model")
 		}
 
+		// temperature dependent parameters
+		if len(mat.SldTdep) > 0 {
+			o.Tdep, err = mdlsolid.NewTempDep(mat.SldName, o.Ndim, sim.Data.Pstress, mat.SldPrms, mat.SldTdep, nip)
+			if err != nil {
+				chk.Panic("cannot allocate temperature dependent model of solid-thermal element {tag=%d, id=%d}:\n%v", cell.Tag, cell.Id, err)
+			}
+			o.DσdT = la.MatAlloc(nip, 2*o.Ndim)
+		}
+
 		// local starred variables
 		o.ζs = la.MatAlloc(nip, o.Ndim)
 		o.χs = la.MatAlloc(nip, o.Ndim)
@@ -471,19 +486,20 @@ func (o *SolidThermal) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
 
 	// contribution from natural boundary conditions
 	if len(o.NatBcs) > 0 {
-		err = o.add_surfloads_to_rhs(fb, sol)
-		if err != nil {
-			return
-		}
-		err = o.add_natbcs_to_rhs(fb, sol)
-		if err != nil {
-			return
-		}
+		return o.AddNatBcsToRhs(fb, sol)
 	}
-
 	return
 }
 
+// AddNatBcsToRhs adds surface loads and natural boundary conditions to rhs
+func (o *SolidThermal) AddNatBcsToRhs(fb []float64, sol *ele.Solution) (err error) {
+	err = o.add_surfloads_to_rhs(fb, sol)
+	if err != nil {
+		return
+	}
+	return o.add_natbcs_to_rhs(fb, sol)
+}
+
 // adds element K to global Jacobian matrix Kb
 func (o *SolidThermal) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
 
@@ -526,7 +542,11 @@ func (o *SolidThermal) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool)
 		dkdu = o.TrmMdl.DkDu(o.tval)
 
 		// consistent tangent model matrix
-		err = o.SldMdlSmall.CalcD(o.D, o.States[idx], firstIt)
+		mdl := o.SldMdlSmall
+		if o.Tdep != nil {
+			mdl = o.Tdep.Mdls[idx]
+		}
+		err = mdl.CalcD(o.D, o.States[idx], firstIt)
 		if err != nil {
 			return
 		}
@@ -567,6 +587,22 @@ func (o *SolidThermal) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool)
 					o.Ktu[m][r] += coef * St[m] * G[n][i] * o.TrmMdl.Acte[i] * E[0] * (o.tval + o.TrmMdl.T0) * α4
 				}
 			}
+			if o.Tdep != nil { // temperature dependent parameters: ∂σ/∂T term
+				for r := 0; r < o.Nu; r++ {
+					var bσ float64
+					if o.UseB {
+						for k, v := range o.DσdT[idx] {
+							bσ += o.B[k][r] * v
+						}
+					} else {
+						i, n := r % o.Ndim, r / o.Ndim
+						for j := 0; j < o.Ndim; j++ {
+							bσ += tsr.M2T(o.DσdT[idx], i, j) * G[n][j]
+						}
+					}
+					o.Kut[r][m] += coef * bσ * St[m]
+				}
+			}
 			for n := 0; n < t_nverts; n++ {
 				o.Ktt[n][m] += coef * St[m] * St[n] * β1 * ρ * o.TrmMdl.Cp
 				for i := 0; i < o.Ndim; i++ {
@@ -596,6 +632,11 @@ func (o *SolidThermal) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool)
 	}
 	for i, I := range o.Tmap {
 		for j, J := range o.Umap {
+			if o.split != ele.SplitNone { // staggered solution: keep sparsity pattern only
+				Kb.Put(I, J, 0)
+				Kb.Put(J, I, 0)
+				continue
+			}
 			Kb.Put(I, J, o.Ktu[i][j])
 			Kb.Put(J, I, o.Kut[j][i])
 		}
@@ -654,8 +695,25 @@ func (o *SolidThermal) Update(sol *ele.Solution) (err error) {
 			elesolid.IpStrainsAndInc(o.ε, o.Δε, nverts, o.Ndim, sol.Y, sol.ΔY, o.Umap, G)
 		}
 
+		// model with parameters at current temperature and ∂σ/∂T
+		mdl := o.SldMdlSmall
+		if o.Tdep != nil {
+			err = o.ipvars(idx, sol)
+			if err != nil {
+				return
+			}
+			err = o.Tdep.DsigDT(o.DσdT[idx], o.States[idx], o.ε, o.Δε, o.Id(), idx, sol.T, o.tval)
+			if err != nil {
+				return chk.Err("cannot compute ∂σ/∂T (eid=%d, ip=%d):\n%v", o.Id(), idx, err)
+			}
+			mdl, err = o.Tdep.Model(idx, o.tval)
+			if err != nil {
+				return chk.Err("eid=%d, ip=%d:\n%v", o.Id(), idx, err)
+			}
+		}
+
 		// call model update => update stresses
-		err = o.SldMdlSmall.Update(o.States[idx], o.ε, o.Δε, o.Id(), idx, sol.T)
+		err = mdl.Update(o.States[idx], o.ε, o.Δε, o.Id(), idx, sol.T)
 		if err != nil {
 			return chk.Err("Update failed (eid=%d, ip=%d)\nΔε=%v\n%v", o.Id(), idx, o.Δε, err)
 		}
@@ -665,6 +723,18 @@ func (o *SolidThermal) Update(sol *ele.Solution) (err error) {
 
 // internal variables ///////////////////////////////////////////////////////////////////////////////
 
+// SetSplit sets the stage of the staggered solution
+//  Note: there is no stabilisation term for the thermal coupling; i.e. slot and β are ignored
+func (o *SolidThermal) SetSplit(stage, slot int, β float64, sol *ele.Solution) (err error) {
+	o.split = stage
+	return
+}
+
+// RecordSplit does nothing
+func (o *SolidThermal) RecordSplit(slot int, sol *ele.Solution) (err error) {
+	return
+}
+
 // SetIniIvs sets initial ivs for given values in sol and ivs map
 func (o *SolidThermal) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
 	// allocate slices of states
@@ -758,11 +828,7 @@ func (o *SolidThermal) OutIpCoords() (C [][]float64) {
 
 // OutIpKeys returns the integration points' keys
 func (o *SolidThermal) OutIpKeys() []string {
-	keys := elesolid.StressKeys(o.Ndim)
-	for i := 0; i < len(o.States[0].Alp); i++ {
-		keys = append(keys, io.Sf("alp%d", i))
-	}
-	return keys
+	return append(elesolid.StressKeys(o.Ndim), mdlsolid.IntVarNames(o.SldName, o.SldMdl, len(o.States[0].Alp))...)
 }
 
 // OutIpVals returns the integration points' values corresponding to keys
@@ -773,8 +839,7 @@ func (o *SolidThermal) OutIpVals(M *ele.IpsMap, sol *ele.Solution) {
 			M.Set(key, idx, nip, o.States[idx].Sig[i])
 		}
 	}
-	for i := 0; i < len(o.States[0].Alp); i++ {
-		key := io.Sf("alp%d", i)
+	for i, key := range mdlsolid.IntVarNames(o.SldName, o.SldMdl, len(o.States[0].Alp)) {
 		for idx, _ := range o.IpsElem {
 			M.Set(key, idx, nip, o.States[idx].Alp[i])
 		}
@@ -861,9 +926,6 @@ func (o *SolidThermal) add_surfloads_to_rhs(fb []float64, sol *ele.Solution) (er
 	var res float64
 	for _, nbc := range o.NatBcs {
 
-		// function evaluation
-		res = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for _, ipf := range o.IpsFace {
 
@@ -873,6 +935,10 @@ func (o *SolidThermal) add_surfloads_to_rhs(fb []float64, sol *ele.Solution) (er
 			if err != nil {
 				return
 			}
+
+			// function evaluation @ face ip
+			res = ele.NatBcValue(nbc.Fcn, sol.T, o.Cell.Shp, o.X, iface)
+
 			Sf := o.Cell.Shp.Sf
 			nvec := o.Cell.Shp.Fnvec
 
@@ -903,9 +969,6 @@ func (o *SolidThermal) add_natbcs_to_rhs(fb []float64, sol *ele.Solution) (err e
 	var bcval float64
 	for _, nbc := range o.NatBcs {
 
-		// specified boandary condition value: qb->flux, qc->external temp, qr->external temp
-		bcval = nbc.Fcn.F(sol.T, nil)
-
 		// loop over ips of face
 		for _, ipf := range o.IpsFace {
 
@@ -916,6 +979,10 @@ func (o *SolidThermal) add_natbcs_to_rhs(fb []float64, sol *ele.Solution) (err e
 				return
 			}
 
+			// specified boandary condition value: qb->flux, qc->external temp, qr->external temp @ face ip
+			bcval = ele.NatBcValue(nbc.Fcn, sol.T, o.LbbCell.Shp, o.X, iface)
+
+
 			Sf := o.LbbCell.Shp.Sf
 			Jf := la.VecNorm(o.LbbCell.Shp.Fnvec)
 			coef := ipf[3] * Jf
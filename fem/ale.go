@@ -0,0 +1,276 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/inp"
+	mdlsolid "github.com/cpmech/gofem/mdl/solid"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Ale implements the updated-Lagrangian analysis of solids with ALE (arbitrary Lagrangian-Eulerian)
+// mesh smoothing to delay the failure of analyses with large distortions of elements; e.g. due to
+// large settlements or penetration
+//  Note: (1) after each converged time step, the coordinates of the solid elements are updated to
+//            the current coordinates x = X + u; thus, the strain increments of the next steps are
+//            computed in the current configuration. The displacements u are total (from the
+//            reference coordinates X of vertices)
+//        (2) if the minimum Jacobian ratio of cells in the current configuration is smaller than
+//            Qmin, the mesh is smoothed with the smart Laplacian method (see inp.Mesh.Smooth). The
+//            nodal values (and rates) at the moved vertices are then interpolated within the cells
+//            of the previous mesh containing them. The reference coordinates are interpolated as
+//            well; thus x = X + u holds at the moved vertices
+//        (3) the states at integration points are convected by taking the states of the closest
+//            integration points of the previous elements containing them
+//        (4) only solid elements with small-strain models are supported; the models must be
+//            incremental (i.e. based on Δε) because the total strains are not consistent across
+//            configurations. Hourglass control, contact, XFEM and updated body accelerations are
+//            not available
+//        (5) all solid cells of the mesh must be active and only serial runs are supported
+type Ale struct {
+	Dat     *inp.AleData   // input data
+	Elems   []*solid.Solid // solid elements
+	Nsmooth int            // number of smoothings of the mesh
+}
+
+// NewAle allocates a new Ale structure for the solids of domain
+func NewAle(d *Domain, dat *inp.AleData) (o *Ale, err error) {
+	if d.Distr {
+		return nil, chk.Err("ALE mesh smoothing is not available in parallel runs")
+	}
+	if d.Batches != nil || d.Eros != nil || d.Crack != nil {
+		return nil, chk.Err("ALE mesh smoothing cannot be combined with batches of elements, erosion or cracking")
+	}
+	o = new(Ale)
+	o.Dat = dat
+	for _, e := range d.Elems {
+		s, ok := e.(*solid.Solid)
+		if !ok {
+			return nil, chk.Err("ALE mesh smoothing requires solid elements only. element (eid=%d) is invalid", e.Id())
+		}
+		if s.MdlSmall == nil {
+			return nil, chk.Err("ALE mesh smoothing requires small-strain models. model %q of solid (eid=%d) is invalid", s.MdlName, s.Id())
+		}
+		if s.HgCoef > 0 || s.HasContact || s.Xfem || s.Aupd {
+			return nil, chk.Err("ALE mesh smoothing is not available for solids with hourglass control, contact, XFEM or updated accelerations. solid (eid=%d) is invalid", s.Id())
+		}
+		o.Elems = append(o.Elems, s)
+	}
+	for _, c := range d.Msh.Cells {
+		if c.IsSolid && d.Cid2elem[c.Id] == nil {
+			return nil, chk.Err("ALE mesh smoothing requires all solid cells to be active. cell # %d is inactive", c.Id)
+		}
+	}
+	if len(o.Elems) == 0 {
+		return nil, chk.Err("ALE mesh smoothing requires solid elements")
+	}
+	return
+}
+
+// Step updates the coordinates of elements and smoothes the mesh if needed after a time step has
+// converged
+func (o *Ale) Step(d *Domain) (err error) {
+
+	// quality of cells in current configuration
+	x := ale_current(d)
+	cur := d.Msh.Deformed(x)
+	qmin := math.Inf(1)
+	for _, q := range cur.Quality().Cells {
+		qmin = math.Min(qmin, q.Jratio)
+	}
+
+	// smoothing
+	if qmin < o.Dat.Qmin {
+		err = o.smooth(d, cur, x)
+		if err != nil {
+			return
+		}
+		o.Nsmooth++
+		x = ale_current(d)
+		if d.ShowMsg {
+			cur = d.Msh.Deformed(x)
+			qnew := math.Inf(1)
+			for _, q := range cur.Quality().Cells {
+				qnew = math.Min(qnew, q.Jratio)
+			}
+			io.Pf("\n>> ALE mesh smoothing at t = %g: min Jacobian ratio = %g => %g\n", d.Sol.T, qmin, qnew)
+		}
+	}
+
+	// update coordinates of elements
+	for _, s := range o.Elems {
+		for m, v := range s.Cell.Verts {
+			for i := 0; i < s.Ndim; i++ {
+				s.X[i][m] = x[v][i]
+			}
+		}
+		s.Emat = nil
+	}
+	return
+}
+
+// smooth smoothes the mesh in the current configuration and remaps the nodal values and the
+// states at integration points
+//  cur -- mesh with current coordinates x; modified
+func (o *Ale) smooth(d *Domain, cur *inp.Mesh, x [][]float64) (err error) {
+
+	// locator of points in previous mesh
+	loc := inp.NewCellLocator(cur, 0)
+
+	// previous integration points and states
+	prevX := make(map[int][][]float64)
+	prevS := make(map[int][]*mdlsolid.State)
+	for _, s := range o.Elems {
+		prevX[s.Id()] = ale_ipcoords(s, x)
+		prevS[s.Id()] = make([]*mdlsolid.State, len(s.States))
+		for i, st := range s.States {
+			prevS[s.Id()][i] = st.GetCopy()
+		}
+	}
+
+	// smoothing
+	moved, err := cur.Smooth(o.Dat.Niter, o.Dat.Omega)
+	if err != nil {
+		return
+	}
+	if len(moved) == 0 {
+		return
+	}
+
+	// interpolate reference coordinates and nodal values at new positions of vertices
+	nd := d.Msh.Ndim
+	newX := make(map[int][]float64)
+	type update struct {
+		eq      int
+		y, v, a float64
+	}
+	var upds []update
+	for _, vid := range moved {
+		c, r := loc.Find(cur.Verts[vid].C)
+		if c == nil {
+			return chk.Err("cannot find vertex # %d (x=%v) in previous mesh", vid, cur.Verts[vid].C)
+		}
+		sh := c.Shp
+		sh.Func(sh.S, sh.DSdR, r, false, -1)
+		newX[vid] = make([]float64, nd)
+		for m, w := range c.Verts {
+			for i := 0; i < nd; i++ {
+				newX[vid][i] += sh.S[m] * d.Msh.Verts[w].C[i]
+			}
+		}
+		nod := d.Vid2node[vid]
+		if nod == nil {
+			continue
+		}
+		for _, dof := range nod.Dofs {
+			sh, eqs, ok := interp_shape(d, c, r, dof.Key)
+			if !ok {
+				return chk.Err("cannot interpolate dof %q of vertex # %d", dof.Key, vid)
+			}
+			u := update{eq: dof.Eq}
+			for m := 0; m < sh.Nverts; m++ {
+				u.y += sh.S[m] * d.Sol.Y[eqs[m]]
+				u.v += sh.S[m] * d.Sol.Dydt[eqs[m]]
+				u.a += sh.S[m] * d.Sol.D2ydt2[eqs[m]]
+			}
+			upds = append(upds, u)
+		}
+	}
+	for _, u := range upds {
+		d.Sol.Y[u.eq], d.Sol.Dydt[u.eq], d.Sol.D2ydt2[u.eq] = u.y, u.v, u.a
+	}
+	for vid, X := range newX {
+		copy(d.Msh.Verts[vid].C, X)
+	}
+
+	// convect states at integration points of elements with moved vertices
+	xnew := ale_current(d)
+	elems := make(map[int]bool)
+	for _, vid := range moved {
+		for _, cid := range d.Msh.Verts[vid].SharedBy {
+			elems[cid] = true
+		}
+	}
+	for _, s := range o.Elems {
+		if !elems[s.Id()] {
+			continue
+		}
+		for idx, y := range ale_ipcoords(s, xnew) {
+			c, _ := loc.Find(y)
+			if c == nil {
+				return chk.Err("cannot find integration point (x=%v) of solid (eid=%d) in previous mesh", y, s.Id())
+			}
+			if c.Tag != s.Cell.Tag {
+				return chk.Err("cannot remap state of integration point (x=%v) of solid (eid=%d) from cell # %d with different tag", y, s.Id(), c.Id)
+			}
+			jclose, dmin := 0, -1.0
+			for j, z := range prevX[c.Id] {
+				dist := utl.L2norm(y, z)
+				if dmin < 0 || dist < dmin {
+					jclose, dmin = j, dist
+				}
+			}
+			s.States[idx].Set(prevS[c.Id][jclose])
+		}
+		err = s.BackupIvs(false)
+		if err != nil {
+			return
+		}
+		err = s.BackupIvs(true)
+		if err != nil {
+			return
+		}
+	}
+
+	// save mesh with new reference coordinates
+	d.Msh.WriteMsh(d.Sim.DirOut, d.Sim.Key+"_ale.msh")
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// ale_current returns the current coordinates x = X + u of all vertices of domain
+func ale_current(d *Domain) (x [][]float64) {
+	ukeys := []string{"ux", "uy", "uz"}
+	nd := d.Msh.Ndim
+	x = make([][]float64, len(d.Msh.Verts))
+	for vid, v := range d.Msh.Verts {
+		x[vid] = make([]float64, nd)
+		copy(x[vid], v.C[:nd])
+		nod := d.Vid2node[vid]
+		if nod == nil {
+			continue
+		}
+		for i := 0; i < nd; i++ {
+			if eq := nod.GetEq(ukeys[i]); eq >= 0 {
+				x[vid][i] += d.Sol.Y[eq]
+			}
+		}
+	}
+	return
+}
+
+// ale_ipcoords returns the coordinates of the integration points of solid with the coordinates x
+// of vertices
+func ale_ipcoords(s *solid.Solid, x [][]float64) (C [][]float64) {
+	X := make([][]float64, s.Ndim)
+	for i := 0; i < s.Ndim; i++ {
+		X[i] = make([]float64, len(s.Cell.Verts))
+		for m, v := range s.Cell.Verts {
+			X[i][m] = x[v][i]
+		}
+	}
+	C = make([][]float64, len(s.IpsElem))
+	for idx, ip := range s.IpsElem {
+		C[idx] = s.Cell.Shp.IpRealCoords(X, ip)
+	}
+	return
+}
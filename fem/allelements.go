@@ -6,6 +6,7 @@ package fem
 
 import (
 	"github.com/cpmech/gofem/ele/diffusion"
+	"github.com/cpmech/gofem/ele/fracture"
 	"github.com/cpmech/gofem/ele/porous"
 	"github.com/cpmech/gofem/ele/seepage"
 	"github.com/cpmech/gofem/ele/solid"
@@ -15,6 +16,7 @@ import (
 // enforce loading of all elements
 func init() {
 	_ = diffusion.Diffusion{}
+	_ = fracture.SolidPhaseField{}
 	_ = porous.SolidLiquid{}
 	_ = seepage.Liquid{}
 	_ = solid.Solid{}
@@ -0,0 +1,160 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// CentrifugeAcc implements fun.Func with the component I of the centrifugal acceleration
+//  a(t, x) = m(t)・ω²・(r - (r・d) d)  with  r = x - xa
+// where xa is a point on the axis of rotation, d is the (unit) direction of the axis and m(t) is
+// the multiplier of the acceleration; e.g. the spin-up of the centrifuge
+//  Note: in 2D, the axis is perpendicular to the plane; i.e. d = 0
+type CentrifugeAcc struct {
+	I    int       // index of component
+	Ω2   float64   // square of angular velocity: ω²
+	Xa   []float64 // [ndim] point on axis of rotation
+	Dir  []float64 // [3] unit direction of axis (3D only); nil in 2D
+	Mult fun.Func  // multiplier m(t)
+}
+
+// Init does nothing: parameters are set by Domain.SetCentrifuge
+func (o *CentrifugeAcc) Init(prms fun.Prms) (err error) {
+	return
+}
+
+// F returns y = F(t, x)
+func (o *CentrifugeAcc) F(t float64, x []float64) float64 {
+	return o.Mult.F(t, nil) * o.Ω2 * o.radial(x)
+}
+
+// G returns ∂y/∂t_cteX = G(t, x)
+func (o *CentrifugeAcc) G(t float64, x []float64) float64 {
+	return o.Mult.G(t, nil) * o.Ω2 * o.radial(x)
+}
+
+// H returns ∂²y/∂t²_cteX = H(t, x)
+func (o *CentrifugeAcc) H(t float64, x []float64) float64 {
+	return o.Mult.H(t, nil) * o.Ω2 * o.radial(x)
+}
+
+// Grad returns ∇F = ∂y/∂x = Grad(t, x)
+func (o *CentrifugeAcc) Grad(v []float64, t float64, x []float64) {
+	c := o.Mult.F(t, nil) * o.Ω2
+	for j := range v {
+		v[j] = 0
+		if j == o.I {
+			v[j] = c
+		}
+		if o.Dir != nil {
+			v[j] -= c * o.Dir[o.I] * o.Dir[j]
+		}
+	}
+}
+
+// radial returns the component I of the vector from the axis to x (perpendicular to axis)
+func (o *CentrifugeAcc) radial(x []float64) (r float64) {
+	r = x[o.I] - o.Xa[o.I]
+	if o.Dir != nil {
+		var rd float64
+		for j, d := range o.Dir {
+			rd += (x[j] - o.Xa[j]) * d
+		}
+		r -= rd * o.Dir[o.I]
+	}
+	return
+}
+
+// SetCentrifuge sets the centrifugal acceleration of elements as body accelerations ("ax", "ay"
+// and "az" element conditions). See inp.CentrifugeData
+func (o *Domain) SetCentrifuge(dat *inp.CentrifugeData) (err error) {
+
+	// check
+	if dat == nil {
+		return
+	}
+	ndim := o.Sim.Ndim
+	if len(dat.Axis) != ndim {
+		return chk.Err("point on the axis of rotation must have %d coordinates. axis = %v is invalid", ndim, dat.Axis)
+	}
+	if dat.Ng <= 0 || dat.Rref <= 0 {
+		return chk.Err("g-level and reference radius must be positive. ng = %g and rref = %g are invalid", dat.Ng, dat.Rref)
+	}
+
+	// direction of axis
+	var dir []float64
+	if ndim == 3 {
+		if len(dat.Dir) != 3 {
+			return chk.Err("direction of the axis of rotation must be given in 3D. dir = %v is invalid", dat.Dir)
+		}
+		var norm float64
+		for _, d := range dat.Dir {
+			norm += d * d
+		}
+		norm = math.Sqrt(norm)
+		if norm < 1e-14 {
+			return chk.Err("direction of the axis of rotation must not be zero")
+		}
+		dir = make([]float64, 3)
+		for i, d := range dat.Dir {
+			dir[i] = d / norm
+		}
+	}
+
+	// angular velocity
+	g := dat.G
+	if g <= 0 {
+		g = o.Sim.Grav0
+	}
+	if g <= 0 {
+		g = 10
+	}
+	Ω2 := dat.Ng * g / dat.Rref
+
+	// multiplier
+	var mult fun.Func = &fun.Cte{C: 1}
+	if dat.Mult != "" {
+		mult, err = o.Sim.Functions.Get(dat.Mult)
+		if err != nil {
+			return
+		}
+	}
+
+	// elements
+	extra := ""
+	if dat.Updated {
+		extra = "!updated:1"
+	}
+	tags := make(map[int]bool)
+	for _, tag := range dat.Tags {
+		if _, ok := o.Msh.CellTag2cells[tag]; !ok {
+			return chk.Err("cannot find cells with tag = %d given in centrifuge data", tag)
+		}
+		tags[tag] = true
+	}
+	for _, cell := range o.Msh.Cells {
+		if len(tags) > 0 && !tags[cell.Tag] {
+			continue
+		}
+		e := o.Cid2elem[cell.Id]
+		if e == nil {
+			continue
+		}
+		for i := 0; i < ndim; i++ {
+			acc := &CentrifugeAcc{I: i, Ω2: Ω2, Xa: dat.Axis, Dir: dir, Mult: mult}
+			err = e.SetEleConds(io.Sf("a%c", 'x'+i), acc, extra)
+			if err != nil {
+				return chk.Err("cannot set centrifugal acceleration of element # %d:\n%v", cell.Id, err)
+			}
+		}
+	}
+	return
+}
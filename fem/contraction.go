@@ -0,0 +1,168 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// Contraction implements the volume-loss controlled excavation of tunnels. See inp.ContractionData
+//  Note: the nodes on the tunnel boundary are sorted by their angle around the centre in order to
+//        compute the area enclosed by the boundary. In half models (symmetry), the boundary is
+//        closed by the straight line between the crown and the invert
+type Contraction struct {
+	Dat   *inp.ContractionData // input data
+	R     float64              // radius of tunnel
+	Rc    float64              // radius of contracted cross-section
+	Nodes []*Node              // nodes on tunnel boundary sorted by angle around centre
+	U     [][]float64          // [len(Nodes)][2] prescribed displacements when m = 1
+}
+
+// NewContraction allocates a new Contraction structure and sets the prescribed displacements of
+// nodes on the tunnel boundary
+func NewContraction(d *Domain, dat *inp.ContractionData) (o *Contraction, err error) {
+
+	// check
+	if d.Sim.Ndim != 2 {
+		return nil, chk.Err("contraction of tunnels is only available in 2D")
+	}
+	if len(dat.Centre) != 2 {
+		return nil, chk.Err("centre of tunnel cross-section must have two coordinates. %v is invalid", dat.Centre)
+	}
+	if dat.Vl <= 0 || dat.Vl >= 1 {
+		return nil, chk.Err("volume loss must be in ]0,1[. vl = %g is invalid", dat.Vl)
+	}
+	switch dat.Mode {
+	case "":
+		dat.Mode = "uniform"
+	case "uniform", "invert":
+	default:
+		return nil, chk.Err("displacement pattern %q is not available. options are \"uniform\" and \"invert\"", dat.Mode)
+	}
+
+	// multiplier
+	var mult fun.Func
+	if dat.Mult != "" {
+		mult, err = d.Sim.Functions.Get(dat.Mult)
+		if err != nil {
+			return
+		}
+	}
+
+	// nodes on tunnel boundary
+	o = new(Contraction)
+	o.Dat = dat
+	var α []float64
+	added := make(map[int]bool)
+	for _, tag := range dat.Tags {
+		pairs, ok := d.Msh.FaceTag2cells[tag]
+		if !ok {
+			return nil, chk.Err("cannot find faces with tag = %d to set contraction of tunnel", tag)
+		}
+		for _, pair := range pairs {
+			for _, l := range pair.C.Shp.FaceLocalVerts[pair.Fid] {
+				vid := pair.C.Verts[l]
+				nod := d.Vid2node[vid]
+				if nod == nil || added[vid] {
+					continue
+				}
+				added[vid] = true
+				o.Nodes = append(o.Nodes, nod)
+				α = append(α, math.Atan2(nod.Vert.C[1]-dat.Centre[1], nod.Vert.C[0]-dat.Centre[0]))
+			}
+		}
+	}
+	if len(o.Nodes) < 3 {
+		return nil, chk.Err("at least 3 active nodes are required on tunnel boundary. %d found", len(o.Nodes))
+	}
+	sort.Sort(&contr_nodes{o.Nodes, α})
+
+	// radius
+	o.R = dat.R
+	if o.R <= 0 {
+		for _, nod := range o.Nodes {
+			o.R += contr_dist(nod.Vert.C, dat.Centre)
+		}
+		o.R /= float64(len(o.Nodes))
+	}
+	o.Rc = o.R * math.Sqrt(1.0-dat.Vl)
+
+	// prescribed displacements
+	o.U = make([][]float64, len(o.Nodes))
+	for k, nod := range o.Nodes {
+		x := nod.Vert.C
+		ρ := contr_dist(x, dat.Centre)
+		o.U[k] = make([]float64, 2)
+		for i := 0; i < 2; i++ {
+			if dat.Mode == "uniform" { // u = -(R-R')・(x-c)/|x-c|
+				o.U[k][i] = -(o.R - o.Rc) * (x[i] - dat.Centre[i]) / ρ
+			} else { // u = -(R-R')・ey + (R'/R-1)・(x-c)
+				o.U[k][i] = (o.Rc/o.R - 1.0) * (x[i] - dat.Centre[i])
+				if i == 1 {
+					o.U[k][i] -= o.R - o.Rc
+				}
+			}
+		}
+		for i, key := range []string{"ux", "uy"} {
+			var fcn fun.Func = &fun.Cte{C: o.U[k][i]}
+			if mult != nil {
+				fcn = &fun.Mul{Fa: mult, Fb: fcn}
+			}
+			err = d.EssenBcs.Set(key, []*Node{nod}, fcn, "")
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// VolLoss computes the volume loss corresponding to the current displacements of the nodes on the
+// tunnel boundary; i.e. the relative reduction of the area enclosed by the boundary
+func (o *Contraction) VolLoss(sol *ele.Solution) float64 {
+	var a0, a float64
+	n := len(o.Nodes)
+	for k, nod := range o.Nodes {
+		nxt := o.Nodes[(k+1)%n]
+		x0, y0 := nod.Vert.C[0], nod.Vert.C[1]
+		x1, y1 := nxt.Vert.C[0], nxt.Vert.C[1]
+		a0 += x0*y1 - x1*y0
+		x0 += sol.Y[nod.GetEq("ux")]
+		y0 += sol.Y[nod.GetEq("uy")]
+		x1 += sol.Y[nxt.GetEq("ux")]
+		y1 += sol.Y[nxt.GetEq("uy")]
+		a += x0*y1 - x1*y0
+	}
+	return (a0 - a) / a0
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// contr_dist returns the distance between x and the centre c
+func contr_dist(x, c []float64) float64 {
+	return math.Sqrt((x[0]-c[0])*(x[0]-c[0]) + (x[1]-c[1])*(x[1]-c[1]))
+}
+
+// contr_nodes sorts nodes by angle
+type contr_nodes struct {
+	nodes []*Node
+	α     []float64
+}
+
+func (o *contr_nodes) Len() int { return len(o.nodes) }
+
+func (o *contr_nodes) Swap(i, j int) {
+	o.nodes[i], o.nodes[j] = o.nodes[j], o.nodes[i]
+	o.α[i], o.α[j] = o.α[j], o.α[i]
+}
+
+func (o *contr_nodes) Less(i, j int) bool { return o.α[i] < o.α[j] }
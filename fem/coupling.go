@@ -0,0 +1,260 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// SetCoupling sets the constraints of a coupling between structural nodes (beams or shells) and
+// the vertices of solid faces. The rigid body motion of a structural node b at the solid vertex j
+// is given by
+//
+//      u_j = u_b + θ_b × r_j = P_j・[u_b, θ_b]    with    r_j = x_j - x_b
+//
+//  Distributing coupling: [u_b, θ_b] is the weighted least-squares fit of the solid displacements
+//
+//      [u_b, θ_b] = A⁻¹・Σ_j w_j P_jᵀ・u_j    with    A = Σ_j w_j P_jᵀ・P_j
+//
+//  where w_j = ∫ N_j dΓ are the tributary areas (lengths in 2D) of solid vertices
+//  Kinematic coupling: u_j - P_j・[u_b, θ_b] = 0 for each solid vertex j
+//  Note: the rotations are (rz) in 2D and (rx, ry, rz) in 3D
+func (o *Domain) SetCoupling(dat *inp.CouplingData) (err error) {
+
+	// check
+	if o.Distr {
+		return chk.Err("couplings are not available in parallel runs")
+	}
+	verts, ok := o.Msh.VertTag2verts[dat.Node]
+	if !ok {
+		return chk.Err("cannot find structural nodes with tag = %d to set coupling", dat.Node)
+	}
+	pairs, ok := o.Msh.FaceTag2cells[dat.Face]
+	if !ok {
+		return chk.Err("cannot find solid faces with tag = %d to set coupling", dat.Face)
+	}
+
+	// structural nodes
+	ndim := o.Msh.Ndim
+	ukeys := DispKeys(ndim)
+	rkeys := RotKeys(ndim)
+	var nodes []*Node
+	isnode := make(map[int]bool)
+	for _, v := range verts {
+		nod := o.Vid2node[v.Id]
+		if nod == nil || nod.GetEq("ux") < 0 {
+			continue
+		}
+		nodes = append(nodes, nod)
+		isnode[v.Id] = true
+	}
+	if len(nodes) == 0 {
+		return chk.Err("structural nodes with tag = %d do not have displacements", dat.Node)
+	}
+
+	// tributary areas of solid vertices
+	w := make(map[int]float64)
+	for _, pair := range pairs {
+		c, fid := pair.C, pair.Fid
+		if o.Cid2elem[c.Id] == nil || c.Shp == nil {
+			continue
+		}
+		sh := c.Shp
+		_, ipf, err := sh.GetIps(0, 0)
+		if err != nil {
+			return err
+		}
+		X := o.cell_coords(c, sh.Nverts)
+		for _, ip := range ipf {
+			err = sh.CalcAtFaceIp(X, ip, fid)
+			if err != nil {
+				return err
+			}
+			coef := ip[3] * la.VecNorm(sh.Fnvec)
+			for k, m := range sh.FaceLocalVerts[fid] {
+				vid := c.Verts[m]
+				nod := o.Vid2node[vid]
+				if isnode[vid] || nod == nil || nod.GetEq("ux") < 0 {
+					continue
+				}
+				w[vid] += coef * sh.Sf[k]
+			}
+		}
+	}
+	if len(w) == 0 {
+		return chk.Err("solid faces with tag = %d do not have displacements", dat.Face)
+	}
+
+	// assign solid vertices to the closest structural node
+	groups := make([][]int, len(nodes))
+	svids := make([]int, 0, len(w))
+	for vid, _ := range w {
+		svids = append(svids, vid)
+	}
+	sort.Ints(svids)
+	for _, vid := range svids {
+		best, ib := 0.0, -1
+		for i, nod := range nodes {
+			dist := cpl_dist(o.Msh.Verts[vid].C, nod.Vert.C, ndim)
+			if ib < 0 || dist < best {
+				best, ib = dist, i
+			}
+		}
+		groups[ib] = append(groups[ib], vid)
+	}
+
+	// equations with single-point constraints
+	spc := make(map[int]bool)
+	for _, bc := range o.EssenBcs.Bcs {
+		if len(bc.Eqs) == 1 {
+			spc[bc.Eqs[0]] = true
+		}
+	}
+
+	// constraints
+	for i, nod := range nodes {
+		if len(groups[i]) == 0 {
+			continue
+		}
+
+		// equations of structural node: [u_b, θ_b]
+		beqs := make([]int, 0, ndim+len(rkeys))
+		for _, key := range ukeys {
+			beqs = append(beqs, nod.GetEq(key))
+		}
+		hasrot := true
+		for _, key := range rkeys {
+			if nod.GetEq(key) < 0 {
+				hasrot = false
+			}
+		}
+		if hasrot {
+			for _, key := range rkeys {
+				beqs = append(beqs, nod.GetEq(key))
+			}
+		}
+		nb := len(beqs)
+
+		// matrices P_j
+		P := make([][][]float64, len(groups[i]))
+		for j, vid := range groups[i] {
+			P[j] = cpl_pmat(o.Msh.Verts[vid].C, nod.Vert.C, ndim, nb)
+		}
+
+		// kinematic coupling
+		if dat.Type == "kin" {
+			for j, vid := range groups[i] {
+				snod := o.Vid2node[vid]
+				for a, key := range ukeys {
+					eq := snod.GetEq(key)
+					if eq < 0 || spc[eq] {
+						continue
+					}
+					row := map[int]float64{eq: 1}
+					for r := 0; r < nb; r++ {
+						if P[j][a][r] != 0 {
+							row[beqs[r]] -= P[j][a][r]
+						}
+					}
+					o.cpl_add_mpc(row)
+				}
+			}
+			continue
+		}
+
+		// distributing coupling: A = Σ w P_jᵀ P_j
+		A := la.MatAlloc(nb, nb)
+		for j, vid := range groups[i] {
+			for r := 0; r < nb; r++ {
+				for s := 0; s < nb; s++ {
+					for a := 0; a < ndim; a++ {
+						A[r][s] += w[vid] * P[j][a][r] * P[j][a][s]
+					}
+				}
+			}
+		}
+		Ai := la.MatAlloc(nb, nb)
+		_, err = la.MatInv(Ai, A, 1e-14)
+		if err != nil {
+			return chk.Err("cannot set distributing coupling of structural node # %d: the %d solid vertices do not constrain its rigid body motion:\n%v", nod.Vert.Id, len(groups[i]), err)
+		}
+
+		// constraints: y_b,r - Σ_j Σ_a (A⁻¹ w_j P_jᵀ)_ra u_j,a = 0
+		for r := 0; r < nb; r++ {
+			row := map[int]float64{beqs[r]: 1}
+			for j, vid := range groups[i] {
+				snod := o.Vid2node[vid]
+				for a, key := range ukeys {
+					eq := snod.GetEq(key)
+					if eq < 0 {
+						continue
+					}
+					var c float64
+					for s := 0; s < nb; s++ {
+						c += Ai[r][s] * w[vid] * P[j][a][s]
+					}
+					if math.Abs(c) > 1e-15 {
+						row[eq] -= c
+					}
+				}
+			}
+			o.cpl_add_mpc(row)
+		}
+	}
+	return
+}
+
+// cpl_add_mpc adds the multi-point constraint Σ row[eq]・y_eq = 0
+func (o *Domain) cpl_add_mpc(row map[int]float64) {
+	eqs := make([]int, 0, len(row))
+	for eq, _ := range row {
+		eqs = append(eqs, eq)
+	}
+	sort.Ints(eqs)
+	vals := make([]float64, len(eqs))
+	for j, eq := range eqs {
+		vals[j] = row[eq]
+	}
+	o.EssenBcs.AddMpc("coupling", eqs, vals, &fun.Zero)
+}
+
+// cpl_pmat returns the matrix P [ndim][nb] of the rigid body motion of a structural node at xb
+// evaluated at x; i.e. u = P・[u_b, θ_b]. nb = ndim => displacements only
+func cpl_pmat(x, xb []float64, ndim, nb int) (P [][]float64) {
+	P = la.MatAlloc(ndim, nb)
+	for a := 0; a < ndim; a++ {
+		P[a][a] = 1
+	}
+	if nb == ndim {
+		return
+	}
+	r := make([]float64, 3)
+	for a := 0; a < ndim; a++ {
+		r[a] = x[a] - xb[a]
+	}
+	if ndim == 2 { // θz × r = θz (-ry, rx)
+		P[0][2], P[1][2] = -r[1], r[0]
+		return
+	}
+	P[1][3], P[2][3] = -r[2], r[1] // θx
+	P[0][4], P[2][4] = r[2], -r[0] // θy
+	P[0][5], P[1][5] = -r[1], r[0] // θz
+	return
+}
+
+// cpl_dist returns the distance between points a and b
+func cpl_dist(a, b []float64, ndim int) float64 {
+	var sum float64
+	for i := 0; i < ndim; i++ {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// CplxSystem holds a complex linear system A・x = b with A = Ar + i Ai; e.g. the dynamic stiffness
+// (K - ω² M + i ω C)・u = f of frequency-domain (harmonic) analyses or the complex moduli of
+// viscoelastic correspondence analyses
+//  Note: (1) the system is solved either by the complex solve path of the linear solver (complex
+//            triplet) or, if "cplxblock" is set in "linsol", as the real 2x2 block equivalent
+//                [ Ar  Ai ] [  xr ]   [ br ]
+//                [ Ai -Ar ] [ -xi ] = [ bi ]
+//            which is symmetric if Ar and Ai are symmetric; e.g. for solvers without complex support
+//        (2) entries are added with Put (repeated entries are summed) between Start and Fact
+type CplxSystem struct {
+	N      int             // size of system
+	Block  bool            // solve the real 2x2 block equivalent system
+	A      *la.TripletC    // complex matrix (if !Block)
+	B      *la.Triplet     // real block matrix (if Block)
+	Sol    la.LinSol       // linear solver
+	dat    *inp.LinSolData // linear solver data
+	init   bool            // solver must be initialised
+	xb, bb []float64       // [2*N] block solution and right-hand side (if Block)
+}
+
+// NewCplxSystem allocates a new complex system
+//  n   -- size of system
+//  nnz -- max number of non-zero entries of A (repeated entries included)
+//  dat -- linear solver data
+func NewCplxSystem(n, nnz int, dat *inp.LinSolData) (o *CplxSystem, err error) {
+	if dat.Mixed {
+		return nil, chk.Err("mixed-precision solver is not available for complex systems")
+	}
+	o = &CplxSystem{N: n, Block: dat.CplxBlock, dat: dat, init: true}
+	o.Sol = la.GetSolver(dat.Name)
+	if o.Block {
+		o.B = new(la.Triplet)
+		o.B.Init(2*n, 2*n, 4*nnz)
+		o.xb = make([]float64, 2*n)
+		o.bb = make([]float64, 2*n)
+		return
+	}
+	o.A = new(la.TripletC)
+	o.A.Init(n, n, nnz, dat.Name == "mumps") // MUMPS requires monolithic (real,imag) pairs
+	return
+}
+
+// Start (re)starts the assembly of A
+func (o *CplxSystem) Start() {
+	if o.Block {
+		o.B.Start()
+		return
+	}
+	o.A.Start()
+}
+
+// Put adds Aij = re + i im
+func (o *CplxSystem) Put(i, j int, re, im float64) {
+	if o.Block {
+		n := o.N
+		o.B.Put(i, j, re)
+		o.B.Put(i, n+j, im)
+		o.B.Put(n+i, j, im)
+		o.B.Put(n+i, n+j, -re)
+		return
+	}
+	o.A.Put(i, j, re, im)
+}
+
+// Fact initialises the linear solver (first call only) and factorises A
+func (o *CplxSystem) Fact() (err error) {
+	if o.init {
+		if o.Block {
+			err = o.Sol.InitR(o.B, o.dat.Symmetric, o.dat.Verbose, o.dat.Timing)
+		} else {
+			err = o.Sol.InitC(o.A, o.dat.Symmetric, o.dat.Verbose, o.dat.Timing)
+		}
+		if err != nil {
+			return chk.Err("cannot initialise linear solver for complex system:\n%v", err)
+		}
+		o.Sol.SetOrdScal(o.dat.Ordering, o.dat.Scaling)
+		o.init = false
+	}
+	err = o.Sol.Fact()
+	if err != nil {
+		return chk.Err("factorisation of complex system failed:\n%v", err)
+	}
+	return
+}
+
+// Solve solves A・x = b with x = xR + i xC and b = bR + i bC
+//  Note: A must have been factorised (Fact)
+func (o *CplxSystem) Solve(xR, xC, bR, bC []float64) (err error) {
+	if len(xR) != o.N || len(xC) != o.N || len(bR) != o.N || len(bC) != o.N {
+		return chk.Err("vectors of complex system must have size equal to %d", o.N)
+	}
+	if !o.Block {
+		return o.Sol.SolveC(xR, xC, bR, bC, false)
+	}
+	n := o.N
+	copy(o.bb[:n], bR)
+	copy(o.bb[n:], bC)
+	err = o.Sol.SolveR(o.xb, o.bb, false)
+	if err != nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		xR[i] = o.xb[i]
+		xC[i] = -o.xb[n+i]
+	}
+	return
+}
+
+// Clean cleans up the linear solver
+func (o *CplxSystem) Clean() {
+	if !o.init {
+		o.Sol.Clean()
+		o.init = true
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"math"
+	"sort"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Cracking implements the propagation of discrete cracks (2D) by splitting the mesh along the
+// edges between solids and inserting interface (cohesive) elements; e.g. with the "interface-mc"
+// model with tensile strength ft
+//  Note: (1) after each converged time step, the edges where the traction reaches the strength are
+//            split (at most Nmax per step); the vertices are duplicated where the cracks separate
+//            the solids around them; thus, cracks propagate without being guided by the mesh
+//        (2) the nodes, equations and elements are then re-allocated by calling SetStage; the
+//            solution at new nodes is copied from the nodes they were duplicated from and the
+//            internal variables of existent elements are kept. The new interface elements start
+//            with zero tractions; thus, the tractions on the split edges are released in the
+//            next time step
+//        (3) the split mesh is saved in dirout/fnkey_crk.msh
+//        (4) only serial runs with the implicit solver are supported; cracking cannot be combined
+//            with erosion, relaxation, batches, the staggered solution or with boundary conditions
+//            depending on initial values. Water balance, steady-state detection and cycle jumping
+//            are restarted after each split because they depend on equation numbers
+type Cracking struct {
+	Dat   *inp.CrackData     // input data
+	Stage int                // index of stage
+	Tags  map[int]bool       // tags of solids that can be split; empty => all solids
+	Edges []*inp.CrackedEdge // edges already split
+}
+
+// NewCracking allocates a new Cracking structure for the solids of domain
+func NewCracking(d *Domain, stgidx int, dat *inp.CrackData) (o *Cracking, err error) {
+	if d.Distr {
+		return nil, chk.Err("propagation of cracks is not available in parallel runs")
+	}
+	if d.Sim.Ndim != 2 {
+		return nil, chk.Err("propagation of cracks is only available in 2D")
+	}
+	if d.Eros != nil || d.Relax != nil || d.Batches != nil || d.Split != nil {
+		return nil, chk.Err("propagation of cracks cannot be combined with erosion, relaxation, batches or staggered solution")
+	}
+	if len(d.EssenBcs.EqsIni) > 0 {
+		return nil, chk.Err("propagation of cracks cannot be combined with boundary conditions depending on initial values")
+	}
+	if dat.Ft <= 0 || dat.Ss < 0 {
+		return nil, chk.Err("tensile strength ft=%g must be positive and shear strength ss=%g must be non-negative", dat.Ft, dat.Ss)
+	}
+	edat := d.Reg.Etag2data(dat.Itag)
+	if edat == nil || edat.Type != "interface" {
+		return nil, chk.Err("tag of interface cells itag=%d must be given in \"elemsdata\" with type \"interface\"", dat.Itag)
+	}
+	o = new(Cracking)
+	o.Dat = dat
+	o.Stage = stgidx
+	o.Tags = make(map[int]bool)
+	for _, tag := range dat.Tags {
+		o.Tags[tag] = true
+	}
+	return
+}
+
+// Step splits the edges where the traction reaches the strength after a time step has converged
+// and re-allocates the nodes, equations and elements of domain
+func (o *Cracking) Step(d *Domain) (err error) {
+
+	// candidate edges
+	cands := o.candidates(d)
+	if len(cands) == 0 {
+		return
+	}
+
+	// old nodes, solution and internal variables
+	oldVid2node := d.Vid2node
+	oldSol := d.Sol
+	ivs := make(map[int]*bytes.Buffer)
+	for _, e := range d.Elems {
+		var buf bytes.Buffer
+		err = e.Encode(utl.GetEncoder(&buf, d.Sim.EncType))
+		if err != nil {
+			return chk.Err("cannot encode internal variables of element (eid=%d):\n%v", e.Id(), err)
+		}
+		ivs[e.Id()] = &buf
+	}
+
+	// split edges
+	src := make(map[int]int) // new vertex => vertex in old mesh
+	nsplit := 0
+	for k, c := range cands {
+		if k == o.Dat.Nmax {
+			break
+		}
+		if ea, _ := d.Msh.SharedEdge(d.Msh.Cells[c.a], d.Msh.Cells[c.b]); ea < 0 {
+			continue // separated by a previous split
+		}
+		newverts, err := d.Msh.SplitEdge(c.a, c.b, o.Dat.Itag, &o.Edges)
+		if err != nil {
+			return chk.Err("cannot split edge between cells %d and %d:\n%v", c.a, c.b, err)
+		}
+		for nv, v := range newverts {
+			if s, ok := src[v]; ok {
+				v = s
+			}
+			src[nv] = v
+		}
+		nsplit++
+	}
+
+	// re-allocate nodes, equations and elements
+	err = d.SetStage(o.Stage)
+	if err != nil {
+		return chk.Err("cannot set stage after splitting edges:\n%v", err)
+	}
+	d.Crack = o
+	d.bkpSol = nil
+
+	// solution at nodes
+	d.Sol.T, d.Sol.Dt = oldSol.T, oldSol.Dt
+	for _, nod := range d.Nodes {
+		vid := nod.Vert.Id
+		if v, ok := src[vid]; ok {
+			vid = v
+		}
+		old := oldVid2node[vid]
+		if old == nil {
+			continue
+		}
+		for _, dof := range nod.Dofs {
+			eq := old.GetEq(dof.Key)
+			if eq < 0 {
+				continue
+			}
+			d.Sol.Y[dof.Eq] = oldSol.Y[eq]
+			if !d.Sim.Data.Steady {
+				d.Sol.Dydt[dof.Eq] = oldSol.Dydt[eq]
+				d.Sol.D2ydt2[dof.Eq] = oldSol.D2ydt2[eq]
+				d.Sol.Psi[dof.Eq] = oldSol.Psi[eq]
+				d.Sol.Zet[dof.Eq] = oldSol.Zet[eq]
+				d.Sol.Chi[dof.Eq] = oldSol.Chi[eq]
+			}
+		}
+	}
+
+	// internal variables
+	for _, e := range d.ElemIntvars {
+		err = e.SetIniIvs(d.Sol, nil)
+		if err != nil {
+			return chk.Err("cannot initialise internal variables after splitting edges:\n%v", err)
+		}
+	}
+	for _, e := range d.Elems {
+		buf, ok := ivs[e.Id()]
+		if !ok {
+			continue // new interface element
+		}
+		err = e.Decode(utl.GetDecoder(buf, d.Sim.EncType))
+		if err != nil {
+			return chk.Err("cannot decode internal variables of element (eid=%d):\n%v", e.Id(), err)
+		}
+	}
+
+	// save mesh
+	d.Msh.WriteMsh(d.Sim.DirOut, d.Sim.Key+"_crk.msh")
+	if d.ShowMsg {
+		io.Pf("\n>> %d edges split at t = %g; total = %d\n", nsplit, d.Sol.T, len(o.Edges))
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// crack_cand holds an edge that can be split
+type crack_cand struct {
+	a, b int     // ids of solids sharing edge
+	r    float64 // ratio between traction and strength
+}
+
+// crack_cands implements sort.Interface to sort candidates by decreasing ratio
+type crack_cands []*crack_cand
+
+func (o crack_cands) Len() int           { return len(o) }
+func (o crack_cands) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o crack_cands) Less(i, j int) bool { return o[i].r > o[j].r }
+
+// candidates returns the edges where the traction reaches the strength sorted by decreasing ratio
+// between traction and strength
+func (o *Cracking) candidates(d *Domain) (cands crack_cands) {
+
+	// solids that can be split and their mean stresses
+	icells := make(map[int]bool)
+	for _, e := range o.Edges {
+		icells[e.Icell] = true
+	}
+	σ := make(map[int][]float64)
+	for _, e := range d.Elems {
+		c := d.Msh.Cells[e.Id()]
+		if !c.IsSolid || icells[c.Id] || c.Tag == o.Dat.Itag || (len(o.Tags) > 0 && !o.Tags[c.Tag]) {
+			continue
+		}
+		out, ok := e.(ele.CanOutputIps)
+		if !ok {
+			continue
+		}
+		M := ele.NewIpsMap()
+		out.OutIpVals(M, d.Sol)
+		if _, ok = (*M)["sx"]; !ok {
+			continue
+		}
+		σ[c.Id] = make([]float64, 3) // {σxx, σyy, σxy}; note that "sxy" is the Mandel component √2・σxy
+		for i, key := range []string{"sx", "sy", "sxy"} {
+			for _, val := range (*M)[key] {
+				σ[c.Id][i] += val / float64(len((*M)[key]))
+			}
+		}
+		σ[c.Id][2] /= math.Sqrt2
+	}
+
+	// cracked pairs of solids
+	pairs := make(map[[2]int]bool)
+	for _, e := range o.Edges {
+		pairs[[2]int{e.A, e.B}], pairs[[2]int{e.B, e.A}] = true, true
+	}
+
+	// edges shared by two solids
+	for _, ca := range d.Msh.Cells {
+		if _, ok := σ[ca.Id]; !ok {
+			continue
+		}
+		for _, l := range ca.Shp.FaceLocalVerts {
+			if len(l) != 2 {
+				break
+			}
+			v0, v1 := d.Msh.Verts[ca.Verts[l[0]]], d.Msh.Verts[ca.Verts[l[1]]]
+			for _, cid := range v0.SharedBy {
+				if cid <= ca.Id || pairs[[2]int{ca.Id, cid}] {
+					continue
+				}
+				if _, ok := σ[cid]; !ok || utl.IntIndexSmall(v1.SharedBy, cid) < 0 {
+					continue
+				}
+				if ea, _ := d.Msh.SharedEdge(ca, d.Msh.Cells[cid]); ea < 0 {
+					continue
+				}
+
+				// tractions on edge with mean stresses of both solids
+				s := make([]float64, 3)
+				for i := 0; i < 3; i++ {
+					s[i] = (σ[ca.Id][i] + σ[cid][i]) / 2.0
+				}
+				tx, ty := v1.C[0]-v0.C[0], v1.C[1]-v0.C[1]
+				L := math.Sqrt(tx*tx + ty*ty)
+				tx, ty = tx/L, ty/L
+				nx, ny := -ty, tx
+				tn := nx*nx*s[0] + ny*ny*s[1] + 2.0*nx*ny*s[2]
+				ts := tx*nx*s[0] + ty*ny*s[1] + (tx*ny+ty*nx)*s[2]
+				r := tn / o.Dat.Ft
+				if o.Dat.Ss > 0 {
+					r = math.Max(r, math.Abs(ts)/o.Dat.Ss)
+				}
+				if r >= 1 {
+					cands = append(cands, &crack_cand{ca.Id, cid, r})
+				}
+			}
+		}
+	}
+	sort.Stable(cands)
+	return
+}
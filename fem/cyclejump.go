@@ -0,0 +1,153 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// CycleJump implements the cycle-jump acceleration of quasi-static cyclic loading; e.g. storms
+// acting on offshore foundations. At the end of each cycle computed explicitly, the state
+// (Y, λ and internal variables of elements) is recorded; after Nexpl cycles, the state is
+// extrapolated over ΔN skipped cycles with the rate of change of the last computed cycle:
+//
+//      X(N + ΔN) = X(N) + ΔN・(X(N) - X(N-1))
+//
+//  and the time is advanced by ΔN・Period; thus, the time remains equal to the number of cycles
+//  times the period
+//  Note: (1) only elements implementing ele.WithCycleJump are extrapolated; the internal variables
+//            of other elements are kept
+//        (2) the extrapolated state is not in equilibrium; it is corrected by the first cycle
+//            computed after the jump
+//        (3) only serial runs with the implicit solver ("imp") are supported
+type CycleJump struct {
+	Dat   *inp.CycleJumpData  // input data
+	Elems []ele.WithCycleJump // elements with internal variables that can be extrapolated
+	Jumps []int               // number of cycles skipped in each jump
+	Ncomp int                 // number of cycles computed explicitly
+	tnext float64             // time at the end of the current cycle
+	nexpl int                 // number of cycles computed explicitly since the last jump
+	yold  []float64           // [ny+nlam] Y and λ at the end of the previous cycle; nil => not available
+	xold  [][]float64         // [nelems] internal variables at the end of the previous cycle
+}
+
+// NewCycleJump allocates a new CycleJump structure for the elements of domain
+func NewCycleJump(d *Domain, dat *inp.CycleJumpData) (o *CycleJump, err error) {
+	if d.Distr {
+		return nil, chk.Err("cycle jumping is not available in parallel runs")
+	}
+	if d.Sim.Solver.Type != "imp" {
+		return nil, chk.Err("cycle jumping requires the implicit solver (\"imp\"). %q is invalid", d.Sim.Solver.Type)
+	}
+	o = &CycleJump{Dat: dat, tnext: dat.Period}
+	for _, e := range d.ElemIntvars {
+		if ej, ok := e.(ele.WithCycleJump); ok {
+			o.Elems = append(o.Elems, ej)
+		}
+	}
+	if len(o.Elems) == 0 {
+		return nil, chk.Err("there are no elements with internal variables that can be extrapolated by cycle jumping")
+	}
+	return
+}
+
+// Step records the state at the end of each cycle and performs the jump over skipped cycles if
+// possible. It must be called after a time step has converged. tf is the final time of stage
+//  Output:
+//   Δtj -- time increment corresponding to the skipped cycles; zero if no jump was performed
+func (o *CycleJump) Step(d *Domain, tf float64, verbose bool) (Δtj float64, err error) {
+
+	// end of cycle?
+	P := o.Dat.Period
+	if d.Sol.T < o.tnext-1e-10*P {
+		return
+	}
+	o.tnext += P
+	o.Ncomp++
+	o.nexpl++
+
+	// state at the end of cycle
+	y := make([]float64, d.Ny+d.Nlam)
+	copy(y, d.Sol.Y)
+	copy(y[d.Ny:], d.Sol.L)
+	x := make([][]float64, len(o.Elems))
+	for i, e := range o.Elems {
+		x[i] = e.GetIvsVec()
+	}
+	yold, xold := o.yold, o.xold
+	o.yold, o.xold = y, x
+	if o.nexpl < o.Dat.Nexpl || yold == nil {
+		return
+	}
+
+	// number of cycles to skip
+	ΔN := o.Dat.Njump
+	nleft := int(math.Floor((tf-d.Sol.T)/P+1e-10)) - o.Dat.Nexpl
+	if nleft < ΔN {
+		ΔN = nleft
+	}
+	ΔN = cycle_jump_limit(ΔN, o.Dat.Tol, [][]float64{y}, [][]float64{yold})
+	ΔN = cycle_jump_limit(ΔN, o.Dat.Tol, x, xold)
+	if ΔN < 1 {
+		return
+	}
+
+	// extrapolate state
+	n := float64(ΔN)
+	for i := 0; i < d.Ny; i++ {
+		d.Sol.Y[i] = y[i] + n*(y[i]-yold[i])
+	}
+	for i := 0; i < d.Nlam; i++ {
+		d.Sol.L[i] = y[d.Ny+i] + n*(y[d.Ny+i]-yold[d.Ny+i])
+	}
+	la.VecFill(d.Sol.ΔY, 0)
+	for i, e := range o.Elems {
+		for j := 0; j < len(x[i]); j++ {
+			x[i][j] += n * (x[i][j] - xold[i][j])
+		}
+		err = e.SetIvsVec(x[i])
+		if err != nil {
+			return
+		}
+	}
+
+	// advance time
+	Δtj = n * P
+	d.Sol.T += Δtj
+	o.tnext += Δtj
+	o.Jumps = append(o.Jumps, ΔN)
+	o.nexpl = 0
+	o.yold, o.xold = nil, nil
+	if verbose {
+		io.Pfcyan("\n> Cycle jump: %d cycles skipped; t = %g\n", ΔN, d.Sol.T)
+	}
+	return
+}
+
+// cycle_jump_limit limits the number of cycles to skip (ΔN) such that the relative change of the
+// state variables x (with values xold at the end of the previous cycle) is smaller than tol
+func cycle_jump_limit(ΔN int, tol float64, x, xold [][]float64) int {
+	var nx, nd float64
+	for i := 0; i < len(x); i++ {
+		for j := 0; j < len(x[i]); j++ {
+			nx += x[i][j] * x[i][j]
+			nd += (x[i][j] - xold[i][j]) * (x[i][j] - xold[i][j])
+		}
+	}
+	if nd < 1e-30 {
+		return ΔN
+	}
+	nmax := int(math.Floor(tol * math.Sqrt(nx) / math.Sqrt(nd)))
+	if nmax < ΔN {
+		return nmax
+	}
+	return ΔN
+}
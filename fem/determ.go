@@ -0,0 +1,81 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/mpi"
+)
+
+// all_reduce_sum computes v := Σ_proc v_proc over all processors using w as workspace
+//  Note: (1) the order of the sums carried out by MPI_Allreduce depends on the implementation
+//            (and the network), which may cause bit differences in results of different runs
+//        (2) in the deterministic mode (see inp.SolverData.Determ), the nonzero entries of each
+//            processor are gathered in the order of ranks and then summed in this order by all
+//            processors. The gathering is exact because each slot receives the contribution of
+//            one processor only (all others add zeros). The overhead corresponds to a second
+//            reduction of size 2 × (number of nonzero entries of all processors)
+//        (3) the Jacobian Kb is assembled in the order of cell ids in each processor; thus, its
+//            entries are also given to the linear solver in the same order in all runs
+func all_reduce_sum(determ bool, v, w []float64) {
+	if !determ {
+		mpi.AllReduceSum(v, w)
+		return
+	}
+
+	// number of nonzero entries in each processor
+	nproc, rank := mpi.Size(), mpi.Rank()
+	cnt := make([]float64, nproc)
+	cnt[rank] = float64(determ_nnz(v))
+	mpi.AllReduceSum(cnt, make([]float64, nproc))
+
+	// gather pairs (index, value) of all processors in the order of ranks
+	buf := determ_pack(v, cnt, rank)
+	mpi.AllReduceSum(buf, make([]float64, len(buf)))
+
+	// sum in the order of ranks
+	determ_unpack(v, buf)
+}
+
+// determ_nnz returns the number of nonzero entries of v
+func determ_nnz(v []float64) (nnz int) {
+	for _, x := range v {
+		if x != 0 {
+			nnz++
+		}
+	}
+	return
+}
+
+// determ_pack returns a buffer with the pairs (index, value) of the nonzero entries of v placed in
+// the slot of processor rank; all other slots are zero
+//  cnt -- [nproc] number of nonzero entries in each processor
+func determ_pack(v, cnt []float64, rank int) (buf []float64) {
+	var start, total int
+	for p := 0; p < len(cnt); p++ {
+		if p < rank {
+			start += int(cnt[p])
+		}
+		total += int(cnt[p])
+	}
+	buf = make([]float64, 2*total)
+	k := 2 * start
+	for i, x := range v {
+		if x != 0 {
+			buf[k] = float64(i)
+			buf[k+1] = x
+			k += 2
+		}
+	}
+	return
+}
+
+// determ_unpack sets v with the sum of the pairs (index, value) in buf in the order of ranks
+func determ_unpack(v, buf []float64) {
+	la.VecFill(v, 0)
+	for k := 0; k < len(buf); k += 2 {
+		v[int(buf[k])] += buf[k+1]
+	}
+}
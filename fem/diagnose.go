@@ -0,0 +1,321 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// KbPivot holds information about a suspicious pivot (diagonal term) of the Jacobian matrix
+type KbPivot struct {
+	Eq  int       // equation number
+	Key string    // dof key; e.g. "ux", "pl"
+	Vid int       // vertex id
+	X   []float64 // coordinates of vertex
+	Val float64   // diagonal term K_ii
+}
+
+// KbDiagnosis holds the results of diagnosing the Jacobian matrix (Kb)
+//  Note: (1) the extreme singular values of Kb are estimated with a few Lanczos iterations (with
+//            full reorthogonalisation) applied to trans(Kb)・Kb. Ritz values lie within the
+//            spectrum; thus Cond is a lower bound of the condition number (exact if the number
+//            of iterations reaches the number of equations). Because of the squared spectrum,
+//            condition numbers larger than about 3e7 are reported as +Inf
+//        (2) the diagonal terms of the (y,y) block of Kb are extracted by products with probing
+//            vectors; nodes that do not share cells (or constraints) are probed together
+//        (3) only serial runs are supported
+type KbDiagnosis struct {
+	Nit     int        // number of Lanczos iterations performed
+	SigMin  float64    // estimate of smallest singular value of Kb
+	SigMax  float64    // estimate of largest singular value of Kb
+	Cond    float64    // estimate of condition number: SigMax / SigMin. +Inf => singular
+	Zero    []*KbPivot // zero pivots: |K_ii| ≤ ϵ・max|K_jj|
+	Neg     []*KbPivot // negative pivots
+	Invalid []*KbPivot // NaN or Inf pivots
+	Causes  []string   // likely causes of problems
+}
+
+// DiagnoseKb diagnoses the Jacobian matrix of domain; i.e. Kb must have been assembled already
+// (including the terms of essential boundary conditions)
+func DiagnoseKb(d *Domain) (o *KbDiagnosis, err error) {
+
+	// check
+	if d.Distr {
+		return nil, chk.Err("diagnostics of Kb are not available in parallel runs")
+	}
+	dat := d.Sim.Solver
+	Km := d.Kb.ToMatrix(nil)
+
+	// dofs
+	eq2node := make([]*Node, d.Ny)
+	eq2key := make([]string, d.Ny)
+	for _, nod := range d.Nodes {
+		for _, dof := range nod.Dofs {
+			eq2node[dof.Eq] = nod
+			eq2key[dof.Eq] = dof.Key
+		}
+	}
+
+	// condition number
+	o = new(KbDiagnosis)
+	err = o.lanczos(Km, d.Nyb, dat.DiagNit)
+	if err != nil {
+		return
+	}
+
+	// pivots
+	diag := diagnose_diagonal(d, Km)
+	var dmax float64
+	for _, v := range diag {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			dmax = math.Max(dmax, math.Abs(v))
+		}
+	}
+	for eq, v := range diag {
+		if _, ok := d.EssenBcs.ElimEq[eq]; ok {
+			continue
+		}
+		p := &KbPivot{Eq: eq, Key: eq2key[eq], Vid: -1, Val: v}
+		if nod := eq2node[eq]; nod != nil {
+			p.Vid = nod.Vert.Id
+			p.X = nod.Vert.C
+		}
+		switch {
+		case math.IsNaN(v) || math.IsInf(v, 0):
+			o.Invalid = append(o.Invalid, p)
+		case math.Abs(v) <= dat.Eps*dmax || dmax == 0:
+			o.Zero = append(o.Zero, p)
+		case v < 0:
+			o.Neg = append(o.Neg, p)
+		}
+	}
+
+	// causes
+	if len(o.Invalid) > 0 {
+		o.Causes = append(o.Causes, io.Sf("%d diagonal terms are NaN or Inf: check material parameters (e.g. zero or negative moduli) and distorted cells (e.g. negative Jacobians) around vertex %d", len(o.Invalid), o.Invalid[0].Vid))
+	}
+	if len(o.Zero) > 0 {
+		o.Causes = append(o.Causes, io.Sf("%d dofs have zero stiffness: dofs not connected to active cells, zero material parameters (e.g. E, k) of cells around vertex %d %v or missing constraints of free rotations/ends", len(o.Zero), o.Zero[0].Vid, d.diagnose_mats(o.Zero[0].Vid)))
+	}
+	if len(o.Neg) > 0 {
+		o.Causes = append(o.Causes, io.Sf("%d dofs have negative stiffness: negative material parameters or softening of cells around vertex %d %v", len(o.Neg), o.Neg[0].Vid, d.diagnose_mats(o.Neg[0].Vid)))
+	}
+	if o.Cond > dat.DiagCmax {
+		o.Causes = append(o.Causes, io.Sf("Kb is nearly singular (cond ≈ %g > %g)", o.Cond, dat.DiagCmax))
+		constrained := make(map[string]bool)
+		for _, bc := range d.EssenBcs.Bcs {
+			for _, eq := range bc.Eqs {
+				if eq < d.Ny {
+					constrained[eq2key[eq]] = true
+				}
+			}
+		}
+		var free []string
+		for _, key := range eq2key {
+			if !constrained[key] {
+				constrained[key] = true
+				free = append(free, key)
+			}
+		}
+		sort.Strings(free)
+		for _, key := range free {
+			o.Causes = append(o.Causes, io.Sf("no essential boundary conditions on %q: rigid body modes (or the level of %q) are not prevented", key, key))
+		}
+		if len(free) == 0 {
+			o.Causes = append(o.Causes, "essential boundary conditions may not prevent all rigid body modes (translations and rotations) of all bodies")
+		}
+		if d.EssenBcs.Strategy == "penalty" {
+			o.Causes = append(o.Causes, io.Sf("the penalty coefficient (%g) increases the condition number", d.EssenBcs.Penalty))
+		}
+	}
+	return
+}
+
+// String returns a report of the diagnosis
+func (o *KbDiagnosis) String() string {
+	var b bytes.Buffer
+	io.Ff(&b, "diagnosis of Kb (%d Lanczos iterations)\n", o.Nit)
+	io.Ff(&b, "  σmin ≈ %g, σmax ≈ %g, cond ≈ %g\n", o.SigMin, o.SigMax, o.Cond)
+	pivots := func(title string, P []*KbPivot) {
+		if len(P) == 0 {
+			return
+		}
+		io.Ff(&b, "  %s pivots:\n", title)
+		io.Ff(&b, "%8s%6s%8s%23s  %s\n", "eq", "key", "vid", "K_ii", "x")
+		for _, p := range P {
+			io.Ff(&b, "%8d%6s%8d%23.15e  %v\n", p.Eq, p.Key, p.Vid, p.Val, p.X)
+		}
+	}
+	pivots("invalid", o.Invalid)
+	pivots("zero", o.Zero)
+	pivots("negative", o.Neg)
+	if len(o.Causes) > 0 {
+		io.Ff(&b, "  likely causes:\n")
+		for _, c := range o.Causes {
+			io.Ff(&b, "   - %s\n", c)
+		}
+	}
+	return b.String()
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// diagnose_kb diagnoses Kb if requested (serial runs only). The report is printed if err == nil or
+// appended to err (e.g. failed factorisation) otherwise
+func diagnose_kb(d *Domain, err error) error {
+	if !d.Sim.Solver.Diagnose || d.Distr {
+		return err
+	}
+	o, e := DiagnoseKb(d)
+	if e != nil {
+		io.Pfred("cannot diagnose Kb:\n%v\n", e)
+		return err
+	}
+	if err != nil {
+		return chk.Err("%v\n%s", err, o.String())
+	}
+	io.Pf("%s", o.String())
+	return nil
+}
+
+// lanczos estimates the extreme singular values of K with nit iterations of the Lanczos method
+// applied to trans(K)・K
+func (o *KbDiagnosis) lanczos(K *la.CCMatrix, n, nit int) (err error) {
+
+	// initial vector
+	if n < 1 {
+		return chk.Err("Kb is empty")
+	}
+	if nit > n {
+		nit = n
+	}
+	v := make([]float64, n)
+	for i := 0; i < n; i++ {
+		v[i] = 1.0 + float64(i%7)/7.0
+	}
+	la.VecScale(v, 0, 1.0/la.VecNorm(v), v)
+
+	// iterations
+	var α, β []float64
+	var V [][]float64
+	Kv := make([]float64, n)
+	for k := 0; k < nit; k++ {
+		V = append(V, v)
+		w := make([]float64, n)
+		la.SpMatVecMul(Kv, 1, K, v)
+		la.SpMatTrVecMul(w, 1, K, Kv) // w = trans(K)・K・v
+		a := la.VecDot(w, v)
+		if math.IsNaN(a) || math.IsInf(a, 0) {
+			o.Nit, o.SigMin, o.SigMax, o.Cond = k, math.NaN(), math.NaN(), math.NaN()
+			return
+		}
+		α = append(α, a)
+		for _, u := range V { // full reorthogonalisation
+			c := la.VecDot(w, u)
+			for i := 0; i < n; i++ {
+				w[i] -= c * u[i]
+			}
+		}
+		bk := la.VecNorm(w)
+		if bk <= 1e-14*math.Abs(a) || k == nit-1 {
+			break
+		}
+		β = append(β, bk)
+		v = w
+		la.VecScale(v, 0, 1.0/bk, v)
+	}
+
+	// eigenvalues of tridiagonal matrix
+	m := len(α)
+	T := la.MatAlloc(m, m)
+	for i := 0; i < m; i++ {
+		T[i][i] = α[i]
+		if i < m-1 {
+			T[i][i+1], T[i+1][i] = β[i], β[i]
+		}
+	}
+	Q := la.MatAlloc(m, m)
+	θ := make([]float64, m)
+	err = la.Jacobi(Q, θ, T)
+	if err != nil {
+		return chk.Err("eigenvalues of Lanczos' tridiagonal matrix failed:\n%v", err)
+	}
+	θmin, θmax := θ[0], θ[0]
+	for _, t := range θ {
+		θmin, θmax = math.Min(θmin, t), math.Max(θmax, t)
+	}
+	o.Nit = m
+	o.SigMin = math.Sqrt(math.Max(θmin, 0))
+	o.SigMax = math.Sqrt(math.Max(θmax, 0))
+	o.Cond = math.Inf(1)
+	if θmin > 1e-15*θmax {
+		o.Cond = o.SigMax / o.SigMin
+	}
+	return
+}
+
+// diagnose_diagonal extracts the diagonal of the (y,y) block of Kb by products with probing vectors.
+// Nodes are coloured such that nodes of the same colour do not share cells or constraints
+func diagnose_diagonal(d *Domain, K *la.CCMatrix) (diag []float64) {
+
+	// colouring of nodes
+	colours := graph_colouring(d.nodes_graph(false, d.EssenBcs.Bcs), false)
+
+	// probing
+	diag = make([]float64, d.Ny)
+	e := make([]float64, d.Nyb)
+	Ke := make([]float64, d.Nyb)
+	for _, nodes := range colours {
+		for k := 0; ; k++ {
+			var eqs []int
+			for _, i := range nodes {
+				if nod := d.Nodes[i]; k < len(nod.Dofs) {
+					eqs = append(eqs, nod.Dofs[k].Eq)
+				}
+			}
+			if len(eqs) == 0 {
+				break
+			}
+			for _, eq := range eqs {
+				e[eq] = 1
+			}
+			la.SpMatVecMul(Ke, 1, K, e) // Ke = K・e
+			for _, eq := range eqs {
+				diag[eq] = Ke[eq]
+				e[eq] = 0
+			}
+		}
+	}
+	return
+}
+
+// diagnose_mats returns the names of materials of active cells around vertex
+func (o *Domain) diagnose_mats(vid int) (mats []string) {
+	if vid < 0 {
+		return
+	}
+	found := make(map[string]bool)
+	for _, c := range o.Msh.Cells {
+		if o.Cid2elem[c.Id] == nil {
+			continue
+		}
+		for _, v := range c.Verts {
+			if v != vid {
+				continue
+			}
+			if edat := o.Reg.Etag2data(c.Tag); edat != nil && !found[edat.Mat] {
+				found[edat.Mat] = true
+				mats = append(mats, edat.Mat)
+			}
+		}
+	}
+	return
+}
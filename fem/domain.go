@@ -6,6 +6,7 @@ package fem
 
 import (
 	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/solid"
 	"github.com/cpmech/gofem/inp"
 
 	"github.com/cpmech/gosl/chk"
@@ -27,7 +28,7 @@ type Domain struct {
 	Sim     *inp.Simulation // [from FEM] input data
 	Reg     *inp.Region     // region data
 	Msh     *inp.Mesh       // mesh data
-	LinSol  la.LinSol       // linear solver
+	LinSol  *LinSolver      // linear solver
 	DynCfs  *ele.DynCoefs   // [from FEM] coefficients for dynamics/transient simulations
 
 	// stage: nodes (active) and elements (active AND in this processor)
@@ -54,9 +55,65 @@ type Domain struct {
 	ElemFixedKM   []ele.WithFixedKM    // elements with fixed K,M matrices; to be recomputed if prms are changed
 
 	// stage: coefficients and prescribed forces
-	EssenBcs EssentialBcs // constraints (Lagrange multipliers)
+	EssenBcs EssentialBcs // constraints (Lagrange multipliers, penalty or elimination)
 	PtNatBcs PtNaturalBcs // point loads such as prescribed forces at nodes
 
+	// stage: element erosion
+	Eros *Erosion // element deletion (erosion) during stage; nil if not requested
+
+	// stage: batched evaluation of solid elements on a device (e.g. GPU)
+	Batches *solid.Batches // batches of identical solid elements; nil if not requested
+
+	// stage: staggered solution of u-p problems
+	Split *Splitting // fixed-stress split; nil if monolithic
+
+	// stage: propagation of discrete cracks
+	Crack *Cracking // splitting of mesh and insertion of interface elements; nil if not requested
+
+	// stage: tracking of embedded cracks
+	Track *Tracking // initiation and propagation of embedded cracks; nil if not requested
+
+	// stage: updated-Lagrangian analysis with ALE mesh smoothing
+	Ale *Ale // update of coordinates of solids and smoothing of mesh; nil if not requested
+
+	// stage: excavation of tunnels
+	Relax     *Relaxation    // convergence-confinement (β) method of tunnelling; nil if not requested
+	Contracts []*Contraction // volume-loss controlled excavation of tunnels (prescribed contraction)
+
+	// stage: steady-state detection and cycle jumping
+	Steady  *SteadyState // early stop of transient stage; nil if not requested
+	CycJump *CycleJump   // cycle-jump acceleration of cyclic loading; nil if not requested
+
+	// stage: water balance
+	WBal *WaterBalance // accounting of liquid mass; nil if not requested
+
+	// stage: hydraulic gradients
+	Pip *Piping // check of hydraulic gradients (piping and heave); nil if not requested
+
+	// result files
+	Cksums map[string]string // checksums (SHA-256) of result files saved by this processor; filename => checksum
+	RdSums map[string]string // checksums used to verify result files when reading; e.g. from Summary
+
+	// stage: selective output
+	OutFlt  *OutFilter // fields, regions and decimation of output; nil => save everything
+	outfull bool       // save everything regardless of OutFlt; e.g. after interruptions
+
+	// all stages: envelopes of internal forces of structural members
+	Menv *MemberEnvelopes // min/max of internal forces over all steps and stages; nil if not requested
+
+	// all stages: damping from equivalent-linear site response
+	SiteResp *SiteResponse // strain-compatible properties of columns; nil if damping is not requested
+
+	// stage: moving loads and surcharges
+	MovLoads   []*MovingLoad // point loads travelling along paths; e.g. train loads
+	Surcharges []*Surcharge  // parametric surface loads; e.g. strip footings and embankments
+
+	// stage: drawdown of groundwater table
+	DdPl0 map[int]*fun.Cte // equation => pressure at the beginning of stage; set in SetIniVals
+
+	// stage: method of manufactured solutions
+	Mms *Mms // exact solution, source terms and boundary conditions; nil if not requested
+
 	// stage: t1 and t2 variables
 	T1eqs []int // first t-derivative variables; e.g.:  dp/dt vars (subset of ykeys)
 	T2eqs []int // second t-derivative variables; e.g.: d²u/dt² vars (subset of ykeys)
@@ -74,6 +131,10 @@ type Domain struct {
 	Fb       []float64     // residual == -fb
 	Wb       []float64     // workspace
 	InitLSol bool          // flag telling that linear solver needs to be initialised prior to any further call
+	Nit      int           // number of iterations of the last step
+
+	// live monitoring
+	Mon *Monitor // live monitoring server (shared by all domains); nil if not requested
 
 	// for divergence control
 	bkpSol *ele.Solution // backup solution
@@ -82,6 +143,8 @@ type Domain struct {
 // Clean cleans memory allocated by domain
 func (o *Domain) Clean() {
 	o.LinSol.Clean()
+	o.Batches.Free()
+	o.Batches = nil
 	o.InitLSol = true // tell solver that lis has to be initialised before use
 }
 
@@ -102,7 +165,11 @@ func NewDomains(sim *inp.Simulation, dyncfs *ele.DynCoefs, proc, nproc int, dist
 				chk.Panic("number of processors must be equal to the number of partitions defined in mesh file. %d != %d", nproc, len(reg.Msh.Part2cells))
 			}
 		}
-		doms[i].LinSol = la.GetSolver(sim.LinSol.Name)
+		if sim.LinSol.Mixed {
+			doms[i].LinSol = NewLinSolver(NewMixedSolver(doms[i]), false)
+		} else {
+			doms[i].LinSol = NewLinSolver(la.GetSolver(sim.LinSol.Name), !distr && sim.LinSol.Name == "umfpack")
+		}
 		doms[i].DynCfs = dyncfs
 	}
 	return
@@ -245,6 +312,14 @@ func (o *Domain) SetStage(stgidx int) (err error) {
 		}
 	}
 
+	// renumber equations
+	if o.Sim.LinSol.Renum != "" {
+		err = o.Renumber(o.Sim.LinSol.Renum)
+		if err != nil {
+			return
+		}
+	}
+
 	// connect elements (e.g. Joints)
 	for _, e := range o.ElemConnect {
 		nnz, err := e.Connect(o.Cid2elem, o.Msh.Cells[e.Id()])
@@ -254,10 +329,32 @@ func (o *Domain) SetStage(stgidx int) (err error) {
 		o.NnzKb += nnz
 	}
 
+	// temperatures @ nodes from "temp" dofs of other elements (e.g. thermomech) sharing vertices
+	for _, e := range o.Elems {
+		if et, ok := e.(ele.WithNodalTemp); ok {
+			verts := o.Msh.Cells[e.Id()].Verts
+			eqs := make([]int, len(verts))
+			for j, v := range verts {
+				eqs[j] = o.Vid2node[v].GetEq("temp")
+				if eqs[j] < 0 {
+					eqs = nil
+					break
+				}
+			}
+			et.SetTempEqs(eqs)
+		}
+	}
+
 	// element conditions, essential and natural boundary conditions --------------------------------
 
 	// (re)set constraints and prescribed forces structures
-	o.EssenBcs.Init(o.Sim.LiqMdl)
+	err = o.EssenBcs.Init(o.Sim.LiqMdl, o.Sim.Solver.Constraints, o.Sim.Solver.Penalty)
+	if err != nil {
+		return
+	}
+	if o.Distr && o.EssenBcs.Strategy == "elim" {
+		return chk.Err("elimination of constraints is not available in parallel runs")
+	}
 	o.PtNatBcs.Reset()
 
 	// element conditions
@@ -281,6 +378,37 @@ func (o *Domain) SetStage(stgidx int) (err error) {
 		}
 	}
 
+	// centrifuge modelling
+	err = o.SetCentrifuge(stg.Centrif)
+	if err != nil {
+		return chk.Err("setting of centrifugal acceleration failed:\n%v", err)
+	}
+
+	// drawdown of groundwater table
+	err = o.SetDrawdown(stg.Drawdown)
+	if err != nil {
+		return chk.Err("setting of drawdown of groundwater table failed:\n%v", err)
+	}
+
+	// volume-loss controlled excavation of tunnels
+	o.Contracts = make([]*Contraction, 0)
+	for _, dat := range stg.Contracts {
+		c, err := NewContraction(o, dat)
+		if err != nil {
+			return chk.Err("cannot set contraction of tunnel:\n%v", err)
+		}
+		o.Contracts = append(o.Contracts, c)
+	}
+
+	// method of manufactured solutions
+	o.Mms = nil
+	if stg.Mms != nil {
+		o.Mms, err = NewMms(o, stg.Mms)
+		if err != nil {
+			return chk.Err("cannot set manufactured solution:\n%v", err)
+		}
+	}
+
 	// face essential boundary conditions
 	for _, fc := range stg.FaceBcs {
 		pairs, ok := o.Msh.FaceTag2cells[fc.Tag]
@@ -331,6 +459,22 @@ func (o *Domain) SetStage(stgidx int) (err error) {
 		}
 	}
 
+	// tie constraints
+	for _, tie := range stg.Ties {
+		err = o.SetTie(tie)
+		if err != nil {
+			return chk.Err("setting of tie constraints failed:\n%v", err)
+		}
+	}
+
+	// couplings between structural elements and solids
+	for _, cpl := range stg.Couplings {
+		err = o.SetCoupling(cpl)
+		if err != nil {
+			return chk.Err("setting of coupling constraints failed:\n%v", err)
+		}
+	}
+
 	// resize slices --------------------------------------------------------------------------------
 
 	// t1 and t2 equations
@@ -353,6 +497,7 @@ func (o *Domain) SetStage(stgidx int) (err error) {
 	o.Ny = eq
 	o.Nlam, o.NnzA = o.EssenBcs.Build(o.Ny)
 	o.Nyb = o.Ny + o.Nlam
+	o.EssenBcs.ElimGraph(o)
 
 	// solution structure and linear solver
 	o.Sol = new(ele.Solution)
@@ -365,7 +510,7 @@ func (o *Domain) SetStage(stgidx int) (err error) {
 	o.Kb = new(la.Triplet)
 	o.Fb = make([]float64, o.Nyb)
 	o.Wb = make([]float64, o.Nyb)
-	o.Kb.Init(o.Nyb, o.Nyb, o.NnzKb+2*o.NnzA)
+	o.Kb.Init(o.Nyb, o.Nyb, o.NnzKb+o.EssenBcs.NnzKb(o.NnzKb, o.NnzA))
 	o.InitLSol = true // tell solver that lis has to be initialised before use
 
 	// allocate arrays
@@ -384,6 +529,171 @@ func (o *Domain) SetStage(stgidx int) (err error) {
 	o.Sol.Ext = make(map[int][]float64, 0)
 	o.Sol.Cnt = make(map[int]int, 0)
 
+	// mass scaling and selective time integration
+	err = o.SetDynCtrls(stg.DynCtrls)
+	if err != nil {
+		return
+	}
+
+	// damping from equivalent-linear site response
+	if o.SiteResp != nil {
+		o.SiteResp.apply(o)
+	}
+
+	// stressing of anchors and struts
+	err = o.SetPrestress(stg.Prestress)
+	if err != nil {
+		return
+	}
+
+	// element erosion
+	o.Eros = nil
+	if stg.Erosion != nil {
+		o.Eros, err = NewErosion(o, stg.Erosion)
+		if err != nil {
+			return
+		}
+	}
+
+	// excavation with stress relaxation and installation of lining
+	o.Relax = nil
+	if stg.Relax != nil {
+		o.Relax, err = NewRelaxation(o, stg.Relax, stg.Control.Tf)
+		if err != nil {
+			return
+		}
+	}
+
+	// batches of identical solid elements evaluated on a device
+	o.Batches.Free()
+	o.Batches = nil
+	if o.Sim.Solver.Device != "" {
+		if o.Eros != nil || o.Relax != nil {
+			return chk.Err("evaluation of elements on a device cannot be combined with erosion or relaxation")
+		}
+		o.Batches, err = solid.NewBatches(o.Elems, o.Sim.Solver.Device, o.Sim.Data.Steady)
+		if err != nil {
+			return
+		}
+		if o.ShowMsg {
+			io.Pf(">> Number of batches of solid elements evaluated on device %q = %d\n", o.Sim.Solver.Device, len(o.Batches.List))
+		}
+	}
+
+	// staggered solution with the fixed-stress split
+	o.Split = nil
+	if o.Sim.Solver.Split {
+		o.Split, err = NewSplitting(o, stg.Subcycle)
+		if err != nil {
+			return
+		}
+	} else if stg.Subcycle != nil {
+		return chk.Err("subcycling requires the staggered solution; i.e. solver.split = true")
+	}
+
+	// propagation of discrete cracks
+	o.Crack = nil
+	if stg.Cracking != nil {
+		o.Crack, err = NewCracking(o, stgidx, stg.Cracking)
+		if err != nil {
+			return
+		}
+	}
+
+	// tracking of embedded cracks
+	o.Track = nil
+	if stg.Tracking != nil {
+		o.Track, err = NewTracking(o, stg.Tracking)
+		if err != nil {
+			return
+		}
+	}
+
+	// updated-Lagrangian analysis with ALE mesh smoothing
+	o.Ale = nil
+	if stg.Ale != nil {
+		o.Ale, err = NewAle(o, stg.Ale)
+		if err != nil {
+			return
+		}
+	}
+
+	// steady-state detection
+	o.Steady = nil
+	if stg.Control.SteadyTol > 0 {
+		o.Steady, err = NewSteadyState(o, stgidx, &stg.Control)
+		if err != nil {
+			return
+		}
+	}
+
+	// water balance
+	o.WBal = nil
+	if o.Sim.Data.WaterBal {
+		o.WBal, err = NewWaterBalance(o, stgidx)
+		if err != nil {
+			return
+		}
+	}
+
+	// selective output
+	o.OutFlt = nil
+	if stg.Output != nil {
+		o.OutFlt, err = NewOutFilter(o, stg.Output, stg.Control.Tf)
+		if err != nil {
+			return
+		}
+	}
+
+	// envelopes of internal forces of members (kept over all stages)
+	if o.Sim.Data.Envelopes {
+		if o.Menv == nil {
+			o.Menv, err = NewMemberEnvelopes(o)
+			if err != nil {
+				return
+			}
+		}
+		o.Menv.Stage = stgidx
+	}
+
+	// hydraulic gradients
+	o.Pip = nil
+	if stg.Piping != nil {
+		o.Pip, err = NewPiping(o, stgidx, stg.Piping)
+		if err != nil {
+			return
+		}
+	}
+
+	// cycle jumping
+	o.CycJump = nil
+	if stg.CycleJump != nil {
+		o.CycJump, err = NewCycleJump(o, stg.CycleJump)
+		if err != nil {
+			return
+		}
+	}
+
+	// moving loads
+	o.MovLoads = make([]*MovingLoad, 0)
+	for _, dat := range stg.MovingLoads {
+		ml, err := NewMovingLoad(o, dat)
+		if err != nil {
+			return chk.Err("cannot set moving loads:\n%v", err)
+		}
+		o.MovLoads = append(o.MovLoads, ml)
+	}
+
+	// surcharges
+	o.Surcharges = make([]*Surcharge, 0)
+	for _, dat := range stg.Surcharges {
+		sc, err := NewSurcharge(o, dat)
+		if err != nil {
+			return chk.Err("cannot set surcharge:\n%v", err)
+		}
+		o.Surcharges = append(o.Surcharges, sc)
+	}
+
 	// message
 	if o.ShowMsg {
 		io.Pf(">> Steady=%v, Axisym=%v, Pstress=%v\n", o.Sol.Steady, o.Sol.Axisym, o.Sol.Pstress)
@@ -431,6 +741,14 @@ func (o *Domain) SetIniVals(stgidx int, zeroSol bool) (err error) {
 		if o.ShowMsg {
 			io.Pf(">> Initial state set by using function\n")
 		}
+	} else if stg.IniInterp != nil {
+		err = o.IniInterpolate(stg)
+		if err != nil {
+			return
+		}
+		if o.ShowMsg {
+			io.Pf(">> Initial state interpolated from previous simulation\n")
+		}
 	} else {
 		for _, e := range o.ElemIntvars {
 			e.SetIniIvs(o.Sol, nil)
@@ -462,7 +780,7 @@ func (o *Domain) SetIniVals(stgidx int, zeroSol bool) (err error) {
 				}
 			}
 			for _, nod := range o.Nodes {
-				for _, ukey := range []string{"ux", "uy", "uz"} {
+				for _, ukey := range []string{"ux", "uy", "uz", "rx", "ry", "rz"} {
 					eq := nod.GetEq(ukey)
 					if eq >= 0 {
 						o.Sol.Y[eq] = 0
@@ -479,14 +797,43 @@ func (o *Domain) SetIniVals(stgidx int, zeroSol bool) (err error) {
 		}
 	}
 
+	// forces of excavated elements at the beginning of stage
+	if o.Relax != nil {
+		err = o.Relax.Start(o)
+		if err != nil {
+			return chk.Err("cannot start relaxation of excavated elements:\n%v", err)
+		}
+	}
+
 	// set boundary conditions that depend on initial values
 	o.EssenBcs.FixIniVals(o.Sol)
+	for eq, pl0 := range o.DdPl0 {
+		pl0.C = o.Sol.Y[eq]
+	}
 
 	// list boundary conditions
 	if o.Sim.Data.ListBcs {
 		io.Pf("%v", o.EssenBcs.List(stg.Control.Tf))
 	}
 
+	// initial values for steady-state detection
+	if o.Steady != nil {
+		o.Steady.Start(o.Sol)
+	}
+
+	// initial mass of liquid for water balance
+	if o.WBal != nil {
+		err = o.WBal.Start(o)
+		if err != nil {
+			return
+		}
+	}
+
+	// records of hydraulic gradients
+	if o.Pip != nil {
+		o.Pip.Start()
+	}
+
 	// make sure time is zero at the beginning of simulation
 	o.Sol.T = 0
 	return
@@ -0,0 +1,134 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// SetDrawdown sets the constraints of pore-liquid pressures at the nodes of regions where the
+// groundwater table is lowered (or raised). See inp.DrawdownData
+//  Note: the pressures at the beginning of the stage (pl0) are only known after the initial
+//        values are set; thus they are stored in DdPl0 and fixed in SetIniVals
+func (o *Domain) SetDrawdown(dats []*inp.DrawdownData) (err error) {
+
+	// check
+	o.DdPl0 = make(map[int]*fun.Cte)
+	if len(dats) == 0 {
+		return
+	}
+	if o.Sim.LiqMdl == nil {
+		return chk.Err("liquid model is required to compute hydrostatic pressures below the new phreatic line")
+	}
+
+	// for each region
+	ndim := o.Sim.Ndim
+	for _, dat := range dats {
+
+		// phreatic line
+		var zwfcn fun.Func
+		if dat.Fcn != "" {
+			zwfcn, err = o.Sim.Functions.Get(dat.Fcn)
+			if err != nil {
+				return
+			}
+		} else {
+			if len(dat.Line) < 1 {
+				return chk.Err("phreatic line must be given by \"line\" or \"fcn\"")
+			}
+			for i, p := range dat.Line {
+				if len(p) != 2 {
+					return chk.Err("points of phreatic line must have two coordinates {x, zw}. point %d is invalid: %v", i, p)
+				}
+				if i > 0 && p[0] <= dat.Line[i-1][0] {
+					return chk.Err("points of phreatic line must be sorted by x")
+				}
+			}
+		}
+
+		// multiplier
+		var mult fun.Func = &fun.Cte{C: 1}
+		if dat.Mult != "" {
+			mult, err = o.Sim.Functions.Get(dat.Mult)
+			if err != nil {
+				return
+			}
+		}
+		one_minus_m := &fun.Add{A: 1, Fa: &fun.Cte{C: 1}, B: -1, Fb: mult}
+
+		// nodes
+		for _, tag := range dat.Tags {
+			cells, ok := o.Msh.CellTag2cells[tag]
+			if !ok {
+				return chk.Err("cannot find cells with tag = %d given in drawdown data", tag)
+			}
+			for _, cell := range cells {
+				for _, vid := range cell.Verts {
+					nod := o.Vid2node[vid]
+					if nod == nil {
+						continue
+					}
+					d := nod.GetDof("pl")
+					if d == nil {
+						continue // node doesn't have pl; e.g. in qua8/qua4 elements
+					}
+					if _, ok := o.DdPl0[d.Eq]; ok {
+						continue // node shared by cells
+					}
+
+					// hydrostatic pressure below new phreatic line
+					x := nod.Vert.C
+					var zw float64
+					if zwfcn != nil {
+						zw = zwfcn.F(0, x)
+					} else {
+						zw = drawdown_level(dat.Line, x[0])
+					}
+					liq := *o.Sim.LiqMdl
+					liq.H = zw
+					plw, _ := liq.Calc(x[ndim-1])
+					if dat.NoSuc {
+						plw = math.Max(plw, 0)
+					}
+
+					// constraint: pl(t) = (1 - m(t))・pl0 + m(t)・plw
+					pl0 := &fun.Cte{}
+					fcn := &fun.Add{
+						A: 1, Fa: &fun.Mul{Fa: one_minus_m, Fb: pl0},
+						B: 1, Fb: &fun.Mul{Fa: mult, Fb: &fun.Cte{C: plw}},
+					}
+					err = o.EssenBcs.Set("pl", []*Node{nod}, fcn, "")
+					if err != nil {
+						return
+					}
+					o.DdPl0[d.Eq] = pl0
+				}
+			}
+		}
+	}
+	return
+}
+
+// drawdown_level computes the elevation of the phreatic line at x by linear interpolation
+func drawdown_level(line [][]float64, x float64) float64 {
+	n := len(line)
+	if x <= line[0][0] {
+		return line[0][1]
+	}
+	if x >= line[n-1][0] {
+		return line[n-1][1]
+	}
+	for i := 1; i < n; i++ {
+		if x <= line[i][0] {
+			ξ := (x - line[i-1][0]) / (line[i][0] - line[i-1][0])
+			return line[i-1][1] + ξ*(line[i][1]-line[i-1][1])
+		}
+	}
+	return line[n-1][1]
+}
@@ -0,0 +1,92 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+)
+
+// DYNCTRL_MAXRATIO is the maximum ratio of mass scaling factors of dynamic elements sharing a node
+// (interfaces between regions); larger jumps lead to spurious reflections of waves
+const DYNCTRL_MAXRATIO = 100.0
+
+// SetDynCtrls sets mass scaling factors and schemes (dynamic or quasi-static) of elements with the
+// tags (regions) given in the stage data
+//  Note: the following consistency checks are performed
+//        (1) the simulation must be transient
+//        (2) each tag must exist in the mesh and be given only once
+//        (3) the elements with given tags must support dynamics control (ele.WithDynCtrl)
+//        (4) the ratio of mass scaling factors of dynamic elements sharing a node (at interfaces
+//            between regions) must not exceed DYNCTRL_MAXRATIO
+func (o *Domain) SetDynCtrls(dats []*inp.DynCtrlData) (err error) {
+
+	// check
+	if len(dats) == 0 {
+		return
+	}
+	if o.Sim.Data.Steady {
+		return chk.Err("mass scaling and selective time integration require a transient simulation")
+	}
+
+	// tags
+	tag2dat := make(map[int]*inp.DynCtrlData)
+	for _, dat := range dats {
+		for _, tag := range dat.Tags {
+			if _, ok := tag2dat[tag]; ok {
+				return chk.Err("tag %d is given more than once in dynamics control data", tag)
+			}
+			tag2dat[tag] = dat
+		}
+	}
+	found := make(map[int]bool)
+	for _, c := range o.Msh.Cells {
+		found[c.Tag] = true
+	}
+	for tag, _ := range tag2dat {
+		if !found[tag] {
+			return chk.Err("cannot find cells with tag = %d given in dynamics control data", tag)
+		}
+	}
+
+	// set elements and collect mass scaling factors at nodes
+	smin := make(map[int]float64) // vertex id => min factor of dynamic elements
+	smax := make(map[int]float64) // vertex id => max factor of dynamic elements
+	for _, c := range o.Msh.Cells {
+		e := o.Cid2elem[c.Id]
+		if e == nil {
+			continue
+		}
+		mscale := 1.0
+		if dat, ok := tag2dat[c.Tag]; ok {
+			edc, ok := e.(ele.WithDynCtrl)
+			if !ok {
+				return chk.Err("element of cell # %d (tag = %d) does not support mass scaling or selective time integration", c.Id, c.Tag)
+			}
+			edc.SetDynCtrl(dat.Mscale, dat.Scheme == "qsta")
+			if dat.Scheme == "qsta" {
+				continue
+			}
+			mscale = dat.Mscale
+		}
+		for _, vid := range c.Verts {
+			if s, ok := smin[vid]; !ok || mscale < s {
+				smin[vid] = mscale
+			}
+			smax[vid] = math.Max(smax[vid], mscale)
+		}
+	}
+
+	// check interfaces
+	for vid, s := range smin {
+		if smax[vid]/s > DYNCTRL_MAXRATIO {
+			return chk.Err("ratio of mass scaling factors of elements sharing vertex # %d is too large: %g / %g > %g", vid, smax[vid], s, DYNCTRL_MAXRATIO)
+		}
+	}
+	return
+}
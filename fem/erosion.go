@@ -0,0 +1,305 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Erosion implements the deletion (erosion) of elements during a stage
+//  Note: (1) eroded elements are replaced by ErodedElem in the list of elements; thus, the number
+//            of equations does not change. The forces of eroded elements are released over Nrel
+//            time steps and the equations of nodes that are no longer attached to any active element
+//            (freed nodes) are held in place by unit springs
+//        (2) the internal variables of eroded elements are frozen; connectors (e.g. rod-joints)
+//            attached to eroded elements are not eroded automatically
+//        (3) only serial runs with the implicit solver ("imp") are supported
+type Erosion struct {
+	Dat   *inp.ErosionData // input data
+	Tags  map[int]bool     // tags of elements that can be eroded
+	Elems []*ErodedElem    // eroded elements
+	Freed map[int]float64  // freed equations => value of y when freed
+	neles []int            // [ny] number of active elements sharing each equation
+}
+
+// NewErosion allocates a new Erosion structure for the elements of domain
+func NewErosion(d *Domain, dat *inp.ErosionData) (o *Erosion, err error) {
+	if d.Distr {
+		return nil, chk.Err("element erosion is not available in parallel runs")
+	}
+	if d.Sim.Solver.Type != "imp" {
+		return nil, chk.Err("element erosion requires the implicit solver (\"imp\"). %q is invalid", d.Sim.Solver.Type)
+	}
+	if len(dat.Crits) == 0 {
+		return nil, chk.Err("at least one erosion criterion must be given")
+	}
+	o = new(Erosion)
+	o.Dat = dat
+	o.Tags = make(map[int]bool)
+	for _, tag := range dat.Tags {
+		o.Tags[tag] = true
+	}
+	o.Freed = make(map[int]float64)
+	o.neles = make([]int, d.Ny)
+	for _, e := range d.Elems {
+		for _, eq := range elem_eqs(d, e) {
+			o.neles[eq]++
+		}
+	}
+	return
+}
+
+// Step performs the erosion tasks after a time step has converged; i.e. it advances the release
+// of forces of already eroded elements and erodes the elements satisfying any criterion
+func (o *Erosion) Step(d *Domain) (err error) {
+
+	// advance release of forces
+	for _, e := range o.Elems {
+		e.Nstp++
+	}
+
+	// check criteria
+	var neroded int
+	for idx, e := range d.Elems {
+		if _, eroded := e.(*ErodedElem); eroded {
+			continue
+		}
+		if !o.Tags[d.Msh.Cells[e.Id()].Tag] {
+			continue
+		}
+		out, ok := e.(ele.CanOutputIps)
+		if !ok {
+			continue
+		}
+		M := ele.NewIpsMap()
+		out.OutIpVals(M, d.Sol)
+		if !o.criterion(M) {
+			continue
+		}
+		err = o.erode(d, idx)
+		if err != nil {
+			return
+		}
+		neroded++
+	}
+
+	// message
+	if neroded > 0 && d.ShowMsg {
+		io.Pf("\n>> %d elements eroded at t = %g; total = %d\n", neroded, d.Sol.T, len(o.Elems))
+	}
+	return
+}
+
+// AddToRhs adds the contribution of springs holding freed nodes to fb
+func (o *Erosion) AddToRhs(fb []float64, sol *ele.Solution) {
+	for eq, y0 := range o.Freed {
+		fb[eq] -= sol.Y[eq] - y0
+	}
+}
+
+// AddToKb adds the contribution of springs holding freed nodes to Kb
+func (o *Erosion) AddToKb(Kb *la.Triplet) {
+	for eq, _ := range o.Freed {
+		Kb.Put(eq, eq, 1)
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// elem_eqs returns the equations of the nodes of element
+func elem_eqs(d *Domain, e ele.Element) (eqs []int) {
+	for _, v := range d.Msh.Cells[e.Id()].Verts {
+		if nod := d.Vid2node[v]; nod != nil {
+			for _, dof := range nod.Dofs {
+				eqs = append(eqs, dof.Eq)
+			}
+		}
+	}
+	return
+}
+
+// criterion checks whether any criterion is satisfied at any integration point
+func (o *Erosion) criterion(M *ele.IpsMap) bool {
+	for _, crit := range o.Dat.Crits {
+		if crit.Key == "sig1" {
+			if o.max_princ_stress(M) > crit.Max {
+				return true
+			}
+			continue
+		}
+		for _, val := range (*M)[crit.Key] {
+			if val > crit.Max {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// max_princ_stress returns the maximum principal stress among all integration points
+func (o *Erosion) max_princ_stress(M *ele.IpsMap) (res float64) {
+	res = math.Inf(-1)
+	sx, ok := (*M)["sx"]
+	if !ok {
+		return
+	}
+	nsig := 4
+	if _, ok = (*M)["syz"]; ok {
+		nsig = 6
+	}
+	keys := []string{"sx", "sy", "sz", "sxy", "syz", "szx"}
+	σ := make([]float64, nsig)
+	for idx, _ := range sx {
+		for i := 0; i < nsig; i++ {
+			σ[i] = M.Get(keys[i], idx)
+		}
+		σ1, σ2, σ3, err := tsr.M_PrincValsNum(σ)
+		if err != nil {
+			continue
+		}
+		res = math.Max(res, math.Max(σ1, math.Max(σ2, σ3)))
+	}
+	return
+}
+
+// erode replaces element d.Elems[idx] by an eroded element
+func (o *Erosion) erode(d *Domain, idx int) (err error) {
+
+	// forces at the time of erosion
+	e := d.Elems[idx]
+	fb := make([]float64, d.Nyb)
+	err = e.AddToRhs(fb, d.Sol)
+	if err != nil {
+		return chk.Err("cannot compute forces of element (eid=%d) to be eroded:\n%v", e.Id(), err)
+	}
+
+	// eroded element
+	r := &ErodedElem{E: e, Ero: o, Nrel: o.Dat.Nrel}
+	r.Eqs = elem_eqs(d, e)
+	r.F0 = make([]float64, len(r.Eqs))
+	for k, eq := range r.Eqs {
+		r.F0[k] = fb[eq]
+	}
+	d.Elems[idx] = r
+	d.Cid2elem[e.Id()] = r
+	o.Elems = append(o.Elems, r)
+
+	// freed equations
+	for _, eq := range r.Eqs {
+		o.neles[eq]--
+		if o.neles[eq] == 0 {
+			o.Freed[eq] = d.Sol.Y[eq]
+		}
+	}
+
+	// remove element from subsets
+	remove_from_subsets(d, e)
+	return
+}
+
+// remove_from_subsets removes element e from the subsets of elements of domain
+func remove_from_subsets(d *Domain, e ele.Element) {
+	d.ElemIntvars = remove_ivs_elem(d.ElemIntvars, e)
+	d.ElemIvsCon = remove_ivs_elem(d.ElemIvsCon, e)
+	d.ElemIvsNotCon = remove_ivs_elem(d.ElemIvsNotCon, e)
+	for i, c := range d.ElemConnect {
+		if c.Id() == e.Id() {
+			d.ElemConnect = append(d.ElemConnect[:i], d.ElemConnect[i+1:]...)
+			break
+		}
+	}
+	for i, c := range d.ElemExtrap {
+		if c.(ele.Element).Id() == e.Id() {
+			d.ElemExtrap = append(d.ElemExtrap[:i], d.ElemExtrap[i+1:]...)
+			break
+		}
+	}
+	for i, c := range d.ElemFixedKM {
+		if c.(ele.Element).Id() == e.Id() {
+			d.ElemFixedKM = append(d.ElemFixedKM[:i], d.ElemFixedKM[i+1:]...)
+			break
+		}
+	}
+}
+
+// remove_ivs_elem removes element e from list of elements with internal variables
+func remove_ivs_elem(list []ele.WithIntVars, e ele.Element) []ele.WithIntVars {
+	for i, c := range list {
+		if c.(ele.Element).Id() == e.Id() {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+// ErodedElem implements an element that has been eroded
+//  Note: the contribution to fb at the time of erosion (F0) is linearly reduced to zero over Nrel
+//        time steps; except at freed equations. No contribution to Kb is added
+type ErodedElem struct {
+	E    ele.Element // eroded element
+	Ero  *Erosion    // erosion structure
+	Eqs  []int       // equations of element's nodes
+	F0   []float64   // [len(Eqs)] contribution to fb at the time of erosion
+	Nrel int         // number of time steps to release forces
+	Nstp int         // number of (converged) time steps after erosion
+}
+
+// Id returns the cell Id
+func (o *ErodedElem) Id() int { return o.E.Id() }
+
+// SetEqs set equations
+func (o *ErodedElem) SetEqs(eqs [][]int, mixedform_eqs []int) (err error) {
+	return
+}
+
+// SetEleConds set element conditions
+func (o *ErodedElem) SetEleConds(key string, f fun.Func, extra string) (err error) {
+	return
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *ErodedElem) InterpStarVars(sol *ele.Solution) (err error) {
+	return
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o *ErodedElem) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
+	m := 1.0 - float64(o.Nstp)/float64(o.Nrel)
+	if m <= 0 {
+		return
+	}
+	for k, eq := range o.Eqs {
+		if _, freed := o.Ero.Freed[eq]; freed {
+			continue
+		}
+		fb[eq] += m * o.F0[k]
+	}
+	return
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o *ErodedElem) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+	return
+}
+
+// Encode encodes internal variables (frozen at the time of erosion)
+func (o *ErodedElem) Encode(enc utl.Encoder) (err error) {
+	return o.E.Encode(enc)
+}
+
+// Decode decodes internal variables
+func (o *ErodedElem) Decode(dec utl.Decoder) (err error) {
+	return o.E.Decode(dec)
+}
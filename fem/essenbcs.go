@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/mdl/fluid"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
@@ -18,7 +19,7 @@ import (
 )
 
 // EssentialBc holds information about essential bounday conditions such as constrained nodes.
-// Lagrange multipliers are used to implement both single- and multi-point constraints.
+// By default, Lagrange multipliers are used to implement both single- and multi-point constraints.
 //  In general, essential bcs / constraints are defined by means of:
 //
 //      A・y = c
@@ -30,6 +31,16 @@ import (
 //     |_ A   0 _| \ δλ /   \  c - A*y  /
 //         Kb       δyb          fb
 //
+//  Alternatively (see EssentialBcs.Strategy), with the large-penalty method (β), no Lagrange
+//  multipliers are needed and the system becomes:
+//
+//     (K + β At*A)・δy = -R + β At*(c - A*y)
+//
+//  or, with the exact elimination of single-point constraints (y_j = c_j), the rows and columns
+//  of constrained equations are replaced by the identity and the system becomes:
+//
+//     K_ff・δy_f = -R_f - K_fj・δy_j   with   δy_j = c_j - y_j
+//
 type EssentialBc struct {
 	Key   string    // key such as 'ux', 'uy', 'rigid', 'incsup', 'hst'
 	Eqs   []int     // equations numbers; can be more than one e.g. for inclined support
@@ -41,20 +52,63 @@ type EssentialBc struct {
 type EbcArray []*EssentialBc
 
 // EssentialBcs implements a structure to record the definition of essential bcs / constraints.
-// Each constraint will have a unique Lagrange multiplier index.
+// Each constraint handled by Lagrange multipliers will have a unique Lagrange multiplier index.
+//  Strategy -- "lagrange": Lagrange multipliers (default). Saddle-point system
+//              "penalty":  large-penalty method. Approximate; the accuracy depends on the penalty
+//                          coefficient compared with the stiffness coefficients
+//              "elim":     exact elimination of single-point constraints; multi-point constraints
+//                          (e.g. "rigid" and "incsup") are still handled by Lagrange multipliers.
+//                          Recommended for iterative linear solvers
+//  Note: (1) with "elim", the rows and columns of Kb corresponding to constrained equations are
+//            extracted after each assembly by products with probing vectors: the constraints are
+//            coloured such that constraints of the same colour have no coupled equations in common
+//            (distance-2 colouring); thus, one pair of products is required per colour (not per
+//            constraint) and the cost is proportional to the number of non-zeros of Kb. Since
+//            each probed entry corresponds to a single K_ij, the cancellation is exact. The
+//            reactions are not available in Sol.L
+//        (2) "elim" is not available in parallel runs
 type EssentialBcs struct {
-	LiqMdl *fluid.Model // for computing hydrostatic conditions
-	EqsIni map[int]bool // equations that depend on initial values
-	Bcs    EbcArray     // active essential bcs / constraints
-	A      la.Triplet   // matrix of coefficients 'A'
-	Am     *la.CCMatrix // compressed form of A matrix
+	LiqMdl   *fluid.Model // for computing hydrostatic conditions
+	Strategy string       // strategy to handle constraints: "lagrange", "penalty" or "elim"
+	Penalty  float64      // penalty coefficient β
+	EqsIni   map[int]bool // equations that depend on initial values
+	Bcs      EbcArray     // active essential bcs / constraints
+	A        la.Triplet   // matrix of coefficients 'A'
+	Am       *la.CCMatrix // compressed form of A matrix
+
+	// subsets of Bcs according to strategy
+	Mbcs   EbcArray    // bcs / constraints with Lagrange multipliers
+	Pbcs   EbcArray    // penalised bcs / constraints
+	Ebcs   EbcArray    // eliminated (single-point) bcs / constraints
+	ElimEq map[int]int // eliminated equation => index in Ebcs
+
+	// coupling terms of eliminated constraints: K_ij with i free and j = Ebcs[k].Eqs[0]
+	elimI [][]int     // [nelim][nnz] indices i
+	elimK [][]float64 // [nelim][nnz] values K_ij
+
+	// probing of rows and columns of eliminated constraints (see ElimGraph)
+	elimNbr [][]int      // [nelim] equations coupled with each eliminated equation (including itself)
+	elimCol [][]int      // [ncolours] indices in Ebcs of constraints probed together
+	km      *la.CCMatrix // compressed form of Kb
+	e, c, r []float64    // [nyb] probing vector, column and row entries
 }
 
 // Init initialises this structure
-func (o *EssentialBcs) Init(liqmdl *fluid.Model) {
+//  strategy -- "lagrange", "penalty" or "elim". empty => "lagrange"
+//  penalty  -- penalty coefficient (used with "penalty" only)
+func (o *EssentialBcs) Init(liqmdl *fluid.Model, strategy string, penalty float64) (err error) {
 	o.LiqMdl = liqmdl
+	o.Strategy = strategy
+	if o.Strategy == "" {
+		o.Strategy = "lagrange"
+	}
+	if o.Strategy != "lagrange" && o.Strategy != "penalty" && o.Strategy != "elim" {
+		return chk.Err("strategy to handle constraints %q is invalid; options are \"lagrange\", \"penalty\" and \"elim\"", strategy)
+	}
+	o.Penalty = penalty
 	o.EqsIni = make(map[int]bool)
 	o.Bcs = make([]*EssentialBc, 0)
+	return
 }
 
 // Build builds the structures required for assembling A matrix
@@ -63,22 +117,44 @@ func (o *EssentialBcs) Init(liqmdl *fluid.Model) {
 func (o *EssentialBcs) Build(ny int) (nλ, nnzA int) {
 
 	// skip if there are no constraints
-	nλ = len(o.Bcs)
-	if nλ == 0 {
+	o.Mbcs, o.Pbcs, o.Ebcs = make([]*EssentialBc, 0), make([]*EssentialBc, 0), make([]*EssentialBc, 0)
+	o.ElimEq = make(map[int]int)
+	if len(o.Bcs) == 0 {
 		return
 	}
 
 	// sort bcs to make sure all processors will number Lagrange multipliers in the same order
 	sort.Sort(o.Bcs)
 
-	// count number of non-zeros in matrix A
+	// subsets according to strategy
 	for _, bc := range o.Bcs {
+		switch {
+		case o.Strategy == "penalty":
+			o.Pbcs = append(o.Pbcs, bc)
+		case o.Strategy == "elim" && len(bc.Eqs) == 1 && bc.ValsA[0] != 0:
+			o.ElimEq[bc.Eqs[0]] = len(o.Ebcs)
+			o.Ebcs = append(o.Ebcs, bc)
+		default:
+			o.Mbcs = append(o.Mbcs, bc)
+		}
+	}
+	o.elimI = make([][]int, len(o.Ebcs))
+	o.elimK = make([][]float64, len(o.Ebcs))
+
+	// skip if there are no Lagrange multipliers
+	nλ = len(o.Mbcs)
+	if nλ == 0 {
+		return
+	}
+
+	// count number of non-zeros in matrix A
+	for _, bc := range o.Mbcs {
 		nnzA += len(bc.ValsA)
 	}
 
 	// set matrix A
 	o.A.Init(nλ, ny, nnzA)
-	for i, bc := range o.Bcs {
+	for i, bc := range o.Mbcs {
 		for j, eq := range bc.Eqs {
 			o.A.Put(i, eq, bc.ValsA[j])
 		}
@@ -87,6 +163,20 @@ func (o *EssentialBcs) Build(ny int) (nλ, nnzA int) {
 	return
 }
 
+// NnzKb returns the number of non-zeros added to Kb by AddToKb
+//  nnzK -- number of non-zeros in K (from elements)
+//  nnzA -- number of non-zeros in A (from Build)
+func (o *EssentialBcs) NnzKb(nnzK, nnzA int) (nnz int) {
+	nnz = 2 * nnzA
+	for _, bc := range o.Pbcs {
+		nnz += len(bc.Eqs) * len(bc.Eqs)
+	}
+	if len(o.Ebcs) > 0 {
+		nnz += nnzK + 2*nnzA + len(o.Ebcs) // cancellation terms in rows/columns of eliminated equations + identity
+	}
+	return
+}
+
 // AddtoRhs adds the essential bcs / constraints terms to the augmented fb vector
 func (o *EssentialBcs) AddToRhs(fb []float64, sol *ele.Solution) {
 
@@ -95,29 +185,217 @@ func (o *EssentialBcs) AddToRhs(fb []float64, sol *ele.Solution) {
 		return
 	}
 
+	// penalised constraints: add β At*(c - A*y) to fb
+	for _, bc := range o.Pbcs {
+		r := bc.Fcn.F(sol.T, nil)
+		for j, eq := range bc.Eqs {
+			r -= bc.ValsA[j] * sol.Y[eq]
+		}
+		for j, eq := range bc.Eqs {
+			fb[eq] += o.Penalty * bc.ValsA[j] * r
+		}
+	}
+
+	// eliminated constraints: fb_j = δy_j = c_j - y_j
+	for _, bc := range o.Ebcs {
+		eq := bc.Eqs[0]
+		fb[eq] = bc.Fcn.F(sol.T, nil)/bc.ValsA[0] - sol.Y[eq]
+	}
+
+	// skip if there are no Lagrange multipliers
+	if len(o.Mbcs) == 0 {
+		return
+	}
+
 	// add -At*λ to fb
 	la.SpMatTrVecMulAdd(fb, -1, o.Am, sol.L) // fb += -1 * At * λ
 
 	// assemble -rc = c - A*y into fb
 	ny := len(sol.Y)
-	for i, bc := range o.Bcs {
+	for i, bc := range o.Mbcs {
 		fb[ny+i] = bc.Fcn.F(sol.T, nil)
 	}
 	la.SpMatVecMulAdd(fb[ny:], -1, o.Am, sol.Y) // fb += -1 * A * y
 }
 
+// AddToKb adds the essential bcs / constraints terms to the Kb matrix; it must be called after
+// all elements have added their contributions
+//  Note: with "elim", the coupling terms used by AddElimToRhs are computed here
+func (o *EssentialBcs) AddToKb(Kb *la.Triplet, nyb int) {
+
+	// Lagrange multipliers: join A and tr(A) matrices into Kb
+	if len(o.Mbcs) > 0 {
+		Kb.PutMatAndMatT(&o.A)
+	}
+
+	// penalised constraints: add β At*A
+	for _, bc := range o.Pbcs {
+		for i, eqi := range bc.Eqs {
+			for j, eqj := range bc.Eqs {
+				Kb.Put(eqi, eqj, o.Penalty*bc.ValsA[i]*bc.ValsA[j])
+			}
+		}
+	}
+
+	// eliminated constraints: cancel rows and columns and set identity
+	if len(o.Ebcs) == 0 {
+		return
+	}
+	if len(o.e) != nyb {
+		o.e, o.c, o.r = make([]float64, nyb), make([]float64, nyb), make([]float64, nyb)
+	}
+	o.km = Kb.ToMatrix(o.km)
+	for _, ks := range o.elimCol {
+
+		// probe all constraints of colour: c = K・Σe_j and r = Kt・Σe_j
+		for _, k := range ks {
+			o.e[o.Ebcs[k].Eqs[0]] = 1
+		}
+		la.SpMatVecMul(o.c, 1, o.km, o.e)
+		la.SpMatTrVecMul(o.r, 1, o.km, o.e)
+		for _, k := range ks {
+			o.e[o.Ebcs[k].Eqs[0]] = 0
+		}
+
+		// columns and rows of each constraint
+		for _, k := range ks {
+			j := o.Ebcs[k].Eqs[0]
+			o.elimI[k] = o.elimI[k][:0]
+			o.elimK[k] = o.elimK[k][:0]
+			for _, i := range o.elimNbr[k] {
+				if v := o.c[i]; v != 0 {
+					Kb.Put(i, j, -v)
+					if _, elim := o.ElimEq[i]; !elim {
+						o.elimI[k] = append(o.elimI[k], i)
+						o.elimK[k] = append(o.elimK[k], v)
+					}
+				}
+				if _, elim := o.ElimEq[i]; o.r[i] != 0 && !elim {
+					Kb.Put(j, i, -o.r[i])
+				}
+				o.c[i], o.r[i] = 0, 0
+			}
+			Kb.Put(j, j, 1)
+		}
+
+		// check that all entries have been found
+		for i := 0; i < nyb; i++ {
+			if o.c[i] != 0 || o.r[i] != 0 {
+				chk.Panic("equation %d is coupled with eliminated constraints but is not in the graph of constraints", i)
+			}
+		}
+	}
+}
+
+// ElimGraph computes the equations coupled with each eliminated equation and colours the
+// eliminated constraints such that constraints of the same colour have no coupled equations in
+// common (distance-2 colouring). It must be called after Build when equations and active cells
+// have been set
+func (o *EssentialBcs) ElimGraph(d *Domain) {
+
+	// coupled equations: dofs of the node of each eliminated equation and of its neighbours (see
+	// Domain.nodes_graph) and Lagrange multipliers of constraints with the eliminated equation
+	o.elimCol = nil
+	if len(o.Ebcs) == 0 {
+		return
+	}
+	eq2n := make(map[int]int)
+	for i, nod := range d.Nodes {
+		for _, dof := range nod.Dofs {
+			eq2n[dof.Eq] = i
+		}
+	}
+	nbr := d.nodes_graph(false, o.Pbcs)
+	nbrs := make([]map[int]bool, len(o.Ebcs))
+	for k, bc := range o.Ebcs {
+		nbrs[k] = map[int]bool{bc.Eqs[0]: true}
+		i, ok := eq2n[bc.Eqs[0]]
+		if !ok {
+			continue
+		}
+		for _, dof := range d.Nodes[i].Dofs {
+			nbrs[k][dof.Eq] = true
+		}
+		for _, j := range nbr[i] {
+			for _, dof := range d.Nodes[j].Dofs {
+				nbrs[k][dof.Eq] = true
+			}
+		}
+	}
+	connect := func(eqs []int) {
+		for _, j := range eqs {
+			if k, ok := o.ElimEq[j]; ok {
+				for _, i := range eqs {
+					nbrs[k][i] = true
+				}
+			}
+		}
+	}
+	for i, bc := range o.Mbcs {
+		connect(append([]int{d.Ny + i}, bc.Eqs...))
+	}
+	o.elimNbr = make([][]int, len(o.Ebcs))
+	for k, m := range nbrs {
+		for i := range m {
+			o.elimNbr[k] = append(o.elimNbr[k], i)
+		}
+		sort.Ints(o.elimNbr[k])
+	}
+
+	// colouring
+	owners := make(map[int][]int) // equation => constraints coupled with it
+	colour := make([]int, len(o.Ebcs))
+	for k := range o.Ebcs {
+		used := make(map[int]bool)
+		for _, i := range o.elimNbr[k] {
+			for _, l := range owners[i] {
+				used[colour[l]] = true
+			}
+		}
+		c := 0
+		for used[c] {
+			c++
+		}
+		colour[k] = c
+		if c == len(o.elimCol) {
+			o.elimCol = append(o.elimCol, nil)
+		}
+		o.elimCol[c] = append(o.elimCol[c], k)
+		for _, i := range o.elimNbr[k] {
+			owners[i] = append(owners[i], k)
+		}
+	}
+}
+
+// AddElimToRhs adds the coupling terms of eliminated constraints to fb; i.e. fb_i -= K_ij・δy_j.
+// It must be called after AddToRhs and AddToKb and before solving the linear system
+func (o *EssentialBcs) AddElimToRhs(fb []float64) {
+	for k, bc := range o.Ebcs {
+		δy := fb[bc.Eqs[0]]
+		for m, i := range o.elimI[k] {
+			fb[i] -= o.elimK[k][m] * δy
+		}
+	}
+}
+
 // GetIsEssenKeyMap returns the "YandC" map with special keys that EssentialBcs can handle,
 // including:
 //  rigid  -- define rigid element constraints
 //  incsup -- inclined support constraints
 //  hst    -- set hydrostatic pressures
+//  symx, symy, symz             -- symmetry planes normal to x, y or z
+//  antisymx, antisymy, antisymz -- antisymmetry planes normal to x, y or z
+//  pin    -- all displacements
+//  clamp  -- all displacements and rotations
 func GetIsEssenKeyMap() map[string]bool {
-	return map[string]bool{"rigid": true, "incsup": true, "hst": true}
+	return map[string]bool{"rigid": true, "incsup": true, "hst": true,
+		"symx": true, "symy": true, "symz": true, "antisymx": true, "antisymy": true, "antisymz": true,
+		"pin": true, "clamp": true}
 }
 
 // Set sets a constraint if it does not exist yet.
 //  key   -- can be Dof key such as "ux", "uy" or constraint type such as "incsup" or "rigid"
-//  extra -- is a keycode-style data. e.g. "!type:incsup2d !alp:30"
+//  extra -- is a keycode-style data. e.g. "!alp:30" or "!nx:1 !ny:1 !nz:0" for "incsup"
 //  Notes:
 //   1) the default key is single point constraint; e.g. "ux", "uy", ...
 //   2) hydraulic head can be set with key == "hst" (hydrostatic). In this case, fcn==shift
@@ -126,6 +404,25 @@ func GetIsEssenKeyMap() map[string]bool {
 //   3) if the key as a suffix "_ini", the initial value of essential key will be multiplied
 //      by fcn==mult in order to define the boundary condition according to:
 //          y(t,z) = y(z)_ini・mult(t)
+//   4) inclined (skewed) supports are set with key == "incsup". The displacement along the
+//      normal n of the support is constrained according to n・u = fcn (e.g. roller on an
+//      inclined surface). The normal is given in extra by (see incsup_dirs):
+//          "!alp:α"               -- angle (degrees) between n and the x-axis (2D only)
+//          "!nx:a !ny:b !nz:c"    -- components of n (normalised here)
+//          "!cx:a !cy:b !cz:c"    -- centre; n is the radial direction of each node
+//          "!ax:a !ay:b !az:c"    -- with a centre: axis; n is normal to the axis (cylinder)
+//      In 3D, a second constrained direction "!mx:a !my:b !mz:c" can be given such that the
+//      node slides along the line normal to n and m only (with m・u = 0)
+//   5) symmetry (e.g. "symx") and antisymmetry (e.g. "antisymx") planes normal to the x, y or z
+//      axis are set with the zero function; fcn is ignored. The constrained dofs are (see
+//      sym_constrained):
+//          sym:     the normal displacement and the rotations about in-plane axes. The normal
+//                   fluxes of scalar fields (e.g. "pl", "h") vanish naturally
+//          antisym: the in-plane displacements, the rotation about the normal axis and the
+//                   scalar fields (e.g. "pl", "h")
+//   6) all displacements are prescribed with key == "pin" and all displacements and rotations
+//      (e.g. at the end of beams) with key == "clamp"; i.e. y = fcn for each dof. Nodes without
+//      rotations (e.g. of solids) are pinned by "clamp"
 func (o *EssentialBcs) Set(key string, nodes []*Node, fcn fun.Func, extra string) (err error) {
 
 	// auxiliary
@@ -148,24 +445,61 @@ func (o *EssentialBcs) Set(key string, nodes []*Node, fcn fun.Func, extra string
 
 	// inclined support
 	if key == "incsup" {
+		for _, nod := range nodes {
 
-		// check
-		if ndim != 2 {
-			return chk.Err("inclined support works only in 2D for now")
+			// constrained directions
+			dirs, err := incsup_dirs(nod.Vert.C, extra)
+			if err != nil {
+				return chk.Err("cannot set inclined support at node # %d:\n%v", nod.Vert.Id, err)
+			}
+
+			// equations of displacements
+			eqs := make([]int, ndim)
+			for i, ukey := range []string{"ux", "uy", "uz"}[:ndim] {
+				eqs[i] = nod.GetEq(ukey)
+				if eqs[i] < 0 {
+					return chk.Err("cannot set inclined support at node # %d because it does not have %q", nod.Vert.Id, ukey)
+				}
+			}
+
+			// set constraints: the second direction (3D) cannot replace the first one
+			o.set_eqs(key, eqs, dirs[0], fcn_at(fcn, nod.Vert.C))
+			if len(dirs) > 1 {
+				o.Bcs = append(o.Bcs, &EssentialBc{key, eqs, dirs[1], &fun.Zero})
+			}
 		}
+		return // success
+	}
 
-		// get data
-		var α float64
-		if val, found := io.Keycode(extra, "alp"); found {
-			α = io.Atof(val) * math.Pi / 180.0
+	// symmetry and antisymmetry planes
+	if strings.HasPrefix(key, "sym") || strings.HasPrefix(key, "antisym") {
+		anti := strings.HasPrefix(key, "anti")
+		axis := strings.TrimPrefix(strings.TrimPrefix(key, "anti"), "sym")
+		if len(axis) != 1 || strings.Index("xyz"[:ndim], axis) < 0 {
+			return chk.Err("symmetry plane %q is invalid in %dD", key, ndim)
+		}
+		for _, nod := range nodes {
+			for _, d := range nod.Dofs {
+				if sym_constrained(d.Key, axis, anti) {
+					o.set_eqs(d.Key, []int{d.Eq}, []float64{1}, &fun.Zero)
+				}
+			}
 		}
-		co, si := math.Cos(α), math.Sin(α)
+		return // success
+	}
 
-		// set for all nodes
+	// pinned and clamped nodes
+	if key == "pin" || key == "clamp" {
+		keys := DispKeys(ndim)
+		if key == "clamp" {
+			keys = append(keys, RotKeys(ndim)...)
+		}
 		for _, nod := range nodes {
-			eqx := nod.Dofs[0].Eq
-			eqy := nod.Dofs[1].Eq
-			o.set_eqs(key, []int{eqx, eqy}, []float64{co, si}, &fun.Zero)
+			for _, k := range keys {
+				if d := nod.GetDof(k); d != nil {
+					o.set_eqs(k, []int{d.Eq}, []float64{1}, fcn_at(fcn, nod.Vert.C))
+				}
+			}
 		}
 		return // success
 	}
@@ -195,7 +529,7 @@ func (o *EssentialBcs) Set(key string, nodes []*Node, fcn fun.Func, extra string
 			plVal, _ := o.LiqMdl.Calc(z)
 			pl := fun.Add{
 				B: 1, Fb: &fun.Cte{C: plVal},
-				A: -1, Fa: fcn,
+				A: -1, Fa: fcn_at(fcn, nod.Vert.C),
 			}
 
 			// set constraint
@@ -218,7 +552,7 @@ func (o *EssentialBcs) Set(key string, nodes []*Node, fcn fun.Func, extra string
 			}
 
 			// create function
-			f := fun.Mul{Fa: fcn, Fb: &fun.Cte{}}
+			f := fun.Mul{Fa: fcn_at(fcn, nod.Vert.C), Fb: &fun.Cte{}}
 
 			// set constraint
 			o.set_eqs(kkey, []int{d.Eq}, []float64{1}, &f)
@@ -237,13 +571,20 @@ func (o *EssentialBcs) Set(key string, nodes []*Node, fcn fun.Func, extra string
 		}
 
 		// set constraint
-		o.set_eqs(key, []int{d.Eq}, []float64{1}, fcn)
+		o.set_eqs(key, []int{d.Eq}, []float64{1}, fcn_at(fcn, nod.Vert.C))
 	}
 
 	// success
 	return
 }
 
+// AddMpc adds a multi-point constraint without replacing existent ones; e.g. for tie constraints
+// sharing equations of master vertices
+//  Note: eqs must be sorted in increasing order
+func (o *EssentialBcs) AddMpc(key string, eqs []int, valsA []float64, fcn fun.Func) {
+	o.Bcs = append(o.Bcs, &EssentialBc{key, eqs, valsA, fcn})
+}
+
 // FixIniVals fixes functions of BCs that depend on initial values
 func (o *EssentialBcs) FixIniVals(sol *ele.Solution) {
 	for eq, _ := range o.EqsIni {
@@ -274,6 +615,98 @@ func (o *EssentialBcs) List(t float64) (l string) {
 
 // auxiliary /////////////////////////////////////////////////////////////////////////////////////////
 
+// sym_constrained returns whether dof (key) is constrained on a symmetry (or antisymmetry if anti)
+// plane normal to axis ("x", "y" or "z")
+func sym_constrained(key, axis string, anti bool) bool {
+	if len(key) == 2 && strings.Contains("xyz", key[1:]) {
+		switch key[0] {
+		case 'u': // displacements
+			return (key[1:] == axis) != anti
+		case 'r': // rotations
+			return (key[1:] == axis) == anti
+		}
+	}
+	if key == "fl" { // seepage face multiplier
+		return false
+	}
+	return anti // scalar fields
+}
+
+// incsup_dirs computes the constrained (unit) directions of an inclined support at point x
+func incsup_dirs(x []float64, extra string) (dirs [][]float64, err error) {
+
+	// read vector with components given by keys (prefix + "x", "y", "z")
+	ndim := len(x)
+	vec := func(prefix string) (v []float64, found bool) {
+		v = make([]float64, ndim)
+		for i, c := range []string{"x", "y", "z"}[:ndim] {
+			if val, ok := io.Keycode(extra, prefix+c); ok {
+				v[i], found = io.Atof(val), true
+			}
+		}
+		return
+	}
+	unit := func(v []float64, name string) error {
+		norm := la.VecNorm(v)
+		if norm < 1e-12 {
+			return chk.Err("%s direction of inclined support is undefined: %v", name, v)
+		}
+		for i := 0; i < ndim; i++ {
+			v[i] /= norm
+		}
+		return nil
+	}
+
+	// normal direction
+	n, hasn := vec("n")
+	c, hasc := vec("c")
+	switch {
+	case hasn:
+	case hasc:
+		for i := 0; i < ndim; i++ {
+			n[i] = x[i] - c[i]
+		}
+		if a, hasa := vec("a"); hasa {
+			if err = unit(a, "axial"); err != nil {
+				return
+			}
+			d := la.VecDot(n, a)
+			for i := 0; i < ndim; i++ {
+				n[i] -= d * a[i]
+			}
+		}
+	default:
+		if ndim != 2 {
+			return nil, chk.Err("the normal of inclined supports in 3D must be given by \"!nx !ny !nz\" or by a centre \"!cx !cy !cz\"")
+		}
+		var α float64
+		if val, found := io.Keycode(extra, "alp"); found {
+			α = io.Atof(val) * math.Pi / 180.0
+		}
+		n[0], n[1] = math.Cos(α), math.Sin(α)
+	}
+	if err = unit(n, "normal"); err != nil {
+		return
+	}
+	dirs = [][]float64{n}
+
+	// second direction: orthogonalised with respect to the normal
+	if m, hasm := vec("m"); hasm {
+		if ndim != 3 {
+			return nil, chk.Err("second direction of inclined support is only available in 3D")
+		}
+		d := la.VecDot(m, n)
+		for i := 0; i < ndim; i++ {
+			m[i] -= d * n[i]
+		}
+		if err = unit(m, "second"); err != nil {
+			return
+		}
+		dirs = append(dirs, m)
+	}
+	return
+}
+
 // set_eqs sets/replace constraint and equations
 func (o *EssentialBcs) set_eqs(key string, eqs []int, valsA []float64, fcn fun.Func) {
 
@@ -301,3 +734,12 @@ func (o EbcArray) Less(i, j int) bool {
 	sort.Ints(o[j].Eqs)
 	return o[i].Eqs[0] < o[j].Eqs[0]
 }
+
+// fcn_at returns a copy of fcn evaluated at the coordinates x (e.g. of a node) if fcn depends on
+// coordinates (see inp.ExprFunc); otherwise fcn is returned
+func fcn_at(fcn fun.Func, x []float64) fun.Func {
+	if f, ok := fcn.(*inp.ExprFunc); ok && f.X == nil {
+		return f.At(x)
+	}
+	return fcn
+}
@@ -6,9 +6,15 @@ package fem
 
 import (
 	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	goio "io"
 	"os"
 	"path"
+	"strings"
 
+	"github.com/cpmech/gofem/ele"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/utl"
@@ -26,27 +32,33 @@ func (o *Domain) SaveSol(tidx int, verbose bool) (err error) {
 	var buf bytes.Buffer
 	enc := utl.GetEncoder(&buf, o.Sim.EncType)
 
+	// selected equations
+	Y, Dydt, D2ydt2 := o.Sol.Y, o.Sol.Dydt, o.Sol.D2ydt2
+	if o.OutFlt != nil && !o.outfull {
+		Y, Dydt, D2ydt2 = o.OutFlt.Vec(Y), o.OutFlt.Vec(Dydt), o.OutFlt.Vec(D2ydt2)
+	}
+
 	// encode Sol
 	err = enc.Encode(o.Sol.T)
 	if err != nil {
 		return chk.Err("cannot encode Domain.Sol.T\n%v", err)
 	}
-	err = enc.Encode(o.Sol.Y)
+	err = enc.Encode(Y)
 	if err != nil {
 		return chk.Err("cannot encode Domain.Sol.Y\n%v", err)
 	}
-	err = enc.Encode(o.Sol.Dydt)
+	err = enc.Encode(Dydt)
 	if err != nil {
 		return chk.Err("cannot encode Domain.Sol.Dydt\n%v", err)
 	}
-	err = enc.Encode(o.Sol.D2ydt2)
+	err = enc.Encode(D2ydt2)
 	if err != nil {
 		return chk.Err("cannot encode Domain.Sol.D2ydt2\n%v", err)
 	}
 
 	// save file
 	fn := out_nod_path(o.Sim.DirOut, o.Sim.Key, o.Sim.EncType, tidx, o.Proc)
-	return save_file(fn, &buf, verbose)
+	return o.save_res(fn, &buf, verbose)
 }
 
 // ReadSol reads Solution from a file which name is set with tidx (time output index)
@@ -54,11 +66,10 @@ func (o *Domain) ReadSol(dir, fnkey, enctype string, tidx int) (err error) {
 
 	// open file
 	fn := out_nod_path(dir, fnkey, enctype, tidx, 0) // 0 => reading always from proc # 0
-	fil, err := os.Open(fn)
+	fil, err := o.open_res(fn)
 	if err != nil {
 		return
 	}
-	defer func() { err = fil.Close() }()
 
 	// get decoder
 	dec := utl.GetDecoder(fil, enctype)
@@ -91,10 +102,19 @@ func (o *Domain) SaveIvs(tidx int, verbose bool) (err error) {
 	enc := utl.GetEncoder(&buf, o.Sim.EncType)
 
 	// elements that go to file
-	enc.Encode(o.MyCids)
+	elems := o.Elems
+	if o.OutFlt != nil && !o.outfull {
+		enc.Encode(o.OutFlt.Cids)
+		elems = make([]ele.Element, len(o.OutFlt.Cids))
+		for i, cid := range o.OutFlt.Cids {
+			elems[i] = o.Cid2elem[cid]
+		}
+	} else {
+		enc.Encode(o.MyCids)
+	}
 
 	// encode internal variables
-	for _, e := range o.Elems {
+	for _, e := range elems {
 		err = e.Encode(enc)
 		if err != nil {
 			return
@@ -103,7 +123,7 @@ func (o *Domain) SaveIvs(tidx int, verbose bool) (err error) {
 
 	// save file
 	fn := out_ele_path(o.Sim.DirOut, o.Sim.Key, o.Sim.EncType, tidx, o.Proc)
-	return save_file(fn, &buf, verbose)
+	return o.save_res(fn, &buf, verbose)
 }
 
 // ReadIvs reads elements's internal values from a file which name is set with tidx (time output index)
@@ -111,11 +131,10 @@ func (o *Domain) ReadIvs(dir, fnkey, enctype string, tidx, proc int) (err error)
 
 	// open file
 	fn := out_ele_path(dir, fnkey, enctype, tidx, proc)
-	fil, err := os.Open(fn)
+	fil, err := o.open_res(fn)
 	if err != nil {
 		return
 	}
-	defer func() { err = fil.Close() }()
 
 	// decoder
 	dec := utl.GetDecoder(fil, enctype)
@@ -159,6 +178,9 @@ func (o *Domain) Save(tidx int, verbose bool) (err error) {
 //
 func (o *Domain) Read(sum *Summary, tidx, proc int, allInOne bool) (err error) {
 
+	// checksums of files
+	o.RdSums = sum.Checksums
+
 	// serial run
 	if allInOne {
 		for i := 0; i < sum.Nproc; i++ {
@@ -188,7 +210,70 @@ func out_ele_path(dir, fnkey, enctype string, tidx, proc int) string {
 	return path.Join(dir, io.Sf("%s_p%d_ele_%010d.%s", fnkey, proc, tidx, enctype))
 }
 
+// save_res saves a result file (nodes or elements) with compression (filename.gz) and records
+// its checksum if requested
+func (o *Domain) save_res(filename string, buf *bytes.Buffer, verbose bool) (err error) {
+	if o.Sim.Data.Compress == "gzip" {
+		var zbuf bytes.Buffer
+		zw := gzip.NewWriter(&zbuf)
+		_, err = zw.Write(buf.Bytes())
+		if err != nil {
+			return chk.Err("cannot compress result file <%s>:\n%v", filename, err)
+		}
+		err = zw.Close()
+		if err != nil {
+			return chk.Err("cannot compress result file <%s>:\n%v", filename, err)
+		}
+		buf, filename = &zbuf, filename+".gz"
+	}
+	if o.Sim.Data.Checksum {
+		if o.Cksums == nil {
+			o.Cksums = make(map[string]string)
+		}
+		o.Cksums[path.Base(filename)] = checksum(buf.Bytes())
+	}
+	return save_file(filename, buf, verbose)
+}
+
+// open_res returns a reader of the result file filename or filename.gz (decompressed). The
+// checksum is verified if recorded in RdSums
+func (o *Domain) open_res(filename string) (r goio.Reader, err error) {
+	b, err := read_file(filename)
+	if os.IsNotExist(err) {
+		filename += ".gz"
+		b, err = read_file(filename)
+	}
+	if err != nil {
+		return
+	}
+	if sum, ok := o.RdSums[path.Base(filename)]; ok {
+		if checksum(b) != sum {
+			return nil, chk.Err("checksum of result file <%s> does not match the one recorded in summary; the file is corrupted", filename)
+		}
+	}
+	if strings.HasSuffix(filename, ".gz") {
+		r, err = gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, chk.Err("cannot decompress result file <%s>:\n%v", filename, err)
+		}
+		return
+	}
+	return bytes.NewReader(b), nil
+}
+
+// checksum returns the SHA-256 checksum of b as an hexadecimal string
+func checksum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
 func save_file(filename string, buf *bytes.Buffer, verbose bool) (err error) {
+	if memfs_write(filename, buf.Bytes()) {
+		if verbose {
+			io.Pfblue2("file <%s> kept in memory\n", filename)
+		}
+		return
+	}
 	fil, err := os.Create(filename)
 	if err != nil {
 		return
@@ -0,0 +1,130 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// FaceFlux holds the fluxes across faces with a tag recovered by RecoverFaceFlux
+type FaceFlux struct {
+	Total float64   // total flux across faces (outwards)
+	Vids  []int     // ids of vertices on faces
+	Qnod  []float64 // [len(Vids)] nodal fluxes (outwards); i.e. consistent with the residual
+	Qdist []float64 // [len(Vids)] flux densities (outwards) at vertices; i.e. flux per unit area
+}
+
+// RecoverFaceFlux recovers the flux of dof (key) across faces with tag (ftag) from the residual
+// of the internal terms of the elements (consistent flux recovery); e.g. key = "pl" gives the
+// outflow of liquid (mass rate) and key = "u" gives the outward flux of diffusion problems
+//  Note: (1) the nodal fluxes are the residuals of all terms of the elements except the natural
+//            boundary conditions, computed with the current solution (Sol); thus, they hold
+//            the storage terms and sources too and are accurate after convergence only
+//        (2) the flux densities are found by solving the boundary mass system ∫Nᵢ・Nⱼ dΓ q = Qnod;
+//            they are much more accurate than the fluxes evaluated at boundary ips
+//        (3) vertices at the intersection with other boundaries receive the whole nodal flux
+//        (4) the results are per unit thickness in 2D and per radian in axisymmetric problems
+func (o *Domain) RecoverFaceFlux(ftag int, key string) (res *FaceFlux, err error) {
+
+	// vertices on faces
+	pairs, ok := o.Msh.FaceTag2cells[ftag]
+	if !ok {
+		return nil, chk.Err("cannot find faces with tag = %d for flux recovery", ftag)
+	}
+	res = new(FaceFlux)
+	vid2idx := make(map[int]int)
+	for _, pair := range pairs {
+		c := pair.C
+		if c.Shp == nil {
+			continue
+		}
+		for _, m := range c.Shp.FaceLocalVerts[pair.Fid] {
+			vid := c.Verts[m]
+			if _, ok := vid2idx[vid]; !ok {
+				vid2idx[vid] = len(res.Vids)
+				res.Vids = append(res.Vids, vid)
+			}
+		}
+	}
+	nv := len(res.Vids)
+	if nv == 0 {
+		return nil, chk.Err("cannot find vertices of faces with tag = %d for flux recovery", ftag)
+	}
+
+	// residual of internal terms: fb(all) - fb(natural boundary conditions)
+	fb := make([]float64, o.Nyb)
+	fn := make([]float64, o.Nyb)
+	for _, e := range o.Elems {
+		err = e.AddToRhs(fb, o.Sol)
+		if err != nil {
+			return
+		}
+		if en, ok := e.(ele.WithNatBcs); ok {
+			err = en.AddNatBcsToRhs(fn, o.Sol)
+			if err != nil {
+				return
+			}
+		}
+	}
+	if o.Distr {
+		all_reduce_sum(o.Sim.Solver.Determ, fb, o.Wb)
+		all_reduce_sum(o.Sim.Solver.Determ, fn, o.Wb)
+	}
+	res.Qnod = make([]float64, nv)
+	for i, vid := range res.Vids {
+		nod := o.Vid2node[vid]
+		eq := -1
+		if nod != nil {
+			eq = nod.GetEq(key)
+		}
+		if eq < 0 {
+			return nil, chk.Err("cannot find dof %q at vertex # %d of face with tag = %d", key, vid, ftag)
+		}
+		res.Qnod[i] = fb[eq] - fn[eq]
+		res.Total += res.Qnod[i]
+	}
+
+	// boundary mass matrix
+	M := la.MatAlloc(nv, nv)
+	err = o.integ_faces(ftag, nil, func(c *inp.Cell, fid int, sh *shp.Shape, coef float64, V [][]float64) error {
+		coef *= la.VecNorm(sh.Fnvec)
+		lverts := sh.FaceLocalVerts[fid]
+		for k, m := range lverts {
+			I := vid2idx[c.Verts[m]]
+			for l, n := range lverts {
+				J := vid2idx[c.Verts[n]]
+				M[I][J] += coef * sh.Sf[k] * sh.Sf[l]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	if o.Distr {
+		Mv, Mw := make([]float64, nv*nv), make([]float64, nv*nv)
+		for i := 0; i < nv; i++ {
+			copy(Mv[i*nv:(i+1)*nv], M[i])
+		}
+		all_reduce_sum(o.Sim.Solver.Determ, Mv, Mw)
+		for i := 0; i < nv; i++ {
+			copy(M[i], Mv[i*nv:(i+1)*nv])
+		}
+	}
+
+	// flux densities
+	Mi := la.MatAlloc(nv, nv)
+	err = la.MatInvG(Mi, M, 1e-10)
+	if err != nil {
+		return nil, chk.Err("cannot invert boundary mass matrix of faces with tag = %d:\n%v", ftag, err)
+	}
+	res.Qdist = make([]float64, nv)
+	la.MatVecMul(res.Qdist, 1, Mi, res.Qnod)
+	return
+}
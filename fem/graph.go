@@ -0,0 +1,127 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"sort"
+
+	"github.com/cpmech/gofem/inp"
+)
+
+// cell_verts returns the vertices whose dofs are coupled by cell c: the vertices of c, the vertices
+// of solids connected to it (beam-joints) and, for joints, the vertices of the rod (or beam) and
+// solid cells connected by the joint (see Rjoint.Connect)
+func (o *Domain) cell_verts(c *inp.Cell) (vids []int) {
+	vids = append(append([]int{}, c.Verts...), c.JntConVerts...)
+	if !c.IsJoint {
+		return
+	}
+	cids := c.JsldIds
+	if len(cids) == 0 {
+		cids = []int{c.JsldId}
+	}
+	for _, cid := range append([]int{c.JlinId}, cids...) {
+		if cid >= 0 && cid < len(o.Msh.Cells) {
+			vids = append(vids, o.Msh.Cells[cid].Verts...)
+		}
+	}
+	return
+}
+
+// nodes_graph computes the graph of nodes: two nodes are neighbours if their dofs are coupled in Kb
+// by a cell or by a constraint in bcs
+//  anyproc -- consider cells active in any processor (see Cid2active) instead of the cells of
+//             this processor only (see Cid2elem)
+//  nbr     -- [nnod] sorted indices in Nodes of the neighbours of each node, excluding itself
+func (o *Domain) nodes_graph(anyproc bool, bcs []*EssentialBc) (nbr [][]int) {
+	nnod := len(o.Nodes)
+	idx := make(map[*Node]int)
+	eq2n := make(map[int]int)
+	for i, nod := range o.Nodes {
+		idx[nod] = i
+		for _, dof := range nod.Dofs {
+			eq2n[dof.Eq] = i
+		}
+	}
+	nbrs := make([]map[int]bool, nnod)
+	for i := range nbrs {
+		nbrs[i] = make(map[int]bool)
+	}
+	connect := func(ns []int) {
+		for _, a := range ns {
+			for _, b := range ns {
+				if a != b {
+					nbrs[a][b] = true
+				}
+			}
+		}
+	}
+	for _, c := range o.Msh.Cells {
+		if anyproc && !o.Cid2active[c.Id] || !anyproc && o.Cid2elem[c.Id] == nil {
+			continue
+		}
+		var ns []int
+		for _, vid := range o.cell_verts(c) {
+			if nod := o.Vid2node[vid]; nod != nil {
+				ns = append(ns, idx[nod])
+			}
+		}
+		connect(ns)
+	}
+	for _, bc := range bcs {
+		var ns []int
+		for _, eq := range bc.Eqs {
+			if i, ok := eq2n[eq]; ok {
+				ns = append(ns, i)
+			}
+		}
+		connect(ns)
+	}
+	nbr = make([][]int, nnod)
+	for i, m := range nbrs {
+		for j := range m {
+			nbr[i] = append(nbr[i], j)
+		}
+		sort.Ints(nbr[i])
+	}
+	return
+}
+
+// graph_colouring colours the vertices of a graph by the greedy algorithm such that neighbours
+// (distance-1) or vertices with common neighbours as well (distance-2; e.g. to extract columns of a
+// matrix by probing all vertices of a colour) have different colours
+//  nbr     -- [nverts] neighbours of each vertex
+//  colours -- [ncolours][...] vertices of each colour
+func graph_colouring(nbr [][]int, dist2 bool) (colours [][]int) {
+	colour := make([]int, len(nbr))
+	for v := range colour {
+		colour[v] = -1
+	}
+	for v := range nbr {
+		used := make(map[int]bool)
+		for _, w := range nbr[v] {
+			if colour[w] >= 0 {
+				used[colour[w]] = true
+			}
+			if dist2 {
+				for _, u := range nbr[w] {
+					if colour[u] >= 0 {
+						used[colour[u]] = true
+					}
+				}
+			}
+		}
+		c := 0
+		for used[c] {
+			c++
+		}
+		colour[v] = c
+		if c == len(colours) {
+			colours = append(colours, nil)
+		}
+		colours[c] = append(colours[c], v)
+	}
+	return
+}
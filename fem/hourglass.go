@@ -0,0 +1,67 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gosl/io"
+)
+
+// HgFlag holds an element with dominant zero-energy (hourglass) deformation
+type HgFlag struct {
+	Cid   int     // cell id
+	Ratio float64 // ratio between hourglass and total deformations; see solid.HourglassRatio
+}
+
+// HgFlags is a set of HgFlag sorted by decreasing ratio
+type HgFlags []*HgFlag
+
+func (o HgFlags) Len() int           { return len(o) }
+func (o HgFlags) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o HgFlags) Less(i, j int) bool { return o[i].Ratio > o[j].Ratio }
+
+// CheckHourglass returns the elements of domain (with reduced integration) whose hourglass ratio
+// is larger than rmax, sorted by decreasing ratio
+func CheckHourglass(d *Domain, rmax float64) (flags []*HgFlag) {
+	for _, e := range d.Elems {
+		if eh, ok := e.(ele.WithHourglass); ok {
+			if r, ok := eh.HourglassRatio(d.Sol); ok && r > rmax {
+				flags = append(flags, &HgFlag{e.Id(), r})
+			}
+		}
+	}
+	sort.Sort(HgFlags(flags))
+	return
+}
+
+// HgReport returns a report of elements with dominant hourglass deformation
+func HgReport(flags []*HgFlag, rmax float64) string {
+	var b bytes.Buffer
+	io.Ff(&b, "%d elements with hourglass ratio > %g\n", len(flags), rmax)
+	io.Ff(&b, "%8s%23s\n", "cid", "ratio")
+	for _, f := range flags {
+		io.Ff(&b, "%8d%23.15e\n", f.Cid, f.Ratio)
+	}
+	return b.String()
+}
+
+// check_hourglass reports elements with dominant hourglass deformation after the simulation
+func (o *Main) check_hourglass() {
+	rmax := o.Sim.Data.HgCheck
+	if rmax <= 0 {
+		return
+	}
+	for _, d := range o.Domains {
+		flags := CheckHourglass(d, rmax)
+		if len(flags) > 0 {
+			io.Pfred("%s", HgReport(flags, rmax))
+		} else if o.ShowMsg {
+			io.Pf("> No elements with dominant hourglass deformation\n")
+		}
+	}
+}
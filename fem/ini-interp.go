@@ -0,0 +1,215 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// IniInterpolate sets initial state by interpolating the results of a previous simulation with
+// a different mesh (e.g. coarser or with different element types)
+//  Note: (1) the values at nodes are interpolated with the shape functions of the previous cells
+//            containing each node. If some vertices of a previous cell do not have the dof (e.g.
+//            "pl" in qua8 cells of mixed formulations), the basic shape (e.g. qua4) is used
+//        (2) the stresses at integration points are taken from the closest integration point of
+//            the previous element containing each new integration point; the other internal
+//            variables are then initialised by the models with these stresses
+//        (3) the region of the previous simulation must have the same index as this one
+func (o *Domain) IniInterpolate(stg *inp.Stage) (err error) {
+
+	// read previous simulation and allocate its domain
+	dat := stg.IniInterp
+	prev := inp.ReadSim(dat.Sim, "", false, o.Sim.GoroutineId)
+	if prev == nil {
+		return chk.Err("cannot read previous simulation file %q", dat.Sim)
+	}
+	ireg := 0
+	for i, reg := range o.Sim.Regions {
+		if reg == o.Reg {
+			ireg = i
+		}
+	}
+	if ireg >= len(prev.Regions) {
+		return chk.Err("previous simulation does not have region # %d", ireg)
+	}
+	if dat.Stage < 0 || dat.Stage >= len(prev.Stages) {
+		return chk.Err("stage index %d of previous simulation is invalid", dat.Stage)
+	}
+	dc := new(ele.DynCoefs)
+	dc.Init(&prev.Solver)
+	pd := NewDomains(prev, dc, 0, 1, false, false)[ireg]
+	err = pd.SetStage(dat.Stage)
+	if err != nil {
+		return chk.Err("cannot set stage of previous simulation:\n%v", err)
+	}
+	err = pd.SetIniVals(dat.Stage, true)
+	if err != nil {
+		return chk.Err("cannot initialise previous simulation:\n%v", err)
+	}
+
+	// read results of previous simulation
+	sum := new(Summary)
+	err = sum.Read(prev.DirOut, prev.Key, prev.EncType)
+	if err != nil {
+		return chk.Err("cannot read summary of previous simulation %s/%s:\n%v", prev.DirOut, prev.Key, err)
+	}
+	tidx := dat.Tidx
+	if tidx <= 0 {
+		tidx = len(sum.OutTimes) - 1
+	}
+	err = pd.Read(sum, tidx, 0, true)
+	if err != nil {
+		return chk.Err("cannot read results of previous simulation:\n%v", err)
+	}
+
+	// locator of points in previous mesh
+	loc := inp.NewCellLocator(pd.Msh, 0)
+
+	// dofs to be interpolated
+	dofs := make(map[string]bool)
+	for _, key := range dat.Dofs {
+		dofs[key] = true
+	}
+
+	// interpolate values at nodes
+	for _, nod := range o.Nodes {
+		c, r := loc.Find(nod.Vert.C)
+		if c == nil {
+			return chk.Err("cannot find node # %d (x=%v) in mesh of previous simulation", nod.Vert.Id, nod.Vert.C)
+		}
+		for _, dof := range nod.Dofs {
+			if len(dofs) > 0 && !dofs[dof.Key] {
+				continue
+			}
+			if dat.ResetU && IsDispOrRot(dof.Key) {
+				continue
+			}
+			val, ok := interp_at_cell(pd, c, r, dof.Key)
+			if !ok {
+				return chk.Err("cannot interpolate dof %q of node # %d from previous simulation", dof.Key, nod.Vert.Id)
+			}
+			o.Sol.Y[dof.Eq] = val
+		}
+	}
+
+	// stresses at integration points
+	keys := solid.StressKeys(o.Msh.Ndim)
+	prevM := make(map[int]*ele.IpsMap)
+	prevX := make(map[int][][]float64)
+	for _, e := range o.ElemIntvars {
+
+		// element without output of integration points values
+		ipe, ok := e.(ele.CanOutputIps)
+		if dat.NoIvs || !ok {
+			err = e.SetIniIvs(o.Sol, nil)
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		// closest integration points of previous elements
+		coords := ipe.OutIpCoords()
+		nip := len(coords)
+		ivs := make(map[string][]float64)
+		for idx, x := range coords {
+			c, _ := loc.Find(x)
+			if c == nil {
+				return chk.Err("cannot find integration point (x=%v) of element # %d in mesh of previous simulation", x, ipe.Id())
+			}
+			if _, found := prevM[c.Id]; !found {
+				pe, isout := pd.Cid2elem[c.Id].(ele.CanOutputIps)
+				if !isout {
+					prevM[c.Id] = nil
+					continue
+				}
+				prevM[c.Id] = ele.NewIpsMap()
+				pe.OutIpVals(prevM[c.Id], pd.Sol)
+				prevX[c.Id] = pe.OutIpCoords()
+			}
+			M := prevM[c.Id]
+			if M == nil {
+				continue
+			}
+			jclose, dmin := 0, -1.0
+			for j, y := range prevX[c.Id] {
+				d := utl.L2norm(x[:o.Msh.Ndim], y[:o.Msh.Ndim])
+				if dmin < 0 || d < dmin {
+					jclose, dmin = j, d
+				}
+			}
+			for _, key := range keys {
+				if _, has := (*M)[key]; !has {
+					continue
+				}
+				if _, has := ivs[key]; !has {
+					ivs[key] = make([]float64, nip)
+				}
+				ivs[key][idx] = M.Get(key, jclose)
+			}
+		}
+		if len(ivs) == 0 {
+			ivs = nil
+		}
+
+		// set element's states
+		err = e.SetIniIvs(o.Sol, ivs)
+		if err != nil {
+			return chk.Err("cannot set interpolated internal values of element # %d:\n%v", ipe.Id(), err)
+		}
+	}
+	return
+}
+
+// interp_at_cell interpolates the nodal values of dof (key) within cell c of domain d at the
+// natural coordinates r
+func interp_at_cell(d *Domain, c *inp.Cell, r []float64, key string) (val float64, ok bool) {
+	sh, eqs, ok := interp_shape(d, c, r, key)
+	if !ok {
+		return
+	}
+	for m := 0; m < sh.Nverts; m++ {
+		val += sh.S[m] * d.Sol.Y[eqs[m]]
+	}
+	return
+}
+
+// interp_shape computes the shape functions (sh.S) at the natural coordinates r of cell c of
+// domain d to interpolate the nodal values of dof (key) and returns the equations of this dof at
+// the vertices of cell
+func interp_shape(d *Domain, c *inp.Cell, r []float64, key string) (sh *shp.Shape, eqs []int, ok bool) {
+
+	// equations of vertices of cell
+	eqs = make([]int, len(c.Verts))
+	nvalid := 0
+	for m, vid := range c.Verts {
+		eqs[m] = -1
+		if nod := d.Vid2node[vid]; nod != nil {
+			eqs[m] = nod.GetEq(key)
+		}
+		if eqs[m] >= 0 && nvalid == m {
+			nvalid++
+		}
+	}
+
+	// shape functions
+	sh = c.Shp
+	if nvalid < sh.Nverts {
+		if nvalid < sh.BasicNverts || sh.BasicType == sh.Type {
+			return
+		}
+		sh = shp.Get(sh.BasicType, c.GoroutineId)
+		if sh == nil {
+			return
+		}
+	}
+	sh.Func(sh.S, sh.DSdR, r, false, -1)
+	return sh, eqs, true
+}
@@ -206,6 +206,9 @@ func (o *Domain) IniSetPorous(stg *inp.Stage) (err error) {
 	// make sure all elements tags were handled
 	lins_and_joints := make(map[int]bool)
 	for tag, cells := range o.Msh.CellTag2cells {
+		if o.Msh.SetTags[tag] {
+			continue
+		}
 		solids := true
 		for _, cell := range cells {
 			if !cell.IsSolid {
@@ -0,0 +1,242 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/seepage"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// Integrals over tagged faces and cells computed with the current solution (Sol) and states
+//  Note: (1) the integrals are computed with the shape functions of cells (and faces) at their
+//            default integration points; the values at integration points of elements (e.g.
+//            stresses) are first extrapolated to the vertices of each element
+//        (2) the results are per unit thickness in 2D and per radian in axisymmetric problems
+//        (3) only the cells of this processor are considered
+
+// IntegFaceDof computes the integral of dof (key) over faces with tag (ftag) and the area (length
+// in 2D) of these faces. Thus, the average value is res / area; e.g. average settlement
+func (o *Domain) IntegFaceDof(ftag int, key string) (res, area float64, err error) {
+	err = o.integ_faces(ftag, nil, func(c *inp.Cell, fid int, sh *shp.Shape, coef float64, V [][]float64) error {
+		var y float64
+		for k, m := range sh.FaceLocalVerts[fid] {
+			nod := o.Vid2node[c.Verts[m]]
+			eq := -1
+			if nod != nil {
+				eq = nod.GetEq(key)
+			}
+			if eq < 0 {
+				return chk.Err("cannot find dof %q at vertex # %d of face with tag = %d", key, c.Verts[m], ftag)
+			}
+			y += sh.Sf[k] * o.Sol.Y[eq]
+		}
+		res += coef * la.VecNorm(sh.Fnvec) * y
+		area += coef * la.VecNorm(sh.Fnvec)
+		return nil
+	})
+	return
+}
+
+// IntegFaceFlow computes the total flow rate of liquid across faces with tag (ftag); i.e. the
+// integral of the normal component of the filter velocity (nwl・n) with n pointing outwards
+func (o *Domain) IntegFaceFlow(ftag int) (res float64, err error) {
+	keys := seepage.LiqFlowKeys(o.Msh.Ndim)
+	err = o.integ_faces(ftag, keys, func(c *inp.Cell, fid int, sh *shp.Shape, coef float64, V [][]float64) error {
+		for i := 0; i < o.Msh.Ndim; i++ {
+			res += coef * face_interp(sh, fid, V[i]) * sh.Fnvec[i]
+		}
+		return nil
+	})
+	return
+}
+
+// IntegFaceForce computes the total force (traction σ・n integrated) on faces with tag (ftag)
+// with n pointing outwards
+func (o *Domain) IntegFaceForce(ftag int) (F []float64, err error) {
+	ndim := o.Msh.Ndim
+	keys := solid.StressKeys(ndim)
+	F = make([]float64, ndim)
+	σ := make([]float64, len(keys))
+	err = o.integ_faces(ftag, keys, func(c *inp.Cell, fid int, sh *shp.Shape, coef float64, V [][]float64) error {
+		for k := 0; k < len(keys); k++ {
+			σ[k] = face_interp(sh, fid, V[k])
+		}
+		for i := 0; i < ndim; i++ {
+			for j := 0; j < ndim; j++ {
+				F[i] += coef * tsr.M2T(σ, i, j) * sh.Fnvec[j]
+			}
+		}
+		return nil
+	})
+	return
+}
+
+// IntegCells computes the integral of integration point value (key) over the cells with tag and
+// the volume (area in 2D) of these cells. Thus, the average value is res / vol
+func (o *Domain) IntegCells(tag int, key string) (res, vol float64, err error) {
+	err = o.integ_cells(tag, key, func(v, coef float64) {
+		res += coef * v
+		vol += coef
+	})
+	return
+}
+
+// IntegCellsAbove computes the volume (area in 2D) of the cells with tag where the integration
+// point value (key) is greater than thres; e.g. the volume of plastified material with "alpha"
+func (o *Domain) IntegCellsAbove(tag int, key string, thres float64) (vol float64, err error) {
+	err = o.integ_cells(tag, key, func(v, coef float64) {
+		if v > thres {
+			vol += coef
+		}
+	})
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// integ_faces loops over the integration points of faces with tag (ftag) calling fcn with the
+// integration coefficient (weight, radius in axisymmetric problems) and the extrapolated values
+// V[nkeys][nverts] of keys (nil if keys is nil). sh.Sf and sh.Fnvec are computed before each call
+func (o *Domain) integ_faces(ftag int, keys []string, fcn func(c *inp.Cell, fid int, sh *shp.Shape, coef float64, V [][]float64) error) (err error) {
+	pairs, ok := o.Msh.FaceTag2cells[ftag]
+	if !ok {
+		return chk.Err("cannot find faces with tag = %d for integration", ftag)
+	}
+	for _, pair := range pairs {
+		c, fid := pair.C, pair.Fid
+		sh := c.Shp
+		if o.Cid2elem[c.Id] == nil || sh == nil || sh.Nurbs != nil {
+			continue
+		}
+		var V [][]float64
+		if keys != nil {
+			V, err = o.cell_ext_vals(c, keys)
+			if err != nil {
+				return
+			}
+		}
+		X := o.cell_coords(c, sh.Nverts)
+		_, ipf, err := sh.GetIps(0, 0)
+		if err != nil {
+			return err
+		}
+		for _, ip := range ipf {
+			err = sh.CalcAtFaceIp(X, ip, fid)
+			if err != nil {
+				return err
+			}
+			coef := ip[3]
+			if o.Sim.Data.Axisym {
+				coef *= sh.AxisymGetRadiusF(X, fid)
+			}
+			err = fcn(c, fid, sh, coef, V)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return
+}
+
+// integ_cells loops over the default integration points of cells with tag calling fcn with the
+// value of key (interpolated from extrapolated values) and the integration coefficient
+func (o *Domain) integ_cells(tag int, key string, fcn func(v, coef float64)) (err error) {
+	found := false
+	for _, c := range o.Msh.Cells {
+		sh := c.Shp
+		if c.Tag != tag || o.Cid2elem[c.Id] == nil || sh == nil || sh.Nurbs != nil {
+			continue
+		}
+		found = true
+		V, err := o.cell_ext_vals(c, []string{key})
+		if err != nil {
+			return err
+		}
+		X := o.cell_coords(c, sh.Nverts)
+		ips, _, err := sh.GetIps(0, 0)
+		if err != nil {
+			return err
+		}
+		for _, ip := range ips {
+			err = sh.CalcAtIp(X, ip, false)
+			if err != nil {
+				return err
+			}
+			coef := ip[3] * sh.J
+			if o.Sim.Data.Axisym {
+				coef *= sh.AxisymGetRadius(X)
+			}
+			var v float64
+			for m := 0; m < sh.Nverts; m++ {
+				v += sh.S[m] * V[0][m]
+			}
+			fcn(v, coef)
+		}
+	}
+	if !found {
+		return chk.Err("cannot find cells with tag = %d for integration", tag)
+	}
+	return
+}
+
+// cell_ext_vals extrapolates the integration points values (keys) of the element of cell c to its
+// vertices. Output: V[nkeys][nverts]
+func (o *Domain) cell_ext_vals(c *inp.Cell, keys []string) (V [][]float64, err error) {
+
+	// values at integration points
+	e, ok := o.Cid2elem[c.Id].(ele.CanOutputIps)
+	if !ok {
+		return nil, chk.Err("element of cell # %d cannot output integration points values", c.Id)
+	}
+	M := ele.NewIpsMap()
+	e.OutIpVals(M, o.Sol)
+
+	// natural coordinates of integration points
+	sh := c.Shp
+	X := o.cell_coords(c, sh.Nverts)
+	coords := e.OutIpCoords()
+	ips := make([]shp.Ipoint, len(coords))
+	for i, x := range coords {
+		ips[i] = make([]float64, 4)
+		err = sh.InvMap(ips[i], x, X)
+		if err != nil {
+			return nil, chk.Err("cannot compute natural coordinates of integration point of cell # %d:\n%v", c.Id, err)
+		}
+	}
+
+	// extrapolate
+	E := la.MatAlloc(sh.Nverts, len(ips))
+	err = sh.Extrapolator(E, ips)
+	if err != nil {
+		return
+	}
+	V = la.MatAlloc(len(keys), sh.Nverts)
+	for k, key := range keys {
+		vals, ok := (*M)[key]
+		if !ok {
+			return nil, chk.Err("element of cell # %d does not have integration point value %q", c.Id, key)
+		}
+		for m := 0; m < sh.Nverts; m++ {
+			for i, v := range vals {
+				V[k][m] += E[m][i] * v
+			}
+		}
+	}
+	return
+}
+
+// face_interp interpolates the values at vertices of cell (vals) on face fid with sh.Sf
+func face_interp(sh *shp.Shape, fid int, vals []float64) (res float64) {
+	for k, m := range sh.FaceLocalVerts[fid] {
+		res += sh.Sf[k] * vals[m]
+	}
+	return
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// interrupt_flag is set to 1 when SIGINT or SIGTERM is received during a simulation
+var interrupt_flag int32
+
+// Interrupt requests the solvers to stop the simulation at the end of the current time step as if
+// SIGINT or SIGTERM were received; e.g. from a GUI or a test
+func Interrupt() {
+	atomic.StoreInt32(&interrupt_flag, 1)
+}
+
+// catch_signals handles SIGINT and SIGTERM during a simulation: the first signal requests the
+// solvers to finish the current time step, save the state and return (see interrupted); a second
+// signal aborts the program immediately. The returned function restores the default behaviour
+func catch_signals(verbose bool) (stop func()) {
+	atomic.StoreInt32(&interrupt_flag, 0)
+	ch := make(chan os.Signal, 2)
+	done := make(chan bool)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				if atomic.LoadInt32(&interrupt_flag) == 1 {
+					io.PfRed("\n> %v received again: aborting\n", sig)
+					os.Exit(1)
+				}
+				Interrupt()
+				if verbose {
+					io.Pfyel("\n> %v received: finishing current time step and saving state (repeat to abort)\n", sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// interrupted returns whether the simulation must be stopped. With MPI, all processors stop if
+// any of them received the signal
+func interrupted(doms []*Domain) bool {
+	flag := []float64{float64(atomic.LoadInt32(&interrupt_flag))}
+	if doms[0].Distr {
+		wrk := make([]float64, 1)
+		all_reduce_sum(doms[0].Sim.Solver.Determ, flag, wrk)
+	}
+	return flag[0] > 0
+}
+
+// save_interrupted saves the state at time t (if not saved by the last output yet or if the last
+// output was filtered) and returns the error telling that the simulation was interrupted
+func save_interrupted(doms []*Domain, sum *Summary, t float64) (err error) {
+	if sum != nil {
+		n := len(sum.OutTimes)
+		if n == 0 || sum.OutTimes[n-1] != t || doms[0].OutFlt != nil {
+			err = sum.save_domains(t, doms, true, false)
+			if err != nil {
+				return chk.Err("cannot save results after interruption:\n%v", err)
+			}
+		}
+	}
+	return chk.Err("simulation interrupted at t = %g", t)
+}
+
+// resume_hint prints how to continue a simulation interrupted during stage stgidx
+func (o *Main) resume_hint(stgidx int) {
+	if o.Proc != 0 || atomic.LoadInt32(&interrupt_flag) == 0 {
+		return
+	}
+	if o.Summary == nil || len(o.Summary.OutTimes) == 0 {
+		io.Pfyel("> Simulation interrupted. The state was not saved because there is no summary\n")
+		return
+	}
+	n := len(o.Summary.OutTimes)
+	io.Pfyel("> Simulation interrupted. The state at t = %g was saved with output index %d\n", o.Summary.OutTimes[n-1], n-1)
+	io.Pfyel("> To resume, copy stage %d and the following ones to a new .sim file and add to the first one:\n", stgidx)
+	io.Pfyel("    \"import\" : { \"dir\":%q, \"fnk\":%q }\n", o.Sim.DirOut, o.Sim.Key)
+}
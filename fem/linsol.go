@@ -0,0 +1,83 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"sync"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// LinSolver wraps a linear solver and extends it with the solution of many right-hand sides using
+// the same factorisation; e.g. by load cases, sensitivity or homogenisation drivers
+type LinSolver struct {
+	la.LinSol       // underlying linear solver
+	Concurrent bool // the solution phase can be called concurrently
+}
+
+// NewLinSolver returns a new wrapper of linear solver
+//  concurrent -- the solution phase can be called concurrently; e.g. UMFPACK (serial) because its
+//                factors are not modified by the solution phase. MUMPS, the mixed-precision solver
+//                and parallel runs must solve sequentially
+func NewLinSolver(lsol la.LinSol, concurrent bool) *LinSolver {
+	return &LinSolver{lsol, concurrent}
+}
+
+// SolveMultiR solves A x = b for many (real) right-hand sides with the factorisation of A
+//  Input:
+//   B        -- [nrhs][n] right-hand sides
+//   nworkers -- number of concurrent workers (goroutines). nworkers ≤ 1 or !Concurrent => sequential
+//  Output:
+//   X -- [nrhs][n] solutions
+//  Note: the matrix must have been factorised before
+func (o *LinSolver) SolveMultiR(X, B [][]float64, nworkers int) (err error) {
+
+	// check
+	if len(X) != len(B) {
+		return chk.Err("number of solution vectors (%d) must be equal to the number of right-hand sides (%d)", len(X), len(B))
+	}
+	for i := 0; i < len(B); i++ {
+		if len(X[i]) != len(B[i]) {
+			return chk.Err("right-hand side # %d and its solution vector must have the same size. %d != %d", i, len(B[i]), len(X[i]))
+		}
+	}
+
+	// sequential
+	if nworkers < 2 || !o.Concurrent {
+		for i := 0; i < len(B); i++ {
+			err = o.SolveR(X[i], B[i], false)
+			if err != nil {
+				return chk.Err("solve with right-hand side # %d failed:\n%v", i, err)
+			}
+		}
+		return
+	}
+
+	// concurrent
+	errs := make([]error, len(B))
+	jobs := make(chan int, len(B))
+	for i := 0; i < len(B); i++ {
+		jobs <- i
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for w := 0; w < nworkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = o.SolveR(X[i], B[i], false)
+			}
+		}()
+	}
+	wg.Wait()
+	for i, e := range errs {
+		if e != nil {
+			return chk.Err("solve with right-hand side # %d failed:\n%v", i, e)
+		}
+	}
+	return
+}
@@ -0,0 +1,232 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// Envelope holds the maximum and minimum values of a quantity among all load combinations
+type Envelope struct {
+	Name string    // name of envelope
+	Type string    // "node" or "ip"
+	Key  string    // key of dof or integration point quantity
+	Ids  []int     // [nvals] vertex ids ("node") or cell ids ("ip")
+	Ips  []int     // [nvals] indices of integration points ("ip"); -1 for nodes
+	Max  []float64 // [nvals] maximum values
+	Min  []float64 // [nvals] minimum values
+	Cmax []int     // [nvals] indices of combinations with maximum values
+	Cmin []int     // [nvals] indices of combinations with minimum values
+}
+
+// run_load_cases solves all load cases (stages) of a linear analysis by assembling and factorising
+// the stiffness matrix just once and computes the factored combinations and their envelopes
+//  Note: (1) the right-hand sides of all load cases are assembled first and then solved in one
+//            batch; the stiffness matrix is assembled with the elements of the last stage
+//        (2) the results of load cases and combinations are saved as output times (tidx);
+//            first the cases and then the combinations. Summary.LcNames holds their names
+//        (3) the states are obtained by superposition of the primary variables and Lagrange
+//            multipliers; the secondary variables (e.g. stresses) are then computed by updating
+//            the elements from the initial state (i.e. the material must be linear)
+func (o *Main) run_load_cases() (err error) {
+
+	// check
+	dat := o.Sim.LoadCases
+	if len(o.Domains) != 1 || o.Nproc > 1 {
+		return chk.Err("load cases are only available in serial runs with one domain")
+	}
+	if o.Sim.Solver.Type != "lin-imp" {
+		return chk.Err("load cases require the linear implicit solver (\"lin-imp\"). %q is invalid", o.Sim.Solver.Type)
+	}
+	if !o.Sim.Data.Steady {
+		return chk.Err("load cases are only available in steady simulations")
+	}
+	if o.Summary == nil {
+		return chk.Err("load cases require the summary to be saved")
+	}
+	d := o.Domains[0]
+	doms := []*Domain{d}
+
+	// right-hand sides of load cases
+	ncases := len(o.Sim.Stages)
+	B := make([][]float64, ncases)
+	for i, stg := range o.Sim.Stages {
+		err = o.SetStage(i)
+		if err != nil {
+			return
+		}
+		err = o.ZeroStage(i, true)
+		if err != nil {
+			return
+		}
+		if i > 0 && d.Nyb != len(B[0]) {
+			return chk.Err("load case (stage) # %d has different number of equations or constraints than load case # 0", i)
+		}
+		d.Sol.T = stg.Control.Tf
+		err = assemble_linear_rhs(d.Sol.T, d)
+		if err != nil {
+			return chk.Err("cannot assemble right-hand side of load case # %d:\n%v", i, err)
+		}
+		B[i] = make([]float64, d.Nyb)
+		copy(B[i], d.Fb)
+	}
+
+	// solve all load cases with one factorisation
+	if o.ShowMsg {
+		io.Pf("> Solving %d load cases\n", ncases)
+	}
+	err = assemble_and_fact_linear_kb(d)
+	if err != nil {
+		return
+	}
+	for i := 0; i < ncases; i++ {
+		d.EssenBcs.AddElimToRhs(B[i])
+	}
+	X := la.MatAlloc(ncases, d.Nyb)
+	err = d.LinSol.SolveMultiR(X, B, dat.Nworkers)
+	if err != nil {
+		return
+	}
+
+	// envelopes
+	envs := make([]*Envelope, len(dat.Envs))
+	for k, edat := range dat.Envs {
+		envs[k] = &Envelope{Name: edat.Name, Type: edat.Type, Key: edat.Key}
+	}
+
+	// save load cases and combinations
+	stgidx := ncases - 1
+	factors := make([]float64, ncases)
+	for j := 0; j < ncases+len(dat.Combs); j++ {
+
+		// factors and name
+		var name string
+		if j < ncases {
+			la.VecFill(factors, 0)
+			factors[j] = 1
+			name = o.Sim.Stages[j].Desc
+		} else {
+			comb := dat.Combs[j-ncases]
+			copy(factors, comb.Factors)
+			name = comb.Name
+		}
+
+		// superposition
+		err = o.ZeroStage(stgidx, true)
+		if err != nil {
+			return
+		}
+		for i := 0; i < ncases; i++ {
+			for k := 0; k < d.Ny; k++ {
+				d.Sol.Y[k] += factors[i] * X[i][k]
+			}
+			for k := 0; k < d.Nlam; k++ {
+				d.Sol.L[k] += factors[i] * X[i][d.Ny+k]
+			}
+		}
+		copy(d.Sol.ΔY, d.Sol.Y)
+		err = d.UpdateElems()
+		if err != nil {
+			return chk.Err("cannot update elements with results of %q:\n%v", name, err)
+		}
+
+		// save results
+		err = o.Summary.SaveDomains(float64(j), doms, false)
+		if err != nil {
+			return chk.Err("cannot save results of %q:\n%v", name, err)
+		}
+		o.Summary.LcNames = append(o.Summary.LcNames, name)
+
+		// update envelopes
+		if j >= ncases {
+			for k, edat := range dat.Envs {
+				err = envs[k].update(d, edat, j-ncases)
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+	o.Summary.Envelopes = envs
+	return
+}
+
+// update updates envelope with the current state of domain corresponding to combination comb
+func (o *Envelope) update(d *Domain, dat *inp.EnvelopeData, comb int) (err error) {
+
+	// current values
+	var ids, ips []int
+	var vals []float64
+	switch dat.Type {
+	case "node":
+		verts, ok := d.Msh.VertTag2verts[dat.Tag]
+		if !ok {
+			return chk.Err("envelope %q: cannot find vertices with tag = %d", dat.Name, dat.Tag)
+		}
+		for _, v := range verts {
+			if nod := d.Vid2node[v.Id]; nod != nil {
+				if eq := nod.GetEq(dat.Key); eq >= 0 {
+					ids = append(ids, v.Id)
+					ips = append(ips, -1)
+					vals = append(vals, d.Sol.Y[eq])
+				}
+			}
+		}
+	case "ip":
+		cells, ok := d.Msh.CellTag2cells[dat.Tag]
+		if !ok {
+			return chk.Err("envelope %q: cannot find cells with tag = %d", dat.Name, dat.Tag)
+		}
+		for _, c := range cells {
+			if e, isout := d.Cid2elem[c.Id].(ele.CanOutputIps); isout {
+				M := ele.NewIpsMap()
+				e.OutIpVals(M, d.Sol)
+				for idx, v := range (*M)[dat.Key] {
+					ids = append(ids, c.Id)
+					ips = append(ips, idx)
+					vals = append(vals, v)
+				}
+			}
+		}
+	default:
+		return chk.Err("envelope %q: type %q is invalid; options are \"node\" and \"ip\"", dat.Name, dat.Type)
+	}
+	if len(vals) == 0 {
+		return chk.Err("envelope %q: cannot find values of %q at %ss with tag = %d", dat.Name, dat.Key, dat.Type, dat.Tag)
+	}
+
+	// first combination
+	if o.Ids == nil {
+		o.Ids, o.Ips = ids, ips
+		o.Max = make([]float64, len(vals))
+		o.Min = make([]float64, len(vals))
+		o.Cmax = make([]int, len(vals))
+		o.Cmin = make([]int, len(vals))
+		copy(o.Max, vals)
+		copy(o.Min, vals)
+		for i := 0; i < len(vals); i++ {
+			o.Cmax[i], o.Cmin[i] = comb, comb
+		}
+		return
+	}
+
+	// update maximum and minimum values
+	if len(vals) != len(o.Max) {
+		return chk.Err("envelope %q: number of values changed among combinations", dat.Name)
+	}
+	for i, v := range vals {
+		if v > o.Max[i] {
+			o.Max[i], o.Cmax[i] = v, comb
+		}
+		if v < o.Min[i] {
+			o.Min[i], o.Cmin[i] = v, comb
+		}
+	}
+	return
+}
@@ -21,15 +21,17 @@ type DebugKb_t func(d *Domain, it int)
 
 // Main holds all data for a simulation using the finite element method
 type Main struct {
-	Sim     *inp.Simulation // simulation data
-	Summary *Summary        // summary structure
-	DynCfs  *ele.DynCoefs   // coefficients for dynamics/transient simulations
-	Domains []*Domain       // all domains
-	Solver  Solver          // finite element method solver; e.g. implicit, Richardson extrapolation, etc.
-	DebugKb DebugKb_t       // debug Kb callback function
-	Nproc   int             // number of processors
-	Proc    int             // processor id
-	ShowMsg bool            // show messages
+	Sim        *inp.Simulation // simulation data
+	Summary    *Summary        // summary structure
+	DynCfs     *ele.DynCoefs   // coefficients for dynamics/transient simulations
+	Domains    []*Domain       // all domains
+	Solver     Solver          // finite element method solver; e.g. implicit, Richardson extrapolation, etc.
+	DebugKb    DebugKb_t       // debug Kb callback function
+	Nproc      int             // number of processors
+	Proc       int             // processor id
+	ShowMsg    bool            // show messages
+	KeepLinSol bool            // keep linear solvers (factorisations) of domains after Run or SolveOneStage; e.g. to compute sensitivities. Clean must be called afterwards
+	Mon        *Monitor        // live monitoring server started by NewMain; nil if not requested. see StopMonitor
 }
 
 // NewMain returns a new Main structure
@@ -64,6 +66,17 @@ func NewMain(simfilepath, alias string, erasePrev, saveSummary, readSummary, all
 		mpi.Barrier()
 	}
 
+	// results kept in memory
+	if o.Sim.Data.InMemory {
+		if mpi.IsOn() && mpi.Size() > 1 {
+			chk.Panic("results cannot be kept in memory in parallel runs")
+		}
+		InMemory(o.Sim.DirOut, true)
+		if erasePrev {
+			ClearMemory(o.Sim.DirOut, o.Sim.Key)
+		}
+	}
+
 	// read summary of previous simulation
 	if saveSummary || readSummary {
 		o.Summary = new(Summary)
@@ -111,6 +124,21 @@ func NewMain(simfilepath, alias string, erasePrev, saveSummary, readSummary, all
 	} else {
 		chk.Panic("cannot find solver type named %q", o.Sim.Solver.Type)
 	}
+
+	// live monitoring server
+	if o.Sim.Data.Serve != "" && o.Proc == 0 {
+		mon, err := NewMonitor(o.Sim.Data.Serve, o.Domains[0], o.Sim.Data.Monitor)
+		if err != nil {
+			chk.Panic("%v", err)
+		}
+		o.Mon = mon
+		for _, d := range o.Domains {
+			d.Mon = mon
+		}
+		if o.ShowMsg {
+			io.Pf("> Live monitoring server started at http://%s\n", mon.Addr)
+		}
+	}
 	return
 }
 
@@ -119,7 +147,11 @@ func (o *Main) Run() (err error) {
 
 	// exit commands
 	cputime := time.Now()
-	defer func() { err = o.onexit(cputime, err) }()
+	defer func() { err = o.onexit(cputime, err, true) }()
+
+	// handle interruptions (SIGINT and SIGTERM)
+	stop := catch_signals(o.ShowMsg)
+	defer stop()
 
 	// plot functions
 	if o.Sim.PlotF != nil {
@@ -132,6 +164,26 @@ func (o *Main) Run() (err error) {
 		return
 	}
 
+	// load cases and combinations
+	if o.Sim.LoadCases != nil {
+		if o.ShowMsg {
+			io.Pf("> Solving load cases\n")
+		}
+		err = o.run_load_cases()
+		return
+	}
+
+	// equivalent-linear site response
+	if o.Sim.SiteResp != nil {
+		if o.ShowMsg {
+			io.Pf("> Solving equivalent-linear site response\n")
+		}
+		err = o.run_site_response()
+		if err != nil {
+			return
+		}
+	}
+
 	// message
 	if o.ShowMsg {
 		io.Pf("> Solving stages\n")
@@ -150,6 +202,9 @@ func (o *Main) Run() (err error) {
 		if err != nil {
 			return
 		}
+		if o.Domains[0].Mon != nil {
+			o.Domains[0].Mon.set_stage(stgidx)
+		}
 
 		// initialise solution vectors
 		err = o.ZeroStage(stgidx, true)
@@ -163,11 +218,26 @@ func (o *Main) Run() (err error) {
 		}
 
 		// time loop
+		stopwall := o.wall_budget(stg)
 		err = o.Solver.Run(stg.Control.Tf, stg.Control.DtFunc, stg.Control.DtoFunc, o.ShowMsg, o.DebugKb)
+		stopwall()
 		if err != nil {
+			o.resume_hint(stgidx)
 			return
 		}
+
+		// water balance report
+		o.save_water_balance(stgidx)
+
+		// hydraulic gradients report
+		o.save_piping(stgidx)
 	}
+
+	// envelopes of internal forces of members
+	o.save_member_envelopes()
+
+	// post-run checks
+	o.check_hourglass()
 	return
 }
 
@@ -213,7 +283,11 @@ func (o *Main) SolveOneStage(stgidx int, zerostage bool) (err error) {
 
 	// exit commands
 	cputime := time.Now()
-	defer func() { err = o.onexit(cputime, err) }()
+	defer func() { err = o.onexit(cputime, err, false) }()
+
+	// handle interruptions (SIGINT and SIGTERM)
+	stop := catch_signals(o.ShowMsg)
+	defer stop()
 
 	// zero stage
 	if zerostage {
@@ -224,20 +298,59 @@ func (o *Main) SolveOneStage(stgidx int, zerostage bool) (err error) {
 	}
 
 	// run
+	if o.Domains[0].Mon != nil {
+		o.Domains[0].Mon.set_stage(stgidx)
+	}
 	stg := o.Sim.Stages[stgidx]
+	stopwall := o.wall_budget(stg)
 	err = o.Solver.Run(stg.Control.Tf, stg.Control.DtFunc, stg.Control.DtoFunc, o.ShowMsg, o.DebugKb)
+	stopwall()
+	if err != nil {
+		o.resume_hint(stgidx)
+	}
 	return
 }
 
+// Clean cleans the resources of all domains; e.g. after Run or SolveOneStage with KeepLinSol
+func (o *Main) Clean() {
+	for _, d := range o.Domains {
+		d.Clean()
+	}
+}
+
+// StopMonitor stops the live monitoring server started by NewMain. It is called at the end of Run;
+// but the server keeps running after SolveOneStage (e.g. for the next stages)
+func (o *Main) StopMonitor() {
+	if o.Mon == nil {
+		return
+	}
+	o.Mon.Stop()
+	for _, d := range o.Domains {
+		if d.Mon == o.Mon {
+			d.Mon = nil
+		}
+	}
+	o.Mon = nil
+}
+
 // auxiliary //////////////////////////////////////////////////////////////////////////////////////
 
 // onexit clean domains, prints final message with simulation and cpu times and save summary
-func (o *Main) onexit(cputime time.Time, prevErr error) (err error) {
+//  last -- end of simulation (Run); the live monitoring server is stopped
+func (o *Main) onexit(cputime time.Time, prevErr error, last bool) (err error) {
 
 	// clean resources
 	o.Sim.Clean()
-	for _, d := range o.Domains {
-		d.Clean()
+	if !o.KeepLinSol {
+		o.Clean()
+	}
+
+	// tell live monitoring server that the simulation (or stage) finished
+	if len(o.Domains) > 0 && o.Domains[0].Mon != nil {
+		o.Domains[0].Mon.finish()
+	}
+	if last {
+		o.StopMonitor()
 	}
 
 	// show final message
@@ -0,0 +1,151 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// MemberEnv holds the maximum and minimum values of an internal force of a structural member over
+// all steps and stages
+type MemberEnv struct {
+	Cid  int     // cell id
+	Tag  int     // cell tag
+	Kind string  // "beam", "rod", "lining" or "anchor"
+	Key  string  // internal force; e.g. "N", "V1", "M22"
+	Max  float64 // maximum value among all stations/integration points
+	Min  float64 // minimum value among all stations/integration points
+	Tmax float64 // time of maximum value
+	Tmin float64 // time of minimum value
+	Smax int     // stage of maximum value
+	Smin int     // stage of minimum value
+}
+
+// MemberEnvelopes implements the tracking of the envelopes (maximum and minimum values) of the
+// internal forces of structural members over all steps and stages; e.g. for the design
+// verification of staged construction
+//  Note: (1) the internal forces are given by elements implementing ele.WithMemberForces:
+//            beams: N, V1 and M22 (2D) or N, M22, M11 and T00 (3D); rods: N (axial force)
+//        (2) lining elements (see Relaxation) are reported as "lining" (N is the hoop force) and
+//            prestressed rods as "anchor" (N is the anchor force)
+//        (3) the envelopes are updated after each converged time step; they are kept when
+//            elements are deactivated in later stages
+//        (4) only serial runs are supported
+type MemberEnvelopes struct {
+	Stage int                           // index of current stage
+	Envs  map[int]map[string]*MemberEnv // cell id => key => envelope
+}
+
+// NewMemberEnvelopes allocates a new MemberEnvelopes structure for domain
+func NewMemberEnvelopes(d *Domain) (o *MemberEnvelopes, err error) {
+	if d.Distr {
+		return nil, chk.Err("envelopes of internal forces are not available in parallel runs")
+	}
+	o = &MemberEnvelopes{Envs: make(map[int]map[string]*MemberEnv)}
+	return
+}
+
+// Step updates the envelopes with the state of domain after a time step has converged
+func (o *MemberEnvelopes) Step(d *Domain) {
+	for _, e := range d.Elems {
+		em, ok := e.(ele.WithMemberForces)
+		if !ok {
+			continue
+		}
+		F := em.MemberForces(d.Sol)
+		if len(F) == 0 {
+			continue
+		}
+		cid := e.Id()
+		envs, ok := o.Envs[cid]
+		if !ok {
+			envs = make(map[string]*MemberEnv)
+			o.Envs[cid] = envs
+		}
+		for key, vals := range F {
+			if len(vals) == 0 {
+				continue
+			}
+			env, ok := envs[key]
+			if !ok {
+				env = &MemberEnv{Cid: cid, Tag: d.Msh.Cells[cid].Tag, Kind: member_kind(e), Key: key}
+				env.Max, env.Min = vals[0], vals[0]
+				env.Tmax, env.Tmin = d.Sol.T, d.Sol.T
+				env.Smax, env.Smin = o.Stage, o.Stage
+				envs[key] = env
+			}
+			for _, v := range vals {
+				if v > env.Max {
+					env.Max, env.Tmax, env.Smax = v, d.Sol.T, o.Stage
+				}
+				if v < env.Min {
+					env.Min, env.Tmin, env.Smin = v, d.Sol.T, o.Stage
+				}
+			}
+		}
+	}
+}
+
+// Report returns a table with the envelopes sorted by cell id and key
+func (o *MemberEnvelopes) Report() string {
+	cids := make([]int, 0, len(o.Envs))
+	for cid, _ := range o.Envs {
+		cids = append(cids, cid)
+	}
+	sort.Ints(cids)
+	var b bytes.Buffer
+	io.Ff(&b, "%8s%8s%8s%6s%23s%23s%6s%23s%23s%6s\n", "cid", "tag", "kind", "key", "max", "tmax", "smax", "min", "tmin", "smin")
+	for _, cid := range cids {
+		keys := make([]string, 0, len(o.Envs[cid]))
+		for key, _ := range o.Envs[cid] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			r := o.Envs[cid][key]
+			io.Ff(&b, "%8d%8d%8s%6s%23.15e%23.15e%6d%23.15e%23.15e%6d\n", r.Cid, r.Tag, r.Kind, r.Key, r.Max, r.Tmax, r.Smax, r.Min, r.Tmin, r.Smin)
+		}
+	}
+	return b.String()
+}
+
+// member_kind returns the kind of structural member
+func member_kind(e ele.Element) string {
+	switch m := e.(type) {
+	case *LiningElem:
+		return "lining"
+	case *solid.Rod:
+		if m.Pre {
+			return "anchor"
+		}
+		return "rod"
+	case *solid.ElastRod:
+		return "rod"
+	}
+	return "beam"
+}
+
+// save_member_envelopes writes the envelopes of internal forces of members to files in DirOut
+func (o *Main) save_member_envelopes() {
+	if o.Proc != 0 {
+		return
+	}
+	for i, d := range o.Domains {
+		if d.Menv == nil {
+			continue
+		}
+		fn := io.Sf("%s_envelopes_d%d.res", o.Sim.Key, i)
+		io.WriteFileSD(o.Sim.DirOut, fn, d.Menv.Report())
+		if o.ShowMsg {
+			io.Pf("> Envelopes of internal forces of members of domain %d: see %s/%s\n", i, o.Sim.DirOut, fn)
+		}
+	}
+}
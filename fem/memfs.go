@@ -0,0 +1,73 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+)
+
+// memfs holds the result files (summary, nodes and elements) of simulations run in memory
+var memfs = struct {
+	sync.Mutex
+	dirs  map[string]bool   // output directories whose files are kept in memory
+	files map[string][]byte // path of file => contents
+}{
+	dirs:  make(map[string]bool),
+	files: make(map[string][]byte),
+}
+
+// InMemory sets whether the result files (summary, nodes and elements) written to dirout are kept
+// in memory instead of being saved to disk. The files are then read back transparently; e.g. by
+// the out package. This makes tests of elements and solvers faster and hermetic
+//  Note: (1) reports such as water balance or envelopes are still written to disk
+//        (2) the memory is only released by ClearMemory
+func InMemory(dirout string, on bool) {
+	memfs.Lock()
+	defer memfs.Unlock()
+	if on {
+		memfs.dirs[path.Clean(dirout)] = true
+		return
+	}
+	delete(memfs.dirs, path.Clean(dirout))
+}
+
+// ClearMemory removes the result files kept in memory of simulation fnkey in dirout
+func ClearMemory(dirout, fnkey string) {
+	memfs.Lock()
+	defer memfs.Unlock()
+	prefix := path.Join(dirout, fnkey)
+	for fn, _ := range memfs.files {
+		if strings.HasPrefix(fn, prefix) {
+			delete(memfs.files, fn)
+		}
+	}
+}
+
+// memfs_write keeps the contents of file in memory if its directory was set with InMemory.
+// Returns false if the file must be written to disk
+func memfs_write(filename string, b []byte) (ok bool) {
+	memfs.Lock()
+	defer memfs.Unlock()
+	filename = path.Clean(filename)
+	if !memfs.dirs[path.Dir(filename)] {
+		return false
+	}
+	memfs.files[filename] = append([]byte{}, b...)
+	return true
+}
+
+// read_file reads the contents of file from memory (see InMemory) or disk
+func read_file(filename string) (b []byte, err error) {
+	memfs.Lock()
+	b, ok := memfs.files[path.Clean(filename)]
+	memfs.Unlock()
+	if ok {
+		return b, nil
+	}
+	return ioutil.ReadFile(filename)
+}
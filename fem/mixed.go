@@ -0,0 +1,386 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// MixedSolver implements a mixed-precision linear solver for symmetric systems: Kb is factorised
+// in single precision (LDLᵀ with skyline storage after reverse Cuthill-McKee reordering) and the
+// solution is improved to double precision by iterative refinement with residuals computed in
+// double precision. The factors take half of the memory of double precision skyline factors. It is
+// selected with "linsol" : { "mixed" : true, "symmetric" : true }
+//  Note: (1) only serial runs with symmetric matrices are supported
+//        (2) the entries of Kb are extracted by products with probing vectors; thus the sparsity
+//            pattern is derived from the connectivity of cells and the constraints. The extracted
+//            entries are checked against a product with Kb
+//        (3) refinement converges if cond(Kb) is much smaller than 1/ε_single ≈ 1e7; otherwise
+//            SolveR returns an error. Penalised constraints may spoil the conditioning
+//        (4) Lagrange multipliers are ordered after the equations they constrain such that their
+//            (zero) diagonal entries do not produce null pivots
+//        (5) the profile of the skyline grows faster with the number of equations than the fill-in
+//            of the orderings of sparse direct solvers (e.g. nested dissection); thus the solver
+//            suits 2D meshes and banded 3D meshes (e.g. slender or layered ones) only. For large
+//            compact 3D meshes, umfpack or mumps require less memory and time
+type MixedSolver struct {
+
+	// refinement
+	Tol     float64 // tolerance: ‖b - Kb・x‖∞ ≤ Tol ‖b‖∞
+	NmaxRef int     // max number of refinement iterations
+	Nref    int     // number of refinement iterations of the last solution
+
+	// input
+	dom          *Domain      // domain
+	tK           *la.Triplet  // Kb matrix
+	K            *la.CCMatrix // compressed form of Kb (for residuals)
+	verb, timing bool         // verbose and timing flags
+
+	// symbolic data
+	groups  [][]int // [ngroups][neqs] equations of groups: dofs of nodes or Lagrange multipliers
+	gnbr    [][]int // [ngroups][nnbr] neighbour groups (including group itself)
+	colours [][]int // [ncolours][ngroups] groups of each colour (distance-2 colouring)
+	perm    []int   // [nyb] new equation => old equation
+	iperm   []int   // [nyb] old equation => new equation
+
+	// factors (skyline; new numbering)
+	fr  []int     // [nyb] first row of each column
+	ptr []int     // [nyb+1] position of the first entry of each column
+	L   []float32 // [ptr[nyb]] factors: l_ij above the diagonal and d_j on the diagonal
+
+	// workspace
+	y, dx, r []float64
+}
+
+// NewMixedSolver returns a new mixed-precision solver for domain d
+func NewMixedSolver(d *Domain) *MixedSolver {
+	return &MixedSolver{Tol: d.Sim.LinSol.RefTol, NmaxRef: d.Sim.LinSol.RefNmax, dom: d}
+}
+
+// InitR initialises the solver and carries out the symbolic analysis
+func (o *MixedSolver) InitR(tR *la.Triplet, symmetric, verbose, timing bool) (err error) {
+	d := o.dom
+	if d.Distr {
+		return chk.Err("mixed-precision solver is not available in parallel runs")
+	}
+	if !symmetric {
+		return chk.Err("mixed-precision solver requires a symmetric matrix; set \"symmetric\" : true in \"linsol\"")
+	}
+	o.tK, o.verb, o.timing = tR, verbose, timing
+	t0 := time.Now()
+	o.graph()
+	o.colours = graph_colouring(o.gnbr, true)
+	o.ordering()
+	if len(o.perm) != d.Nyb {
+		return chk.Err("mixed-precision solver cannot handle equations that do not belong to nodes or constraints. %d != %d", len(o.perm), d.Nyb)
+	}
+	o.profile()
+	n := d.Nyb
+	o.y, o.dx, o.r = make([]float64, n), make([]float64, n), make([]float64, n)
+	if o.verb {
+		io.Pforan("mixed: neq = %d  ncolours = %d  profile = %d  memory(factors) = %.1f MB\n", n, len(o.colours), len(o.L), float64(4*len(o.L))/1048576.0)
+	}
+	if o.timing {
+		io.Pforan("mixed: time spent in symbolic analysis = %v\n", time.Now().Sub(t0))
+	}
+	return
+}
+
+// InitC is not available
+func (o *MixedSolver) InitC(tC *la.TripletC, symmetric, verbose, timing bool) (err error) {
+	return chk.Err("mixed-precision solver is not available for complex systems")
+}
+
+// Fact extracts the entries of Kb and computes the single precision factors
+func (o *MixedSolver) Fact() (err error) {
+	t0 := time.Now()
+	o.K = o.tK.ToMatrix(o.K)
+	err = o.extract()
+	if err != nil {
+		return
+	}
+	err = o.factorise()
+	if o.timing {
+		io.Pforan("mixed: time spent in factorisation = %v\n", time.Now().Sub(t0))
+	}
+	return
+}
+
+// SolveR solves Kb・x = b with iterative refinement
+func (o *MixedSolver) SolveR(xR, bR []float64, sum_b_to_root bool) (err error) {
+	bnorm := la.VecLargest(bR, 1)
+	la.VecFill(xR, 0)
+	copy(o.r, bR)
+	var rnorm float64
+	for o.Nref = 1; o.Nref <= o.NmaxRef; o.Nref++ {
+		o.solve(o.dx, o.r)
+		for i := range xR {
+			xR[i] += o.dx[i]
+		}
+		la.SpMatVecMul(o.r, 1, o.K, xR) // r = K・x
+		for i := range o.r {
+			o.r[i] = bR[i] - o.r[i]
+		}
+		rnorm = la.VecLargest(o.r, 1)
+		if rnorm <= o.Tol*bnorm {
+			if o.verb {
+				io.Pforan("mixed: refinement converged after %d iterations. ‖r‖∞ = %g\n", o.Nref, rnorm)
+			}
+			return
+		}
+	}
+	return chk.Err("mixed-precision refinement did not converge after %d iterations: ‖r‖∞ = %g > %g. Kb is probably too ill-conditioned for a single precision factorisation", o.NmaxRef, rnorm, o.Tol*bnorm)
+}
+
+// SolveC is not available
+func (o *MixedSolver) SolveC(xR, xC, bR, bC []float64, sum_b_to_root bool) (err error) {
+	return chk.Err("mixed-precision solver is not available for complex systems")
+}
+
+// Clean deletes the factors
+func (o *MixedSolver) Clean() {
+	o.K, o.L, o.fr, o.ptr = nil, nil, nil, nil
+	o.groups, o.gnbr, o.colours, o.perm, o.iperm = nil, nil, nil, nil, nil
+}
+
+// SetOrdScal does nothing: the ordering is always reverse Cuthill-McKee and there is no scaling
+func (o *MixedSolver) SetOrdScal(ordering, scaling string) (err error) {
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// graph sets the groups of equations and their connectivity (see Domain.nodes_graph)
+func (o *MixedSolver) graph() {
+
+	// groups of dofs of nodes
+	d := o.dom
+	nnod := len(d.Nodes)
+	o.groups = make([][]int, nnod)
+	eq2g := make(map[int]int)
+	for g, nod := range d.Nodes {
+		for _, dof := range nod.Dofs {
+			eq2g[dof.Eq] = g
+			o.groups[g] = append(o.groups[g], dof.Eq)
+		}
+	}
+	o.gnbr = d.nodes_graph(false, d.EssenBcs.Bcs)
+	for g := range o.gnbr {
+		o.gnbr[g] = append(o.gnbr[g], g)
+	}
+
+	// Lagrange multipliers
+	for i, bc := range d.EssenBcs.Mbcs {
+		g := len(o.groups)
+		o.groups = append(o.groups, []int{d.Ny + i})
+		o.gnbr = append(o.gnbr, []int{g})
+		hs := make(map[int]bool)
+		for _, eq := range bc.Eqs {
+			hs[eq2g[eq]] = true
+		}
+		for h := range hs {
+			o.gnbr[g] = append(o.gnbr[g], h)
+			o.gnbr[h] = append(o.gnbr[h], g)
+		}
+	}
+	for g := range o.gnbr {
+		sort.Ints(o.gnbr[g])
+	}
+}
+
+// ordering computes the reverse Cuthill-McKee ordering of groups without Lagrange multipliers and
+// places each multiplier right after the last equation it constrains
+func (o *MixedSolver) ordering() {
+
+	// reverse Cuthill-McKee (groups of dofs of nodes)
+	d := o.dom
+	nnod := len(d.Nodes)
+	nodes := make([]int, nnod)
+	for g := range nodes {
+		nodes[g] = g
+	}
+	order := renum_rcm(o.gnbr, nodes)
+
+	// Lagrange multipliers after the last neighbour
+	after := make(map[int][]int)
+	pos := make([]int, nnod)
+	for p, g := range order {
+		pos[g] = p
+	}
+	for g := nnod; g < len(o.groups); g++ {
+		last := 0
+		for _, h := range o.gnbr[g] {
+			if h < nnod && pos[h] > last {
+				last = pos[h]
+			}
+		}
+		after[last] = append(after[last], g)
+	}
+
+	// permutation
+	o.perm = make([]int, 0, d.Nyb)
+	for p, g := range order {
+		o.perm = append(o.perm, o.groups[g]...)
+		for _, h := range after[p] {
+			o.perm = append(o.perm, o.groups[h]...)
+		}
+	}
+	o.iperm = make([]int, d.Nyb)
+	for inew, iold := range o.perm {
+		o.iperm[iold] = inew
+	}
+}
+
+// profile computes the skyline structure
+func (o *MixedSolver) profile() {
+	n := o.dom.Nyb
+	o.fr = make([]int, n)
+	for j := 0; j < n; j++ {
+		o.fr[j] = j
+	}
+	for g, eqs := range o.groups {
+		for _, h := range o.gnbr[g] {
+			for _, j := range eqs {
+				jn := o.iperm[j]
+				for _, i := range o.groups[h] {
+					if in := o.iperm[i]; in < o.fr[jn] {
+						o.fr[jn] = in
+					}
+				}
+			}
+		}
+	}
+	o.ptr = make([]int, n+1)
+	for j := 0; j < n; j++ {
+		o.ptr[j+1] = o.ptr[j] + j - o.fr[j] + 1
+	}
+	o.L = make([]float32, o.ptr[n])
+}
+
+// extract extracts the entries of Kb into the skyline by products with probing vectors and checks
+// the result with a product Kb・s where s is a test vector
+func (o *MixedSolver) extract() (err error) {
+	n := o.dom.Nyb
+	for i := range o.L {
+		o.L[i] = 0
+	}
+	e := make([]float64, n)
+	Ke := make([]float64, n)
+	s := make([]float64, n)
+	z := make([]float64, n)
+	for i := 0; i < n; i++ {
+		s[i] = 1.0 + float64(i%7)/7.0
+	}
+	for _, gs := range o.colours {
+		for k := 0; ; k++ {
+			found := false
+			for _, g := range gs {
+				if k < len(o.groups[g]) {
+					e[o.groups[g][k]] = 1
+					found = true
+				}
+			}
+			if !found {
+				break
+			}
+			la.SpMatVecMul(Ke, 1, o.K, e) // Ke = K・e
+			for _, g := range gs {
+				if k >= len(o.groups[g]) {
+					continue
+				}
+				j := o.groups[g][k]
+				e[j] = 0
+				jn := o.iperm[j]
+				for _, h := range o.gnbr[g] {
+					for _, i := range o.groups[h] {
+						z[i] += Ke[i] * s[j]
+						if in := o.iperm[i]; in <= jn {
+							o.L[o.ptr[jn]+in-o.fr[jn]] = float32(Ke[i])
+						}
+					}
+				}
+			}
+		}
+	}
+	la.SpMatVecMul(Ke, 1, o.K, s) // Ke = K・s
+	var diff float64
+	for i := 0; i < n; i++ {
+		diff = math.Max(diff, math.Abs(Ke[i]-z[i]))
+	}
+	if diff > 1e-10*(1.0+la.VecLargest(Ke, 1)) {
+		return chk.Err("mixed-precision solver cannot extract the entries of Kb: unknown couplings between equations. max difference = %g", diff)
+	}
+	return
+}
+
+// factorise computes the LDLᵀ factorisation in place. The factors are stored in single precision
+// but the sums are computed in double precision
+func (o *MixedSolver) factorise() (err error) {
+	L, fr, ptr := o.L, o.fr, o.ptr
+	for j := 0; j < len(fr); j++ {
+		pj, fj := ptr[j], fr[j]
+
+		// g_ij = a_ij - Σ l_ki g_kj
+		for i := fj; i < j; i++ {
+			pi, fi := ptr[i], fr[i]
+			m := fi
+			if fj > m {
+				m = fj
+			}
+			sum := float64(L[pj+i-fj])
+			for k := m; k < i; k++ {
+				sum -= float64(L[pi+k-fi]) * float64(L[pj+k-fj])
+			}
+			L[pj+i-fj] = float32(sum)
+		}
+
+		// l_ij = g_ij / d_i and d_j = a_jj - Σ l_ij g_ij
+		djj := float64(L[pj+j-fj])
+		for i := fj; i < j; i++ {
+			g := float64(L[pj+i-fj])
+			l := g / float64(L[ptr[i+1]-1])
+			djj -= l * g
+			L[pj+i-fj] = float32(l)
+		}
+		if djj == 0 || math.IsNaN(djj) || math.IsInf(djj, 0) {
+			return chk.Err("mixed-precision factorisation failed: null pivot at equation %d. The constraints may be redundant", o.perm[j])
+		}
+		L[pj+j-fj] = float32(djj)
+	}
+	return
+}
+
+// solve solves (L・D・Lᵀ)・x = b with the single precision factors
+func (o *MixedSolver) solve(x, b []float64) {
+	L, fr, ptr, y := o.L, o.fr, o.ptr, o.y
+	n := len(fr)
+	for j := 0; j < n; j++ {
+		y[j] = b[o.perm[j]]
+	}
+	for j := 0; j < n; j++ {
+		sum := y[j]
+		for i := fr[j]; i < j; i++ {
+			sum -= float64(L[ptr[j]+i-fr[j]]) * y[i]
+		}
+		y[j] = sum
+	}
+	for j := 0; j < n; j++ {
+		y[j] /= float64(L[ptr[j+1]-1])
+	}
+	for j := n - 1; j >= 0; j-- {
+		for i := fr[j]; i < j; i++ {
+			y[i] -= float64(L[ptr[j]+i-fr[j]]) * y[j]
+		}
+	}
+	for j := 0; j < n; j++ {
+		x[o.perm[j]] = y[j]
+	}
+}
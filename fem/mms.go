@@ -0,0 +1,331 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gofem/ele/diffusion"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/inp"
+	mdldiffusion "github.com/cpmech/gofem/mdl/diffusion"
+	mdlsolid "github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// Mms implements the method of manufactured solutions. See inp.MmsData
+//  Note: (1) the source terms are computed by central differences of the exact solution:
+//             diffusion elements:    s = ρ・∂u/∂t - ∇・(kval(u)・kcte・∇u)
+//             linear elastic solids: b = ρ・∂²u/∂t² - ∇・σ(u) with σ = λ・tr(ε)・I + 2・G・ε
+//        (2) source terms of plane-stress and axisymmetric problems are not available
+type Mms struct {
+	Dat   *inp.MmsData // input data
+	Exact []fun.Func   // [nkeys] exact solution of each key
+	Delta float64      // relative step of numerical derivatives
+	Nodes []*Node      // nodes on boundary of mesh (with prescribed exact solution)
+}
+
+// NewMms allocates a new Mms structure, sets the source terms of all elements and prescribes the
+// exact solution at the nodes on the boundary of the mesh
+func NewMms(d *Domain, dat *inp.MmsData) (o *Mms, err error) {
+
+	// check
+	if len(dat.Keys) < 1 || len(dat.Keys) != len(dat.Fcns) {
+		return nil, chk.Err("the numbers of keys and functions of exact solution must be equal and greater than zero. %d != %d", len(dat.Keys), len(dat.Fcns))
+	}
+
+	// exact solution
+	o = new(Mms)
+	o.Dat = dat
+	o.Delta = dat.Delta
+	if o.Delta <= 0 {
+		o.Delta = 1e-4
+	}
+	o.Exact = make([]fun.Func, len(dat.Keys))
+	for i, name := range dat.Fcns {
+		o.Exact[i], err = d.Sim.Functions.Get(name)
+		if err != nil {
+			return
+		}
+	}
+
+	// source terms
+	if !dat.NoSrc {
+		err = o.set_sources(d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// nodes on boundary: faces that are not shared by two active cells
+	faces := make(map[string]int)
+	for _, c := range d.Msh.Cells {
+		if d.Cid2elem[c.Id] == nil || !c.IsSolid {
+			continue
+		}
+		for fid := range c.Shp.FaceLocalVerts {
+			faces[mms_face_key(c, fid)]++
+		}
+	}
+	added := make(map[int]bool)
+	for _, c := range d.Msh.Cells {
+		if d.Cid2elem[c.Id] == nil || !c.IsSolid {
+			continue
+		}
+		for fid, lverts := range c.Shp.FaceLocalVerts {
+			if faces[mms_face_key(c, fid)] > 1 {
+				continue
+			}
+			for _, l := range lverts {
+				vid := c.Verts[l]
+				if added[vid] || d.Vid2node[vid] == nil {
+					continue
+				}
+				added[vid] = true
+				o.Nodes = append(o.Nodes, d.Vid2node[vid])
+			}
+		}
+	}
+
+	// prescribe exact solution
+	for _, nod := range o.Nodes {
+		x := nod.Vert.C
+		for i, key := range dat.Keys {
+			if nod.GetDof(key) == nil {
+				continue
+			}
+			f := o.Exact[i]
+			err = d.EssenBcs.Set(key, []*Node{nod}, o.newfcn(func(t float64, _ []float64) float64 { return f.F(t, x) }), "")
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Errors computes the L2 norms of the errors (difference between numerical and exact solutions)
+// of each key and the characteristic size of cells; i.e. h = (volume / ncells)^(1/ndim)
+func (o *Mms) Errors(d *Domain) (errs []float64, h float64, err error) {
+	errs = make([]float64, len(o.Dat.Keys))
+	var vol float64
+	var ncells int
+	for _, c := range d.Msh.Cells {
+		sh := c.Shp
+		if d.Cid2elem[c.Id] == nil || !c.IsSolid || sh == nil || sh.Nurbs != nil {
+			continue
+		}
+		ncells++
+		eqs := make([][]int, len(o.Dat.Keys))
+		for k, key := range o.Dat.Keys {
+			eqs[k] = make([]int, sh.Nverts)
+			for m := 0; m < sh.Nverts; m++ {
+				eqs[k][m] = d.Vid2node[c.Verts[m]].GetEq(key)
+				if eqs[k][m] < 0 {
+					return nil, 0, chk.Err("cannot compute error of %q: vertex # %d of cell # %d does not have this dof", key, c.Verts[m], c.Id)
+				}
+			}
+		}
+		X := d.cell_coords(c, sh.Nverts)
+		ips, _, err := sh.GetIps(0, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		x := make([]float64, d.Msh.Ndim)
+		for _, ip := range ips {
+			err = sh.CalcAtIp(X, ip, true)
+			if err != nil {
+				return nil, 0, err
+			}
+			coef := ip[3] * sh.J
+			for i := 0; i < d.Msh.Ndim; i++ {
+				x[i] = 0
+				for m := 0; m < sh.Nverts; m++ {
+					x[i] += sh.S[m] * X[i][m]
+				}
+			}
+			for k := range o.Dat.Keys {
+				var uh float64
+				for m := 0; m < sh.Nverts; m++ {
+					uh += sh.S[m] * d.Sol.Y[eqs[k][m]]
+				}
+				e := uh - o.Exact[k].F(d.Sol.T, x)
+				errs[k] += coef * e * e
+			}
+			vol += coef
+		}
+	}
+	if ncells == 0 {
+		return nil, 0, chk.Err("cannot compute errors without active cells")
+	}
+	for k := range errs {
+		errs[k] = math.Sqrt(errs[k])
+	}
+	h = math.Pow(vol/float64(ncells), 1.0/float64(d.Msh.Ndim))
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// exact returns the exact solution of key; nil if not given
+func (o *Mms) exact(key string) fun.Func {
+	for i, k := range o.Dat.Keys {
+		if k == key {
+			return o.Exact[i]
+		}
+	}
+	return nil
+}
+
+// set_sources sets the source terms of all elements
+func (o *Mms) set_sources(d *Domain) (err error) {
+	if d.Sim.Data.Axisym || d.Sim.Data.Pstress {
+		return chk.Err("source terms are not available for axisymmetric and plane-stress problems")
+	}
+	for _, e := range d.Elems {
+		switch e := e.(type) {
+		case *diffusion.Diffusion:
+			u := o.exact("u")
+			if u == nil {
+				return chk.Err("exact solution of \"u\" is required by diffusion element # %d", e.Id())
+			}
+			err = e.SetEleConds("s", o.diffusion_source(e.Mdl, u), "")
+			if err != nil {
+				return
+			}
+		case *solid.Solid:
+			mdl, ok := e.Mdl.(*mdlsolid.LinElast)
+			if !ok {
+				return chk.Err("manufactured solutions of solid elements require linear elastic materials. element # %d has a different model", e.Id())
+			}
+			u := make([]fun.Func, e.Ndim)
+			for i := 0; i < e.Ndim; i++ {
+				u[i] = o.exact(io.Sf("u%c", 'x'+i))
+				if u[i] == nil {
+					return chk.Err("exact solution of %q is required by solid element # %d", io.Sf("u%c", 'x'+i), e.Id())
+				}
+			}
+			qsta := d.Sim.Data.Steady || e.Qsta
+			for i := 0; i < e.Ndim; i++ {
+				err = e.SetEleConds(io.Sf("b%c", 'x'+i), o.solid_body_force(mdl, u, i, qsta), "")
+				if err != nil {
+					return
+				}
+			}
+		default:
+			return chk.Err("manufactured solutions are not available for element %T", e)
+		}
+	}
+	return
+}
+
+// diffusion_source returns the source term of diffusion elements
+func (o *Mms) diffusion_source(mdl *mdldiffusion.M1, u fun.Func) fun.Func {
+	return o.newfcn(func(t float64, x []float64) (s float64) {
+		ndim := len(x)
+		y := make([]float64, ndim)
+		copy(y, x)
+		s = mdl.Rho * u.G(t, y)
+		for i := 0; i < ndim; i++ {
+			s += o.deriv(func(z []float64) (wi float64) { // w = -kval(u)・kcte・∇u
+				kval := mdl.Kval(u.F(t, z))
+				for j := 0; j < ndim; j++ {
+					wi -= kval * mdl.Kcte[i][j] * o.deriv(func(v []float64) float64 { return u.F(t, v) }, z, j)
+				}
+				return
+			}, y, i)
+		}
+		return
+	})
+}
+
+// solid_body_force returns the component i of the body force of linear elastic solid elements
+func (o *Mms) solid_body_force(mdl *mdlsolid.LinElast, u []fun.Func, i int, qsta bool) fun.Func {
+	return o.newfcn(func(t float64, x []float64) (b float64) {
+		ndim := len(x)
+		y := make([]float64, ndim)
+		copy(y, x)
+		if !qsta {
+			b = mdl.GetRho() * u[i].H(t, y)
+		}
+		for j := 0; j < ndim; j++ {
+			b -= o.deriv(func(z []float64) (σij float64) {
+				grad := func(k, l int) float64 { // ∂u_k/∂x_l
+					return o.deriv(func(v []float64) float64 { return u[k].F(t, v) }, z, l)
+				}
+				if i == j {
+					for k := 0; k < ndim; k++ {
+						σij += mdl.L * grad(k, k)
+					}
+				}
+				σij += mdl.G * (grad(i, j) + grad(j, i))
+				return
+			}, y, j)
+		}
+		return
+	})
+}
+
+// deriv computes ∂f/∂x_i by central differences
+func (o *Mms) deriv(f func(x []float64) float64, x []float64, i int) float64 {
+	xi := x[i]
+	h := o.Delta * math.Max(1, math.Abs(xi))
+	x[i] = xi + h
+	fp := f(x)
+	x[i] = xi - h
+	fm := f(x)
+	x[i] = xi
+	return (fp - fm) / (2.0 * h)
+}
+
+// newfcn returns a function y(t,x) whose time derivatives are computed by central differences
+func (o *Mms) newfcn(f func(t float64, x []float64) float64) fun.Func {
+	return &mms_fcn{f, o.Delta}
+}
+
+// mms_fcn implements fun.Func for exact solutions at nodes and source terms
+type mms_fcn struct {
+	f func(t float64, x []float64) float64
+	δ float64
+}
+
+func (o *mms_fcn) Init(prms fun.Prms) error         { return nil }
+func (o *mms_fcn) F(t float64, x []float64) float64 { return o.f(t, x) }
+
+func (o *mms_fcn) G(t float64, x []float64) float64 {
+	return (o.f(t+o.δ, x) - o.f(t-o.δ, x)) / (2.0 * o.δ)
+}
+
+func (o *mms_fcn) H(t float64, x []float64) float64 {
+	return (o.f(t+o.δ, x) - 2.0*o.f(t, x) + o.f(t-o.δ, x)) / (o.δ * o.δ)
+}
+
+func (o *mms_fcn) Grad(v []float64, t float64, x []float64) {
+	y := make([]float64, len(x))
+	copy(y, x)
+	for i := range y {
+		xi := y[i]
+		y[i] = xi + o.δ
+		fp := o.f(t, y)
+		y[i] = xi - o.δ
+		fm := o.f(t, y)
+		y[i] = xi
+		v[i] = (fp - fm) / (2.0 * o.δ)
+	}
+}
+
+// mms_face_key returns a key identifying face fid of cell c by its (sorted) vertices
+func mms_face_key(c *inp.Cell, fid int) string {
+	lverts := c.Shp.FaceLocalVerts[fid]
+	vids := make([]int, len(lverts))
+	for k, l := range lverts {
+		vids[k] = c.Verts[l]
+	}
+	sort.Ints(vids)
+	return io.Sf("%v", vids)
+}
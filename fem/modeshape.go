@@ -0,0 +1,73 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// SaveModeShape writes the displacements at vertices to a file (dirout/fn) in the table format read
+// by inp.ModeData; e.g. to seed the geometric imperfections of a subsequent nonlinear simulation
+// with the shape of a buckling mode
+//  Y0 -- reference solution; if not nil, the shape is computed with Y - Y0; e.g. the increment of
+//        displacements close to the limit load. may be nil
+//  Note: only serial runs are supported
+func (o *Domain) SaveModeShape(dirout, fn string, Y0 []float64) (err error) {
+
+	// check
+	if o.Distr {
+		return chk.Err("mode shapes cannot be saved in parallel runs")
+	}
+	if Y0 != nil && len(Y0) != len(o.Sol.Y) {
+		return chk.Err("reference solution must have the same size as Y. %d != %d", len(Y0), len(o.Sol.Y))
+	}
+
+	// header
+	ndim := o.Msh.Ndim
+	ukeys := []string{"ux", "uy", "uz"}[:ndim]
+	var b bytes.Buffer
+	io.Ff(&b, "%8s", "id")
+	for _, key := range ukeys {
+		io.Ff(&b, "%23s", key)
+	}
+	io.Ff(&b, "\n")
+
+	// displacements
+	nrow := 0
+	for _, v := range o.Msh.Verts {
+		nod := o.Vid2node[v.Id]
+		if nod == nil {
+			continue
+		}
+		eqs := make([]int, ndim)
+		for i, key := range ukeys {
+			eqs[i] = nod.GetEq(key)
+		}
+		if eqs[0] < 0 {
+			continue
+		}
+		io.Ff(&b, "%8d", v.Id)
+		for _, eq := range eqs {
+			var u float64
+			if eq >= 0 {
+				u = o.Sol.Y[eq]
+				if Y0 != nil {
+					u -= Y0[eq]
+				}
+			}
+			io.Ff(&b, "%23.15e", u)
+		}
+		io.Ff(&b, "\n")
+		nrow++
+	}
+	if nrow == 0 {
+		return chk.Err("cannot find displacements to save mode shape")
+	}
+	io.WriteFileSD(dirout, fn, b.String())
+	return
+}
@@ -0,0 +1,293 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// MonNmax is the maximum number of items in the histories kept by the live monitoring server
+var MonNmax = 10000
+
+// MonFloats holds a history of values. It is written to JSON with null in place of NaN and ±Inf
+// (e.g. residuals of diverging iterations) which cannot be represented in JSON
+type MonFloats []float64
+
+// MarshalJSON writes the values replacing non-finite ones by null
+func (o MonFloats) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("null"), nil
+	}
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for i, v := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			b.WriteString("null")
+			continue
+		}
+		b.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	b.WriteByte(']')
+	return b.Bytes(), nil
+}
+
+// MonPoint holds the history of dofs at a monitor point
+type MonPoint struct {
+	Vid  int                  `json:"vid"`  // vertex id
+	X    []float64            `json:"x"`    // coordinates
+	Vals map[string]MonFloats `json:"vals"` // [nkeys][nsteps] values of dofs at each time in MonStatus.Times
+}
+
+// MonStatus holds the current status of a simulation as served by the live monitoring server
+type MonStatus struct {
+	Stage  int         `json:"stage"`  // current stage index
+	Step   int         `json:"step"`   // number of converged steps (all stages)
+	Time   float64     `json:"time"`   // current time
+	Dt     float64     `json:"dt"`     // last time increment
+	Done   bool        `json:"done"`   // simulation finished
+	Resids MonFloats   `json:"resids"` // largest absolute component of residual vector at all iterations
+	Iters  []int       `json:"iters"`  // number of iterations of each step
+	Times  MonFloats   `json:"times"`  // times of all converged steps
+	Points []*MonPoint `json:"points"` // monitor points
+}
+
+// Monitor implements a lightweight HTTP server that serves the current status of a simulation such
+// that long runs (e.g. on clusters) can be checked from a browser. Endpoints:
+//   /           -- MonStatus as JSON
+//   /resid.svg  -- log10 of residuals versus iteration number (all steps)
+//   /points.svg -- dofs at monitor points versus time
+//  Note: (1) the histories are limited to the last MonNmax items
+//        (2) only the monitor points in the domains of the root processor are served
+//        (3) non-finite values are served as null (see MonFloats)
+type Monitor struct {
+	Addr     string       // address of server; e.g. "localhost:8080"
+	mutex    sync.Mutex   // protects status
+	status   MonStatus    // current status
+	listener net.Listener // network listener
+}
+
+// NewMonitor starts the live monitoring server at addr (e.g. "localhost:8080" or ":8080"). vids are
+// the ids of vertices (monitor points) of domain d whose dofs are served
+func NewMonitor(addr string, d *Domain, vids []int) (o *Monitor, err error) {
+	o = new(Monitor)
+	for _, vid := range vids {
+		if vid < 0 || vid >= len(d.Msh.Verts) {
+			return nil, chk.Err("cannot find monitor point: vertex id = %d is invalid", vid)
+		}
+		o.status.Points = append(o.status.Points, &MonPoint{vid, d.Msh.Verts[vid].C, make(map[string]MonFloats)})
+	}
+	o.listener, err = net.Listen("tcp", addr)
+	if err != nil {
+		return nil, chk.Err("cannot start live monitoring server:\n%v", err)
+	}
+	o.Addr = o.listener.Addr().String()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", o.serve_status)
+	mux.HandleFunc("/resid.svg", o.serve_resid)
+	mux.HandleFunc("/points.svg", o.serve_points)
+	go http.Serve(o.listener, mux)
+	return
+}
+
+// Stop flags that the simulation finished and closes the server
+func (o *Monitor) Stop() {
+	o.finish()
+	o.listener.Close()
+}
+
+// Status returns a (deep) copy of the current status
+func (o *Monitor) Status() (s MonStatus) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	s = o.status
+	s.Resids = append(MonFloats{}, o.status.Resids...)
+	s.Iters = append([]int{}, o.status.Iters...)
+	s.Times = append(MonFloats{}, o.status.Times...)
+	s.Points = make([]*MonPoint, len(o.status.Points))
+	for i, p := range o.status.Points {
+		s.Points[i] = &MonPoint{p.Vid, append([]float64{}, p.X...), make(map[string]MonFloats)}
+		for key, vals := range p.Vals {
+			s.Points[i].Vals[key] = append(MonFloats{}, vals...)
+		}
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// set_stage sets the current stage index
+func (o *Monitor) set_stage(stgidx int) {
+	o.mutex.Lock()
+	o.status.Stage = stgidx
+	o.status.Done = false
+	o.mutex.Unlock()
+}
+
+// finish flags that the simulation (or stage; see Main.SolveOneStage) finished
+func (o *Monitor) finish() {
+	o.mutex.Lock()
+	o.status.Done = true
+	o.mutex.Unlock()
+}
+
+// resid records the largest component of the residual vector
+func (o *Monitor) resid(largFb float64) {
+	o.mutex.Lock()
+	o.status.Resids = mon_append(o.status.Resids, largFb)
+	o.mutex.Unlock()
+}
+
+// step records the status after a converged step
+func (o *Monitor) step(d *Domain) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	s := &o.status
+	s.Step++
+	s.Time = d.Sol.T
+	s.Dt = d.Sol.Dt
+	s.Iters = append(s.Iters, d.Nit)
+	if len(s.Iters) > MonNmax {
+		s.Iters = s.Iters[1:]
+	}
+	s.Times = mon_append(s.Times, d.Sol.T)
+	for _, p := range s.Points {
+		nod := d.Vid2node[p.Vid]
+		if nod == nil {
+			continue
+		}
+		for _, dof := range nod.Dofs {
+			if dof != nil {
+				p.Vals[dof.Key] = mon_append(p.Vals[dof.Key], d.Sol.Y[dof.Eq])
+			}
+		}
+	}
+}
+
+// serve_status writes the status as JSON
+func (o *Monitor) serve_status(w http.ResponseWriter, r *http.Request) {
+	o.mutex.Lock()
+	b, err := json.MarshalIndent(&o.status, "", "  ")
+	o.mutex.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// serve_resid plots the residuals
+func (o *Monitor) serve_resid(w http.ResponseWriter, r *http.Request) {
+	o.mutex.Lock()
+	y := make([]float64, len(o.status.Resids))
+	x := make([]float64, len(y))
+	for i, v := range o.status.Resids {
+		x[i] = float64(i)
+		y[i] = math.Log10(v + 1e-300)
+	}
+	o.mutex.Unlock()
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(mon_svg("log10(largest residual) versus iteration", []string{"resid"}, [][]float64{x}, [][]float64{y}))
+}
+
+// serve_points plots the dofs at monitor points
+func (o *Monitor) serve_points(w http.ResponseWriter, r *http.Request) {
+	o.mutex.Lock()
+	var labels []string
+	var X, Y [][]float64
+	for _, p := range o.status.Points {
+		keys := make([]string, 0, len(p.Vals))
+		for key := range p.Vals {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			vals := p.Vals[key]
+			n := len(vals)
+			labels = append(labels, io.Sf("%s @ %d", key, p.Vid))
+			X = append(X, append([]float64{}, o.status.Times[len(o.status.Times)-n:]...))
+			Y = append(Y, append([]float64{}, vals...))
+		}
+	}
+	o.mutex.Unlock()
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(mon_svg("dofs at monitor points versus time", labels, X, Y))
+}
+
+// mon_append appends v to s keeping the last MonNmax items
+func mon_append(s []float64, v float64) []float64 {
+	s = append(s, v)
+	if len(s) > MonNmax {
+		s = s[1:]
+	}
+	return s
+}
+
+// mon_colors holds the colors of curves in SVG plots
+var mon_colors = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f"}
+
+// mon_svg returns a simple SVG plot of the curves X[i] versus Y[i]. Non-finite points are skipped
+func mon_svg(title string, labels []string, X, Y [][]float64) []byte {
+	W, H, m := 640.0, 400.0, 50.0
+	xmin, xmax, ymin, ymax := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	finite := func(x, y float64) bool {
+		return !math.IsNaN(x) && !math.IsInf(x, 0) && !math.IsNaN(y) && !math.IsInf(y, 0)
+	}
+	for i := range X {
+		for j := range X[i] {
+			if !finite(X[i][j], Y[i][j]) {
+				continue
+			}
+			xmin, xmax = math.Min(xmin, X[i][j]), math.Max(xmax, X[i][j])
+			ymin, ymax = math.Min(ymin, Y[i][j]), math.Max(ymax, Y[i][j])
+		}
+	}
+	if xmax-xmin <= 0 {
+		xmin, xmax = xmin-1, xmin+1
+	}
+	if ymax-ymin <= 0 {
+		ymin, ymax = ymin-1, ymin+1
+	}
+	if math.IsInf(xmin, 0) {
+		xmin, xmax, ymin, ymax = 0, 1, 0, 1
+	}
+	sx := func(x float64) float64 { return m + (W-2*m)*(x-xmin)/(xmax-xmin) }
+	sy := func(y float64) float64 { return H - m - (H-2*m)*(y-ymin)/(ymax-ymin) }
+	var b bytes.Buffer
+	io.Ff(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" font-family=\"sans-serif\" font-size=\"11\">\n", W, H)
+	io.Ff(&b, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"none\" stroke=\"black\"/>\n", m, m, W-2*m, H-2*m)
+	io.Ff(&b, "<text x=\"%g\" y=\"%g\" text-anchor=\"middle\" font-size=\"13\">%s</text>\n", W/2, m/2, title)
+	io.Ff(&b, "<text x=\"%g\" y=\"%g\" text-anchor=\"middle\">%g</text>\n", m, H-m+15, xmin)
+	io.Ff(&b, "<text x=\"%g\" y=\"%g\" text-anchor=\"middle\">%g</text>\n", W-m, H-m+15, xmax)
+	io.Ff(&b, "<text x=\"%g\" y=\"%g\" text-anchor=\"end\">%.4g</text>\n", m-4, H-m, ymin)
+	io.Ff(&b, "<text x=\"%g\" y=\"%g\" text-anchor=\"end\">%.4g</text>\n", m-4, m+4, ymax)
+	for i := range X {
+		color := mon_colors[i%len(mon_colors)]
+		io.Ff(&b, "<polyline fill=\"none\" stroke=\"%s\" points=\"", color)
+		for j := range X[i] {
+			if !finite(X[i][j], Y[i][j]) {
+				continue
+			}
+			io.Ff(&b, "%.2f,%.2f ", sx(X[i][j]), sy(Y[i][j]))
+		}
+		io.Ff(&b, "\"/>\n")
+		io.Ff(&b, "<text x=\"%g\" y=\"%g\" fill=\"%s\">%s</text>\n", W-m+4, m+12*float64(i+1), color, labels[i])
+	}
+	io.Ff(&b, "</svg>\n")
+	return b.Bytes()
+}
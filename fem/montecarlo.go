@@ -0,0 +1,385 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/mpi"
+	"github.com/cpmech/gosl/rnd"
+)
+
+// MonteCarlo runs many realisations of a simulation with random parameters (adjustable parameters
+// with probability distributions) and random fields; and computes statistics of responses
+//  Note: (1) each realisation re-reads the simulation file; thus, the initial state is clean
+//        (2) with MPI, the realisations are distributed among processors; each FE simulation is
+//            then run in serial mode
+//        (3) the random numbers of each realisation are generated with seed = Seed + realisation
+//            index; thus, results are reproducible regardless the number of processors if Seed > 0
+type MonteCarlo struct {
+	Simfile string              // simulation filename with full path
+	Dat     *inp.MonteCarloData // Monte Carlo data
+	Verbose bool                // show messages
+	Determ  bool                // deterministic reductions; see inp.SolverData.Determ
+
+	// results
+	Vals  [][]float64 // [nreal][nresp] responses
+	Ran   []bool      // [nreal] realisation was run successfully
+	Fails [][]bool    // [nreal][nresp] limit criterion of response was reached
+	Stats []*MCStat   // [nresp] statistics of responses
+	Pf    float64     // probability of failure (system); i.e. any limit criterion is reached
+	Nran  int         // number of realisations run successfully
+}
+
+// MCStat holds statistics of a response quantity
+type MCStat struct {
+	Name   string    // name of response
+	Mean   float64   // mean value
+	Std    float64   // standard deviation
+	Cov    float64   // coefficient of variation = Std / |Mean|
+	Pf     float64   // probability of failure according to limit criterion of response
+	Sorted []float64 // sorted values (among realisations run successfully) to compute empirical CDF
+}
+
+// NewMonteCarlo returns a new Monte Carlo structure
+func NewMonteCarlo(simfile string, verbose bool) (o *MonteCarlo, err error) {
+	sim := inp.ReadSim(simfile, "", false, 0)
+	if sim == nil {
+		return nil, chk.Err("cannot read simulation file %q", simfile)
+	}
+	if sim.MonteCarlo == nil {
+		return nil, chk.Err("simulation file %q does not have Monte Carlo data (montecarlo)", simfile)
+	}
+	if len(sim.MonteCarlo.Resps) == 0 {
+		return nil, chk.Err("at least one response quantity must be given in Monte Carlo data")
+	}
+	if len(sim.AdjRandom) == 0 && len(sim.RandFields) == 0 {
+		return nil, chk.Err("simulation file %q has neither random parameters nor random fields", simfile)
+	}
+	o = new(MonteCarlo)
+	o.Simfile = simfile
+	o.Dat = sim.MonteCarlo
+	o.Verbose = verbose
+	o.Determ = sim.Solver.Determ
+	return
+}
+
+// Run runs all realisations and computes statistics
+func (o *MonteCarlo) Run() (err error) {
+
+	// seed
+	seed := int64(o.Dat.Seed)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	// run realisations
+	nreal, nresp := o.Dat.Nreal, len(o.Dat.Resps)
+	o.Vals, o.Ran = run_samples(o.Simfile, "mc", nreal, o.Dat.Resps, o.Verbose, o.Determ, func(r int, sim *inp.Simulation) error {
+
+		// sample random parameters
+		rng := rand.New(rand.NewSource(seed + int64(r)))
+		for _, dat := range sim.AdjRandom {
+			val, err := mc_sample(rng, dat)
+			if err != nil {
+				return err
+			}
+			sim.PrmAdjust(dat.Prm.Adj, val)
+		}
+
+		// seeds of random fields
+		for _, fld := range sim.RandFields {
+			fld.Seed = 1 + rng.Intn(math.MaxInt32)
+		}
+		return nil
+	})
+
+	// collect results
+	o.Fails = make([][]bool, nreal)
+	o.Nran = 0
+	for r := 0; r < nreal; r++ {
+		o.Fails[r] = make([]bool, nresp)
+		if o.Ran[r] {
+			o.Nran++
+		}
+		for j, resp := range o.Dat.Resps {
+			if !o.Ran[r] {
+				o.Fails[r][j] = o.Dat.DvgFail && resp.Fail != ""
+				continue
+			}
+			switch resp.Fail {
+			case "above":
+				o.Fails[r][j] = o.Vals[r][j] > resp.Lim
+			case "below":
+				o.Fails[r][j] = o.Vals[r][j] < resp.Lim
+			}
+		}
+	}
+	if o.Nran == 0 {
+		return chk.Err("all %d realisations failed", nreal)
+	}
+
+	// statistics
+	o.calc_stats()
+	return
+}
+
+// Report returns a report with statistics of responses
+func (o *MonteCarlo) Report() string {
+	var b bytes.Buffer
+	io.Ff(&b, "number of realisations = %d (run successfully = %d)\n", len(o.Vals), o.Nran)
+	io.Ff(&b, "%16s%16s%16s%16s%16s\n", "response", "mean", "std", "cov", "Pf")
+	for _, s := range o.Stats {
+		io.Ff(&b, "%16s%16g%16g%16g%16g\n", s.Name, s.Mean, s.Std, s.Cov, s.Pf)
+	}
+	io.Ff(&b, "probability of failure (system) = %g\n", o.Pf)
+	return b.String()
+}
+
+// Save saves the table with responses of all realisations (key_mc.res) and the empirical CDF of
+// each response (key_mc_cdf_name.res)
+func (o *MonteCarlo) Save(dirout, fnkey string) {
+	var b bytes.Buffer
+	io.Ff(&b, "%8s%6s", "real", "ran")
+	for _, resp := range o.Dat.Resps {
+		io.Ff(&b, "%23s", resp.Name)
+	}
+	io.Ff(&b, "\n")
+	for r, vals := range o.Vals {
+		io.Ff(&b, "%8d%6v", r, o.Ran[r])
+		for _, v := range vals {
+			io.Ff(&b, "%23.15e", v)
+		}
+		io.Ff(&b, "\n")
+	}
+	io.WriteFileVD(dirout, fnkey+"_mc.res", &b)
+	for _, s := range o.Stats {
+		var c bytes.Buffer
+		io.Ff(&c, "%23s%23s\n", s.Name, "F")
+		n := float64(len(s.Sorted))
+		for i, v := range s.Sorted {
+			io.Ff(&c, "%23.15e%23.15e\n", v, float64(i+1)/n)
+		}
+		io.WriteFileVD(dirout, io.Sf("%s_mc_cdf_%s.res", fnkey, s.Name), &c)
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// run_samples runs n simulations (samples) and collects the response quantities
+//  Input:
+//   simfile -- simulation filename with full path
+//   prefix  -- prefix of alias of each sample; e.g. "mc" => keys of results are simkey-mc0, ...
+//   n       -- number of samples
+//   resps   -- response quantities
+//   verbose -- show messages
+//   determ  -- deterministic reductions; see inp.SolverData.Determ
+//   set     -- function to set parameters of sample i; called before the models are re-initialised
+//  Output:
+//   vals -- [n][nresp] responses
+//   ran  -- [n] sample was run successfully
+//  Note: with MPI, the samples are distributed among processors and the results are joined
+func run_samples(simfile, prefix string, n int, resps []*inp.MCRespData, verbose, determ bool, set func(i int, sim *inp.Simulation) error) (vals [][]float64, ran []bool) {
+
+	// processors
+	nproc, proc := 1, 0
+	if mpi.IsOn() {
+		nproc, proc = mpi.Size(), mpi.Rank()
+	}
+	showMsg := verbose && proc == 0
+
+	// run samples of this processor
+	nresp := len(resps)
+	ncol := nresp + 1 // responses + flag indicating successful run
+	res := make([]float64, n*ncol)
+	for i := proc; i < n; i += nproc {
+		if showMsg {
+			io.Pf("> sample %d\r", i)
+		}
+		v, err := run_sample(simfile, io.Sf("%s%d", prefix, i), resps, func(sim *inp.Simulation) error {
+			return set(i, sim)
+		})
+		if err != nil {
+			if verbose {
+				io.Pfred("sample %d failed:\n%v\n", i, err)
+			}
+			continue
+		}
+		copy(res[i*ncol:], v)
+		res[i*ncol+nresp] = 1
+	}
+	if showMsg {
+		io.Pf("\n")
+	}
+
+	// join results
+	if nproc > 1 {
+		wrk := make([]float64, len(res))
+		all_reduce_sum(determ, res, wrk)
+	}
+
+	// results
+	vals = make([][]float64, n)
+	ran = make([]bool, n)
+	for i := 0; i < n; i++ {
+		vals[i] = res[i*ncol : i*ncol+nresp]
+		ran[i] = res[i*ncol+nresp] > 0.5
+	}
+	return
+}
+
+// run_sample runs one simulation with parameters set by function set and returns the responses
+func run_sample(simfile, alias string, resps []*inp.MCRespData, set func(sim *inp.Simulation) error) (vals []float64, err error) {
+
+	// catch panics of simulation
+	defer func() {
+		if e := recover(); e != nil {
+			err = chk.Err("%v", e)
+		}
+	}()
+
+	// new simulation
+	m := NewMain(simfile, alias, false, false, false, false, false, 0)
+
+	// set parameters
+	err = set(m.Sim)
+	if err != nil {
+		return
+	}
+	err = m.Sim.ReinitModels()
+	if err != nil {
+		return
+	}
+
+	// run
+	err = m.Run()
+	if err != nil {
+		return
+	}
+
+	// responses
+	d := m.Domains[0]
+	vals = make([]float64, len(resps))
+	for j, resp := range resps {
+		vals[j], err = mc_response(d, resp)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// calc_stats computes the statistics of responses
+func (o *MonteCarlo) calc_stats() {
+	nreal, nresp := len(o.Vals), len(o.Dat.Resps)
+	o.Stats = make([]*MCStat, nresp)
+	for j, resp := range o.Dat.Resps {
+		s := &MCStat{Name: resp.Name}
+		nfail := 0
+		for r := 0; r < nreal; r++ {
+			if o.Fails[r][j] {
+				nfail++
+			}
+			if o.Ran[r] {
+				s.Sorted = append(s.Sorted, o.Vals[r][j])
+			}
+		}
+		sort.Float64s(s.Sorted)
+		n := float64(len(s.Sorted))
+		for _, v := range s.Sorted {
+			s.Mean += v / n
+		}
+		if n > 1 {
+			for _, v := range s.Sorted {
+				s.Std += (v - s.Mean) * (v - s.Mean) / (n - 1.0)
+			}
+			s.Std = math.Sqrt(s.Std)
+		}
+		if math.Abs(s.Mean) > 0 {
+			s.Cov = s.Std / math.Abs(s.Mean)
+		}
+		s.Pf = float64(nfail) / float64(nreal)
+		o.Stats[j] = s
+	}
+	nfail := 0
+	for r := 0; r < nreal; r++ {
+		for j := 0; j < nresp; j++ {
+			if o.Fails[r][j] {
+				nfail++
+				break
+			}
+		}
+	}
+	o.Pf = float64(nfail) / float64(nreal)
+}
+
+// mc_sample generates a random value of a random parameter
+func mc_sample(rng *rand.Rand, dat *rnd.VarData) (val float64, err error) {
+	μ, σ := dat.M, dat.S
+	switch dat.Prm.D {
+	case "normal":
+		return μ + σ*rng.NormFloat64(), nil
+	case "lognormal":
+		if μ <= 0 {
+			return 0, chk.Err("mean value of lognormal parameter %q must be positive", dat.Prm.N)
+		}
+		s := math.Sqrt(math.Log(1.0 + σ*σ/(μ*μ)))
+		return math.Exp(math.Log(μ) - s*s/2.0 + s*rng.NormFloat64()), nil
+	case "gumbel":
+		β := σ * math.Sqrt(6.0) / math.Pi
+		u := μ - 0.5772156649015329*β
+		return u - β*math.Log(-math.Log(rng.Float64())), nil
+	case "uniform":
+		return dat.Min + (dat.Max-dat.Min)*rng.Float64(), nil
+	}
+	return 0, chk.Err("cannot sample parameter %q with distribution %q; options are \"normal\", \"lognormal\", \"gumbel\" and \"uniform\"", dat.Prm.N, dat.Prm.D)
+}
+
+// mc_response computes the response quantity at the end of simulation
+func mc_response(d *Domain, resp *inp.MCRespData) (val float64, err error) {
+	switch resp.Type {
+	case "node":
+		if resp.Vid < 0 || resp.Vid >= len(d.Vid2node) || d.Vid2node[resp.Vid] == nil {
+			return 0, chk.Err("response %q: cannot find active node with vertex id = %d", resp.Name, resp.Vid)
+		}
+		eq := d.Vid2node[resp.Vid].GetEq(resp.Key)
+		if eq < 0 {
+			return 0, chk.Err("response %q: cannot find dof %q at vertex %d", resp.Name, resp.Key, resp.Vid)
+		}
+		return d.Sol.Y[eq], nil
+	case "ipmax", "ipmin":
+		found := false
+		for _, c := range d.Msh.Cells {
+			if c.Tag != resp.Tag {
+				continue
+			}
+			e, ok := d.Cid2elem[c.Id].(ele.CanOutputIps)
+			if !ok {
+				continue
+			}
+			M := ele.NewIpsMap()
+			e.OutIpVals(M, d.Sol)
+			for _, v := range (*M)[resp.Key] {
+				if !found || (resp.Type == "ipmax" && v > val) || (resp.Type == "ipmin" && v < val) {
+					val = v
+					found = true
+				}
+			}
+		}
+		if !found {
+			return 0, chk.Err("response %q: cannot find values of %q at integration points of cells with tag = %d", resp.Name, resp.Key, resp.Tag)
+		}
+		return
+	}
+	return 0, chk.Err("response %q: type %q is invalid; options are \"node\", \"ipmax\" and \"ipmin\"", resp.Name, resp.Type)
+}
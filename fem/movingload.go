@@ -0,0 +1,147 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// MovingLoad implements a set of point loads travelling along a path on the surface of the mesh;
+// e.g. the axles of a train on a railway embankment or of a vehicle on a pavement
+//  Note: (1) only the faces of cells belonging to this processor are considered; thus, in parallel
+//            runs, each load is added by one processor before joining fb
+//        (2) the point loads are added to fb at each time (or iteration); i.e. they are not
+//            included in the Jacobian matrix (follower effects are not considered)
+type MovingLoad struct {
+	Dat   *inp.MovingLoadData // input data
+	Cells []*inp.Cell         // cells with tagged faces (of this processor)
+	Xmat  [][][]float64       // [ncells][ndim][nverts] coordinates of cells
+	Lens  []float64           // [npts] cumulated lengths along path
+	Key   string              // key of dof corresponding to the direction of loads; e.g. "uy"
+	d     *Domain             // domain
+	r     []float64           // [3] scratchpad: natural coordinates
+}
+
+// NewMovingLoad allocates a new MovingLoad structure acting on the cells of domain
+func NewMovingLoad(d *Domain, dat *inp.MovingLoadData) (o *MovingLoad, err error) {
+
+	// direction
+	o = &MovingLoad{Dat: dat, d: d, r: make([]float64, 3)}
+	if utl.StrIndexSmall([]string{"fx", "fy", "fz"}[:d.Msh.Ndim], dat.Key) < 0 {
+		return nil, chk.Err("key %q of moving loads is invalid; options are \"fx\", \"fy\" and \"fz\" (3D)", dat.Key)
+	}
+	o.Key = d.F2Y[dat.Key]
+	if o.Key == "" {
+		return nil, chk.Err("cannot find dof corresponding to key %q of moving loads", dat.Key)
+	}
+
+	// cells with tagged faces
+	pairs, ok := d.Msh.FaceTag2cells[dat.Tag]
+	if !ok {
+		return nil, chk.Err("cannot find faces with tag = %d to apply moving loads", dat.Tag)
+	}
+	done := make(map[int]bool)
+	for _, pair := range pairs {
+		c := pair.C
+		if done[c.Id] || d.Cid2elem[c.Id] == nil || c.Shp == nil || c.Shp.Nurbs != nil {
+			continue
+		}
+		done[c.Id] = true
+		o.Cells = append(o.Cells, c)
+		o.Xmat = append(o.Xmat, d.cell_coords(c, c.Shp.Nverts))
+	}
+
+	// lengths along path
+	o.Lens = make([]float64, len(dat.Path))
+	for i := 1; i < len(dat.Path); i++ {
+		if len(dat.Path[i]) < d.Msh.Ndim || len(dat.Path[i-1]) < d.Msh.Ndim {
+			return nil, chk.Err("points of path of moving loads must have %d coordinates", d.Msh.Ndim)
+		}
+		o.Lens[i] = o.Lens[i-1] + utl.L2norm(dat.Path[i][:d.Msh.Ndim], dat.Path[i-1][:d.Msh.Ndim])
+	}
+	return
+}
+
+// Position returns the coordinates of the point at distance s along path
+//  Note: returns nil if s is outside path
+func (o *MovingLoad) Position(s float64) (x []float64) {
+	n := len(o.Lens)
+	if s < 0 || s > o.Lens[n-1] {
+		return nil
+	}
+	ndim := o.d.Msh.Ndim
+	for i := 1; i < n; i++ {
+		if s <= o.Lens[i] {
+			a, b := o.Dat.Path[i-1], o.Dat.Path[i]
+			ξ := 0.0
+			if o.Lens[i] > o.Lens[i-1] {
+				ξ = (s - o.Lens[i-1]) / (o.Lens[i] - o.Lens[i-1])
+			}
+			x = make([]float64, ndim)
+			for j := 0; j < ndim; j++ {
+				x[j] = (1.0-ξ)*a[j] + ξ*b[j]
+			}
+			return
+		}
+	}
+	return nil
+}
+
+// AddToRhs adds the consistent nodal forces of the moving loads at time t to fb
+//  Note: must be called before joining fb in parallel runs
+func (o *MovingLoad) AddToRhs(fb []float64, t float64) {
+	mult := 1.0
+	if o.Dat.Fcn != nil {
+		mult = o.Dat.Fcn.F(t, nil)
+	}
+	front := o.Dat.S0 + o.Dat.Speed*t
+	for i, axle := range o.Dat.Axles {
+
+		// position of axle
+		x := o.Position(front - axle)
+		if x == nil {
+			continue
+		}
+
+		// cell containing x
+		best, icell := 0.0, -1
+		for k, c := range o.Cells {
+			if c.Shp.InvMap(o.r, x, o.Xmat[k]) != nil {
+				continue
+			}
+			dist := c.Shp.CellBryDist(o.r)
+			if icell < 0 || dist > best {
+				best, icell = dist, k
+			}
+			if dist >= 0 {
+				break
+			}
+		}
+		if icell < 0 || best < -inp.LOCATE_OUTTOL {
+			continue
+		}
+
+		// consistent nodal forces
+		c := o.Cells[icell]
+		sh := c.Shp
+		sh.InvMap(o.r, x, o.Xmat[icell])
+		sh.Func(sh.S, sh.DSdR, o.r, false, -1)
+		P := mult * o.Dat.Loads[i]
+		for m := 0; m < sh.Nverts; m++ {
+			if math.Abs(sh.S[m]) < 1e-12 {
+				continue
+			}
+			if nod := o.d.Vid2node[c.Verts[m]]; nod != nil {
+				if eq := nod.GetEq(o.Key); eq >= 0 {
+					fb[eq] += P * sh.S[m]
+				}
+			}
+		}
+	}
+}
@@ -28,6 +28,28 @@ type Node struct {
 	Vert *inp.Vert // pointer to Vertex
 }
 
+// DispKeys returns the keys of displacements in ndim dimensions; e.g. "ux", "uy"
+func DispKeys(ndim int) []string {
+	return []string{"ux", "uy", "uz"}[:ndim]
+}
+
+// RotKeys returns the keys of rotations in ndim dimensions; i.e. "rz" in 2D and "rx", "ry", "rz" in 3D
+func RotKeys(ndim int) []string {
+	if ndim == 2 {
+		return []string{"rz"}
+	}
+	return []string{"rx", "ry", "rz"}
+}
+
+// IsDispOrRot tells whether key is a displacement or rotation key
+func IsDispOrRot(key string) bool {
+	switch key {
+	case "ux", "uy", "uz", "rx", "ry", "rz":
+		return true
+	}
+	return false
+}
+
 // NewNode allocates a new Node
 func NewNode(v *inp.Vert) *Node {
 	return &Node{[]*Dof{}, v}
@@ -0,0 +1,114 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// OutFilter implements the selective output of results of a stage; i.e. the dofs, elements and
+// output times to be saved (see inp.OutFilterData)
+type OutFilter struct {
+	Dat  *inp.OutFilterData // input data
+	Tf   float64            // final time of stage
+	Eqs  []int              // equations to be saved; nil => all
+	Cids []int              // ids of cells whose internal values are saved
+	nout int                // number of output times requested so far
+}
+
+// NewOutFilter allocates a new output filter for the current stage of domain
+func NewOutFilter(d *Domain, dat *inp.OutFilterData, tf float64) (o *OutFilter, err error) {
+
+	// cells in selected regions
+	o = &OutFilter{Dat: dat, Tf: tf}
+	var incell map[int]bool
+	if len(dat.Tags) > 0 {
+		incell = make(map[int]bool)
+		for _, tag := range dat.Tags {
+			cells, ok := d.Msh.CellTag2cells[tag]
+			if !ok {
+				return nil, chk.Err("cannot find cells with tag = %d to select output", tag)
+			}
+			for _, c := range cells {
+				incell[c.Id] = true
+			}
+		}
+	}
+
+	// elements
+	for _, cid := range d.MyCids {
+		if incell != nil && !incell[cid] {
+			continue
+		}
+		if len(dat.Ikeys) > 0 {
+			e, ok := d.Cid2elem[cid].(ele.CanOutputIps)
+			if !ok || !has_any_key(e.OutIpKeys(), dat.Ikeys) {
+				continue
+			}
+		}
+		o.Cids = append(o.Cids, cid)
+	}
+
+	// equations
+	if incell == nil && len(dat.Nkeys) == 0 {
+		return
+	}
+	invert := make(map[int]bool)
+	if incell != nil {
+		for cid, _ := range incell {
+			for _, vid := range d.Msh.Cells[cid].Verts {
+				invert[vid] = true
+			}
+		}
+	}
+	o.Eqs = make([]int, 0)
+	for _, nod := range d.Nodes {
+		if incell != nil && !invert[nod.Vert.Id] {
+			continue
+		}
+		for _, dof := range nod.Dofs {
+			if len(dat.Nkeys) == 0 || utl.StrIndexSmall(dat.Nkeys, dof.Key) >= 0 {
+				o.Eqs = append(o.Eqs, dof.Eq)
+			}
+		}
+	}
+	return
+}
+
+// Skip tells whether the output at time t must be skipped (decimation). It must be called once
+// for each output time
+func (o *OutFilter) Skip(t float64) bool {
+	n := o.nout
+	o.nout++
+	if o.Dat.Every < 2 || n == 0 || t >= o.Tf {
+		return false
+	}
+	return n%o.Dat.Every != 0
+}
+
+// Vec returns a copy of v with the values of non-selected equations set to zero
+func (o *OutFilter) Vec(v []float64) (res []float64) {
+	if o.Eqs == nil || v == nil {
+		return v
+	}
+	res = make([]float64, len(v))
+	for _, eq := range o.Eqs {
+		res[eq] = v[eq]
+	}
+	return
+}
+
+// has_any_key tells whether any of keys is in list
+func has_any_key(list, keys []string) bool {
+	for _, key := range keys {
+		if utl.StrIndexSmall(list, key) >= 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/porous"
+	"github.com/cpmech/gofem/ele/seepage"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// PipRecord holds the largest hydraulic gradients after a time step
+type PipRecord struct {
+	T     float64   // time
+	Imax  float64   // largest hydraulic gradient |i| among cells
+	Icell int       // id of cell with Imax
+	Rmax  float64   // largest ratio |i|/icr among cells
+	Rcell int       // id of cell with Rmax
+	Iexit []float64 // [nftags] largest exit gradient on faces with each tag
+}
+
+// Piping implements the check of hydraulic gradients against the critical gradient; e.g. for the
+// verification of internal erosion (piping) and heave. See inp.PipingData
+//  Note: (1) the gradients are computed after each converged time step and the largest values
+//            are recorded; thus the report holds the history of the largest values
+//        (2) only elements with liquid pressure are considered; e.g. "liquid" and "solid-liquid"
+type Piping struct {
+	Stage   int               // index of stage
+	Dat     *inp.PipingData   // input data
+	Cells   []*inp.Cell       // checked cells
+	Liqs    []*seepage.Liquid // [len(Cells)] p-elements of checked cells
+	Icr     []float64         // [len(Cells)] critical gradients
+	I       [][]float64       // [len(Cells)][ndim] average hydraulic gradient vector of cells after last step
+	R       []float64         // [len(Cells)] ratio |i|/icr of cells after last step
+	Records []*PipRecord      // records of each time step
+	Nflag   int               // number of steps with ratio larger than 1/Fs
+	cid2idx map[int]int       // cell id => index in Cells
+}
+
+// NewPiping allocates a new Piping structure for the stage (stgidx) of domain
+func NewPiping(d *Domain, stgidx int, dat *inp.PipingData) (o *Piping, err error) {
+
+	// check
+	if d.Distr {
+		return nil, chk.Err("check of hydraulic gradients is not available in parallel runs")
+	}
+	o = &Piping{Stage: stgidx, Dat: dat, cid2idx: make(map[int]int)}
+	if o.Dat.Fs <= 0 {
+		o.Dat.Fs = 1
+	}
+	for _, ftag := range dat.Ftags {
+		if _, ok := d.Msh.FaceTag2cells[ftag]; !ok {
+			return nil, chk.Err("cannot find faces with tag = %d to compute exit gradients", ftag)
+		}
+	}
+
+	// cells
+	tags := make(map[int]bool)
+	for _, tag := range dat.Tags {
+		tags[tag] = true
+	}
+	for _, c := range d.Msh.Cells {
+		if len(tags) > 0 && !tags[c.Tag] {
+			continue
+		}
+		liq := piping_liquid(d.Cid2elem[c.Id])
+		if liq == nil {
+			continue
+		}
+		icr := dat.Icrit
+		if icr <= 0 {
+			mdl := liq.Mdl
+			if mdl.Liq == nil || mdl.Liq.R0 <= 0 {
+				return nil, chk.Err("cannot compute critical gradient of cell # %d: liquid model is required", c.Id)
+			}
+			icr = (1.0 - mdl.Nf0) * (mdl.RhoS0 - mdl.Liq.R0) / mdl.Liq.R0
+			if icr <= 0 {
+				return nil, chk.Err("critical gradient of cell # %d must be positive; check nf0 and rhoS0. icr = %g is invalid", c.Id, icr)
+			}
+		}
+		o.cid2idx[c.Id] = len(o.Cells)
+		o.Cells = append(o.Cells, c)
+		o.Liqs = append(o.Liqs, liq)
+		o.Icr = append(o.Icr, icr)
+	}
+	if len(o.Cells) == 0 {
+		return nil, chk.Err("check of hydraulic gradients requires elements with liquid pressure; e.g. \"liquid\" or \"solid-liquid\"")
+	}
+	o.I = la.MatAlloc(len(o.Cells), d.Msh.Ndim)
+	o.R = make([]float64, len(o.Cells))
+	return
+}
+
+// Start clears the records at the beginning of stage
+func (o *Piping) Start() {
+	o.Records = o.Records[:0]
+	o.Nflag = 0
+}
+
+// Step computes the hydraulic gradients after a time step has converged and records the largest
+// values. A message is printed if the largest ratio is greater than 1/Fs and verbose is true
+func (o *Piping) Step(d *Domain, verbose bool) (err error) {
+	r := &PipRecord{T: d.Sol.T, Icell: -1, Rcell: -1}
+
+	// cells
+	for k, liq := range o.Liqs {
+		err = piping_gradient(o.I[k], liq, d.Sol)
+		if err != nil {
+			return chk.Err("cannot compute hydraulic gradient of cell # %d:\n%v", o.Cells[k].Id, err)
+		}
+		i := la.VecNorm(o.I[k])
+		o.R[k] = i / o.Icr[k]
+		if i > r.Imax || r.Icell < 0 {
+			r.Imax, r.Icell = i, o.Cells[k].Id
+		}
+		if o.R[k] > r.Rmax || r.Rcell < 0 {
+			r.Rmax, r.Rcell = o.R[k], o.Cells[k].Id
+		}
+	}
+
+	// exit faces
+	r.Iexit = make([]float64, len(o.Dat.Ftags))
+	for j, ftag := range o.Dat.Ftags {
+		r.Iexit[j] = math.Inf(-1)
+		for _, pair := range d.Msh.FaceTag2cells[ftag] {
+			k, ok := o.cid2idx[pair.C.Id]
+			if !ok {
+				continue
+			}
+			n, err := face_unit_normal(d, pair.C, pair.Fid)
+			if err != nil {
+				return err
+			}
+			r.Iexit[j] = math.Max(r.Iexit[j], la.VecDot(o.I[k], n))
+		}
+		if math.IsInf(r.Iexit[j], -1) {
+			r.Iexit[j] = 0 // faces of cells that are not checked
+		}
+	}
+
+	// record
+	o.Records = append(o.Records, r)
+	if r.Rmax*o.Dat.Fs > 1 {
+		o.Nflag++
+		if verbose {
+			io.Pfred("\n> hydraulic gradient at t = %g in cell # %d is too large: i/icr = %g > 1/Fs = %g\n", r.T, r.Rcell, r.Rmax, 1.0/o.Dat.Fs)
+		}
+	}
+	return
+}
+
+// Report returns a table with the records of largest hydraulic gradients
+func (o *Piping) Report() string {
+	var b bytes.Buffer
+	var rmax float64
+	for _, r := range o.Records {
+		rmax = math.Max(rmax, r.Rmax)
+	}
+	io.Ff(&b, "# hydraulic gradients of stage %d. max(i/icr) = %g. %d steps with i/icr > 1/Fs = %g\n", o.Stage, rmax, o.Nflag, 1.0/o.Dat.Fs)
+	io.Ff(&b, "%23s%23s%8s%23s%8s", "t", "imax", "icell", "rmax", "rcell")
+	for _, ftag := range o.Dat.Ftags {
+		io.Ff(&b, "%23s", io.Sf("iexit(%d)", ftag))
+	}
+	io.Ff(&b, "\n")
+	for _, r := range o.Records {
+		io.Ff(&b, "%23.15e%23.15e%8d%23.15e%8d", r.T, r.Imax, r.Icell, r.Rmax, r.Rcell)
+		for _, ie := range r.Iexit {
+			io.Ff(&b, "%23.15e", ie)
+		}
+		io.Ff(&b, "\n")
+	}
+	return b.String()
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// piping_liquid returns the p-element of element e; nil if e has no liquid pressure
+func piping_liquid(e ele.Element) *seepage.Liquid {
+	switch e := e.(type) {
+	case *seepage.Liquid:
+		return e
+	case *porous.SolidLiquid:
+		return e.P
+	}
+	return nil
+}
+
+// piping_gradient computes the hydraulic gradient i = -∇h = (ρL・g - ∇pl) / (ρL・|g|) averaged
+// over the element (weighted by the volume of integration points)
+func piping_gradient(i []float64, liq *seepage.Liquid, sol *ele.Solution) (err error) {
+	la.VecFill(i, 0)
+	ρL := liq.Mdl.Liq.R0
+	var vol float64
+	for idx, ip := range liq.IpsElem {
+		err = liq.CalcIpVars(idx, sol)
+		if err != nil {
+			return
+		}
+		g := la.VecNorm(liq.Grav)
+		if g <= 0 {
+			return chk.Err("gravity is required to compute the total head")
+		}
+		coef := liq.Cell.Shp.J * ip[3]
+		for j := range i {
+			i[j] += coef * (ρL*liq.Grav[j] - liq.GradPl[j]) / (ρL * g)
+		}
+		vol += coef
+	}
+	for j := range i {
+		i[j] /= vol
+	}
+	return
+}
+
+// face_unit_normal returns the unit outward normal of face fid of cell c at its first integration point
+func face_unit_normal(d *Domain, c *inp.Cell, fid int) (n []float64, err error) {
+	sh := c.Shp
+	X := d.cell_coords(c, sh.Nverts)
+	_, ipf, err := sh.GetIps(0, 0)
+	if err != nil {
+		return
+	}
+	err = sh.CalcAtFaceIp(X, ipf[0], fid)
+	if err != nil {
+		return
+	}
+	n = la.VecClone(sh.Fnvec)
+	la.VecScale(n, 0, 1.0/la.VecNorm(n), n)
+	return
+}
+
+// save_piping writes the reports of hydraulic gradients of stage (stgidx) to files in DirOut
+func (o *Main) save_piping(stgidx int) {
+	if o.Proc != 0 {
+		return
+	}
+	for i, d := range o.Domains {
+		if d.Pip == nil {
+			continue
+		}
+		fn := io.Sf("%s_piping_d%d_s%d.res", o.Sim.Key, i, stgidx)
+		io.WriteFileSD(o.Sim.DirOut, fn, d.Pip.Report())
+		if o.ShowMsg {
+			io.Pf("> Hydraulic gradients of domain %d: %d steps with i/icr > %g; see %s/%s\n", i, d.Pip.Nflag, 1.0/d.Pip.Dat.Fs, o.Sim.DirOut, fn)
+		}
+	}
+}
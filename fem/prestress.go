@@ -0,0 +1,53 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// SetPrestress sets the prestress forces of anchors and struts with the tags given in the stage data
+//  Note: (1) each tag must exist in the mesh and be given only once
+//        (2) the elements with given tags must support prestressing (ele.WithPrestress)
+func (o *Domain) SetPrestress(dats []*inp.PrestressData) (err error) {
+	tags := make(map[int]bool)
+	for _, dat := range dats {
+		if dat.Tlock < 0 {
+			return chk.Err("time of locking of anchorage must be non-negative. %g is invalid", dat.Tlock)
+		}
+		var fcn fun.Func
+		if dat.Func != "" {
+			fcn, err = o.Sim.Functions.Get(dat.Func)
+			if err != nil {
+				return
+			}
+		}
+		for _, tag := range dat.Tags {
+			if tags[tag] {
+				return chk.Err("tag %d is given more than once in prestress data", tag)
+			}
+			tags[tag] = true
+			cells, ok := o.Msh.CellTag2cells[tag]
+			if !ok {
+				return chk.Err("cannot find cells with tag = %d given in prestress data", tag)
+			}
+			for _, cell := range cells {
+				e := o.Cid2elem[cell.Id]
+				if e == nil { // inactive or in another processor
+					continue
+				}
+				ep, ok := e.(ele.WithPrestress)
+				if !ok {
+					return chk.Err("element of cell # %d (tag = %d) does not support prestressing", cell.Id, tag)
+				}
+				ep.SetPrestress(dat.Force, fcn, dat.Tlock)
+			}
+		}
+	}
+	return
+}
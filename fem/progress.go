@@ -0,0 +1,60 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"time"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/io"
+)
+
+// progress reports the progress of the time loop of a stage: percentage of stage time simulated,
+// number of iterations of the last step and estimated wall-clock time to completion (ETA)
+type progress struct {
+	t0, tf float64   // initial and final times of stage
+	start  time.Time // wall-clock time at the beginning of stage
+}
+
+// new_progress starts recording the progress of a stage from t0 to tf
+func new_progress(t0, tf float64) *progress {
+	return &progress{t0, tf, time.Now()}
+}
+
+// print prints the progress after the step ending at time t which required nit iterations
+func (o *progress) print(t float64, nit int) {
+	elapsed := time.Since(o.start).Seconds()
+	frac := 1.0
+	if o.tf > o.t0 {
+		frac = (t - o.t0) / (o.tf - o.t0)
+	}
+	eta := "?"
+	if frac > 0 {
+		eta = fmt_seconds(elapsed * (1.0 - frac) / frac)
+	}
+	io.Pf("> Time = %f (%5.1f%%)  it = %2d  elapsed = %s  ETA = %s    \r", t, 100.0*frac, nit, fmt_seconds(elapsed), eta)
+}
+
+// wall_budget interrupts the simulation (see Interrupt) when the wall-clock time of stage exceeds
+// stg.Control.WallMax; e.g. to save the state before the job is killed by queue systems.
+// The returned function must be called at the end of stage
+func (o *Main) wall_budget(stg *inp.Stage) (stop func()) {
+	wallmax := stg.Control.WallMax
+	if wallmax <= 0 {
+		return func() {}
+	}
+	timer := time.AfterFunc(time.Duration(wallmax*float64(time.Second)), func() {
+		if o.ShowMsg {
+			io.Pfyel("\n> Wall-clock budget of stage (%s) exhausted: finishing current time step and saving state\n", fmt_seconds(wallmax))
+		}
+		Interrupt()
+	})
+	return func() { timer.Stop() }
+}
+
+// fmt_seconds formats a duration given in seconds; e.g. "1h2m3s"
+func fmt_seconds(secs float64) string {
+	return (time.Duration(secs) * time.Second).String()
+}
@@ -0,0 +1,393 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Relaxation implements the convergence-confinement (β) method of tunnelling. See inp.RelaxationData
+//  Note: (1) excavated elements are replaced by RelaxedElem and lining elements are wrapped by
+//            LiningElem when the initial state is set (Start). Thus, the forces of excavated
+//            elements correspond to the initial stresses
+//        (2) the lining is installed after the first converged time step with t ≥ Tlin
+type Relaxation struct {
+	Dat       *inp.RelaxationData // input data
+	Tf        float64             // final time of stage
+	Exc       map[int]bool        // tags of excavated elements
+	Lin       map[int]bool        // tags of lining elements
+	Relaxed   []*RelaxedElem      // excavated elements
+	Linings   []*LiningElem       // lining elements
+	Installed bool                // lining has been installed
+	Held      map[int]float64     // held equations => value of y when held
+	neles     []int               // [ny] number of installed elements sharing each equation
+}
+
+// NewRelaxation allocates a new Relaxation structure
+func NewRelaxation(d *Domain, dat *inp.RelaxationData, tf float64) (o *Relaxation, err error) {
+	if d.Distr {
+		return nil, chk.Err("relaxation of excavated elements is not available in parallel runs")
+	}
+	if dat.Beta < 0 || dat.Beta > 1 {
+		return nil, chk.Err("relaxation factor β must be in [0,1]. β = %g is invalid", dat.Beta)
+	}
+	if dat.Tlin <= 0 || dat.Tlin >= tf {
+		return nil, chk.Err("time of installation of lining must be in ]0,tf[. tlin = %g is invalid (tf = %g)", dat.Tlin, tf)
+	}
+	if len(dat.Tags) == 0 {
+		return nil, chk.Err("tags of excavated elements must be given")
+	}
+	o = new(Relaxation)
+	o.Dat = dat
+	o.Tf = tf
+	o.Exc = make(map[int]bool)
+	o.Lin = make(map[int]bool)
+	for _, tag := range dat.Tags {
+		if _, ok := d.Msh.CellTag2cells[tag]; !ok {
+			return nil, chk.Err("cannot find excavated cells with tag = %d", tag)
+		}
+		o.Exc[tag] = true
+	}
+	for _, tag := range dat.Lining {
+		if _, ok := d.Msh.CellTag2cells[tag]; !ok {
+			return nil, chk.Err("cannot find lining cells with tag = %d", tag)
+		}
+		if o.Exc[tag] {
+			return nil, chk.Err("cells with tag = %d cannot be both excavated and lining", tag)
+		}
+		o.Lin[tag] = true
+	}
+	return
+}
+
+// Start replaces the excavated elements and wraps the lining elements after the initial state
+// has been set
+//  Note: Start must be called only once after SetStage
+func (o *Relaxation) Start(d *Domain) (err error) {
+
+	// replace elements
+	if o.Relaxed != nil {
+		return chk.Err("relaxation has already been started. SetStage must be called first")
+	}
+	o.Relaxed = make([]*RelaxedElem, 0)
+	o.Linings = make([]*LiningElem, 0)
+	o.Installed = false
+	fb := make([]float64, d.Nyb)
+	for idx, e := range d.Elems {
+		tag := d.Msh.Cells[e.Id()].Tag
+
+		// excavated element: forces at the beginning of stage
+		if o.Exc[tag] {
+			la.VecFill(fb, 0)
+			err = e.AddToRhs(fb, d.Sol)
+			if err != nil {
+				return chk.Err("cannot compute forces of excavated element (eid=%d):\n%v", e.Id(), err)
+			}
+			r := &RelaxedElem{E: e, Rel: o, Eqs: elem_eqs(d, e)}
+			r.F0 = make([]float64, len(r.Eqs))
+			for k, eq := range r.Eqs {
+				r.F0[k] = fb[eq]
+			}
+			d.Elems[idx] = r
+			d.Cid2elem[e.Id()] = r
+			o.Relaxed = append(o.Relaxed, r)
+			remove_from_subsets(d, e)
+			continue
+		}
+
+		// lining element
+		if o.Lin[tag] {
+			l := &LiningElem{E: e}
+			for _, v := range d.Msh.Cells[e.Id()].Verts {
+				for _, dof := range d.Vid2node[v].Dofs {
+					if IsDispOrRot(dof.Key) {
+						l.Ueqs = append(l.Ueqs, dof.Eq)
+					}
+				}
+			}
+			l.Y0 = make([]float64, len(l.Ueqs))
+			d.Elems[idx] = l
+			d.Cid2elem[e.Id()] = l
+			o.Linings = append(o.Linings, l)
+			d.ElemIntvars = replace_ivs_elem(d.ElemIntvars, e, l)
+			d.ElemIvsCon = replace_ivs_elem(d.ElemIvsCon, e, l)
+			d.ElemIvsNotCon = replace_ivs_elem(d.ElemIvsNotCon, e, l)
+		}
+	}
+	if len(o.Relaxed) == 0 {
+		return chk.Err("there are no active excavated elements")
+	}
+
+	// held equations
+	o.neles = make([]int, d.Ny)
+	for _, e := range d.Elems {
+		switch e.(type) {
+		case *RelaxedElem, *LiningElem:
+			continue
+		}
+		for _, eq := range elem_eqs(d, e) {
+			o.neles[eq]++
+		}
+	}
+	o.Held = make(map[int]float64)
+	for eq, n := range o.neles {
+		if n == 0 {
+			o.Held[eq] = d.Sol.Y[eq]
+		}
+	}
+	return
+}
+
+// Step installs the lining after a time step has converged (if t ≥ Tlin)
+func (o *Relaxation) Step(d *Domain) (err error) {
+	if o.Installed || d.Sol.T < o.Dat.Tlin-1e-10 {
+		return
+	}
+	for _, l := range o.Linings {
+		err = l.install(d)
+		if err != nil {
+			return
+		}
+		for _, eq := range elem_eqs(d, l.E) {
+			o.neles[eq]++
+			delete(o.Held, eq)
+		}
+	}
+	o.Installed = true
+	if d.ShowMsg {
+		io.Pf("\n>> lining installed at t = %g; released fraction of forces = %g\n", d.Sol.T, o.Released(d.Sol.T))
+	}
+	return
+}
+
+// Released returns the fraction of released forces of excavated elements at time t
+func (o *Relaxation) Released(t float64) float64 {
+	β, tl := o.Dat.Beta, o.Dat.Tlin
+	if t <= tl {
+		return β * math.Max(t, 0) / tl
+	}
+	return β + (1.0-β)*math.Min((t-tl)/(o.Tf-tl), 1)
+}
+
+// AddToRhs adds the contribution of springs holding nodes to fb
+func (o *Relaxation) AddToRhs(fb []float64, sol *ele.Solution) {
+	for eq, y0 := range o.Held {
+		fb[eq] -= sol.Y[eq] - y0
+	}
+}
+
+// AddToKb adds the contribution of springs holding nodes to Kb
+func (o *Relaxation) AddToKb(Kb *la.Triplet) {
+	for eq, _ := range o.Held {
+		Kb.Put(eq, eq, 1)
+	}
+}
+
+// replace_ivs_elem replaces element e by w in list of elements with internal variables
+func replace_ivs_elem(list []ele.WithIntVars, e ele.Element, w ele.WithIntVars) []ele.WithIntVars {
+	for i, c := range list {
+		if c.(ele.Element).Id() == e.Id() {
+			list[i] = w
+			break
+		}
+	}
+	return list
+}
+
+// RelaxedElem implements an excavated element whose forces are released
+//  Note: the contribution to fb at the beginning of stage (F0) is reduced according to the
+//        released fraction; except at held equations. No contribution to Kb is added
+type RelaxedElem struct {
+	E   ele.Element // excavated element
+	Rel *Relaxation // relaxation structure
+	Eqs []int       // equations of element's nodes
+	F0  []float64   // [len(Eqs)] contribution to fb at the beginning of stage
+}
+
+// Id returns the cell Id
+func (o *RelaxedElem) Id() int { return o.E.Id() }
+
+// SetEqs set equations
+func (o *RelaxedElem) SetEqs(eqs [][]int, mixedform_eqs []int) (err error) {
+	return
+}
+
+// SetEleConds set element conditions
+func (o *RelaxedElem) SetEleConds(key string, f fun.Func, extra string) (err error) {
+	return
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *RelaxedElem) InterpStarVars(sol *ele.Solution) (err error) {
+	return
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o *RelaxedElem) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
+	m := 1.0 - o.Rel.Released(sol.T)
+	if m <= 0 {
+		return
+	}
+	for k, eq := range o.Eqs {
+		if _, held := o.Rel.Held[eq]; held {
+			continue
+		}
+		fb[eq] += m * o.F0[k]
+	}
+	return
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o *RelaxedElem) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+	return
+}
+
+// Encode encodes internal variables (frozen at the beginning of stage)
+func (o *RelaxedElem) Encode(enc utl.Encoder) (err error) {
+	return o.E.Encode(enc)
+}
+
+// Decode decodes internal variables
+func (o *RelaxedElem) Decode(dec utl.Decoder) (err error) {
+	return o.E.Decode(dec)
+}
+
+// LiningElem implements a lining element that is installed during the stage
+//  Note: (1) before installation, the element does not contribute to Kb and fb and its internal
+//            variables are not updated
+//        (2) after installation, the element sees the displacements (and rotations) relative to
+//            those at the time of installation (Y0)
+type LiningElem struct {
+	E         ele.Element // lining element
+	Installed bool        // element has been installed
+	Ueqs      []int       // displacement and rotation equations of element's nodes
+	Y0        []float64   // [len(Ueqs)] displacements and rotations at installation
+}
+
+// Id returns the cell Id
+func (o *LiningElem) Id() int { return o.E.Id() }
+
+// SetEqs set equations
+func (o *LiningElem) SetEqs(eqs [][]int, mixedform_eqs []int) (err error) {
+	return o.E.SetEqs(eqs, mixedform_eqs)
+}
+
+// SetEleConds set element conditions
+func (o *LiningElem) SetEleConds(key string, f fun.Func, extra string) (err error) {
+	return o.E.SetEleConds(key, f, extra)
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *LiningElem) InterpStarVars(sol *ele.Solution) (err error) {
+	if !o.Installed {
+		return
+	}
+	return o.E.InterpStarVars(sol)
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o *LiningElem) AddToRhs(fb []float64, sol *ele.Solution) (err error) {
+	if !o.Installed {
+		return
+	}
+	o.shift(sol, -1)
+	defer o.shift(sol, 1)
+	return o.E.AddToRhs(fb, sol)
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o *LiningElem) AddToKb(Kb *la.Triplet, sol *ele.Solution, firstIt bool) (err error) {
+	if !o.Installed {
+		return
+	}
+	o.shift(sol, -1)
+	defer o.shift(sol, 1)
+	return o.E.AddToKb(Kb, sol, firstIt)
+}
+
+// Update perform (tangent) update
+func (o *LiningElem) Update(sol *ele.Solution) (err error) {
+	if !o.Installed {
+		return
+	}
+	o.shift(sol, -1)
+	defer o.shift(sol, 1)
+	return o.E.(ele.WithIntVars).Update(sol)
+}
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *LiningElem) SetIniIvs(sol *ele.Solution, ivs map[string][]float64) (err error) {
+	return o.E.(ele.WithIntVars).SetIniIvs(sol, ivs)
+}
+
+// BackupIvs create copy of internal variables
+func (o *LiningElem) BackupIvs(aux bool) (err error) {
+	return o.E.(ele.WithIntVars).BackupIvs(aux)
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *LiningElem) RestoreIvs(aux bool) (err error) {
+	return o.E.(ele.WithIntVars).RestoreIvs(aux)
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *LiningElem) Ureset(sol *ele.Solution) (err error) {
+	return o.E.(ele.WithIntVars).Ureset(sol)
+}
+
+// Encode encodes internal variables
+func (o *LiningElem) Encode(enc utl.Encoder) (err error) {
+	return o.E.Encode(enc)
+}
+
+// Decode decodes internal variables
+func (o *LiningElem) Decode(dec utl.Decoder) (err error) {
+	return o.E.Decode(dec)
+}
+
+// MemberForces returns the internal forces of the installed lining; e.g. the hoop force N
+func (o *LiningElem) MemberForces(sol *ele.Solution) (F map[string][]float64) {
+	e, ok := o.E.(ele.WithMemberForces)
+	if !ok || !o.Installed {
+		return
+	}
+	o.shift(sol, -1)
+	defer o.shift(sol, 1)
+	return e.MemberForces(sol)
+}
+
+// install installs the element stress-free at the current state
+func (o *LiningElem) install(d *Domain) (err error) {
+	for k, eq := range o.Ueqs {
+		o.Y0[k] = d.Sol.Y[eq]
+	}
+	o.Installed = true
+	if e, ok := o.E.(ele.WithIntVars); ok {
+		o.shift(d.Sol, -1)
+		defer o.shift(d.Sol, 1)
+		err = e.SetIniIvs(d.Sol, nil)
+		if err != nil {
+			return chk.Err("cannot install lining element (eid=%d):\n%v", o.E.Id(), err)
+		}
+		err = e.BackupIvs(false)
+	}
+	return
+}
+
+// shift subtracts (sign = -1) or adds back (sign = +1) the displacements at installation
+func (o *LiningElem) shift(sol *ele.Solution, sign float64) {
+	for k, eq := range o.Ueqs {
+		sol.Y[eq] += sign * o.Y0[k]
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// renum_ndmin is the number of nodes below which nested dissection switches to reverse Cuthill-McKee
+const renum_ndmin = 64
+
+// Renumber renumbers the equations (dofs) of the active nodes to reduce the bandwidth ("rcm":
+// reverse Cuthill-McKee) or the fill-in ("nd": nested dissection) of Kb before factorisation. The
+// dofs of each node keep consecutive numbers and the new equations are given to the elements
+//  Note: (1) it must be called after the elements have received their equations (SetEqs) and
+//            before the elements are connected and the boundary conditions are set
+//        (2) the renumbering depends on the mesh and input data only; thus results saved to files
+//            (with equations renumbered) are read back transparently by domains with the same input
+//        (3) all processors compute the same numbering in parallel runs
+func (o *Domain) Renumber(method string) (err error) {
+
+	// check
+	if method != "rcm" && method != "nd" {
+		return chk.Err("renumbering method %q is invalid; options are \"rcm\" and \"nd\"", method)
+	}
+
+	// graph of nodes
+	nnod := len(o.Nodes)
+	nbr := o.nodes_graph(true, nil)
+
+	// ordering of nodes
+	var order []int
+	if method == "rcm" {
+		order = renum_rcm(nbr, nil)
+	} else {
+		order = renum_nd(nbr)
+	}
+	if len(order) != nnod {
+		return chk.Err("renumbering failed: %d nodes ordered out of %d", len(order), nnod)
+	}
+
+	// new equations
+	bw0 := renum_bandwidth(o.Nodes, nbr)
+	eq := 0
+	for _, i := range order {
+		for _, dof := range o.Nodes[i].Dofs {
+			dof.Eq = eq
+			eq++
+		}
+	}
+	if o.ShowMsg {
+		io.Pf(">> Renumbering of equations (%s): bandwidth = %d => %d\n", method, bw0, renum_bandwidth(o.Nodes, nbr))
+	}
+
+	// set equations of elements
+	for _, e := range o.Elems {
+		cell := o.Msh.Cells[e.Id()]
+		eqs := make([][]int, len(cell.Verts))
+		for j, v := range cell.Verts {
+			for _, dof := range o.Vid2node[v].Dofs {
+				eqs[j] = append(eqs[j], dof.Eq)
+			}
+		}
+		err = e.SetEqs(eqs, nil)
+		if err != nil {
+			return chk.Err("cannot set element equations after renumbering:\n%v", err)
+		}
+	}
+	return
+}
+
+// renum_rcm computes the reverse Cuthill-McKee ordering of the vertices of a graph
+//  nbr    -- [nverts] neighbours of each vertex; neighbours ≥ nverts are ignored
+//  subset -- vertices to be ordered; nil => all
+func renum_rcm(nbr [][]int, subset []int) (order []int) {
+
+	// vertices
+	n := len(nbr)
+	if subset == nil {
+		subset = make([]int, n)
+		for i := range subset {
+			subset[i] = i
+		}
+	}
+	in := make(map[int]bool)
+	for _, v := range subset {
+		in[v] = true
+	}
+	deg := func(v int) (d int) {
+		for _, w := range nbr[v] {
+			if w < n && in[w] {
+				d++
+			}
+		}
+		return
+	}
+
+	// Cuthill-McKee for each connected component; starting at the vertex with minimum degree
+	visited := make(map[int]bool)
+	for {
+		start := -1
+		for _, v := range subset {
+			if !visited[v] && (start < 0 || deg(v) < deg(start)) {
+				start = v
+			}
+		}
+		if start < 0 {
+			break
+		}
+		visited[start] = true
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			order = append(order, v)
+			var next []int
+			for _, w := range nbr[v] {
+				if w < n && in[w] && !visited[w] {
+					visited[w] = true
+					next = append(next, w)
+				}
+			}
+			for i := 1; i < len(next); i++ { // insertion sort by increasing degree
+				for k := i; k > 0 && deg(next[k]) < deg(next[k-1]); k-- {
+					next[k], next[k-1] = next[k-1], next[k]
+				}
+			}
+			queue = append(queue, next...)
+		}
+	}
+
+	// reverse
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return
+}
+
+// renum_nd computes the nested dissection ordering of the vertices of a graph: each connected
+// part is split by the middle level of a breadth-first search from a pseudo-peripheral vertex; the
+// two halves are ordered (recursively) before the separator
+func renum_nd(nbr [][]int) (order []int) {
+	all := make([]int, len(nbr))
+	for i := range all {
+		all[i] = i
+	}
+	var dissect func(subset []int)
+	dissect = func(subset []int) {
+		if len(subset) <= renum_ndmin {
+			order = append(order, renum_rcm(nbr, subset)...)
+			return
+		}
+		for _, comp := range renum_components(nbr, subset) {
+			levels := renum_levels(nbr, comp, renum_peripheral(nbr, comp))
+			if len(levels) < 3 {
+				order = append(order, renum_rcm(nbr, comp)...)
+				continue
+			}
+			m := len(levels) / 2
+			var left, right []int
+			for l, level := range levels {
+				switch {
+				case l < m:
+					left = append(left, level...)
+				case l > m:
+					right = append(right, level...)
+				}
+			}
+			dissect(left)
+			dissect(right)
+			order = append(order, levels[m]...)
+		}
+	}
+	dissect(all)
+	return
+}
+
+// renum_components returns the connected components of the subgraph with the vertices in subset
+func renum_components(nbr [][]int, subset []int) (comps [][]int) {
+	in := make(map[int]bool)
+	for _, v := range subset {
+		in[v] = true
+	}
+	visited := make(map[int]bool)
+	for _, s := range subset {
+		if visited[s] {
+			continue
+		}
+		visited[s] = true
+		comp := []int{s}
+		for k := 0; k < len(comp); k++ {
+			for _, w := range nbr[comp[k]] {
+				if in[w] && !visited[w] {
+					visited[w] = true
+					comp = append(comp, w)
+				}
+			}
+		}
+		comps = append(comps, comp)
+	}
+	return
+}
+
+// renum_levels returns the level structure of a breadth-first search from start within subset
+func renum_levels(nbr [][]int, subset []int, start int) (levels [][]int) {
+	in := make(map[int]bool)
+	for _, v := range subset {
+		in[v] = true
+	}
+	visited := map[int]bool{start: true}
+	level := []int{start}
+	for len(level) > 0 {
+		levels = append(levels, level)
+		var next []int
+		for _, v := range level {
+			for _, w := range nbr[v] {
+				if in[w] && !visited[w] {
+					visited[w] = true
+					next = append(next, w)
+				}
+			}
+		}
+		level = next
+	}
+	return
+}
+
+// renum_peripheral finds a pseudo-peripheral vertex of a connected subgraph; i.e. one with a large
+// eccentricity (George-Liu algorithm)
+func renum_peripheral(nbr [][]int, subset []int) (v int) {
+	v = subset[0]
+	levels := renum_levels(nbr, subset, v)
+	for {
+		last := levels[len(levels)-1]
+		w := last[0]
+		for _, u := range last {
+			if len(nbr[u]) < len(nbr[w]) {
+				w = u
+			}
+		}
+		next := renum_levels(nbr, subset, w)
+		if len(next) <= len(levels) {
+			return
+		}
+		v, levels = w, next
+	}
+}
+
+// renum_bandwidth computes the bandwidth (max |i - j| over coupled equations) of the nodes' dofs
+func renum_bandwidth(nodes []*Node, nbr [][]int) (bw int) {
+	for i, nod := range nodes {
+		for _, j := range append(nbr[i], i) {
+			for _, a := range nod.Dofs {
+				for _, b := range nodes[j].Dofs {
+					if a.Eq-b.Eq > bw {
+						bw = a.Eq - b.Eq
+					}
+				}
+			}
+		}
+	}
+	return
+}
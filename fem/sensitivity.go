@@ -0,0 +1,249 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// Sensitivity computes the sensitivities (gradients) of the compliance or of displacement
+// functionals with respect to design variables of elements using the adjoint method. The design
+// variable of each element is a scaling factor s_e of its stiffness; i.e. K_e(s) = s_e^p K_e with
+// s_e = 1 at the current state, where p is the penalisation exponent (p = 1 => linear dependence;
+// e.g. cross-sectional area of rods or thickness of plane-stress solids; p = 3 => SIMP densities)
+//  Note: (1) the sensitivities must be computed after solving a stage with Main.KeepLinSol = true;
+//            otherwise, the linear solver of the domain is cleaned on exit of Main.Run or
+//            Main.SolveOneStage. Main.Clean must be called after computing the sensitivities
+//        (2) the factorised tangent matrix of the last iteration is reused to solve the adjoint
+//            problem; thus, the adjoint solution is exact for linear elastic problems and the
+//            tangent is assumed symmetric
+//        (3) the sensitivity with respect to an actual variable v_e with K_e ∝ v_e (e.g. the area
+//            A of rods) is obtained with dJ/dv_e = (dJ/ds_e) / v_e
+//        (4) the stiffness of rod-joint (Rjoint) elements is scaled with the stiffness of the rod
+//            they connect; i.e. the design variable represents the presence of the reinforcement
+//        (5) the external forces are assumed independent of the design variables and the
+//            prescribed displacements are assumed homogeneous
+//        (6) only serial runs are supported
+type Sensitivity struct {
+	Dom    *Domain               // domain
+	Penal  float64               // penalisation exponent p. default = 1
+	Cids   []int                 // ids of cells with design variables
+	Groups map[int][]ele.Element // [ncids] cell id => elements scaled by design variable of cell
+
+	// workspace
+	kmat *la.Triplet // receives the entries put by AddToKb (discarded); aᵀK_e b is computed with ele.WithQuadFormK
+}
+
+// NewSensitivity returns a new structure to compute sensitivities
+//  Input:
+//   d     -- domain after solving a stage with Main.KeepLinSol = true
+//   tags  -- tags of cells with design variables. empty => all (except rod-joints)
+//   penal -- penalisation exponent p. penal ≤ 0 => 1
+func NewSensitivity(d *Domain, tags []int, penal float64) (o *Sensitivity, err error) {
+
+	// check
+	if d.Distr {
+		return nil, chk.Err("sensitivities are not available in parallel runs")
+	}
+	if d.InitLSol {
+		return nil, chk.Err("linear solver of domain is not initialised; a stage must be solved with Main.KeepLinSol = true before computing sensitivities")
+	}
+
+	// new structure
+	o = new(Sensitivity)
+	o.Dom = d
+	o.Penal = penal
+	if o.Penal <= 0 {
+		o.Penal = 1
+	}
+
+	// elements with design variables
+	tagmap := make(map[int]bool)
+	for _, tag := range tags {
+		tagmap[tag] = true
+	}
+	o.Groups = make(map[int][]ele.Element)
+	for _, e := range d.Elems {
+		if _, isjoint := e.(*solid.Rjoint); isjoint {
+			continue
+		}
+		cid := e.Id()
+		if len(tagmap) > 0 && !tagmap[d.Msh.Cells[cid].Tag] {
+			continue
+		}
+		o.Cids = append(o.Cids, cid)
+		o.Groups[cid] = []ele.Element{e}
+	}
+	if len(o.Cids) == 0 {
+		return nil, chk.Err("there are no elements with design variables (tags = %v)", tags)
+	}
+
+	// rod-joints are scaled with their rods
+	for _, e := range d.Elems {
+		if jnt, isjoint := e.(*solid.Rjoint); isjoint {
+			rid := jnt.Rod.Id()
+			if _, ok := o.Groups[rid]; ok {
+				o.Groups[rid] = append(o.Groups[rid], e)
+			}
+		}
+	}
+
+	// elements must compute aᵀK_e b with their own K_e matrices and equations
+	for _, e := range d.Elems {
+		if _, ok := e.(ele.WithQuadFormK); !ok {
+			return nil, chk.Err("sensitivities cannot be computed with element (eid=%d) because it does not implement ele.WithQuadFormK", e.Id())
+		}
+	}
+
+	// workspace
+	o.kmat = new(la.Triplet)
+	o.kmat.Init(d.Nyb, d.Nyb, d.NnzKb)
+	return
+}
+
+// Compliance computes the compliance C = fᵀu and its sensitivities
+//  Output:
+//   C    -- compliance; computed with C = uᵀ K u
+//   dCds -- [ncids] cell id => dC/ds_e = -p uᵀ K_e u
+func (o *Sensitivity) Compliance() (C float64, dCds map[int]float64, err error) {
+
+	// compliance
+	d := o.Dom
+	for _, e := range d.Elems {
+		uKu, err := o.quadform(e, d.Sol.Y, d.Sol.Y)
+		if err != nil {
+			return 0, nil, chk.Err("cannot compute compliance of element # %d:\n%v", e.Id(), err)
+		}
+		C += uKu
+	}
+
+	// sensitivities; self-adjoint problem: λ = u
+	dCds, err = o.calc_sens(d.Sol.Y)
+	return
+}
+
+// Functional computes the displacement functional J = lᵀu and its sensitivities
+//  Input:
+//   l -- [ny] weights of dofs; e.g. with one non-zero component to compute the sensitivity of the
+//        displacement of one node
+//  Output:
+//   J    -- value of functional
+//   dJds -- [ncids] cell id => dJ/ds_e = -p λᵀ K_e u where K λ = l (adjoint problem)
+func (o *Sensitivity) Functional(l []float64) (J float64, dJds map[int]float64, err error) {
+	Js, dJdss, err := o.Functionals([][]float64{l}, 1)
+	if err != nil {
+		return
+	}
+	return Js[0], dJdss[0], nil
+}
+
+// Functionals computes many displacement functionals J_k = l_kᵀu and their sensitivities by solving
+// all adjoint problems in one batch
+//  Input:
+//   L        -- [nfcn][ny] weights of dofs of each functional
+//   nworkers -- number of concurrent solves; see LinSolver.SolveMultiR
+//  Output:
+//   J    -- [nfcn] values of functionals
+//   dJds -- [nfcn][ncids] cell id => dJ_k/ds_e
+func (o *Sensitivity) Functionals(L [][]float64, nworkers int) (J []float64, dJds []map[int]float64, err error) {
+
+	// check
+	d := o.Dom
+	if d.InitLSol {
+		return nil, nil, chk.Err("linear solver must be initialised and the matrix factorised before computing functionals; e.g. with Main.KeepLinSol = true")
+	}
+	for k, l := range L {
+		if len(l) != d.Ny {
+			return nil, nil, chk.Err("size of weights vector # %d must be equal to the number of dofs = %d. %d is invalid", k, d.Ny, len(l))
+		}
+	}
+
+	// values of functionals and right-hand sides of adjoint problems; with homogeneous constraints
+	nfcn := len(L)
+	J = make([]float64, nfcn)
+	B := la.MatAlloc(nfcn, d.Nyb)
+	for k, l := range L {
+		for i := 0; i < d.Ny; i++ {
+			J[k] += l[i] * d.Sol.Y[i]
+		}
+		copy(B[k], l)
+		for eq := range d.EssenBcs.ElimEq {
+			B[k][eq] = 0
+		}
+	}
+
+	// adjoint problems
+	Λ := la.MatAlloc(nfcn, d.Nyb)
+	err = d.LinSol.SolveMultiR(Λ, B, nworkers)
+	if err != nil {
+		return nil, nil, chk.Err("solution of adjoint problems failed:\n%v", err)
+	}
+
+	// sensitivities
+	dJds = make([]map[int]float64, nfcn)
+	for k := 0; k < nfcn; k++ {
+		dJds[k], err = o.calc_sens(Λ[k])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Displacement computes the displacement (dof) of a vertex and its sensitivities
+//  Input:
+//   vid -- vertex id
+//   key -- dof key; e.g. "uy"
+func (o *Sensitivity) Displacement(vid int, key string) (u float64, duds map[int]float64, err error) {
+	d := o.Dom
+	if vid < 0 || vid >= len(d.Vid2node) || d.Vid2node[vid] == nil {
+		return 0, nil, chk.Err("cannot find active node with vertex id = %d", vid)
+	}
+	eq := d.Vid2node[vid].GetEq(key)
+	if eq < 0 {
+		return 0, nil, chk.Err("cannot find dof %q at vertex %d", key, vid)
+	}
+	l := make([]float64, d.Ny)
+	l[eq] = 1
+	return o.Functional(l)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// calc_sens computes -p λᵀ K_e u for all groups of elements
+func (o *Sensitivity) calc_sens(λ []float64) (sens map[int]float64, err error) {
+	d := o.Dom
+	sens = make(map[int]float64)
+	for _, cid := range o.Cids {
+		var s float64
+		for _, e := range o.Groups[cid] {
+			lKu, err := o.quadform(e, λ, d.Sol.Y)
+			if err != nil {
+				return nil, chk.Err("cannot compute sensitivity of element # %d:\n%v", cid, err)
+			}
+			s += lKu
+		}
+		sens[cid] = -o.Penal * s
+	}
+	return
+}
+
+// quadform computes aᵀ K_e b, where K_e is the (tangent) stiffness matrix of element e
+//  Note: AddToKb computes K_e; then, the product is computed with K_e and the equations of the element
+func (o *Sensitivity) quadform(e ele.Element, a, b []float64) (res float64, err error) {
+	o.kmat.Start()
+	err = e.AddToKb(o.kmat, o.Dom.Sol, false)
+	if err != nil {
+		return
+	}
+	res, ok := e.(ele.WithQuadFormK).QuadFormK(a, b)
+	if !ok {
+		return 0, chk.Err("element (eid=%d) cannot compute aᵀK_e b", e.Id())
+	}
+	return
+}
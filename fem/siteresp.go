@@ -0,0 +1,441 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"math"
+	"math/cmplx"
+	"path"
+	"sort"
+
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/inp"
+	mdlsolid "github.com/cpmech/gofem/mdl/solid"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// SiteResponse implements equivalent-linear site response analyses of 1D soil columns extracted
+// from the mesh (see inp.SiteRespData). The vertically propagating shear waves in each column are
+// computed in the frequency domain with the complex moduli G* = G (1 + 2 i ξ) of layers: the
+// displacements in layer m (depth z from the top of layer) are
+//  u = A_m exp(i k*_m z) + B_m exp(-i k*_m z)   with   k*_m = ω √(ρ_m / G*_m)
+// with A_0 = B_0 = 1 at the free surface and, at the interfaces,
+//  A_m+1 = ½ A_m (1 + α*_m) exp(i k*_m h_m) + ½ B_m (1 - α*_m) exp(-i k*_m h_m)
+//  B_m+1 = ½ A_m (1 - α*_m) exp(i k*_m h_m) + ½ B_m (1 + α*_m) exp(-i k*_m h_m)
+// where α*_m = √(ρ_m G*_m) / √(ρ_m+1 G*_m+1) is the impedance ratio. The maximum shear strains at
+// the middle of layers are computed by the inverse transform and the properties of layers are
+// updated with the effective strains (see mdl/solid Hysteretic.Equivalent) until convergence
+//  Note: (1) only serial runs are supported; the columns are extracted from the elements of stage #0
+//        (2) the thickness of layers is the vertical extent of cells; thus the mesh should be
+//            structured with horizontal layers along the columns
+type SiteResponse struct {
+	Dat  *inp.SiteRespData // input data
+	Cols []*SrColumn       // columns
+
+	// auxiliary
+	acc []complex128 // [n] Fourier transform of input acceleration (zero padded; n is a power of 2)
+	ω   []float64    // [n/2+1] circular frequencies
+}
+
+// SrColumn holds a soil column of site response analyses
+type SrColumn struct {
+	X      []float64  // horizontal coordinates
+	Layers []*SrLayer // layers from top to bottom
+	F1     float64    // fundamental frequency [Hz] with strain-compatible properties
+	F1min  float64    // fundamental frequency [Hz] with small-strain properties
+	Amax   float64    // peak acceleration at surface
+	Nit    int        // number of iterations
+}
+
+// SrLayer holds a layer (cell) of soil columns
+type SrLayer struct {
+	Cid  int     // cell id
+	Top  float64 // elevation of top
+	H    float64 // thickness
+	Rho  float64 // density
+	Gmax float64 // small-strain shear modulus
+	Dmin float64 // small-strain damping ratio
+	G    float64 // strain-compatible shear modulus
+	Damp float64 // strain-compatible damping ratio
+	Gam  float64 // maximum shear strain at the middle of layer
+
+	// auxiliary
+	mdl *mdlsolid.Hysteretic // model with strain dependent properties; nil => constant properties
+}
+
+// run_site_response runs the equivalent-linear site response analysis of the first domain before
+// the stages and, if requested, sets the damping of elements in the following stages
+func (o *Main) run_site_response() (err error) {
+
+	// check
+	dat := o.Sim.SiteResp
+	if len(o.Domains) != 1 || o.Nproc > 1 {
+		return chk.Err("site response analyses are only available in serial runs with one domain")
+	}
+	d := o.Domains[0]
+
+	// columns with elements of first stage
+	err = o.SetStage(0)
+	if err != nil {
+		return
+	}
+	accel, err := o.Sim.Functions.Get(dat.Accel)
+	if err != nil {
+		return chk.Err("cannot get input acceleration of site response analysis:\n%v", err)
+	}
+	sr, err := NewSiteResponse(d, dat, accel)
+	if err != nil {
+		return
+	}
+
+	// run and save results
+	err = sr.Run()
+	if err != nil {
+		return
+	}
+	err = sr.Save(o.Sim.DirOut, o.Sim.Key, false)
+	if err != nil {
+		return
+	}
+	if o.ShowMsg {
+		for i, col := range sr.Cols {
+			io.Pf("> Site response of column %d: %d iterations; f1 = %g (small strains: %g); amax = %g\n", i, col.Nit, col.F1, col.F1min, col.Amax)
+		}
+	}
+
+	// damping of elements
+	if dat.Apply != "" {
+		d.SiteResp = sr
+	}
+	return
+}
+
+// NewSiteResponse extracts the columns from the solid elements of domain and computes the Fourier
+// transform of the input acceleration
+func NewSiteResponse(d *Domain, dat *inp.SiteRespData, accel fun.Func) (o *SiteResponse, err error) {
+
+	// check
+	if d.Distr {
+		return nil, chk.Err("site response analyses cannot be run in parallel")
+	}
+
+	// columns
+	o = &SiteResponse{Dat: dat}
+	for _, xc := range dat.Columns {
+		var col *SrColumn
+		col, err = new_sr_column(d, xc)
+		if err != nil {
+			return
+		}
+		o.Cols = append(o.Cols, col)
+	}
+
+	// input acceleration
+	o.acc, o.ω = sr_input(dat, accel)
+	return
+}
+
+// Run computes the strain-compatible properties of all columns
+func (o *SiteResponse) Run() (err error) {
+	for _, col := range o.Cols {
+		err = col.run(o.Dat, o.acc, o.ω)
+		if err != nil {
+			return chk.Err("site response of column at %v failed:\n%v", col.X, err)
+		}
+	}
+	return
+}
+
+// Save saves the strain-compatible properties of layers to dirout/fnkey_siteresp.res
+func (o *SiteResponse) Save(dirout, fnkey string, verbose bool) (err error) {
+	var b bytes.Buffer
+	for i, col := range o.Cols {
+		io.Ff(&b, "# column %d at %v: nit = %d  f1 = %g  f1(small strains) = %g  amax(surface) = %g\n", i, col.X, col.Nit, col.F1, col.F1min, col.Amax)
+		io.Ff(&b, "%8s%8s%23s%23s%23s%23s%23s%23s%23s\n", "col", "cid", "top", "h", "gmax", "g", "dmin", "damp", "gam")
+		for _, l := range col.Layers {
+			io.Ff(&b, "%8d%8d%23.15e%23.15e%23.15e%23.15e%23.15e%23.15e%23.15e\n", i, l.Cid, l.Top, l.H, l.Gmax, l.G, l.Dmin, l.Damp, l.Gam)
+		}
+	}
+	return save_file(path.Join(dirout, fnkey+"_siteresp.res"), &b, verbose)
+}
+
+// apply sets the damping coefficients of the solid elements in columns; i.e. the mass proportional
+// damping c = 2 ρ ξ ω1 gives the damping ratio ξ at the fundamental frequency ω1 of column. The
+// coefficients of cells crossed by many columns are averaged
+func (o *SiteResponse) apply(d *Domain) {
+	sum := make(map[int]float64)
+	cnt := make(map[int]int)
+	for _, col := range o.Cols {
+		for _, l := range col.Layers {
+			ξ, ω1 := l.Damp, 2.0*math.Pi*col.F1
+			if o.Dat.Apply == "min" {
+				ξ, ω1 = l.Dmin, 2.0*math.Pi*col.F1min
+			}
+			sum[l.Cid] += 2.0 * l.Rho * ξ * ω1
+			cnt[l.Cid]++
+		}
+	}
+	for cid, c := range sum {
+		if sld, ok := d.Cid2elem[cid].(*solid.Solid); ok {
+			sld.Cdam = c / float64(cnt[cid])
+		}
+	}
+}
+
+// run performs the equivalent-linear iterations of column
+func (o *SrColumn) run(dat *inp.SiteRespData, acc []complex128, ω []float64) (err error) {
+	for _, l := range o.Layers {
+		l.G, l.Damp = l.Gmax, l.Dmin
+	}
+	o.F1min = o.fundamental()
+	converged := false
+	for o.Nit = 1; o.Nit <= dat.MaxIt; o.Nit++ {
+		err = o.solve(dat, acc, ω)
+		if err != nil {
+			return
+		}
+		var δ float64
+		for _, l := range o.Layers {
+			if l.mdl == nil {
+				continue
+			}
+			G, ξ := l.mdl.Equivalent(l.Gam)
+			δ = math.Max(δ, math.Abs(G-l.G)/G)
+			l.G, l.Damp = G, ξ
+		}
+		if δ <= dat.Tol {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return chk.Err("equivalent-linear iterations did not converge after %d iterations", dat.MaxIt)
+	}
+	o.F1 = o.fundamental()
+	return o.solve(dat, acc, ω) // strains and accelerations with final properties
+}
+
+// solve computes the maximum shear strains of layers and the peak acceleration at surface with the
+// current properties of layers
+func (o *SrColumn) solve(dat *inp.SiteRespData, acc []complex128, ω []float64) (err error) {
+	n, nl := len(acc), len(o.Layers)
+	γ := make([][]complex128, nl)
+	for m := 0; m < nl; m++ {
+		γ[m] = make([]complex128, n)
+	}
+	as := make([]complex128, n)
+	for j := 1; j < len(ω); j++ {
+		hs, hγ := o.transfer(dat, ω[j])
+		if cmplx.IsNaN(hs) || cmplx.IsInf(hs) {
+			return chk.Err("transfer function cannot be computed at ω = %g", ω[j])
+		}
+		u := -acc[j] / complex(ω[j]*ω[j], 0) // displacements
+		as[j] = hs * acc[j]
+		for m := 0; m < nl; m++ {
+			γ[m][j] = hγ[m] * u
+		}
+		if j < n/2 { // negative frequencies
+			as[n-j] = cmplx.Conj(as[j])
+			for m := 0; m < nl; m++ {
+				γ[m][n-j] = cmplx.Conj(γ[m][j])
+			}
+		}
+	}
+	sr_fft(as, true)
+	o.Amax = sr_peak(as)
+	for m, l := range o.Layers {
+		sr_fft(γ[m], true)
+		l.Gam = sr_peak(γ[m])
+	}
+	return
+}
+
+// transfer computes the transfer functions between the input motion and the motion at surface (hs)
+// and the shear strains at the middle of layers (hγ; from the displacements of input motion)
+func (o *SrColumn) transfer(dat *inp.SiteRespData, ω float64) (hs complex128, hγ []complex128) {
+	nl := len(o.Layers)
+	hγ = make([]complex128, nl)
+	A, B := complex(1, 0), complex(1, 0)
+	var k, ea, eb complex128
+	for m, l := range o.Layers {
+		Gs := complex(l.G, 2.0*l.G*l.Damp)
+		k = complex(ω, 0) * cmplx.Sqrt(complex(l.Rho, 0)/Gs)
+		hγ[m] = complex(0, 1) * k * (A*cmplx.Exp(complex(0, 0.5*l.H)*k) - B*cmplx.Exp(complex(0, -0.5*l.H)*k))
+		ea, eb = A*cmplx.Exp(complex(0, l.H)*k), B*cmplx.Exp(complex(0, -l.H)*k)
+		var α complex128
+		switch {
+		case m < nl-1:
+			next := o.Layers[m+1]
+			α = cmplx.Sqrt(complex(l.Rho, 0)*Gs) / cmplx.Sqrt(complex(next.Rho, 0)*complex(next.G, 2.0*next.G*next.Damp))
+		case dat.RockVs > 0:
+			Gr := dat.RockRho * dat.RockVs * dat.RockVs
+			α = cmplx.Sqrt(complex(l.Rho, 0)*Gs) / cmplx.Sqrt(complex(dat.RockRho, 0)*complex(Gr, 2.0*Gr*dat.RockDamp))
+		default:
+			A, B = ea, eb // rigid base
+			continue
+		}
+		A, B = 0.5*(ea*(1+α)+eb*(1-α)), 0.5*(ea*(1-α)+eb*(1+α))
+	}
+	norm := A + B // within motion at rigid base
+	if dat.RockVs > 0 {
+		norm = 2.0 * A // outcrop motion
+	}
+	hs = 2.0 / norm
+	for m := 0; m < nl; m++ {
+		hγ[m] /= norm
+	}
+	return
+}
+
+// fundamental computes the fundamental frequency [Hz] of column with the current properties of
+// layers; i.e. f1 = vs / (4 H) where vs is the average velocity of shear waves (travel time)
+func (o *SrColumn) fundamental() float64 {
+	var t float64
+	for _, l := range o.Layers {
+		t += l.H / math.Sqrt(l.G/l.Rho)
+	}
+	return 1.0 / (4.0 * t)
+}
+
+// new_sr_column extracts the column at horizontal coordinates xc from the solid elements of domain
+func new_sr_column(d *Domain, xc []float64) (o *SrColumn, err error) {
+
+	// cells crossed by vertical line
+	ndim := d.Msh.Ndim
+	o = &SrColumn{X: xc}
+	var layers []*SrLayer
+	for _, e := range d.Elems {
+		sld, ok := e.(*solid.Solid)
+		if !ok {
+			continue
+		}
+		inside := true
+		for i := 0; i < ndim-1; i++ {
+			xmin, xmax := sld.X[i][0], sld.X[i][0]
+			for _, x := range sld.X[i] {
+				xmin, xmax = math.Min(xmin, x), math.Max(xmax, x)
+			}
+			if xc[i] < xmin || xc[i] > xmax {
+				inside = false
+				break
+			}
+		}
+		if !inside {
+			continue
+		}
+		zmin, zmax := sld.X[ndim-1][0], sld.X[ndim-1][0]
+		for _, z := range sld.X[ndim-1] {
+			zmin, zmax = math.Min(zmin, z), math.Max(zmax, z)
+		}
+		l := &SrLayer{Cid: sld.Cell.Id, Top: zmax, H: zmax - zmin, Rho: sld.Mdl.GetRho()}
+		switch m := sld.Mdl.(type) {
+		case *mdlsolid.Hysteretic:
+			l.Gmax, l.Dmin, l.mdl = m.G, m.Dmin, m
+		case *mdlsolid.LinElast:
+			l.Gmax = m.G
+		default:
+			return nil, chk.Err("site response analyses require \"hysteretic\" or \"lin-elast\" models. cell # %d is invalid", l.Cid)
+		}
+		if l.Rho <= 0 || l.Gmax <= 0 {
+			return nil, chk.Err("density and shear modulus of cell # %d must be positive for site response analyses", l.Cid)
+		}
+		layers = append(layers, l)
+	}
+	if len(layers) == 0 {
+		return nil, chk.Err("cannot find solid cells crossed by the vertical line at %v", xc)
+	}
+
+	// sort from top to bottom; skip cells with the same elevations (line along boundaries of cells)
+	sort.Sort(sr_layers(layers))
+	tol := 1e-8 * (layers[0].Top - (layers[len(layers)-1].Top - layers[len(layers)-1].H))
+	bottom := layers[0].Top
+	for _, l := range layers {
+		switch {
+		case l.Top > bottom+tol: // overlap
+			continue
+		case l.Top < bottom-tol:
+			return nil, chk.Err("column at %v has a gap between elevations %g and %g", xc, l.Top, bottom)
+		}
+		o.Layers = append(o.Layers, l)
+		bottom = l.Top - l.H
+	}
+	return
+}
+
+// sr_input computes the Fourier transform of the input acceleration (zero padded) and the circular
+// frequencies
+func sr_input(dat *inp.SiteRespData, accel fun.Func) (acc []complex128, ω []float64) {
+	nt := int(dat.Tf/dat.Dt) + 1
+	n := 1
+	for n < 2*nt { // zero padding (quiet zone) to avoid wrap-around
+		n *= 2
+	}
+	acc = make([]complex128, n)
+	for k := 0; k < nt; k++ {
+		acc[k] = complex(accel.F(float64(k)*dat.Dt, nil), 0)
+	}
+	sr_fft(acc, false)
+	ω = make([]float64, n/2+1)
+	for j := 0; j <= n/2; j++ {
+		ω[j] = 2.0 * math.Pi * float64(j) / (float64(n) * dat.Dt)
+	}
+	return
+}
+
+// sr_layers sorts layers from top to bottom
+type sr_layers []*SrLayer
+
+func (o sr_layers) Len() int           { return len(o) }
+func (o sr_layers) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o sr_layers) Less(i, j int) bool { return o[i].Top > o[j].Top }
+
+// sr_fft computes the discrete Fourier transform of x in place (radix-2; len(x) must be a power of 2)
+//  X_j = Σ_k x_k exp(-2π i j k / n)          (forward)
+//  x_k = 1/n Σ_j X_j exp(2π i j k / n)      (inverse)
+func sr_fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ { // bit reversal
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		w := cmplx.Exp(complex(0, sign*2.0*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			wk := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], wk*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				wk *= w
+			}
+		}
+	}
+	if inverse {
+		for i := 0; i < n; i++ {
+			x[i] /= complex(float64(n), 0)
+		}
+	}
+}
+
+// sr_peak returns the maximum absolute value of the real parts of x
+func sr_peak(x []complex128) (peak float64) {
+	for _, v := range x {
+		peak = math.Max(peak, math.Abs(real(v)))
+	}
+	return
+}
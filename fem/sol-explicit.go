@@ -0,0 +1,266 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// Explicit solves the dynamics of solids using the explicit central difference method (in its
+// velocity Verlet form) with a diagonal (lumped) mass matrix; e.g. for the propagation of waves
+// with spectral elements (see shp.SemType)
+//  Note: (1) all equations must be displacements (second order in time); i.e. solids only
+//        (2) the time step must be smaller than the critical one; i.e. about the smallest distance
+//            between nodes divided by the P-wave velocity (see inp.DtReport). The run stops with an
+//            error if the solution diverges
+//        (3) only single-point essential boundary conditions are supported; their values are set
+//            directly. Constraints between equations (e.g. inclined supports) are not available
+//        (4) the Newmark coefficients and element damping are not used
+//        (5) only serial runs are supported
+type Explicit struct {
+	doms []*Domain
+	sum  *Summary
+	dc   *ele.DynCoefs
+}
+
+// set factory
+func init() {
+	allocators["exp"] = func(doms []*Domain, sum *Summary, dc *ele.DynCoefs) Solver {
+		solver := new(Explicit)
+		solver.doms = doms
+		solver.sum = sum
+		solver.dc = dc
+		return solver
+	}
+}
+
+func (o *Explicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool, dbgKb DebugKb_t) (err error) {
+
+	// lumped masses and initial accelerations
+	masses := make([][]float64, len(o.doms))
+	for i, d := range o.doms {
+		masses[i], err = explicit_masses(d)
+		if err != nil {
+			return
+		}
+		err = explicit_accel(d, masses[i], d.Sol.T)
+		if err != nil {
+			return chk.Err("cannot compute initial accelerations:\n%v", err)
+		}
+	}
+
+	// control
+	t := o.doms[0].Sol.T
+	tout := t + dtoFunc.F(t, nil)
+	prog := new_progress(t, tf)
+
+	// first output
+	if o.sum != nil {
+		err = o.sum.SaveDomains(t, o.doms, false)
+		if err != nil {
+			return chk.Err("cannot save results:\n%v", err)
+		}
+	}
+
+	// message
+	if verbose {
+		defer func() { io.Pf("\n") }()
+	}
+
+	// time loop
+	var Δt float64
+	var lasttimestep bool
+	for t < tf {
+
+		// time increment
+		Δt = dtFunc.F(t, nil)
+		if t+Δt >= tf {
+			lasttimestep = true
+		}
+		t += Δt
+
+		// for all domains
+		for i, d := range o.doms {
+			err = explicit_step(d, masses[i], t, Δt)
+			if err != nil {
+				return chk.Err("explicit time step failed @ t = %g:\n%v", t, err)
+			}
+		}
+
+		// message
+		if verbose {
+			prog.print(t, 0)
+		}
+
+		// envelopes of internal forces of members
+		for _, d := range o.doms {
+			if d.Menv != nil {
+				d.Menv.Step(d)
+			}
+		}
+
+		// live monitoring
+		if o.doms[0].Mon != nil {
+			o.doms[0].Mon.step(o.doms[0])
+		}
+
+		// perform output
+		if t >= tout || lasttimestep {
+			if o.sum != nil {
+				err = o.sum.SaveDomains(t, o.doms, false)
+				if err != nil {
+					return chk.Err("cannot save results:\n%v", err)
+				}
+			}
+			tout += dtoFunc.F(t, nil)
+		}
+
+		// interruption: save state and stop
+		if interrupted(o.doms) {
+			return save_interrupted(o.doms, o.sum, t)
+		}
+	}
+	return
+}
+
+// explicit_masses checks whether domain d can be solved by the explicit solver and computes the
+// diagonal of the lumped mass matrix
+func explicit_masses(d *Domain) (m []float64, err error) {
+
+	// check domain
+	if d.Distr {
+		return nil, chk.Err("the explicit solver is not available in parallel runs")
+	}
+	if d.Sim.Data.Steady {
+		return nil, chk.Err("the explicit solver requires a transient simulation; i.e. steady = false")
+	}
+	if len(d.T1eqs) > 0 || len(d.T2eqs) != d.Ny {
+		return nil, chk.Err("the explicit solver requires all equations to be of second order in time; i.e. displacements only")
+	}
+	for _, bc := range d.EssenBcs.Bcs {
+		if len(bc.Eqs) != 1 || bc.ValsA[0] == 0 {
+			return nil, chk.Err("the explicit solver cannot handle the constraint %q between many equations", bc.Key)
+		}
+	}
+
+	// lumped masses
+	m = make([]float64, d.Ny)
+	for _, e := range d.Elems {
+		el, ok := e.(ele.WithLumpedMass)
+		if !ok {
+			return nil, chk.Err("element of cell # %d cannot compute the lumped mass matrix required by the explicit solver", e.Id())
+		}
+		err = el.AddToLumpedMass(m, d.Sol)
+		if err != nil {
+			return
+		}
+	}
+
+	// check masses of free equations
+	prescribed := make(map[int]bool)
+	for _, bc := range d.EssenBcs.Bcs {
+		prescribed[bc.Eqs[0]] = true
+	}
+	for eq, mass := range m {
+		if mass <= 0 && !prescribed[eq] {
+			return nil, chk.Err("the lumped mass of equation %d is zero. all materials must have a density", eq)
+		}
+	}
+	return
+}
+
+// explicit_step advances the solution of domain d from t - Δt to t
+func explicit_step(d *Domain, m []float64, t, Δt float64) (err error) {
+
+	// velocities @ t - Δt/2 and increments of displacements
+	for i := 0; i < d.Ny; i++ {
+		d.Sol.Dydt[i] += 0.5 * Δt * d.Sol.D2ydt2[i]
+		d.Sol.ΔY[i] = Δt * d.Sol.Dydt[i]
+	}
+
+	// prescribed displacements
+	for _, bc := range d.EssenBcs.Bcs {
+		eq := bc.Eqs[0]
+		d.Sol.ΔY[eq] = bc.Fcn.F(t, nil)/bc.ValsA[0] - d.Sol.Y[eq]
+		d.Sol.Dydt[eq] = d.Sol.ΔY[eq] / Δt
+	}
+
+	// update displacements
+	for i := 0; i < d.Ny; i++ {
+		d.Sol.Y[i] += d.Sol.ΔY[i]
+		if math.IsNaN(d.Sol.Y[i]) || math.IsInf(d.Sol.Y[i], 0) {
+			return chk.Err("the solution diverged. Δt = %g may be larger than the critical time step", Δt)
+		}
+	}
+	d.Sol.T = t
+	d.Sol.Dt = Δt
+
+	// update stresses
+	for _, e := range d.ElemIntvars {
+		e.BackupIvs(false)
+	}
+	err = d.UpdateElems()
+	if err != nil {
+		return
+	}
+
+	// accelerations and velocities @ t
+	err = explicit_accel(d, m, t)
+	if err != nil {
+		return
+	}
+	for i := 0; i < d.Ny; i++ {
+		d.Sol.Dydt[i] += 0.5 * Δt * d.Sol.D2ydt2[i]
+	}
+	return
+}
+
+// explicit_accel computes the accelerations a = (fext - fint) / m of domain d at time t
+func explicit_accel(d *Domain, m []float64, t float64) (err error) {
+
+	// residual without inertia terms
+	steady := d.Sol.Steady
+	d.Sol.Steady = true
+	defer func() { d.Sol.Steady = steady }()
+	la.VecFill(d.Fb, 0)
+	for _, e := range d.Elems {
+		if d.Batches.HasRhs(e) {
+			continue
+		}
+		err = e.AddToRhs(d.Fb, d.Sol)
+		if err != nil {
+			return
+		}
+	}
+	err = d.Batches.AddToRhs(d.Fb, d.Sol)
+	if err != nil {
+		return
+	}
+	for _, ml := range d.MovLoads {
+		ml.AddToRhs(d.Fb, t)
+	}
+	for _, sc := range d.Surcharges {
+		sc.AddToRhs(d.Fb, t)
+	}
+	d.PtNatBcs.AddToRhs(d.Fb, t)
+
+	// accelerations
+	for i := 0; i < d.Ny; i++ {
+		d.Sol.D2ydt2[i] = 0
+		if m[i] > 0 {
+			d.Sol.D2ydt2[i] = d.Fb[i] / m[i]
+		}
+	}
+	for _, bc := range d.EssenBcs.Bcs {
+		d.Sol.D2ydt2[bc.Eqs[0]] = 0
+	}
+	return
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
-	"github.com/cpmech/gosl/mpi"
 )
 
 // Implicit solves FEM problem using an implicit procedure (with Newthon-Raphson method)
@@ -44,6 +43,7 @@ func (o *Implicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool, dbgKb
 	dat := o.doms[0].Sim.Solver
 	tout := t + dtoFunc.F(t, nil)
 	steady := o.doms[0].Sim.Data.Steady
+	prog := new_progress(t, tf)
 
 	// first output
 	if o.sum != nil {
@@ -88,11 +88,6 @@ func (o *Implicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool, dbgKb
 			}
 		}
 
-		// message
-		if verbose && !dat.ShowR {
-			io.Pf("> Time = %f\r", t)
-		}
-
 		// for all domains
 		docontinue := false
 		for _, d := range o.doms {
@@ -132,8 +127,83 @@ func (o *Implicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool, dbgKb
 			continue
 		}
 
+		// message
+		if verbose && !dat.ShowR {
+			prog.print(t, o.doms[0].Nit)
+		}
+
+		// element erosion, installation of lining, propagation of (discrete and embedded) cracks and
+		// update of coordinates with ALE mesh smoothing
+		for _, d := range o.doms {
+			if d.Eros != nil {
+				err = d.Eros.Step(d)
+				if err != nil {
+					return chk.Err("element erosion failed:\n%v", err)
+				}
+			}
+			if d.Relax != nil {
+				err = d.Relax.Step(d)
+				if err != nil {
+					return chk.Err("installation of lining failed:\n%v", err)
+				}
+			}
+			if d.Crack != nil {
+				err = d.Crack.Step(d)
+				if err != nil {
+					return chk.Err("propagation of cracks failed:\n%v", err)
+				}
+			}
+			if d.Track != nil {
+				err = d.Track.Step(d)
+				if err != nil {
+					return chk.Err("tracking of embedded cracks failed:\n%v", err)
+				}
+			}
+			if d.Ale != nil {
+				err = d.Ale.Step(d)
+				if err != nil {
+					return chk.Err("ALE mesh smoothing failed:\n%v", err)
+				}
+			}
+		}
+
+		// water balance
+		for _, d := range o.doms {
+			if d.WBal != nil {
+				err = d.WBal.Step(d, Δt, verbose)
+				if err != nil {
+					return chk.Err("water balance failed:\n%v", err)
+				}
+			}
+		}
+
+		// envelopes of internal forces of members
+		for _, d := range o.doms {
+			if d.Menv != nil {
+				d.Menv.Step(d)
+			}
+		}
+
+		// hydraulic gradients
+		for _, d := range o.doms {
+			if d.Pip != nil {
+				err = d.Pip.Step(d, verbose)
+				if err != nil {
+					return chk.Err("check of hydraulic gradients failed:\n%v", err)
+				}
+			}
+		}
+
+		// live monitoring
+		if o.doms[0].Mon != nil {
+			o.doms[0].Mon.step(o.doms[0])
+		}
+
+		// steady-state detection
+		stop := steady_reached(o.doms, Δt, o.sum, verbose)
+
 		// perform output
-		if t >= tout || lasttimestep {
+		if t >= tout || lasttimestep || stop {
 			if o.sum != nil {
 				err = o.sum.SaveDomains(t, o.doms, false)
 				if err != nil {
@@ -142,17 +212,58 @@ func (o *Implicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool, dbgKb
 			}
 			tout += dtoFunc.F(t, nil)
 		}
+
+		// interruption: save state and stop
+		if interrupted(o.doms) {
+			return save_interrupted(o.doms, o.sum, t)
+		}
+
+		// stop stage before tf
+		if stop {
+			break
+		}
+
+		// cycle jumping
+		if o.doms[0].CycJump != nil {
+			if len(o.doms) > 1 {
+				return chk.Err("cycle jumping is not available with more than one domain")
+			}
+			Δtj, err := o.doms[0].CycJump.Step(o.doms[0], tf, verbose)
+			if err != nil {
+				return chk.Err("cycle jumping failed:\n%v", err)
+			}
+			t += Δtj
+			tout += Δtj
+		}
 	}
 	return
 }
 
 // run_iterations solves the nonlinear problem
+//  Note: the staggered solution is carried out if d.Split != nil; see run_staggered
 func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, dbgKb DebugKb_t) (diverging bool, err error) {
 
+	// staggered solution
+	if d.Split != nil {
+		return run_staggered(t, Δt, d, dc, sum, dbgKb)
+	}
+
 	// zero accumulated increments
 	la.VecFill(d.Sol.ΔY, 0)
 
-	// calculate global starred vectors and interpolate starred variables from nodes to integration points
+	// starred variables
+	err = set_star_vars(d, dc)
+	if err != nil {
+		return
+	}
+
+	// iterations
+	return newton_iterations(t, d, dc, sum, dbgKb, true)
+}
+
+// set_star_vars calculates global starred vectors and interpolates starred variables from nodes to
+// integration points
+func set_star_vars(d *Domain, dc *ele.DynCoefs) (err error) {
 	β1 := dc.GetBet1()
 	β2 := dc.GetBet2()
 	α1 := dc.GetAlp1()
@@ -172,6 +283,9 @@ func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, d
 			d.Sol.Chi[I] = α4*d.Sol.Y[I] + α5*d.Sol.Dydt[I] + α6*d.Sol.D2ydt2[I]
 		}
 
+		// subcycling: starred vectors of the interpolated field
+		d.Split.fix_star_vecs(d, dc)
+
 		// set internal starred variables
 		for _, e := range d.Elems {
 			err = e.InterpStarVars(d.Sol)
@@ -181,12 +295,25 @@ func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, d
 			}
 		}
 	}
+	return
+}
+
+// newton_iterations runs the Newton-Raphson iterations of a time step
+//  first -- first call within the time step: the internal variables are backed up. Otherwise,
+//           they are restored from the backup copy before each update
+func newton_iterations(t float64, d *Domain, dc *ele.DynCoefs, sum *Summary, dbgKb DebugKb_t, first bool) (diverging bool, err error) {
+
+	// coefficients
+	β1 := dc.GetBet1()
+	α1 := dc.GetAlp1()
+	α4 := dc.GetAlp4()
 
 	// auxiliary variables
 	var it int
 	var largFb, largFb0, Lδu float64
 	var prevFb, prevLδu float64
 	dat := d.Sim.Solver
+	defer func() { d.Nit = it }()
 
 	// message
 	if dat.ShowR {
@@ -202,15 +329,30 @@ func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, d
 		// assemble right-hand side vector (fb) with negative of residuals
 		la.VecFill(d.Fb, 0)
 		for _, e := range d.Elems {
+			if d.Batches.HasRhs(e) {
+				continue
+			}
 			err = e.AddToRhs(d.Fb, d.Sol)
 			if err != nil {
 				return
 			}
 		}
+		err = d.Batches.AddToRhs(d.Fb, d.Sol)
+		if err != nil {
+			return
+		}
+
+		// moving loads and surcharges; e.g. train loads and footings
+		for _, ml := range d.MovLoads {
+			ml.AddToRhs(d.Fb, t)
+		}
+		for _, sc := range d.Surcharges {
+			sc.AddToRhs(d.Fb, t)
+		}
 
 		// join all fb
 		if d.Distr {
-			mpi.AllReduceSum(d.Fb, d.Wb) // this must be done here because there might be nodes sharing boundary conditions
+			all_reduce_sum(d.Sim.Solver.Determ, d.Fb, d.Wb) // this must be done here because there might be nodes sharing boundary conditions
 		}
 
 		// point natural boundary conditions; e.g. concentrated loads
@@ -219,6 +361,17 @@ func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, d
 		// essential boundary conditioins; e.g. constraints
 		d.EssenBcs.AddToRhs(d.Fb, d.Sol)
 
+		// springs holding nodes freed by element erosion or excavation
+		if d.Eros != nil {
+			d.Eros.AddToRhs(d.Fb, d.Sol)
+		}
+		if d.Relax != nil {
+			d.Relax.AddToRhs(d.Fb, d.Sol)
+		}
+
+		// staggered solution: zero residuals of frozen equations
+		d.Split.Freeze(d.Fb)
+
 		// find largest absolute component of fb
 		largFb = la.VecLargest(d.Fb, 1)
 
@@ -228,6 +381,9 @@ func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, d
 				sum.Resids.Append(it == 0, largFb)
 			}
 		}
+		if d.Mon != nil {
+			d.Mon.resid(largFb)
+		}
 
 		// check largFb value
 		if it == 0 {
@@ -260,20 +416,33 @@ func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, d
 			// assemble element matrices
 			d.Kb.Start()
 			for _, e := range d.Elems {
+				if d.Batches.Has(e) {
+					continue
+				}
 				err = e.AddToKb(d.Kb, d.Sol, it == 0)
 				if err != nil {
 					return
 				}
 			}
+			err = d.Batches.AddToKb(d.Kb, d.Sol, it == 0)
+			if err != nil {
+				return
+			}
+			if d.Eros != nil {
+				d.Eros.AddToKb(d.Kb)
+			}
+			if d.Relax != nil {
+				d.Relax.AddToKb(d.Kb)
+			}
 
 			// debug
 			if dbgKb != nil {
 				dbgKb(d, it)
 			}
 
-			// join A and tr(A) matrices into Kb
+			// essential bcs / constraints: join A and tr(A) matrices into Kb, penalty or elimination terms
 			if d.Proc == 0 {
-				d.Kb.PutMatAndMatT(&d.EssenBcs.A)
+				d.EssenBcs.AddToKb(d.Kb, d.Nyb)
 			}
 
 			// write smat matrix
@@ -295,11 +464,19 @@ func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, d
 			// perform factorisation
 			err = d.LinSol.Fact()
 			if err != nil {
-				err = chk.Err("factorisation failed:\n%v", err)
+				err = diagnose_kb(d, chk.Err("factorisation failed:\n%v", err))
 				return
 			}
+
+			// diagnostics of Kb
+			if it == 0 {
+				diagnose_kb(d, nil)
+			}
 		}
 
+		// coupling terms of eliminated constraints
+		d.EssenBcs.AddElimToRhs(d.Fb)
+
 		// solve for wb := δyb
 		err = d.LinSol.SolveR(d.Wb, d.Fb, false)
 		if err != nil {
@@ -332,7 +509,7 @@ func run_iterations(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, d
 		}
 
 		// backup / restore
-		if it == 0 {
+		if it == 0 && first {
 			// create backup copy of all secondary variables
 			for _, e := range d.ElemIntvars {
 				e.BackupIvs(false)
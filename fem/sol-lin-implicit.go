@@ -10,7 +10,6 @@ import (
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
-	"github.com/cpmech/gosl/mpi"
 )
 
 // LinearImplicit solves **linear** FEM problem using an implicit procedure
@@ -40,6 +39,7 @@ func (o *LinearImplicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool,
 	t := o.dom.Sol.T
 	tout := t + dtoFunc.F(t, nil)
 	steady := o.dom.Sim.Data.Steady
+	prog := new_progress(t, tf)
 
 	// first output
 	if o.sum != nil {
@@ -95,11 +95,6 @@ func (o *LinearImplicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool,
 			α6 = o.dc.GetAlp6()
 		}
 
-		// message
-		if verbose {
-			io.Pf("> Time = %f\r", t)
-		}
-
 		// calculate global starred vectors and interpolate starred variables from nodes to integration points
 		if !steady {
 
@@ -128,6 +123,12 @@ func (o *LinearImplicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool,
 			return chk.Err("solve_linear_problem failed:\n%v", err)
 		}
 		first = false
+		o.dom.Nit = 1
+
+		// message
+		if verbose {
+			prog.print(t, o.dom.Nit)
+		}
 
 		// update velocity and acceleration
 		if !steady {
@@ -140,8 +141,21 @@ func (o *LinearImplicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool,
 			}
 		}
 
+		// envelopes of internal forces of members
+		if o.dom.Menv != nil {
+			o.dom.Menv.Step(o.dom)
+		}
+
+		// live monitoring
+		if o.dom.Mon != nil {
+			o.dom.Mon.step(o.dom)
+		}
+
+		// steady-state detection
+		stop := steady_reached([]*Domain{o.dom}, Δt, o.sum, verbose)
+
 		// perform output
-		if t >= tout || lasttimestep {
+		if t >= tout || lasttimestep || stop {
 			if o.sum != nil {
 				err = o.sum.SaveDomains(t, []*Domain{o.dom}, false)
 				if err != nil {
@@ -150,6 +164,16 @@ func (o *LinearImplicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool,
 			}
 			tout += dtoFunc.F(t, nil)
 		}
+
+		// interruption: save state and stop
+		if interrupted([]*Domain{o.dom}) {
+			return save_interrupted([]*Domain{o.dom}, o.sum, t)
+		}
+
+		// stop stage before tf
+		if stop {
+			break
+		}
 	}
 	return
 }
@@ -158,17 +182,75 @@ func (o *LinearImplicit) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose bool,
 func solve_linear_problem(t float64, d *Domain, dc *ele.DynCoefs, sum *Summary, first bool) (err error) {
 
 	// assemble right-hand side vector (fb) with **negative** of residuals
+	err = assemble_linear_rhs(t, d)
+	if err != nil {
+		return
+	}
+
+	// assemble and factorise Jacobian matrix just once
+	if first {
+		err = assemble_and_fact_linear_kb(d)
+		if err != nil {
+			return
+		}
+	}
+
+	// coupling terms of eliminated constraints
+	d.EssenBcs.AddElimToRhs(d.Fb)
+
+	// solve for wb
+	err = d.LinSol.SolveR(d.Wb, d.Fb, false)
+	if err != nil {
+		err = chk.Err("solve failed:%v\n", err)
+		return
+	}
+
+	// update primary variables (y)
+	for i := 0; i < d.Ny; i++ {
+		d.Sol.Y[i] += d.Wb[i]  // y += δy
+		d.Sol.ΔY[i] += d.Wb[i] // ΔY += δy
+	}
+
+	// update Lagrange multipliers (λ)
+	for i := 0; i < d.Nlam; i++ {
+		d.Sol.L[i] += d.Wb[d.Ny+i] // λ += δλ
+	}
+
+	// update secondary variables
+	err = d.UpdateElems()
+	return
+}
+
+// assemble_linear_rhs assembles the right-hand side vector (fb) with **negative** of residuals
+func assemble_linear_rhs(t float64, d *Domain) (err error) {
+
+	// element contributions
 	la.VecFill(d.Fb, 0)
 	for _, e := range d.Elems {
+		if d.Batches.HasRhs(e) {
+			continue
+		}
 		err = e.AddToRhs(d.Fb, d.Sol)
 		if err != nil {
 			return
 		}
 	}
+	err = d.Batches.AddToRhs(d.Fb, d.Sol)
+	if err != nil {
+		return
+	}
+
+	// moving loads and surcharges; e.g. train loads and footings
+	for _, ml := range d.MovLoads {
+		ml.AddToRhs(d.Fb, t)
+	}
+	for _, sc := range d.Surcharges {
+		sc.AddToRhs(d.Fb, t)
+	}
 
 	// join all fb
 	if d.Distr {
-		mpi.AllReduceSum(d.Fb, d.Wb) // this must be done here because there might be nodes sharing boundary conditions
+		all_reduce_sum(d.Sim.Solver.Determ, d.Fb, d.Wb) // this must be done here because there might be nodes sharing boundary conditions
 	}
 
 	// point natural boundary conditions; e.g. concentrated loads
@@ -176,67 +258,55 @@ func solve_linear_problem(t float64, d *Domain, dc *ele.DynCoefs, sum *Summary,
 
 	// essential boundary conditioins; e.g. constraints
 	d.EssenBcs.AddToRhs(d.Fb, d.Sol)
+	return
+}
 
-	// assemble and factorise Jacobian matrix just once
-	if first {
-
-		// assemble element matrices
-		d.Kb.Start()
-		for _, e := range d.Elems {
-			err = e.AddToKb(d.Kb, d.Sol, true)
-			if err != nil {
-				return
-			}
-		}
-
-		// join A and tr(A) matrices into Kb
-		if d.Proc == 0 {
-			d.Kb.PutMatAndMatT(&d.EssenBcs.A)
-		}
-
-		// write smat matrix
-		if d.Sim.Data.WriteSmat {
-			la.WriteSmat("/tmp/gofem_Kb", d.Kb.ToMatrix(nil).ToDense(), 1e-14)
-			chk.Panic("file </tmp/gofem_Kb.smat> written. simulation stopped")
-		}
+// assemble_and_fact_linear_kb assembles and factorises the Jacobian matrix (Kb)
+func assemble_and_fact_linear_kb(d *Domain) (err error) {
 
-		// initialise linear solver (just once)
-		if d.InitLSol {
-			err = d.LinSol.InitR(d.Kb, d.Sim.LinSol.Symmetric, d.Sim.LinSol.Verbose, d.Sim.LinSol.Timing)
-			if err != nil {
-				err = chk.Err("cannot initialise linear solver:\n%v", err)
-				return
-			}
-			d.InitLSol = false
+	// assemble element matrices
+	d.Kb.Start()
+	for _, e := range d.Elems {
+		if d.Batches.Has(e) {
+			continue
 		}
-
-		// perform factorisation (always if not CteTg)
-		err = d.LinSol.Fact()
+		err = e.AddToKb(d.Kb, d.Sol, true)
 		if err != nil {
-			err = chk.Err("factorisation failed:\n%v", err)
 			return
 		}
 	}
-
-	// solve for wb
-	err = d.LinSol.SolveR(d.Wb, d.Fb, false)
+	err = d.Batches.AddToKb(d.Kb, d.Sol, true)
 	if err != nil {
-		err = chk.Err("solve failed:%v\n", err)
 		return
 	}
 
-	// update primary variables (y)
-	for i := 0; i < d.Ny; i++ {
-		d.Sol.Y[i] += d.Wb[i]  // y += δy
-		d.Sol.ΔY[i] += d.Wb[i] // ΔY += δy
+	// essential bcs / constraints: join A and tr(A) matrices into Kb, penalty or elimination terms
+	if d.Proc == 0 {
+		d.EssenBcs.AddToKb(d.Kb, d.Nyb)
 	}
 
-	// update Lagrange multipliers (λ)
-	for i := 0; i < d.Nlam; i++ {
-		d.Sol.L[i] += d.Wb[d.Ny+i] // λ += δλ
+	// write smat matrix
+	if d.Sim.Data.WriteSmat {
+		la.WriteSmat("/tmp/gofem_Kb", d.Kb.ToMatrix(nil).ToDense(), 1e-14)
+		chk.Panic("file </tmp/gofem_Kb.smat> written. simulation stopped")
 	}
 
-	// update secondary variables
-	err = d.UpdateElems()
-	return
+	// initialise linear solver (just once)
+	if d.InitLSol {
+		err = d.LinSol.InitR(d.Kb, d.Sim.LinSol.Symmetric, d.Sim.LinSol.Verbose, d.Sim.LinSol.Timing)
+		if err != nil {
+			err = chk.Err("cannot initialise linear solver:\n%v", err)
+			return
+		}
+		d.InitLSol = false
+	}
+
+	// perform factorisation (always if not CteTg)
+	err = d.LinSol.Fact()
+	if err != nil {
+		return diagnose_kb(d, chk.Err("factorisation failed:\n%v", err))
+	}
+
+	// diagnostics of Kb
+	return diagnose_kb(d, nil)
 }
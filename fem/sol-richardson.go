@@ -91,6 +91,7 @@ func (o *RichardsonExtrap) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose boo
 	t := o.doms[0].Sol.T
 	tout := t + dtoFunc.F(t, nil)
 	steady := o.doms[0].Sim.Data.Steady
+	prog := new_progress(t, tf)
 
 	// first output
 	if o.sum != nil {
@@ -200,12 +201,16 @@ func (o *RichardsonExtrap) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose boo
 			d.Sol.T = t
 
 			// output
-			if verbose {
-				if !dat.ShowR {
-					io.PfWhite("%30.15f\r", t)
+			if verbose && !dat.ShowR {
+				prog.print(t, d.Nit)
+			}
+			for _, d := range o.doms {
+				if d.Menv != nil {
+					d.Menv.Step(d)
 				}
 			}
-			if t >= tout || o.laststep {
+			stop := steady_reached(o.doms, o.Δt, o.sum, verbose)
+			if t >= tout || o.laststep || stop {
 				if o.sum != nil {
 					err = o.sum.SaveDomains(t, o.doms, false)
 					if err != nil {
@@ -215,6 +220,11 @@ func (o *RichardsonExtrap) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose boo
 				tout += dtoFunc.F(t, nil)
 			}
 
+			// interruption (signal or wall-clock budget): save state and stop
+			if interrupted(o.doms) {
+				return save_interrupted(o.doms, o.sum, t)
+			}
+
 			// reached final time
 			if o.laststep {
 				if verbose {
@@ -223,6 +233,11 @@ func (o *RichardsonExtrap) Run(tf float64, dtFunc, dtoFunc fun.Func, verbose boo
 				return
 			}
 
+			// reached steady state
+			if stop {
+				return
+			}
+
 			// predictive controller of Gustafsson
 			if !dat.REnogus {
 				if o.naccept > 1 {
@@ -0,0 +1,439 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// Splitting implements the staggered (sequential) solution of coupled problems (e.g. u-p, u-T or
+// u-d of phase-field fracture) with the fixed-stress split. Each time step is solved by an outer
+// loop with two stages:
+//   flow:      the displacements are frozen and the diffusive fields (e.g. pl, temp) are solved; the
+//              fixed-stress term β・sl/K_dr・(pl - pl_k) is added to the liquid mass balance
+//   mechanics: the diffusive fields are frozen and the equilibrium equations are solved
+//  The outer loop stops when the RMS norm of the change of Y during one outer iteration is smaller
+//  than Tol.
+//  Subcycling: with Nsub > 1, one field is solved with Nsub substeps within each step; the values
+//  and rates of the other field are interpolated linearly in time between the beginning of the step
+//  and its latest iterate. Each outer iteration restarts from the beginning of the step: the other
+//  field is solved first with the full step and then the subcycled field is solved with substeps
+//  Note: (1) the coupling blocks (e.g. Kup and Kpu) are replaced by zeros; thus, the equations of
+//            the frozen field have zero residuals and zero increments
+//        (2) constraints (Lagrange multipliers) involving displacements are solved in the mechanics
+//            stage; constraints on the diffusive fields only are solved in the flow stage
+//        (3) only elements implementing ele.WithSplit (e.g. solid-liquid) are supported
+//        (4) with subcycling, the state at the beginning of the step is stored in the auxiliary
+//            copies of internal variables; the same ones used by divergence control
+//        (5) with phase-field fracture (solid-phasefield), the damage is solved in the flow stage
+//            with frozen displacements and there is no fixed-stress term; i.e. the outer loop
+//            alternates the damage and displacement solutions until convergence
+type Splitting struct {
+	Beta   float64 // fixed-stress coefficient β. 1 => L = sl/K_dr
+	Tol    float64 // tolerance for the convergence of the outer loop
+	NmaxIt int     // max number of outer iterations
+	Nsub   int     // number of substeps of the subcycled field. ≤ 1 => no subcycling
+	Mech   bool    // the mechanics (instead of the diffusive fields) is subcycled
+	Nouter int     // number of outer iterations of the last step
+	Ntotal int     // total number of outer iterations
+	elems  []ele.WithSplit
+	flow   []bool    // [nyb] flow equations (including Lagrange multipliers of flow constraints)
+	tnum   []int     // [ny] t-derivative type of equations: 1 or 2
+	stage  int       // current stage; see ele.SplitFlow and ele.SplitMech
+	ybkp   []float64 // [ny] Y at the beginning of the outer iteration
+	dy     []float64 // [ny] change of Y during the outer iteration
+
+	// subcycling
+	frozen         int       // stage whose frozen field is being interpolated; SplitNone => none
+	y0, v0, a0, l0 []float64 // Y, dYdt, d²Ydt² and λ at the beginning of the step
+	y1, v1, a1, l1 []float64 // Y, dYdt, d²Ydt² and λ of the latest iterate
+}
+
+// split_flowkeys holds the keys of the equations of the diffusive fields
+var split_flowkeys = map[string]bool{"pl": true, "fl": true, "temp": true, "pf": true}
+
+// NewSplitting allocates a new Splitting structure for the current stage of domain
+//  sub -- subcycling data; may be nil
+func NewSplitting(d *Domain, sub *inp.SubcycleData) (o *Splitting, err error) {
+
+	// check
+	dat := d.Sim.Solver
+	if dat.Type != "imp" {
+		return nil, chk.Err("staggered solution requires the implicit solver (\"imp\"). %q is invalid", dat.Type)
+	}
+	if d.Eros != nil || d.Relax != nil {
+		return nil, chk.Err("staggered solution cannot be combined with erosion or relaxation")
+	}
+
+	// elements
+	o = &Splitting{Beta: dat.SplitBeta, Tol: dat.SplitTol, NmaxIt: dat.SplitNmax}
+	for _, e := range d.Elems {
+		if es, ok := e.(ele.WithSplit); ok {
+			o.elems = append(o.elems, es)
+		}
+	}
+	if len(o.elems) == 0 {
+		return nil, chk.Err("staggered solution requires coupled elements implementing the fixed-stress split; e.g. solid-liquid or solid-phasefield")
+	}
+
+	// flow equations
+	o.flow = make([]bool, d.Nyb)
+	o.tnum = make([]int, d.Ny)
+	for _, nod := range d.Nodes {
+		for _, dof := range nod.Dofs {
+			if dof.Key == "pg" {
+				return nil, chk.Err("staggered solution is not available for problems with gas pressures")
+			}
+			o.flow[dof.Eq] = split_flowkeys[dof.Key]
+			o.tnum[dof.Eq] = d.Dof2Tnum[dof.Key]
+		}
+	}
+	for i, bc := range d.EssenBcs.Mbcs {
+		allflow := true
+		for _, eq := range bc.Eqs {
+			allflow = allflow && o.flow[eq]
+		}
+		o.flow[d.Ny+i] = allflow
+	}
+
+	// workspace
+	o.ybkp = make([]float64, d.Ny)
+	o.dy = make([]float64, d.Ny)
+
+	// subcycling
+	if sub == nil || sub.Nsub < 2 {
+		return
+	}
+	if d.Sim.Data.Steady {
+		return nil, chk.Err("subcycling requires a transient simulation")
+	}
+	switch sub.Field {
+	case "", "flow":
+	case "mech":
+		o.Mech = true
+	default:
+		return nil, chk.Err("subcycled field %q is invalid; options are \"flow\" and \"mech\"", sub.Field)
+	}
+	o.Nsub = sub.Nsub
+	o.y0, o.v0, o.a0, o.l0 = make([]float64, d.Ny), make([]float64, d.Ny), make([]float64, d.Ny), make([]float64, d.Nlam)
+	o.y1, o.v1, o.a1, o.l1 = make([]float64, d.Ny), make([]float64, d.Ny), make([]float64, d.Ny), make([]float64, d.Nlam)
+	return
+}
+
+// Freeze zeroes the residuals of the frozen equations of the current stage
+func (o *Splitting) Freeze(fb []float64) {
+	if o == nil || o.stage == ele.SplitNone {
+		return
+	}
+	for i, flow := range o.flow {
+		if flow != (o.stage == ele.SplitFlow) {
+			fb[i] = 0
+		}
+	}
+}
+
+// set sets the stage of all coupled elements
+func (o *Splitting) set(stage, slot int, sol *ele.Solution) (err error) {
+	o.stage = stage
+	for _, e := range o.elems {
+		err = e.SetSplit(stage, slot, o.Beta, sol)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// record records the pressures at the end of the flow stage (or substep) in slot
+func (o *Splitting) record(slot int, sol *ele.Solution) (err error) {
+	for _, e := range o.elems {
+		err = e.RecordSplit(slot, sol)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// run_staggered solves a time step with the fixed-stress split; see Splitting
+func run_staggered(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, dbgKb DebugKb_t) (diverging bool, err error) {
+
+	// return elements to the monolithic state
+	o := d.Split
+	defer func() {
+		if e := o.set(ele.SplitNone, 0, d.Sol); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	// subcycling
+	if o.Nsub > 1 {
+		return o.run_subcycles(t, Δt, d, dc, sum, dbgKb)
+	}
+
+	// zero accumulated increments and compute starred variables
+	la.VecFill(d.Sol.ΔY, 0)
+	err = set_star_vars(d, dc)
+	if err != nil {
+		return
+	}
+
+	// outer loop
+	nit := 0
+	converged := false
+	defer func() { d.Nit = nit }()
+	for o.Nouter = 0; o.Nouter < o.NmaxIt && !converged; o.Nouter++ {
+
+		// backup Y
+		copy(o.ybkp, d.Sol.Y)
+
+		// flow stage
+		err = o.set(ele.SplitFlow, 0, d.Sol)
+		if err != nil {
+			return
+		}
+		diverging, err = newton_iterations(t, d, dc, sum, dbgKb, o.Nouter == 0)
+		nit += d.Nit
+		if err != nil {
+			err = chk.Err("flow stage failed (outer iteration %d):\n%v", o.Nouter, err)
+			return
+		}
+		if diverging {
+			return
+		}
+		err = o.record(0, d.Sol)
+		if err != nil {
+			return
+		}
+
+		// mechanics stage
+		err = o.set(ele.SplitMech, 0, d.Sol)
+		if err != nil {
+			return
+		}
+		diverging, err = newton_iterations(t, d, dc, sum, dbgKb, false)
+		nit += d.Nit
+		if err != nil {
+			err = chk.Err("mechanics stage failed (outer iteration %d):\n%v", o.Nouter, err)
+			return
+		}
+		if diverging {
+			return
+		}
+
+		// check convergence of outer loop
+		converged = o.check(t, d, o.ybkp)
+	}
+	o.Ntotal += o.Nouter
+
+	// check if outer loop diverged
+	if !converged {
+		err = chk.Err("staggered solution: max number of outer iterations reached: it = %d\n", o.Nouter)
+	}
+	return
+}
+
+// run_subcycles solves a time step with the fixed-stress split and subcycling; see Splitting
+func (o *Splitting) run_subcycles(t, Δt float64, d *Domain, dc *ele.DynCoefs, sum *Summary, dbgKb DebugKb_t) (diverging bool, err error) {
+
+	// stages
+	full, subc := ele.SplitMech, ele.SplitFlow
+	if o.Mech {
+		full, subc = ele.SplitFlow, ele.SplitMech
+	}
+
+	// state at the beginning of the step
+	copy(o.y0, d.Sol.Y)
+	copy(o.v0, d.Sol.Dydt)
+	copy(o.a0, d.Sol.D2ydt2)
+	copy(o.l0, d.Sol.L)
+	copy(o.y1, d.Sol.Y)
+	copy(o.v1, d.Sol.Dydt)
+	copy(o.a1, d.Sol.D2ydt2)
+	copy(o.l1, d.Sol.L)
+	for _, e := range d.ElemIntvars {
+		e.BackupIvs(true)
+	}
+
+	// restore time, coefficients and increments at the end of the step
+	nit := 0
+	h := Δt / float64(o.Nsub)
+	defer func() {
+		o.frozen = ele.SplitNone
+		d.Sol.T, d.Sol.Dt = t, Δt
+		d.Nit = nit
+		if e := dc.CalcBoth(Δt); e != nil && err == nil {
+			err = e
+		}
+		for i := 0; i < d.Ny; i++ {
+			d.Sol.ΔY[i] = d.Sol.Y[i] - o.y0[i]
+		}
+	}()
+
+	// outer loop
+	converged := false
+	for o.Nouter = 0; o.Nouter < o.NmaxIt && !converged; o.Nouter++ {
+
+		// full step: the subcycled field is frozen at its latest iterate
+		o.restart(d)
+		err = dc.CalcBoth(Δt)
+		if err != nil {
+			return
+		}
+		d.Sol.T, d.Sol.Dt = t, Δt
+		diverging, err = o.solve(full, 0, 1, t, d, dc, sum, dbgKb)
+		nit += d.Nit
+		if err != nil || diverging {
+			return
+		}
+
+		// latest iterate of the field solved with the full step
+		for i := 0; i < d.Ny; i++ {
+			if o.flow[i] == (full == ele.SplitFlow) {
+				o.y1[i], o.v1[i], o.a1[i] = d.Sol.Y[i], d.Sol.Dydt[i], d.Sol.D2ydt2[i]
+			}
+		}
+		for i := 0; i < d.Nlam; i++ {
+			if o.flow[d.Ny+i] == (full == ele.SplitFlow) {
+				o.l1[i] = d.Sol.L[i]
+			}
+		}
+
+		// substeps: the other field is interpolated
+		o.restart(d)
+		err = dc.CalcBoth(h)
+		if err != nil {
+			return
+		}
+		d.Sol.Dt = h
+		for j := 1; j <= o.Nsub; j++ {
+			τ := float64(j) / float64(o.Nsub)
+			d.Sol.T = t - Δt + τ*Δt
+			diverging, err = o.solve(subc, j-1, τ, d.Sol.T, d, dc, sum, dbgKb)
+			nit += d.Nit
+			if err != nil || diverging {
+				return
+			}
+		}
+
+		// check convergence of outer loop and save latest iterate
+		converged = o.check(t, d, o.y1) && o.Nouter > 0
+		copy(o.y1, d.Sol.Y)
+		copy(o.v1, d.Sol.Dydt)
+		copy(o.a1, d.Sol.D2ydt2)
+		copy(o.l1, d.Sol.L)
+	}
+	o.Ntotal += o.Nouter
+
+	// check if outer loop diverged
+	if !converged {
+		err = chk.Err("staggered solution with subcycling: max number of outer iterations reached: it = %d\n", o.Nouter)
+	}
+	return
+}
+
+// restart sets the solution and internal variables back to the beginning of the step
+func (o *Splitting) restart(d *Domain) {
+	copy(d.Sol.Y, o.y0)
+	copy(d.Sol.Dydt, o.v0)
+	copy(d.Sol.D2ydt2, o.a0)
+	copy(d.Sol.L, o.l0)
+	for _, e := range d.ElemIntvars {
+		e.RestoreIvs(true)
+	}
+}
+
+// solve solves one stage (or substep) of the subcycled solution. The frozen field is interpolated at
+// τ = (t - t0) / Δt; the starred variables of the frozen field are computed such that the rates
+// given by the time integrators are the interpolated ones
+func (o *Splitting) solve(stage, slot int, τ, t float64, d *Domain, dc *ele.DynCoefs, sum *Summary, dbgKb DebugKb_t) (diverging bool, err error) {
+
+	// interpolate frozen field
+	la.VecFill(d.Sol.ΔY, 0)
+	frozenflow := stage == ele.SplitMech
+	for i := 0; i < d.Ny; i++ {
+		if o.flow[i] != frozenflow {
+			continue
+		}
+		y := o.y0[i] + τ*(o.y1[i]-o.y0[i])
+		d.Sol.ΔY[i] = y - d.Sol.Y[i]
+		d.Sol.Y[i] = y
+		d.Sol.Dydt[i] = o.v0[i] + τ*(o.v1[i]-o.v0[i])
+		d.Sol.D2ydt2[i] = o.a0[i] + τ*(o.a1[i]-o.a0[i])
+	}
+	for i := 0; i < d.Nlam; i++ {
+		if o.flow[d.Ny+i] == frozenflow {
+			d.Sol.L[i] = o.l0[i] + τ*(o.l1[i]-o.l0[i])
+		}
+	}
+
+	// starred variables
+	o.frozen = stage
+	err = set_star_vars(d, dc)
+	if err != nil {
+		return
+	}
+
+	// solve
+	err = o.set(stage, slot, d.Sol)
+	if err != nil {
+		return
+	}
+	diverging, err = newton_iterations(t, d, dc, sum, dbgKb, true)
+	if err != nil {
+		name := "flow"
+		if stage == ele.SplitMech {
+			name = "mechanics"
+		}
+		err = chk.Err("%s stage failed (outer iteration %d, slot %d):\n%v", name, o.Nouter, slot, err)
+		return
+	}
+	if diverging || stage != ele.SplitFlow {
+		return
+	}
+	err = o.record(slot, d.Sol)
+	return
+}
+
+// fix_star_vecs sets the starred vectors of the frozen (interpolated) field such that the time
+// integrators return the interpolated rates; e.g. dydt = β1・y - ψ
+func (o *Splitting) fix_star_vecs(d *Domain, dc *ele.DynCoefs) {
+	if o == nil || o.frozen == ele.SplitNone {
+		return
+	}
+	β1 := dc.GetBet1()
+	α1 := dc.GetAlp1()
+	α4 := dc.GetAlp4()
+	frozenflow := o.frozen == ele.SplitMech
+	for i := 0; i < d.Ny; i++ {
+		if o.flow[i] != frozenflow {
+			continue
+		}
+		switch o.tnum[i] {
+		case 1:
+			d.Sol.Psi[i] = β1*d.Sol.Y[i] - d.Sol.Dydt[i]
+		case 2:
+			d.Sol.Zet[i] = α1*d.Sol.Y[i] - d.Sol.D2ydt2[i]
+			d.Sol.Chi[i] = α4*d.Sol.Y[i] - d.Sol.Dydt[i]
+		}
+	}
+}
+
+// check checks the convergence of the outer loop by comparing Y with its previous iterate (yprev)
+func (o *Splitting) check(t float64, d *Domain, yprev []float64) (converged bool) {
+	dat := d.Sim.Solver
+	for i := 0; i < d.Ny; i++ {
+		o.dy[i] = d.Sol.Y[i] - yprev[i]
+	}
+	Lδy := la.VecRmsErr(o.dy, dat.Atol, dat.Rtol, d.Sol.Y)
+	if dat.ShowR {
+		io.Pf("%13.6e  outer it = %d  Lδy = %23.15e\n", t, o.Nouter, Lδy)
+	}
+	return Lδy < o.Tol
+}
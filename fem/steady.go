@@ -0,0 +1,137 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// SteadyState implements the detection of steady states during transient stages; e.g. the end of
+// consolidation. The stage is terminated when the criterion is satisfied by Nst consecutive steps
+//  Criteria:
+//   "rate"   -- max |ΔY/Δt| over the selected dofs, with ΔY being the increment during the step
+//   "excess" -- max |pl - pl_hydrostatic| over all liquid pressure dofs; i.e. excess pore pressure
+//  Note: only serial runs are supported
+type SteadyState struct {
+	Stage int              // index of stage
+	Ctrl  *inp.TimeControl // time control data with criterion and tolerance
+	Eqs   []int            // equations checked
+	Plh   []float64        // [len(Eqs)] hydrostatic pressures ("excess" criterion)
+	Value float64          // value of criterion after last step
+	Time  float64          // time when steady state was detected; -1 => not detected
+	count int              // number of consecutive steps satisfying criterion
+	yold  []float64        // [len(Eqs)] values of Y at the beginning of step
+}
+
+// NewSteadyState allocates a new SteadyState structure for the stage (stgidx) of domain
+func NewSteadyState(d *Domain, stgidx int, ctrl *inp.TimeControl) (o *SteadyState, err error) {
+
+	// check
+	if d.Distr {
+		return nil, chk.Err("steady-state detection is not available in parallel runs")
+	}
+	if d.Sim.Data.Steady {
+		return nil, chk.Err("steady-state detection requires a transient simulation")
+	}
+
+	// equations
+	o = &SteadyState{Stage: stgidx, Ctrl: ctrl, Time: -1}
+	ndim := d.Msh.Ndim
+	for _, nod := range d.Nodes {
+		for _, dof := range nod.Dofs {
+			switch ctrl.SteadyCrit {
+			case "rate":
+				if len(ctrl.SteadyKeys) > 0 && utl.StrIndexSmall(ctrl.SteadyKeys, dof.Key) < 0 {
+					continue
+				}
+				o.Eqs = append(o.Eqs, dof.Eq)
+			case "excess":
+				if dof.Key != "pl" {
+					continue
+				}
+				if d.Sim.LiqMdl == nil {
+					return nil, chk.Err("liquid model is required by \"excess\" steady-state criterion")
+				}
+				plh, _ := d.Sim.LiqMdl.Calc(nod.Vert.C[ndim-1])
+				o.Eqs = append(o.Eqs, dof.Eq)
+				o.Plh = append(o.Plh, plh)
+			}
+		}
+	}
+	if len(o.Eqs) == 0 {
+		return nil, chk.Err("cannot find dofs to check with steady-state criterion %q", ctrl.SteadyCrit)
+	}
+	o.yold = make([]float64, len(o.Eqs))
+	return
+}
+
+// Start records the initial values of Y and resets the detection
+func (o *SteadyState) Start(sol *ele.Solution) {
+	for i, eq := range o.Eqs {
+		o.yold[i] = sol.Y[eq]
+	}
+	o.Value = 0
+	o.Time = -1
+	o.count = 0
+}
+
+// Check computes the criterion after a time step (Δt) has converged and returns whether the
+// steady state has been reached
+func (o *SteadyState) Check(sol *ele.Solution, Δt float64) (reached bool) {
+	o.Value = 0
+	for i, eq := range o.Eqs {
+		switch o.Ctrl.SteadyCrit {
+		case "rate":
+			o.Value = math.Max(o.Value, math.Abs(sol.Y[eq]-o.yold[i])/Δt)
+		case "excess":
+			o.Value = math.Max(o.Value, math.Abs(sol.Y[eq]-o.Plh[i]))
+		}
+		o.yold[i] = sol.Y[eq]
+	}
+	if o.Value > o.Ctrl.SteadyTol {
+		o.count = 0
+		return false
+	}
+	o.count++
+	if o.count < o.Ctrl.SteadyNst {
+		return false
+	}
+	o.Time = sol.T
+	return true
+}
+
+// steady_reached checks the steady-state criterion of all domains after a converged time step (Δt).
+// The detection time is recorded in the summary
+func steady_reached(doms []*Domain, Δt float64, sum *Summary, verbose bool) (reached bool) {
+	reached = true
+	for _, d := range doms {
+		if d.Steady == nil {
+			return false
+		}
+		if !d.Steady.Check(d.Sol, Δt) { // all domains must be checked to update yold
+			reached = false
+		}
+	}
+	if !reached {
+		return
+	}
+	s := doms[0].Steady
+	if sum != nil {
+		if sum.Steady == nil {
+			sum.Steady = make(map[int]float64)
+		}
+		sum.Steady[s.Stage] = s.Time
+	}
+	if verbose {
+		io.Pfgreen("\n> Steady state (%s) detected at t = %g: %g ≤ %g\n", s.Ctrl.SteadyCrit, s.Time, s.Value, s.Ctrl.SteadyTol)
+	}
+	return
+}
@@ -6,7 +6,6 @@ package fem
 
 import (
 	"bytes"
-	"os"
 	"path"
 
 	"github.com/cpmech/gosl/chk"
@@ -25,16 +24,45 @@ type Summary struct {
 	OutTimes []float64    // [nOutTimes] output times
 	Resids   utl.DblSlist // residuals (if Stat is on; includes all stages)
 
+	// steady-state detection
+	Steady map[int]float64 // stage index => time when steady state was detected (stage stopped before tf)
+
+	// result files
+	Checksums map[string]string // checksums (SHA-256) of result files of root processor; filename => checksum
+
+	// load cases and combinations (linear analyses)
+	LcNames   []string    // [ncases+ncombs] names of load cases and combinations; one per output time
+	Envelopes []*Envelope // envelopes of results among load combinations
+
 	// auxiliary
 	tidx int // time output index
 }
 
 // SaveDomains save the results from all domains (nodes and elements)
+//  Note: the output is skipped if the output filter of the first domain (see OutFilter) says so;
+//        i.e. decimation of output times
 func (o *Summary) SaveDomains(time float64, doms []*Domain, verbose bool) (err error) {
+	if doms[0].OutFlt != nil && doms[0].OutFlt.Skip(time) {
+		return
+	}
+	return o.save_domains(time, doms, false, verbose)
+}
+
+// save_domains saves the results from all domains; with or without output filters (full)
+func (o *Summary) save_domains(time float64, doms []*Domain, full, verbose bool) (err error) {
 
 	// output results from all domains
 	for _, d := range doms {
+		d.outfull = full
 		err = d.Save(o.tidx, verbose)
+		d.outfull = false
+		for fn, sum := range d.Cksums {
+			if o.Checksums == nil {
+				o.Checksums = make(map[string]string)
+			}
+			o.Checksums[fn] = sum
+		}
+		d.Cksums = nil
 		if d.Distr {
 			mpi.Barrier()
 		}
@@ -82,14 +110,13 @@ func (o *Summary) Read(dir, fnkey, enctype string) (err error) {
 
 	// open file
 	fn := out_sum_path(dir, fnkey, enctype, 0) // reading always from proc # 0
-	fil, err := os.Open(fn)
+	b, err := read_file(fn)
 	if err != nil {
 		return
 	}
-	defer func() { err = fil.Close() }()
 
 	// decode summary
-	dec := utl.GetDecoder(fil, enctype)
+	dec := utl.GetDecoder(bytes.NewReader(b), enctype)
 	err = dec.Decode(o)
 	if err != nil {
 		return chk.Err("cannot decode summary:\n%v", err)
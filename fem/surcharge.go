@@ -0,0 +1,205 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Surcharge implements a parametric surface load placed by coordinates (strip, circle or
+// embankment). The consistent nodal forces are computed once (for a unit multiplier) by
+// integrating over the tagged faces intersected by the loaded area
+//  Note: only the faces of cells belonging to this processor are considered; thus, in parallel
+//        runs, the forces are added before joining fb
+type Surcharge struct {
+	Dat    *inp.SurchargeData // input data
+	Forces map[int]float64    // equation => nodal force (unit multiplier)
+}
+
+// NewSurcharge allocates a new Surcharge structure acting on the cells of domain
+func NewSurcharge(d *Domain, dat *inp.SurchargeData) (o *Surcharge, err error) {
+
+	// direction
+	ndim := d.Msh.Ndim
+	if utl.StrIndexSmall([]string{"fx", "fy", "fz"}[:ndim], dat.Key) < 0 {
+		return nil, chk.Err("key %q of surcharge is invalid; options are \"fx\", \"fy\" and \"fz\" (3D)", dat.Key)
+	}
+	ukey := d.F2Y[dat.Key]
+	if ukey == "" {
+		return nil, chk.Err("cannot find dof corresponding to key %q of surcharge", dat.Key)
+	}
+
+	// faces
+	pairs, ok := d.Msh.FaceTag2cells[dat.Tag]
+	if !ok {
+		return nil, chk.Err("cannot find faces with tag = %d to apply surcharge", dat.Tag)
+	}
+
+	// integrate over faces
+	o = &Surcharge{Dat: dat, Forces: make(map[int]float64)}
+	x := make([]float64, ndim)
+	for _, pair := range pairs {
+		c, fid := pair.C, pair.Fid
+		sh := c.Shp
+		if d.Cid2elem[c.Id] == nil || sh == nil || sh.Nurbs != nil {
+			continue
+		}
+		X := d.cell_coords(c, sh.Nverts)
+		ips, err := o.face_ips(sh, X, fid)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			err = sh.CalcAtFaceIp(X, ip, fid)
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < ndim; i++ {
+				x[i] = 0
+				for k, m := range sh.FaceLocalVerts[fid] {
+					x[i] += sh.Sf[k] * X[i][m]
+				}
+			}
+			q := o.Intensity(x)
+			if q == 0 {
+				continue
+			}
+			coef := ip[3] * la.VecNorm(sh.Fnvec) * q
+			if d.Sim.Data.Axisym {
+				coef *= x[0]
+			}
+			for k, m := range sh.FaceLocalVerts[fid] {
+				if nod := d.Vid2node[c.Verts[m]]; nod != nil {
+					if eq := nod.GetEq(ukey); eq >= 0 {
+						o.Forces[eq] += coef * sh.Sf[k]
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// AddToRhs adds the nodal forces of the surcharge at time t to fb
+func (o *Surcharge) AddToRhs(fb []float64, t float64) {
+	mult := 1.0
+	if o.Dat.Fcn != nil {
+		mult = o.Dat.Fcn.F(t, nil)
+	}
+	for eq, f := range o.Forces {
+		fb[eq] += mult * f
+	}
+}
+
+// Intensity returns the pressure at point x (without multiplier)
+func (o *Surcharge) Intensity(x []float64) float64 {
+	dat := o.Dat
+	switch dat.Type {
+	case "strip":
+		if x[0] < dat.Xmin || x[0] > dat.Xmax {
+			return 0
+		}
+		if len(x) == 3 {
+			if (dat.Ymin != nil && x[1] < *dat.Ymin) || (dat.Ymax != nil && x[1] > *dat.Ymax) {
+				return 0
+			}
+		}
+		return dat.Q
+	case "circle":
+		if utl.L2norm(x[:len(x)-1], dat.Centre[:len(x)-1]) > dat.Radius {
+			return 0
+		}
+		return dat.Q
+	case "embankment":
+		switch {
+		case x[0] < dat.Toes[0] || x[0] > dat.Toes[1]:
+			return 0
+		case x[0] < dat.Xmin:
+			return dat.Q * (x[0] - dat.Toes[0]) / (dat.Xmin - dat.Toes[0])
+		case x[0] > dat.Xmax:
+			return dat.Q * (dat.Toes[1] - x[0]) / (dat.Toes[1] - dat.Xmax)
+		}
+		return dat.Q
+	}
+	return 0
+}
+
+// face_ips returns the integration points over face fid of cell with shape sh and coordinates X
+//  Note: in 2D, the edge is split at the points where the load is discontinuous or has kinks and
+//        each part is integrated with 3 Gauss points; in 3D, the face is subdivided
+func (o *Surcharge) face_ips(sh *shp.Shape, X [][]float64, fid int) (ips []shp.Ipoint, err error) {
+
+	// 3D
+	if sh.Gndim == 3 {
+		_, ipf, err := sh.GetIps(0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return tie_subdivide(sh.FaceType, ipf, o.Dat.Nsub), nil
+	}
+
+	// breakpoints along x
+	dat := o.Dat
+	var xbs []float64
+	switch dat.Type {
+	case "strip":
+		xbs = []float64{dat.Xmin, dat.Xmax}
+	case "circle":
+		xbs = []float64{dat.Centre[0] - dat.Radius, dat.Centre[0] + dat.Radius}
+	case "embankment":
+		xbs = []float64{dat.Toes[0], dat.Xmin, dat.Xmax, dat.Toes[1]}
+	}
+
+	// x-coordinate along edge
+	xe := func(ξ float64) (res float64) {
+		sh.FaceFunc(sh.Sf, sh.DSfdRf, []float64{ξ, 0, 0}, false, fid)
+		for k, m := range sh.FaceLocalVerts[fid] {
+			res += sh.Sf[k] * X[0][m]
+		}
+		return
+	}
+
+	// natural coordinates of breakpoints (bisection)
+	ξs := []float64{-1, 1}
+	for _, xb := range xbs {
+		a, b := -1.0, 1.0
+		fa, fb := xe(a)-xb, xe(b)-xb
+		if fa*fb >= 0 {
+			continue
+		}
+		for it := 0; it < 60; it++ {
+			ξ := (a + b) / 2.0
+			f := xe(ξ) - xb
+			if fa*f <= 0 {
+				b = ξ
+			} else {
+				a, fa = ξ, f
+			}
+		}
+		ξs = append(ξs, (a+b)/2.0)
+	}
+	sort.Float64s(ξs)
+
+	// Gauss points on parts
+	gp := []float64{-math.Sqrt(3.0 / 5.0), 0, math.Sqrt(3.0 / 5.0)}
+	gw := []float64{5.0 / 9.0, 8.0 / 9.0, 5.0 / 9.0}
+	for i := 1; i < len(ξs); i++ {
+		a, b := ξs[i-1], ξs[i]
+		if b-a < 1e-14 {
+			continue
+		}
+		for j := 0; j < 3; j++ {
+			ips = append(ips, shp.Ipoint{(a+b)/2.0 + (b-a)/2.0*gp[j], 0, 0, gw[j] * (b - a) / 2.0})
+		}
+	}
+	return
+}
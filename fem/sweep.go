@@ -0,0 +1,112 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/mpi"
+)
+
+// Sweep runs a parameter sweep; i.e. runs a simulation for each sample of the adjustable parameters
+// generated by design of experiments methods (Latin hypercube or Sobol sequence) and collects the
+// response quantities. The results can be used to build response surfaces (surrogate models)
+//  Note: with MPI, the samples are distributed among processors
+type Sweep struct {
+	Simfile string         // simulation filename with full path
+	Dat     *inp.SweepData // parameter sweep data
+	Verbose bool           // show messages
+	Determ  bool           // deterministic reductions; see inp.SolverData.Determ
+
+	// samples and results
+	Prms fun.Prms    // [nprm] swept parameters
+	X    [][]float64 // [nsamp][nprm] values of parameters
+	Vals [][]float64 // [nsamp][nresp] responses
+	Ran  []bool      // [nsamp] sample was run successfully
+}
+
+// NewSweep returns a new parameter sweep structure and generates the samples
+func NewSweep(simfile string, verbose bool) (o *Sweep, err error) {
+	sim := inp.ReadSim(simfile, "", false, 0)
+	if sim == nil {
+		return nil, chk.Err("cannot read simulation file %q", simfile)
+	}
+	if sim.Sweep == nil {
+		return nil, chk.Err("simulation file %q does not have parameter sweep data (sweep)", simfile)
+	}
+	if len(sim.Sweep.Resps) == 0 {
+		return nil, chk.Err("at least one response quantity must be given in parameter sweep data")
+	}
+	o = new(Sweep)
+	o.Simfile = simfile
+	o.Dat = sim.Sweep
+	o.Verbose = verbose
+	o.Determ = sim.Solver.Determ
+	o.Prms, o.X, err = sim.SweepSamples()
+	if err != nil {
+		return
+	}
+
+	// all processors must use the samples of the root processor (e.g. random seeds)
+	if mpi.IsOn() && mpi.Size() > 1 {
+		nprm := len(o.Prms)
+		buf := make([]float64, len(o.X)*nprm)
+		if mpi.Rank() == 0 {
+			for i, x := range o.X {
+				copy(buf[i*nprm:], x)
+			}
+		}
+		wrk := make([]float64, len(buf))
+		all_reduce_sum(o.Determ, buf, wrk)
+		for i := range o.X {
+			copy(o.X[i], buf[i*nprm:(i+1)*nprm])
+		}
+	}
+	return
+}
+
+// Run runs all samples
+func (o *Sweep) Run() (err error) {
+	o.Vals, o.Ran = run_samples(o.Simfile, "doe", len(o.X), o.Dat.Resps, o.Verbose, o.Determ, func(i int, sim *inp.Simulation) error {
+		for j, prm := range o.Prms {
+			sim.PrmAdjust(prm.Adj, o.X[i][j])
+		}
+		return nil
+	})
+	for _, ran := range o.Ran {
+		if ran {
+			return
+		}
+	}
+	return chk.Err("all %d samples failed", len(o.X))
+}
+
+// Save saves the table with the values of parameters and responses of all samples (key_doe.res)
+func (o *Sweep) Save(dirout, fnkey string) {
+	var b bytes.Buffer
+	io.Ff(&b, "%8s%6s", "sample", "ran")
+	for _, prm := range o.Prms {
+		io.Ff(&b, "%23s", prm.N)
+	}
+	for _, resp := range o.Dat.Resps {
+		io.Ff(&b, "%23s", resp.Name)
+	}
+	io.Ff(&b, "\n")
+	for i, x := range o.X {
+		io.Ff(&b, "%8d%6v", i, o.Ran[i])
+		for _, v := range x {
+			io.Ff(&b, "%23.15e", v)
+		}
+		for _, v := range o.Vals[i] {
+			io.Ff(&b, "%23.15e", v)
+		}
+		io.Ff(&b, "\n")
+	}
+	io.WriteFileVD(dirout, fnkey+"_doe.res", &b)
+}
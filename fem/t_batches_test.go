@@ -0,0 +1,56 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_batches01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("batches01. solid elements evaluated by the reference (cpu) device")
+
+	// reference solution
+	main := NewMain("data/bh16.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	Yref := append([]float64{}, main.Domains[0].Sol.Y...)
+
+	// batches
+	main = NewMain("data/bh16.sim", "", true, false, false, false, chk.Verbose, 0)
+	main.Sim.Solver.Device = "cpu"
+	err = main.Run()
+	if err != nil {
+		tst.Errorf("Run with batches failed:\n%v", err)
+		return
+	}
+	dom := main.Domains[0]
+	if dom.Batches == nil {
+		tst.Errorf("batches should have been created\n")
+		return
+	}
+	nelems, nrhs := 0, 0
+	for _, b := range dom.Batches.List {
+		nelems += len(b.Elems)
+		for _, e := range b.Elems {
+			if dom.Batches.HasRhs(e) {
+				nrhs++
+			}
+		}
+	}
+	io.Pforan("nbatches = %d  nelems = %d  nrhs = %d\n", len(dom.Batches.List), nelems, nrhs)
+	chk.IntAssert(nelems, len(dom.Elems))
+	if nrhs == 0 || nrhs == nelems {
+		tst.Errorf("elements with surface loads must not have their right-hand side computed by batches. nrhs = %d\n", nrhs)
+	}
+	chk.Vector(tst, "Y", 1e-12, dom.Sol.Y, Yref)
+}
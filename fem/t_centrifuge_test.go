@@ -0,0 +1,39 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+func Test_centrifuge01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("centrifuge01. centrifugal acceleration")
+
+	// 2D: axis @ (0, 10) => acceleration points downwards below the axis
+	mult := &fun.Cte{C: 2}
+	ay := &CentrifugeAcc{I: 1, Ω2: 3, Xa: []float64{0, 10}, Mult: mult}
+	chk.Scalar(tst, "ay", 1e-15, ay.F(0, []float64{1, 4}), 2*3*(4-10))
+	v := make([]float64, 2)
+	ay.Grad(v, 0, []float64{1, 4})
+	chk.Vector(tst, "∇ay", 1e-15, v, []float64{0, 6})
+
+	// 3D: axis along z => no axial component
+	dir := []float64{0, 0, 1}
+	ax := &CentrifugeAcc{I: 0, Ω2: 3, Xa: []float64{1, 0, 0}, Dir: dir, Mult: mult}
+	az := &CentrifugeAcc{I: 2, Ω2: 3, Xa: []float64{1, 0, 0}, Dir: dir, Mult: mult}
+	x := []float64{3, 1, 5}
+	chk.Scalar(tst, "ax", 1e-15, ax.F(0, x), 2*3*2)
+	chk.Scalar(tst, "az", 1e-15, az.F(0, x), 0)
+	w := make([]float64, 3)
+	az.Grad(w, 0, x)
+	chk.Vector(tst, "∇az", 1e-15, w, []float64{0, 0, 0})
+	ax.Grad(w, 0, x)
+	chk.Vector(tst, "∇ax", 1e-15, w, []float64{6, 0, 0})
+}
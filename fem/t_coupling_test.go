@@ -0,0 +1,45 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_coupling01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("coupling01. rigid body motion of structural nodes")
+
+	// 2D: u = u_b + θz × r
+	xb := []float64{1, 2, 3}
+	x := []float64{3, 5, 7}
+	P := cpl_pmat(x, xb, 2, 3)
+	u := make([]float64, 2)
+	la.MatVecMul(u, 1, P, []float64{0.1, 0.2, 0.5})
+	chk.Vector(tst, "u(2D)", 1e-15, u, []float64{0.1 - 0.5*3, 0.2 + 0.5*2})
+
+	// 2D: displacements only
+	P = cpl_pmat(x, xb, 2, 2)
+	chk.Matrix(tst, "P(2D,nb=2)", 1e-15, P, [][]float64{{1, 0}, {0, 1}})
+
+	// 3D: u = u_b + θ × r with r = (2, 3, 4)
+	P = cpl_pmat(x, xb, 3, 6)
+	u = make([]float64, 3)
+	θ := []float64{0.3, -0.2, 0.5}
+	la.MatVecMul(u, 1, P, []float64{0, 0, 0, θ[0], θ[1], θ[2]})
+	r := []float64{2, 3, 4}
+	chk.Vector(tst, "u(3D)", 1e-15, u, []float64{
+		θ[1]*r[2] - θ[2]*r[1],
+		θ[2]*r[0] - θ[0]*r[2],
+		θ[0]*r[1] - θ[1]*r[0],
+	})
+
+	// distance
+	chk.Scalar(tst, "dist", 1e-15, cpl_dist(x, xb, 2), 3.605551275463989)
+}
@@ -0,0 +1,115 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_cplxsys01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("cplxsys01. complex systems: harmonic response of SDOF oscillators")
+
+	// m u'' + c u' + k u = F exp(i ω t) => u = F / (k - m ω² + i ω c)
+	m, c, k := 2.0, 4.0, 800.0
+	F := complex(10, -3)
+	ωs := []float64{0, 5, 10, 19, 20, 21, 30, 100} // resonance at ω = 20
+	n := len(ωs)
+
+	for _, block := range []bool{false, true} {
+		io.Pforan("block = %v\n", block)
+		var dat inp.LinSolData
+		dat.SetDefault()
+		dat.CplxBlock = block
+		sys, err := NewCplxSystem(n, n, &dat)
+		if err != nil {
+			tst.Errorf("NewCplxSystem failed:\n%v", err)
+			return
+		}
+
+		// each equation is an oscillator excited with a different frequency; two factorisations
+		// with different damping check the re-assembly
+		xR, xC := make([]float64, n), make([]float64, n)
+		bR, bC := make([]float64, n), make([]float64, n)
+		for _, cc := range []float64{c, 2 * c} {
+			sys.Start()
+			for i, ω := range ωs {
+				sys.Put(i, i, k-m*ω*ω, ω*cc)
+				bR[i], bC[i] = real(F), imag(F)
+			}
+			err = sys.Fact()
+			if err != nil {
+				tst.Errorf("Fact failed:\n%v", err)
+				return
+			}
+			err = sys.Solve(xR, xC, bR, bC)
+			if err != nil {
+				tst.Errorf("Solve failed:\n%v", err)
+				return
+			}
+			for i, ω := range ωs {
+				u := F / complex(k-m*ω*ω, ω*cc)
+				io.Pf("ω = %5g  |u| = %12.6e  arg(u) = %10.6f\n", ω, cmplx.Abs(u), cmplx.Phase(u))
+				chk.Scalar(tst, io.Sf("Re(u) @ ω=%g", ω), 1e-14, xR[i], real(u))
+				chk.Scalar(tst, io.Sf("Im(u) @ ω=%g", ω), 1e-14, xC[i], imag(u))
+			}
+		}
+		sys.Clean()
+	}
+}
+
+func Test_cplxsys02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("cplxsys02. complex systems: coupled 2DOF system")
+
+	// A = [[2+i, -1], [-1, 3-2i]] and x = [1-i, 2+0.5i] => b = A・x
+	A := [][]complex128{{complex(2, 1), -1}, {-1, complex(3, -2)}}
+	x := []complex128{complex(1, -1), complex(2, 0.5)}
+	b := make([]complex128, 2)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			b[i] += A[i][j] * x[j]
+		}
+	}
+
+	for _, block := range []bool{false, true} {
+		var dat inp.LinSolData
+		dat.SetDefault()
+		dat.CplxBlock = block
+		sys, err := NewCplxSystem(2, 4, &dat)
+		if err != nil {
+			tst.Errorf("NewCplxSystem failed:\n%v", err)
+			return
+		}
+		sys.Start()
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				sys.Put(i, j, real(A[i][j]), imag(A[i][j]))
+			}
+		}
+		err = sys.Fact()
+		if err != nil {
+			tst.Errorf("Fact failed:\n%v", err)
+			return
+		}
+		xR, xC := make([]float64, 2), make([]float64, 2)
+		err = sys.Solve(xR, xC, []float64{real(b[0]), real(b[1])}, []float64{imag(b[0]), imag(b[1])})
+		if err != nil {
+			tst.Errorf("Solve failed:\n%v", err)
+			return
+		}
+		chk.Vector(tst, io.Sf("Re(x) block=%v", block), 1e-14, xR, []float64{real(x[0]), real(x[1])})
+		chk.Vector(tst, io.Sf("Im(x) block=%v", block), 1e-14, xC, []float64{imag(x[0]), imag(x[1])})
+		sys.Clean()
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_determ01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("determ01. deterministic reductions")
+
+	// vectors of 4 processors. entry 1 has cancellations: the result depends on the order of sums
+	v := [][]float64{
+		{1, 1e16, 0, 0.1, 0, 0},
+		{0, 1, 2, 0.2, 0, 0},
+		{3, -1e16, 0, 0.3, 0, 4},
+		{0, 1, 0, 0.4, 0, 0},
+	}
+	nproc, n := len(v), len(v[0])
+
+	// sums in the order of ranks and in reverse order
+	fwd := make([]float64, n)
+	rev := make([]float64, n)
+	for p := 0; p < nproc; p++ {
+		for i := 0; i < n; i++ {
+			fwd[i] += v[p][i]
+			rev[i] += v[nproc-1-p][i]
+		}
+	}
+	if fwd[1] == rev[1] {
+		tst.Errorf("test is not effective: sums in different orders must give different results\n")
+		return
+	}
+
+	// number of nonzero entries in each processor
+	cnt := make([]float64, nproc)
+	for p := 0; p < nproc; p++ {
+		cnt[p] = float64(determ_nnz(v[p]))
+	}
+	chk.Vector(tst, "cnt", 1e-15, cnt, []float64{3, 3, 4, 2})
+
+	// buffers of each processor: slots do not overlap
+	bufs := make([][]float64, nproc)
+	for p := 0; p < nproc; p++ {
+		bufs[p] = determ_pack(v[p], cnt, p)
+		chk.IntAssert(len(bufs[p]), 2*12)
+	}
+	for k := 0; k < len(bufs[0]); k += 2 {
+		var nonzero int
+		for p := 0; p < nproc; p++ {
+			if bufs[p][k+1] != 0 {
+				nonzero++
+			}
+		}
+		chk.IntAssert(nonzero, 1)
+	}
+
+	// reductions of buffers in any order give the same result as the sum in the order of ranks
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {2, 0, 3, 1}} {
+		buf := make([]float64, len(bufs[0]))
+		for _, p := range order {
+			for k, x := range bufs[p] {
+				buf[k] += x
+			}
+		}
+		res := make([]float64, n)
+		determ_unpack(res, buf)
+		chk.Vector(tst, io.Sf("order = %v", order), 0, res, fwd)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_diagnose01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("diagnose01. condition number estimate by Lanczos")
+
+	// K = [[2,1,0,0], [1,2,0,0], [0,0,-4,0], [0,0,0,8]] => |eigenvalues| = {1, 3, 4, 8}
+	var K la.Triplet
+	K.Init(4, 4, 6)
+	K.Put(0, 0, 2)
+	K.Put(0, 1, 1)
+	K.Put(1, 0, 1)
+	K.Put(1, 1, 2)
+	K.Put(2, 2, -4)
+	K.Put(3, 3, 8)
+
+	// all iterations => exact
+	var o KbDiagnosis
+	err := o.lanczos(K.ToMatrix(nil), 4, 30)
+	if err != nil {
+		tst.Errorf("lanczos failed:\n%v", err)
+		return
+	}
+	io.Pforan("%s", o.String())
+	chk.IntAssert(o.Nit, 4)
+	chk.Scalar(tst, "σmin", 1e-12, o.SigMin, 1)
+	chk.Scalar(tst, "σmax", 1e-12, o.SigMax, 8)
+	chk.Scalar(tst, "cond", 1e-11, o.Cond, 8)
+
+	// singular matrix
+	K.Start()
+	K.Put(0, 0, 1)
+	K.Put(0, 1, -1)
+	K.Put(1, 0, -1)
+	K.Put(1, 1, 1)
+	K.Put(2, 2, 1)
+	K.Put(3, 3, 1)
+	err = o.lanczos(K.ToMatrix(nil), 4, 30)
+	if err != nil {
+		tst.Errorf("lanczos failed:\n%v", err)
+		return
+	}
+	if !math.IsInf(o.Cond, 1) {
+		tst.Errorf("condition number of singular matrix should be +Inf. %g is incorrect\n", o.Cond)
+	}
+}
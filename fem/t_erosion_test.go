@@ -0,0 +1,159 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_erosion01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("erosion01. erosion of the tip of a bracket")
+
+	// run
+	main := NewMain("data/ero01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	d := main.Domains[0]
+
+	// erosion is rejected with other solvers
+	dat := &inp.ErosionData{Tags: []int{-2}, Crits: []*inp.ErosionCrit{{Key: "sig1", Max: 1e30}}, Nrel: 2}
+	d.Sim.Solver.Type = "lin-imp"
+	_, err = NewErosion(d, dat)
+	d.Sim.Solver.Type = "imp"
+	if err == nil {
+		tst.Errorf("NewErosion must fail with the linear implicit solver\n")
+		return
+	}
+
+	// criterion not satisfied
+	ero, err := NewErosion(d, dat)
+	if err != nil {
+		tst.Errorf("NewErosion failed:\n%v", err)
+		return
+	}
+	err = ero.Step(d)
+	if err != nil {
+		tst.Errorf("Step failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(len(ero.Elems), 0)
+	chk.IntAssert(len(ero.Freed), 0)
+
+	// forces of elements before erosion
+	nels := len(d.Elems)
+	F0 := make([][]float64, nels)
+	for i, e := range d.Elems {
+		fb := make([]float64, d.Nyb)
+		err = e.AddToRhs(fb, d.Sol)
+		if err != nil {
+			tst.Errorf("AddToRhs failed:\n%v", err)
+			return
+		}
+		for _, eq := range elem_eqs(d, e) {
+			F0[i] = append(F0[i], fb[eq])
+		}
+	}
+
+	// criterion satisfied by all elements; but only the ones with tag -2 can be eroded
+	dat.Crits[0].Max = -1e30
+	err = ero.Step(d)
+	if err != nil {
+		tst.Errorf("Step failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(len(ero.Elems), 2)
+	for i, e := range d.Elems {
+		r, eroded := e.(*ErodedElem)
+		if d.Msh.Cells[e.Id()].Tag != -2 {
+			if eroded {
+				tst.Errorf("element %d must not be eroded\n", e.Id())
+			}
+			continue
+		}
+		if !eroded {
+			tst.Errorf("element %d must be eroded\n", e.Id())
+			return
+		}
+		if d.Cid2elem[e.Id()] != e {
+			tst.Errorf("Cid2elem of element %d must point to eroded element\n", e.Id())
+		}
+		chk.Vector(tst, io.Sf("F0 of element %d", e.Id()), 1e-15, r.F0, F0[i])
+	}
+
+	// freed equations: vertices 4 and 5 are only attached to eroded elements
+	var freed []int
+	for _, vid := range []int{4, 5} {
+		for _, key := range []string{"ux", "uy"} {
+			freed = append(freed, d.Vid2node[vid].GetEq(key))
+		}
+	}
+	chk.IntAssert(len(ero.Freed), len(freed))
+	for _, eq := range freed {
+		y0, ok := ero.Freed[eq]
+		if !ok {
+			tst.Errorf("equation %d must be freed\n", eq)
+			return
+		}
+		chk.Scalar(tst, io.Sf("y0 @ %d", eq), 1e-15, y0, d.Sol.Y[eq])
+	}
+
+	// release of forces over Nrel = 2 steps; nothing is added at freed equations
+	for stp, m := range []float64{1, 0.5, 0} {
+		fb := make([]float64, d.Nyb)
+		fc := make([]float64, d.Nyb)
+		for _, r := range ero.Elems {
+			chk.IntAssert(r.Nstp, stp)
+			err = r.AddToRhs(fb, d.Sol)
+			if err != nil {
+				tst.Errorf("AddToRhs failed:\n%v", err)
+				return
+			}
+			for k, eq := range r.Eqs {
+				if _, ok := ero.Freed[eq]; !ok {
+					fc[eq] += m * r.F0[k]
+				}
+			}
+		}
+		chk.Vector(tst, io.Sf("fb (step %d)", stp), 1e-15, fb, fc)
+		err = ero.Step(d)
+		if err != nil {
+			tst.Errorf("Step failed:\n%v", err)
+			return
+		}
+	}
+	chk.IntAssert(len(ero.Elems), 2)
+
+	// springs holding freed nodes
+	fb := make([]float64, d.Nyb)
+	for k, eq := range freed {
+		d.Sol.Y[eq] += float64(k + 1)
+	}
+	ero.AddToRhs(fb, d.Sol)
+	Kb := new(la.Triplet)
+	Kb.Init(d.Nyb, d.Nyb, d.Nyb)
+	ero.AddToKb(Kb)
+	K := Kb.ToMatrix(nil).ToDense()
+	for eq := 0; eq < d.Nyb; eq++ {
+		fc, kc := 0.0, 0.0
+		for k, e := range freed {
+			if e == eq {
+				fc, kc = -float64(k+1), 1
+			}
+		}
+		chk.Scalar(tst, io.Sf("spring: fb[%d]", eq), 1e-14, fb[eq], fc)
+		chk.Scalar(tst, io.Sf("spring: Kb[%d][%d]", eq, eq), 1e-15, K[eq][eq], kc)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_essenbcs01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("essenbcs01. inclined supports")
+
+	// 2D: angle
+	a := NewNode(&inp.Vert{0, -1, []float64{1, 0}, nil})
+	a.Dofs = []*Dof{{"ux", 0}, {"uy", 1}}
+	var bcs EssentialBcs
+	err := bcs.Set("incsup", []*Node{a}, &fun.Zero, "!alp:30")
+	if err != nil {
+		tst.Errorf("Set failed: %v\n", err)
+		return
+	}
+	chk.IntAssert(len(bcs.Bcs), 1)
+	chk.Ints(tst, "2D: eqs", bcs.Bcs[0].Eqs, []int{0, 1})
+	chk.Vector(tst, "2D: n", 1e-15, bcs.Bcs[0].ValsA, []float64{math.Sqrt(3) / 2, 0.5})
+
+	// 2D: radial direction from centre
+	dirs, err := incsup_dirs([]float64{3, 4}, "!cx:0 !cy:0")
+	if err != nil {
+		tst.Errorf("incsup_dirs failed: %v\n", err)
+		return
+	}
+	chk.Vector(tst, "2D: radial", 1e-15, dirs[0], []float64{0.6, 0.8})
+
+	// 3D: normal and second direction
+	b := NewNode(&inp.Vert{1, -1, []float64{0, 0, 0}, nil})
+	b.Dofs = []*Dof{{"ux", 2}, {"uy", 3}, {"uz", 4}}
+	bcs = EssentialBcs{}
+	err = bcs.Set("incsup", []*Node{b}, &fun.Zero, "!nx:1 !ny:1 !nz:0 !mx:1 !my:0 !mz:1")
+	if err != nil {
+		tst.Errorf("Set failed: %v\n", err)
+		return
+	}
+	io.Pforan("bcs = %v\n", bcs.Bcs)
+	chk.IntAssert(len(bcs.Bcs), 2)
+	s2, s6 := 1.0/math.Sqrt(2), 1.0/math.Sqrt(6)
+	chk.Ints(tst, "3D: eqs", bcs.Bcs[0].Eqs, []int{2, 3, 4})
+	chk.Vector(tst, "3D: n", 1e-15, bcs.Bcs[0].ValsA, []float64{s2, s2, 0})
+	chk.Vector(tst, "3D: m", 1e-15, bcs.Bcs[1].ValsA, []float64{s6, -s6, 2 * s6})
+
+	// 3D: cylindrical surface
+	dirs, err = incsup_dirs([]float64{3, 7, 4}, "!cx:0 !cy:0 !cz:0 !ax:0 !ay:1 !az:0")
+	if err != nil {
+		tst.Errorf("incsup_dirs failed: %v\n", err)
+		return
+	}
+	chk.Vector(tst, "3D: cylinder", 1e-15, dirs[0], []float64{0.6, 0, 0.8})
+
+	// 3D: missing normal
+	_, err = incsup_dirs([]float64{0, 0, 0}, "!alp:30")
+	if err == nil {
+		tst.Errorf("incsup_dirs should have failed in 3D without normal\n")
+	}
+}
+
+func Test_essenbcs02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("essenbcs02. symmetry and antisymmetry planes")
+
+	// constrained dofs
+	keys := func(bcs *EssentialBcs) (res []string) {
+		for _, bc := range bcs.Bcs {
+			res = append(res, bc.Key)
+		}
+		return
+	}
+
+	// 2D: beam and porous nodes
+	a := NewNode(&inp.Vert{0, -1, []float64{0, 0}, nil})
+	a.Dofs = []*Dof{{"ux", 0}, {"uy", 1}, {"rz", 2}}
+	b := NewNode(&inp.Vert{1, -1, []float64{0, 1}, nil})
+	b.Dofs = []*Dof{{"ux", 3}, {"uy", 4}, {"pl", 5}}
+	var bcs EssentialBcs
+	err := bcs.Set("symx", []*Node{a, b}, nil, "")
+	if err != nil {
+		tst.Errorf("Set failed: %v\n", err)
+		return
+	}
+	chk.Strings(tst, "2D: symx", keys(&bcs), []string{"ux", "rz", "ux"})
+	bcs = EssentialBcs{}
+	err = bcs.Set("antisymy", []*Node{a, b}, nil, "")
+	if err != nil {
+		tst.Errorf("Set failed: %v\n", err)
+		return
+	}
+	chk.Strings(tst, "2D: antisymy", keys(&bcs), []string{"ux", "ux", "pl"})
+	err = bcs.Set("symz", []*Node{a}, nil, "")
+	if err == nil {
+		tst.Errorf("symz should have failed in 2D\n")
+	}
+
+	// 3D: shell/beam node
+	c := NewNode(&inp.Vert{2, -1, []float64{0, 0, 0}, nil})
+	c.Dofs = []*Dof{{"ux", 0}, {"uy", 1}, {"uz", 2}, {"rx", 3}, {"ry", 4}, {"rz", 5}}
+	bcs = EssentialBcs{}
+	err = bcs.Set("symz", []*Node{c}, nil, "")
+	if err != nil {
+		tst.Errorf("Set failed: %v\n", err)
+		return
+	}
+	chk.Strings(tst, "3D: symz", keys(&bcs), []string{"uz", "rx", "ry"})
+	bcs = EssentialBcs{}
+	err = bcs.Set("antisymz", []*Node{c}, nil, "")
+	if err != nil {
+		tst.Errorf("Set failed: %v\n", err)
+		return
+	}
+	chk.Strings(tst, "3D: antisymz", keys(&bcs), []string{"ux", "uy", "rz"})
+}
+
+func Test_essenbcs03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("essenbcs03. pinned and clamped nodes")
+
+	// beam node (2D) and solid node
+	a := NewNode(&inp.Vert{0, -1, []float64{0, 0}, nil})
+	a.Dofs = []*Dof{{"ux", 0}, {"uy", 1}, {"rz", 2}}
+	b := NewNode(&inp.Vert{1, -1, []float64{1, 0}, nil})
+	b.Dofs = []*Dof{{"ux", 3}, {"uy", 4}, {"pl", 5}}
+	eqs := func(bcs *EssentialBcs) (res []int) {
+		for _, bc := range bcs.Bcs {
+			res = append(res, bc.Eqs...)
+		}
+		return
+	}
+
+	// pin
+	var bcs EssentialBcs
+	err := bcs.Set("pin", []*Node{a, b}, &fun.Zero, "")
+	if err != nil {
+		tst.Errorf("Set failed: %v\n", err)
+		return
+	}
+	chk.Ints(tst, "pin", eqs(&bcs), []int{0, 1, 3, 4})
+
+	// clamp
+	bcs = EssentialBcs{}
+	err = bcs.Set("clamp", []*Node{a, b}, &fun.Zero, "")
+	if err != nil {
+		tst.Errorf("Set failed: %v\n", err)
+		return
+	}
+	chk.Ints(tst, "clamp", eqs(&bcs), []int{0, 1, 2, 3, 4})
+
+	// keys
+	chk.Strings(tst, "2D: rotations", RotKeys(2), []string{"rz"})
+	chk.Strings(tst, "3D: rotations", RotKeys(3), []string{"rx", "ry", "rz"})
+	if !IsDispOrRot("ry") || IsDispOrRot("pl") {
+		tst.Errorf("IsDispOrRot failed\n")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_explicit01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("explicit01. free fall of spectral elements")
+
+	// run
+	main := NewMain("data/sem01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	d := main.Domains[0]
+
+	// lumped masses: ρ・area for each direction; diagonal at the GLL nodes
+	m := make([]float64, d.Ny)
+	for _, e := range d.Elems {
+		err = e.(ele.WithLumpedMass).AddToLumpedMass(m, d.Sol)
+		if err != nil {
+			tst.Errorf("AddToLumpedMass failed:\n%v", err)
+			return
+		}
+	}
+	var mtot float64
+	for _, v := range m {
+		mtot += v
+	}
+	io.Pforan("total mass = %v\n", mtot)
+	chk.Scalar(tst, "total mass", 1e-13, mtot, 2*2)
+
+	// central differences are exact with constant accelerations: uy = -g t²/2
+	t := d.Sol.T
+	g := 10.0
+	for _, nod := range d.Nodes {
+		ux := d.Sol.Y[nod.GetEq("ux")]
+		uy := d.Sol.Y[nod.GetEq("uy")]
+		vy := d.Sol.Dydt[nod.GetEq("uy")]
+		chk.Scalar(tst, io.Sf("ux @ %d", nod.Vert.Id), 1e-13, ux, 0)
+		chk.Scalar(tst, io.Sf("uy @ %d", nod.Vert.Id), 1e-12, uy, -g*t*t/2)
+		chk.Scalar(tst, io.Sf("vy @ %d", nod.Vert.Id), 1e-11, vy, -g*t)
+	}
+}
@@ -5,6 +5,8 @@
 package fem
 
 import (
+	"io/ioutil"
+	"path"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
@@ -71,3 +73,74 @@ func Test_fileio01(tst *testing.T) {
 	chk.Vector(tst, "dy/dt", 1e-17, domA.Sol.Dydt, domB.Sol.Dydt)
 	chk.Vector(tst, "d²y/dt²", 1e-17, domA.Sol.D2ydt2, domB.Sol.D2ydt2)
 }
+
+func Test_fileio02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("fileio02. Compression and checksums")
+
+	// start
+	main := NewMain("data/bh16.sim", "", true, false, false, false, chk.Verbose, 0)
+	main.Sim.Data.Compress = "gzip"
+	main.Sim.Data.Checksum = true
+
+	// domains
+	doms := NewDomains(main.Sim, main.DynCfs, 0, 1, false, false)
+	domsB := NewDomains(main.Sim, main.DynCfs, 0, 1, false, false)
+	if len(doms) == 0 || len(domsB) == 0 {
+		tst.Errorf("NewDomains failed\n")
+		return
+	}
+	domA, domB := doms[0], domsB[0]
+	for _, d := range []*Domain{domA, domB} {
+		err := d.SetStage(0)
+		if err != nil {
+			tst.Errorf("SetStage failed\n%v", err)
+			return
+		}
+	}
+	for i, _ := range domA.Sol.Y {
+		domA.Sol.Y[i] = float64(i)
+	}
+
+	// write compressed file
+	tidx := 124
+	err := domA.SaveSol(tidx, true)
+	if err != nil {
+		tst.Errorf("SaveSol failed:\n%v", err)
+		return
+	}
+	fn := out_nod_path(main.Sim.DirOut, main.Sim.Key, main.Sim.EncType, tidx, 0) + ".gz"
+	if _, ok := domA.Cksums[path.Base(fn)]; !ok {
+		tst.Errorf("checksum of %q was not recorded\n", fn)
+		return
+	}
+
+	// read file
+	domB.RdSums = domA.Cksums
+	err = domB.ReadSol(main.Sim.DirOut, main.Sim.Key, main.Sim.EncType, tidx)
+	if err != nil {
+		tst.Errorf("ReadSol failed:\n%v", err)
+		return
+	}
+	chk.Vector(tst, "Y", 1e-17, domA.Sol.Y, domB.Sol.Y)
+
+	// corrupted file
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		tst.Errorf("cannot read file:\n%v", err)
+		return
+	}
+	b[len(b)/2] ^= 0xff
+	err = ioutil.WriteFile(fn, b, 0644)
+	if err != nil {
+		tst.Errorf("cannot write file:\n%v", err)
+		return
+	}
+	err = domB.ReadSol(main.Sim.DirOut, main.Sim.Key, main.Sim.EncType, tidx)
+	if err == nil {
+		tst.Errorf("ReadSol should have failed with corrupted file\n")
+		return
+	}
+	io.Pforan("ok: %v\n", err)
+}
@@ -0,0 +1,88 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_graph01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("graph01. graph of nodes with rod-joints")
+
+	// rod (cell 2) crossing two solids (cells 0 and 1) connected by joint (cell 3). the vertices
+	// of joints are not given by all meshes; thus the couplings must come from jlinid and jsldids
+	main := NewMain("../tests/solid/data/rjoint04.sim", "", true, false, false, false, chk.Verbose, 0)
+	d := main.Domains[0]
+	err := main.SetStage(0)
+	if err != nil {
+		tst.Errorf("SetStage failed:\n%v", err)
+		return
+	}
+	d.Msh.Cells[3].Verts = nil
+
+	// graph
+	nbr := d.nodes_graph(false, nil)
+	vid2idx := make(map[int]int)
+	for i, nod := range d.Nodes {
+		vid2idx[nod.Vert.Id] = i
+	}
+	connected := func(a, b int) bool {
+		for _, j := range nbr[vid2idx[a]] {
+			if j == vid2idx[b] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, rv := range []int{12, 13} {
+		for _, sv := range []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11} {
+			if !connected(rv, sv) || !connected(sv, rv) {
+				tst.Errorf("rod vertex %d and solid vertex %d must be neighbours\n", rv, sv)
+			}
+		}
+	}
+	for i, ns := range nbr {
+		for _, j := range ns {
+			if j == i {
+				tst.Errorf("node %d must not be neighbour of itself\n", i)
+			}
+		}
+	}
+
+	// colouring
+	for _, dist2 := range []bool{false, true} {
+		colours := graph_colouring(nbr, dist2)
+		colour := make([]int, len(nbr))
+		nverts := 0
+		for c, vs := range colours {
+			for _, v := range vs {
+				colour[v] = c
+				nverts++
+			}
+		}
+		chk.IntAssert(nverts, len(nbr))
+		io.Pforan("dist2 = %v  ncolours = %d\n", dist2, len(colours))
+		for v := range nbr {
+			for _, w := range nbr[v] {
+				if colour[w] == colour[v] {
+					tst.Errorf("dist2 = %v: neighbours %d and %d have the same colour\n", dist2, v, w)
+				}
+				if !dist2 {
+					continue
+				}
+				for _, u := range nbr[w] {
+					if u != v && colour[u] == colour[v] {
+						tst.Errorf("dist2 = %v: %d and %d have a common neighbour and the same colour\n", dist2, v, u)
+					}
+				}
+			}
+		}
+	}
+}
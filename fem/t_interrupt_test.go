@@ -0,0 +1,158 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_interrupt01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interrupt01. interruption with state flush")
+
+	// simulation with 10 steps and outputs at t = 0, 0.5 and 1
+	main := NewMain("data/bh16.sim", "", true, true, false, false, chk.Verbose, 0)
+	ctrl := &main.Sim.Stages[0].Control
+	ctrl.DtFunc = &fun.Cte{C: 0.1}
+	ctrl.DtoFunc = &fun.Cte{C: 0.5}
+
+	// interrupt during third step
+	main.DebugKb = func(d *Domain, it int) {
+		if d.Sol.T > 0.25 {
+			Interrupt()
+		}
+	}
+
+	// run
+	err := main.Run()
+	if err == nil {
+		tst.Errorf("Run should have been interrupted\n")
+		return
+	}
+	io.Pforan("err = %v\n", err)
+	if !strings.Contains(err.Error(), "interrupted") {
+		tst.Errorf("error message is incorrect: %v\n", err)
+	}
+
+	// state at interruption must be saved
+	chk.IntAssert(len(main.Summary.OutTimes), 2)
+	chk.Scalar(tst, "t0", 1e-15, main.Summary.OutTimes[0], 0)
+	chk.Scalar(tst, "t1", 1e-15, main.Summary.OutTimes[1], 0.3)
+	sum := new(Summary)
+	err = sum.Read(main.Sim.DirOut, main.Sim.Key, main.Sim.EncType)
+	if err != nil {
+		tst.Errorf("cannot read summary:\n%v", err)
+		return
+	}
+	chk.Vector(tst, "saved OutTimes", 1e-15, sum.OutTimes, main.Summary.OutTimes)
+}
+
+func Test_interrupt02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interrupt02. wall-clock budget of stage")
+
+	// simulation with 10 steps and outputs at t = 0, 0.5 and 1
+	main := NewMain("data/bh16.sim", "", true, true, false, false, chk.Verbose, 0)
+	ctrl := &main.Sim.Stages[0].Control
+	ctrl.DtFunc = &fun.Cte{C: 0.1}
+	ctrl.DtoFunc = &fun.Cte{C: 0.5}
+
+	// budget exhausted during first step
+	ctrl.WallMax = 1e-9
+	main.DebugKb = func(d *Domain, it int) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// run
+	err := main.Run()
+	if err == nil {
+		tst.Errorf("Run should have been stopped\n")
+		return
+	}
+	io.Pforan("err = %v\n", err)
+
+	// state after first step must be saved
+	chk.IntAssert(len(main.Summary.OutTimes), 2)
+	chk.Scalar(tst, "t1", 1e-15, main.Summary.OutTimes[1], 0.1)
+}
+
+func Test_interrupt03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interrupt03. interruption of Richardson extrapolation solver")
+
+	// simulation with outputs at t = 0, 0.5 and 1
+	main := NewMain("data/bh16rex.sim", "", true, true, false, false, chk.Verbose, 0)
+	ctrl := &main.Sim.Stages[0].Control
+	ctrl.DtFunc = &fun.Cte{C: 0.1}
+	ctrl.DtoFunc = &fun.Cte{C: 0.5}
+
+	// interrupt after t = 0.25
+	main.DebugKb = func(d *Domain, it int) {
+		if d.Sol.T > 0.25 {
+			Interrupt()
+		}
+	}
+
+	// run
+	err := main.Run()
+	if err == nil {
+		tst.Errorf("Run should have been interrupted\n")
+		return
+	}
+	io.Pforan("err = %v\n", err)
+	if !strings.Contains(err.Error(), "interrupted") {
+		tst.Errorf("error message is incorrect: %v\n", err)
+	}
+
+	// state at interruption must be saved
+	chk.IntAssert(len(main.Summary.OutTimes), 2)
+	tint := main.Summary.OutTimes[1]
+	if tint <= 0.25 || tint >= 1 {
+		tst.Errorf("time at interruption is incorrect: %g\n", tint)
+	}
+	chk.Scalar(tst, "t @ interruption", 1e-15, main.Domains[0].Sol.T, tint)
+}
+
+func Test_interrupt04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interrupt04. wall-clock budget with Richardson extrapolation solver")
+
+	// simulation with outputs at t = 0, 0.5 and 1
+	main := NewMain("data/bh16rex.sim", "", true, true, false, false, chk.Verbose, 0)
+	ctrl := &main.Sim.Stages[0].Control
+	ctrl.DtFunc = &fun.Cte{C: 0.1}
+	ctrl.DtoFunc = &fun.Cte{C: 0.5}
+
+	// budget exhausted during first step
+	ctrl.WallMax = 1e-9
+	main.DebugKb = func(d *Domain, it int) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// run
+	err := main.Run()
+	if err == nil {
+		tst.Errorf("Run should have been stopped\n")
+		return
+	}
+	io.Pforan("err = %v\n", err)
+
+	// state after first accepted step must be saved
+	chk.IntAssert(len(main.Summary.OutTimes), 2)
+	tint := main.Summary.OutTimes[1]
+	if tint <= 0 || tint >= 1 {
+		tst.Errorf("time at interruption is incorrect: %g\n", tint)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_linsol01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linsol01. many right-hand sides: concurrent versus sequential")
+
+	// tridiagonal matrix
+	n := 20
+	A := la.MatAlloc(n, n)
+	var t la.Triplet
+	t.Init(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		A[i][i] = 4
+		if i > 0 {
+			A[i][i-1] = -1
+		}
+		if i < n-1 {
+			A[i][i+1] = -2
+		}
+		for j := 0; j < n; j++ {
+			if A[i][j] != 0 {
+				t.Put(i, j, A[i][j])
+			}
+		}
+	}
+
+	// right-hand sides from known solutions
+	nrhs := 9
+	Xref := la.MatAlloc(nrhs, n)
+	B := la.MatAlloc(nrhs, n)
+	for k := 0; k < nrhs; k++ {
+		for i := 0; i < n; i++ {
+			Xref[k][i] = float64((k+1)*(i+1)%7) - 3
+		}
+		la.MatVecMul(B[k], 1, A, Xref[k])
+	}
+
+	// factorisation
+	lsol := la.GetSolver("umfpack")
+	defer lsol.Clean()
+	err := lsol.InitR(&t, false, false, false)
+	if err != nil {
+		tst.Errorf("InitR failed:\n%v", err)
+		return
+	}
+	err = lsol.Fact()
+	if err != nil {
+		tst.Errorf("Fact failed:\n%v", err)
+		return
+	}
+
+	// sequential
+	seq := NewLinSolver(lsol, false)
+	Xseq := la.MatAlloc(nrhs, n)
+	err = seq.SolveMultiR(Xseq, B, 4)
+	if err != nil {
+		tst.Errorf("SolveMultiR (sequential) failed:\n%v", err)
+		return
+	}
+
+	// concurrent
+	con := NewLinSolver(lsol, true)
+	Xcon := la.MatAlloc(nrhs, n)
+	err = con.SolveMultiR(Xcon, B, 4)
+	if err != nil {
+		tst.Errorf("SolveMultiR (concurrent) failed:\n%v", err)
+		return
+	}
+
+	// check
+	for k := 0; k < nrhs; k++ {
+		chk.Vector(tst, io.Sf("x%d (sequential)", k), 1e-13, Xseq[k], Xref[k])
+		chk.Vector(tst, io.Sf("x%d (concurrent)", k), 1e-13, Xcon[k], Xref[k])
+		chk.Vector(tst, io.Sf("x%d: concurrent - sequential", k), 1e-15, Xcon[k], Xseq[k])
+	}
+
+	// wrong sizes
+	err = con.SolveMultiR(Xcon[:nrhs-1], B, 4)
+	if err == nil {
+		tst.Errorf("SolveMultiR must fail with wrong number of solution vectors\n")
+	}
+	Xcon[0] = make([]float64, n-1)
+	err = con.SolveMultiR(Xcon, B, 4)
+	if err == nil {
+		tst.Errorf("SolveMultiR must fail with wrong size of solution vector\n")
+	}
+}
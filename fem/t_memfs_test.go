@@ -0,0 +1,60 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_memfs01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("memfs01. simulation with results kept in memory")
+
+	// run
+	main := NewMain("data/bh16.sim", "", true, true, false, false, chk.Verbose, 0)
+	InMemory(main.Sim.DirOut, true)
+	defer InMemory(main.Sim.DirOut, false)
+	defer ClearMemory(main.Sim.DirOut, main.Sim.Key)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// no files on disk
+	fn := out_sum_path(main.Sim.DirOut, main.Sim.Key, main.Sim.EncType, 0)
+	if _, err = os.Stat(fn); !os.IsNotExist(err) {
+		tst.Errorf("summary file should not have been written to disk\n")
+		return
+	}
+
+	// read results back (as in the out package)
+	res := NewMain("data/bh16.sim", "", false, false, true, false, chk.Verbose, 0)
+	chk.Vector(tst, "OutTimes", 1e-15, res.Summary.OutTimes, main.Summary.OutTimes)
+	err = res.SetStage(0)
+	if err != nil {
+		tst.Errorf("SetStage failed:\n%v", err)
+		return
+	}
+	d := res.Domains[0]
+	err = d.Read(res.Summary, len(res.Summary.OutTimes)-1, 0, true)
+	if err != nil {
+		tst.Errorf("cannot read results:\n%v", err)
+		return
+	}
+	chk.Vector(tst, "Y", 1e-17, d.Sol.Y, main.Domains[0].Sol.Y)
+
+	// clear memory
+	ClearMemory(main.Sim.DirOut, main.Sim.Key)
+	sum := new(Summary)
+	err = sum.Read(main.Sim.DirOut, main.Sim.Key, main.Sim.EncType)
+	if err == nil {
+		tst.Errorf("summary should have been removed from memory\n")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_mixed01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mixed01. mixed-precision linear solver")
+
+	// reference solution (double precision)
+	main := NewMain("data/bh16.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	Yref := append([]float64{}, main.Domains[0].Sol.Y...)
+	Lref := append([]float64{}, main.Domains[0].Sol.L...)
+
+	// mixed precision
+	main = NewMain("data/bh16.sim", "", true, false, false, false, chk.Verbose, 0)
+	main.Sim.LinSol.Symmetric = true
+	dom := main.Domains[0]
+	mix := NewMixedSolver(dom)
+	dom.LinSol = NewLinSolver(mix, false)
+	err = main.Run()
+	if err != nil {
+		tst.Errorf("Run with mixed-precision solver failed:\n%v", err)
+		return
+	}
+	io.Pforan("number of refinement iterations = %d\n", mix.Nref)
+	if mix.Nref < 1 || mix.Nref > mix.NmaxRef {
+		tst.Errorf("number of refinement iterations %d is incorrect\n", mix.Nref)
+	}
+	chk.IntAssert(len(mix.perm), dom.Nyb)
+	chk.Vector(tst, "Y", 1e-10, dom.Sol.Y, Yref)
+	chk.Vector(tst, "L", 1e-8, dom.Sol.L, Lref)
+}
@@ -0,0 +1,147 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_monitor01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("monitor01. live monitoring server")
+
+	// start simulation and server
+	main := NewMain("data/bh16.sim", "", true, false, false, false, chk.Verbose, 0)
+	dom := main.Domains[0]
+	mon, err := NewMonitor("localhost:0", dom, []int{5})
+	if err != nil {
+		tst.Errorf("NewMonitor failed:\n%v", err)
+		return
+	}
+	defer mon.Stop()
+	dom.Mon = mon
+
+	// run simulation
+	err = main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// status
+	get := func(path string) string {
+		res, err := http.Get("http://" + mon.Addr + path)
+		if err != nil {
+			tst.Errorf("GET %s failed:\n%v", path, err)
+			return ""
+		}
+		defer res.Body.Close()
+		b, _ := ioutil.ReadAll(res.Body)
+		return string(b)
+	}
+	var s MonStatus
+	err = json.Unmarshal([]byte(get("/")), &s)
+	if err != nil {
+		tst.Errorf("cannot unmarshal status:\n%v", err)
+		return
+	}
+	io.Pforan("status: step=%d time=%g done=%v resids=%v\n", s.Step, s.Time, s.Done, s.Resids)
+	chk.IntAssert(s.Step, 1)
+	chk.Scalar(tst, "time", 1e-15, s.Time, 1)
+	if !s.Done {
+		tst.Errorf("simulation should be flagged as done\n")
+	}
+	if len(s.Resids) < 2 {
+		tst.Errorf("there should be at least two residuals. %v is incorrect\n", s.Resids)
+	}
+	chk.IntAssert(len(s.Points), 1)
+	chk.IntAssert(len(s.Points[0].Vals["uy"]), 1)
+	chk.Scalar(tst, "uy @ 5", 1e-15, s.Points[0].Vals["uy"][0], dom.Sol.Y[dom.Vid2node[5].GetEq("uy")])
+
+	// plots
+	for _, path := range []string{"/resid.svg", "/points.svg"} {
+		if !strings.HasPrefix(get(path), "<svg") {
+			tst.Errorf("%s should be an SVG figure\n", path)
+		}
+	}
+}
+
+func Test_monitor02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("monitor02. non-finite residuals and shutdown of server")
+
+	// server started by NewMain
+	main := NewMain("data/bh16mon.sim", "", true, false, false, false, chk.Verbose, 0)
+	mon := main.Mon
+	if mon == nil {
+		tst.Errorf("live monitoring server should have been started\n")
+		return
+	}
+	addr := mon.Addr
+
+	// diverging iterations
+	mon.resid(1)
+	mon.resid(math.NaN())
+	mon.resid(math.Inf(1))
+
+	// non-finite values are served as null
+	res, err := http.Get("http://" + addr + "/")
+	if err != nil {
+		tst.Errorf("GET failed:\n%v", err)
+		return
+	}
+	b, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	chk.IntAssert(res.StatusCode, http.StatusOK)
+	io.Pforan("status = %s\n", b)
+	if !strings.Contains(string(b), "null") {
+		tst.Errorf("non-finite residuals should be served as null\n")
+	}
+	var s MonStatus
+	err = json.Unmarshal(b, &s)
+	if err != nil {
+		tst.Errorf("cannot unmarshal status:\n%v", err)
+		return
+	}
+	chk.IntAssert(len(s.Resids), 3)
+	chk.Scalar(tst, "resid0", 1e-15, s.Resids[0], 1)
+
+	// the copy of status keeps non-finite values
+	s = mon.Status()
+	chk.IntAssert(len(s.Resids), 3)
+	if !math.IsNaN(s.Resids[1]) || !math.IsInf(s.Resids[2], 1) {
+		tst.Errorf("Status should keep non-finite values. %v is incorrect\n", s.Resids)
+	}
+	s.Resids[0] = 123
+	chk.Scalar(tst, "resid0 (after change of copy)", 1e-15, mon.Status().Resids[0], 1)
+
+	// the server is closed at the end of Run
+	err = main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	if main.Mon != nil || main.Domains[0].Mon != nil {
+		tst.Errorf("live monitoring server should have been released\n")
+	}
+	if !mon.Status().Done {
+		tst.Errorf("simulation should be flagged as done\n")
+	}
+	res, err = http.Get("http://" + addr + "/")
+	if err == nil {
+		res.Body.Close()
+		tst.Errorf("live monitoring server should have been closed\n")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/rnd"
+)
+
+func Test_montecarlo01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("montecarlo01. sampling of random parameters")
+
+	rng := rand.New(rand.NewSource(1234))
+	n := 20000
+	for _, dist := range []string{"normal", "lognormal", "gumbel", "uniform"} {
+		dat := &rnd.VarData{M: 10, S: 2, Min: 6, Max: 14, Prm: &fun.Prm{N: "x", D: dist}}
+		if dist == "uniform" {
+			dat.S = 8.0 / math.Sqrt(12.0)
+		}
+		var μ, σ float64
+		X := make([]float64, n)
+		for i := 0; i < n; i++ {
+			x, err := mc_sample(rng, dat)
+			if err != nil {
+				tst.Errorf("mc_sample failed:\n%v", err)
+				return
+			}
+			X[i] = x
+			μ += x / float64(n)
+		}
+		for _, x := range X {
+			σ += (x - μ) * (x - μ) / float64(n-1)
+		}
+		σ = math.Sqrt(σ)
+		io.Pforan("%10s: μ = %v  σ = %v\n", dist, μ, σ)
+		chk.Scalar(tst, dist+": mean", 0.05, μ, dat.M)
+		chk.Scalar(tst, dist+": std", 0.05, σ, dat.S)
+	}
+
+	// invalid distribution
+	_, err := mc_sample(rng, &rnd.VarData{Prm: &fun.Prm{N: "x", D: "frechet"}})
+	if err == nil {
+		tst.Errorf("mc_sample should have failed with invalid distribution\n")
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_outfilter01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("outfilter01. decimation and selected equations")
+
+	// decimation: first and last output times are always saved
+	o := &OutFilter{Dat: &inp.OutFilterData{Every: 3}, Tf: 1}
+	var saved []float64
+	for _, t := range []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 1} {
+		if !o.Skip(t) {
+			saved = append(saved, t)
+		}
+	}
+	chk.Vector(tst, "saved", 1e-15, saved, []float64{0, 0.3, 0.6, 1})
+
+	// no decimation
+	o = &OutFilter{Dat: &inp.OutFilterData{}, Tf: 1}
+	for _, t := range []float64{0, 0.5, 1} {
+		if o.Skip(t) {
+			tst.Errorf("output at t = %g should not be skipped\n", t)
+			return
+		}
+	}
+
+	// selected equations
+	v := []float64{1, 2, 3, 4}
+	chk.Vector(tst, "all", 1e-15, o.Vec(v), v)
+	o.Eqs = []int{1, 3}
+	chk.Vector(tst, "selected", 1e-15, o.Vec(v), []float64{0, 2, 0, 4})
+	if o.Vec(nil) != nil {
+		tst.Errorf("nil vector must remain nil\n")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_renum01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("renum01. renumbering of equations (rcm and nd)")
+
+	// reference solution
+	main := NewMain("data/bh16.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	dom := main.Domains[0]
+	Yref := make(map[string]float64)
+	for _, nod := range dom.Nodes {
+		for _, dof := range nod.Dofs {
+			Yref[io.Sf("%d_%s", nod.Vert.Id, dof.Key)] = dom.Sol.Y[dof.Eq]
+		}
+	}
+
+	// renumbered
+	for _, method := range []string{"rcm", "nd"} {
+		main = NewMain("data/bh16.sim", "", true, false, false, false, chk.Verbose, 0)
+		main.Sim.LinSol.Renum = method
+		err = main.Run()
+		if err != nil {
+			tst.Errorf("Run with %q renumbering failed:\n%v", method, err)
+			return
+		}
+		dom = main.Domains[0]
+		chk.IntAssert(len(Yref), dom.Ny)
+		used := make([]bool, dom.Ny)
+		for _, nod := range dom.Nodes {
+			for _, dof := range nod.Dofs {
+				if used[dof.Eq] {
+					tst.Errorf("%s: equation %d is repeated\n", method, dof.Eq)
+					return
+				}
+				used[dof.Eq] = true
+				key := io.Sf("%d_%s", nod.Vert.Id, dof.Key)
+				chk.Scalar(tst, method+": "+key, 1e-12, dom.Sol.Y[dof.Eq], Yref[key])
+			}
+		}
+	}
+}
+
+func Test_renum02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("renum02. rcm and nd orderings of a grid")
+
+	// 10 x 10 grid of vertices with scattered numbering
+	nx, ny := 10, 10
+	nv := nx * ny
+	vid := func(i, j int) int { return (7*(i*ny+j) + 3) % nv } // 7 and 100 are coprime
+	nbr := make([][]int, nv)
+	for i := 0; i < nx; i++ {
+		for j := 0; j < ny; j++ {
+			a := vid(i, j)
+			if i > 0 {
+				nbr[a] = append(nbr[a], vid(i-1, j))
+			}
+			if i < nx-1 {
+				nbr[a] = append(nbr[a], vid(i+1, j))
+			}
+			if j > 0 {
+				nbr[a] = append(nbr[a], vid(i, j-1))
+			}
+			if j < ny-1 {
+				nbr[a] = append(nbr[a], vid(i, j+1))
+			}
+		}
+	}
+	bandwidth := func(order []int) (bw int) {
+		pos := make([]int, nv)
+		for k, v := range order {
+			pos[v] = k
+		}
+		for a := 0; a < nv; a++ {
+			for _, b := range nbr[a] {
+				if pos[a]-pos[b] > bw {
+					bw = pos[a] - pos[b]
+				}
+			}
+		}
+		return
+	}
+	natural := make([]int, nv)
+	for i := range natural {
+		natural[i] = i
+	}
+	for _, order := range [][]int{renum_rcm(nbr, nil), renum_nd(nbr)} {
+		chk.IntAssert(len(order), nv)
+		seen := make([]bool, nv)
+		for _, v := range order {
+			if seen[v] {
+				tst.Errorf("vertex %d is repeated\n", v)
+				return
+			}
+			seen[v] = true
+		}
+	}
+	bw0, bw1 := bandwidth(natural), bandwidth(renum_rcm(nbr, nil))
+	io.Pforan("bandwidth: natural = %d  rcm = %d\n", bw0, bw1)
+	if bw1 > 2*ny || bw1 >= bw0 {
+		tst.Errorf("rcm failed to reduce the bandwidth: %d => %d\n", bw0, bw1)
+	}
+}
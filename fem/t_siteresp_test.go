@@ -0,0 +1,154 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/cpmech/gofem/inp"
+	mdlsolid "github.com/cpmech/gofem/mdl/solid"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_siteresp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("siteresp01. fft")
+
+	n := 16
+	x := make([]complex128, n)
+	for k := 0; k < n; k++ {
+		x[k] = complex(math.Sin(0.3*float64(k*k)), math.Cos(float64(k)))
+	}
+	X := make([]complex128, n)
+	copy(X, x)
+	sr_fft(X, false)
+	for j := 0; j < n; j++ {
+		var dft complex128
+		for k := 0; k < n; k++ {
+			dft += x[k] * cmplx.Exp(complex(0, -2.0*math.Pi*float64(j*k)/float64(n)))
+		}
+		chk.Scalar(tst, io.Sf("X[%d]", j), 1e-13, cmplx.Abs(X[j]-dft), 0)
+	}
+	sr_fft(X, true)
+	for k := 0; k < n; k++ {
+		chk.Scalar(tst, io.Sf("x[%d]", k), 1e-15, cmplx.Abs(X[k]-x[k]), 0)
+	}
+}
+
+func Test_siteresp02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("siteresp02. transfer functions of uniform layer")
+
+	// uniform damped layer: H = 10, vs = 200
+	ρ, vs, ξ, H := 2.0, 200.0, 0.05, 10.0
+	G := ρ * vs * vs
+	layer := func(h float64) *SrLayer { return &SrLayer{H: h, Rho: ρ, G: G, Damp: ξ} }
+	one := &SrColumn{Layers: []*SrLayer{layer(H)}}
+	two := &SrColumn{Layers: []*SrLayer{layer(H / 2), layer(H / 2)}}
+	var dat inp.SiteRespData
+	for _, ω := range []float64{1, 20, 31.4, 50, 100} {
+		k := complex(ω, 0) / cmplx.Sqrt(complex(G, 2*G*ξ)/complex(ρ, 0))
+		kH := k * complex(H, 0)
+
+		// rigid base: hs = 1 / cos(k* H) and γ(z) = -k* sin(k* z) / cos(k* H)
+		dat.RockVs = 0
+		hs, hγ := one.transfer(&dat, ω)
+		chk.Scalar(tst, "hs(rigid)", 1e-13, cmplx.Abs(hs-1/cmplx.Cos(kH)), 0)
+		chk.Scalar(tst, "hγ(rigid)", 1e-13, cmplx.Abs(hγ[0]+k*cmplx.Sin(kH/2)/cmplx.Cos(kH)), 0)
+		hs2, hγ2 := two.transfer(&dat, ω)
+		chk.Scalar(tst, "hs(two layers)", 1e-13, cmplx.Abs(hs2-hs), 0)
+		chk.Scalar(tst, "hγ(two layers)", 1e-13, cmplx.Abs(hγ2[0]+k*cmplx.Sin(kH/4)/cmplx.Cos(kH)), 0)
+
+		// elastic bedrock (outcrop motion): hs = 1 / (cos(k* H) + i α* sin(k* H))
+		dat.RockVs, dat.RockRho, dat.RockDamp = 800, 2.5, 0.01
+		Gr := dat.RockRho * dat.RockVs * dat.RockVs
+		α := cmplx.Sqrt(complex(ρ, 0)*complex(G, 2*G*ξ)) / cmplx.Sqrt(complex(dat.RockRho, 0)*complex(Gr, 2*Gr*dat.RockDamp))
+		hs, _ = one.transfer(&dat, ω)
+		io.Pforan("ω = %5g  |hs| = %v\n", ω, cmplx.Abs(hs))
+		chk.Scalar(tst, "hs(rock)", 1e-13, cmplx.Abs(hs-1/(cmplx.Cos(kH)+complex(0, 1)*α*cmplx.Sin(kH))), 0)
+		hs2, _ = two.transfer(&dat, ω)
+		chk.Scalar(tst, "hs(rock; two layers)", 1e-13, cmplx.Abs(hs2-hs), 0)
+	}
+
+	// fundamental frequency: vs / (4 H)
+	chk.Scalar(tst, "f1", 1e-13, two.fundamental(), vs/(4*H))
+}
+
+func Test_siteresp03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("siteresp03. equivalent-linear iterations")
+
+	// hysteretic layers
+	ρ, vs := 2.0, 200.0
+	var mdl mdlsolid.Hysteretic
+	err := mdl.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "G", V: ρ * vs * vs},
+		&fun.Prm{N: "nu", V: 0.3},
+		&fun.Prm{N: "rho", V: ρ},
+		&fun.Prm{N: "gref", V: 5e-4},
+		&fun.Prm{N: "Dmin", V: 0.01},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	col := new(SrColumn)
+	for i := 0; i < 5; i++ {
+		col.Layers = append(col.Layers, &SrLayer{Cid: i, Top: -4 * float64(i), H: 4, Rho: ρ, Gmax: mdl.G, Dmin: mdl.Dmin, mdl: &mdl})
+	}
+
+	// harmonic input at f = 2 Hz over bedrock
+	dat := &inp.SiteRespData{Dt: 0.01, Tf: 4, RockVs: 800, RockRho: 2.5, RockDamp: 0.01, Tol: 0.01, MaxIt: 20}
+	for _, amp := range []float64{0.001, 2} {
+		accel, err := fun.New("cos", []*fun.Prm{
+			&fun.Prm{N: "a", V: amp},
+			&fun.Prm{N: "b", V: 4 * math.Pi},
+			&fun.Prm{N: "c", V: 0},
+		})
+		if err != nil {
+			tst.Errorf("cannot allocate function:\n%v", err)
+			return
+		}
+		acc, ω := sr_input(dat, accel)
+		err = col.run(dat, acc, ω)
+		if err != nil {
+			tst.Errorf("run failed:\n%v", err)
+			return
+		}
+		io.Pforan("amp = %v: nit = %d  f1 = %v  f1min = %v  amax = %v\n", amp, col.Nit, col.F1, col.F1min, col.Amax)
+		chk.Scalar(tst, "f1min", 1e-13, col.F1min, vs/(4*20))
+		for _, l := range col.Layers {
+			io.Pforan("  γ = %11.4e  G/Gmax = %.4f  ξ = %.4f\n", l.Gam, l.G/l.Gmax, l.Damp)
+			G, ξ := mdl.Equivalent(l.Gam)
+			chk.Scalar(tst, "G", 0.02*l.G, l.G, G)
+			chk.Scalar(tst, "ξ", 0.02*l.Damp, l.Damp, ξ)
+			if amp < 0.01 {
+				chk.Scalar(tst, "G/Gmax(weak)", 1e-2, l.G/l.Gmax, 1)
+			}
+		}
+		if amp < 0.01 {
+			if col.Nit > 2 {
+				tst.Errorf("weak motion should converge in at most 2 iterations. nit = %d\n", col.Nit)
+			}
+			continue
+		}
+		if col.F1 >= col.F1min {
+			tst.Errorf("strong motion should reduce the fundamental frequency. f1 = %g, f1min = %g\n", col.F1, col.F1min)
+		}
+		for _, l := range col.Layers[1:] {
+			if l.G > 0.9*l.Gmax || l.Damp < 2*l.Dmin {
+				tst.Errorf("strong motion should degrade the properties of layer %d. G/Gmax = %g and ξ = %g\n", l.Cid, l.G/l.Gmax, l.Damp)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_tracking01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("tracking01. nonlocal principal stresses for embedded cracks")
+
+	// principal stresses
+	σ1, n := track_principal([]float64{3, 1, 0})
+	chk.Scalar(tst, "σ1 (diagonal)", 1e-15, σ1, 3)
+	chk.Vector(tst, "n (diagonal)", 1e-15, n, []float64{1, 0})
+	σ1, n = track_principal([]float64{1, 3, 0})
+	chk.Scalar(tst, "σ1 (diagonal; y)", 1e-15, σ1, 3)
+	chk.Vector(tst, "n (diagonal; y)", 1e-15, []float64{math.Abs(n[0]), math.Abs(n[1])}, []float64{0, 1})
+	σ1, n = track_principal([]float64{0, 0, 2}) // pure shear
+	io.Pforan("n = %v\n", n)
+	chk.Scalar(tst, "σ1 (shear)", 1e-15, σ1, 2)
+	chk.Vector(tst, "n (shear)", 1e-15, n, []float64{math.Sqrt2 / 2, math.Sqrt2 / 2})
+
+	// nonlocal average: symmetric points => mean; points outside radius are ignored
+	pts := [][]float64{{-0.5, 0}, {0.5, 0}, {0, 0}, {2, 0}}
+	σs := [][]float64{{1, 0, 0}, {3, 0, 0}, {2, 1, 0}, {100, 100, 100}}
+	σ := track_nonlocal([]float64{0, 0}, 1, pts, σs)
+	w := (1 - 0.25) * (1 - 0.25)
+	chk.Vector(tst, "σ (nonlocal)", 1e-15, σ, []float64{2, 1 / (1 + 2*w), 0})
+	σ = track_nonlocal([]float64{10, 10}, 1, pts, σs)
+	chk.Vector(tst, "σ (no points)", 1e-15, σ, []float64{0, 0, 0})
+}
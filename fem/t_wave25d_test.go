@@ -0,0 +1,93 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_wave25d01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("wave25d01. 2.5D dynamic stiffness of qua4")
+
+	// unit square
+	sh := shp.Get("qua4", 0)
+	X := [][]float64{
+		{0, 1, 1, 0},
+		{0, 0, 1, 1},
+	}
+	ips, _, err := sh.GetIps(4, 0)
+	if err != nil {
+		tst.Errorf("GetIps failed:\n%v", err)
+		return
+	}
+	λ, G, ρ := 100.0, 50.0, 2.0
+	n := 3 * sh.Nverts
+	K := make([][]complex128, n)
+	for i := 0; i < n; i++ {
+		K[i] = make([]complex128, n)
+	}
+
+	// quadratic form uᴴ K u
+	form := func(u []complex128) (res complex128) {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				res += cmplx.Conj(u[i]) * K[i][j] * u[j]
+			}
+		}
+		return
+	}
+	uniform := func(a int) (u []complex128) {
+		u = make([]complex128, n)
+		for m := 0; m < sh.Nverts; m++ {
+			u[3*m+a] = 1
+		}
+		return
+	}
+
+	// k = 0, Ω = 0: rigid body translations
+	err = Wave25Matrix(K, sh, X, ips, λ, G, ρ, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("Wave25Matrix failed:\n%v", err)
+		return
+	}
+	for a := 0; a < 3; a++ {
+		chk.Scalar(tst, io.Sf("rigid %d", a), 1e-13, cmplx.Abs(form(uniform(a))), 0)
+	}
+
+	// k ≠ 0: K is Hermitian and uniform displacements give ε_zz = -i k uz or γ = -i k u
+	k := 3.0
+	err = Wave25Matrix(K, sh, X, ips, λ, G, ρ, 0, k, 0)
+	if err != nil {
+		tst.Errorf("Wave25Matrix failed:\n%v", err)
+		return
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			chk.Scalar(tst, "hermitian", 1e-13, cmplx.Abs(K[i][j]-cmplx.Conj(K[j][i])), 0)
+		}
+	}
+	chk.Scalar(tst, "energy ux", 1e-12, real(form(uniform(0))), G*k*k)
+	chk.Scalar(tst, "energy uy", 1e-12, real(form(uniform(1))), G*k*k)
+	chk.Scalar(tst, "energy uz", 1e-12, real(form(uniform(2))), (λ+2.0*G)*k*k)
+
+	// damping and inertia
+	ξ, Ω := 0.05, 10.0
+	err = Wave25Matrix(K, sh, X, ips, λ, G, ρ, ξ, k, Ω)
+	if err != nil {
+		tst.Errorf("Wave25Matrix failed:\n%v", err)
+		return
+	}
+	res := form(uniform(2))
+	chk.Scalar(tst, "re(uz)", 1e-12, real(res), (λ+2.0*G)*k*k-ρ*Ω*Ω)
+	chk.Scalar(tst, "im(uz)", 1e-12, imag(res), 2.0*ξ*(λ+2.0*G)*k*k)
+}
@@ -0,0 +1,264 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// SetTie sets the constraints of a tie between non-matching meshed parts (mortar method)
+//  The constraints for each slave vertex i and dof are:
+//
+//      Σ_j D_ij・y_j - Σ_k M_ik・y_k = 0
+//
+//  with
+//
+//      D_ij = ∫ N_i N_j dΓ    and    M_ik = ∫ N_i Ñ_k dΓ
+//
+//  where N are the shape functions of slave faces, Ñ are the shape functions of master cells
+//  evaluated at the projections of the slave points and the integrals are computed over the
+//  slave faces
+//  Note: (1) the slave faces are subdivided (Nsub) because Ñ is only piecewise smooth on them
+//        (2) if the cells do not have the dof at all vertices (e.g. "pl" in qua8 cells of mixed
+//            formulations), the basic shape (e.g. qua4) is used
+//        (3) the constraints are handled by Lagrange multipliers or penalties; "elim" only
+//            eliminates the single-point constraints
+func (o *Domain) SetTie(dat *inp.TieData) (err error) {
+
+	// check
+	if o.Distr {
+		return chk.Err("tie constraints are not available in parallel runs")
+	}
+	slaves, ok := o.Msh.FaceTag2cells[dat.Slave]
+	if !ok {
+		return chk.Err("cannot find slave faces with tag = %d to set tie constraints", dat.Slave)
+	}
+	masters, ok := o.Msh.FaceTag2cells[dat.Master]
+	if !ok {
+		return chk.Err("cannot find master faces with tag = %d to set tie constraints", dat.Master)
+	}
+
+	// equations with single-point constraints
+	spc := make(map[int]bool)
+	for _, bc := range o.EssenBcs.Bcs {
+		if len(bc.Eqs) == 1 {
+			spc[bc.Eqs[0]] = true
+		}
+	}
+
+	// constraints
+	for _, key := range dat.Keys {
+		rows, err := o.tie_mortar(slaves, masters, key, dat.Nsub)
+		if err != nil {
+			return chk.Err("cannot compute mortar coefficients of tie constraints for %q:\n%v", key, err)
+		}
+		svids := make([]int, 0, len(rows))
+		for vid, _ := range rows {
+			svids = append(svids, vid)
+		}
+		sort.Ints(svids)
+		for _, svid := range svids {
+			if spc[o.Vid2node[svid].GetEq(key)] {
+				continue
+			}
+			row := rows[svid]
+			eqs := make([]int, 0, len(row))
+			eq2vid := make(map[int]int)
+			for vid, _ := range row {
+				eq := o.Vid2node[vid].GetEq(key)
+				eqs = append(eqs, eq)
+				eq2vid[eq] = vid
+			}
+			sort.Ints(eqs)
+			vals := make([]float64, len(eqs))
+			for j, eq := range eqs {
+				vals[j] = row[eq2vid[eq]]
+			}
+			o.EssenBcs.AddMpc("tie", eqs, vals, &fun.Zero)
+		}
+	}
+	return
+}
+
+// tie_mortar computes the mortar coefficients of tie constraints for dof (key)
+//  Output:
+//   rows -- slave vertex id => vertex id => coefficient; i.e. D_ij (slave) and -M_ik (master)
+func (o *Domain) tie_mortar(slaves, masters []inp.CellFaceId, key string, nsub int) (rows map[int]map[int]float64, err error) {
+
+	// master cells and their shapes
+	mcells := make([]*inp.Cell, 0)
+	mshps := make([]*shp.Shape, 0)
+	mX := make([][][]float64, 0)
+	for _, pair := range masters {
+		sh := o.tie_shape(pair.C, key)
+		if sh == nil || o.Cid2elem[pair.C.Id] == nil {
+			continue
+		}
+		mcells = append(mcells, pair.C)
+		mshps = append(mshps, sh)
+		mX = append(mX, o.cell_coords(pair.C, pair.C.Shp.Nverts))
+	}
+
+	// loop over slave faces
+	rows = make(map[int]map[int]float64)
+	ndim := o.Msh.Ndim
+	x := make([]float64, ndim)
+	r := make([]float64, 3)
+	for _, pair := range slaves {
+		c, fid := pair.C, pair.Fid
+		sh := o.tie_shape(c, key)
+		if sh == nil || o.Cid2elem[c.Id] == nil {
+			continue
+		}
+		_, ipf, err := sh.GetIps(0, 0)
+		if err != nil {
+			return nil, err
+		}
+		X := o.cell_coords(c, sh.Nverts)
+		fverts := sh.FaceLocalVerts[fid]
+		sf := make([]float64, len(fverts))
+		for _, ip := range tie_subdivide(sh.FaceType, ipf, nsub) {
+
+			// slave shape functions and coordinates
+			err = sh.CalcAtFaceIp(X, ip, fid)
+			if err != nil {
+				return nil, err
+			}
+			coef := ip[3] * la.VecNorm(sh.Fnvec)
+			for i := 0; i < ndim; i++ {
+				x[i] = 0
+				for k, m := range fverts {
+					x[i] += sh.Sf[k] * X[i][m]
+				}
+			}
+			copy(sf, sh.Sf)
+
+			// master cell containing x
+			best, imaster := 0.0, -1
+			for i, mc := range mcells {
+				if mc.Shp.InvMap(r, x, mX[i]) != nil {
+					continue
+				}
+				dist := mc.Shp.CellBryDist(r)
+				if imaster < 0 || dist > best {
+					best, imaster = dist, i
+				}
+				if dist >= 0 {
+					break
+				}
+			}
+			if imaster < 0 || best < -inp.LOCATE_OUTTOL {
+				return nil, chk.Err("cannot project point x=%v of slave faces onto master faces", x)
+			}
+			mc, msh := mcells[imaster], mshps[imaster]
+			mc.Shp.InvMap(r, x, mX[imaster])
+			msh.Func(msh.S, msh.DSdR, r, false, -1)
+
+			// coefficients
+			for k, m := range fverts {
+				row, ok := rows[c.Verts[m]]
+				if !ok {
+					row = make(map[int]float64)
+					rows[c.Verts[m]] = row
+				}
+				for l, n := range fverts {
+					row[c.Verts[n]] += coef * sf[k] * sf[l]
+				}
+				for n := 0; n < msh.Nverts; n++ {
+					if math.Abs(msh.S[n]) > 1e-12 {
+						row[mc.Verts[n]] -= coef * sf[k] * msh.S[n]
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// tie_shape returns the shape structure of cell c to interpolate dof (key); i.e. the cell's shape
+// or its basic shape if some vertices do not have the dof. Returns nil if the dof is not available
+func (o *Domain) tie_shape(c *inp.Cell, key string) *shp.Shape {
+	has := func(nverts int) bool {
+		for _, vid := range c.Verts[:nverts] {
+			nod := o.Vid2node[vid]
+			if nod == nil || nod.GetEq(key) < 0 {
+				return false
+			}
+		}
+		return true
+	}
+	sh := c.Shp
+	if sh == nil || sh.Nurbs != nil {
+		return nil
+	}
+	if has(sh.Nverts) {
+		return sh
+	}
+	if sh.BasicType == sh.Type || !has(sh.BasicNverts) {
+		return nil
+	}
+	return shp.Get(sh.BasicType, c.GoroutineId)
+}
+
+// cell_coords returns the matrix of coordinates [ndim][nverts] of the first nverts vertices of cell c
+func (o *Domain) cell_coords(c *inp.Cell, nverts int) (X [][]float64) {
+	X = la.MatAlloc(o.Msh.Ndim, nverts)
+	for i := 0; i < o.Msh.Ndim; i++ {
+		for j := 0; j < nverts; j++ {
+			X[i][j] = o.Msh.Verts[c.Verts[j]].C[i]
+		}
+	}
+	return
+}
+
+// tie_subdivide returns the integration points of a face (of type ftype) subdivided into nsub
+// parts along each direction; ipf are the integration points of the face
+func tie_subdivide(ftype string, ipf []shp.Ipoint, nsub int) (res []shp.Ipoint) {
+	h := 1.0 / float64(nsub)
+
+	// triangles: nsub² sub-triangles (upward and downward)
+	if strings.HasPrefix(ftype, "tri") {
+		for i := 0; i < nsub; i++ {
+			for j := 0; i+j < nsub; j++ {
+				for _, ip := range ipf {
+					a, b := float64(i)+ip[0], float64(j)+ip[1]
+					res = append(res, shp.Ipoint{a * h, b * h, 0, ip[3] * h * h})
+					if i+j < nsub-1 {
+						a, b = float64(i+1)-ip[0], float64(j+1)-ip[1]
+						res = append(res, shp.Ipoint{a * h, b * h, 0, ip[3] * h * h})
+					}
+				}
+			}
+		}
+		return
+	}
+
+	// lines and quadrilaterals: sub-intervals of [-1, 1]
+	centre := func(i int) float64 { return -1.0 + float64(2*i+1)*h }
+	if strings.HasPrefix(ftype, "lin") {
+		for i := 0; i < nsub; i++ {
+			for _, ip := range ipf {
+				res = append(res, shp.Ipoint{centre(i) + ip[0]*h, 0, 0, ip[3] * h})
+			}
+		}
+		return
+	}
+	for i := 0; i < nsub; i++ {
+		for j := 0; j < nsub; j++ {
+			for _, ip := range ipf {
+				res = append(res, shp.Ipoint{centre(i) + ip[0]*h, centre(j) + ip[1]*h, 0, ip[3] * h * h})
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,249 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Tracking implements the initiation and propagation of embedded cracks (2D) in solids with
+// extra = "!xcrk:1" (E-FEM; see ele/solid/solid-xfem.go)
+//  Note: (1) after each converged time step, the nonlocal stresses are computed as weighted
+//            averages of the stresses at integration points within the radius R with the
+//            bell-shaped function w(r) = (1 - r²/R²)²
+//        (2) a crack propagates from its tip into the neighbour (uncracked) element if the nonlocal
+//            maximum principal stress at the tip reaches the tensile strength of the cohesive law;
+//            the new segment starts at the tip and is perpendicular to the maximum principal
+//            stress. Thus, the crack path is continuous across elements
+//        (3) a crack is initiated at the centroid of an element (not adjacent to cracked elements)
+//            if the nonlocal maximum principal stress at the centroid reaches the tensile strength
+//        (4) at most Nmax elements are cracked after each time step; those with larger ratios
+//            between stress and strength first
+//        (5) only serial runs are supported because the nonlocal stresses require all elements
+type Tracking struct {
+	Dat    *inp.TrackData       // input data
+	Tags   map[int]bool         // tags of solids where cracks can be initiated; empty => all solids
+	Radius float64              // radius of nonlocal averaging
+	Elems  map[int]*solid.Solid // solids with embedded cracks; cell id => element
+}
+
+// NewTracking allocates a new Tracking structure for the solids of domain
+func NewTracking(d *Domain, dat *inp.TrackData) (o *Tracking, err error) {
+	if d.Distr {
+		return nil, chk.Err("tracking of embedded cracks is not available in parallel runs")
+	}
+	if d.Sim.Ndim != 2 {
+		return nil, chk.Err("tracking of embedded cracks is only available in 2D")
+	}
+	if d.Sim.LinSol.Symmetric {
+		return nil, chk.Err("tracking of embedded cracks requires a non-symmetric linear solver; i.e. linsol.symmetric = false")
+	}
+	o = new(Tracking)
+	o.Dat = dat
+	o.Tags = make(map[int]bool)
+	for _, tag := range dat.Tags {
+		o.Tags[tag] = true
+	}
+	o.Elems = make(map[int]*solid.Solid)
+	var size float64
+	for _, e := range d.Elems {
+		s, ok := e.(*solid.Solid)
+		if !ok || !s.Xcrk {
+			continue
+		}
+		if s.XcrkStrength() <= 0 {
+			return nil, chk.Err("cohesive law of solid (eid=%d) must have a positive tensile strength", s.Id())
+		}
+		o.Elems[s.Id()] = s
+		size += math.Sqrt(track_area(s))
+	}
+	if len(o.Elems) == 0 {
+		return nil, chk.Err("tracking of embedded cracks requires solids with extra = \"!xcrk:1\"")
+	}
+	o.Radius = dat.Radius
+	if o.Radius <= 0 {
+		o.Radius = 2.0 * size / float64(len(o.Elems))
+	}
+	return
+}
+
+// Step initiates or propagates embedded cracks after a time step has converged
+func (o *Tracking) Step(d *Domain) (err error) {
+
+	// stresses at integration points
+	var pts, σs [][]float64
+	for _, e := range d.Elems {
+		out, ok := e.(ele.CanOutputIps)
+		if !ok {
+			continue
+		}
+		M := ele.NewIpsMap()
+		out.OutIpVals(M, d.Sol)
+		if _, ok = (*M)["sx"]; !ok {
+			continue
+		}
+		for i, x := range out.OutIpCoords() {
+			pts = append(pts, x)
+			σs = append(σs, []float64{(*M)["sx"][i], (*M)["sy"][i], (*M)["sxy"][i] / math.Sqrt2}) // "sxy" is the Mandel component
+		}
+	}
+
+	// candidates: propagation from tips
+	var cands track_cands
+	near := make(map[int]bool) // elements sharing vertices with cracked elements
+	for cid, s := range o.Elems {
+		if !s.XcrkOn() {
+			continue
+		}
+		for _, v := range s.Cell.Verts {
+			for _, c := range d.Msh.Verts[v].SharedBy {
+				near[c] = true
+			}
+		}
+		pa, pb, ea, eb := s.XcrkSegment()
+		for k, e := range []int{ea, eb} {
+			if e < 0 {
+				continue
+			}
+			x := pa
+			if k == 1 {
+				x = pb
+			}
+			nb := o.neighbour(d, cid, e)
+			if nb == nil || nb.XcrkOn() {
+				continue
+			}
+			σ1, n := track_principal(track_nonlocal(x, o.Radius, pts, σs))
+			if r := σ1 / nb.XcrkStrength(); r >= 1 {
+				cands = append(cands, &track_cand{nb, x, n, r})
+			}
+		}
+	}
+
+	// candidates: initiation
+	for cid, s := range o.Elems {
+		if s.XcrkOn() || near[cid] || (len(o.Tags) > 0 && !o.Tags[s.Cell.Tag]) {
+			continue
+		}
+		x := track_centroid(s)
+		σ1, n := track_principal(track_nonlocal(x, o.Radius, pts, σs))
+		if r := σ1 / s.XcrkStrength(); r >= 1 {
+			cands = append(cands, &track_cand{s, x, n, r})
+		}
+	}
+	sort.Stable(cands)
+
+	// crack elements
+	ncrk := 0
+	for _, c := range cands {
+		if ncrk == o.Dat.Nmax {
+			break
+		}
+		if c.s.XcrkOn() {
+			continue // cracked by another candidate
+		}
+		if c.s.XcrkInit(c.x, c.n) != nil {
+			continue // line does not cross the element; e.g. tip @ vertex
+		}
+		ncrk++
+		if d.ShowMsg {
+			io.Pf("\n>> embedded crack in element %d at t = %g: x0 = %v, n = %v\n", c.s.Id(), d.Sol.T, c.x, c.n)
+		}
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// track_cand holds an element that can be cracked
+type track_cand struct {
+	s    *solid.Solid // element
+	x, n []float64    // point and normal of crack line
+	r    float64      // ratio between nonlocal principal stress and strength
+}
+
+// track_cands implements sort.Interface to sort candidates by decreasing ratio
+type track_cands []*track_cand
+
+func (o track_cands) Len() int           { return len(o) }
+func (o track_cands) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o track_cands) Less(i, j int) bool { return o[i].r > o[j].r }
+
+// neighbour returns the solid with embedded crack sharing edge e of cell cid; or nil
+func (o *Tracking) neighbour(d *Domain, cid, e int) *solid.Solid {
+	c := d.Msh.Cells[cid]
+	l := c.Shp.FaceLocalVerts[e]
+	v0, v1 := d.Msh.Verts[c.Verts[l[0]]], d.Msh.Verts[c.Verts[l[1]]]
+	for _, nb := range v0.SharedBy {
+		if nb == cid || utl.IntIndexSmall(v1.SharedBy, nb) < 0 {
+			continue
+		}
+		if s, ok := o.Elems[nb]; ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// track_nonlocal computes the nonlocal stresses {σxx, σyy, σxy} at x with the bell-shaped weight
+// function; returns zero if there are no points within the radius
+func track_nonlocal(x []float64, radius float64, pts, σs [][]float64) (σ []float64) {
+	σ = make([]float64, 3)
+	var sum float64
+	for k, p := range pts {
+		r2 := ((p[0]-x[0])*(p[0]-x[0]) + (p[1]-x[1])*(p[1]-x[1])) / (radius * radius)
+		if r2 >= 1 {
+			continue
+		}
+		w := (1 - r2) * (1 - r2)
+		for i := 0; i < 3; i++ {
+			σ[i] += w * σs[k][i]
+		}
+		sum += w
+	}
+	if sum > 0 {
+		for i := 0; i < 3; i++ {
+			σ[i] /= sum
+		}
+	}
+	return
+}
+
+// track_principal returns the maximum principal stress and its direction
+func track_principal(σ []float64) (σ1 float64, n []float64) {
+	c, r := (σ[0]+σ[1])/2.0, math.Sqrt((σ[0]-σ[1])*(σ[0]-σ[1])/4.0+σ[2]*σ[2])
+	θ := math.Atan2(2.0*σ[2], σ[0]-σ[1]) / 2.0
+	return c + r, []float64{math.Cos(θ), math.Sin(θ)}
+}
+
+// track_centroid returns the centroid of the corner vertices of element
+func track_centroid(s *solid.Solid) (x []float64) {
+	x = make([]float64, 2)
+	l := s.Cell.Shp.FaceLocalVerts
+	for _, f := range l {
+		for i := 0; i < 2; i++ {
+			x[i] += s.X[i][f[0]] / float64(len(l))
+		}
+	}
+	return
+}
+
+// track_area returns the area of the polygon made of the corner vertices of element
+func track_area(s *solid.Solid) (A float64) {
+	for _, f := range s.Cell.Shp.FaceLocalVerts {
+		p, q := f[0], f[1]
+		A += (s.X[0][p]*s.X[1][q] - s.X[0][q]*s.X[1][p]) / 2.0
+	}
+	return math.Abs(A)
+}
@@ -0,0 +1,209 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// WbRecord holds the water balance after a time step
+type WbRecord struct {
+	T      float64 // time
+	Mass   float64 // mass of liquid stored in elements
+	Qnat   float64 // rate of inflow across faces with natural boundary conditions; e.g. "ql" and "seep"
+	Qess   float64 // rate of inflow across nodes with prescribed liquid pressure (reactions)
+	Qsrc   float64 // rate of inflow by point sources (and unbalanced residuals)
+	Vin    float64 // cumulative inflow (mass) since the beginning of stage
+	Err    float64 // balance error: (Mass - Mass0) - Vin
+	RelErr float64 // relative balance error: Err / max(|Mass - Mass0|, |Vin|)
+}
+
+// WaterBalance implements the accounting of liquid mass during transient stages of seepage and
+// porous simulations. After each step, the change of mass stored in elements (∫ρl dV) is compared
+// with the inflow across boundaries (natural and essential conditions) and by point sources,
+// integrated in time with the θ-method
+//  Note: (1) the inflow rates are computed from the residuals of the elements at the liquid
+//            pressure equations; thus the error measures the inconsistency between the stored
+//            mass and its linearised (time-discrete) rate; large errors flag time steps that are
+//            too large or iterations that have not converged
+//        (2) only elements implementing ele.WithLiqStorage are considered; e.g. seepage.Liquid
+//            and porous.SolidLiquid
+//        (3) only serial runs are supported
+type WaterBalance struct {
+	Stage   int         // index of stage
+	Tol     float64     // relative errors larger than this value are flagged
+	Eqs     []int       // liquid pressure equations
+	Ess     []bool      // [len(Eqs)] liquid pressure is prescribed
+	Mass0   float64     // mass of liquid at the beginning of stage
+	Records []*WbRecord // records of each time step
+	Nflag   int         // number of steps with relative error larger than Tol
+	qin     float64     // total rate of inflow after last step
+}
+
+// NewWaterBalance allocates a new WaterBalance structure for the stage (stgidx) of domain
+func NewWaterBalance(d *Domain, stgidx int) (o *WaterBalance, err error) {
+
+	// check
+	if d.Distr {
+		return nil, chk.Err("water balance is not available in parallel runs")
+	}
+	if d.Sim.Data.Steady {
+		return nil, chk.Err("water balance requires a transient simulation")
+	}
+	found := false
+	for _, e := range d.Elems {
+		if _, ok := e.(ele.WithLiqStorage); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, chk.Err("water balance requires elements storing liquid; e.g. \"liquid\" or \"solid-liquid\"")
+	}
+
+	// prescribed equations
+	prescribed := make(map[int]bool)
+	for _, c := range d.EssenBcs.Bcs {
+		if c.Key == "pl" {
+			for _, eq := range c.Eqs {
+				prescribed[eq] = true
+			}
+		}
+	}
+
+	// liquid pressure equations
+	o = &WaterBalance{Stage: stgidx, Tol: d.Sim.Data.WaterTol}
+	if o.Tol <= 0 {
+		o.Tol = 1e-2
+	}
+	for _, nod := range d.Nodes {
+		if eq := nod.GetEq("pl"); eq >= 0 {
+			o.Eqs = append(o.Eqs, eq)
+			o.Ess = append(o.Ess, prescribed[eq])
+		}
+	}
+	return
+}
+
+// Start records the mass of liquid at the beginning of stage
+func (o *WaterBalance) Start(d *Domain) (err error) {
+	o.Records = o.Records[:0]
+	o.Nflag = 0
+	o.qin = 0 // consistent with dy/dt = 0 at the beginning of stage
+	o.Mass0, err = o.mass(d)
+	return
+}
+
+// Step computes the water balance after a time step (Δt) has converged. A message is printed if
+// the relative error is larger than Tol and verbose is true
+func (o *WaterBalance) Step(d *Domain, Δt float64, verbose bool) (err error) {
+	r := &WbRecord{T: d.Sol.T}
+	qold := o.qin
+	r.Mass, err = o.mass(d)
+	if err != nil {
+		return
+	}
+	r.Qnat, r.Qess, r.Qsrc, err = o.rates(d)
+	if err != nil {
+		return
+	}
+	θ := d.Sim.Solver.Theta
+	if len(o.Records) > 0 {
+		r.Vin = o.Records[len(o.Records)-1].Vin
+	}
+	r.Vin += Δt * (θ*o.qin + (1.0-θ)*qold)
+	ΔM := r.Mass - o.Mass0
+	r.Err = ΔM - r.Vin
+	den := math.Max(math.Abs(ΔM), math.Abs(r.Vin))
+	if den > 0 {
+		r.RelErr = r.Err / den
+	}
+	o.Records = append(o.Records, r)
+	if math.Abs(r.RelErr) > o.Tol {
+		o.Nflag++
+		if verbose {
+			io.Pfred("\n> water balance error at t = %g: %g (relative = %g) > %g\n", r.T, r.Err, r.RelErr, o.Tol)
+		}
+	}
+	return
+}
+
+// Report returns a table with the records of water balance
+func (o *WaterBalance) Report() string {
+	var b bytes.Buffer
+	io.Ff(&b, "# water balance of stage %d. mass0 = %23.15e. %d steps with |relerr| > %g\n", o.Stage, o.Mass0, o.Nflag, o.Tol)
+	io.Ff(&b, "%23s%23s%23s%23s%23s%23s%23s%23s\n", "t", "mass", "Qnat", "Qess", "Qsrc", "Vin", "err", "relerr")
+	for _, r := range o.Records {
+		io.Ff(&b, "%23.15e%23.15e%23.15e%23.15e%23.15e%23.15e%23.15e%23.15e\n", r.T, r.Mass, r.Qnat, r.Qess, r.Qsrc, r.Vin, r.Err, r.RelErr)
+	}
+	return b.String()
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// mass computes the mass of liquid stored in elements
+func (o *WaterBalance) mass(d *Domain) (mass float64, err error) {
+	for _, e := range d.Elems {
+		if es, ok := e.(ele.WithLiqStorage); ok {
+			m, err := es.LiqMass(d.Sol)
+			if err != nil {
+				return 0, chk.Err("cannot compute mass of liquid in element # %d:\n%v", e.Id(), err)
+			}
+			mass += m
+		}
+	}
+	return
+}
+
+// rates computes the rates of inflow from the residuals of elements: fb(all) and fb(natural
+// boundary conditions) at the liquid pressure equations. The total rate is stored in o.qin
+func (o *WaterBalance) rates(d *Domain) (qnat, qess, qsrc float64, err error) {
+	fb := make([]float64, d.Nyb)
+	fn := make([]float64, d.Nyb)
+	for _, e := range d.Elems {
+		err = e.AddToRhs(fb, d.Sol)
+		if err != nil {
+			return
+		}
+		if en, ok := e.(ele.WithNatBcs); ok {
+			err = en.AddNatBcsToRhs(fn, d.Sol)
+			if err != nil {
+				return
+			}
+		}
+	}
+	for i, eq := range o.Eqs {
+		qnat += fn[eq]
+		if o.Ess[i] {
+			qess -= fb[eq]
+		} else {
+			qsrc -= fb[eq]
+		}
+	}
+	o.qin = qnat + qess + qsrc
+	return
+}
+
+// save_water_balance writes the water balance reports of stage (stgidx) to files in DirOut
+func (o *Main) save_water_balance(stgidx int) {
+	if o.Proc != 0 {
+		return
+	}
+	for i, d := range o.Domains {
+		if d.WBal == nil {
+			continue
+		}
+		fn := io.Sf("%s_waterbal_d%d_s%d.res", o.Sim.Key, i, stgidx)
+		io.WriteFileSD(o.Sim.DirOut, fn, d.WBal.Report())
+		if o.ShowMsg {
+			io.Pf("> Water balance of domain %d: %d steps with relative error > %g; see %s/%s\n", i, d.WBal.Nflag, d.WBal.Tol, o.Sim.DirOut, fn)
+		}
+	}
+}
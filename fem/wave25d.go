@@ -0,0 +1,300 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"bytes"
+	"math"
+	"math/cmplx"
+
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/inp"
+	mdlsolid "github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Wave25D implements 2.5D (semi-analytical) harmonic analyses of elastic solids: the displacements
+// are u(x,y,z,t) = û(x,y;k)・exp(i (Ω t - k z)) and, for each wavenumber k, the 2D cross-section
+// with three displacements per vertex (ux, uy, uz) is solved with
+//  [K(k) - Ω² M]・û = f̂   with   Ω = ω + k・v
+// where K(k) = K0 + i k K1 + k² K2 is assembled with the gradients (∂x, ∂y, -i k) of the complex
+// displacements and v is the speed of loads. The displacements in space are then given by the
+// inverse transform
+//  u(x,y,z') = 1/(2π) ∫ û(x,y;k)・exp(-i k z') dk   with   z' = z - v t
+// computed with the trapezoidal rule over the wavenumbers
+//  Note: (1) only serial runs are supported; the domain must be set with stage #0
+//        (2) the boundaries of the cross-section are either free or fixed (reflecting); thus the
+//            mesh must be large enough and/or damping must be given to avoid spurious reflections
+type Wave25D struct {
+	Dat *inp.Wave25Data // input data
+	Ks  []float64       // [nk] wavenumbers
+	Neq int             // number of equations
+	Eqs map[int][]int   // vertex id => [3] equations of (ux, uy, uz); -1 => fixed
+	Ur  [][]float64     // [nk][neq] real part of û
+	Ui  [][]float64     // [nk][neq] imaginary part of û
+	Fr  []float64       // [neq] real loads (same for all k)
+
+	// auxiliary
+	d     *Domain              // domain
+	elems []*solid.Solid       // solid elements
+	mdls  []*mdlsolid.LinElast // elastic models of elements
+	umaps [][]int              // [nelems] location arrays
+}
+
+// NewWave25D allocates a new structure with the solid elements of domain
+func NewWave25D(d *Domain, dat *inp.Wave25Data) (o *Wave25D, err error) {
+
+	// check
+	if d.Distr {
+		return nil, chk.Err("2.5D analyses cannot be run in parallel")
+	}
+	if d.Msh.Ndim != 2 {
+		return nil, chk.Err("2.5D analyses require 2D meshes. ndim = %d is invalid", d.Msh.Ndim)
+	}
+
+	// wavenumbers
+	o = &Wave25D{Dat: dat, d: d}
+	o.Ks = utl.LinSpace(-dat.Kmax, dat.Kmax, dat.Nk)
+
+	// fixed vertices
+	fixed := make(map[int]bool)
+	for _, tag := range dat.Fixed {
+		verts, ok := d.Msh.VertTag2verts[tag]
+		if !ok {
+			return nil, chk.Err("cannot find vertices with tag = %d to be fixed in 2.5D analysis", tag)
+		}
+		for _, v := range verts {
+			fixed[v.Id] = true
+		}
+	}
+
+	// elements and equations
+	o.Eqs = make(map[int][]int)
+	for _, e := range d.Elems {
+		sld, ok := e.(*solid.Solid)
+		if !ok {
+			continue
+		}
+		mdl, ok := sld.Mdl.(*mdlsolid.LinElast)
+		if !ok {
+			return nil, chk.Err("2.5D analyses require linear elastic models (\"lin-elast\"). cell # %d is invalid", sld.Cell.Id)
+		}
+		umap := make([]int, 3*len(sld.Cell.Verts))
+		for m, vid := range sld.Cell.Verts {
+			eqs, ok := o.Eqs[vid]
+			if !ok {
+				eqs = []int{-1, -1, -1}
+				if !fixed[vid] {
+					for i := 0; i < 3; i++ {
+						eqs[i] = o.Neq
+						o.Neq++
+					}
+				}
+				o.Eqs[vid] = eqs
+			}
+			copy(umap[3*m:], eqs)
+		}
+		o.elems = append(o.elems, sld)
+		o.mdls = append(o.mdls, mdl)
+		o.umaps = append(o.umaps, umap)
+	}
+	if len(o.elems) == 0 {
+		return nil, chk.Err("2.5D analysis requires solid elements")
+	}
+
+	// loads
+	o.Fr = make([]float64, o.Neq)
+	for _, load := range dat.Loads {
+		verts, ok := d.Msh.VertTag2verts[load.Tag]
+		if !ok {
+			return nil, chk.Err("cannot find vertices with tag = %d to apply loads in 2.5D analysis", load.Tag)
+		}
+		for _, v := range verts {
+			eqs, ok := o.Eqs[v.Id]
+			if !ok {
+				continue
+			}
+			for i, f := range []float64{load.Fx, load.Fy, load.Fz} {
+				if eqs[i] >= 0 {
+					o.Fr[eqs[i]] += f
+				}
+			}
+		}
+	}
+	return
+}
+
+// Run solves the system for all wavenumbers
+func (o *Wave25D) Run() (err error) {
+
+	// complex system
+	nnz := 0
+	for _, umap := range o.umaps {
+		nnz += len(umap) * len(umap)
+	}
+	lsdat := o.d.Sim.LinSol
+	lsdat.Symmetric = false // K(k) is not symmetric
+	sys, err := NewCplxSystem(o.Neq, nnz, &lsdat)
+	if err != nil {
+		return
+	}
+	defer sys.Clean()
+
+	// element matrices
+	K := make([][][]complex128, len(o.elems))
+	for e, umap := range o.umaps {
+		K[e] = make([][]complex128, len(umap))
+		for i := range umap {
+			K[e][i] = make([]complex128, len(umap))
+		}
+	}
+
+	// solve for each wavenumber
+	zero := make([]float64, o.Neq)
+	o.Ur = la.MatAlloc(len(o.Ks), o.Neq)
+	o.Ui = la.MatAlloc(len(o.Ks), o.Neq)
+	for j, k := range o.Ks {
+		Ω := o.Dat.Omega + k*o.Dat.Speed
+		sys.Start()
+		for e, sld := range o.elems {
+			mdl := o.mdls[e]
+			err = Wave25Matrix(K[e], sld.Cell.Shp, sld.X, sld.IpsElem, mdl.L, mdl.G, mdl.GetRho(), o.Dat.Damp, k, Ω)
+			if err != nil {
+				return
+			}
+			for r, I := range o.umaps[e] {
+				if I < 0 {
+					continue
+				}
+				for c, J := range o.umaps[e] {
+					if J < 0 {
+						continue
+					}
+					sys.Put(I, J, real(K[e][r][c]), imag(K[e][r][c]))
+				}
+			}
+		}
+		err = sys.Fact()
+		if err != nil {
+			return chk.Err("2.5D analysis failed with k = %g:\n%v", k, err)
+		}
+		err = sys.Solve(o.Ur[j], o.Ui[j], o.Fr, zero)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Displacements computes the (complex) amplitudes of displacements at vertex vid and distance z
+// along the z-axis (moving frame) by means of the inverse transform
+//  Output: u -- [3] displacements (ux, uy, uz); the response is real(u・exp(i ω t))
+func (o *Wave25D) Displacements(vid int, z float64) (u []complex128, err error) {
+	eqs, ok := o.Eqs[vid]
+	if !ok {
+		return nil, chk.Err("cannot find vertex # %d in 2.5D analysis", vid)
+	}
+	if o.Ur == nil {
+		return nil, chk.Err("2.5D analysis must be run before computing displacements")
+	}
+	nk := len(o.Ks)
+	Δk := o.Ks[1] - o.Ks[0]
+	u = make([]complex128, 3)
+	for j, k := range o.Ks {
+		w := 1.0
+		if j == 0 || j == nk-1 {
+			w = 0.5
+		}
+		e := cmplx.Exp(complex(0, -k*z))
+		for i, eq := range eqs {
+			if eq >= 0 {
+				u[i] += complex(w, 0) * complex(o.Ur[j][eq], o.Ui[j][eq]) * e
+			}
+		}
+	}
+	for i := 0; i < 3; i++ {
+		u[i] *= complex(Δk/(2.0*math.Pi), 0)
+	}
+	return
+}
+
+// Save saves the displacements at the output vertices and distances to dirout/fnkey_wave25d.res
+func (o *Wave25D) Save(dirout, fnkey string) (err error) {
+	var b bytes.Buffer
+	io.Ff(&b, "%8s%23s", "id", "z")
+	for _, key := range []string{"ux", "uy", "uz"} {
+		io.Ff(&b, "%23s%23s", "re_"+key, "im_"+key)
+	}
+	io.Ff(&b, "\n")
+	for _, vid := range o.Dat.Verts {
+		for _, z := range o.Dat.Zs {
+			u, err := o.Displacements(vid, z)
+			if err != nil {
+				return err
+			}
+			io.Ff(&b, "%8d%23.15e", vid, z)
+			for _, v := range u {
+				io.Ff(&b, "%23.15e%23.15e", real(v), imag(v))
+			}
+			io.Ff(&b, "\n")
+		}
+	}
+	io.WriteFileVD(dirout, fnkey+"_wave25d.res", &b)
+	return
+}
+
+// Wave25Matrix computes the (complex) dynamic stiffness matrix of a 2D cell for 2.5D analyses
+//  K = (1 + 2 i ξ) K(k) - Ω² M
+// with the dofs ordered as (ux, uy, uz) of each vertex; i.e. K[3m+a][3n+b]
+//  Input:
+//   sh, X, ips -- shape structure, coordinates [2][nverts] and integration points of cell
+//   λ, G, ρ    -- Lamé's coefficients and density
+//   ξ          -- hysteretic damping ratio
+//   k, Ω       -- wavenumber and angular frequency
+func Wave25Matrix(K [][]complex128, sh *shp.Shape, X [][]float64, ips []shp.Ipoint, λ, G, ρ, ξ, k, Ω float64) (err error) {
+	nverts := sh.Nverts
+	for i := 0; i < 3*nverts; i++ {
+		for j := 0; j < 3*nverts; j++ {
+			K[i][j] = 0
+		}
+	}
+	ik := complex(0, k)
+	fac := complex(1, 2*ξ)
+	gm, gn := make([]complex128, 3), make([]complex128, 3)
+	for _, ip := range ips {
+		err = sh.CalcAtIp(X, ip, true)
+		if err != nil {
+			return
+		}
+		coef := sh.J * ip[3]
+		for m := 0; m < nverts; m++ {
+			gm[0], gm[1], gm[2] = complex(sh.G[m][0], 0), complex(sh.G[m][1], 0), ik*complex(sh.S[m], 0) // conjugate of (∂x, ∂y, -ik)N
+			for n := 0; n < nverts; n++ {
+				gn[0], gn[1], gn[2] = complex(sh.G[n][0], 0), complex(sh.G[n][1], 0), -ik*complex(sh.S[n], 0)
+				dot := gm[0]*gn[0] + gm[1]*gn[1] + gm[2]*gn[2]
+				mass := ρ * Ω * Ω * sh.S[m] * sh.S[n]
+				for a := 0; a < 3; a++ {
+					for b := 0; b < 3; b++ {
+						v := complex(λ, 0)*gm[a]*gn[b] + complex(G, 0)*gm[b]*gn[a]
+						if a == b {
+							v += complex(G, 0) * dot
+						}
+						v *= fac
+						if a == b {
+							v -= complex(mass, 0)
+						}
+						K[3*m+a][3*n+b] += complex(coef, 0) * v
+					}
+				}
+			}
+		}
+	}
+	return
+}
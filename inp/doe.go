@@ -0,0 +1,177 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// SOBOL_MAXDIM is the maximum number of dimensions of Sobol sequences
+const SOBOL_MAXDIM = 16
+
+// sobol_dirs holds the degree s, the coefficients a and the initial direction numbers m of the
+// primitive polynomials for dimensions 2 to SOBOL_MAXDIM (Joe and Kuo, 2008)
+var sobol_dirs = []struct {
+	s, a int
+	m    []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+	{3, 2, []uint32{1, 1, 1}},
+	{4, 1, []uint32{1, 1, 3, 3}},
+	{4, 4, []uint32{1, 3, 5, 13}},
+	{5, 2, []uint32{1, 1, 5, 5, 17}},
+	{5, 4, []uint32{1, 1, 5, 5, 5}},
+	{5, 7, []uint32{1, 1, 7, 11, 19}},
+	{5, 11, []uint32{1, 1, 5, 1, 1}},
+	{5, 13, []uint32{1, 1, 1, 3, 11}},
+	{5, 14, []uint32{1, 3, 5, 5, 31}},
+	{6, 1, []uint32{1, 3, 3, 9, 7, 49}},
+	{6, 13, []uint32{1, 1, 1, 15, 21, 21}},
+	{6, 16, []uint32{1, 3, 1, 13, 27, 49}},
+}
+
+// LatinHypercube generates nsamp samples in the ndim-dimensional unit hypercube using the Latin
+// hypercube sampling; i.e. the range [0,1) of each dimension is divided into nsamp intervals of
+// equal size and each interval has exactly one sample
+//  Output:
+//   U -- [nsamp][ndim] samples
+func LatinHypercube(nsamp, ndim int, rng *rand.Rand) (U [][]float64) {
+	U = make([][]float64, nsamp)
+	for i := 0; i < nsamp; i++ {
+		U[i] = make([]float64, ndim)
+	}
+	for j := 0; j < ndim; j++ {
+		perm := rng.Perm(nsamp)
+		for i := 0; i < nsamp; i++ {
+			U[i][j] = (float64(perm[i]) + rng.Float64()) / float64(nsamp)
+		}
+	}
+	return
+}
+
+// Sobol generates the first nsamp points of the ndim-dimensional Sobol sequence (quasi-random
+// low-discrepancy sequence) in the unit hypercube
+//  Output:
+//   U -- [nsamp][ndim] samples
+//  Note: (1) the first point of the sequence (the origin) is skipped
+//        (2) the direction numbers are computed with 32 bits; thus nsamp < 2³²
+func Sobol(nsamp, ndim int) (U [][]float64, err error) {
+
+	// check
+	if ndim < 1 || ndim > SOBOL_MAXDIM {
+		return nil, chk.Err("number of dimensions of Sobol sequence must be in [1, %d]. %d is invalid", SOBOL_MAXDIM, ndim)
+	}
+
+	// direction numbers
+	const nbits = 32
+	V := make([][]uint32, ndim)
+	for j := 0; j < ndim; j++ {
+		V[j] = make([]uint32, nbits+1)
+		if j == 0 {
+			for k := 1; k <= nbits; k++ {
+				V[j][k] = 1 << uint(nbits-k)
+			}
+			continue
+		}
+		d := sobol_dirs[j-1]
+		for k := 1; k <= nbits; k++ {
+			if k <= d.s {
+				V[j][k] = d.m[k-1] << uint(nbits-k)
+				continue
+			}
+			V[j][k] = V[j][k-d.s] ^ (V[j][k-d.s] >> uint(d.s))
+			for i := 1; i < d.s; i++ {
+				if (d.a>>uint(d.s-1-i))&1 == 1 {
+					V[j][k] ^= V[j][k-i]
+				}
+			}
+		}
+	}
+
+	// points (Gray code implementation)
+	scale := 1.0 / float64(uint64(1)<<nbits)
+	X := make([]uint32, ndim)
+	U = make([][]float64, nsamp)
+	for i := 0; i < nsamp; i++ {
+		c := 1 // index of rightmost zero bit of i
+		for t := i; t&1 == 1; t >>= 1 {
+			c++
+		}
+		U[i] = make([]float64, ndim)
+		for j := 0; j < ndim; j++ {
+			X[j] ^= V[j][c]
+			U[i][j] = float64(X[j]) * scale
+		}
+	}
+	return
+}
+
+// SweepSamples generates the values of the adjustable parameters of the samples of a parameter sweep
+//  Output:
+//   prms -- [nprm] swept parameters
+//   X    -- [nsamp][nprm] values of parameters
+func (o *Simulation) SweepSamples() (prms fun.Prms, X [][]float64, err error) {
+
+	// check
+	dat := o.Sweep
+	if dat == nil {
+		return nil, nil, chk.Err("parameter sweep data (sweep) is not available")
+	}
+
+	// parameters
+	if len(dat.Adjs) == 0 {
+		prms = o.Adjustable
+	}
+	for _, adj := range dat.Adjs {
+		prm, ok := o.adjmap[adj]
+		if !ok {
+			return nil, nil, chk.Err("cannot find adjustable parameter %d of parameter sweep", adj)
+		}
+		prms = append(prms, prm)
+	}
+	if len(prms) == 0 {
+		return nil, nil, chk.Err("there are no adjustable parameters for parameter sweep")
+	}
+	for _, prm := range prms {
+		if prm.Max <= prm.Min {
+			return nil, nil, chk.Err("range [Min,Max] = [%g,%g] of parameter %q is invalid for parameter sweep", prm.Min, prm.Max, prm.N)
+		}
+	}
+
+	// samples in unit hypercube
+	nprm := len(prms)
+	var U [][]float64
+	switch dat.Method {
+	case "lhs":
+		seed := int64(dat.Seed)
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		U = LatinHypercube(dat.Nsamp, nprm, rand.New(rand.NewSource(seed)))
+	case "sobol":
+		U, err = Sobol(dat.Nsamp, nprm)
+		if err != nil {
+			return
+		}
+	default:
+		return nil, nil, chk.Err("sampling method %q is not available; options are \"lhs\" and \"sobol\"", dat.Method)
+	}
+
+	// values of parameters
+	X = make([][]float64, dat.Nsamp)
+	for i, u := range U {
+		X[i] = make([]float64, nprm)
+		for j, prm := range prms {
+			X[i][j] = prm.Min + (prm.Max-prm.Min)*u[j]
+		}
+	}
+	return
+}
@@ -0,0 +1,227 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// DtInfo holds the critical time step information of the cells with the same tag
+//  Note: (1) the element size is the minimum distance between vertices of cells; i.e. the nodal
+//            spacing, which is half the cell size for quadratic elements
+//        (2) the consolidation coefficient is cv = kl・(K + 4G/3) / γw with γw = ρL・g; the
+//            compressibility of the liquid is not considered
+//        (3) the diffusion coefficient of "diffusion" elements is a0・k / rho
+type DtInfo struct {
+	Tag    int     // tag of cells
+	Type   string  // type of element; e.g. "u", "up", "diffusion"
+	Mat    string  // name of material
+	Ncells int     // number of cells
+	Hmin   float64 // minimum element size
+	Vp     float64 // speed of P-waves; 0 => not available
+	Vs     float64 // speed of S-waves; 0 => not available
+	Cv     float64 // consolidation or diffusion coefficient; 0 => not available
+	DtWave float64 // Hmin / Vp: stable Δt of explicit dynamics (CFL) and largest accurate Δt of implicit dynamics
+	DtExp  float64 // Hmin² / (2・ndim・cv): stable Δt of explicit consolidation or diffusion
+	DtOsc  float64 // Hmin² / (6・cv): smallest Δt avoiding spurious oscillations in implicit consolidation or diffusion
+}
+
+// DtStage holds the recommended range of time steps of a stage
+type DtStage struct {
+	Stage    int      // index of stage
+	Dt       float64  // time step of stage (at t=0 if given by function)
+	DtMin    float64  // smallest recommended Δt; 0 => no lower limit
+	DtMax    float64  // largest recommended Δt; 0 => no upper limit
+	Warnings []string // warnings if Dt is outside the recommended range
+}
+
+// DtReport holds the report of critical time steps for all regions and stages
+type DtReport struct {
+	Infos  []*DtInfo  // [ntags] information of cells with the same tag
+	Stages []*DtStage // [nstages] recommended ranges for each stage
+}
+
+// CalcDtReport scans the meshes and materials and computes the minimum element sizes, wave
+// speeds, consolidation coefficients and the corresponding stable/accurate Δt of each stage
+//  Note: (1) dynamics is considered for "u" elements of transient simulations
+//        (2) consolidation is considered for "up" elements and diffusion for "diffusion" elements
+func (o *Simulation) CalcDtReport() (rpt *DtReport) {
+
+	// information of each tag
+	rpt = new(DtReport)
+	for _, reg := range o.Regions {
+		for _, edat := range reg.ElemsData {
+			info := &DtInfo{Tag: edat.Tag, Type: edat.Type, Mat: edat.Mat}
+			for _, cell := range reg.Msh.Cells {
+				if cell.Tag != edat.Tag {
+					continue
+				}
+				h := dtr_cell_size(reg.Msh, cell)
+				if info.Ncells == 0 || h < info.Hmin {
+					info.Hmin = h
+				}
+				info.Ncells++
+			}
+			if info.Ncells == 0 || info.Hmin <= 0 {
+				continue
+			}
+			o.dtr_coefficients(info)
+			rpt.Infos = append(rpt.Infos, info)
+		}
+	}
+
+	// stages
+	for i, stg := range o.Stages {
+		if stg.Skip {
+			continue
+		}
+		res := &DtStage{Stage: i, Dt: stg.Control.Dt}
+		if !o.Data.Steady {
+			for _, info := range rpt.Infos {
+				if info.DtOsc > 0 {
+					res.DtMin = math.Max(res.DtMin, info.DtOsc)
+					if res.Dt < info.DtOsc {
+						res.Warnings = append(res.Warnings, io.Sf("tag %d: Δt = %g < h²/(6・cv) = %g => spurious oscillations of pressures may occur", info.Tag, res.Dt, info.DtOsc))
+					}
+				}
+				if info.DtWave > 0 {
+					if res.DtMax == 0 || info.DtWave < res.DtMax {
+						res.DtMax = info.DtWave
+					}
+					if res.Dt > info.DtWave {
+						res.Warnings = append(res.Warnings, io.Sf("tag %d: Δt = %g > h/Vp = %g => waves are not resolved accurately", info.Tag, res.Dt, info.DtWave))
+					}
+				}
+			}
+			if res.DtMin > 0 && res.DtMax > 0 && res.DtMin > res.DtMax {
+				res.Warnings = append(res.Warnings, io.Sf("recommended range of Δt is empty: [%g, %g] => refine mesh", res.DtMin, res.DtMax))
+			}
+		}
+		rpt.Stages = append(rpt.Stages, res)
+	}
+	return
+}
+
+// String returns the report formatted as tables
+func (o *DtReport) String() string {
+	b := new(bytes.Buffer)
+	io.Ff(b, "%6s %10s %14s %6s %12s %12s %12s %12s %12s %12s %12s\n", "tag", "type", "mat", "ncells", "hmin", "Vp", "Vs", "cv", "h/Vp", "h²/(2d・cv)", "h²/(6・cv)")
+	for _, info := range o.Infos {
+		io.Ff(b, "%6d %10s %14s %6d %12.4e %12.4e %12.4e %12.4e %12.4e %12.4e %12.4e\n", info.Tag, info.Type, info.Mat, info.Ncells, info.Hmin, info.Vp, info.Vs, info.Cv, info.DtWave, info.DtExp, info.DtOsc)
+	}
+	io.Ff(b, "\n%6s %12s %12s %12s\n", "stage", "Δt", "Δt(min)", "Δt(max)")
+	for _, stg := range o.Stages {
+		io.Ff(b, "%6d %12.4e %12.4e %12.4e\n", stg.Stage, stg.Dt, stg.DtMin, stg.DtMax)
+		for _, w := range stg.Warnings {
+			io.Ff(b, "       WARNING: %s\n", w)
+		}
+	}
+	return b.String()
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// dtr_coefficients computes wave speeds, consolidation coefficients and critical time steps
+func (o *Simulation) dtr_coefficients(info *DtInfo) {
+
+	// material
+	if o.MatModels == nil {
+		return
+	}
+	mat := o.MatModels.Get(info.Mat)
+	if mat == nil {
+		return
+	}
+	ndim := float64(o.Ndim)
+	h := info.Hmin
+
+	// coefficients
+	switch info.Type {
+	case "u":
+		K, G, ok := dtr_elastic(mat.SldPrms)
+		rho := dtr_prm(mat.SldPrms, "rho")
+		if ok && rho > 0 {
+			info.Vp = math.Sqrt((K + 4.0*G/3.0) / rho)
+			info.Vs = math.Sqrt(G / rho)
+		}
+	case "up":
+		K, G, ok := dtr_elastic(mat.SldPrms)
+		kl := dtr_prm(mat.Prms, "kl")
+		if ok && kl > 0 && mat.Liq != nil && o.Grav0 > 0 {
+			info.Cv = kl * (K + 4.0*G/3.0) / (mat.Liq.R0 * o.Grav0)
+		}
+	case "diffusion":
+		rho := dtr_prm(mat.Prms, "rho")
+		k := dtr_prm(mat.Prms, "k")
+		if k == 0 {
+			k = dtr_prm(mat.Prms, "kx")
+		}
+		if rho > 0 {
+			info.Cv = dtr_prm(mat.Prms, "a0") * k / rho
+		}
+	}
+
+	// time steps
+	if o.Data.Steady {
+		return
+	}
+	if info.Vp > 0 && info.Type == "u" {
+		info.DtWave = h / info.Vp
+	}
+	if info.Cv > 0 {
+		info.DtExp = h * h / (2.0 * ndim * info.Cv)
+		info.DtOsc = h * h / (6.0 * info.Cv)
+	}
+}
+
+// dtr_cell_size returns the minimum distance between the vertices of a cell
+func dtr_cell_size(msh *Mesh, cell *Cell) (h float64) {
+	h = -1
+	for i := 0; i < len(cell.Verts); i++ {
+		a := msh.Verts[cell.Verts[i]].C
+		for j := i + 1; j < len(cell.Verts); j++ {
+			b := msh.Verts[cell.Verts[j]].C
+			var d float64
+			for k := 0; k < msh.Ndim; k++ {
+				d += (a[k] - b[k]) * (a[k] - b[k])
+			}
+			d = math.Sqrt(d)
+			if d > 0 && (h < 0 || d < h) {
+				h = d
+			}
+		}
+	}
+	return
+}
+
+// dtr_elastic returns the bulk and shear moduli from the parameters of a solid model
+func dtr_elastic(prms fun.Prms) (K, G float64, ok bool) {
+	E, ν := prms.Find("E"), prms.Find("nu")
+	l, g, k := prms.Find("l"), prms.Find("G"), prms.Find("K")
+	switch {
+	case E != nil && ν != nil:
+		return solid.Calc_K_from_Enu(E.V, ν.V), solid.Calc_G_from_Enu(E.V, ν.V), true
+	case l != nil && g != nil:
+		return solid.Calc_K_from_lG(l.V, g.V), g.V, true
+	case k != nil && g != nil:
+		return k.V, g.V, true
+	case k != nil && ν != nil:
+		return k.V, solid.Calc_G_from_Knu(k.V, ν.V), true
+	}
+	return
+}
+
+// dtr_prm returns the value of parameter (name) or zero if not found
+func dtr_prm(prms fun.Prms, name string) float64 {
+	if p := prms.Find(name); p != nil {
+		return p.V
+	}
+	return 0
+}
@@ -0,0 +1,192 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// Expr holds a compiled arithmetic/logical expression; e.g. "gamw*(zw - z)" or "x > 1 && y < 2"
+//  Note: (1) the syntax is Go's: + - * / ( ) < <= > >= == != && || ! and calls to the functions
+//            abs, sqrt, exp, log, sin, cos, tan, asin, acos, atan, atan2, pow, min, max, floor, ceil
+//        (2) logical results are 1 (true) or 0 (false); non-zero values are true
+//        (3) identifiers are variables (given to Eval in the order of compilation), constants or "pi"
+//        (4) the expression is parsed and compiled once into a tree of closures
+type Expr struct {
+	Src  string                       // source
+	Vars []string                     // variables
+	eval func(vals []float64) float64 // compiled expression
+}
+
+// expr_funcs1 and expr_funcs2 hold the functions of one and two arguments
+var (
+	expr_funcs1 = map[string]func(float64) float64{
+		"abs": math.Abs, "sqrt": math.Sqrt, "exp": math.Exp, "log": math.Log,
+		"sin": math.Sin, "cos": math.Cos, "tan": math.Tan, "asin": math.Asin, "acos": math.Acos,
+		"atan": math.Atan, "floor": math.Floor, "ceil": math.Ceil,
+	}
+	expr_funcs2 = map[string]func(float64, float64) float64{
+		"atan2": math.Atan2, "pow": math.Pow, "min": math.Min, "max": math.Max,
+	}
+)
+
+// NewExpr parses and compiles an expression
+//  src    -- source; e.g. "gamw*(zw - z)"
+//  vars   -- names of variables; e.g. ["x", "y", "z", "t"]
+//  consts -- named constants; e.g. {"gamw" : 10, "zw" : 3}. may be nil
+func NewExpr(src string, vars []string, consts map[string]float64) (o *Expr, err error) {
+	tree, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, chk.Err("cannot parse expression %q:\n%v", src, err)
+	}
+	o = &Expr{Src: src, Vars: vars}
+	o.eval, err = expr_compile(tree, vars, consts)
+	if err != nil {
+		return nil, chk.Err("cannot compile expression %q:\n%v", src, err)
+	}
+	return
+}
+
+// Eval evaluates expression with the values of variables (in the order of Vars)
+func (o *Expr) Eval(vals ...float64) float64 {
+	return o.eval(vals)
+}
+
+// True evaluates expression and returns whether the result is non-zero
+func (o *Expr) True(vals ...float64) bool {
+	return o.eval(vals) != 0
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// expr_bool converts a logical value to float64
+func expr_bool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// expr_compile compiles a node of the syntax tree
+func expr_compile(node ast.Expr, vars []string, consts map[string]float64) (f func([]float64) float64, err error) {
+	switch n := node.(type) {
+
+	case *ast.ParenExpr:
+		return expr_compile(n.X, vars, consts)
+
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT {
+			return nil, chk.Err("invalid literal %q", n.Value)
+		}
+		v, e := strconv.ParseFloat(n.Value, 64)
+		if e != nil {
+			return nil, chk.Err("invalid number %q", n.Value)
+		}
+		return func([]float64) float64 { return v }, nil
+
+	case *ast.Ident:
+		for i, name := range vars {
+			if name == n.Name {
+				return func(vals []float64) float64 { return vals[i] }, nil
+			}
+		}
+		if v, ok := consts[n.Name]; ok {
+			return func([]float64) float64 { return v }, nil
+		}
+		if n.Name == "pi" {
+			return func([]float64) float64 { return math.Pi }, nil
+		}
+		return nil, chk.Err("unknown variable or constant %q", n.Name)
+
+	case *ast.UnaryExpr:
+		a, e := expr_compile(n.X, vars, consts)
+		if e != nil {
+			return nil, e
+		}
+		switch n.Op {
+		case token.SUB:
+			return func(vals []float64) float64 { return -a(vals) }, nil
+		case token.ADD:
+			return a, nil
+		case token.NOT:
+			return func(vals []float64) float64 { return expr_bool(a(vals) == 0) }, nil
+		}
+		return nil, chk.Err("invalid unary operator %q", n.Op)
+
+	case *ast.BinaryExpr:
+		a, e := expr_compile(n.X, vars, consts)
+		if e != nil {
+			return nil, e
+		}
+		b, e := expr_compile(n.Y, vars, consts)
+		if e != nil {
+			return nil, e
+		}
+		switch n.Op {
+		case token.ADD:
+			return func(v []float64) float64 { return a(v) + b(v) }, nil
+		case token.SUB:
+			return func(v []float64) float64 { return a(v) - b(v) }, nil
+		case token.MUL:
+			return func(v []float64) float64 { return a(v) * b(v) }, nil
+		case token.QUO:
+			return func(v []float64) float64 { return a(v) / b(v) }, nil
+		case token.LSS:
+			return func(v []float64) float64 { return expr_bool(a(v) < b(v)) }, nil
+		case token.LEQ:
+			return func(v []float64) float64 { return expr_bool(a(v) <= b(v)) }, nil
+		case token.GTR:
+			return func(v []float64) float64 { return expr_bool(a(v) > b(v)) }, nil
+		case token.GEQ:
+			return func(v []float64) float64 { return expr_bool(a(v) >= b(v)) }, nil
+		case token.EQL:
+			return func(v []float64) float64 { return expr_bool(a(v) == b(v)) }, nil
+		case token.NEQ:
+			return func(v []float64) float64 { return expr_bool(a(v) != b(v)) }, nil
+		case token.LAND:
+			return func(v []float64) float64 { return expr_bool(a(v) != 0 && b(v) != 0) }, nil
+		case token.LOR:
+			return func(v []float64) float64 { return expr_bool(a(v) != 0 || b(v) != 0) }, nil
+		case token.XOR:
+			return nil, chk.Err("operator ^ is not available; use pow(a, b)")
+		}
+		return nil, chk.Err("invalid binary operator %q", n.Op)
+
+	case *ast.CallExpr:
+		id, ok := n.Fun.(*ast.Ident)
+		if !ok {
+			return nil, chk.Err("invalid function call")
+		}
+		args := make([]func([]float64) float64, len(n.Args))
+		for i, arg := range n.Args {
+			args[i], err = expr_compile(arg, vars, consts)
+			if err != nil {
+				return
+			}
+		}
+		if g, ok := expr_funcs1[id.Name]; ok {
+			if len(args) != 1 {
+				return nil, chk.Err("function %q requires 1 argument", id.Name)
+			}
+			a := args[0]
+			return func(v []float64) float64 { return g(a(v)) }, nil
+		}
+		if g, ok := expr_funcs2[id.Name]; ok {
+			if len(args) != 2 {
+				return nil, chk.Err("function %q requires 2 arguments", id.Name)
+			}
+			a, b := args[0], args[1]
+			return func(v []float64) float64 { return g(a(v), b(v)) }, nil
+		}
+		return nil, chk.Err("unknown function %q", id.Name)
+	}
+	return nil, chk.Err("invalid expression")
+}
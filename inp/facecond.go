@@ -73,11 +73,25 @@ func (o FaceConds) GetVerts(conds ...string) (verts []int) {
 // SetFaceConds sets face boundary conditions map in cell
 func (o *Cell) SetFaceConds(stg *Stage, functions FuncsData) (err error) {
 
+	// tags of faces and of sets of faces
+	var faceIds, faceTags []int
+	for faceId, faceTag := range o.FTags {
+		faceIds = append(faceIds, faceId)
+		faceTags = append(faceTags, faceTag)
+	}
+	for faceId := 0; o.Shp != nil && faceId < len(o.Shp.FaceLocalVerts); faceId++ {
+		for _, faceTag := range o.FaceSets[faceId] {
+			faceIds = append(faceIds, faceId)
+			faceTags = append(faceTags, faceTag)
+		}
+	}
+
 	// for each face tag
 	o.FaceBcs = make([]*FaceCond, 0)
-	for faceId, faceTag := range o.FTags {
+	for k, faceId := range faceIds {
 
 		// skip zero or positive tags
+		faceTag := faceTags[k]
 		if faceTag >= 0 {
 			continue
 		}
@@ -5,6 +5,8 @@
 package inp
 
 import (
+	"math"
+
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
@@ -26,6 +28,7 @@ type FuncData struct {
 	Name     string   `json:"name"`     // name of function. ex: zero, load, myfunction1, etc.
 	Type     string   `json:"type"`     // type of function. ex: cte, rmp
 	Prms     fun.Prms `json:"prms"`     // parameters
+	Expr     string   `json:"expr"`     // expression of x, y, z and t if Type == "expr"; e.g. "gamw*(zw - z)". see ExprFunc
 	PltExtra string   `json:"pltextra"` // extra arguments for plotting
 
 	// extra data for plotting
@@ -43,6 +46,13 @@ func (o FuncsData) Get(name string) (fcn fun.Func, err error) {
 	}
 	for _, f := range o {
 		if f.Name == name {
+			if f.Type == "expr" {
+				fcn, err = NewExprFunc(f.Expr, f.Prms)
+				if err != nil {
+					err = chk.Err("cannot get function named %q because of the following error:\n%v", name, err)
+				}
+				return
+			}
 			fcn, err = fun.New(f.Type, f.Prms)
 			if err != nil {
 				err = chk.Err("cannot get function named %q because of the following error:\n%v", name, err)
@@ -54,6 +64,99 @@ func (o FuncsData) Get(name string) (fcn fun.Func, err error) {
 	return
 }
 
+// ExprFunc implements fun.Func with an expression of the coordinates x, y, z and time t; e.g. the
+// hydrostatic pressure "gamw*(zw - z)" with the constants gamw and zw given as parameters
+//  Note: (1) the expression is parsed and compiled once; i.e. when the function is obtained with
+//            FuncsData.Get for each face or node condition
+//        (2) in 2D, z == 0; i.e. y is the vertical coordinate
+//        (3) functions for essential boundary conditions are evaluated at the coordinates of each
+//            node (see At); natural boundary conditions are evaluated at face integration points
+//        (4) G and H (time derivatives) and Grad (spatial gradient) are computed numerically
+type ExprFunc struct {
+	Expr *Expr     // compiled expression of x, y, z and t
+	X    []float64 // fixed coordinates; e.g. of node. nil => use the coordinates given to F
+}
+
+// NewExprFunc returns a new function defined by an expression
+//  src  -- expression of x, y, z and t
+//  prms -- named constants
+func NewExprFunc(src string, prms fun.Prms) (o *ExprFunc, err error) {
+	if src == "" {
+		return nil, chk.Err("expression of function with type \"expr\" must be given in \"expr\"")
+	}
+	consts := make(map[string]float64)
+	for _, p := range prms {
+		consts[p.N] = p.V
+	}
+	o = new(ExprFunc)
+	o.Expr, err = NewExpr(src, []string{"x", "y", "z", "t"}, consts)
+	return
+}
+
+// FuncDependsOnX tells whether function fcn depends on coordinates given to F
+func FuncDependsOnX(fcn fun.Func) bool {
+	if f, ok := fcn.(*ExprFunc); ok {
+		return f.X == nil
+	}
+	return false
+}
+
+// At returns a copy of this function with fixed coordinates x
+func (o *ExprFunc) At(x []float64) *ExprFunc {
+	return &ExprFunc{o.Expr, x}
+}
+
+// Init does nothing: parameters are given to NewExprFunc
+func (o *ExprFunc) Init(prms fun.Prms) (err error) {
+	return
+}
+
+// F returns y = F(t, x)
+func (o *ExprFunc) F(t float64, x []float64) float64 {
+	if o.X != nil {
+		x = o.X
+	}
+	return o.eval(t, x)
+}
+
+// G returns ∂y/∂t_cteX = G(t, x)
+func (o *ExprFunc) G(t float64, x []float64) float64 {
+	h := 1e-6 * (1 + math.Abs(t))
+	return (o.F(t+h, x) - o.F(t-h, x)) / (2 * h)
+}
+
+// H returns ∂²y/∂t²_cteX = H(t, x)
+func (o *ExprFunc) H(t float64, x []float64) float64 {
+	h := 1e-4 * (1 + math.Abs(t))
+	return (o.F(t+h, x) - 2*o.F(t, x) + o.F(t-h, x)) / (h * h)
+}
+
+// Grad returns ∇F = ∂y/∂x = Grad(t, x)
+func (o *ExprFunc) Grad(v []float64, t float64, x []float64) {
+	if o.X != nil {
+		x = o.X
+	}
+	xx := make([]float64, len(x))
+	for i := range v {
+		copy(xx, x)
+		h := 1e-6 * (1 + math.Abs(x[i]))
+		xx[i] = x[i] + h
+		f1 := o.eval(t, xx)
+		xx[i] = x[i] - h
+		v[i] = (f1 - o.eval(t, xx)) / (2 * h)
+	}
+}
+
+// eval evaluates expression at t and x
+func (o *ExprFunc) eval(t float64, x []float64) float64 {
+	var v [4]float64
+	for i := 0; i < len(x) && i < 3; i++ {
+		v[i] = x[i]
+	}
+	v[3] = t
+	return o.Expr.Eval(v[:]...)
+}
+
 // PlotAll plot all functions
 func (o FuncsData) PlotAll(pd *PlotFdata, dirout, fnkey string) {
 	for _, f := range o {
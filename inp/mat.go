@@ -31,16 +31,22 @@ type Material struct {
 	Deps  []string `json:"deps"`  // dependencies; other material names. e.g. ["water", "dryair", "solid1", "conduct1", "lreten1"]
 	Prms  fun.Prms `json:"prms"`  // prms holds all model parameters for this material
 
+	// input: temperature dependent parameters (solid models)
+	Tdep map[string]*solid.TdepData `json:"tdep"` // parameter name => table of values; e.g. {"E":{"T-table":[[20,210e3],[600,100e3]]}}
+
 	// derived
-	Gen generic.Model    // pointer to generic model
-	Sld solid.Model      // pointer to solid model
-	Liq *fluid.Model     // pointer to liquid model
-	Gas *fluid.Model     // pointer to gas model
-	Cnd conduct.Model    // pointer to conductivity model
-	Lrm retention.Model  // pointer to retention model
-	Dif diffusion.Model  // pointer to diffusion model
-	Trm thermomech.Model // pointer to thermo-mechanical model
-	Por *porous.Model    // pointer to porous model
+	Gen     generic.Model              // pointer to generic model
+	Sld     solid.Model                // pointer to solid model
+	SldPrms fun.Prms                   // parameters of solid model; i.e. Prms of "sld" material or of its "sld" dependency
+	SldName string                     // name of solid model; i.e. Model of "sld" material or of its "sld" dependency
+	SldTdep map[string]*solid.TdepData // temperature dependent parameters of solid model
+	Liq     *fluid.Model               // pointer to liquid model
+	Gas     *fluid.Model               // pointer to gas model
+	Cnd     conduct.Model              // pointer to conductivity model
+	Lrm     retention.Model            // pointer to retention model
+	Dif     diffusion.Model            // pointer to diffusion model
+	Trm     thermomech.Model           // pointer to thermo-mechanical model
+	Por     *porous.Model              // pointer to porous model
 }
 
 // Mats holds materials
@@ -63,6 +69,9 @@ type MatDb struct {
 	DIF map[string]*Material // subset with materials/models: diffusion
 	TRM map[string]*Material // subset with materials/models: thermomech
 	POR map[string]*Material // subset with materials/models: porous materials
+
+	// derived: random fields
+	CellMats map[int]map[string]*Material // copies of materials with random parameters; cell id => name => material
 }
 
 // Clean cleans resources
@@ -72,6 +81,13 @@ func (o *MatDb) Clean() {
 			mat.Sld.Clean()
 		}
 	}
+	for _, mats := range o.CellMats {
+		for _, mat := range mats {
+			if mat.Type == "sld" && mat.Sld != nil {
+				mat.Sld.Clean()
+			}
+		}
+	}
 }
 
 // ReadMat reads all materials data from a .mat JSON file
@@ -171,6 +187,14 @@ func ReadMat(dir, fn string, ndim int, pstress bool, H, grav float64) (mdb *MatD
 			err = chk.Err("cannot initialise solid model %q / material %q\n%v", m.Model, m.Name, err)
 			return
 		}
+		m.SldPrms = m.Prms
+		m.SldName = m.Model
+		m.SldTdep = m.Tdep
+		err = solid.CheckTdep(m.Prms, m.Tdep)
+		if err != nil {
+			err = chk.Err("invalid temperature dependent parameters of material %q\n%v", m.Name, err)
+			return
+		}
 	}
 
 	// alloc/init: liquids
@@ -232,6 +256,9 @@ func ReadMat(dir, fn string, ndim int, pstress bool, H, grav float64) (mdb *MatD
 		for _, name := range m.Deps {
 			if mm, ok := mdb.SLD[name]; ok {
 				m.Sld = mm.Sld
+				m.SldPrms = mm.Prms
+				m.SldName = mm.Model
+				m.SldTdep = mm.Tdep
 			}
 		}
 		m.Trm, err = thermomech.New(m.Model)
@@ -251,6 +278,9 @@ func ReadMat(dir, fn string, ndim int, pstress bool, H, grav float64) (mdb *MatD
 		for _, name := range m.Deps {
 			if mm, ok := mdb.SLD[name]; ok {
 				m.Sld = mm.Sld
+				m.SldPrms = mm.Prms
+				m.SldName = mm.Model
+				m.SldTdep = mm.Tdep
 			}
 			if mm, ok := mdb.CND[name]; ok {
 				m.Cnd = mm.Cnd
@@ -275,6 +305,37 @@ func ReadMat(dir, fn string, ndim int, pstress bool, H, grav float64) (mdb *MatD
 	return
 }
 
+// ReinitModels re-initialises the solid, conductivity, retention and porous models with the
+// current values of parameters; e.g. after adjustable parameters have been modified
+//  Note: the models are initialised in place; thus, elements pointing to them are updated
+func (o *MatDb) ReinitModels(ndim int, pstress bool, grav float64) (err error) {
+	for _, m := range o.SLD {
+		err = m.Sld.Init(ndim, pstress, m.Prms)
+		if err != nil {
+			return chk.Err("cannot re-initialise solid model %q / material %q\n%v", m.Model, m.Name, err)
+		}
+	}
+	for _, m := range o.CND {
+		err = m.Cnd.Init(m.Prms)
+		if err != nil {
+			return chk.Err("cannot re-initialise conductivity model %q / material %q\n%v", m.Model, m.Name, err)
+		}
+	}
+	for _, m := range o.LRM {
+		err = m.Lrm.Init(m.Prms)
+		if err != nil {
+			return chk.Err("cannot re-initialise liquid retention model %q / material %q\n%v", m.Model, m.Name, err)
+		}
+	}
+	for _, m := range o.POR {
+		err = m.Por.Init(m.Prms, m.Cnd, m.Lrm, m.Liq, m.Gas, grav)
+		if err != nil {
+			return chk.Err("cannot re-initialise porous model (material %q):\n%v\n", m.Name, err)
+		}
+	}
+	return
+}
+
 // Get returns a material
 //  Note: returns nil if not found
 func (o MatDb) Get(name string) *Material {
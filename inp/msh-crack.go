@@ -0,0 +1,179 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// CrackedEdge holds the data of an edge between two solids that has been split by a crack
+type CrackedEdge struct {
+	A, B   int // ids of solids on both sides; A is at the bottom of the interface and B at the top
+	Ea, Eb int // local ids of the edge in A and B
+	Icell  int // id of interface cell
+}
+
+// SharedEdge finds the edge shared by cells a and b (2D); i.e. the edges with the same vertices
+//  Note: returns ea = eb = -1 if not found or if the edges do not have two vertices
+func (o *Mesh) SharedEdge(a, b *Cell) (ea, eb int) {
+	if a.Shp == nil || b.Shp == nil {
+		return -1, -1
+	}
+	for i, li := range a.Shp.FaceLocalVerts {
+		if len(li) != 2 {
+			return -1, -1
+		}
+		for j, lj := range b.Shp.FaceLocalVerts {
+			if len(lj) != 2 {
+				return -1, -1
+			}
+			p, q := a.Verts[li[0]], a.Verts[li[1]]
+			r, s := b.Verts[lj[0]], b.Verts[lj[1]]
+			if (p == r && q == s) || (p == s && q == r) {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// SplitEdge splits this (2D) mesh along the edge shared by the solids with ids a and b and inserts
+// a "qua4" interface cell with tag itag. The vertices of the edge are duplicated if the cracks
+// separate the solids around them. cracked holds the edges split previously and is updated.
+// Returns newverts: id of new vertex => id of vertex it was duplicated from
+//  Note: (1) only solids with edges having two vertices (e.g. "tri3" and "qua4") are supported
+//        (2) vertices at crack tips are not duplicated because the solids around them are still
+//            connected by edges that are not cracked
+//        (3) new vertices have the same tag as the vertices they were duplicated from; thus, they
+//            receive the same boundary conditions
+//        (4) the vertices of all interface cells are updated and the derived data (maps) are
+//            re-computed by calling CalcDerived
+func (o *Mesh) SplitEdge(a, b, itag int, cracked *[]*CrackedEdge) (newverts map[int]int, err error) {
+
+	// check
+	if o.Ndim != 2 {
+		return nil, chk.Err("edges can only be split in 2D meshes\n")
+	}
+	ca, cb := o.Cells[a], o.Cells[b]
+	ea, eb := o.SharedEdge(ca, cb)
+	if ea < 0 {
+		return nil, chk.Err("cells %d and %d do not share an edge with two vertices\n", a, b)
+	}
+
+	// new cracked edge with interface cell
+	ic := &Cell{Id: len(o.Cells), Tag: itag, Type: "qua4", Part: ca.Part, Verts: make([]int, 4)}
+	o.Cells = append(o.Cells, ic)
+	*cracked = append(*cracked, &CrackedEdge{a, b, ea, eb, ic.Id})
+
+	// cracked pairs of solids and interface cells
+	pairs := make(map[[2]int]bool)
+	icells := make(map[int]bool)
+	for _, e := range *cracked {
+		pairs[[2]int{e.A, e.B}], pairs[[2]int{e.B, e.A}] = true, true
+		icells[e.Icell] = true
+	}
+
+	// duplicate vertices
+	newverts = make(map[int]int)
+	for _, l := range ca.Shp.FaceLocalVerts[ea] {
+		v := ca.Verts[l]
+
+		// solids around vertex
+		var around []*Cell
+		for _, cid := range o.Verts[v].SharedBy {
+			c := o.Cells[cid]
+			if c.IsSolid && !icells[cid] {
+				around = append(around, c)
+			}
+		}
+
+		// groups of solids connected by edges (with v) that are not cracked
+		group := make(map[int]int)
+		ngroups := 0
+		for _, c := range around {
+			if _, ok := group[c.Id]; ok {
+				continue
+			}
+			group[c.Id] = ngroups
+			stack := []*Cell{c}
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, q := range around {
+					if _, ok := group[q.Id]; ok || pairs[[2]int{p.Id, q.Id}] {
+						continue
+					}
+					if ep, _ := o.SharedEdge(p, q); ep >= 0 && crack_edge_has(p, ep, v) {
+						group[q.Id] = ngroups
+						stack = append(stack, q)
+					}
+				}
+			}
+			ngroups++
+		}
+
+		// new vertices for all groups but the one with solid A
+		for k := 0; k < ngroups; k++ {
+			if k == group[a] {
+				continue
+			}
+			nv := &Vert{Id: len(o.Verts), Tag: o.Verts[v].Tag, C: make([]float64, len(o.Verts[v].C))}
+			copy(nv.C, o.Verts[v].C)
+			o.Verts = append(o.Verts, nv)
+			newverts[nv.Id] = v
+			for _, c := range around {
+				if group[c.Id] != k {
+					continue
+				}
+				for i, w := range c.Verts {
+					if w == v {
+						c.Verts[i] = nv.Id
+					}
+				}
+			}
+		}
+	}
+
+	// vertices of interface cells: {0,1} on the edge of A and {3,2} on the edge of B
+	for _, e := range *cracked {
+		la := o.Cells[e.A].Shp.FaceLocalVerts[e.Ea]
+		lb := o.Cells[e.B].Shp.FaceLocalVerts[e.Eb]
+		p0, p1 := o.Cells[e.A].Verts[la[0]], o.Cells[e.A].Verts[la[1]]
+		q0, q1 := o.Cells[e.B].Verts[lb[0]], o.Cells[e.B].Verts[lb[1]]
+		if crack_dist(o.Verts[p0].C, o.Verts[q0].C) > crack_dist(o.Verts[p0].C, o.Verts[q1].C) {
+			q0, q1 = q1, q0
+		}
+		o.Cells[e.Icell].Verts = []int{p0, p1, q1, q0}
+	}
+
+	// derived data
+	for _, v := range o.Verts {
+		v.SharedBy = nil
+	}
+	err = o.CalcDerived(ca.GoroutineId)
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// crack_edge_has returns whether the edge e of cell c has vertex v
+func crack_edge_has(c *Cell, e, v int) bool {
+	for _, l := range c.Shp.FaceLocalVerts[e] {
+		if c.Verts[l] == v {
+			return true
+		}
+	}
+	return false
+}
+
+// crack_dist returns the distance between two points
+func crack_dist(x, y []float64) (d float64) {
+	for i := 0; i < len(x); i++ {
+		d += (x[i] - y[i]) * (x[i] - y[i])
+	}
+	return math.Sqrt(d)
+}
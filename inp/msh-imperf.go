@@ -0,0 +1,156 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"path/filepath"
+
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// ModeData holds data for seeding imperfections with the shape of a buckling mode (or any other
+// displacement field) computed by a previous simulation; see Domain.SaveModeShape
+//  Note: the file is a table with columns "id ux uy" (2D) or "id ux uy uz" (3D) where "id" are the
+//        ids of vertices; the shape is normalised such that its largest displacement is equal to one
+type ModeData struct {
+	File  string  `json:"file"`  // filename of mode shape; relative to the directory of .sim file unless absolute
+	Scale float64 `json:"scale"` // scale factor: largest displacement (imperfection) corresponding to this mode
+}
+
+// ImperfData holds data for seeding geometric imperfections of meshes; e.g. for buckling analyses
+//  Note: (1) the coordinates of vertices are perturbed by values that depend only on the seed and
+//            on the ids of vertices; thus, the perturbed mesh is the same after restarts
+//        (2) if Dir is given, the vertices are moved along Dir (normalised) by one random value;
+//            otherwise, each coordinate is perturbed independently
+//        (3) the scaled mode shapes are added to the random perturbations; thus, Amp may be zero
+type ImperfData struct {
+	Amp    float64     `json:"amp"`    // amplitude: max perturbation (uniform) or standard deviation (normal)
+	Seed   int         `json:"seed"`   // seed; the same seed gives the same imperfections
+	Normal bool        `json:"normal"` // normal distribution instead of uniform
+	Dir    []float64   `json:"dir"`    // [ndim] direction of perturbation; e.g. [1,0] for lateral imperfections of columns. may be nil
+	Vtags  []int       `json:"vtags"`  // tags of perturbed vertices; empty => all vertices
+	Skip   []int       `json:"skip"`   // tags of vertices that are not perturbed; e.g. supports
+	Modes  []*ModeData `json:"modes"`  // mode shapes to be scaled and added to the coordinates of vertices
+}
+
+// Perturb perturbs the coordinates of vertices according to dat and recomputes the derived data
+//  simdir -- directory of .sim file (for mode shapes given with relative paths)
+func (o *Mesh) Perturb(dat *ImperfData, simdir string, goroutineId int) (err error) {
+
+	// check
+	if len(o.Nurbss) > 0 {
+		return chk.Err("geometric imperfections are not available for NURBS meshes")
+	}
+	var dir []float64
+	if len(dat.Dir) > 0 {
+		if len(dat.Dir) < o.Ndim {
+			return chk.Err("direction of imperfections must have %d components. dir = %v is invalid", o.Ndim, dat.Dir)
+		}
+		var norm float64
+		for i := 0; i < o.Ndim; i++ {
+			norm += dat.Dir[i] * dat.Dir[i]
+		}
+		norm = math.Sqrt(norm)
+		if norm < 1e-14 {
+			return chk.Err("direction of imperfections must not be zero")
+		}
+		dir = make([]float64, o.Ndim)
+		for i := 0; i < o.Ndim; i++ {
+			dir[i] = dat.Dir[i] / norm
+		}
+	}
+	only := make(map[int]bool)
+	for _, tag := range dat.Vtags {
+		only[tag] = true
+	}
+	skip := make(map[int]bool)
+	for _, tag := range dat.Skip {
+		skip[tag] = true
+	}
+
+	// mode shapes
+	perturbed := func(v *Vert) bool {
+		return !((len(only) > 0 && !only[v.Tag]) || skip[v.Tag])
+	}
+	for _, mode := range dat.Modes {
+		err = o.add_mode(mode, simdir, perturbed)
+		if err != nil {
+			return
+		}
+	}
+
+	// perturb coordinates
+	noise := &solid.Noise{Amp: dat.Amp, Seed: int64(dat.Seed), Normal: dat.Normal}
+	for _, v := range o.Verts {
+		if !perturbed(v) {
+			continue
+		}
+		if dir != nil {
+			δ := noise.Value(v.Id)
+			for i := 0; i < o.Ndim; i++ {
+				v.C[i] += δ * dir[i]
+			}
+			continue
+		}
+		for i := 0; i < o.Ndim; i++ {
+			v.C[i] += noise.Value(v.Id, i)
+		}
+	}
+	return o.CalcDerived(goroutineId)
+}
+
+// add_mode adds a scaled mode shape to the coordinates of vertices
+func (o *Mesh) add_mode(mode *ModeData, dir string, perturbed func(v *Vert) bool) (err error) {
+
+	// read table
+	fn := mode.File
+	if !filepath.IsAbs(fn) {
+		fn = filepath.Join(dir, fn)
+	}
+	_, d, err := io.ReadTable(fn)
+	if err != nil {
+		return chk.Err("cannot read mode shape file %q:\n%v", fn, err)
+	}
+	ukeys := []string{"ux", "uy", "uz"}[:o.Ndim]
+	for _, key := range append([]string{"id"}, ukeys...) {
+		if _, ok := d[key]; !ok {
+			return chk.Err("mode shape file %q must have column %q", fn, key)
+		}
+	}
+
+	// normalisation
+	ids := d["id"]
+	var umax float64
+	for k := range ids {
+		var norm float64
+		for _, key := range ukeys {
+			norm += d[key][k] * d[key][k]
+		}
+		umax = math.Max(umax, math.Sqrt(norm))
+	}
+	if umax < 1e-14 {
+		return chk.Err("mode shape in file %q must not be zero", fn)
+	}
+
+	// add scaled shape
+	α := mode.Scale / umax
+	for k, id := range ids {
+		vid := int(id)
+		if vid < 0 || vid >= len(o.Verts) {
+			return chk.Err("mode shape file %q has invalid vertex id = %d", fn, vid)
+		}
+		v := o.Verts[vid]
+		if !perturbed(v) {
+			continue
+		}
+		for i, key := range ukeys {
+			v.C[i] += α * d[key][k]
+		}
+	}
+	return
+}
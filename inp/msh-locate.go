@@ -0,0 +1,141 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+)
+
+// constants for locating points in cells
+const (
+	LOCATE_NDIV   = 20   // default number of divisions of grid along each direction
+	LOCATE_OUTTOL = 1e-2 // tolerance (in natural coordinates) to accept points slightly outside cells
+)
+
+// CellLocator finds the solid cells containing given points
+//  Note: (1) a uniform grid holding the cells with overlapping bounding boxes is employed to
+//            speed up the search
+//        (2) the inverse mapping is performed with the shape structure of each cell; thus, the
+//            cell's scratchpad is modified
+//        (3) NURBS cells are ignored
+type CellLocator struct {
+	Msh  *Mesh           // mesh
+	Ndiv int             // number of divisions along each direction
+	Xmin []float64       // [ndim] minimum coordinates of grid
+	Dx   []float64       // [ndim] sizes of grid cells
+	Grid map[int][]*Cell // grid index => cells with bounding boxes overlapping grid cell
+	Xmat [][][]float64   // [ncells][ndim][nverts] coordinates of cells (only solids)
+}
+
+// NewCellLocator returns a new locator for the solid cells of mesh
+//  ndiv -- number of divisions of grid along each direction; use ndiv < 1 for default value
+func NewCellLocator(msh *Mesh, ndiv int) (o *CellLocator) {
+
+	// grid
+	o = new(CellLocator)
+	o.Msh = msh
+	o.Ndiv = ndiv
+	if o.Ndiv < 1 {
+		o.Ndiv = LOCATE_NDIV
+	}
+	xmin := []float64{msh.Xmin, msh.Ymin, msh.Zmin}
+	xmax := []float64{msh.Xmax, msh.Ymax, msh.Zmax}
+	o.Xmin = make([]float64, msh.Ndim)
+	o.Dx = make([]float64, msh.Ndim)
+	for i := 0; i < msh.Ndim; i++ {
+		δ := 1e-8 * (1.0 + xmax[i] - xmin[i])
+		o.Xmin[i] = xmin[i] - δ
+		o.Dx[i] = (xmax[i] - xmin[i] + 2.0*δ) / float64(o.Ndiv)
+	}
+
+	// add cells to grid
+	o.Grid = make(map[int][]*Cell)
+	o.Xmat = make([][][]float64, len(msh.Cells))
+	imin := make([]int, 3)
+	imax := make([]int, 3)
+	for _, c := range msh.Cells {
+		if !c.IsSolid || c.Shp == nil || c.Shp.Nurbs != nil {
+			continue
+		}
+		o.Xmat[c.Id] = msh.cell_coords(c)
+		for i := 0; i < msh.Ndim; i++ {
+			a, b := o.Xmat[c.Id][i][0], o.Xmat[c.Id][i][0]
+			for _, x := range o.Xmat[c.Id][i] {
+				a = math.Min(a, x)
+				b = math.Max(b, x)
+			}
+			imin[i] = o.index1d(a, i)
+			imax[i] = o.index1d(b, i)
+		}
+		for i := imin[0]; i <= imax[0]; i++ {
+			for j := imin[1]; j <= imax[1]; j++ {
+				if msh.Ndim == 2 {
+					idx := i + j*o.Ndiv
+					o.Grid[idx] = append(o.Grid[idx], c)
+					continue
+				}
+				for k := imin[2]; k <= imax[2]; k++ {
+					idx := i + j*o.Ndiv + k*o.Ndiv*o.Ndiv
+					o.Grid[idx] = append(o.Grid[idx], c)
+				}
+			}
+		}
+	}
+	return
+}
+
+// Find finds the cell containing point x
+//  Output:
+//   c -- cell containing x. If x is slightly outside the mesh (e.g. due to curved boundaries),
+//        the closest cell in natural coordinates is returned. nil is returned if not found
+//   r -- [3] natural coordinates of x in c
+func (o *CellLocator) Find(x []float64) (c *Cell, r []float64) {
+
+	// grid cell
+	idx := 0
+	stride := 1
+	for i := 0; i < o.Msh.Ndim; i++ {
+		if x[i] < o.Xmin[i] || x[i] > o.Xmin[i]+float64(o.Ndiv)*o.Dx[i] {
+			return nil, nil
+		}
+		idx += o.index1d(x[i], i) * stride
+		stride *= o.Ndiv
+	}
+
+	// search candidates
+	best := math.Inf(-1)
+	rc := make([]float64, 3)
+	for _, cand := range o.Grid[idx] {
+		err := cand.Shp.InvMap(rc, x, o.Xmat[cand.Id])
+		if err != nil {
+			continue
+		}
+		dist := cand.Shp.CellBryDist(rc)
+		if dist > best {
+			best = dist
+			c = cand
+			r = []float64{rc[0], rc[1], rc[2]}
+		}
+		if dist >= 0 {
+			return
+		}
+	}
+	if best < -LOCATE_OUTTOL {
+		return nil, nil
+	}
+	return
+}
+
+// index1d returns the grid index along direction i corresponding to coordinate x
+func (o *CellLocator) index1d(x float64, i int) int {
+	idx := int((x - o.Xmin[i]) / o.Dx[i])
+	if idx < 0 {
+		return 0
+	}
+	if idx >= o.Ndiv {
+		return o.Ndiv - 1
+	}
+	return idx
+}
@@ -0,0 +1,260 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// tags of pull-out test model
+const (
+	PULLOUT_SLD  = -1  // tag of solid (hex8) cells
+	PULLOUT_ROD  = -2  // tag of rod cells
+	PULLOUT_JNT  = -3  // tag of rod-joint cells
+	PULLOUT_LOAD = -66 // tag of loaded vertex at the end of the rod
+)
+
+// PullOutData holds the data of the standard pull-out test model: a rod embedded along the axis of
+// a square block of solid, bonded by rod-joints (rjoint-m1), and pulled out at the loaded face y=L.
+// The block is B×L×B with the rod along y at x=z=B/2. The faces are tagged as follows:
+//  x=0 => -10, x=B => -11, y=0 => -20, y=L => -21, z=0 => -30, z=B => -31
+//  Note: (1) Nb must be odd such that the rod does not run along edges of solids
+//        (2) the loaded face (y=L) is supported in the y-direction (bearing plate) and the lateral
+//            faces are supported in the normal direction
+//        (3) with a stiff block, kh=0 and μ≈0, the force-displacement curve of the loaded vertex
+//            corresponds to the analytical solution ana.PullOut; see AnaPrms
+type PullOutData struct {
+
+	// geometry and mesh
+	B  float64 // width of block
+	L  float64 // length of block (and embedded length of rod)
+	Nb int     // number of divisions along x and z (odd)
+	Nl int     // number of divisions along y
+
+	// materials
+	Esld  float64 // Young's modulus of block
+	Nusld float64 // Poisson's coefficient of block
+	Erod  float64 // Young's modulus of rod
+	Arod  float64 // cross-sectional area of rod
+	Ks    float64 // rjoint-m1: shear stiffness of bond
+	Kl    float64 // rjoint-m1: lateral stiffness of bond
+	Tauy0 float64 // rjoint-m1: initial yield bond stress
+	Kh    float64 // rjoint-m1: hardening modulus
+	Mu    float64 // rjoint-m1: friction coefficient (must be positive)
+	H     float64 // rjoint-m1: perimeter of rod
+
+	// loading
+	Pmax   float64 // final pull-out force (applied linearly in time from t=0 to t=1)
+	Nsteps int     // number of load steps
+}
+
+// SetDefault sets default values
+func (o *PullOutData) SetDefault() {
+	o.B, o.L, o.Nb, o.Nl = 1, 2, 3, 8
+	o.Esld, o.Nusld = 1e6, 0.25
+	o.Erod, o.Arod = 2e5, 0.005
+	o.Ks, o.Kl, o.Tauy0, o.Kh, o.Mu, o.H = 1e4, 1e4, 20, 0, 1e-6, 0.25
+	o.Pmax, o.Nsteps = 8, 10
+}
+
+// AnaPrms returns the parameters of the analytical solution ana.PullOut
+func (o PullOutData) AnaPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "EA", V: o.Erod * o.Arod},
+		&fun.Prm{N: "ks", V: o.Ks},
+		&fun.Prm{N: "h", V: o.H},
+		&fun.Prm{N: "tauy", V: o.Tauy0},
+		&fun.Prm{N: "L", V: o.L},
+	}
+}
+
+// Mesh generates the mesh of the pull-out test model
+func (o PullOutData) Mesh() (msh *Mesh, err error) {
+
+	// check
+	if o.B <= 0 || o.L <= 0 {
+		return nil, chk.Err("pull-out: dimensions B=%g and L=%g must be positive\n", o.B, o.L)
+	}
+	if o.Nb < 1 || o.Nb%2 == 0 || o.Nl < 1 {
+		return nil, chk.Err("pull-out: Nb=%d must be odd and Nl=%d must be positive\n", o.Nb, o.Nl)
+	}
+
+	// vertices
+	msh = new(Mesh)
+	n, m := o.Nb+1, o.Nl+1
+	vid := func(i, j, k int) int { return i + j*n + k*n*m }
+	for k := 0; k < n; k++ {
+		for j := 0; j < m; j++ {
+			for i := 0; i < n; i++ {
+				x := o.B * float64(i) / float64(o.Nb)
+				y := o.L * float64(j) / float64(o.Nl)
+				z := o.B * float64(k) / float64(o.Nb)
+				msh.Verts = append(msh.Verts, &Vert{Id: len(msh.Verts), C: []float64{x, y, z}})
+			}
+		}
+	}
+
+	// solids; faces of hex8: x-, x+, y-, y+, z-, z+
+	bry := func(idx, nmax, tagmin, tagmax int) (tmin, tmax int) {
+		if idx == 0 {
+			tmin = tagmin
+		}
+		if idx == nmax-1 {
+			tmax = tagmax
+		}
+		return
+	}
+	for k := 0; k < o.Nb; k++ {
+		for j := 0; j < o.Nl; j++ {
+			for i := 0; i < o.Nb; i++ {
+				c := &Cell{Id: len(msh.Cells), Tag: PULLOUT_SLD, Type: "hex8", FTags: make([]int, 6)}
+				c.Verts = []int{
+					vid(i, j, k), vid(i+1, j, k), vid(i+1, j+1, k), vid(i, j+1, k),
+					vid(i, j, k+1), vid(i+1, j, k+1), vid(i+1, j+1, k+1), vid(i, j+1, k+1),
+				}
+				c.FTags[0], c.FTags[1] = bry(i, o.Nb, -10, -11)
+				c.FTags[2], c.FTags[3] = bry(j, o.Nl, -20, -21)
+				c.FTags[4], c.FTags[5] = bry(k, o.Nb, -30, -31)
+				msh.Cells = append(msh.Cells, c)
+			}
+		}
+	}
+	err = msh.CalcDerived(0)
+	if err != nil {
+		return
+	}
+
+	// rod and rod-joints
+	xc := o.B / 2.0
+	err = msh.AddRods([]*RodPolyline{
+		&RodPolyline{Tag: PULLOUT_ROD, Jtag: PULLOUT_JNT, Pts: [][]float64{{xc, 0, xc}, {xc, o.L, xc}}},
+	})
+	if err != nil {
+		return
+	}
+
+	// loaded vertex
+	for _, v := range msh.Verts {
+		if math.Abs(v.C[0]-xc) < TOL_COINCIDENT_VERTS && math.Abs(v.C[1]-o.L) < TOL_COINCIDENT_VERTS && math.Abs(v.C[2]-xc) < TOL_COINCIDENT_VERTS {
+			v.Tag = PULLOUT_LOAD
+			return
+		}
+	}
+	return nil, chk.Err("pull-out: cannot find loaded vertex at the end of the rod\n")
+}
+
+// Write writes the mesh (fnkey.msh), materials (fnkey.mat) and simulation (fnkey.sim) files of the
+// pull-out test model to directory dirout. The results are saved in /tmp/gofem/fnkey
+func (o PullOutData) Write(dirout, fnkey string) (err error) {
+
+	// mesh
+	msh, err := o.Mesh()
+	if err != nil {
+		return
+	}
+	msh.WriteMsh(dirout, fnkey+".msh")
+
+	// materials
+	io.WriteFileSD(dirout, fnkey+".mat", io.Sf(pullout_mat,
+		o.Esld, o.Nusld,
+		o.Erod, o.Arod,
+		o.Ks, o.Kl, o.Tauy0, o.Kh, o.Mu, o.H))
+
+	// simulation
+	dt := 1.0 / float64(utl.Imax(o.Nsteps, 1))
+	io.WriteFileSD(dirout, fnkey+".sim", io.Sf(pullout_sim,
+		fnkey, o.Pmax, fnkey,
+		PULLOUT_SLD, PULLOUT_ROD, PULLOUT_JNT,
+		PULLOUT_LOAD, dt, dt))
+	return
+}
+
+// templates of pull-out test files
+const pullout_mat = `{
+  "functions" : [],
+  "materials" : [
+    {
+      "name"  : "pullout: solid",
+      "type"  : "sld",
+      "model" : "lin-elast",
+      "prms"  : [
+        {"n":"E",   "v":%g},
+        {"n":"nu",  "v":%g},
+        {"n":"rho", "v":1}
+      ]
+    },
+    {
+      "name"  : "pullout: rod",
+      "type"  : "sld",
+      "model" : "oned-elast",
+      "prms"  : [
+        {"n":"E",   "v":%g},
+        {"n":"A",   "v":%g},
+        {"n":"rho", "v":1}
+      ]
+    },
+    {
+      "name"  : "pullout: bond",
+      "type"  : "sld",
+      "model" : "rjoint-m1",
+      "prms"  : [
+        {"n":"ks",    "v":%g},
+        {"n":"kl",    "v":%g},
+        {"n":"tauy0", "v":%g},
+        {"n":"kh",    "v":%g},
+        {"n":"mu",    "v":%g},
+        {"n":"h",     "v":%g}
+      ]
+    }
+  ]
+}
+`
+
+const pullout_sim = `{
+  "data" : {
+    "desc"    : "pull-out test",
+    "matfile" : "%s.mat",
+    "steady"  : true
+  },
+  "functions" : [
+    { "name":"load", "type":"lin", "prms":[{"n":"m", "v":%g}] }
+  ],
+  "regions" : [
+    {
+      "mshfile" : "%s.msh",
+      "elemsdata" : [
+        { "tag":%d, "mat":"pullout: solid", "type":"solid", "extra":"!nip:8" },
+        { "tag":%d, "mat":"pullout: rod",   "type":"rod", "extra":"!nip:3" },
+        { "tag":%d, "mat":"pullout: bond",  "type":"rjoint" }
+      ]
+    }
+  ],
+  "stages" : [
+    {
+      "desc" : "pull rod",
+      "nodebcs" : [
+        { "tag":%d, "keys":["fy"], "funcs":["load"] }
+      ],
+      "facebcs" : [
+        { "tag":-10, "keys":["ux"], "funcs":["zero"] },
+        { "tag":-11, "keys":["ux"], "funcs":["zero"] },
+        { "tag":-21, "keys":["uy"], "funcs":["zero"] },
+        { "tag":-30, "keys":["uz"], "funcs":["zero"] },
+        { "tag":-31, "keys":["uz"], "funcs":["zero"] }
+      ],
+      "control" : {
+        "tf"    : 1.0,
+        "dt"    : %g,
+        "dtout" : %g
+      }
+    }
+  ]
+}
+`
@@ -0,0 +1,329 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"bytes"
+	"math"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// QualityData holds the limits for checking the quality of the solid cells of meshes; a zero value
+// means "do not check". Cells with non-positive Jacobian ratios (inverted or degenerate) always fail
+type QualityData struct {
+	Jratio   float64 `json:"jratio"`   // min Jacobian ratio: min(det(J)) / max(det(J)) at corners
+	Aspect   float64 `json:"aspect"`   // max aspect ratio: max √(λmax/λmin) of Jᵀ・J at corners
+	Skew     float64 `json:"skew"`     // max equiangle skewness: 0 (ideal) ≤ skew ≤ 1 (degenerate)
+	MinAngle float64 `json:"minangle"` // min angle between edges at corners [degrees]
+	Vtk      bool    `json:"vtk"`      // write <dirout>/<fnkey>_reg<i>_quality.vtu file with quality fields of region i
+	Warn     bool    `json:"warn"`     // only print the report (do not stop the simulation)
+}
+
+// CellQuality holds quality measures of a solid cell
+type CellQuality struct {
+	Id       int     // id of cell
+	Jratio   float64 // min(det(J)) / max(|det(J)|) at corners; ≤ 0 => inverted or degenerate
+	Aspect   float64 // max √(λmax/λmin) of Jᵀ・J at corners; with J mapping the ideal (equilateral) simplex for tri/tet
+	Skew     float64 // equiangle skewness: max of (θ-θe)/(180-θe) and (θe-θ)/θe; θe = 60 (tri faces) or 90 (qua faces)
+	MinAngle float64 // min angle between edges at corners [degrees]
+}
+
+// MeshQuality holds the quality measures of all solid cells of a mesh
+//  Note: (1) only the corners of cells are considered; e.g. the mid-side vertices of qua8 are
+//            used to compute J but the angles are computed with straight edges between corners
+//        (2) the eigenvalues λ of Jᵀ・J (metric tensor) give the squared stretches of the mapping
+//            from natural coordinates; thus √(λmax/λmin) is the aspect ratio of the cell at corner
+//        (3) NURBS cells and cells without shape (beams, joints, rods) are not checked
+type MeshQuality struct {
+	Msh   *Mesh          // mesh
+	Cells []*CellQuality // [nsolids] quality of solid cells
+}
+
+// Quality computes the quality measures of the solid cells of mesh
+func (o *Mesh) Quality() (q *MeshQuality) {
+	q = &MeshQuality{Msh: o}
+	for _, c := range o.Cells {
+		if !c.IsSolid || c.Shp == nil || c.Shp.Nurbs != nil || c.Shp.Gndim != o.Ndim {
+			continue
+		}
+		q.Cells = append(q.Cells, o.cell_quality(c))
+	}
+	return
+}
+
+// Check checks the quality measures against limits and returns the failed cells
+func (o *MeshQuality) Check(lim *QualityData) (bad []*CellQuality) {
+	for _, c := range o.Cells {
+		fail := c.Jratio <= 0 || c.Jratio < lim.Jratio
+		fail = fail || (lim.Aspect > 0 && c.Aspect > lim.Aspect)
+		fail = fail || (lim.Skew > 0 && c.Skew > lim.Skew)
+		fail = fail || (lim.MinAngle > 0 && c.MinAngle < lim.MinAngle)
+		if fail {
+			bad = append(bad, c)
+		}
+	}
+	return
+}
+
+// Report returns a report with the range of quality measures and the list of failed cells
+func (o *MeshQuality) Report(bad []*CellQuality) string {
+	if len(o.Cells) == 0 {
+		return "mesh quality: there are no solid cells to be checked\n"
+	}
+	inf := math.Inf(1)
+	mins := []float64{inf, inf, inf, inf}
+	maxs := []float64{-inf, -inf, -inf, -inf}
+	for _, c := range o.Cells {
+		for i, v := range []float64{c.Jratio, c.Aspect, c.Skew, c.MinAngle} {
+			mins[i] = math.Min(mins[i], v)
+			maxs[i] = math.Max(maxs[i], v)
+		}
+	}
+	var b bytes.Buffer
+	io.Ff(&b, "mesh quality of %d solid cells in %q\n", len(o.Cells), o.Msh.FnamePath)
+	io.Ff(&b, "%10s%14s%14s\n", "measure", "min", "max")
+	for i, key := range []string{"jratio", "aspect", "skew", "minangle"} {
+		io.Ff(&b, "%10s%14.6g%14.6g\n", key, mins[i], maxs[i])
+	}
+	if len(bad) > 0 {
+		io.Ff(&b, "%d cells failed the quality check:\n", len(bad))
+		io.Ff(&b, "%8s%8s%14s%14s%14s%14s\n", "cell", "type", "jratio", "aspect", "skew", "minangle")
+		for _, c := range bad {
+			io.Ff(&b, "%8d%8s%14.6g%14.6g%14.6g%14.6g\n", c.Id, o.Msh.Cells[c.Id].Type, c.Jratio, c.Aspect, c.Skew, c.MinAngle)
+		}
+	}
+	return b.String()
+}
+
+// WriteVtu writes a VTU file with the quality measures of solid cells as cell data
+func (o *MeshQuality) WriteVtu(dirout, fnkey string) {
+
+	// topology
+	m := o.Msh
+	var geo, dat bytes.Buffer
+	io.Ff(&geo, "<Points>\n<DataArray type=\"Float64\" NumberOfComponents=\"3\" format=\"ascii\">\n")
+	for _, v := range m.Verts {
+		var z float64
+		if m.Ndim == 3 {
+			z = v.C[2]
+		}
+		io.Ff(&geo, "%23.15e %23.15e %23.15e ", v.C[0], v.C[1], z)
+	}
+	io.Ff(&geo, "\n</DataArray>\n</Points>\n")
+	io.Ff(&geo, "<Cells>\n<DataArray type=\"Int32\" Name=\"connectivity\" format=\"ascii\">\n")
+	for _, q := range o.Cells {
+		c := m.Cells[q.Id]
+		nverts, _ := c.GetVtkInfo(false, false)
+		for j := 0; j < nverts; j++ {
+			io.Ff(&geo, "%d ", c.Verts[j])
+		}
+	}
+	io.Ff(&geo, "\n</DataArray>\n<DataArray type=\"Int32\" Name=\"offsets\" format=\"ascii\">\n")
+	var offset int
+	for _, q := range o.Cells {
+		nverts, _ := m.Cells[q.Id].GetVtkInfo(false, false)
+		offset += nverts
+		io.Ff(&geo, "%d ", offset)
+	}
+	io.Ff(&geo, "\n</DataArray>\n<DataArray type=\"UInt8\" Name=\"types\" format=\"ascii\">\n")
+	for _, q := range o.Cells {
+		_, vtkcode := m.Cells[q.Id].GetVtkInfo(false, false)
+		io.Ff(&geo, "%d ", vtkcode)
+	}
+	io.Ff(&geo, "\n</DataArray>\n</Cells>\n")
+
+	// cell data
+	io.Ff(&dat, "<CellData Scalars=\"TheScalars\">\n")
+	io.Ff(&dat, "<DataArray type=\"Int32\" Name=\"eid\" NumberOfComponents=\"1\" format=\"ascii\">\n")
+	for _, q := range o.Cells {
+		io.Ff(&dat, "%d ", q.Id)
+	}
+	io.Ff(&dat, "\n</DataArray>\n")
+	for i, key := range []string{"jratio", "aspect", "skew", "minangle"} {
+		io.Ff(&dat, "<DataArray type=\"Float64\" Name=\"%s\" NumberOfComponents=\"1\" format=\"ascii\">\n", key)
+		for _, q := range o.Cells {
+			io.Ff(&dat, "%23.15e ", []float64{q.Jratio, q.Aspect, q.Skew, q.MinAngle}[i])
+		}
+		io.Ff(&dat, "\n</DataArray>\n")
+	}
+	io.Ff(&dat, "</CellData>\n")
+
+	// write file
+	var hdr, foo bytes.Buffer
+	io.Ff(&hdr, "<?xml version=\"1.0\"?>\n<VTKFile type=\"UnstructuredGrid\" version=\"0.1\" byte_order=\"LittleEndian\">\n<UnstructuredGrid>\n")
+	io.Ff(&hdr, "<Piece NumberOfPoints=\"%d\" NumberOfCells=\"%d\">\n", len(m.Verts), len(o.Cells))
+	io.Ff(&foo, "</Piece>\n</UnstructuredGrid>\n</VTKFile>\n")
+	io.WriteFileVD(dirout, fnkey+"_quality.vtu", &hdr, &geo, &dat, &foo)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// cell_quality computes the quality measures of solid cell c
+func (o *Mesh) cell_quality(c *Cell) (q *CellQuality) {
+
+	// Jacobians at corners
+	q = &CellQuality{Id: c.Id}
+	shape := c.Shp
+	nd := o.Ndim
+	ncorners := shape.BasicNverts
+	x := o.cell_coords(c)
+	S := make([]float64, shape.Nverts)
+	dSdR := la.MatAlloc(shape.Nverts, nd)
+	J := la.MatAlloc(nd, nd)
+	C := la.MatAlloc(nd, nd)
+	M := la.MatAlloc(nd, nd)
+	Wi := quality_ideal_inv(shape.Type, nd)
+	r := make([]float64, 3)
+	detmin, detmax := math.Inf(1), 0.0
+	for k := 0; k < ncorners; k++ {
+		for i := 0; i < nd; i++ {
+			r[i] = shape.NatCoords[i][k]
+		}
+		shape.Func(S, dSdR, r, true, -1)
+		for i := 0; i < nd; i++ {
+			for j := 0; j < nd; j++ {
+				J[i][j] = 0
+				for m := 0; m < shape.Nverts; m++ {
+					J[i][j] += x[i][m] * dSdR[m][j]
+				}
+			}
+		}
+		det := quality_det(J)
+		detmin = math.Min(detmin, det)
+		detmax = math.Max(detmax, math.Abs(det))
+
+		// metric tensor of mapping from ideal cell: M = Cᵀ・C with C = J・W⁻¹
+		for i := 0; i < nd; i++ {
+			for j := 0; j < nd; j++ {
+				C[i][j] = 0
+				for l := 0; l < nd; l++ {
+					C[i][j] += J[i][l] * Wi[l][j]
+				}
+			}
+		}
+		for i := 0; i < nd; i++ {
+			for j := 0; j < nd; j++ {
+				M[i][j] = 0
+				for l := 0; l < nd; l++ {
+					M[i][j] += C[l][i] * C[l][j]
+				}
+			}
+		}
+		λmin, λmax := quality_eigs(M)
+		if λmin <= 0 {
+			q.Aspect = math.Inf(1)
+		} else {
+			q.Aspect = math.Max(q.Aspect, math.Sqrt(λmax/λmin))
+		}
+	}
+	if detmax > 0 {
+		q.Jratio = detmin / detmax
+	}
+
+	// angles at corners of faces (3D) or of cell (2D)
+	var polys [][]int
+	if nd == 2 {
+		polys = [][]int{utl.IntRange(ncorners)}
+	} else {
+		nc := 4
+		if strings.HasPrefix(shape.FaceType, "tri") {
+			nc = 3
+		}
+		for _, lverts := range shape.FaceLocalVerts {
+			polys = append(polys, lverts[:nc])
+		}
+	}
+	q.MinAngle = 180
+	for _, poly := range polys {
+		n := len(poly)
+		θe := 90.0
+		if n == 3 {
+			θe = 60.0
+		}
+		for k := 0; k < n; k++ {
+			a, b, d := poly[(k+n-1)%n], poly[k], poly[(k+1)%n]
+			var dot, la2, lb2 float64
+			for i := 0; i < nd; i++ {
+				u, v := x[i][a]-x[i][b], x[i][d]-x[i][b]
+				dot += u * v
+				la2 += u * u
+				lb2 += v * v
+			}
+			θ := 0.0
+			if la2 > 0 && lb2 > 0 {
+				θ = math.Acos(math.Max(-1, math.Min(1, dot/math.Sqrt(la2*lb2)))) * 180.0 / math.Pi
+			}
+			q.MinAngle = math.Min(q.MinAngle, θ)
+			q.Skew = math.Max(q.Skew, math.Max((θ-θe)/(180.0-θe), (θe-θ)/θe))
+		}
+	}
+	return
+}
+
+// quality_ideal_inv returns the inverse of the Jacobian mapping the natural (reference) cell to
+// the ideal one: equilateral triangles and regular tetrahedra; identity for quadrilaterals/hexahedra
+func quality_ideal_inv(celltype string, ndim int) (Wi [][]float64) {
+	Wi = la.MatAlloc(ndim, ndim)
+	for i := 0; i < ndim; i++ {
+		Wi[i][i] = 1
+	}
+	if !strings.HasPrefix(celltype, "tri") && !strings.HasPrefix(celltype, "tet") {
+		return
+	}
+	W := [][]float64{{1, 0.5}, {0, math.Sqrt(3) / 2}}
+	if ndim == 3 {
+		W = [][]float64{{1, 0.5, 0.5}, {0, math.Sqrt(3) / 2, math.Sqrt(3) / 6}, {0, 0, math.Sqrt(2.0 / 3.0)}}
+	}
+	_, err := la.MatInv(Wi, W, 1e-14)
+	if err != nil {
+		chk.Panic("cannot invert Jacobian of ideal cell:\n%v", err)
+	}
+	return
+}
+
+// quality_det returns the determinant of a 2x2 or 3x3 matrix
+func quality_det(a [][]float64) float64 {
+	if len(a) == 2 {
+		return a[0][0]*a[1][1] - a[0][1]*a[1][0]
+	}
+	return a[0][0]*(a[1][1]*a[2][2]-a[1][2]*a[2][1]) -
+		a[0][1]*(a[1][0]*a[2][2]-a[1][2]*a[2][0]) +
+		a[0][2]*(a[1][0]*a[2][1]-a[1][1]*a[2][0])
+}
+
+// quality_eigs returns the min and max eigenvalues of a symmetric 2x2 or 3x3 matrix (closed-form)
+func quality_eigs(a [][]float64) (λmin, λmax float64) {
+	if len(a) == 2 {
+		m := (a[0][0] + a[1][1]) / 2
+		d := math.Sqrt(math.Pow((a[0][0]-a[1][1])/2, 2) + a[0][1]*a[0][1])
+		return m - d, m + d
+	}
+	p1 := a[0][1]*a[0][1] + a[0][2]*a[0][2] + a[1][2]*a[1][2]
+	tr := (a[0][0] + a[1][1] + a[2][2]) / 3
+	p2 := math.Pow(a[0][0]-tr, 2) + math.Pow(a[1][1]-tr, 2) + math.Pow(a[2][2]-tr, 2) + 2*p1
+	if p2 < 1e-30*(tr*tr+1e-300) {
+		return tr, tr
+	}
+	p := math.Sqrt(p2 / 6)
+	b := la.MatAlloc(3, 3)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			b[i][j] = a[i][j] / p
+			if i == j {
+				b[i][j] -= tr / p
+			}
+		}
+	}
+	h := math.Max(-1, math.Min(1, quality_det(b)/2))
+	φ := math.Acos(h) / 3
+	λmax = tr + 2*p*math.Cos(φ)
+	λmin = tr + 2*p*math.Cos(φ+2*math.Pi/3)
+	return
+}
@@ -0,0 +1,190 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// constants for rod/solid intersections
+const (
+	RODS_NSAMPLES = 21     // number of sample points along each polyline segment (or rod)
+	RODS_BISTOL   = 1.0e-9 // tolerance (in parametric coordinate) for bisection
+	RODS_INSTOL   = 1.0e-8 // tolerance (in solid's natural coordinates) to consider a point inside solid
+)
+
+// RodPolyline holds the data of a reinforcement line to be embedded into a solid mesh
+type RodPolyline struct {
+	Tag  int         // tag of rod cells; e.g. -2
+	Jtag int         // tag of joint (rjoint) cells; e.g. -3
+	Part int         // partition id of new cells
+	Pts  [][]float64 // [npts][ndim] points defining polyline
+}
+
+// AddRods computes the intersections between reinforcement polylines and the solids of this mesh
+// and generates new rod ("lin2") cells and joint cells connecting rods to the solids containing them.
+//  Note: (1) this mesh must have been read already (i.e. CalcDerived must have been called)
+//        (2) new vertices and cells are appended to Verts and Cells; however, the derived data
+//            (e.g. maps) are not re-computed. Use WriteMsh and then ReadMsh to get a consistent mesh
+//        (3) the intersections are found by sampling each polyline segment and by bisection; see
+//            RodSplit. The same procedure splits rjoint rods crossing many solids in ele/solid
+func (o *Mesh) AddRods(lines []*RodPolyline) (err error) {
+
+	// solid cells and coordinates
+	var shps []*shp.Shape
+	var slds []*Cell
+	var xsld [][][]float64
+	for _, c := range o.Cells {
+		if c.IsSolid && c.Shp != nil && c.Shp.Nurbs == nil {
+			shps = append(shps, c.Shp)
+			slds = append(slds, c)
+			xsld = append(xsld, o.cell_coords(c))
+		}
+	}
+	if len(slds) == 0 {
+		return chk.Err("cannot add rods because there are no solid cells in mesh\n")
+	}
+
+	// for each polyline
+	for iline, line := range lines {
+		if len(line.Pts) < 2 {
+			return chk.Err("polyline # %d must have at least 2 points\n", iline)
+		}
+
+		// for each segment of polyline
+		for k := 1; k < len(line.Pts); k++ {
+			p, q := line.Pts[k-1], line.Pts[k]
+			if len(p) < o.Ndim || len(q) < o.Ndim {
+				return chk.Err("points of polyline # %d must have %d coordinates\n", iline, o.Ndim)
+			}
+
+			// parts of segment inside each solid
+			ts, owners := RodSplit(0, 1, func(t float64) int {
+				return RodOwner(shps, xsld, rods_point(p, q, t, o.Ndim))
+			})
+
+			// generate rods and joints
+			for i, isld := range owners {
+				if isld < 0 {
+					y := rods_point(p, q, (ts[i]+ts[i+1])/2.0, o.Ndim)
+					return chk.Err("point %v of polyline # %d is outside the solid mesh\n", y, iline)
+				}
+				a := o.find_or_add_vert(rods_point(p, q, ts[i], o.Ndim))
+				b := o.find_or_add_vert(rods_point(p, q, ts[i+1], o.Ndim))
+				rod := &Cell{Id: len(o.Cells), Tag: line.Tag, Type: "lin2", Part: line.Part, Verts: []int{a, b}}
+				o.Cells = append(o.Cells, rod)
+				sld := slds[isld]
+				jnt := &Cell{Id: len(o.Cells), Tag: line.Jtag, Geo: 13, Type: "joint", Part: line.Part, JlinId: rod.Id, JsldId: sld.Id}
+				jnt.Verts = make([]int, 0, len(sld.Verts)+2)
+				jnt.Verts = append(append(jnt.Verts, sld.Verts...), rod.Verts...)
+				o.Cells = append(o.Cells, jnt)
+			}
+		}
+	}
+	return
+}
+
+// RodSplit splits the interval [ta, tb] of the parametric coordinate of a polyline segment (or rod)
+// into parts inside one solid each. The interval is sampled at RODS_NSAMPLES points and the
+// boundaries between solids are found by bisection
+//  owner -- returns the index of the solid containing the point with parametric coordinate t or -1
+//  Output:
+//   ts     -- [nparts+1] limits of parts; ts[0] = ta and ts[nparts] = tb
+//   owners -- [nparts] indices of solids containing each part; -1 if outside all solids
+//  Note: solids crossed over a length smaller than the distance between samples may be missed
+func RodSplit(ta, tb float64, owner func(t float64) int) (ts []float64, owners []int) {
+	ts = []float64{ta}
+	t0, prev := ta, owner(ta)
+	for i := 1; i < RODS_NSAMPLES; i++ {
+		t1 := ta + (tb-ta)*float64(i)/float64(RODS_NSAMPLES-1)
+		curr := owner(t1)
+		if curr != prev {
+			a, b := t0, t1
+			for b-a > RODS_BISTOL {
+				mid := (a + b) / 2.0
+				if owner(mid) == prev {
+					a = mid
+				} else {
+					b = mid
+				}
+			}
+			ts = append(ts, (a+b)/2.0)
+			owners = append(owners, prev)
+			prev = curr
+		}
+		t0 = t1
+	}
+	ts = append(ts, tb)
+	owners = append(owners, prev)
+	return
+}
+
+// RodOwner returns the index of the solid containing point y or -1 if not found. The solid with
+// the largest distance of y to its boundary (in natural coordinates) is selected
+//  shps -- [nsolids] shape structures of solids
+//  x    -- [nsolids][ndim][nverts] coordinates of solids
+func RodOwner(shps []*shp.Shape, x [][][]float64, y []float64) (idx int) {
+	idx = -1
+	r := make([]float64, 3)
+	dmax := math.Inf(-1)
+	for i, sh := range shps {
+		err := sh.InvMap(r, y, x[i])
+		if err != nil {
+			continue
+		}
+		d := sh.CellBryDist(r)
+		if d > -RODS_INSTOL && d > dmax {
+			dmax = d
+			idx = i
+		}
+	}
+	return
+}
+
+// WriteMsh writes this mesh to a file (e.g. after AddRods)
+func (o *Mesh) WriteMsh(dirout, fn string) {
+	io.WriteFileSD(dirout, fn, o.String())
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// cell_coords returns the matrix of coordinates of cell [ndim][nverts]
+func (o *Mesh) cell_coords(c *Cell) (x [][]float64) {
+	x = la.MatAlloc(o.Ndim, len(c.Verts))
+	for i := 0; i < o.Ndim; i++ {
+		for j, v := range c.Verts {
+			x[i][j] = o.Verts[v].C[i]
+		}
+	}
+	return
+}
+
+// find_or_add_vert returns the id of a vertex coincident with y or adds a new vertex
+func (o *Mesh) find_or_add_vert(y []float64) (vid int) {
+	for _, v := range o.Verts {
+		if utl.L2norm(v.C[:o.Ndim], y) < TOL_COINCIDENT_VERTS {
+			return v.Id
+		}
+	}
+	vid = len(o.Verts)
+	o.Verts = append(o.Verts, &Vert{Id: vid, C: y})
+	return
+}
+
+// rods_point returns the point on segment p-q corresponding to 0 ≤ t ≤ 1
+func rods_point(p, q []float64, t float64, ndim int) (y []float64) {
+	y = make([]float64, ndim)
+	for i := 0; i < ndim; i++ {
+		y[i] = p[i] + t*(q[i]-p[i])
+	}
+	return
+}
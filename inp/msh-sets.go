@@ -0,0 +1,369 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// SetData holds data of a named set of vertices, cells or faces. The set is given a new (negative)
+// tag which can be used anywhere tags are accepted; e.g. boundary conditions, outputs and stage
+// operations. Example:
+//   "sets" : [
+//     { "name":"left",   "kind":"faces", "tag":-901, "plane":[1, 0, 0] },
+//     { "name":"top",    "kind":"faces", "tag":-902, "expr":"y > 9.999" },
+//     { "name":"bottom", "kind":"verts", "tag":-903, "box":[0, 10, 0, 0] },
+//     { "name":"soils",  "kind":"cells", "tag":-904, "tags":[-1, -2] },
+//     { "name":"upper",  "kind":"cells", "tag":-905, "diff":["soils"], "box":[0, 10, 5, 10] }
+//   ]
+//  Note: (1) entities must satisfy all given criteria (tags, box, plane and expr). Then, the
+//            boolean operations combine previously defined sets of the same kind: the sets in
+//            "union" are added, the sets in "inter" are intersected and the sets in "diff" are
+//            removed. Without criteria and "union", the first set in "inter" (or "diff") is the
+//            starting set; e.g. "diff":["a","b"] gives a - b
+//        (2) cells and faces satisfy the geometric criteria (box, plane, expr) if all of their
+//            vertices do. Only faces on the boundary of the mesh are selected by geometric criteria
+type SetData struct {
+	Name  string    `json:"name"`  // name of set
+	Kind  string    `json:"kind"`  // "verts", "cells" or "faces"
+	Tag   int       `json:"tag"`   // new tag of set (negative and not used in mesh)
+	Tags  []int     `json:"tags"`  // entities with any of these tags
+	Box   []float64 `json:"box"`   // bounding box: [xmin, xmax, ymin, ymax] or [xmin, xmax, ymin, ymax, zmin, zmax]
+	Plane []float64 `json:"plane"` // plane a x + b y = c (2D) or a x + b y + c z = d (3D): [a, b, c] or [a, b, c, d]
+	Expr  string    `json:"expr"`  // logical expression of coordinates x, y and z; e.g. "x*x + y*y < 4"
+	Tol   float64   `json:"tol"`   // tolerance for box and plane criteria; default = 1e-8
+	Union []string  `json:"union"` // union of sets
+	Inter []string  `json:"inter"` // intersection of sets
+	Diff  []string  `json:"diff"`  // difference of sets
+}
+
+// msh_set holds the entities of a set: vertices ids, cells ids or (cell id, face id) pairs
+type msh_set struct {
+	kind string
+	ids  map[CellFaceId]bool // for vertices and cells, only CellFaceId.Fid is used (with C == nil)
+}
+
+// AddSets computes sets of vertices, cells or faces and assigns their tags
+//  Note: the derived maps (VertTag2verts, CellTag2cells, FaceTag2cells and FaceTag2verts) are
+//        updated and the tags of face sets are added to cells (FaceSets) such that face boundary
+//        conditions can be set with the new tags
+func (o *Mesh) AddSets(sets []*SetData) (err error) {
+	if len(sets) == 0 {
+		return
+	}
+	if o.SetTags == nil {
+		o.SetTags = make(map[int]bool)
+	}
+	named := make(map[string]*msh_set)
+	bryfaces := o.boundary_faces()
+	for _, dat := range sets {
+
+		// check
+		if dat.Name == "" {
+			return chk.Err("name of set must be given")
+		}
+		if _, ok := named[dat.Name]; ok {
+			return chk.Err("set named %q is defined more than once", dat.Name)
+		}
+		if dat.Tag >= 0 {
+			return chk.Err("tag of set %q must be negative. %d is invalid", dat.Name, dat.Tag)
+		}
+		if o.tag_used(dat.Tag) {
+			return chk.Err("tag %d of set %q is already used in mesh", dat.Tag, dat.Name)
+		}
+
+		// compute set
+		s, err := o.compute_set(dat, named, bryfaces)
+		if err != nil {
+			return chk.Err("cannot compute set %q:\n%v", dat.Name, err)
+		}
+		named[dat.Name] = s
+
+		// assign tag
+		o.SetTags[dat.Tag] = true
+		keys := s.sorted()
+		switch s.kind {
+		case "verts":
+			o.VertTag2verts[dat.Tag] = make([]*Vert, 0)
+			for _, k := range keys {
+				o.VertTag2verts[dat.Tag] = append(o.VertTag2verts[dat.Tag], o.Verts[k.Fid])
+			}
+		case "cells":
+			o.CellTag2cells[dat.Tag] = make([]*Cell, 0)
+			for _, k := range keys {
+				o.CellTag2cells[dat.Tag] = append(o.CellTag2cells[dat.Tag], o.Cells[k.Fid])
+			}
+		case "faces":
+			o.FaceTag2cells[dat.Tag] = make([]CellFaceId, 0)
+			for _, k := range keys {
+				o.FaceTag2cells[dat.Tag] = append(o.FaceTag2cells[dat.Tag], k)
+				if k.C.FaceSets == nil {
+					k.C.FaceSets = make(map[int][]int)
+				}
+				k.C.FaceSets[k.Fid] = append(k.C.FaceSets[k.Fid], dat.Tag)
+				for _, l := range k.C.Shp.FaceLocalVerts[k.Fid] {
+					utl.IntIntsMapAppend(&o.FaceTag2verts, dat.Tag, k.C.Verts[l])
+				}
+			}
+			o.FaceTag2verts[dat.Tag] = utl.IntUnique(o.FaceTag2verts[dat.Tag])
+		}
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// compute_set computes the entities of set
+func (o *Mesh) compute_set(dat *SetData, named map[string]*msh_set, bryfaces map[CellFaceId]bool) (s *msh_set, err error) {
+
+	// check kind
+	if dat.Kind != "verts" && dat.Kind != "cells" && dat.Kind != "faces" {
+		return nil, chk.Err("kind of set must be \"verts\", \"cells\" or \"faces\". %q is invalid", dat.Kind)
+	}
+	s = &msh_set{kind: dat.Kind, ids: make(map[CellFaceId]bool)}
+
+	// referenced sets
+	get := func(names []string) (res []*msh_set, err error) {
+		for _, name := range names {
+			r, ok := named[name]
+			if !ok {
+				return nil, chk.Err("set %q must be defined before being used", name)
+			}
+			if r.kind != dat.Kind {
+				return nil, chk.Err("set %q has kind %q but %q is required", name, r.kind, dat.Kind)
+			}
+			res = append(res, r)
+		}
+		return
+	}
+	union, err := get(dat.Union)
+	if err != nil {
+		return
+	}
+	inter, err := get(dat.Inter)
+	if err != nil {
+		return
+	}
+	diff, err := get(dat.Diff)
+	if err != nil {
+		return
+	}
+
+	// geometric criteria
+	tol := dat.Tol
+	if tol <= 0 {
+		tol = 1e-8
+	}
+	if len(dat.Box) > 0 && len(dat.Box) != 2*o.Ndim {
+		return nil, chk.Err("box must have %d values", 2*o.Ndim)
+	}
+	if len(dat.Plane) > 0 && len(dat.Plane) != o.Ndim+1 {
+		return nil, chk.Err("plane must have %d values", o.Ndim+1)
+	}
+	var expr *Expr
+	if dat.Expr != "" {
+		expr, err = NewExpr(dat.Expr, []string{"x", "y", "z"}, nil)
+		if err != nil {
+			return
+		}
+	}
+	geometric := len(dat.Box) > 0 || len(dat.Plane) > 0 || expr != nil
+	x := make([]float64, 3)
+	vertok := func(vid int) bool {
+		for i := 0; i < 3; i++ {
+			x[i] = 0
+		}
+		copy(x, o.Verts[vid].C[:o.Ndim])
+		for i := 0; i < len(dat.Box)/2; i++ {
+			if x[i] < dat.Box[2*i]-tol || x[i] > dat.Box[2*i+1]+tol {
+				return false
+			}
+		}
+		if len(dat.Plane) > 0 {
+			var dot, nrm float64
+			for i := 0; i < o.Ndim; i++ {
+				dot += dat.Plane[i] * x[i]
+				nrm += dat.Plane[i] * dat.Plane[i]
+			}
+			if math.Abs(dot-dat.Plane[o.Ndim]) > tol*math.Sqrt(nrm) {
+				return false
+			}
+		}
+		if expr != nil && !expr.True(x...) {
+			return false
+		}
+		return true
+	}
+
+	// entities satisfying criteria
+	criteria := len(dat.Tags) > 0 || geometric
+	if criteria {
+		switch dat.Kind {
+		case "verts":
+			for _, v := range o.Verts {
+				if (len(dat.Tags) == 0 || utl.IntIndexSmall(dat.Tags, v.Tag) >= 0) && (!geometric || vertok(v.Id)) {
+					s.ids[CellFaceId{nil, v.Id}] = true
+				}
+			}
+		case "cells":
+			for _, c := range o.Cells {
+				if len(dat.Tags) > 0 && utl.IntIndexSmall(dat.Tags, c.Tag) < 0 {
+					continue
+				}
+				if geometric && !o.all_verts_ok(c.Verts, vertok) {
+					continue
+				}
+				s.ids[CellFaceId{nil, c.Id}] = true
+			}
+		case "faces":
+			for _, c := range o.Cells {
+				if c.Shp == nil {
+					continue
+				}
+				for fid, lverts := range c.Shp.FaceLocalVerts {
+					k := CellFaceId{c, fid}
+					if len(dat.Tags) > 0 && (fid >= len(c.FTags) || utl.IntIndexSmall(dat.Tags, c.FTags[fid]) < 0) {
+						continue
+					}
+					if geometric {
+						gverts := make([]int, len(lverts))
+						for i, l := range lverts {
+							gverts[i] = c.Verts[l]
+						}
+						if !bryfaces[k] || !o.all_verts_ok(gverts, vertok) {
+							continue
+						}
+					}
+					s.ids[k] = true
+				}
+			}
+		}
+	}
+
+	// boolean operations
+	switch {
+	case criteria || len(union) > 0:
+	case len(inter) > 0:
+		s.unite(inter[0])
+		inter = inter[1:]
+	case len(diff) > 0:
+		s.unite(diff[0])
+		diff = diff[1:]
+	default:
+		return nil, chk.Err("at least one criterion (tags, box, plane, expr, union, inter or diff) must be given")
+	}
+	for _, r := range union {
+		s.unite(r)
+	}
+	for _, r := range inter {
+		s.intersect(r)
+	}
+	for _, r := range diff {
+		s.remove(r)
+	}
+	return
+}
+
+// all_verts_ok tells whether all vertices satisfy the given criterion
+func (o *Mesh) all_verts_ok(verts []int, ok func(vid int) bool) bool {
+	for _, vid := range verts {
+		if !ok(vid) {
+			return false
+		}
+	}
+	return true
+}
+
+// unite adds the entities in r
+func (o *msh_set) unite(r *msh_set) {
+	for k := range r.ids {
+		o.ids[k] = true
+	}
+}
+
+// intersect keeps the entities also in r
+func (o *msh_set) intersect(r *msh_set) {
+	for k := range o.ids {
+		if !r.ids[k] {
+			delete(o.ids, k)
+		}
+	}
+}
+
+// remove removes the entities in r
+func (o *msh_set) remove(r *msh_set) {
+	for k := range r.ids {
+		delete(o.ids, k)
+	}
+}
+
+// sorted returns the entities sorted by (cell/vertex) id and face id
+func (o *msh_set) sorted() (keys []CellFaceId) {
+	for k := range o.ids {
+		keys = append(keys, k)
+	}
+	sort.Sort(cellfaceids(keys))
+	return
+}
+
+// cellfaceids sorts CellFaceId by cell id and face id (vertices and cells: by Fid)
+type cellfaceids []CellFaceId
+
+func (o cellfaceids) Len() int      { return len(o) }
+func (o cellfaceids) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o cellfaceids) Less(i, j int) bool {
+	if o[i].C != nil && o[j].C != nil && o[i].C.Id != o[j].C.Id {
+		return o[i].C.Id < o[j].C.Id
+	}
+	return o[i].Fid < o[j].Fid
+}
+
+// boundary_faces returns the faces of solid cells that are not shared with other solid cells
+func (o *Mesh) boundary_faces() (bry map[CellFaceId]bool) {
+	count := make(map[string]int)
+	keys := make(map[CellFaceId]string)
+	for _, c := range o.Cells {
+		if !c.IsSolid || c.Shp == nil {
+			continue
+		}
+		for fid, lverts := range c.Shp.FaceLocalVerts {
+			gverts := make([]int, len(lverts))
+			for i, l := range lverts {
+				gverts[i] = c.Verts[l]
+			}
+			sort.Ints(gverts)
+			key := io.Sf("%v", gverts)
+			count[key]++
+			keys[CellFaceId{c, fid}] = key
+		}
+	}
+	bry = make(map[CellFaceId]bool)
+	for k, key := range keys {
+		if count[key] == 1 {
+			bry[k] = true
+		}
+	}
+	return
+}
+
+// tag_used tells whether tag is used by vertices, cells, faces or seams of mesh
+func (o *Mesh) tag_used(tag int) bool {
+	if _, ok := o.VertTag2verts[tag]; ok {
+		return true
+	}
+	if _, ok := o.CellTag2cells[tag]; ok {
+		return true
+	}
+	if _, ok := o.FaceTag2cells[tag]; ok {
+		return true
+	}
+	_, ok := o.SeamTag2cells[tag]
+	return ok
+}
@@ -0,0 +1,166 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Deformed returns a copy of this mesh with the coordinates of vertices replaced by x; e.g. the
+// current coordinates X + u in updated-Lagrangian analyses
+//  x -- [nverts][ndim] coordinates of vertices
+//  Note: the cells are shared with this mesh; only the vertices and limits are allocated
+func (o *Mesh) Deformed(x [][]float64) (m *Mesh) {
+	m = &Mesh{FnamePath: o.FnamePath, Ndim: o.Ndim, Cells: o.Cells}
+	m.Verts = make([]*Vert, len(o.Verts))
+	for i, v := range o.Verts {
+		m.Verts[i] = &Vert{Id: v.Id, Tag: v.Tag, C: make([]float64, len(v.C)), SharedBy: v.SharedBy}
+		copy(m.Verts[i].C, x[i])
+	}
+	m.Xmin, m.Xmax = math.Inf(1), math.Inf(-1)
+	m.Ymin, m.Ymax = math.Inf(1), math.Inf(-1)
+	if m.Ndim == 3 {
+		m.Zmin, m.Zmax = math.Inf(1), math.Inf(-1)
+	}
+	for _, v := range m.Verts {
+		m.Xmin = utl.Min(m.Xmin, v.C[0])
+		m.Xmax = utl.Max(m.Xmax, v.C[0])
+		m.Ymin = utl.Min(m.Ymin, v.C[1])
+		m.Ymax = utl.Max(m.Ymax, v.C[1])
+		if m.Ndim == 3 {
+			m.Zmin = utl.Min(m.Zmin, v.C[2])
+			m.Zmax = utl.Max(m.Zmax, v.C[2])
+		}
+	}
+	return
+}
+
+// Smooth performs the Laplacian smoothing of the vertices of solid cells to improve the quality of
+// distorted cells; e.g. in ALE analyses. At each iteration, the coordinates of each free vertex are
+// updated with x ← (1 - ω)・x + ω・x̄, where x̄ is the mean of the coordinates of the vertices
+// connected to it by edges of cells
+//  Input:
+//   niter -- number of iterations
+//   ω     -- relaxation factor: 0 < ω ≤ 1
+//  Output:
+//   moved -- sorted ids of vertices that have been moved
+//  Note: (1) vertices on the boundary of solids, vertices shared by cells with different tags (e.g.
+//            between materials), vertices of non-solid cells (e.g. rods and joints) and vertices
+//            with tags (e.g. with boundary conditions or output) are not moved
+//        (2) a vertex is moved only if the minimum Jacobian ratio of the cells sharing it does not
+//            decrease (smart Laplacian smoothing); thus, valid cells do not become inverted
+//        (3) only cells without mid-side vertices (e.g. tri3, qua4, tet4 and hex8) are supported
+func (o *Mesh) Smooth(niter int, ω float64) (moved []int, err error) {
+
+	// check
+	if ω <= 0 || ω > 1 {
+		return nil, chk.Err("relaxation factor of smoothing must be in ]0, 1]. ω = %g is invalid", ω)
+	}
+
+	// fixed vertices and neighbours connected by edges
+	fixed := make(map[int]bool)
+	ctags := make(map[int]int)
+	nbs := make(map[int]map[int]bool)
+	for _, c := range o.Cells {
+		if !c.IsSolid || c.Shp == nil || c.Shp.Nurbs != nil || c.Shp.Gndim != o.Ndim {
+			for _, v := range c.Verts {
+				fixed[v] = true
+			}
+			continue
+		}
+		if c.Shp.Nverts != c.Shp.BasicNverts {
+			return nil, chk.Err("smoothing of cells with mid-side vertices is not available. cell # %d of type %q is invalid", c.Id, c.Shp.Type)
+		}
+		for _, v := range c.Verts {
+			if tag, ok := ctags[v]; ok && tag != c.Tag {
+				fixed[v] = true
+			}
+			ctags[v] = c.Tag
+		}
+		for _, lverts := range c.Shp.FaceLocalVerts {
+			n := len(lverts)
+			for i := 0; i < n; i++ {
+				if n == 2 && i == 1 {
+					break // edge of 2D cell
+				}
+				a, b := c.Verts[lverts[i]], c.Verts[lverts[(i+1)%n]]
+				if nbs[a] == nil {
+					nbs[a] = make(map[int]bool)
+				}
+				if nbs[b] == nil {
+					nbs[b] = make(map[int]bool)
+				}
+				nbs[a][b] = true
+				nbs[b][a] = true
+			}
+		}
+	}
+	for k := range o.boundary_faces() {
+		for _, l := range k.C.Shp.FaceLocalVerts[k.Fid] {
+			fixed[k.C.Verts[l]] = true
+		}
+	}
+
+	// iterations
+	done := make(map[int]bool)
+	nd := o.Ndim
+	xold := make([]float64, nd)
+	for it := 0; it < niter; it++ {
+		nmoved := 0
+		for _, v := range o.Verts {
+			if fixed[v.Id] || v.Tag != 0 || len(nbs[v.Id]) == 0 {
+				continue
+			}
+			q0 := o.min_jratio(v.SharedBy)
+			copy(xold, v.C[:nd])
+			for i := 0; i < nd; i++ {
+				var xm float64
+				for w := range nbs[v.Id] {
+					xm += o.Verts[w].C[i]
+				}
+				xm /= float64(len(nbs[v.Id]))
+				v.C[i] = (1.0-ω)*v.C[i] + ω*xm
+			}
+			if o.min_jratio(v.SharedBy) < q0 {
+				copy(v.C, xold)
+				continue
+			}
+			for i := 0; i < nd; i++ {
+				if v.C[i] != xold[i] {
+					done[v.Id] = true
+					nmoved++
+					break
+				}
+			}
+		}
+		if nmoved == 0 {
+			break
+		}
+	}
+
+	// results
+	for v := range done {
+		moved = append(moved, v)
+	}
+	sort.Ints(moved)
+	return
+}
+
+// min_jratio returns the minimum Jacobian ratio among the given (solid) cells
+func (o *Mesh) min_jratio(cids []int) (jmin float64) {
+	jmin = math.Inf(1)
+	for _, cid := range cids {
+		c := o.Cells[cid]
+		if !c.IsSolid || c.Shp == nil {
+			continue
+		}
+		jmin = math.Min(jmin, o.cell_quality(c).Jratio)
+	}
+	return
+}
@@ -37,16 +37,17 @@ type Vert struct {
 type Cell struct {
 
 	// input data
-	Id     int    // id
-	Tag    int    // tag
-	Geo    int    // geometry type (gemlab code)
-	Type   string // geometry type (string)
-	Part   int    // partition id
-	Verts  []int  // vertices
-	FTags  []int  // edge (2D) or face (3D) tags
-	STags  []int  // seam tags (for 3D only; it is actually a 3D edge tag)
-	JlinId int    // joint line id
-	JsldId int    // joint solid id
+	Id      int    // id
+	Tag     int    // tag
+	Geo     int    // geometry type (gemlab code)
+	Type    string // geometry type (string)
+	Part    int    // partition id
+	Verts   []int  // vertices
+	FTags   []int  // edge (2D) or face (3D) tags
+	STags   []int  // seam tags (for 3D only; it is actually a 3D edge tag)
+	JlinId  int    // joint line id
+	JsldId  int    // joint solid id
+	JsldIds []int  // joint solid ids; for rods crossing many solids (JsldId is ignored if given)
 
 	// derived
 	Shp         *shp.Shape // shape structure
@@ -55,13 +56,14 @@ type Cell struct {
 	Disabled    bool       // cell is disabled (regardless inactive flag)
 
 	// specific problems data
-	IsBeam      bool         // simple beam element (no need for shape structure)
-	IsJoint     bool         // cell represents joint element
-	IsSolid     bool         // is 2D or 3D solid element; i.e. not "lin#", not "beam", not "joint"
-	SeepVerts   map[int]bool // local vertices ids of vertices on seepage faces
-	Extrap      bool         // needs to extrapolate internal values; e.g. because is connected to joint
-	JntConVerts []int        // vertices of solids connected to it
-	JntConCells []int        // cells connected to it
+	IsBeam      bool          // simple beam element (no need for shape structure)
+	IsJoint     bool          // cell represents joint element
+	IsSolid     bool          // is 2D or 3D solid element; i.e. not "lin#", not "beam", not "joint"
+	SeepVerts   map[int]bool  // local vertices ids of vertices on seepage faces
+	Extrap      bool          // needs to extrapolate internal values; e.g. because is connected to joint
+	JntConVerts []int         // vertices of solids connected to it
+	JntConCells []int         // cells connected to it
+	FaceSets    map[int][]int // face id => tags of sets of faces including this face; see SetData
 
 	// NURBS
 	Nrb  int   // index of NURBS patch to which this cell belongs to
@@ -103,6 +105,7 @@ type Mesh struct {
 	SeamTag2cells map[int][]CellSeamId // seam tag => set of cells
 	Ctype2cells   map[string][]*Cell   // cell type => set of cells
 	Part2cells    map[int][]*Cell      // partition number => set of cells
+	SetTags       map[int]bool         // tags of sets of vertices, cells or faces; see SetData
 
 	// NURBS
 	Nurbss   []gm.NurbsD   // all NURBS' data (read from file)
@@ -264,7 +267,7 @@ func (o *Mesh) CalcDerived(goroutineId int) (err error) {
 				err = chk.Err("cannot allocate \"shape\" structure for cell type = %q\n", c.Type)
 				return
 			}
-			if c.Type[:3] != "lin" {
+			if c.Type[:3] != "lin" && c.Shp.Gndim == o.Ndim { // surfaces in 3D (e.g. membranes) are not solids
 				c.IsSolid = true
 			}
 		}
@@ -355,7 +358,21 @@ func (o *Cell) String() string {
 		}
 		l += io.Sf("%d", x)
 	}
-	l += "] }"
+	l += "]"
+	if o.Type == "joint" {
+		l += io.Sf(", \"jlinid\":%d, \"jsldid\":%d", o.JlinId, o.JsldId)
+		if len(o.JsldIds) > 0 {
+			l += ", \"jsldids\":["
+			for i, x := range o.JsldIds {
+				if i > 0 {
+					l += ", "
+				}
+				l += io.Sf("%d", x)
+			}
+			l += "]"
+		}
+	}
+	l += " }"
 	return l
 }
 
@@ -414,6 +431,7 @@ func (o *Cell) GetSimilar(lbb bool) (newcell *Cell) {
 	newcell.STags = o.STags
 	newcell.JlinId = o.JlinId
 	newcell.JsldId = o.JsldId
+	newcell.JsldIds = o.JsldIds
 
 	// new cell type
 	ctype := o.Shp.Type
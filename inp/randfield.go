@@ -0,0 +1,331 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/cpmech/gofem/mdl/conduct"
+	"github.com/cpmech/gofem/mdl/porous"
+	"github.com/cpmech/gofem/mdl/solid"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// RandFieldData holds data for generating a random field of a material parameter
+//  Note: (1) the values are generated at the centroids of cells; i.e. each cell gets its own copy
+//            of the material with the random value of the parameter
+//        (2) the mean value of the field is the value of the parameter in the materials file
+//        (3) the field is stationary with the exponential (Markov) correlation function:
+//              ρ(Δx) = exp(-2 √(Σ (Δx_i/θ_i)²))
+//            where θ_i are the scales of fluctuation (correlation lengths) along each direction
+//        (4) the underlying Gaussian field is generated by the (discrete) Karhunen-Loève expansion;
+//            i.e. using the eigenvalues and eigenvectors of the correlation matrix of centroids
+//        (5) only simulations with one region are supported
+//        (6) only parameters of "sld" and "cnd" materials can be randomised; materials depending on
+//            them (e.g. "por" and "trm") are copied as well
+type RandFieldData struct {
+	Mat    string    `json:"mat"`    // name of material; e.g. "soil1"
+	Prm    string    `json:"prm"`    // name of parameter; e.g. "E", "c", "phi", "su", "kl"
+	Dist   string    `json:"dist"`   // distribution: "normal" or "lognormal". default = "lognormal"
+	Cov    float64   `json:"cov"`    // coefficient of variation = σ / μ
+	Theta  []float64 `json:"theta"`  // [ndim] scales of fluctuation along x-y-z; a single value => isotropic
+	Nterms int       `json:"nterms"` // number of terms in KL expansion. default (≤ 0) => all
+	Seed   int       `json:"seed"`   // seed for random numbers generator. default = 0 => time is used
+	Tags   []int     `json:"tags"`   // tags of cells with random values. default (empty) => all with material
+}
+
+// GenRandField generates the values of a random field at points X
+//  Input:
+//   dat  -- random field data
+//   mean -- mean value of field
+//   X    -- [npts][ndim] coordinates of points
+//  Output:
+//   vals -- [npts] values of field
+func GenRandField(dat *RandFieldData, mean float64, X [][]float64) (vals []float64, err error) {
+
+	// check
+	npts := len(X)
+	if npts < 1 {
+		return
+	}
+	if len(dat.Theta) < 1 {
+		return nil, chk.Err("scale of fluctuation (theta) of random field of parameter %q must be given", dat.Prm)
+	}
+	ndim := len(X[0])
+	θ := make([]float64, ndim)
+	for i := 0; i < ndim; i++ {
+		θ[i] = dat.Theta[0]
+		if i < len(dat.Theta) {
+			θ[i] = dat.Theta[i]
+		}
+		if θ[i] <= 0 {
+			return nil, chk.Err("scales of fluctuation of random field must be positive. theta = %v is invalid", dat.Theta)
+		}
+	}
+
+	// parameters of distribution
+	var μ, σ float64
+	lognormal := true
+	switch dat.Dist {
+	case "", "lognormal":
+		if mean <= 0 {
+			return nil, chk.Err("mean value of lognormal random field of parameter %q must be positive. %g is invalid", dat.Prm, mean)
+		}
+		σ = math.Sqrt(math.Log(1.0 + dat.Cov*dat.Cov))
+		μ = math.Log(mean) - σ*σ/2.0
+	case "normal":
+		lognormal = false
+		σ = dat.Cov * math.Abs(mean)
+		μ = mean
+	default:
+		return nil, chk.Err("distribution %q of random field is not available; options are \"normal\" and \"lognormal\"", dat.Dist)
+	}
+
+	// correlation matrix
+	A := la.MatAlloc(npts, npts)
+	for i := 0; i < npts; i++ {
+		for j := i; j < npts; j++ {
+			var τ float64
+			for k := 0; k < ndim; k++ {
+				τ += math.Pow((X[i][k]-X[j][k])/θ[k], 2.0)
+			}
+			A[i][j] = math.Exp(-2.0 * math.Sqrt(τ))
+			A[j][i] = A[i][j]
+		}
+	}
+
+	// eigenvalues and eigenvectors
+	Q := la.MatAlloc(npts, npts)
+	λ := make([]float64, npts)
+	err = la.Jacobi(Q, λ, A)
+	if err != nil {
+		return nil, chk.Err("eigen-decomposition of correlation matrix failed:\n%v", err)
+	}
+	idx := make([]int, npts) // indices of eigenvalues in decreasing order
+	for i := 0; i < npts; i++ {
+		idx[i] = i
+		for j := i; j > 0 && λ[idx[j]] > λ[idx[j-1]]; j-- {
+			idx[j], idx[j-1] = idx[j-1], idx[j]
+		}
+	}
+	nterms := npts
+	if dat.Nterms > 0 && dat.Nterms < npts {
+		nterms = dat.Nterms
+	}
+
+	// random numbers
+	seed := int64(dat.Seed)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	ξ := make([]float64, nterms)
+	for k := 0; k < nterms; k++ {
+		ξ[k] = rng.NormFloat64()
+	}
+
+	// KL expansion
+	vals = make([]float64, npts)
+	for i := 0; i < npts; i++ {
+		var g float64
+		for k := 0; k < nterms; k++ {
+			j := idx[k]
+			if λ[j] > 0 {
+				g += math.Sqrt(λ[j]) * Q[i][j] * ξ[k]
+			}
+		}
+		vals[i] = μ + σ*g
+		if lognormal {
+			vals[i] = math.Exp(vals[i])
+		}
+	}
+	return
+}
+
+// SetRandFields generates random fields for the cells of all regions and allocates the materials
+// of cells with random values of parameters
+func (o *Simulation) SetRandFields() (err error) {
+	if len(o.Regions) != 1 {
+		return chk.Err("random fields are only available in simulations with one region")
+	}
+	o.MatModels.CellMats = make(map[int]map[string]*Material)
+	for _, dat := range o.RandFields {
+
+		// material
+		mat := o.MatModels.Get(dat.Mat)
+		if mat == nil {
+			return chk.Err("cannot find material %q of random field", dat.Mat)
+		}
+		if mat.Type != "sld" && mat.Type != "cnd" {
+			return chk.Err("random fields are only available for parameters of \"sld\" and \"cnd\" materials. material %q has type %q", mat.Name, mat.Type)
+		}
+		prm := mat.Prms.Find(dat.Prm)
+		if prm == nil {
+			return chk.Err("cannot find parameter %q of material %q for random field", dat.Prm, dat.Mat)
+		}
+		tags := make(map[int]bool)
+		for _, tag := range dat.Tags {
+			tags[tag] = true
+		}
+
+		// cells and centroids
+		reg := o.Regions[0]
+		var cells []*Cell
+		var X [][]float64
+		for _, c := range reg.Msh.Cells {
+			if len(tags) > 0 && !tags[c.Tag] {
+				continue
+			}
+			edat := reg.Etag2data(c.Tag)
+			if edat == nil || !o.MatModels.uses(edat.Mat, dat.Mat) {
+				continue
+			}
+			x := make([]float64, reg.Msh.Ndim)
+			for _, v := range c.Verts {
+				for i := 0; i < reg.Msh.Ndim; i++ {
+					x[i] += reg.Msh.Verts[v].C[i] / float64(len(c.Verts))
+				}
+			}
+			cells = append(cells, c)
+			X = append(X, x)
+		}
+
+		// generate field and set parameters of cells
+		vals, err := GenRandField(dat, prm.V, X)
+		if err != nil {
+			return chk.Err("cannot generate random field of parameter %q of material %q:\n%v", dat.Prm, dat.Mat, err)
+		}
+		for i, c := range cells {
+			o.MatModels.cell_mat(c.Id, mat).Prms.Find(dat.Prm).V = vals[i]
+		}
+	}
+
+	// initialise models of cells
+	for cid, mats := range o.MatModels.CellMats {
+		err = o.MatModels.init_cell_mats(mats, o.Ndim, o.Data.Pstress, o.Grav0)
+		if err != nil {
+			return chk.Err("cannot initialise models of cell # %d with random parameters:\n%v", cid, err)
+		}
+	}
+	return
+}
+
+// GetCell returns the material of a cell; i.e. a copy of the material with random values of
+// parameters if any random field is defined for the cell; otherwise, returns Get(name)
+//  Note: returns nil if not found
+func (o MatDb) GetCell(name string, cell *Cell) *Material {
+	if mats, ok := o.CellMats[cell.Id]; ok {
+		if mat, found := mats[name]; found {
+			return mat
+		}
+	}
+	return o.Get(name)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// uses returns whether material (name) is or depends on material (dep)
+func (o MatDb) uses(name, dep string) bool {
+	if name == dep {
+		return true
+	}
+	if mat := o.Get(name); mat != nil {
+		for _, d := range mat.Deps {
+			if d == dep {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cell_mat returns the copy of material of cell; allocating it if needed
+func (o *MatDb) cell_mat(cid int, mat *Material) *Material {
+	mats, ok := o.CellMats[cid]
+	if !ok {
+		mats = make(map[string]*Material)
+		o.CellMats[cid] = mats
+	}
+	if m, found := mats[mat.Name]; found {
+		return m
+	}
+	m := *mat
+	m.Prms = make(fun.Prms, len(mat.Prms))
+	for i, prm := range mat.Prms {
+		p := *prm
+		m.Prms[i] = &p
+	}
+	mats[mat.Name] = &m
+	return &m
+}
+
+// init_cell_mats initialises the models of the copies of materials of a cell and creates copies of
+// materials depending on them
+func (o *MatDb) init_cell_mats(mats map[string]*Material, ndim int, pstress bool, grav float64) (err error) {
+
+	// solids and conductivities
+	for _, m := range mats {
+		switch m.Type {
+		case "sld":
+			m.Sld, err = solid.New(m.Model)
+			if err != nil {
+				return
+			}
+			err = m.Sld.Init(ndim, pstress, m.Prms)
+			if err != nil {
+				return
+			}
+			m.SldPrms = m.Prms
+		case "cnd":
+			m.Cnd, err = conduct.New(m.Model)
+			if err != nil {
+				return
+			}
+			err = m.Cnd.Init(m.Prms)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	// dependent materials
+	for _, orig := range o.Materials {
+		if orig.Type != "trm" && orig.Type != "por" {
+			continue
+		}
+		var deps []*Material
+		for _, name := range orig.Deps {
+			if m, ok := mats[name]; ok {
+				deps = append(deps, m)
+			}
+		}
+		if len(deps) == 0 {
+			continue
+		}
+		m := *orig
+		for _, dep := range deps {
+			switch dep.Type {
+			case "sld":
+				m.Sld = dep.Sld
+				m.SldPrms = dep.Prms
+			case "cnd":
+				m.Cnd = dep.Cnd
+			}
+		}
+		if m.Type == "por" {
+			m.Por = new(porous.Model)
+			err = m.Por.Init(m.Prms, m.Cnd, m.Lrm, m.Liq, m.Gas, grav)
+			if err != nil {
+				return
+			}
+		}
+		mats[m.Name] = &m
+	}
+	return
+}
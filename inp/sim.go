@@ -24,10 +24,13 @@ import (
 type Data struct {
 
 	// global information
-	Desc    string `json:"desc"`    // description of simulation
-	Matfile string `json:"matfile"` // materials file path
-	DirOut  string `json:"dirout"`  // directory for output; e.g. /tmp/gofem
-	Encoder string `json:"encoder"` // encoder name; e.g. "gob" "json" "xml"
+	Desc     string `json:"desc"`     // description of simulation
+	Matfile  string `json:"matfile"`  // materials file path
+	DirOut   string `json:"dirout"`   // directory for output; e.g. /tmp/gofem
+	Encoder  string `json:"encoder"`  // encoder name; e.g. "gob" "json" "xml"
+	Compress string `json:"compress"` // compression of result files (nodes and elements): "" => none or "gzip"
+	Checksum bool   `json:"checksum"` // record checksums (SHA-256) of result files in the summary; verified when reading
+	InMemory bool   `json:"inmemory"` // keep result files (summary, nodes and elements) in memory instead of disk; e.g. for tests. see fem.InMemory
 
 	// problem definition and options
 	Steady    bool    `json:"steady"`    // steady simulation
@@ -41,6 +44,16 @@ type Data struct {
 	GasMat    string  `json:"gas"`       // name of gas material
 	ListBcs   bool    `json:"listbcs"`   // list boundary conditions
 	WriteSmat bool    `json:"writesmat"` // writes /tmp/gofem_Kb.smat file for debugging global Jacobian matrix. The simulation will be stopped.
+	HgCheck   float64 `json:"hgcheck"`   // post-run check: report one-point qua4/hex8 elements with hourglass ratio larger than this value; 0 => no check
+	WaterBal  bool    `json:"waterbal"`  // per-step accounting of liquid mass stored vs. inflow in transient stages; see fem.WaterBalance
+	WaterTol  float64 `json:"watertol"`  // water balance: relative errors larger than this value are flagged; default = 1e-2
+	Serve     string  `json:"serve"`     // address of live monitoring HTTP server; e.g. "localhost:8080" or ":8080"; "" => no server
+	Monitor   []int   `json:"monitor"`   // ids of vertices (monitor points) whose dofs are served by the live monitoring server
+	Envelopes bool    `json:"envelopes"` // min/max of internal forces of structural members (beams, rods, linings and anchors) over all steps and stages; see fem.MemberEnvelopes
+	SoA       bool    `json:"soa"`       // store the states at integration points of each element in contiguous arrays (structure-of-arrays) for better cache locality
+
+	// checks
+	Quality *QualityData `json:"quality"` // check the quality of the solid cells of meshes before running; nil => no check
 }
 
 // LinSolData holds data for linear solvers
@@ -51,6 +64,15 @@ type LinSolData struct {
 	Timing    bool   `json:"timing"`    // show timing statistics
 	Ordering  string `json:"ordering"`  // ordering scheme
 	Scaling   string `json:"scaling"`   // scaling scheme
+	Renum     string `json:"renum"`     // renumbering of equations before factorisation: "" (none), "rcm" (bandwidth) or "nd" (fill-in); see fem.Domain.Renumber
+
+	// mixed precision: single precision factorisation with iterative refinement; see fem.MixedSolver
+	Mixed   bool    `json:"mixed"`   // use mixed-precision solver (serial and symmetric only; 2D or banded 3D meshes)
+	RefTol  float64 `json:"reftol"`  // tolerance for iterative refinement: ‖r‖∞ ≤ RefTol ‖b‖∞
+	RefNmax int     `json:"refnmax"` // max number of refinement iterations
+
+	// complex systems; see fem.CplxSystem
+	CplxBlock bool `json:"cplxblock"` // solve complex systems as real 2x2 block equivalent systems
 }
 
 // SolverData holds FEM solver data
@@ -67,6 +89,24 @@ type SolverData struct {
 	NdvgMax int     `json:"ndvgmax"` // max number of continued divergence
 	CteTg   bool    `json:"ctetg"`   // use constant tangent (modified Newton) during iterations
 	ShowR   bool    `json:"showr"`   // show residual
+	Determ  bool    `json:"determ"`  // deterministic parallel runs: reductions are carried out in the order of processors; see fem.all_reduce_sum
+	Device  string  `json:"device"`  // experimental: evaluate kernels of batches of identical solid elements on a device: "cpu" or "opencl" (e.g. GPU). empty => none; see solid.NewBatches
+
+	// staggered solution of hydro-mechanical (u-p) problems with the fixed-stress split; or of
+	// phase-field fracture (u-d)
+	Split     bool    `json:"split"`     // solve flow and mechanics sequentially instead of monolithically; see fem.Splitting
+	SplitBeta float64 `json:"splitbeta"` // fixed-stress coefficient β multiplying sl/K_dr
+	SplitTol  float64 `json:"splittol"`  // tolerance for the convergence of the outer loop
+	SplitNmax int     `json:"splitnmax"` // max number of outer iterations
+
+	// essential boundary conditions / constraints
+	Constraints string  `json:"constraints"` // strategy: "lagrange" (multipliers), "penalty" or "elim" (elimination; for iterative linear solvers). default = "lagrange"
+	Penalty     float64 `json:"penalty"`     // penalty coefficient with "penalty"; must be much larger than the stiffness coefficients
+
+	// diagnostics of Jacobian matrix
+	Diagnose bool    `json:"diagnose"` // diagnose Kb after assembly (serial runs): condition number estimate, zero/negative pivots and likely causes
+	DiagNit  int     `json:"diagnit"`  // number of Lanczos iterations to estimate the condition number of Kb
+	DiagCmax float64 `json:"diagcmax"` // condition numbers larger than this value indicate a nearly singular Kb
 
 	// Richardson's extrapolation
 	REnogus  bool    `json:"renogus"`  // Richardson extrapolation: no Gustafsson's step control
@@ -103,18 +143,31 @@ type SolverData struct {
 type ElemData struct {
 
 	// input data
-	Tag   int    `json:"tag"`   // tag of element
-	Mat   string `json:"mat"`   // material name
-	Type  string `json:"type"`  // type of element. ex: u, p, up, rod, beam, rjoint
-	Nip   int    `json:"nip"`   // number of integration points; 0 => use default
-	Nipf  int    `json:"nipf"`  // number of integration points on face; 0 => use default
-	Extra string `json:"extra"` // extra flags (in keycode format). ex: "!thick:0.2 !nip:4"
-	Inact bool   `json:"inact"` // whether element starts inactive or not
+	Tag   int        `json:"tag"`   // tag of element
+	Mat   string     `json:"mat"`   // material name
+	Type  string     `json:"type"`  // type of element. ex: u, p, up, rod, beam, rjoint
+	Nip   int        `json:"nip"`   // number of integration points; 0 => use default or Rule
+	Nipf  int        `json:"nipf"`  // number of integration points on face; 0 => use default
+	Rule  string     `json:"rule"`  // integration rule: "" or "full" => default; "reduced" => reduced integration
+	Ips   []*IpsData `json:"ips"`   // integration rules of groups of cells; overriding Nip, Nipf and Rule
+	Extra string     `json:"extra"` // extra flags (in keycode format). ex: "!thick:0.2 !nip:4"
+	Inact bool       `json:"inact"` // whether element starts inactive or not
 
 	// auxiliary/internal
 	Lbb bool // LBB element
 }
 
+// IpsData holds the integration rule of a group of cells with the same tag
+//  Note: (1) Nip and Nipf take precedence over Rule; e.g. {"rule":"reduced", "nipf":3}
+//        (2) the rule refers to the shape of the cell; elements integrating over other shapes
+//            (e.g. interfaces and beam-joints) must use Nip instead
+type IpsData struct {
+	Cids []int  `json:"cids"` // ids of cells
+	Nip  int    `json:"nip"`  // number of integration points; 0 => use default or Rule
+	Nipf int    `json:"nipf"` // number of integration points on face; 0 => use default
+	Rule string `json:"rule"` // integration rule: "" or "full" => default; "reduced" => reduced integration
+}
+
 // Region holds region data
 type Region struct {
 
@@ -123,6 +176,8 @@ type Region struct {
 	Mshfile   string      `json:"mshfile"`   // file path of file with mesh data
 	ElemsData []*ElemData `json:"elemsdata"` // list of elements data
 	AbsPath   bool        `json:"abspath"`   // mesh filename is given in absolute path
+	Sets      []*SetData  `json:"sets"`      // named sets of vertices, cells or faces with new tags
+	Imperf    *ImperfData `json:"imperf"`    // geometric imperfections seeded by perturbing the coordinates of vertices; may be nil
 
 	// derived
 	Msh *Mesh // the mesh
@@ -168,6 +223,15 @@ type TimeControl struct {
 	DtFcn  string  `json:"dtfcn"`  // time step size (function name)
 	DtoFcn string  `json:"dtofcn"` // time step size for output (function name)
 
+	// steady-state detection: stop transient stage before tf
+	SteadyTol  float64  `json:"steadytol"`  // tolerance of steady-state criterion; ≤ 0 => run until tf
+	SteadyCrit string   `json:"steadycrit"` // criterion: "rate" => max |ΔY/Δt|; "excess" => max |pl - pl_hydrostatic|
+	SteadyKeys []string `json:"steadykeys"` // keys of dofs checked with "rate"; e.g. ["pl"]; default = all dofs
+	SteadyNst  int      `json:"steadynst"`  // number of consecutive steps satisfying criterion; default = 1
+
+	// wall-clock budget; e.g. for queue systems
+	WallMax float64 `json:"wallmax"` // maximum wall-clock time of stage in seconds; when exceeded, the state is saved and the simulation is stopped; ≤ 0 => no limit
+
 	// derived
 	DtFunc  fun.Func // time step function
 	DtoFunc fun.Func // output time step function
@@ -202,7 +266,454 @@ type IniFcnData struct {
 type IniImportRes struct {
 	Dir    string `json:"dir"`    // output directory with previous simulation files
 	Fnk    string `json:"fnk"`    // previous simulation file name key (without .sim)
-	ResetU bool   `json:"resetu"` // reset/zero u (displacements)
+	ResetU bool   `json:"resetu"` // reset/zero u (displacements and rotations)
+}
+
+// IniInterpRes holds definitions for setting the initial state by interpolating the results of a
+// previous simulation with a different (e.g. coarser) mesh
+type IniInterpRes struct {
+	Sim    string   `json:"sim"`    // previous simulation (.sim) filename with full path
+	Stage  int      `json:"stage"`  // index of stage of previous simulation corresponding to Tidx. default = 0
+	Tidx   int      `json:"tidx"`   // output time index of previous simulation. default (≤ 0) => last one
+	Dofs   []string `json:"dofs"`   // dofs to be interpolated; e.g. ["ux", "uy", "pl"]. default (empty) => all
+	NoIvs  bool     `json:"noivs"`  // do not interpolate stresses at integration points
+	ResetU bool     `json:"resetu"` // reset/zero u (displacements and rotations)
+}
+
+// ErosionCrit holds an erosion criterion: an element is eroded if any integration point value
+// corresponding to Key exceeds Max.
+//  Key -- key of integration point value; e.g. "alpha" (internal variable named by the model; see
+//         solid.IntVarNames) or "sig1" which is the maximum principal stress computed from the stress keys
+type ErosionCrit struct {
+	Key string  `json:"key"` // key of integration point value
+	Max float64 `json:"max"` // maximum value
+}
+
+// ErosionData holds data for deleting (eroding) elements during a stage
+type ErosionData struct {
+	Tags  []int          `json:"tags"`  // tags of elements that can be eroded
+	Crits []*ErosionCrit `json:"crits"` // erosion criteria; any of them leads to erosion
+	Nrel  int            `json:"nrel"`  // number of time steps to release the forces of eroded elements. default = 1
+}
+
+// CrackData holds data for the propagation of discrete cracks (2D): after each time step, the mesh
+// is split along the edges between solids where the traction reaches the strength and interface
+// (cohesive) elements are inserted; see fem.Cracking
+//  Note: the tractions on edges are computed with the mean stresses of the two solids sharing them
+type CrackData struct {
+	Tags []int   `json:"tags"` // tags of solids that can be split; empty => all solids
+	Itag int     `json:"itag"` // tag of new interface cells; must be given in "elemsdata" with type "interface"
+	Ft   float64 `json:"ft"`   // tensile strength: edges are split when the normal traction reaches ft
+	Ss   float64 `json:"ss"`   // shear strength: edges are also split when the shear traction reaches ss. 0 => not used
+	Nmax int     `json:"nmax"` // max number of edges split after each time step. default = 1
+}
+
+// TrackData holds data for the tracking of embedded cracks (2D): after each time step, cracks are
+// initiated in solids with extra = "!xcrk:1" where the nonlocal maximum principal stress reaches the
+// tensile strength of their cohesive law; see fem.Tracking
+//  Note: the nonlocal stresses are weighted averages of the stresses at integration points within
+//        the radius; the cracks propagate from their tips perpendicularly to the nonlocal maximum
+//        principal stress
+type TrackData struct {
+	Tags   []int   `json:"tags"`   // tags of solids where cracks can be initiated; empty => all solids
+	Radius float64 `json:"radius"` // radius of nonlocal averaging. 0 => twice the mean size of elements
+	Nmax   int     `json:"nmax"`   // max number of elements cracked after each time step. default = 1
+}
+
+// AleData holds data for the updated-Lagrangian analysis of solids with ALE (arbitrary
+// Lagrangian-Eulerian) mesh smoothing: after each time step, the coordinates of elements are
+// updated and, if the quality of cells is poor, the mesh is smoothed and the solution and states at
+// integration points are remapped; see fem.Ale
+//  Note: the quality is measured by the Jacobian ratio; see QualityData
+type AleData struct {
+	Qmin  float64 `json:"qmin"`  // min Jacobian ratio of cells triggering the smoothing. default = 0.3
+	Niter int     `json:"niter"` // number of iterations of Laplacian smoothing. default = 10
+	Omega float64 `json:"omega"` // relaxation factor of Laplacian smoothing. default = 0.5
+}
+
+// DynCtrlData holds data for mass scaling and selective time integration of the elements with
+// given tags (regions) in transient analyses
+//  Note: (1) the "dyn" scheme includes the inertia (with effective density Mscale・ρ) and damping
+//            terms; the "qsta" scheme ignores them; i.e. the region is treated as quasi-static
+//        (2) the elements with tags not listed use the "dyn" scheme with Mscale = 1
+type DynCtrlData struct {
+	Tags   []int   `json:"tags"`   // tags of elements
+	Scheme string  `json:"scheme"` // "dyn" or "qsta". default = "dyn"
+	Mscale float64 `json:"mscale"` // mass scaling factor. default = 1
+}
+
+// PrestressData holds data for the stressing of anchors, tiebacks and struts: the axial force of
+// the rods with given tags is prescribed (stressing against the current state of the surrounding
+// soil) until the anchorage is locked at time Tlock; then, the rods respond to further
+// deformations starting from the locked-in force
+//  Note: (1) tension is positive; e.g. negative forces must be given to preload struts
+//        (2) the force is multiplied by the function Func at each time (if given); e.g. to ramp
+//            the prestress during stressing
+//        (3) if Tlock is not given, the force is prescribed during the whole stage
+type PrestressData struct {
+	Tags  []int   `json:"tags"`  // tags of rod elements
+	Force float64 `json:"force"` // axial force
+	Func  string  `json:"func"`  // [optional] function multiplying Force
+	Tlock float64 `json:"tlock"` // [optional] time of locking of anchorage
+}
+
+// DrawdownData holds data for lowering (or raising) the groundwater table over a region: the
+// pore-liquid pressures (pl) at the nodes of the cells with given tags are prescribed according to
+//   pl(t) = pl0 + m(t)・(plw - pl0)
+// where pl0 is the pressure at the beginning of the stage, plw is the hydrostatic pressure below
+// the new phreatic line and m(t) is the multiplier given by Mult
+//  Note: (1) the new phreatic line is given by the points in Line {x, zw}, interpolated linearly
+//            along x and constant beyond the first and last points; or by the function Fcn
+//            with zw = Fcn(0, x) where x are the (horizontal) coordinates of nodes
+//        (2) if Mult is not given, m(t) = 1; i.e. the change is sudden. Ramp functions should be
+//            preferred in order to apply the unbalanced forces due to the change of effective
+//            stresses gradually
+//        (3) the pressures above the phreatic line are negative (suction) unless NoSuc is true
+//        (4) boundary conditions (facebcs and nodebcs) of the same nodes replace the drawdown
+type DrawdownData struct {
+	Tags  []int       `json:"tags"`  // tags of cells in region
+	Line  [][]float64 `json:"line"`  // points {x, zw} on new phreatic line
+	Fcn   string      `json:"fcn"`   // or function zw(x) defining the new phreatic line
+	Mult  string      `json:"mult"`  // [optional] multiplier m(t) of the change of pressures
+	NoSuc bool        `json:"nosuc"` // set zero pressures above the phreatic line
+}
+
+// CentrifugeData holds data for the simulation of geotechnical centrifuge tests at model scale: the
+// elements are subjected to the centrifugal acceleration ω²・r pointing away from the axis of
+// rotation, where r is the distance to the axis
+//  Note: (1) the angular velocity is found from the g-level at the reference radius; i.e.
+//            ω² = Ng・g / Rref; thus the acceleration at Rref is Ng・g
+//        (2) the acceleration is multiplied by the function Mult (if given); e.g. the spin-up
+//        (3) in 2D, the axis is perpendicular to the plane; in 3D, the direction Dir is required
+//        (4) the acceleration is evaluated at the current coordinates (X + u) of integration
+//            points of solid elements if Updated is true; e.g. with large displacements
+//        (5) the acceleration is added to gravity ("g" element conditions), which is usually not
+//            given in centrifuge simulations
+type CentrifugeData struct {
+	Tags    []int     `json:"tags"`    // tags of cells; empty => all
+	Axis    []float64 `json:"axis"`    // [ndim] point on the axis of rotation
+	Dir     []float64 `json:"dir"`     // [3] direction of the axis of rotation (3D only)
+	Ng      float64   `json:"ng"`      // g-level at reference radius
+	Rref    float64   `json:"rref"`    // reference radius; e.g. distance from axis to the mid-height of the model
+	G       float64   `json:"g"`       // acceleration of gravity. default = gravity from stage #0 or 10
+	Mult    string    `json:"mult"`    // [optional] multiplier m(t) of the acceleration
+	Updated bool      `json:"updated"` // evaluate the acceleration at the current coordinates of ips
+}
+
+// PipingData holds data for checking the hydraulic gradients against the critical gradient during
+// a stage; e.g. for the verification of internal erosion (piping) and heave at excavations and dams
+//  Note: (1) the hydraulic gradient is the vector i = -∇h with the total head h = z + pl/(ρL・g);
+//            its magnitude |i| is averaged over each cell and compared with the critical gradient
+//        (2) the exit gradient of faces is ie = i・n (n pointing outwards) computed with the gradient
+//            of the adjacent cell; i.e. it is positive if the liquid exits across the face
+//        (3) the critical gradient icr = (1 - nf0)・(ρS0 - ρL)/ρL is computed with the parameters of
+//            the porous model of each element unless Icrit is given
+type PipingData struct {
+	Tags  []int   `json:"tags"`  // tags of checked cells; empty => all cells with liquid pressure
+	Ftags []int   `json:"ftags"` // tags of exit faces; e.g. the bottom of excavation
+	Icrit float64 `json:"icrit"` // critical hydraulic gradient; 0 => computed with the porous models
+	Fs    float64 `json:"fs"`    // required factor of safety; steps with i > icr/Fs are flagged. default = 1
+}
+
+// RelaxationData holds data for the convergence-confinement (β) method of tunnelling: the forces
+// that the excavated elements exert on the surrounding ground are released in two phases; first,
+// the fraction β is released until the installation of the lining at time Tlin; then, the
+// remaining fraction (1-β) is released until the end of the stage with the lining in place
+//  Note: (1) both excavated and lining elements must be active in the stage; the lining elements
+//            do not contribute to the system until Tlin and are installed stress-free; i.e. they
+//            only respond to deformations after Tlin
+//        (2) the release is linear in time: from 0 to β in [0, Tlin] and from β to 1 in [Tlin, tf]
+//        (3) nodes attached only to excavated or not-yet-installed lining elements are held in
+//            place by unit springs
+//        (4) only serial runs are supported
+type RelaxationData struct {
+	Tags   []int   `json:"tags"`   // tags of excavated elements
+	Lining []int   `json:"lining"` // tags of lining elements
+	Beta   float64 `json:"beta"`   // relaxation factor: fraction of forces released before the installation of the lining
+	Tlin   float64 `json:"tlin"`   // time of installation of the lining
+}
+
+// ContractionData holds data for the volume-loss controlled excavation of tunnels (2D): the
+// displacements of the nodes on the tunnel boundary (faces with given tags) are prescribed in
+// order to contract the cross-section of radius R by the volume loss Vl; i.e. the ratio between
+// the lost area and the area of the excavated cross-section (π・R²)
+//  Note: (1) the contracted section is a circle with radius R' = R・sqrt(1 - Vl), either with the
+//            same centre ("uniform" pattern; uniform radial displacements) or touching the original
+//            circle at the invert ("invert" pattern; the invert does not move and the crown moves
+//            the most; as observed in TBM tunnelling)
+//        (2) the displacements are multiplied by m(t) given by Mult (if given); thus, the target
+//            volume loss is reached when m = 1
+//        (3) if R is not given, it is computed as the mean distance between nodes and centre
+type ContractionData struct {
+	Tags   []int     `json:"tags"`   // face (edge) tags of tunnel boundary
+	Centre []float64 `json:"centre"` // centre of tunnel cross-section
+	R      float64   `json:"r"`      // [optional] radius of tunnel
+	Vl     float64   `json:"vl"`     // volume loss
+	Mode   string    `json:"mode"`   // displacement pattern: "uniform" or "invert". default = "uniform"
+	Mult   string    `json:"mult"`   // [optional] multiplier m(t) of displacements
+}
+
+// MmsData holds data for the verification of elements with the method of manufactured solutions:
+// the exact solution of each dof (key) is given by a function u(t,x) and the corresponding source
+// terms are computed numerically and applied to all elements; the exact solution is prescribed at
+// all nodes on the boundary of the mesh
+//  Note: (1) the source terms are computed by central differences of the exact solution and the
+//            parameters of the material models (see fem.Mms)
+//        (2) the errors are measured in the L2 norm; thus, a sequence of meshes (one simulation for
+//            each mesh) can be used to compute convergence rates
+type MmsData struct {
+	Keys  []string `json:"keys"`  // dof keys; e.g. ["ux", "uy"] or ["u"]
+	Fcns  []string `json:"fcns"`  // exact solution u(t,x) of each key (from functions database)
+	Delta float64  `json:"delta"` // [optional] relative step of numerical derivatives. default = 1e-4
+	NoSrc bool     `json:"nosrc"` // do not apply source terms; e.g. in patch tests with linear fields
+}
+
+// CycleJumpData holds data for the cycle-jump acceleration of quasi-static cyclic loading; i.e.
+// some cycles are computed explicitly and the evolution of the state is extrapolated over skipped
+// cycles. The total number of cycles is Tf / Period
+//  Note: (1) the time functions of loads and prescribed values must be periodic with Period and
+//            the time step must divide Period
+//        (2) the number of cycles skipped in each jump is limited by Njump and by the maximum
+//            relative change (Tol) of the state variables (Y and internal variables) in one jump
+//        (3) Nexpl ≥ 2 cycles are computed explicitly after each jump (and before the end of the
+//            stage): the first re-establishes equilibrium and the last two give the rate of change
+type CycleJumpData struct {
+	Period float64 `json:"period"` // period of loading cycles
+	Nexpl  int     `json:"nexpl"`  // number of cycles computed explicitly between jumps. default = 2
+	Njump  int     `json:"njump"`  // maximum number of cycles skipped in one jump. default = 100
+	Tol    float64 `json:"tol"`    // maximum relative change of state variables in one jump. default = 0.05
+}
+
+// SubcycleData holds data for subcycling in coupled problems solved by the staggered solution (see
+// SolverData.Split); i.e. different time steps for the diffusive fields (e.g. pl and temp) and for
+// mechanics
+//  Note: (1) the time step of the stage (Control) is the large step; the subcycled field is solved
+//            with Nsub substeps of Δt/Nsub whereas the other field is solved with Δt
+//        (2) during the substeps, the values and rates of the other field are interpolated linearly
+//            in time between the beginning of the step and its latest iterate
+type SubcycleData struct {
+	Field string `json:"field"` // subcycled field: "flow" (diffusive fields) or "mech" (mechanics). default = "flow"
+	Nsub  int    `json:"nsub"`  // number of substeps of the subcycled field within each step
+}
+
+// MovingLoadData holds data of a set of point loads (e.g. axles of a train or vehicle) travelling
+// with constant speed along a path on the surface of the mesh
+//  Note: (1) the position of the front of the set along the path at time t is s(t) = S0 + Speed・t
+//            and the position of each axle i is s(t) - Axles[i]
+//        (2) the loads are distributed to the vertices of the tagged faces (edges in 2D) containing
+//            the position of each axle with the shape functions of the cells (consistent nodal
+//            forces). Axles outside the path or not on tagged faces do not apply loads
+//        (3) the magnitudes are multiplied by the function Func (if given); e.g. for dynamic
+//            amplification or ramping
+type MovingLoadData struct {
+	Tag   int         `json:"tag"`   // tag of faces (edges) where the loads are applied
+	Path  [][]float64 `json:"path"`  // [npts][ndim] points of polyline defining the path
+	Axles []float64   `json:"axles"` // distances of axles behind the front of the set; e.g. [0, 2.5, 15, 17.5]
+	Loads []float64   `json:"loads"` // [naxles] magnitudes of loads (with sign); e.g. -100 for downwards forces
+	Key   string      `json:"key"`   // direction of loads: "fx", "fy" or "fz". default = vertical ("fy" in 2D; "fz" in 3D)
+	Speed float64     `json:"speed"` // speed along path
+	S0    float64     `json:"s0"`    // initial position of front along path
+	Func  string      `json:"func"`  // name of multiplier function. default (empty) => 1
+
+	// derived
+	Fcn fun.Func // multiplier function; nil if not given
+}
+
+// SurchargeData holds data of a parametric surface load placed by coordinates; e.g. construction
+// traffic, footings or embankments. The loads are integrated over the tagged faces (edges in 2D)
+// intersected by the loaded area; thus they do not depend on the mesh
+//  Type -- "strip":      uniform pressure for Xmin ≤ x ≤ Xmax (and Ymin ≤ y ≤ Ymax in 3D, if given)
+//          "circle":     uniform pressure within Radius of Centre (x in 2D; e.g. axisymmetric footing)
+//          "embankment": pressure Q on the crest (Xmin ≤ x ≤ Xmax) decreasing linearly to zero at
+//                        the toes (Toes[0] < Xmin and Toes[1] > Xmax)
+//  Note: (1) x and y are the horizontal coordinates; the loads act along the direction given by Key
+//        (2) in 2D, the faces are split at the discontinuities of the load; thus the integration
+//            is exact. In 3D, the faces are subdivided (Nsub) for integration
+type SurchargeData struct {
+	Type   string    `json:"type"`   // "strip", "circle" or "embankment"
+	Tag    int       `json:"tag"`    // tag of faces (edges) of the surface
+	Q      float64   `json:"q"`      // magnitude (with sign) of pressure; e.g. -100 for downwards loads
+	Key    string    `json:"key"`    // direction of loads: "fx", "fy" or "fz". default = vertical ("fy" in 2D; "fz" in 3D)
+	Func   string    `json:"func"`   // name of multiplier function. default (empty) => 1
+	Xmin   float64   `json:"xmin"`   // strip: minimum x; embankment: left end of crest
+	Xmax   float64   `json:"xmax"`   // strip: maximum x; embankment: right end of crest
+	Ymin   *float64  `json:"ymin"`   // strip (3D): minimum y. default (nil) => unbounded
+	Ymax   *float64  `json:"ymax"`   // strip (3D): maximum y. default (nil) => unbounded
+	Centre []float64 `json:"centre"` // circle: centre ([x] in 2D; [x, y] in 3D)
+	Radius float64   `json:"radius"` // circle: radius
+	Toes   []float64 `json:"toes"`   // embankment: [left, right] x-coordinates of toes
+	Nsub   int       `json:"nsub"`   // 3D: number of subdivisions of faces for integration. default = 8
+
+	// derived
+	Fcn fun.Func // multiplier function; nil if not given
+}
+
+// TieData holds data of a tie constraint gluing two (non-matching) meshed parts along tagged faces
+// (edges in 2D) by means of the mortar method; i.e. the dofs on the slave faces follow the dofs on
+// the master faces in a weak (integral) sense
+//  Note: (1) the finer side should be chosen as slave
+//        (2) the slave faces must lie on the master faces; gaps are not allowed
+//        (3) slave dofs with single-point constraints (e.g. "ux" on symmetry planes) are not tied
+type TieData struct {
+	Slave  int      `json:"slave"`  // face (edge) tag on the slave side
+	Master int      `json:"master"` // face (edge) tag on the master side
+	Keys   []string `json:"keys"`   // dofs to be tied. default (empty) => "ux", "uy", "uz" and "pl" if available
+	Nsub   int      `json:"nsub"`   // number of subdivisions of slave faces for integration. default = 4
+}
+
+// CouplingData holds data of a coupling between structural elements (beams or shells) and solids
+// (mixed-dimensional coupling); i.e. the structural nodes with tag Node are tied to the vertices of
+// the solid faces (edges in 2D) with tag Face. Each solid vertex is assigned to the closest
+// structural node; e.g. a single beam end or the nodes along the edge of a shell
+//  Type -- "dist": distributing coupling; the displacements and rotations of each structural node
+//                  are the least-squares rigid body motion of the assigned solid vertices weighted
+//                  by their tributary areas (the solid faces can deform)
+//          "kin":  kinematic coupling; the assigned solid vertices follow the rigid body motion of
+//                  the structural node (the solid faces cannot deform)
+//  Note: (1) if the structural nodes do not have rotations (e.g. rods), only the displacements are
+//            coupled
+//        (2) with kinematic couplings, solid dofs with single-point constraints are not constrained
+type CouplingData struct {
+	Node int    `json:"node"` // tag of structural nodes (vertices)
+	Face int    `json:"face"` // tag of faces (edges in 2D) of solid cells
+	Type string `json:"type"` // type of coupling: "dist" or "kin". default = "dist"
+}
+
+// MCRespData holds data of a response quantity collected in Monte Carlo simulations
+//  Type -- "node":  value of dof (Key) at vertex (Vid) at the end of simulation
+//          "ipmax": maximum value of integration point quantity (Key) among cells with tag (Tag)
+//          "ipmin": minimum value of integration point quantity (Key) among cells with tag (Tag)
+//  Fail -- limit criterion: "above" => failure if value > Lim; "below" => failure if value < Lim;
+//          empty => no limit criterion
+type MCRespData struct {
+	Name string  `json:"name"` // name of response; e.g. "settlement"
+	Type string  `json:"type"` // type of response: "node", "ipmax" or "ipmin"
+	Key  string  `json:"key"`  // key of dof or integration point quantity; e.g. "uy", "alpha"
+	Vid  int     `json:"vid"`  // vertex id if Type == "node"
+	Tag  int     `json:"tag"`  // cell tag if Type == "ipmax" or "ipmin"
+	Fail string  `json:"fail"` // limit criterion: "above", "below" or ""
+	Lim  float64 `json:"lim"`  // limit value
+}
+
+// MonteCarloData holds data for Monte Carlo (reliability) simulations
+type MonteCarloData struct {
+	Nreal   int           `json:"nreal"`   // number of realisations. default = 100
+	Seed    int           `json:"seed"`    // seed of random numbers generator. default = 0 => time is used
+	Resps   []*MCRespData `json:"resps"`   // response quantities
+	DvgFail bool          `json:"dvgfail"` // realisations that cannot be run (e.g. diverging) are counted as failures
+}
+
+// SweepData holds data for parameter sweeps; e.g. to generate the data of response surfaces for
+// surrogate models (design of experiments)
+//  Note: (1) the samples are generated in the unit hypercube and then mapped to the [Min,Max] ranges
+//            of the adjustable parameters; thus, Min and Max must be given for all parameters
+//        (2) the Sobol sequence is available for up to 16 parameters
+type SweepData struct {
+	Method string        `json:"method"` // sampling method: "lhs" (Latin hypercube) or "sobol". default = "lhs"
+	Nsamp  int           `json:"nsamp"`  // number of samples. default = 10
+	Seed   int           `json:"seed"`   // seed of random numbers generator (lhs). default = 0 => time is used
+	Adjs   []int         `json:"adjs"`   // ids of adjustable parameters to be swept. default (empty) => all
+	Resps  []*MCRespData `json:"resps"`  // response quantities
+}
+
+// Wave25Data holds data of 2.5D (semi-analytical) harmonic analyses of elastic solids; i.e. the
+// geometry is 2D (cross-section in the x-y plane) and the response varies harmonically along the
+// z-axis. The response is computed in the wavenumber domain and transformed back to space; e.g.
+// for railway-induced vibrations along long embankments
+//  Note: (1) the loads are F・δ(z - Speed・t)・exp(i ω t); thus the displacements are given at the
+//            distances z' = z - Speed・t (moving frame) from the load
+//        (2) the wavenumbers are Nk equally spaced values in [-Kmax, Kmax]; Kmax must be large
+//            enough to represent the response near the load (e.g. a few times ω/cs)
+//        (3) the material must be linear elastic ("lin-elast"); the hysteretic damping ratio Damp
+//            gives the complex moduli (1 + 2 i Damp)・{λ, G}
+type Wave25Data struct {
+	Omega float64       `json:"omega"` // angular frequency ω of loads
+	Speed float64       `json:"speed"` // speed of loads along z. default = 0 => stationary loads
+	Damp  float64       `json:"damp"`  // hysteretic damping ratio of materials
+	Kmax  float64       `json:"kmax"`  // max wavenumber
+	Nk    int           `json:"nk"`    // number of wavenumbers (odd => k = 0 is included). default = 101
+	Fixed []int         `json:"fixed"` // tags of vertices with all displacements prescribed (zero); e.g. bottom of model
+	Loads []*Wave25Load `json:"loads"` // point loads at vertices (line z = 0)
+	Verts []int         `json:"verts"` // ids of vertices where the displacements are computed (output)
+	Zs    []float64     `json:"zs"`    // distances along z (moving frame) where the displacements are computed (output)
+}
+
+// Wave25Load holds data of point loads of 2.5D analyses
+type Wave25Load struct {
+	Tag int     `json:"tag"` // tag of vertices where the load is applied
+	Fx  float64 `json:"fx"`  // amplitude of force along x
+	Fy  float64 `json:"fy"`  // amplitude of force along y
+	Fz  float64 `json:"fz"`  // amplitude of force along z (longitudinal)
+}
+
+// SiteRespData holds data of equivalent-linear site response analyses of 1D soil columns extracted
+// from the mesh; i.e. the vertically propagating shear waves are computed in the frequency domain
+// and the shear moduli and damping ratios of layers are iteratively updated with the effective
+// shear strains (strain-compatible properties); e.g. to initialise nonlinear dynamic analyses
+//  Note: (1) each column is the vertical line at the horizontal coordinates given in Columns; the
+//            layers are the solid cells crossed by the line
+//        (2) the materials must be "hysteretic" (strain dependent properties) or "lin-elast"
+//            (constant properties without damping)
+//        (3) the input motion is the horizontal acceleration Accel at the base of columns; it is
+//            the outcrop motion over an elastic bedrock if RockVs > 0; otherwise it is the motion
+//            of a rigid base (within motion)
+//        (4) Apply selects the damping of solid elements in the columns for the following stages:
+//              "eql" => strain-compatible damping ratios; e.g. for linear time-domain analyses
+//              "min" => small-strain damping ratios; e.g. for nonlinear (hysteretic) analyses
+//            the damping is proportional to the mass and matches the ratios at the fundamental
+//            frequency of columns
+type SiteRespData struct {
+	Columns  [][]float64 `json:"columns"`  // horizontal coordinates of columns: [x] in 2D or [x, y] in 3D
+	Accel    string      `json:"accel"`    // function with input acceleration (base or outcrop)
+	Dt       float64     `json:"dt"`       // time step to sample the input acceleration
+	Tf       float64     `json:"tf"`       // duration of input acceleration
+	RockVs   float64     `json:"rockvs"`   // shear wave velocity of bedrock. default = 0 => rigid base
+	RockRho  float64     `json:"rockrho"`  // density of bedrock
+	RockDamp float64     `json:"rockdamp"` // damping ratio of bedrock
+	Tol      float64     `json:"tol"`      // tolerance on relative changes of shear moduli. default = 0.02
+	MaxIt    int         `json:"maxit"`    // max number of iterations. default = 15
+	Apply    string      `json:"apply"`    // damping of elements: "", "eql" or "min". default = "" => none
+}
+
+// OutFilterData holds data to select the results saved at the output times of a stage
+//  Note: (1) the solution vectors keep their size; the values of dofs that are not selected are
+//            saved as zero, which is cheap with the "gob" encoder
+//        (2) the internal values (e.g. stresses) of elements that are not selected are not saved
+//        (3) the first and last output times of stage are always saved (with filters); results
+//            saved after interruptions are not filtered in order to allow resuming simulations
+type OutFilterData struct {
+	Nkeys []string `json:"nkeys"` // nodal keys (dofs) to be saved; e.g. ["ux", "uy"]. empty => all
+	Ikeys []string `json:"ikeys"` // integration point keys; elements without any of these keys are not saved; e.g. ["sx", "pl"]. empty => all
+	Tags  []int    `json:"tags"`  // tags of cells (regions) whose elements and vertices are saved. empty => all
+	Every int      `json:"every"` // decimation factor: save only every n-th output time. 0 or 1 => all
+}
+
+// LoadCombData holds data of a factored combination of load cases
+type LoadCombData struct {
+	Name    string    `json:"name"`    // name of combination; e.g. "ULS1"
+	Factors []float64 `json:"factors"` // [nstages] factor of each load case (stage)
+}
+
+// EnvelopeData holds data of a quantity whose envelope (maximum and minimum values among all load
+// combinations) is computed
+//  Type -- "node": values of dof (Key) at vertices with tag (Tag)
+//          "ip":   values of integration point quantity (Key) in cells with tag (Tag)
+type EnvelopeData struct {
+	Name string `json:"name"` // name of envelope; e.g. "settlements"
+	Type string `json:"type"` // type of quantity: "node" or "ip"
+	Key  string `json:"key"`  // key of dof or integration point quantity; e.g. "uy", "sx"
+	Tag  int    `json:"tag"`  // tag of vertices or cells
+}
+
+// LoadCasesData holds data for linear analyses with load cases and combinations
+//  Note: (1) each stage defines one load case (e.g. self weight, surcharge, wind); thus all stages
+//            must have the same active elements and essential boundary conditions
+//        (2) the loads of each case are computed at the final time (tf) of its stage
+//        (3) the stiffness matrix is assembled and factorised only once
+type LoadCasesData struct {
+	Combs    []*LoadCombData `json:"combs"`    // factored combinations
+	Envs     []*EnvelopeData `json:"envs"`     // envelopes of results among combinations
+	Nworkers int             `json:"nworkers"` // number of concurrent solves of load cases. default = 1 => sequential
 }
 
 // Stage holds stage data
@@ -217,17 +728,41 @@ type Stage struct {
 	Skip       bool   `json:"skip"`       // do not run stage
 
 	// specific problems data
-	SeepFaces []int          `json:"seepfaces"` // face tags corresponding to seepage faces
-	IniPorous *IniPorousData `json:"iniporous"` // initial porous media state (geostatic and hydrostatic included)
-	IniStress *IniStressData `json:"inistress"` // initial stress data
-	IniFcn    *IniFcnData    `json:"inifcn"`    // set initial solution values such as Y, dYdt and d2Ydt2
-	IniImport *IniImportRes  `json:"import"`    // import results from another previous simulation
+	SeepFaces []int              `json:"seepfaces"`  // face tags corresponding to seepage faces
+	IniPorous *IniPorousData     `json:"iniporous"`  // initial porous media state (geostatic and hydrostatic included)
+	IniStress *IniStressData     `json:"inistress"`  // initial stress data
+	IniFcn    *IniFcnData        `json:"inifcn"`     // set initial solution values such as Y, dYdt and d2Ydt2
+	IniImport *IniImportRes      `json:"import"`     // import results from another previous simulation
+	IniInterp *IniInterpRes      `json:"iniinterp"`  // interpolate results from a previous simulation with a different mesh
+	Erosion   *ErosionData       `json:"erosion"`    // element deletion (erosion) during stage
+	Cracking  *CrackData         `json:"cracking"`   // propagation of discrete cracks by splitting the mesh (2D)
+	Tracking  *TrackData         `json:"tracking"`   // initiation and propagation of embedded cracks (2D)
+	Ale       *AleData           `json:"ale"`        // updated-Lagrangian analysis of solids with ALE mesh smoothing
+	CycleJump *CycleJumpData     `json:"cyclejump"`  // cycle-jump acceleration of quasi-static cyclic loading
+	DynCtrls  []*DynCtrlData     `json:"dynctrls"`   // mass scaling and selective time integration of regions
+	Prestress []*PrestressData   `json:"prestress"`  // stressing and locking of anchors and struts
+	Drawdown  []*DrawdownData    `json:"drawdown"`   // lowering of groundwater table over regions
+	Centrif   *CentrifugeData    `json:"centrifuge"` // centrifuge modelling: radial acceleration field
+	Piping    *PipingData        `json:"piping"`     // check of hydraulic gradients (piping and heave)
+	Relax     *RelaxationData    `json:"relax"`      // excavation with stress relaxation and lining installation (β-method)
+	Contracts []*ContractionData `json:"contracts"`  // volume-loss controlled excavation of tunnels (prescribed contraction)
+	Mms       *MmsData           `json:"mms"`        // method of manufactured solutions: exact solution, sources and boundary conditions
+	Subcycle  *SubcycleData      `json:"subcycle"`   // different time steps for flow and mechanics (staggered solution)
 
 	// conditions
-	EleConds []*EleCond `json:"eleconds"` // element conditions. ex: gravity or beam distributed loads
-	FaceBcs  []*FaceBc  `json:"facebcs"`  // face boundary conditions
-	SeamBcs  []*SeamBc  `json:"seambcs"`  // seam (3D) boundary conditions
-	NodeBcs  []*NodeBc  `json:"nodebcs"`  // node boundary conditions
+	EleConds  []*EleCond      `json:"eleconds"`  // element conditions. ex: gravity or beam distributed loads
+	FaceBcs   []*FaceBc       `json:"facebcs"`   // face boundary conditions
+	SeamBcs   []*SeamBc       `json:"seambcs"`   // seam (3D) boundary conditions
+	NodeBcs   []*NodeBc       `json:"nodebcs"`   // node boundary conditions
+	Ties      []*TieData      `json:"ties"`      // tie constraints between non-matching meshed parts
+	Couplings []*CouplingData `json:"couplings"` // couplings between structural elements (beams/shells) and solids
+
+	// moving loads
+	MovingLoads []*MovingLoadData `json:"movingloads"` // point loads travelling along paths; e.g. train loads
+	Surcharges  []*SurchargeData  `json:"surcharges"`  // parametric surface loads; e.g. strip footings and embankments
+
+	// output
+	Output *OutFilterData `json:"output"` // selective output of fields and regions; nil => save everything
 
 	// timecontrol
 	Control TimeControl `json:"control"` // time control
@@ -245,6 +780,18 @@ type Simulation struct {
 	Solver    SolverData `json:"solver"`    // FEM solver data
 	Stages    []*Stage   `json:"stages"`    // stores all stages
 
+	// random fields and sampling
+	RandFields []*RandFieldData `json:"randfields"` // random fields of material parameters
+	MonteCarlo *MonteCarloData  `json:"montecarlo"` // Monte Carlo simulations
+	Sweep      *SweepData       `json:"sweep"`      // parameter sweep (design of experiments)
+
+	// frequency-wavenumber analyses
+	Wave25   *Wave25Data   `json:"wave25d"`  // 2.5D harmonic analysis (wavenumber domain) of elastic solids
+	SiteResp *SiteRespData `json:"siteresp"` // equivalent-linear site response of 1D columns (frequency domain)
+
+	// load cases
+	LoadCases *LoadCasesData `json:"loadcases"` // load cases and combinations (linear analyses)
+
 	// derived
 	GoroutineId int          // id of goroutine to avoid race problems
 	DirOut      string       // directory to save results
@@ -318,6 +865,15 @@ func ReadSim(simfilepath, alias string, erasefiles bool, goroutineId int) *Simul
 		o.EncType = "gob"
 	}
 
+	// compression of results
+	switch o.Data.Compress {
+	case "", "gzip":
+	case "zstd":
+		chk.Panic("compression with zstd is not available; use \"gzip\" instead")
+	default:
+		chk.Panic("compression of results must be \"\" or \"gzip\". %q is invalid", o.Data.Compress)
+	}
+
 	// create directory and erase previous simulation results
 	if erasefiles {
 		err = os.MkdirAll(o.DirOut, 0777)
@@ -343,6 +899,43 @@ func ReadSim(simfilepath, alias string, erasefiles bool, goroutineId int) *Simul
 			chk.Panic("ReadSim: cannot read mesh file:\n%v", err)
 		}
 
+		// geometric imperfections
+		if reg.Imperf != nil {
+			err = reg.Msh.Perturb(reg.Imperf, dir, goroutineId)
+			if err != nil {
+				chk.Panic("ReadSim: cannot seed geometric imperfections:\n%v", err)
+			}
+		}
+
+		// sets of vertices, cells and faces
+		err = reg.Msh.AddSets(reg.Sets)
+		if err != nil {
+			chk.Panic("ReadSim: cannot add sets to mesh:\n%v", err)
+		}
+
+		// integration rules
+		err = reg.check_ips()
+		if err != nil {
+			chk.Panic("ReadSim: integration rules of region %d are incorrect:\n%v", i, err)
+		}
+
+		// check quality of mesh
+		if o.Data.Quality != nil {
+			qual := reg.Msh.Quality()
+			bad := qual.Check(o.Data.Quality)
+			if o.Data.Quality.Vtk && goroutineId == 0 {
+				qual.WriteVtu(o.DirOut, io.Sf("%s_reg%d", fnkey, i))
+			}
+			if len(bad) > 0 {
+				if !o.Data.Quality.Warn {
+					chk.Panic("ReadSim: mesh quality check failed:\n%s", qual.Report(bad))
+				}
+				if goroutineId == 0 {
+					io.PfRed("%s", qual.Report(bad))
+				}
+			}
+		}
+
 		// get ndim and max elevation
 		if i == 0 {
 			o.Ndim = reg.Msh.Ndim
@@ -397,6 +990,161 @@ func ReadSim(simfilepath, alias string, erasefiles bool, goroutineId int) *Simul
 			stg.Control.DtOut = stg.Control.DtoFunc.F(t, nil)
 		}
 
+		// fix steady-state detection data
+		if stg.Control.SteadyTol > 0 {
+			if stg.Control.SteadyCrit == "" {
+				stg.Control.SteadyCrit = "rate"
+			}
+			if stg.Control.SteadyCrit != "rate" && stg.Control.SteadyCrit != "excess" {
+				chk.Panic("steady-state criterion %q is invalid; options are \"rate\" and \"excess\"", stg.Control.SteadyCrit)
+			}
+			if stg.Control.SteadyNst < 1 {
+				stg.Control.SteadyNst = 1
+			}
+		}
+
+		// fix erosion data
+		if stg.Erosion != nil {
+			if stg.Erosion.Nrel < 1 {
+				stg.Erosion.Nrel = 1
+			}
+		}
+
+		// fix cracking data
+		if stg.Cracking != nil {
+			if stg.Cracking.Nmax < 1 {
+				stg.Cracking.Nmax = 1
+			}
+		}
+
+		// fix tracking data
+		if stg.Tracking != nil {
+			if stg.Tracking.Nmax < 1 {
+				stg.Tracking.Nmax = 1
+			}
+		}
+
+		// fix ALE data
+		if stg.Ale != nil {
+			if stg.Ale.Qmin <= 0 {
+				stg.Ale.Qmin = 0.3
+			}
+			if stg.Ale.Niter < 1 {
+				stg.Ale.Niter = 10
+			}
+			if stg.Ale.Omega <= 0 {
+				stg.Ale.Omega = 0.5
+			}
+		}
+
+		// fix dynamics control data
+		for _, dc := range stg.DynCtrls {
+			if dc.Scheme == "" {
+				dc.Scheme = "dyn"
+			}
+			if dc.Scheme != "dyn" && dc.Scheme != "qsta" {
+				chk.Panic("scheme %q of dynamics control is invalid; options are \"dyn\" and \"qsta\"", dc.Scheme)
+			}
+			if dc.Mscale == 0 {
+				dc.Mscale = 1
+			}
+			if dc.Mscale < 0 {
+				chk.Panic("mass scaling factor must be positive. %g is invalid", dc.Mscale)
+			}
+		}
+
+		// fix cycle jump data
+		if stg.CycleJump != nil {
+			cj := stg.CycleJump
+			if cj.Period <= 0 {
+				chk.Panic("period of loading cycles for cycle jumping must be positive. %g is invalid", cj.Period)
+			}
+			if cj.Nexpl < 2 {
+				cj.Nexpl = 2
+			}
+			if cj.Njump < 1 {
+				cj.Njump = 100
+			}
+			if cj.Tol <= 0 {
+				cj.Tol = 0.05
+			}
+		}
+
+		// fix moving loads data
+		for _, ml := range stg.MovingLoads {
+			if len(ml.Path) < 2 {
+				chk.Panic("path of moving loads must have at least 2 points")
+			}
+			if len(ml.Loads) != len(ml.Axles) {
+				chk.Panic("number of loads (%d) must be equal to the number of axles (%d) of moving loads", len(ml.Loads), len(ml.Axles))
+			}
+			if ml.Key == "" {
+				ml.Key = "fy"
+				if o.Ndim == 3 {
+					ml.Key = "fz"
+				}
+			}
+			if ml.Func != "" {
+				ml.Fcn, err = o.Functions.Get(ml.Func)
+				if err != nil {
+					chk.Panic("%v", err)
+				}
+			}
+		}
+
+		// fix surcharges data
+		for _, sc := range stg.Surcharges {
+			switch sc.Type {
+			case "strip":
+			case "circle":
+				if len(sc.Centre) < o.Ndim-1 || sc.Radius <= 0 {
+					chk.Panic("circular surcharge needs the %d coordinate(s) of centre and a positive radius", o.Ndim-1)
+				}
+			case "embankment":
+				if len(sc.Toes) != 2 || sc.Toes[0] > sc.Xmin || sc.Toes[1] < sc.Xmax {
+					chk.Panic("embankment surcharge needs the 2 toes with toes[0] ≤ xmin and toes[1] ≥ xmax")
+				}
+			default:
+				chk.Panic("type of surcharge %q is invalid; options are \"strip\", \"circle\" and \"embankment\"", sc.Type)
+			}
+			if sc.Key == "" {
+				sc.Key = "fy"
+				if o.Ndim == 3 {
+					sc.Key = "fz"
+				}
+			}
+			if sc.Nsub < 1 {
+				sc.Nsub = 8
+			}
+			if sc.Func != "" {
+				sc.Fcn, err = o.Functions.Get(sc.Func)
+				if err != nil {
+					chk.Panic("%v", err)
+				}
+			}
+		}
+
+		// fix tie data
+		for _, tie := range stg.Ties {
+			if tie.Nsub < 1 {
+				tie.Nsub = 4
+			}
+			if len(tie.Keys) == 0 {
+				tie.Keys = append([]string{"ux", "uy", "uz"}[:o.Ndim], "pl")
+			}
+		}
+
+		// fix coupling data
+		for _, cpl := range stg.Couplings {
+			switch cpl.Type {
+			case "":
+				cpl.Type = "dist"
+			case "dist", "kin":
+			default:
+				chk.Panic("type of coupling must be \"dist\" or \"kin\". %q is invalid", cpl.Type)
+			}
+		}
+
 		// first stage
 		if i == 0 {
 
@@ -431,6 +1179,85 @@ func ReadSim(simfilepath, alias string, erasefiles bool, goroutineId int) *Simul
 		chk.Panic("loading materials and initialising models failed:\n%v", err)
 	}
 
+	// Monte Carlo data
+	if o.MonteCarlo != nil {
+		if o.MonteCarlo.Nreal < 1 {
+			o.MonteCarlo.Nreal = 100
+		}
+	}
+
+	// load cases and combinations
+	if o.LoadCases != nil {
+		for _, comb := range o.LoadCases.Combs {
+			if len(comb.Factors) != len(o.Stages) {
+				chk.Panic("number of factors of load combination %q must be equal to the number of stages (load cases) = %d", comb.Name, len(o.Stages))
+			}
+		}
+	}
+
+	// parameter sweep data
+	if o.Sweep != nil {
+		if o.Sweep.Method == "" {
+			o.Sweep.Method = "lhs"
+		}
+		if o.Sweep.Nsamp < 1 {
+			o.Sweep.Nsamp = 10
+		}
+	}
+
+	// 2.5D analysis data
+	if o.Wave25 != nil {
+		if o.Ndim != 2 {
+			chk.Panic("2.5D analyses require 2D meshes. ndim = %d is invalid", o.Ndim)
+		}
+		if o.Wave25.Kmax <= 0 {
+			chk.Panic("max wavenumber of 2.5D analysis must be positive. kmax = %g is invalid", o.Wave25.Kmax)
+		}
+		if o.Wave25.Nk < 2 {
+			o.Wave25.Nk = 101
+		}
+		if o.Wave25.Damp < 0 {
+			chk.Panic("damping ratio of 2.5D analysis must be non-negative. damp = %g is invalid", o.Wave25.Damp)
+		}
+	}
+
+	// site response data
+	if o.SiteResp != nil {
+		if len(o.SiteResp.Columns) == 0 {
+			chk.Panic("site response analysis requires at least one column")
+		}
+		for _, xc := range o.SiteResp.Columns {
+			if len(xc) != o.Ndim-1 {
+				chk.Panic("horizontal coordinates of columns must have %d values. %v is invalid", o.Ndim-1, xc)
+			}
+		}
+		if o.SiteResp.Dt <= 0 || o.SiteResp.Tf <= o.SiteResp.Dt {
+			chk.Panic("site response analysis requires dt > 0 and tf > dt. dt = %g and tf = %g are invalid", o.SiteResp.Dt, o.SiteResp.Tf)
+		}
+		if o.SiteResp.RockVs > 0 && o.SiteResp.RockRho <= 0 {
+			chk.Panic("density of bedrock must be positive. rockrho = %g is invalid", o.SiteResp.RockRho)
+		}
+		if o.SiteResp.Tol <= 0 {
+			o.SiteResp.Tol = 0.02
+		}
+		if o.SiteResp.MaxIt < 1 {
+			o.SiteResp.MaxIt = 15
+		}
+		switch o.SiteResp.Apply {
+		case "", "eql", "min":
+		default:
+			chk.Panic("damping of site response analysis must be \"\", \"eql\" or \"min\". apply = %q is invalid", o.SiteResp.Apply)
+		}
+	}
+
+	// random fields
+	if len(o.RandFields) > 0 {
+		err = o.SetRandFields()
+		if err != nil {
+			chk.Panic("cannot set random fields:\n%v", err)
+		}
+	}
+
 	// adjustable and random parameters
 	o.adjmap = make(map[int]*fun.Prm)
 	for _, mat := range o.MatModels.Materials {
@@ -518,6 +1345,75 @@ func (o *Region) Etag2data(etag int) *ElemData {
 	return nil
 }
 
+// Cell2data returns the ElemData of cell with the number of integration points (Nip and Nipf)
+// resolved according to Rule and the overrides of this cell (see IpsData)
+//  Note: a copy of ElemData is returned if the cell does not use the data of the element type as is
+func (o *Region) Cell2data(cell *Cell) (edat *ElemData, err error) {
+	edat = o.Etag2data(cell.Tag)
+	if edat == nil {
+		return nil, chk.Err("cannot get data for element {tag=%d, id=%d}", cell.Tag, cell.Id)
+	}
+	nip, nipf, rule := edat.Nip, edat.Nipf, edat.Rule
+	for _, dat := range edat.Ips {
+		if utl.IntIndexSmall(dat.Cids, cell.Id) >= 0 {
+			nip, nipf, rule = dat.Nip, dat.Nipf, dat.Rule
+			break
+		}
+	}
+	if nip == edat.Nip && nipf == edat.Nipf && rule == "" {
+		return
+	}
+	if rule != "" && cell.Shp != nil {
+		rnip, rnipf, e := cell.Shp.RuleNips(rule)
+		if e != nil {
+			return nil, chk.Err("cannot get integration rule of element {tag=%d, id=%d}:\n%v", cell.Tag, cell.Id, e)
+		}
+		if nip == 0 {
+			nip = rnip
+		}
+		if nipf == 0 {
+			nipf = rnipf
+		}
+	}
+	cpy := *edat
+	cpy.Nip, cpy.Nipf, cpy.Rule, cpy.Ips = nip, nipf, "", nil
+	return &cpy, nil
+}
+
+// check_ips checks the integration rules of elements
+func (o *Region) check_ips() (err error) {
+	for _, edat := range o.ElemsData {
+		if edat.Nip < 0 || edat.Nipf < 0 {
+			return chk.Err("numbers of integration points of elements with tag = %d must be non-negative", edat.Tag)
+		}
+		done := make(map[int]bool)
+		for _, dat := range edat.Ips {
+			if dat.Nip < 0 || dat.Nipf < 0 {
+				return chk.Err("numbers of integration points of cells %v must be non-negative", dat.Cids)
+			}
+			for _, cid := range dat.Cids {
+				if cid < 0 || cid >= len(o.Msh.Cells) {
+					return chk.Err("cannot find cell with id = %d to set integration rule", cid)
+				}
+				if o.Msh.Cells[cid].Tag != edat.Tag {
+					return chk.Err("cell with id = %d does not have tag = %d", cid, edat.Tag)
+				}
+				if done[cid] {
+					return chk.Err("integration rule of cell with id = %d is given more than once", cid)
+				}
+				done[cid] = true
+			}
+		}
+		for _, cell := range o.Msh.CellTag2cells[edat.Tag] {
+			_, err = o.Cell2data(cell)
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
 // GetInfo returns formatted information
 func (o *Simulation) GetInfo(w goio.Writer) (err error) {
 	b, err := json.MarshalIndent(o, "", "  ")
@@ -568,6 +1464,8 @@ func (o *LinSolData) SetDefault() {
 	o.Name = "umfpack"
 	o.Ordering = "amf"
 	o.Scaling = "rcit"
+	o.RefTol = 1e-12
+	o.RefNmax = 20
 }
 
 // SetDefault set defaults values
@@ -582,6 +1480,19 @@ func (o *SolverData) SetDefault() {
 	o.FbMin = 1e-14
 	o.NdvgMax = 20
 
+	// staggered solution
+	o.SplitBeta = 1
+	o.SplitTol = 1e-3
+	o.SplitNmax = 50
+
+	// essential boundary conditions / constraints
+	o.Constraints = "lagrange"
+	o.Penalty = 1e12
+
+	// diagnostics of Jacobian matrix
+	o.DiagNit = 30
+	o.DiagCmax = 1e7
+
 	// Richardson's extrapolation
 	o.REnssmax = 10000
 	o.REatol = 1e-6
@@ -643,6 +1554,19 @@ func (o *Simulation) PrmGetAdj(adj int) (val float64) {
 	return
 }
 
+// ReinitModels re-initialises the material models and re-generates the random fields (if any)
+// after parameters have been adjusted. It must be called before the elements are allocated
+func (o *Simulation) ReinitModels() (err error) {
+	err = o.MatModels.ReinitModels(o.Ndim, o.Data.Pstress, o.Grav0)
+	if err != nil {
+		return
+	}
+	if len(o.RandFields) > 0 {
+		return o.SetRandFields()
+	}
+	return
+}
+
 // append_adjustable_parameter add prm to lists
 func (o *Simulation) append_adjustable_parameter(prm *fun.Prm) {
 
@@ -0,0 +1,83 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_crack01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("crack01. splitting of edges between solids")
+
+	// 2x2 mesh of qua4 cells
+	//  6---7---8
+	//  | 2 | 3 |
+	//  3---4---5
+	//  | 0 | 1 |
+	//  0---1---2
+	var msh Mesh
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			msh.Verts = append(msh.Verts, &Vert{Id: len(msh.Verts), C: []float64{float64(i), float64(j)}})
+		}
+	}
+	for _, verts := range [][]int{{0, 1, 4, 3}, {1, 2, 5, 4}, {3, 4, 7, 6}, {4, 5, 8, 7}} {
+		msh.Cells = append(msh.Cells, &Cell{Id: len(msh.Cells), Tag: -1, Type: "qua4", Verts: verts})
+	}
+	err := msh.CalcDerived(0)
+	if err != nil {
+		tst.Errorf("CalcDerived failed:\n%v", err)
+		return
+	}
+	ea, eb := msh.SharedEdge(msh.Cells[0], msh.Cells[1])
+	chk.Ints(tst, "edges of 0 and 1", []int{ea, eb}, []int{1, 3})
+	ea, _ = msh.SharedEdge(msh.Cells[0], msh.Cells[3])
+	chk.IntAssert(ea, -1)
+
+	// lower edge: vertex @ crack tip (4) is not duplicated
+	var cracked []*CrackedEdge
+	newverts, err := msh.SplitEdge(0, 1, -5, &cracked)
+	if err != nil {
+		tst.Errorf("SplitEdge failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(len(msh.Verts), 10)
+	chk.IntAssert(len(msh.Cells), 5)
+	chk.IntAssert(newverts[9], 1)
+	chk.Ints(tst, "verts of cell 1", msh.Cells[1].Verts, []int{9, 2, 5, 4})
+	chk.Ints(tst, "verts of interface 4", msh.Cells[4].Verts, []int{1, 4, 4, 9})
+	chk.IntAssert(msh.Cells[4].Tag, -5)
+	chk.Ints(tst, "cells sharing 9", msh.Verts[9].SharedBy, []int{1, 4})
+
+	// upper edge: crack crosses the mesh; thus vertex 4 is duplicated
+	newverts, err = msh.SplitEdge(2, 3, -5, &cracked)
+	if err != nil {
+		tst.Errorf("SplitEdge failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(len(cracked), 2)
+	chk.IntAssert(len(msh.Verts), 12)
+	chk.IntAssert(len(msh.Cells), 6)
+	chk.IntAssert(newverts[10], 4)
+	chk.IntAssert(newverts[11], 7)
+	chk.Ints(tst, "verts of cell 1", msh.Cells[1].Verts, []int{9, 2, 5, 10})
+	chk.Ints(tst, "verts of cell 3", msh.Cells[3].Verts, []int{10, 5, 8, 11})
+	chk.Ints(tst, "verts of interface 4", msh.Cells[4].Verts, []int{1, 4, 10, 9})
+	chk.Ints(tst, "verts of interface 5", msh.Cells[5].Verts, []int{4, 7, 11, 10})
+	chk.Vector(tst, "x10", 1e-17, msh.Verts[10].C, []float64{1, 1})
+	chk.Vector(tst, "x11", 1e-17, msh.Verts[11].C, []float64{1, 2})
+
+	// separated solids do not share edges anymore
+	ea, _ = msh.SharedEdge(msh.Cells[0], msh.Cells[1])
+	chk.IntAssert(ea, -1)
+	_, err = msh.SplitEdge(2, 3, -5, &cracked)
+	if err == nil {
+		tst.Errorf("SplitEdge must fail with separated solids")
+	}
+}
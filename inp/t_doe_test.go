@@ -0,0 +1,69 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_doe01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("doe01. Latin hypercube sampling")
+
+	nsamp, ndim := 10, 3
+	U := LatinHypercube(nsamp, ndim, rand.New(rand.NewSource(1234)))
+	chk.IntAssert(len(U), nsamp)
+	for j := 0; j < ndim; j++ {
+		count := make([]int, nsamp)
+		for i := 0; i < nsamp; i++ {
+			io.Pforan("%8.5f", U[i][j])
+			if U[i][j] < 0 || U[i][j] >= 1 {
+				tst.Errorf("sample is outside unit hypercube: %v\n", U[i][j])
+				return
+			}
+			count[int(U[i][j]*float64(nsamp))]++
+		}
+		io.Pf("\n")
+		for k := 0; k < nsamp; k++ {
+			chk.IntAssert(count[k], 1)
+		}
+	}
+}
+
+func Test_doe02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("doe02. Sobol sequence")
+
+	U, err := Sobol(8, 4)
+	if err != nil {
+		tst.Errorf("Sobol failed:\n%v", err)
+		return
+	}
+	correct := [][]float64{
+		{0.5, 0.5, 0.5, 0.5},
+		{0.75, 0.25, 0.25, 0.25},
+		{0.25, 0.75, 0.75, 0.75},
+		{0.375, 0.375, 0.625, 0.875},
+		{0.875, 0.875, 0.125, 0.375},
+		{0.625, 0.125, 0.875, 0.625},
+		{0.125, 0.625, 0.375, 0.125},
+		{0.1875, 0.3125, 0.9375, 0.4375},
+	}
+	for i, u := range U {
+		io.Pforan("%v\n", u)
+		chk.Vector(tst, io.Sf("U%d", i), 1e-15, u, correct[i])
+	}
+
+	_, err = Sobol(8, SOBOL_MAXDIM+1)
+	if err == nil {
+		tst.Errorf("Sobol should have failed with too many dimensions\n")
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_dtreport01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("dtreport01. Critical time steps of transient solid")
+
+	sim := ReadSim("data/bh16dyn.sim", "", true, 0)
+	if sim == nil {
+		tst.Errorf("test failed\n")
+		return
+	}
+	rpt := sim.CalcDtReport()
+	io.Pf("%v", rpt)
+
+	// elastic waves
+	E, ν, ρ := 10000.0, 0.2, 1.0
+	K := E / (3.0 * (1.0 - 2.0*ν))
+	G := E / (2.0 * (1.0 + ν))
+	chk.IntAssert(len(rpt.Infos), 1)
+	info := rpt.Infos[0]
+	chk.IntAssert(info.Ncells, 4)
+	chk.Scalar(tst, "hmin", 1e-15, info.Hmin, 1.0)
+	chk.Scalar(tst, "Vp", 1e-12, info.Vp, math.Sqrt((K+4.0*G/3.0)/ρ))
+	chk.Scalar(tst, "Vs", 1e-12, info.Vs, math.Sqrt(G/ρ))
+	chk.Scalar(tst, "cv", 1e-15, info.Cv, 0)
+	chk.Scalar(tst, "h/Vp", 1e-15, info.DtWave, 1.0/info.Vp)
+
+	// stage
+	chk.IntAssert(len(rpt.Stages), 1)
+	stg := rpt.Stages[0]
+	chk.Scalar(tst, "Δt", 1e-15, stg.Dt, 1.0)
+	chk.Scalar(tst, "Δt(min)", 1e-15, stg.DtMin, 0)
+	chk.Scalar(tst, "Δt(max)", 1e-15, stg.DtMax, info.DtWave)
+	chk.IntAssert(len(stg.Warnings), 1)
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+func Test_expr01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("expr01. expressions")
+
+	vars := []string{"x", "y", "z", "t"}
+	consts := map[string]float64{"gamw": 10, "zw": 3}
+	for _, c := range []struct {
+		src string
+		res float64
+	}{
+		{"gamw*(zw - z)", 10},
+		{"-x + 2*y/4", 0},
+		{"sqrt(x*x + y*y)", math.Sqrt(5)},
+		{"pow(t, 2) + max(x, y) - min(x, y)", 0.25 + 1},
+		{"x > 0.5 && y < 1", 0},
+		{"x > 0.5 || y < 1", 1},
+		{"!(z == 2)", 0},
+		{"atan2(y, x) - pi/2 + abs(-1)", math.Atan2(2, 1) - math.Pi/2 + 1},
+	} {
+		e, err := NewExpr(c.src, vars, consts)
+		if err != nil {
+			tst.Errorf("NewExpr failed:\n%v", err)
+			return
+		}
+		chk.Scalar(tst, c.src, 1e-15, e.Eval(1, 2, 2, 0.5), c.res)
+	}
+
+	// errors
+	for _, src := range []string{"x^2", "w + 1", "foo(x)", "sqrt(x, y)", "x +"} {
+		_, err := NewExpr(src, vars, consts)
+		if err == nil {
+			tst.Errorf("expression %q should have failed\n", src)
+		}
+	}
+}
+
+func Test_expr02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("expr02. functions defined by expressions")
+
+	funcs := FuncsData{
+		&FuncData{Name: "hst", Type: "expr", Expr: "gamw*(zw - y)*t", Prms: fun.Prms{
+			&fun.Prm{N: "gamw", V: 10},
+			&fun.Prm{N: "zw", V: 3},
+		}},
+		&FuncData{Name: "bad", Type: "expr"},
+	}
+	fcn, err := funcs.Get("hst")
+	if err != nil {
+		tst.Errorf("Get failed:\n%v", err)
+		return
+	}
+	if !FuncDependsOnX(fcn) {
+		tst.Errorf("function should depend on x\n")
+		return
+	}
+	x := []float64{1, 1}
+	chk.Scalar(tst, "F", 1e-15, fcn.F(2, x), 40)
+	chk.Scalar(tst, "G", 1e-8, fcn.G(2, x), 20)
+	chk.Scalar(tst, "H", 1e-5, fcn.H(2, x), 0)
+	g := make([]float64, 2)
+	fcn.Grad(g, 2, x)
+	chk.Vector(tst, "Grad", 1e-8, g, []float64{0, -20})
+
+	// bound to coordinates
+	f := fcn.(*ExprFunc).At([]float64{0, 4})
+	if FuncDependsOnX(f) {
+		tst.Errorf("bound function should not depend on x\n")
+		return
+	}
+	chk.Scalar(tst, "F(bound)", 1e-15, f.F(2, nil), -20)
+	f.Grad(g, 2, nil)
+	chk.Vector(tst, "Grad(bound)", 1e-8, g, []float64{0, -20})
+
+	// error
+	_, err = funcs.Get("bad")
+	if err == nil {
+		tst.Errorf("function without expression should have failed\n")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_imperf01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("imperf01. geometric imperfections")
+
+	// original and perturbed meshes
+	read := func(dat *ImperfData) *Mesh {
+		msh, err := ReadMsh("data", "quality01.msh", 0)
+		if err != nil {
+			tst.Errorf("ReadMsh failed:\n%v", err)
+			return nil
+		}
+		if dat != nil {
+			err = msh.Perturb(dat, "data", 0)
+			if err != nil {
+				tst.Errorf("Perturb failed:\n%v", err)
+				return nil
+			}
+		}
+		return msh
+	}
+	m0 := read(nil)
+	dat := &ImperfData{Amp: 0.01, Seed: 42}
+	m1 := read(dat)
+	m2 := read(dat)
+	if m0 == nil || m1 == nil || m2 == nil {
+		return
+	}
+
+	// same seed => same mesh; perturbations within amplitude
+	changed := false
+	for i, v := range m1.Verts {
+		chk.Vector(tst, io.Sf("x%d", i), 1e-17, v.C, m2.Verts[i].C)
+		for j := 0; j < 2; j++ {
+			δ := v.C[j] - m0.Verts[i].C[j]
+			if math.Abs(δ) > dat.Amp {
+				tst.Errorf("perturbation of vertex %d is larger than amplitude: %g\n", i, δ)
+				return
+			}
+			if δ != 0 {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		tst.Errorf("vertices must have been perturbed\n")
+		return
+	}
+
+	// perturbation along direction
+	m3 := read(&ImperfData{Amp: 0.01, Seed: 42, Dir: []float64{0, 2}})
+	if m3 == nil {
+		return
+	}
+	for i, v := range m3.Verts {
+		chk.Scalar(tst, io.Sf("x%d", i), 1e-17, v.C[0], m0.Verts[i].C[0])
+	}
+}
+
+func Test_imperf02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("imperf02. imperfections from mode shapes")
+
+	// mode shape: vertices 0 and 2 only; largest displacement = 2
+	io.WriteFileSD("/tmp/gofem/inp", "imperf02-mode.res", "id ux uy\n0 0 2\n2 1 -1\n")
+
+	// perturbed meshes
+	m0, err := ReadMsh("data", "quality01.msh", 0)
+	if err != nil {
+		tst.Errorf("ReadMsh failed:\n%v", err)
+		return
+	}
+	m1, err := ReadMsh("data", "quality01.msh", 0)
+	if err != nil {
+		tst.Errorf("ReadMsh failed:\n%v", err)
+		return
+	}
+	mode := &ModeData{File: "imperf02-mode.res", Scale: 0.1}
+	err = m1.Perturb(&ImperfData{Modes: []*ModeData{mode}}, "/tmp/gofem/inp", 0)
+	if err != nil {
+		tst.Errorf("Perturb failed:\n%v", err)
+		return
+	}
+
+	// check
+	chk.Vector(tst, "x0", 1e-15, m1.Verts[0].C, []float64{0, 0.1})
+	chk.Vector(tst, "x2", 1e-15, m1.Verts[2].C, []float64{1.05, 0.95})
+	for i := 3; i < len(m1.Verts); i++ {
+		chk.Vector(tst, io.Sf("x%d", i), 1e-17, m1.Verts[i].C, m0.Verts[i].C)
+	}
+
+	// zero mode
+	io.WriteFileSD("/tmp/gofem/inp", "imperf02-zero.res", "id ux uy\n0 0 0\n")
+	mode.File = "imperf02-zero.res"
+	err = m1.Perturb(&ImperfData{Modes: []*ModeData{mode}}, "/tmp/gofem/inp", 0)
+	if err == nil {
+		tst.Errorf("Perturb should have failed with zero mode shape\n")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_ips01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ips01. integration rules per element type and per cell")
+
+	msh, err := ReadMsh("data", "bh16.msh", 0)
+	if err != nil {
+		tst.Errorf("test failed:\n%v", err)
+		return
+	}
+	edat := &ElemData{Tag: -1, Mat: "mat", Type: "solid", Nip: 3, Ips: []*IpsData{
+		{Cids: []int{1, 2}, Nip: 6},
+		{Cids: []int{3}, Rule: "full"},
+	}}
+	reg := &Region{ElemsData: []*ElemData{edat}, Msh: msh}
+	err = reg.check_ips()
+	if err != nil {
+		tst.Errorf("check_ips failed:\n%v", err)
+		return
+	}
+
+	// element type
+	d, err := reg.Cell2data(msh.Cells[0])
+	if err != nil {
+		tst.Errorf("Cell2data failed:\n%v", err)
+		return
+	}
+	if d != edat {
+		tst.Errorf("cell 0 must use the data of the element type")
+		return
+	}
+
+	// overrides
+	nips := []int{3, 6, 6, 0}
+	for i, cell := range msh.Cells {
+		d, err = reg.Cell2data(cell)
+		if err != nil {
+			tst.Errorf("Cell2data failed:\n%v", err)
+			return
+		}
+		chk.IntAssert(d.Nip, nips[i])
+		if i > 0 && (d == edat || d.Ips != nil || d.Mat != "mat") {
+			tst.Errorf("cell %d must use a copy of the element data without overrides", i)
+			return
+		}
+	}
+	chk.IntAssert(edat.Nip, 3)
+
+	// reduced integration is not available for tri3
+	edat.Ips = []*IpsData{{Cids: []int{3}, Rule: "reduced"}}
+	err = reg.check_ips()
+	if err == nil {
+		tst.Errorf("check_ips should have failed: reduced rule for tri3")
+		return
+	}
+	io.Pforan("%v\n", err)
+
+	// cell given twice
+	edat.Ips = []*IpsData{{Cids: []int{1}, Nip: 3}, {Cids: []int{1}, Nip: 6}}
+	err = reg.check_ips()
+	if err == nil {
+		tst.Errorf("check_ips should have failed: cell given twice")
+		return
+	}
+	io.Pforan("%v\n", err)
+
+	// wrong cell id
+	edat.Ips = []*IpsData{{Cids: []int{4}, Nip: 3}}
+	err = reg.check_ips()
+	if err == nil {
+		tst.Errorf("check_ips should have failed: cell does not exist")
+		return
+	}
+	io.Pforan("%v\n", err)
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_quality01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("quality01. quality of cells")
+
+	msh, err := ReadMsh("data", "quality01.msh", 0)
+	if err != nil {
+		tst.Errorf("test failed:\n%v", err)
+		return
+	}
+	qual := msh.Quality()
+	chk.IntAssert(len(qual.Cells), 5)
+
+	// square, 4x1 rectangle, 45° parallelogram, inverted square and equilateral triangle
+	jratio := []float64{1, 1, 1, -1, 1}
+	aspect := []float64{1, 4, (3 + math.Sqrt(5)) / 2, 1, 1}
+	skew := []float64{0, 0, 0.5, 0, 0}
+	minangle := []float64{90, 90, 45, 90, 60}
+	for i, c := range qual.Cells {
+		chk.IntAssert(c.Id, i)
+		chk.Scalar(tst, io.Sf("jratio%d", i), 1e-15, c.Jratio, jratio[i])
+		chk.Scalar(tst, io.Sf("aspect%d", i), 1e-14, c.Aspect, aspect[i])
+		chk.Scalar(tst, io.Sf("skew%d", i), 1e-14, c.Skew, skew[i])
+		chk.Scalar(tst, io.Sf("minangle%d", i), 1e-13, c.MinAngle, minangle[i])
+	}
+
+	// inverted cells always fail
+	bad := qual.Check(&QualityData{})
+	chk.IntAssert(len(bad), 1)
+	chk.IntAssert(bad[0].Id, 3)
+
+	// limits
+	bad = qual.Check(&QualityData{Aspect: 3, Skew: 0.4})
+	ids := make([]int, len(bad))
+	for i, c := range bad {
+		ids[i] = c.Id
+	}
+	chk.Ints(tst, "bad cells", ids, []int{1, 2, 3})
+	io.Pf("%s", qual.Report(bad))
+}
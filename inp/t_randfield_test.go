@@ -0,0 +1,82 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_randfield01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("randfield01. lognormal random field")
+
+	// grid of points
+	n := 20
+	X := make([][]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			X[i+j*n] = []float64{float64(i), float64(j)}
+		}
+	}
+
+	// very short scale of fluctuation => nearly independent values
+	mean, cov := 100.0, 0.3
+	dat := &RandFieldData{Prm: "E", Cov: cov, Theta: []float64{1e-3}, Seed: 1234}
+	vals, err := GenRandField(dat, mean, X)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	var μ, σ float64
+	for _, v := range vals {
+		if v <= 0 {
+			tst.Errorf("values of lognormal field must be positive: %g\n", v)
+			return
+		}
+		μ += v / float64(len(vals))
+	}
+	for _, v := range vals {
+		σ += (v - μ) * (v - μ) / float64(len(vals)-1)
+	}
+	σ = math.Sqrt(σ)
+	io.Pforan("μ = %v  σ/μ = %v\n", μ, σ/μ)
+	chk.Scalar(tst, "mean", 0.05*mean, μ, mean)
+	chk.Scalar(tst, "cov", 0.05, σ/μ, cov)
+
+	// same seed => same field
+	vals2, err := GenRandField(dat, mean, X)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	chk.Vector(tst, "same seed", 1e-15, vals2, vals)
+
+	// very large scale of fluctuation => nearly homogeneous field
+	dat.Theta = []float64{1e6}
+	vals, err = GenRandField(dat, mean, X)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	for _, v := range vals {
+		chk.Scalar(tst, "homogeneous", 1e-2*vals[0], v, vals[0])
+	}
+
+	// normal distribution
+	dat.Dist = "normal"
+	dat.Theta = []float64{5, 2}
+	dat.Nterms = 50
+	vals, err = GenRandField(dat, mean, X)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	chk.IntAssert(len(vals), n*n)
+}
@@ -100,6 +100,114 @@ func Test_msh03(tst *testing.T) {
 	chk.Scalar(tst, "MaxElev", 1e-17, msh.MaxElev, 1)
 }
 
+func Test_msh04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("msh04. rods crossing solids")
+
+	msh, err := ReadMsh("data", "rods01.msh", 0)
+	if err != nil {
+		tst.Errorf("test failed:\n%v", err)
+		return
+	}
+
+	err = msh.AddRods([]*RodPolyline{
+		{Tag: -2, Jtag: -3, Pts: [][]float64{{0.2, 0.5}, {1.8, 0.5}}},
+	})
+	if err != nil {
+		tst.Errorf("test failed:\n%v", err)
+		return
+	}
+	io.Pforan("%v\n", msh)
+
+	chk.IntAssert(len(msh.Verts), 9)
+	chk.IntAssert(len(msh.Cells), 6)
+	chk.Vector(tst, "x6", 1e-15, msh.Verts[6].C, []float64{0.2, 0.5})
+	chk.Vector(tst, "x7", 1e-8, msh.Verts[7].C, []float64{1.0, 0.5})
+	chk.Vector(tst, "x8", 1e-15, msh.Verts[8].C, []float64{1.8, 0.5})
+	chk.Ints(tst, "rod 2: verts", msh.Cells[2].Verts, []int{6, 7})
+	chk.Ints(tst, "rod 4: verts", msh.Cells[4].Verts, []int{7, 8})
+	chk.IntAssert(msh.Cells[3].JlinId, 2)
+	chk.IntAssert(msh.Cells[3].JsldId, 0)
+	chk.IntAssert(msh.Cells[5].JlinId, 4)
+	chk.IntAssert(msh.Cells[5].JsldId, 1)
+
+	// write and read again
+	msh.WriteMsh("/tmp/gofem/inp", "rods01-augmented.msh")
+	msh, err = ReadMsh("/tmp/gofem/inp", "rods01-augmented.msh", 0)
+	if err != nil {
+		tst.Errorf("test failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(msh.Cells[5].JsldId, 1)
+	chk.Ints(tst, "joint 5: verts", msh.Cells[5].Verts, []int{1, 2, 5, 4, 7, 8})
+
+	// splitting with given owners; e.g. along rod's natural coordinate
+	ts, owners := RodSplit(-1, 1, func(t float64) int {
+		switch {
+		case t < -0.25:
+			return 0
+		case t < 0.5:
+			return 1
+		}
+		return -1
+	})
+	chk.Vector(tst, "ts", 1e-8, ts, []float64{-1, -0.25, 0.5, 1})
+	chk.Ints(tst, "owners", owners, []int{0, 1, -1})
+}
+
+func Test_msh05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("msh05. locate points in cells")
+
+	msh, err := ReadMsh("data", "bh16.msh", 0)
+	if err != nil {
+		tst.Errorf("test failed:\n%v", err)
+		return
+	}
+
+	loc := NewCellLocator(msh, 3)
+	for _, dat := range []struct {
+		x   []float64
+		cid int
+	}{
+		{[]float64{11.0, -0.5}, 0},
+		{[]float64{10.5, 0.5}, 1},
+		{[]float64{13.5, -0.8}, 2},
+		{[]float64{12.5, 0.0}, 3},
+		{[]float64{10.0, -1.0}, 0},
+	} {
+		c, r := loc.Find(dat.x)
+		if c == nil {
+			tst.Errorf("cannot find cell containing %v\n", dat.x)
+			return
+		}
+		io.Pforan("x = %v  =>  cell = %d  r = %v\n", dat.x, c.Id, r)
+		chk.IntAssert(c.Id, dat.cid)
+
+		// check natural coordinates
+		c.Shp.Func(c.Shp.S, c.Shp.DSdR, r, false, -1)
+		y := make([]float64, 2)
+		for i := 0; i < 2; i++ {
+			for m, v := range c.Verts {
+				y[i] += c.Shp.S[m] * msh.Verts[v].C[i]
+			}
+		}
+		chk.Vector(tst, "x", 1e-10, y, dat.x)
+	}
+
+	// outside
+	if c, _ := loc.Find([]float64{20, 0}); c != nil {
+		tst.Errorf("point outside mesh must not be located\n")
+		return
+	}
+	if c, _ := loc.Find([]float64{13.0, 0.9}); c != nil {
+		tst.Errorf("point outside cells (but within bounding box) must not be located\n")
+		return
+	}
+}
+
 func Test_mat01(tst *testing.T) {
 
 	//verbose()
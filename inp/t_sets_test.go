@@ -0,0 +1,75 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_sets01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("sets01. sets of vertices, cells and faces")
+
+	msh, err := ReadMsh("data", "bh16.msh", 0)
+	if err != nil {
+		tst.Errorf("test failed:\n%v", err)
+		return
+	}
+	err = msh.AddSets([]*SetData{
+		{Name: "left", Kind: "verts", Tag: -901, Plane: []float64{1, 0, 10}},
+		{Name: "bottom", Kind: "faces", Tag: -902, Box: []float64{10, 14, -1, -1}},
+		{Name: "right", Kind: "cells", Tag: -903, Expr: "x > 11.9"},
+		{Name: "all", Kind: "cells", Tag: -904, Tags: []int{-1}},
+		{Name: "leftcells", Kind: "cells", Tag: -905, Diff: []string{"all", "right"}},
+		{Name: "both", Kind: "verts", Tag: -906, Union: []string{"left"}, Expr: "x > 13.9"},
+		{Name: "lower", Kind: "verts", Tag: -907, Inter: []string{"both"}, Box: []float64{10, 14, -1, -0.5}},
+	})
+	if err != nil {
+		tst.Errorf("AddSets failed:\n%v", err)
+		return
+	}
+
+	vids := func(tag int) (ids []int) {
+		for _, v := range msh.VertTag2verts[tag] {
+			ids = append(ids, v.Id)
+		}
+		return
+	}
+	cids := func(tag int) (ids []int) {
+		for _, c := range msh.CellTag2cells[tag] {
+			ids = append(ids, c.Id)
+		}
+		return
+	}
+	chk.Ints(tst, "left", vids(-901), []int{0, 1})
+	chk.Ints(tst, "right", cids(-903), []int{2, 3})
+	chk.Ints(tst, "all", cids(-904), []int{0, 1, 2, 3})
+	chk.Ints(tst, "leftcells", cids(-905), []int{0, 1})
+	chk.Ints(tst, "both", vids(-906), []int{0, 1, 4, 5})
+	chk.Ints(tst, "lower", vids(-907), []int{0, 4})
+
+	// faces
+	pairs := msh.FaceTag2cells[-902]
+	chk.IntAssert(len(pairs), 2)
+	chk.IntAssert(pairs[0].C.Id, 0)
+	chk.IntAssert(pairs[0].Fid, 0)
+	chk.IntAssert(pairs[1].C.Id, 2)
+	chk.IntAssert(pairs[1].Fid, 0)
+	chk.Ints(tst, "bottom: verts", msh.FaceTag2verts[-902], []int{0, 2, 4})
+	chk.Ints(tst, "bottom: cell 0 face 0", msh.Cells[0].FaceSets[0], []int{-902})
+
+	// errors
+	err = msh.AddSets([]*SetData{{Name: "dup", Kind: "verts", Tag: -100, Tags: []int{-100}}})
+	if err == nil {
+		tst.Errorf("tag already used in mesh should have caused an error\n")
+	}
+	err = msh.AddSets([]*SetData{{Name: "wrong", Kind: "cells", Tag: -950, Union: []string{"left"}}})
+	if err == nil {
+		tst.Errorf("set of different kind should have caused an error\n")
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_smooth01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("smooth01. Laplacian smoothing of distorted mesh")
+
+	msh, err := ReadMsh("data", "smooth01.msh", 0)
+	if err != nil {
+		tst.Errorf("test failed:\n%v", err)
+		return
+	}
+
+	// distorted mesh with inverted cell
+	qmin := msh.min_jratio([]int{0, 1, 2, 3, 4, 5, 6, 7, 8})
+	io.Pforan("qmin (before) = %v\n", qmin)
+	if qmin > 0 {
+		tst.Errorf("cell of distorted mesh should be inverted")
+		return
+	}
+
+	// invalid relaxation factor
+	_, err = msh.Smooth(10, 1.5)
+	if err == nil {
+		tst.Errorf("Smooth should have failed with ω = 1.5")
+		return
+	}
+
+	// deformed copy; this mesh must not be modified
+	x := make([][]float64, len(msh.Verts))
+	for i, v := range msh.Verts {
+		x[i] = []float64{v.C[0], v.C[1]}
+	}
+	m := msh.Deformed(x)
+	chk.Vector(tst, "limits", 1e-15, []float64{m.Xmin, m.Xmax, m.Ymin, m.Ymax}, []float64{0, 3, 0, 3})
+	moved, err := m.Smooth(100, 0.5)
+	if err != nil {
+		tst.Errorf("Smooth failed:\n%v", err)
+		return
+	}
+	chk.Ints(tst, "moved", moved, []int{5, 6, 9, 10})
+	chk.Vector(tst, "x5 (original)", 1e-15, msh.Verts[5].C, []float64{1.6, 1.5})
+
+	// interior vertices converge to regular grid; boundary vertices are not moved
+	for i, v := range m.Verts {
+		chk.Vector(tst, io.Sf("x%d", i), 1e-12, v.C, []float64{float64(i % 4), float64(i / 4)})
+	}
+	chk.Scalar(tst, "qmin (after)", 1e-12, m.min_jratio([]int{0, 1, 2, 3, 4, 5, 6, 7, 8}), 1)
+}
@@ -223,3 +223,15 @@ func (o Model) CalcLgs(res *LgsVars, sta *State, pl, pg, divus float64, derivs b
 	}
 	return
 }
+
+// PackStates returns copies of states stored in a contiguous array; thus loops over integration
+// points access memory sequentially. See also solid.PackStates
+func PackStates(states []*State) (packed []*State) {
+	structs := make([]State, len(states))
+	packed = make([]*State, len(states))
+	for i, s := range states {
+		structs[i] = *s
+		packed[i] = &structs[i]
+	}
+	return
+}
@@ -150,3 +150,29 @@ func Test_mdl01(tst *testing.T) {
 		retention.PlotEnd(true)
 	}
 }
+
+// bench_states runs a loop over the states of many elements like the update of saturations
+func bench_states(b *testing.B, soa bool) {
+	nele, nip := 4000, 27
+	elems := make([][]*State, nele)
+	for e := 0; e < nele; e++ {
+		elems[e] = make([]*State, nip)
+		for i := 0; i < nip; i++ {
+			elems[e][i] = &State{A_ns0: 0.7, A_sl: 1, A_ρL: 1, A_ρG: 0.001}
+		}
+		if soa {
+			elems[e] = PackStates(elems[e])
+		}
+	}
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for _, states := range elems {
+			for _, s := range states {
+				s.A_sl = 0.5 * (s.A_sl + s.A_ns0*s.A_ρL)
+			}
+		}
+	}
+}
+
+func Benchmark_states_aos(b *testing.B) { bench_states(b, false) }
+func Benchmark_states_soa(b *testing.B) { bench_states(b, true) }
@@ -0,0 +1,72 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retention
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/plt"
+)
+
+func Test_vghyst01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("vghyst01")
+
+	mdl := new(VgHyst)
+	prm := mdl.GetPrms(true)
+	prm.Find("pae").V = 2
+	err := mdl.Init(prm)
+	if err != nil {
+		tst.Errorf("init failed: %v\n", err)
+		return
+	}
+
+	pc0 := -5.0
+	sl0 := mdl.SlMax()
+	pcm := 10.0
+	pcf := 4.0
+	nptsA := 41
+	nptsB := 11
+
+	if chk.Verbose {
+		plt.Reset()
+		Plot(mdl, pc0, sl0, pcm, nptsA, false, "'b.-'", "'r+-'", "vghyst_drying")
+	}
+
+	// drying along main curve
+	tolCc := 1e-17
+	tolD1a, tolD1b := 1e-8, 1e-8
+	tolD2a, tolD2b := 1e-7, 1e-7
+	Check(tst, mdl, pc0, sl0, pcm, nptsB, tolCc, tolD1a, tolD1b, tolD2a, tolD2b, chk.Verbose, []float64{0, 2}, 1e-3, false)
+
+	slm, err := Update(mdl, pc0, sl0, pcm-pc0)
+	if err != nil {
+		tst.Errorf("update failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "sl(drying)", 1e-3, slm, mdl.SlDry(pcm))
+
+	// wetting along scanning curve
+	tolD1b = 1e-6
+	tolD2b = 1e-6
+	Check(tst, mdl, pcm, slm, pcf, nptsB, tolCc, tolD1a, tolD1b, tolD2a, tolD2b, chk.Verbose, []float64{0, 2}, 1e-3, false)
+
+	slf, err := Update(mdl, pcm, slm, pcf-pcm)
+	if err != nil {
+		tst.Errorf("update failed: %v\n", err)
+		return
+	}
+	if slf <= mdl.SlWet(pcf) || slf >= mdl.SlDry(pcf) {
+		tst.Errorf("sl = %g after reversal must be between main curves: %g < sl < %g\n", slf, mdl.SlWet(pcf), mdl.SlDry(pcf))
+	}
+
+	if chk.Verbose {
+		Plot(mdl, pcm, slm, pcf, nptsA, false, "'b*-'", "'r+:'", "vghyst_wetting")
+		PlotEnd(false)
+		plt.SaveD("/tmp/gofem", "fig_vghyst01.eps")
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retention
+
+import (
+	"math"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// VgHyst implements a hysteretic model with main drying and wetting curves of van Genuchten's type
+// and scanning curves in between
+//  Main curves:
+//    Sd(pc) = vg(pc - pae; αd)   drying (upper curve); pae is the air-entry value
+//    Sw(pc) = vg(pc; αw)         wetting (lower curve); αw ≥ αd
+//  Scanning curves: the slope is the slope of the main curve approached (bound) reduced by the
+//  normalised distance δ ∈ [0, 1] from the current state to the bound:
+//    drying:  Cc = Cd(pc)・exp(-β・δ)  with  δ = (Sd - sl) / (Sd - Sw)
+//    wetting: Cc = Cw(pc)・exp(-β・δ)  with  δ = (sl - Sw) / (Sd - Sw)
+//  Thus, reversals from a main curve start with the reduced slope exp(-β)・Cc and the state
+//  approaches the opposite main curve smoothly
+//  Note: (1) the model is of rate type; i.e. sl is a state variable integrated at each ip and the
+//            path dependence is given by the wetting flag
+//        (2) sl is clamped to the band between main curves when computing δ
+type VgHyst struct {
+	dry, wet *VanGen // main drying and wetting curves
+	pae      float64 // air-entry value: shift of main drying curve
+	β        float64 // rate of approach of scanning curves to the main curves
+}
+
+// add model to factory
+func init() {
+	allocators["vg-hyst"] = func() Model { return new(VgHyst) }
+}
+
+// Init initialises model
+func (o *VgHyst) Init(prms fun.Prms) (err error) {
+	o.β = 5
+	αd, αw := -1.0, -1.0
+	var common fun.Prms
+	for _, p := range prms {
+		switch strings.ToLower(p.N) {
+		case "alpd":
+			αd = p.V
+		case "alpw":
+			αw = p.V
+		case "pae":
+			o.pae = p.V
+		case "bet":
+			o.β = p.V
+		case "m", "n", "slmin", "slmax", "pcmin":
+			common = append(common, p)
+		default:
+			return chk.Err("vg-hyst: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if αd <= 0 || αw < αd {
+		return chk.Err("vg-hyst: parameters must satisfy 0 < alpd ≤ alpw. alpd = %g and alpw = %g are invalid\n", αd, αw)
+	}
+	if o.pae < 0 || o.β < 0 {
+		return chk.Err("vg-hyst: pae and bet must be non-negative. pae = %g and bet = %g are invalid\n", o.pae, o.β)
+	}
+	o.dry, o.wet = new(VanGen), new(VanGen)
+	err = o.dry.Init(append(common, &fun.Prm{N: "alp", V: αd}))
+	if err != nil {
+		return
+	}
+	return o.wet.Init(append(common, &fun.Prm{N: "alp", V: αw}))
+}
+
+// GetPrms gets (an example) of parameters
+func (o VgHyst) GetPrms(example bool) fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "alpd", V: 0.08},
+		&fun.Prm{N: "alpw", V: 0.16},
+		&fun.Prm{N: "pae", V: 0},
+		&fun.Prm{N: "bet", V: 5},
+		&fun.Prm{N: "m", V: 4},
+		&fun.Prm{N: "n", V: 4},
+		&fun.Prm{N: "slmin", V: 0.01},
+		&fun.Prm{N: "slmax", V: 1.0},
+		&fun.Prm{N: "pcmin", V: 1e-3},
+	}
+}
+
+// SlMin returns sl_min
+func (o VgHyst) SlMin() float64 {
+	return o.dry.slmin
+}
+
+// SlMax returns sl_max
+func (o VgHyst) SlMax() float64 {
+	return o.dry.slmax
+}
+
+// SlDry computes sl on the main drying curve
+func (o VgHyst) SlDry(pc float64) float64 {
+	return o.dry.Sl(pc - o.pae)
+}
+
+// SlWet computes sl on the main wetting curve
+func (o VgHyst) SlWet(pc float64) float64 {
+	return o.wet.Sl(pc)
+}
+
+// Cc computes Cc(pc) := dsl/dpc
+func (o VgHyst) Cc(pc, sl float64, wet bool) (float64, error) {
+	Cc, _, _, _, _, _ := o.calc(pc, sl, wet, false)
+	return Cc, nil
+}
+
+// L computes L = ∂Cc/∂pc
+func (o VgHyst) L(pc, sl float64, wet bool) (float64, error) {
+	_, L, _, _, _, _ := o.calc(pc, sl, wet, false)
+	return L, nil
+}
+
+// J computes J = ∂Cc/∂sl
+func (o VgHyst) J(pc, sl float64, wet bool) (float64, error) {
+	_, _, _, J, _, _ := o.calc(pc, sl, wet, false)
+	return J, nil
+}
+
+// Derivs compute ∂Cc/∂pc and ∂²Cc/∂pc²
+func (o VgHyst) Derivs(pc, sl float64, wet bool) (L, Lx, J, Jx, Jy float64, err error) {
+	_, L, Lx, J, Jx, Jy = o.calc(pc, sl, wet, true)
+	return
+}
+
+// calc computes Cc and its derivatives. Lx is only computed if second is true
+func (o VgHyst) calc(pc, sl float64, wet, second bool) (Cc, L, Lx, J, Jx, Jy float64) {
+
+	// main curves: values and derivatives w.r.t pc
+	xd := pc - o.pae
+	Sd, Sw := o.dry.Sl(xd), o.wet.Sl(pc)
+	Cd, _ := o.dry.Cc(xd, 0, false)
+	Cw, _ := o.wet.Cc(pc, 0, true)
+	var Ld, Lw, Lxd, Lxw float64
+	if second {
+		Ld, Lxd, _, _, _, _ = o.dry.Derivs(xd, 0, false)
+		Lw, Lxw, _, _, _, _ = o.wet.Derivs(pc, 0, true)
+	} else {
+		Ld, _ = o.dry.L(xd, 0, false)
+		Lw, _ = o.wet.L(pc, 0, true)
+	}
+
+	// bound and distance: δ = N / D
+	C, C1, C2 := Cd, Ld, Lxd
+	N, Nc, Nl := Sd-sl, Cd, Ld // drying
+	s := -1.0                  // ∂N/∂sl
+	if wet {
+		C, C1, C2 = Cw, Lw, Lxw
+		N, Nc, Nl = sl-Sw, -Cw, -Lw
+		s = 1.0
+	}
+	D, D1, D2 := Sd-Sw, Cd-Cw, Ld-Lw
+	if D < 1e-14 { // main curves coincide; e.g. pc ≤ pcmin
+		return C, C1, C2, 0, 0, 0
+	}
+	δ := N / D
+	δpc := (Nc - δ*D1) / D
+	δpcpc := (Nl - 2.0*δpc*D1 - δ*D2) / D
+	δsl := s / D
+	if δ < 0 || δ > 1 { // outside band
+		δ = math.Max(0, math.Min(1, δ))
+		δpc, δpcpc, δsl = 0, 0, 0
+	}
+
+	// Cc and derivatives
+	f := math.Exp(-o.β * δ)
+	fpc := -o.β * f * δpc
+	fsl := -o.β * f * δsl
+	Cc = C * f
+	L = C1*f + C*fpc
+	J = C * fsl
+	Jy = C * o.β * o.β * f * δsl * δsl
+	Jx = -o.β * (C1*f*δsl + C*fpc*δsl + C*f*(-s*D1/(D*D)))
+	if δsl == 0 {
+		Jx = 0
+	}
+	if second {
+		fpcpc := f * (o.β*o.β*δpc*δpc - o.β*δpcpc)
+		Lx = C2*f + 2.0*C1*fpc + C*fpcpc
+	}
+	return
+}
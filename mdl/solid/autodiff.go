@@ -0,0 +1,288 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// AD_NMAX is the maximum number of independent variables of dual numbers; i.e. the number of
+// components of Mandel vectors in 3D
+const AD_NMAX = 6
+
+// Dual implements dual numbers for the forward-mode automatic differentiation of functions of up
+// to AD_NMAX independent variables; i.e. x = V + Σ D[i] εi with εi εj = 0
+//  Note: (1) dual numbers are values; thus expressions such as a.Mul(b).Add(c) do not allocate memory
+//        (2) the derivatives of iterative algorithms (e.g. Newton's method) are exact after convergence
+type Dual struct {
+	V float64          // value
+	D [AD_NMAX]float64 // derivatives with respect to the independent variables
+}
+
+// DualConst returns a dual number with zero derivatives
+func DualConst(v float64) Dual {
+	return Dual{V: v}
+}
+
+// DualVar returns the dual number corresponding to the i-th independent variable
+func DualVar(v float64, i int) (x Dual) {
+	x.V, x.D[i] = v, 1
+	return
+}
+
+// Add returns a + b
+func (a Dual) Add(b Dual) Dual {
+	a.V += b.V
+	for i := 0; i < AD_NMAX; i++ {
+		a.D[i] += b.D[i]
+	}
+	return a
+}
+
+// Sub returns a - b
+func (a Dual) Sub(b Dual) Dual {
+	a.V -= b.V
+	for i := 0; i < AD_NMAX; i++ {
+		a.D[i] -= b.D[i]
+	}
+	return a
+}
+
+// Mul returns a * b
+func (a Dual) Mul(b Dual) (c Dual) {
+	c.V = a.V * b.V
+	for i := 0; i < AD_NMAX; i++ {
+		c.D[i] = a.D[i]*b.V + a.V*b.D[i]
+	}
+	return
+}
+
+// Div returns a / b
+func (a Dual) Div(b Dual) (c Dual) {
+	c.V = a.V / b.V
+	for i := 0; i < AD_NMAX; i++ {
+		c.D[i] = (a.D[i] - c.V*b.D[i]) / b.V
+	}
+	return
+}
+
+// Plus returns a + s where s is a scalar
+func (a Dual) Plus(s float64) Dual {
+	a.V += s
+	return a
+}
+
+// Scale returns s * a where s is a scalar
+func (a Dual) Scale(s float64) Dual {
+	a.V *= s
+	for i := 0; i < AD_NMAX; i++ {
+		a.D[i] *= s
+	}
+	return a
+}
+
+// Neg returns -a
+func (a Dual) Neg() Dual {
+	return a.Scale(-1)
+}
+
+// Sqrt returns sqrt(a)
+func (a Dual) Sqrt() Dual {
+	v := math.Sqrt(a.V)
+	return a.chain(v, 0.5/v)
+}
+
+// Exp returns exp(a)
+func (a Dual) Exp() Dual {
+	v := math.Exp(a.V)
+	return a.chain(v, v)
+}
+
+// Log returns ln(a)
+func (a Dual) Log() Dual {
+	return a.chain(math.Log(a.V), 1.0/a.V)
+}
+
+// Pow returns a^n
+func (a Dual) Pow(n float64) Dual {
+	return a.chain(math.Pow(a.V, n), n*math.Pow(a.V, n-1.0))
+}
+
+// Abs returns |a|
+func (a Dual) Abs() Dual {
+	if a.V < 0 {
+		return a.Neg()
+	}
+	return a
+}
+
+// Sin returns sin(a)
+func (a Dual) Sin() Dual {
+	return a.chain(math.Sin(a.V), math.Cos(a.V))
+}
+
+// Cos returns cos(a)
+func (a Dual) Cos() Dual {
+	return a.chain(math.Cos(a.V), -math.Sin(a.V))
+}
+
+// Asin returns asin(a)
+func (a Dual) Asin() Dual {
+	return a.chain(math.Asin(a.V), 1.0/math.Sqrt(1.0-a.V*a.V))
+}
+
+// chain returns f(a) given f(a.V) and f'(a.V)
+func (a Dual) chain(f, df float64) Dual {
+	a.V = f
+	for i := 0; i < AD_NMAX; i++ {
+		a.D[i] *= df
+	}
+	return a
+}
+
+// Mandel vectors of dual numbers ///////////////////////////////////////////////////////////////////
+
+// AdVars returns the dual numbers corresponding to the independent variables x
+func AdVars(x []float64) (a []Dual) {
+	if len(x) > AD_NMAX {
+		chk.Panic("AdVars: the number of independent variables (%d) must not exceed %d", len(x), AD_NMAX)
+	}
+	a = make([]Dual, len(x))
+	for i, v := range x {
+		a[i] = DualVar(v, i)
+	}
+	return
+}
+
+// AdConsts returns dual numbers with zero derivatives
+func AdConsts(x []float64) (a []Dual) {
+	a = make([]Dual, len(x))
+	for i, v := range x {
+		a[i].V = v
+	}
+	return
+}
+
+// AdValues copies the values of a into v
+func AdValues(v []float64, a []Dual) {
+	for i := 0; i < len(a); i++ {
+		v[i] = a[i].V
+	}
+}
+
+// AdJacobian copies the derivatives of a into J; i.e. J[i][j] = ∂a[i]/∂xj
+func AdJacobian(J [][]float64, a []Dual) {
+	for i := 0; i < len(J); i++ {
+		for j := 0; j < len(J[i]); j++ {
+			J[i][j] = a[i].D[j]
+		}
+	}
+}
+
+// AdM_Tr returns the trace of a (Mandel vector)
+func AdM_Tr(a []Dual) Dual {
+	return a[0].Add(a[1]).Add(a[2])
+}
+
+// AdM_p returns the mean pressure p = -tr(a)/3
+func AdM_p(a []Dual) Dual {
+	return AdM_Tr(a).Scale(-1.0 / 3.0)
+}
+
+// AdM_Dev computes the deviatoric tensor s := dev(a)
+func AdM_Dev(s, a []Dual) {
+	m := AdM_Tr(a).Scale(1.0 / 3.0)
+	for i := 0; i < len(a); i++ {
+		s[i] = a[i]
+		if i < 3 {
+			s[i] = s[i].Sub(m)
+		}
+	}
+}
+
+// AdM_Dot returns a : b
+func AdM_Dot(a, b []Dual) (c Dual) {
+	for i := 0; i < len(a); i++ {
+		c = c.Add(a[i].Mul(b[i]))
+	}
+	return
+}
+
+// AdM_Norm returns |a| = sqrt(a : a)
+//  Note: the derivatives of the null tensor are zero
+func AdM_Norm(a []Dual) Dual {
+	n := AdM_Dot(a, a)
+	if n.V <= 0 {
+		return Dual{}
+	}
+	return n.Sqrt()
+}
+
+// AdM_q returns the deviatoric stress invariant q = sqrt(3/2) |dev(a)|
+func AdM_q(a []Dual) Dual {
+	var s [AD_NMAX]Dual
+	AdM_Dev(s[:len(a)], a)
+	return AdM_Norm(s[:len(a)]).Scale(math.Sqrt(1.5))
+}
+
+// AdM_Det returns the determinant of a (Mandel vector)
+func AdM_Det(a []Dual) Dual {
+	var a3, a4, a5 Dual
+	a3 = a[3].Scale(1.0 / math.Sqrt2)
+	if len(a) > 4 {
+		a4, a5 = a[4].Scale(1.0/math.Sqrt2), a[5].Scale(1.0/math.Sqrt2)
+	}
+	return a[0].Mul(a[1]).Mul(a[2]).Add(a3.Mul(a4).Mul(a5).Scale(2)).
+		Sub(a[0].Mul(a4).Mul(a4)).Sub(a[1].Mul(a5).Mul(a5)).Sub(a[2].Mul(a3).Mul(a3))
+}
+
+// AdM_Elast computes σ := σ0 + K tr(Δε) I + 2G dev(Δε)
+func AdM_Elast(σ []Dual, σ0 []float64, Δε []Dual, K, G float64) {
+	trΔε := AdM_Tr(Δε)
+	for i := 0; i < len(σ); i++ {
+		σ[i] = Δε[i].Sub(trΔε.Scale(tsr.Im[i] / 3.0)).Scale(2.0 * G).Add(trΔε.Scale(K * tsr.Im[i])).Plus(σ0[i])
+	}
+}
+
+// automatic consistent tangent ////////////////////////////////////////////////////////////////////
+
+// AdSmall defines small-strain models whose stress update is (also) written with dual numbers
+// such that the consistent tangent D = dσ_new/dε_new is obtained by automatic differentiation
+//  UpdateAd computes the new stresses σ for the strain increment Δε (seeded as independent
+//  variables) from the state s at the beginning of the increment; UpdateAd also updates the
+//  internal variables and flags of s but must not modify s.Sig
+type AdSmall interface {
+	UpdateAd(σ []Dual, s *State, Δε []Dual, eid, ipid int, time float64) error
+}
+
+// AdUpdate updates the state of an AdSmall model and stores the consistent tangent in s.Dad;
+// e.g. to be called in Update during the development of models (see AdCalcD)
+func AdUpdate(m AdSmall, s *State, Δε []float64, eid, ipid int, time float64) (err error) {
+	nsig := len(s.Sig)
+	σ := make([]Dual, nsig)
+	err = m.UpdateAd(σ, s, AdVars(Δε[:nsig]), eid, ipid, time)
+	if err != nil {
+		return
+	}
+	if len(s.Dad) != nsig {
+		s.Dad = la.MatAlloc(nsig, nsig)
+	}
+	AdValues(s.Sig, σ)
+	AdJacobian(s.Dad, σ)
+	return
+}
+
+// AdCalcD returns the consistent tangent computed by AdUpdate; e.g. to be called in CalcD
+func AdCalcD(D [][]float64, s *State) (err error) {
+	if len(s.Dad) == 0 {
+		return chk.Err("consistent tangent computed by automatic differentiation is not available. AdUpdate must be called first")
+	}
+	la.MatCopy(D, 1, s.Dad)
+	return
+}
@@ -0,0 +1,322 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// BoundingSurf implements a bounding surface plasticity model with radial mapping for clays
+//  Notation (compression positive):
+//   σc = -σ,  p = tr(σc)/3,  s = dev(σc)  and  q² = (3/2) s:s
+//  Bounding surface (modified Cam clay ellipse):
+//   F(σ̄, pc) = q̄² / M² + p̄ (p̄ - pc)
+//  Radial mapping: the image point σ̄ on the bounding surface is given by
+//   σ̄ = αc + b (σc - αc)   with   b ≥ 1
+//  where αc is the projection center, which is placed at the stress point of the last load reversal
+//  Plastic modulus:
+//   Kp = K̄p + h ⋅ pc ⋅ (b - 1)
+//  where K̄p is the plastic modulus at the image point (from the consistency condition) and h is a
+//  shape parameter. The loading direction n is the (normalised) gradient of F at the image point
+//  Hardening:
+//   dpc = pc ⋅ dεvp / (λ* - κ*)
+//  Elasticity:
+//   K = p / κ*   and   G = 3 K (1 - 2 νur) / (2 (1 + νur))
+//  Internal variables:
+//   α[0]        = pc -- size of bounding surface
+//   α[1:1+nsig] = αc -- projection center (compression positive)
+//  Note: the projection center is initially at the origin; hence, virgin loading of normally
+//        consolidated states occurs on the bounding surface. The stress update employs explicit
+//        sub-steps
+//  References:
+//   [1] Dafalias YF and Herrmann LR. Bounding surface plasticity. II: Application to isotropic
+//       cohesive soils. Journal of Engineering Mechanics, 112(12):1263-1291; 1986
+type BoundingSurf struct {
+
+	// basic data
+	Nsig int // number of σ and ε components
+
+	// parameters
+	M    float64 // slope of critical state line
+	λs   float64 // λ*: modified compression index
+	κs   float64 // κ*: modified swelling index
+	νur  float64 // Poisson's coefficient for unloading/reloading
+	h    float64 // shape parameter for the plastic modulus
+	ocr  float64 // initial over-consolidation ratio
+	pmin float64 // minimum pressure to compute elastic moduli
+	nsub int     // number of sub-steps
+	rho  float64 // density
+
+	// auxiliary
+	σc  []float64   // compression-positive stress
+	dε  []float64   // compression-positive strain increment of sub-step
+	n   []float64   // loading direction
+	Dn  []float64   // De ⋅ n
+	Δσ  []float64   // stress increment
+	tmp []float64   // temporary vector
+	De  [][]float64 // elastic modulus
+}
+
+// constants
+const (
+	BSURF_BMAX = 1e6 // maximum value of the mapping factor b (image point far away)
+)
+
+// add model to factory
+func init() {
+	allocators["bsurf"] = func() Model { return new(BoundingSurf) }
+}
+
+// Clean clean resources
+func (o *BoundingSurf) Clean() {
+}
+
+// GetRho returns density
+func (o *BoundingSurf) GetRho() float64 {
+	return o.rho
+}
+
+// Init initialises model
+func (o *BoundingSurf) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// basic data
+	o.Nsig = 2 * ndim
+
+	// parameters
+	o.M, o.νur, o.h, o.ocr, o.pmin, o.nsub = 1, 0.2, 10, 1, 1e-3, 10
+	φ := -1.0
+	for _, p := range prms {
+		switch p.N {
+		case "M":
+			o.M = p.V
+		case "phi":
+			φ = p.V
+		case "lamS":
+			o.λs = p.V
+		case "kapS":
+			o.κs = p.V
+		case "nur":
+			o.νur = p.V
+		case "h":
+			o.h = p.V
+		case "ocr":
+			o.ocr = p.V
+		case "pmin":
+			o.pmin = p.V
+		case "nsub":
+			o.nsub = int(p.V)
+		case "rho":
+			o.rho = p.V
+		}
+	}
+	if φ > 0 {
+		o.M, _, err = Mmatch(0, φ, 0)
+		if err != nil {
+			return
+		}
+	}
+
+	// check
+	if o.κs <= 0 || o.λs <= o.κs {
+		return chk.Err("bounding surface: parameters must satisfy 0 < kapS < lamS. kapS=%g, lamS=%g are invalid\n", o.κs, o.λs)
+	}
+	if o.M <= 0 || o.h < 0 || o.pmin <= 0 || o.nsub < 1 {
+		return chk.Err("bounding surface: M, pmin and nsub must be positive and h must be non-negative. M=%g, pmin=%g, nsub=%d, h=%g are invalid\n", o.M, o.pmin, o.nsub, o.h)
+	}
+
+	// auxiliary
+	o.σc = make([]float64, o.Nsig)
+	o.dε = make([]float64, o.Nsig)
+	o.n = make([]float64, o.Nsig)
+	o.Dn = make([]float64, o.Nsig)
+	o.Δσ = make([]float64, o.Nsig)
+	o.tmp = make([]float64, o.Nsig)
+	o.De = la.MatAlloc(o.Nsig, o.Nsig)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o *BoundingSurf) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "M", V: 1.2},
+		&fun.Prm{N: "lamS", V: 0.1},
+		&fun.Prm{N: "kapS", V: 0.02},
+		&fun.Prm{N: "nur", V: 0.2},
+		&fun.Prm{N: "h", V: 10},
+		&fun.Prm{N: "ocr", V: 1},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o *BoundingSurf) InitIntVars(σ []float64) (s *State, err error) {
+	nalp := 1 + o.Nsig // alp[0] = pc, alp[1:] = αc
+	s = NewState(o.Nsig, nalp, false, false)
+	copy(s.Sig, σ)
+	p, q := tsr.M_p(σ), tsr.M_q(σ)
+	if p <= 0 {
+		return nil, chk.Err("bounding surface: initial mean pressure must be positive. p=%g is invalid\n", p)
+	}
+	s.Alp[0] = o.ocr * (p + q*q/(o.M*o.M*p))
+	return
+}
+
+// IntVarNames returns the names of internal variables
+func (o *BoundingSurf) IntVarNames() []string {
+	return append([]string{"p0"}, ivnames("alpc", o.Nsig)...)
+}
+
+// Update updates stresses for given strains
+func (o *BoundingSurf) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+	s.Loading = false
+	s.Dgam = 0
+	for i := 0; i < o.Nsig; i++ {
+		o.σc[i] = -s.Sig[i]
+	}
+	for k := 0; k < o.nsub; k++ {
+
+		// compression-positive strain increment
+		for i := 0; i < o.Nsig; i++ {
+			o.dε[i] = -Δε[i] / float64(o.nsub)
+		}
+
+		// loading index
+		o.calc_De(o.σc)
+		Kp, ok := o.mapping(o.σc, s.Alp)
+		la.MatVecMul(o.Δσ, 1, o.De, o.dε)
+		L := 0.0
+		if ok {
+			la.MatVecMul(o.Dn, 1, o.De, o.n)
+			L = la.VecDot(o.n, o.Δσ) / (la.VecDot(o.n, o.Dn) + Kp)
+		}
+
+		// elastic: at projection center or unloading; in the latter case, the projection center
+		// moves to the reversal point
+		if L <= 0 {
+			if ok {
+				copy(s.Alp[1:], o.σc)
+			}
+			for i := 0; i < o.Nsig; i++ {
+				o.σc[i] += o.Δσ[i]
+			}
+			continue
+		}
+
+		// loading => elastic-plastic
+		for i := 0; i < o.Nsig; i++ {
+			o.σc[i] += o.Δσ[i] - L*o.Dn[i]
+		}
+		εvp := L * (o.n[0] + o.n[1] + o.n[2])
+		s.Alp[0] *= math.Exp(εvp / (o.λs - o.κs))
+		s.Dgam += L
+		s.Loading = true
+	}
+	for i := 0; i < o.Nsig; i++ {
+		s.Sig[i] = -o.σc[i]
+	}
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+//  Note: the continuum elastoplastic modulus is returned
+func (o *BoundingSurf) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+	for i := 0; i < o.Nsig; i++ {
+		o.σc[i] = -s.Sig[i]
+	}
+	o.calc_De(o.σc)
+	la.MatCopy(D, 1, o.De)
+	if !s.Loading {
+		return
+	}
+	Kp, ok := o.mapping(o.σc, s.Alp)
+	if !ok {
+		return
+	}
+	la.MatVecMul(o.Dn, 1, o.De, o.n)
+	den := la.VecDot(o.n, o.Dn) + Kp
+	if den <= 0 {
+		return chk.Err("bounding surface: cannot compute modulus. n:De:n + Kp = %g ≤ 0\n", den)
+	}
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			D[i][j] -= o.Dn[i] * o.Dn[j] / den
+		}
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *BoundingSurf) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// mapping finds the image point and computes the loading direction n and the plastic modulus Kp
+//  Note: ok == false if σc coincides with the projection center (n is undefined)
+func (o *BoundingSurf) mapping(σc, alp []float64) (Kp float64, ok bool) {
+
+	// quadratic equation F(b) = A b² + B b + C = 0 with d = σc - αc
+	pc, αc := alp[0], alp[1:]
+	pa := (αc[0] + αc[1] + αc[2]) / 3.0
+	pd := (σc[0]+σc[1]+σc[2])/3.0 - pa
+	c := 1.5 / (o.M * o.M)
+	var sdsd, sasd, sasa, sa, sd float64
+	for i := 0; i < o.Nsig; i++ {
+		sa = αc[i] - pa*tsr.Im[i]
+		sd = σc[i] - αc[i] - pd*tsr.Im[i]
+		sdsd += sd * sd
+		sasd += sa * sd
+		sasa += sa * sa
+	}
+	A := c*sdsd + pd*pd
+	B := 2.0*c*sasd + 2.0*pa*pd - pc*pd
+	C := c*sasa + pa*pa - pc*pa
+	if A < 1e-14*pc*pc {
+		return
+	}
+	Δ := math.Max(B*B-4.0*A*C, 0)
+	b := (-B + math.Sqrt(Δ)) / (2.0 * A)
+	b = math.Min(math.Max(b, 1), BSURF_BMAX)
+
+	// image point and gradient of F
+	for i := 0; i < o.Nsig; i++ {
+		o.tmp[i] = αc[i] + b*(σc[i]-αc[i])
+	}
+	pb := (o.tmp[0] + o.tmp[1] + o.tmp[2]) / 3.0
+	for i := 0; i < o.Nsig; i++ {
+		o.n[i] = 2.0*c*(o.tmp[i]-pb*tsr.Im[i]) + (2.0*pb-pc)*tsr.Im[i]/3.0
+	}
+	nrm := la.VecNorm(o.n)
+	if nrm < 1e-14*pc {
+		return
+	}
+	la.VecScale(o.n, 0, 1.0/nrm, o.n)
+
+	// plastic modulus
+	trn := o.n[0] + o.n[1] + o.n[2]
+	Kpb := pb * pc * trn / ((o.λs - o.κs) * nrm)
+	Kp = Kpb + o.h*pc*(b-1.0)
+	ok = true
+	return
+}
+
+// calc_De computes the (stress dependent) elastic modulus
+func (o *BoundingSurf) calc_De(σc []float64) {
+	p := math.Max((σc[0]+σc[1]+σc[2])/3.0, o.pmin)
+	K := p / o.κs
+	G := Calc_G_from_Knu(K, o.νur)
+	a := K - 2.0*G/3.0
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			o.De[i][j] = a * tsr.Im[i] * tsr.Im[j]
+		}
+		o.De[i][i] += 2.0 * G
+	}
+}
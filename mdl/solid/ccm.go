@@ -37,6 +37,7 @@ type CamClayMod struct {
 // add model to factory
 func init() {
 	allocators["ccm"] = func() Model { return new(CamClayMod) }
+	intvars["ccm"] = []string{"p0"}
 }
 
 // Clean clean resources
@@ -0,0 +1,194 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// Cohesive implements a cohesive law with exponential softening for cracks (quasi-brittle materials)
+//  The normal traction follows the envelope
+//     f(κ) = kn・κ                          if κ ≤ w0 = ft/kn
+//     f(κ) = ft・exp(-ft・(κ - w0) / gf)     otherwise
+//  where κ is the maximum opening reached so far. Unloading and reloading follow the secant
+//  stiffness ks(κ) = f(κ)/κ and the shear stiffness is reduced by the same factor; i.e.
+//     tn = ks(κ)・wn   and   ts = ks(κ)/kn・ks・ws
+//  Note: (1) kn is a penalty (initially rigid) stiffness; thus kn should be large compared to ft/wc,
+//            where wc = gf/ft is the characteristic opening. The fracture energy is gf + ft・w0/2
+//        (2) the crack is closed in compression (wn < 0) with the stiffness kn
+//        (3) the secant stiffness is bounded by kr・kn to avoid singular matrices
+//        (4) state: EpsE holds the total relative displacements and Alp = {κ, open}
+type Cohesive struct {
+	Kn   float64 // normal (penalty) stiffness
+	Ks   float64 // shear stiffness
+	Ft   float64 // tensile strength
+	Gf   float64 // fracture energy (of softening branch)
+	Kr   float64 // ratio of residual stiffness
+	Ndim int     // space dimension
+
+	// derived
+	W0 float64 // opening at peak traction: ft/kn
+}
+
+// add model to factory
+func init() {
+	allocators["cohesive"] = func() Model { return new(Cohesive) }
+}
+
+// Clean clean resources
+func (o *Cohesive) Clean() {
+}
+
+// GetRho returns density
+func (o *Cohesive) GetRho() float64 {
+	return 0
+}
+
+// Init initialises model
+func (o *Cohesive) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+	o.Ndim = ndim
+	o.Kr = 1e-6
+	for _, p := range prms {
+		switch p.N {
+		case "kn":
+			o.Kn = p.V
+		case "ks":
+			o.Ks = p.V
+		case "ft":
+			o.Ft = p.V
+		case "gf":
+			o.Gf = p.V
+		case "kr":
+			o.Kr = p.V
+		}
+	}
+	if o.Kn <= 0 || o.Ks < 0 || o.Ft <= 0 || o.Gf <= 0 {
+		return chk.Err("invalid parameters: {kn=%g, ft=%g, gf=%g} must be > 0 and ks=%g must be >= 0", o.Kn, o.Ft, o.Gf, o.Ks)
+	}
+	if o.Kr <= 0 || o.Kr >= 1 {
+		return chk.Err("invalid parameters: kr=%g must be in ]0,1[", o.Kr)
+	}
+	o.W0 = o.Ft / o.Kn
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o Cohesive) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e9},
+		&fun.Prm{N: "ks", V: 1e8},
+		&fun.Prm{N: "ft", V: 3e3},
+		&fun.Prm{N: "gf", V: 0.1},
+		&fun.Prm{N: "kr", V: 1e-6},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Input:
+//   σ -- initial tractions {tn, ts1, [ts2]}; must be zero because the crack is initially closed
+func (o Cohesive) InitIntVars(σ []float64) (s *State, err error) {
+	if len(σ) != o.Ndim {
+		return nil, chk.Err("number of components of tractions (%d) must be equal to ndim (%d)", len(σ), o.Ndim)
+	}
+	s = NewState(o.Ndim, 2, false, false)
+	for i := 0; i < o.Ndim; i++ {
+		if σ[i] != 0 {
+			return nil, chk.Err("initial tractions of cohesive cracks must be zero. t = %v is invalid", σ)
+		}
+	}
+	return
+}
+
+// IntVarNames returns the names of internal variables
+func (o Cohesive) IntVarNames() []string {
+	return []string{"kappa", "open"}
+}
+
+// IsOpen returns whether the crack is open (softening) or not
+func (o Cohesive) IsOpen(s *State) bool {
+	return s.Alp[1] > 0
+}
+
+// Leakance returns the (mass) leakance across the crack; zero
+func (o Cohesive) Leakance(s *State) float64 {
+	return 0
+}
+
+// TensileStrength returns the tensile strength ft
+func (o Cohesive) TensileStrength() float64 {
+	return o.Ft
+}
+
+// Update updates tractions for given increment of relative displacements
+//  Note: time is not used
+func (o Cohesive) Update(s *State, Δw []float64, time float64) (err error) {
+
+	// relative displacements and maximum opening
+	for i := 0; i < o.Ndim; i++ {
+		s.EpsE[i] += Δw[i]
+	}
+	w := s.EpsE
+	κ := &s.Alp[0]
+	s.Loading = false
+	if w[0] > *κ {
+		*κ = w[0]
+		s.Loading = true
+	}
+	if *κ > o.W0 {
+		s.Alp[1] = 1
+	}
+
+	// tractions
+	ks, _ := o.secant(*κ)
+	s.Sig[0] = o.Kn * w[0]
+	if w[0] > 0 {
+		s.Sig[0] = ks * w[0]
+	}
+	for i := 1; i < o.Ndim; i++ {
+		s.Sig[i] = ks / o.Kn * o.Ks * w[i]
+	}
+	return
+}
+
+// CalcD computes D = dt_new/dw_new consistent with Update
+func (o Cohesive) CalcD(D [][]float64, s *State) (err error) {
+	la.MatFill(D, 0)
+	w, κ := s.EpsE, s.Alp[0]
+	ks, dksdκ := o.secant(κ)
+	D[0][0] = o.Kn
+	if w[0] > 0 {
+		D[0][0] = ks
+	}
+	for i := 1; i < o.Ndim; i++ {
+		D[i][i] = ks / o.Kn * o.Ks
+	}
+	if s.Loading {
+		D[0][0] += dksdκ * w[0]
+		for i := 1; i < o.Ndim; i++ {
+			D[i][0] = dksdκ / o.Kn * o.Ks * w[i]
+		}
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// secant returns the secant stiffness f(κ)/κ and its derivative with respect to κ
+func (o Cohesive) secant(κ float64) (ks, dksdκ float64) {
+	if κ <= o.W0 {
+		return o.Kn, 0
+	}
+	f := o.Ft * math.Exp(-o.Ft*(κ-o.W0)/o.Gf)
+	ks = f / κ
+	if ks < o.Kr*o.Kn {
+		return o.Kr * o.Kn, 0
+	}
+	dksdκ = (-o.Ft/o.Gf*f*κ - f) / (κ * κ)
+	return
+}
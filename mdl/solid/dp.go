@@ -24,6 +24,7 @@ type DruckerPrager struct {
 // add model to factory
 func init() {
 	allocators["dp"] = func() Model { return new(DruckerPrager) }
+	intvars["dp"] = []string{"alpha"}
 }
 
 // Clean clean resources
@@ -65,7 +66,9 @@ func (o *DruckerPrager) Init(ndim int, pstress bool, prms fun.Prms) (err error)
 			o.rho = p.V
 		case "E", "nu", "l", "G", "K":
 		default:
-			return chk.Err("dp: parameter named %q is incorrect\n", p.N)
+			if !KgcPrm(p.N) {
+				return chk.Err("dp: parameter named %q is incorrect\n", p.N)
+			}
 		}
 	}
 
@@ -113,6 +116,10 @@ func (o *DruckerPrager) Update(s *State, ε, Δε []float64, eid, ipid int, time
 	s.ApexReturn = false // => not return-to-apex
 	s.Dgam = 0           // Δγ := 0
 
+	// non-linear elasticity
+	o.StartIncrement(s)
+	defer o.EndIncrement(s)
+
 	// accessors
 	σ := s.Sig
 	α0 := &s.Alp[0]
@@ -0,0 +1,177 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// DPTensionCut implements the Drucker-Prager model with a tension cut-off (on the mean stress)
+// using the generic return-mapping algorithm (see RetMap). The yield surfaces are
+//  cone:    f1 = q - M p - qy0 - H α   with   g1 = q - Mb p
+//  cut-off: f2 = -p - pt               (associated)
+//  Note: (1) the returns to the corner between the cone and the cut-off plane are computed with
+//            Koiter's rule; thus pt < qy0 / M is required
+//        (2) only linear elasticity is available
+type DPTensionCut struct {
+	SmallElasticity
+	M    float64 // slope of fc line
+	Mb   float64 // slope of fc line of plastic potential
+	qy0  float64 // initial qy
+	H    float64 // hardening variable
+	Pt   float64 // tensile strength in terms of the mean stress; i.e. p ≥ -pt
+	rho  float64 // density
+	rmap RetMap  // return-mapping
+}
+
+// add model to factory
+func init() {
+	allocators["dp-tc"] = func() Model { return new(DPTensionCut) }
+	intvars["dp-tc"] = []string{"alpha"}
+}
+
+// Clean clean resources
+func (o *DPTensionCut) Clean() {
+}
+
+// GetRho returns density
+func (o *DPTensionCut) GetRho() float64 {
+	return o.rho
+}
+
+// Init initialises model
+func (o *DPTensionCut) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// parse parameters
+	if pstress {
+		return chk.Err("dp-tc: plane-stress analyses are not available")
+	}
+	err = o.SmallElasticity.Init(ndim, pstress, prms)
+	if err != nil {
+		return
+	}
+	for _, p := range prms {
+		switch p.N {
+		case "M":
+			o.M = p.V
+		case "Mb":
+			o.Mb = p.V
+		case "qy0":
+			o.qy0 = p.V
+		case "H":
+			o.H = p.V
+		case "pt":
+			o.Pt = p.V
+		case "rho":
+			o.rho = p.V
+		case "E", "nu", "l", "G", "K":
+		default:
+			return chk.Err("dp-tc: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.Pt < 0 || (o.M > 0 && o.Pt*o.M >= o.qy0) {
+		return chk.Err("dp-tc: the tensile strength must satisfy 0 ≤ pt < qy0/M. pt=%g is invalid", o.Pt)
+	}
+
+	// return-mapping
+	return o.rmap.Init(ndim, 1, o.K, o.G, []RmSurface{
+		dptc_cone{o.M, o.Mb, o.qy0, o.H},
+		dptc_cut{o.Pt},
+	})
+}
+
+// GetPrms gets (an example) of parameters
+func (o DPTensionCut) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "M", V: 1},
+		&fun.Prm{N: "Mb", V: 1},
+		&fun.Prm{N: "qy0", V: 0.5},
+		&fun.Prm{N: "H", V: 0},
+		&fun.Prm{N: "pt", V: 0.1},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o DPTensionCut) InitIntVars(σ []float64) (s *State, err error) {
+	s = NewState(o.Nsig, 1, false, false)
+	copy(s.Sig, σ)
+	return
+}
+
+// Update updates stresses for given strains
+func (o *DPTensionCut) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+	return o.rmap.Update(s, Δε)
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o *DPTensionCut) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+	return o.rmap.CalcD(D, s)
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *DPTensionCut) ContD(D [][]float64, s *State) (err error) {
+	return chk.Err("ContD is not available in dp-tc model")
+}
+
+// YieldFuncs computes the yield functions
+func (o DPTensionCut) YieldFuncs(s *State) []float64 {
+	σ, α := AdConsts(s.Sig), AdConsts(s.Alp)
+	return []float64{o.rmap.Surfs[0].F(σ, α).V, o.rmap.Surfs[1].F(σ, α).V}
+}
+
+// surfaces /////////////////////////////////////////////////////////////////////////////////////////
+
+// dptc_cone implements the Drucker-Prager cone
+type dptc_cone struct {
+	M, Mb, qy0, H float64
+}
+
+// F returns f = q - M p - qy0 - H α
+func (o dptc_cone) F(σ, α []Dual) Dual {
+	return AdM_q(σ).Sub(AdM_p(σ).Scale(o.M)).Sub(α[0].Scale(o.H)).Plus(-o.qy0)
+}
+
+// Nb returns Nb = ∂g/∂σ = 3 dev(σ) / (2q) + Mb I / 3
+func (o dptc_cone) Nb(Nb, σ, α []Dual) {
+	q := AdM_q(σ)
+	AdM_Dev(Nb, σ)
+	for i := 0; i < len(σ); i++ {
+		if q.V > 0 {
+			Nb[i] = Nb[i].Scale(1.5).Div(q)
+		} else {
+			Nb[i] = Dual{}
+		}
+		Nb[i] = Nb[i].Plus(o.Mb * tsr.Im[i] / 3.0)
+	}
+}
+
+// Hard returns h = 1; i.e. α is the accumulated plastic multiplier
+func (o dptc_cone) Hard(h, σ, α []Dual) {
+	h[0] = DualConst(1)
+}
+
+// dptc_cut implements the tension cut-off
+type dptc_cut struct {
+	pt float64
+}
+
+// F returns f = -p - pt
+func (o dptc_cut) F(σ, α []Dual) Dual {
+	return AdM_p(σ).Neg().Plus(-o.pt)
+}
+
+// Nb returns Nb = ∂f/∂σ = I / 3
+func (o dptc_cut) Nb(Nb, σ, α []Dual) {
+	for i := 0; i < len(σ); i++ {
+		Nb[i] = DualConst(tsr.Im[i] / 3.0)
+	}
+}
+
+// Hard returns h = 0
+func (o dptc_cut) Hard(h, σ, α []Dual) {
+	h[0] = Dual{}
+}
@@ -26,6 +26,7 @@ type Driver struct {
 	TolD    float64 // tolerance to check consistent matrix
 	VerD    bool    // verbose check of D
 	WithPC  bool    // with predictor-corrector data
+	Noise   *Noise  // perturbation of the normal components of strain increments (reproducible); nil => none
 
 	// results
 	Res []*State    // stress/ivs results
@@ -109,6 +110,7 @@ func (o *Driver) Run(pth *Path) (err error) {
 	// auxiliary variables
 	Δσ := make([]float64, o.nsig)
 	Δε := make([]float64, o.nsig)
+	Δε0 := make([]float64, o.nsig)
 
 	// variables for checking D
 	var tmp float64
@@ -163,8 +165,16 @@ func (o *Driver) Run(pth *Path) (err error) {
 			Δε[0] = pth.MultE * (pth.Ex[i] - pth.Ex[i-1]) / float64(pth.Nincs)
 			Δε[1] = pth.MultE * (pth.Ey[i] - pth.Ey[i-1]) / float64(pth.Nincs)
 			Δε[2] = pth.MultE * (pth.Ez[i] - pth.Ez[i-1]) / float64(pth.Nincs)
+			copy(Δε0, Δε)
 			for inc := 0; inc < pth.Nincs; inc++ {
 
+				// perturbation of strain increment; depends on the index of result (k) only
+				if o.Noise != nil {
+					for j := 0; j < 3; j++ {
+						Δε[j] = Δε0[j] + o.Noise.Value(k, j)
+					}
+				}
+
 				// update strains
 				la.VecAdd2(o.Eps[k], 1, o.Eps[k-1], 1, Δε) // εnew = εold + Δε
 
@@ -45,6 +45,7 @@ type SmallElasticity struct {
 	rho   float64      // density
 	Pse   bool         // is plane-stress?
 	Kgc   KGcalculator // K and G calculator for non-linear models
+	sig0  []float64    // stresses at the beginning of increment (non-linear models)
 }
 
 // GetRho returns density
@@ -56,6 +57,7 @@ func (o *SmallElasticity) GetRho() float64 {
 func (o *SmallElasticity) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
 	o.Nsig = 2 * ndim
 	o.Pse = pstress
+	o.Kgc = nil
 	var has_E, has_ν, has_l, has_G, has_K bool
 	for _, p := range prms {
 		switch p.N {
@@ -73,10 +75,11 @@ func (o *SmallElasticity) Init(ndim int, pstress bool, prms fun.Prms) (err error
 			o.rho = p.V
 		}
 		if skgc, found := io.Keycode(p.Extra, "kgc"); found {
-			o.Kgc = GetKgc(skgc, prms)
-			if o.Kgc == nil {
+			allocator, ok := kgcfactory[skgc]
+			if !ok {
 				return chk.Err("cannot find kgc model named %s", skgc)
 			}
+			o.Kgc = allocator()
 			err = o.Kgc.Init(prms)
 			if err != nil {
 				return
@@ -103,6 +106,12 @@ func (o *SmallElasticity) Init(ndim int, pstress bool, prms fun.Prms) (err error
 	default:
 		return chk.Err("combination of Elastic constants is incorrect. options are {E,nu}, {l,G}, {K,G} and {K,nu}\n")
 	}
+	if o.Kgc != nil {
+		if o.Pse {
+			return chk.Err("plane-stress analysis does not work with nonlinear K and G\n")
+		}
+		o.sig0 = make([]float64, o.Nsig)
+	}
 	return
 }
 
@@ -114,8 +123,38 @@ func (o SmallElasticity) GetPrms() fun.Prms {
 	}
 }
 
+// StartIncrement computes K and G with the non-linear calculator (if any) at the state s of the
+// beginning of the increment; i.e. the moduli are constant during the increment
+//  Note: models with non-linear elasticity must call StartIncrement and EndIncrement in Update
+func (o *SmallElasticity) StartIncrement(s *State) {
+	if o.Kgc == nil {
+		return
+	}
+	o.K, o.G = o.Kgc.Calc(s)
+	o.L = Calc_l_from_KG(o.K, o.G)
+	copy(o.sig0, s.Sig)
+}
+
+// EndIncrement updates the elastic strains of non-linear models with the stress increment:
+//  Δεe = tr(Δσ)/(9K) I + dev(Δσ)/(2G)
+func (o *SmallElasticity) EndIncrement(s *State) {
+	if o.Kgc == nil || len(s.EpsE) == 0 {
+		return
+	}
+	var trΔσ float64
+	for i := 0; i < 3; i++ {
+		trΔσ += s.Sig[i] - o.sig0[i]
+	}
+	for i := 0; i < o.Nsig; i++ {
+		Δσ := s.Sig[i] - o.sig0[i]
+		s.EpsE[i] += trΔσ*tsr.Im[i]/(9.0*o.K) + (Δσ-trΔσ*tsr.Im[i]/3.0)/(2.0*o.G)
+	}
+}
+
 // Update computes new stresses for new strain increment Δε
 func (o SmallElasticity) Update(s *State, Δε []float64) (err error) {
+	o.StartIncrement(s)
+	defer o.EndIncrement(s)
 	σ := s.Sig
 	if o.Pse {
 		c := o.E / (1.0 - o.Nu*o.Nu)
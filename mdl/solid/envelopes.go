@@ -0,0 +1,165 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// YsPiPlane computes the cross section of the (first) yield surface of model m in the π-plane
+// (deviatoric plane) with mean pressure p; i.e. the octahedral coordinates (σa, σb) of f = 0 along
+// nθ rays spanning [0, 2π). The internal variables are taken from s
+//  Note: rays with f(origin) ≥ 0 or without crossing f = 0 are skipped
+func YsPiPlane(m EPmodel, s *State, p float64, nθ int) (σa, σb []float64, err error) {
+	if nθ < 3 {
+		return nil, nil, chk.Err("number of rays must be at least 3. nθ=%d is invalid", nθ)
+	}
+	v := s.GetCopy()
+	σc := p * tsr.SQ3
+	scale := math.Max(math.Abs(σc), ys_scale(m, v))
+	for i := 0; i < nθ; i++ {
+		θ := 2.0 * math.Pi * float64(i) / float64(nθ)
+		c, d := math.Cos(θ), math.Sin(θ)
+		r, ok := ys_radius(func(r float64) float64 {
+			v.Sig[0], v.Sig[1], v.Sig[2] = tsr.O2L(r*c, r*d, σc)
+			for j := 3; j < len(v.Sig); j++ {
+				v.Sig[j] = 0
+			}
+			return m.YieldFuncs(v)[0]
+		}, scale)
+		if ok {
+			σa = append(σa, r*c)
+			σb = append(σb, r*d)
+		}
+	}
+	return
+}
+
+// YsMeridian computes the meridian section of the (first) yield surface of model m with Lode
+// parameter w (w = 1: compression; w = -1: extension); i.e. the values of q such that f(p, q) = 0
+// for np values of p in [pmin, pmax]. The internal variables are taken from s
+//  Note: points with f(p, 0) ≥ 0 or without crossing f = 0 are skipped
+func YsMeridian(m EPmodel, s *State, w, pmin, pmax float64, np int) (P, Q []float64, err error) {
+	if np < 2 {
+		return nil, nil, chk.Err("number of points must be at least 2. np=%d is invalid", np)
+	}
+	v := s.GetCopy()
+	scale := math.Max(math.Max(math.Abs(pmin), math.Abs(pmax)), ys_scale(m, v))
+	for i := 0; i < np; i++ {
+		p := pmin + (pmax-pmin)*float64(i)/float64(np-1)
+		q, ok := ys_radius(func(q float64) float64 {
+			σa, σb, σc := tsr.PQW2O(p, q, w)
+			v.Sig[0], v.Sig[1], v.Sig[2] = tsr.O2L(σa, σb, σc)
+			for j := 3; j < len(v.Sig); j++ {
+				v.Sig[j] = 0
+			}
+			return m.YieldFuncs(v)[0]
+		}, scale)
+		if ok {
+			P = append(P, p)
+			Q = append(Q, q)
+		}
+	}
+	return
+}
+
+// ResponseEnvelope computes the response envelope (Gudehus) of a small-strain model at state s
+// with total strain ε; i.e. the stress increments due to ndir strain increments with norm Δε
+// and directions θ in the Rendulic plane (axisymmetric conditions: axial = 0, radial = 1 and 2):
+//  Δε_a = Δε cos(θ)   Δε_r = Δε sin(θ) / √2
+//  Output:
+//   θ   -- directions of strain increments [ndir]
+//   Δσr -- radial stress increments (Rendulic: √2 Δσ_r) [ndir]
+//   Δσa -- axial stress increments [ndir]
+//  Note: the state s is not modified
+func ResponseEnvelope(mdl Small, s *State, ε []float64, Δε float64, ndir int) (θ, Δσr, Δσa []float64, err error) {
+	if ndir < 3 {
+		return nil, nil, nil, chk.Err("number of directions must be at least 3. ndir=%d is invalid", ndir)
+	}
+	nsig := len(s.Sig)
+	θ, Δσr, Δσa = make([]float64, ndir), make([]float64, ndir), make([]float64, ndir)
+	εnew, dε := make([]float64, nsig), make([]float64, nsig)
+	for i := 0; i < ndir; i++ {
+		θ[i] = 2.0 * math.Pi * float64(i) / float64(ndir)
+		dε[0] = Δε * math.Cos(θ[i])
+		dε[1] = Δε * math.Sin(θ[i]) / tsr.SQ2
+		dε[2] = dε[1]
+		la.VecAdd2(εnew, 1, ε, 1, dε)
+		v := s.GetCopy()
+		err = mdl.Update(v, εnew, dε, 0, 0, 0)
+		if err != nil {
+			return
+		}
+		Δσa[i] = v.Sig[0] - s.Sig[0]
+		Δσr[i] = (v.Sig[1] - s.Sig[1] + v.Sig[2] - s.Sig[2]) / tsr.SQ2
+	}
+	return
+}
+
+// CycleStarts returns the indices where the loops of a cyclic history x start. A loop starts at
+// 0 and at every second reversal of x; i.e. each loop contains a loading and an unloading branch
+//  Note: changes smaller than tol are ignored when detecting reversals
+func CycleStarts(x []float64, tol float64) (idx []int) {
+	if len(x) < 1 {
+		return
+	}
+	idx = append(idx, 0)
+	dir, nrev, iref := 0, 0, 0
+	for i := 1; i < len(x); i++ {
+		d := x[i] - x[iref]
+		if math.Abs(d) <= tol {
+			continue
+		}
+		newdir := 1
+		if d < 0 {
+			newdir = -1
+		}
+		if dir != 0 && newdir != dir {
+			nrev++
+			if nrev%2 == 0 {
+				idx = append(idx, iref)
+			}
+		}
+		dir, iref = newdir, i
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////
+
+// ys_scale returns a scale for the search of yield surfaces
+func ys_scale(m EPmodel, s *State) float64 {
+	return math.Max(1.0, math.Abs(m.YieldFuncs(s)[0])+math.Abs(tsr.M_p(s.Sig))+tsr.M_q(s.Sig))
+}
+
+// ys_radius finds r > 0 such that f(r) = 0 with f(0) < 0 by increasing r and bisection
+func ys_radius(f func(r float64) float64, scale float64) (r float64, ok bool) {
+	if f(0) >= 0 {
+		return
+	}
+	a, b := 0.0, 1e-3*scale
+	for f(b) < 0 {
+		a, b = b, 2.0*b
+		if b > 1e6*scale {
+			return
+		}
+	}
+	for it := 0; it < 100; it++ {
+		r = (a + b) / 2.0
+		if f(r) < 0 {
+			a = r
+		} else {
+			b = r
+		}
+		if b-a < 1e-14*(1.0+b) {
+			break
+		}
+	}
+	return (a + b) / 2.0, true
+}
@@ -0,0 +1,488 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// constants
+const (
+	FA_MAXIT = 100   // maximum number of iterations of the return mapping of Fault
+	FA_TOL   = 1e-13 // tolerance of the return mapping of Fault (relative to the trial shear traction or on μ)
+)
+
+// Fault implements a frictional model for faults and discontinuities (to be used with interface
+// elements) with slip-weakening or rate-and-state friction; e.g. for induced-seismicity and reservoir
+// geomechanics studies
+//  The tractions t = {tn, ts1, [ts2]} are effective (tension positive); thus the shear strength
+//  depends on the pore-pressure on the fault (see the coupled interface elements)
+//    |ts| ≤ c + μ σn   with   σn = -tn  (effective normal compression)
+//  Linear slip-weakening:
+//    μ(δ) = μs - (μs - μd) min(δ / Dc, 1)
+//  Rate-and-state (regularised; aging law):
+//    μ(V, θ) = a asinh(V / (2 V0) exp(ψ / a))   with   ψ = μ0 + b ln(V0 θ / Dc)
+//    dθ/dt = 1 - V θ / Dc
+//  where δ is the accumulated slip, V the slip rate and θ the state variable. In steady-state
+//  sliding, μ ≈ μ0 + (a - b) ln(V / V0); thus a - b < 0 means velocity weakening (unstable slip)
+//  Parameters: "kn", "ks" (elastic stiffnesses), "c" (cohesion; default = 0), "mus", "mud" (static
+//              and dynamic friction coefficients; mud = mus by default), "dc" (critical slip distance
+//              or characteristic slip distance of rate-and-state friction), "rs" (1 => rate-and-state;
+//              default = 0 => slip-weakening), "a", "b", "v0", "mu0" (rate-and-state parameters),
+//              "theta0" (initial state; default = Dc / V0), "kr" (ratio of residual stiffness of open
+//              faults; default = 1e-6), "kl", "klo" and "kls" (leakances across closed, open and
+//              fully slipped faults; klo = kls = kl by default)
+//  Internal variables: α = {wpn, wps1, [wps2], open, δ, θ, V, μ, dμ/dΔγ, time}
+//  Note: (1) the fault opens (gap) when tn > 0; then the tractions vanish (except for a residual
+//            stiffness) and the fault slides freely
+//        (2) the aging law is integrated exactly with V = Δδ / Δt constant during the time step.
+//            If the time does not advance (Δt = 0), the friction coefficient is computed with V = V0
+//            and θ is kept constant; i.e. the friction is rate-independent
+//        (3) the leakance of closed faults increases linearly with slip from kl to kls (δ = Dc);
+//            e.g. to model the permeability enhancement due to shearing
+//        (4) no dilation is considered
+type Fault struct {
+	Kn     float64 // normal stiffness
+	Ks     float64 // shear stiffness
+	C      float64 // cohesion
+	Mus    float64 // static friction coefficient
+	Mud    float64 // dynamic friction coefficient
+	Dc     float64 // critical slip distance
+	Rs     bool    // rate-and-state friction; otherwise slip-weakening
+	A      float64 // direct effect parameter of rate-and-state friction
+	B      float64 // evolution effect parameter of rate-and-state friction
+	V0     float64 // reference slip rate
+	Mu0    float64 // reference friction coefficient (steady-state @ V0)
+	Theta0 float64 // initial state variable
+	Kr     float64 // ratio of residual stiffness of open faults
+	Kl     float64 // leakance of closed faults
+	Klo    float64 // leakance of open faults
+	Kls    float64 // leakance of closed faults after slipping Dc
+	Ndim   int     // space dimension
+}
+
+// indices of internal variables (after the ndim irreversible relative displacements)
+const (
+	fa_open  = 0 // open flag
+	fa_slip  = 1 // δ: accumulated slip
+	fa_theta = 2 // θ: state variable
+	fa_vel   = 3 // V: slip rate
+	fa_mu    = 4 // μ: friction coefficient
+	fa_dmu   = 5 // dμ/dΔγ: derivative of friction coefficient w.r.t. slip increment
+	fa_time  = 6 // time of last update
+	fa_nalp  = 7 // number of internal variables
+)
+
+// add model to factory
+func init() {
+	allocators["fault"] = func() Model { return new(Fault) }
+}
+
+// Clean clean resources
+func (o *Fault) Clean() {
+}
+
+// GetRho returns density
+func (o *Fault) GetRho() float64 {
+	return 0
+}
+
+// Init initialises model
+func (o *Fault) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+	o.Ndim = ndim
+	o.Mud, o.V0, o.Theta0, o.Kr, o.Klo, o.Kls = -1, 1e-6, -1, 1e-6, -1, -1
+	for _, p := range prms {
+		switch p.N {
+		case "kn":
+			o.Kn = p.V
+		case "ks":
+			o.Ks = p.V
+		case "c":
+			o.C = p.V
+		case "mus":
+			o.Mus = p.V
+		case "mud":
+			o.Mud = p.V
+		case "dc":
+			o.Dc = p.V
+		case "rs":
+			o.Rs = p.V > 0
+		case "a":
+			o.A = p.V
+		case "b":
+			o.B = p.V
+		case "v0":
+			o.V0 = p.V
+		case "mu0":
+			o.Mu0 = p.V
+		case "theta0":
+			o.Theta0 = p.V
+		case "kr":
+			o.Kr = p.V
+		case "kl":
+			o.Kl = p.V
+		case "klo":
+			o.Klo = p.V
+		case "kls":
+			o.Kls = p.V
+		default:
+			return chk.Err("fault: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.Mud < 0 {
+		o.Mud = o.Mus
+	}
+	if o.Klo < 0 {
+		o.Klo = o.Kl
+	}
+	if o.Kls < 0 {
+		o.Kls = o.Kl
+	}
+	if o.Kn <= 0 || o.Ks <= 0 || o.Dc <= 0 || o.C < 0 || o.Kl < 0 || o.Klo < 0 || o.Kls < 0 {
+		return chk.Err("fault: invalid parameters: {kn=%g, ks=%g, dc=%g} must be > 0 and {c=%g, kl=%g, klo=%g, kls=%g} must be >= 0\n", o.Kn, o.Ks, o.Dc, o.C, o.Kl, o.Klo, o.Kls)
+	}
+	if o.Kr <= 0 || o.Kr >= 1 {
+		return chk.Err("fault: invalid parameters: kr=%g must be in ]0,1[\n", o.Kr)
+	}
+	if o.Rs {
+		if o.Theta0 < 0 {
+			o.Theta0 = o.Dc / o.V0
+		}
+		if o.A <= 0 || o.B < 0 || o.V0 <= 0 || o.Mu0 <= 0 || o.Theta0 <= 0 {
+			return chk.Err("fault: invalid rate-and-state parameters: {a=%g, v0=%g, mu0=%g, theta0=%g} must be > 0 and b=%g must be >= 0\n", o.A, o.V0, o.Mu0, o.Theta0, o.B)
+		}
+		return
+	}
+	if o.Mud < 0 || o.Mus < o.Mud {
+		return chk.Err("fault: invalid slip-weakening parameters: mus=%g and mud=%g must satisfy mus >= mud >= 0\n", o.Mus, o.Mud)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o Fault) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e7},
+		&fun.Prm{N: "ks", V: 1e6},
+		&fun.Prm{N: "c", V: 0},
+		&fun.Prm{N: "mus", V: 0.6},
+		&fun.Prm{N: "mud", V: 0.4},
+		&fun.Prm{N: "dc", V: 0.01},
+		&fun.Prm{N: "kl", V: 0},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Input:
+//   σ -- initial tractions {tn, ts1, [ts2]}
+func (o Fault) InitIntVars(σ []float64) (s *State, err error) {
+	if len(σ) != o.Ndim {
+		return nil, chk.Err("fault: number of components of tractions (%d) must be equal to ndim (%d)\n", len(σ), o.Ndim)
+	}
+	s = NewState(o.Ndim, o.Ndim+fa_nalp, false, false)
+	copy(s.Sig, σ)
+	copy(s.EpsTr, σ)
+	for i := 0; i < o.Ndim; i++ { // relative displacements consistent with initial tractions
+		s.EpsE[i] = σ[i] / o.stiff(i)
+	}
+	a := s.Alp[o.Ndim:]
+	a[fa_theta] = o.Theta0
+	a[fa_mu], _, _ = o.friction(0, 0, o.Theta0, 0)
+	return
+}
+
+// IntVarNames returns the names of internal variables
+func (o Fault) IntVarNames() []string {
+	return append(ivnames("wp", o.Ndim), "open", "slip", "theta", "vel", "mu", "dmu", "tlast")
+}
+
+// IsOpen returns whether the fault is open (gap) or not
+func (o Fault) IsOpen(s *State) bool {
+	return s.Alp[o.Ndim+fa_open] > 0
+}
+
+// SlipVars returns the accumulated slip δ, the slip rate V, the friction coefficient μ and the
+// state variable θ
+func (o Fault) SlipVars(s *State) (δ, V, μ, θ float64) {
+	a := s.Alp[o.Ndim:]
+	return a[fa_slip], a[fa_vel], a[fa_mu], a[fa_theta]
+}
+
+// Update updates tractions for given increment of relative displacements
+func (o Fault) Update(s *State, Δw []float64, time float64) (err error) {
+
+	// auxiliary
+	nd := o.Ndim
+	wp, a := s.Alp[:nd], s.Alp[nd:]
+	for i := 0; i < nd; i++ {
+		s.EpsE[i] += Δw[i]
+	}
+	w := s.EpsE
+	Δt := time - a[fa_time]
+	if Δt < 0 {
+		Δt = 0
+	}
+	a[fa_time] = time
+	δ, θ := a[fa_slip], a[fa_theta]
+
+	// trial tractions
+	for i := 0; i < nd; i++ {
+		s.EpsTr[i] = o.stiff(i) * (w[i] - wp[i])
+	}
+	s.Dgam = 0
+	s.Loading = false
+	a[fa_vel], a[fa_dmu] = 0, 0
+
+	// gap: free sliding
+	if s.EpsTr[0] > 0 {
+		var Δγ float64
+		for i := 1; i < nd; i++ {
+			Δγ += (w[i] - wp[i]) * (w[i] - wp[i])
+			wp[i] = w[i]
+			s.Sig[i] = 0
+		}
+		Δγ = math.Sqrt(Δγ)
+		_, _, a[fa_theta] = o.friction(Δγ, δ, θ, Δt)
+		a[fa_open], a[fa_slip], a[fa_mu] = 1, δ+Δγ, 0
+		if Δt > 0 {
+			a[fa_vel] = Δγ / Δt
+		}
+		s.Sig[0] = o.Kr * s.EpsTr[0]
+		return
+	}
+	a[fa_open] = 0
+
+	// trial yield function
+	σn := -s.EpsTr[0]
+	τtr := o.shear_norm(s.EpsTr)
+	copy(s.Sig, s.EpsTr)
+	μ, dμ, θnew := o.friction(0, δ, θ, Δt)
+	f := τtr - o.C - μ*σn
+	if f <= 0 {
+		a[fa_theta], a[fa_mu] = θnew, μ
+		return
+	}
+
+	// return mapping
+	var Δγ float64
+	if o.Rs && Δt > 0 && σn > 0 {
+		Δγ, μ, dμ, θnew, err = o.return_rs(τtr, σn, θ, Δt)
+	} else {
+		Δγ, μ, dμ, θnew, err = o.return_slip(f, τtr, σn, δ, θ, Δt)
+	}
+	if err != nil {
+		return
+	}
+
+	// update
+	for i := 1; i < nd; i++ {
+		m := s.EpsTr[i] / τtr
+		s.Sig[i] = s.EpsTr[i] - o.Ks*Δγ*m
+		wp[i] += Δγ * m
+	}
+	a[fa_slip], a[fa_theta], a[fa_mu], a[fa_dmu] = δ+Δγ, θnew, μ, dμ
+	if Δt > 0 {
+		a[fa_vel] = Δγ / Δt
+	}
+	s.Dgam = Δγ
+	s.Loading = true
+	return
+}
+
+// CalcD computes D = dt_new/dw_new consistent with Update
+func (o Fault) CalcD(D [][]float64, s *State) (err error) {
+
+	// elastic
+	nd := o.Ndim
+	la.MatFill(D, 0)
+	for i := 0; i < nd; i++ {
+		D[i][i] = o.stiff(i)
+	}
+
+	// open
+	if o.IsOpen(s) {
+		for i := 0; i < nd; i++ {
+			D[i][i] *= o.Kr
+		}
+		return
+	}
+	if !s.Loading {
+		return
+	}
+
+	// slipping: dΔγ = (ks m・dws + μ kn dwn) / H with H = ks + σn dμ/dΔγ
+	a := s.Alp[nd:]
+	σn := -s.EpsTr[0]
+	τtr := o.shear_norm(s.EpsTr)
+	τ := τtr - o.Ks*s.Dgam
+	H := o.Ks + a[fa_dmu]*σn
+	if H == 0 {
+		return chk.Err("fault: tangent modulus is singular (ks = -σn dμ/dΔγ = %g)\n", o.Ks)
+	}
+	for i := 1; i < nd; i++ {
+		mi := s.EpsTr[i] / τtr
+		D[i][0] = -mi * o.Ks * a[fa_mu] * o.Kn / H
+		for j := 1; j < nd; j++ {
+			mj := s.EpsTr[j] / τtr
+			D[i][j] = o.Ks*(τ/τtr)*(-mi*mj) + o.Ks*a[fa_dmu]*σn*mi*mj/H
+			if i == j {
+				D[i][j] += o.Ks * τ / τtr
+			}
+		}
+	}
+	return
+}
+
+// Leakance returns the (mass) leakance across the fault
+func (o Fault) Leakance(s *State) float64 {
+	if o.IsOpen(s) {
+		return o.Klo
+	}
+	r := math.Min(s.Alp[o.Ndim+fa_slip]/o.Dc, 1)
+	return o.Kl + (o.Kls-o.Kl)*r
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// return_slip solves f(Δγ) = τtr - ks Δγ - c - μ(Δγ) σn = 0 with Newton's method safeguarded by
+// bisection; note that f(0) > 0 and f((τtr - c) / ks) ≤ 0
+func (o Fault) return_slip(f0, τtr, σn, δ, θ, Δt float64) (Δγ, μ, dμ, θnew float64, err error) {
+	lo, hi := 0.0, (τtr-o.C)/o.Ks
+	Δγ = f0 / o.Ks
+	for it := 0; it < FA_MAXIT; it++ {
+		μ, dμ, θnew = o.friction(Δγ, δ, θ, Δt)
+		f := τtr - o.Ks*Δγ - o.C - μ*σn
+		if math.Abs(f) <= FA_TOL*τtr {
+			return
+		}
+		if f > 0 {
+			lo = Δγ
+		} else {
+			hi = Δγ
+		}
+		next := Δγ - f/(-o.Ks-dμ*σn)
+		if !(next > lo && next < hi) {
+			next = (lo + hi) / 2.0
+		}
+		Δγ = next
+	}
+	err = chk.Err("fault: return mapping did not converge after %d iterations. τtr=%g, σn=%g, Δt=%g\n", FA_MAXIT, τtr, σn, Δt)
+	return
+}
+
+// return_rs solves the return mapping of rate-and-state friction in terms of μ, which is better
+// conditioned than in terms of Δγ when the slip rate is small:
+//  r(μ) = (τtr - c - μ σn) / ks - sinh(μ/a) / cθ = 0   with   cθ = exp(ψ(θ)/a) / (2 V0 Δt)
+//  Note: r(0) > 0 and r((τtr - c) / σn) ≤ 0
+func (o Fault) return_rs(τtr, σn, θ, Δt float64) (Δγ, μ, dμ, θnew float64, err error) {
+	var dθ, cθ float64
+	eval := func() {
+		Δγ = math.Max((τtr-o.C-μ*σn)/o.Ks, 0)
+		θnew, dθ = o.aging(Δγ, θ, Δt)
+		cθ = math.Exp((o.Mu0+o.B*math.Log(o.V0*θnew/o.Dc))/o.A) / (2.0 * o.V0 * Δt)
+	}
+	lo, hi := 0.0, (τtr-o.C)/σn
+	μ = o.Mu0 + o.B*math.Log(o.V0*θ/o.Dc) // steady-state @ V0
+	if !(μ > lo && μ < hi) {
+		μ = (lo + hi) / 2.0
+	}
+	converged := false
+	for it := 0; it < FA_MAXIT; it++ {
+		eval()
+		sh, ch := math.Sinh(μ/o.A), math.Cosh(μ/o.A)
+		r := Δγ - sh/cθ
+		if r == 0 {
+			converged = true
+			break
+		}
+		if r > 0 {
+			lo = μ
+		} else {
+			hi = μ
+		}
+		dr := -σn/o.Ks - ch/(o.A*cθ) - (sh/cθ)*(o.B/o.A)*(dθ/θnew)*(σn/o.Ks)
+		next := μ - r/dr
+		if !(next > lo && next < hi) {
+			next = (lo + hi) / 2.0
+		}
+		if math.Abs(next-μ) <= FA_TOL {
+			μ = next
+			converged = true
+			break
+		}
+		μ = next
+	}
+	if !converged {
+		err = chk.Err("fault: return mapping did not converge after %d iterations. τtr=%g, σn=%g, Δt=%g\n", FA_MAXIT, τtr, σn, Δt)
+		return
+	}
+
+	// dμ/dΔγ from μ = a asinh(cθ Δγ)
+	eval()
+	dμ = o.A * cθ * (1.0 + Δγ*o.B*dθ/(o.A*θnew)) / math.Cosh(μ/o.A)
+	return
+}
+
+// friction computes the friction coefficient μ, its derivative w.r.t. the slip increment Δγ and the
+// new state variable θ for the slip increment Δγ during the time increment Δt
+//  δ and θ are the accumulated slip and state variable at the beginning of the time step
+func (o Fault) friction(Δγ, δ, θ, Δt float64) (μ, dμ, θnew float64) {
+
+	// slip-weakening
+	if !o.Rs {
+		if δ+Δγ < o.Dc {
+			return o.Mus - (o.Mus-o.Mud)*(δ+Δγ)/o.Dc, -(o.Mus - o.Mud) / o.Dc, θ
+		}
+		return o.Mud, 0, θ
+	}
+
+	// rate-and-state without time increment
+	if Δt <= 0 {
+		ψ := o.Mu0 + o.B*math.Log(o.V0*θ/o.Dc)
+		return o.A * math.Asinh(0.5*math.Exp(ψ/o.A)), 0, θ
+	}
+
+	// state variable
+	θnew, dθ := o.aging(Δγ, θ, Δt)
+
+	// friction coefficient: μ = a asinh(c Δγ) with c = exp(ψ/a) / (2 V0 Δt)
+	c := math.Exp((o.Mu0+o.B*math.Log(o.V0*θnew/o.Dc))/o.A) / (2.0 * o.V0 * Δt)
+	u := c * Δγ
+	μ = o.A * math.Asinh(u)
+	dμ = o.A * c * (1.0 + Δγ*o.B*dθ/(o.A*θnew)) / math.Hypot(1, u)
+	return
+}
+
+// aging integrates the aging law with V = Δγ/Δt constant and returns θ and dθ/dΔγ
+//  θ = θ0 exp(-x) + Δt φ(x)   with   x = Δγ/Dc   and   φ = (1 - exp(-x)) / x
+func (o Fault) aging(Δγ, θ0, Δt float64) (θ, dθ float64) {
+	x := Δγ / o.Dc
+	e := math.Exp(-x)
+	φ, dφ := 1.0-x/2.0+x*x/6.0, -0.5+x/3.0-x*x/8.0
+	if x > 1e-3 {
+		φ, dφ = -math.Expm1(-x)/x, (e*(x+1.0)-1.0)/(x*x)
+	}
+	return θ0*e + Δt*φ, (-θ0*e + Δt*dφ) / o.Dc
+}
+
+// stiff returns the elastic stiffness corresponding to component i
+func (o Fault) stiff(i int) float64 {
+	if i == 0 {
+		return o.Kn
+	}
+	return o.Ks
+}
+
+// shear_norm returns the norm of the shear components
+func (o Fault) shear_norm(t []float64) (τ float64) {
+	for i := 1; i < o.Ndim; i++ {
+		τ += t[i] * t[i]
+	}
+	return math.Sqrt(τ)
+}
@@ -0,0 +1,290 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// Hysteretic implements a small-strain hysteretic model for soils under cyclic loading (seismic
+// analyses). The deviatoric response follows the (modified) Hardin-Drnevich backbone curve
+//  τ = F(γ) = G(γ) γ   with   G(γ) = Gmax / (1 + (γ/γr)^a)
+// and the Masing rules for unloading and reloading; i.e. after a reversal at (γrev, τrev):
+//  τ - τrev = 2 F((γ - γrev) / 2)
+// where γ = √2 |dev(ε)| is the shear strain (e.g. γ = γxy in simple shear), Gmax = G is the
+// small-strain shear modulus and γr is the reference shear strain at which G = Gmax / 2. The
+// volumetric response is linear elastic with the bulk modulus K
+//  Equivalent-linear analyses: GGmax and Damping give the G/Gmax and damping curves of the model;
+//  i.e. the secant modulus and the Masing damping of a symmetric loop with amplitude γ (plus the
+//  small-strain damping Dmin). Equivalent computes the properties for the effective strain
+//  γeff = reff γmax, where γmax is the maximum shear strain of the previous iteration
+//  Parameters: "E", "nu" (or other elastic constants; G = Gmax), "gref" (γr), "a" (curvature;
+//              0 < a ≤ 1; default = 1 => Hardin-Drnevich), "Dmin" (small-strain damping ratio;
+//              default = 0) and "reff" (effective strain ratio; default = 0.65)
+//  Internal variables: α = {γmax, n, γd, erev, srev, sini} where γmax is the largest shear strain
+//                      on the backbone curve, n = 1 (backbone) or 2 (Masing), γd = √2 |e - erev|
+//                      is the shear strain since the last reversal, erev and srev are the
+//                      deviatoric strains and stresses at the last reversal and sini holds the
+//                      initial deviatoric stresses (origin of the backbone curve)
+//  Note: (1) a reversal is detected when γd decreases. The backbone curve is followed again
+//            when the shear strain exceeds γmax (extended Masing rules)
+//        (2) in multiaxial loading, the backbone curve is rejoined approximately; i.e. the
+//            stresses may jump slightly
+//        (3) the hysteretic damping is zero at small strains; Dmin must thus be introduced with
+//            Rayleigh damping in fully nonlinear analyses
+//        (4) plane-stress analyses and stress dependent moduli (kgc) are not available
+type Hysteretic struct {
+	SmallElasticity
+	Gref float64 // reference shear strain γr; G(γr) = Gmax / 2
+	A    float64 // curvature parameter
+	Dmin float64 // small-strain damping ratio
+	Reff float64 // ratio between effective and maximum shear strains (equivalent-linear analyses)
+}
+
+// indices of internal variables
+const (
+	hy_gmax = 0 // γmax: largest shear strain on backbone curve
+	hy_n    = 1 // n: 1 => backbone; 2 => Masing
+	hy_gd   = 2 // γd: shear strain since last reversal
+	hy_erev = 3 // erev, srev and sini (nsig values each)
+)
+
+// add model to factory
+func init() {
+	allocators["hysteretic"] = func() Model { return new(Hysteretic) }
+}
+
+// Clean clean resources
+func (o *Hysteretic) Clean() {
+}
+
+// Init initialises model
+func (o *Hysteretic) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+	if pstress {
+		return chk.Err("hysteretic: plane-stress analyses are not available\n")
+	}
+	err = o.SmallElasticity.Init(ndim, pstress, prms)
+	if err != nil {
+		return
+	}
+	if o.Kgc != nil {
+		return chk.Err("hysteretic: stress dependent moduli (kgc) are not available\n")
+	}
+	o.A, o.Dmin, o.Reff = 1, 0, 0.65
+	for _, p := range prms {
+		switch p.N {
+		case "gref":
+			o.Gref = p.V
+		case "a":
+			o.A = p.V
+		case "Dmin":
+			o.Dmin = p.V
+		case "reff":
+			o.Reff = p.V
+		case "E", "nu", "l", "G", "K", "rho":
+		default:
+			return chk.Err("hysteretic: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.Gref <= 0 {
+		return chk.Err("hysteretic: reference shear strain must be positive. gref = %g is invalid\n", o.Gref)
+	}
+	if o.A <= 0 || o.A > 1 {
+		return chk.Err("hysteretic: curvature parameter must be in (0, 1]. a = %g is invalid\n", o.A)
+	}
+	if o.Dmin < 0 || o.Dmin >= 1 {
+		return chk.Err("hysteretic: small-strain damping ratio must be in [0, 1). Dmin = %g is invalid\n", o.Dmin)
+	}
+	if o.Reff <= 0 || o.Reff > 1 {
+		return chk.Err("hysteretic: effective strain ratio must be in (0, 1]. reff = %g is invalid\n", o.Reff)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o Hysteretic) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "G", V: 60000},
+		&fun.Prm{N: "nu", V: 0.3},
+		&fun.Prm{N: "gref", V: 5e-4},
+		&fun.Prm{N: "a", V: 0.92},
+		&fun.Prm{N: "Dmin", V: 0.01},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o Hysteretic) InitIntVars(σ []float64) (s *State, err error) {
+	s = NewState(o.Nsig, hy_erev+3*o.Nsig, false, false)
+	copy(s.Sig, σ)
+	s.Alp[hy_n] = 1
+	srev, sini := s.Alp[hy_erev+o.Nsig:hy_erev+2*o.Nsig], s.Alp[hy_erev+2*o.Nsig:]
+	hy_dev(srev, σ)
+	copy(sini, srev)
+	return
+}
+
+// IntVarNames returns the names of internal variables
+func (o Hysteretic) IntVarNames() []string {
+	names := append([]string{"gmax", "branch", "gd"}, ivnames("erev", o.Nsig)...)
+	names = append(names, ivnames("srev", o.Nsig)...)
+	return append(names, ivnames("sini", o.Nsig)...)
+}
+
+// Update updates stresses for given strains
+func (o *Hysteretic) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+
+	// internal variables
+	erev := s.Alp[hy_erev : hy_erev+o.Nsig]
+	srev := s.Alp[hy_erev+o.Nsig : hy_erev+2*o.Nsig]
+	sini := s.Alp[hy_erev+2*o.Nsig:]
+
+	// deviatoric strains and mean stress
+	e := make([]float64, o.Nsig)
+	eold := make([]float64, o.Nsig)
+	hy_dev(e, ε)
+	for i := 0; i < o.Nsig; i++ {
+		eold[i] = ε[i] - Δε[i]
+	}
+	hy_dev(eold, eold)
+	trΔε := Δε[0] + Δε[1] + Δε[2]
+	pm := (s.Sig[0]+s.Sig[1]+s.Sig[2])/3.0 + o.K*trΔε
+
+	// reversal: start Masing curve from the previous state
+	γd := hy_dist(e, erev)
+	if γd < s.Alp[hy_gd] {
+		copy(erev, eold)
+		hy_dev(srev, s.Sig)
+		s.Alp[hy_n] = 2
+		γd = hy_dist(e, erev)
+	}
+
+	// rejoin backbone curve
+	γ := hy_dist(e, nil)
+	if s.Alp[hy_n] > 1 && γ > s.Alp[hy_gmax] {
+		for i := 0; i < o.Nsig; i++ {
+			erev[i] = 0
+		}
+		copy(srev, sini)
+		s.Alp[hy_n] = 1
+		γd = γ
+	}
+	s.Loading = s.Alp[hy_n] < 2 && γ > s.Alp[hy_gmax]
+	if s.Loading {
+		s.Alp[hy_gmax] = γ
+	}
+	s.Alp[hy_gd] = γd
+
+	// stresses
+	G := o.Secant(γd / s.Alp[hy_n])
+	for i := 0; i < o.Nsig; i++ {
+		s.Sig[i] = srev[i] + 2.0*G*(e[i]-erev[i]) + pm*tsr.Im[i]
+	}
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new (consistent)
+func (o *Hysteretic) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+
+	// secant modulus
+	n, γd := s.Alp[hy_n], s.Alp[hy_gd]
+	G := o.Secant(γd / n)
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			D[i][j] = o.K*tsr.Im[i]*tsr.Im[j] + 2.0*G*tsr.Psd[i][j]
+		}
+	}
+	if γd <= 0 {
+		return
+	}
+
+	// derivative of secant modulus: e - erev = (s - srev) / (2 G)
+	x := γd / n
+	y := math.Pow(x/o.Gref, o.A)
+	dGdx := -o.G * o.A * y / (x * (1.0 + y) * (1.0 + y))
+	c := 4.0 * dGdx / (n * γd)
+	srev := s.Alp[hy_erev+o.Nsig : hy_erev+2*o.Nsig]
+	Δe := make([]float64, o.Nsig)
+	hy_dev(Δe, s.Sig)
+	for i := 0; i < o.Nsig; i++ {
+		Δe[i] = (Δe[i] - srev[i]) / (2.0 * G)
+	}
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			D[i][j] += c * Δe[i] * Δe[j]
+		}
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *Hysteretic) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// Secant returns the secant shear modulus G(γ) of the backbone curve
+func (o *Hysteretic) Secant(γ float64) float64 {
+	return o.G * o.GGmax(γ)
+}
+
+// GGmax returns the ratio G/Gmax for the shear strain γ
+func (o *Hysteretic) GGmax(γ float64) float64 {
+	return 1.0 / (1.0 + math.Pow(math.Abs(γ)/o.Gref, o.A))
+}
+
+// Damping returns the damping ratio for the shear strain amplitude γ; i.e. Dmin plus the damping
+// of a symmetric Masing loop
+//  ξ = ΔW / (4 π W)   with   ΔW = 8 (∫₀^γ F dγ - F(γ) γ / 2)   and   W = F(γ) γ / 2
+func (o *Hysteretic) Damping(γ float64) float64 {
+	γ = math.Abs(γ)
+	if γ <= 0 {
+		return o.Dmin
+	}
+	nint := 1000 // Simpson's rule
+	h := γ / float64(nint)
+	F := func(g float64) float64 { return o.GGmax(g) * g }
+	area := F(0) + F(γ)
+	for i := 1; i < nint; i++ {
+		if i%2 == 1 {
+			area += 4.0 * F(float64(i)*h)
+		} else {
+			area += 2.0 * F(float64(i)*h)
+		}
+	}
+	area *= h / 3.0
+	return o.Dmin + 2.0*(2.0*area/(F(γ)*γ)-1.0)/math.Pi
+}
+
+// Equivalent returns the shear modulus and damping ratio for equivalent-linear analyses
+//  γmax -- maximum shear strain (e.g. from the previous iteration of equivalent-linear analyses)
+func (o *Hysteretic) Equivalent(γmax float64) (G, ξ float64) {
+	γeff := o.Reff * math.Abs(γmax)
+	return o.Secant(γeff), o.Damping(γeff)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// hy_dev computes the deviator d = dev(t) (Mandel's basis); d and t may be the same
+func hy_dev(d, t []float64) {
+	trt := t[0] + t[1] + t[2]
+	for i := 0; i < len(d); i++ {
+		d[i] = t[i] - trt*tsr.Im[i]/3.0
+	}
+}
+
+// hy_dist returns the shear strain √2 |a - b| between deviatoric strains a and b; b may be nil
+func hy_dist(a, b []float64) float64 {
+	var sum float64
+	for i, v := range a {
+		if b != nil {
+			v -= b[i]
+		}
+		sum += v * v
+	}
+	return math.Sqrt(2.0 * sum)
+}
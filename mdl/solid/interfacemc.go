@@ -0,0 +1,295 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// InterfaceMC implements a Mohr-Coulomb model for interfaces (soil-structure contact) with
+// dilation, tension cutoff and gap opening/closing
+//  Note: (1) the "stresses" are the tractions t = {tn, ts1, ts2} (tension positive) and the
+//            "strains" are the relative displacements w = {wn, ws1, ws2} in the local system
+//            of the interface; with n being the normal direction
+//        (2) yield function: f = |ts| + tn・tan(φ) - c; plastic potential: g = |ts| + tn・tan(ψ)
+//        (3) the interface opens (gap) when tn reaches the tensile strength ft; then the tensile
+//            strength is lost and the tractions vanish (except for a residual stiffness kr・kn and
+//            kr・ks to avoid singular matrices) until the gap is closed again
+//        (4) state: EpsE holds the total relative displacements, EpsTr the trial tractions,
+//            Alp = {wpn, wps1, [wps2], open, debonded} with wp being the irreversible
+//            (plastic and sliding) relative displacements
+//        (5) kl and klo are the (mass) leakances across closed and open interfaces used by
+//            interface elements with coupled pore-pressures
+type InterfaceMC struct {
+	Kn   float64 // normal stiffness
+	Ks   float64 // shear stiffness
+	C    float64 // cohesion (adhesion)
+	Phi  float64 // friction angle [deg]
+	Psi  float64 // dilation angle [deg]
+	Ft   float64 // tensile strength (tension cutoff)
+	Kr   float64 // ratio of residual stiffness of open interfaces
+	Kl   float64 // leakance of closed interfaces
+	Klo  float64 // leakance of open interfaces
+	Ndim int     // space dimension
+
+	// derived
+	tanφ float64 // tan(φ)
+	tanψ float64 // tan(ψ)
+}
+
+// add model to factory
+func init() {
+	allocators["interface-mc"] = func() Model { return new(InterfaceMC) }
+}
+
+// Clean clean resources
+func (o *InterfaceMC) Clean() {
+}
+
+// GetRho returns density
+func (o *InterfaceMC) GetRho() float64 {
+	return 0
+}
+
+// Init initialises model
+func (o *InterfaceMC) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+	o.Ndim = ndim
+	o.Kr = 1e-6
+	o.Klo = -1
+	for _, p := range prms {
+		switch p.N {
+		case "kn":
+			o.Kn = p.V
+		case "ks":
+			o.Ks = p.V
+		case "c":
+			o.C = p.V
+		case "phi":
+			o.Phi = p.V
+		case "psi":
+			o.Psi = p.V
+		case "ft":
+			o.Ft = p.V
+		case "kr":
+			o.Kr = p.V
+		case "kl":
+			o.Kl = p.V
+		case "klo":
+			o.Klo = p.V
+		}
+	}
+	if o.Klo < 0 {
+		o.Klo = o.Kl
+	}
+	if o.Kn <= 0 || o.Ks <= 0 || o.C < 0 || o.Ft < 0 || o.Kl < 0 || o.Klo < 0 {
+		return chk.Err("invalid parameters: {kn=%g, ks=%g} must be > 0 and {c=%g, ft=%g, kl=%g, klo=%g} must be >= 0", o.Kn, o.Ks, o.C, o.Ft, o.Kl, o.Klo)
+	}
+	if o.Phi < 0 || o.Phi >= 90 || o.Psi < 0 || o.Psi > o.Phi {
+		return chk.Err("invalid parameters: phi=%g must be in [0,90[ and psi=%g must be in [0,phi]", o.Phi, o.Psi)
+	}
+	if o.Kr <= 0 || o.Kr >= 1 {
+		return chk.Err("invalid parameters: kr=%g must be in ]0,1[", o.Kr)
+	}
+	o.tanφ = math.Tan(o.Phi * math.Pi / 180.0)
+	o.tanψ = math.Tan(o.Psi * math.Pi / 180.0)
+	if o.tanφ > 0 && o.Ft > o.C/o.tanφ {
+		return chk.Err("tensile strength ft=%g must not exceed the apex of the Mohr-Coulomb envelope c/tan(φ)=%g", o.Ft, o.C/o.tanφ)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o InterfaceMC) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e6},
+		&fun.Prm{N: "ks", V: 1e5},
+		&fun.Prm{N: "c", V: 0},
+		&fun.Prm{N: "phi", V: 25},
+		&fun.Prm{N: "psi", V: 0},
+		&fun.Prm{N: "ft", V: 0},
+		&fun.Prm{N: "kr", V: 1e-6},
+		&fun.Prm{N: "kl", V: 0},
+		&fun.Prm{N: "klo", V: 0},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Input:
+//   σ -- initial tractions {tn, ts1, [ts2]}
+func (o InterfaceMC) InitIntVars(σ []float64) (s *State, err error) {
+	if len(σ) != o.Ndim {
+		return nil, chk.Err("number of components of tractions (%d) must be equal to ndim (%d)", len(σ), o.Ndim)
+	}
+	s = NewState(o.Ndim, o.Ndim+2, false, false)
+	copy(s.Sig, σ)
+	copy(s.EpsTr, σ)
+	for i := 0; i < o.Ndim; i++ { // relative displacements consistent with initial tractions
+		s.EpsE[i] = σ[i] / o.stiff(i)
+		s.Alp[i] = 0
+	}
+	return
+}
+
+// IntVarNames returns the names of internal variables
+func (o InterfaceMC) IntVarNames() []string {
+	return append(ivnames("wp", o.Ndim), "open", "debonded")
+}
+
+// IsOpen returns whether the interface is open (gap) or not
+func (o InterfaceMC) IsOpen(s *State) bool {
+	return s.Alp[o.Ndim] > 0
+}
+
+// TensileStrength returns the tensile strength ft
+func (o InterfaceMC) TensileStrength() float64 {
+	return o.Ft
+}
+
+// Update updates tractions for given increment of relative displacements
+//  Note: time is not used
+func (o InterfaceMC) Update(s *State, Δw []float64, time float64) (err error) {
+
+	// auxiliary
+	nd := o.Ndim
+	wp := s.Alp[:nd]
+	open, debonded := &s.Alp[nd], &s.Alp[nd+1]
+	for i := 0; i < nd; i++ {
+		s.EpsE[i] += Δw[i]
+	}
+	w := s.EpsE
+
+	// trial tractions
+	for i := 0; i < nd; i++ {
+		s.EpsTr[i] = o.stiff(i) * (w[i] - wp[i])
+	}
+	s.Dgam = 0
+	s.Loading = false
+
+	// gap: opening or remaining open
+	ft := o.Ft
+	if *debonded > 0 {
+		ft = 0
+	}
+	if s.EpsTr[0] > ft {
+		*open, *debonded = 1, 1
+		for i := 1; i < nd; i++ { // free sliding
+			wp[i] = w[i]
+		}
+		s.Sig[0] = o.Kr * s.EpsTr[0]
+		for i := 1; i < nd; i++ {
+			s.Sig[i] = 0
+		}
+		return
+	}
+	*open = 0
+
+	// trial yield function
+	τtr := o.shear_norm(s.EpsTr)
+	ftr := τtr + s.EpsTr[0]*o.tanφ - o.C
+	copy(s.Sig, s.EpsTr)
+	if ftr <= 0 {
+		return
+	}
+
+	// return mapping
+	Δγ := ftr / (o.Ks + o.Kn*o.tanψ*o.tanφ)
+	if o.Ks*Δγ >= τtr { // dilation drives the interface to the apex => open
+		*open, *debonded = 1, 1
+		for i := 1; i < nd; i++ {
+			wp[i] = w[i]
+			s.Sig[i] = 0
+		}
+		s.Sig[0] = o.Kr * s.EpsTr[0]
+		return
+	}
+	s.Sig[0] = s.EpsTr[0] - o.Kn*Δγ*o.tanψ
+	wp[0] += Δγ * o.tanψ
+	for i := 1; i < nd; i++ {
+		m := s.EpsTr[i] / τtr
+		s.Sig[i] = s.EpsTr[i] - o.Ks*Δγ*m
+		wp[i] += Δγ * m
+	}
+	s.Dgam = Δγ
+	s.Loading = true
+	return
+}
+
+// CalcD computes D = dt_new/dw_new consistent with Update
+func (o InterfaceMC) CalcD(D [][]float64, s *State) (err error) {
+
+	// elastic
+	nd := o.Ndim
+	la.MatFill(D, 0)
+	for i := 0; i < nd; i++ {
+		D[i][i] = o.stiff(i)
+	}
+
+	// open
+	if o.IsOpen(s) {
+		for i := 0; i < nd; i++ {
+			D[i][i] *= o.Kr
+		}
+		return
+	}
+	if !s.Loading {
+		return
+	}
+
+	// plastic: D = K - (K・m)⊗(K・n)/(n・K・m) - ks²・Δγ/|ts_tr|・(I - s⊗s)
+	τtr := o.shear_norm(s.EpsTr)
+	Km := make([]float64, nd)
+	Kn := make([]float64, nd)
+	Km[0], Kn[0] = o.Kn*o.tanψ, o.Kn*o.tanφ
+	for i := 1; i < nd; i++ {
+		Km[i] = o.Ks * s.EpsTr[i] / τtr
+		Kn[i] = Km[i]
+	}
+	den := o.Ks + o.Kn*o.tanψ*o.tanφ
+	for i := 0; i < nd; i++ {
+		for j := 0; j < nd; j++ {
+			D[i][j] -= Km[i] * Kn[j] / den
+		}
+	}
+	c := o.Ks * o.Ks * s.Dgam / τtr
+	for i := 1; i < nd; i++ {
+		for j := 1; j < nd; j++ {
+			D[i][j] += c * s.EpsTr[i] * s.EpsTr[j] / (τtr * τtr)
+			if i == j {
+				D[i][j] -= c
+			}
+		}
+	}
+	return
+}
+
+// Leakance returns the (mass) leakance across the interface
+func (o InterfaceMC) Leakance(s *State) float64 {
+	if o.IsOpen(s) {
+		return o.Klo
+	}
+	return o.Kl
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// stiff returns the elastic stiffness corresponding to component i
+func (o InterfaceMC) stiff(i int) float64 {
+	if i == 0 {
+		return o.Kn
+	}
+	return o.Ks
+}
+
+// shear_norm returns the norm of the shear components
+func (o InterfaceMC) shear_norm(t []float64) (τ float64) {
+	for i := 1; i < o.Ndim; i++ {
+		τ += t[i] * t[i]
+	}
+	return math.Sqrt(τ)
+}
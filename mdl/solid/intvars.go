@@ -0,0 +1,43 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import "github.com/cpmech/gosl/io"
+
+// IntVarNamer defines models that name their internal variables (State.Alp) according to their
+// parameters or to the space dimension; e.g. models with tensorial internal variables
+type IntVarNamer interface {
+	IntVarNames() []string // returns the names of internal variables [nalp]
+}
+
+// intvars holds the names of internal variables of models with a fixed number of internal
+// variables; e.g. intvars["vm"] = {"alpha"}. Models register their names in init(), next to
+// the allocators
+var intvars = make(map[string][]string)
+
+// IntVarNames returns the names of the nalp internal variables (State.Alp) of model mdl with
+// given name; e.g. for output of results at integration points
+//  Note: (1) IntVarNamer has priority over the names registered in intvars
+//        (2) "alp0", "alp1", ... are returned if the model does not name all nalp variables
+func IntVarNames(name string, mdl Model, nalp int) (names []string) {
+	if m, ok := mdl.(IntVarNamer); ok {
+		names = m.IntVarNames()
+	} else {
+		names = intvars[name]
+	}
+	if len(names) == nalp {
+		return
+	}
+	return ivnames("alp", nalp)
+}
+
+// ivnames returns the names of n internal variables with prefix; e.g. "alp0", "alp1", ...
+func ivnames(prefix string, n int) (names []string) {
+	names = make([]string, n)
+	for i := 0; i < n; i++ {
+		names[i] = io.Sf("%s%d", prefix, i)
+	}
+	return
+}
@@ -0,0 +1,147 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// KgcPow implements stress dependent elastic moduli (power law)
+//  G = G0 (p/pref)^n   and   K = 2 G (1+ν) / (3 (1-2ν))
+// where p = max(-tr(σ)/3, pmin); i.e. compression is positive
+//  Parameters: "G0", "pref", "n", "pmin" (optional; default = 1e-3 pref) and "nu"
+//  Usage: the calculator is selected by the extra code of any parameter of the elastic model;
+//         e.g. {"n":"nu", "v":0.25, "extra":"!kgc:pow"}
+type KgcPow struct {
+	G0   float64 // shear modulus at reference pressure
+	Pref float64 // reference pressure
+	N    float64 // exponent
+	Pmin float64 // minimum pressure
+	Nu   float64 // Poisson's coefficient
+}
+
+// KgcHss implements stress dependent elastic moduli with a small-strain overlay; i.e. the shear
+// modulus of KgcPow is reduced by the (modified) Hardin-Drnevich degradation curve
+//  G = G0 (p/pref)^n ⋅ max(1 / (1 + a γ/γ07), gmin)   with   a = 0.385
+// where γ = √2 |dev(εe)| is the shear strain (e.g. γ = γxy in simple shear) and gmin is the
+// ratio between the unloading-reloading modulus (large strains) and the small-strain modulus
+//  Parameters: the parameters of KgcPow and "g07" and "gmin" (optional; default = 0)
+//  Note: the shear strain is measured from the elastic strains accumulated since the beginning
+//        of the simulation; i.e. the degradation is not reset when the loading is reversed
+type KgcHss struct {
+	KgcPow
+	G07  float64 // shear strain at which G = 0.722 G0
+	Gmin float64 // minimum ratio G/G0
+}
+
+// add calculators to factory
+func init() {
+	kgcfactory["pow"] = func() KGcalculator { return new(KgcPow) }
+	kgcfactory["hss"] = func() KGcalculator { return new(KgcHss) }
+}
+
+// KgcPrm tells whether name is a parameter of KG calculators
+func KgcPrm(name string) bool {
+	switch name {
+	case "G0", "pref", "n", "pmin", "g07", "gmin":
+		return true
+	}
+	return false
+}
+
+// Init initialises calculator
+func (o *KgcPow) Init(prms fun.Prms) (err error) {
+	o.Nu = -1
+	for _, p := range prms {
+		switch p.N {
+		case "G0":
+			o.G0 = p.V
+		case "pref":
+			o.Pref = p.V
+		case "n":
+			o.N = p.V
+		case "pmin":
+			o.Pmin = p.V
+		case "nu":
+			o.Nu = p.V
+		}
+	}
+	if o.G0 <= 0 || o.Pref <= 0 {
+		return chk.Err("kgc: G0 and pref must be positive. G0 = %g and pref = %g are invalid\n", o.G0, o.Pref)
+	}
+	if o.Nu < 0 || o.Nu >= 0.5 {
+		return chk.Err("kgc: Poisson's coefficient nu must be given and 0 ≤ nu < 0.5. nu = %g is invalid\n", o.Nu)
+	}
+	if o.Pmin <= 0 {
+		o.Pmin = 1e-3 * o.Pref
+	}
+	return
+}
+
+// Calc computes K and G
+func (o *KgcPow) Calc(s *State) (K, G float64) {
+	G = o.calcG(s)
+	K = 2.0 * G * (1.0 + o.Nu) / (3.0 * (1.0 - 2.0*o.Nu))
+	return
+}
+
+// calcG computes G = G0 (p/pref)^n
+func (o *KgcPow) calcG(s *State) float64 {
+	p := math.Max(tsr.M_p(s.Sig), o.Pmin)
+	return o.G0 * math.Pow(p/o.Pref, o.N)
+}
+
+// Init initialises calculator
+func (o *KgcHss) Init(prms fun.Prms) (err error) {
+	err = o.KgcPow.Init(prms)
+	if err != nil {
+		return
+	}
+	for _, p := range prms {
+		switch p.N {
+		case "g07":
+			o.G07 = p.V
+		case "gmin":
+			o.Gmin = p.V
+		}
+	}
+	if o.G07 <= 0 {
+		return chk.Err("kgc: shear strain g07 must be positive. g07 = %g is invalid\n", o.G07)
+	}
+	if o.Gmin < 0 || o.Gmin > 1 {
+		return chk.Err("kgc: ratio gmin must be in [0, 1]. gmin = %g is invalid\n", o.Gmin)
+	}
+	return
+}
+
+// Calc computes K and G
+func (o *KgcHss) Calc(s *State) (K, G float64) {
+	G = o.calcG(s) * o.Degradation(ShearStrain(s.EpsE))
+	K = 2.0 * G * (1.0 + o.Nu) / (3.0 * (1.0 - 2.0*o.Nu))
+	return
+}
+
+// Degradation returns the ratio G/G0 for the shear strain γ
+func (o *KgcHss) Degradation(γ float64) float64 {
+	return math.Max(1.0/(1.0+0.385*γ/o.G07), o.Gmin)
+}
+
+// ShearStrain returns γ = √2 |dev(ε)| (Mandel's basis); zero if ε is nil
+func ShearStrain(ε []float64) float64 {
+	if len(ε) == 0 {
+		return 0
+	}
+	trε := ε[0] + ε[1] + ε[2]
+	var sum float64
+	for i, v := range ε {
+		d := v - trε*tsr.Im[i]/3.0
+		sum += d * d
+	}
+	return math.Sqrt(2.0 * sum)
+}
@@ -32,7 +32,7 @@ func (o LinElast) GetPrms() fun.Prms {
 
 // InitIntVars initialises internal (secondary) variables
 func (o LinElast) InitIntVars(σ []float64) (s *State, err error) {
-	s = NewState(o.Nsig, 0, false, false)
+	s = NewState(o.Nsig, 0, false, o.Kgc != nil)
 	copy(s.Sig, σ)
 	return
 }
@@ -0,0 +1,158 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// MohrCoulomb implements the Mohr-Coulomb (or Tresca if φ = 0) plasticity model with return-mapping
+// in principal stresses space (see PrincRetMap). With σ0 ≥ σ1 ≥ σ2, the yield function is
+//  f = (σ0 - σ2) + (σ0 + σ2) sin(φ) - 2 (c + H α) cos(φ)
+//  and the plastic potential g has the same form with ψ (dilatancy angle) instead of φ
+//  Note: (1) the returns to the edges (σ0 = σ1 or σ1 = σ2) are computed with Koiter's rule
+//        (2) returns to the apex (tension) are not available
+//        (3) angles are given in degrees; ψ = φ (associated) if "psi" is not given
+type MohrCoulomb struct {
+	SmallElasticity
+	C    float64     // cohesion
+	Phi  float64     // friction angle
+	Psi  float64     // dilatancy angle
+	H    float64     // hardening modulus of cohesion
+	rho  float64     // density
+	rmap PrincRetMap // return-mapping
+}
+
+// add model to factory
+func init() {
+	allocators["mc"] = func() Model { return new(MohrCoulomb) }
+	intvars["mc"] = []string{"alpha"}
+}
+
+// Clean clean resources
+func (o *MohrCoulomb) Clean() {
+}
+
+// GetRho returns density
+func (o *MohrCoulomb) GetRho() float64 {
+	return o.rho
+}
+
+// Init initialises model
+func (o *MohrCoulomb) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// parse parameters
+	if pstress {
+		return chk.Err("mc: plane-stress analyses are not available")
+	}
+	err = o.SmallElasticity.Init(ndim, pstress, prms)
+	if err != nil {
+		return
+	}
+	o.Psi = -1
+	for _, p := range prms {
+		switch p.N {
+		case "c":
+			o.C = p.V
+		case "phi":
+			o.Phi = p.V
+		case "psi":
+			o.Psi = p.V
+		case "H":
+			o.H = p.V
+		case "rho":
+			o.rho = p.V
+		case "E", "nu", "l", "G", "K":
+		default:
+			return chk.Err("mc: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.Psi < 0 {
+		o.Psi = o.Phi
+	}
+	if o.Phi < 0 || o.Phi >= 90 || o.Psi > o.Phi {
+		return chk.Err("mc: angles must satisfy 0 ≤ ψ ≤ φ < 90. φ=%g and ψ=%g are invalid", o.Phi, o.Psi)
+	}
+
+	// return-mapping with main plane and planes of adjacent sextants
+	sφ, cφ := math.Sin(o.Phi*math.Pi/180.0), math.Cos(o.Phi*math.Pi/180.0)
+	sψ := math.Sin(o.Psi * math.Pi / 180.0)
+	return o.rmap.Init(ndim, 1, o.K, o.G, []RmSurface{
+		mc_plane{0, 2, sφ, cφ, sψ, o.C, o.H},
+		mc_plane{1, 2, sφ, cφ, sψ, o.C, o.H},
+		mc_plane{0, 1, sφ, cφ, sψ, o.C, o.H},
+	})
+}
+
+// GetPrms gets (an example) of parameters
+func (o MohrCoulomb) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "c", V: 10},
+		&fun.Prm{N: "phi", V: 30},
+		&fun.Prm{N: "psi", V: 0},
+		&fun.Prm{N: "H", V: 0},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o MohrCoulomb) InitIntVars(σ []float64) (s *State, err error) {
+	s = NewState(o.Nsig, 1, false, false)
+	copy(s.Sig, σ)
+	return
+}
+
+// Update updates stresses for given strains
+func (o *MohrCoulomb) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+	return o.rmap.Update(s, Δε)
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o *MohrCoulomb) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+	return o.rmap.CalcD(D, s)
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *MohrCoulomb) ContD(D [][]float64, s *State) (err error) {
+	return chk.Err("ContD is not available in mc model")
+}
+
+// YieldFunc computes the yield function for given principal stresses (any order)
+func (o MohrCoulomb) YieldFunc(σ, α []float64) float64 {
+	σmax := math.Max(σ[0], math.Max(σ[1], σ[2]))
+	σmin := math.Min(σ[0], math.Min(σ[1], σ[2]))
+	sφ, cφ := math.Sin(o.Phi*math.Pi/180.0), math.Cos(o.Phi*math.Pi/180.0)
+	return (σmax - σmin) + (σmax+σmin)*sφ - 2.0*(o.C+o.H*α[0])*cφ
+}
+
+// surfaces /////////////////////////////////////////////////////////////////////////////////////////
+
+// mc_plane implements the Mohr-Coulomb plane f = (σa - σb) + (σa + σb) sin(φ) - 2 (c + H α) cos(φ)
+type mc_plane struct {
+	a, b       int     // indices of principal values
+	sφ, cφ, sψ float64 // sin(φ), cos(φ) and sin(ψ)
+	c, hc      float64 // cohesion and hardening modulus
+}
+
+// F returns the yield function
+func (o mc_plane) F(σ, α []Dual) Dual {
+	return σ[o.a].Sub(σ[o.b]).Add(σ[o.a].Add(σ[o.b]).Scale(o.sφ)).Sub(α[0].Scale(o.hc).Plus(o.c).Scale(2.0 * o.cφ))
+}
+
+// Nb returns Nb = ∂g/∂σ
+func (o mc_plane) Nb(Nb, σ, α []Dual) {
+	for i := 0; i < 3; i++ {
+		Nb[i] = Dual{}
+	}
+	Nb[o.a] = DualConst(1 + o.sψ)
+	Nb[o.b] = DualConst(-1 + o.sψ)
+}
+
+// Hard returns h = 1; i.e. α is the accumulated plastic multiplier
+func (o mc_plane) Hard(h, σ, α []Dual) {
+	h[0] = DualConst(1)
+}
@@ -54,6 +54,22 @@ type Large interface {
 	CalcA(A [][][][]float64, s *State, firstIt bool) error // computes tangent modulus A = (2/J) * ∂τ/∂b . b - σ palm I
 }
 
+// Joint defines models for interfaces, joints and faults in terms of the tractions t = {tn, ts1, [ts2]}
+// (effective; tension positive) and the relative displacements w = {wn, ws1, [ws2]} in the local
+// system of the interface
+type Joint interface {
+	InitIntVars(t []float64) (*State, error)           // initialises AND allocates internal (secondary) variables
+	Update(s *State, Δw []float64, time float64) error // updates tractions for given increment of relative displacements
+	CalcD(D [][]float64, s *State) error               // computes D = dt_new/dw_new consistent with Update
+	Leakance(s *State) float64                         // returns the (mass) leakance across the interface
+	IsOpen(s *State) bool                              // returns whether the interface is open (gap) or not
+}
+
+// JointStrength defines joint models with a tensile strength; e.g. to initiate embedded cracks
+type JointStrength interface {
+	TensileStrength() float64 // returns the tensile strength
+}
+
 // OneD specialises Model to 1D
 type OneD interface {
 	InitIntVars1D() (*OnedState, error)                         // initialises AND allocates internal (secondary) variables
@@ -0,0 +1,54 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import "math"
+
+// Noise implements a seedable source of perturbations that is reproducible across restarts: each
+// value depends only on the seed and on a set of integer indices (e.g. increment and component, or
+// vertex id and direction) and not on the sequence of previous calls (counter-based generator)
+//  Note: the indices are hashed with the SplitMix64 mixing function
+type Noise struct {
+	Amp    float64 // amplitude: values are in [-Amp, Amp] (uniform) or the standard deviation (normal)
+	Seed   int64   // seed; the same seed and indices always give the same values
+	Normal bool    // normal distribution instead of uniform
+}
+
+// Value returns the perturbation corresponding to indices
+func (o *Noise) Value(idx ...int) float64 {
+	if o.Normal {
+		u1 := noise_unit(noise_hash(o.Seed, 1, idx))
+		u2 := noise_unit(noise_hash(o.Seed, 2, idx))
+		if u1 < 1e-300 {
+			u1 = 1e-300
+		}
+		return o.Amp * math.Sqrt(-2.0*math.Log(u1)) * math.Cos(2.0*math.Pi*u2) // Box-Muller
+	}
+	return o.Amp * (2.0*noise_unit(noise_hash(o.Seed, 0, idx)) - 1.0)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// noise_mix implements the SplitMix64 mixing function
+func noise_mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// noise_hash combines seed, stream and indices into a hash
+func noise_hash(seed int64, stream uint64, idx []int) (h uint64) {
+	h = noise_mix(uint64(seed) ^ noise_mix(stream))
+	for _, i := range idx {
+		h = noise_mix(h ^ uint64(i))
+	}
+	return
+}
+
+// noise_unit converts a hash to a number in [0, 1)
+func noise_unit(h uint64) float64 {
+	return float64(h>>11) / (1 << 53)
+}
@@ -0,0 +1,184 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// OnedElastPlast implements an elastoplastic model for rods (e.g. nails, bolts and anchors) with
+// yielding and linear hardening in tension, limited capacity in compression (yielding or Euler
+// buckling) and optional rupture
+//  Note: (1) the compressive capacity is σc = min(sc, π² E I / (Lb² A)), where the Euler term is
+//            only considered if I and Lb are given; there is no hardening in compression
+//        (2) the rod ruptures if the total strain reaches eu (if eu > 0); afterwards, the stress is
+//            zero; i.e. the contribution of the rod is removed
+//        (3) a small residual stiffness kr・E is considered for buckled and ruptured states to avoid
+//            singular matrices
+//        (4) internal variables: Alp = {εp, α, ε, ruptured}; where α is the accumulated plastic
+//            strain in tension and ε is the total strain
+type OnedElastPlast struct {
+	E   float64 // Young's modulus
+	A   float64 // cross-sectional area
+	Sy  float64 // yield stress in tension
+	H   float64 // hardening modulus (tension)
+	Sc  float64 // yield stress in compression (positive)
+	I   float64 // moment of inertia of cross section (for buckling)
+	Lb  float64 // buckling (effective) length
+	Eu  float64 // rupture strain (tension); 0 => no rupture
+	Kr  float64 // ratio of residual stiffness
+	Rho float64 // density
+
+	// derived
+	Scap float64 // compressive capacity σc (positive)
+}
+
+// add model to factory
+func init() {
+	allocators["oned-ep"] = func() Model { return new(OnedElastPlast) }
+}
+
+// Clean clean resources
+func (o *OnedElastPlast) Clean() {
+}
+
+// GetRho returns density
+func (o *OnedElastPlast) GetRho() float64 {
+	return o.Rho
+}
+
+// GetA returns cross-sectional area
+func (o *OnedElastPlast) GetA() float64 {
+	return o.A
+}
+
+// Init initialises model
+func (o *OnedElastPlast) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+	o.Sc, o.Kr = -1, 1e-6
+	for _, p := range prms {
+		switch p.N {
+		case "E":
+			o.E = p.V
+		case "A":
+			o.A = p.V
+		case "sy":
+			o.Sy = p.V
+		case "H":
+			o.H = p.V
+		case "sc":
+			o.Sc = p.V
+		case "I":
+			o.I = p.V
+		case "Lb":
+			o.Lb = p.V
+		case "eu":
+			o.Eu = p.V
+		case "kr":
+			o.Kr = p.V
+		case "rho":
+			o.Rho = p.V
+		}
+	}
+	if o.Sc < 0 {
+		o.Sc = o.Sy
+	}
+	if o.E <= 0 || o.A <= 0 || o.Sy <= 0 || o.Sc <= 0 || o.H < 0 || o.Eu < 0 || o.I < 0 || o.Lb < 0 || o.Kr < 0 || o.Kr >= 1 {
+		return chk.Err("invalid parameters: {E=%g, A=%g, sy=%g, sc=%g} must be > 0, {H=%g, eu=%g, I=%g, Lb=%g} must be ≥ 0 and kr=%g must be in [0,1[", o.E, o.A, o.Sy, o.Sc, o.H, o.Eu, o.I, o.Lb, o.Kr)
+	}
+	o.Scap = o.Sc
+	if o.I > 0 && o.Lb > 0 {
+		o.Scap = math.Min(o.Sc, math.Pi*math.Pi*o.E*o.I/(o.Lb*o.Lb*o.A))
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o OnedElastPlast) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "E", V: 2e8},
+		&fun.Prm{N: "A", V: 5e-4},
+		&fun.Prm{N: "sy", V: 5e5},
+		&fun.Prm{N: "H", V: 2e6},
+		&fun.Prm{N: "I", V: 2e-8},
+		&fun.Prm{N: "Lb", V: 1},
+		&fun.Prm{N: "eu", V: 0.05},
+		&fun.Prm{N: "rho", V: 7.85},
+	}
+}
+
+// InitIntVars: unused
+func (o *OnedElastPlast) InitIntVars(σ []float64) (s *State, err error) {
+	return
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o OnedElastPlast) InitIntVars1D() (s *OnedState, err error) {
+	s = NewOnedState(4, 0) // 4:{εp, α, ε, ruptured}
+	return
+}
+
+// Update updates stresses for given strains
+func (o OnedElastPlast) Update(s *OnedState, ε, Δε, aux float64) (err error) {
+
+	// total strain and rupture
+	s.Alp[2] += Δε
+	s.Dgam, s.Loading = 0, false
+	if o.Eu > 0 && s.Alp[2] >= o.Eu {
+		s.Alp[3] = 1
+	}
+	if s.Alp[3] > 0 {
+		s.Sig = 0
+		return
+	}
+
+	// trial stress
+	σtr := s.Sig + o.E*Δε
+
+	// yielding in tension
+	σy := o.Sy + o.H*s.Alp[1]
+	if σtr > σy {
+		s.Dgam = (σtr - σy) / (o.E + o.H)
+		s.Sig = σtr - o.E*s.Dgam
+		s.Alp[0] += s.Dgam
+		s.Alp[1] += s.Dgam
+		s.Loading = true
+		return
+	}
+
+	// yielding or buckling in compression
+	if σtr < -o.Scap {
+		s.Dgam = (-o.Scap - σtr) / o.E
+		s.Sig = -o.Scap
+		s.Alp[0] -= s.Dgam
+		s.Loading = true
+		return
+	}
+
+	// elastic
+	s.Sig = σtr
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o OnedElastPlast) CalcD(s *OnedState, firstIt bool) (float64, float64, error) {
+	if s.Alp[3] > 0 {
+		return o.Kr * o.E, 0, nil
+	}
+	if s.Loading {
+		if s.Sig > 0 {
+			return math.Max(o.E*o.H/(o.E+o.H), o.Kr*o.E), 0, nil
+		}
+		return o.Kr * o.E, 0, nil
+	}
+	return o.E, 0, nil
+}
+
+// Ruptured returns whether the rod has ruptured or not
+func (o OnedElastPlast) Ruptured(s *OnedState) bool {
+	return s.Alp[3] > 0
+}
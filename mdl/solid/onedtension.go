@@ -0,0 +1,110 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// OnedTension implements a tension-only (no compression) linear elastic model for geotextiles,
+// geogrids and membranes
+//  Note: (1) A is the cross-sectional area of rods or the thickness of membranes; i.e. the area
+//            per unit width of geogrids in 2D (plane-strain) analyses
+//        (2) a small residual stiffness Kc・E is considered under compression (slack state) to
+//            avoid singular matrices; the corresponding (small) compressive stress is also computed
+//        (3) the total strain is stored in Alp[0] and Loading indicates the taut state
+type OnedTension struct {
+	E   float64 // Young's modulus
+	Nu  float64 // Poisson's coefficient (membranes only)
+	A   float64 // cross-sectional area or thickness
+	Kc  float64 // ratio of residual stiffness under compression
+	Rho float64 // density
+}
+
+// add model to factory
+func init() {
+	allocators["oned-tension"] = func() Model { return new(OnedTension) }
+}
+
+// Clean clean resources
+func (o *OnedTension) Clean() {
+}
+
+// GetRho returns density
+func (o *OnedTension) GetRho() float64 {
+	return o.Rho
+}
+
+// GetA returns cross-sectional area
+func (o *OnedTension) GetA() float64 {
+	return o.A
+}
+
+// Init initialises model
+func (o *OnedTension) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+	o.Kc = 1e-6
+	for _, p := range prms {
+		switch p.N {
+		case "E":
+			o.E = p.V
+		case "nu":
+			o.Nu = p.V
+		case "A":
+			o.A = p.V
+		case "kc":
+			o.Kc = p.V
+		case "rho":
+			o.Rho = p.V
+		}
+	}
+	if o.E <= 0 || o.A <= 0 || o.Kc < 0 || o.Kc >= 1 || o.Nu < 0 || o.Nu >= 0.5 {
+		return chk.Err("invalid parameters: {E=%g, A=%g} must be > 0, kc=%g must be in [0,1[ and nu=%g must be in [0,0.5[", o.E, o.A, o.Kc, o.Nu)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o OnedTension) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "E", V: 1e6},
+		&fun.Prm{N: "nu", V: 0},
+		&fun.Prm{N: "A", V: 2e-3},
+		&fun.Prm{N: "kc", V: 1e-6},
+		&fun.Prm{N: "rho", V: 1},
+	}
+}
+
+// InitIntVars: unused
+func (o *OnedTension) InitIntVars(σ []float64) (s *State, err error) {
+	return
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o OnedTension) InitIntVars1D() (s *OnedState, err error) {
+	s = NewOnedState(1, 0) // 1:{ε}
+	s.Loading = true       // taut: initial tangent is E
+	return
+}
+
+// Update updates stresses for given strains
+func (o OnedTension) Update(s *OnedState, ε, Δε, aux float64) (err error) {
+	s.Alp[0] += Δε
+	s.Loading = s.Alp[0] >= 0 // taut
+	if s.Loading {
+		s.Sig = o.E * s.Alp[0]
+		return
+	}
+	s.Sig = o.Kc * o.E * s.Alp[0]
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o OnedTension) CalcD(s *OnedState, firstIt bool) (float64, float64, error) {
+	if s.Loading {
+		return o.E, 0, nil
+	}
+	return o.Kc * o.E, 0, nil
+}
@@ -0,0 +1,258 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// PhaseFieldModel defines models whose stiffness is degraded by a damage (phase) field computed
+// by elements; e.g. for phase-field fracture
+type PhaseFieldModel interface {
+	SetDamage(s *State, d float64)                   // sets the damage d @ ip; to be called before Update
+	Degrad(d float64) (g, dgdd float64)              // degradation function g(d) and its derivative
+	Driving(d, H float64) (f, dfdd float64)          // local term of phase-field equation and its derivative
+	Diffusivity() float64                            // coefficient of gradient term of phase-field equation
+	PosStress(σp []float64, s *State) (loading bool) // σ⁺ = ∂ψ⁺/∂ε and whether H is increasing
+}
+
+// PhaseField implements linear elasticity degraded by a damage (phase) field d for the phase-field
+// modelling of brittle fracture [1,2,3]
+//  The stresses are σ = g(d)・σ⁺ + σ⁻, where g(d) = (1 - k)・(1 - d)² + k is the degradation
+//  function and σ± = ∂ψ±/∂ε are given by the split of the strain energy ψ = ψ⁺ + ψ⁻:
+//    split = 0 (none):                   ψ⁺ = ½ ε:D:ε                 and ψ⁻ = 0
+//    split = 1 (volumetric-deviatoric):  ψ⁺ = ½ K <tr ε>₊² + G e:e    and ψ⁻ = ½ K <tr ε>₋²
+//  The damage is governed by (see ele/fracture)
+//    g'(d)・H + Gc/(cw・l)・w'(d) - 2・Gc・l/cw・∇²d = 0
+//  where H = max ψ⁺ over the loading history (irreversibility) and
+//    AT2: w(d) = d², cw = 2
+//    AT1: w(d) = d,  cw = 8/3
+//  Parameters: "E", "nu" (or other elastic constants), "gc" (fracture toughness), "lc" (length
+//              scale l), "at" (1 or 2; default = 2), "kres" (residual stiffness k; default = 1e-6)
+//              and "split" (0 or 1; default = 1)
+//  Internal variables: α = {d, H}
+//  References:
+//   [1] Miehe C, Hofacker M, Welschinger F. A phase field model for rate-independent crack
+//       propagation: robust algorithmic implementation based on operator splits. Computer Methods
+//       in Applied Mechanics and Engineering, 199:2765-2778; 2010
+//   [2] Amor H, Marigo JJ, Maurini C. Regularized formulation of the variational brittle fracture
+//       with unilateral contact. Journal of the Mechanics and Physics of Solids, 57:1209-1229; 2009
+//   [3] Pham K, Amor H, Marigo JJ, Maurini C. Gradient damage models and their use to approximate
+//       brittle fracture. International Journal of Damage Mechanics, 20:618-652; 2011
+//  Note: (1) the damage d is set by elements with SetDamage before Update
+//        (2) with AT1, H is bounded below by ψc = 3・Gc / (16・l・(1 - k)); i.e. the damage is zero
+//            while ψ⁺ < ψc (elastic stage)
+//        (3) the stresses are computed with the total strains; thus, initial stresses are not
+//            allowed. Plane-stress analyses are not available
+type PhaseField struct {
+	SmallElasticity
+	Gc    float64 // fracture toughness (critical energy release rate)
+	Lc    float64 // length scale
+	At1   bool    // AT1 model; otherwise AT2
+	Kres  float64 // residual stiffness k
+	Split bool    // volumetric-deviatoric split; otherwise no split
+
+	// derived
+	Cw   float64 // normalisation constant cw
+	PsiC float64 // threshold of AT1 model
+
+	// auxiliary
+	σp, σm []float64 // [nsig] positive and negative stresses
+}
+
+// indices of internal variables
+const (
+	pf_d    = 0 // damage
+	pf_H    = 1 // history of positive energy
+	pf_nalp = 2 // number of internal variables
+)
+
+// add model to factory
+func init() {
+	allocators["phase-field"] = func() Model { return new(PhaseField) }
+	intvars["phase-field"] = []string{"d", "H"}
+}
+
+// Clean clean resources
+func (o *PhaseField) Clean() {
+}
+
+// Init initialises model
+func (o *PhaseField) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+	if pstress {
+		return chk.Err("phase-field: plane-stress analyses are not available\n")
+	}
+	err = o.SmallElasticity.Init(ndim, pstress, prms)
+	if err != nil {
+		return
+	}
+	if o.Kgc != nil {
+		return chk.Err("phase-field: non-linear elasticity is not available\n")
+	}
+	o.At1, o.Kres, o.Split = false, 1e-6, true
+	for _, p := range prms {
+		switch p.N {
+		case "gc":
+			o.Gc = p.V
+		case "lc":
+			o.Lc = p.V
+		case "at":
+			switch p.V {
+			case 1:
+				o.At1 = true
+			case 2:
+				o.At1 = false
+			default:
+				return chk.Err("phase-field: at must be 1 or 2. at = %g is invalid\n", p.V)
+			}
+		case "kres":
+			o.Kres = p.V
+		case "split":
+			o.Split = p.V > 0
+		case "E", "nu", "l", "G", "K", "rho":
+		default:
+			return chk.Err("phase-field: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.Gc <= 0 || o.Lc <= 0 {
+		return chk.Err("phase-field: gc and lc must be positive. gc = %g and lc = %g are invalid\n", o.Gc, o.Lc)
+	}
+	if o.Kres < 0 || o.Kres >= 1 {
+		return chk.Err("phase-field: kres must be in [0, 1[. kres = %g is invalid\n", o.Kres)
+	}
+	o.Cw, o.PsiC = 2, 0
+	if o.At1 {
+		o.Cw = 8.0 / 3.0
+		o.PsiC = 3.0 * o.Gc / (16.0 * o.Lc * (1.0 - o.Kres))
+	}
+	o.σp = make([]float64, o.Nsig)
+	o.σm = make([]float64, o.Nsig)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o PhaseField) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "E", V: 210000},
+		&fun.Prm{N: "nu", V: 0.3},
+		&fun.Prm{N: "gc", V: 2.7},
+		&fun.Prm{N: "lc", V: 0.015},
+		&fun.Prm{N: "at", V: 2},
+		&fun.Prm{N: "kres", V: 1e-6},
+		&fun.Prm{N: "split", V: 1},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o PhaseField) InitIntVars(σ []float64) (s *State, err error) {
+	for _, v := range σ {
+		if v != 0 {
+			return nil, chk.Err("phase-field: initial stresses are not allowed. σ = %v is invalid\n", σ)
+		}
+	}
+	s = NewState(o.Nsig, pf_nalp, false, false)
+	return
+}
+
+// SetDamage sets the damage d @ ip
+func (o PhaseField) SetDamage(s *State, d float64) {
+	s.Alp[pf_d] = d
+}
+
+// Update updates stresses for given strains
+//  Note: EpsE holds the total strains
+func (o *PhaseField) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+	copy(s.EpsE, ε)
+	ψp := o.split(o.σp, o.σm, ε)
+	s.Loading = false
+	if ψp > s.Alp[pf_H] {
+		s.Alp[pf_H] = ψp
+		s.Loading = ψp > o.PsiC
+	}
+	g, _ := o.Degrad(s.Alp[pf_d])
+	for i := 0; i < o.Nsig; i++ {
+		s.Sig[i] = g*o.σp[i] + o.σm[i]
+	}
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new (consistent) with frozen damage
+func (o *PhaseField) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+	g, _ := o.Degrad(s.Alp[pf_d])
+	gK := g * o.K
+	if o.Split && s.EpsE[0]+s.EpsE[1]+s.EpsE[2] <= 0 {
+		gK = o.K
+	}
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			D[i][j] = gK*tsr.Im[i]*tsr.Im[j] + 2*g*o.G*tsr.Psd[i][j]
+		}
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *PhaseField) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// Degrad returns the degradation function g(d) and its derivative
+func (o PhaseField) Degrad(d float64) (g, dgdd float64) {
+	g = (1.0-o.Kres)*(1.0-d)*(1.0-d) + o.Kres
+	dgdd = -2.0 * (1.0 - o.Kres) * (1.0 - d)
+	return
+}
+
+// Driving returns the local term f = g'(d)・H + Gc/(cw・l)・w'(d) of the phase-field equation and its
+// derivative with respect to d
+func (o PhaseField) Driving(d, H float64) (f, dfdd float64) {
+	H = math.Max(H, o.PsiC)
+	_, dgdd := o.Degrad(d)
+	c := o.Gc / (o.Cw * o.Lc)
+	if o.At1 {
+		return dgdd*H + c, 2.0 * (1.0 - o.Kres) * H
+	}
+	return dgdd*H + 2.0*c*d, 2.0*(1.0-o.Kres)*H + 2.0*c
+}
+
+// Diffusivity returns the coefficient 2・Gc・l/cw of the gradient term of the phase-field equation
+func (o PhaseField) Diffusivity() float64 {
+	return 2.0 * o.Gc * o.Lc / o.Cw
+}
+
+// PosStress computes σ⁺ = ∂ψ⁺/∂ε and returns whether H is increasing; i.e. H = ψ⁺ in the last
+// Update
+func (o *PhaseField) PosStress(σp []float64, s *State) (loading bool) {
+	o.split(σp, o.σm, s.EpsE)
+	return s.Loading
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// split computes the positive and negative stresses and returns the positive energy ψ⁺
+func (o PhaseField) split(σp, σm, ε []float64) (ψp float64) {
+	trε := ε[0] + ε[1] + ε[2]
+	if !o.Split {
+		for i := 0; i < o.Nsig; i++ {
+			σp[i] = o.L*trε*tsr.Im[i] + 2.0*o.G*ε[i]
+			σm[i] = 0
+			ψp += σp[i] * ε[i] / 2.0
+		}
+		return
+	}
+	trp, trm := math.Max(trε, 0), math.Min(trε, 0)
+	ψp = o.K * trp * trp / 2.0
+	for i := 0; i < o.Nsig; i++ {
+		e := ε[i] - trε*tsr.Im[i]/3.0
+		σp[i] = o.K*trp*tsr.Im[i] + 2.0*o.G*e
+		σm[i] = o.K * trm * tsr.Im[i]
+		ψp += o.G * e * e
+	}
+	return
+}
@@ -26,6 +26,7 @@ var (
 	PlotSet6 = []string{"ed,q", "ed,ev", "p,q,ys", "p,ev", "s3,s1,ys", "oct,ys"}
 	PlotSet7 = []string{"ed,q", "i,f", "p,q,ys", "ed,ev", "p,ev", "s3,s1,ys", "i,alp", "Dgam,f", "oct,ys"}
 	PlotSet8 = []string{"ed,q", "i,f", "p,q,ys", "ed,ev", "log(p),ev", "s3,s1,ys", "i,alp", "Dgam,f", "oct,ys"}
+	PlotSet9 = []string{"p,q", "pi,ys", "mer,ys", "env", "gam,tau", "i,f"}
 )
 
 type RampFcn_t func(x float64) float64
@@ -77,6 +78,13 @@ type Plotter struct {
 	AxLblX   string    // axis y-label. "" => use default
 	AxLblY   string    // axis x-label. "" => use default
 
+	// envelopes and cyclic loops
+	EnvDeps float64  // norm of strain increments for response envelopes
+	EnvNdir int      // number of directions for response envelopes
+	EnvIdx  []int    // indices of states to compute response envelopes; nil => last state
+	CycClrs []string // colors of loops in cyclic plots; nil => default
+	CycTol  float64  // tolerance to detect reversals in cyclic plots
+
 	// SMP coefficients
 	SMPa  float64 // SMP coefficient
 	SMPb  float64 // SMP coefficient
@@ -262,6 +270,14 @@ func (o *Plotter) Plot(keys []string, res []*State, sts [][]float64, first, last
 		case "s3,s1,ys":
 			o.WithYs = true
 			o.Plot_s3_s1(x, y, res, sts, last)
+		case "pi,ys":
+			o.Plot_pi(x, y, res, sts, last)
+		case "mer,ys":
+			o.Plot_mer(x, y, res, sts, last)
+		case "env":
+			o.Plot_env(x, y, res, sts, last)
+		case "gam,tau":
+			o.Plot_gam_tau(x, y, res, sts, last)
 		case "empty":
 			continue
 		default:
@@ -807,6 +823,202 @@ func (o *Plotter) Plot_s3_s1(x, y []float64, res []*State, sts [][]float64, last
 	}
 }
 
+// Plot_pi plots the cross sections of the yield surface in the π-plane at the first and last
+// states with their mean pressures
+func (o *Plotter) Plot_pi(x, y []float64, res []*State, sts [][]float64, last bool) {
+	if o.m == nil {
+		o.set_empty()
+		return
+	}
+	// stress path
+	nr := len(res)
+	k := nr - 1
+	for i := 0; i < nr; i++ {
+		x[i], y[i], _ = tsr.PQW2O(o.P[i], o.Q[i], o.W[i])
+		o.maxR = utl.Max(o.maxR, math.Sqrt(x[i]*x[i]+y[i]*y[i]))
+	}
+	plt.Plot(x, y, io.Sf("'r.', ls='%s', clip_on=0, color='%s', marker='%s', label=r'%s'", o.Ls, o.Clr, o.Mrk, o.Lbl))
+	plt.PlotOne(x[0], y[0], io.Sf("'bo', clip_on=0, color='%s', marker='%s', ms=%d", o.SpClr, o.SpMrk, o.SpMs))
+	plt.PlotOne(x[k], y[k], io.Sf("'bs', clip_on=0, color='%s', marker='%s', ms=%d", o.SpClr, o.EpMrk, o.EpMs))
+	// yield surfaces
+	for j, i := range []int{0, k} {
+		σa, σb, err := YsPiPlane(o.m, res[i], o.P[i], 4*o.NptsOct)
+		if err != nil {
+			chk.Panic("cannot compute cross section of yield surface:\n%v", err)
+		}
+		if len(σa) < 2 {
+			continue
+		}
+		for l := 0; l < len(σa); l++ {
+			o.maxR = utl.Max(o.maxR, math.Sqrt(σa[l]*σa[l]+σb[l]*σb[l]))
+		}
+		σa, σb = append(σa, σa[0]), append(σb, σb[0])
+		clr, ls, lw := o.YsClr0, o.YsLs0, o.YsLw0
+		if j == 1 {
+			clr, ls, lw = o.YsClr1, o.YsLs1, o.YsLw1
+		}
+		plt.Plot(σa, σb, io.Sf("color='%s', ls='%s', lw=%g, clip_on=0", clr, ls, lw)+o.ArgsYs)
+	}
+	// rosette and settings
+	if last {
+		if o.maxR < 1e-10 {
+			o.maxR = 1
+		}
+		tsr.PlotRosette(o.maxR, false, true, true, 6)
+		if o.OctAxOff {
+			plt.AxisOff()
+		}
+		plt.Equal()
+		plt.Gll("$\\sigma_a$", "$\\sigma_b$", "")
+		if lims, ok := o.Lims["pi,ys"]; ok {
+			plt.AxisLims(lims)
+		}
+	}
+}
+
+// Plot_mer plots the compression (q > 0) and extension (q < 0) meridians of the yield surface at
+// the last state and the stress path with q multiplied by the sign of the Lode parameter
+func (o *Plotter) Plot_mer(x, y []float64, res []*State, sts [][]float64, last bool) {
+	if o.m == nil {
+		o.set_empty()
+		return
+	}
+	// stress path
+	nr := len(res)
+	k := nr - 1
+	xmi, xma := o.P[0], o.P[0]
+	for i := 0; i < nr; i++ {
+		x[i], y[i] = o.P[i], o.Q[i]*fun.Sign(o.W[i])
+		xmi = utl.Min(xmi, x[i])
+		xma = utl.Max(xma, x[i])
+	}
+	plt.Plot(x, y, io.Sf("'r.', ls='%s', clip_on=0, color='%s', marker='%s', label=r'%s'", o.Ls, o.Clr, o.Mrk, o.Lbl))
+	plt.PlotOne(x[0], y[0], io.Sf("'bo', clip_on=0, color='%s', marker='%s', ms=%d", o.SpClr, o.SpMrk, o.SpMs))
+	plt.PlotOne(x[k], y[k], io.Sf("'bs', clip_on=0, color='%s', marker='%s', ms=%d", o.SpClr, o.EpMrk, o.EpMs))
+	// meridians
+	if o.UsePmin {
+		xmi = utl.Min(xmi, o.Pmin)
+	}
+	if o.UsePmax {
+		xma = utl.Max(xma, o.Pmax)
+	}
+	xmi, xma, _, _ = o.fix_range(xmi, xmi, xma, 0, 0)
+	if o.PqLims != nil {
+		xmi, xma = o.PqLims[0], o.PqLims[1]
+	}
+	for j, w := range []float64{1, -1} {
+		pp, qq, err := YsMeridian(o.m, res[k], w, xmi, xma, o.NptsPq)
+		if err != nil {
+			chk.Panic("cannot compute meridian of yield surface:\n%v", err)
+		}
+		for l := 0; l < len(qq); l++ {
+			qq[l] *= w
+		}
+		clr, ls, lw := o.YsClr0, o.YsLs0, o.YsLw0
+		if j == 1 {
+			clr, ls, lw = o.YsClr1, o.YsLs1, o.YsLw1
+		}
+		plt.Plot(pp, qq, io.Sf("color='%s', ls='%s', lw=%g, clip_on=0", clr, ls, lw)+o.ArgsYs)
+	}
+	// settings
+	if last {
+		plt.Equal()
+		plt.Gll("$p$", "$q\\,\\mathrm{sign}(w)$", "leg_out=1, leg_ncol=4, leg_hlen=1.5")
+		if lims, ok := o.Lims["mer,ys"]; ok {
+			plt.AxisLims(lims)
+		}
+	}
+}
+
+// Plot_env plots response envelopes in the Rendulic plane (compression positive) at selected
+// states (EnvIdx; default: last state). The envelopes are drawn around the stress points
+func (o *Plotter) Plot_env(x, y []float64, res []*State, sts [][]float64, last bool) {
+	nr := len(res)
+	if o.m == nil || len(sts) != nr {
+		o.set_empty()
+		return
+	}
+	if o.EnvDeps <= 0 {
+		o.EnvDeps = 1e-4
+	}
+	if o.EnvNdir < 3 {
+		o.EnvNdir = 36
+	}
+	idx := o.EnvIdx
+	if len(idx) == 0 {
+		idx = []int{nr - 1}
+	}
+	// stress path
+	for i := 0; i < nr; i++ {
+		x[i] = -(res[i].Sig[1] + res[i].Sig[2]) / tsr.SQ2
+		y[i] = -res[i].Sig[0]
+	}
+	plt.Plot(x, y, io.Sf("'r.', ls='%s', clip_on=0, color='%s', marker='%s', label=r'%s'", o.Ls, o.Clr, o.Mrk, o.Lbl))
+	// envelopes
+	for _, i := range idx {
+		if i < 0 || i >= nr {
+			continue
+		}
+		_, Δσr, Δσa, err := ResponseEnvelope(o.m, res[i], sts[i], o.EnvDeps, o.EnvNdir)
+		if err != nil {
+			chk.Panic("cannot compute response envelope:\n%v", err)
+		}
+		ex, ey := make([]float64, o.EnvNdir+1), make([]float64, o.EnvNdir+1)
+		for j := 0; j <= o.EnvNdir; j++ {
+			ex[j] = x[i] - Δσr[j%o.EnvNdir]
+			ey[j] = y[i] - Δσa[j%o.EnvNdir]
+		}
+		plt.Plot(ex, ey, io.Sf("color='%s', ls='%s', lw=%g, clip_on=0", o.YsClr0, o.YsLs0, o.YsLw0))
+		plt.PlotOne(x[i], y[i], io.Sf("'bs', clip_on=0, color='%s', marker='%s', ms=%d", o.EpClr, o.EpMrk, o.EpMs))
+	}
+	// settings
+	if last {
+		plt.Equal()
+		plt.Gll("$-\\sqrt{2}\\sigma_r$", "$-\\sigma_a$", "leg_out=1, leg_ncol=4, leg_hlen=1.5")
+		if lims, ok := o.Lims["env"]; ok {
+			plt.AxisLims(lims)
+		}
+	}
+}
+
+// Plot_gam_tau plots shear stress versus shear strain (xy components) with one color per loop of
+// cyclic loading
+func (o *Plotter) Plot_gam_tau(x, y []float64, res []*State, sts [][]float64, last bool) {
+	nr := len(res)
+	if len(sts) != nr {
+		o.set_empty()
+		return
+	}
+	clrs := o.CycClrs
+	if len(clrs) == 0 {
+		clrs = []string{"red", "blue", "green", "magenta", "orange", "cyan", "black"}
+	}
+	for i := 0; i < nr; i++ {
+		x[i] = tsr.SQ2 * sts[i][3] * 100.0 // γ = 2 ε_xy
+		y[i] = res[i].Sig[3] / tsr.SQ2     // τ = σ_xy
+	}
+	idx := append(CycleStarts(x[:nr], o.CycTol), nr-1)
+	for j := 1; j < len(idx); j++ {
+		a, b := idx[j-1], idx[j]+1
+		if b-a < 2 {
+			continue
+		}
+		lbl := ""
+		if j == 1 {
+			lbl = o.Lbl
+		}
+		plt.Plot(x[a:b], y[a:b], io.Sf("'r.', ls='%s', clip_on=0, color='%s', marker='%s', label=r'%s'", o.Ls, clrs[(j-1)%len(clrs)], o.Mrk, lbl))
+	}
+	plt.PlotOne(x[0], y[0], io.Sf("'bo', clip_on=0, color='%s', marker='%s', ms=%d", o.SpClr, o.SpMrk, o.SpMs))
+	plt.PlotOne(x[nr-1], y[nr-1], io.Sf("'bs', clip_on=0, color='%s', marker='%s', ms=%d", o.SpClr, o.EpMrk, o.EpMs))
+	if last {
+		plt.Gll("$\\gamma_{xy}\\;[\\%]$", "$\\tau_{xy}$", "leg_out=1, leg_ncol=4, leg_hlen=1.5")
+		if lims, ok := o.Lims["gam,tau"]; ok {
+			plt.AxisLims(lims)
+		}
+	}
+}
+
 // PlotRamp plots the ramp function (contour)
 func (o *Plotter) DrawRamp(xmi, xma, ymi, yma float64) {
 	if o.Rmpf == nil {
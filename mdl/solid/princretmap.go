@@ -0,0 +1,216 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// PrincRetMap implements the return-mapping algorithm in principal stresses space (spectral
+// decomposition) for isotropic models with linear elasticity and one or more yield surfaces. The
+// principal values of the trial stress are sorted (σ0 ≥ σ1 ≥ σ2) and projected by RetMap with
+// nsig = 3; e.g. Mohr-Coulomb and Tresca models are written with the planes of the sextant
+// containing the trial state and the adjacent ones, such that returns to the edges are handled
+// by the active-set iterations. The consistent tangent is
+//  D = [ Σ_ab Tab Pa ⊗ Pb + Σ_a≠b θab Pa ⊠ Pb ] : De
+//  where T = ∂σ_a/∂λ_b is the tangent in principal space, λ are the trial principal stresses and
+//  θab = (σa - σb) / (λa - λb) comes from the derivatives of the eigenprojectors Pa (spin term).
+//  Note: (1) the yield surfaces (RmSurface) receive the principal values [3] instead of σ [nsig]
+//        (2) for repeated trial eigenvalues, θab is replaced by its limit Taa - Tab
+//        (3) the consistent tangent is computed at the end of Update and saved in State.Dad
+type PrincRetMap struct {
+	RetMap             // return-mapping in principal space
+	Ntsr   int         // number of stress components (tensor) = 2 * ndim
+	EvTol  float64     // tolerance to detect repeated eigenvalues
+	DeTsr  [][]float64 // elastic stiffness for the stress tensor [ntsr][ntsr]
+	P      [][]float64 // eigenprojectors of trial stress [3][ntsr]
+	λ      []float64   // trial principal stresses [3]
+	σtsr   []float64   // trial stress tensor [ntsr]
+	ptmp   [][]float64 // auxiliary eigenprojectors [3][ntsr]
+	ltmp   []float64   // auxiliary eigenvalues [3]
+	A      [][]float64 // dσ/dσtr [ntsr][ntsr]
+}
+
+// prm_mi holds the indices (i,j) of the Mandel components
+var prm_mi = [][]int{{0, 0}, {1, 1}, {2, 2}, {0, 1}, {1, 2}, {2, 0}}
+
+// prm_im holds the Mandel indices of the off-diagonal components (i,j)
+var prm_im = [][]int{{0, 3, 5}, {3, 1, 4}, {5, 4, 2}}
+
+// Init initialises this structure for linear elasticity with bulk modulus K and shear modulus G
+func (o *PrincRetMap) Init(ndim, nalp int, K, G float64, surfs []RmSurface) (err error) {
+	err = o.RetMap.init(3, nalp, K, G, surfs)
+	if err != nil {
+		return
+	}
+	if o.EvTol <= 0 {
+		o.EvTol = 1e-10
+	}
+	o.Ntsr = 2 * ndim
+	o.DeTsr = la.MatAlloc(o.Ntsr, o.Ntsr)
+	for i := 0; i < o.Ntsr; i++ {
+		for j := 0; j < o.Ntsr; j++ {
+			o.DeTsr[i][j] = 2.0*G*tsr.Psd[i][j] + K*tsr.Im[i]*tsr.Im[j]
+		}
+	}
+	o.P = la.MatAlloc(3, o.Ntsr)
+	o.λ = make([]float64, 3)
+	o.σtsr = make([]float64, o.Ntsr)
+	o.ptmp = la.MatAlloc(3, o.Ntsr)
+	o.ltmp = make([]float64, 3)
+	o.A = la.MatAlloc(o.Ntsr, o.Ntsr)
+	return
+}
+
+// Update updates stresses and internal variables for the strain increment Δε; the consistent
+// tangent is saved in s.Dad
+func (o *PrincRetMap) Update(s *State, Δε []float64) (err error) {
+
+	// set flags
+	s.Loading = false
+	s.ApexReturn = false
+	s.Dgam = 0
+	if len(s.Dad) != o.Ntsr {
+		s.Dad = la.MatAlloc(o.Ntsr, o.Ntsr)
+	}
+
+	// trial stress
+	De := o.DeTsr
+	for i := 0; i < o.Ntsr; i++ {
+		o.σtsr[i] = s.Sig[i]
+		for j := 0; j < o.Ntsr; j++ {
+			o.σtsr[i] += De[i][j] * Δε[j]
+		}
+	}
+
+	// sorted eigenvalues and eigenprojectors of trial stress
+	err = tsr.M_EigenValsProjsNum(o.ptmp, o.ltmp, o.σtsr)
+	if err != nil {
+		return
+	}
+	idx := []int{0, 1, 2}
+	for i := 0; i < 2; i++ {
+		for j := i + 1; j < 3; j++ {
+			if o.ltmp[idx[j]] > o.ltmp[idx[i]] {
+				idx[i], idx[j] = idx[j], idx[i]
+			}
+		}
+	}
+	for a, k := range idx {
+		o.λ[a] = o.ltmp[k]
+		copy(o.P[a], o.ptmp[k])
+	}
+
+	// return-mapping in principal space
+	copy(o.σtr, o.λ)
+	copy(o.αn, s.Alp)
+	s.Loading, s.Dgam, err = o.project()
+	if err != nil {
+		return
+	}
+
+	// elastic update
+	if !s.Loading {
+		copy(s.Sig, o.σtsr)
+		la.MatCopy(s.Dad, 1, De)
+		return
+	}
+
+	// set new state
+	σ := o.x[:3]
+	for i := 0; i < o.Ntsr; i++ {
+		s.Sig[i] = σ[0]*o.P[0][i] + σ[1]*o.P[1][i] + σ[2]*o.P[2][i]
+	}
+	copy(s.Alp, o.x[3:3+o.Nalp])
+	o.tangent(s.Dad)
+	return
+}
+
+// CalcD returns the consistent tangent computed by Update
+func (o *PrincRetMap) CalcD(D [][]float64, s *State) (err error) {
+	if len(s.Dad) == 0 {
+		la.MatCopy(D, 1, o.DeTsr)
+		return
+	}
+	la.MatCopy(D, 1, s.Dad)
+	return
+}
+
+// tangent computes the consistent tangent D after a plastic update
+func (o *PrincRetMap) tangent(D [][]float64) {
+
+	// T = ∂σ/∂λ = (J⁻¹)σσ Ce
+	var T [3][3]float64
+	for a := 0; a < 3; a++ {
+		for b := 0; b < 3; b++ {
+			for c := 0; c < 3; c++ {
+				T[a][b] += o.Ji[a][c] * o.Ce[c][b]
+			}
+		}
+	}
+
+	// A = dσ/dσtr
+	σ := o.x[:3]
+	la.MatFill(o.A, 0)
+	for a := 0; a < 3; a++ {
+		for b := 0; b < 3; b++ {
+			for I := 0; I < o.Ntsr; I++ {
+				for J := 0; J < o.Ntsr; J++ {
+					o.A[I][J] += T[a][b] * o.P[a][I] * o.P[b][J]
+				}
+			}
+			if a == b {
+				continue
+			}
+			θ := T[a][a] - T[a][b]
+			if math.Abs(o.λ[a]-o.λ[b]) > o.EvTol*(1.0+math.Abs(o.λ[a])+math.Abs(o.λ[b])) {
+				θ = (σ[a] - σ[b]) / (o.λ[a] - o.λ[b])
+			}
+			for I := 0; I < o.Ntsr; I++ {
+				i, j := prm_mi[I][0], prm_mi[I][1]
+				for J := 0; J < o.Ntsr; J++ {
+					k, l := prm_mi[J][0], prm_mi[J][1]
+					v := prm_t(o.P[a], i, k)*prm_t(o.P[b], j, l) + prm_t(o.P[a], i, l)*prm_t(o.P[b], j, k)
+					o.A[I][J] += θ * prm_w(I) * prm_w(J) * v / 2.0
+				}
+			}
+		}
+	}
+
+	// D = A : De
+	De := o.DeTsr
+	for I := 0; I < o.Ntsr; I++ {
+		for J := 0; J < o.Ntsr; J++ {
+			D[I][J] = 0
+			for K := 0; K < o.Ntsr; K++ {
+				D[I][J] += o.A[I][K] * De[K][J]
+			}
+		}
+	}
+}
+
+// prm_w returns the weight of the Mandel component I
+func prm_w(I int) float64 {
+	if I > 2 {
+		return math.Sqrt2
+	}
+	return 1
+}
+
+// prm_t returns the (i,j) component of the tensor given by the Mandel vector a; a may have 4 or
+// 6 components
+func prm_t(a []float64, i, j int) float64 {
+	if i == j {
+		return a[i]
+	}
+	I := prm_im[i][j]
+	if I >= len(a) {
+		return 0
+	}
+	return a[I] / math.Sqrt2
+}
@@ -0,0 +1,419 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// RmSurface defines a yield surface with its plastic potential and hardening law for RetMap. The
+// functions are written with dual numbers (see Dual) such that all derivatives required by the
+// return-mapping and by the consistent tangent are computed automatically
+//  F    -- yield function f(σ, α)
+//  Nb   -- flow direction Nb = ∂g/∂σ [nsig]; e.g. Nb = ∂f/∂σ for associated flow
+//  Hard -- hardening moduli h [nalp]; i.e. Δα = Σ Δγ_k h_k (Koiter's rule)
+type RmSurface interface {
+	F(σ, α []Dual) Dual
+	Nb(Nb, σ, α []Dual)
+	Hard(h, σ, α []Dual)
+}
+
+// RetMap implements an implicit (closest-point projection) return-mapping algorithm for
+// small-strain elastoplastic models with linear elasticity and one or more yield surfaces. The
+// stresses σ, the internal variables α and the increments of plastic multipliers Δγ_k of the
+// active surfaces are computed with Newton's method from the residuals
+//  rσ = Ce (σ - σtr) + Σ Δγ_k Nb_k
+//  rα = α - αn - Σ Δγ_k h_k
+//  rk = f_k                        (for all active surfaces)
+//  The active surfaces are found iteratively: surfaces with Δγ_k < 0 are released and surfaces
+//  with f_k > Fzero are added. The consistent tangent is D = ∂σ/∂ε = (J⁻¹)σσ where J is the
+//  Jacobian of the residuals at the solution
+//  Note: (1) the consistent tangent is computed at the end of Update and saved in State.Dad
+//        (2) the number of internal variables must not exceed AD_NMAX
+type RetMap struct {
+
+	// settings
+	Fzero float64 // zero yield function value
+	Tol   float64 // tolerance on the residuals rσ and rα
+	MaxIt int     // maximum number of Newton iterations
+	MaxAs int     // maximum number of changes of the active set
+
+	// data
+	Nsig  int         // number of stress components
+	Nalp  int         // number of internal variables
+	Surfs []RmSurface // yield surfaces
+	De    [][]float64 // elastic stiffness
+	Ce    [][]float64 // elastic compliance
+
+	// results
+	Nit int // total number of iterations in the last update
+
+	// derivatives of surfaces [nsurf]
+	f  []float64     // f_k
+	N  [][]float64   // ∂f_k/∂σ   [nsig]
+	A  [][]float64   // ∂f_k/∂α   [nalp]
+	nb [][]float64   // Nb_k      [nsig]
+	Mb [][][]float64 // ∂Nb_k/∂σ  [nsig][nsig]
+	a  [][][]float64 // ∂Nb_k/∂α  [nsig][nalp]
+	h  [][]float64   // h_k       [nalp]
+	b  [][][]float64 // ∂h_k/∂σ   [nalp][nsig]
+	c  [][][]float64 // ∂h_k/∂α   [nalp][nalp]
+
+	// workspace
+	σtr []float64   // trial stresses
+	αn  []float64   // α at the beginning of the increment
+	x   []float64   // {σ, α, Δγ_act}
+	r   []float64   // residuals
+	J   [][]float64 // Jacobian
+	Ji  [][]float64 // inverse of Jacobian
+	σd  []Dual      // stresses as dual numbers
+	αd  []Dual      // internal variables as dual numbers
+	nbd []Dual      // flow direction as dual numbers
+	hd  []Dual      // hardening moduli as dual numbers
+}
+
+// Init initialises this structure for linear elasticity with bulk modulus K and shear modulus G
+func (o *RetMap) Init(ndim, nalp int, K, G float64, surfs []RmSurface) (err error) {
+	return o.init(2*ndim, nalp, K, G, surfs)
+}
+
+// init initialises this structure with nsig stress components; e.g. nsig = 3 for principal values
+func (o *RetMap) init(nsig, nalp int, K, G float64, surfs []RmSurface) (err error) {
+
+	// check
+	nsurf := len(surfs)
+	if nsurf < 1 {
+		return chk.Err("RetMap: at least one yield surface is required")
+	}
+	if nalp > AD_NMAX {
+		return chk.Err("RetMap: the number of internal variables (%d) must not exceed %d", nalp, AD_NMAX)
+	}
+
+	// settings
+	if o.Fzero <= 0 {
+		o.Fzero = 1e-9
+	}
+	if o.Tol <= 0 {
+		o.Tol = 1e-12
+	}
+	if o.MaxIt < 1 {
+		o.MaxIt = 30
+	}
+	if o.MaxAs < 1 {
+		o.MaxAs = 2*nsurf + 2
+	}
+
+	// data
+	o.Nsig, o.Nalp, o.Surfs = nsig, nalp, surfs
+	o.De = la.MatAlloc(o.Nsig, o.Nsig)
+	o.Ce = la.MatAlloc(o.Nsig, o.Nsig)
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			o.De[i][j] = 2.0*G*tsr.Psd[i][j] + K*tsr.Im[i]*tsr.Im[j]
+			o.Ce[i][j] = tsr.Psd[i][j]/(2.0*G) + tsr.Im[i]*tsr.Im[j]/(9.0*K)
+		}
+	}
+
+	// derivatives
+	o.f = make([]float64, nsurf)
+	o.N = la.MatAlloc(nsurf, o.Nsig)
+	o.A = la.MatAlloc(nsurf, nalp)
+	o.nb = la.MatAlloc(nsurf, o.Nsig)
+	o.h = la.MatAlloc(nsurf, nalp)
+	o.Mb = make([][][]float64, nsurf)
+	o.a = make([][][]float64, nsurf)
+	o.b = make([][][]float64, nsurf)
+	o.c = make([][][]float64, nsurf)
+	for k := 0; k < nsurf; k++ {
+		o.Mb[k] = la.MatAlloc(o.Nsig, o.Nsig)
+		o.a[k] = la.MatAlloc(o.Nsig, nalp)
+		o.b[k] = la.MatAlloc(nalp, o.Nsig)
+		o.c[k] = la.MatAlloc(nalp, nalp)
+	}
+
+	// workspace
+	n := o.Nsig + nalp + nsurf
+	o.σtr = make([]float64, o.Nsig)
+	o.αn = make([]float64, nalp)
+	o.x = make([]float64, n)
+	o.r = make([]float64, n)
+	o.J = la.MatAlloc(n, n)
+	o.Ji = la.MatAlloc(n, n)
+	o.σd = make([]Dual, o.Nsig)
+	o.αd = make([]Dual, nalp)
+	o.nbd = make([]Dual, o.Nsig)
+	o.hd = make([]Dual, nalp)
+	return
+}
+
+// Update updates stresses and internal variables for the strain increment Δε; the consistent
+// tangent is saved in s.Dad
+func (o *RetMap) Update(s *State, Δε []float64) (err error) {
+
+	// set flags
+	s.Loading = false
+	s.ApexReturn = false
+	s.Dgam = 0
+	if len(s.Dad) != o.Nsig {
+		s.Dad = la.MatAlloc(o.Nsig, o.Nsig)
+	}
+
+	// trial state
+	copy(o.αn, s.Alp)
+	for i := 0; i < o.Nsig; i++ {
+		o.σtr[i] = s.Sig[i]
+		for j := 0; j < o.Nsig; j++ {
+			o.σtr[i] += o.De[i][j] * Δε[j]
+		}
+	}
+
+	// return-mapping
+	s.Loading, s.Dgam, err = o.project()
+	if err != nil {
+		return
+	}
+
+	// elastic update
+	if !s.Loading {
+		copy(s.Sig, o.σtr)
+		la.MatCopy(s.Dad, 1, o.De)
+		return
+	}
+
+	// set new state
+	copy(s.Sig, o.x[:o.Nsig])
+	copy(s.Alp, o.x[o.Nsig:o.Nsig+o.Nalp])
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			s.Dad[i][j] = o.Ji[i][j]
+		}
+	}
+	return
+}
+
+// CalcD returns the consistent tangent computed by Update
+func (o *RetMap) CalcD(D [][]float64, s *State) (err error) {
+	if len(s.Dad) == 0 {
+		la.MatCopy(D, 1, o.De)
+		return
+	}
+	la.MatCopy(D, 1, s.Dad)
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// project projects the trial state {σtr, αn} onto the yield surfaces with active-set
+// iterations. If loading, the solution is saved in x, the sum of increments of plastic
+// multipliers is returned in Δγsum and the inverse of the Jacobian at the solution is saved in Ji
+func (o *RetMap) project() (loading bool, Δγsum float64, err error) {
+
+	// trial yield functions
+	o.Nit = 0
+	o.derivs(o.σtr, o.αn)
+	var act []int
+	for k, f := range o.f {
+		if f > o.Fzero {
+			act = append(act, k)
+		}
+	}
+	if len(act) == 0 {
+		return
+	}
+
+	// active-set iterations
+	for it := 0; it < o.MaxAs; it++ {
+		err = o.solve(act)
+		if err != nil {
+			return
+		}
+		nact := len(act)
+		Δγ := o.x[o.Nsig+o.Nalp : o.Nsig+o.Nalp+nact]
+
+		// release surface with the most negative Δγ
+		kmin := -1
+		for i := 0; i < nact; i++ {
+			if Δγ[i] < 0 && (kmin < 0 || Δγ[i] < Δγ[kmin]) {
+				kmin = i
+			}
+		}
+		if kmin >= 0 && nact > 1 {
+			act = append(act[:kmin], act[kmin+1:]...)
+			continue
+		}
+
+		// add violated surfaces
+		σ, α := o.x[:o.Nsig], o.x[o.Nsig:o.Nsig+o.Nalp]
+		added := false
+		for k := range o.Surfs {
+			if rm_has(act, k) {
+				continue
+			}
+			if o.Surfs[k].F(AdConsts(σ), AdConsts(α)).V > o.Fzero {
+				act = append(act, k)
+				added = true
+			}
+		}
+		if added {
+			continue
+		}
+
+		// results
+		if kmin >= 0 {
+			err = chk.Err("RetMap: the increment of plastic multiplier is negative: Δγ = %g", Δγ[kmin])
+			return
+		}
+		for _, v := range Δγ {
+			Δγsum += v
+		}
+		loading = true
+		return
+	}
+	err = chk.Err("RetMap: cannot find the set of active surfaces after %d changes", o.MaxAs)
+	return
+}
+
+
+// solve solves the return-mapping equations with the surfaces in act being active. The
+// solution is saved in x and the inverse of the Jacobian at the solution in Ji
+func (o *RetMap) solve(act []int) (err error) {
+
+	// initial values
+	ns, na, nact := o.Nsig, o.Nalp, len(act)
+	n := ns + na + nact
+	copy(o.x, o.σtr)
+	copy(o.x[ns:], o.αn)
+	for i := 0; i < nact; i++ {
+		o.x[ns+na+i] = 0
+	}
+	J, Ji := make([][]float64, n), make([][]float64, n)
+	for i := 0; i < n; i++ {
+		J[i], Ji[i] = o.J[i][:n], o.Ji[i][:n]
+	}
+
+	// Newton's method
+	for it := 0; it < o.MaxIt; it++ {
+		o.Nit++
+		σ, α, Δγ := o.x[:ns], o.x[ns:ns+na], o.x[ns+na:n]
+		o.derivs(σ, α)
+
+		// residuals and Jacobian
+		la.MatFill(J, 0)
+		var rmax, fmax float64
+		for i := 0; i < ns; i++ {
+			o.r[i] = 0
+			for j := 0; j < ns; j++ {
+				o.r[i] += o.Ce[i][j] * (σ[j] - o.σtr[j])
+				J[i][j] = o.Ce[i][j]
+			}
+		}
+		for i := 0; i < na; i++ {
+			o.r[ns+i] = α[i] - o.αn[i]
+			J[ns+i][ns+i] = 1
+		}
+		for m, k := range act {
+			for i := 0; i < ns; i++ {
+				o.r[i] += Δγ[m] * o.nb[k][i]
+				for j := 0; j < ns; j++ {
+					J[i][j] += Δγ[m] * o.Mb[k][i][j]
+				}
+				for j := 0; j < na; j++ {
+					J[i][ns+j] += Δγ[m] * o.a[k][i][j]
+				}
+				J[i][ns+na+m] = o.nb[k][i]
+				J[ns+na+m][i] = o.N[k][i]
+			}
+			for i := 0; i < na; i++ {
+				o.r[ns+i] -= Δγ[m] * o.h[k][i]
+				for j := 0; j < ns; j++ {
+					J[ns+i][j] -= Δγ[m] * o.b[k][i][j]
+				}
+				for j := 0; j < na; j++ {
+					J[ns+i][ns+j] -= Δγ[m] * o.c[k][i][j]
+				}
+				J[ns+i][ns+na+m] = -o.h[k][i]
+				J[ns+na+m][ns+i] = o.A[k][i]
+			}
+			o.r[ns+na+m] = o.f[k]
+			fmax = math.Max(fmax, math.Abs(o.f[k]))
+		}
+		for i := 0; i < ns+na; i++ {
+			rmax = math.Max(rmax, math.Abs(o.r[i]))
+		}
+
+		// inverse of Jacobian; also needed by the consistent tangent
+		err = la.MatInvG(Ji, J, 1e-14)
+		if err != nil {
+			return chk.Err("RetMap: cannot invert Jacobian:\n%v", err)
+		}
+
+		// check convergence
+		if rmax <= o.Tol && fmax <= o.Fzero {
+			return
+		}
+
+		// update
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				o.x[i] -= Ji[i][j] * o.r[j]
+			}
+		}
+	}
+	return chk.Err("RetMap: Newton's method did not converge after %d iterations", o.MaxIt)
+}
+
+// derivs computes the values and derivatives of all yield surfaces at (σ, α)
+func (o *RetMap) derivs(σ, α []float64) {
+	for k, srf := range o.Surfs {
+
+		// derivatives with respect to σ
+		for i := 0; i < o.Nsig; i++ {
+			o.σd[i] = DualVar(σ[i], i)
+		}
+		for i := 0; i < o.Nalp; i++ {
+			o.αd[i] = DualConst(α[i])
+		}
+		f := srf.F(o.σd, o.αd)
+		srf.Nb(o.nbd, o.σd, o.αd)
+		srf.Hard(o.hd, o.σd, o.αd)
+		o.f[k] = f.V
+		copy(o.N[k], f.D[:o.Nsig])
+		AdValues(o.nb[k], o.nbd)
+		AdJacobian(o.Mb[k], o.nbd)
+		AdValues(o.h[k], o.hd)
+		AdJacobian(o.b[k], o.hd)
+		if o.Nalp == 0 {
+			continue
+		}
+
+		// derivatives with respect to α
+		for i := 0; i < o.Nsig; i++ {
+			o.σd[i] = DualConst(σ[i])
+		}
+		for i := 0; i < o.Nalp; i++ {
+			o.αd[i] = DualVar(α[i], i)
+		}
+		f = srf.F(o.σd, o.αd)
+		srf.Nb(o.nbd, o.σd, o.αd)
+		srf.Hard(o.hd, o.σd, o.αd)
+		copy(o.A[k], f.D[:o.Nalp])
+		AdJacobian(o.a[k], o.nbd)
+		AdJacobian(o.c[k], o.hd)
+	}
+}
+
+// rm_has returns whether k is in act
+func rm_has(act []int, k int) bool {
+	for _, v := range act {
+		if v == k {
+			return true
+		}
+	}
+	return false
+}
@@ -25,6 +25,7 @@ type RjointM1 struct {
 // add model to factory
 func init() {
 	allocators["rjoint-m1"] = func() Model { return new(RjointM1) }
+	intvars["rjoint-m1"] = []string{"ompb"}
 }
 
 // Set_mu sets μ parameter
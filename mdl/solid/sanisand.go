@@ -0,0 +1,502 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// SaniSand implements a two-surface critical state model for sands (SANISAND; Dafalias-Manzari
+// family) with fabric-dilatancy tensor. The model reproduces the cyclic mobility and liquefaction
+// of sands when used with the (dynamic) u-p porous element
+//  Notation (compression positive):
+//   σc = -σ,  p = tr(σc)/3,  s = dev(σc)  and  r = s/p
+//  Yield surface (narrow cone):
+//   f = |s - p α| - √(2/3) m p   and   n = (r - α) / |r - α|
+//  Critical state line and state parameter:
+//   ec = e0 - λc (p/patm)^ξ   and   ψ = e - ec
+//  Bounding and dilatancy surfaces:
+//   αb = √(2/3) [g(θ,c) M exp(-nb ψ) - m] n   and   αd = √(2/3) [g(θ,c) M exp(nd ψ) - m] n
+//  Hardening (back-stress ratio):
+//   dα = L (2/3) h (αb - α)   with   h = b0 / ((α - αin) : n)   and   b0 = G0 h0 (1 - ch e) (p/patm)^(-1/2)
+//  Plastic flow:
+//   dεp = L R   with   R = B n - C (n² - I/3) + D I / 3   and   D = A0 (1 + ⟨z:n⟩) (αd - α) : n
+//  Fabric-dilatancy tensor:
+//   dz = -cz ⟨-L D⟩ (zmax n + z)
+//  Elasticity:
+//   G = G0 patm (2.97 - e)² / (1 + e) √(p/patm)   and   K = 2 (1 + ν) G / (3 (1 - 2 ν))
+//  Internal variables:
+//   α[0]                 = e   -- void ratio
+//   α[1:1+nsig]          = α   -- back-stress ratio
+//   α[1+nsig:1+2*nsig]   = z   -- fabric-dilatancy tensor
+//   α[1+2*nsig:1+3*nsig] = αin -- back-stress ratio at the last load reversal
+//  Note: the stress update employs explicit sub-steps with the back-stress ratio being corrected
+//        after each sub-step such that the stress remains on the yield surface
+//  References:
+//   [1] Dafalias YF and Manzari MT. Simple plasticity sand model accounting for fabric change
+//       effects. Journal of Engineering Mechanics, 130(6):622-634; 2004
+type SaniSand struct {
+
+	// basic data
+	Nsig int // number of σ and ε components
+
+	// parameters
+	G0   float64 // elastic shear modulus constant
+	ν    float64 // Poisson's coefficient
+	M    float64 // critical state stress ratio (triaxial compression)
+	c    float64 // ratio between critical state stress ratios in extension and compression
+	λc   float64 // critical state line constant
+	e0   float64 // void ratio at zero pressure on the critical state line
+	ξ    float64 // critical state line constant
+	m    float64 // size of yield surface
+	h0   float64 // hardening constant
+	ch   float64 // hardening constant
+	nb   float64 // bounding surface constant
+	A0   float64 // dilatancy constant
+	nd   float64 // dilatancy surface constant
+	zmax float64 // maximum value of fabric-dilatancy tensor
+	cz   float64 // fabric-dilatancy constant
+	patm float64 // atmospheric pressure
+	ein  float64 // initial void ratio
+	pmin float64 // minimum pressure
+	nsub int     // number of sub-steps
+	rho  float64 // density
+
+	// auxiliary
+	σc  []float64   // compression-positive stress
+	dε  []float64   // compression-positive strain increment of sub-step
+	s   []float64   // dev(σc)
+	n   []float64   // unit normal to yield surface
+	n2  []float64   // n² - I/3
+	R   []float64   // direction of plastic flow
+	fσ  []float64   // ∂f/∂σc
+	hα  []float64   // dα/dL
+	DR  []float64   // De ⋅ R
+	Δσ  []float64   // stress increment
+	tmp []float64   // temporary vector
+	De  [][]float64 // elastic modulus
+	t   [][]float64 // temporary 3x3 tensor
+}
+
+// constants
+const (
+	SANI_HTOL = 1e-10 // minimum value of (α - αin) : n to compute h
+)
+
+// add model to factory
+func init() {
+	allocators["sanisand"] = func() Model { return new(SaniSand) }
+}
+
+// Clean clean resources
+func (o *SaniSand) Clean() {
+}
+
+// GetRho returns density
+func (o *SaniSand) GetRho() float64 {
+	return o.rho
+}
+
+// Init initialises model
+func (o *SaniSand) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// basic data
+	o.Nsig = 2 * ndim
+
+	// parameters (default values: Toyoura sand [1])
+	o.G0, o.ν, o.M, o.c, o.λc, o.e0, o.ξ = 125, 0.05, 1.25, 0.712, 0.019, 0.934, 0.7
+	o.m, o.h0, o.ch, o.nb, o.A0, o.nd = 0.01, 7.05, 0.968, 1.1, 0.704, 3.5
+	o.zmax, o.cz, o.patm, o.ein = 4, 600, 101, 0.8
+	o.pmin, o.nsub = 1e-2, 20
+	for _, p := range prms {
+		switch p.N {
+		case "G0":
+			o.G0 = p.V
+		case "nu":
+			o.ν = p.V
+		case "M":
+			o.M = p.V
+		case "c":
+			o.c = p.V
+		case "lamc":
+			o.λc = p.V
+		case "e0":
+			o.e0 = p.V
+		case "xi":
+			o.ξ = p.V
+		case "m":
+			o.m = p.V
+		case "h0":
+			o.h0 = p.V
+		case "ch":
+			o.ch = p.V
+		case "nb":
+			o.nb = p.V
+		case "A0":
+			o.A0 = p.V
+		case "nd":
+			o.nd = p.V
+		case "zmax":
+			o.zmax = p.V
+		case "cz":
+			o.cz = p.V
+		case "patm":
+			o.patm = p.V
+		case "ein":
+			o.ein = p.V
+		case "pmin":
+			o.pmin = p.V
+		case "nsub":
+			o.nsub = int(p.V)
+		case "rho":
+			o.rho = p.V
+		}
+	}
+
+	// check
+	if o.G0 <= 0 || o.M <= 0 || o.m <= 0 || o.patm <= 0 || o.ein <= 0 || o.pmin <= 0 || o.nsub < 1 {
+		return chk.Err("SANISAND: G0, M, m, patm, ein, pmin and nsub must be positive. G0=%g, M=%g, m=%g, patm=%g, ein=%g, pmin=%g, nsub=%d are invalid\n", o.G0, o.M, o.m, o.patm, o.ein, o.pmin, o.nsub)
+	}
+	if o.c <= 0 || o.c > 1 || o.ν < 0 || o.ν >= 0.5 {
+		return chk.Err("SANISAND: parameters must satisfy 0 < c ≤ 1 and 0 ≤ nu < 0.5. c=%g, nu=%g are invalid\n", o.c, o.ν)
+	}
+
+	// auxiliary
+	o.σc = make([]float64, o.Nsig)
+	o.dε = make([]float64, o.Nsig)
+	o.s = make([]float64, o.Nsig)
+	o.n = make([]float64, o.Nsig)
+	o.n2 = make([]float64, o.Nsig)
+	o.R = make([]float64, o.Nsig)
+	o.fσ = make([]float64, o.Nsig)
+	o.hα = make([]float64, o.Nsig)
+	o.DR = make([]float64, o.Nsig)
+	o.Δσ = make([]float64, o.Nsig)
+	o.tmp = make([]float64, o.Nsig)
+	o.De = la.MatAlloc(o.Nsig, o.Nsig)
+	o.t = la.MatAlloc(3, 3)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o *SaniSand) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "G0", V: 125},
+		&fun.Prm{N: "nu", V: 0.05},
+		&fun.Prm{N: "M", V: 1.25},
+		&fun.Prm{N: "c", V: 0.712},
+		&fun.Prm{N: "lamc", V: 0.019},
+		&fun.Prm{N: "e0", V: 0.934},
+		&fun.Prm{N: "xi", V: 0.7},
+		&fun.Prm{N: "m", V: 0.01},
+		&fun.Prm{N: "h0", V: 7.05},
+		&fun.Prm{N: "ch", V: 0.968},
+		&fun.Prm{N: "nb", V: 1.1},
+		&fun.Prm{N: "A0", V: 0.704},
+		&fun.Prm{N: "nd", V: 3.5},
+		&fun.Prm{N: "zmax", V: 4},
+		&fun.Prm{N: "cz", V: 600},
+		&fun.Prm{N: "patm", V: 101},
+		&fun.Prm{N: "ein", V: 0.8},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Note: the initial back-stress ratio is set equal to the initial stress ratio
+func (o *SaniSand) InitIntVars(σ []float64) (s *State, err error) {
+	nalp := 1 + 3*o.Nsig // alp[0] = e, then α, z and αin
+	s = NewState(o.Nsig, nalp, false, false)
+	copy(s.Sig, σ)
+	p := o.set_σc(σ)
+	if p < o.pmin {
+		return nil, chk.Err("SANISAND: initial mean pressure must be greater than pmin=%g. p=%g is invalid\n", o.pmin, p)
+	}
+	s.Alp[0] = o.ein
+	α, αin := o.ivs(s.Alp)
+	for i := 0; i < o.Nsig; i++ {
+		α[i] = o.s[i] / p
+		αin[i] = α[i]
+	}
+	return
+}
+
+// IntVarNames returns the names of internal variables
+func (o *SaniSand) IntVarNames() []string {
+	names := append([]string{"e"}, ivnames("alp", o.Nsig)...)
+	names = append(names, ivnames("z", o.Nsig)...)
+	return append(names, ivnames("alpin", o.Nsig)...)
+}
+
+// Update updates stresses for given strains
+func (o *SaniSand) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+	s.Loading = false
+	s.Dgam = 0
+	α, αin := o.ivs(s.Alp)
+	for k := 0; k < o.nsub; k++ {
+
+		// compression-positive strain increment
+		for i := 0; i < o.Nsig; i++ {
+			o.dε[i] = -Δε[i] / float64(o.nsub)
+		}
+		Δεv := o.dε[0] + o.dε[1] + o.dε[2]
+
+		// trial elastic increment
+		p := o.set_σc(s.Sig)
+		f0 := o.yield_func(p, α)
+		o.calc_De(p, s.Alp[0])
+		la.MatVecMul(o.Δσ, 1, o.De, o.dε)
+		for i := 0; i < o.Nsig; i++ {
+			o.tmp[i] = o.σc[i] + o.Δσ[i]
+		}
+		ftr := o.yield_func(o.set_σc_comp(o.tmp), α)
+		if ftr <= 0 {
+			o.finish_substep(s, o.tmp, Δεv)
+			continue
+		}
+
+		// elastic fraction (transition from elastic to elastic-plastic)
+		r := 0.0
+		if f0 < 0 {
+			a, b := 0.0, 1.0
+			for it := 0; it < 40; it++ {
+				r = (a + b) / 2.0
+				for i := 0; i < o.Nsig; i++ {
+					o.tmp[i] = o.σc[i] + r*o.Δσ[i]
+				}
+				if o.yield_func(o.set_σc_comp(o.tmp), α) > 0 {
+					b = r
+				} else {
+					a = r
+				}
+			}
+			r = a
+			for i := 0; i < o.Nsig; i++ {
+				o.σc[i] += r * o.Δσ[i]
+				o.dε[i] *= (1.0 - r)
+			}
+		}
+
+		// loading index
+		p = o.set_σc_comp(o.σc)
+		Kp, D := o.calc_derivs(p, s.Alp)
+		la.MatVecMul(o.Δσ, 1, o.De, o.dε)
+		la.MatVecMul(o.DR, 1, o.De, o.R)
+		den := Kp + la.VecDot(o.fσ, o.DR)
+		if den <= 0 {
+			return chk.Err("SANISAND: cannot compute loading index. Kp + fσ:De:R = %g ≤ 0\n", den)
+		}
+		L := la.VecDot(o.fσ, o.Δσ) / den
+
+		// unloading => elastic and load reversal
+		if L <= 0 {
+			copy(αin, α)
+			for i := 0; i < o.Nsig; i++ {
+				o.tmp[i] = o.σc[i] + o.Δσ[i]
+			}
+			o.finish_substep(s, o.tmp, Δεv)
+			continue
+		}
+
+		// loading => elastic-plastic
+		for i := 0; i < o.Nsig; i++ {
+			o.tmp[i] = o.σc[i] + o.Δσ[i] - L*o.DR[i]
+			α[i] += L * o.hα[i]
+		}
+		z := s.Alp[1+o.Nsig : 1+2*o.Nsig]
+		if L*D < 0 {
+			for i := 0; i < o.Nsig; i++ {
+				z[i] += o.cz * L * D * (o.zmax*o.n[i] + z[i])
+			}
+		}
+		o.finish_substep(s, o.tmp, Δεv)
+
+		// drift correction: move α such that the stress is on the yield surface
+		p = o.set_σc(s.Sig)
+		if o.yield_func(p, α) > 0 {
+			pe := math.Max(p, o.pmin)
+			for i := 0; i < o.Nsig; i++ {
+				α[i] = o.s[i]/pe - math.Sqrt(2.0/3.0)*o.m*o.n[i]
+			}
+		}
+		s.Dgam += L
+		s.Loading = true
+	}
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+//  Note: the continuum elastoplastic modulus is returned
+func (o *SaniSand) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+	p := o.set_σc(s.Sig)
+	o.calc_De(p, s.Alp[0])
+	la.MatCopy(D, 1, o.De)
+	if !s.Loading {
+		return
+	}
+	Kp, _ := o.calc_derivs(p, s.Alp)
+	la.MatVecMul(o.DR, 1, o.De, o.R)
+	la.MatVecMul(o.tmp, 1, o.De, o.fσ) // De is symmetric
+	den := Kp + la.VecDot(o.fσ, o.DR)
+	if den <= 0 {
+		return chk.Err("SANISAND: cannot compute modulus. Kp + fσ:De:R = %g ≤ 0\n", den)
+	}
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			D[i][j] -= o.DR[i] * o.tmp[j] / den
+		}
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *SaniSand) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// ivs returns views to α and αin
+func (o *SaniSand) ivs(alp []float64) (α, αin []float64) {
+	return alp[1 : 1+o.Nsig], alp[1+2*o.Nsig : 1+3*o.Nsig]
+}
+
+// set_σc sets σc = -σ and s = dev(σc). Returns p
+func (o *SaniSand) set_σc(σ []float64) (p float64) {
+	for i := 0; i < o.Nsig; i++ {
+		o.σc[i] = -σ[i]
+	}
+	return o.set_σc_comp(o.σc)
+}
+
+// set_σc_comp sets s = dev(σc) from a compression-positive stress. Returns p
+func (o *SaniSand) set_σc_comp(σc []float64) (p float64) {
+	p = (σc[0] + σc[1] + σc[2]) / 3.0
+	for i := 0; i < o.Nsig; i++ {
+		o.s[i] = σc[i] - p*tsr.Im[i]
+	}
+	return
+}
+
+// yield_func computes f using p and s = dev(σc)
+func (o *SaniSand) yield_func(p float64, α []float64) (f float64) {
+	for i := 0; i < o.Nsig; i++ {
+		f += math.Pow(o.s[i]-p*α[i], 2)
+	}
+	return math.Sqrt(f) - math.Sqrt(2.0/3.0)*o.m*p
+}
+
+// finish_substep sets the new stress and updates the void ratio
+func (o *SaniSand) finish_substep(s *State, σc []float64, Δεv float64) {
+	for i := 0; i < o.Nsig; i++ {
+		s.Sig[i] = -σc[i]
+	}
+	s.Alp[0] -= (1.0 + s.Alp[0]) * Δεv
+}
+
+// calc_derivs computes n, R, fσ = ∂f/∂σc and hα = dα/dL and returns the plastic modulus and dilatancy
+func (o *SaniSand) calc_derivs(p float64, alp []float64) (Kp, D float64) {
+
+	// unit normal
+	e := alp[0]
+	α, αin := o.ivs(alp)
+	z := alp[1+o.Nsig : 1+2*o.Nsig]
+	pe := math.Max(p, o.pmin)
+	for i := 0; i < o.Nsig; i++ {
+		o.n[i] = o.s[i]/pe - α[i]
+	}
+	nrm := la.VecNorm(o.n)
+	if nrm > 0 {
+		la.VecScale(o.n, 0, 1.0/nrm, o.n)
+	}
+
+	// Lode angle and interpolation function
+	cos3θ := o.n_squared()
+	cos3θ = math.Max(-1, math.Min(1, cos3θ))
+	g := 2.0 * o.c / ((1.0 + o.c) - (1.0-o.c)*cos3θ)
+
+	// state parameter and bounding/dilatancy surfaces
+	ψ := e - (o.e0 - o.λc*math.Pow(pe/o.patm, o.ξ))
+	sq := math.Sqrt(2.0 / 3.0)
+	αbθ := sq * (g*o.M*math.Exp(-o.nb*ψ) - o.m)
+	αdθ := sq * (g*o.M*math.Exp(o.nd*ψ) - o.m)
+	var αn, αinn, zn float64
+	for i := 0; i < o.Nsig; i++ {
+		αn += α[i] * o.n[i]
+		αinn += (α[i] - αin[i]) * o.n[i]
+		zn += z[i] * o.n[i]
+	}
+
+	// hardening
+	b0 := o.G0 * o.h0 * (1.0 - o.ch*e) / math.Sqrt(pe/o.patm)
+	h := b0 / math.Max(αinn, SANI_HTOL)
+	for i := 0; i < o.Nsig; i++ {
+		o.hα[i] = 2.0 * h * (αbθ*o.n[i] - α[i]) / 3.0
+	}
+	Kp = 2.0 * p * h * (αbθ - αn) / 3.0
+
+	// dilatancy and flow direction
+	Ad := o.A0 * (1.0 + math.Max(zn, 0))
+	D = Ad * (αdθ - αn)
+	B := 1.0 + 1.5*(1.0-o.c)*g*cos3θ/o.c
+	C := 3.0 * math.Sqrt(1.5) * (1.0 - o.c) * g / o.c
+	N := αn + sq*o.m
+	for i := 0; i < o.Nsig; i++ {
+		o.R[i] = B*o.n[i] - C*o.n2[i] + D*tsr.Im[i]/3.0
+		o.fσ[i] = o.n[i] - N*tsr.Im[i]/3.0
+	}
+	return
+}
+
+// n_squared computes n2 = n² - I/3 and returns cos(3θ) = √6 tr(n³)
+func (o *SaniSand) n_squared() (cos3θ float64) {
+	o.t[0][0], o.t[1][1], o.t[2][2] = o.n[0], o.n[1], o.n[2]
+	o.t[0][1], o.t[1][0] = o.n[3]/tsr.SQ2, o.n[3]/tsr.SQ2
+	if o.Nsig > 4 {
+		o.t[1][2], o.t[2][1] = o.n[4]/tsr.SQ2, o.n[4]/tsr.SQ2
+		o.t[2][0], o.t[0][2] = o.n[5]/tsr.SQ2, o.n[5]/tsr.SQ2
+	}
+	var trn3 float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			nn := 0.0
+			for k := 0; k < 3; k++ {
+				nn += o.t[i][k] * o.t[k][j]
+			}
+			trn3 += nn * o.t[j][i]
+			if i == j {
+				o.n2[i] = nn - 1.0/3.0
+			}
+			if i == 0 && j == 1 {
+				o.n2[3] = nn * tsr.SQ2
+			}
+			if o.Nsig > 4 && i == 1 && j == 2 {
+				o.n2[4] = nn * tsr.SQ2
+			}
+			if o.Nsig > 4 && i == 2 && j == 0 {
+				o.n2[5] = nn * tsr.SQ2
+			}
+		}
+	}
+	return math.Sqrt(6.0) * trn3
+}
+
+// calc_De computes the (stress and void ratio dependent) elastic modulus
+func (o *SaniSand) calc_De(p, e float64) {
+	pe := math.Max(p, o.pmin)
+	G := o.G0 * o.patm * math.Pow(2.97-e, 2) / (1.0 + e) * math.Sqrt(pe/o.patm)
+	K := 2.0 * (1.0 + o.ν) * G / (3.0 * (1.0 - 2.0*o.ν))
+	a := K - 2.0*G/3.0
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			o.De[i][j] = a * tsr.Im[i] * tsr.Im[j]
+		}
+		o.De[i][i] += 2.0 * G
+	}
+}
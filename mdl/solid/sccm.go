@@ -0,0 +1,215 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// StructuredCamClay implements the modified CamClay model for structured soils (e.g. sensitive and
+// lightly cemented clays) with destructuration. The size of the yield surface is enlarged by the
+// structure variable S, which degrades with plastic straining
+//  f  = q² + M² (p + pt) (p - pc)   with   pc = (1 + S) α0
+//  dα0 = Δγ ch (pa + α0) tr(Nb)   (intrinsic hardening as in CamClayMod)
+//  dS  = -Δγ k S |Nb|             (destructuration; i.e. dS = -k S |dεp|)
+//  Parameters: the parameters of CamClayMod and "S0" (initial structure) and "kS" (rate of
+//              destructuration)
+//  Note: (1) α = {α0, S}; the initial yield surface pc0 = ocr ⋅ (p + q²/(M² (p+pt))) is the
+//            size of the structured surface; thus α0 = pc0 / (1 + S0)
+//        (2) the response after yielding is brittle: the yield surface shrinks if the
+//            destructuration is faster than the intrinsic hardening; with S0 = 0, the model
+//            is equivalent to CamClayMod
+//  References:
+//   [1] Liu MD and Carter JP (2002) A structured Cam Clay model, Canadian Geotechnical Journal,
+//       39(6), 1313-1332
+//   [2] Kavvadas M and Amorosi A (2000) A constitutive model for structured soils, Géotechnique,
+//       50(3), 263-273
+type StructuredCamClay struct {
+	CamClayMod
+	S0 float64 // initial structure
+	Ks float64 // rate of destructuration
+}
+
+// add model to factory
+func init() {
+	allocators["sccm"] = func() Model { return new(StructuredCamClay) }
+	intvars["sccm"] = []string{"p0", "S"}
+}
+
+// Init initialises model
+func (o *StructuredCamClay) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// CamClay
+	err = o.CamClayMod.Init(ndim, pstress, prms)
+	if err != nil {
+		return
+	}
+
+	// parameters
+	for _, p := range prms {
+		switch p.N {
+		case "S0":
+			o.S0 = p.V
+		case "kS":
+			o.Ks = p.V
+		}
+	}
+	if o.S0 < 0 || o.Ks < 0 {
+		return chk.Err("sccm: S0 and kS must be non-negative. S0 = %g and kS = %g are invalid\n", o.S0, o.Ks)
+	}
+
+	// stress updater with this model
+	o.PU.Clean()
+	return o.PU.Init(ndim, prms, o)
+}
+
+// GetPrms gets (an example) of parameters
+func (o *StructuredCamClay) GetPrms() fun.Prms {
+	return append(o.CamClayMod.GetPrms(),
+		&fun.Prm{N: "S0", V: 1},
+		&fun.Prm{N: "kS", V: 10},
+	)
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o *StructuredCamClay) InitIntVars(σ []float64) (s *State, err error) {
+
+	// size of structured surface
+	p, q, w := tsr.M_pqw(σ)
+	M := o.CS.M(w)
+	pt := o.HE.pt
+	var pc float64
+	if math.Abs(p+pt) < 1e-8 {
+		pc = 1e-8
+	} else {
+		pc = p + q*q/(M*M*(p+pt))
+	}
+
+	// set state
+	nalp := 2 // alp[0] = α0 (size of intrinsic surface), alp[1] = S (structure)
+	s = NewState(o.Nsig, nalp, false, true)
+	copy(s.Sig, σ)
+	s.Alp[0] = pc * o.ocr / (1.0 + o.S0)
+	s.Alp[1] = o.S0
+
+	// compute initial strains
+	o.HE.CalcEps0(s)
+	return
+}
+
+// EPmodel ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Info returns some information and data from this model
+func (o *StructuredCamClay) Info() (nalp, nsurf int) {
+	return 2, 1
+}
+
+// L_YieldFunc computes the yield function value for given principal stresses (σ)
+func (o *StructuredCamClay) L_YieldFunc(σ, α []float64) float64 {
+	return o.CamClayMod.L_YieldFunc(σ, []float64{(1.0 + α[1]) * α[0]})
+}
+
+// YieldFuncs computes yield function values
+func (o *StructuredCamClay) YieldFuncs(s *State) []float64 {
+	p, q, w := tsr.M_pqw(s.Sig)
+	M := o.CS.M(w)
+	pt := o.HE.pt
+	pc := (1.0 + s.Alp[1]) * s.Alp[0]
+	n0 := (p + pt) * (p - pc)
+	return []float64{q*q + M*M*n0}
+}
+
+// L_FlowHard computes model variabes for given principal values
+func (o *StructuredCamClay) L_FlowHard(Nb, h, σ, α []float64) (f float64, err error) {
+	p, q, w := tsr.M_pqws(o.s, σ)
+	M := o.CS.M(w)
+	pt := o.HE.pt
+	pc := (1.0 + α[1]) * α[0]
+	n0 := (p + pt) * (p - pc)
+	n1 := 2.0*p + pt - pc
+	I := tsr.Im
+	for i := 0; i < 3; i++ {
+		Nb[i] = 3.0*o.s[i] - M*M*n1*I[i]/3.0 + 2.0*M*n0*o.n[i]
+	}
+	trNb := Nb[0] + Nb[1] + Nb[2]
+	h[0] = o.ch * (o.HE.pa + α[0]) * trNb
+	h[1] = -o.Ks * α[1] * sccm_norm(Nb)
+	f = q*q + M*M*n0
+	return
+}
+
+// L_SecondDerivs computes second order derivatives
+//  N    -- ∂f/∂σ     [nsig]
+//  Nb   -- ∂g/∂σ     [nsig]
+//  A    -- ∂f/∂α_i   [nalp]
+//  h    -- hardening [nalp]
+//  Mb   -- ∂Nb/∂εe   [nsig][nsig]
+//  a_i  -- ∂Nb/∂α_i  [nalp][nsig]
+//  b_i  -- ∂h_i/∂εe  [nalp][nsig]
+//  c_ij -- ∂h_i/∂α_j [nalp][nalp]
+func (o *StructuredCamClay) L_SecondDerivs(N, Nb, A, h []float64, Mb, a, b, c [][]float64, σ, α []float64) (err error) {
+
+	// intrinsic part: as CamClayMod with pc instead of α0
+	p, _, w := tsr.M_pqws(o.s, σ)
+	M := o.CS.M(w)
+	pt := o.HE.pt
+	S := α[1]
+	pc := (1.0 + S) * α[0]
+	n0 := (p + pt) * (p - pc)
+	n1 := 2.0*p + pt - pc
+	I := tsr.Im
+	for i := 0; i < 3; i++ {
+		Nb[i] = 3.0*o.s[i] - M*M*n1*I[i]/3.0 + 2.0*M*n0*o.n[i]
+		N[i] = Nb[i]
+	}
+	d0 := 2.0 * M * M / 9.0
+	d1 := -2.0 * M * n1 / 3.0
+	d2 := 2.0 * n0
+	d3 := 2.0 * M * n0
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			Mb[i][j] = 3.0*tsr.Psd[i][j] + d0*I[i]*I[j] + d1*(I[i]*o.n[j]+o.n[i]*I[j]) + d2*o.n[i]*o.n[j] + d3*o.m[i][j]
+		}
+		dNbdpc := M*M*I[i]/3.0 - 2.0*M*(p+pt)*o.n[i]
+		a[0][i] = (1.0 + S) * dNbdpc
+		a[1][i] = α[0] * dNbdpc
+		b[0][i] = o.ch * (o.HE.pa + α[0]) * M * (2.0*M*I[i]/3.0 - 2.0*n1*o.n[i])
+	}
+	trNb := Nb[0] + Nb[1] + Nb[2]
+	A[0] = -M * M * (p + pt) * (1.0 + S)
+	A[1] = -M * M * (p + pt) * α[0]
+	h[0] = o.ch * (o.HE.pa + α[0]) * trNb
+	c[0][0] = o.ch*trNb + o.ch*(o.HE.pa+α[0])*M*M*(1.0+S)
+	c[0][1] = o.ch * (o.HE.pa + α[0]) * M * M * α[0]
+
+	// destructuration: h1 = -k S |Nb|
+	nrm := sccm_norm(Nb)
+	h[1] = -o.Ks * S * nrm
+	c[1][0], c[1][1] = 0, -o.Ks*nrm
+	for j := 0; j < 3; j++ {
+		b[1][j] = 0
+	}
+	if nrm < 1e-14 {
+		return
+	}
+	for i := 0; i < 3; i++ {
+		u := Nb[i] / nrm // unit(Nb)
+		for j := 0; j < 3; j++ {
+			b[1][j] -= o.Ks * S * u * Mb[i][j]
+		}
+		c[1][0] -= o.Ks * S * u * a[0][i]
+		c[1][1] -= o.Ks * S * u * a[1][i]
+	}
+	return
+}
+
+// sccm_norm returns the norm of principal values
+func sccm_norm(v []float64) float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
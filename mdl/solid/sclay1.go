@@ -0,0 +1,394 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// SClay1 implements the S-CLAY1 model: an anisotropic Cam clay model with an inclined yield surface
+// and rotational hardening for natural (anisotropically consolidated) clays
+//  Notation (compression positive):
+//   σc = -σ,  p = tr(σc)/3,  s = dev(σc),  η = s/p  and  β = s - p α
+//  Yield function (associated flow rule):
+//   f = (3/2) β:β - (M² - (3/2) α:α) (pm - p) p
+//  Hardening:
+//   dpm = pm ⋅ dεvp / (λ* - κ*)
+//   dα  = ω [ (3η/4 - α) ⟨dεvp⟩ + ωd (η/3 - α) dεdp ]
+//  Elasticity:
+//   K = p / κ*   and   G = 3 K (1 - 2 νur) / (2 (1 + νur))
+//  Internal variables:
+//   α[0]        = pm -- size of yield surface
+//   α[1:1+nsig] = α  -- (deviatoric) fabric tensor defining the inclination of the yield surface
+//  Note: the initial inclination is α0 = η0 (e.g. K0-consolidated clay) and pm0 is computed such that
+//        the initial stress is on the yield surface (if ocr = 1). The stress update employs explicit
+//        sub-steps with a drift correction
+//  References:
+//   [1] Wheeler SJ, Näätänen A, Karstunen M and Lojander M. An anisotropic elastoplastic model for
+//       soft clays. Canadian Geotechnical Journal, 40:403-418; 2003
+type SClay1 struct {
+
+	// basic data
+	Nsig int // number of σ and ε components
+
+	// parameters
+	M    float64 // slope of critical state line
+	λs   float64 // λ*: modified compression index
+	κs   float64 // κ*: modified swelling index
+	νur  float64 // Poisson's coefficient for unloading/reloading
+	ω    float64 // rate of rotational hardening
+	ωd   float64 // relative effectiveness of deviatoric plastic strains on rotational hardening
+	ocr  float64 // initial over-consolidation ratio
+	pmin float64 // minimum pressure to compute elastic moduli and η
+	nsub int     // number of sub-steps
+	rho  float64 // density
+
+	// auxiliary
+	σc  []float64   // compression-positive stress
+	dε  []float64   // compression-positive strain increment of sub-step
+	s   []float64   // dev(σc)
+	β   []float64   // s - p α
+	n   []float64   // ∂f/∂σc
+	hα  []float64   // dα/dλ
+	Dn  []float64   // De ⋅ n
+	Δσ  []float64   // stress increment
+	tmp []float64   // temporary vector
+	De  [][]float64 // elastic modulus
+}
+
+// add model to factory
+func init() {
+	allocators["sclay1"] = func() Model { return new(SClay1) }
+}
+
+// Clean clean resources
+func (o *SClay1) Clean() {
+}
+
+// GetRho returns density
+func (o *SClay1) GetRho() float64 {
+	return o.rho
+}
+
+// Init initialises model
+func (o *SClay1) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// basic data
+	o.Nsig = 2 * ndim
+
+	// parameters
+	o.M, o.νur, o.ωd, o.ocr, o.pmin, o.nsub = 1, 0.2, 1, 1, 1e-3, 10
+	φ := -1.0
+	for _, p := range prms {
+		switch p.N {
+		case "M":
+			o.M = p.V
+		case "phi":
+			φ = p.V
+		case "lamS":
+			o.λs = p.V
+		case "kapS":
+			o.κs = p.V
+		case "nur":
+			o.νur = p.V
+		case "om":
+			o.ω = p.V
+		case "omd":
+			o.ωd = p.V
+		case "ocr":
+			o.ocr = p.V
+		case "pmin":
+			o.pmin = p.V
+		case "nsub":
+			o.nsub = int(p.V)
+		case "rho":
+			o.rho = p.V
+		}
+	}
+	if φ > 0 {
+		o.M, _, err = Mmatch(0, φ, 0)
+		if err != nil {
+			return
+		}
+	}
+
+	// check
+	if o.κs <= 0 || o.λs <= o.κs {
+		return chk.Err("S-CLAY1: parameters must satisfy 0 < kapS < lamS. kapS=%g, lamS=%g are invalid\n", o.κs, o.λs)
+	}
+	if o.M <= 0 || o.ω < 0 || o.ωd < 0 || o.pmin <= 0 || o.nsub < 1 {
+		return chk.Err("S-CLAY1: M, pmin and nsub must be positive and om, omd must be non-negative. M=%g, pmin=%g, nsub=%d, om=%g, omd=%g are invalid\n", o.M, o.pmin, o.nsub, o.ω, o.ωd)
+	}
+
+	// auxiliary
+	o.σc = make([]float64, o.Nsig)
+	o.dε = make([]float64, o.Nsig)
+	o.s = make([]float64, o.Nsig)
+	o.β = make([]float64, o.Nsig)
+	o.n = make([]float64, o.Nsig)
+	o.hα = make([]float64, o.Nsig)
+	o.Dn = make([]float64, o.Nsig)
+	o.Δσ = make([]float64, o.Nsig)
+	o.tmp = make([]float64, o.Nsig)
+	o.De = la.MatAlloc(o.Nsig, o.Nsig)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o *SClay1) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "M", V: 1.2},
+		&fun.Prm{N: "lamS", V: 0.1},
+		&fun.Prm{N: "kapS", V: 0.02},
+		&fun.Prm{N: "nur", V: 0.2},
+		&fun.Prm{N: "om", V: 50},
+		&fun.Prm{N: "omd", V: 1},
+		&fun.Prm{N: "ocr", V: 1},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o *SClay1) InitIntVars(σ []float64) (s *State, err error) {
+	nalp := 1 + o.Nsig // alp[0] = pm, alp[1:] = α
+	s = NewState(o.Nsig, nalp, false, false)
+	copy(s.Sig, σ)
+	α := s.Alp[1:]
+	p := o.set_σc(σ)
+	for i := 0; i < o.Nsig; i++ {
+		α[i] = o.s[i] / p
+	}
+	c := o.M*o.M - 1.5*la.VecDot(α, α)
+	if c <= 0 {
+		return nil, chk.Err("S-CLAY1: initial stress ratio is too large; i.e. M² - 1.5 α:α = %g ≤ 0. σ = %v\n", c, σ)
+	}
+	for i := 0; i < o.Nsig; i++ {
+		o.β[i] = o.s[i] - p*α[i]
+	}
+	s.Alp[0] = o.ocr * (p + 1.5*la.VecDot(o.β, o.β)/(c*p))
+	return
+}
+
+// IntVarNames returns the names of internal variables
+func (o *SClay1) IntVarNames() []string {
+	return append([]string{"pm"}, ivnames("alp", o.Nsig)...)
+}
+
+// Update updates stresses for given strains
+func (o *SClay1) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+
+	// sub-steps
+	s.Loading = false
+	s.Dgam = 0
+	for k := 0; k < o.nsub; k++ {
+
+		// compression-positive strain increment
+		for i := 0; i < o.Nsig; i++ {
+			o.dε[i] = -Δε[i] / float64(o.nsub)
+		}
+
+		// trial elastic increment
+		p := o.set_σc(s.Sig)
+		f0 := o.yield_func(p, s.Alp)
+		o.calc_De(p)
+		la.MatVecMul(o.Δσ, 1, o.De, o.dε)
+		for i := 0; i < o.Nsig; i++ {
+			o.tmp[i] = o.σc[i] + o.Δσ[i]
+		}
+		p = o.set_σc_comp(o.tmp)
+		ftr := o.yield_func(p, s.Alp)
+		if ftr <= 0 {
+			for i := 0; i < o.Nsig; i++ {
+				s.Sig[i] = -o.tmp[i]
+			}
+			continue
+		}
+
+		// elastic fraction (transition from elastic to elastic-plastic)
+		r := 0.0
+		if f0 < 0 {
+			a, b := 0.0, 1.0
+			for it := 0; it < 40; it++ {
+				r = (a + b) / 2.0
+				for i := 0; i < o.Nsig; i++ {
+					o.tmp[i] = o.σc[i] + r*o.Δσ[i]
+				}
+				if o.yield_func(o.set_σc_comp(o.tmp), s.Alp) > 0 {
+					b = r
+				} else {
+					a = r
+				}
+			}
+			r = a
+			for i := 0; i < o.Nsig; i++ {
+				o.σc[i] += r * o.Δσ[i]
+			}
+		}
+
+		// elastic-plastic increment
+		for i := 0; i < o.Nsig; i++ {
+			o.dε[i] *= (1.0 - r)
+		}
+		p = o.set_σc_comp(o.σc)
+		o.calc_De(p)
+		H := o.calc_grads(p, s.Alp)
+		la.MatVecMul(o.Dn, 1, o.De, o.n)
+		den := la.VecDot(o.n, o.Dn) + H
+		if den <= 0 {
+			return chk.Err("S-CLAY1: cannot compute plastic multiplier. n:De:n + H = %g ≤ 0\n", den)
+		}
+		Δλ := math.Max(la.VecDot(o.Dn, o.dε)/den, 0)
+		la.MatVecMul(o.Δσ, 1, o.De, o.dε)
+		for i := 0; i < o.Nsig; i++ {
+			o.σc[i] += o.Δσ[i] - Δλ*o.Dn[i]
+		}
+		o.harden(s.Alp, Δλ)
+
+		// drift correction
+		p = o.set_σc_comp(o.σc)
+		f := o.yield_func(p, s.Alp)
+		H = o.calc_grads(p, s.Alp)
+		la.MatVecMul(o.Dn, 1, o.De, o.n)
+		den = la.VecDot(o.n, o.Dn) + H
+		if den > 0 {
+			δλ := f / den
+			for i := 0; i < o.Nsig; i++ {
+				o.σc[i] -= δλ * o.Dn[i]
+			}
+			o.harden(s.Alp, δλ)
+			Δλ += δλ
+		}
+
+		// results
+		for i := 0; i < o.Nsig; i++ {
+			s.Sig[i] = -o.σc[i]
+		}
+		s.Dgam += Δλ
+		s.Loading = true
+	}
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+//  Note: the continuum elastoplastic modulus is returned
+func (o *SClay1) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+	p := o.set_σc(s.Sig)
+	o.calc_De(p)
+	if !s.Loading {
+		la.MatCopy(D, 1, o.De)
+		return
+	}
+	H := o.calc_grads(p, s.Alp)
+	la.MatVecMul(o.Dn, 1, o.De, o.n)
+	den := la.VecDot(o.n, o.Dn) + H
+	if den <= 0 {
+		return chk.Err("S-CLAY1: cannot compute modulus. n:De:n + H = %g ≤ 0\n", den)
+	}
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			D[i][j] = o.De[i][j] - o.Dn[i]*o.Dn[j]/den
+		}
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *SClay1) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// set_σc sets σc = -σ and s = dev(σc). Returns p
+func (o *SClay1) set_σc(σ []float64) (p float64) {
+	for i := 0; i < o.Nsig; i++ {
+		o.σc[i] = -σ[i]
+	}
+	return o.set_σc_comp(o.σc)
+}
+
+// set_σc_comp sets s = dev(σc) from a compression-positive stress. Returns p
+func (o *SClay1) set_σc_comp(σc []float64) (p float64) {
+	p = (σc[0] + σc[1] + σc[2]) / 3.0
+	for i := 0; i < o.Nsig; i++ {
+		o.s[i] = σc[i] - p*tsr.Im[i]
+	}
+	return
+}
+
+// yield_func computes f using p and s = dev(σc)
+func (o *SClay1) yield_func(p float64, alp []float64) float64 {
+	α := alp[1:]
+	for i := 0; i < o.Nsig; i++ {
+		o.β[i] = o.s[i] - p*α[i]
+	}
+	return 1.5*la.VecDot(o.β, o.β) - (o.M*o.M-1.5*la.VecDot(α, α))*(alp[0]-p)*p
+}
+
+// calc_grads computes n = ∂f/∂σc, hα = dα/dλ and returns the plastic modulus H = -(∂f/∂pm⋅dpm/dλ + ∂f/∂α:dα/dλ)
+func (o *SClay1) calc_grads(p float64, alp []float64) (H float64) {
+
+	// gradient
+	pm, α := alp[0], alp[1:]
+	c := o.M*o.M - 1.5*la.VecDot(α, α)
+	for i := 0; i < o.Nsig; i++ {
+		o.β[i] = o.s[i] - p*α[i]
+	}
+	βα := la.VecDot(o.β, α)
+	for i := 0; i < o.Nsig; i++ {
+		o.n[i] = 3.0*o.β[i] - (βα+c*(pm-2.0*p)/3.0)*tsr.Im[i]
+	}
+
+	// plastic strain invariants (per unit λ)
+	εv := o.n[0] + o.n[1] + o.n[2]
+	εd := 0.0
+	for i := 0; i < o.Nsig; i++ {
+		o.tmp[i] = o.n[i] - εv*tsr.Im[i]/3.0
+		εd += o.tmp[i] * o.tmp[i]
+	}
+	εd = math.Sqrt(2.0 * εd / 3.0)
+
+	// rotational hardening
+	pe := math.Max(p, o.pmin)
+	for i := 0; i < o.Nsig; i++ {
+		η := o.s[i] / pe
+		o.hα[i] = o.ω * ((0.75*η-α[i])*math.Max(εv, 0) + o.ωd*(η/3.0-α[i])*εd)
+	}
+
+	// plastic modulus
+	dfdpm := -c * p
+	hpm := pm * εv / (o.λs - o.κs)
+	H = -dfdpm * hpm
+	for i := 0; i < o.Nsig; i++ {
+		H -= (-3.0*p*o.β[i] + 3.0*α[i]*(pm-p)*p) * o.hα[i]
+	}
+	return
+}
+
+// harden updates internal variables using the last computed gradients
+func (o *SClay1) harden(alp []float64, Δλ float64) {
+	εv := o.n[0] + o.n[1] + o.n[2]
+	alp[0] *= math.Exp(Δλ * εv / (o.λs - o.κs))
+	for i := 0; i < o.Nsig; i++ {
+		alp[1+i] += Δλ * o.hα[i]
+	}
+}
+
+// calc_De computes the (stress dependent) elastic modulus
+func (o *SClay1) calc_De(p float64) {
+	K := math.Max(p, o.pmin) / o.κs
+	G := Calc_G_from_Knu(K, o.νur)
+	a := K - 2.0*G/3.0
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			o.De[i][j] = a * tsr.Im[i] * tsr.Im[j]
+		}
+		o.De[i][i] += 2.0 * G
+	}
+}
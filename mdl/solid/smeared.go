@@ -0,0 +1,354 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// CharLengthSetter defines models that require the characteristic length of elements; e.g. for
+// the crack band regularisation of softening. The length is stored in the state of each ip
+type CharLengthSetter interface {
+	SetCharLength(s *State, h float64) // sets the characteristic length h of the element of state s
+}
+
+// SmearedCrack implements a total-strain smeared crack model with tension softening and shear
+// retention; e.g. for masonry (homogenised) and cemented materials
+//  The stresses are computed in the crack frame {n0, n1, n2} as follows:
+//    σ'_kk = (1 - d_k) σe'_kk  if σe'_kk > 0 (open crack), otherwise σ'_kk = σe'_kk
+//    σ'_ij = β σe'_ij          if direction i or j is cracked (i ≠ j)
+//  where σe' = λ tr(ε') I + 2 G ε' are the elastic stresses and ε' = Qᵀ ε Q. The damage d_k
+//  depends on the largest normal strain κ_k reached along n_k and on the softening law:
+//    linear:      σ(κ) = ft (εu - κ) / (εu - ε0)      with  εu = 2 Gf / (ft h)
+//    exponential: σ(κ) = ft exp(-(κ - ε0) / εf)     with  εf = Gf / (ft h) - ε0 / 2
+//  where ε0 = ft / E and h is the characteristic length of the element (crack band); thus the
+//  dissipated energy per unit area of crack is equal to the fracture energy Gf regardless of h
+//  Fixed crack: the frame is given by the principal directions of strains at crack initiation
+//  Rotating crack: the frame is given by the current principal directions of strains (coaxial)
+//  Parameters: "E", "nu" (or other elastic constants), "ft", "Gf", "beta" (shear retention;
+//              default = 0.1), "fixed" (1 => fixed crack; default = 0 => rotating), "soft"
+//              (0 => linear; 1 => exponential), "h" (length if not set by elements; default = 1)
+//  Internal variables: α = {κ0, κ1, κ2, fixed flag, n0, n1, n2, h}
+//  Note: (1) the tangent stiffness is the secant stiffness; i.e. the damage is frozen
+//        (2) if h is too large (snap-back; e.g. h > 2 E Gf / ft² for linear softening), the
+//            softening is replaced by a sudden drop of stress after ε0
+//        (3) plane-stress analyses are not available
+type SmearedCrack struct {
+	SmallElasticity
+	Ft    float64 // tensile strength
+	Gf    float64 // fracture energy
+	Beta  float64 // shear retention factor
+	Fixed bool    // fixed crack; otherwise rotating
+	Exp   bool    // exponential softening; otherwise linear
+	H     float64 // default characteristic length
+
+	// auxiliary
+	ε, σ, Q [][]float64 // [3][3] tensors and frame
+	A       [][]float64 // [3][3] matrix for eigenvectors
+	λ       []float64   // [3] eigenvalues
+	em      []float64   // [nsig] unit Mandel vector
+}
+
+// indices of internal variables
+const (
+	sc_kap   = 0  // κ0, κ1, κ2
+	sc_fixed = 3  // flag: frame is fixed
+	sc_frame = 4  // n0, n1, n2 (9 values)
+	sc_h     = 13 // characteristic length
+	sc_nalp  = 14 // number of internal variables
+)
+
+// add model to factory
+func init() {
+	allocators["smeared-crack"] = func() Model { return new(SmearedCrack) }
+	intvars["smeared-crack"] = append(append(append(ivnames("kap", 3), "fixed"), ivnames("frame", 9)...), "lch")
+}
+
+// Clean clean resources
+func (o *SmearedCrack) Clean() {
+}
+
+// Init initialises model
+func (o *SmearedCrack) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+	if pstress {
+		return chk.Err("smeared-crack: plane-stress analyses are not available\n")
+	}
+	err = o.SmallElasticity.Init(ndim, pstress, prms)
+	if err != nil {
+		return
+	}
+	o.Beta, o.H, o.Fixed, o.Exp = 0.1, 1, false, false
+	for _, p := range prms {
+		switch p.N {
+		case "ft":
+			o.Ft = p.V
+		case "Gf":
+			o.Gf = p.V
+		case "beta":
+			o.Beta = p.V
+		case "fixed":
+			o.Fixed = p.V > 0
+		case "soft":
+			o.Exp = p.V > 0
+		case "h":
+			o.H = p.V
+		case "E", "nu", "l", "G", "K", "rho":
+		default:
+			return chk.Err("smeared-crack: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.Ft <= 0 || o.Gf <= 0 || o.H <= 0 {
+		return chk.Err("smeared-crack: ft, Gf and h must be positive. ft = %g, Gf = %g and h = %g are invalid\n", o.Ft, o.Gf, o.H)
+	}
+	if o.Beta < 0 || o.Beta > 1 {
+		return chk.Err("smeared-crack: shear retention factor must be in [0, 1]. beta = %g is invalid\n", o.Beta)
+	}
+	o.ε, o.σ, o.Q, o.A = la.MatAlloc(3, 3), la.MatAlloc(3, 3), la.MatAlloc(3, 3), la.MatAlloc(3, 3)
+	o.λ = make([]float64, 3)
+	o.em = make([]float64, o.Nsig)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o SmearedCrack) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "E", V: 30000},
+		&fun.Prm{N: "nu", V: 0.2},
+		&fun.Prm{N: "ft", V: 3},
+		&fun.Prm{N: "Gf", V: 0.1},
+		&fun.Prm{N: "beta", V: 0.1},
+		&fun.Prm{N: "fixed", V: 0},
+		&fun.Prm{N: "soft", V: 0},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o SmearedCrack) InitIntVars(σ []float64) (s *State, err error) {
+	s = NewState(o.Nsig, sc_nalp, false, false)
+	copy(s.Sig, σ)
+	for k := 0; k < 3; k++ {
+		s.Alp[sc_frame+4*k] = 1 // identity frame
+	}
+	s.Alp[sc_h] = o.H
+	return
+}
+
+// SetCharLength sets the characteristic length h of the element of state s
+func (o SmearedCrack) SetCharLength(s *State, h float64) {
+	s.Alp[sc_h] = h
+}
+
+// Update updates stresses for given strains
+func (o *SmearedCrack) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+
+	// frame
+	s.Loading = false
+	o.m2t(o.ε, ε)
+	if !(o.Fixed && s.Alp[sc_fixed] > 0) {
+		err = o.principal_frame(s)
+		if err != nil {
+			return
+		}
+	}
+	o.get_frame(s)
+
+	// history and crack initiation
+	ε0 := o.Ft / o.E
+	for k := 0; k < 3; k++ {
+		εkk := sc_normal(o.Q, o.ε, k)
+		if εkk > s.Alp[sc_kap+k] {
+			s.Alp[sc_kap+k] = εkk
+			if εkk > ε0 {
+				s.Loading = true
+				s.Alp[sc_fixed] = 1
+			}
+		}
+	}
+
+	// stresses
+	o.secant(s.Sig, ε, s, nil)
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new (secant)
+func (o *SmearedCrack) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+	o.get_frame(s)
+	o.m2t(o.σ, s.Sig)
+	var open [3]bool
+	for k := 0; k < 3; k++ {
+		open[k] = sc_normal(o.Q, o.σ, k) > 0
+	}
+	col := make([]float64, o.Nsig)
+	for m := 0; m < o.Nsig; m++ {
+		la.VecFill(o.em, 0)
+		o.em[m] = 1
+		o.secant(col, o.em, s, open[:])
+		for i := 0; i < o.Nsig; i++ {
+			D[i][m] = col[i]
+		}
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *SmearedCrack) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// Damage returns the damage d and the stress σ(κ) for the largest normal strain κ
+func (o *SmearedCrack) Damage(κ, h float64) (d, σ float64) {
+	ε0 := o.Ft / o.E
+	if κ <= ε0 {
+		return 0, o.E * κ
+	}
+	if o.Exp {
+		εf := o.Gf/(o.Ft*h) - ε0/2.0
+		if εf <= 0 { // snap-back
+			return 1, 0
+		}
+		σ = o.Ft * math.Exp(-(κ-ε0)/εf)
+	} else {
+		εu := 2.0 * o.Gf / (o.Ft * h)
+		if εu <= ε0 || κ >= εu { // snap-back or fully open
+			return 1, 0
+		}
+		σ = o.Ft * (εu - κ) / (εu - ε0)
+	}
+	return 1.0 - σ/(o.E*κ), σ
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// secant computes σ = S(ε) with the frame, damage and shear retention of state s
+//  open -- [3] flags of open cracks; nil => from sign of elastic stresses
+func (o *SmearedCrack) secant(σ, ε []float64, s *State, open []bool) {
+
+	// strains in crack frame
+	var εc [3][3]float64
+	o.m2t(o.ε, ε)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				for l := 0; l < 3; l++ {
+					εc[i][j] += o.Q[k][i] * o.ε[k][l] * o.Q[l][j]
+				}
+			}
+		}
+	}
+
+	// stresses in crack frame
+	var d [3]float64
+	var cracked [3]bool
+	for k := 0; k < 3; k++ {
+		d[k], _ = o.Damage(s.Alp[sc_kap+k], s.Alp[sc_h])
+		cracked[k] = d[k] > 0
+	}
+	trε := εc[0][0] + εc[1][1] + εc[2][2]
+	var σc [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			σc[i][j] = 2.0 * o.G * εc[i][j]
+			if i == j {
+				σc[i][j] += o.L * trε
+				isopen := σc[i][i] > 0
+				if open != nil {
+					isopen = open[i]
+				}
+				if isopen {
+					σc[i][j] *= 1.0 - d[i]
+				}
+				continue
+			}
+			if cracked[i] || cracked[j] {
+				σc[i][j] *= o.Beta
+			}
+		}
+	}
+
+	// back to global frame: σ = Q σc Qᵀ
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.σ[i][j] = 0
+			for k := 0; k < 3; k++ {
+				for l := 0; l < 3; l++ {
+					o.σ[i][j] += o.Q[i][k] * σc[k][l] * o.Q[j][l]
+				}
+			}
+		}
+	}
+	o.t2m(σ, o.σ)
+}
+
+// principal_frame computes the principal directions of strains (o.ε) in decreasing order of
+// principal values and stores them in state s
+func (o *SmearedCrack) principal_frame(s *State) (err error) {
+	la.MatCopy(o.A, 1, o.ε)
+	err = la.Jacobi(o.Q, o.λ, o.A)
+	if err != nil {
+		return chk.Err("smeared-crack: cannot compute principal directions of strains:\n%v", err)
+	}
+	idx := []int{0, 1, 2}
+	for i := 1; i < 3; i++ {
+		for j := i; j > 0 && o.λ[idx[j]] > o.λ[idx[j-1]]; j-- {
+			idx[j], idx[j-1] = idx[j-1], idx[j]
+		}
+	}
+	for k, c := range idx {
+		for i := 0; i < 3; i++ {
+			s.Alp[sc_frame+3*k+i] = o.Q[i][c]
+		}
+	}
+	return
+}
+
+// get_frame sets o.Q with the frame in state s; i.e. Q[i][k] = (n_k)_i
+func (o *SmearedCrack) get_frame(s *State) {
+	for k := 0; k < 3; k++ {
+		for i := 0; i < 3; i++ {
+			o.Q[i][k] = s.Alp[sc_frame+3*k+i]
+		}
+	}
+}
+
+// m2t converts Mandel components to tensor
+func (o *SmearedCrack) m2t(t [][]float64, m []float64) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			t[i][j] = tsr.M2T(m, i, j)
+		}
+	}
+}
+
+// t2m converts tensor to Mandel components (components out of plane are ignored in 2D)
+func (o *SmearedCrack) t2m(m []float64, t [][]float64) {
+	for i := 0; i < 3; i++ {
+		for j := i; j < 3; j++ {
+			I := tsr.T2MI[i][j]
+			if I >= len(m) {
+				continue
+			}
+			if i == j {
+				m[I] = t[i][j]
+			} else {
+				m[I] = tsr.SQ2 * t[i][j]
+			}
+		}
+	}
+}
+
+// sc_normal returns the normal component n_kᵀ・T・n_k of tensor T along direction k of frame Q
+func sc_normal(Q, T [][]float64, k int) (v float64) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			v += Q[i][k] * T[i][j] * Q[j][k]
+		}
+	}
+	return
+}
@@ -44,6 +44,7 @@ type SmpInvs struct {
 // add model to factory
 func init() {
 	allocators["smp"] = func() Model { return new(SmpInvs) }
+	intvars["smp"] = []string{"epsp"}
 }
 
 // Clean clean resources
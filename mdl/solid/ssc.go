@@ -0,0 +1,328 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// SoftSoilCreep implements the Soft Soil Creep (isotache) model for secondary consolidation
+//  Equivalent pressure and creep strain rate:
+//   peq  = p + q² / (M² p)
+//   dεc/dt = λdot ⋅ ∂peq/∂σ   with   λdot = (μ*/τ) ⋅ (peq/pp)^((λ*-κ*)/μ*) / (∂peq/∂p)
+//  Elasticity:
+//   K = p / κ*   and   G = 3 K (1 - 2 νur) / (2 (1 + νur))
+//  Hardening (preconsolidation pressure):
+//   dpp = - pp ⋅ dεvc / (λ* - κ*)
+//  Internal variables:
+//   α[0] = pp -- preconsolidation pressure (isotache)
+//   α[1] = t  -- time corresponding to the state (time of last update)
+//  Note: (1) the stress update is explicit w.r.t the creep rate; sub-steps are automatically
+//            employed to keep the creep multiplier increment (λdot⋅dt) of each sub-step below
+//            DεcMax. Since the creep rate decreases rapidly with peq/pp, large time steps of
+//            consolidation analyses can be used
+//        (2) the algorithmic tangent is computed at the end of Update by central differences of the
+//            sub-stepping w.r.t Δε and saved in State.Dad
+//  References:
+//   [1] Vermeer PA and Neher HP. A soft soil model that accounts for creep. In: Beyond 2000 in
+//       Computational Geotechnics, Brinkgreve RBJ (Ed.), Balkema, 249-261; 1999
+type SoftSoilCreep struct {
+
+	// basic data
+	Nsig int       // number of σ and ε components
+	CS   tsr.NcteM // slope of cs line
+
+	// parameters
+	λs     float64 // λ*: modified compression index
+	κs     float64 // κ*: modified swelling index
+	μs     float64 // μ*: modified creep index
+	τ      float64 // reference time
+	νur    float64 // Poisson's coefficient for unloading/reloading
+	ocr    float64 // initial over-consolidation ratio (in terms of peq)
+	pmin   float64 // minimum pressure to compute elastic moduli
+	DεcMax float64 // maximum creep multiplier increment (λdot⋅dt) in each sub-step
+	rho    float64 // density
+
+	// auxiliary
+	β    float64     // (λ*-κ*)/μ*
+	s    []float64   // dev(σ)
+	N    []float64   // ∂peq/∂σ
+	Δσ   []float64   // stress increment of sub-step
+	De   [][]float64 // elastic modulus
+	nsub int         // number of sub-steps of last update
+	σ0   []float64   // stress at the beginning of the increment
+	σh   []float64   // perturbed stress for computing the tangent
+	Δεh  []float64   // perturbed strain increment for computing the tangent
+}
+
+// constants
+const (
+	SSC_NSUBMAX = 1000 // maximum number of sub-steps
+	SSC_DPEQMIN = 1e-2 // minimum value of ∂peq/∂p to avoid division by zero near the cs line
+	SSC_HDERIV  = 1e-7 // step of central differences to compute the algorithmic tangent
+)
+
+// add model to factory
+func init() {
+	allocators["ssc"] = func() Model { return new(SoftSoilCreep) }
+	intvars["ssc"] = []string{"pp", "tlast"}
+}
+
+// Clean clean resources
+func (o *SoftSoilCreep) Clean() {
+}
+
+// GetRho returns density
+func (o *SoftSoilCreep) GetRho() float64 {
+	return o.rho
+}
+
+// Init initialises model
+func (o *SoftSoilCreep) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// basic data
+	o.Nsig = 2 * ndim
+
+	// parameters for CS model
+	pp := []string{"φ", "Mfix"}
+	vv := []float64{25, 1}
+	for _, p := range prms {
+		switch p.N {
+		case "phi":
+			vv[0] = p.V
+		case "Mfix":
+			vv[1] = p.V
+		}
+	}
+	o.CS.Init(pp, vv)
+
+	// parameters
+	o.τ, o.νur, o.ocr, o.pmin, o.DεcMax = 1, 0.15, 1, 1e-3, 1e-4
+	for _, p := range prms {
+		switch p.N {
+		case "lamS":
+			o.λs = p.V
+		case "kapS":
+			o.κs = p.V
+		case "muS":
+			o.μs = p.V
+		case "tau":
+			o.τ = p.V
+		case "nur":
+			o.νur = p.V
+		case "ocr":
+			o.ocr = p.V
+		case "pmin":
+			o.pmin = p.V
+		case "dcmax":
+			o.DεcMax = p.V
+		case "rho":
+			o.rho = p.V
+		}
+	}
+
+	// check
+	if o.κs <= 0 || o.μs <= 0 || o.λs <= o.κs {
+		return chk.Err("SSC: parameters must satisfy 0 < kapS < lamS and muS > 0. kapS=%g, lamS=%g, muS=%g are invalid\n", o.κs, o.λs, o.μs)
+	}
+	if o.τ <= 0 || o.pmin <= 0 || o.DεcMax <= 0 {
+		return chk.Err("SSC: tau, pmin and dcmax must be positive. tau=%g, pmin=%g, dcmax=%g are invalid\n", o.τ, o.pmin, o.DεcMax)
+	}
+
+	// auxiliary
+	o.β = (o.λs - o.κs) / o.μs
+	o.s = make([]float64, o.Nsig)
+	o.N = make([]float64, o.Nsig)
+	o.Δσ = make([]float64, o.Nsig)
+	o.De = la.MatAlloc(o.Nsig, o.Nsig)
+	o.σ0 = make([]float64, o.Nsig)
+	o.σh = make([]float64, o.Nsig)
+	o.Δεh = make([]float64, o.Nsig)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o *SoftSoilCreep) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "phi", V: 25},
+		&fun.Prm{N: "Mfix", V: 1},
+		&fun.Prm{N: "lamS", V: 0.1},
+		&fun.Prm{N: "kapS", V: 0.02},
+		&fun.Prm{N: "muS", V: 0.004},
+		&fun.Prm{N: "tau", V: 1},
+		&fun.Prm{N: "nur", V: 0.15},
+		&fun.Prm{N: "ocr", V: 1},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o *SoftSoilCreep) InitIntVars(σ []float64) (s *State, err error) {
+	nalp := 2 // alp[0] = pp, alp[1] = time
+	s = NewState(o.Nsig, nalp, false, false)
+	copy(s.Sig, σ)
+	peq, _ := o.peq_and_derivs(σ, false)
+	s.Alp[0] = o.ocr * peq
+	return
+}
+
+// Update updates stresses for given strains
+func (o *SoftSoilCreep) Update(s *State, ε, Δε []float64, eid, ipid int, time float64) (err error) {
+
+	// time increment
+	Δt := time - s.Alp[1]
+	if Δt < 0 {
+		Δt = 0
+	}
+	s.Alp[1] = time
+	if len(s.Dad) != o.Nsig {
+		s.Dad = la.MatAlloc(o.Nsig, o.Nsig)
+	}
+
+	// elastic response
+	if Δt == 0 {
+		o.calc_De(s.Sig)
+		la.MatVecMulAdd(s.Sig, 1, o.De, Δε) // σ += De ⋅ Δε
+		la.MatCopy(s.Dad, 1, o.De)
+		s.Loading = false
+		return
+	}
+
+	// sub-steps with size controlled by the creep strain increment
+	copy(o.σ0, s.Sig)
+	pp0 := s.Alp[0]
+	o.nsub, err = o.substeps(s.Sig, &s.Alp[0], Δε, Δt)
+	if err != nil {
+		return
+	}
+	s.Loading = true
+
+	// algorithmic tangent
+	copy(o.Δεh, Δε)
+	for j := 0; j < o.Nsig; j++ {
+		for i := 0; i < o.Nsig; i++ {
+			s.Dad[i][j] = 0
+		}
+		for _, sgn := range []float64{1, -1} {
+			o.Δεh[j] = Δε[j] + sgn*SSC_HDERIV
+			copy(o.σh, o.σ0)
+			pp := pp0
+			_, err = o.substeps(o.σh, &pp, o.Δεh, Δt)
+			if err != nil {
+				return
+			}
+			for i := 0; i < o.Nsig; i++ {
+				s.Dad[i][j] += sgn * o.σh[i] / (2.0 * SSC_HDERIV)
+			}
+		}
+		o.Δεh[j] = Δε[j]
+	}
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+//  Note: the tangent computed by Update is returned; i.e. the elastic modulus at the beginning of
+//        the increment (elastic response) or the algorithmic tangent of the sub-stepping. The
+//        elastic modulus is returned if Update has not been called yet
+func (o *SoftSoilCreep) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+	if len(s.Dad) == 0 {
+		o.calc_De(s.Sig)
+		la.MatCopy(D, 1, o.De)
+		return
+	}
+	la.MatCopy(D, 1, s.Dad)
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *SoftSoilCreep) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// substeps integrates the creep rate with sub-steps over the time increment Δt
+//  σ  -- stress at the beginning of the increment; updated
+//  pp -- preconsolidation pressure at the beginning of the increment; updated
+func (o *SoftSoilCreep) substeps(σ []float64, pp *float64, Δε []float64, Δt float64) (nsub int, err error) {
+	var λdot, rate, dt, Δεvc float64
+	tsub := 0.0 // pseudo-time of sub-step (from 0 to Δt)
+	for ; tsub < Δt; nsub++ {
+
+		// check
+		if nsub == SSC_NSUBMAX {
+			return nsub, chk.Err("SSC: number of sub-steps reached the maximum (%d). Δt=%g is too large\n", SSC_NSUBMAX, Δt)
+		}
+
+		// creep rate
+		peq, α := o.peq_and_derivs(σ, true)
+		rate = o.μs / o.τ * math.Pow(peq/(*pp), o.β) // volumetric creep strain rate
+		λdot = rate / math.Max(α, SSC_DPEQMIN)
+
+		// sub-step size
+		dt = Δt - tsub
+		if λdot*dt > o.DεcMax {
+			dt = o.DεcMax / λdot
+		}
+		tsub += dt
+
+		// stress increment: Δσ = De : (Δε - Δεc)
+		o.calc_De(σ)
+		for i := 0; i < o.Nsig; i++ {
+			o.Δσ[i] = 0
+			for j := 0; j < o.Nsig; j++ {
+				o.Δσ[i] += o.De[i][j] * (dt/Δt*Δε[j] - dt*λdot*o.N[j])
+			}
+		}
+		for i := 0; i < o.Nsig; i++ {
+			σ[i] += o.Δσ[i]
+		}
+
+		// hardening
+		Δεvc = 0
+		for i := 0; i < 3; i++ {
+			Δεvc += dt * λdot * o.N[i]
+		}
+		*pp *= math.Exp(-Δεvc / (o.λs - o.κs))
+	}
+	return
+}
+
+// peq_and_derivs computes peq and ∂peq/∂p. Optionally computes N = ∂peq/∂σ
+func (o *SoftSoilCreep) peq_and_derivs(σ []float64, derivs bool) (peq, dpeqdp float64) {
+	p, q, w := tsr.M_pqws(o.s, σ)
+	M := o.CS.M(w)
+	p = math.Max(p, o.pmin)
+	peq = p + q*q/(M*M*p)
+	dpeqdp = 1.0 - q*q/(M*M*p*p)
+	if derivs {
+		dpeqdq := 2.0 * q / (M * M * p)
+		for i := 0; i < o.Nsig; i++ {
+			o.N[i] = -dpeqdp * tsr.Im[i] / 3.0 // ∂p/∂σ = -I/3
+			if q > 0 {
+				o.N[i] += dpeqdq * 1.5 * o.s[i] / q // ∂q/∂σ = 3 s / (2 q)
+			}
+		}
+	}
+	return
+}
+
+// calc_De computes the (stress dependent) elastic modulus
+func (o *SoftSoilCreep) calc_De(σ []float64) {
+	p := math.Max(tsr.M_p(σ), o.pmin)
+	K := p / o.κs
+	G := Calc_G_from_Knu(K, o.νur)
+	a := K - 2.0*G/3.0
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			o.De[i][j] = a * tsr.Im[i] * tsr.Im[j]
+		}
+		o.De[i][i] += 2.0 * G
+	}
+}
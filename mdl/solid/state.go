@@ -22,6 +22,9 @@ type State struct {
 
 	// for large deformations
 	F [][]float64 // deformation gradient [3][3]
+
+	// for automatic differentiation (if len(Dad) > 0)
+	Dad [][]float64 // consistent tangent computed during the update (AdUpdate or RetMap) [nsig][nsig]
 }
 
 // NewState allocates state structure for small or large deformation analyses
@@ -52,7 +55,7 @@ func NewState(nsig, nalp int, large, nle bool) *State {
 }
 
 // Set copies states
-//  Note: 1) this and other states must have been pre-allocated with the same sizes
+//  Note: 1) this and other states must have been pre-allocated with the same sizes; except Dad
 //        2) this method does not check for errors
 func (o *State) Set(other *State) {
 
@@ -77,6 +80,14 @@ func (o *State) Set(other *State) {
 	if len(o.F) > 0 {
 		la.MatCopy(o.F, 1, other.F)
 	}
+
+	// automatic differentiation
+	if len(other.Dad) > 0 {
+		if len(o.Dad) != len(other.Dad) {
+			o.Dad = la.MatAlloc(len(other.Dad), len(other.Dad))
+		}
+		la.MatCopy(o.Dad, 1, other.Dad)
+	}
 }
 
 // GetCopy returns a copy of this state
@@ -86,3 +97,51 @@ func (o *State) GetCopy() *State {
 	other.Set(o)
 	return other
 }
+
+// PackStates returns copies of states whose data are stored in contiguous arrays
+// (structure-of-arrays); e.g. σ of all states are stored in one array. The returned states are
+// views of these arrays and the State structures are also stored contiguously; thus loops over
+// integration points access memory sequentially and the API is not changed
+//  Note: (1) all states must have the same sizes
+//        (2) the views must not be resized; e.g. with append
+func PackStates(states []*State) (packed []*State) {
+	n := len(states)
+	if n == 0 {
+		return
+	}
+	nsig, nalp, ntr, nel, nf := len(states[0].Sig), len(states[0].Alp), len(states[0].EpsTr), len(states[0].EpsE), len(states[0].F)
+	structs := make([]State, n)
+	sig := make([]float64, n*nsig)
+	alp := make([]float64, n*nalp)
+	epstr := make([]float64, n*ntr)
+	epse := make([]float64, n*nel)
+	f := make([]float64, n*nf*nf)
+	view := func(a []float64, i, m int) []float64 {
+		if m == 0 {
+			return nil
+		}
+		return a[i*m : (i+1)*m : (i+1)*m]
+	}
+	packed = make([]*State, n)
+	for i, s := range states {
+		structs[i] = *s
+		p := &structs[i]
+		p.Sig = view(sig, i, nsig)
+		p.Alp = view(alp, i, nalp)
+		p.EpsTr = view(epstr, i, ntr)
+		p.EpsE = view(epse, i, nel)
+		copy(p.Sig, s.Sig)
+		copy(p.Alp, s.Alp)
+		copy(p.EpsTr, s.EpsTr)
+		copy(p.EpsE, s.EpsE)
+		if nf > 0 {
+			p.F = make([][]float64, nf)
+			for j := 0; j < nf; j++ {
+				p.F[j] = view(f, i*nf+j, nf)
+				copy(p.F[j], s.F[j])
+			}
+		}
+		packed[i] = p
+	}
+	return
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// Swelling computes volumetric eigen-strains due to swelling/shrinkage of expansive clays
+// driven by changes of liquid saturation (moisture) and/or capillary pressure (suction)
+//  The following laws are added together:
+//   linear (moisture-driven):     εv = βs ⋅ (sl - sl0)
+//   nonlinear (suction-driven):   εv = κs ⋅ ln((pc0 + pa) / (pc + pa))    with pc ≥ 0
+//  Note: εv > 0 means expansion (heave)
+type Swelling struct {
+	Bs  float64 // βs: coefficient of linear (moisture-driven) law
+	Sl0 float64 // sl0: reference liquid saturation
+	Ks  float64 // κs: swelling index of nonlinear (suction-driven) law
+	Pc0 float64 // pc0: reference capillary pressure (suction)
+	Pa  float64 // pa: reference (atmospheric) pressure to avoid ln(0)
+}
+
+// NewSwelling returns a new Swelling structure if "swBs" or "swKs" is found in prms
+//  Note: returns nil if neither "swBs" nor "swKs" are given; i.e. no swelling strains
+func NewSwelling(prms fun.Prms) (o *Swelling, err error) {
+	var has_βs, has_κs bool
+	s := Swelling{Sl0: 1, Pa: 100}
+	for _, p := range prms {
+		switch p.N {
+		case "swBs":
+			s.Bs, has_βs = p.V, true
+		case "swSl0":
+			s.Sl0 = p.V
+		case "swKs":
+			s.Ks, has_κs = p.V, true
+		case "swPc0":
+			s.Pc0 = p.V
+		case "swPa":
+			s.Pa = p.V
+		}
+	}
+	if !has_βs && !has_κs {
+		return
+	}
+	if s.Pa <= 0 {
+		return nil, chk.Err("swelling: pa parameter must be positive. pa = %g is invalid\n", s.Pa)
+	}
+	return &s, nil
+}
+
+// VolStrain returns the volumetric swelling strain for given liquid saturation and capillary pressure
+func (o *Swelling) VolStrain(sl, pc float64) (εv float64) {
+	εv = o.Bs * (sl - o.Sl0)
+	if o.Ks > 0 {
+		εv += o.Ks * math.Log((math.Max(o.Pc0, 0)+o.Pa)/(math.Max(pc, 0)+o.Pa))
+	}
+	return
+}
+
+// DVolStrainDpc returns the derivative of the volumetric swelling strain w.r.t the capillary pressure
+//  Cc -- dsl/dpc; e.g. consistent with the update of the liquid retention model
+func (o *Swelling) DVolStrainDpc(Cc, pc float64) (dεvdpc float64) {
+	dεvdpc = o.Bs * Cc
+	if o.Ks > 0 && pc > 0 {
+		dεvdpc -= o.Ks / (pc + o.Pa)
+	}
+	return
+}
+
+// SubtractVolStrain subtracts volumetric eigen-strains from total (ε) and incremental (Δε) strains
+// (Mandel's basis); i.e. εv/3 is subtracted from each normal component
+func SubtractVolStrain(ε, Δε []float64, εv, Δεv float64) {
+	for i := 0; i < 3; i++ {
+		ε[i] -= εv / 3.0
+		Δε[i] -= Δεv / 3.0
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_autodiff01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("autodiff01. dual numbers")
+
+	// f(x,y) = exp(x) sin(y) / sqrt(x² + y) + ln(x) y^1.5 - |x - y| cos(x y) + asin(x/4)
+	x, y := 1.3, 0.7
+	X, Y := DualVar(x, 0), DualVar(y, 1)
+	F := X.Exp().Mul(Y.Sin()).Div(X.Mul(X).Add(Y).Sqrt()).Add(X.Log().Mul(Y.Pow(1.5))).
+		Sub(X.Sub(Y).Abs().Mul(X.Mul(Y).Cos())).Add(X.Scale(0.25).Asin()).Plus(2)
+	f := func(x, y float64) float64 {
+		return math.Exp(x)*math.Sin(y)/math.Sqrt(x*x+y) + math.Log(x)*math.Pow(y, 1.5) - math.Abs(x-y)*math.Cos(x*y) + math.Asin(x/4) + 2
+	}
+	h := 1e-6
+	chk.Scalar(tst, "f", 1e-15, F.V, f(x, y))
+	chk.Scalar(tst, "df/dx", 1e-9, F.D[0], (f(x+h, y)-f(x-h, y))/(2*h))
+	chk.Scalar(tst, "df/dy", 1e-9, F.D[1], (f(x, y+h)-f(x, y-h))/(2*h))
+	chk.Vector(tst, "df/dz", 1e-15, F.D[2:], []float64{0, 0, 0, 0})
+	chk.Scalar(tst, "neg", 1e-15, F.Neg().D[0], -F.D[0])
+	chk.Scalar(tst, "const", 1e-15, DualConst(3).Mul(X).D[0], 3)
+}
+
+func Test_autodiff02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("autodiff02. invariants of Mandel vectors")
+
+	for _, σ := range [][]float64{
+		{-3, -2, -1, 0.5 * math.Sqrt2},
+		{-3, -2, -1, 0.5 * math.Sqrt2, -0.3 * math.Sqrt2, 0.8 * math.Sqrt2},
+	} {
+		n := len(σ)
+		fcns := map[string]func(a []Dual) Dual{
+			"tr":   AdM_Tr,
+			"p":    AdM_p,
+			"q":    AdM_q,
+			"det":  AdM_Det,
+			"norm": AdM_Norm,
+		}
+		for key, fcn := range fcns {
+			a := fcn(AdVars(σ))
+			for j := 0; j < n; j++ {
+				h := 1e-6
+				σa, σb := make([]float64, n), make([]float64, n)
+				copy(σa, σ)
+				copy(σb, σ)
+				σa[j] += h
+				σb[j] -= h
+				dnum := (fcn(AdConsts(σa)).V - fcn(AdConsts(σb)).V) / (2 * h)
+				chk.AnaNum(tst, io.Sf("d%s/dσ%d", key, j), 1e-9, a.D[j], dnum, chk.Verbose)
+			}
+		}
+
+		// values
+		s := AdConsts(σ)
+		q := math.Sqrt(0.5*((σ[0]-σ[1])*(σ[0]-σ[1])+(σ[1]-σ[2])*(σ[1]-σ[2])+(σ[2]-σ[0])*(σ[2]-σ[0])) + 1.5*σ[3]*σ[3])
+		det := σ[0]*σ[1]*σ[2] - σ[2]*σ[3]*σ[3]/2
+		if n > 4 {
+			q = math.Sqrt(q*q + 1.5*(σ[4]*σ[4]+σ[5]*σ[5]))
+			det += σ[3]*σ[4]*σ[5]/math.Sqrt2 - σ[0]*σ[4]*σ[4]/2 - σ[1]*σ[5]*σ[5]/2
+		}
+		chk.Scalar(tst, "p", 1e-15, AdM_p(s).V, 2)
+		chk.Scalar(tst, "q", 1e-14, AdM_q(s).V, q)
+		chk.Scalar(tst, "det", 1e-14, AdM_Det(s).V, det)
+
+		// null deviator: no NaNs
+		z := AdM_q(AdVars([]float64{-1, -1, -1, 0}))
+		chk.Vector(tst, "dq(iso)", 1e-15, z.D[:], make([]float64, AD_NMAX))
+	}
+}
+
+func Test_autodiff03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("autodiff03. von Mises with consistent tangent by automatic differentiation")
+
+	for _, ndim := range []int{2, 3} {
+		prms := []*fun.Prm{
+			&fun.Prm{N: "K", V: 1.5},
+			&fun.Prm{N: "G", V: 1},
+			&fun.Prm{N: "qy0", V: 2},
+			&fun.Prm{N: "H", V: 0.5},
+		}
+		var hand, auto VonMises
+		err := hand.Init(ndim, false, prms)
+		if err != nil {
+			tst.Errorf("Init failed:\n%v", err)
+			return
+		}
+		err = auto.Init(ndim, false, append(prms, &fun.Prm{N: "ad", V: 1}))
+		if err != nil {
+			tst.Errorf("Init failed:\n%v", err)
+			return
+		}
+		nsig := 2 * ndim
+		σ0 := []float64{-1, -1, -1, 0, 0, 0}[:nsig]
+		sh, _ := hand.InitIntVars(σ0)
+		sa, _ := auto.InitIntVars(σ0)
+		ε := make([]float64, nsig)
+		Δε := []float64{-0.1, 0.3, -0.05, 0.2, -0.1, 0.15}[:nsig]
+		Dh, Da := la.MatAlloc(nsig, nsig), la.MatAlloc(nsig, nsig)
+		for inc := 0; inc < 5; inc++ {
+			la.VecAdd2(ε, 1, ε, 1, Δε)
+			err = hand.Update(sh, ε, Δε, 0, 0, 0)
+			if err != nil {
+				tst.Errorf("Update failed:\n%v", err)
+				return
+			}
+			err = auto.Update(sa, ε, Δε, 0, 0, 0)
+			if err != nil {
+				tst.Errorf("Update failed:\n%v", err)
+				return
+			}
+			io.Pforan("ndim = %d  inc = %d  loading = %v  σ = %v\n", ndim, inc, sa.Loading, sa.Sig)
+			chk.Vector(tst, "σ", 1e-14, sa.Sig, sh.Sig)
+			chk.Vector(tst, "α", 1e-14, sa.Alp, sh.Alp)
+			chk.Scalar(tst, "Δγ", 1e-14, sa.Dgam, sh.Dgam)
+			if sa.Loading != sh.Loading {
+				tst.Errorf("loading flags are different")
+				return
+			}
+			hand.CalcD(Dh, sh, false)
+			err = auto.CalcD(Da, sa, false)
+			if err != nil {
+				tst.Errorf("CalcD failed:\n%v", err)
+				return
+			}
+			chk.Matrix(tst, "D", 1e-13, Da, Dh)
+		}
+
+		// copy of state
+		chk.Matrix(tst, "Dad(copy)", 1e-15, sa.GetCopy().Dad, sa.Dad)
+	}
+
+	// driver with check of D
+	var drv Driver
+	err := drv.Init("test", "vm", 3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "qy0", V: 2},
+		&fun.Prm{N: "H", V: 0.5},
+		&fun.Prm{N: "ad", V: 1},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	drv.CheckD = true
+	var pth Path
+	err = pth.SetPQstrain(3, 1, 1, 1.5, 1, 0, []float64{3, 3, 2}, []float64{2.1, 4, 2}, 0)
+	if err != nil {
+		tst.Errorf("SetPQstrain failed:\n%v", err)
+		return
+	}
+	err = drv.Run(&pth)
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/tsr"
+)
+
+func Test_bsurf01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("bsurf01")
+
+	// model
+	ndim, pstress := 2, false
+	var m BoundingSurf
+	err := m.Init(ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "M", V: 1.2},
+		&fun.Prm{N: "lamS", V: 0.1},
+		&fun.Prm{N: "kapS", V: 0.02},
+		&fun.Prm{N: "nur", V: 0.2},
+		&fun.Prm{N: "h", V: 10},
+		&fun.Prm{N: "ocr", V: 1},
+		&fun.Prm{N: "nsub", V: 50},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// isotropic and normally consolidated initial state
+	p0 := 100.0
+	s, err := m.InitIntVars([]float64{-p0, -p0, -p0, 0})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "pc0", 1e-15, s.Alp[0], p0)
+
+	// run isotropic compression step
+	ε := make([]float64, 4)
+	step := func(dεv float64) (Δγ float64) {
+		Δε := []float64{dεv / 3, dεv / 3, dεv / 3, 0}
+		for i := 0; i < 3; i++ {
+			ε[i] += Δε[i]
+		}
+		err = m.Update(s, ε, Δε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		io.Pforan("p = %8.3f  pc = %8.3f  Δγ = %v  loading = %v\n", tsr.M_p(s.Sig), s.Alp[0], s.Dgam, s.Loading)
+		return s.Dgam
+	}
+
+	// virgin loading => on bounding surface
+	Δγvirgin := step(-2e-3)
+	if !s.Loading {
+		tst.Errorf("virgin loading must be elastoplastic\n")
+		return
+	}
+	chk.Scalar(tst, "p = pc", 1e-2, tsr.M_p(s.Sig), s.Alp[0])
+
+	// unloading => projection center at reversal point and (much) stiffer response than virgin loading
+	σrev := []float64{-s.Sig[0], -s.Sig[1], -s.Sig[2], -s.Sig[3]}
+	Δγunload := step(1e-3)
+	chk.Vector(tst, "αc", 1e-13, s.Alp[1:], σrev)
+	if Δγunload >= Δγvirgin/10 {
+		tst.Errorf("unloading must be much stiffer than virgin loading: Δγ = %g >= %g\n", Δγunload, Δγvirgin/10)
+		return
+	}
+
+	// reloading => elastoplastic with (much) stiffer response than virgin loading
+	Δγreload := step(-2e-3)
+	if !s.Loading {
+		tst.Errorf("reloading must be elastoplastic\n")
+		return
+	}
+	if Δγreload >= Δγvirgin {
+		tst.Errorf("reloading must be stiffer than virgin loading: Δγ = %g >= %g\n", Δγreload, Δγvirgin)
+		return
+	}
+}
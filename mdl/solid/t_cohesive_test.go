@@ -0,0 +1,88 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_cohesive01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("cohesive01. cohesive law with exponential softening")
+
+	var m Cohesive
+	ft, gf := 2.0, 0.01
+	err := m.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e6},
+		&fun.Prm{N: "ks", V: 1e5},
+		&fun.Prm{N: "ft", V: ft},
+		&fun.Prm{N: "gf", V: gf},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, err := m.InitIntVars([]float64{0, 0})
+	if err != nil {
+		tst.Errorf("InitIntVars failed:\n%v", err)
+		return
+	}
+	f := func(κ float64) float64 { return ft * math.Exp(-ft*(κ-m.W0)/gf) }
+
+	// opening: tractions follow the envelope and the dissipated energy approaches gf + ft・w0/2
+	n := 20000
+	Δw := 0.02 / float64(n)
+	var energy float64
+	for i := 0; i < n; i++ {
+		t0 := s.Sig[0]
+		m.Update(s, []float64{Δw, 0}, 0)
+		energy += (t0 + s.Sig[0]) * Δw / 2
+	}
+	io.Pforan("energy = %v\n", energy)
+	chk.Scalar(tst, "tn", 1e-12, s.Sig[0], f(0.02))
+	chk.Scalar(tst, "energy", 1e-6, energy, gf*(1-math.Exp(-ft*(0.02-m.W0)/gf))+ft*m.W0/2)
+	if !m.IsOpen(s) {
+		tst.Errorf("crack must be open")
+		return
+	}
+
+	// unloading with shear: secant stiffness
+	m.Update(s, []float64{-0.012, 0.01}, 0)
+	ks := f(0.02) / 0.02
+	chk.Vector(tst, "t (unloading)", 1e-12, s.Sig, []float64{ks * 0.008, ks / 1e6 * 1e5 * 0.01})
+	chk.Scalar(tst, "kappa", 1e-14, s.Alp[0], 0.02)
+
+	// closing: penalty stiffness
+	m.Update(s, []float64{-0.009, 0}, 0)
+	chk.Scalar(tst, "tn (closed)", 1e-9, s.Sig[0], -1e6*0.001)
+
+	// consistent tangent: numerical derivatives
+	D := la.MatAlloc(2, 2)
+	for _, w := range [][]float64{{0.5 * m.W0, 0.001}, {0.02, 0.003}, {0.06, -0.002}} {
+		s0, _ := m.InitIntVars([]float64{0, 0})
+		m.Update(s0, []float64{0.01, 0}, 0)
+		Δw := []float64{w[0] - s0.EpsE[0], w[1]}
+		s1 := s0.GetCopy()
+		m.Update(s1, Δw, 0)
+		m.CalcD(D, s1)
+		for j := 0; j < 2; j++ {
+			h := 1e-8
+			s2 := s0.GetCopy()
+			Δw[j] += h
+			m.Update(s2, Δw, 0)
+			Δw[j] -= h
+			for i := 0; i < 2; i++ {
+				chk.AnaNum(tst, io.Sf("D%d%d", i, j), 1e-4*math.Max(1, math.Abs(D[i][j])), D[i][j], (s2.Sig[i]-s1.Sig[i])/h, chk.Verbose)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/num"
+)
+
+func Test_thermal01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("thermal01")
+
+	th := NewThermalStrain([]*fun.Prm{&fun.Prm{N: "E", V: 1}})
+	if th != nil {
+		tst.Errorf("thermal strain structure should be nil if alpT is not given\n")
+		return
+	}
+
+	th = NewThermalStrain([]*fun.Prm{
+		&fun.Prm{N: "alpT", V: 1e-3},
+		&fun.Prm{N: "T0", V: 20},
+	})
+	io.Pforan("th = %+v\n", th)
+	chk.Scalar(tst, "εth", 1e-15, th.Strain(30), 0.01)
+
+	ε := []float64{0.1, 0.2, 0.3, 0.4}
+	Δε := []float64{0.01, 0.02, 0.03, 0.04}
+	th.Subtract(ε, Δε, 30, 25)
+	chk.Vector(tst, "ε", 1e-15, ε, []float64{0.09, 0.19, 0.29, 0.4})
+	chk.Vector(tst, "Δε", 1e-15, Δε, []float64{0.005, 0.015, 0.025, 0.04})
+
+	// linear elastic model with thermal strains => free expansion yields no stress
+	var m LinElast
+	err := m.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "E", V: 1000},
+		&fun.Prm{N: "nu", V: 0.25},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	s, _ := m.InitIntVars(make([]float64, 4))
+	ε = []float64{0.01, 0.01, 0.01, 0}
+	Δε = []float64{0.01, 0.01, 0.01, 0}
+	th.Subtract(ε, Δε, 30, 20)
+	err = m.Update(s, ε, Δε, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	chk.Vector(tst, "σ", 1e-13, s.Sig, []float64{0, 0, 0, 0})
+}
+
+func Test_swelling01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("swelling01")
+
+	sw, err := NewSwelling([]*fun.Prm{&fun.Prm{N: "E", V: 1}})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	if sw != nil {
+		tst.Errorf("swelling structure should be nil if swBs and swKs are not given\n")
+		return
+	}
+
+	sw, err = NewSwelling([]*fun.Prm{
+		&fun.Prm{N: "swBs", V: 0.1},
+		&fun.Prm{N: "swSl0", V: 0.5},
+		&fun.Prm{N: "swKs", V: 0.02},
+		&fun.Prm{N: "swPc0", V: 100},
+		&fun.Prm{N: "swPa", V: 100},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	io.Pforan("sw = %+v\n", sw)
+	chk.Scalar(tst, "εv(ref)", 1e-17, sw.VolStrain(0.5, 100), 0)
+	chk.Scalar(tst, "εv(wet)", 1e-15, sw.VolStrain(1.0, 0), 0.05+0.02*math.Log(2))
+	chk.Scalar(tst, "εv(dry)", 1e-15, sw.VolStrain(0.3, 300), -0.02-0.02*math.Log(2))
+
+	// derivative w.r.t pc with sl = 0.5 - 0.001 (pc - 100)
+	Cc := -0.001
+	for _, pc := range []float64{-50, 50, 200} {
+		dnum := num.DerivCen(func(x float64, args ...interface{}) float64 {
+			return sw.VolStrain(0.5+Cc*(x-100), x)
+		}, pc)
+		chk.AnaNum(tst, io.Sf("dεv/dpc @ pc=%g", pc), 1e-9, sw.DVolStrainDpc(Cc, pc), dnum, chk.Verbose)
+	}
+
+	ε := []float64{0.1, 0.1, 0.1, 0.2}
+	Δε := []float64{0.01, 0.01, 0.01, 0.02}
+	SubtractVolStrain(ε, Δε, 0.3, 0.03)
+	chk.Vector(tst, "ε", 1e-15, ε, []float64{0, 0, 0, 0.2})
+	chk.Vector(tst, "Δε", 1e-15, Δε, []float64{0, 0, 0, 0.02})
+}
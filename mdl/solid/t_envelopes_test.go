@@ -0,0 +1,158 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/tsr"
+)
+
+func Test_envelopes01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("envelopes01. cross sections of yield surfaces")
+
+	// von Mises: circle in π-plane and q = qy0 + H α in meridians
+	var vm VonMises
+	err := vm.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "qy0", V: 2},
+		&fun.Prm{N: "H", V: 0.5},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ := vm.InitIntVars([]float64{-1, -1, -1, 0, 0, 0})
+	s.Alp[0] = 0.4
+	qy := 2.0 + 0.5*0.4
+	σa, σb, err := YsPiPlane(&vm, s, 1, 12)
+	if err != nil {
+		tst.Errorf("YsPiPlane failed:\n%v", err)
+		return
+	}
+	if len(σa) != 12 {
+		tst.Errorf("number of points in π-plane is incorrect: %d != 12", len(σa))
+		return
+	}
+	for i := 0; i < len(σa); i++ {
+		chk.Scalar(tst, io.Sf("r%d", i), 1e-12, math.Sqrt(σa[i]*σa[i]+σb[i]*σb[i]), tsr.SQ2by3*qy)
+	}
+	for _, w := range []float64{1, 0, -1} {
+		P, Q, err := YsMeridian(&vm, s, w, -2, 8, 6)
+		if err != nil {
+			tst.Errorf("YsMeridian failed:\n%v", err)
+			return
+		}
+		chk.Vector(tst, io.Sf("p(w=%g)", w), 1e-15, P, []float64{-2, 0, 2, 4, 6, 8})
+		chk.Vector(tst, io.Sf("q(w=%g)", w), 1e-12, Q, []float64{qy, qy, qy, qy, qy, qy})
+	}
+
+	// Drucker-Prager: q = qy0 + M p; no points beyond the apex
+	var dp DruckerPrager
+	err = dp.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "M", V: 1.2},
+		&fun.Prm{N: "Mb", V: 1.2},
+		&fun.Prm{N: "qy0", V: 0.6},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ = dp.InitIntVars([]float64{-1, -1, -1, 0, 0, 0})
+	P, Q, err := YsMeridian(&dp, s, 1, -1, 3, 9)
+	if err != nil {
+		tst.Errorf("YsMeridian failed:\n%v", err)
+		return
+	}
+	io.Pforan("P = %v\nQ = %v\n", P, Q)
+	chk.Vector(tst, "p", 1e-15, P, []float64{0, 0.5, 1, 1.5, 2, 2.5, 3})
+	for i, p := range P {
+		chk.Scalar(tst, io.Sf("q(p=%g)", p), 1e-12, Q[i], 0.6+1.2*p)
+	}
+}
+
+func Test_envelopes02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("envelopes02. response envelopes")
+
+	K, G, qy0 := 1.5, 1.0, 2.0
+	var vm VonMises
+	err := vm.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: K},
+		&fun.Prm{N: "G", V: G},
+		&fun.Prm{N: "qy0", V: qy0},
+		&fun.Prm{N: "H", V: 0},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+
+	// elastic: ellipse given by De
+	ε := make([]float64, 6)
+	Δε := 1e-3
+	s, _ := vm.InitIntVars([]float64{-1, -1, -1, 0, 0, 0})
+	θ, Δσr, Δσa, err := ResponseEnvelope(&vm, s, ε, Δε, 8)
+	if err != nil {
+		tst.Errorf("ResponseEnvelope failed:\n%v", err)
+		return
+	}
+	for i := 0; i < len(θ); i++ {
+		εa, εr := Δε*math.Cos(θ[i]), Δε*math.Sin(θ[i])/tsr.SQ2
+		ev := εa + 2.0*εr
+		chk.Scalar(tst, "Δσa", 1e-14, Δσa[i], K*ev+2.0*G*(εa-ev/3.0))
+		chk.Scalar(tst, "Δσr", 1e-14, Δσr[i], 2.0*(K*ev+2.0*G*(εr-ev/3.0))/tsr.SQ2)
+	}
+	chk.Vector(tst, "σ (unchanged)", 1e-15, s.Sig, []float64{-1, -1, -1, 0, 0, 0})
+
+	// perfectly plastic at triaxial compression: q does not increase in loading directions
+	s, _ = vm.InitIntVars([]float64{-1, -1 + qy0, -1 + qy0, 0, 0, 0})
+	θ, Δσr, Δσa, err = ResponseEnvelope(&vm, s, ε, Δε, 16)
+	if err != nil {
+		tst.Errorf("ResponseEnvelope failed:\n%v", err)
+		return
+	}
+	nload := 0
+	for i := 0; i < len(θ); i++ {
+		Δq := Δσr[i]/tsr.SQ2 - Δσa[i]
+		if Δq > 1e-15 {
+			tst.Errorf("q must not increase: Δq = %g", Δq)
+			return
+		}
+		if math.Abs(Δq) < 1e-13 {
+			nload++
+		}
+	}
+	io.Pforan("number of plastic directions = %d\n", nload)
+	if nload < 1 || nload == len(θ) {
+		tst.Errorf("plastic and elastic directions must exist: %d", nload)
+	}
+}
+
+func Test_envelopes03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("envelopes03. cycles")
+
+	n := 121
+	x := make([]float64, n)
+	for i := 0; i < n; i++ {
+		x[i] = math.Sin(2.0 * math.Pi * 3.0 * float64(i) / float64(n-1))
+	}
+	idx := CycleStarts(x, 1e-10)
+	io.Pforan("idx = %v\n", idx)
+	chk.Ints(tst, "idx", idx, []int{0, 30, 70, 110})
+	chk.Ints(tst, "mono", CycleStarts([]float64{0, 1, 2, 2, 3}, 1e-10), []int{0})
+}
@@ -0,0 +1,207 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_fault01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("fault01. slip-weakening friction and effective normal traction")
+
+	var m Fault
+	err := m.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e4},
+		&fun.Prm{N: "ks", V: 1e3},
+		&fun.Prm{N: "mus", V: 0.6},
+		&fun.Prm{N: "mud", V: 0.4},
+		&fun.Prm{N: "dc", V: 0.01},
+		&fun.Prm{N: "kl", V: 1},
+		&fun.Prm{N: "kls", V: 5},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, err := m.InitIntVars([]float64{-10, 0})
+	if err != nil {
+		tst.Errorf("InitIntVars failed:\n%v", err)
+		return
+	}
+
+	// shearing: peak strength μs・σn, weakening and residual strength μd・σn
+	for i := 0; i < 300; i++ {
+		err = m.Update(s, []float64{0, 1e-4}, 0)
+		if err != nil {
+			tst.Errorf("Update failed:\n%v", err)
+			return
+		}
+		δ, _, μ, _ := m.SlipVars(s)
+		if s.Sig[1] > 6+1e-12 {
+			tst.Errorf("shear traction %g exceeds the peak strength", s.Sig[1])
+			return
+		}
+		if s.Loading {
+			chk.Scalar(tst, "μ", 1e-15, μ, 0.6-0.2*math.Min(δ/0.01, 1))
+			chk.Scalar(tst, "τ", 1e-12, s.Sig[1], μ*10)
+		}
+	}
+	δ, _, _, _ := m.SlipVars(s)
+	io.Pforan("δ = %v  t = %v\n", δ, s.Sig)
+	chk.Scalar(tst, "δ", 1e-12, δ, 0.03-0.004)
+	chk.Scalar(tst, "τ (residual)", 1e-12, s.Sig[1], 4)
+	chk.Scalar(tst, "kl (slipped)", 1e-15, m.Leakance(s), 5)
+
+	// reduction of effective normal compression (e.g. due to pore-pressure increase)
+	m.Update(s, []float64{5e-4, 0}, 0)
+	chk.Vector(tst, "t (σn = 5)", 1e-12, s.Sig, []float64{-5, 2})
+	if !s.Loading {
+		tst.Errorf("fault must be slipping")
+		return
+	}
+
+	// opening
+	m.Update(s, []float64{1e-3, 0}, 0)
+	if !m.IsOpen(s) {
+		tst.Errorf("fault must be open")
+		return
+	}
+	chk.Vector(tst, "t (open)", 1e-15, s.Sig, []float64{1e-6 * 1e4 * 5e-4, 0})
+}
+
+func Test_fault02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("fault02. consistent tangent")
+
+	sw := []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e4},
+		&fun.Prm{N: "ks", V: 1e3},
+		&fun.Prm{N: "c", V: 0.5},
+		&fun.Prm{N: "mus", V: 0.6},
+		&fun.Prm{N: "mud", V: 0.4},
+		&fun.Prm{N: "dc", V: 0.01},
+	}
+	rs := []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e4},
+		&fun.Prm{N: "ks", V: 1e3},
+		&fun.Prm{N: "dc", V: 1e-4},
+		&fun.Prm{N: "rs", V: 1},
+		&fun.Prm{N: "a", V: 0.01},
+		&fun.Prm{N: "b", V: 0.015},
+		&fun.Prm{N: "mu0", V: 0.6},
+	}
+	for k, prms := range [][]*fun.Prm{sw, rs, sw, rs} {
+		ndim := 2 + k/2
+		var m Fault
+		err := m.Init(ndim, false, prms)
+		if err != nil {
+			tst.Errorf("Init failed:\n%v", err)
+			return
+		}
+		t0, Δw := []float64{-10, 1}, []float64{1e-4, 8e-3}
+		if ndim == 3 {
+			t0, Δw = []float64{-10, 1, 2}, []float64{1e-4, 6e-3, 4e-3}
+		}
+		s, _ := m.InitIntVars(t0)
+		tmp := s.GetCopy()
+		err = m.Update(s, Δw, 1)
+		if err != nil {
+			tst.Errorf("Update failed:\n%v", err)
+			return
+		}
+		if !s.Loading {
+			tst.Errorf("fault must be slipping")
+			return
+		}
+		δ, V, μ, θ := m.SlipVars(s)
+		io.Pforan("ndim = %d  rs = %v: δ = %v  V = %v  μ = %v  θ = %v\n", ndim, m.Rs, δ, V, μ, θ)
+		chk.Scalar(tst, "f", 1e-12, m.shear_norm(s.Sig)-m.C+μ*s.Sig[0], 0)
+
+		// numerical tangent
+		D := la.MatAlloc(ndim, ndim)
+		m.CalcD(D, s)
+		Dnum := la.MatAlloc(ndim, ndim)
+		h := 1e-7
+		for j := 0; j < ndim; j++ {
+			sa, sb := tmp.GetCopy(), tmp.GetCopy()
+			Δwa, Δwb := make([]float64, ndim), make([]float64, ndim)
+			copy(Δwa, Δw)
+			copy(Δwb, Δw)
+			Δwa[j] += h
+			Δwb[j] -= h
+			m.Update(sa, Δwa, 1)
+			m.Update(sb, Δwb, 1)
+			for i := 0; i < ndim; i++ {
+				Dnum[i][j] = (sa.Sig[i] - sb.Sig[i]) / (2.0 * h)
+			}
+		}
+		chk.Matrix(tst, "D", 1e-4, D, Dnum)
+	}
+}
+
+func Test_fault03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("fault03. rate-and-state friction: steady-state and velocity step")
+
+	a, b, dc, v0, μ0 := 0.01, 0.015, 1e-4, 1e-6, 0.6
+	var m Fault
+	err := m.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e4},
+		&fun.Prm{N: "ks", V: 1e6},
+		&fun.Prm{N: "dc", V: dc},
+		&fun.Prm{N: "rs", V: 1},
+		&fun.Prm{N: "a", V: a},
+		&fun.Prm{N: "b", V: b},
+		&fun.Prm{N: "v0", V: v0},
+		&fun.Prm{N: "mu0", V: μ0},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ := m.InitIntVars([]float64{-10, 0})
+	_, _, μ, θ := m.SlipVars(s)
+	chk.Scalar(tst, "μ(V0)", 1e-12, μ, μ0)
+	chk.Scalar(tst, "θ0", 1e-15, θ, dc/v0)
+
+	// sliding with prescribed rates (20 dc each)
+	var t float64
+	Δt := 0.1
+	for _, V := range []float64{1e-5, 1e-4} {
+		μold := μ
+		var μpeak float64
+		nsteps := int(20 * dc / (V * Δt))
+		for i := 0; i < nsteps; i++ {
+			t += Δt
+			err = m.Update(s, []float64{0, V * Δt}, t)
+			if err != nil {
+				tst.Errorf("Update failed:\n%v", err)
+				return
+			}
+			_, _, μ, _ = m.SlipVars(s)
+			μpeak = math.Max(μpeak, μ)
+		}
+		δ, Vs, μ, θ := m.SlipVars(s)
+		io.Pforan("V = %g: δ = %v  Vs = %v  μ = %v  θ = %v  μpeak = %v\n", V, δ, Vs, μ, θ, μpeak)
+		chk.Scalar(tst, "Vs", 1e-8*V, Vs, V)
+		chk.Scalar(tst, "θss", 1e-6*dc/V, θ, dc/V)
+		chk.Scalar(tst, "μss", 1e-8, μ, a*math.Asinh(V/(2*v0)*math.Exp((μ0+b*math.Log(v0*θ/dc))/a)))
+		chk.Scalar(tst, "μss ≈ μ0 + (a - b) ln(V/V0)", 1e-7, μ, μ0+(a-b)*math.Log(V/v0))
+		chk.Scalar(tst, "τ", 1e-12, s.Sig[1], μ*10)
+		if μold > 0 && μpeak < μold+0.9*a*math.Log(10) {
+			tst.Errorf("the direct effect of the velocity step is missing: μpeak = %g", μpeak)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func hysteretic_model(tst *testing.T, a float64) (mdl *Hysteretic) {
+	mdl = new(Hysteretic)
+	err := mdl.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "G", V: 1000},
+		&fun.Prm{N: "nu", V: 0.3},
+		&fun.Prm{N: "gref", V: 1e-3},
+		&fun.Prm{N: "a", V: a},
+		&fun.Prm{N: "Dmin", V: 0.02},
+	})
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return nil
+	}
+	return
+}
+
+func Test_hysteretic01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("hysteretic01")
+
+	mdl := hysteretic_model(tst, 1)
+	if mdl == nil {
+		return
+	}
+
+	// G/Gmax curve
+	chk.Scalar(tst, "G/Gmax(0)", 1e-15, mdl.GGmax(0), 1)
+	chk.Scalar(tst, "G/Gmax(γr)", 1e-15, mdl.GGmax(1e-3), 0.5)
+	chk.Scalar(tst, "G/Gmax(9γr)", 1e-15, mdl.GGmax(9e-3), 0.1)
+
+	// damping curve: Masing damping of the hyperbolic model (a = 1)
+	chk.Scalar(tst, "ξ(0)", 1e-15, mdl.Damping(0), 0.02)
+	for _, x := range []float64{0.01, 0.1, 1, 10, 100} {
+		ξ := 0.02 + (4.0/math.Pi)*(1.0+1.0/x)*(1.0-math.Log(1.0+x)/x) - 2.0/math.Pi
+		io.Pforan("γ/γr = %5g  ξ = %v\n", x, mdl.Damping(x*1e-3))
+		chk.Scalar(tst, io.Sf("ξ(%gγr)", x), 1e-7, mdl.Damping(x*1e-3), ξ)
+	}
+
+	// equivalent properties
+	G, ξ := mdl.Equivalent(-1e-3 / 0.65)
+	chk.Scalar(tst, "Geq", 1e-12, G, 500)
+	chk.Scalar(tst, "ξeq", 1e-15, ξ, mdl.Damping(1e-3))
+}
+
+func Test_hysteretic02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("hysteretic02")
+
+	// cyclic simple shear: γ = 0 → γa → -γa → γa → 2γa
+	for _, a := range []float64{1, 0.8} {
+		mdl := hysteretic_model(tst, a)
+		if mdl == nil {
+			return
+		}
+		s, _ := mdl.InitIntVars([]float64{-100, -100, -100, 0})
+		γa := 2e-3
+		F := func(γ float64) float64 { return mdl.Secant(γ) * γ }
+		ε := make([]float64, 4)
+		Δε := make([]float64, 4)
+		nincs := 2000
+		var ΔW, γ, τ float64
+		for k, γf := range []float64{γa, -γa, γa, 2 * γa} {
+			dγ := (γf - γ) / float64(nincs)
+			for i := 0; i < nincs; i++ {
+				Δε[3] = dγ / math.Sqrt2 // Mandel: √2 εxy = γ / √2
+				ε[3] += Δε[3]
+				err := mdl.Update(s, ε, Δε, 0, 0, 0)
+				if err != nil {
+					tst.Errorf("Update failed: %v\n", err)
+					return
+				}
+				τnew := s.Sig[3] / math.Sqrt2
+				if k == 1 || k == 2 {
+					ΔW += (τ + τnew) * dγ / 2.0
+				}
+				γ, τ = γ+dγ, τnew
+			}
+			io.Pforan("a = %v: γ = %11.8f  τ = %v\n", a, γ, τ)
+			chk.Scalar(tst, "τ", 1e-10, τ, math.Copysign(F(math.Abs(γf)), γf))
+			chk.Scalar(tst, "p", 1e-12, -(s.Sig[0]+s.Sig[1]+s.Sig[2])/3.0, 100)
+		}
+
+		// loop damping
+		W := F(γa) * γa / 2.0
+		ξ := ΔW / (4.0 * math.Pi * W)
+		io.Pforan("a = %v: ξ(loop) = %v  ξ(curve) = %v\n", a, ξ, mdl.Damping(γa)-mdl.Dmin)
+		chk.Scalar(tst, "ξ", 1e-5, ξ, mdl.Damping(γa)-mdl.Dmin)
+	}
+}
+
+func Test_hysteretic03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("hysteretic03")
+
+	// consistent matrix on the backbone and Masing curves
+	mdl := hysteretic_model(tst, 0.8)
+	if mdl == nil {
+		return
+	}
+	D := la.MatAlloc(4, 4)
+	s, _ := mdl.InitIntVars([]float64{-100, -100, -100, 0})
+	εold := make([]float64, 4)
+	for _, Δε := range [][]float64{{1e-3, -2e-3, 5e-4, 1e-3}, {-1e-3, 1e-3, 0, -2e-3}} {
+		sold := s.GetCopy()
+		εnew := make([]float64, 4)
+		la.VecAdd2(εnew, 1, εold, 1, Δε)
+		err := mdl.Update(s, εnew, Δε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("Update failed: %v\n", err)
+			return
+		}
+		io.Pforan("n = %v  γd = %v\n", s.Alp[hy_n], s.Alp[hy_gd])
+		err = mdl.CalcD(D, s, false)
+		if err != nil {
+			tst.Errorf("CalcD failed: %v\n", err)
+			return
+		}
+		Dnum := la.MatAlloc(4, 4)
+		stmp := s.GetCopy()
+		Δεtmp := make([]float64, 4)
+		σa := make([]float64, 4)
+		h := 1e-8
+		for j := 0; j < 4; j++ {
+			tmp := εnew[j]
+			for k, δ := range []float64{h, -h} {
+				εnew[j] = tmp + δ
+				la.VecAdd2(Δεtmp, 1, εnew, -1, εold)
+				stmp.Set(sold)
+				err = mdl.Update(stmp, εnew, Δεtmp, 0, 0, 0)
+				if err != nil {
+					tst.Errorf("Update failed: %v\n", err)
+					return
+				}
+				if k == 0 {
+					copy(σa, stmp.Sig)
+				}
+			}
+			εnew[j] = tmp
+			for i := 0; i < 4; i++ {
+				Dnum[i][j] = (σa[i] - stmp.Sig[i]) / (2.0 * h)
+			}
+		}
+		chk.Matrix(tst, "D", 1e-5, D, Dnum)
+		copy(εold, εnew)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_interfacemc01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interfacemc01. Mohr-Coulomb interface: sliding and gap")
+
+	var m InterfaceMC
+	err := m.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1000},
+		&fun.Prm{N: "ks", V: 100},
+		&fun.Prm{N: "c", V: 1},
+		&fun.Prm{N: "phi", V: 45},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, err := m.InitIntVars([]float64{-10, 0})
+	if err != nil {
+		tst.Errorf("InitIntVars failed:\n%v", err)
+		return
+	}
+	chk.Vector(tst, "w0", 1e-15, s.EpsE, []float64{-0.01, 0})
+
+	// sliding: |ts| = c - tn・tan(φ) = 11
+	m.Update(s, []float64{0, 0.2}, 0)
+	chk.Vector(tst, "t (sliding)", 1e-12, s.Sig, []float64{-10, 11})
+	chk.Scalar(tst, "wps", 1e-12, s.Alp[1], 0.2-0.11)
+	if !s.Loading {
+		tst.Errorf("interface must be sliding")
+		return
+	}
+
+	// gap opening: tension strength is zero
+	m.Update(s, []float64{0.015, 0}, 0)
+	if !m.IsOpen(s) {
+		tst.Errorf("interface must be open")
+		return
+	}
+	chk.Scalar(tst, "tn (open)", 1e-12, s.Sig[0], 1e-6*1000*0.005)
+	chk.Scalar(tst, "ts (open)", 1e-15, s.Sig[1], 0)
+	D := la.MatAlloc(2, 2)
+	m.CalcD(D, s)
+	chk.Matrix(tst, "D (open)", 1e-15, D, [][]float64{{1e-3, 0}, {0, 1e-4}})
+
+	// gap closing: back to the initial contact position
+	m.Update(s, []float64{-0.01, 0}, 0)
+	if m.IsOpen(s) {
+		tst.Errorf("interface must be closed")
+		return
+	}
+	chk.Vector(tst, "t (closed)", 1e-12, s.Sig, []float64{-5, 0})
+}
+
+func Test_interfacemc02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interfacemc02. Mohr-Coulomb interface: consistent tangent")
+
+	var m InterfaceMC
+	err := m.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1000},
+		&fun.Prm{N: "ks", V: 200},
+		&fun.Prm{N: "c", V: 2},
+		&fun.Prm{N: "phi", V: 30},
+		&fun.Prm{N: "psi", V: 10},
+		&fun.Prm{N: "ft", V: 1},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ := m.InitIntVars([]float64{-20, 0, 0})
+	Δw := []float64{0.001, 0.1, 0.06}
+	tmp := s.GetCopy()
+	m.Update(s, Δw, 0)
+	if !s.Loading {
+		tst.Errorf("interface must be sliding")
+		return
+	}
+	f := math.Sqrt(s.Sig[1]*s.Sig[1]+s.Sig[2]*s.Sig[2]) + s.Sig[0]*math.Tan(math.Pi/6.0) - 2
+	chk.Scalar(tst, "f", 1e-12, f, 0)
+
+	// numerical tangent
+	D := la.MatAlloc(3, 3)
+	m.CalcD(D, s)
+	Dnum := la.MatAlloc(3, 3)
+	h := 1e-8
+	for j := 0; j < 3; j++ {
+		stmp := tmp.GetCopy()
+		Δwtmp := []float64{Δw[0], Δw[1], Δw[2]}
+		Δwtmp[j] += h
+		m.Update(stmp, Δwtmp, 0)
+		for i := 0; i < 3; i++ {
+			Dnum[i][j] = (stmp.Sig[i] - s.Sig[i]) / h
+		}
+	}
+	chk.Matrix(tst, "D", 1e-5, D, Dnum)
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_intvars01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("intvars01. names of internal variables")
+
+	// registered names
+	var vm VonMises
+	err := vm.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "qy0", V: 2},
+		&fun.Prm{N: "H", V: 0.5},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ := vm.InitIntVars([]float64{-1, -1, -1, 0, 0, 0})
+	chk.Strings(tst, "vm", IntVarNames("vm", &vm, len(s.Alp)), []string{"alpha"})
+
+	// default names: unknown model or inconsistent number of internal variables
+	chk.Strings(tst, "unknown", IntVarNames("unknown", &vm, 2), []string{"alp0", "alp1"})
+	chk.Strings(tst, "nalp", IntVarNames("vm", &vm, 2), []string{"alp0", "alp1"})
+
+	// names depending on the space dimension
+	var imc InterfaceMC
+	err = imc.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1000},
+		&fun.Prm{N: "ks", V: 200},
+		&fun.Prm{N: "c", V: 2},
+		&fun.Prm{N: "phi", V: 30},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ = imc.InitIntVars([]float64{-1, 0, 0})
+	chk.Strings(tst, "interface-mc", IntVarNames("interface-mc", &imc, len(s.Alp)), []string{"wp0", "wp1", "wp2", "open", "debonded"})
+
+	// all internal variables are named and names are unique
+	var hy Hysteretic
+	err = hy.Init(2, false, hy.GetPrms())
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ = hy.InitIntVars([]float64{-1, -1, -1, 0})
+	for name, names := range map[string][]string{
+		"hysteretic":    IntVarNames("hysteretic", &hy, len(s.Alp)),
+		"smeared-crack": IntVarNames("smeared-crack", nil, sc_nalp),
+	} {
+		io.Pforan("%s: %v\n", name, names)
+		if names[0] == "alp0" {
+			tst.Errorf("%s: internal variables must be named", name)
+			return
+		}
+		unique := make(map[string]bool)
+		for _, n := range names {
+			if unique[n] {
+				tst.Errorf("%s: name %q is repeated", name, n)
+				return
+			}
+			unique[n] = true
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_kgc01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("kgc01")
+
+	prms := []*fun.Prm{
+		&fun.Prm{N: "E", V: 1000},
+		&fun.Prm{N: "nu", V: 0.25, Extra: "!kgc:hss"},
+		&fun.Prm{N: "G0", V: 400},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "n", V: 0.5},
+		&fun.Prm{N: "g07", V: 1e-4},
+		&fun.Prm{N: "gmin", V: 0.1},
+	}
+	var pow KgcPow
+	err := pow.Init(prms)
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+
+	// power law
+	s := NewState(4, 0, false, true)
+	s.Sig = []float64{-400, -400, -400, 0}
+	K, G := pow.Calc(s)
+	io.Pforan("K = %v  G = %v\n", K, G)
+	chk.Scalar(tst, "G(p=400)", 1e-13, G, 800)
+	chk.Scalar(tst, "K(p=400)", 1e-13, K, Calc_K_from_Enu(Calc_E_from_KG(K, G), 0.25))
+	s.Sig = []float64{1, 1, 1, 0} // tension => pmin
+	_, G = pow.Calc(s)
+	chk.Scalar(tst, "G(pmin)", 1e-13, G, 400*math.Sqrt(1e-3))
+
+	// small-strain overlay
+	var hss KgcHss
+	err = hss.Init(prms)
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+	s.Sig = []float64{-100, -100, -100, 0}
+	s.EpsE = []float64{0, 0, 0, 0}
+	_, G = hss.Calc(s)
+	chk.Scalar(tst, "G(γ=0)", 1e-13, G, 400)
+	s.EpsE = []float64{0, 0, 0, 1e-4 / 1.4142135623730951} // γxy = 1e-4 (Mandel: √2 εxy)
+	chk.Scalar(tst, "γ", 1e-17, ShearStrain(s.EpsE), 1e-4)
+	_, G = hss.Calc(s)
+	chk.Scalar(tst, "G(γ=γ07)", 1e-13, G, 400/1.385)
+	s.EpsE[3] = 1.0
+	_, G = hss.Calc(s)
+	chk.Scalar(tst, "G(γ→∞)", 1e-13, G, 40)
+}
+
+func Test_kgc02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("kgc02")
+
+	// linear elastic model with stress dependent moduli
+	var m LinElast
+	err := m.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "E", V: 1000},
+		&fun.Prm{N: "nu", V: 0.25, Extra: "!kgc:pow"},
+		&fun.Prm{N: "G0", V: 400},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "n", V: 0.5},
+	})
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+	s, _ := m.InitIntVars([]float64{-100, -100, -100, 0, 0, 0})
+
+	// first increment with G = G0
+	ε := make([]float64, 6)
+	Δε := []float64{0, 0, 0, 1e-4, 0, 0}
+	err = m.Update(s, ε, Δε, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("Update failed: %v\n", err)
+		return
+	}
+	io.Pforan("σ  = %v\n", s.Sig)
+	io.Pforan("εe = %v\n", s.EpsE)
+	chk.Vector(tst, "σ", 1e-13, s.Sig, []float64{-100, -100, -100, 2 * 400 * 1e-4, 0, 0})
+	chk.Vector(tst, "εe", 1e-17, s.EpsE, Δε)
+
+	// isotropic compression: stiffness increases with pressure
+	Δε = []float64{-1e-3, -1e-3, -1e-3, 0, 0, 0}
+	var Δp []float64
+	for i := 0; i < 3; i++ {
+		p0 := -s.Sig[0]
+		m.Update(s, ε, Δε, 0, 0, 0)
+		Δp = append(Δp, -s.Sig[0]-p0)
+	}
+	io.Pforan("Δp = %v\n", Δp)
+	if Δp[1] <= Δp[0] || Δp[2] <= Δp[1] {
+		tst.Errorf("stiffness must increase with pressure. Δp = %v is invalid\n", Δp)
+	}
+
+	// elastic part of plasticity model
+	var dp DruckerPrager
+	err = dp.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "E", V: 1000},
+		&fun.Prm{N: "nu", V: 0.25, Extra: "!kgc:pow"},
+		&fun.Prm{N: "G0", V: 400},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "n", V: 0.5},
+		&fun.Prm{N: "M", V: 1},
+		&fun.Prm{N: "Mb", V: 1},
+		&fun.Prm{N: "qy0", V: 10},
+	})
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+	s, _ = dp.InitIntVars([]float64{-400, -400, -400, 0, 0, 0})
+	err = dp.Update(s, ε, []float64{0, 0, 0, 1e-5, 0, 0}, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("Update failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "dp: σxy", 1e-13, s.Sig[3], 2*800*1e-5)
+}
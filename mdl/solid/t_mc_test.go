@@ -0,0 +1,133 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/tsr"
+)
+
+func Test_mc01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mc01. Tresca: main plane and edges")
+
+	mdl, err := New("mc")
+	if err != nil {
+		tst.Errorf("New failed:\n%v", err)
+		return
+	}
+	K, G, c := 10.0, 6.0, 1.0
+	err = mdl.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: K},
+		&fun.Prm{N: "G", V: G},
+		&fun.Prm{N: "c", V: c},
+		&fun.Prm{N: "phi", V: 0},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	m := mdl.(*MohrCoulomb)
+	s0, _ := m.InitIntVars([]float64{-1, -1, -1, 0, 0, 0})
+
+	// main plane: Δγ = ftr / (4 G)
+	Δε := []float64{0.1, 0, -0.1, 0, 0, 0}
+	s := s0.GetCopy()
+	err = m.Update(s, nil, Δε, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("Update failed:\n%v", err)
+		return
+	}
+	ftr := 2.0*G*0.2 - 2.0*c
+	io.Pforan("σ = %v  Δγ = %v\n", s.Sig, s.Dgam)
+	chk.Scalar(tst, "Δγ", 1e-14, s.Dgam, ftr/(4.0*G))
+	chk.Vector(tst, "σ", 1e-14, s.Sig, []float64{0, -1, -2, 0, 0, 0})
+
+	// edges with repeated trial eigenvalues: triaxial extension and compression
+	for k, Δε := range [][]float64{
+		{0.1, 0.1, -0.2, 0, 0, 0},
+		{0.2, -0.1, -0.1, 0, 0, 0},
+	} {
+		s = s0.GetCopy()
+		err = m.Update(s, nil, Δε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("Update failed:\n%v", err)
+			return
+		}
+		io.Pforan("k = %d  σ = %v  Δγ = %v\n", k, s.Sig, s.Dgam)
+		chk.Scalar(tst, "f", 1e-13, m.YieldFunc(s.Sig[:3], s.Alp), 0)
+		chk.Scalar(tst, "p", 1e-13, tsr.M_p(s.Sig), tsr.M_p(s0.Sig))
+		if k == 0 {
+			chk.Scalar(tst, "σ0-σ1", 1e-13, s.Sig[0]-s.Sig[1], 0)
+		} else {
+			chk.Scalar(tst, "σ1-σ2", 1e-13, s.Sig[1]-s.Sig[2], 0)
+		}
+		rm_checkD(tst, m, s0, Δε, 1e-6)
+	}
+}
+
+func Test_mc02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mc02. Mohr-Coulomb: consistent tangent with spectral decomposition")
+
+	for _, ndim := range []int{2, 3} {
+		mdl, err := New("mc")
+		if err != nil {
+			tst.Errorf("New failed:\n%v", err)
+			return
+		}
+		err = mdl.Init(ndim, false, []*fun.Prm{
+			&fun.Prm{N: "E", V: 1000},
+			&fun.Prm{N: "nu", V: 0.25},
+			&fun.Prm{N: "c", V: 2},
+			&fun.Prm{N: "phi", V: 30},
+			&fun.Prm{N: "psi", V: 10},
+			&fun.Prm{N: "H", V: 50},
+		})
+		if err != nil {
+			tst.Errorf("Init failed:\n%v", err)
+			return
+		}
+		m := mdl.(*MohrCoulomb)
+		nsig := 2 * ndim
+		s0, _ := m.InitIntVars([]float64{-10, -12, -11, 1, 0.5, -0.5}[:nsig])
+		for k, Δε := range [][]float64{
+			{-0.03, 0.012, 0.006, 0.009, -0.006, 0.003},     // main plane
+			{-0.03, 0.012, 0.0117, 0.0015, 0.0003, 0.0006},  // near compression edge
+			{-0.036, -0.033, 0.018, 0.0015, 0.0006, 0.0003}, // near extension edge
+		} {
+			Δε = Δε[:nsig]
+			s := s0.GetCopy()
+			err = m.Update(s, nil, Δε, 0, 0, 0)
+			if err != nil {
+				tst.Errorf("Update failed:\n%v", err)
+				return
+			}
+			σ := make([]float64, 3)
+			P := tsr.M_AllocEigenprojs(nsig)
+			tsr.M_EigenValsProjsNum(P, σ, s.Sig)
+			f := m.YieldFunc(σ, s.Alp)
+			io.Pforan("ndim = %d  k = %d  nit = %d  σ = %v  α = %v  f = %v\n", ndim, k, m.rmap.Nit, σ, s.Alp, f)
+			if !s.Loading {
+				tst.Errorf("state must be elastoplastic")
+				return
+			}
+			chk.Scalar(tst, "f", 1e-10, f, 0)
+			chk.Scalar(tst, "α", 1e-15, s.Alp[0], s.Dgam)
+			if math.IsNaN(s.Sig[0]) {
+				tst.Errorf("NaN found in stresses")
+				return
+			}
+			rm_checkD(tst, m, s0, Δε, 1e-5)
+		}
+	}
+}
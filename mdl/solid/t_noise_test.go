@@ -0,0 +1,58 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_noise01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("noise01")
+
+	// reproducible and independent of the sequence of calls
+	a := &Noise{Amp: 0.1, Seed: 123}
+	b := &Noise{Amp: 0.1, Seed: 123}
+	v := a.Value(7, 2)
+	for i := 0; i < 10; i++ {
+		b.Value(i, 0)
+	}
+	chk.Scalar(tst, "same indices", 1e-17, b.Value(7, 2), v)
+	c := &Noise{Amp: 0.1, Seed: 124}
+	if c.Value(7, 2) == v || a.Value(2, 7) == v {
+		tst.Errorf("different seeds or indices must give different values\n")
+		return
+	}
+
+	// uniform: bounds and mean
+	n := 20000
+	var sum float64
+	for i := 0; i < n; i++ {
+		x := a.Value(i)
+		if math.Abs(x) > a.Amp {
+			tst.Errorf("uniform noise must be within [-Amp, Amp]. %g is invalid\n", x)
+			return
+		}
+		sum += x
+	}
+	chk.Scalar(tst, "mean(uniform)", 2e-3, sum/float64(n), 0)
+
+	// normal: mean and standard deviation
+	d := &Noise{Amp: 2, Seed: 1, Normal: true}
+	var sum2 float64
+	sum = 0
+	for i := 0; i < n; i++ {
+		x := d.Value(i)
+		sum += x
+		sum2 += x * x
+	}
+	mean := sum / float64(n)
+	chk.Scalar(tst, "mean(normal)", 5e-2, mean, 0)
+	chk.Scalar(tst, "std(normal)", 5e-2, math.Sqrt(sum2/float64(n)-mean*mean), 2)
+}
@@ -0,0 +1,95 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+func Test_onedep01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("onedep01. elastoplastic rod with buckling and rupture")
+
+	var m OnedElastPlast
+	err := m.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "E", V: 1000},
+		&fun.Prm{N: "A", V: 0.01},
+		&fun.Prm{N: "sy", V: 2},
+		&fun.Prm{N: "H", V: 250},
+		&fun.Prm{N: "I", V: 1e-6},
+		&fun.Prm{N: "Lb", V: 1},
+		&fun.Prm{N: "eu", V: 0.02},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	σcr := math.Pi * math.Pi * 1000 * 1e-6 / 0.01 // π² E I / (Lb² A)
+	chk.Scalar(tst, "σc", 1e-15, m.Scap, σcr)
+	s, _ := m.InitIntVars1D()
+
+	// elastic
+	m.Update(s, 0, 1e-3, 0)
+	D, _, _ := m.CalcD(s, false)
+	chk.Scalar(tst, "σ", 1e-14, s.Sig, 1)
+	chk.Scalar(tst, "D", 1e-14, D, 1000)
+
+	// yielding in tension: σ = sy + E H / (E + H) (ε - sy/E)
+	m.Update(s, 0, 3e-3, 0)
+	D, _, _ = m.CalcD(s, false)
+	chk.Scalar(tst, "σ", 1e-14, s.Sig, 2+200*2e-3)
+	chk.Scalar(tst, "D", 1e-14, D, 200)
+	chk.Scalar(tst, "εp", 1e-15, s.Alp[0], 4e-3-2.4/1000)
+
+	// elastic unloading and buckling in compression
+	m.Update(s, 0, -2e-3, 0)
+	chk.Scalar(tst, "σ", 1e-14, s.Sig, 0.4)
+	m.Update(s, 0, -5e-3, 0)
+	D, _, _ = m.CalcD(s, false)
+	chk.Scalar(tst, "σ", 1e-14, s.Sig, -σcr)
+	chk.Scalar(tst, "D", 1e-14, D, 1e-6*1000)
+	if !s.Loading {
+		tst.Errorf("rod must be buckling")
+		return
+	}
+
+	// reloading in tension with hardening kept and rupture
+	m.Update(s, 0, 1e-3, 0)
+	chk.Scalar(tst, "σ", 1e-14, s.Sig, 1-σcr)
+	m.Update(s, 0, 0.03, 0)
+	D, _, _ = m.CalcD(s, false)
+	chk.Scalar(tst, "σ", 1e-15, s.Sig, 0)
+	chk.Scalar(tst, "D", 1e-15, D, 1e-6*1000)
+	if !m.Ruptured(s) {
+		tst.Errorf("rod must be ruptured")
+		return
+	}
+	m.Update(s, 0, -0.05, 0)
+	chk.Scalar(tst, "σ", 1e-15, s.Sig, 0)
+
+	// compressive yielding governs if sc < σcr
+	m = OnedElastPlast{}
+	prms := m.GetPrms()
+	prms = append(prms, &fun.Prm{N: "sc", V: 1e3})
+	err = m.Init(2, false, prms)
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	chk.Scalar(tst, "σc", 1e-15, m.Scap, 1e3)
+
+	// invalid parameters
+	m = OnedElastPlast{}
+	prms = m.GetPrms()
+	prms[2].V = 0
+	if m.Init(2, false, prms) == nil {
+		tst.Errorf("Init must fail with sy = 0")
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_onedtension01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("onedtension01. tension-only model")
+
+	var m OnedTension
+	err := m.Init(2, false, m.GetPrms())
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ := m.InitIntVars1D()
+
+	// loading in tension
+	m.Update(s, 0, 1e-3, 0)
+	D, _, _ := m.CalcD(s, false)
+	chk.Scalar(tst, "σ", 1e-12, s.Sig, 1e3)
+	chk.Scalar(tst, "D", 1e-12, D, 1e6)
+
+	// unloading into compression
+	m.Update(s, 0, -3e-3, 0)
+	D, _, _ = m.CalcD(s, false)
+	chk.Scalar(tst, "σ", 1e-12, s.Sig, -2e-3)
+	chk.Scalar(tst, "D", 1e-12, D, 1)
+	if s.Loading {
+		tst.Errorf("model must be slack under compression")
+	}
+
+	// invalid parameters
+	m = OnedTension{}
+	prms := m.GetPrms()
+	prms[0].V = 0
+	if m.Init(2, false, prms) == nil {
+		tst.Errorf("Init must fail with E = 0")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_phasefield01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("phasefield01. degraded elasticity and driving terms")
+
+	E, ν, gc, lc := 1000.0, 0.25, 0.1, 0.05
+	prms := func(at, split float64) fun.Prms {
+		return []*fun.Prm{
+			&fun.Prm{N: "E", V: E},
+			&fun.Prm{N: "nu", V: ν},
+			&fun.Prm{N: "gc", V: gc},
+			&fun.Prm{N: "lc", V: lc},
+			&fun.Prm{N: "at", V: at},
+			&fun.Prm{N: "kres", V: 0},
+			&fun.Prm{N: "split", V: split},
+		}
+	}
+
+	// no split: σ = g(d)・D:ε and H = ½ ε:D:ε
+	var m PhaseField
+	err := m.Init(2, false, prms(2, 0))
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ := m.InitIntVars(make([]float64, 4))
+	ε := []float64{0.001, -0.0002, 0, 0.0004}
+	m.SetDamage(s, 0.5)
+	m.Update(s, ε, ε, 0, 0, 0)
+	D := la.MatAlloc(4, 4)
+	m.CalcD(D, s, true)
+	σ0 := make([]float64, 4)
+	la.MatVecMul(σ0, 1, D, ε) // D is already degraded
+	chk.Vector(tst, "σ", 1e-15, s.Sig, σ0)
+	chk.Scalar(tst, "H", 1e-15, s.Alp[1], la.VecDot(σ0, ε)/0.25/2)
+	if !s.Loading {
+		tst.Errorf("H must be increasing")
+		return
+	}
+
+	// history does not decrease
+	m.Update(s, []float64{0.0005, 0, 0, 0}, ε, 0, 0, 0)
+	chk.Scalar(tst, "H (unloading)", 1e-15, s.Alp[1], la.VecDot(σ0, ε)/0.25/2)
+
+	// homogeneous solution of AT2: d = 2H / (2H + gc/lc)
+	H := 0.3
+	d := 2 * H / (2*H + gc/lc)
+	f, _ := m.Driving(d, H)
+	chk.Scalar(tst, "f(d*) AT2", 1e-15, f, 0)
+	chk.Scalar(tst, "Dc AT2", 1e-15, m.Diffusivity(), gc*lc)
+
+	// volumetric-deviatoric split: no degradation of compressive volumetric stresses
+	err = m.Init(2, false, prms(1, 1))
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	s, _ = m.InitIntVars(make([]float64, 4))
+	m.SetDamage(s, 1)
+	ε = []float64{-0.001, -0.001, 0, 0}
+	m.Update(s, ε, ε, 0, 0, 0)
+	chk.Vector(tst, "σ (compression)", 1e-13, s.Sig, []float64{-2 * m.K * 0.001, -2 * m.K * 0.001, -2 * m.K * 0.001, 0})
+	chk.Scalar(tst, "H (compression)", 1e-15, s.Alp[1], m.G*(2*0.001*0.001/9+4*0.001*0.001/9))
+
+	// AT1: no damage below threshold; homogeneous solution above it
+	f, _ = m.Driving(0, 0.5*m.PsiC)
+	chk.Scalar(tst, "f(0) AT1", 1e-15, f, 0)
+	H = 4 * m.PsiC
+	d = 1 - 3*gc/(16*lc*H)
+	f, _ = m.Driving(d, H)
+	chk.Scalar(tst, "f(d*) AT1", 1e-15, f, 0)
+
+	// consistent tangent: numerical derivatives
+	for _, ε := range [][]float64{{0.001, -0.0002, 0, 0.0004}, {-0.001, 0.0002, 0, 0.0004}} {
+		s0, _ := m.InitIntVars(make([]float64, 4))
+		m.SetDamage(s0, 0.3)
+		s1 := s0.GetCopy()
+		m.Update(s1, ε, ε, 0, 0, 0)
+		m.CalcD(D, s1, false)
+		for j := 0; j < 4; j++ {
+			h := 1e-8
+			s2 := s0.GetCopy()
+			ε[j] += h
+			m.Update(s2, ε, ε, 0, 0, 0)
+			ε[j] -= h
+			for i := 0; i < 4; i++ {
+				chk.AnaNum(tst, io.Sf("D%d%d", i, j), 1e-4*math.Max(1, math.Abs(D[i][j])), D[i][j], (s2.Sig[i]-s1.Sig[i])/h, chk.Verbose)
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// rmvm implements the von Mises surface with linear hardening for testing RetMap
+type rmvm struct {
+	qy0, H float64
+}
+
+func (o rmvm) F(σ, α []Dual) Dual  { return AdM_q(σ).Sub(α[0].Scale(o.H)).Plus(-o.qy0) }
+func (o rmvm) Hard(h, σ, α []Dual) { h[0] = DualConst(1) }
+func (o rmvm) Nb(Nb, σ, α []Dual) {
+	q := AdM_q(σ)
+	AdM_Dev(Nb, σ)
+	for i := 0; i < len(σ); i++ {
+		Nb[i] = Nb[i].Scale(1.5).Div(q)
+	}
+}
+
+// rm_checkD checks the consistent tangent of model with numerical derivatives
+func rm_checkD(tst *testing.T, mdl Small, s0 *State, Δε []float64, tol float64) {
+	nsig := len(Δε)
+	s := s0.GetCopy()
+	ε := make([]float64, nsig)
+	err := mdl.Update(s, ε, Δε, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("Update failed:\n%v", err)
+		return
+	}
+	D, Dnum := la.MatAlloc(nsig, nsig), la.MatAlloc(nsig, nsig)
+	mdl.CalcD(D, s, false)
+	h := 1e-7
+	for j := 0; j < nsig; j++ {
+		sa, sb := s0.GetCopy(), s0.GetCopy()
+		Δεa, Δεb := make([]float64, nsig), make([]float64, nsig)
+		copy(Δεa, Δε)
+		copy(Δεb, Δε)
+		Δεa[j] += h
+		Δεb[j] -= h
+		mdl.Update(sa, ε, Δεa, 0, 0, 0)
+		mdl.Update(sb, ε, Δεb, 0, 0, 0)
+		for i := 0; i < nsig; i++ {
+			Dnum[i][j] = (sa.Sig[i] - sb.Sig[i]) / (2 * h)
+		}
+	}
+	chk.Matrix(tst, "D", tol, D, Dnum)
+}
+
+func Test_retmap01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("retmap01. von Mises and Drucker-Prager with RetMap")
+
+	// von Mises
+	K, G, qy0, H := 1.5, 1.0, 2.0, 0.5
+	var vm VonMises
+	err := vm.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: K},
+		&fun.Prm{N: "G", V: G},
+		&fun.Prm{N: "qy0", V: qy0},
+		&fun.Prm{N: "H", V: H},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	var rm RetMap
+	err = rm.Init(3, 1, K, G, []RmSurface{rmvm{qy0, H}})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	σ0 := []float64{-1, -1, -1, 0, 0, 0}
+	s1, _ := vm.InitIntVars(σ0)
+	s2, _ := vm.InitIntVars(σ0)
+	ε := make([]float64, 6)
+	Δε := []float64{-0.1, 0.3, -0.05, 0.2, -0.1, 0.15}
+	D1, D2 := la.MatAlloc(6, 6), la.MatAlloc(6, 6)
+	for inc := 0; inc < 4; inc++ {
+		vm.Update(s1, ε, Δε, 0, 0, 0)
+		err = rm.Update(s2, Δε)
+		if err != nil {
+			tst.Errorf("Update failed:\n%v", err)
+			return
+		}
+		io.Pforan("inc = %d  nit = %d  loading = %v  α = %v\n", inc, rm.Nit, s2.Loading, s2.Alp)
+		chk.Vector(tst, "σ", 1e-12, s2.Sig, s1.Sig)
+		chk.Vector(tst, "α", 1e-12, s2.Alp, s1.Alp)
+		chk.Scalar(tst, "Δγ", 1e-12, s2.Dgam, s1.Dgam)
+		vm.CalcD(D1, s1, false)
+		rm.CalcD(D2, s2)
+		chk.Matrix(tst, "D", 1e-11, D2, D1)
+	}
+
+	// Drucker-Prager without reaching the cut-off
+	prms := []*fun.Prm{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "M", V: 1.2},
+		&fun.Prm{N: "Mb", V: 0.6},
+		&fun.Prm{N: "qy0", V: 0.5},
+		&fun.Prm{N: "H", V: 0.3},
+	}
+	var dp DruckerPrager
+	err = dp.Init(2, false, prms)
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	mdl, err := New("dp-tc")
+	if err != nil {
+		tst.Errorf("New failed:\n%v", err)
+		return
+	}
+	err = mdl.Init(2, false, append(prms, &fun.Prm{N: "pt", V: 0.2}))
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	dptc := mdl.(*DPTensionCut)
+	σ0 = []float64{-2, -2, -2, 0}
+	s1, _ = dp.InitIntVars(σ0)
+	s2, _ = dptc.InitIntVars(σ0)
+	ε = make([]float64, 4)
+	Δε = []float64{-0.2, 0.25, 0.05, 0.3}
+	D1, D2 = la.MatAlloc(4, 4), la.MatAlloc(4, 4)
+	for inc := 0; inc < 4; inc++ {
+		dp.Update(s1, ε, Δε, 0, 0, 0)
+		dptc.Update(s2, ε, Δε, 0, 0, 0)
+		io.Pforan("inc = %d  loading = %v  f = %v\n", inc, s2.Loading, dptc.YieldFuncs(s2))
+		chk.Vector(tst, "σ", 1e-12, s2.Sig, s1.Sig)
+		chk.Vector(tst, "α", 1e-12, s2.Alp, s1.Alp)
+		dp.CalcD(D1, s1, false)
+		dptc.CalcD(D2, s2, false)
+		chk.Matrix(tst, "D", 1e-11, D2, D1)
+	}
+}
+
+func Test_retmap02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("retmap02. Drucker-Prager with tension cut-off: corner and consistent tangent")
+
+	mdl, err := New("dp-tc")
+	if err != nil {
+		tst.Errorf("New failed:\n%v", err)
+		return
+	}
+	err = mdl.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: 10},
+		&fun.Prm{N: "G", V: 6},
+		&fun.Prm{N: "M", V: 1},
+		&fun.Prm{N: "Mb", V: 0.5},
+		&fun.Prm{N: "qy0", V: 3},
+		&fun.Prm{N: "H", V: 2},
+		&fun.Prm{N: "pt", V: 1},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	m := mdl.(*DPTensionCut)
+	s0, _ := m.InitIntVars([]float64{-1, -1, -1, 0, 0, 0})
+
+	// cone, cut-off and corner
+	for k, Δε := range [][]float64{
+		{-0.3, 0.2, 0.05, 0.25, -0.1, 0.15}, // cone
+		{0.1, 0.1, 0.1, 0.01, 0, 0},         // cut-off
+		{0.2, 0.1, 0.05, 0.3, 0.05, 0},      // corner
+	} {
+		s := s0.GetCopy()
+		err = m.Update(s, nil, Δε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("Update failed:\n%v", err)
+			return
+		}
+		f := m.YieldFuncs(s)
+		io.Pforan("k = %d  nit = %d  σ = %v  α = %v  f = %v\n", k, m.rmap.Nit, s.Sig, s.Alp, f)
+		if !s.Loading {
+			tst.Errorf("state must be elastoplastic")
+			return
+		}
+		switch k {
+		case 0:
+			chk.Scalar(tst, "f1", 1e-9, f[0], 0)
+			if f[1] > 0 {
+				tst.Errorf("cut-off must not be violated")
+			}
+		case 1:
+			chk.Scalar(tst, "f2", 1e-9, f[1], 0)
+			chk.Scalar(tst, "α", 1e-15, s.Alp[0], 0)
+			if f[0] > 0 {
+				tst.Errorf("cone must not be violated")
+			}
+		case 2:
+			chk.Scalar(tst, "f1", 1e-9, f[0], 0)
+			chk.Scalar(tst, "f2", 1e-9, f[1], 0)
+			if s.Alp[0] <= 0 || s.Dgam <= s.Alp[0] {
+				tst.Errorf("both surfaces must be active: α = %g, Δγ = %g", s.Alp[0], s.Dgam)
+			}
+		}
+		rm_checkD(tst, m, s0, Δε, 1e-6)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+func sanisand_model(tst *testing.T, ein float64) (m *SaniSand) {
+	m = new(SaniSand)
+	err := m.Init(2, false, []*fun.Prm{
+		&fun.Prm{N: "ein", V: ein},
+		&fun.Prm{N: "nsub", V: 50},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return nil
+	}
+	return
+}
+
+func Test_sanisand01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("sanisand01. drained triaxial compression")
+
+	// dense sand
+	m := sanisand_model(tst, 0.7)
+	if m == nil {
+		return
+	}
+	p0 := 100.0
+	s, err := m.InitIntVars([]float64{-p0, -p0, -p0, 0})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// axial compression with constant lateral stresses
+	//  the lateral strain is found by Newton iterations such that Δσx = Δσz = 0
+	ε := make([]float64, 4)
+	D := la.MatAlloc(4, 4)
+	Δεy := -1e-3
+	var εv, εvmax float64
+	for k := 0; k < 100; k++ {
+		x := 0.0
+		var trial *State
+		for it := 0; it < 20; it++ {
+			trial = s.GetCopy()
+			Δε := []float64{x, Δεy, x, 0}
+			err = m.Update(trial, ε, Δε, 0, 0, 0)
+			if err != nil {
+				tst.Errorf("test failed: %v\n", err)
+				return
+			}
+			res := trial.Sig[0] + p0
+			if math.Abs(res) < 1e-8*p0 {
+				break
+			}
+			err = m.CalcD(D, trial, false)
+			if err != nil {
+				tst.Errorf("test failed: %v\n", err)
+				return
+			}
+			x -= res / (D[0][0] + D[0][2])
+		}
+		s.Set(trial)
+		ε[0] += x
+		ε[1] += Δεy
+		ε[2] += x
+		εv = -(ε[0] + ε[1] + ε[2]) // compression positive
+		εvmax = math.Max(εv, εvmax)
+		if k%10 == 0 {
+			io.Pforan("εa = %6.3f%%  q = %8.3f  εv = %8.5f%%  e = %v\n", -ε[1]*100, tsr.M_q(s.Sig), εv*100, s.Alp[0])
+		}
+	}
+
+	// dense sand: initial contraction followed by dilation
+	if εvmax <= 0 {
+		tst.Errorf("dense sand should contract initially: εvmax = %g\n", εvmax)
+		return
+	}
+	if εv >= εvmax {
+		tst.Errorf("dense sand should dilate: εv = %g >= εvmax = %g\n", εv, εvmax)
+		return
+	}
+	chk.Scalar(tst, "σx", 1e-6, s.Sig[0], -p0)
+	chk.Scalar(tst, "e", 1e-4, s.Alp[0], (1+0.7)*math.Exp(-εv)-1)
+}
+
+func Test_sanisand02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("sanisand02. undrained cyclic simple shear")
+
+	// loose sand
+	m := sanisand_model(tst, 0.85)
+	if m == nil {
+		return
+	}
+	p0 := 100.0
+	s, err := m.InitIntVars([]float64{-p0, -p0, -p0, 0})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// undrained (isochoric) cyclic shear strain
+	ε := make([]float64, 4)
+	γamp, nincs, ncycles := 2e-3, 40, 5
+	pold := p0
+	for cyc := 0; cyc < ncycles; cyc++ {
+		for _, sgn := range []float64{1, -1, -1, 1} {
+			Δε := []float64{0, 0, 0, sgn * tsr.SQ2 * γamp / 2 / float64(nincs)}
+			for k := 0; k < nincs; k++ {
+				ε[3] += Δε[3]
+				err = m.Update(s, ε, Δε, 0, 0, 0)
+				if err != nil {
+					tst.Errorf("test failed: %v\n", err)
+					return
+				}
+			}
+		}
+		p := tsr.M_p(s.Sig)
+		io.Pforan("cycle %d: p = %8.3f  (excess pore-water pressure ratio = %.3f)\n", cyc, p, 1-p/p0)
+		if p >= pold {
+			tst.Errorf("mean effective stress should decrease with cycles: %g >= %g\n", p, pold)
+			return
+		}
+		pold = p
+	}
+	chk.Scalar(tst, "e (undrained)", 1e-15, s.Alp[0], 0.85)
+}
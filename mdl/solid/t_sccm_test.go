@@ -0,0 +1,120 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func sccm_prms(G, K, S0, kS float64) fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "phi", V: 25},
+		&fun.Prm{N: "Mfix", V: 1},
+		&fun.Prm{N: "c", V: 1},
+		&fun.Prm{N: "lam", V: 0.1},
+		&fun.Prm{N: "ocr", V: 1},
+		&fun.Prm{N: "kap", V: 0.05},
+		&fun.Prm{N: "kapb", V: 0.01},
+		&fun.Prm{N: "G0", V: G},
+		&fun.Prm{N: "pr", V: 1},
+		&fun.Prm{N: "le", V: 0},
+		&fun.Prm{N: "K0", V: K},
+		&fun.Prm{N: "S0", V: S0},
+		&fun.Prm{N: "kS", V: kS},
+	}
+}
+
+func Test_sccm01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("sccm01")
+
+	E, ν := 1500.0, 0.25
+	K := Calc_K_from_Enu(E, ν)
+	G := Calc_G_from_Enu(E, ν)
+
+	// without structure => CamClay
+	var ccm CamClayMod
+	var sccm StructuredCamClay
+	err := ccm.Init(2, false, sccm_prms(G, K, 0, 0))
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	err = sccm.Init(2, false, sccm_prms(G, K, 0, 10))
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	σ := []float64{-1, -2, -1, 0.5}
+	s0, _ := ccm.InitIntVars(σ)
+	s1, _ := sccm.InitIntVars(σ)
+	chk.Scalar(tst, "α0", 1e-15, s1.Alp[0], s0.Alp[0])
+	chk.Scalar(tst, "f", 1e-15, sccm.YieldFuncs(s1)[0], ccm.YieldFuncs(s0)[0])
+
+	// with structure: same initial yield surface
+	err = sccm.Init(2, false, sccm_prms(G, K, 1, 10))
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	s1, _ = sccm.InitIntVars(σ)
+	chk.Scalar(tst, "α0", 1e-15, s1.Alp[0], s0.Alp[0]/2)
+	chk.Scalar(tst, "S", 1e-15, s1.Alp[1], 1)
+	chk.Scalar(tst, "f", 1e-15, sccm.YieldFuncs(s1)[0], ccm.YieldFuncs(s0)[0])
+}
+
+func Test_sccm02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("sccm02")
+
+	E, ν := 1500.0, 0.25
+	K := Calc_K_from_Enu(E, ν)
+	G := Calc_G_from_Enu(E, ν)
+
+	// allocate driver
+	ndim, pstress := 2, false
+	var drv Driver
+	err := drv.Init("test", "sccm", ndim, pstress, sccm_prms(G, K, 1, 10))
+	drv.CheckD = true
+	drv.TolD = 1e-4
+	drv.VerD = io.Verbose // verbose
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// path
+	var pth Path
+	pth.Sx = []float64{-1}
+	pth.Sy = []float64{-2}
+	pth.Sz = []float64{-1}
+	pth.Ex = []float64{0, 0, 0.001, -0.004}
+	pth.Ey = []float64{0, -0.005, 0, -0.002}
+	pth.Ez = []float64{0, 0, 0.001, -0.004}
+	pth.UseS = []int{0, 0}
+	pth.UseE = []int{0, 1}
+	pth.Init(ndim)
+
+	// run
+	err = drv.Run(&pth)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// destructuration
+	n := len(drv.Res)
+	S := drv.Res[n-1].Alp[1]
+	io.Pforan("S = %v\n", S)
+	if S >= 1 || S < 0 {
+		tst.Errorf("structure must degrade with plastic strains: 0 ≤ S < S0. S = %g is invalid\n", S)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/tsr"
+)
+
+func Test_sclay1_01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("sclay1_01")
+
+	// model
+	ndim, pstress := 2, false
+	var m SClay1
+	err := m.Init(ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "M", V: 1.2},
+		&fun.Prm{N: "lamS", V: 0.1},
+		&fun.Prm{N: "kapS", V: 0.02},
+		&fun.Prm{N: "nur", V: 0.2},
+		&fun.Prm{N: "om", V: 50},
+		&fun.Prm{N: "omd", V: 1},
+		&fun.Prm{N: "ocr", V: 1},
+		&fun.Prm{N: "nsub", V: 20},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// isotropic initial state => no inclination and pm = p
+	p0 := 100.0
+	s, err := m.InitIntVars([]float64{-p0, -p0, -p0, 0})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "pm0", 1e-13, s.Alp[0], p0)
+	chk.Vector(tst, "α0", 1e-15, s.Alp[1:], []float64{0, 0, 0, 0})
+
+	// K0 initial state => inclined yield surface passing through σ0
+	K0 := 0.6
+	σ0 := []float64{-K0 * p0, -p0, -K0 * p0, 0}
+	s, err = m.InitIntVars(σ0)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	p := m.set_σc(s.Sig)
+	chk.Scalar(tst, "f0", 1e-10, m.yield_func(p, s.Alp), 0)
+	chk.Scalar(tst, "pm0(K0)", 1e-10, s.Alp[0], tsr.M_p(σ0))
+
+	// oedometric loading => plastic loading with increasing pm and stress remaining on yield surface
+	ε := make([]float64, 4)
+	Δε := []float64{0, -1e-3, 0, 0}
+	pmold := s.Alp[0]
+	for k := 0; k < 10; k++ {
+		ε[1] += Δε[1]
+		err = m.Update(s, ε, Δε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		p = m.set_σc(s.Sig)
+		f := m.yield_func(p, s.Alp)
+		io.Pforan("k=%d  p=%8.3f  pm=%8.3f  α=%v  f=%v\n", k, p, s.Alp[0], s.Alp[1:], f)
+		if !s.Loading {
+			tst.Errorf("oedometric loading must be elastoplastic\n")
+			return
+		}
+		if s.Alp[0] <= pmold {
+			tst.Errorf("pm must increase: %g <= %g\n", s.Alp[0], pmold)
+			return
+		}
+		if math.Abs(f) > 1e-6*p*p {
+			tst.Errorf("stress must remain on yield surface: f = %g\n", f)
+			return
+		}
+		pmold = s.Alp[0]
+	}
+
+	// unloading => elastic
+	Δε = []float64{0, 1e-4, 0, 0}
+	ε[1] += Δε[1]
+	err = m.Update(s, ε, Δε, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	if s.Loading {
+		tst.Errorf("unloading must be elastic\n")
+		return
+	}
+	chk.Scalar(tst, "pm (unloading)", 1e-15, s.Alp[0], pmold)
+}
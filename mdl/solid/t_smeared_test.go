@@ -0,0 +1,154 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func smeared_model(tst *testing.T, fixed, soft float64) (mdl *SmearedCrack) {
+	mdl = new(SmearedCrack)
+	err := mdl.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "E", V: 1000},
+		&fun.Prm{N: "nu", V: 0},
+		&fun.Prm{N: "ft", V: 1},
+		&fun.Prm{N: "Gf", V: 0.01},
+		&fun.Prm{N: "beta", V: 0.2},
+		&fun.Prm{N: "fixed", V: fixed},
+		&fun.Prm{N: "soft", V: soft},
+	})
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return nil
+	}
+	return
+}
+
+func Test_smeared01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("smeared01")
+
+	// uniaxial strain in x (ν = 0): dissipated energy = Gf / h
+	h := 0.5
+	for _, soft := range []float64{0, 1} {
+		mdl := smeared_model(tst, 1, soft)
+		if mdl == nil {
+			return
+		}
+		s, _ := mdl.InitIntVars(make([]float64, 6))
+		mdl.SetCharLength(s, h)
+		ε := make([]float64, 6)
+		Δε := make([]float64, 6)
+		nincs := 4000
+		εmax := 0.2
+		if soft == 1 {
+			εmax = 0.4
+		}
+		var W, σold float64
+		for i := 0; i < nincs; i++ {
+			Δε[0] = εmax / float64(nincs)
+			ε[0] += Δε[0]
+			err := mdl.Update(s, ε, Δε, 0, 0, 0)
+			if err != nil {
+				tst.Errorf("Update failed: %v\n", err)
+				return
+			}
+			W += (σold + s.Sig[0]) * Δε[0] / 2.0
+			σold = s.Sig[0]
+			if s.Sig[0] > mdl.Ft+1e-12 {
+				tst.Errorf("stress is greater than tensile strength: σ = %v\n", s.Sig[0])
+				return
+			}
+		}
+		io.Pforan("soft = %v: W = %v  Gf/h = %v\n", soft, W, mdl.Gf/h)
+		chk.Scalar(tst, "W", 1e-4, W, mdl.Gf/h)
+		chk.Vector(tst, "n0", 1e-15, s.Alp[sc_frame:sc_frame+3], []float64{1, 0, 0})
+
+		// unloading with secant stiffness
+		d, _ := mdl.Damage(s.Alp[sc_kap], h)
+		Δε[0] = -ε[0] / 2.0
+		ε[0] += Δε[0]
+		err := mdl.Update(s, ε, Δε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("Update failed: %v\n", err)
+			return
+		}
+		chk.Scalar(tst, "σ(unload)", 1e-12, s.Sig[0], (1.0-d)*mdl.E*ε[0])
+
+		// compression: crack is closed
+		Δε[0] = -ε[0] - 1e-4
+		ε[0] += Δε[0]
+		err = mdl.Update(s, ε, Δε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("Update failed: %v\n", err)
+			return
+		}
+		chk.Scalar(tst, "σ(closed)", 1e-12, s.Sig[0], mdl.E*ε[0])
+	}
+}
+
+func Test_smeared02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("smeared02")
+
+	// fixed crack: shear retention
+	for _, fixed := range []float64{1, 0} {
+		mdl := smeared_model(tst, fixed, 0)
+		if mdl == nil {
+			return
+		}
+		s, _ := mdl.InitIntVars(make([]float64, 6))
+		ε := []float64{0.005, 0, 0, 0, 0, 0}
+		err := mdl.Update(s, ε, ε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("Update failed: %v\n", err)
+			return
+		}
+		d, _ := mdl.Damage(s.Alp[sc_kap], s.Alp[sc_h])
+		if d <= 0 {
+			tst.Errorf("crack should be open\n")
+			return
+		}
+
+		// shear
+		Δε := []float64{0, 0, 0, 1e-5 * math.Sqrt2, 0, 0}
+		ε[3] += Δε[3]
+		err = mdl.Update(s, ε, Δε, 0, 0, 0)
+		if err != nil {
+			tst.Errorf("Update failed: %v\n", err)
+			return
+		}
+		io.Pforan("fixed = %v: σ = %v\n", fixed, s.Sig)
+		if fixed == 1 {
+			chk.Vector(tst, "n0", 1e-15, s.Alp[sc_frame:sc_frame+3], []float64{1, 0, 0})
+			chk.Scalar(tst, "σxy", 1e-12, s.Sig[3], mdl.Beta*2.0*mdl.G*ε[3])
+		} else {
+			n0 := s.Alp[sc_frame : sc_frame+3]
+			if math.Abs(n0[1]) < 1e-6 {
+				tst.Errorf("rotating crack: frame should rotate with principal strains. n0 = %v\n", n0)
+				return
+			}
+		}
+
+		// consistent secant stiffness: σ = D ε
+		D := la.MatAlloc(6, 6)
+		err = mdl.CalcD(D, s, false)
+		if err != nil {
+			tst.Errorf("CalcD failed: %v\n", err)
+			return
+		}
+		σ := make([]float64, 6)
+		la.MatVecMul(σ, 1, D, ε)
+		chk.Vector(tst, "σ = D ε", 1e-12, σ, s.Sig)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+func Test_ssc01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ssc01")
+
+	// model
+	ndim, pstress := 2, false
+	var m SoftSoilCreep
+	err := m.Init(ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "phi", V: 25},
+		&fun.Prm{N: "lamS", V: 0.1},
+		&fun.Prm{N: "kapS", V: 0.02},
+		&fun.Prm{N: "muS", V: 0.004},
+		&fun.Prm{N: "tau", V: 1},
+		&fun.Prm{N: "nur", V: 0.2},
+		&fun.Prm{N: "ocr", V: 1},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// initial state: isotropic and normally consolidated
+	p0 := 100.0
+	s, err := m.InitIntVars([]float64{-p0, -p0, -p0, 0})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "pp0", 1e-15, s.Alp[0], p0)
+
+	// no time increment => elastic response
+	Δε := []float64{-1e-4, -1e-4, -1e-4, 0}
+	ε := []float64{-1e-4, -1e-4, -1e-4, 0}
+	err = m.Update(s, ε, Δε, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	K := p0 / 0.02
+	p1 := tsr.M_p(s.Sig)
+	io.Pforan("p1 = %v\n", p1)
+	chk.Scalar(tst, "p1", 1e-12, p1, p0+K*3e-4)
+	chk.Scalar(tst, "pp1", 1e-15, s.Alp[0], p0)
+
+	// constant strains and time passing => stress relaxation and increase of pp
+	ppold, pold := s.Alp[0], p1
+	Δε = []float64{0, 0, 0, 0}
+	for _, t := range []float64{1, 10, 100, 1000} {
+		err = m.Update(s, ε, Δε, 0, 0, t)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		p := tsr.M_p(s.Sig)
+		io.Pforan("t = %6g  p = %v  pp = %v\n", t, p, s.Alp[0])
+		if p >= pold {
+			tst.Errorf("p should decrease due to relaxation: %g >= %g\n", p, pold)
+			return
+		}
+		if s.Alp[0] <= ppold {
+			tst.Errorf("pp should increase due to creep: %g <= %g\n", s.Alp[0], ppold)
+			return
+		}
+		pold, ppold = p, s.Alp[0]
+	}
+}
+
+func Test_ssc02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ssc02. algorithmic tangent and convergence of Newton's method")
+
+	// model
+	ndim, pstress := 2, false
+	var m SoftSoilCreep
+	err := m.Init(ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "phi", V: 25},
+		&fun.Prm{N: "lamS", V: 0.1},
+		&fun.Prm{N: "kapS", V: 0.02},
+		&fun.Prm{N: "muS", V: 0.004},
+		&fun.Prm{N: "tau", V: 1},
+		&fun.Prm{N: "nur", V: 0.2},
+		&fun.Prm{N: "ocr", V: 1},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// initial state: anisotropic and normally consolidated
+	s0, err := m.InitIntVars([]float64{-80, -120, -80, 0})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// update with creep
+	ε := []float64{-1e-4, -3e-4, 0, 1e-4}
+	Δε := []float64{-1e-4, -3e-4, 0, 1e-4}
+	s := s0.GetCopy()
+	err = m.Update(s, ε, Δε, 0, 0, 10)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	if m.nsub < 2 {
+		tst.Errorf("update should have been performed with sub-steps. nsub = %d\n", m.nsub)
+		return
+	}
+
+	// compare tangent with central differences of Update
+	D := la.MatAlloc(4, 4)
+	De := la.MatAlloc(4, 4)
+	m.CalcD(D, s, false)
+	m.calc_De(s0.Sig)
+	la.MatCopy(De, 1, m.De)
+	h := 1e-9
+	stmp := s0.GetCopy()
+	Δεtmp := make([]float64, 4)
+	var diffDe float64
+	for j := 0; j < 4; j++ {
+		var σp, σm []float64
+		for _, sgn := range []float64{1, -1} {
+			copy(Δεtmp, Δε)
+			Δεtmp[j] += sgn * h
+			stmp.Set(s0)
+			err = m.Update(stmp, ε, Δεtmp, 0, 0, 10)
+			if err != nil {
+				tst.Errorf("test failed: %v\n", err)
+				return
+			}
+			if sgn > 0 {
+				σp = append([]float64{}, stmp.Sig...)
+			} else {
+				σm = append([]float64{}, stmp.Sig...)
+			}
+		}
+		for i := 0; i < 4; i++ {
+			dnum := (σp[i] - σm[i]) / (2.0 * h)
+			chk.AnaNum(tst, io.Sf("D%d%d", i, j), 1e-4*math.Max(1, math.Abs(D[i][j])), D[i][j], dnum, chk.Verbose)
+			diffDe = math.Max(diffDe, math.Abs(De[i][j]-dnum))
+		}
+	}
+	io.Pforan("max difference between elastic modulus and numerical tangent = %v\n", diffDe)
+	if diffDe < 1 {
+		tst.Errorf("elastic modulus should differ from the algorithmic tangent\n")
+		return
+	}
+
+	// Newton's method: find the lateral strain such that σxx is kept constant during creep
+	σxx := s0.Sig[0]
+	Δε = []float64{0, -3e-4, 0, 0}
+	var r float64
+	it := 0
+	for ; it < 10; it++ {
+		s.Set(s0)
+		err = m.Update(s, ε, Δε, 0, 0, 10)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		r = math.Abs(s.Sig[0] - σxx)
+		io.Pforan("it = %d  Δεxx = %v  |r| = %v\n", it, Δε[0], r)
+		if r < 1e-9 {
+			break
+		}
+		m.CalcD(D, s, false)
+		Δε[0] -= (s.Sig[0] - σxx) / D[0][0]
+	}
+	if it > 5 {
+		tst.Errorf("Newton's method should converge in less than 5 iterations. it = %d\n", it)
+	}
+}
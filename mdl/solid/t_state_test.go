@@ -6,6 +6,7 @@ package solid
 
 import (
 	"testing"
+	"unsafe"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
@@ -41,3 +42,75 @@ func Test_state01(tst *testing.T) {
 	chk.Vector(tst, "alp", 1.0e-17, state2.Alp, []float64{20})
 	chk.Vector(tst, "epsE", 1.0e-17, state2.EpsE, []float64{0, 0, 0, 0})
 }
+
+func Test_state02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("state02. structure-of-arrays")
+
+	nsig, nalp, large, nle := 4, 2, true, true
+	states := make([]*State, 3)
+	for i := 0; i < len(states); i++ {
+		states[i] = NewState(nsig, nalp, large, nle)
+		for j := 0; j < nsig; j++ {
+			states[i].Sig[j] = float64(10*i + j)
+			states[i].EpsE[j] = -float64(10*i + j)
+		}
+		states[i].Alp[1] = float64(i)
+		states[i].F[2][2] = float64(i)
+		states[i].Dgam = float64(i)
+	}
+
+	packed := PackStates(states)
+	chk.IntAssert(len(packed), 3)
+	for i, s := range packed {
+		chk.Vector(tst, io.Sf("sig%d", i), 1e-17, s.Sig, states[i].Sig)
+		chk.Vector(tst, io.Sf("epsE%d", i), 1e-17, s.EpsE, states[i].EpsE)
+		chk.Vector(tst, io.Sf("alp%d", i), 1e-17, s.Alp, states[i].Alp)
+		chk.Matrix(tst, io.Sf("F%d", i), 1e-17, s.F, states[i].F)
+		chk.Scalar(tst, io.Sf("Δγ%d", i), 1e-17, s.Dgam, states[i].Dgam)
+		if cap(s.Sig) != nsig {
+			tst.Errorf("capacity of views must be equal to their length\n")
+		}
+	}
+
+	// contiguous storage
+	for i := 1; i < len(packed); i++ {
+		a := uintptr(unsafe.Pointer(&packed[i-1].Sig[nsig-1]))
+		b := uintptr(unsafe.Pointer(&packed[i].Sig[0]))
+		if b-a != unsafe.Sizeof(float64(0)) {
+			tst.Errorf("σ of states must be stored contiguously\n")
+		}
+	}
+	packed[1].Set(states[2])
+	chk.Vector(tst, "sig1 after Set", 1e-17, packed[1].Sig, states[2].Sig)
+	chk.Vector(tst, "sig0 after Set", 1e-17, packed[0].Sig, states[0].Sig)
+}
+
+// bench_states runs a loop over the states of many elements like the update of stresses
+func bench_states(b *testing.B, soa bool) {
+	nele, nip, nsig := 4000, 27, 6
+	elems := make([][]*State, nele)
+	for e := 0; e < nele; e++ {
+		elems[e] = make([]*State, nip)
+		for i := 0; i < nip; i++ {
+			elems[e][i] = NewState(nsig, 2, false, true)
+		}
+		if soa {
+			elems[e] = PackStates(elems[e])
+		}
+	}
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for _, states := range elems {
+			for _, s := range states {
+				for j := 0; j < nsig; j++ {
+					s.Sig[j] += s.EpsE[j] + s.Alp[0]
+				}
+			}
+		}
+	}
+}
+
+func Benchmark_states_aos(b *testing.B) { bench_states(b, false) }
+func Benchmark_states_soa(b *testing.B) { bench_states(b, true) }
@@ -0,0 +1,99 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_tempdep01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("tempdep01")
+
+	tab := &TdepData{[][]float64{{20, 200}, {100, 120}, {500, 40}}}
+	for _, d := range [][]float64{{0, 200, 0}, {20, 200, -1}, {60, 160, -1}, {300, 80, -0.2}, {600, 40, 0}} {
+		v, dvdT := tab.Value(d[0])
+		io.Pforan("T = %5g  v = %5g  dvdT = %5g\n", d[0], v, dvdT)
+		chk.Scalar(tst, io.Sf("v(%g)", d[0]), 1e-13, v, d[1])
+		if d[0] != 20 {
+			chk.Scalar(tst, io.Sf("dvdT(%g)", d[0]), 1e-15, dvdT, d[2])
+		}
+	}
+
+	prms := []*fun.Prm{
+		&fun.Prm{N: "E", V: 200},
+		&fun.Prm{N: "nu", V: 0.25},
+	}
+	err := CheckTdep(prms, map[string]*TdepData{"K": tab})
+	if err == nil {
+		tst.Errorf("CheckTdep should have failed with unknown parameter\n")
+		return
+	}
+	err = CheckTdep(prms, map[string]*TdepData{"E": &TdepData{[][]float64{{20, 200}, {10, 100}}}})
+	if err == nil {
+		tst.Errorf("CheckTdep should have failed with decreasing temperatures\n")
+		return
+	}
+}
+
+func Test_tempdep02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("tempdep02")
+
+	prms := []*fun.Prm{
+		&fun.Prm{N: "E", V: 200},
+		&fun.Prm{N: "nu", V: 0.25},
+	}
+	tab := &TdepData{[][]float64{{20, 200}, {100, 120}}}
+	o, err := NewTempDep("lin-elast", 2, false, prms, map[string]*TdepData{"E": tab}, 1)
+	if err != nil {
+		tst.Errorf("NewTempDep failed: %v\n", err)
+		return
+	}
+
+	// update at T = 60 => E = 160
+	T := 60.0
+	mdl, err := o.Model(0, T)
+	if err != nil {
+		tst.Errorf("Model failed: %v\n", err)
+		return
+	}
+	s0, _ := mdl.(Model).InitIntVars(make([]float64, 4))
+	s := s0.GetCopy()
+	ε := []float64{0.01, -0.002, 0, 0.004}
+	Δε := []float64{0.01, -0.002, 0, 0.004}
+	err = mdl.Update(s, ε, Δε, 0, 0, 0)
+	if err != nil {
+		tst.Errorf("Update failed: %v\n", err)
+		return
+	}
+	var ref LinElast
+	ref.Init(2, false, []*fun.Prm{&fun.Prm{N: "E", V: 160}, &fun.Prm{N: "nu", V: 0.25}})
+	sref := s0.GetCopy()
+	ref.Update(sref, ε, Δε, 0, 0, 0)
+	io.Pforan("σ = %v\n", s.Sig)
+	chk.Vector(tst, "σ", 1e-13, s.Sig, sref.Sig)
+
+	// ∂σ/∂T = (dE/dT / E) σ
+	dσdT := make([]float64, 4)
+	err = o.DsigDT(dσdT, s0, ε, Δε, 0, 0, 0, T)
+	if err != nil {
+		tst.Errorf("DsigDT failed: %v\n", err)
+		return
+	}
+	ana := make([]float64, 4)
+	for i, σ := range s.Sig {
+		ana[i] = -1.0 * σ / 160.0
+	}
+	io.Pforan("dσdT = %v\n", dσdT)
+	chk.Vector(tst, "dσdT", 1e-10, dσdT, ana)
+	chk.Vector(tst, "s0.σ (unchanged)", 1e-17, s0.Sig, []float64{0, 0, 0, 0})
+}
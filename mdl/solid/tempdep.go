@@ -0,0 +1,182 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// TdepData holds the values of a parameter at a set of temperatures. The values are linearly
+// interpolated and are constant outside the range of temperatures
+//  Example (materials file): "tdep" : { "E" : { "T-table" : [[20, 210e3], [600, 100e3]] } }
+type TdepData struct {
+	Table [][]float64 `json:"T-table"` // [npts][2] pairs (T, value) sorted by T
+}
+
+// Value returns the value of parameter at temperature T and its derivative dv/dT
+func (o *TdepData) Value(T float64) (v, dvdT float64) {
+	n := len(o.Table)
+	if T <= o.Table[0][0] {
+		return o.Table[0][1], 0
+	}
+	if T >= o.Table[n-1][0] {
+		return o.Table[n-1][1], 0
+	}
+	for i := 1; i < n; i++ {
+		if T <= o.Table[i][0] {
+			a, b := o.Table[i-1], o.Table[i]
+			dvdT = (b[1] - a[1]) / (b[0] - a[0])
+			return a[1] + dvdT*(T-a[0]), dvdT
+		}
+	}
+	return o.Table[n-1][1], 0
+}
+
+// CheckTdep checks the tables of temperature dependent parameters
+func CheckTdep(prms fun.Prms, dat map[string]*TdepData) (err error) {
+	for name, tab := range dat {
+		if prms.Find(name) == nil {
+			return chk.Err("cannot find parameter %q with temperature dependent values", name)
+		}
+		if tab == nil || len(tab.Table) < 1 {
+			return chk.Err("table of temperature dependent parameter %q must have at least one row", name)
+		}
+		for i, row := range tab.Table {
+			if len(row) != 2 {
+				return chk.Err("rows of table of temperature dependent parameter %q must have two columns (T, value). %v is invalid", name, row)
+			}
+			if i > 0 && row[0] <= tab.Table[i-1][0] {
+				return chk.Err("temperatures in table of parameter %q must be increasing. %g after %g is invalid", name, row[0], tab.Table[i-1][0])
+			}
+		}
+	}
+	return
+}
+
+// TempDep implements temperature dependent parameters of small-strain models. A copy of the model
+// is allocated for each integration point and re-initialised with the values of parameters at the
+// temperature of the integration point whenever this temperature changes
+//  Note: (1) the field is the temperature given by the "temp" element condition (solid elements) or
+//            the temperature degree of freedom (coupled solid-thermal elements)
+//        (2) the state variables are not modified when the parameters change; e.g. for linear
+//            elasticity, the stresses are updated with Δσ = D(T)・Δε
+//        (3) ∂σ/∂T is computed by finite differences with the model updated from the state at
+//            the beginning of the increment; it is only required by coupled runs
+type TempDep struct {
+	Dat   map[string]*TdepData // parameter name => table of values
+	Mdls  []Small              // [nip] models initialised with the parameters at the temperature of each ip
+	Temps []float64            // [nip] temperatures corresponding to Mdls
+	Delta float64              // relative increment of temperature for ∂σ/∂T
+
+	// auxiliary
+	name    string   // name of model
+	ndim    int      // space dimension
+	pstress bool     // plane-stress
+	prms    fun.Prms // copy of parameters
+	aux     Small    // auxiliary model for ∂σ/∂T
+	s1, s2  *State   // auxiliary states for ∂σ/∂T
+	ε1, Δε1 []float64
+}
+
+// NewTempDep allocates a new structure for the model called name with parameters prms and tables
+// dat for nip integration points. The models are initialised with the values in prms
+func NewTempDep(name string, ndim int, pstress bool, prms fun.Prms, dat map[string]*TdepData, nip int) (o *TempDep, err error) {
+	err = CheckTdep(prms, dat)
+	if err != nil {
+		return
+	}
+	o = &TempDep{Dat: dat, Delta: 1e-4, name: name, ndim: ndim, pstress: pstress}
+	o.prms = make([]*fun.Prm, len(prms))
+	for i, p := range prms {
+		q := *p
+		o.prms[i] = &q
+	}
+	o.Mdls = make([]Small, nip)
+	o.Temps = make([]float64, nip)
+	for i := 0; i < nip; i++ {
+		o.Mdls[i], err = o.alloc()
+		if err != nil {
+			return
+		}
+		o.Temps[i] = math.NaN()
+	}
+	o.aux, err = o.alloc()
+	return
+}
+
+// Model returns the model of integration point idx initialised at temperature T
+func (o *TempDep) Model(idx int, T float64) (mdl Small, err error) {
+	if T != o.Temps[idx] {
+		err = o.init(o.Mdls[idx], T)
+		if err != nil {
+			return
+		}
+		o.Temps[idx] = T
+	}
+	return o.Mdls[idx], nil
+}
+
+// DsigDT computes ∂σ/∂T by finite differences
+//  Input:
+//   s -- state at the beginning of the increment (not modified)
+//   ε, Δε -- total and incremental strains (as given to Update)
+//   T -- temperature
+//  Output:
+//   dσdT -- [nsig] derivatives of stresses w.r.t temperature
+func (o *TempDep) DsigDT(dσdT []float64, s *State, ε, Δε []float64, eid, ipid int, time, T float64) (err error) {
+	if o.s1 == nil {
+		o.s1, o.s2 = s.GetCopy(), s.GetCopy()
+		o.ε1, o.Δε1 = make([]float64, len(ε)), make([]float64, len(ε))
+	}
+	δ := o.Delta * math.Max(1, math.Abs(T))
+	for k, st := range []*State{o.s1, o.s2} {
+		st.Set(s)
+		copy(o.ε1, ε)
+		copy(o.Δε1, Δε)
+		err = o.init(o.aux, T+float64(k)*δ)
+		if err != nil {
+			return
+		}
+		err = o.aux.Update(st, o.ε1, o.Δε1, eid, ipid, time)
+		if err != nil {
+			return
+		}
+	}
+	for i := range dσdT {
+		dσdT[i] = (o.s2.Sig[i] - o.s1.Sig[i]) / δ
+	}
+	return
+}
+
+// alloc allocates and initialises a copy of the model with the values in prms
+func (o *TempDep) alloc() (mdl Small, err error) {
+	m, err := New(o.name)
+	if err != nil {
+		return
+	}
+	mdl, ok := m.(Small)
+	if !ok {
+		return nil, chk.Err("temperature dependent parameters are only available for small-strain models. %q is invalid", o.name)
+	}
+	err = m.Init(o.ndim, o.pstress, o.prms)
+	return
+}
+
+// init initialises model with the parameters at temperature T
+func (o *TempDep) init(mdl Small, T float64) (err error) {
+	for _, p := range o.prms {
+		if tab, ok := o.Dat[p.N]; ok {
+			p.V, _ = tab.Value(T)
+		}
+	}
+	err = mdl.(Model).Init(o.ndim, o.pstress, o.prms)
+	if err != nil {
+		return chk.Err("cannot initialise model %q at temperature T = %g:\n%v", o.name, T, err)
+	}
+	return
+}
@@ -0,0 +1,51 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solid
+
+import "github.com/cpmech/gosl/fun"
+
+// ThermalStrain computes thermal (eigen) strains εth = α (T - T0) I that are subtracted from
+// the total strains before calling Update of small-strain models
+//  Note: a prescribed volumetric eigen-strain ε0 can be given by using α = 1, T0 = 0 and T = ε0/3
+type ThermalStrain struct {
+	Alpha float64 // coefficient of thermal expansion
+	T0    float64 // reference temperature
+}
+
+// NewThermalStrain returns a new ThermalStrain structure if "alpT" is found in prms
+//  Note: returns nil if "alpT" is not given; i.e. no thermal strains
+func NewThermalStrain(prms fun.Prms) *ThermalStrain {
+	var α, T0 float64
+	var has_α bool
+	for _, p := range prms {
+		switch p.N {
+		case "alpT":
+			α, has_α = p.V, true
+		case "T0":
+			T0 = p.V
+		}
+	}
+	if !has_α {
+		return nil
+	}
+	return &ThermalStrain{α, T0}
+}
+
+// Strain returns the (uniaxial) thermal strain εth = α (T - T0); e.g. for rods
+func (o *ThermalStrain) Strain(T float64) float64 {
+	return o.Alpha * (T - o.T0)
+}
+
+// Subtract subtracts thermal strains from total (ε) and incremental (Δε) strains (Mandel's basis)
+//  T    -- current temperature
+//  Told -- temperature at the beginning of the increment
+func (o *ThermalStrain) Subtract(ε, Δε []float64, T, Told float64) {
+	εth := o.Strain(T)
+	Δεth := o.Alpha * (T - Told)
+	for i := 0; i < 3; i++ {
+		ε[i] -= εth
+		Δε[i] -= Δεth
+	}
+}
@@ -13,15 +13,17 @@ import (
 // VonMises implements von Mises plasticity model
 type VonMises struct {
 	SmallElasticity
-	qy0 float64   // initial qy
-	H   float64   // hardening variable
-	rho float64   // density
-	ten []float64 // auxiliary tensor
+	qy0   float64   // initial qy
+	H     float64   // hardening variable
+	rho   float64   // density
+	UseAd bool      // use consistent tangent computed by automatic differentiation
+	ten   []float64 // auxiliary tensor
 }
 
 // add model to factory
 func init() {
 	allocators["vm"] = func() Model { return new(VonMises) }
+	intvars["vm"] = []string{"alpha"}
 }
 
 // Clean clean resources
@@ -49,9 +51,13 @@ func (o *VonMises) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
 			o.H = p.V
 		case "rho":
 			o.rho = p.V
+		case "ad":
+			o.UseAd = p.V > 0
 		case "E", "nu", "l", "G", "K":
 		default:
-			return chk.Err("vm: parameter named %q is incorrect\n", p.N)
+			if !KgcPrm(p.N) {
+				return chk.Err("vm: parameter named %q is incorrect\n", p.N)
+			}
 		}
 	}
 
@@ -83,6 +89,15 @@ func (o *VonMises) Update(s *State, ε, Δε []float64, eid, ipid int, time floa
 	s.ApexReturn = false // => not return-to-apex
 	s.Dgam = 0           // Δγ := 0
 
+	// non-linear elasticity
+	o.StartIncrement(s)
+	defer o.EndIncrement(s)
+
+	// automatic differentiation
+	if o.UseAd {
+		return AdUpdate(o, s, Δε, eid, ipid, time)
+	}
+
 	// accessors
 	σ := s.Sig
 	α0 := &s.Alp[0]
@@ -128,6 +143,11 @@ func (o *VonMises) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
 		s.Dgam = 0
 	}
 
+	// automatic differentiation
+	if o.UseAd && !firstIt {
+		return AdCalcD(D, s)
+	}
+
 	// elastic
 	if !s.Loading {
 		return o.SmallElasticity.CalcD(D, s)
@@ -154,6 +174,38 @@ func (o *VonMises) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
 	return
 }
 
+// UpdateAd updates stresses using dual numbers (see AdSmall)
+func (o *VonMises) UpdateAd(σ []Dual, s *State, Δε []Dual, eid, ipid int, time float64) (err error) {
+
+	// set flags
+	s.Loading = false
+	s.ApexReturn = false
+	s.Dgam = 0
+
+	// trial stress
+	AdM_Elast(σ, s.Sig, Δε, o.K, o.G) // σ := σtr
+	ptr, qtr := AdM_p(σ), AdM_q(σ)
+
+	// trial yield function
+	ftr := qtr.Plus(-o.qy0 - o.H*s.Alp[0])
+
+	// elastic update
+	if ftr.V <= 0.0 {
+		return
+	}
+
+	// elastoplastic update
+	Δγ := ftr.Scale(1.0 / (3.0*o.G + o.H))
+	m := Δγ.Scale(-3.0 * o.G).Div(qtr).Plus(1) // m = 1 - 3 G Δγ / qtr
+	for i := 0; i < o.Nsig; i++ {
+		σ[i] = m.Mul(σ[i].Add(ptr.Scale(tsr.Im[i]))).Sub(ptr.Scale(tsr.Im[i]))
+	}
+	s.Dgam = Δγ.V
+	s.Alp[0] += Δγ.V
+	s.Loading = true
+	return
+}
+
 // ContD computes D = dσ_new/dε_new continuous
 func (o *VonMises) ContD(D [][]float64, s *State) (err error) {
 
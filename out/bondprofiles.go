@@ -0,0 +1,144 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"sort"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gosl/chk"
+)
+
+// BondProfile holds the profiles of bond stress τ(s), slip ω(s) and axial force N(s) along a
+// reinforcement line; i.e. a chain of rods with rod-joints connected by their end vertices
+type BondProfile struct {
+	Cids []int       // ids of rjoint cells along the line
+	L    float64     // length of line
+	S    []float64   // [nip] parametric coordinate (arc length from the start of the line) of ips
+	X    [][]float64 // [nip] coordinates of ips
+	Tau  [][]float64 // [ntimes][nip] bond stress τ
+	Slip [][]float64 // [ntimes][nip] slip ω
+	N    [][]float64 // [ntimes][nip] axial force
+}
+
+// BondProfiles computes the profiles of bond stress, slip and axial force along each reinforcement
+// line for all selected output times. The integration points of all rjoint elements along a line
+// are sorted by the parametric coordinate s
+//  Note: (1) LoadResults must be called first
+//        (2) lines start at the rod with the smallest id among the ones with a free end vertex
+//        (3) lines are split at vertices shared by more than two rods
+func BondProfiles() (lines []*BondProfile) {
+
+	// rods connected to end vertices
+	vid2rjs := make(map[int][]int)
+	for i, rj := range Rjoints {
+		verts := rj.Rod.Cell.Verts
+		vid2rjs[verts[0]] = append(vid2rjs[verts[0]], i)
+		vid2rjs[verts[1]] = append(vid2rjs[verts[1]], i)
+	}
+	inner := func(vid int) bool { return len(vid2rjs[vid]) == 2 }
+
+	// build lines; first starting at free ends and then closed loops
+	done := make([]bool, len(Rjoints))
+	for pass := 0; pass < 2; pass++ {
+		for first, rj := range Rjoints {
+			verts := rj.Rod.Cell.Verts
+			if done[first] || (pass == 0 && inner(verts[0]) && inner(verts[1])) {
+				continue
+			}
+
+			// chain of rods
+			var rjs []*solid.Rjoint
+			var inv []bool
+			vid := verts[0]
+			if inner(vid) {
+				vid = verts[1]
+			}
+			for i := first; i >= 0; {
+				done[i] = true
+				verts = Rjoints[i].Rod.Cell.Verts
+				reverse := verts[1] == vid
+				vid = verts[0]
+				if !reverse {
+					vid = verts[1]
+				}
+				rjs = append(rjs, Rjoints[i])
+				inv = append(inv, reverse)
+				i = -1
+				if inner(vid) {
+					for _, j := range vid2rjs[vid] {
+						if !done[j] {
+							i = j
+						}
+					}
+				}
+			}
+			lines = append(lines, bp_line(rjs, inv))
+		}
+	}
+	return
+}
+
+// bp_line computes the profiles along a chain of rjoints; inv indicates rods with reversed direction
+func bp_line(rjs []*solid.Rjoint, inv []bool) (line *BondProfile) {
+
+	// parametric coordinates and coordinates of ips
+	line = new(BondProfile)
+	var ips bp_ips
+	for k, rj := range rjs {
+		line.Cids = append(line.Cids, rj.Id())
+		s, L, err := rj.OutIpArcLengths()
+		if err != nil {
+			chk.Panic("cannot compute arc lengths along the rod of rjoint %d:\n%v", rj.Id(), err)
+		}
+		X := rj.OutIpCoords()
+		for i := 0; i < len(s); i++ {
+			if inv[k] {
+				s[i] = L - s[i]
+			}
+			ips = append(ips, bp_ip{line.L + s[i], X[i], k, i})
+		}
+		line.L += L
+	}
+	sort.Stable(ips)
+	for _, ip := range ips {
+		line.S = append(line.S, ip.s)
+		line.X = append(line.X, ip.x)
+	}
+
+	// values for all selected output times
+	nt, nip := len(TimeInds), len(ips)
+	line.Tau, line.Slip, line.N = make([][]float64, nt), make([][]float64, nt), make([][]float64, nt)
+	vals := make([]*ele.IpsMap, len(rjs))
+	for t := 0; t < nt; t++ {
+		read_for_integ(t)
+		for k, rj := range rjs {
+			vals[k] = ele.NewIpsMap()
+			rj.OutIpVals(vals[k], Dom.Sol)
+		}
+		line.Tau[t], line.Slip[t], line.N[t] = make([]float64, nip), make([]float64, nip), make([]float64, nip)
+		for i, ip := range ips {
+			line.Tau[t][i] = vals[ip.k].Get("tau", ip.i)
+			line.Slip[t][i] = vals[ip.k].Get("slip", ip.i)
+			line.N[t][i] = vals[ip.k].Get("N", ip.i)
+		}
+	}
+	return
+}
+
+// bp_ip holds an integration point of a line
+type bp_ip struct {
+	s    float64   // parametric coordinate
+	x    []float64 // coordinates
+	k, i int       // index of rjoint in line and index of ip in rjoint
+}
+
+// bp_ips implements sort.Interface to sort ips by parametric coordinate
+type bp_ips []bp_ip
+
+func (o bp_ips) Len() int           { return len(o) }
+func (o bp_ips) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o bp_ips) Less(i, j int) bool { return o[i].s < o[j].s }
@@ -0,0 +1,46 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// WriteData writes the numeric data of all subplots (see Splot and Plot) to files, such that
+// the final plotting can be carried out with other tools
+//  dirout -- directory to save files; use "" for the current directory
+//  fnkey  -- filename key
+//  Output:
+//   <fnkey>.json      -- all subplots with labels, x- and y-values and coordinates of points
+//   <fnkey>_<id>.csv  -- one file per subplot with the columns: alias,xkey,ykey,x,y
+//  Note: the values are not scaled; see SplotDat.Xscale and SplotDat.Yscale
+func WriteData(dirout, fnkey string) {
+	if dirout == "" {
+		dirout = "."
+	}
+
+	// json
+	buf, err := json.MarshalIndent(Splots, "", "  ")
+	if err != nil {
+		chk.Panic("cannot marshal plot data:\n%v", err)
+	}
+	io.WriteFileVD(dirout, fnkey+".json", bytes.NewBuffer(buf))
+
+	// csv
+	for _, spl := range Splots {
+		var b bytes.Buffer
+		io.Ff(&b, "alias,xkey,ykey,x,y\n")
+		for _, d := range spl.Data {
+			for i := range d.X {
+				io.Ff(&b, "%q,%q,%q,%g,%g\n", d.Alias, d.Xlbl, d.Ylbl, d.X[i], d.Y[i])
+			}
+		}
+		io.WriteFileVD(dirout, io.Sf("%s_%s.csv", fnkey, spl.Id), &b)
+	}
+}
@@ -14,6 +14,11 @@ import (
 	"github.com/cpmech/gosl/la"
 )
 
+// ComputeExtrapolatedValues extrapolates the values at integration points of elements to their vertices
+//  Note: (1) the values of each cell are stored in ExCellVals without averaging; i.e. element-discontinuous
+//            fields. These should be used when inspecting quantities at interfaces between materials
+//        (2) the values at vertices shared by several cells are averaged and stored in ExVals;
+//            i.e. smooth fields
 func ComputeExtrapolatedValues(extrapKeys []string) {
 
 	// auxiliary
@@ -23,6 +28,7 @@ func ComputeExtrapolatedValues(extrapKeys []string) {
 	// allocate structures for extrapolation
 	nverts := len(verts)
 	ExVals = make([]map[string]float64, nverts)
+	ExCellVals = make([][]map[string]float64, len(cells))
 	counts := make([]map[string]float64, nverts)
 	for i := 0; i < nverts; i++ {
 		ExVals[i] = make(map[string]float64)
@@ -68,18 +74,24 @@ func ComputeExtrapolatedValues(extrapKeys []string) {
 
 			// perform extrapolation
 			cell := cells[element.Id()]
+			cvals := make([]map[string]float64, sha.Nverts)
+			for i := 0; i < sha.Nverts; i++ {
+				cvals[i] = make(map[string]float64)
+			}
 			for _, key := range extrapKeys {
 				if vals, ok := (*allvals)[key]; ok {
 					for i := 0; i < sha.Nverts; i++ {
 						v := cell.Verts[i]
 						for j := 0; j < len(ips); j++ {
-							ExVals[v][key] += Emat[i][j] * vals[j]
+							cvals[i][key] += Emat[i][j] * vals[j]
 						}
+						ExVals[v][key] += cvals[i][key]
 					}
 				} else {
 					chk.Panic("ip does not have key = %s", key)
 				}
 			}
+			ExCellVals[cell.Id] = cvals
 
 			// increment counter
 			for i := 0; i < sha.Nverts; i++ {
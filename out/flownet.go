@@ -0,0 +1,346 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/cpmech/gofem/ele/seepage"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/plt"
+)
+
+// Polyline holds the points of a line; e.g. streamlines and equipotential lines of flow nets
+type Polyline [][]float64 // [npoints][ndim]
+
+// Streamlines traces the streamlines of the filter (Darcy) velocity nwl passing through the seed
+// points at the selected output time with index idxI
+//  seeds -- [nseeds][ndim] points where streamlines start; e.g. points along the upstream face
+//  h     -- length of each step along streamlines
+//  nmax  -- maximum number of steps in each direction
+//  Output: one polyline per seed point, from upstream to downstream. Seed points outside the
+//          mesh give empty polylines
+//  Note: (1) LoadResults must be called first
+//        (2) the velocities are extrapolated from integration points to vertices (averaged) and
+//            interpolated within cells with the shape functions; the streamlines are integrated
+//            with the Runge-Kutta method (4th order) along the direction of velocity
+//        (3) the lines stop at the boundaries of the mesh and at stagnation points
+//        (4) ExVals and ExCellVals are recomputed with the flow keys and restored afterwards
+func Streamlines(seeds [][]float64, idxI int, h float64, nmax int) (lines []Polyline) {
+
+	// check
+	if h <= 0 || nmax < 1 {
+		chk.Panic("step length and maximum number of steps of streamlines must be positive. h = %g, nmax = %d are invalid", h, nmax)
+	}
+
+	// velocities at vertices
+	ndim := Dom.Msh.Ndim
+	keys := seepage.LiqFlowKeys(ndim)
+	exvals, excellvals := ExVals, ExCellVals
+	read_for_integ(idxI)
+	ComputeExtrapolatedValues(keys)
+	vel := make([][]float64, len(Dom.Msh.Verts))
+	for i, vals := range ExVals {
+		vel[i] = make([]float64, ndim)
+		for j, key := range keys {
+			vel[i][j] = vals[key]
+		}
+	}
+	ExVals, ExCellVals = exvals, excellvals
+
+	// trace lines
+	loc := inp.NewCellLocator(Dom.Msh, 0)
+	lines = make([]Polyline, len(seeds))
+	for k, x0 := range seeds {
+		if len(x0) != ndim {
+			chk.Panic("seed points must have %d coordinates. %v is invalid", ndim, x0)
+		}
+		if flownet_velocity(loc, vel, x0) == nil {
+			continue
+		}
+		up := flownet_trace(loc, vel, x0, -h, nmax)
+		down := flownet_trace(loc, vel, x0, h, nmax)
+		for i := len(up) - 1; i > 0; i-- {
+			lines[k] = append(lines[k], up[i])
+		}
+		lines[k] = append(lines[k], down...)
+	}
+	return
+}
+
+// Equipotentials extracts the contour lines of the liquid total head (key = "hl") or of a nodal
+// value (e.g. key = "pl") at the selected output time with index idxI
+//  levels -- values of contour lines
+//  Output: lines[nlevels][nlines] polylines of each level
+//  Note: (1) LoadResults must be called first
+//        (2) only 2D meshes are supported. The values at the corners of cells are linearly
+//            interpolated over triangles (quadrilaterals are split into two triangles)
+//        (3) the total head is hl = z + pl / (ρL0・g) with the liquid model of the simulation
+func Equipotentials(key string, levels []float64, idxI int) (lines [][]Polyline) {
+
+	// check
+	if Dom.Msh.Ndim != 2 {
+		chk.Panic("equipotential lines are only available in 2D")
+	}
+	var γl float64
+	if key == "hl" {
+		if Dom.Sim.LiqMdl == nil {
+			chk.Panic("liquid model is required to compute total heads")
+		}
+		γl = Dom.Sim.LiqMdl.R0 * Dom.Sim.LiqMdl.Grav
+	}
+
+	// values at vertices
+	read_for_integ(idxI)
+	dof := key
+	if key == "hl" {
+		dof = "pl"
+	}
+	nverts := len(Dom.Msh.Verts)
+	vals := make([]float64, nverts)
+	has := make([]bool, nverts)
+	for _, nod := range Dom.Nodes {
+		eq := nod.GetEq(dof)
+		if eq < 0 {
+			continue
+		}
+		v := nod.Vert
+		vals[v.Id] = Dom.Sol.Y[eq]
+		if key == "hl" {
+			vals[v.Id] = v.C[1] + vals[v.Id]/γl
+		}
+		has[v.Id] = true
+	}
+
+	// contour segments over triangles
+	δ := 1e-9 * math.Max(Dom.Msh.Xmax-Dom.Msh.Xmin, Dom.Msh.Ymax-Dom.Msh.Ymin)
+	lines = make([][]Polyline, len(levels))
+	var x [3][]float64
+	var f [3]float64
+	for l, level := range levels {
+		var segs [][2][]float64
+		for _, c := range Dom.Msh.Cells {
+			if c.Shp == nil || !c.IsSolid || Dom.Cid2elem[c.Id] == nil {
+				continue
+			}
+			ncorners := c.Shp.BasicNverts
+			ok := true
+			for m := 0; m < ncorners; m++ {
+				if !has[c.Verts[m]] {
+					ok = false
+				}
+			}
+			if !ok {
+				continue
+			}
+			for t := 1; t < ncorners-1; t++ {
+				for i, m := range []int{0, t, t + 1} {
+					vid := c.Verts[m]
+					x[i], f[i] = Dom.Msh.Verts[vid].C, vals[vid]
+				}
+				if p, q, found := flownet_contour_tri(x, f, level); found {
+					segs = append(segs, [2][]float64{p, q})
+				}
+			}
+		}
+		lines[l] = flownet_join(segs, δ)
+	}
+	return
+}
+
+// WritePolylines writes polylines to a file; one point per row and blank lines between polylines
+// (e.g. as required by gnuplot)
+func WritePolylines(dirout, fn string, lines []Polyline) {
+	var b bytes.Buffer
+	for k, line := range lines {
+		io.Ff(&b, "# line %d\n", k)
+		for _, p := range line {
+			for _, v := range p {
+				io.Ff(&b, "%23.15e", v)
+			}
+			io.Ff(&b, "\n")
+		}
+		io.Ff(&b, "\n")
+	}
+	io.WriteFileVD(dirout, fn, &b)
+}
+
+// PlotPolylines plots polylines (x-y plane)
+//  fm -- formatting codes; e.g. plt.Fmt{C:"blue"}
+func PlotPolylines(lines []Polyline, fm plt.Fmt) {
+	for _, line := range lines {
+		if len(line) < 2 {
+			continue
+		}
+		x := make([]float64, len(line))
+		y := make([]float64, len(line))
+		for i, p := range line {
+			x[i], y[i] = p[0], p[1]
+		}
+		plt.Plot(x, y, fm.GetArgs("clip_on=0"))
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// flownet_velocity interpolates the velocity (vel at vertices) at x. Returns nil if x is outside
+// the mesh or if the cell is not active
+func flownet_velocity(loc *inp.CellLocator, vel [][]float64, x []float64) (v []float64) {
+	c, r := loc.Find(x)
+	if c == nil || Dom.Cid2elem[c.Id] == nil {
+		return nil
+	}
+	sh := c.Shp
+	sh.Func(sh.S, sh.DSdR, r, false, -1)
+	v = make([]float64, len(x))
+	for m := 0; m < sh.Nverts; m++ {
+		for i := range v {
+			v[i] += sh.S[m] * vel[c.Verts[m]][i]
+		}
+	}
+	return
+}
+
+// flownet_direction returns the unit direction of velocity at x; nil if outside or stagnant
+func flownet_direction(loc *inp.CellLocator, vel [][]float64, x []float64) (d []float64) {
+	d = flownet_velocity(loc, vel, x)
+	if d == nil {
+		return
+	}
+	var norm float64
+	for _, v := range d {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	if norm < 1e-14 {
+		return nil
+	}
+	for i := range d {
+		d[i] /= norm
+	}
+	return
+}
+
+// flownet_trace integrates a streamline from x0 with steps h (negative => upstream)
+func flownet_trace(loc *inp.CellLocator, vel [][]float64, x0 []float64, h float64, nmax int) (line Polyline) {
+	ndim := len(x0)
+	x := append([]float64{}, x0...)
+	line = Polyline{append([]float64{}, x...)}
+	y := make([]float64, ndim)
+	stage := func(d []float64, α float64) []float64 {
+		for i := 0; i < ndim; i++ {
+			y[i] = x[i] + α*h*d[i]
+		}
+		return flownet_direction(loc, vel, y)
+	}
+	for step := 0; step < nmax; step++ {
+		k1 := flownet_direction(loc, vel, x)
+		if k1 == nil {
+			return
+		}
+		k2 := stage(k1, 0.5)
+		if k2 == nil {
+			return
+		}
+		k3 := stage(k2, 0.5)
+		if k3 == nil {
+			return
+		}
+		k4 := stage(k3, 1.0)
+		if k4 == nil {
+			return
+		}
+		for i := 0; i < ndim; i++ {
+			x[i] += h * (k1[i] + 2.0*k2[i] + 2.0*k3[i] + k4[i]) / 6.0
+		}
+		line = append(line, append([]float64{}, x...))
+	}
+	return
+}
+
+// flownet_contour_tri finds the segment (p, q) of the contour line f = level over a linear
+// triangle with vertices x and values f
+func flownet_contour_tri(x [3][]float64, f [3]float64, level float64) (p, q []float64, found bool) {
+	var pts [][]float64
+	for i := 0; i < 3; i++ {
+		j := (i + 1) % 3
+		a, b := f[i]-level, f[j]-level
+		if a == 0 {
+			a = 1e-300 // values equal to level are considered above level
+		}
+		if b == 0 {
+			b = 1e-300
+		}
+		if a*b > 0 {
+			continue
+		}
+		s := a / (a - b)
+		pts = append(pts, []float64{x[i][0] + s*(x[j][0]-x[i][0]), x[i][1] + s*(x[j][1]-x[i][1])})
+	}
+	if len(pts) != 2 {
+		return
+	}
+	return pts[0], pts[1], true
+}
+
+// flownet_join joins segments with coincident ends (within tolerance δ) into polylines
+func flownet_join(segs [][2][]float64, δ float64) (lines []Polyline) {
+	key := func(p []float64) [2]int64 {
+		return [2]int64{int64(math.Floor(p[0]/δ + 0.5)), int64(math.Floor(p[1]/δ + 0.5))}
+	}
+	ends := make(map[[2]int64][]int)
+	for k, s := range segs {
+		ends[key(s[0])] = append(ends[key(s[0])], k)
+		ends[key(s[1])] = append(ends[key(s[1])], k)
+	}
+	used := make([]bool, len(segs))
+	next := func(p []float64) (k int, q []float64) {
+		for _, k = range ends[key(p)] {
+			if used[k] {
+				continue
+			}
+			used[k] = true
+			if key(segs[k][0]) == key(p) {
+				return k, segs[k][1]
+			}
+			return k, segs[k][0]
+		}
+		return -1, nil
+	}
+	for k, s := range segs {
+		if used[k] {
+			continue
+		}
+		used[k] = true
+		line := Polyline{s[0], s[1]}
+		for { // forwards
+			j, q := next(line[len(line)-1])
+			if j < 0 {
+				break
+			}
+			line = append(line, q)
+		}
+		var back Polyline
+		for { // backwards
+			p := s[0]
+			if len(back) > 0 {
+				p = back[len(back)-1]
+			}
+			j, q := next(p)
+			if j < 0 {
+				break
+			}
+			back = append(back, q)
+		}
+		for i, j := 0, len(back)-1; i < j; i, j = i+1, j-1 {
+			back[i], back[j] = back[j], back[i]
+		}
+		lines = append(lines, append(back, line...))
+	}
+	return
+}
@@ -0,0 +1,308 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"math"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// FrameVectors holds the prefixes of vector keys transformed by frames; e.g. "u" => "ux", "uy", "uz"
+// and "r" => "rx", "ry", "rz" (rotations; 3D only)
+var FrameVectors = []string{"u", "r", "nwl", "nwg"}
+
+// FrameTensors holds the prefixes of (symmetric) tensor keys transformed by frames; e.g.
+// "s" => "sx", "sy", "sz", "sxy", "syz", "szx" (Mandel components)
+var FrameTensors = []string{"s"}
+
+// Frame defines a local, cylindrical or spherical frame to which vectors and tensors are transformed
+//  Note: (1) the components of the new frame are named (suffixes of keys):
+//             "local" => "1", "2", "3"; e.g. "u1", "s12"
+//             "cyl"   => "r", "t", "a" (radial, tangential, axial); e.g. "ur", "srt"
+//             "sph"   => "r", "t", "p" (radial, polar, azimuthal); e.g. "sr", "stp"
+//        (2) tensors are given and returned with Mandel components; i.e. the shear components
+//            multiplied by √2, as with the stresses output by elements
+//        (3) in 2D, the third axis is always z; thus "cyl" is a polar frame and "sph" is not available
+//        (4) the base vectors of "cyl" and "sph" frames depend on the position; e.g. the radial
+//            stress around a tunnel is obtained with a "cyl" frame with origin at the tunnel centre
+type Frame struct {
+	Type   string      // "local", "cyl" or "sph"
+	Origin []float64   // origin; e.g. point on axis of cylinder or centre of sphere
+	Axis   []float64   // local: direction of 1st axis; cyl: direction of axis; sph: polar axis. default = z
+	Ref    []float64   // local 3D: vector on the 1-2 plane. may be nil
+	Comps  []string    // names of components; e.g. "r", "t", "a"
+	ndim   int         // space dimension
+	e      [][]float64 // base vectors
+	q      [][]float64 // workspace: transformed tensor
+	a, b   [][]float64 // workspace
+}
+
+// NewFrame returns a new frame
+//  typ    -- "local", "cyl" or "sph"
+//  ndim   -- space dimension
+//  origin -- origin of frame. may be nil => origin = {0,0,0}
+//  axis   -- see Frame.Axis. may be nil
+//  ref    -- see Frame.Ref. may be nil
+func NewFrame(typ string, ndim int, origin, axis, ref []float64) (o *Frame, err error) {
+
+	// frame
+	o = new(Frame)
+	o.Type = typ
+	o.ndim = ndim
+	o.Origin = make([]float64, 3)
+	o.Axis = []float64{0, 0, 1}
+	copy(o.Origin, origin)
+	if axis != nil {
+		o.Axis = make([]float64, 3)
+		copy(o.Axis, axis)
+		if ndim == 2 {
+			o.Axis[2] = 0
+		}
+	}
+	switch typ {
+	case "local":
+		o.Comps = []string{"1", "2", "3"}
+		if axis == nil {
+			o.Axis = []float64{1, 0, 0}
+		}
+	case "cyl":
+		o.Comps = []string{"r", "t", "a"}
+		if ndim == 2 {
+			o.Axis = []float64{0, 0, 1}
+		}
+	case "sph":
+		o.Comps = []string{"r", "t", "p"}
+		if ndim == 2 {
+			return nil, chk.Err("spherical frames are not available in 2D")
+		}
+	default:
+		return nil, chk.Err("frame type %q is not available; options are \"local\", \"cyl\" or \"sph\"", typ)
+	}
+	if frame_norm(o.Axis) < 1e-14 {
+		return nil, chk.Err("axis of %q frame must not be zero. axis = %v", typ, axis)
+	}
+	frame_unit(o.Axis)
+
+	// workspace
+	o.e = [][]float64{make([]float64, 3), make([]float64, 3), make([]float64, 3)}
+	o.q, o.a, o.b = frame_alloc(), frame_alloc(), frame_alloc()
+
+	// local frame: fixed base vectors
+	if typ == "local" {
+		copy(o.e[0], o.Axis)
+		if ndim == 2 {
+			o.e[1][0], o.e[1][1], o.e[2][2] = -o.e[0][1], o.e[0][0], 1
+			return
+		}
+		if ref != nil {
+			o.Ref = make([]float64, 3)
+			copy(o.Ref, ref)
+		}
+		err = frame_perp(o.e[1], o.e[0], o.Ref)
+		if err != nil {
+			return nil, err
+		}
+		frame_cross(o.e[2], o.e[0], o.e[1])
+	}
+	return
+}
+
+// ParseFrame parses the definition of a frame given as "type;origin;axis;ref", where origin, axis
+// and ref are lists of numbers separated by spaces. origin, axis and ref may be omitted
+//  Examples: "cyl;5 0 0" (polar frame around (5,0)), "sph;0 0 0;0 1 0", "local;0 0;1 1"
+func ParseFrame(spec string, ndim int) (o *Frame, err error) {
+	res := strings.Split(spec, ";")
+	vecs := make([][]float64, 3)
+	for i := 1; i < len(res) && i < 4; i++ {
+		for _, s := range strings.Fields(res[i]) {
+			vecs[i-1] = append(vecs[i-1], io.Atof(s))
+		}
+	}
+	return NewFrame(strings.TrimSpace(res[0]), ndim, vecs[0], vecs[1], vecs[2])
+}
+
+// Basis computes the base vectors of frame at point x. e[i] is the i-th unit vector
+//  Note: e is internal to Frame; i.e. it is overwritten by the next call
+func (o *Frame) Basis(x []float64) (e [][]float64) {
+	e = o.e
+	if o.Type == "local" {
+		return
+	}
+	d := make([]float64, 3)
+	for i := 0; i < o.ndim; i++ {
+		d[i] = x[i] - o.Origin[i]
+	}
+	switch o.Type {
+	case "cyl":
+		// radial direction: d minus its projection onto the axis
+		dot := frame_dot(d, o.Axis)
+		for i := 0; i < 3; i++ {
+			e[0][i] = d[i] - dot*o.Axis[i]
+		}
+		if frame_norm(e[0]) < 1e-14 { // point on axis
+			frame_perp(e[0], o.Axis, nil)
+		}
+		frame_unit(e[0])
+		copy(e[2], o.Axis)
+		frame_cross(e[1], e[2], e[0])
+	case "sph":
+		copy(e[0], d)
+		if frame_norm(e[0]) < 1e-14 { // point at centre
+			copy(e[0], o.Axis)
+		}
+		frame_unit(e[0])
+		frame_cross(e[2], o.Axis, e[0])
+		if frame_norm(e[2]) < 1e-14 { // point on polar axis
+			frame_perp(e[2], e[0], nil)
+		}
+		frame_unit(e[2])
+		frame_cross(e[1], e[2], e[0])
+	}
+	return
+}
+
+// Vector transforms the vector v (3 components) at point x: w = Q・v, where Q[i] = e[i]
+func (o *Frame) Vector(w, v, x []float64) {
+	e := o.Basis(x)
+	for i := 0; i < 3; i++ {
+		w[i] = frame_dot(e[i], v)
+	}
+}
+
+// Tensor transforms the symmetric tensor with Mandel components s (6 components) at point x:
+// t = Q・s・Qᵀ, where Q[i] = e[i]
+func (o *Frame) Tensor(t, s, x []float64) {
+	e := o.Basis(x)
+	frame_m2t(o.a, s)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.b[i][j] = 0
+			for k := 0; k < 3; k++ {
+				o.b[i][j] += o.a[i][k] * e[j][k]
+			}
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.q[i][j] = 0
+			for k := 0; k < 3; k++ {
+				o.q[i][j] += e[i][k] * o.b[k][j]
+			}
+		}
+	}
+	sq2 := math.Sqrt2
+	t[0], t[1], t[2] = o.q[0][0], o.q[1][1], o.q[2][2]
+	t[3], t[4], t[5] = o.q[0][1]*sq2, o.q[1][2]*sq2, o.q[2][0]*sq2
+}
+
+// VectorKeys returns the keys of the transformed components of vector; e.g. "u" => "ur", "ut", "ua"
+func (o *Frame) VectorKeys(prefix string) []string {
+	c := o.Comps
+	return []string{prefix + c[0], prefix + c[1], prefix + c[2]}
+}
+
+// TensorKeys returns the keys of the transformed components of tensor; e.g. "s" => "sr", "st", "sa",
+// "srt", "sta", "sar"
+func (o *Frame) TensorKeys(prefix string) []string {
+	c := o.Comps
+	return []string{prefix + c[0], prefix + c[1], prefix + c[2], prefix + c[0] + c[1], prefix + c[1] + c[2], prefix + c[2] + c[0]}
+}
+
+// AddDerived adds the transformed components of vectors (FrameVectors) and tensors (FrameTensors)
+// found in vals to vals; e.g. with a "cyl" frame, "ux" and "uy" @ x => "ur", "ut" and "ua"
+//  Note: in 2D, only the in-plane components of vectors and the 4 components of tensors are added
+func (o *Frame) AddDerived(vals map[string]float64, x []float64) {
+	v, w := make([]float64, 6), make([]float64, 6)
+	xyz := []string{"x", "y", "z"}
+	for _, prefix := range FrameVectors {
+		if _, ok := vals[prefix+"x"]; !ok {
+			continue
+		}
+		for i := 0; i < 3; i++ {
+			v[i] = vals[prefix+xyz[i]]
+		}
+		o.Vector(w, v, x)
+		for i, key := range o.VectorKeys(prefix)[:o.ndim] {
+			vals[key] = w[i]
+		}
+	}
+	for _, prefix := range FrameTensors {
+		if _, ok := vals[prefix+"xy"]; !ok {
+			continue
+		}
+		for i, key := range []string{"x", "y", "z", "xy", "yz", "zx"} {
+			v[i] = vals[prefix+key]
+		}
+		o.Tensor(w, v, x)
+		for i, key := range o.TensorKeys(prefix)[:2*o.ndim] {
+			vals[key] = w[i]
+		}
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// frame_alloc allocates a 3x3 matrix
+func frame_alloc() [][]float64 {
+	return [][]float64{make([]float64, 3), make([]float64, 3), make([]float64, 3)}
+}
+
+// frame_m2t converts Mandel components to a 3x3 tensor
+func frame_m2t(a [][]float64, m []float64) {
+	sq2 := math.Sqrt2
+	a[0][0], a[1][1], a[2][2] = m[0], m[1], m[2]
+	a[0][1], a[1][2], a[2][0] = m[3]/sq2, m[4]/sq2, m[5]/sq2
+	a[1][0], a[2][1], a[0][2] = a[0][1], a[1][2], a[2][0]
+}
+
+// frame_dot returns u・v
+func frame_dot(u, v []float64) float64 {
+	return u[0]*v[0] + u[1]*v[1] + u[2]*v[2]
+}
+
+// frame_norm returns |u|
+func frame_norm(u []float64) float64 {
+	return math.Sqrt(frame_dot(u, u))
+}
+
+// frame_unit normalises u
+func frame_unit(u []float64) {
+	n := frame_norm(u)
+	u[0], u[1], u[2] = u[0]/n, u[1]/n, u[2]/n
+}
+
+// frame_cross computes w = u × v
+func frame_cross(w, u, v []float64) {
+	w[0] = u[1]*v[2] - u[2]*v[1]
+	w[1] = u[2]*v[0] - u[0]*v[2]
+	w[2] = u[0]*v[1] - u[1]*v[0]
+}
+
+// frame_perp computes a unit vector w perpendicular to the unit vector u from the reference
+// vector ref (Gram-Schmidt). If ref is nil, the global axis least aligned with u is used
+func frame_perp(w, u, ref []float64) (err error) {
+	if ref == nil {
+		k := 0
+		for i := 1; i < 3; i++ {
+			if math.Abs(u[i]) < math.Abs(u[k]) {
+				k = i
+			}
+		}
+		ref = make([]float64, 3)
+		ref[k] = 1
+	}
+	dot := frame_dot(ref, u)
+	for i := 0; i < 3; i++ {
+		w[i] = ref[i] - dot*u[i]
+	}
+	if frame_norm(w) < 1e-14 {
+		return chk.Err("reference vector %v must not be parallel to axis %v", ref, u)
+	}
+	frame_unit(w)
+	return
+}
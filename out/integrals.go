@@ -0,0 +1,115 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"github.com/cpmech/gosl/chk"
+)
+
+// FaceForce computes the total force (traction integrated) on faces with tag (ftag) for all
+// selected output times. Output: F[ntimes][ndim]
+//  Note: LoadResults must be called first. See fem.Domain.IntegFaceForce
+func FaceForce(ftag int) (F [][]float64) {
+	F = make([][]float64, len(TimeInds))
+	for i := range TimeInds {
+		read_for_integ(i)
+		var err error
+		F[i], err = Dom.IntegFaceForce(ftag)
+		if err != nil {
+			chk.Panic("cannot integrate force on faces with tag = %d:\n%v", ftag, err)
+		}
+	}
+	return
+}
+
+// FaceFlow computes the total flow rate of liquid across faces with tag (ftag) for all selected
+// output times
+//  Note: LoadResults must be called first. See fem.Domain.IntegFaceFlow
+func FaceFlow(ftag int) (res []float64) {
+	res = make([]float64, len(TimeInds))
+	for i := range TimeInds {
+		read_for_integ(i)
+		var err error
+		res[i], err = Dom.IntegFaceFlow(ftag)
+		if err != nil {
+			chk.Panic("cannot integrate flow rate across faces with tag = %d:\n%v", ftag, err)
+		}
+	}
+	return
+}
+
+// FaceFluxRecovered computes the total flux of dof (key) across faces with tag (ftag) recovered
+// from the residual of the elements for all selected output times; e.g. the outflow with "pl"
+//  Note: LoadResults must be called first. See fem.Domain.RecoverFaceFlux. The storage terms of
+//        transient simulations are computed with the star variables of the last step only;
+//        thus, this function is appropriate for steady states
+func FaceFluxRecovered(key string, ftag int) (res []float64) {
+	res = make([]float64, len(TimeInds))
+	for i := range TimeInds {
+		read_for_integ(i)
+		flux, err := Dom.RecoverFaceFlux(ftag, key)
+		if err != nil {
+			chk.Panic("cannot recover flux of %q across faces with tag = %d:\n%v", key, ftag, err)
+		}
+		res[i] = flux.Total
+	}
+	return
+}
+
+// FaceAverage computes the average of dof (key) over faces with tag (ftag) for all selected output
+// times; e.g. the average settlement of a foundation with key = "uy"
+//  Note: LoadResults must be called first. See fem.Domain.IntegFaceDof
+func FaceAverage(key string, ftag int) (res []float64) {
+	res = make([]float64, len(TimeInds))
+	for i := range TimeInds {
+		read_for_integ(i)
+		integ, area, err := Dom.IntegFaceDof(ftag, key)
+		if err != nil || area <= 0 {
+			chk.Panic("cannot compute average of %q over faces with tag = %d:\n%v", key, ftag, err)
+		}
+		res[i] = integ / area
+	}
+	return
+}
+
+// CellsInteg computes the integral of the integration point value (key) over the cells with tag
+// for all selected output times
+//  Note: LoadResults must be called first. See fem.Domain.IntegCells
+func CellsInteg(key string, tag int) (res []float64) {
+	res = make([]float64, len(TimeInds))
+	for i := range TimeInds {
+		read_for_integ(i)
+		var err error
+		res[i], _, err = Dom.IntegCells(tag, key)
+		if err != nil {
+			chk.Panic("cannot integrate %q over cells with tag = %d:\n%v", key, tag, err)
+		}
+	}
+	return
+}
+
+// CellsVolAbove computes the volume of the cells with tag where the integration point value (key)
+// is greater than thres for all selected output times; e.g. the volume of plastified material
+//  Note: LoadResults must be called first. See fem.Domain.IntegCellsAbove
+func CellsVolAbove(key string, tag int, thres float64) (res []float64) {
+	res = make([]float64, len(TimeInds))
+	for i := range TimeInds {
+		read_for_integ(i)
+		var err error
+		res[i], err = Dom.IntegCellsAbove(tag, key, thres)
+		if err != nil {
+			chk.Panic("cannot compute volume of cells with tag = %d where %q > %g:\n%v", tag, key, thres, err)
+		}
+	}
+	return
+}
+
+// read_for_integ reads the results corresponding to the selected output time with index idxI
+func read_for_integ(idxI int) {
+	err := Dom.Read(Sum, TimeInds[idxI], 0, true)
+	if err != nil {
+		chk.Panic("cannot load results into domain:\n%v", err)
+	}
+}
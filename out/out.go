@@ -47,6 +47,7 @@ var (
 	IpsMin     []float64          // [ndim] {x,y,z}_min among all ips
 	IpsMax     []float64          // [ndim] {x,y,z}_max among all ips
 	Beams      []*solid.Beam      // beams, if any
+	Rjoints    []*solid.Rjoint    // rod-joints, if any
 	ElemOutIps []ele.CanOutputIps // subset of element that can output IP values
 
 	// defined entities and results loaded by LoadResults
@@ -56,8 +57,12 @@ var (
 	Times    []float64             // selected output times
 
 	// extrapolated values
-	Extrap []string             // keys to be extrapolated; e.g. []string{"nwlx", "nwly"}
-	ExVals []map[string]float64 // [nverts][nkeys] extrapolated values
+	Extrap     []string               // keys to be extrapolated; e.g. []string{"nwlx", "nwly"}
+	ExVals     []map[string]float64   // [nverts][nkeys] extrapolated values (averaged at vertices)
+	ExCellVals [][]map[string]float64 // [ncells][ncverts][nkeys] extrapolated values at vertices of each cell (not averaged)
+
+	// transformation of vectors and tensors
+	Frm *Frame // frame to which results are transformed; e.g. "cyl" => "ur", "srt". may be nil
 
 	// subplots
 	Splots []*SplotDat // all subplots
@@ -95,6 +100,7 @@ func Start(simfnpath string, stageIdx, regionIdx int) {
 	Times = make([]float64, 0)
 	Splots = make([]*SplotDat, 0)
 	Beams = make([]*solid.Beam, 0)
+	Rjoints = make([]*solid.Rjoint, 0)
 	ElemOutIps = make([]ele.CanOutputIps, 0)
 
 	// bins
@@ -180,5 +186,10 @@ func Start(simfnpath string, stageIdx, regionIdx int) {
 		if beam, ok := element.(*solid.Beam); ok {
 			Beams = append(Beams, beam)
 		}
+
+		// find rod-joints
+		if rjoint, ok := element.(*solid.Rjoint); ok {
+			Rjoints = append(Rjoints, rjoint)
+		}
 	}
 }
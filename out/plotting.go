@@ -16,27 +16,28 @@ import (
 
 // PltEntity stores all data for a plot entity (X vs Y)
 type PltEntity struct {
-	Alias string    // alias
-	X     []float64 // x-values
-	Y     []float64 // y-values
-	Xlbl  string    // horizontal axis label (raw; e.g. "t")
-	Ylbl  string    // vertical axis label (raw; e.g. "pl")
-	Style plt.Fmt   // style
+	Alias  string      `json:"alias"`  // alias
+	X      []float64   `json:"x"`      // x-values
+	Y      []float64   `json:"y"`      // y-values
+	Xlbl   string      `json:"xkey"`   // horizontal axis label (raw; e.g. "t")
+	Ylbl   string      `json:"ykey"`   // vertical axis label (raw; e.g. "pl")
+	Coords [][]float64 `json:"coords"` // coordinates of all points with alias; nil if not available
+	Style  plt.Fmt     `json:"-"`      // style
 }
 
 // SplotDat stores all data for one subplot
 type SplotDat struct {
-	Id      string       // unique identifier
-	Title   string       // title of subplot
-	Topts   string       // title options
-	Xscale  float64      // x-axis scale
-	Yscale  float64      // y-axis scale
-	Xrange  []float64    // x range
-	Yrange  []float64    // x range
-	Xlbl    string       // x-axis label (formatted; e.g. "$t$")
-	Ylbl    string       // y-axis label (formatted; e.g. "$p_{\ell}$")
-	GllArgs string       // extra arguments for Gll such as leg_out
-	Data    []*PltEntity // data and styles to be plotted
+	Id      string       `json:"id"`               // unique identifier
+	Title   string       `json:"title"`            // title of subplot
+	Topts   string       `json:"-"`                // title options
+	Xscale  float64      `json:"xscale"`           // x-axis scale
+	Yscale  float64      `json:"yscale"`           // y-axis scale
+	Xrange  []float64    `json:"xrange,omitempty"` // x range
+	Yrange  []float64    `json:"yrange,omitempty"` // x range
+	Xlbl    string       `json:"xlabel"`           // x-axis label (formatted; e.g. "$t$")
+	Ylbl    string       `json:"ylabel"`           // y-axis label (formatted; e.g. "$p_{\ell}$")
+	GllArgs string       `json:"-"`                // extra arguments for Gll such as leg_out
+	Data    []*PltEntity `json:"data"`             // data and styles to be plotted
 }
 
 // Splot activates a new subplot window
@@ -73,6 +74,12 @@ func Plot(xHandle, yHandle interface{}, alias string, fm plt.Fmt, idxI int) {
 	e.Style = fm
 	e.X, e.Xlbl = get_vals_and_labels(xHandle, yHandle, alias, idxI)
 	e.Y, e.Ylbl = get_vals_and_labels(yHandle, xHandle, alias, idxI)
+	if pts, ok := Results[alias]; ok {
+		e.Coords = make([][]float64, len(pts))
+		for i, p := range pts {
+			e.Coords[i] = p.X
+		}
+	}
 	if len(e.X) != len(e.Y) {
 		chk.Panic("lengths of x- and y-series are different. len(x)=%d, len(y)=%d, x=%v, y=%v", len(e.X), len(e.Y), xHandle, yHandle)
 	}
@@ -90,6 +97,7 @@ func Plot(xHandle, yHandle interface{}, alias string, fm plt.Fmt, idxI int) {
 //  nc     -- number of columns. Use -1 to compute best value
 //  split  -- split subplots into separated figures
 //  extra  -- is called just after Subplot command and before any plotting
+//  Note: if fname is given, the numeric data is also saved with WriteData
 func Draw(dirout, fname string, nr, nc int, split bool, extra func(id string)) {
 	var fnk string // filename key
 	var ext string // extension
@@ -142,6 +150,9 @@ func Draw(dirout, fname string, nr, nc int, split bool, extra func(id string)) {
 	if !split && fname != "" {
 		savefig(dirout, fnk, ext, "")
 	}
+	if fname != "" {
+		WriteData(dirout, fnk)
+	}
 	if fname == "" {
 		plt.Show()
 	}
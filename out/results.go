@@ -93,6 +93,11 @@ func LoadResults(times []float64) {
 					Ipoints[ipid].Vals[key] = vals[i]
 				}
 			}
+			if Frm != nil {
+				for _, ipid := range ipids {
+					Frm.AddDerived(Ipoints[ipid].Vals, Ipoints[ipid].X)
+				}
+			}
 		}
 
 		// for each point
@@ -106,20 +111,29 @@ func LoadResults(times []float64) {
 				// handle node
 				if vid >= 0 {
 
-					// add dofs to results map
+					// collect dofs and extrapolated values
 					nod := Dom.Vid2node[vid]
+					vals := make(map[string]float64)
 					for _, dof := range nod.Dofs {
 						if dof != nil {
-							utl.StrDblsMapAppend(&p.Vals, dof.Key, Dom.Sol.Y[dof.Eq])
+							vals[dof.Key] = Dom.Sol.Y[dof.Eq]
 						}
 					}
-
-					// add extrapolated values to results map
 					if ExVals != nil {
 						for key, val := range ExVals[vid] {
-							utl.StrDblsMapAppend(&p.Vals, key, val)
+							vals[key] = val
 						}
 					}
+
+					// transformed values
+					if Frm != nil {
+						Frm.AddDerived(vals, nod.Vert.C)
+					}
+
+					// add values to results map
+					for key, val := range vals {
+						utl.StrDblsMapAppend(&p.Vals, key, val)
+					}
 				}
 
 				// handle integration point
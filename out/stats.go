@@ -0,0 +1,124 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// RegionStat holds the statistics of a key over a material region at one output time
+type RegionStat struct {
+	Time float64   // output time
+	Min  float64   // minimum value
+	Max  float64   // maximum value
+	Mean float64   // mean value over nodes or integration points
+	Xmin []float64 // coordinates of node or integration point with minimum value
+	Xmax []float64 // coordinates of node or integration point with maximum value
+}
+
+// RegionStats computes the minimum, maximum and mean values of key over the cells with tag (material
+// region) for all selected output times; e.g. the maximum excess pore-water pressure in a layer.
+// The results are also written to "<dirout>/<fnkey>_stats_<key>_<tag>.csv"
+//  Note: (1) LoadResults must be called first
+//        (2) key can be a dof (e.g. "uy", "pl"), computed at nodes, or an integration point
+//            value (e.g. "sx", "alpha"), computed at the integration points of elements
+//        (3) the mean value is the arithmetic mean over nodes or integration points; i.e. it
+//            is not weighted by volume. See CellsInteg for integrals over cells
+func RegionStats(tag int, key string) (res []*RegionStat) {
+
+	// check key
+	_, isdof := Dom.Dof2Tnum[key]
+	if !isdof && !Ipkeys[key] {
+		chk.Panic("cannot compute statistics of %q: key is neither a dof nor an integration point value", key)
+	}
+
+	// for each selected output time
+	res = make([]*RegionStat, len(TimeInds))
+	for i := range TimeInds {
+		read_for_integ(i)
+		s := &RegionStat{Time: Times[i], Min: math.Inf(1), Max: math.Inf(-1)}
+		var n int
+		add := func(v float64, x []float64) {
+			if v < s.Min {
+				s.Min, s.Xmin = v, x
+			}
+			if v > s.Max {
+				s.Max, s.Xmax = v, x
+			}
+			s.Mean += v
+			n++
+		}
+		if isdof {
+			added := make(map[int]bool)
+			for _, c := range Dom.Msh.Cells {
+				if c.Tag != tag || Dom.Cid2elem[c.Id] == nil {
+					continue
+				}
+				for _, vid := range c.Verts {
+					nod := Dom.Vid2node[vid]
+					if added[vid] || nod == nil {
+						continue
+					}
+					eq := nod.GetEq(key)
+					if eq < 0 {
+						continue
+					}
+					added[vid] = true
+					add(Dom.Sol.Y[eq], nod.Vert.C)
+				}
+			}
+		} else {
+			for _, e := range ElemOutIps {
+				if Dom.Msh.Cells[e.Id()].Tag != tag {
+					continue
+				}
+				allvals := ele.NewIpsMap()
+				e.OutIpVals(allvals, Dom.Sol)
+				vals, ok := (*allvals)[key]
+				if !ok {
+					continue
+				}
+				for j, ipid := range Cid2ips[e.Id()] {
+					add(vals[j], Ipoints[ipid].X)
+				}
+			}
+		}
+		if n == 0 {
+			chk.Panic("cannot find values of %q in cells with tag = %d", key, tag)
+		}
+		s.Mean /= float64(n)
+		res[i] = s
+	}
+
+	// write file
+	var b bytes.Buffer
+	io.Ff(&b, "time,min")
+	for _, c := range "xyz"[:Dom.Msh.Ndim] {
+		io.Ff(&b, ",%cmin", c)
+	}
+	io.Ff(&b, ",max")
+	for _, c := range "xyz"[:Dom.Msh.Ndim] {
+		io.Ff(&b, ",%cmax", c)
+	}
+	io.Ff(&b, ",mean\n")
+	for _, s := range res {
+		io.Ff(&b, "%g,%g", s.Time, s.Min)
+		for _, x := range s.Xmin {
+			io.Ff(&b, ",%g", x)
+		}
+		io.Ff(&b, ",%g", s.Max)
+		for _, x := range s.Xmax {
+			io.Ff(&b, ",%g", x)
+		}
+		io.Ff(&b, ",%g\n", s.Mean)
+	}
+	io.WriteFileVD(Dom.Sim.DirOut, io.Sf("%s_stats_%s_%d.csv", Dom.Sim.Key, key, tag), &b)
+	return
+}
@@ -31,6 +31,12 @@ func GetTexLabel(key, unit string) string {
 		l += "u_y"
 	case "uz":
 		l += "u_z"
+	case "rx":
+		l += "\\theta_x"
+	case "ry":
+		l += "\\theta_y"
+	case "rz":
+		l += "\\theta_z"
 	case "sl":
 		l += "s_{\\ell}"
 	case "sg":
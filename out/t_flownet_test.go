@@ -0,0 +1,60 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_flownet01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("flownet01. contour lines over triangles")
+
+	// unit square split into two triangles; f = x
+	X := [][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	tris := [][]int{{0, 1, 2}, {0, 2, 3}}
+	var segs [][2][]float64
+	var x [3][]float64
+	var f [3]float64
+	for _, t := range tris {
+		for i, m := range t {
+			x[i], f[i] = X[m], X[m][0]
+		}
+		p, q, found := flownet_contour_tri(x, f, 0.5)
+		if !found {
+			tst.Errorf("contour segment must be found in triangle %v\n", t)
+			return
+		}
+		segs = append(segs, [2][]float64{p, q})
+	}
+
+	// joined line
+	lines := flownet_join(segs, 1e-9)
+	if len(lines) != 1 {
+		tst.Errorf("segments must be joined into one line. %d lines found\n", len(lines))
+		return
+	}
+	if len(lines[0]) != 3 {
+		tst.Errorf("line must have 3 points. %d points found\n", len(lines[0]))
+		return
+	}
+	for i, p := range lines[0] {
+		chk.Scalar(tst, io.Sf("x%d", i), 1e-15, p[0], 0.5)
+	}
+	y0, y2 := lines[0][0][1], lines[0][2][1]
+	if !((y0 == 0 && y2 == 1) || (y0 == 1 && y2 == 0)) {
+		tst.Errorf("ends of line must be at y = 0 and y = 1. y0 = %g, y2 = %g\n", y0, y2)
+	}
+
+	// level outside range
+	_, _, found := flownet_contour_tri(x, f, 2)
+	if found {
+		tst.Errorf("contour segment must not be found with level outside the range of values\n")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_frame01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("frame01")
+
+	// polar frame in 2D
+	frm, err := ParseFrame("cyl;0 0", 2)
+	if err != nil {
+		tst.Errorf("ParseFrame failed:\n%v", err)
+		return
+	}
+	vals := map[string]float64{"ux": 1, "uy": 0, "sx": -2, "sy": -1, "sz": 0, "sxy": 0}
+	frm.AddDerived(vals, []float64{0, 1})
+	chk.Scalar(tst, "ur", 1e-15, vals["ur"], 0)
+	chk.Scalar(tst, "ut", 1e-15, vals["ut"], -1)
+	chk.Scalar(tst, "sr", 1e-15, vals["sr"], -1)
+	chk.Scalar(tst, "st", 1e-15, vals["st"], -2)
+	chk.Scalar(tst, "sa", 1e-15, vals["sa"], 0)
+	chk.Scalar(tst, "srt", 1e-15, vals["srt"], 0)
+	if _, ok := vals["ua"]; ok {
+		tst.Errorf("axial component of vector must not be added in 2D")
+	}
+
+	// spherical frame is not available in 2D
+	_, err = NewFrame("sph", 2, nil, nil, nil)
+	if err == nil {
+		tst.Errorf("NewFrame should have failed with spherical frame in 2D")
+	}
+}
+
+func Test_frame02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("frame02")
+
+	// spherical frame @ equator
+	frm, err := NewFrame("sph", 3, nil, nil, nil)
+	if err != nil {
+		tst.Errorf("NewFrame failed:\n%v", err)
+		return
+	}
+	e := frm.Basis([]float64{2, 0, 0})
+	chk.Vector(tst, "er", 1e-15, e[0], []float64{1, 0, 0})
+	chk.Vector(tst, "et", 1e-15, e[1], []float64{0, 0, -1})
+	chk.Vector(tst, "ep", 1e-15, e[2], []float64{0, 1, 0})
+
+	// local frame rotated by 45° around z
+	frm, err = NewFrame("local", 3, nil, []float64{1, 1, 0}, nil)
+	if err != nil {
+		tst.Errorf("NewFrame failed:\n%v", err)
+		return
+	}
+	t := make([]float64, 6)
+	frm.Tensor(t, []float64{1, 0, 0, 0, 0, 0}, nil)
+	chk.Vector(tst, "t", 1e-15, t, []float64{0.5, 0, 0.5, 0, 0, math.Sqrt2 * 0.5})
+
+	// reference vector parallel to axis
+	_, err = NewFrame("local", 3, nil, []float64{1, 0, 0}, []float64{2, 0, 0})
+	if err == nil {
+		tst.Errorf("NewFrame should have failed with reference vector parallel to axis")
+	}
+}
@@ -194,6 +194,7 @@ func Test_out02(tst *testing.T) {
 	Define("ips", Along{{xip[0], 0}, {xip[0], 1}})
 
 	// load results
+	Extrap = []string{"sx", "sy"}
 	LoadResults(nil)
 
 	// solution
@@ -213,4 +214,126 @@ func Test_out02(tst *testing.T) {
 		io.Pfyel("t=%g\n", t)
 		sol.CheckDispl(tst, t, []float64{ux[j], uy[j]}, x, tolu)
 	}
+
+	// check extrapolated values (last time) at vertices shared by the two cells: 2 and 3
+	tolσ := 1e-12
+	σx, σy, _, _, _ := sol.Solution(Times[len(Times)-1])
+	chk.IntAssert(len(ExCellVals), 2)
+	for _, v := range []struct{ cid, loc, vid int }{{0, 3, 3}, {0, 2, 2}, {1, 0, 3}, {1, 1, 2}} {
+		vals := ExCellVals[v.cid][v.loc]
+		chk.Scalar(tst, io.Sf("sx @ cell %d, vert %d", v.cid, v.vid), tolσ, vals["sx"], σx)
+		chk.Scalar(tst, io.Sf("sy @ cell %d, vert %d", v.cid, v.vid), tolσ, vals["sy"], σy)
+		chk.Scalar(tst, io.Sf("sy(avg) @ vert %d", v.vid), tolσ, ExVals[v.vid]["sy"], vals["sy"])
+	}
+}
+
+func Test_out03(tst *testing.T) {
+
+	// finalise analysis process and catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			tst.Fail()
+			io.PfRed("ERROR: %v\n", err)
+		}
+	}()
+
+	// test title
+	//verbose()
+	chk.PrintTitle("out03. integrals over faces and cells")
+
+	// run simulation
+	main := fem.NewMain("data/onequa4.sim", "", true, true, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// start post-processing and load results
+	Start("data/onequa4.sim", 0, 0)
+	LoadResults(nil)
+
+	// solution
+	var sol ana.CteStressPstrain
+	sol.Init(fun.Prms{
+		&fun.Prm{N: "qnH", V: -50},
+		&fun.Prm{N: "qnV", V: -100},
+	})
+
+	// check integrals
+	Ftop := FaceForce(-12)
+	Fright := FaceForce(-11)
+	Fbot := FaceForce(-10)
+	uyTop := FaceAverage("uy", -12)
+	sx := CellsInteg("sx", -1)
+	vcomp := CellsVolAbove("sy", -1, -150)
+	vtens := CellsVolAbove("sy", -1, 0)
+	for j, t := range Times {
+		σx, σy, _, _, εy := sol.Solution(t)
+		io.Pfyel("t=%g: Ftop=%v Fright=%v uyTop=%v\n", t, Ftop[j], Fright[j], uyTop[j])
+		chk.Vector(tst, "Ftop", 1e-12, Ftop[j], []float64{0, σy})
+		chk.Vector(tst, "Fright", 1e-12, Fright[j], []float64{σx, 0})
+		chk.Vector(tst, "Fbot", 1e-12, Fbot[j], []float64{0, -σy})
+		chk.Scalar(tst, "uyTop", 1e-15, uyTop[j], εy)
+		chk.Scalar(tst, "∫sx", 1e-12, sx[j], σx)
+		chk.Scalar(tst, "vol(sy > -150)", 1e-15, vcomp[j], 1)
+		if t > 0 {
+			chk.Scalar(tst, "vol(sy > 0)", 1e-15, vtens[j], 0)
+		}
+	}
+}
+
+func Test_out04(tst *testing.T) {
+
+	// finalise analysis process and catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			tst.Fail()
+			io.PfRed("ERROR: %v\n", err)
+		}
+	}()
+
+	// test title
+	//verbose()
+	chk.PrintTitle("out04. region statistics")
+
+	// run simulation
+	main := fem.NewMain("data/onequa4.sim", "", true, true, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// start post-processing and load results
+	Start("data/onequa4.sim", 0, 0)
+	LoadResults(nil)
+
+	// solution
+	var sol ana.CteStressPstrain
+	sol.Init(fun.Prms{
+		&fun.Prm{N: "qnH", V: -50},
+		&fun.Prm{N: "qnV", V: -100},
+	})
+
+	// check statistics
+	sy := RegionStats(-1, "sy")
+	uy := RegionStats(-1, "uy")
+	chk.IntAssert(len(sy), len(Times))
+	chk.IntAssert(len(uy), len(Times))
+	for j, t := range Times {
+		_, σy, _, _, εy := sol.Solution(t)
+		io.Pfyel("t=%g\n", t)
+		chk.Scalar(tst, "sy: time", 1e-15, sy[j].Time, t)
+		chk.Scalar(tst, "sy: min ", 1e-12, sy[j].Min, σy)
+		chk.Scalar(tst, "sy: max ", 1e-12, sy[j].Max, σy)
+		chk.Scalar(tst, "sy: mean", 1e-12, sy[j].Mean, σy)
+		chk.Scalar(tst, "uy: min ", 1e-15, uy[j].Min, εy)
+		chk.Scalar(tst, "uy: max ", 1e-15, uy[j].Max, 0)
+		chk.Scalar(tst, "uy: mean", 1e-15, uy[j].Mean, εy/2.0)
+		if t > 0 {
+			chk.Scalar(tst, "uy: y @ min", 1e-15, uy[j].Xmin[1], 1)
+			chk.Scalar(tst, "uy: y @ max", 1e-15, uy[j].Xmax[1], 0)
+		}
+	}
 }
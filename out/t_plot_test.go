@@ -5,6 +5,7 @@
 package out
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/cpmech/gofem/fem"
@@ -60,6 +61,27 @@ func Test_plot01(tst *testing.T) {
 	Plot("y", "pl", "left", plt.Fmt{C: "b", M: "o", L: io.Sf("t=%g", Times[0])}, 0)
 	Plot("y", "pl", "left", plt.Fmt{C: "m", M: "*", Lw: 2, L: io.Sf("t=%g", Times[last])}, -1)
 
+	// export data
+	WriteData("/tmp/gofem", "plot01")
+	b, err := io.ReadFile("/tmp/gofem/plot01.json")
+	if err != nil {
+		tst.Errorf("cannot read exported data:\n%v", err)
+		return
+	}
+	var splots []*SplotDat
+	err = json.Unmarshal(b, &splots)
+	if err != nil {
+		tst.Errorf("cannot unmarshal exported data:\n%v", err)
+		return
+	}
+	chk.IntAssert(len(splots), 4)
+	chk.StrAssert(splots[0].Id, "t-pl")
+	chk.StrAssert(splots[0].Data[1].Alias, "A")
+	chk.StrAssert(splots[0].Data[1].Ylbl, "A-type")
+	chk.Vector(tst, "t", 1e-15, splots[0].Data[0].X, Times)
+	chk.Vector(tst, "pl(A)", 1e-15, splots[0].Data[1].Y, plA)
+	chk.Vector(tst, "x(B)", 1e-15, splots[0].Data[0].Coords[0], []float64{0, 1})
+
 	if chk.Verbose {
 		Draw("", "", -1, -1, false, nil)
 	}
@@ -101,68 +101,65 @@ func NodesOnPlane(ftag int) *PlaneData {
 	first := true
 	cells := Dom.Msh.FaceTag2cells[ftag]
 	for _, cell := range cells {
-		for fidx, cftag := range cell.C.FTags {
-			if cftag == ftag {
+		fidx := cell.Fid
 
-				// check face type
-				ftype := cell.C.Shp.FaceType
-				if !(ftype == "qua4" || ftype == "qua8") {
-					chk.Panic("can only handle qua4 or qua8 faces for now. ftype=%q", ftype)
-				}
+		// check face type
+		ftype := cell.C.Shp.FaceType
+		if !(ftype == "qua4" || ftype == "qua8") {
+			chk.Panic("can only handle qua4 or qua8 faces for now. ftype=%q", ftype)
+		}
 
-				// vertices on face
-				flvids := cell.C.Shp.FaceLocalVerts[fidx]
-				nv := len(flvids)
-				if nv == 8 {
-					nv = 4 // avoid middle nodes
-				}
-				for i := 0; i < nv; i++ {
-					vid := cell.C.Verts[flvids[i]]
-					dat.Ids[vid] = true
-				}
+		// vertices on face
+		flvids := cell.C.Shp.FaceLocalVerts[fidx]
+		nv := len(flvids)
+		if nv == 8 {
+			nv = 4 // avoid middle nodes
+		}
+		for i := 0; i < nv; i++ {
+			vid := cell.C.Verts[flvids[i]]
+			dat.Ids[vid] = true
+		}
 
-				// compute and check increments in global coordinates
-				for i := 1; i < nv; i++ {
-					a := cell.C.Verts[flvids[i]]
-					b := cell.C.Verts[flvids[i-1]]
-					xa := Dom.Msh.Verts[a].C
-					xb := Dom.Msh.Verts[b].C
-					for j := 0; j < ndim; j++ {
-						Δx[j] = utl.Max(Δx[j], math.Abs(xa[j]-xb[j]))
-					}
-				}
-				if first {
-					for j := 0; j < ndim; j++ {
-						dat.Dx[j] = Δx[j]
-					}
-					first = false
-				} else {
-					for j := 0; j < ndim; j++ {
-						if math.Abs(dat.Dx[j]-Δx[j]) > 1e-10 {
-							chk.Panic("all faces must have the same Δx,Δy,Δz")
-						}
-					}
+		// compute and check increments in global coordinates
+		for i := 1; i < nv; i++ {
+			a := cell.C.Verts[flvids[i]]
+			b := cell.C.Verts[flvids[i-1]]
+			xa := Dom.Msh.Verts[a].C
+			xb := Dom.Msh.Verts[b].C
+			for j := 0; j < ndim; j++ {
+				Δx[j] = utl.Max(Δx[j], math.Abs(xa[j]-xb[j]))
+			}
+		}
+		if first {
+			for j := 0; j < ndim; j++ {
+				dat.Dx[j] = Δx[j]
+			}
+			first = false
+		} else {
+			for j := 0; j < ndim; j++ {
+				if math.Abs(dat.Dx[j]-Δx[j]) > 1e-10 {
+					chk.Panic("all faces must have the same Δx,Δy,Δz")
 				}
+			}
+		}
 
-				// find which plane vertices are located on => which plane this face is parallel to
-				perpto := -1 // "perpendicular to" indicator
-				switch {
-				case Δx[0] < DIST_TOL: // plane perpendicular to x-axis
-					perpto = 0
-				case Δx[1] < DIST_TOL: // plane perpendicular to y-axis
-					perpto = 1
-				case Δx[2] < DIST_TOL: // plane perpendicular to z-axis
-					perpto = 2
-				default:
-					chk.Panic("planes must be perpendicular to one of the x-y-z axes")
-				}
-				if dat.Plane < 0 {
-					dat.Plane = perpto
-				} else {
-					if perpto != dat.Plane {
-						chk.Panic("all planes must be perperdicular to the same axis")
-					}
-				}
+		// find which plane vertices are located on => which plane this face is parallel to
+		perpto := -1 // "perpendicular to" indicator
+		switch {
+		case Δx[0] < DIST_TOL: // plane perpendicular to x-axis
+			perpto = 0
+		case Δx[1] < DIST_TOL: // plane perpendicular to y-axis
+			perpto = 1
+		case Δx[2] < DIST_TOL: // plane perpendicular to z-axis
+			perpto = 2
+		default:
+			chk.Panic("planes must be perpendicular to one of the x-y-z axes")
+		}
+		if dat.Plane < 0 {
+			dat.Plane = perpto
+		} else {
+			if perpto != dat.Plane {
+				chk.Panic("all planes must be perperdicular to the same axis")
 			}
 		}
 	}
@@ -32,6 +32,7 @@ func init() {
 	hex8.init_scratchpad()
 	factory["hex8"] = &hex8
 	ipsfactory["hex8_0"] = ips_hex_8
+	ipsfactory["hex8_1"] = ips_hex_1
 	ipsfactory["hex8_8"] = ips_hex_8
 	ipsfactory["hex8_14"] = ips_hex_14
 	ipsfactory["hex8_27"] = ips_hex_27
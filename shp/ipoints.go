@@ -83,6 +83,39 @@ func (o *Shape) GetIps(nips, nipf int) (ips, ipf []Ipoint, err error) {
 	return
 }
 
+// ipsreduced holds the number of integration points of reduced integration rules
+var ipsreduced = map[string]int{
+	"lin3":  2,
+	"lin4":  2,
+	"tri6":  1,
+	"qua4":  1,
+	"qua8":  4,
+	"qua9":  4,
+	"tet10": 1,
+	"hex8":  1,
+	"hex20": 8,
+}
+
+// RuleNips returns the number of integration points corresponding to an integration rule
+//  rule -- "" or "full" => default rule; "reduced" => reduced integration; e.g. 1 ip in qua4
+//  Note: (1) the rule applies to the element only; the default rule is returned for faces (nipf)
+//        (2) NURBS elements only accept the default rule
+func (o *Shape) RuleNips(rule string) (nips, nipf int, err error) {
+	switch rule {
+	case "", "full":
+		return
+	case "reduced":
+		var ok bool
+		nips, ok = ipsreduced[o.Type]
+		if !ok {
+			err = chk.Err("reduced integration rule is not available for geometry type = %s", o.Type)
+		}
+		return
+	}
+	err = chk.Err("integration rule %q is not available. options are \"full\" or \"reduced\"", rule)
+	return
+}
+
 var (
 	ips_lin_2 = []Ipoint{
 		Ipoint{-math.Sqrt(3.0) / 3.0, 0.0, 0.0, 1.0},
@@ -113,6 +146,15 @@ var (
 		Ipoint{1.0 / 6.0, 2.0 / 3.0, 0.0, 1.0 / 6.0},
 	}
 
+	ips_tri_6 = []Ipoint{ // Dunavant (degree 4)
+		Ipoint{0.445948490915965, 0.445948490915965, 0, 0.111690794839005},
+		Ipoint{0.108103018168070, 0.445948490915965, 0, 0.111690794839005},
+		Ipoint{0.445948490915965, 0.108103018168070, 0, 0.111690794839005},
+		Ipoint{0.091576213509771, 0.091576213509771, 0, 0.054975871827661},
+		Ipoint{0.816847572980459, 0.091576213509771, 0, 0.054975871827661},
+		Ipoint{0.091576213509771, 0.816847572980459, 0, 0.054975871827661},
+	}
+
 	ips_tri_12 = []Ipoint{
 		Ipoint{0.873821971016996, 0.063089014491502, 0, 0.0254224531851035},
 		Ipoint{0.063089014491502, 0.873821971016996, 0, 0.0254224531851035},
@@ -147,6 +189,10 @@ var (
 		Ipoint{2.63112829634638E-01, 8.39477740995800E-03, 0.0, 1.36151570872175E-02},
 	}
 
+	ips_qua_1 = []Ipoint{
+		Ipoint{0.0, 0.0, 0.0, 4.0},
+	}
+
 	ips_qua_4 = []Ipoint{
 		Ipoint{-math.Sqrt(3.0) / 3.0, -math.Sqrt(3.0) / 3.0, 0.0, 1.0},
 		Ipoint{math.Sqrt(3.0) / 3.0, -math.Sqrt(3.0) / 3.0, 0.0, 1.0},
@@ -185,6 +231,24 @@ var (
 		Ipoint{1.0 / 2.0, 1.0 / 6.0, 1.0 / 6.0, 3.0 / 40.0},
 	}
 
+	ips_tet_11 = []Ipoint{ // Keast (degree 4)
+		Ipoint{0.25, 0.25, 0.25, -0.01315555555555556},
+		Ipoint{0.07142857142857143, 0.07142857142857143, 0.07142857142857143, 0.007622222222222222},
+		Ipoint{0.7857142857142857, 0.07142857142857143, 0.07142857142857143, 0.007622222222222222},
+		Ipoint{0.07142857142857143, 0.7857142857142857, 0.07142857142857143, 0.007622222222222222},
+		Ipoint{0.07142857142857143, 0.07142857142857143, 0.7857142857142857, 0.007622222222222222},
+		Ipoint{0.3994035761667992, 0.3994035761667992, 0.1005964238332008, 0.02488888888888889},
+		Ipoint{0.3994035761667992, 0.1005964238332008, 0.3994035761667992, 0.02488888888888889},
+		Ipoint{0.1005964238332008, 0.3994035761667992, 0.3994035761667992, 0.02488888888888889},
+		Ipoint{0.3994035761667992, 0.1005964238332008, 0.1005964238332008, 0.02488888888888889},
+		Ipoint{0.1005964238332008, 0.3994035761667992, 0.1005964238332008, 0.02488888888888889},
+		Ipoint{0.1005964238332008, 0.1005964238332008, 0.3994035761667992, 0.02488888888888889},
+	}
+
+	ips_hex_1 = []Ipoint{
+		Ipoint{0.0, 0.0, 0.0, 8.0},
+	}
+
 	ips_hex_6 = []Ipoint{
 		Ipoint{1.0, 0.0, 0.0, 4.0 / 3.0},
 		Ipoint{-1.0, 0.0, 0.0, 4.0 / 3.0},
@@ -30,6 +30,7 @@ func init() {
 	qua4.init_scratchpad()
 	factory["qua4"] = &qua4
 	ipsfactory["qua4_0"] = ips_qua_4
+	ipsfactory["qua4_1"] = ips_qua_1
 	ipsfactory["qua4_4"] = ips_qua_4
 	ipsfactory["qua4_9"] = ips_qua_9
 
@@ -0,0 +1,288 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shp
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// spectral elements ////////////////////////////////////////////////////////////////////////////////
+//
+//  Lines, quadrilaterals and hexahedra of orders p = 4 to 8 with nodes at the Gauss-Lobatto-Legendre
+//  (GLL) points; e.g. for spectral-element analyses of wave propagation. The geometry types are
+//  named as "lin5sem", "qua25sem" or "hex125sem" (p = 4) up to "lin9sem", "qua81sem" or "hex729sem"
+//  (p = 8); i.e. the number of nodes followed by "sem".
+//
+//  Note: (1) the corner nodes come first, with the same ordering as in lin2, qua4 or hex8. The other
+//            nodes follow in lexicographic order; i.e. with r varying first, then s and then t
+//        (2) the default integration points coincide with the nodes (GLL quadrature); thus the
+//            consistent mass matrix is diagonal
+//        (3) the faces are the spectral shapes of the same order in one dimension less
+
+// spectral orders available
+const (
+	SEM_PMIN = 4 // minimum order of spectral elements
+	SEM_PMAX = 8 // maximum order of spectral elements
+)
+
+func init() {
+	for p := SEM_PMIN; p <= SEM_PMAX; p++ {
+		lin := new_spectral(1, p, nil)
+		qua := new_spectral(2, p, lin)
+		hex := new_spectral(3, p, qua)
+		for _, s := range []*Shape{lin, qua, hex} {
+			factory[s.Type] = s
+		}
+	}
+}
+
+// SemType returns the name of the spectral shape of order p and geometry gndim; e.g. "qua25sem"
+func SemType(gndim, p int) string {
+	n := int(math.Pow(float64(p+1), float64(gndim)))
+	return io.Sf("%s%dsem", []string{"", "lin", "qua", "hex"}[gndim], n)
+}
+
+// new_spectral allocates a new spectral shape of order p. face is the shape of faces (nil for lines)
+func new_spectral(gndim, p int, face *Shape) (o *Shape) {
+
+	// GLL points and indices of nodes along each direction
+	ξ, w := gll_points(p + 1)
+	idx := sem_indices(gndim, p)
+	nverts := len(idx)
+
+	// geometry
+	o = new(Shape)
+	o.Type = SemType(gndim, p)
+	o.Gndim = gndim
+	o.Nverts = nverts
+	o.NatCoords = make([][]float64, gndim)
+	for i := 0; i < gndim; i++ {
+		o.NatCoords[i] = make([]float64, nverts)
+		for n, I := range idx {
+			o.NatCoords[i][n] = ξ[I[i]]
+		}
+	}
+	o.Func = func(S []float64, dSdR [][]float64, R []float64, derivs bool, idxface int) {
+		l := make([][]float64, gndim)
+		dl := make([][]float64, gndim)
+		for i := 0; i < gndim; i++ {
+			l[i], dl[i] = lagrange_gll(ξ, R[i], derivs)
+		}
+		for n, I := range idx {
+			S[n] = 1
+			for i := 0; i < gndim; i++ {
+				S[n] *= l[i][I[i]]
+			}
+			if !derivs {
+				continue
+			}
+			for j := 0; j < gndim; j++ {
+				dSdR[n][j] = 1
+				for i := 0; i < gndim; i++ {
+					if i == j {
+						dSdR[n][j] *= dl[i][I[i]]
+					} else {
+						dSdR[n][j] *= l[i][I[i]]
+					}
+				}
+			}
+		}
+	}
+
+	// basic type
+	switch gndim {
+	case 1:
+		o.BasicType, o.BasicNverts, o.BasicVtkCode = "lin2", 2, VTK_LINE
+	case 2:
+		o.BasicType, o.BasicNverts, o.BasicVtkCode = "qua4", 4, VTK_QUAD
+	case 3:
+		o.BasicType, o.BasicNverts, o.BasicVtkCode = "hex8", 8, VTK_HEXAHEDRON
+	}
+	o.VtkCode = o.BasicVtkCode
+	o.VtkNverts = o.BasicNverts
+
+	// faces
+	if face != nil {
+		o.FaceType = face.Type
+		o.FaceFunc = face.Func
+		o.FaceNvertsMax = face.Nverts
+		o.FaceLocalVerts = sem_face_verts(o, face)
+	}
+	o.init_scratchpad()
+
+	// integration points @ nodes
+	ips := make([]Ipoint, nverts)
+	for n, I := range idx {
+		ips[n] = Ipoint{0, 0, 0, 1}
+		for i := 0; i < gndim; i++ {
+			ips[n][i] = ξ[I[i]]
+			ips[n][3] *= w[I[i]]
+		}
+	}
+	ipsfactory[io.Sf("%s_0", o.Type)] = ips
+	ipsfactory[io.Sf("%s_%d", o.Type, nverts)] = ips
+	return
+}
+
+// sem_indices returns the indices of GLL points along each direction of all nodes of a spectral
+// shape of order p; corners first
+func sem_indices(gndim, p int) (idx [][]int) {
+	switch gndim {
+	case 1:
+		idx = [][]int{{0}, {p}}
+	case 2:
+		idx = [][]int{{0, 0}, {p, 0}, {p, p}, {0, p}}
+	case 3:
+		idx = [][]int{{0, 0, 0}, {p, 0, 0}, {p, p, 0}, {0, p, 0}, {0, 0, p}, {p, 0, p}, {p, p, p}, {0, p, p}}
+	}
+	iscorner := func(I []int) bool {
+		for _, i := range I {
+			if i != 0 && i != p {
+				return false
+			}
+		}
+		return true
+	}
+	n := int(math.Pow(float64(p+1), float64(gndim)))
+	for k := 0; k < n; k++ {
+		I := make([]int, gndim)
+		for i, m := 0, k; i < gndim; i++ {
+			I[i] = m % (p + 1)
+			m /= p + 1
+		}
+		if !iscorner(I) {
+			idx = append(idx, I)
+		}
+	}
+	return
+}
+
+// sem_face_verts returns the local vertices of faces of spectral shape o. The corners of faces are
+// the same as in qua4 or hex8 and the other vertices correspond to the nodes of the face shape
+func sem_face_verts(o, face *Shape) (lverts [][]int) {
+
+	// corners of faces and bilinear interpolation functions of face shape
+	var corners [][]int
+	var interp func(ρ []float64) []float64
+	if o.Gndim == 2 {
+		corners = [][]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}}
+		interp = func(ρ []float64) []float64 {
+			return []float64{(1 - ρ[0]) / 2, (1 + ρ[0]) / 2}
+		}
+	} else {
+		corners = [][]int{{0, 4, 7, 3}, {1, 2, 6, 5}, {0, 1, 5, 4}, {2, 3, 7, 6}, {0, 3, 2, 1}, {4, 5, 6, 7}}
+		interp = func(ρ []float64) []float64 {
+			r, s := ρ[0], ρ[1]
+			return []float64{(1 - r) * (1 - s) / 4, (1 + r) * (1 - s) / 4, (1 + r) * (1 + s) / 4, (1 - r) * (1 + s) / 4}
+		}
+	}
+
+	// find vertices of cell at the natural coordinates of the nodes of face
+	lverts = make([][]int, len(corners))
+	ρ := make([]float64, face.Gndim)
+	x := make([]float64, o.Gndim)
+	for f, cs := range corners {
+		lverts[f] = make([]int, face.Nverts)
+		for k := 0; k < face.Nverts; k++ {
+			for i := 0; i < face.Gndim; i++ {
+				ρ[i] = face.NatCoords[i][k]
+			}
+			N := interp(ρ)
+			for i := 0; i < o.Gndim; i++ {
+				x[i] = 0
+				for c, m := range cs {
+					x[i] += N[c] * o.NatCoords[i][m]
+				}
+			}
+			lverts[f][k] = -1
+			for n := 0; n < o.Nverts; n++ {
+				dist := 0.0
+				for i := 0; i < o.Gndim; i++ {
+					dist += math.Abs(x[i] - o.NatCoords[i][n])
+				}
+				if dist < 1e-10 {
+					lverts[f][k] = n
+					break
+				}
+			}
+			if lverts[f][k] < 0 {
+				chk.Panic("cannot find vertex of %s at face %d with natural coordinates %v", o.Type, f, x)
+			}
+		}
+	}
+	return
+}
+
+// gll_points computes the n Gauss-Lobatto-Legendre points (in ascending order) and weights on [-1, 1]
+func gll_points(n int) (ξ, w []float64) {
+	N := n - 1
+	ξ = make([]float64, n)
+	w = make([]float64, n)
+	P := make([]float64, n) // Legendre polynomials @ ξ[i]
+	for i := 0; i < n; i++ {
+		x := -math.Cos(math.Pi * float64(i) / float64(N)) // Chebyshev-Gauss-Lobatto initial guess
+		for it := 0; it < 100; it++ {
+			P[0], P[1] = 1, x
+			for k := 2; k <= N; k++ {
+				P[k] = ((2*float64(k)-1)*x*P[k-1] - (float64(k)-1)*P[k-2]) / float64(k)
+			}
+			δ := (x*P[N] - P[N-1]) / (float64(n) * P[N])
+			x -= δ
+			if math.Abs(δ) < 1e-16 {
+				break
+			}
+		}
+		P[0], P[1] = 1, x
+		for k := 2; k <= N; k++ {
+			P[k] = ((2*float64(k)-1)*x*P[k-1] - (float64(k)-1)*P[k-2]) / float64(k)
+		}
+		ξ[i] = x
+		w[i] = 2.0 / (float64(N*n) * P[N] * P[N])
+	}
+	for i := 0; i < n/2; i++ { // symmetry
+		ξ[i], ξ[N-i] = -0.5*(ξ[N-i]-ξ[i]), 0.5*(ξ[N-i]-ξ[i])
+		w[i], w[N-i] = 0.5*(w[i]+w[N-i]), 0.5*(w[i]+w[N-i])
+	}
+	if n%2 == 1 {
+		ξ[N/2] = 0
+	}
+	return
+}
+
+// lagrange_gll computes the Lagrange polynomials (and derivatives) of nodes ξ at r
+func lagrange_gll(ξ []float64, r float64, derivs bool) (l, dl []float64) {
+	n := len(ξ)
+	l = make([]float64, n)
+	if derivs {
+		dl = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		l[i] = 1
+		for j := 0; j < n; j++ {
+			if j != i {
+				l[i] *= (r - ξ[j]) / (ξ[i] - ξ[j])
+			}
+		}
+		if !derivs {
+			continue
+		}
+		for k := 0; k < n; k++ {
+			if k == i {
+				continue
+			}
+			d := 1.0 / (ξ[i] - ξ[k])
+			for j := 0; j < n; j++ {
+				if j != i && j != k {
+					d *= (r - ξ[j]) / (ξ[i] - ξ[j])
+				}
+			}
+			dl[i] += d
+		}
+	}
+	return
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_ipoints01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ipoints01. integration of monomials over triangles and tetrahedra")
+
+	// ∫ r^a s^b t^c over the reference simplex = a! b! c! / (a + b + c + ndim)!
+	fact := func(n int) float64 {
+		res := 1.0
+		for i := 2; i <= n; i++ {
+			res *= float64(i)
+		}
+		return res
+	}
+	check := func(name string, ips []Ipoint, ndim, degree int) {
+		var maxerr float64
+		for a := 0; a <= degree; a++ {
+			for b := 0; a+b <= degree; b++ {
+				for c := 0; a+b+c <= degree; c++ {
+					if ndim == 2 && c > 0 {
+						break
+					}
+					num := 0.0
+					for _, ip := range ips {
+						num += math.Pow(ip[0], float64(a)) * math.Pow(ip[1], float64(b)) * math.Pow(ip[2], float64(c)) * ip[3]
+					}
+					ana := fact(a) * fact(b) * fact(c) / fact(a+b+c+ndim)
+					maxerr = math.Max(maxerr, math.Abs(num-ana))
+				}
+			}
+		}
+		io.Pforan("%-8s : max error = %v\n", name, maxerr)
+		if maxerr > 1e-14 {
+			tst.Errorf("%s fails to integrate polynomials of degree %d. max error = %v", name, degree, maxerr)
+		}
+	}
+	check("tri_3", ips_tri_3, 2, 2)
+	check("tri_6", ips_tri_6, 2, 4)
+	check("tet_4", ips_tet_4, 3, 2)
+	check("tet_11", ips_tet_11, 3, 4)
+}
+
+func Test_ipoints02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ipoints02. integration rules")
+
+	for _, name := range []string{"qua4", "qua8", "tri6", "tet10", "hex8", "hex20"} {
+		shape := Get(name, 0)
+		nip, nipf, err := shape.RuleNips("reduced")
+		if err != nil {
+			tst.Errorf("RuleNips failed:\n%v", err)
+			return
+		}
+		chk.IntAssert(nipf, 0)
+		ips, _, err := shape.GetIps(nip, nipf)
+		if err != nil {
+			tst.Errorf("GetIps failed:\n%v", err)
+			return
+		}
+		ipsdef, _, _ := shape.GetIps(0, 0)
+		io.Pforan("%-6s : reduced nip = %d, default nip = %d\n", name, len(ips), len(ipsdef))
+		if len(ips) >= len(ipsdef) {
+			tst.Errorf("reduced rule of %s must have less integration points than the default rule", name)
+			return
+		}
+	}
+
+	nip, _, err := Get("qua8", 0).RuleNips("full")
+	if err != nil {
+		tst.Errorf("RuleNips failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(nip, 0)
+
+	_, _, err = Get("tri3", 0).RuleNips("reduced")
+	if err == nil {
+		tst.Errorf("RuleNips should have failed: reduced rule for tri3")
+		return
+	}
+	_, _, err = Get("qua4", 0).RuleNips("selective")
+	if err == nil {
+		tst.Errorf("RuleNips should have failed: unknown rule")
+		return
+	}
+}
@@ -5,6 +5,7 @@
 package shp
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
@@ -42,6 +43,9 @@ func Test_shape01(tst *testing.T) {
 		if name == "tri15" {
 			tol = 1e-9
 		}
+		if strings.HasSuffix(name, "sem") {
+			tol = 1e-8
+		}
 		CheckDSdR(tst, shape, r, tol, verb)
 
 		io.PfGreen("OK\n")
@@ -35,6 +35,7 @@ func init() {
 	ipsfactory["tet4_1"] = ips_tet_1
 	ipsfactory["tet4_4"] = ips_tet_4
 	ipsfactory["tet4_5"] = ips_tet_5
+	ipsfactory["tet4_11"] = ips_tet_11
 
 	// tet10
 	tet10.Type = "tet10"
@@ -58,8 +59,10 @@ func init() {
 	tet10.init_scratchpad()
 	factory["tet10"] = &tet10
 	ipsfactory["tet10_0"] = ips_tet_4
+	ipsfactory["tet10_1"] = ips_tet_1
 	ipsfactory["tet10_4"] = ips_tet_4
 	ipsfactory["tet10_5"] = ips_tet_5
+	ipsfactory["tet10_11"] = ips_tet_11
 }
 
 // Tet4 calculates the shape functions (S) and derivatives of shape functions (dSdR) of tet4
@@ -33,6 +33,7 @@ func init() {
 	ipsfactory["tri3_0"] = ips_tri_1
 	ipsfactory["tri3_1"] = ips_tri_1
 	ipsfactory["tri3_3"] = ips_tri_3
+	ipsfactory["tri3_6"] = ips_tri_6
 
 	// tri6
 	tri6.Type = "tri6"
@@ -55,7 +56,9 @@ func init() {
 	tri6.init_scratchpad()
 	factory["tri6"] = &tri6
 	ipsfactory["tri6_0"] = ips_tri_3
+	ipsfactory["tri6_1"] = ips_tri_1
 	ipsfactory["tri6_3"] = ips_tri_3
+	ipsfactory["tri6_6"] = ips_tri_6
 
 	// tri10
 	tri10.Type = "tri10"
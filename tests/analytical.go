@@ -0,0 +1,183 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// AnaFcn defines an analytical solution of a dof; e.g. func(t, x) { return sol.Pressure(t, x) }
+// with sol being one of the structures of package ana
+type AnaFcn func(t float64, x []float64) float64
+
+// AnaErrors holds the errors of a simulation with respect to an analytical solution at an output time
+type AnaErrors struct {
+	T     float64 // time
+	Key   string  // dof key
+	Nnod  int     // number of nodes with key
+	Max   float64 // maximum nodal error: max |num - ana|
+	Vid   int     // vertex with maximum error
+	Rms   float64 // root-mean-square of nodal errors
+	L2    float64 // L2 norm of error: sqrt(∫ (uh - u)² dΩ)
+	L2rel float64 // L2 norm of error divided by the L2 norm of the analytical solution
+}
+
+// CompareAna computes the errors of a simulation (read from output files) with respect to the
+// analytical solution f of dof key at output time t
+//  Note: (1) the nodal errors are computed at all nodes with key
+//        (2) the L2 norm is computed over the solid cells whose vertices all have key; the
+//            numerical solution is interpolated at the integration points of these cells
+func CompareAna(dom *fem.Domain, sum *fem.Summary, t float64, key string, f AnaFcn) (o *AnaErrors, err error) {
+
+	// read results
+	err = ref_read(dom, sum, t, 1e-8)
+	if err != nil {
+		return
+	}
+	o = &AnaErrors{T: t, Key: key, Vid: -1}
+
+	// nodal errors
+	for _, nod := range dom.Nodes {
+		eq := nod.GetEq(key)
+		if eq < 0 {
+			continue
+		}
+		e := math.Abs(dom.Sol.Y[eq] - f(t, nod.Vert.C))
+		if e > o.Max || o.Vid < 0 {
+			o.Max, o.Vid = e, nod.Vert.Id
+		}
+		o.Rms += e * e
+		o.Nnod++
+	}
+	if o.Nnod == 0 {
+		return nil, chk.Err("cannot compare results with analytical solution: there are no nodes with %q", key)
+	}
+	o.Rms = math.Sqrt(o.Rms / float64(o.Nnod))
+
+	// L2 norm
+	ndim := dom.Msh.Ndim
+	x := make([]float64, ndim)
+	var norm float64
+	for _, c := range dom.Msh.Cells {
+		sh := c.Shp
+		if dom.Cid2elem[c.Id] == nil || !c.IsSolid || sh == nil || sh.Nurbs != nil {
+			continue
+		}
+		eqs := make([]int, sh.Nverts)
+		for m := 0; m < sh.Nverts; m++ {
+			eqs[m] = -1
+			if nod := dom.Vid2node[c.Verts[m]]; nod != nil {
+				eqs[m] = nod.GetEq(key)
+			}
+			if eqs[m] < 0 {
+				eqs = nil
+				break
+			}
+		}
+		if eqs == nil {
+			continue
+		}
+		X := la.MatAlloc(ndim, sh.Nverts)
+		for i := 0; i < ndim; i++ {
+			for m := 0; m < sh.Nverts; m++ {
+				X[i][m] = dom.Msh.Verts[c.Verts[m]].C[i]
+			}
+		}
+		ips, _, err := sh.GetIps(0, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			err = sh.CalcAtIp(X, ip, true)
+			if err != nil {
+				return nil, err
+			}
+			coef := ip[3] * sh.J
+			for i := 0; i < ndim; i++ {
+				x[i] = 0
+				for m := 0; m < sh.Nverts; m++ {
+					x[i] += sh.S[m] * X[i][m]
+				}
+			}
+			var uh float64
+			for m := 0; m < sh.Nverts; m++ {
+				uh += sh.S[m] * dom.Sol.Y[eqs[m]]
+			}
+			u := f(t, x)
+			o.L2 += coef * (uh - u) * (uh - u)
+			norm += coef * u * u
+		}
+	}
+	o.L2 = math.Sqrt(o.L2)
+	if norm > 0 {
+		o.L2rel = o.L2 / math.Sqrt(norm)
+	}
+	return
+}
+
+// String returns a table with the errors
+func (o *AnaErrors) String() string {
+	var b bytes.Buffer
+	io.Ff(&b, "%13s%6s%6s%13s%6s%13s%13s%13s\n", "t", "key", "nnod", "max", "vid", "rms", "L2", "L2rel")
+	io.Ff(&b, "%13g%6s%6d%13.6e%6d%13.6e%13.6e%13.6e\n", o.T, o.Key, o.Nnod, o.Max, o.Vid, o.Rms, o.L2, o.L2rel)
+	return b.String()
+}
+
+// CheckAna runs CompareAna for all times and reports the ones with maximum nodal error greater than tol
+func CheckAna(tst *testing.T, main *fem.Main, times []float64, key string, f AnaFcn, tol float64, verbose bool) {
+	for _, t := range times {
+		o, err := CompareAna(main.Domains[0], main.Summary, t, key, f)
+		if err != nil {
+			tst.Errorf("CheckAna failed:\n%v", err)
+			return
+		}
+		if verbose {
+			io.Pf("%s", o.String())
+		}
+		if o.Max > tol {
+			tst.Errorf("comparison with analytical solution failed (tol = %g):\n%s", tol, o.String())
+		}
+	}
+}
+
+// NewAnaReference records reference results from analytical solutions; e.g. to be saved and used
+// with CompareReference
+//  Input:
+//   dom   -- domain; only the mesh and the nodes are used
+//   times -- output times to be recorded
+//   vids  -- vertices ids
+//   fcns  -- analytical solutions for each dof key
+func NewAnaReference(desc string, dom *fem.Domain, times []float64, vids []int, fcns map[string]AnaFcn) (o *Reference, err error) {
+	keys := make([]string, 0, len(fcns))
+	for key := range fcns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	o = &Reference{Desc: desc, Tols: map[string]*RefTol{"*": &RefTol{Abs: 1e-10, Rel: 1e-8}}, Ttol: 1e-8}
+	for _, t := range times {
+		rt := &RefTime{T: t}
+		for _, vid := range vids {
+			if vid < 0 || vid >= len(dom.Vid2node) || dom.Vid2node[vid] == nil {
+				return nil, chk.Err("cannot record analytical solution at vertex %d: there is no node", vid)
+			}
+			nod := dom.Vid2node[vid]
+			for _, key := range keys {
+				if nod.GetEq(key) >= 0 {
+					rt.Nodes = append(rt.Nodes, &RefNode{vid, key, fcns[key](t, nod.Vert.C)})
+				}
+			}
+		}
+		o.Times = append(o.Times, rt)
+	}
+	return
+}
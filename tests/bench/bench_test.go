@@ -0,0 +1,132 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/cpmech/gofem/ele/solid"
+	"github.com/cpmech/gofem/fem"
+	msolid "github.com/cpmech/gofem/mdl/solid"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// bench_domain returns the domain of a simulation after setting and zeroing the first stage
+func bench_domain(b *testing.B, simfilepath string) *fem.Domain {
+	main := fem.NewMain(simfilepath, "", true, false, false, false, false, 0)
+	err := main.SetStage(0)
+	if err != nil {
+		b.Fatalf("SetStage failed:\n%v", err)
+	}
+	err = main.ZeroStage(0, true)
+	if err != nil {
+		b.Fatalf("ZeroStage failed:\n%v", err)
+	}
+	return main.Domains[0]
+}
+
+// bench_assemble assembles Kb and fb
+func bench_assemble(b *testing.B, d *fem.Domain) {
+	d.Kb.Start()
+	la.VecFill(d.Fb, 0)
+	for _, e := range d.Elems {
+		err := e.AddToKb(d.Kb, d.Sol, true)
+		if err != nil {
+			b.Fatalf("AddToKb failed:\n%v", err)
+		}
+		err = e.AddToRhs(d.Fb, d.Sol)
+		if err != nil {
+			b.Fatalf("AddToRhs failed:\n%v", err)
+		}
+	}
+	d.EssenBcs.AddToKb(d.Kb, d.Nyb)
+	d.EssenBcs.AddToRhs(d.Fb, d.Sol)
+}
+
+func BenchmarkAssembly(b *testing.B) {
+	d := bench_domain(b, "data/cube1000.sim")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bench_assemble(b, d)
+	}
+}
+
+func BenchmarkVonMisesUpdate(b *testing.B) {
+	mdl, err := msolid.New("vm")
+	if err != nil {
+		b.Fatalf("cannot allocate model:\n%v", err)
+	}
+	err = mdl.Init(3, false, fun.Prms{
+		&fun.Prm{N: "E", V: 1000},
+		&fun.Prm{N: "nu", V: 0.25},
+		&fun.Prm{N: "qy0", V: 1},
+		&fun.Prm{N: "H", V: 10},
+	})
+	if err != nil {
+		b.Fatalf("cannot initialise model:\n%v", err)
+	}
+	vm := mdl.(msolid.Small)
+	s0, err := mdl.InitIntVars(make([]float64, 6))
+	if err != nil {
+		b.Fatalf("InitIntVars failed:\n%v", err)
+	}
+	s := s0.GetCopy()
+	ε := make([]float64, 6)
+	Δε := []float64{-1e-3, 2e-3, -1e-3, 1e-3, 0, 0} // causes yielding
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Set(s0)
+		err = vm.Update(s, ε, Δε, 0, 0, 0)
+		if err != nil {
+			b.Fatalf("Update failed:\n%v", err)
+		}
+	}
+}
+
+func BenchmarkRjointKb(b *testing.B) {
+	d := bench_domain(b, "../solid/data/rjoint01.sim")
+	var joints []*solid.Rjoint
+	for _, e := range d.Elems {
+		if jnt, ok := e.(*solid.Rjoint); ok {
+			joints = append(joints, jnt)
+		}
+	}
+	if len(joints) == 0 {
+		b.Fatalf("there are no rjoint elements")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Kb.Start()
+		for _, jnt := range joints {
+			err := jnt.AddToKb(d.Kb, d.Sol, true)
+			if err != nil {
+				b.Fatalf("AddToKb failed:\n%v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	d := bench_domain(b, "data/cube1000.sim")
+	bench_assemble(b, d)
+	err := d.LinSol.InitR(d.Kb, d.Sim.LinSol.Symmetric, false, false)
+	if err != nil {
+		b.Fatalf("InitR failed:\n%v", err)
+	}
+	defer d.LinSol.Clean()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = d.LinSol.Fact()
+		if err != nil {
+			b.Fatalf("Fact failed:\n%v", err)
+		}
+		err = d.LinSol.SolveR(d.Wb, d.Fb, false)
+		if err != nil {
+			b.Fatalf("SolveR failed:\n%v", err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ignore
+
+package main
+
+import (
+	"bytes"
+
+	"github.com/cpmech/gosl/io"
+)
+
+// genmesh generates the structured mesh of hex8 cells of a unit cube used by the benchmarks
+//  face tags: -10,-11 (x=0,1); -20,-21 (y=0,1); -30,-31 (z=0,1)
+func main() {
+	n := 10
+	var b bytes.Buffer
+	io.Ff(&b, "{\n  \"verts\" : [\n")
+	nv := (n + 1) * (n + 1) * (n + 1)
+	vid := func(i, j, k int) int { return i + j*(n+1) + k*(n+1)*(n+1) }
+	for k := 0; k <= n; k++ {
+		for j := 0; j <= n; j++ {
+			for i := 0; i <= n; i++ {
+				tag := 0
+				if i == 0 && j == 0 && k == 0 {
+					tag = -100
+				}
+				sep := ","
+				if vid(i, j, k) == nv-1 {
+					sep = ""
+				}
+				io.Ff(&b, "    { \"id\":%d, \"tag\":%d, \"c\":[%g, %g, %g] }%s\n", vid(i, j, k), tag, float64(i)/float64(n), float64(j)/float64(n), float64(k)/float64(n), sep)
+			}
+		}
+	}
+	io.Ff(&b, "  ],\n  \"cells\" : [\n")
+	cid := 0
+	for k := 0; k < n; k++ {
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				ftag := func(c, last int, t0, t1 int) int {
+					if c == 0 && last == 0 {
+						return t0
+					}
+					if c == n-1 && last == 1 {
+						return t1
+					}
+					return 0
+				}
+				sep := ","
+				if cid == n*n*n-1 {
+					sep = ""
+				}
+				io.Ff(&b, "    { \"id\":%d, \"tag\":-1, \"type\":\"hex8\", \"part\":0, \"verts\":[%d, %d, %d, %d, %d, %d, %d, %d], \"ftags\":[%d, %d, %d, %d, %d, %d] }%s\n", cid,
+					vid(i, j, k), vid(i+1, j, k), vid(i+1, j+1, k), vid(i, j+1, k),
+					vid(i, j, k+1), vid(i+1, j, k+1), vid(i+1, j+1, k+1), vid(i, j+1, k+1),
+					ftag(i, 0, -10, -11), ftag(i, 1, -10, -11), ftag(j, 0, -20, -21), ftag(j, 1, -20, -21), ftag(k, 0, -30, -31), ftag(k, 1, -30, -31), sep)
+				cid++
+			}
+		}
+	}
+	io.Ff(&b, "  ]\n}\n")
+	io.WriteFileVD("data", "cube1000.msh", &b)
+}
@@ -218,9 +218,12 @@ func Rjoint(main *fem.Main, o *Kb) {
 
 			// check
 			o.check("Krr", d, e, e.Rod.Umap, e.Rod.Umap, e.Krr, o.Tol)
-			o.check("Krs", d, e, e.Rod.Umap, e.Sld.Umap, e.Krs, o.Tol)
-			o.check("Ksr", d, e, e.Sld.Umap, e.Rod.Umap, e.Ksr, o.Tol)
-			o.check("Kss", d, e, e.Sld.Umap, e.Sld.Umap, e.Kss, o.Tol)
+			// note: solids shared by segments make the numerical Krs, Ksr and Kss include all segments
+			for _, seg := range e.Segs {
+				o.check("Krs", d, e, e.Rod.Umap, seg.Sld.Umap, seg.Krs, o.Tol)
+				o.check("Ksr", d, e, seg.Sld.Umap, e.Rod.Umap, seg.Ksr, o.Tol)
+				o.check("Kss", d, e, seg.Sld.Umap, seg.Sld.Umap, seg.Kss, o.Tol)
+			}
 		} else {
 			io.Pfred("warning: Eid=%d does not correspond to Rjoint element\n", o.Eid)
 		}
@@ -284,6 +284,43 @@ func Test_diffu02b(tst *testing.T) {
 	}
 }
 
+func Test_diffu02c(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("diffu02c. Diffusion (Poisson) equation 02. Recovered fluxes")
+
+	// run simulation
+	main := fem.NewMain("data/diffu02.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// analytical solution: outward flux = -u'(0) @ bottom and u'(L) @ top
+	L, W := 10.0, 2.5
+	C := L * L / 6.0
+	qbot, qtop := C, L*L/2.0-C
+
+	// check
+	dom := main.Domains[0]
+	for _, test := range []struct {
+		ftag int
+		q    float64
+	}{{-10, qbot}, {-12, qtop}} {
+		flux, err := dom.RecoverFaceFlux(test.ftag, "u")
+		if err != nil {
+			tst.Errorf("RecoverFaceFlux failed:\n%v", err)
+			return
+		}
+		chk.IntAssert(len(flux.Vids), 3)
+		chk.Scalar(tst, io.Sf("total @ %d", test.ftag), 1e-10, flux.Total, test.q*W)
+		for i, vid := range flux.Vids {
+			chk.Scalar(tst, io.Sf("q @ vertex %d", vid), 1e-10, flux.Qdist[i], test.q)
+		}
+	}
+}
+
 func test_diffu03(tst *testing.T) {
 
 	//tests.Verbose()
@@ -327,3 +364,43 @@ func test_diffu03(tst *testing.T) {
 		//chk.AnaNum(tst, io.Sf("u(%6.3f)", x), 1e-12, unum, uana, chk.Verbose)
 	}
 }
+
+func Test_diffu04(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("diffu04. Diffusion equation 04. Transient stage stopped at steady state")
+
+	// run simulation
+	main := fem.NewMain("data/diffu04.sim", "", true, true, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// check detection time
+	dom := main.Domains[0]
+	tsteady, ok := main.Summary.Steady[0]
+	if !ok {
+		tst.Errorf("steady state of stage 0 was not detected\n")
+		return
+	}
+	io.Pforan("steady state detected at t = %v (criterion = %v)\n", tsteady, dom.Steady.Value)
+	chk.Scalar(tst, "t(final)", 1e-15, dom.Sol.T, tsteady)
+	chk.Scalar(tst, "t(out)", 1e-15, main.Summary.OutTimes[len(main.Summary.OutTimes)-1], tsteady)
+	if tsteady > 200 {
+		tst.Errorf("steady state should have been detected before t = 200. t = %v\n", tsteady)
+	}
+	if dom.Steady.Value > 1e-3 {
+		tst.Errorf("criterion must be smaller than tolerance. %v > 1e-3\n", dom.Steady.Value)
+	}
+
+	// check solution against steady-state analytical solution
+	L := 10.0
+	C := L * L / 6.0
+	for _, nod := range dom.Nodes {
+		x := nod.Vert.C
+		uana := -math.Pow(x[1], 3.0)/6.0 + C*x[1]
+		chk.AnaNum(tst, io.Sf("u(%6.3f)", x), 0.05, dom.Sol.Y[nod.GetEq("u")], uana, chk.Verbose)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// MmsResults holds the results of a convergence study with the method of manufactured solutions
+type MmsResults struct {
+	Keys   []string    // dof keys
+	H      []float64   // [nmeshes] characteristic sizes of cells
+	Errors [][]float64 // [nkeys][nmeshes] L2 norms of errors
+	Rates  [][]float64 // [nkeys][nmeshes-1] convergence rates: log(e[i-1]/e[i]) / log(h[i-1]/h[i])
+}
+
+// RunMms runs simulations with manufactured solutions (see inp.MmsData) and computes the
+// convergence rates of the errors
+//  Input:
+//   simfiles -- simulation files; one for each mesh of the sequence (coarse to fine)
+func RunMms(simfiles []string, verbose bool) (o *MmsResults, err error) {
+	o = new(MmsResults)
+	for _, simfile := range simfiles {
+
+		// run simulation
+		main := fem.NewMain(simfile, "", true, false, false, false, verbose, 0)
+		err = main.Run()
+		if err != nil {
+			return nil, chk.Err("simulation %q failed:\n%v", simfile, err)
+		}
+
+		// errors
+		dom := main.Domains[0]
+		if dom.Mms == nil {
+			return nil, chk.Err("simulation %q does not have manufactured solution", simfile)
+		}
+		errs, h, err := dom.Mms.Errors(dom)
+		if err != nil {
+			return nil, err
+		}
+		if o.Keys == nil {
+			o.Keys = dom.Mms.Dat.Keys
+			o.Errors = make([][]float64, len(o.Keys))
+			o.Rates = make([][]float64, len(o.Keys))
+		}
+		if len(errs) != len(o.Keys) {
+			return nil, chk.Err("all simulations must have the same keys of manufactured solution")
+		}
+		o.H = append(o.H, h)
+		for k, e := range errs {
+			o.Errors[k] = append(o.Errors[k], e)
+		}
+	}
+
+	// rates
+	for k := range o.Keys {
+		for i := 1; i < len(o.H); i++ {
+			r := math.Log(o.Errors[k][i-1]/o.Errors[k][i]) / math.Log(o.H[i-1]/o.H[i])
+			o.Rates[k] = append(o.Rates[k], r)
+		}
+	}
+	if verbose {
+		io.Pf("%s", o.String())
+	}
+	return
+}
+
+// String returns a table with errors and convergence rates
+func (o *MmsResults) String() string {
+	var b bytes.Buffer
+	io.Ff(&b, "%13s", "h")
+	for _, key := range o.Keys {
+		io.Ff(&b, "%13s%8s", "err("+key+")", "rate")
+	}
+	io.Ff(&b, "\n")
+	for i, h := range o.H {
+		io.Ff(&b, "%13.6e", h)
+		for k := range o.Keys {
+			io.Ff(&b, "%13.6e", o.Errors[k][i])
+			if i > 0 {
+				io.Ff(&b, "%8.3f", o.Rates[k][i-1])
+			} else {
+				io.Ff(&b, "%8s", "-")
+			}
+		}
+		io.Ff(&b, "\n")
+	}
+	return b.String()
+}
+
+// CheckMmsRates checks that the convergence rates of all keys between the two finest meshes are
+// equal to the expected rate within tol
+func CheckMmsRates(tst *testing.T, o *MmsResults, rate, tol float64) {
+	if len(o.H) < 2 {
+		tst.Errorf("at least two meshes are required to compute convergence rates\n")
+		return
+	}
+	n := len(o.H) - 2
+	for k, key := range o.Keys {
+		if math.Abs(o.Rates[k][n]-rate) > tol {
+			tst.Errorf("convergence rate of %q is incorrect: %g != %g (tol = %g)\n%s", key, o.Rates[k][n], rate, tol, o.String())
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// PatchTest holds the data of patch tests: a linear field is prescribed at the boundary of a small
+// patch of distorted cells and the numerical solution must reproduce it exactly; i.e. the values at
+// internal nodes must follow the linear field and the values at integration points (e.g. stresses
+// or fluxes) must be constant
+//  Note: (1) the patches are the ones by MacNeal and Harder (1985): 5 quadrilaterals in 2D (or 10
+//            triangles) and 7 hexahedra in 3D; higher order cells have straight edges
+//        (2) the linear fields and boundary conditions are set with the "mms" stage data (without
+//            source terms); see inp.MmsData
+//        (3) the simulation files are written to /tmp/gofem/patch
+type PatchTest struct {
+	Etype   string      // element type; e.g. "solid", "diffusion"
+	Geos    []string    // cell types; e.g. "qua4", "qua8", "tri6", "hex8"
+	Matfile string      // materials file (with path)
+	Mat     string      // material name
+	Extra   string      // extra flags of element; e.g. "!thick:1"
+	Nip     int         // number of integration points; 0 => default
+	Keys    []string    // dof keys; e.g. ["ux", "uy"]
+	Grads   [][]float64 // [nkeys][ndim] gradients of the linear fields; i.e. u_k(x) = Grads[k]・x
+	IpKeys  []string    // keys of integration points values to be checked; e.g. ["sx", "sy", "sxy"]
+	IpVals  []float64   // expected (constant) values at integration points corresponding to IpKeys
+	Tol     float64     // tolerance
+	Verbose bool        // show messages
+}
+
+// PatchMesh returns the coordinates of vertices X[nverts][ndim] and the vertices of cells
+// C[ncells][nverts_cell] of a distorted patch with cells of type geo
+func PatchMesh(geo string) (X [][]float64, C [][]int, err error) {
+
+	// basic patch
+	switch geo {
+	case "qua4", "qua8", "qua9", "tri3", "tri6":
+		X = [][]float64{
+			{0, 0}, {0.24, 0}, {0.24, 0.12}, {0, 0.12}, // outer
+			{0.04, 0.02}, {0.18, 0.03}, {0.16, 0.08}, {0.08, 0.08}, // inner
+		}
+		C = [][]int{{0, 1, 5, 4}, {1, 2, 6, 5}, {2, 3, 7, 6}, {3, 0, 4, 7}, {4, 5, 6, 7}}
+		if geo[:3] == "tri" {
+			var T [][]int
+			for _, c := range C {
+				T = append(T, []int{c[0], c[1], c[2]}, []int{c[0], c[2], c[3]})
+			}
+			C = T
+		}
+	case "hex8", "hex20":
+		X = [][]float64{
+			{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1}, // outer
+			{0.249, 0.342, 0.192}, {0.826, 0.288, 0.288}, {0.850, 0.649, 0.263}, {0.273, 0.750, 0.230}, // inner
+			{0.320, 0.186, 0.643}, {0.677, 0.305, 0.683}, {0.788, 0.693, 0.644}, {0.165, 0.745, 0.702},
+		}
+		C = [][]int{
+			{8, 9, 10, 11, 12, 13, 14, 15},
+			{0, 1, 2, 3, 8, 9, 10, 11},
+			{12, 13, 14, 15, 4, 5, 6, 7},
+			{8, 9, 13, 12, 0, 1, 5, 4},
+			{9, 10, 14, 13, 1, 2, 6, 5},
+			{10, 11, 15, 14, 2, 3, 7, 6},
+			{11, 8, 12, 15, 3, 0, 4, 7},
+		}
+	default:
+		return nil, nil, chk.Err("patch with cells of type %q is not available", geo)
+	}
+
+	// higher order cells: vertices at the middle of edges (and centre of qua9)
+	var edges [][]int
+	switch geo {
+	case "qua8", "qua9":
+		edges = [][]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}}
+	case "tri6":
+		edges = [][]int{{0, 1}, {1, 2}, {2, 0}}
+	case "hex20":
+		edges = [][]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 5}, {5, 6}, {6, 7}, {7, 4}, {0, 4}, {1, 5}, {2, 6}, {3, 7}}
+	}
+	mid := make(map[string]int)
+	for k, c := range C {
+		for _, e := range edges {
+			a, b := c[e[0]], c[e[1]]
+			key := io.Sf("%d_%d", utl.Imin(a, b), utl.Imax(a, b))
+			if _, ok := mid[key]; !ok {
+				mid[key] = len(X)
+				X = append(X, patch_avg(X, []int{a, b}))
+			}
+			C[k] = append(C[k], mid[key])
+		}
+		if geo == "qua9" {
+			C[k] = append(C[k], len(X))
+			X = append(X, patch_avg(X, c[:4]))
+		}
+	}
+	return
+}
+
+// Run runs the patch test for all cell types and returns the messages of failed checks
+func (o *PatchTest) Run() (failures []string, err error) {
+	for _, geo := range o.Geos {
+		var msgs []string
+		msgs, err = o.run(geo)
+		if err != nil {
+			return nil, chk.Err("patch test with %q cells failed:\n%v", geo, err)
+		}
+		failures = append(failures, msgs...)
+	}
+	return
+}
+
+// CheckPatch runs patch tests and reports failed checks
+func CheckPatch(tst *testing.T, o *PatchTest) {
+	failures, err := o.Run()
+	if err != nil {
+		tst.Errorf("CheckPatch failed:\n%v", err)
+		return
+	}
+	if len(failures) > 0 {
+		tst.Errorf("patch test of %q elements failed:\n%s", o.Etype, strings.Join(failures, "\n"))
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// run runs the patch test with cells of type geo
+func (o *PatchTest) run(geo string) (failures []string, err error) {
+
+	// mesh
+	X, C, err := PatchMesh(geo)
+	if err != nil {
+		return
+	}
+	ndim := len(X[0])
+	for k, g := range o.Grads {
+		if len(g) != ndim {
+			return nil, chk.Err("gradient of %q must have %d components", o.Keys[k], ndim)
+		}
+	}
+
+	// write files
+	dir := "/tmp/gofem/patch"
+	fnkey := io.Sf("patch-%s-%s", o.Etype, geo)
+	buf, err := io.ReadFile(o.Matfile)
+	if err != nil {
+		return nil, chk.Err("cannot read materials file:\n%v", err)
+	}
+	io.WriteFileSD(dir, filepath.Base(o.Matfile), string(buf))
+	io.WriteFileSD(dir, fnkey+".msh", patch_msh(X, C, geo).String())
+	io.WriteFileSD(dir, fnkey+".sim", o.sim(fnkey, filepath.Base(o.Matfile), ndim).String())
+
+	// run
+	main := fem.NewMain(filepath.Join(dir, fnkey+".sim"), "", true, false, false, false, o.Verbose, 0)
+	err = main.Run()
+	if err != nil {
+		return
+	}
+	dom := main.Domains[0]
+
+	// check values at nodes
+	for _, nod := range dom.Nodes {
+		for k, key := range o.Keys {
+			eq := nod.GetEq(key)
+			if eq < 0 {
+				continue
+			}
+			var ana float64
+			for i := 0; i < ndim; i++ {
+				ana += o.Grads[k][i] * nod.Vert.C[i]
+			}
+			if math.Abs(dom.Sol.Y[eq]-ana) > o.Tol {
+				failures = append(failures, io.Sf("%s: %s @ node %d: %g != %g", geo, key, nod.Vert.Id, dom.Sol.Y[eq], ana))
+			}
+		}
+	}
+
+	// check values at integration points
+	for _, e := range dom.Elems {
+		eo, ok := e.(ele.CanOutputIps)
+		if !ok {
+			continue
+		}
+		M := ele.NewIpsMap()
+		eo.OutIpVals(M, dom.Sol)
+		for k, key := range o.IpKeys {
+			vals, ok := (*M)[key]
+			if !ok {
+				return nil, chk.Err("element %d does not have integration point value %q", e.Id(), key)
+			}
+			for idx, v := range vals {
+				if math.Abs(v-o.IpVals[k]) > o.Tol {
+					failures = append(failures, io.Sf("%s: %s @ element %d, ip %d: %g != %g", geo, key, e.Id(), idx, v, o.IpVals[k]))
+				}
+			}
+		}
+	}
+	return
+}
+
+// sim returns the simulation file of patch test
+func (o *PatchTest) sim(fnkey, matfile string, ndim int) *bytes.Buffer {
+	var b bytes.Buffer
+	io.Ff(&b, "{\n")
+	io.Ff(&b, "  \"data\" : {\n")
+	io.Ff(&b, "    \"desc\"    : \"patch test of %s element\",\n", o.Etype)
+	io.Ff(&b, "    \"matfile\" : %q,\n", matfile)
+	io.Ff(&b, "    \"steady\"  : true\n")
+	io.Ff(&b, "  },\n")
+	io.Ff(&b, "  \"functions\" : [\n")
+	var keys, fcns []string
+	for k, key := range o.Keys {
+		g := o.Grads[k]
+		var prms []string
+		for i := 0; i < ndim; i++ {
+			prms = append(prms, io.Sf("      { \"n\":\"a%d\", \"v\":%g }", i, g[i]))
+		}
+		if ndim == 2 {
+			prms = append(prms, "      { \"n\":\"2D\", \"v\":1 }")
+		}
+		io.Ff(&b, "    { \"name\":\"%s-lin\", \"type\":\"xpoly1\", \"prms\":[\n", key)
+		io.Ff(&b, "%s]\n", strings.Join(prms, ",\n"))
+		comma := ","
+		if k == len(o.Keys)-1 {
+			comma = ""
+		}
+		io.Ff(&b, "    }%s\n", comma)
+		keys = append(keys, io.Sf("%q", key))
+		fcns = append(fcns, io.Sf("\"%s-lin\"", key))
+	}
+	io.Ff(&b, "  ],\n")
+	io.Ff(&b, "  \"regions\" : [\n")
+	io.Ff(&b, "    {\n")
+	io.Ff(&b, "      \"mshfile\"   : \"%s.msh\",\n", fnkey)
+	io.Ff(&b, "      \"elemsdata\" : [\n")
+	io.Ff(&b, "        { \"tag\":-1, \"mat\":%q, \"type\":%q, \"nip\":%d, \"extra\":%q }\n", o.Mat, o.Etype, o.Nip, o.Extra)
+	io.Ff(&b, "      ]\n")
+	io.Ff(&b, "    }\n")
+	io.Ff(&b, "  ],\n")
+	io.Ff(&b, "  \"stages\" : [\n")
+	io.Ff(&b, "    {\n")
+	io.Ff(&b, "      \"desc\" : \"linear field prescribed on boundary\",\n")
+	io.Ff(&b, "      \"mms\"  : { \"keys\":[%s], \"fcns\":[%s], \"nosrc\":true }\n", strings.Join(keys, ", "), strings.Join(fcns, ", "))
+	io.Ff(&b, "    }\n")
+	io.Ff(&b, "  ]\n")
+	io.Ff(&b, "}\n")
+	return &b
+}
+
+// patch_msh returns the mesh file of patch
+func patch_msh(X [][]float64, C [][]int, geo string) *bytes.Buffer {
+	var b bytes.Buffer
+	io.Ff(&b, "{\n")
+	io.Ff(&b, "  \"verts\" : [\n")
+	for i, x := range X {
+		comma := ","
+		if i == len(X)-1 {
+			comma = ""
+		}
+		io.Ff(&b, "    { \"id\":%3d, \"tag\":  0, \"c\":[", i)
+		for j, v := range x {
+			if j > 0 {
+				io.Ff(&b, ", ")
+			}
+			io.Ff(&b, "%23.15e", v)
+		}
+		io.Ff(&b, "] }%s\n", comma)
+	}
+	io.Ff(&b, "  ],\n")
+	io.Ff(&b, "  \"cells\" : [\n")
+	for i, c := range C {
+		comma := ","
+		if i == len(C)-1 {
+			comma = ""
+		}
+		io.Ff(&b, "    { \"id\":%3d, \"tag\": -1, \"type\":%q, \"part\":  0, \"verts\":[", i, geo)
+		for j, v := range c {
+			if j > 0 {
+				io.Ff(&b, ", ")
+			}
+			io.Ff(&b, "%3d", v)
+		}
+		io.Ff(&b, "] }%s\n", comma)
+	}
+	io.Ff(&b, "  ]\n")
+	io.Ff(&b, "}\n")
+	return &b
+}
+
+// patch_avg returns the average of the coordinates of vertices (ids)
+func patch_avg(X [][]float64, ids []int) (x []float64) {
+	x = make([]float64, len(X[0]))
+	for _, id := range ids {
+		for i := range x {
+			x[i] += X[id][i] / float64(len(ids))
+		}
+	}
+	return
+}
@@ -12,9 +12,11 @@ import (
 	"github.com/cpmech/gofem/ele"
 	"github.com/cpmech/gofem/ele/porous"
 	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/mdl/retention"
 	"github.com/cpmech/gofem/tests"
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/plt"
 	"github.com/cpmech/gosl/utl"
@@ -341,3 +343,155 @@ func Test_up01b(tst *testing.T) {
 		plt.SaveD("/tmp/gofem", "fig_up01.eps")
 	}
 }
+
+func Test_up01c(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("up01c. Solid-Liquid coupling. Staggered solution with fixed-stress split")
+
+	// monolithic solution
+	main := fem.NewMain("data/up01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	Yref := append([]float64{}, main.Domains[0].Sol.Y...)
+
+	// staggered solution
+	main = fem.NewMain("data/up01.sim", "", true, false, false, false, chk.Verbose, 0)
+	main.Sim.Solver.Split = true
+	err = main.Run()
+	if err != nil {
+		tst.Errorf("Run with staggered solution failed:\n%v", err)
+		return
+	}
+	dom := main.Domains[0]
+	if dom.Split == nil {
+		tst.Errorf("fixed-stress split should have been created\n")
+		return
+	}
+	nsteps := 1200 / 20
+	io.Pforan("total number of outer iterations = %d (%d steps)\n", dom.Split.Ntotal, nsteps)
+	if dom.Split.Ntotal < nsteps {
+		tst.Errorf("number of outer iterations is incorrect: %d < %d\n", dom.Split.Ntotal, nsteps)
+	}
+	chk.Vector(tst, "Y", 1e-5, dom.Sol.Y, Yref)
+}
+
+func Test_up01d(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("up01d. Solid-Liquid coupling. Subcycling of flow within mechanical steps")
+
+	// monolithic solution
+	main := fem.NewMain("data/up01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	Yref := append([]float64{}, main.Domains[0].Sol.Y...)
+
+	// staggered solution with subcycling
+	for _, field := range []string{"flow", "mech"} {
+		main = fem.NewMain("data/up01.sim", "", true, false, false, false, chk.Verbose, 0)
+		main.Sim.Solver.Split = true
+		main.Sim.Stages[0].Subcycle = &inp.SubcycleData{Field: field, Nsub: 4}
+		err = main.Run()
+		if err != nil {
+			tst.Errorf("Run with subcycling of %q failed:\n%v", field, err)
+			return
+		}
+		dom := main.Domains[0]
+		chk.IntAssert(dom.Split.Nsub, 4)
+
+		// the time discretisation is different => compare relative difference
+		var dmax, ymax float64
+		for i, y := range dom.Sol.Y {
+			dmax = math.Max(dmax, math.Abs(y-Yref[i]))
+			ymax = math.Max(ymax, math.Abs(Yref[i]))
+		}
+		io.Pforan("%s: outer iterations = %d  max relative difference = %g\n", field, dom.Split.Ntotal, dmax/ymax)
+		if dmax > 1e-3*ymax {
+			tst.Errorf("subcycling of %q: difference with monolithic solution is too large: %g\n", field, dmax/ymax)
+		}
+	}
+}
+
+func Test_drawdown01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("drawdown01. lowering of groundwater table in top layer")
+
+	// start simulation
+	main := fem.NewMain("data/drawdown01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.SetStage(0)
+	if err != nil {
+		tst.Errorf("SetStage failed:\n%v", err)
+		return
+	}
+	err = main.ZeroStage(0, true)
+	if err != nil {
+		tst.Errorf("ZeroStage failed:\n%v", err)
+		return
+	}
+
+	// constraints @ nodes of top layer
+	dom := main.Domains[0]
+	chk.IntAssert(len(dom.DdPl0), 6)
+	for _, vid := range []int{4, 5, 6, 7, 8, 9} {
+		nod := dom.Vid2node[vid]
+		eq := nod.GetEq("pl")
+		var fcn fun.Func
+		for _, bc := range dom.EssenBcs.Bcs {
+			if bc.Eqs[0] == eq {
+				fcn = bc.Fcn
+			}
+		}
+		if fcn == nil {
+			tst.Errorf("pl @ node %d must be prescribed\n", vid)
+			return
+		}
+		x, z := nod.Vert.C[0], nod.Vert.C[1]
+		liq := *main.Sim.LiqMdl
+		liq.H = 2.5 - x*0.5/0.75
+		plw, _ := liq.Calc(z)
+		chk.Scalar(tst, io.Sf("pl0 @ %d", vid), 1e-15, fcn.F(0, nil), dom.Sol.Y[eq])
+		chk.Scalar(tst, io.Sf("plw @ %d", vid), 1e-12, fcn.F(100, nil), plw)
+		chk.Scalar(tst, io.Sf("pl(50) @ %d", vid), 1e-12, fcn.F(50, nil), (dom.Sol.Y[eq]+plw)/2)
+	}
+	if _, ok := dom.DdPl0[dom.Vid2node[0].GetEq("pl")]; ok {
+		tst.Errorf("pl @ node 0 must not be lowered\n")
+	}
+}
+
+func Test_up02(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("up02. Solid-Liquid coupling with swelling. Check Kb")
+
+	// start simulation
+	main := fem.NewMain("data/up02.sim", "", true, false, false, false, chk.Verbose, 0)
+
+	// check Kb, including the swelling term of Kup
+	tests.SolidLiquid(main, &tests.Kb{
+		Tst: tst, Eid: 3, Tol: 1e-7, Tol2: 1e-5, Verb: chk.Verbose,
+		Ni: -1, Nj: -1, ItMin: 1, ItMax: -1, Tmin: -1, Tmax: -1,
+	})
+
+	// run simulation
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// swelling strains are active
+	e := main.Domains[0].Elems[3].(*porous.SolidLiquid)
+	if e.Swell == nil {
+		tst.Errorf("swelling model should have been allocated\n")
+		return
+	}
+	io.Pforan("εsw = %v\n", e.U.EigV)
+}
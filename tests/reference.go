@@ -0,0 +1,284 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpmech/gofem/ele"
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// RefTol holds the tolerances of a field; i.e. the comparison passes if
+//   |num - ref| ≤ Abs + Rel・|ref|
+type RefTol struct {
+	Abs float64 `json:"abs"` // absolute tolerance
+	Rel float64 `json:"rel"` // relative tolerance
+}
+
+// RefNode holds the reference value of a dof at a node
+type RefNode struct {
+	Vid int     `json:"vid"` // vertex id
+	Key string  `json:"key"` // dof key; e.g. "ux", "pl"
+	Val float64 `json:"val"` // reference value
+}
+
+// RefIp holds the reference value of a state variable at an integration point
+type RefIp struct {
+	Cid int     `json:"cid"` // cell id
+	Ip  int     `json:"ip"`  // index of integration point
+	Key string  `json:"key"` // key of integration point value; e.g. "sx", "pl"
+	Val float64 `json:"val"` // reference value
+}
+
+// RefTime holds reference values at an output time
+type RefTime struct {
+	T     float64    `json:"t"`     // time
+	Nodes []*RefNode `json:"nodes"` // values at nodes
+	Ips   []*RefIp   `json:"ips"`   // values at integration points
+}
+
+// Reference holds reference results of a simulation; e.g. analytical solutions of benchmarks or
+// results from trusted runs (regression tests)
+//  Note: (1) the tolerances are given for each key; "*" holds the default tolerances
+//        (2) the reference times are matched with the output times within Ttol
+type Reference struct {
+	Desc  string             `json:"desc"`  // description; e.g. source of reference results
+	Tols  map[string]*RefTol `json:"tols"`  // tolerances for each key
+	Ttol  float64            `json:"ttol"`  // tolerance to match output times. default = 1e-8
+	Times []*RefTime         `json:"times"` // reference values at output times
+}
+
+// RefDiff holds the result of comparing one reference value
+type RefDiff struct {
+	T     float64 // time
+	Where string  // location; e.g. "node 3" or "cell 2, ip 1"
+	Key   string  // key of value
+	Ref   float64 // reference value
+	Num   float64 // numerical value
+	Err   float64 // |num - ref|
+	Tol   float64 // Abs + Rel・|ref|
+	Ok    bool    // comparison passed
+	Found bool    // value was found in results
+}
+
+// RefReport holds the results of comparing a simulation with reference results
+type RefReport struct {
+	Desc  string     // description of reference results
+	Diffs []*RefDiff // all comparisons
+	Nfail int        // number of failed comparisons
+}
+
+// ReadReference reads reference results from a JSON file
+func ReadReference(filename string) (o *Reference, err error) {
+	buf, err := io.ReadFile(filename)
+	if err != nil {
+		return nil, chk.Err("cannot read reference file %q:\n%v", filename, err)
+	}
+	o = new(Reference)
+	err = json.Unmarshal(buf, o)
+	if err != nil {
+		return nil, chk.Err("cannot unmarshal reference file %q:\n%v", filename, err)
+	}
+	if o.Ttol <= 0 {
+		o.Ttol = 1e-8
+	}
+	return
+}
+
+// NewReference records reference results from the output files of a (trusted) simulation
+//  Input:
+//   dom    -- domain; results are read into it
+//   sum    -- summary of simulation
+//   times  -- output times to be recorded
+//   vids   -- vertices ids
+//   ykeys  -- dof keys to be recorded at vertices
+//   cids   -- cells ids
+//   ipkeys -- keys of integration points values to be recorded at cells
+func NewReference(desc string, dom *fem.Domain, sum *fem.Summary, times []float64, vids []int, ykeys []string, cids []int, ipkeys []string) (o *Reference, err error) {
+	o = &Reference{Desc: desc, Tols: map[string]*RefTol{"*": &RefTol{Abs: 1e-10, Rel: 1e-8}}, Ttol: 1e-8}
+	for _, t := range times {
+		err = ref_read(dom, sum, t, o.Ttol)
+		if err != nil {
+			return
+		}
+		rt := &RefTime{T: t}
+		for _, vid := range vids {
+			for _, key := range ykeys {
+				if v, ok := ref_node_val(dom, vid, key); ok {
+					rt.Nodes = append(rt.Nodes, &RefNode{vid, key, v})
+				}
+			}
+		}
+		for _, cid := range cids {
+			M, nip := ref_ips_vals(dom, cid)
+			for ip := 0; ip < nip; ip++ {
+				for _, key := range ipkeys {
+					if _, ok := (*M)[key]; ok {
+						rt.Ips = append(rt.Ips, &RefIp{cid, ip, key, M.Get(key, ip)})
+					}
+				}
+			}
+		}
+		o.Times = append(o.Times, rt)
+	}
+	return
+}
+
+// Save saves reference results to a JSON file
+func (o *Reference) Save(filename string) (err error) {
+	buf, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return chk.Err("cannot marshal reference results:\n%v", err)
+	}
+	io.WriteFileVD(filepath.Dir(filename), filepath.Base(filename), bytes.NewBuffer(buf))
+	return
+}
+
+// Tol returns the tolerances of key
+func (o *Reference) Tol(key string) *RefTol {
+	if tol, ok := o.Tols[key]; ok {
+		return tol
+	}
+	if tol, ok := o.Tols["*"]; ok {
+		return tol
+	}
+	return &RefTol{Abs: 1e-10, Rel: 1e-8}
+}
+
+// CompareReference compares the results of a simulation (read from output files) with reference results
+func CompareReference(dom *fem.Domain, sum *fem.Summary, ref *Reference) (o *RefReport, err error) {
+	o = &RefReport{Desc: ref.Desc}
+	for _, rt := range ref.Times {
+		err = ref_read(dom, sum, rt.T, ref.Ttol)
+		if err != nil {
+			return
+		}
+		for _, r := range rt.Nodes {
+			num, found := ref_node_val(dom, r.Vid, r.Key)
+			o.add(rt.T, io.Sf("node %d", r.Vid), r.Key, r.Val, num, found, ref.Tol(r.Key))
+		}
+		cid := -1
+		var M *ele.IpsMap
+		var nip int
+		for _, r := range rt.Ips {
+			if r.Cid != cid {
+				cid = r.Cid
+				M, nip = ref_ips_vals(dom, cid)
+			}
+			var num float64
+			found := false
+			if _, ok := (*M)[r.Key]; ok && r.Ip < nip {
+				num, found = M.Get(r.Key, r.Ip), true
+			}
+			o.add(rt.T, io.Sf("cell %d, ip %d", r.Cid, r.Ip), r.Key, r.Val, num, found, ref.Tol(r.Key))
+		}
+	}
+	return
+}
+
+// String returns the diff report as a table; only failed comparisons are listed if onlyFailed
+func (o *RefReport) String(onlyFailed bool) string {
+	var b bytes.Buffer
+	io.Ff(&b, "%s\n", o.Desc)
+	io.Ff(&b, "%13s%18s%6s%23s%23s%13s%13s%6s\n", "t", "where", "key", "ref", "num", "err", "tol", "ok")
+	for _, d := range o.Diffs {
+		if onlyFailed && d.Ok {
+			continue
+		}
+		status := "ok"
+		if !d.Found {
+			status = "N/A"
+		} else if !d.Ok {
+			status = "FAIL"
+		}
+		io.Ff(&b, "%13g%18s%6s%23.15e%23.15e%13.6e%13.6e%6s\n", d.T, d.Where, d.Key, d.Ref, d.Num, d.Err, d.Tol, status)
+	}
+	io.Ff(&b, "%d comparisons; %d failed\n", len(o.Diffs), o.Nfail)
+	return b.String()
+}
+
+// CheckReference compares the results of a simulation that has been run with reference results
+// given in a file and reports the failed comparisons
+func CheckReference(tst *testing.T, main *fem.Main, reffile string, verbose bool) {
+	ref, err := ReadReference(reffile)
+	if err != nil {
+		tst.Errorf("CheckReference failed:\n%v", err)
+		return
+	}
+	rpt, err := CompareReference(main.Domains[0], main.Summary, ref)
+	if err != nil {
+		tst.Errorf("CheckReference failed:\n%v", err)
+		return
+	}
+	if verbose {
+		io.Pf("%s", rpt.String(false))
+	}
+	if rpt.Nfail > 0 {
+		tst.Errorf("comparison with reference results failed:\n%s", rpt.String(true))
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// add adds comparison to report
+func (o *RefReport) add(t float64, where, key string, ref, num float64, found bool, tol *RefTol) {
+	d := &RefDiff{T: t, Where: where, Key: key, Ref: ref, Num: num, Found: found}
+	d.Err = math.Abs(num - ref)
+	d.Tol = tol.Abs + tol.Rel*math.Abs(ref)
+	d.Ok = found && d.Err <= d.Tol
+	if !d.Ok {
+		o.Nfail++
+	}
+	o.Diffs = append(o.Diffs, d)
+}
+
+// ref_read reads the results corresponding to output time t
+func ref_read(dom *fem.Domain, sum *fem.Summary, t, ttol float64) (err error) {
+	if sum == nil {
+		return chk.Err("summary is not available")
+	}
+	for tidx, tout := range sum.OutTimes {
+		if math.Abs(tout-t) <= ttol {
+			err = dom.Read(sum, tidx, 0, true)
+			if err != nil {
+				return chk.Err("cannot read results at t = %g:\n%v", t, err)
+			}
+			return
+		}
+	}
+	return chk.Err("cannot find output time matching t = %g", t)
+}
+
+// ref_node_val returns the value of dof at vertex
+func ref_node_val(dom *fem.Domain, vid int, key string) (val float64, found bool) {
+	if vid < 0 || vid >= len(dom.Vid2node) || dom.Vid2node[vid] == nil {
+		return
+	}
+	eq := dom.Vid2node[vid].GetEq(key)
+	if eq < 0 {
+		return
+	}
+	return dom.Sol.Y[eq], true
+}
+
+// ref_ips_vals returns the values at the integration points of cell
+func ref_ips_vals(dom *fem.Domain, cid int) (M *ele.IpsMap, nip int) {
+	M = ele.NewIpsMap()
+	if cid < 0 || cid >= len(dom.Cid2elem) {
+		return
+	}
+	if e, ok := dom.Cid2elem[cid].(ele.CanOutputIps); ok {
+		nip = len(e.OutIpCoords())
+		e.OutIpVals(M, dom.Sol)
+	}
+	return
+}
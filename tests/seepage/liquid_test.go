@@ -5,11 +5,13 @@
 package main
 
 import (
+	"math"
 	"sort"
 	"testing"
 
 	"github.com/cpmech/gofem/ele/seepage"
 	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/tests"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
@@ -167,6 +169,64 @@ func Test_p01b(tst *testing.T) {
 	// TODO: add check here
 }
 
+func Test_p01c(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("p01c. Liquid. Water balance")
+
+	// run simulation
+	main := fem.NewMain("data/p01.sim", "", true, false, false, false, chk.Verbose, 0)
+	main.Sim.Data.WaterBal = true
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// check: the column is drained from the bottom
+	wb := main.Domains[0].WBal
+	if chk.Verbose {
+		io.Pf("%s", wb.Report())
+	}
+	chk.IntAssert(len(wb.Records), 100)
+	r := wb.Records[len(wb.Records)-1]
+	if r.Mass >= wb.Mass0 || r.Vin >= 0 || r.Qess >= 0 {
+		tst.Errorf("liquid must flow out of the column: mass0=%g mass=%g Vin=%g Qess=%g\n", wb.Mass0, r.Mass, r.Vin, r.Qess)
+		return
+	}
+	chk.Scalar(tst, "Qnat", 1e-15, r.Qnat, 0)
+	if math.Abs(r.RelErr) > 0.1 {
+		tst.Errorf("relative water balance error is too large: %g\n", r.RelErr)
+	}
+}
+
+func Test_p01d(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("p01d. Liquid. Hydraulic gradients")
+
+	// run simulation
+	main := fem.NewMain("data/p01.sim", "", true, false, false, false, chk.Verbose, 0)
+	main.Sim.Stages[0].Piping = &inp.PipingData{Ftags: []int{-10}, Icrit: 1}
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// check: the column is drained from the bottom => liquid exits across the bottom face
+	pip := main.Domains[0].Pip
+	if chk.Verbose {
+		io.Pf("%s", pip.Report())
+	}
+	chk.IntAssert(len(pip.Records), 100)
+	r := pip.Records[len(pip.Records)-1]
+	chk.Scalar(tst, "rmax", 1e-15, r.Rmax, r.Imax)
+	if r.Iexit[0] <= 0 || r.Iexit[0] > r.Imax+1e-15 {
+		tst.Errorf("exit gradient at bottom must be positive and not larger than imax: iexit=%g imax=%g\n", r.Iexit[0], r.Imax)
+	}
+}
+
 func Test_p02(tst *testing.T) {
 
 	//tests.Verbose()
@@ -14,6 +14,7 @@ import (
 	"github.com/cpmech/gofem/tests"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
 )
 
 func Test_bh16a(tst *testing.T) {
@@ -317,3 +318,168 @@ func Test_bh14erod(tst *testing.T) {
 	tols := 1e-13
 	tests.CompareResults(tst, "data/bh14erod.sim", "cmp/bh14.cmp", "", tolK, tolu, tols, skipK, chk.Verbose, nil)
 }
+
+func Test_bh14e(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("bh14e. truss. sensitivities of compliance and displacement")
+
+	// start simulation
+	main := fem.NewMain("data/bh14adj.sim", "sens", true, false, false, false, chk.Verbose, 0)
+	main.KeepLinSol = true
+	defer main.Clean()
+	err := main.SetStage(0)
+	if err != nil {
+		tst.Errorf("SetStage failed:\n%v", err)
+		return
+	}
+	dom := main.Domains[0]
+
+	// solve with areas A1 and A3
+	solve := func(A1, A3 float64) (C, u float64, dCds, duds map[int]float64) {
+		dom.Sim.PrmAdjust(1, A1)
+		dom.Sim.PrmAdjust(2, 200000)
+		dom.Sim.PrmAdjust(3, A3)
+		dom.Sim.PrmAdjust(666, -150000)
+		dom.RecomputeKM()
+		err := main.SolveOneStage(0, true)
+		if err != nil {
+			tst.Errorf("SolveOneStage failed:\n%v", err)
+			return
+		}
+		sens, err := fem.NewSensitivity(dom, nil, 1)
+		if err != nil {
+			tst.Errorf("NewSensitivity failed:\n%v", err)
+			return
+		}
+		C, dCds, err = sens.Compliance()
+		if err != nil {
+			tst.Errorf("Compliance failed:\n%v", err)
+			return
+		}
+		u, duds, err = sens.Displacement(1, "uy")
+		if err != nil {
+			tst.Errorf("Displacement failed:\n%v", err)
+			return
+		}
+		return
+	}
+	A1, A3 := 4000.0, 2000.0
+	C, u, dCds, duds := solve(A1, A3)
+	if tst.Failed() {
+		return
+	}
+	io.Pforan("C = %v  u = %v\n", C, u)
+	io.Pforan("dCds = %v\n", dCds)
+	io.Pforan("duds = %v\n", duds)
+
+	// compliance = fᵀu
+	chk.Scalar(tst, "C", 1e-8, C, -150000*u)
+
+	// compare with central differences: dJ/dA = Σ dJ/ds_e / A
+	h := 1e-3
+	Cp, up, _, _ := solve(A1+h, A3)
+	Cm, um, _, _ := solve(A1-h, A3)
+	chk.AnaNum(tst, "dC/dA1", 1e-6, (dCds[0]+dCds[1])/A1, (Cp-Cm)/(2*h), chk.Verbose)
+	chk.AnaNum(tst, "du/dA1", 1e-10, (duds[0]+duds[1])/A1, (up-um)/(2*h), chk.Verbose)
+	Cp, up, _, _ = solve(A1, A3+h)
+	Cm, um, _, _ = solve(A1, A3-h)
+	chk.AnaNum(tst, "dC/dA3", 1e-6, dCds[4]/A3, (Cp-Cm)/(2*h), chk.Verbose)
+	chk.AnaNum(tst, "du/dA3", 1e-10, duds[4]/A3, (up-um)/(2*h), chk.Verbose)
+}
+
+func Test_bh14f(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("bh14f. truss. load cases and combinations")
+
+	// run simulation
+	main := fem.NewMain("data/bh14lc.sim", "", true, true, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// check summary
+	sum := main.Summary
+	io.Pforan("names = %v\n", sum.LcNames)
+	chk.Strings(tst, "names", sum.LcNames, []string{"vertical load", "horizontal load", "vert", "comb1", "comb2"})
+	chk.Vector(tst, "times", 1e-17, sum.OutTimes, []float64{0, 1, 2, 3, 4})
+
+	// read results
+	dom := fem.NewDomains(main.Sim, main.DynCfs, 0, 1, false, false)[0]
+	err = dom.SetStage(0)
+	if err != nil {
+		tst.Errorf("SetStage failed:\n%v", err)
+		return
+	}
+	Y := make([][]float64, len(sum.OutTimes))
+	for tidx := range sum.OutTimes {
+		err = dom.Read(sum, tidx, 0, true)
+		if err != nil {
+			tst.Errorf("Read failed:\n%v", err)
+			return
+		}
+		Y[tidx] = make([]float64, dom.Ny)
+		copy(Y[tidx], dom.Sol.Y)
+	}
+
+	// vertical load case compared with Bhatti's solution
+	eqx := dom.Vid2node[1].GetEq("ux")
+	eqy := dom.Vid2node[1].GetEq("uy")
+	io.Pforan("case 0: u1 = %v %v\n", Y[0][eqx], Y[0][eqy])
+	chk.Scalar(tst, "ux @ 1", 1e-15, Y[0][eqx], 0.5389536380057676)
+	chk.Scalar(tst, "uy @ 1", 1e-15, Y[0][eqy], -0.9530613006371175)
+
+	// combinations
+	for j, comb := range main.Sim.LoadCases.Combs {
+		res := make([]float64, dom.Ny)
+		for i, f := range comb.Factors {
+			for k := 0; k < dom.Ny; k++ {
+				res[k] += f * Y[i][k]
+			}
+		}
+		chk.Vector(tst, comb.Name, 1e-15, Y[2+j], res)
+	}
+
+	// envelope of displacements
+	env := sum.Envelopes[0]
+	uy := []float64{Y[2][eqy], Y[3][eqy], Y[4][eqy]}
+	io.Pforan("uy = %v\n", uy)
+	chk.Ints(tst, "ids", env.Ids, []int{1})
+	chk.Scalar(tst, "max(uy)", 1e-15, env.Max[0], utl.Max(uy[0], utl.Max(uy[1], uy[2])))
+	chk.Scalar(tst, "min(uy)", 1e-15, env.Min[0], utl.Min(uy[0], utl.Min(uy[1], uy[2])))
+
+	// envelope of stresses in rods with tag = -1
+	for _, cid := range sum.Envelopes[1].Ids {
+		if cid != 0 && cid != 1 {
+			tst.Errorf("envelope of stresses must have cells 0 and 1 only. %d is invalid\n", cid)
+			return
+		}
+	}
+}
+
+func Test_bh14g(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("bh14g. truss. constraints by elimination and penalty")
+
+	// elimination
+	main := fem.NewMain("data/bh14elim.sim", "", true, true, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	tests.CompareResults(tst, "data/bh14elim.sim", "cmp/bh14.cmp", "", 1e-10, 1e-13, 1e-10, false, chk.Verbose, nil)
+
+	// penalty
+	main = fem.NewMain("data/bh14pen.sim", "", true, true, false, false, chk.Verbose, 0)
+	err = main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	tests.CompareResults(tst, "data/bh14pen.sim", "cmp/bh14.cmp", "", 1e-10, 1e-8, 1e-6, false, chk.Verbose, nil)
+}
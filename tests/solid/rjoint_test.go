@@ -5,11 +5,17 @@
 package main
 
 import (
+	"math"
 	"testing"
 
+	"github.com/cpmech/gofem/ana"
+	"github.com/cpmech/gofem/ele/solid"
 	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/out"
 	"github.com/cpmech/gofem/tests"
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
 )
 
 func Test_rjoint01(tst *testing.T) {
@@ -36,3 +42,150 @@ func Test_rjoint01(tst *testing.T) {
 		return
 	}
 }
+
+func Test_rjoint02(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("rjoint02. profiles of bond stress, slip and axial force")
+
+	// run simulation
+	main := fem.NewMain("data/rjoint01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// profiles
+	out.Start("data/rjoint01.sim", 0, 0)
+	out.LoadResults(nil)
+	lines := out.BondProfiles()
+	if len(lines) != 1 {
+		tst.Errorf("number of lines is incorrect: %d != 1", len(lines))
+		return
+	}
+	line := lines[0]
+	chk.Ints(tst, "cids", line.Cids, []int{2})
+	io.Pforan("L = %v\nS = %v\n", line.L, line.S)
+	if line.S[0] < 0 || line.S[len(line.S)-1] > line.L {
+		tst.Errorf("parametric coordinates must be within [0, L]")
+		return
+	}
+	for i := 1; i < len(line.S); i++ {
+		if line.S[i] < line.S[i-1] {
+			tst.Errorf("parametric coordinates must be sorted")
+			return
+		}
+	}
+
+	// monotonic loading: τ = ks ω where the bond is still elastic
+	ks, τy0 := 2000.0, 1.0
+	for t := 0; t < len(out.Times); t++ {
+		io.Pfyel("t = %g  τ = %v  ω = %v  N = %v\n", out.Times[t], line.Tau[t], line.Slip[t], line.N[t])
+		for i, τ := range line.Tau[t] {
+			if math.Abs(τ) < τy0 {
+				chk.Scalar(tst, "τ", 1e-8, τ, ks*line.Slip[t][i])
+			}
+		}
+	}
+}
+
+func Test_rjoint03(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("rjoint03. pull-out test versus analytical solution")
+
+	// generate model
+	var dat inp.PullOutData
+	dat.SetDefault()
+	dat.Nl, dat.Nsteps = 16, 8
+	err := dat.Write("/tmp/gofem/pullout", "pullout")
+	if err != nil {
+		tst.Errorf("Write failed:\n%v", err)
+		return
+	}
+
+	// run simulation
+	main := fem.NewMain("/tmp/gofem/pullout/pullout.sim", "", true, false, false, false, chk.Verbose, 0)
+	err = main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// analytical solution
+	var sol ana.PullOut
+	err = sol.Init(dat.AnaPrms())
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	io.Pforan("Py = %v  Pu = %v  Pmax = %v\n", sol.Yield(), sol.Limit(), dat.Pmax)
+
+	// force-displacement curve of the loaded end
+	out.Start("/tmp/gofem/pullout/pullout.sim", 0, 0)
+	out.Define("A", out.N{inp.PULLOUT_LOAD})
+	out.LoadResults(nil)
+	uy := out.GetRes("uy", "A", 0)
+	for i, t := range out.Times {
+		u, a, err := sol.Displ(dat.Pmax * t)
+		if err != nil {
+			tst.Errorf("Displ failed:\n%v", err)
+			return
+		}
+		io.Pfyel("P = %6.3f  a = %6.3f  u = %12.5e  uana = %12.5e\n", dat.Pmax*t, a, uy[i], u)
+		chk.Scalar(tst, "u", 0.03*u+1e-12, uy[i], u)
+	}
+}
+
+func Test_rjoint04(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("rjoint04. rod crossing two solids")
+
+	// initialisation
+	main := fem.NewMain("data/rjoint04.sim", "", true, false, false, false, chk.Verbose, 0)
+
+	// callback to check consistent tangent operators
+	tests.Rjoint(main, &tests.Kb{
+		Tst: tst, Eid: 3, Tol: 1e-8, Verb: chk.Verbose,
+		Ni: -1, Nj: -1, ItMin: 1, ItMax: -1, Tmin: -1, Tmax: -1,
+	})
+
+	// run simulation
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// segments: rod from x = -0.6 to x = 0.9 crosses the boundary between solids at x = 0
+	dom := main.Domains[0]
+	e := dom.Cid2elem[3].(*solid.Rjoint)
+	if len(e.Segs) != 2 {
+		tst.Errorf("number of segments is incorrect: %d != 2", len(e.Segs))
+		return
+	}
+	chk.Ints(tst, "solids", []int{e.Segs[0].Sld.Id(), e.Segs[1].Sld.Id()}, []int{0, 1})
+	chk.Scalar(tst, "Sa0", 1e-15, e.Segs[0].Sa, -1)
+	chk.Scalar(tst, "Sb0", 1e-8, e.Segs[0].Sb, -0.2)
+	chk.Scalar(tst, "Sa1", 1e-15, e.Segs[1].Sa, e.Segs[0].Sb)
+	chk.Scalar(tst, "Sb1", 1e-15, e.Segs[1].Sb, 1)
+
+	// integration points of segments
+	nip := len(e.Rod.IpsElem)
+	chk.IntAssert(e.Nip, 2*nip)
+	for i, seg := range e.Segs {
+		chk.IntAssert(len(seg.Ips), nip)
+		chk.IntAssert(seg.Ip0, i*nip)
+		var L float64
+		for _, ip := range seg.Ips {
+			if ip[0] < seg.Sa || ip[0] > seg.Sb {
+				tst.Errorf("integration point (s=%g) is outside segment [%g, %g]", ip[0], seg.Sa, seg.Sb)
+				return
+			}
+			L += ip[3]
+		}
+		chk.Scalar(tst, io.Sf("sum of weights of segment %d", i), 1e-14, L, seg.Sb-seg.Sa)
+	}
+}
@@ -71,3 +71,37 @@ func Test_bridge01b(tst *testing.T) {
 	tols := 1e-9
 	tests.CompareResults(tst, "data/bridge01erod.sim", "cmp/bridge01.cmp", "", tolK, tolu, tols, skipK, chk.Verbose, nil)
 }
+
+func Test_rod01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("rod01. temperature and prestrain loading")
+
+	// fem
+	main := fem.NewMain("data/rodtemp.sim", "", true, false, false, false, chk.Verbose, 0)
+
+	// run simulation
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// clamped rods: σ = -E α ΔT
+	dom := main.Domains[0]
+	E, α, ΔT := 1000.0, 1e-3, 100.0
+	for _, eid := range []int{0, 1} {
+		e := dom.Cid2elem[eid].(*solid.Rod)
+		for _, s := range e.States {
+			chk.Scalar(tst, "σ", 1e-10, s.Sig, -E*α*ΔT)
+		}
+	}
+	chk.Scalar(tst, "ux1", 1e-15, dom.Sol.Y[dom.Vid2node[1].GetEq("ux")], 0)
+
+	// free rod: u = ε0 L and σ = 0
+	e := dom.Cid2elem[2].(*solid.Rod)
+	for _, s := range e.States {
+		chk.Scalar(tst, "σ", 1e-10, s.Sig, 0)
+	}
+	chk.Scalar(tst, "ux4", 1e-15, dom.Sol.Y[dom.Vid2node[4].GetEq("ux")], 0.01*2.0)
+}
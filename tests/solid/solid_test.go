@@ -5,11 +5,13 @@
 package main
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gofem/ana"
 	"github.com/cpmech/gofem/ele/solid"
 	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/tests"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
@@ -203,6 +205,31 @@ func Test_selfweight01(tst *testing.T) {
 	}
 }
 
+func Test_selfweight03(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("selfweight03. self-weight as body acceleration")
+
+	// fem
+	main := fem.NewMain("data/selfweight03.sim", "", true, true, false, false, chk.Verbose, 0)
+
+	// run simulation
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed\n%v", err)
+		return
+	}
+
+	// displacement @ top: same as selfweight01
+	dom := main.Domains[0]
+	nod := dom.Vid2node[0]
+	eqy := nod.GetEq("uy")
+	uy := dom.Sol.Y[eqy]
+	uy_cor := -8.737017006803450E-05
+	io.Pforan("uy @ top = %v (%v)\n", uy, uy_cor)
+	chk.Scalar(tst, "uy @ top", 1e-11, uy, uy_cor)
+}
+
 func Test_selfweight02(tst *testing.T) {
 
 	//tests.Verbose()
@@ -254,3 +281,352 @@ func Test_selfweight02(tst *testing.T) {
 		sol.CheckStress(tst, t, σ, x, tols)
 	}
 }
+
+func Test_tie01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("tie01. non-matching blocks glued by tie constraints")
+
+	// fem
+	main := fem.NewMain("data/tie01.sim", "", true, false, false, false, chk.Verbose, 0)
+
+	// run simulation
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed\n%v", err)
+		return
+	}
+
+	// domain
+	dom := main.Domains[0]
+
+	// tie constraints: slave vertex 6 has ux = 0 already
+	ntie := 0
+	for _, c := range dom.EssenBcs.Bcs {
+		if c.Key == "tie" {
+			ntie++
+		}
+	}
+	chk.IntAssert(ntie, 7)
+
+	// solution
+	var sol ana.CteStressPstrain
+	sol.Init(fun.Prms{
+		&fun.Prm{N: "qnH", V: -50},
+		&fun.Prm{N: "qnV", V: -100},
+		&fun.Prm{N: "lx", V: 1.5},
+		&fun.Prm{N: "ly", V: 2.0},
+	})
+
+	// check displacements: constant strain state must be reproduced (patch test)
+	t := dom.Sol.T
+	tolu := 1e-14
+	for _, n := range dom.Nodes {
+		eqx := n.GetEq("ux")
+		eqy := n.GetEq("uy")
+		u := []float64{dom.Sol.Y[eqx], dom.Sol.Y[eqy]}
+		io.Pfyel("u = %v\n", u)
+		sol.CheckDispl(tst, t, u, n.Vert.C, tolu)
+	}
+
+	// check stresses
+	tols := 1e-12
+	for _, ele := range dom.ElemIntvars {
+		e := ele.(*solid.Solid)
+		for idx, ip := range e.IpsElem {
+			x := e.Cell.Shp.IpRealCoords(e.X, ip)
+			sol.CheckStress(tst, t, e.States[idx].Sig, x, tols)
+		}
+	}
+}
+
+func Test_movload01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("movload01. moving loads on top edge")
+
+	// fem
+	main := fem.NewMain("data/movload01.sim", "", true, false, false, false, chk.Verbose, 0)
+
+	// set stage
+	err := main.SetStage(0)
+	if err != nil {
+		tst.Errorf("SetStage failed:\n%v", err)
+		return
+	}
+
+	// domain
+	dom := main.Domains[0]
+	chk.IntAssert(len(dom.MovLoads), 1)
+	ml := dom.MovLoads[0]
+	chk.Vector(tst, "x(0.25)", 1e-15, ml.Position(0.25), []float64{0.25, 1})
+	if ml.Position(1.2) != nil {
+		tst.Errorf("position outside path must be nil\n")
+		return
+	}
+
+	// vertical forces at top vertices (2 and 3)
+	eq2 := dom.Vid2node[2].GetEq("uy")
+	eq3 := dom.Vid2node[3].GetEq("uy")
+	forces := func(t float64) []float64 {
+		fb := make([]float64, dom.Ny)
+		ml.AddToRhs(fb, t)
+		return []float64{fb[eq2], fb[eq3]}
+	}
+
+	// both axles on edge: at x=0.5 and x=0.25
+	chk.Vector(tst, "f(0.25)", 1e-13, forces(0.25), []float64{-50 - 12.5, -50 - 37.5})
+
+	// first axle out of path; second one at x=0.95
+	chk.Vector(tst, "f(0.6)", 1e-13, forces(0.6), []float64{-47.5, -2.5})
+
+	// all axles out of path
+	chk.Vector(tst, "f(1.0)", 1e-15, forces(1.0), []float64{0, 0})
+}
+
+func Test_surch01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("surch01. strip and embankment surcharges")
+
+	// fem
+	main := fem.NewMain("data/surch01.sim", "", true, false, false, false, chk.Verbose, 0)
+
+	// set stage
+	err := main.SetStage(0)
+	if err != nil {
+		tst.Errorf("SetStage failed:\n%v", err)
+		return
+	}
+
+	// domain
+	dom := main.Domains[0]
+	chk.IntAssert(len(dom.Surcharges), 2)
+
+	// vertical forces at top vertices
+	forces := func(sc *fem.Surcharge) (res []float64) {
+		for _, vid := range []int{10, 11, 12, 13} {
+			res = append(res, sc.Forces[dom.Vid2node[vid].GetEq("uy")])
+		}
+		return
+	}
+
+	// strip: edges are split at x = 0.25 and x = 0.75
+	chk.Vector(tst, "strip", 1e-13, forces(dom.Surcharges[0]), []float64{-6.25, -37.5, -6.25, 0})
+
+	// embankment: linear ramps on first and last edges
+	chk.Vector(tst, "embankment", 1e-13, forces(dom.Surcharges[1]), []float64{-100.0 / 12.0, -125.0 / 3.0, -125.0 / 3.0, -100.0 / 12.0})
+}
+
+func Test_cycjump01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("cycjump01. cyclic load with cycle jumping")
+
+	// run simulation
+	main := fem.NewMain("data/cycjump01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// cycles: the state is periodic; thus jumps are only limited by njump and by the number of
+	// cycles left at the end of the stage
+	dom := main.Domains[0]
+	cj := dom.CycJump
+	io.Pforan("jumps = %v\n", cj.Jumps)
+	chk.Ints(tst, "jumps", cj.Jumps, []int{5, 5, 2})
+	chk.IntAssert(cj.Ncomp, 8)
+	chk.Scalar(tst, "t", 1e-15, dom.Sol.T, 40)
+
+	// solution at the end of last cycle: q = -100 on top
+	E, ν, q := 1000.0, 0.25, -100.0
+	uy := q * (1.0 - ν*ν) / E
+	ux := -ν * (1.0 + ν) * q / E
+	chk.Scalar(tst, "ux(2)", 1e-12, dom.Sol.Y[dom.Vid2node[2].GetEq("ux")], ux)
+	chk.Scalar(tst, "uy(2)", 1e-12, dom.Sol.Y[dom.Vid2node[2].GetEq("uy")], uy)
+	chk.Scalar(tst, "uy(3)", 1e-12, dom.Sol.Y[dom.Vid2node[3].GetEq("uy")], uy)
+}
+
+func Test_dynctrl01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("dynctrl01. quasi-static region in transient analysis")
+
+	// run simulation
+	main := fem.NewMain("data/dynctrl01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// flags of element
+	dom := main.Domains[0]
+	e := dom.Elems[0].(*solid.Solid)
+	if !e.Qsta {
+		tst.Errorf("element must be quasi-static\n")
+		return
+	}
+	chk.Scalar(tst, "mscale", 1e-15, e.Mscale, 1)
+
+	// without inertia, the solution is equal to the static one
+	E, ν, q := 1000.0, 0.25, -100.0
+	uy := q * (1.0 - ν*ν) / E
+	ux := -ν * (1.0 + ν) * q / E
+	chk.Scalar(tst, "ux(2)", 1e-12, dom.Sol.Y[dom.Vid2node[2].GetEq("ux")], ux)
+	chk.Scalar(tst, "uy(2)", 1e-12, dom.Sol.Y[dom.Vid2node[2].GetEq("uy")], uy)
+
+	// consistency checks
+	stg := main.Sim.Stages[0]
+	stg.DynCtrls = []*inp.DynCtrlData{{Tags: []int{-1}, Scheme: "dyn", Mscale: 4}}
+	err = main.SetStage(0)
+	if err != nil {
+		tst.Errorf("SetStage failed:\n%v", err)
+		return
+	}
+	chk.Scalar(tst, "mscale", 1e-15, main.Domains[0].Elems[0].(*solid.Solid).Mscale, 4)
+	stg.DynCtrls = []*inp.DynCtrlData{{Tags: []int{-1}, Scheme: "dyn", Mscale: 2}, {Tags: []int{-1}, Scheme: "qsta", Mscale: 1}}
+	if main.SetStage(0) == nil {
+		tst.Errorf("SetStage must fail because tag -1 is given twice\n")
+		return
+	}
+	stg.DynCtrls = []*inp.DynCtrlData{{Tags: []int{-5}, Scheme: "dyn", Mscale: 2}}
+	if main.SetStage(0) == nil {
+		tst.Errorf("SetStage must fail because tag -5 does not exist\n")
+		return
+	}
+}
+
+func Test_prestress01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("prestress01. stressing and locking of anchor")
+
+	// run simulation
+	main := fem.NewMain("data/prestress01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// stressing: u1 = -P/k; locking and loading: Δu1 = F/(2k)
+	k, A, P, F := 200000.0*4000.0, 4000.0, 100000.0, 50000.0
+	dom := main.Domains[0]
+	chk.Scalar(tst, "ux(1)", 1e-15, dom.Sol.Y[dom.Vid2node[1].GetEq("ux")], -P/k+F/(2*k))
+
+	// forces in anchor and spring
+	anchor := dom.Elems[0].(*solid.Rod)
+	spring := dom.Elems[1].(*solid.Rod)
+	chk.Scalar(tst, "N(anchor)", 1e-8, anchor.States[0].Sig*A, P+F/2)
+	chk.Scalar(tst, "N(spring)", 1e-8, spring.States[0].Sig*A, P-F/2)
+
+	// consistency checks
+	stg := main.Sim.Stages[0]
+	stg.Prestress = []*inp.PrestressData{{Tags: []int{-1}, Force: P}, {Tags: []int{-1}, Force: P}}
+	if main.SetStage(0) == nil {
+		tst.Errorf("SetStage must fail because tag -1 is given twice\n")
+		return
+	}
+	stg.Prestress = []*inp.PrestressData{{Tags: []int{-5}, Force: P}}
+	if main.SetStage(0) == nil {
+		tst.Errorf("SetStage must fail because tag -5 does not exist\n")
+		return
+	}
+}
+
+func Test_relax01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("relax01. excavation with relaxation before installation of lining")
+
+	// run simulation
+	main := fem.NewMain("data/relax01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// released fraction of forces
+	dom := main.Domains[0]
+	chk.Scalar(tst, "λ(0.5)", 1e-15, dom.Relax.Released(0.5), 0.2)
+	chk.Scalar(tst, "λ(1.0)", 1e-15, dom.Relax.Released(1.0), 0.4)
+	chk.Scalar(tst, "λ(1.5)", 1e-15, dom.Relax.Released(1.5), 0.7)
+	chk.Scalar(tst, "λ(2.0)", 1e-15, dom.Relax.Released(2.0), 1.0)
+	if !dom.Relax.Installed {
+		tst.Errorf("lining must be installed\n")
+		return
+	}
+
+	// displacements of excavation boundary: u = β・σ0/M + (1-β)・σ0/(M+2k)
+	// M: oedometric modulus of remaining block; k: stiffness of each lining rod
+	σ0, β, M, k := 50.0, 0.4, 1200.0, 500.0
+	uβ := β * σ0 / M
+	Δu := (1.0 - β) * σ0 / (M + 2.0*k)
+	for _, vid := range []int{1, 4} {
+		chk.Scalar(tst, io.Sf("ux(%d)", vid), 1e-12, dom.Sol.Y[dom.Vid2node[vid].GetEq("ux")], uβ+Δu)
+	}
+
+	// lining is installed stress-free: N = -k・Δu
+	for _, cid := range []int{2, 3} {
+		rod := dom.Cid2elem[cid].(*fem.LiningElem).E.(*solid.Rod)
+		chk.Scalar(tst, io.Sf("N(%d)", cid), 1e-10, rod.States[0].Sig*0.5, -k*Δu)
+	}
+
+	// consistency checks
+	stg := main.Sim.Stages[0]
+	stg.Relax = &inp.RelaxationData{Tags: []int{-2}, Lining: []int{-3}, Beta: 1.5, Tlin: 1}
+	if main.SetStage(0) == nil {
+		tst.Errorf("SetStage must fail because β is invalid\n")
+		return
+	}
+	stg.Relax = &inp.RelaxationData{Tags: []int{-2}, Lining: []int{-2}, Beta: 0.4, Tlin: 1}
+	if main.SetStage(0) == nil {
+		tst.Errorf("SetStage must fail because tag -2 is both excavated and lining\n")
+		return
+	}
+}
+
+func Test_contract01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("contract01. volume-loss controlled excavation of tunnel")
+
+	// run simulation with invert pattern
+	main := fem.NewMain("data/contract01.sim", "", true, false, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// volume loss and displacements @ invert and crown
+	Vl := 0.02
+	δ := 1.0 - math.Sqrt(1.0-Vl)
+	dom := main.Domains[0]
+	chk.Scalar(tst, "Vl(invert)", 1e-12, dom.Contracts[0].VolLoss(dom.Sol), Vl)
+	chk.Scalar(tst, "uy(invert)", 1e-12, dom.Sol.Y[dom.Vid2node[6].GetEq("uy")], 0)
+	chk.Scalar(tst, "uy(crown)", 1e-12, dom.Sol.Y[dom.Vid2node[2].GetEq("uy")], -2*δ)
+	chk.Scalar(tst, "ux(side)", 1e-12, dom.Sol.Y[dom.Vid2node[0].GetEq("ux")], -δ)
+
+	// run simulation with uniform pattern
+	main.Sim.Stages[0].Contracts[0].Mode = "uniform"
+	err = main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+	dom = main.Domains[0]
+	chk.Scalar(tst, "Vl(uniform)", 1e-12, dom.Contracts[0].VolLoss(dom.Sol), Vl)
+	chk.Scalar(tst, "uy(invert)", 1e-12, dom.Sol.Y[dom.Vid2node[6].GetEq("uy")], δ)
+	chk.Scalar(tst, "uy(crown)", 1e-12, dom.Sol.Y[dom.Vid2node[2].GetEq("uy")], -δ)
+
+	// consistency checks
+	main.Sim.Stages[0].Contracts[0].Vl = 1.2
+	if main.SetStage(0) == nil {
+		tst.Errorf("SetStage must fail because volume loss is invalid\n")
+	}
+}
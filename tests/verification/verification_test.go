@@ -0,0 +1,290 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gofem/ana"
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gofem/tests"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+func Test_terzaghi01(tst *testing.T) {
+
+	/*  Terzaghi's one-dimensional consolidation
+	 *
+	 *   The diffusion equation ρ du/dt = k ∇²u is solved with cv = k/ρ = 1
+	 *   and compared with the analytical series solution.
+	 *
+	 *       u = 0 (drained)
+	 *       ===========
+	 *       |         |
+	 *       |         |  H = 10
+	 *       |  u0 = 1 |
+	 *       |         |
+	 *       |         |
+	 *       -----------
+	 *       impermeable
+	 */
+
+	//tests.Verbose()
+	chk.PrintTitle("terzaghi01. Terzaghi's consolidation")
+
+	// run simulation
+	main := fem.NewMain("data/terzaghi.sim", "", true, true, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// check
+	tests.CheckReference(tst, main, "data/terzaghi.ref", chk.Verbose)
+
+	// compare with analytical solution at all nodes
+	var sol ana.Terzaghi
+	err = sol.Init([]*fun.Prm{
+		&fun.Prm{N: "cv", V: 1},
+		&fun.Prm{N: "H", V: 10},
+		&fun.Prm{N: "u0", V: 1},
+	})
+	if err != nil {
+		tst.Errorf("Init failed:\n%v", err)
+		return
+	}
+	tests.CheckAna(tst, main, []float64{5, 20, 50}, "u", sol.Pressure, 0.01, chk.Verbose)
+
+	// reference results generated from analytical solution
+	ref, err := tests.NewAnaReference("Terzaghi", main.Domains[0], []float64{5, 20, 50}, []int{0, 10, 28, 46, 58}, map[string]tests.AnaFcn{"u": sol.Pressure})
+	if err != nil {
+		tst.Errorf("NewAnaReference failed:\n%v", err)
+		return
+	}
+	ref.Tols["u"] = &tests.RefTol{Abs: 0.01}
+	rpt, err := tests.CompareReference(main.Domains[0], main.Summary, ref)
+	if err != nil {
+		tst.Errorf("CompareReference failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(rpt.Nfail, 0)
+}
+
+func Test_cylinder01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("cylinder01. thick cylinder under internal pressure")
+
+	// run simulation
+	main := fem.NewMain("data/cylinder.sim", "", true, true, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// check
+	tests.CheckReference(tst, main, "data/cylinder.ref", chk.Verbose)
+
+	// the comparison must detect changes in results
+	ref, err := tests.ReadReference("data/cylinder.ref")
+	if err != nil {
+		tst.Errorf("ReadReference failed:\n%v", err)
+		return
+	}
+	ref.Times[0].Nodes[0].Val *= 1.001
+	ref.Times[1].Ips[0].Val += 1e-3
+	rpt, err := tests.CompareReference(main.Domains[0], main.Summary, ref)
+	if err != nil {
+		tst.Errorf("CompareReference failed:\n%v", err)
+		return
+	}
+	chk.IntAssert(rpt.Nfail, 2)
+
+	// unknown output time
+	ref.Times[0].T = 0.55
+	_, err = tests.CompareReference(main.Domains[0], main.Summary, ref)
+	if err == nil {
+		tst.Errorf("CompareReference should have failed with unknown output time\n")
+	}
+}
+
+func Test_footing01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("footing01. strip footing collapse")
+
+	// run simulation
+	main := fem.NewMain("data/footing.sim", "", true, true, false, false, chk.Verbose, 0)
+	err := main.Run()
+	if err != nil {
+		tst.Errorf("Run failed:\n%v", err)
+		return
+	}
+
+	// check
+	tests.CheckReference(tst, main, "data/footing.ref", chk.Verbose)
+}
+
+func Test_mms01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("mms01. manufactured solution. diffusion. qua4")
+
+	// run simulations
+	res, err := tests.RunMms([]string{"data/mms-diffu4.sim", "data/mms-diffu8.sim", "data/mms-diffu16.sim"}, chk.Verbose)
+	if err != nil {
+		tst.Errorf("RunMms failed:\n%v", err)
+		return
+	}
+
+	// check: O(h²) for bilinear elements
+	tests.CheckMmsRates(tst, res, 2.0, 0.1)
+}
+
+func Test_mms02(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("mms02. manufactured solution. linear elasticity. qua4")
+
+	// run simulations
+	res, err := tests.RunMms([]string{"data/mms-elast4.sim", "data/mms-elast8.sim", "data/mms-elast16.sim"}, chk.Verbose)
+	if err != nil {
+		tst.Errorf("RunMms failed:\n%v", err)
+		return
+	}
+
+	// check: O(h²) for bilinear elements
+	tests.CheckMmsRates(tst, res, 2.0, 0.1)
+}
+
+func Test_patch01(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("patch01. patch test. linear elasticity (plane-strain)")
+
+	// linear displacements: ux = 0.002・x + 0.001・y and uy = 0.002・x + 0.003・y
+	E, ν := 1000.0, 0.25
+	λ := E * ν / ((1.0 + ν) * (1.0 - 2.0*ν))
+	G := E / (2.0 * (1.0 + ν))
+	εx, εy, εxy := 0.002, 0.003, (0.001+0.002)/2.0
+	tr := εx + εy
+
+	// constant stresses (sxy in Mandel's basis)
+	p := &tests.PatchTest{
+		Etype:   "solid",
+		Geos:    []string{"qua4", "qua8", "qua9", "tri3", "tri6"},
+		Matfile: "data/verification.mat",
+		Mat:     "mms-elast",
+		Keys:    []string{"ux", "uy"},
+		Grads:   [][]float64{{0.002, 0.001}, {0.002, 0.003}},
+		IpKeys:  []string{"sx", "sy", "sz", "sxy"},
+		IpVals:  []float64{λ*tr + 2.0*G*εx, λ*tr + 2.0*G*εy, λ * tr, math.Sqrt2 * 2.0 * G * εxy},
+		Tol:     1e-10,
+		Verbose: chk.Verbose,
+	}
+	tests.CheckPatch(tst, p)
+}
+
+func Test_patch02(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("patch02. patch test. linear elasticity (3D)")
+
+	// linear displacements
+	E, ν := 1000.0, 0.25
+	λ := E * ν / ((1.0 + ν) * (1.0 - 2.0*ν))
+	G := E / (2.0 * (1.0 + ν))
+	grads := [][]float64{
+		{0.002, 0.001, -0.001},
+		{0.002, 0.003, 0.000},
+		{0.001, -0.002, 0.001},
+	}
+	ε := func(i, j int) float64 { return (grads[i][j] + grads[j][i]) / 2.0 }
+	tr := ε(0, 0) + ε(1, 1) + ε(2, 2)
+
+	// constant stresses (shear components in Mandel's basis)
+	p := &tests.PatchTest{
+		Etype:   "solid",
+		Geos:    []string{"hex8", "hex20"},
+		Matfile: "data/verification.mat",
+		Mat:     "mms-elast",
+		Keys:    []string{"ux", "uy", "uz"},
+		Grads:   grads,
+		IpKeys:  []string{"sx", "sy", "sz", "sxy", "syz", "szx"},
+		IpVals: []float64{
+			λ*tr + 2.0*G*ε(0, 0),
+			λ*tr + 2.0*G*ε(1, 1),
+			λ*tr + 2.0*G*ε(2, 2),
+			math.Sqrt2 * 2.0 * G * ε(0, 1),
+			math.Sqrt2 * 2.0 * G * ε(1, 2),
+			math.Sqrt2 * 2.0 * G * ε(2, 0),
+		},
+		Tol:     1e-10,
+		Verbose: chk.Verbose,
+	}
+	tests.CheckPatch(tst, p)
+}
+
+func Test_patch03(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("patch03. patch test. diffusion")
+
+	// linear field u = 2・x + 3・y => constant flux w = -k・∇u with k = 1
+	p := &tests.PatchTest{
+		Etype:   "diffusion",
+		Geos:    []string{"qua4", "qua8", "qua9", "tri3", "tri6"},
+		Matfile: "data/verification.mat",
+		Mat:     "consol",
+		Keys:    []string{"u"},
+		Grads:   [][]float64{{2, 3}},
+		IpKeys:  []string{"wx", "wy"},
+		IpVals:  []float64{-2, -3},
+		Tol:     1e-10,
+		Verbose: chk.Verbose,
+	}
+	tests.CheckPatch(tst, p)
+}
+
+func Test_patch04(tst *testing.T) {
+
+	//tests.Verbose()
+	chk.PrintTitle("patch04. patch test. one-point qua4 and hex8 with hourglass control")
+
+	// linear displacements: ux = 0.002・x + 0.001・y and uy = 0.002・x + 0.003・y
+	E, ν := 1000.0, 0.25
+	λ := E * ν / ((1.0 + ν) * (1.0 - 2.0*ν))
+	G := E / (2.0 * (1.0 + ν))
+	εx, εy, εxy := 0.002, 0.003, (0.001+0.002)/2.0
+	tr := εx + εy
+
+	// 2D: hourglass forces must vanish for linear fields
+	p := &tests.PatchTest{
+		Etype:   "solid",
+		Geos:    []string{"qua4"},
+		Matfile: "data/verification.mat",
+		Mat:     "mms-elast",
+		Nip:     1,
+		Keys:    []string{"ux", "uy"},
+		Grads:   [][]float64{{0.002, 0.001}, {0.002, 0.003}},
+		IpKeys:  []string{"sx", "sy", "sz", "sxy"},
+		IpVals:  []float64{λ*tr + 2.0*G*εx, λ*tr + 2.0*G*εy, λ * tr, math.Sqrt2 * 2.0 * G * εxy},
+		Tol:     1e-10,
+		Verbose: chk.Verbose,
+	}
+	tests.CheckPatch(tst, p)
+
+	// 3D: uniaxial strain
+	p.Geos = []string{"hex8"}
+	p.Keys = []string{"ux", "uy", "uz"}
+	p.Grads = [][]float64{{0.001, 0, 0}, {0, 0, 0}, {0, 0, 0}}
+	p.IpKeys = []string{"sx", "sy", "sz", "sxy", "syz", "szx"}
+	p.IpVals = []float64{(λ + 2.0*G) * 0.001, λ * 0.001, λ * 0.001, 0, 0, 0}
+	tests.CheckPatch(tst, p)
+}
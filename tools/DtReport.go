@@ -0,0 +1,40 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ignore
+
+package main
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gosl/io"
+)
+
+func main() {
+
+	// catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			io.PfRed("ERROR: %v\n", err)
+		}
+	}()
+
+	// input data
+	simfn, _ := io.ArgToFilename(0, "", ".sim", true)
+	io.Pf("\n%s\n", io.ArgsTable("INPUT ARGUMENTS",
+		"simulation filename", "simfn", simfn,
+	))
+
+	// report
+	sim := inp.ReadSim(simfn, "", false, 0)
+	rpt := sim.CalcDtReport()
+	io.Pf("%v", rpt)
+	for _, stg := range rpt.Stages {
+		if len(stg.Warnings) > 0 {
+			io.PfRed("\nWARNING: Δt of some stages is outside the recommended ranges\n")
+			return
+		}
+	}
+	io.PfGreen("\nΔt of all stages is within the recommended ranges\n")
+}
@@ -30,6 +30,11 @@ var (
 	dirout string // directory for output
 	fnkey  string // filename key
 	steady bool   // steady simulation
+	lbb    bool   // LBB elements; i.e. u-p elements with lower order p
+	v3beam bool   // show v3 of beams
+	exdisc bool   // element-discontinuous extrapolated values; i.e. without averaging at vertices
+
+	frmvals []map[string]float64 // [nverts] dofs transformed to frame, if out.Frm != nil
 
 	ukeys   = []string{"ux", "uy", "uz"}                      // displacement keys
 	skeys   = []string{"sx", "sy", "sz", "sxy", "syz", "szx"} // stress keys
@@ -38,11 +43,14 @@ var (
 	plkeys  = []string{"pl"}                                  // liquid pressure keys
 	pgkeys  = []string{"pg"}                                  // gas pressure keys
 	flkeys  = []string{"fl"}                                  // constraint/flux/seepage face key
+	liqkeys = []string{"Dpl", "ru", "gamc"}                   // liquefaction keys: excess pl, ru and cumulative shear strain
 
 	is_sig     map[string]bool     // is sigma key? "sx" => true
 	is_nwl     map[string]bool     // is nwl key? "nwlx" => true
 	is_nwg     map[string]bool     // is nwg key? "nwgx" => true
 	label2keys map[string][]string // maps, e.g., "u" => ukeys
+
+	label2prefix = map[string]string{"u": "u", "ex_nwl": "nwl", "ex_nwg": "nwg"} // prefixes of vectors transformed by frames
 )
 
 func init() {
@@ -50,7 +58,7 @@ func init() {
 	is_nwl = map[string]bool{"nwlx": true, "nwly": true, "nwlz": true}
 	is_nwg = map[string]bool{"nwgx": true, "nwgy": true, "nwgz": true}
 	label2keys = map[string][]string{
-		"u": ukeys, "sig": skeys, "nwl": nwlkeys, "ex_nwl": nwlkeys, "nwg": nwgkeys, "ex_nwg": nwgkeys,
+		"u": ukeys, "sig": skeys, "nwl": nwlkeys, "ex_nwl": nwlkeys, "nwg": nwgkeys, "ex_nwg": nwgkeys, "ex_liq": liqkeys,
 	}
 }
 
@@ -68,13 +76,19 @@ func main() {
 	exnwl := io.ArgToBool(1, false)
 	exnwg := io.ArgToBool(2, false)
 	stgidx := io.ArgToInt(3, 0)
-	v3beam := io.ArgToBool(4, false)
+	v3beam = io.ArgToBool(4, false)
+	exliq := io.ArgToBool(5, false)
+	exdisc = io.ArgToBool(6, false)
+	frmspec := io.ArgToString(7, "")
 	io.Pf("\n%s\n", io.ArgsTable("INPUT ARGUMENTS",
 		"simulation filename", "simfn", simfn,
 		"extrapolate nwl", "exnwl", exnwl,
 		"extrapolate nwg", "exnwg", exnwg,
 		"stage index", "stgidx", stgidx,
 		"show v3 of beams", "v3beam", v3beam,
+		"extrapolate liquefaction keys", "exliq", exliq,
+		"discontinuous extrapolated values", "exdisc", exdisc,
+		"frame for vectors and tensors", "frmspec", frmspec,
 	))
 
 	// start analysis process
@@ -90,6 +104,16 @@ func main() {
 	fnkey = out.Dom.Sim.Key
 	steady = out.Dom.Sim.Data.Steady
 
+	// frame
+	if frmspec != "" {
+		frm, err := out.ParseFrame(frmspec, ndim)
+		if err != nil {
+			chk.Panic("cannot parse frame:\n%v", err)
+		}
+		out.Frm = frm
+		frmvals = make([]map[string]float64, len(verts))
+	}
+
 	// flags
 	has_u := out.Dom.YandC["ux"]
 	has_pl := out.Dom.YandC["pl"]
@@ -97,8 +121,9 @@ func main() {
 	has_sig := out.Ipkeys["sx"]
 	has_nwl := out.Ipkeys["nwlx"]
 	has_nwg := out.Ipkeys["nwgx"]
+	has_liq := out.Ipkeys["ru"]
 	has_p := has_pl || has_pg
-	lbb := has_u && has_p
+	lbb = has_u && has_p
 	if out.Dom.Sim.Data.NoLBB {
 		lbb = false
 	}
@@ -136,6 +161,11 @@ func main() {
 		geo["ex_nwg"] = new(bytes.Buffer)
 		vtu["ex_nwg"] = new(bytes.Buffer)
 	}
+	if exliq && has_liq {
+		pvd["ex_liq"] = new(bytes.Buffer)
+		geo["ex_liq"] = new(bytes.Buffer)
+		vtu["ex_liq"] = new(bytes.Buffer)
+	}
 
 	// extrapolated values keys
 	var extrap_keys []string
@@ -145,6 +175,9 @@ func main() {
 	if exnwg && has_nwg {
 		extrap_keys = append(extrap_keys, nwgkeys[:ndim]...)
 	}
+	if exliq && has_liq {
+		extrap_keys = append(extrap_keys, liqkeys...)
+	}
 
 	// headers
 	for _, b := range pvd {
@@ -166,7 +199,7 @@ func main() {
 		// generate topology
 		if tidx == 0 {
 			for label, b := range geo {
-				topology(b, label == "ips", lbb, v3beam)
+				topology(b, label == "ips", discont(label))
 			}
 		}
 
@@ -187,6 +220,11 @@ func main() {
 			out.ComputeExtrapolatedValues(extrap_keys)
 		}
 
+		// transform to frame
+		if out.Frm != nil {
+			frame_transform()
+		}
+
 		// for each data buffer
 		for label, b := range vtu {
 
@@ -205,15 +243,37 @@ func main() {
 				if has_nwg {
 					pdata_write(b, "nwg", nwgkeys, true)
 				}
+				if out.Frm != nil {
+					if has_sig {
+						pdata_write(b, "sig_"+out.Frm.Type, out.Frm.TensorKeys("s"), true)
+					}
+					if has_nwl {
+						pdata_write(b, "nwl_"+out.Frm.Type, out.Frm.VectorKeys("nwl"), true)
+					}
+					if has_nwg {
+						pdata_write(b, "nwg_"+out.Frm.Type, out.Frm.VectorKeys("nwg"), true)
+					}
+				}
 				for key, _ := range out.Ipkeys {
 					if !is_sig[key] && !is_nwl[key] && !is_nwg[key] {
 						pdata_write(b, key, []string{key}, true)
 					}
 				}
 				pdata_close(b)
+			} else if label == "ex_liq" {
+				pdata_open(b)
+				for _, key := range liqkeys {
+					pdata_write(b, "ex_"+key, []string{key}, false)
+				}
+				pdata_close(b)
 			} else {
 				pdata_open(b)
 				pdata_write(b, label, label2keys[label], false)
+				if out.Frm != nil {
+					if prefix, ok := label2prefix[label]; ok {
+						pdata_write(b, label+"_"+out.Frm.Type, out.Frm.VectorKeys(prefix), false)
+					}
+				}
 				pdata_close(b)
 			}
 
@@ -270,6 +330,13 @@ func vtu_write(geo, dat *bytes.Buffer, tidx int, label string) {
 		nv = len(out.Ipoints)
 		nc = nv
 	}
+	if discont(label) {
+		nv = 0
+		for _, e := range elems {
+			nverts, _ := cells[e.Id()].GetVtkInfo(lbb, v3beam)
+			nv += nverts
+		}
+	}
 	var hdr, foo bytes.Buffer
 	io.Ff(&hdr, "<?xml version=\"1.0\"?>\n<VTKFile type=\"UnstructuredGrid\" version=\"0.1\" byte_order=\"LittleEndian\">\n<UnstructuredGrid>\n")
 	io.Ff(&hdr, "<Piece NumberOfPoints=\"%d\" NumberOfCells=\"%d\">\n", nv, nc)
@@ -279,7 +346,9 @@ func vtu_write(geo, dat *bytes.Buffer, tidx int, label string) {
 
 // topology ////////////////////////////////////////////////////////////////////////////////////////
 
-func topology(buf *bytes.Buffer, ips, lbb, v3beam bool) {
+// topology writes the coordinates and connectivities of cells. disc => vertices are duplicated
+// for each cell such that element-discontinuous values can be written (see discont)
+func topology(buf *bytes.Buffer, ips, disc bool) {
 	if buf == nil {
 		return
 	}
@@ -294,6 +363,18 @@ func topology(buf *bytes.Buffer, ips, lbb, v3beam bool) {
 			}
 			io.Ff(buf, "%23.15e %23.15e %23.15e ", p.X[0], p.X[1], z)
 		}
+	} else if disc {
+		for _, e := range elems {
+			cell := cells[e.Id()]
+			nverts, _ := cell.GetVtkInfo(lbb, v3beam)
+			for j := 0; j < nverts; j++ {
+				v := verts[cell.Verts[j]]
+				if ndim == 3 {
+					z = v.C[2]
+				}
+				io.Ff(buf, "%23.15e %23.15e %23.15e ", v.C[0], v.C[1], z)
+			}
+		}
 	} else {
 		for _, v := range verts {
 			if ndim == 3 {
@@ -310,6 +391,15 @@ func topology(buf *bytes.Buffer, ips, lbb, v3beam bool) {
 		for i, _ := range out.Ipoints {
 			io.Ff(buf, "%d ", i)
 		}
+	} else if disc {
+		var k int
+		for _, e := range elems {
+			nverts, _ := cells[e.Id()].GetVtkInfo(lbb, v3beam)
+			for j := 0; j < nverts; j++ {
+				io.Ff(buf, "%d ", k)
+				k++
+			}
+		}
 	} else {
 		for _, e := range elems {
 			cell := cells[e.Id()]
@@ -409,6 +499,27 @@ func pdata_write(buf *bytes.Buffer, label string, keys []string, ips bool) {
 			}
 			io.Ff(buf, l)
 		}
+	} else if extrap && exdisc {
+		// loop over vertices of cells => with extrapolated values without averaging
+		for _, e := range elems {
+			cell := cells[e.Id()]
+			nverts, _ := cell.GetVtkInfo(lbb, v3beam)
+			cvals := out.ExCellVals[cell.Id]
+			for j := 0; j < nverts; j++ {
+				l := zeros
+				if j < len(cvals) {
+					l = ""
+					for _, key := range keys {
+						if val, ok := cvals[j][key]; ok {
+							l += io.Sf("%23.15e ", val)
+						} else {
+							l += "0 "
+						}
+					}
+				}
+				io.Ff(buf, l)
+			}
+		}
 	} else if extrap {
 		// loop over vertices => with extrapolated values
 		for _, v := range verts {
@@ -444,6 +555,8 @@ func pdata_write(buf *bytes.Buffer, label string, keys []string, ips bool) {
 					eq := n.GetEq(key)
 					if eq >= 0 {
 						l += io.Sf("%23.15e ", Y[eq])
+					} else if val, ok := frame_val(v.Id, key); ok {
+						l += io.Sf("%23.15e ", val)
 					} else {
 						l += "0 "
 					}
@@ -457,8 +570,18 @@ func pdata_write(buf *bytes.Buffer, label string, keys []string, ips bool) {
 	// positive tags
 	if !ips {
 		io.Ff(buf, "<DataArray type=\"Int32\" Name=\"tag\" NumberOfComponents=\"1\" format=\"ascii\">\n")
-		for _, v := range verts {
-			io.Ff(buf, "%d ", iabs(v.Tag))
+		if extrap && exdisc {
+			for _, e := range elems {
+				cell := cells[e.Id()]
+				nverts, _ := cell.GetVtkInfo(lbb, v3beam)
+				for j := 0; j < nverts; j++ {
+					io.Ff(buf, "%d ", iabs(verts[cell.Verts[j]].Tag))
+				}
+			}
+		} else {
+			for _, v := range verts {
+				io.Ff(buf, "%d ", iabs(v.Tag))
+			}
 		}
 		io.Ff(buf, "\n</DataArray>\n")
 	}
@@ -502,6 +625,49 @@ func cdata_write(buf *bytes.Buffer, ips bool) {
 	io.Ff(buf, "\n</DataArray>\n</CellData>\n")
 }
 
+// frame_transform adds the components of vectors and tensors transformed to out.Frm to the values
+// at integration points, to the extrapolated values and to frmvals
+func frame_transform() {
+	for _, ip := range out.Ipoints {
+		out.Frm.AddDerived(ip.Vals, ip.X)
+	}
+	for _, v := range verts {
+		if out.ExVals != nil {
+			out.Frm.AddDerived(out.ExVals[v.Id], v.C)
+		}
+		n := out.Dom.Vid2node[v.Id]
+		if n == nil {
+			continue
+		}
+		frmvals[v.Id] = make(map[string]float64)
+		for _, key := range ukeys[:ndim] {
+			if eq := n.GetEq(key); eq >= 0 {
+				frmvals[v.Id][key] = out.Dom.Sol.Y[eq]
+			}
+		}
+		out.Frm.AddDerived(frmvals[v.Id], v.C)
+	}
+	for cid, cvals := range out.ExCellVals {
+		for j, vals := range cvals {
+			out.Frm.AddDerived(vals, verts[cells[cid].Verts[j]].C)
+		}
+	}
+}
+
+// frame_val returns the value of dof transformed to out.Frm at vertex vid, if available
+func frame_val(vid int, key string) (val float64, ok bool) {
+	if frmvals == nil || frmvals[vid] == nil {
+		return
+	}
+	val, ok = frmvals[vid][key]
+	return
+}
+
+// discont returns whether the data with label are written as element-discontinuous fields
+func discont(label string) bool {
+	return exdisc && len(label) > 2 && label[:2] == "ex"
+}
+
 func iabs(val int) int {
 	if val < 0 {
 		return -val
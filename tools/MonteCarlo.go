@@ -0,0 +1,58 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ignore
+
+package main
+
+import (
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/mpi"
+)
+
+func main() {
+
+	// catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			if mpi.Rank() == 0 {
+				io.PfRed("ERROR: %v\n", err)
+			}
+		}
+		mpi.Stop(false)
+	}()
+	mpi.Start(false)
+
+	// input data
+	simfn, fnkey := io.ArgToFilename(0, "", ".sim", true)
+	dirout := io.ArgToString(1, "/tmp/gofem")
+	verbose := io.ArgToBool(2, true)
+
+	// print input data
+	if mpi.Rank() == 0 {
+		io.Pf("\n%s\n", io.ArgsTable("INPUT ARGUMENTS",
+			"simulation filename", "simfn", simfn,
+			"directory for results of Monte Carlo simulations", "dirout", dirout,
+			"show messages", "verbose", verbose,
+		))
+	}
+
+	// run realisations
+	mc, err := fem.NewMonteCarlo(simfn, verbose)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+	err = mc.Run()
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+
+	// results
+	if mpi.Rank() == 0 {
+		io.Pf("\n%s", mc.Report())
+		mc.Save(dirout, fnkey)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2016 The Gofem Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ignore
+
+package main
+
+import (
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func main() {
+
+	// catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			io.PfRed("ERROR: %v\n", err)
+		}
+	}()
+
+	// input data
+	simfn, fnkey := io.ArgToFilename(0, "", ".sim", true)
+	dirout := io.ArgToString(1, "/tmp/gofem")
+
+	// print input data
+	io.Pf("\n%s\n", io.ArgsTable("INPUT ARGUMENTS",
+		"simulation filename", "simfn", simfn,
+		"directory for results of 2.5D analysis", "dirout", dirout,
+	))
+
+	// domain
+	fm := fem.NewMain(simfn, "", false, false, false, false, true, 0)
+	if fm.Sim.Wave25 == nil {
+		chk.Panic("simulation file %q does not have 2.5D analysis data (wave25d)", simfn)
+	}
+	err := fm.SetStage(0)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+
+	// run
+	w, err := fem.NewWave25D(fm.Domains[0], fm.Sim.Wave25)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+	err = w.Run()
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+	err = w.Save(dirout, fnkey)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+}